@@ -26,6 +26,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/crypto"
@@ -261,3 +262,24 @@ func UnpackRevert(data []byte) (string, error) {
 	}
 	return reason, nil
 }
+
+// panicSelector is a special function selector for panic code unpacking.
+var panicSelector = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+
+// UnpackPanic resolves the abi-encoded panic code. Since solidity 0.8.0, the failed assertions
+// and the runtime errors like an arithmetic overflow revert with the panic code encoded as if
+// it were a call to a function `Panic(uint256)`.
+func UnpackPanic(data []byte) (*big.Int, error) {
+	if len(data) < 4 {
+		return nil, errors.New("invalid data for unpacking")
+	}
+	if !bytes.Equal(data[:4], panicSelector) {
+		return nil, errors.New("invalid data for unpacking")
+	}
+	var code *big.Int
+	typ, _ := NewType("uint256", "", nil)
+	if err := (Arguments{{Type: typ}}).Unpack(&code, data[4:]); err != nil {
+		return nil, err
+	}
+	return code, nil
+}
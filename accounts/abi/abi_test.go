@@ -1168,3 +1168,34 @@ func TestUnpackRevert(t *testing.T) {
 		})
 	}
 }
+
+func TestUnpackPanic(t *testing.T) {
+	t.Parallel()
+
+	var cases = []struct {
+		input     string
+		expect    *big.Int
+		expectErr error
+	}{
+		{"", nil, errors.New("invalid data for unpacking")},
+		{"08c379a00000000000000000000000000000000000000000000000000000000000000011", nil, errors.New("invalid data for unpacking")},
+		{"4e487b710000000000000000000000000000000000000000000000000000000000000011", big.NewInt(0x11), nil},
+	}
+	for index, c := range cases {
+		t.Run(fmt.Sprintf("case %d", index), func(t *testing.T) {
+			got, err := UnpackPanic(common.Hex2Bytes(c.input))
+			if c.expectErr != nil {
+				if err == nil {
+					t.Fatalf("Expected non-nil error")
+				}
+				if err.Error() != c.expectErr.Error() {
+					t.Fatalf("Expected error mismatch, want %v, got %v", c.expectErr, err)
+				}
+				return
+			}
+			if c.expect.Cmp(got) != 0 {
+				t.Fatalf("Output mismatch, want %v, got %v", c.expect, got)
+			}
+		})
+	}
+}
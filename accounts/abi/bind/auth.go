@@ -81,6 +81,21 @@ func NewKeyedTransactor(key *ecdsa.PrivateKey) *TransactOpts {
 	}
 }
 
+// NewKeyedFeePayerTransactor is a utility method to easily create a fee payer signer
+// for fee-delegated transactions from a single private key.
+func NewKeyedFeePayerTransactor(key *ecdsa.PrivateKey) *FeePayerOpts {
+	keyAddr := crypto.PubkeyToAddress(key.PublicKey)
+	return &FeePayerOpts{
+		FeePayer: keyAddr,
+		Signer: func(signer types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != keyAddr {
+				return nil, errors.New("not authorized to sign this account")
+			}
+			return types.SignTxAsFeePayer(tx, signer, key)
+		},
+	}
+}
+
 // TODO-klaytn: clef related code
 /*
 // NewClefTransactor is a utility method to easily create a transaction signer
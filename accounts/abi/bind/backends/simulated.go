@@ -106,6 +106,13 @@ func NewSimulatedBackend(alloc blockchain.GenesisAlloc) *SimulatedBackend {
 	return NewSimulatedBackendWithDatabase(database.NewMemoryDBManager(), alloc, params.AllGxhashProtocolChanges)
 }
 
+// NewSimulatedBackendWithConfig creates a new binding backend using a simulated blockchain
+// with a given chain config for testing purposes. Governance parameters such as the unit price
+// and the reward config are taken from the given config.
+func NewSimulatedBackendWithConfig(alloc blockchain.GenesisAlloc, cfg *params.ChainConfig) *SimulatedBackend {
+	return NewSimulatedBackendWithDatabase(database.NewMemoryDBManager(), alloc, cfg)
+}
+
 // Close terminates the underlying blockchain's update loop.
 func (b *SimulatedBackend) Close() error {
 	b.blockchain.Stop()
@@ -137,7 +144,7 @@ func (b *SimulatedBackend) rollback() {
 	stateDB, _ := b.blockchain.State()
 
 	b.pendingBlock = blocks[0]
-	b.pendingState, _ = state.New(b.pendingBlock.Root(), stateDB.Database())
+	b.pendingState, _ = state.New(b.pendingBlock.Root(), stateDB.Database(), nil)
 }
 
 // stateByBlockNumber retrieves a state by a given blocknumber.
@@ -508,18 +515,38 @@ func (b *SimulatedBackend) callContract(_ context.Context, call klaytn.CallMsg,
 }
 
 // SendTransaction updates the pending block to include the given transaction.
-// It panics if the transaction is invalid.
+// Every Klaytn transaction type is supported. The signatures of the sender and the fee payer
+// are validated against their account keys in the pending state.
 func (b *SimulatedBackend) SendTransaction(_ context.Context, tx *types.Transaction) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	sender, err := types.Sender(types.NewEIP155Signer(b.config.ChainID), tx)
-	if err != nil {
-		panic(fmt.Errorf("invalid transaction: %v", err))
+	signer := types.NewEIP155Signer(b.config.ChainID)
+	blockNumber := b.pendingBlock.NumberU64()
+
+	if err := tx.Validate(b.pendingState, blockNumber); err != nil {
+		return fmt.Errorf("invalid transaction: %v", err)
+	}
+	if _, err := tx.ValidateSender(signer, b.pendingState, blockNumber); err != nil {
+		return fmt.Errorf("invalid transaction: %v", err)
+	}
+	if tx.IsFeeDelegatedTransaction() {
+		if _, err := tx.ValidateFeePayer(signer, b.pendingState, blockNumber); err != nil {
+			return fmt.Errorf("invalid fee payer: %v", err)
+		}
 	}
+	sender := tx.ValidatedSender()
 	nonce := b.pendingState.GetNonce(sender)
 	if tx.Nonce() != nonce {
-		panic(fmt.Errorf("invalid transaction nonce: got %d, want %d", tx.Nonce(), nonce))
+		return fmt.Errorf("invalid transaction nonce: got %d, want %d", tx.Nonce(), nonce)
+	}
+
+	// Apply the transaction to a copy of the pending state in advance,
+	// since the block generation below panics if the transaction cannot be applied.
+	header := b.pendingBlock.Header()
+	usedGas := header.GasUsed
+	if _, _, _, err := b.blockchain.ApplyTransaction(b.config, &params.AuthorAddressForTesting, b.pendingState.Copy(), header, tx, &usedGas, &vm.Config{}); err != nil {
+		return fmt.Errorf("failed to apply transaction: %v", err)
 	}
 
 	blocks, _ := blockchain.GenerateChain(b.config, b.blockchain.CurrentBlock(), gxhash.NewFaker(), b.database, 1, func(number int, block *blockchain.BlockGen) {
@@ -531,7 +558,7 @@ func (b *SimulatedBackend) SendTransaction(_ context.Context, tx *types.Transact
 	stateDB, _ := b.blockchain.State()
 
 	b.pendingBlock = blocks[0]
-	b.pendingState, _ = state.New(b.pendingBlock.Root(), stateDB.Database())
+	b.pendingState, _ = state.New(b.pendingBlock.Root(), stateDB.Database(), nil)
 	return nil
 }
 
@@ -651,7 +678,7 @@ func (b *SimulatedBackend) AdjustTime(adjustment time.Duration) error {
 	stateDB, _ := b.blockchain.State()
 
 	b.pendingBlock = blocks[0]
-	b.pendingState, _ = state.New(b.pendingBlock.Root(), stateDB.Database())
+	b.pendingState, _ = state.New(b.pendingBlock.Root(), stateDB.Database(), nil)
 
 	return nil
 }
@@ -702,6 +729,10 @@ func (fb *filterBackend) SubscribeRemovedLogsEvent(ch chan<- blockchain.RemovedL
 	return fb.bc.SubscribeRemovedLogsEvent(ch)
 }
 
+func (fb *filterBackend) SubscribeChainReorgEvent(ch chan<- blockchain.ChainReorgEvent) event.Subscription {
+	return fb.bc.SubscribeChainReorgEvent(ch)
+}
+
 func (fb *filterBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	return fb.bc.SubscribeLogsEvent(ch)
 }
@@ -712,6 +743,8 @@ func (fb *filterBackend) SubscribePendingLogsEvent(_ chan<- []*types.Log) event.
 
 func (fb *filterBackend) BloomStatus() (uint64, uint64) { return 4096, 0 }
 
+func (fb *filterBackend) LogIndexStatus() (uint64, uint64, bool) { return 0, 0, false }
+
 func (fb *filterBackend) ServiceFilter(_ context.Context, _ *bloombits.MatcherSession) {
 	panic("not supported")
 }
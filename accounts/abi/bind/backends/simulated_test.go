@@ -23,6 +23,7 @@ package backends
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
 	"math/big"
 	"strings"
 	"testing"
@@ -33,6 +34,7 @@ import (
 	"github.com/klaytn/klaytn/accounts/abi/bind"
 	"github.com/klaytn/klaytn/blockchain"
 	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/types/accountkey"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/params"
@@ -838,3 +840,170 @@ func TestSimulatedBackend_PendingAndCallContract(t *testing.T) {
 		t.Errorf("response from calling contract was expected to be 'hello world' instead received %v", string(res))
 	}
 }
+
+func TestSimulatedBackend_SendFeeDelegatedTransaction(t *testing.T) {
+	testAddr := crypto.PubkeyToAddress(testKey.PublicKey)
+	feePayerKey, _ := crypto.GenerateKey()
+	feePayer := crypto.PubkeyToAddress(feePayerKey.PublicKey)
+	to := common.HexToAddress("0x1000")
+
+	sim := NewSimulatedBackend(
+		blockchain.GenesisAlloc{
+			testAddr: {Balance: big.NewInt(10000000000)},
+			feePayer: {Balance: big.NewInt(10000000000)},
+		},
+	)
+	defer sim.Close()
+	bgCtx := context.Background()
+	signer := types.NewEIP155Signer(sim.config.ChainID)
+
+	// newTx returns a new transaction every time since the transaction caches the recovered public keys
+	newTx := func(feePayerKey *ecdsa.PrivateKey) *types.Transaction {
+		tx, err := types.NewTransactionWithMap(types.TxTypeFeeDelegatedValueTransfer, map[types.TxValueKeyType]interface{}{
+			types.TxValueKeyNonce:    uint64(0),
+			types.TxValueKeyFrom:     testAddr,
+			types.TxValueKeyTo:       to,
+			types.TxValueKeyAmount:   big.NewInt(1000),
+			types.TxValueKeyGasLimit: uint64(100000),
+			types.TxValueKeyGasPrice: big.NewInt(1),
+			types.TxValueKeyFeePayer: feePayer,
+		})
+		if err != nil {
+			t.Fatalf("could not create tx: %v", err)
+		}
+		if err := tx.SignWithKeys(signer, []*ecdsa.PrivateKey{testKey}); err != nil {
+			t.Fatalf("could not sign tx: %v", err)
+		}
+		if err := tx.SignFeePayerWithKeys(signer, []*ecdsa.PrivateKey{feePayerKey}); err != nil {
+			t.Fatalf("could not sign tx as a fee payer: %v", err)
+		}
+		return tx
+	}
+
+	// a fee-delegated transaction signed by a wrong fee payer key should be rejected
+	wrongKey, _ := crypto.GenerateKey()
+	if err := sim.SendTransaction(bgCtx, newTx(wrongKey)); err == nil {
+		t.Fatal("tx with an invalid fee payer signature should be rejected")
+	}
+
+	tx := newTx(feePayerKey)
+	if err := sim.SendTransaction(bgCtx, tx); err != nil {
+		t.Fatalf("could not add tx to pending block: %v", err)
+	}
+	sim.Commit()
+
+	receipt, err := sim.TransactionReceipt(bgCtx, tx.Hash())
+	if err != nil {
+		t.Fatalf("could not get transaction receipt: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Errorf("unexpected receipt status: %v", receipt.Status)
+	}
+
+	// the sender pays only the amount and the fee payer pays the whole fee
+	balance, err := sim.BalanceAt(bgCtx, testAddr, nil)
+	if err != nil {
+		t.Fatalf("could not get balance: %v", err)
+	}
+	if balance.Cmp(big.NewInt(10000000000-1000)) != 0 {
+		t.Errorf("unexpected sender balance: %v", balance)
+	}
+	balance, err = sim.BalanceAt(bgCtx, feePayer, nil)
+	if err != nil {
+		t.Fatalf("could not get balance: %v", err)
+	}
+	if balance.Cmp(big.NewInt(int64(10000000000-receipt.GasUsed))) != 0 {
+		t.Errorf("unexpected fee payer balance: %v", balance)
+	}
+}
+
+func TestSimulatedBackend_SendAccountUpdateAndAnchoringTransaction(t *testing.T) {
+	testAddr := crypto.PubkeyToAddress(testKey.PublicKey)
+	newKey, _ := crypto.GenerateKey()
+
+	sim := simTestBackend(testAddr)
+	defer sim.Close()
+	bgCtx := context.Background()
+	signer := types.NewEIP155Signer(sim.config.ChainID)
+
+	// update the account key of testAddr to a public key
+	tx, err := types.NewTransactionWithMap(types.TxTypeAccountUpdate, map[types.TxValueKeyType]interface{}{
+		types.TxValueKeyNonce:      uint64(0),
+		types.TxValueKeyFrom:       testAddr,
+		types.TxValueKeyGasLimit:   uint64(100000),
+		types.TxValueKeyGasPrice:   big.NewInt(1),
+		types.TxValueKeyAccountKey: accountkey.NewAccountKeyPublicWithValue(&newKey.PublicKey),
+	})
+	if err != nil {
+		t.Fatalf("could not create tx: %v", err)
+	}
+	if err := tx.SignWithKeys(signer, []*ecdsa.PrivateKey{testKey}); err != nil {
+		t.Fatalf("could not sign tx: %v", err)
+	}
+	if err := sim.SendTransaction(bgCtx, tx); err != nil {
+		t.Fatalf("could not add tx to pending block: %v", err)
+	}
+	sim.Commit()
+
+	// a legacy transaction cannot be sent any more since the account key is not a legacy key
+	legacyTx, err := types.SignTx(types.NewTransaction(uint64(1), testAddr, big.NewInt(1000), params.TxGas, big.NewInt(1), nil), signer, testKey)
+	if err != nil {
+		t.Fatalf("could not sign tx: %v", err)
+	}
+	if err := sim.SendTransaction(bgCtx, legacyTx); err == nil {
+		t.Fatal("legacy tx should be rejected after the account key is updated")
+	}
+
+	// newAnchoringTx returns a new transaction every time since the transaction caches the recovered public keys
+	newAnchoringTx := func(key *ecdsa.PrivateKey) *types.Transaction {
+		tx, err := types.NewTransactionWithMap(types.TxTypeChainDataAnchoring, map[types.TxValueKeyType]interface{}{
+			types.TxValueKeyNonce:        uint64(1),
+			types.TxValueKeyFrom:         testAddr,
+			types.TxValueKeyGasLimit:     uint64(100000),
+			types.TxValueKeyGasPrice:     big.NewInt(1),
+			types.TxValueKeyAnchoredData: []byte{0x01, 0x02, 0x03},
+		})
+		if err != nil {
+			t.Fatalf("could not create tx: %v", err)
+		}
+		if err := tx.SignWithKeys(signer, []*ecdsa.PrivateKey{key}); err != nil {
+			t.Fatalf("could not sign tx: %v", err)
+		}
+		return tx
+	}
+
+	// the old key is no longer valid for testAddr
+	if err := sim.SendTransaction(bgCtx, newAnchoringTx(testKey)); err == nil {
+		t.Fatal("tx signed with the old key should be rejected")
+	}
+
+	anchoringTx := newAnchoringTx(newKey)
+	if err := sim.SendTransaction(bgCtx, anchoringTx); err != nil {
+		t.Fatalf("could not add tx to pending block: %v", err)
+	}
+	sim.Commit()
+
+	receipt, err := sim.TransactionReceipt(bgCtx, anchoringTx.Hash())
+	if err != nil {
+		t.Fatalf("could not get transaction receipt: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Errorf("unexpected receipt status: %v", receipt.Status)
+	}
+}
+
+func TestNewSimulatedBackendWithConfig(t *testing.T) {
+	config := *params.AllGxhashProtocolChanges
+	config.UnitPrice = 25 * params.Ston
+
+	sim := NewSimulatedBackendWithConfig(blockchain.GenesisAlloc{}, &config)
+	defer sim.Close()
+
+	gasPrice, err := sim.SuggestGasPrice(context.Background())
+	if err != nil {
+		t.Errorf("could not get gas price: %v", err)
+	}
+	if gasPrice.Uint64() != config.UnitPrice {
+		t.Errorf("gas price was not expected value of %v. actual: %v", config.UnitPrice, gasPrice.Uint64())
+	}
+}
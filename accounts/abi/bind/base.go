@@ -32,6 +32,7 @@ import (
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/params"
 )
 
 // SignerFn is a signer function callback when a contract requires a method to
@@ -60,6 +61,15 @@ type TransactOpts struct {
 	Context context.Context // Network context to support cancellation and timeouts (nil = no timeout)
 }
 
+// FeePayerOpts is the collection of authorization data required for a fee payer
+// to pay the transaction fee of a fee-delegated transaction on behalf of the sender.
+type FeePayerOpts struct {
+	FeePayer common.Address // Klaytn account to pay the transaction fee
+	Signer   SignerFn       // Method to use for signing the transaction as the fee payer (mandatory)
+
+	FeeRatio types.FeeRatio // Ratio of the fee paid by the fee payer in percentage (0 = whole fee)
+}
+
 // FilterOpts is the collection of options to fine tune filtering for events
 // within a bound contract.
 type FilterOpts struct {
@@ -109,7 +119,7 @@ func DeployContract(opts *TransactOpts, abi abi.ABI, bytecode []byte, backend Co
 	if err != nil {
 		return common.Address{}, nil, nil, err
 	}
-	tx, err := c.transact(opts, nil, append(bytecode, input...))
+	tx, err := c.transact(opts, nil, nil, append(bytecode, input...))
 	if err != nil {
 		return common.Address{}, nil, nil, err
 	}
@@ -177,7 +187,21 @@ func (c *BoundContract) Transact(opts *TransactOpts, method string, params ...in
 	}
 	// todo(rjl493456442) check the method is payable or not,
 	// reject invalid transaction at the first place
-	return c.transact(opts, &c.address, input)
+	return c.transact(opts, nil, &c.address, input)
+}
+
+// FeeDelegatedTransact invokes the (paid) contract method with params as input values
+// by a fee-delegated smart contract execution transaction. The transaction fee is
+// paid by the fee payer given by feePayerOpts.
+func (c *BoundContract) FeeDelegatedTransact(opts *TransactOpts, feePayerOpts *FeePayerOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	if feePayerOpts == nil {
+		return nil, errors.New("nil feePayerOpts")
+	}
+	input, err := c.abi.Pack(method, params...)
+	if err != nil {
+		return nil, err
+	}
+	return c.transact(opts, feePayerOpts, &c.address, input)
 }
 
 // RawTransact initiates a transaction with the given raw calldata as the input.
@@ -185,7 +209,16 @@ func (c *BoundContract) Transact(opts *TransactOpts, method string, params ...in
 func (c *BoundContract) RawTransact(opts *TransactOpts, calldata []byte) (*types.Transaction, error) {
 	// todo(rjl493456442) check the method is payable or not,
 	// reject invalid transaction at the first place
-	return c.transact(opts, &c.address, calldata)
+	return c.transact(opts, nil, &c.address, calldata)
+}
+
+// FeeDelegatedRawTransact initiates a fee-delegated transaction with the given raw calldata as the input.
+// The transaction fee is paid by the fee payer given by feePayerOpts.
+func (c *BoundContract) FeeDelegatedRawTransact(opts *TransactOpts, feePayerOpts *FeePayerOpts, calldata []byte) (*types.Transaction, error) {
+	if feePayerOpts == nil {
+		return nil, errors.New("nil feePayerOpts")
+	}
+	return c.transact(opts, feePayerOpts, &c.address, calldata)
 }
 
 // Transfer initiates a plain transaction to move funds to the contract, calling
@@ -193,17 +226,27 @@ func (c *BoundContract) RawTransact(opts *TransactOpts, calldata []byte) (*types
 func (c *BoundContract) Transfer(opts *TransactOpts) (*types.Transaction, error) {
 	// todo(rjl493456442) check the payable fallback or receive is defined
 	// or not, reject invalid transaction at the first place
-	return c.transact(opts, &c.address, nil)
+	return c.transact(opts, nil, &c.address, nil)
 }
 
 // transact executes an actual transaction invocation, first deriving any missing
 // authorization fields, and then scheduling the transaction for execution.
-func (c *BoundContract) transact(opts *TransactOpts, contract *common.Address, input []byte) (*types.Transaction, error) {
+// If feePayerOpts is not nil, a fee-delegated smart contract execution transaction
+// signed by both the sender and the fee payer is scheduled instead.
+func (c *BoundContract) transact(opts *TransactOpts, feePayerOpts *FeePayerOpts, contract *common.Address, input []byte) (*types.Transaction, error) {
 	var err error
 
 	if opts == nil {
 		return nil, errors.New("nil transactOpts")
 	}
+	if feePayerOpts != nil {
+		if contract == nil {
+			return nil, errors.New("fee-delegated contract deployment is not supported")
+		}
+		if feePayerOpts.FeeRatio != 0 && !feePayerOpts.FeeRatio.IsValid() {
+			return nil, fmt.Errorf("invalid fee ratio: %d", feePayerOpts.FeeRatio)
+		}
+	}
 
 	// Ensure a valid value field and resolve the account nonce
 	value := opts.Value
@@ -243,10 +286,23 @@ func (c *BoundContract) transact(opts *TransactOpts, contract *common.Address, i
 		if err != nil {
 			return nil, fmt.Errorf("failed to estimate gas needed: %v", err)
 		}
+		// The estimation is done with a legacy message, so add the intrinsic gas for the fee delegation
+		if feePayerOpts != nil {
+			if feePayerOpts.FeeRatio == 0 {
+				gasLimit += params.TxGasFeeDelegated
+			} else {
+				gasLimit += params.TxGasFeeDelegatedWithRatio
+			}
+		}
 	}
 	// Create the transaction, sign it and schedule it for execution
 	var rawTx *types.Transaction
-	if contract == nil {
+	if feePayerOpts != nil {
+		rawTx, err = newFeeDelegatedExecution(opts.From, feePayerOpts, nonce, c.address, value, gasLimit, gasPrice, input)
+		if err != nil {
+			return nil, err
+		}
+	} else if contract == nil {
 		rawTx = types.NewContractCreation(nonce, value, gasLimit, gasPrice, input)
 	} else {
 		rawTx = types.NewTransaction(nonce, c.address, value, gasLimit, gasPrice, input)
@@ -265,12 +321,42 @@ func (c *BoundContract) transact(opts *TransactOpts, contract *common.Address, i
 	if err != nil {
 		return nil, err
 	}
+	if feePayerOpts != nil {
+		if feePayerOpts.Signer == nil {
+			return nil, errors.New("no fee payer signer to authorize the transaction with")
+		}
+		signedTx, err = feePayerOpts.Signer(signer, feePayerOpts.FeePayer, signedTx)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if err := c.transactor.SendTransaction(ensureContext(opts.Context), signedTx); err != nil {
 		return nil, err
 	}
 	return signedTx, nil
 }
 
+// newFeeDelegatedExecution creates an unsigned fee-delegated smart contract execution transaction.
+// The fee ratio type is used if the fee ratio of feePayerOpts is set.
+func newFeeDelegatedExecution(from common.Address, feePayerOpts *FeePayerOpts, nonce uint64, to common.Address, value *big.Int, gasLimit uint64, gasPrice *big.Int, input []byte) (*types.Transaction, error) {
+	txType := types.TxTypeFeeDelegatedSmartContractExecution
+	values := map[types.TxValueKeyType]interface{}{
+		types.TxValueKeyNonce:    nonce,
+		types.TxValueKeyFrom:     from,
+		types.TxValueKeyTo:       to,
+		types.TxValueKeyAmount:   value,
+		types.TxValueKeyGasLimit: gasLimit,
+		types.TxValueKeyGasPrice: gasPrice,
+		types.TxValueKeyData:     input,
+		types.TxValueKeyFeePayer: feePayerOpts.FeePayer,
+	}
+	if feePayerOpts.FeeRatio != 0 {
+		txType = types.TxTypeFeeDelegatedSmartContractExecutionWithRatio
+		values[types.TxValueKeyFeeRatioOfFeePayer] = feePayerOpts.FeeRatio
+	}
+	return types.NewTransactionWithMap(txType, values)
+}
+
 // FilterLogs filters contract logs for past blocks, returning the necessary
 // channels to construct a strongly typed bound iterator on top of them.
 func (c *BoundContract) FilterLogs(opts *FilterOpts, name string, query ...[]interface{}) (chan types.Log, event.Subscription, error) {
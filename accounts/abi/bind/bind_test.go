@@ -335,6 +335,87 @@ var bindTests = []struct {
 		nil,
 		nil,
 	},
+	// Tests that a fee payer can pay the fee of a contract transaction on behalf of the sender
+	{
+		`FeeDelegatedInteractor`,
+		`
+			contract FeeDelegatedInteractor {
+				string public deployString;
+				string public transactString;
+
+				function FeeDelegatedInteractor(string str) {
+				  deployString = str;
+				}
+
+				function transact(string str) {
+				  transactString = str;
+				}
+			}
+		`,
+		[]string{`6060604052604051610328380380610328833981016040528051018060006000509080519060200190828054600181600116156101000203166002900490600052602060002090601f016020900481019282601f10608d57805160ff19168380011785555b50607c9291505b8082111560ba57838155600101606b565b50505061026a806100be6000396000f35b828001600101855582156064579182015b828111156064578251826000505591602001919060010190609e565b509056606060405260e060020a60003504630d86a0e181146100315780636874e8091461008d578063d736c513146100ea575b005b610190600180546020600282841615610100026000190190921691909104601f810182900490910260809081016040526060828152929190828280156102295780601f106101fe57610100808354040283529160200191610229565b61019060008054602060026001831615610100026000190190921691909104601f810182900490910260809081016040526060828152929190828280156102295780601f106101fe57610100808354040283529160200191610229565b60206004803580820135601f81018490049093026080908101604052606084815261002f946024939192918401918190838280828437509496505050505050508060016000509080519060200190828054600181600116156101000203166002900490600052602060002090601f016020900481019282601f1061023157805160ff19168380011785555b506102619291505b808211156102665760008155830161017d565b60405180806020018281038252838181518152602001915080519060200190808383829060006004602084601f0104600f02600301f150905090810190601f1680156101f05780820380516001836020036101000a031916815260200191505b509250505060405180910390f35b820191906000526020600020905b81548152906001019060200180831161020c57829003601f168201915b505050505081565b82800160010185558215610175579182015b82811115610175578251826000505591602001919060010190610243565b505050565b509056`},
+		[]string{`[{"constant":true,"inputs":[],"name":"transactString","outputs":[{"name":"","type":"string"}],"type":"function"},{"constant":true,"inputs":[],"name":"deployString","outputs":[{"name":"","type":"string"}],"type":"function"},{"constant":false,"inputs":[{"name":"str","type":"string"}],"name":"transact","outputs":[],"type":"function"},{"inputs":[{"name":"str","type":"string"}],"type":"constructor"}]`},
+		`
+			"context"
+			"math/big"
+
+			"github.com/klaytn/klaytn/accounts/abi/bind"
+			"github.com/klaytn/klaytn/accounts/abi/bind/backends"
+			"github.com/klaytn/klaytn/blockchain"
+			"github.com/klaytn/klaytn/crypto"
+		`,
+		`
+			// Generate a sender, a fee payer and a funded simulator
+			key, _ := crypto.GenerateKey()
+			auth := bind.NewKeyedTransactor(key)
+			feePayerKey, _ := crypto.GenerateKey()
+			feePayerAuth := bind.NewKeyedFeePayerTransactor(feePayerKey)
+
+			sim := backends.NewSimulatedBackend(blockchain.GenesisAlloc{
+				auth.From:             {Balance: big.NewInt(10000000000)},
+				feePayerAuth.FeePayer: {Balance: big.NewInt(10000000000)},
+			})
+			defer sim.Close()
+
+			// Deploy an interaction tester contract
+			_, _, interactor, err := DeployFeeDelegatedInteractor(auth, sim, "Deploy string")
+			if err != nil {
+				t.Fatalf("Failed to deploy interactor contract: %v", err)
+			}
+			sim.Commit()
+
+			senderBalance, _ := sim.BalanceAt(context.Background(), auth.From, nil)
+
+			// Publish a fee-delegated transaction on a deployed contract
+			auth.GasPrice = big.NewInt(1)
+			tx, err := interactor.FeeDelegatedTransact(auth, feePayerAuth, "Transact string")
+			if err != nil {
+				t.Fatalf("Failed to transact with interactor contract: %v", err)
+			}
+			sim.Commit()
+
+			if str, err := interactor.TransactString(nil); err != nil {
+				t.Fatalf("Failed to retrieve transact string: %v", err)
+			} else if str != "Transact string" {
+				t.Fatalf("Transact string mismatch: have '%s', want 'Transact string'", str)
+			}
+			// The fee should be paid by the fee payer only
+			receipt, err := sim.TransactionReceipt(context.Background(), tx.Hash())
+			if err != nil {
+				t.Fatalf("Failed to retrieve receipt: %v", err)
+			}
+			if balance, _ := sim.BalanceAt(context.Background(), auth.From, nil); balance.Cmp(senderBalance) != 0 {
+				t.Fatalf("Sender balance mismatch: have %v, want %v", balance, senderBalance)
+			}
+			want := new(big.Int).Sub(big.NewInt(10000000000), new(big.Int).SetUint64(receipt.GasUsed))
+			if balance, _ := sim.BalanceAt(context.Background(), feePayerAuth.FeePayer, nil); balance.Cmp(want) != 0 {
+				t.Fatalf("Fee payer balance mismatch: have %v, want %v", balance, want)
+			}
+		`,
+		nil,
+		nil,
+		nil,
+		nil,
+	},
 	// Tests that plain values can be properly returned and deserialized
 	{
 		`Getter`,
@@ -283,6 +283,12 @@ var (
 		return _{{$contract.Type}}.Contract.{{$contract.Type}}Transactor.contract.Transact(opts, method, params...)
 	}
 
+	// FeeDelegatedTransact invokes the (paid) contract method with params as input values
+	// by a fee-delegated transaction whose fee is paid by the fee payer.
+	func (_{{$contract.Type}} *{{$contract.Type}}Raw) FeeDelegatedTransact(opts *bind.TransactOpts, feePayerOpts *bind.FeePayerOpts, method string, params ...interface{}) (*types.Transaction, error) {
+		return _{{$contract.Type}}.Contract.{{$contract.Type}}Transactor.contract.FeeDelegatedTransact(opts, feePayerOpts, method, params...)
+	}
+
 	// Call invokes the (constant) contract method with params as input values and
 	// sets the output to result. The result type might be a single field for simple
 	// returns, a slice of interfaces for anonymous returns and a struct for named
@@ -302,6 +308,12 @@ var (
 		return _{{$contract.Type}}.Contract.contract.Transact(opts, method, params...)
 	}
 
+	// FeeDelegatedTransact invokes the (paid) contract method with params as input values
+	// by a fee-delegated transaction whose fee is paid by the fee payer.
+	func (_{{$contract.Type}} *{{$contract.Type}}TransactorRaw) FeeDelegatedTransact(opts *bind.TransactOpts, feePayerOpts *bind.FeePayerOpts, method string, params ...interface{}) (*types.Transaction, error) {
+		return _{{$contract.Type}}.Contract.contract.FeeDelegatedTransact(opts, feePayerOpts, method, params...)
+	}
+
 	{{range .Calls}}
 		// {{.Normalized.Name}} is a free data retrieval call binding the contract method 0x{{printf "%x" .Original.ID}}.
 		//
@@ -345,6 +357,13 @@ var (
 			return _{{$contract.Type}}.contract.Transact(opts, "{{.Original.Name}}" {{range .Normalized.Inputs}}, {{.Name}}{{end}})
 		}
 
+		// FeeDelegated{{.Normalized.Name}} is a paid mutator fee-delegated transaction binding the contract method 0x{{printf "%x" .Original.ID}}.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}Transactor) FeeDelegated{{.Normalized.Name}}(opts *bind.TransactOpts, feePayerOpts *bind.FeePayerOpts {{range .Normalized.Inputs}}, {{.Name}} {{bindtype .Type $structs}} {{end}}) (*types.Transaction, error) {
+			return _{{$contract.Type}}.contract.FeeDelegatedTransact(opts, feePayerOpts, "{{.Original.Name}}" {{range .Normalized.Inputs}}, {{.Name}}{{end}})
+		}
+
 		// {{.Normalized.Name}} is a paid mutator transaction binding the contract method 0x{{printf "%x" .Original.ID}}.
 		//
 		// Solidity: {{.Original.String}}
@@ -368,6 +387,13 @@ var (
 			return _{{$contract.Type}}.contract.RawTransact(opts, calldata)
 		}
 
+		// FeeDelegatedFallback is a paid mutator fee-delegated transaction binding the contract fallback function.
+		//
+		// Solidity: {{.Fallback.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}Transactor) FeeDelegatedFallback(opts *bind.TransactOpts, feePayerOpts *bind.FeePayerOpts, calldata []byte) (*types.Transaction, error) {
+			return _{{$contract.Type}}.contract.FeeDelegatedRawTransact(opts, feePayerOpts, calldata)
+		}
+
 		// Fallback is a paid mutator transaction binding the contract fallback function.
 		//
 		// Solidity: {{.Fallback.Original.String}}
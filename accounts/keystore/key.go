@@ -255,6 +255,13 @@ func writeTemporaryKeyFile(file string, content []byte) (string, error) {
 		os.Remove(f.Name())
 		return "", err
 	}
+	// Flush the content before the file is moved into place, not to leave a partially
+	// written key file on a crash
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
 	f.Close()
 	return f.Name(), err
 }
@@ -98,6 +98,9 @@ func (ks *KeyStore) init(keydir string) {
 	ks.mu.Lock()
 	defer ks.mu.Unlock()
 
+	// Recover the key files whose writes were interrupted before they are cached
+	recoverKeyDir(keydir)
+
 	// Initialize the set of unlocked keys and the account cache
 	ks.unlocked = make(map[common.Address]*unlocked)
 	ks.cache, ks.changes = newAccountCache(keydir)
@@ -0,0 +1,119 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/klaytn/klaytn/common"
+)
+
+// tmpKeyFilePattern matches the temporary files created by writeTemporaryKeyFile,
+// which are left in the keystore directory if the writes are interrupted.
+var tmpKeyFilePattern = regexp.MustCompile(`^\.(.+)\.tmp[0-9]+$`)
+
+// keyFilePattern matches the names of the key files given by keyFileName.
+var keyFilePattern = regexp.MustCompile(`^UTC--.+--[0-9a-fA-F]{40}$`)
+
+// RecoveryReport describes the leftovers of the interrupted writes found in a keystore directory.
+type RecoveryReport struct {
+	Recovered []string // complete temporary key files moved into place
+	Removed   []string // temporary key files removed since they are incomplete or already in place
+	Corrupted []string // key files which cannot be decoded, possibly written partially
+}
+
+// Empty returns true if nothing is found to recover.
+func (r *RecoveryReport) Empty() bool {
+	return len(r.Recovered) == 0 && len(r.Removed) == 0 && len(r.Corrupted) == 0
+}
+
+// RecoverKeyDir recovers the keystore directory from the writes interrupted by an unclean shutdown.
+// A temporary key file is moved into place if it is complete and the key file does not exist,
+// since the key may exist only in the temporary file. Otherwise it is removed. The corrupted key
+// files are only reported, since they must be restored by the operator from a backup.
+func RecoverKeyDir(keydir string) (*RecoveryReport, error) {
+	report := new(RecoveryReport)
+	files, err := ioutil.ReadDir(keydir)
+	if os.IsNotExist(err) {
+		return report, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, fi := range files {
+		path := filepath.Join(keydir, fi.Name())
+		if fi.IsDir() || fi.Mode()&os.ModeType != 0 {
+			continue
+		}
+		if match := tmpKeyFilePattern.FindStringSubmatch(fi.Name()); match != nil {
+			target := filepath.Join(keydir, match[1])
+			if _, err := os.Stat(target); os.IsNotExist(err) && isCompleteKeyFile(path) {
+				if err := os.Rename(path, target); err != nil {
+					return nil, err
+				}
+				report.Recovered = append(report.Recovered, target)
+				continue
+			}
+			if err := os.Remove(path); err != nil {
+				return nil, err
+			}
+			report.Removed = append(report.Removed, path)
+			continue
+		}
+		// Only the files named as key files are checked, since other files can be in the directory
+		if keyFilePattern.MatchString(fi.Name()) && !isCompleteKeyFile(path) {
+			report.Corrupted = append(report.Corrupted, path)
+		}
+	}
+	return report, nil
+}
+
+// isCompleteKeyFile returns true if the file is a JSON object with a non-zero address.
+func isCompleteKeyFile(path string) bool {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var key struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(content, &key); err != nil {
+		return false
+	}
+	return common.HexToAddress(key.Address) != common.Address{}
+}
+
+// recoverKeyDir recovers the keystore directory and reports the result to the operator.
+func recoverKeyDir(keydir string) {
+	report, err := RecoverKeyDir(keydir)
+	if err != nil {
+		logger.Error("Failed to recover the keystore directory", "dir", keydir, "err", err)
+		return
+	}
+	for _, path := range report.Recovered {
+		logger.Warn("Recovered a key file whose write was interrupted", "path", path)
+	}
+	for _, path := range report.Removed {
+		logger.Info("Removed a temporary key file left by an interrupted write", "path", path)
+	}
+	for _, path := range report.Corrupted {
+		logger.Error("Found a corrupted key file, possibly written partially by an unclean shutdown; restore it from a backup", "path", path)
+	}
+}
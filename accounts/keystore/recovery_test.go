@@ -0,0 +1,80 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRecoverKeyDir tests if the leftovers of the interrupted writes are recovered or reported.
+func TestRecoverKeyDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "klaytn-keystore-recovery")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		content    = []byte(`{"address":"7ef5a6135f1fd6a02593eedc869c6d41d934aef8","crypto":{}}`)
+		complete   = filepath.Join(dir, keyFileName(common.HexToAddress("0x1")))
+		incomplete = filepath.Join(dir, keyFileName(common.HexToAddress("0x2")))
+		written    = filepath.Join(dir, keyFileName(common.HexToAddress("0x3")))
+		corrupted  = filepath.Join(dir, keyFileName(common.HexToAddress("0x4")))
+	)
+	write := func(path string, content []byte) {
+		assert.NoError(t, ioutil.WriteFile(path, content, 0600))
+	}
+	tmpName := func(path string) string {
+		return filepath.Join(dir, "."+filepath.Base(path)+".tmp123")
+	}
+	write(tmpName(complete), content)
+	write(tmpName(incomplete), content[:20])
+	write(tmpName(written), content)
+	write(written, content)
+	write(corrupted, content[:20])
+	write(filepath.Join(dir, "README"), []byte("not a key file"))
+
+	report, err := RecoverKeyDir(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{complete}, report.Recovered)
+	assert.ElementsMatch(t, []string{tmpName(incomplete), tmpName(written)}, report.Removed)
+	assert.Equal(t, []string{corrupted}, report.Corrupted)
+
+	recovered, err := ioutil.ReadFile(complete)
+	assert.NoError(t, err)
+	assert.Equal(t, content, recovered)
+	for _, path := range []string{tmpName(complete), tmpName(incomplete), tmpName(written)} {
+		_, err := os.Stat(path)
+		assert.True(t, os.IsNotExist(err), path)
+	}
+
+	// Nothing is left to recover, and the corrupted key file is reported again
+	report, err = RecoverKeyDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Recovered)
+	assert.Empty(t, report.Removed)
+	assert.Equal(t, []string{corrupted}, report.Corrupted)
+
+	// A missing directory has nothing to recover
+	report, err = RecoverKeyDir(filepath.Join(dir, "missing"))
+	assert.NoError(t, err)
+	assert.True(t, report.Empty())
+}
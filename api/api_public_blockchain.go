@@ -22,11 +22,13 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"time"
 
 	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
 	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/blockchain/types/account"
 	"github.com/klaytn/klaytn/blockchain/types/accountkey"
@@ -96,13 +98,15 @@ func (s *PublicBlockChainAPI) IsContractAccount(ctx context.Context, address com
 //	return state.IsHumanReadable(address), state.Error()
 //}
 
-// GetBlockReceipts returns all the transaction receipts for the given block hash.
-func (s *PublicBlockChainAPI) GetBlockReceipts(ctx context.Context, blockHash common.Hash) ([]map[string]interface{}, error) {
-	receipts := s.b.GetBlockReceipts(ctx, blockHash)
-	block, err := s.b.BlockByHash(ctx, blockHash)
-	if err != nil {
+// GetBlockReceipts returns all the transaction receipts for the given block number or hash.
+// A block hash is accepted as it is, so the existing callers giving the hash are still served.
+func (s *PublicBlockChainAPI) GetBlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]map[string]interface{}, error) {
+	block, err := s.b.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if block == nil || err != nil {
 		return nil, err
 	}
+	blockHash := block.Hash()
+	receipts := s.b.GetBlockReceipts(ctx, blockHash)
 	txs := block.Transactions()
 	if receipts.Len() != txs.Len() {
 		return nil, fmt.Errorf("the size of transactions and receipts is different in the block (%s)", blockHash.String())
@@ -202,6 +206,71 @@ func (s *PublicBlockChainAPI) GetStorageAt(ctx context.Context, address common.A
 	return res[:], state.Error()
 }
 
+// AccountResult is the Merkle proof of an account and its storage slots, defined by EIP-1186.
+// The proofs are the RLP-encoded trie nodes from the root to the leaf, and the account proof
+// proves the account encoded in the klaytn account format.
+type AccountResult struct {
+	Address      common.Address  `json:"address"`
+	AccountProof []hexutil.Bytes `json:"accountProof"`
+	Balance      *hexutil.Big    `json:"balance"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	Nonce        hexutil.Uint64  `json:"nonce"`
+	StorageHash  common.Hash     `json:"storageHash"`
+	StorageProof []StorageResult `json:"storageProof"`
+}
+
+// StorageResult is the Merkle proof of a storage slot.
+type StorageResult struct {
+	Key   string          `json:"key"`
+	Value *hexutil.Big    `json:"value"`
+	Proof []hexutil.Bytes `json:"proof"`
+}
+
+// GetProof returns the Merkle proof of the account and its storage slots of the given keys at the
+// given block, which can be verified with the state root of the block.
+func (s *PublicBlockChainAPI) GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNrOrHash rpc.BlockNumberOrHash) (*AccountResult, error) {
+	state, _, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	accountProof, err := state.GetProof(address)
+	if err != nil {
+		return nil, err
+	}
+
+	// The storage proofs of a non-existent account are empty
+	exist := state.Exist(address)
+	storageProof := make([]StorageResult, len(storageKeys))
+	for i, key := range storageKeys {
+		var proof [][]byte
+		if exist {
+			if proof, err = state.GetStorageProof(address, common.HexToHash(key)); err != nil {
+				return nil, err
+			}
+		}
+		value := state.GetState(address, common.HexToHash(key))
+		storageProof[i] = StorageResult{Key: key, Value: (*hexutil.Big)(value.Big()), Proof: toHexBytesSlice(proof)}
+	}
+
+	return &AccountResult{
+		Address:      address,
+		AccountProof: toHexBytesSlice(accountProof),
+		Balance:      (*hexutil.Big)(state.GetBalance(address)),
+		CodeHash:     state.GetCodeHash(address),
+		Nonce:        hexutil.Uint64(state.GetNonce(address)),
+		StorageHash:  state.GetStorageHash(address),
+		StorageProof: storageProof,
+	}, state.Error()
+}
+
+func toHexBytesSlice(b [][]byte) []hexutil.Bytes {
+	r := make([]hexutil.Bytes, len(b))
+	for i := range b {
+		r[i] = b[i]
+	}
+	return r
+}
+
 // GetAccountKey returns the account key of EOA at a given address.
 // If the account of the given address is a Legacy Account or a Smart Contract Account, it will return nil.
 func (s *PublicBlockChainAPI) GetAccountKey(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*accountkey.AccountKeySerializer, error) {
@@ -239,22 +308,9 @@ type CallArgs struct {
 	Data     hexutil.Bytes   `json:"data"`
 }
 
-func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, vmCfg vm.Config, timeout time.Duration, globalGasCap *big.Int) ([]byte, uint64, uint64, bool, error) {
-	defer func(start time.Time) { logger.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
-
-	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
-	if state == nil || err != nil {
-		return nil, 0, 0, false, err
-	}
-	// Set sender address or use a default if none specified
-	addr := args.From
-	if addr == (common.Address{}) {
-		if wallets := b.AccountManager().Wallets(); len(wallets) > 0 {
-			if accounts := wallets[0].Accounts(); len(accounts) > 0 {
-				addr = accounts[0].Address
-			}
-		}
-	}
+// ToMessage converts the call arguments to a call message. The default gas and gas price are used
+// if they are not given, and the gas is capped by globalGasCap.
+func (args *CallArgs) ToMessage(globalGasCap *big.Int, rules params.Rules) (*types.Transaction, error) {
 	// Set default gas & gas price if none were set
 	gas, gasPrice := uint64(args.Gas), args.GasPrice.ToInt()
 	if gas == 0 {
@@ -268,13 +324,127 @@ func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.Blo
 		gasPrice = new(big.Int).SetUint64(defaultGasPrice)
 	}
 
-	intrinsicGas, err := types.IntrinsicGas(args.Data, args.To == nil, b.ChainConfig().Rules(header.Number))
+	intrinsicGas, err := types.IntrinsicGas(args.Data, args.To == nil, rules)
 	if err != nil {
+		return nil, err
+	}
+	return types.NewMessage(args.From, args.To, 0, args.Value.ToInt(), gas, gasPrice, args.Data, false, intrinsicGas), nil
+}
+
+// OverrideAccount specifies the fields of an account overridden during the execution of a call.
+// State replaces the entire storage of the account while StateDiff replaces only the given slots.
+// Code can be overridden only for a smart contract account or an account which does not exist.
+type OverrideAccount struct {
+	Nonce     *hexutil.Uint64              `json:"nonce"`
+	Code      *hexutil.Bytes               `json:"code"`
+	Balance   *hexutil.Big                 `json:"balance"`
+	State     *map[common.Hash]common.Hash `json:"state"`
+	StateDiff *map[common.Hash]common.Hash `json:"stateDiff"`
+}
+
+// StateOverride is the set of the accounts overridden during the execution of a call.
+type StateOverride map[common.Address]OverrideAccount
+
+// Apply overrides the accounts in the given state.
+func (diff *StateOverride) Apply(statedb *state.StateDB) error {
+	if diff == nil {
+		return nil
+	}
+	for addr, override := range *diff {
+		if override.State != nil && override.StateDiff != nil {
+			return fmt.Errorf("account %s has both 'state' and 'stateDiff'", addr.Hex())
+		}
+		// The code is set first, which creates a smart contract account if the account does not exist
+		if override.Code != nil {
+			if err := statedb.SetCode(addr, *override.Code); err != nil {
+				return fmt.Errorf("failed to override the code of account %s: %v", addr.Hex(), err)
+			}
+		}
+		if override.Nonce != nil {
+			statedb.SetNonce(addr, uint64(*override.Nonce))
+		}
+		if override.Balance != nil {
+			statedb.SetBalance(addr, override.Balance.ToInt())
+		}
+		if override.State != nil {
+			statedb.SetStorage(addr, *override.State)
+		}
+		if override.StateDiff != nil {
+			for key, value := range *override.StateDiff {
+				statedb.SetState(addr, key, value)
+			}
+		}
+	}
+	return nil
+}
+
+// BlockOverrides specifies the fields of the block header overridden during the execution of a call.
+// BlockScore is returned by the DIFFICULTY opcode, since a Klaytn block has no randomness of its own.
+type BlockOverrides struct {
+	Number     *hexutil.Big `json:"number"`
+	Time       *hexutil.Big `json:"time"`
+	BlockScore *hexutil.Big `json:"blockScore"`
+}
+
+// Apply overrides the fields of the given header.
+func (diff *BlockOverrides) Apply(header *types.Header) {
+	if diff == nil {
+		return
+	}
+	if diff.Number != nil {
+		header.Number = diff.Number.ToInt()
+	}
+	if diff.Time != nil {
+		header.Time = diff.Time.ToInt()
+	}
+	if diff.BlockScore != nil {
+		header.BlockScore = diff.BlockScore.ToInt()
+	}
+}
+
+// stateAndHeaderWithOverrides returns the state and the header of the given block with the overrides applied.
+// The header is copied before it is overridden, so the cached header of the block is not modified.
+func stateAndHeaderWithOverrides(ctx context.Context, b Backend, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, blockOverrides *BlockOverrides) (*state.StateDB, *types.Header, error) {
+	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, nil, err
+	}
+	if err := overrides.Apply(state); err != nil {
+		return nil, nil, err
+	}
+	if blockOverrides != nil {
+		header = types.CopyHeader(header)
+		blockOverrides.Apply(header)
+	}
+	return state, header, nil
+}
+
+func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, blockOverrides *BlockOverrides, vmCfg vm.Config, timeout time.Duration, globalGasCap *big.Int) ([]byte, uint64, uint64, bool, error) {
+	defer func(start time.Time) { logger.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
+
+	state, header, err := stateAndHeaderWithOverrides(ctx, b, blockNrOrHash, overrides, blockOverrides)
+	if state == nil || err != nil {
 		return nil, 0, 0, false, err
 	}
+	return doCall(ctx, b, args, state, header, vmCfg, timeout, globalGasCap)
+}
+
+// doCall executes the call on the given state, which is modified by the execution.
+func doCall(ctx context.Context, b Backend, args CallArgs, state *state.StateDB, header *types.Header, vmCfg vm.Config, timeout time.Duration, globalGasCap *big.Int) ([]byte, uint64, uint64, bool, error) {
+	// Set sender address or use a default if none specified
+	if args.From == (common.Address{}) {
+		if wallets := b.AccountManager().Wallets(); len(wallets) > 0 {
+			if accounts := wallets[0].Accounts(); len(accounts) > 0 {
+				args.From = accounts[0].Address
+			}
+		}
+	}
 
 	// Create new call message
-	msg := types.NewMessage(addr, args.To, 0, args.Value.ToInt(), gas, gasPrice, args.Data, false, intrinsicGas)
+	msg, err := args.ToMessage(globalGasCap, b.ChainConfig().Rules(header.Number))
+	if err != nil {
+		return nil, 0, 0, false, err
+	}
 
 	// Setup context so it may be cancelled the call has completed
 	// or, in case of unmetered gas, setup a context with a timeout.
@@ -311,8 +481,8 @@ func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.Blo
 	}
 
 	// Propagate error of Receipt as JSON RPC error
-	if err == nil {
-		err = blockchain.GetVMerrFromReceiptStatus(kerr.Status)
+	if err == nil && kerr.Status != types.ReceiptStatusSuccessful {
+		err = newTxError(kerr.Status, res)
 	}
 
 	return res, gas, evm.GetOpCodeComputationCost(), kerr.Status != types.ReceiptStatusSuccessful, err
@@ -320,27 +490,177 @@ func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.Blo
 
 // Call executes the given transaction on the state for the given block number or hash.
 // It doesn't make and changes in the state/blockchain and is useful to execute and retrieve values.
-func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
-	result, _, _, _, err := DoCall(ctx, s.b, args, blockNrOrHash, vm.Config{}, localTxExecutionTime, s.b.RPCGasCap())
+// The accounts and the header of the block can be overridden during the execution.
+func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, blockOverrides *BlockOverrides) (hexutil.Bytes, error) {
+	result, _, _, _, err := DoCall(ctx, s.b, args, blockNrOrHash, overrides, blockOverrides, vm.Config{}, localTxExecutionTime, s.b.RPCGasCap())
 	return (hexutil.Bytes)(result), err
 }
 
-func (s *PublicBlockChainAPI) EstimateComputationCost(ctx context.Context, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
-	_, _, computationCost, _, err := DoCall(ctx, s.b, args, blockNrOrHash, vm.Config{UseOpcodeComputationCost: true}, localTxExecutionTime, s.b.RPCGasCap())
+// EstimateComputationCost returns the computation cost of executing the given transaction against the given block.
+// The accounts can be overridden during the execution, e.g. to simulate a contract not deployed yet.
+func (s *PublicBlockChainAPI) EstimateComputationCost(ctx context.Context, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride) (hexutil.Uint64, error) {
+	_, _, computationCost, _, err := DoCall(ctx, s.b, args, blockNrOrHash, overrides, nil, vm.Config{UseOpcodeComputationCost: true}, localTxExecutionTime, s.b.RPCGasCap())
 	return (hexutil.Uint64)(computationCost), err
 }
 
-// EstimateGas returns an estimate of the amount of gas needed to execute the given transaction against the latest block.
-func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (hexutil.Uint64, error) {
-	return s.DoEstimateGas(ctx, s.b, args, s.b.RPCGasCap())
+// accessListResult is the result of CreateAccessList.
+type accessListResult struct {
+	Accesslist *types.AccessList `json:"accessList"`
+	Error      string            `json:"error,omitempty"`
+	GasUsed    hexutil.Uint64    `json:"gasUsed"`
+}
+
+// CreateAccessList executes the given transaction against the given block, the latest block by default,
+// and returns the accounts and the storage slots accessed by the execution with the gas used.
+// If the execution fails, the list accessed until the failure is returned with the error.
+// The accounts can be overridden during the execution.
+func (s *PublicBlockChainAPI) CreateAccessList(ctx context.Context, args CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *StateOverride) (*accessListResult, error) {
+	bNrOrHash := rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+	state, header, err := stateAndHeaderWithOverrides(ctx, s.b, bNrOrHash, overrides, nil)
+	if state == nil || err != nil {
+		return nil, err
+	}
+
+	tracer := vm.NewAccessListTracer()
+	_, gasUsed, _, _, err := doCall(ctx, s.b, args, state, header, vm.Config{Debug: true, Tracer: tracer}, localTxExecutionTime, s.b.RPCGasCap())
+	// Only the failure of the execution is returned in the result, not the invalid transaction
+	var txErr *TxError
+	if err != nil && !errors.As(err, &txErr) {
+		return nil, err
+	}
+	accessList := tracer.AccessList()
+	result := &accessListResult{Accesslist: &accessList, GasUsed: hexutil.Uint64(gasUsed)}
+	if txErr != nil {
+		result.Error = txErr.Error()
+	}
+	return result, nil
+}
+
+// maxSimulateCalls is the maximum number of calls simulated by SimulateV1 in a request.
+const maxSimulateCalls = 1000
+
+// SimulateOpts is the bundle of calls simulated by SimulateV1. The accounts and the header of the
+// block are overridden before the first call.
+type SimulateOpts struct {
+	Calls          []CallArgs      `json:"calls"`
+	StateOverrides *StateOverride  `json:"stateOverrides"`
+	BlockOverrides *BlockOverrides `json:"blockOverrides"`
+}
+
+// simCallError is the error of a failed call in a bundle, which has the same code and data as
+// the JSON-RPC error returned by klay_call.
+type simCallError struct {
+	Message string      `json:"message"`
+	Code    int         `json:"code"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// simCallResult is the result of a call in a bundle simulated by SimulateV1.
+type simCallResult struct {
+	ReturnData hexutil.Bytes  `json:"returnData"`
+	Logs       []*types.Log   `json:"logs"`
+	GasUsed    hexutil.Uint64 `json:"gasUsed"`
+	Status     hexutil.Uint   `json:"status"`
+	Error      *simCallError  `json:"error,omitempty"`
+}
+
+// SimulateV1 executes the given calls in order against the given block, the latest block by default.
+// Each call is executed on the state left by the previous calls, so a multi-step interaction can be
+// tested at once without sending transactions. A failed call is returned in its result and the rest
+// of the bundle goes on, while an invalid call aborts the whole bundle. The gas cap of the node
+// limits the total gas used by the bundle, and the timeout applies to the whole bundle.
+func (s *PublicBlockChainAPI) SimulateV1(ctx context.Context, opts SimulateOpts, blockNrOrHash *rpc.BlockNumberOrHash) ([]*simCallResult, error) {
+	if len(opts.Calls) == 0 {
+		return nil, errors.New("no calls to simulate")
+	}
+	if len(opts.Calls) > maxSimulateCalls {
+		return nil, fmt.Errorf("too many calls to simulate: %d > %d", len(opts.Calls), maxSimulateCalls)
+	}
+	bNrOrHash := rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+	state, header, err := stateAndHeaderWithOverrides(ctx, s.b, bNrOrHash, opts.StateOverrides, opts.BlockOverrides)
+	if state == nil || err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, localTxExecutionTime)
+	defer cancel()
+
+	gasCap := s.b.RPCGasCap()
+	if gasCap != nil {
+		gasCap = new(big.Int).Set(gasCap)
+	}
+	results := make([]*simCallResult, len(opts.Calls))
+	for i, args := range opts.Calls {
+		if gasCap != nil && gasCap.Sign() == 0 {
+			return nil, fmt.Errorf("call %d: the gas cap of the bundle is used up", i)
+		}
+		// The logs of a call are kept apart by a hash given to the call, which has no transaction hash
+		callHash := common.BigToHash(big.NewInt(int64(i + 1)))
+		state.Prepare(callHash, header.Hash(), i)
+
+		ret, gasUsed, _, _, err := doCall(ctx, s.b, args, state, header, vm.Config{}, localTxExecutionTime, gasCap)
+		var txErr *TxError
+		if err != nil && !errors.As(err, &txErr) {
+			return nil, fmt.Errorf("call %d: %v", i, err)
+		}
+		state.Finalise(true, false)
+
+		result := &simCallResult{
+			ReturnData: common.CopyBytes(ret),
+			Logs:       []*types.Log{},
+			GasUsed:    hexutil.Uint64(gasUsed),
+			Status:     hexutil.Uint(types.ReceiptStatusSuccessful),
+		}
+		for _, log := range state.GetLogs(callHash) {
+			log.TxHash = common.Hash{}
+			result.Logs = append(result.Logs, log)
+		}
+		if txErr != nil {
+			result.Status = hexutil.Uint(txErr.Status())
+			result.Error = &simCallError{Message: txErr.Error(), Code: txErr.ErrorCode(), Data: txErr.ErrorData()}
+		}
+		results[i] = result
+
+		if gasCap != nil {
+			if gasCap.Uint64() > gasUsed {
+				gasCap.SetUint64(gasCap.Uint64() - gasUsed)
+			} else {
+				gasCap.SetUint64(0)
+			}
+		}
+	}
+	return results, nil
+}
+
+// EstimateGas returns an estimate of the amount of gas needed to execute the given transaction against the
+// given block, the latest block by default. The accounts and the header of the block can be overridden
+// during the estimation. If the transaction is reverted, the returned error carries the revert reason.
+func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *StateOverride, blockOverrides *BlockOverrides) (hexutil.Uint64, error) {
+	bNrOrHash := rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+	return s.DoEstimateGas(ctx, s.b, args, bNrOrHash, overrides, blockOverrides, s.b.RPCGasCap())
 }
 
-func (s *PublicBlockChainAPI) DoEstimateGas(ctx context.Context, b Backend, args CallArgs, gasCap *big.Int) (hexutil.Uint64, error) {
+func (s *PublicBlockChainAPI) DoEstimateGas(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, blockOverrides *BlockOverrides, gasCap *big.Int) (hexutil.Uint64, error) {
+	// The state is retrieved once and copied for each execution
+	state, header, err := stateAndHeaderWithOverrides(ctx, b, blockNrOrHash, overrides, blockOverrides)
+	if state == nil || err != nil {
+		return 0, err
+	}
+
 	// Binary search the gas requirement, as it may be higher than the amount used
 	var (
-		lo  uint64 = params.TxGas - 1
-		hi  uint64
-		cap uint64
+		lo        uint64 = params.TxGas - 1
+		hi        uint64
+		tolerance = b.RPCEstimateGasTolerance()
 	)
 	if uint64(args.Gas) >= params.TxGas {
 		hi = uint64(args.Gas)
@@ -352,31 +672,64 @@ func (s *PublicBlockChainAPI) DoEstimateGas(ctx context.Context, b Backend, args
 		logger.Warn("Caller gas above allowance, capping", "requested", hi, "cap", gasCap)
 		hi = gasCap.Uint64()
 	}
-	cap = hi
 
-	// Create a helper to check if a gas allowance results in an executable transaction
-	executable := func(gas uint64) bool {
+	// Create a helper to execute the transaction with a gas allowance
+	execute := func(gas uint64) (uint64, bool, error) {
 		args.Gas = hexutil.Uint64(gas)
 
-		_, _, _, failed, err := DoCall(ctx, b, args, rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber), vm.Config{UseOpcodeComputationCost: true}, localTxExecutionTime, gasCap)
-		if err != nil || failed {
-			return false
+		_, usedGas, _, failed, err := doCall(ctx, b, args, state.Copy(), header, vm.Config{UseOpcodeComputationCost: true}, localTxExecutionTime, gasCap)
+		return usedGas, failed, err
+	}
+	// Create a helper to check if a gas allowance results in an executable transaction
+	executable := func(gas uint64) (bool, error) {
+		_, failed, err := execute(gas)
+		if failed {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	// Reject the transaction as invalid if it fails at the highest allowance
+	usedGas, failed, err := execute(hi)
+	if failed {
+		if errors.Is(err, vm.ErrExecutionReverted) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("gas required exceeds allowance or always failing transaction: %v", err)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// The used gas is a lower bound of the required gas, which rarely exceeds the used gas much
+	// unless the remaining gas is checked by the transaction, so it is tried first
+	lo = usedGas - 1
+	if optimistic := (usedGas + params.CallStipend) * 64 / 63; optimistic < hi {
+		ok, err := executable(optimistic)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			hi = optimistic
+		} else {
+			lo = optimistic
 		}
-		return true
 	}
 	// Execute the binary search and hone in on an executable gas limit
 	for lo+1 < hi {
+		// Stop if the gas allowance exceeds the required gas within the tolerance
+		if float64(hi-lo)/float64(hi) < tolerance {
+			break
+		}
 		mid := (hi + lo) / 2
-		if !executable(mid) {
-			lo = mid
-		} else {
-			hi = mid
+		ok, err := executable(mid)
+		if err != nil {
+			return 0, err
 		}
-	}
-	// Reject the transaction as invalid if it still fails at the highest allowance
-	if hi == cap {
-		if !executable(hi) {
-			return 0, fmt.Errorf("gas required exceeds allowance or always failing transaction")
+		if ok {
+			hi = mid
+		} else {
+			lo = mid
 		}
 	}
 	return hexutil.Uint64(hi), nil
@@ -525,6 +878,12 @@ func newRPCPendingTransaction(tx *types.Transaction) map[string]interface{} {
 	return newRPCTransaction(tx, common.Hash{}, 0, 0)
 }
 
+// RpcOutputPendingTransaction converts the given pending transaction to the RPC output
+// which is the same as the one of klay_getTransactionByHash.
+func RpcOutputPendingTransaction(tx *types.Transaction) map[string]interface{} {
+	return newRPCPendingTransaction(tx)
+}
+
 // newRPCTransactionFromBlockIndex returns a transaction that will serialize to the RPC representation.
 func newRPCTransactionFromBlockIndex(b *types.Block, index uint64) map[string]interface{} {
 	txs := b.Transactions()
@@ -0,0 +1,436 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mock_api "github.com/klaytn/klaytn/api/mocks"
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+// revertingCode is the runtime code which reverts with Error("nope") if the storage slot 0 is zero,
+// or stores 1 to the storage slot 1 otherwise.
+var revertingCode = common.FromHex(
+	"600054" + "607757" + // SLOAD(0), JUMPI(0x77)
+		"7f08c379a000000000000000000000000000000000000000000000000000000000" + "600052" + // selector and offset
+		"7f0000002000000000000000000000000000000000000000000000000000000000" + "602052" + // offset and length
+		"7f000000046e6f7065000000000000000000000000000000000000000000000000" + "604052" + // length and "nope"
+		"60646000fd" + // REVERT(0, 100)
+		"5b" + "6001600155" + "00") // JUMPDEST, SSTORE(1, 1), STOP
+
+func TestEstimateGas(t *testing.T) {
+	var (
+		mockCtrl = gomock.NewController(t)
+		backend  = mock_api.NewMockBackend(mockCtrl)
+		api      = NewPublicBlockChainAPI(backend)
+		header   = &types.Header{Number: big.NewInt(1), Time: big.NewInt(1), BlockScore: big.NewInt(1)}
+		from     = common.HexToAddress("0x1000")
+		contract = common.HexToAddress("0x2000")
+		latest   = rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	)
+	defer mockCtrl.Finish()
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
+	assert.NoError(t, err)
+
+	executions := 0
+	backend.EXPECT().ChainConfig().Return(params.TestChainConfig).AnyTimes()
+	backend.EXPECT().RPCGasCap().Return(nil).AnyTimes()
+	backend.EXPECT().StateAndHeaderByNumberOrHash(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+			return statedb.Copy(), header, nil
+		}).AnyTimes()
+	backend.EXPECT().GetEVM(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, msg blockchain.Message, state *state.StateDB, header *types.Header, vmCfg vm.Config) (*vm.EVM, func() error, error) {
+			executions++
+			state.AddBalance(msg.ValidatedSender(), new(big.Int).Mul(new(big.Int).SetUint64(msg.Gas()), msg.GasPrice()))
+			context := blockchain.NewEVMContext(msg, header, nil, &common.Address{})
+			return vm.NewEVM(context, state, params.TestChainConfig, &vmCfg), func() error { return nil }, nil
+		}).AnyTimes()
+
+	tolerance := 0.0
+	backend.EXPECT().RPCEstimateGasTolerance().DoAndReturn(func() float64 { return tolerance }).AnyTimes()
+
+	// A value transfer needs the exact intrinsic gas
+	gas, err := api.EstimateGas(context.Background(), CallArgs{From: from, To: &contract}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, hexutil.Uint64(params.TxGas), gas)
+
+	// The balance of the sender can be overridden
+	value := hexutil.Big(*big.NewInt(params.KLAY))
+	_, err = api.EstimateGas(context.Background(), CallArgs{From: from, To: &contract, Value: value}, nil, nil, nil)
+	assert.Error(t, err)
+	gas, err = api.EstimateGas(context.Background(), CallArgs{From: from, To: &contract, Value: value}, &latest, &StateOverride{
+		from: {Balance: &value},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, hexutil.Uint64(params.TxGas), gas)
+
+	// The revert reason is returned if the transaction is reverted
+	code := hexutil.Bytes(revertingCode)
+	_, err = api.EstimateGas(context.Background(), CallArgs{From: from, To: &contract}, nil, &StateOverride{
+		contract: {Code: &code},
+	}, nil)
+	if assert.IsType(t, &TxError{}, err) {
+		revert := err.(*TxError)
+		assert.Equal(t, "evm: execution reverted: nope", revert.Error())
+		assert.Equal(t, 3, revert.ErrorCode())
+		assert.Equal(t, &RevertReason{Kind: RevertKindError, Message: "nope", Data: common.FromHex("0x08c379a0" +
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+			"0000000000000000000000000000000000000000000000000000000000000004" +
+			"6e6f706500000000000000000000000000000000000000000000000000000000")}, revert.Revert())
+	}
+
+	// The storage can be overridden entirely or partially
+	slot0 := map[common.Hash]common.Hash{{}: common.BytesToHash([]byte{1})}
+	assert.Error(t, (&StateOverride{contract: {State: &slot0, StateDiff: &slot0}}).Apply(statedb.Copy()))
+
+	var (
+		exact           hexutil.Uint64
+		exactExecutions int
+	)
+	for _, override := range []OverrideAccount{{Code: &code, State: &slot0}, {Code: &code, StateDiff: &slot0}} {
+		executions = 0
+		overrides := &StateOverride{contract: override}
+		exact, err = api.EstimateGas(context.Background(), CallArgs{From: from, To: &contract}, nil, overrides, nil)
+		assert.NoError(t, err)
+		exactExecutions = executions
+
+		// The estimated gas is the least gas to execute the transaction
+		_, _, _, failed, err := DoCall(context.Background(), backend, CallArgs{From: from, To: &contract, Gas: exact}, latest, overrides, nil, vm.Config{}, 0, nil)
+		assert.NoError(t, err)
+		assert.False(t, failed)
+		_, _, _, failed, _ = DoCall(context.Background(), backend, CallArgs{From: from, To: &contract, Gas: exact - 1}, latest, overrides, nil, vm.Config{}, 0, nil)
+		assert.True(t, failed)
+	}
+
+	// The estimation stops early within the tolerance
+	tolerance = 0.1
+	executions = 0
+	gas, err = api.EstimateGas(context.Background(), CallArgs{From: from, To: &contract}, nil, &StateOverride{
+		contract: {Code: &code, StateDiff: &slot0},
+	}, nil)
+	assert.NoError(t, err)
+	assert.True(t, gas >= exact && float64(gas) < float64(exact)*1.1, "estimated %d, exact %d", gas, exact)
+	assert.True(t, executions < exactExecutions, "executions %d, exact executions %d", executions, exactExecutions)
+}
+
+func TestGetProof(t *testing.T) {
+	var (
+		mockCtrl = gomock.NewController(t)
+		backend  = mock_api.NewMockBackend(mockCtrl)
+		api      = NewPublicBlockChainAPI(backend)
+		contract = common.HexToAddress("0x2000")
+		missing  = common.HexToAddress("0x3000")
+		latest   = rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	)
+	defer mockCtrl.Finish()
+
+	db := state.NewDatabase(database.NewMemoryDBManager())
+	statedb, err := state.New(common.Hash{}, db, nil)
+	assert.NoError(t, err)
+	statedb.SetCode(contract, revertingCode)
+	statedb.SetState(contract, common.Hash{}, common.BigToHash(big.NewInt(5)))
+	root, err := statedb.Commit(true)
+	assert.NoError(t, err)
+
+	backend.EXPECT().StateAndHeaderByNumberOrHash(gomock.Any(), latest).DoAndReturn(
+		func(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+			statedb, err := state.New(root, db, nil)
+			return statedb, &types.Header{Number: big.NewInt(1), Root: root}, err
+		}).AnyTimes()
+
+	result, err := api.GetProof(context.Background(), contract, []string{"0x0", "0x1"}, latest)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, result.AccountProof)
+	assert.Equal(t, crypto.Keccak256Hash(revertingCode), result.CodeHash)
+	assert.NotEqual(t, common.Hash{}, result.StorageHash)
+	if assert.Len(t, result.StorageProof, 2) {
+		assert.Equal(t, "0x0", result.StorageProof[0].Key)
+		assert.Equal(t, big.NewInt(5), result.StorageProof[0].Value.ToInt())
+		assert.NotEmpty(t, result.StorageProof[0].Proof)
+		assert.Zero(t, result.StorageProof[1].Value.ToInt().Sign())
+	}
+
+	// The absence of an account is proven without the storage proofs
+	result, err = api.GetProof(context.Background(), missing, []string{"0x0"}, latest)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, result.AccountProof)
+	assert.Zero(t, result.Balance.ToInt().Sign())
+	if assert.Len(t, result.StorageProof, 1) {
+		assert.Empty(t, result.StorageProof[0].Proof)
+	}
+}
+
+// TestCallWithBlockOverrides tests if the block number, the time and the block score seen by a call
+// can be overridden without modifying the header of the block.
+func TestCallWithBlockOverrides(t *testing.T) {
+	var (
+		mockCtrl = gomock.NewController(t)
+		backend  = mock_api.NewMockBackend(mockCtrl)
+		api      = NewPublicBlockChainAPI(backend)
+		header   = &types.Header{Number: big.NewInt(1), Time: big.NewInt(1), BlockScore: big.NewInt(1)}
+		from     = common.HexToAddress("0x1000")
+		contract = common.HexToAddress("0x2000")
+		latest   = rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		// returns NUMBER, TIMESTAMP and DIFFICULTY
+		code = hexutil.Bytes(common.FromHex("43600052" + "42602052" + "44604052" + "60606000f3"))
+	)
+	defer mockCtrl.Finish()
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
+	assert.NoError(t, err)
+
+	backend.EXPECT().ChainConfig().Return(params.TestChainConfig).AnyTimes()
+	backend.EXPECT().RPCGasCap().Return(nil).AnyTimes()
+	backend.EXPECT().RPCEstimateGasTolerance().Return(0.0).AnyTimes()
+	backend.EXPECT().StateAndHeaderByNumberOrHash(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+			return statedb.Copy(), header, nil
+		}).AnyTimes()
+	backend.EXPECT().GetEVM(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, msg blockchain.Message, state *state.StateDB, header *types.Header, vmCfg vm.Config) (*vm.EVM, func() error, error) {
+			state.AddBalance(msg.ValidatedSender(), new(big.Int).Mul(new(big.Int).SetUint64(msg.Gas()), msg.GasPrice()))
+			context := blockchain.NewEVMContext(msg, header, nil, &common.Address{})
+			return vm.NewEVM(context, state, params.TestChainConfig, &vmCfg), func() error { return nil }, nil
+		}).AnyTimes()
+
+	overrides := &StateOverride{contract: {Code: &code}}
+	ret, err := api.Call(context.Background(), CallArgs{From: from, To: &contract}, latest, overrides, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, common.FromHex("0x"+
+		"0000000000000000000000000000000000000000000000000000000000000001"+
+		"0000000000000000000000000000000000000000000000000000000000000001"+
+		"0000000000000000000000000000000000000000000000000000000000000001"), []byte(ret))
+
+	blockOverrides := &BlockOverrides{
+		Number:     (*hexutil.Big)(big.NewInt(100)),
+		Time:       (*hexutil.Big)(big.NewInt(200)),
+		BlockScore: (*hexutil.Big)(big.NewInt(3)),
+	}
+	ret, err = api.Call(context.Background(), CallArgs{From: from, To: &contract}, latest, overrides, blockOverrides)
+	assert.NoError(t, err)
+	assert.Equal(t, common.FromHex("0x"+
+		"0000000000000000000000000000000000000000000000000000000000000064"+
+		"00000000000000000000000000000000000000000000000000000000000000c8"+
+		"0000000000000000000000000000000000000000000000000000000000000003"), []byte(ret))
+	assert.Equal(t, big.NewInt(1), header.Number)
+
+	// The gas is estimated with the overridden block as well
+	_, err = api.EstimateGas(context.Background(), CallArgs{From: from, To: &contract}, &latest, overrides, blockOverrides)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), header.Time)
+}
+
+// TestGetBlockReceipts tests if the receipts of a block are returned by the number or the hash of the block.
+func TestGetBlockReceipts(t *testing.T) {
+	var (
+		mockCtrl = gomock.NewController(t)
+		backend  = mock_api.NewMockBackend(mockCtrl)
+		api      = NewPublicBlockChainAPI(backend)
+		signer   = types.NewEIP155Signer(params.TestChainConfig.ChainID)
+		key, _   = crypto.GenerateKey()
+		txs      types.Transactions
+		receipts types.Receipts
+	)
+	defer mockCtrl.Finish()
+
+	for i := 0; i < 2; i++ {
+		tx, err := types.SignTx(types.NewTransaction(uint64(i), common.HexToAddress("0x2000"), big.NewInt(1), 21000, big.NewInt(1), nil), signer, key)
+		assert.NoError(t, err)
+		txs = append(txs, tx)
+		receipts = append(receipts, &types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: 21000, TxHash: tx.Hash()})
+	}
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(3)}).WithBody(txs)
+
+	byNumber := rpc.NewBlockNumberOrHashWithNumber(3)
+	byHash := rpc.NewBlockNumberOrHashWithHash(block.Hash(), false)
+	unknown := rpc.NewBlockNumberOrHashWithNumber(4)
+	backend.EXPECT().BlockByNumberOrHash(gomock.Any(), byNumber).Return(block, nil)
+	backend.EXPECT().BlockByNumberOrHash(gomock.Any(), byHash).Return(block, nil)
+	backend.EXPECT().BlockByNumberOrHash(gomock.Any(), unknown).Return(nil, nil)
+	backend.EXPECT().GetBlockReceipts(gomock.Any(), block.Hash()).Return(receipts).Times(2)
+
+	for _, blockNrOrHash := range []rpc.BlockNumberOrHash{byNumber, byHash} {
+		results, err := api.GetBlockReceipts(context.Background(), blockNrOrHash)
+		assert.NoError(t, err)
+		if assert.Len(t, results, 2) {
+			for i, result := range results {
+				assert.Equal(t, txs[i].Hash(), result["transactionHash"])
+				assert.Equal(t, block.Hash(), result["blockHash"])
+				assert.Equal(t, hexutil.Uint(i), result["transactionIndex"])
+			}
+		}
+	}
+
+	results, err := api.GetBlockReceipts(context.Background(), unknown)
+	assert.NoError(t, err)
+	assert.Nil(t, results)
+}
+
+// TestSimulationsWithStateOverrides tests if the accounts can be overridden when the computation cost
+// and the access list of a transaction are estimated, e.g. to simulate a contract not deployed yet.
+func TestSimulationsWithStateOverrides(t *testing.T) {
+	var (
+		mockCtrl = gomock.NewController(t)
+		backend  = mock_api.NewMockBackend(mockCtrl)
+		api      = NewPublicBlockChainAPI(backend)
+		header   = &types.Header{Number: big.NewInt(1), Time: big.NewInt(1), BlockScore: big.NewInt(1)}
+		from     = common.HexToAddress("0x1000")
+		contract = common.HexToAddress("0x2000")
+		latest   = rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		code     = hexutil.Bytes(revertingCode)
+		slot0    = map[common.Hash]common.Hash{{}: common.BytesToHash([]byte{1})}
+	)
+	defer mockCtrl.Finish()
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
+	assert.NoError(t, err)
+
+	backend.EXPECT().ChainConfig().Return(params.TestChainConfig).AnyTimes()
+	backend.EXPECT().RPCGasCap().Return(nil).AnyTimes()
+	backend.EXPECT().StateAndHeaderByNumberOrHash(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+			return statedb.Copy(), header, nil
+		}).AnyTimes()
+	backend.EXPECT().GetEVM(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, msg blockchain.Message, state *state.StateDB, header *types.Header, vmCfg vm.Config) (*vm.EVM, func() error, error) {
+			state.AddBalance(msg.ValidatedSender(), new(big.Int).Mul(new(big.Int).SetUint64(msg.Gas()), msg.GasPrice()))
+			context := blockchain.NewEVMContext(msg, header, nil, &common.Address{})
+			return vm.NewEVM(context, state, params.TestChainConfig, &vmCfg), func() error { return nil }, nil
+		}).AnyTimes()
+
+	// The contract not deployed yet costs nothing to call
+	cost, err := api.EstimateComputationCost(context.Background(), CallArgs{From: from, To: &contract}, latest, nil)
+	assert.NoError(t, err)
+	assert.Zero(t, cost)
+	cost, err = api.EstimateComputationCost(context.Background(), CallArgs{From: from, To: &contract}, latest, &StateOverride{
+		contract: {Code: &code, StateDiff: &slot0},
+	})
+	assert.NoError(t, err)
+	assert.NotZero(t, cost)
+
+	// The storage slots of the overridden contract are accessed
+	result, err := api.CreateAccessList(context.Background(), CallArgs{From: from, To: &contract}, nil, &StateOverride{
+		contract: {Code: &code, StateDiff: &slot0},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Error)
+	assert.NotZero(t, result.GasUsed)
+	assert.Equal(t, &types.AccessList{{Address: contract, StorageKeys: []common.Hash{{}, common.BytesToHash([]byte{1})}}}, result.Accesslist)
+
+	// The access list is returned with the error if the execution is reverted
+	result, err = api.CreateAccessList(context.Background(), CallArgs{From: from, To: &contract}, &latest, &StateOverride{
+		contract: {Code: &code},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "evm: execution reverted: nope", result.Error)
+	assert.Equal(t, &types.AccessList{{Address: contract, StorageKeys: []common.Hash{{}}}}, result.Accesslist)
+}
+
+// counterCode is the runtime code which increments the storage slot 0, and logs and returns the new value.
+var counterCode = common.FromHex(
+	"600054" + "600101" + "80600055" + // SSTORE(0, SLOAD(0)+1)
+		"600052" + "60206000a0" + // MSTORE(0, value), LOG0(0, 32)
+		"60206000f3") // RETURN(0, 32)
+
+// TestSimulateV1 tests if the calls of a bundle are executed on the state left by the previous calls,
+// and a failed call does not stop the bundle.
+func TestSimulateV1(t *testing.T) {
+	var (
+		mockCtrl  = gomock.NewController(t)
+		backend   = mock_api.NewMockBackend(mockCtrl)
+		api       = NewPublicBlockChainAPI(backend)
+		header    = &types.Header{Number: big.NewInt(1), Time: big.NewInt(1), BlockScore: big.NewInt(1)}
+		from      = common.HexToAddress("0x1000")
+		reverting = common.HexToAddress("0x2000")
+		counter   = common.HexToAddress("0x3000")
+		code      = hexutil.Bytes(counterCode)
+	)
+	defer mockCtrl.Finish()
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
+	assert.NoError(t, err)
+	statedb.SetCode(reverting, revertingCode)
+
+	backend.EXPECT().ChainConfig().Return(params.TestChainConfig).AnyTimes()
+	backend.EXPECT().RPCGasCap().Return(nil).AnyTimes()
+	backend.EXPECT().StateAndHeaderByNumberOrHash(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+			return statedb.Copy(), header, nil
+		}).AnyTimes()
+	backend.EXPECT().GetEVM(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, msg blockchain.Message, state *state.StateDB, header *types.Header, vmCfg vm.Config) (*vm.EVM, func() error, error) {
+			state.AddBalance(msg.ValidatedSender(), new(big.Int).Mul(new(big.Int).SetUint64(msg.Gas()), msg.GasPrice()))
+			context := blockchain.NewEVMContext(msg, header, nil, &common.Address{})
+			return vm.NewEVM(context, state, params.TestChainConfig, &vmCfg), func() error { return nil }, nil
+		}).AnyTimes()
+
+	results, err := api.SimulateV1(context.Background(), SimulateOpts{
+		Calls: []CallArgs{
+			{From: from, To: &counter},
+			{From: from, To: &counter},
+			{From: from, To: &reverting},
+			{From: from, To: &counter},
+		},
+		StateOverrides: &StateOverride{counter: {Code: &code}},
+	}, nil)
+	assert.NoError(t, err)
+	if !assert.Len(t, results, 4) {
+		return
+	}
+	for i, value := range map[int]byte{0: 1, 1: 2, 3: 3} {
+		result := results[i]
+		assert.Equal(t, hexutil.Bytes(common.BytesToHash([]byte{value}).Bytes()), result.ReturnData)
+		assert.Equal(t, hexutil.Uint(types.ReceiptStatusSuccessful), result.Status)
+		assert.Nil(t, result.Error)
+		assert.NotZero(t, result.GasUsed)
+		if assert.Len(t, result.Logs, 1) {
+			assert.Equal(t, counter, result.Logs[0].Address)
+			assert.Equal(t, common.BytesToHash([]byte{value}).Bytes(), result.Logs[0].Data)
+			assert.Equal(t, uint(i), result.Logs[0].TxIndex)
+		}
+	}
+	assert.Equal(t, hexutil.Uint(types.ReceiptStatusErrExecutionReverted), results[2].Status)
+	assert.Equal(t, "evm: execution reverted: nope", results[2].Error.Message)
+	assert.Empty(t, results[2].Logs)
+
+	// The state of the block is not modified by the simulation
+	results, err = api.SimulateV1(context.Background(), SimulateOpts{
+		Calls:          []CallArgs{{From: from, To: &counter}},
+		StateOverrides: &StateOverride{counter: {Code: &code}},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, hexutil.Bytes(common.BytesToHash([]byte{1}).Bytes()), results[0].ReturnData)
+
+	_, err = api.SimulateV1(context.Background(), SimulateOpts{}, nil)
+	assert.Error(t, err)
+}
@@ -50,7 +50,7 @@ func (s *PublicBlockChainAPI) callCypressCreditGetFunc(ctx context.Context, pars
 		To:   &cypressCreditContractAddress,
 		Data: abiGet,
 	}
-	ret, err := s.Call(ctx, args, rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+	ret, err := s.Call(ctx, args, rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber), nil, nil)
 	if err != nil {
 		return nil, err
 	}
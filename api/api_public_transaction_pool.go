@@ -120,7 +120,11 @@ func (s *PublicTransactionPoolAPI) GetTransactionCount(ctx context.Context, addr
 	return (*hexutil.Uint64)(&nonce), state.Error()
 }
 
-func (s *PublicTransactionPoolAPI) GetTransactionBySenderTxHash(ctx context.Context, senderTxHash common.Hash) map[string]interface{} {
+// errTxIndexingInProgress is returned if a transaction is not found while the transactions of
+// the older blocks are being indexed.
+var errTxIndexingInProgress = errors.New("transaction indexing is in progress")
+
+func (s *PublicTransactionPoolAPI) GetTransactionBySenderTxHash(ctx context.Context, senderTxHash common.Hash) (map[string]interface{}, error) {
 	txhash := s.b.ChainDB().ReadTxHashFromSenderTxHash(senderTxHash)
 	if common.EmptyHash(txhash) {
 		txhash = senderTxHash
@@ -128,18 +132,23 @@ func (s *PublicTransactionPoolAPI) GetTransactionBySenderTxHash(ctx context.Cont
 	return s.GetTransactionByHash(ctx, txhash)
 }
 
-// GetTransactionByHash returns the transaction for the given hash
-func (s *PublicTransactionPoolAPI) GetTransactionByHash(ctx context.Context, hash common.Hash) map[string]interface{} {
+// GetTransactionByHash returns the transaction for the given hash.
+// If the transaction is not found while the transaction lookup index is being built,
+// an error is returned since the transaction may be in the blocks not indexed yet.
+func (s *PublicTransactionPoolAPI) GetTransactionByHash(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
 	// Try to return an already finalized transaction
 	if tx, blockHash, blockNumber, index := s.b.ChainDB().ReadTxAndLookupInfo(hash); tx != nil {
-		return newRPCTransaction(tx, blockHash, blockNumber, index)
+		return newRPCTransaction(tx, blockHash, blockNumber, index), nil
 	}
 	// No finalized transaction, try to retrieve it from the pool
 	if tx := s.b.GetPoolTransaction(hash); tx != nil {
-		return newRPCPendingTransaction(tx)
+		return newRPCPendingTransaction(tx), nil
 	}
 	// Transaction unknown, return as such
-	return nil
+	if s.b.TxIndexStatus().Building() {
+		return nil, errTxIndexingInProgress
+	}
+	return nil, nil
 }
 
 // GetDecodedAnchoringTransactionByHash returns the decoded anchoring data of anchoring transaction for the given hash
@@ -247,8 +256,22 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceiptBySenderTxHash(ctx conte
 }
 
 // GetTransactionReceipt returns the transaction receipt for the given transaction hash.
+// If ReceiptRevertReason is enabled, the receipt of a reverted transaction has its revert reason.
 func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
-	return RpcOutputReceipt(s.b.GetTxLookupInfoAndReceipt(ctx, hash)), nil
+	tx, blockHash, blockNumber, index, receipt := s.b.GetTxLookupInfoAndReceipt(ctx, hash)
+	if tx == nil && s.b.TxIndexStatus().Building() {
+		return nil, errTxIndexingInProgress
+	}
+	fields := RpcOutputReceipt(tx, blockHash, blockNumber, index, receipt)
+	if ReceiptRevertReason && fields != nil && receipt.Status == types.ReceiptStatusErrExecutionReverted {
+		reason, err := replayRevertReason(ctx, s.b, blockHash, index)
+		if err != nil {
+			logger.Debug("Failed to replay the reverted transaction", "hash", hash, "err", err)
+		} else if reason != nil {
+			fields["revertReason"] = reason
+		}
+	}
+	return fields, nil
 }
 
 // GetTransactionReceiptInCache returns the transaction receipt for the given transaction hash.
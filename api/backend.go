@@ -48,7 +48,8 @@ type Backend interface {
 	ChainDB() database.DBManager
 	EventMux() *event.TypeMux
 	AccountManager() accounts.AccountManager
-	RPCGasCap() *big.Int // global gas cap for klay_call over rpc: DoS protection
+	RPCGasCap() *big.Int              // global gas cap for klay_call over rpc: DoS protection
+	RPCEstimateGasTolerance() float64 // tolerated ratio of the overestimated gas in klay_estimateGas
 
 	// BlockChain API
 	SetHead(number uint64)
@@ -71,6 +72,7 @@ type Backend interface {
 	IsParallelDBWrite() bool
 
 	IsSenderTxHashIndexingEnabled() bool
+	TxIndexStatus() blockchain.TxIndexStatus
 
 	// TxPool API
 	SendTx(ctx context.Context, signedTx *types.Transaction) error
@@ -79,6 +81,7 @@ type Backend interface {
 	GetPoolNonce(ctx context.Context, addr common.Address) uint64
 	Stats() (pending int, queued int)
 	TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
+	TxPoolContentFrom(addr common.Address) (types.Transactions, types.Transactions)
 	SubscribeNewTxsEvent(chan<- blockchain.NewTxsEvent) event.Subscription
 
 	ChainConfig() *params.ChainConfig
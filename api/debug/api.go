@@ -62,6 +62,8 @@ type HandlerT struct {
 
 	logDir    string   // log directory path
 	vmLogFile *os.File // a file descriptor of the vmlog output file
+
+	vmLogs vmLogRouter // routes of the vmlog of contracts
 }
 
 // Verbosity sets the log verbosity ceiling. The verbosity of individual packages
@@ -34,5 +34,6 @@ Source Files
   - loudpanic_fallback.go : (deprecated) implements fallback of LoudPanic.
   - trace.go              : implements start/stop functions of go trace.
   - trace_fallback.go     : implements fallback of StartGoTrace and StopGoTrace for Go < 1.5.
+  - vmlog.go              : implements routing the vmlog output per contract to files and subscriptions.
 */
 package debug
@@ -174,6 +174,7 @@ func Exit() {
 		Handler.vmLogFile.Close()
 		Handler.vmLogFile = nil
 	}
+	Handler.closeContractVMLogFiles()
 	if Handler.memFile != "" {
 		Handler.WriteMemProfile(Handler.memFile)
 	}
@@ -0,0 +1,245 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package debug
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/params"
+)
+
+// vmLogSubscriberBuffer is the number of the vmlog messages buffered for a subscriber.
+// The messages are dropped if the subscriber cannot keep up with them.
+const vmLogSubscriberBuffer = 256
+
+// VMLog is a message written by a contract via the vmlog precompiled contract.
+type VMLog struct {
+	TxHash common.Hash    `json:"txHash"`
+	Caller common.Address `json:"caller"`
+	Msg    string         `json:"msg"`
+}
+
+// vmLogRoute is the output target of the vmlog of a contract, overriding the global target.
+type vmLogRoute struct {
+	target int
+	file   *os.File // DATADIR/logs/vmlog/<address>.log, nil if the target does not include file
+}
+
+// vmLogSubscriber receives the vmlog messages of the given contracts, or of all contracts if addrs is empty.
+type vmLogSubscriber struct {
+	addrs map[common.Address]struct{}
+	ch    chan VMLog
+}
+
+// vmLogRouter routes the vmlog messages to the contract specific targets and to the subscribers.
+type vmLogRouter struct {
+	mu          sync.RWMutex
+	routes      map[common.Address]*vmLogRoute
+	subscribers map[*vmLogSubscriber]struct{}
+}
+
+// VMLogTargetOf returns the output target of the vmlog of the given contract.
+// If no target is set for the contract, the global target is returned.
+func (h *HandlerT) VMLogTargetOf(addr common.Address) int {
+	h.vmLogs.mu.RLock()
+	defer h.vmLogs.mu.RUnlock()
+
+	if route, ok := h.vmLogs.routes[addr]; ok {
+		return route.target
+	}
+	return params.VMLogTarget
+}
+
+// WriteContractVMLog writes msg to the vmlog output file of the given contract.
+// If no target is set for the contract, msg is written to the global vmlog output file.
+func (h *HandlerT) WriteContractVMLog(addr common.Address, msg string) {
+	h.vmLogs.mu.RLock()
+	route, ok := h.vmLogs.routes[addr]
+	if !ok {
+		h.vmLogs.mu.RUnlock()
+		h.WriteVMLog(msg)
+		return
+	}
+	defer h.vmLogs.mu.RUnlock()
+
+	if route.file != nil {
+		if _, err := route.file.WriteString(msg + "\n"); err != nil {
+			// Since vmlog is a debugging feature, write failure can be treated as a warning.
+			logger.Warn("Failed to write to a vmlog file", "contract", addr, "msg", msg, "err", err)
+		}
+	}
+}
+
+// SendVMLog sends a vmlog message to the subscribers watching the caller.
+func (h *HandlerT) SendVMLog(txHash common.Hash, caller common.Address, msg string) {
+	h.vmLogs.mu.RLock()
+	defer h.vmLogs.mu.RUnlock()
+
+	for sub := range h.vmLogs.subscribers {
+		if _, ok := sub.addrs[caller]; len(sub.addrs) > 0 && !ok {
+			continue
+		}
+		select {
+		case sub.ch <- VMLog{TxHash: txHash, Caller: caller, Msg: msg}:
+		default:
+			logger.Debug("Dropped a vmlog message for a slow subscriber", "tx", txHash, "caller", caller)
+		}
+	}
+}
+
+// EnableContractVMLog sets the output target of the vmlog of the given contract, which overrides
+// the global target. The file output of the contract is DATADIR/logs/vmlog/<address>.log.
+func (h *HandlerT) EnableContractVMLog(addr common.Address, target int) (string, error) {
+	if target < 0 || target > params.VMLogToAll {
+		return "", fmt.Errorf("target should be between 0 and %d", params.VMLogToAll)
+	}
+
+	h.vmLogs.mu.Lock()
+	defer h.vmLogs.mu.Unlock()
+
+	route, ok := h.vmLogs.routes[addr]
+	if !ok {
+		route = &vmLogRoute{}
+	}
+	if (target&params.VMLogToFile) != 0 && route.file == nil {
+		dir := filepath.Join(h.logDir, "vmlog")
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return "", err
+		}
+		file, err := os.OpenFile(filepath.Join(dir, addr.Hex()+".log"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return "", err
+		}
+		route.file = file
+	} else if (target&params.VMLogToFile) == 0 && route.file != nil {
+		closeVMLogFile(addr, route.file)
+		route.file = nil
+	}
+	route.target = target
+
+	if h.vmLogs.routes == nil {
+		h.vmLogs.routes = make(map[common.Address]*vmLogRoute)
+	}
+	h.vmLogs.routes[addr] = route
+	return vmLogTargetToString(target), nil
+}
+
+// DisableContractVMLog removes the output target of the vmlog of the given contract,
+// so the vmlog of the contract follows the global target again.
+func (h *HandlerT) DisableContractVMLog(addr common.Address) error {
+	h.vmLogs.mu.Lock()
+	defer h.vmLogs.mu.Unlock()
+
+	route, ok := h.vmLogs.routes[addr]
+	if !ok {
+		return fmt.Errorf("vmlog target is not set for the contract %s", addr.Hex())
+	}
+	if route.file != nil {
+		closeVMLogFile(addr, route.file)
+	}
+	delete(h.vmLogs.routes, addr)
+	return nil
+}
+
+// ContractVMLogTargets returns the output targets of the vmlog set for contracts.
+func (h *HandlerT) ContractVMLogTargets() map[common.Address]string {
+	h.vmLogs.mu.RLock()
+	defer h.vmLogs.mu.RUnlock()
+
+	targets := make(map[common.Address]string, len(h.vmLogs.routes))
+	for addr, route := range h.vmLogs.routes {
+		targets[addr] = vmLogTargetToString(route.target)
+	}
+	return targets
+}
+
+// ContractVMLogs creates a subscription that fires for the vmlog messages of the given contracts,
+// or of all contracts if no contract is given. The messages are sent regardless of the output targets.
+func (h *HandlerT) ContractVMLogs(ctx context.Context, addrs []common.Address) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	sub := h.subscribeVMLogs(addrs)
+
+	go func() {
+		defer h.unsubscribeVMLogs(sub)
+		for {
+			select {
+			case vmLog := <-sub.ch:
+				notifier.Notify(rpcSub.ID, vmLog)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+func (h *HandlerT) subscribeVMLogs(addrs []common.Address) *vmLogSubscriber {
+	sub := &vmLogSubscriber{
+		addrs: make(map[common.Address]struct{}, len(addrs)),
+		ch:    make(chan VMLog, vmLogSubscriberBuffer),
+	}
+	for _, addr := range addrs {
+		sub.addrs[addr] = struct{}{}
+	}
+
+	h.vmLogs.mu.Lock()
+	defer h.vmLogs.mu.Unlock()
+	if h.vmLogs.subscribers == nil {
+		h.vmLogs.subscribers = make(map[*vmLogSubscriber]struct{})
+	}
+	h.vmLogs.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (h *HandlerT) unsubscribeVMLogs(sub *vmLogSubscriber) {
+	h.vmLogs.mu.Lock()
+	defer h.vmLogs.mu.Unlock()
+	delete(h.vmLogs.subscribers, sub)
+}
+
+// closeContractVMLogFiles closes the vmlog output files of all contracts.
+func (h *HandlerT) closeContractVMLogFiles() {
+	h.vmLogs.mu.Lock()
+	defer h.vmLogs.mu.Unlock()
+
+	for addr, route := range h.vmLogs.routes {
+		if route.file != nil {
+			closeVMLogFile(addr, route.file)
+			route.file = nil
+		}
+	}
+}
+
+func closeVMLogFile(addr common.Address, file *os.File) {
+	if err := file.Close(); err != nil {
+		logger.Warn("Failed to close the vmlog file", "contract", addr, "err", err)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package debug
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_ContractVMLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "klaytn-vmlog-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		h      = &HandlerT{logDir: dir}
+		routed = common.Address{0x01}
+		other  = common.Address{0x02}
+	)
+	defer h.closeContractVMLogFiles()
+
+	// Without a route, the global target is used
+	assert.Equal(t, params.VMLogTarget, h.VMLogTargetOf(routed))
+
+	_, err = h.EnableContractVMLog(routed, params.VMLogToAll+1)
+	assert.Error(t, err)
+
+	target, err := h.EnableContractVMLog(routed, params.VMLogToFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "file", target)
+	assert.Equal(t, params.VMLogToFile, h.VMLogTargetOf(routed))
+	assert.Equal(t, map[common.Address]string{routed: "file"}, h.ContractVMLogTargets())
+
+	h.WriteContractVMLog(routed, "hello")
+	data, err := ioutil.ReadFile(filepath.Join(dir, "vmlog", routed.Hex()+".log"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello\n", string(data))
+
+	// Subscribers receive the messages of the watched contracts only
+	sub := h.subscribeVMLogs([]common.Address{routed})
+	all := h.subscribeVMLogs(nil)
+	h.SendVMLog(common.Hash{0x10}, routed, "to both")
+	h.SendVMLog(common.Hash{0x11}, other, "to all only")
+	assert.Equal(t, VMLog{TxHash: common.Hash{0x10}, Caller: routed, Msg: "to both"}, <-sub.ch)
+	assert.Equal(t, VMLog{TxHash: common.Hash{0x10}, Caller: routed, Msg: "to both"}, <-all.ch)
+	assert.Equal(t, VMLog{TxHash: common.Hash{0x11}, Caller: other, Msg: "to all only"}, <-all.ch)
+	assert.Len(t, sub.ch, 0)
+
+	// Messages are dropped instead of blocking if a subscriber is slow
+	for i := 0; i < vmLogSubscriberBuffer+1; i++ {
+		h.SendVMLog(common.Hash{}, routed, "flood")
+	}
+	assert.Len(t, sub.ch, vmLogSubscriberBuffer)
+
+	h.unsubscribeVMLogs(sub)
+	h.unsubscribeVMLogs(all)
+	assert.Len(t, h.vmLogs.subscribers, 0)
+
+	assert.NoError(t, h.DisableContractVMLog(routed))
+	assert.Error(t, h.DisableContractVMLog(routed))
+	assert.Equal(t, params.VMLogTarget, h.VMLogTargetOf(routed))
+	assert.Len(t, h.ContractVMLogTargets(), 0)
+}
@@ -146,7 +146,7 @@ func (mr *MockBackendMockRecorder) EventMux() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EventMux", reflect.TypeOf((*MockBackend)(nil).EventMux))
 }
 
-// GetBlock mocks base method
+// BlockByHash mocks base method
 func (m *MockBackend) BlockByHash(arg0 context.Context, arg1 common.Hash) (*types.Block, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "BlockByHash", arg0, arg1)
@@ -155,8 +155,8 @@ func (m *MockBackend) BlockByHash(arg0 context.Context, arg1 common.Hash) (*type
 	return ret0, ret1
 }
 
-// GetBlock indicates an expected call of GetBlock
-func (mr *MockBackendMockRecorder) GetBlock(arg0, arg1 interface{}) *gomock.Call {
+// BlockByHash indicates an expected call of BlockByHash
+func (mr *MockBackendMockRecorder) BlockByHash(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockByHash", reflect.TypeOf((*MockBackend)(nil).BlockByHash), arg0, arg1)
 }
@@ -433,6 +433,20 @@ func (mr *MockBackendMockRecorder) ProtocolVersion() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProtocolVersion", reflect.TypeOf((*MockBackend)(nil).ProtocolVersion))
 }
 
+// RPCEstimateGasTolerance mocks base method
+func (m *MockBackend) RPCEstimateGasTolerance() float64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RPCEstimateGasTolerance")
+	ret0, _ := ret[0].(float64)
+	return ret0
+}
+
+// RPCEstimateGasTolerance indicates an expected call of RPCEstimateGasTolerance
+func (mr *MockBackendMockRecorder) RPCEstimateGasTolerance() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RPCEstimateGasTolerance", reflect.TypeOf((*MockBackend)(nil).RPCEstimateGasTolerance))
+}
+
 // RPCGasCap mocks base method
 func (m *MockBackend) RPCGasCap() *big.Int {
 	m.ctrl.T.Helper()
@@ -492,7 +506,7 @@ func (mr *MockBackendMockRecorder) StateAndHeaderByNumber(arg0, arg1 interface{}
 // StateAndHeaderByNumberOrHash mocks base method
 func (m *MockBackend) StateAndHeaderByNumberOrHash(arg0 context.Context, arg1 rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "StateAndHeaderByNumber", arg0, arg1)
+	ret := m.ctrl.Call(m, "StateAndHeaderByNumberOrHash", arg0, arg1)
 	ret0, _ := ret[0].(*state.StateDB)
 	ret1, _ := ret[1].(*types.Header)
 	ret2, _ := ret[2].(error)
@@ -591,6 +605,20 @@ func (mr *MockBackendMockRecorder) SuggestPrice(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuggestPrice", reflect.TypeOf((*MockBackend)(nil).SuggestPrice), arg0)
 }
 
+// TxIndexStatus mocks base method
+func (m *MockBackend) TxIndexStatus() blockchain.TxIndexStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxIndexStatus")
+	ret0, _ := ret[0].(blockchain.TxIndexStatus)
+	return ret0
+}
+
+// TxIndexStatus indicates an expected call of TxIndexStatus
+func (mr *MockBackendMockRecorder) TxIndexStatus() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxIndexStatus", reflect.TypeOf((*MockBackend)(nil).TxIndexStatus))
+}
+
 // TxPoolContent mocks base method
 func (m *MockBackend) TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
 	m.ctrl.T.Helper()
@@ -605,3 +633,18 @@ func (mr *MockBackendMockRecorder) TxPoolContent() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxPoolContent", reflect.TypeOf((*MockBackend)(nil).TxPoolContent))
 }
+
+// TxPoolContentFrom mocks base method
+func (m *MockBackend) TxPoolContentFrom(arg0 common.Address) (types.Transactions, types.Transactions) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxPoolContentFrom", arg0)
+	ret0, _ := ret[0].(types.Transactions)
+	ret1, _ := ret[1].(types.Transactions)
+	return ret0, ret1
+}
+
+// TxPoolContentFrom indicates an expected call of TxPoolContentFrom
+func (mr *MockBackendMockRecorder) TxPoolContentFrom(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxPoolContentFrom", reflect.TypeOf((*MockBackend)(nil).TxPoolContentFrom), arg0)
+}
@@ -0,0 +1,231 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/klaytn/klaytn/accounts/abi"
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/networks/rpc"
+)
+
+// defaultTxErrorCode is the JSON-RPC error code of the failed executions not in TxErrorCodes.
+const defaultTxErrorCode = -32000
+
+var (
+	// TxErrorCodes maps the txError of a failed execution to the JSON-RPC error code returned by
+	// klay_call and klay_estimateGas. The code of a reverted execution is the same as Ethereum.
+	TxErrorCodes = map[uint]int{
+		types.ReceiptStatusErrExecutionReverted: 3,
+	}
+
+	// ReceiptRevertReason enables the revert reasons of the reverted transactions in the receipts.
+	// Since the revert data are not stored, the transactions of the block are re-executed up to
+	// the reverted one on the state of the parent block.
+	ReceiptRevertReason = false
+)
+
+// ParseTxErrorCodes parses a comma separated list of txError:code pairs, e.g. "0x09:3,0x07:-32010".
+func ParseTxErrorCodes(s string) (map[uint]int, error) {
+	codes := make(map[uint]int)
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		kv := strings.Split(pair, ":")
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid txError code pair %q", pair)
+		}
+		status, err := strconv.ParseUint(strings.TrimSpace(kv[0]), 0, 32)
+		if err != nil || uint(status) == types.ReceiptStatusSuccessful || uint(status) >= types.ReceiptStatusLast {
+			return nil, fmt.Errorf("invalid txError %q", kv[0])
+		}
+		code, err := strconv.Atoi(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid error code %q", kv[1])
+		}
+		codes[uint(status)] = code
+	}
+	return codes, nil
+}
+
+// Kinds of the revert reasons.
+const (
+	RevertKindError  = "Error"  // revert or require with a reason string
+	RevertKindPanic  = "Panic"  // failed assertion or runtime error of solidity 0.8.0 or later
+	RevertKindCustom = "Custom" // custom error, which cannot be decoded without the abi of the contract
+)
+
+// panicReasons describes the panic codes of solidity.
+var panicReasons = map[uint64]string{
+	0x00: "generic panic",
+	0x01: "assert(false)",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "enum overflow",
+	0x22: "invalid encoded storage byte array accessed",
+	0x31: "out-of-bounds array access; popping on an empty array",
+	0x32: "out-of-bounds access of an array or bytesN",
+	0x41: "out of memory",
+	0x51: "uninitialized function",
+}
+
+// RevertReason is the decoded revert data of a reverted execution.
+type RevertReason struct {
+	Kind     string        `json:"kind"`
+	Message  string        `json:"message,omitempty"`  // reason string of Error or description of the panic code
+	Code     *hexutil.Big  `json:"code,omitempty"`     // panic code
+	Selector hexutil.Bytes `json:"selector,omitempty"` // selector of the custom error
+	Data     hexutil.Bytes `json:"data"`               // raw revert data
+}
+
+// UnpackRevertReason decodes the revert data of Error(string), Panic(uint256) or a custom error.
+// It returns nil if there is no revert data.
+func UnpackRevertReason(data []byte) *RevertReason {
+	if len(data) == 0 {
+		return nil
+	}
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return &RevertReason{Kind: RevertKindError, Message: reason, Data: data}
+	}
+	if code, err := abi.UnpackPanic(data); err == nil {
+		message, ok := panicReasons[code.Uint64()]
+		if !ok || !code.IsUint64() {
+			message = "unknown panic code"
+		}
+		return &RevertReason{Kind: RevertKindPanic, Message: message, Code: (*hexutil.Big)(code), Data: data}
+	}
+	reason := &RevertReason{Kind: RevertKindCustom, Data: data}
+	if len(data) >= 4 {
+		reason.Selector = data[:4]
+	}
+	return reason
+}
+
+// TxError is the error of an execution failed with a txError. It is returned as a JSON-RPC error
+// whose code is mapped by TxErrorCodes and whose data has the txError and the revert reason.
+type TxError struct {
+	err    error
+	status uint
+	revert *RevertReason
+}
+
+// txErrorData is the data of the JSON-RPC error of a TxError.
+type txErrorData struct {
+	TxError hexutil.Uint  `json:"txError"`
+	Revert  *RevertReason `json:"revert,omitempty"`
+}
+
+// newTxError returns the error of the execution failed with the given status and return data.
+func newTxError(status uint, ret []byte) *TxError {
+	e := &TxError{err: blockchain.GetVMerrFromReceiptStatus(status), status: status}
+	if status == types.ReceiptStatusErrExecutionReverted {
+		e.revert = UnpackRevertReason(common.CopyBytes(ret))
+	}
+	return e
+}
+
+func (e *TxError) Error() string {
+	if e.revert != nil && e.revert.Message != "" {
+		return fmt.Sprintf("%v: %v", e.err, e.revert.Message)
+	}
+	return e.err.Error()
+}
+
+// Unwrap returns the VM error of the txError, so it can be compared with errors.Is.
+func (e *TxError) Unwrap() error {
+	return e.err
+}
+
+// ErrorCode returns the JSON-RPC error code mapped to the txError.
+func (e *TxError) ErrorCode() int {
+	if code, ok := TxErrorCodes[e.status]; ok {
+		return code
+	}
+	return defaultTxErrorCode
+}
+
+// ErrorData returns the txError and the revert reason.
+func (e *TxError) ErrorData() interface{} {
+	return &txErrorData{TxError: hexutil.Uint(e.status), Revert: e.revert}
+}
+
+// Status returns the txError of the failed execution.
+func (e *TxError) Status() uint {
+	return e.status
+}
+
+// Revert returns the revert reason, or nil if the execution is not reverted or has no revert data.
+func (e *TxError) Revert() *RevertReason {
+	return e.revert
+}
+
+// replayRevertReason re-executes the transactions of the block up to the one at the given index,
+// and returns the revert reason of the transaction.
+func replayRevertReason(ctx context.Context, b Backend, blockHash common.Hash, index uint64) (*RevertReason, error) {
+	block, err := b.BlockByHash(ctx, blockHash)
+	if block == nil || err != nil {
+		return nil, fmt.Errorf("block %#x not found", blockHash)
+	}
+	if index >= uint64(len(block.Transactions())) {
+		return nil, fmt.Errorf("transaction index %d out of range for block %#x", index, blockHash)
+	}
+	statedb, _, err := b.StateAndHeaderByNumberOrHash(ctx, rpc.NewBlockNumberOrHashWithHash(block.ParentHash(), false))
+	if statedb == nil || err != nil {
+		return nil, fmt.Errorf("state of the parent block %#x is not available: %v", block.ParentHash(), err)
+	}
+
+	signer := types.MakeSigner(b.ChainConfig(), block.Number())
+	for i, tx := range block.Transactions()[:index+1] {
+		// The messages of all transaction types, including the fee delegated ones, are validated with the account keys
+		msg, err := tx.AsMessageWithAccountKeyPicker(signer, statedb, block.NumberU64())
+		if err != nil {
+			return nil, fmt.Errorf("transaction %#x failed: %v", tx.Hash(), err)
+		}
+		evm, vmError, err := b.GetEVM(ctx, msg, statedb, block.Header(), vm.Config{})
+		if err != nil {
+			return nil, err
+		}
+		ret, _, kerr := blockchain.ApplyMessage(evm, msg)
+		if err := vmError(); err != nil {
+			return nil, err
+		}
+		if kerr.ErrTxInvalid != nil {
+			return nil, fmt.Errorf("transaction %#x failed: %v", tx.Hash(), kerr.ErrTxInvalid)
+		}
+		if uint64(i) == index {
+			if kerr.Status != types.ReceiptStatusErrExecutionReverted {
+				return nil, fmt.Errorf("transaction %#x is not reverted in the re-execution", tx.Hash())
+			}
+			return UnpackRevertReason(ret), nil
+		}
+		// GetEVM credits the sender with the gas fee for the calls, which is taken back not to
+		// affect the following transactions
+		statedb.SubBalance(msg.ValidatedSender(), new(big.Int).Mul(new(big.Int).SetUint64(msg.Gas()), msg.GasPrice()))
+		statedb.Finalise(true, true)
+	}
+	return nil, nil
+}
@@ -0,0 +1,161 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mock_api "github.com/klaytn/klaytn/api/mocks"
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/fork"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnpackRevertReason(t *testing.T) {
+	var (
+		errorData  = common.FromHex("0x08c379a000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000004" + "6e6f706500000000000000000000000000000000000000000000000000000000")
+		panicData  = common.FromHex("0x4e487b710000000000000000000000000000000000000000000000000000000000000011")
+		customData = common.FromHex("0xcafebabe000000000000000000000000000000000000000000000000000000000000002a")
+	)
+
+	assert.Nil(t, UnpackRevertReason(nil))
+	assert.Equal(t, &RevertReason{Kind: RevertKindError, Message: "nope", Data: errorData}, UnpackRevertReason(errorData))
+	assert.Equal(t, &RevertReason{Kind: RevertKindPanic, Message: "arithmetic underflow or overflow", Code: (*hexutil.Big)(big.NewInt(0x11)), Data: panicData}, UnpackRevertReason(panicData))
+	assert.Equal(t, &RevertReason{Kind: RevertKindCustom, Selector: customData[:4], Data: customData}, UnpackRevertReason(customData))
+	assert.Equal(t, &RevertReason{Kind: RevertKindCustom, Data: []byte{0x01}}, UnpackRevertReason([]byte{0x01}))
+
+	// The unknown panic code is decoded as well
+	panicData = common.FromHex("0x4e487b7100000000000000000000000000000000000000000000000000000000000000ff")
+	assert.Equal(t, "unknown panic code", UnpackRevertReason(panicData).Message)
+}
+
+func TestTxError(t *testing.T) {
+	reverted := newTxError(types.ReceiptStatusErrExecutionReverted, common.FromHex("0x4e487b710000000000000000000000000000000000000000000000000000000000000001"))
+	assert.True(t, errors.Is(reverted, vm.ErrExecutionReverted))
+	assert.Equal(t, "evm: execution reverted: assert(false)", reverted.Error())
+	assert.Equal(t, 3, reverted.ErrorCode())
+	assert.Equal(t, &txErrorData{TxError: 0x09, Revert: reverted.Revert()}, reverted.ErrorData())
+
+	outOfGas := newTxError(types.ReceiptStatusErrOutOfGas, nil)
+	assert.Equal(t, "out of gas", outOfGas.Error())
+	assert.Equal(t, defaultTxErrorCode, outOfGas.ErrorCode())
+	assert.Equal(t, &txErrorData{TxError: 0x07}, outOfGas.ErrorData())
+
+	// The error codes can be overridden
+	codes, err := ParseTxErrorCodes("0x07:-32010, 9:-32011")
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint]int{types.ReceiptStatusErrOutOfGas: -32010, types.ReceiptStatusErrExecutionReverted: -32011}, codes)
+
+	defer func(original map[uint]int) { TxErrorCodes = original }(TxErrorCodes)
+	TxErrorCodes = codes
+	assert.Equal(t, -32010, outOfGas.ErrorCode())
+	assert.Equal(t, -32011, reverted.ErrorCode())
+
+	for _, invalid := range []string{"0x07", "0x07:a", "0x01:3", "0x100:3", "a:3"} {
+		_, err := ParseTxErrorCodes(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+// TestReceiptRevertReason tests if the revert reason of a reverted fee delegated transaction is
+// added to the receipt by re-executing the transactions of the block.
+func TestReceiptRevertReason(t *testing.T) {
+	var (
+		mockCtrl    = gomock.NewController(t)
+		backend     = mock_api.NewMockBackend(mockCtrl)
+		api         = NewPublicTransactionPoolAPI(backend, new(AddrLocker))
+		senderKey   = senderPrvKey
+		sender      = crypto.PubkeyToAddress(senderKey.PublicKey)
+		feePayerKey = feePayerPrvKey
+		feePayer    = crypto.PubkeyToAddress(feePayerKey.PublicKey)
+		contract    = common.HexToAddress("0x2000")
+		signer      = types.MakeSigner(params.TestChainConfig, big.NewInt(1))
+	)
+	defer mockCtrl.Finish()
+
+	fork.SetHardForkBlockNumberConfig(params.TestChainConfig)
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
+	assert.NoError(t, err)
+	statedb.AddBalance(sender, big.NewInt(params.KLAY))
+	statedb.AddBalance(feePayer, big.NewInt(params.KLAY))
+	statedb.SetCode(contract, revertingCode)
+
+	// The reverted transaction can be executed only after the preceding one because of its nonce
+	transfer := types.NewTransaction(0, common.HexToAddress("0x3000"), big.NewInt(1), params.TxGas, big.NewInt(0), nil)
+	assert.NoError(t, transfer.Sign(signer, senderKey))
+	execution, err := types.NewTransactionWithMap(types.TxTypeFeeDelegatedSmartContractExecution, map[types.TxValueKeyType]interface{}{
+		types.TxValueKeyNonce:    uint64(1),
+		types.TxValueKeyFeePayer: feePayer,
+		types.TxValueKeyGasPrice: big.NewInt(0),
+		types.TxValueKeyGasLimit: uint64(100000),
+		types.TxValueKeyFrom:     sender,
+		types.TxValueKeyAmount:   big.NewInt(0),
+		types.TxValueKeyTo:       contract,
+		types.TxValueKeyData:     []byte{},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, execution.Sign(signer, senderKey))
+	assert.NoError(t, execution.SignFeePayer(signer, feePayerKey))
+
+	header := &types.Header{Number: big.NewInt(1), Time: big.NewInt(1), BlockScore: big.NewInt(1)}
+	block := types.NewBlockWithHeader(header).WithBody(types.Transactions{transfer, execution})
+	receipt := &types.Receipt{Status: types.ReceiptStatusErrExecutionReverted, TxHash: execution.Hash()}
+
+	backend.EXPECT().ChainConfig().Return(params.TestChainConfig).AnyTimes()
+	backend.EXPECT().GetTxLookupInfoAndReceipt(gomock.Any(), execution.Hash()).Return(execution, block.Hash(), uint64(1), uint64(1), receipt).AnyTimes()
+	backend.EXPECT().BlockByHash(gomock.Any(), block.Hash()).Return(block, nil).AnyTimes()
+	backend.EXPECT().StateAndHeaderByNumberOrHash(gomock.Any(), rpc.NewBlockNumberOrHashWithHash(block.ParentHash(), false)).DoAndReturn(
+		func(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+			return statedb.Copy(), &types.Header{Number: big.NewInt(0)}, nil
+		}).AnyTimes()
+	backend.EXPECT().GetEVM(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, msg blockchain.Message, state *state.StateDB, header *types.Header, vmCfg vm.Config) (*vm.EVM, func() error, error) {
+			state.AddBalance(msg.ValidatedSender(), new(big.Int).Mul(new(big.Int).SetUint64(msg.Gas()), msg.GasPrice()))
+			context := blockchain.NewEVMContext(msg, header, nil, &common.Address{})
+			return vm.NewEVM(context, state, params.TestChainConfig, &vmCfg), func() error { return nil }, nil
+		}).AnyTimes()
+
+	// The revert reason is added only if it is enabled
+	fields, err := api.GetTransactionReceipt(context.Background(), execution.Hash())
+	assert.NoError(t, err)
+	assert.NotContains(t, fields, "revertReason")
+
+	defer func() { ReceiptRevertReason = false }()
+	ReceiptRevertReason = true
+	fields, err = api.GetTransactionReceipt(context.Background(), execution.Hash())
+	assert.NoError(t, err)
+	assert.Equal(t, hexutil.Uint(types.ReceiptStatusErrExecutionReverted), fields["txError"])
+	if assert.Contains(t, fields, "revertReason") {
+		reason := fields["revertReason"].(*RevertReason)
+		assert.Equal(t, RevertKindError, reason.Kind)
+		assert.Equal(t, "nope", reason.Message)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/rcrowley/go-metrics"
+)
+
+// blockImportStatsWindow is the number of the recently imported blocks whose import stats are kept.
+const blockImportStatsWindow = 1024
+
+var (
+	// The stage timings of the block imports in nanoseconds
+	blockImportSendersHistogram    = metrics.NewRegisteredHistogram("chain/import/senders", nil, metrics.NewExpDecaySample(1028, 0.015))
+	blockImportExecutionHistogram  = metrics.NewRegisteredHistogram("chain/import/execution", nil, metrics.NewExpDecaySample(1028, 0.015))
+	blockImportStateHistogram      = metrics.NewRegisteredHistogram("chain/import/state", nil, metrics.NewExpDecaySample(1028, 0.015))
+	blockImportValidationHistogram = metrics.NewRegisteredHistogram("chain/import/validation", nil, metrics.NewExpDecaySample(1028, 0.015))
+	blockImportTrieCommitHistogram = metrics.NewRegisteredHistogram("chain/import/triecommit", nil, metrics.NewExpDecaySample(1028, 0.015))
+	blockImportDBWriteHistogram    = metrics.NewRegisteredHistogram("chain/import/dbwrite", nil, metrics.NewExpDecaySample(1028, 0.015))
+)
+
+// BlockImportStats holds the time spent in each stage of importing a block by InsertChain.
+// The durations are in nanoseconds when marshalled to JSON.
+type BlockImportStats struct {
+	Number  uint64      `json:"number"`
+	Hash    common.Hash `json:"hash"`
+	Txs     int         `json:"txs"`
+	GasUsed uint64      `json:"gasUsed"`
+
+	SenderRecovery time.Duration `json:"senderRecovery"` // recovering the senders not recovered in background yet
+	Execution      time.Duration `json:"execution"`      // applying the transactions and finalizing the block
+	StateAccess    time.Duration `json:"stateAccess"`    // reading, hashing and updating the state tries during execution and validation
+	Validation     time.Duration `json:"validation"`     // verifying the header and the body, and validating the state
+	TrieCommit     time.Duration `json:"trieCommit"`     // committing the state trie
+	DBWrite        time.Duration `json:"dbWrite"`        // writing the block, receipts and the others except the state trie
+	Total          time.Duration `json:"total"`
+}
+
+// updateMetrics records the stage timings in the metrics.
+func (s *BlockImportStats) updateMetrics() {
+	blockImportSendersHistogram.Update(int64(s.SenderRecovery))
+	blockImportExecutionHistogram.Update(int64(s.Execution))
+	blockImportStateHistogram.Update(int64(s.StateAccess))
+	blockImportValidationHistogram.Update(int64(s.Validation))
+	blockImportTrieCommitHistogram.Update(int64(s.TrieCommit))
+	blockImportDBWriteHistogram.Update(int64(s.DBWrite))
+}
+
+// blockImportStatsRing keeps the import stats of the recently imported blocks.
+type blockImportStatsRing struct {
+	mu    sync.Mutex
+	stats []*BlockImportStats
+	next  int
+}
+
+func newBlockImportStatsRing(size int) *blockImportStatsRing {
+	return &blockImportStatsRing{stats: make([]*BlockImportStats, 0, size)}
+}
+
+func (r *blockImportStatsRing) add(s *BlockImportStats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.stats) < cap(r.stats) {
+		r.stats = append(r.stats, s)
+		return
+	}
+	r.stats[r.next] = s
+	r.next = (r.next + 1) % len(r.stats)
+}
+
+// slowest returns up to n stats with the longest total import times, slowest first.
+func (r *blockImportStatsRing) slowest(n int) []*BlockImportStats {
+	r.mu.Lock()
+	stats := make([]*BlockImportStats, len(r.stats))
+	copy(stats, r.stats)
+	r.mu.Unlock()
+
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].Total > stats[j].Total })
+	if n < 0 {
+		n = 0
+	}
+	if n < len(stats) {
+		stats = stats[:n]
+	}
+	return stats
+}
+
+// SlowestBlockImports returns up to n block imports taking the longest among the recently
+// imported blocks, slowest first.
+func (bc *BlockChain) SlowestBlockImports(n int) []*BlockImportStats {
+	return bc.recentImports.slowest(n)
+}
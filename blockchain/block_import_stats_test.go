@@ -0,0 +1,58 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockImportStatsRing(t *testing.T) {
+	ring := newBlockImportStatsRing(3)
+	for i, total := range []time.Duration{5, 1, 4, 3, 2} {
+		ring.add(&BlockImportStats{Number: uint64(i), Total: total})
+	}
+
+	// The stats of the first two blocks are overwritten
+	var numbers []uint64
+	for _, stats := range ring.slowest(10) {
+		numbers = append(numbers, stats.Number)
+	}
+	assert.Equal(t, []uint64{2, 3, 4}, numbers)
+	assert.Len(t, ring.slowest(2), 2)
+	assert.Empty(t, ring.slowest(-1))
+}
+
+// TestSlowestBlockImports tests if the stage timings of the inserted blocks are kept.
+func TestSlowestBlockImports(t *testing.T) {
+	_, blockchain, err := newCanonical(gxhash.NewFaker(), 5, true)
+	assert.NoError(t, err)
+	defer blockchain.Stop()
+
+	stats := blockchain.SlowestBlockImports(10)
+	assert.Len(t, stats, 5)
+	for i, s := range stats {
+		if i > 0 {
+			assert.True(t, stats[i-1].Total >= s.Total)
+		}
+		assert.Equal(t, blockchain.GetBlockByNumber(s.Number).Hash(), s.Hash)
+		assert.True(t, s.Total >= s.Execution+s.Validation)
+	}
+}
@@ -70,6 +70,23 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 	if hash := types.DeriveSha(block.Transactions()); hash != header.TxHash {
 		return fmt.Errorf("transaction root hash mismatch: have %x, want %x", hash, header.TxHash)
 	}
+	// The size limit is validated only if it is set by the chain config, since the default one
+	// is a heuristic of the tx pool which has not been validated for the blocks.
+	if v.config.TxData != nil && v.config.TxData.MaxTxSize != 0 {
+		for _, tx := range block.Transactions() {
+			if err := validateTxSize(v.config, tx); err != nil {
+				return fmt.Errorf("transaction %x: %v", tx.Hash(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateTxSize checks whether the size of the transaction is within the limit of the chain config.
+func validateTxSize(config *params.ChainConfig, tx *types.Transaction) error {
+	if uint64(tx.Size()) > config.TxData.TxSizeLimit() {
+		return ErrOversizedData
+	}
 	return nil
 }
 
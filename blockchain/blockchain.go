@@ -36,6 +36,7 @@ import (
 	"github.com/go-redis/redis/v7"
 	lru "github.com/hashicorp/golang-lru"
 	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/state/snapshot"
 	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/blockchain/vm"
 	"github.com/klaytn/klaytn/common"
@@ -80,6 +81,11 @@ var (
 	blockPrefetchExecuteTimer   = klaytnmetrics.NewRegisteredHybridTimer("chain/prefetch/executes", nil)
 	blockPrefetchInterruptMeter = metrics.NewRegisteredMeter("chain/prefetch/interrupts", nil)
 
+	blockReorgMeter          = metrics.NewRegisteredMeter("chain/reorg/executes", nil)
+	blockReorgDropMeter      = metrics.NewRegisteredMeter("chain/reorg/drop", nil)
+	blockReorgAddMeter       = metrics.NewRegisteredMeter("chain/reorg/add", nil)
+	blockReorgDepthHistogram = metrics.NewRegisteredHistogram("chain/reorg/depth", nil, metrics.NewExpDecaySample(1028, 0.015))
+
 	ErrNoGenesis            = errors.New("genesis not found in chain")
 	ErrNotExistNode         = errors.New("the node does not exist in cached node")
 	ErrQuitBySignal         = errors.New("quit by signal")
@@ -118,6 +124,16 @@ type CacheConfig struct {
 	TriesInMemory        uint64                       // Maximum number of recent state tries according to its block number
 	SenderTxHashIndexing bool                         // Enables saving senderTxHash to txHash mapping information to database and cache
 	TrieNodeCacheConfig  *statedb.TrieNodeCacheConfig // Configures trie node cache
+	ParallelTxExecution  bool                         // Enables executing the transactions of a block in parallel
+	ParallelTxWorkers    int                          // Number of workers for the parallel transaction execution (0 = number of CPUs)
+	TxLookupLimit        uint64                       // Number of recent blocks whose transactions are indexed (0 = entire chain)
+	BodyRetention        uint64                       // Number of recent blocks whose bodies and receipts are kept (0 = entire chain)
+	AncientThreshold     uint64                       // Number of recent blocks kept in the database when the older ones are frozen to the ancient store
+	StorageOwnerIndexing bool                         // Enables indexing the contract accounts owning the storage trie roots
+	SnapshotCacheSize    int                          // Size of in-memory cache of the state snapshot (MiB, 0 = snapshot disabled)
+	SnapshotAsyncGen     bool                         // Enables generating the state snapshot in background, without blocking the start
+	LivePruningInterval  uint64                       // Number of blocks between the cycles pruning the past states while running (0 = disabled)
+	LivePruningBloomSize uint64                       // Size of the bloom filter marking the state to keep by the live state pruning (MiB)
 }
 
 // gcBlock is used for priority queue for GC.
@@ -152,6 +168,7 @@ type BlockChain struct {
 
 	hc            *HeaderChain
 	rmLogsFeed    event.Feed
+	reorgFeed     event.Feed
 	chainFeed     event.Feed
 	chainSideFeed event.Feed
 	chainHeadFeed event.Feed
@@ -167,6 +184,7 @@ type BlockChain struct {
 	currentFastBlock atomic.Value // Current head of the fast-sync chain (may be above the block chain!)
 
 	stateCache   state.Database // State database to reuse between imports (contains state cache)
+	snaps        *snapshot.Tree // Snapshot tree for fast state access (nil if disabled)
 	futureBlocks *lru.Cache     // future blocks are blocks added for later processing
 
 	quit    chan struct{} // blockchain quit channel
@@ -181,8 +199,14 @@ type BlockChain struct {
 	validator  Validator  // block and state validator interface
 	vmConfig   vm.Config
 
+	// lastSerialFallback is the last time a block rejected by ParallelStateProcessor is processed serially.
+	// It is protected by chainmu.
+	lastSerialFallback time.Time
+
 	badBlocks *lru.Cache // Bad block cache
 
+	recentImports *blockImportStatsRing // Stage timings of the recently imported blocks
+
 	parallelDBWrite bool // TODO-Klaytn-Storage parallelDBWrite will be replaced by number of goroutines when worker pool pattern is introduced.
 
 	// State migration
@@ -199,6 +223,19 @@ type BlockChain struct {
 	quitWarmUp         chan struct{}
 
 	prefetchTxCh chan prefetchTx
+
+	// Transaction lookup index
+	txLookupLimit   uint64      // must be atomically accessed
+	txLookupLimitCh chan uint64 // channel for changing the lookup limit
+	txIndexing      int32       // must be atomically accessed
+
+	// Block body pruning
+	bodyRetention   uint64      // must be atomically accessed
+	bodyRetentionCh chan uint64 // channel for changing the body retention
+	bodyPruning     int32       // must be atomically accessed
+
+	// Live state pruning
+	livePruner *livePruner // nil if the past states are not pruned while running
 }
 
 // prefetchTx is used to prefetch transactions, when fetcher works.
@@ -227,10 +264,18 @@ func NewBlockChain(db database.DBManager, cacheConfig *CacheConfig, chainConfig
 	}
 
 	state.EnabledExpensive = db.GetDBConfig().EnableDBPerfMetrics
+	state.StorageTrieOwnerIndexing = cacheConfig.StorageOwnerIndexing
 
 	// Initialize DeriveSha implementation
 	InitDeriveSha(chainConfig.DeriveShaImpl)
 
+	if err := vm.ValidateChainPrecompiles(chainConfig); err != nil {
+		return nil, err
+	}
+	if err := vm.ValidateDisabledEVMFeatures(chainConfig); err != nil {
+		return nil, err
+	}
+
 	futureBlocks, _ := lru.New(maxFutureBlocks)
 	badBlocks, _ := lru.New(maxBadBlocks)
 
@@ -247,9 +292,14 @@ func NewBlockChain(db database.DBManager, cacheConfig *CacheConfig, chainConfig
 		engine:             engine,
 		vmConfig:           vmConfig,
 		badBlocks:          badBlocks,
+		recentImports:      newBlockImportStatsRing(blockImportStatsWindow),
 		parallelDBWrite:    db.IsParallelDBWrite(),
 		stopStateMigration: make(chan struct{}),
 		prefetchTxCh:       make(chan prefetchTx, MaxPrefetchTxs),
+		txLookupLimit:      cacheConfig.TxLookupLimit,
+		txLookupLimitCh:    make(chan uint64),
+		bodyRetention:      cacheConfig.BodyRetention,
+		bodyRetentionCh:    make(chan uint64),
 	}
 
 	// set hardForkBlockNumberConfig which will be used as a global variable
@@ -259,7 +309,11 @@ func NewBlockChain(db database.DBManager, cacheConfig *CacheConfig, chainConfig
 
 	bc.validator = NewBlockValidator(chainConfig, bc, engine)
 	bc.prefetcher = newStatePrefetcher(chainConfig, bc, engine)
-	bc.processor = NewStateProcessor(chainConfig, bc, engine)
+	if cacheConfig.ParallelTxExecution {
+		bc.processor = NewParallelStateProcessor(chainConfig, bc, engine, cacheConfig.ParallelTxWorkers)
+	} else {
+		bc.processor = NewStateProcessor(chainConfig, bc, engine)
+	}
 
 	var err error
 	bc.hc, err = NewHeaderChain(db, chainConfig, engine, bc.getProcInterrupt)
@@ -290,6 +344,19 @@ func NewBlockChain(db database.DBManager, cacheConfig *CacheConfig, chainConfig
 			}
 		}
 	}
+	// Load any existing snapshot, regenerating it if loading failed
+	if bc.cacheConfig.SnapshotCacheSize > 0 {
+		bc.snaps = snapshot.New(bc.db, bc.stateCache.TrieDB(), bc.cacheConfig.SnapshotCacheSize, bc.CurrentBlock().Root(), !bc.cacheConfig.SnapshotAsyncGen)
+	}
+	if bc.cacheConfig.LivePruningInterval > 0 {
+		if bc.isArchiveMode() {
+			logger.Warn("Live state pruning is disabled in the archive mode")
+		} else if err := checkStatePrunable(bc.db); err != nil {
+			logger.Warn("Live state pruning is disabled", "err", err)
+		} else {
+			bc.livePruner = newLivePruner(bc, bc.cacheConfig.LivePruningInterval, bc.cacheConfig.LivePruningBloomSize)
+		}
+	}
 
 	for i := 1; i <= bc.cacheConfig.TrieNodeCacheConfig.NumFetcherPrefetchWorker; i++ {
 		bc.wg.Add(1)
@@ -299,6 +366,9 @@ func NewBlockChain(db database.DBManager, cacheConfig *CacheConfig, chainConfig
 
 	// Take ownership of this particular state
 	go bc.update()
+	bc.startTxIndexer()
+	bc.startBodyPruner()
+	bc.startChainFreezer()
 	bc.gcCachedNodeLoop()
 	bc.restartStateMigration()
 
@@ -407,7 +477,7 @@ func (bc *BlockChain) loadLastState() error {
 		return bc.Reset()
 	}
 	// Make sure the state associated with the block is available
-	if _, err := state.New(currentBlock.Root(), bc.stateCache); err != nil {
+	if _, err := state.New(currentBlock.Root(), bc.stateCache, nil); err != nil {
 		// Dangling block without a state associated, init from scratch
 		logger.Error("Head state missing, repairing chain",
 			"number", currentBlock.NumberU64(), "hash", currentBlock.Hash().String())
@@ -460,13 +530,23 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	bc.mu.Lock()
 	defer bc.mu.Unlock()
 
-	// Rewind the header chain, deleting all block bodies until then
+	// Rewind the header chain, deleting all block bodies, receipts and tx lookup entries until then
 	delFn := func(hash common.Hash, num uint64) {
-		bc.db.DeleteBody(hash, num)
+		if body := bc.db.ReadBody(hash, num); body != nil {
+			for _, tx := range body.Transactions {
+				bc.db.DeleteTxLookupEntry(tx.Hash())
+			}
+		}
+		bc.db.DeleteBodyAndReceipts(hash, num)
 	}
 	bc.hc.SetHead(head, delFn)
 	currentHeader := bc.CurrentHeader()
 
+	// Discard the frozen blocks above the new head
+	if err := bc.db.TruncateAncientBlocks(currentHeader.Number.Uint64() + 1); err != nil {
+		logger.Error("Failed to truncate the ancient store", "err", err)
+	}
+
 	// Clear out any stale content from the caches
 	bc.futureBlocks.Purge()
 	bc.db.ClearBlockChainCache()
@@ -475,11 +555,13 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	if currentBlock := bc.CurrentBlock(); currentBlock != nil && currentHeader.Number.Uint64() < currentBlock.NumberU64() {
 		bc.currentBlock.Store(bc.GetBlock(currentHeader.Hash(), currentHeader.Number.Uint64()))
 	}
-	if currentBlock := bc.CurrentBlock(); currentBlock != nil {
-		if _, err := state.New(currentBlock.Root(), bc.stateCache); err != nil {
-			// Rewound state missing, rolled back to before pivot, reset to genesis
-			bc.currentBlock.Store(bc.genesisBlock)
+	// Rewind the block chain further to the nearest block whose state is available, or the genesis
+	for currentBlock := bc.CurrentBlock(); currentBlock != nil && currentBlock.NumberU64() > 0; currentBlock = bc.CurrentBlock() {
+		if _, err := state.New(currentBlock.Root(), bc.stateCache, nil); err == nil {
+			break
 		}
+		logger.Warn("Rewinding the block without state", "number", currentBlock.NumberU64(), "hash", currentBlock.Hash())
+		bc.currentBlock.Store(bc.GetBlock(currentBlock.ParentHash(), currentBlock.NumberU64()-1))
 	}
 	// Rewind the fast block in a simpleton way to the target head
 	if currentFastBlock := bc.CurrentFastBlock(); currentFastBlock != nil && currentHeader.Number.Uint64() < currentFastBlock.NumberU64() {
@@ -498,6 +580,10 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	bc.db.WriteHeadBlockHash(currentBlock.Hash())
 	bc.db.WriteHeadFastBlockHash(currentFastBlock.Hash())
 
+	// Destroy any existing state snapshot and regenerate it in the background
+	if bc.snaps != nil {
+		bc.snaps.Rebuild(currentBlock.Root())
+	}
 	return bc.loadLastState()
 }
 
@@ -518,6 +604,11 @@ func (bc *BlockChain) FastSyncCommitHead(hash common.Hash) error {
 	bc.lastCommittedBlock = block.NumberU64()
 	bc.mu.Unlock()
 
+	// Destroy any existing state snapshot and regenerate it in the background
+	if bc.snaps != nil {
+		bc.snaps.Rebuild(block.Root())
+	}
+
 	logger.Info("Committed new head block", "number", block.Number(), "hash", hash)
 	return nil
 }
@@ -551,7 +642,7 @@ func (bc *BlockChain) State() (*state.StateDB, error) {
 
 // StateAt returns a new mutable state based on a particular point in time.
 func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
-	return state.New(root, bc.stateCache)
+	return state.New(root, bc.stateCache, bc.snaps)
 }
 
 // StateAtWithPersistent returns a new mutable state based on a particular point in time with persistent trie nodes.
@@ -560,7 +651,7 @@ func (bc *BlockChain) StateAtWithPersistent(root common.Hash) (*state.StateDB, e
 	if !exist {
 		return nil, ErrNotExistNode
 	}
-	return state.New(root, bc.stateCache)
+	return state.New(root, bc.stateCache, bc.snaps)
 }
 
 // StateAtWithGCLock returns a new mutable state based on a particular point in time with read lock of the state nodes.
@@ -573,7 +664,7 @@ func (bc *BlockChain) StateAtWithGCLock(root common.Hash) (*state.StateDB, error
 		return nil, ErrNotExistNode
 	}
 
-	stateDB, err := state.New(root, bc.stateCache)
+	stateDB, err := state.New(root, bc.stateCache, bc.snaps)
 	if err != nil {
 		bc.RUnlockGCCachedNode()
 		return nil, err
@@ -587,6 +678,12 @@ func (bc *BlockChain) StateCache() state.Database {
 	return bc.stateCache
 }
 
+// Snapshots returns the state snapshot tree of the blockchain, or nil if the
+// snapshot is disabled.
+func (bc *BlockChain) Snapshots() *snapshot.Tree {
+	return bc.snaps
+}
+
 // Reset purges the entire blockchain, restoring it to its genesis state.
 func (bc *BlockChain) Reset() error {
 	return bc.ResetWithGenesisBlock(bc.genesisBlock)
@@ -625,7 +722,7 @@ func (bc *BlockChain) ResetWithGenesisBlock(genesis *types.Block) error {
 func (bc *BlockChain) repair(head **types.Block) error {
 	for {
 		// Abort if we've rewound to a head block that does have associated state
-		if _, err := state.New((*head).Root(), bc.stateCache); err == nil {
+		if _, err := state.New((*head).Root(), bc.stateCache, nil); err == nil {
 			logger.Info("Rewound blockchain to past state", "number", (*head).Number(), "hash", (*head).Hash())
 			return nil
 		} else {
@@ -887,6 +984,13 @@ func (bc *BlockChain) Stop() {
 			logger.Error("Dangling trie nodes after full cleanup")
 		}
 	}
+	// Flatten the state snapshot into the disk layer of the committed head state,
+	// so that it can be loaded on the next start
+	if bc.snaps != nil {
+		if err := bc.snaps.Persist(bc.CurrentBlock().Root()); err != nil {
+			logger.Error("Failed to persist state snapshot", "err", err)
+		}
+	}
 	if triedb.TrieNodeCache() != nil {
 		_ = triedb.TrieNodeCache().Close()
 	}
@@ -1170,6 +1274,10 @@ func (bc *BlockChain) writeStateTrie(block *types.Block, state *state.StateDB) e
 			}
 
 			bc.lastCommittedBlock = block.NumberU64()
+
+			if bc.livePruner != nil {
+				bc.livePruner.committed(block.NumberU64(), root)
+			}
 		}
 
 		bc.chBlock <- gcBlock{root, block.NumberU64()}
@@ -1667,6 +1775,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 		if err == nil {
 			err = bc.validator.ValidateBody(block)
 		}
+		verifyTime := time.Since(bstart)
 
 		switch {
 		case err == ErrKnownBlock:
@@ -1735,16 +1844,27 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 			return i, events, coalescedLogs, err
 		}
 
+		// Recover the senders which are not recovered by the background recoverer yet
+		senderStart := time.Now()
+		signer := types.MakeSigner(bc.chainConfig, block.Number())
+		for _, tx := range block.Transactions() {
+			cacheSender(signer, tx)
+		}
+		senderTime := time.Since(senderStart)
+
 		// Process block using the parent state as reference point.
 		receipts, logs, usedGas, internalTxTraces, procStats, err := bc.processor.Process(block, stateDB, bc.vmConfig)
-		if err != nil {
-			bc.reportBlock(block, receipts, err)
-			atomic.StoreUint32(&followupInterrupt, 1)
-			return i, events, coalescedLogs, err
+		if err == nil {
+			// Validate the state using the default validator
+			err = bc.validator.ValidateState(block, parent, stateDB, receipts, usedGas)
+			// The errors of Process are the same as the ones of the serial execution, but a mismatching state
+			// may be a flaw of the parallel execution, so retry with the serial execution before rejecting the block
+			if _, parallel := bc.processor.(*ParallelStateProcessor); err != nil && parallel && bc.allowSerialFallback() {
+				logger.Warn("Parallel block processing failed, falling back to serial processing",
+					"number", block.NumberU64(), "hash", block.Hash(), "err", err)
+				stateDB, receipts, logs, usedGas, internalTxTraces, procStats, err = bc.processSerially(block, parent)
+			}
 		}
-
-		// Validate the state using the default validator
-		err = bc.validator.ValidateState(block, parent, stateDB, receipts, usedGas)
 		if err != nil {
 			bc.reportBlock(block, receipts, err)
 			atomic.StoreUint32(&followupInterrupt, 1)
@@ -1780,6 +1900,22 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 
 		blockAgeTimer.Update(time.Since(time.Unix(int64(block.Time().Uint64()), 0)))
 
+		importStats := &BlockImportStats{
+			Number:         block.NumberU64(),
+			Hash:           block.Hash(),
+			Txs:            len(block.Transactions()),
+			GasUsed:        block.GasUsed(),
+			SenderRecovery: senderTime,
+			Execution:      procStats.AfterFinalize.Sub(procStats.BeforeApplyTxs),
+			StateAccess:    stateDB.AccountReads + stateDB.AccountHashes + stateDB.AccountUpdates + stateDB.StorageReads + stateDB.StorageHashes + stateDB.StorageUpdates,
+			Validation:     verifyTime + afterValidate.Sub(procStats.AfterFinalize),
+			TrieCommit:     writeResult.TrieWriteTime,
+			DBWrite:        writeResult.TotalWriteTime - writeResult.TrieWriteTime,
+			Total:          time.Since(bstart),
+		}
+		importStats.updateMetrics()
+		bc.recentImports.add(importStats)
+
 		switch writeResult.Status {
 		case CanonStatTy:
 			processTxsTime := common.PrettyDuration(procStats.AfterApplyTxs.Sub(procStats.BeforeApplyTxs))
@@ -2123,6 +2259,14 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 	if len(deletedLogs) > 0 {
 		go bc.rmLogsFeed.Send(RemovedLogsEvent{deletedLogs})
 	}
+	if len(oldChain) > 0 && len(newChain) > 0 {
+		blockReorgMeter.Mark(1)
+		blockReorgDropMeter.Mark(int64(len(oldChain)))
+		blockReorgAddMeter.Mark(int64(len(newChain)))
+		blockReorgDepthHistogram.Update(int64(len(oldChain)))
+
+		go bc.reorgFeed.Send(newChainReorgEvent(oldChain, newChain, commonBlock, diff, types.TxDifference(addedTxs, deletedTxs)))
+	}
 	if len(oldChain) > 0 {
 		go func() {
 			for _, block := range oldChain {
@@ -2134,6 +2278,32 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 	return nil
 }
 
+// newChainReorgEvent returns a ChainReorgEvent of the dropped and the added blocks listed from the head.
+func newChainReorgEvent(oldChain, newChain types.Blocks, commonBlock *types.Block, droppedTxs, addedTxs types.Transactions) ChainReorgEvent {
+	ev := ChainReorgEvent{
+		OldHead:        oldChain[0].Header(),
+		NewHead:        newChain[0].Header(),
+		CommonAncestor: commonBlock.Header(),
+		DroppedBlocks:  make([]common.Hash, len(oldChain)),
+		AddedBlocks:    make([]common.Hash, len(newChain)),
+		DroppedTxs:     make([]common.Hash, len(droppedTxs)),
+		AddedTxs:       make([]common.Hash, len(addedTxs)),
+	}
+	for i, block := range oldChain {
+		ev.DroppedBlocks[i] = block.Hash()
+	}
+	for i, block := range newChain {
+		ev.AddedBlocks[i] = block.Hash()
+	}
+	for i, tx := range droppedTxs {
+		ev.DroppedTxs[i] = tx.Hash()
+	}
+	for i, tx := range addedTxs {
+		ev.AddedTxs[i] = tx.Hash()
+	}
+	return ev
+}
+
 // PostChainEvents iterates over the events generated by a chain insertion and
 // posts them into the event feed.
 // TODO: Should not expose PostChainEvents. The chain events should be posted in WriteBlock.
@@ -2341,6 +2511,11 @@ func (bc *BlockChain) SubscribeRemovedLogsEvent(ch chan<- RemovedLogsEvent) even
 	return bc.scope.Track(bc.rmLogsFeed.Subscribe(ch))
 }
 
+// SubscribeChainReorgEvent registers a subscription of ChainReorgEvent.
+func (bc *BlockChain) SubscribeChainReorgEvent(ch chan<- ChainReorgEvent) event.Subscription {
+	return bc.scope.Track(bc.reorgFeed.Subscribe(ch))
+}
+
 // SubscribeChainEvent registers a subscription of ChainEvent.
 func (bc *BlockChain) SubscribeChainEvent(ch chan<- ChainEvent) event.Subscription {
 	return bc.scope.Track(bc.chainFeed.Subscribe(ch))
@@ -2387,11 +2562,43 @@ func (bc *BlockChain) SaveTrieNodeCacheToDisk() error {
 	return nil
 }
 
+// DumpTrieNodeCacheToDisk saves the trie node cache to disk and writes its portable dump,
+// which can be loaded by another node, to dumpPath in background.
+func (bc *BlockChain) DumpTrieNodeCacheToDisk(dumpPath string) error {
+	if err := bc.stateCache.TrieDB().CanLoadTrieNodeCache(); err != nil {
+		return err
+	}
+	go bc.stateCache.TrieDB().DumpTrieNodeCacheToFile(bc.cacheConfig.TrieNodeCacheConfig.FastCacheFileDir, dumpPath, runtime.NumCPU()/2)
+	return nil
+}
+
+// LoadTrieNodeCache replaces the trie node cache with the one in the portable dump read from r
+// in background, and closes r after loading it.
+func (bc *BlockChain) LoadTrieNodeCache(r io.ReadCloser) error {
+	if err := bc.stateCache.TrieDB().CanLoadTrieNodeCache(); err != nil {
+		r.Close()
+		return err
+	}
+	go func() {
+		defer r.Close()
+		if err := bc.stateCache.TrieDB().LoadTrieNodeCache(r); err != nil {
+			logger.Error("Failed to load the trie node cache", "err", err)
+		}
+	}()
+	return nil
+}
+
 // ApplyTransaction attempts to apply a transaction to the given state database
 // and uses the input parameters for its environment. It returns the receipt
 // for the transaction, gas used and an error if the transaction failed,
 // indicating the block was invalid.
 func (bc *BlockChain) ApplyTransaction(chainConfig *params.ChainConfig, author *common.Address, statedb *state.StateDB, header *types.Header, tx *types.Transaction, usedGas *uint64, vmConfig *vm.Config) (*types.Receipt, uint64, *vm.InternalTxTrace, error) {
+	return applyTransaction(chainConfig, bc, author, statedb, header, tx, usedGas, vmConfig)
+}
+
+// applyTransaction applies a transaction in the same way as ApplyTransaction, reading the
+// ancestor headers for the BLOCKHASH opcode from the given chain.
+func applyTransaction(chainConfig *params.ChainConfig, chain ChainContext, author *common.Address, statedb *state.StateDB, header *types.Header, tx *types.Transaction, usedGas *uint64, vmConfig *vm.Config) (*types.Receipt, uint64, *vm.InternalTxTrace, error) {
 
 	// TODO-Klaytn We reject transactions with unexpected gasPrice and do not put the transaction into TxPool.
 	//         And we run transactions regardless of gasPrice if we push transactions in the TxPool.
@@ -2414,7 +2621,7 @@ func (bc *BlockChain) ApplyTransaction(chainConfig *params.ChainConfig, author *
 		return nil, 0, nil, err
 	}
 	// Create a new context to be used in the EVM environment
-	context := NewEVMContext(msg, header, bc, author)
+	context := NewEVMContext(msg, header, chain, author)
 	// Create a new environment which holds all relevant information
 	// about the transaction and calling mechanisms.
 	vmenv := vm.NewEVM(context, statedb, chainConfig, vmConfig)
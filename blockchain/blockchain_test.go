@@ -161,7 +161,7 @@ func testBlockChainImport(chain types.Blocks, blockchain *BlockChain) error {
 			}
 			return err
 		}
-		statedb, err := state.New(blockchain.GetBlockByHash(block.ParentHash()).Root(), blockchain.stateCache)
+		statedb, err := state.New(blockchain.GetBlockByHash(block.ParentHash()).Root(), blockchain.stateCache, nil)
 		if err != nil {
 			return err
 		}
@@ -931,6 +931,73 @@ func TestLogReorgs(t *testing.T) {
 	}
 }
 
+// TestChainReorgEvent tests if a reorg posts the dropped and added blocks and transactions.
+func TestChainReorgEvent(t *testing.T) {
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		db      = database.NewMemoryDBManager()
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{addr1: {Balance: big.NewInt(10000000000000)}}}
+		genesis = gspec.MustCommit(db)
+		signer  = types.NewEIP155Signer(gspec.Config.ChainID)
+	)
+
+	blockchain, _ := NewBlockChain(db, nil, gspec.Config, gxhash.NewFaker(), vm.Config{})
+	defer blockchain.Stop()
+
+	reorgCh := make(chan ChainReorgEvent)
+	blockchain.SubscribeChainReorgEvent(reorgCh)
+
+	transfer := func(gen *BlockGen, to common.Address) *types.Transaction {
+		tx, err := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), to, big.NewInt(1), params.TxGas, new(big.Int), nil), signer, key1)
+		if err != nil {
+			t.Fatalf("failed to create tx: %v", err)
+		}
+		gen.AddTx(tx)
+		return tx
+	}
+	// Both chains include the same transaction in the first block, but different ones in the following blocks
+	var oldTxs, newTxs []*types.Transaction
+	oldChain, _ := GenerateChain(params.TestChainConfig, genesis, gxhash.NewFaker(), db, 2, func(i int, gen *BlockGen) {
+		if i == 0 {
+			// Raise the blockscore not to reorganize at the same height with the same total blockscore
+			gen.OffsetTime(-9)
+		}
+		oldTxs = append(oldTxs, transfer(gen, common.Address{1}))
+	})
+	if _, err := blockchain.InsertChain(oldChain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	newChain, _ := GenerateChain(params.TestChainConfig, genesis, gxhash.NewFaker(), db, 3, func(i int, gen *BlockGen) {
+		gen.SetExtra([]byte("fork"))
+		if i == 0 {
+			newTxs = append(newTxs, transfer(gen, common.Address{1}))
+		} else {
+			newTxs = append(newTxs, transfer(gen, common.Address{2}))
+		}
+	})
+
+	reorgs, depths := blockReorgMeter.Count(), blockReorgDepthHistogram.Sum()
+	if _, err := blockchain.InsertChain(newChain); err != nil {
+		t.Fatalf("failed to insert forked chain: %v", err)
+	}
+
+	select {
+	case ev := <-reorgCh:
+		assert.Equal(t, oldChain[1].Hash(), ev.OldHead.Hash())
+		assert.Equal(t, newChain[2].Hash(), ev.NewHead.Hash())
+		assert.Equal(t, genesis.Hash(), ev.CommonAncestor.Hash())
+		assert.Equal(t, []common.Hash{oldChain[1].Hash(), oldChain[0].Hash()}, ev.DroppedBlocks)
+		assert.Equal(t, []common.Hash{newChain[2].Hash(), newChain[1].Hash(), newChain[0].Hash()}, ev.AddedBlocks)
+		assert.Equal(t, []common.Hash{oldTxs[1].Hash()}, ev.DroppedTxs)
+		assert.Equal(t, []common.Hash{newTxs[1].Hash(), newTxs[2].Hash()}, ev.AddedTxs)
+	case <-time.After(1 * time.Second):
+		t.Fatal("Timeout. There is no ChainReorgEvent has been sent.")
+	}
+	assert.Equal(t, reorgs+1, blockReorgMeter.Count())
+	assert.Equal(t, depths+2, blockReorgDepthHistogram.Sum())
+}
+
 func TestReorgSideEvent(t *testing.T) {
 	var (
 		db      = database.NewMemoryDBManager()
@@ -1677,3 +1744,55 @@ func TestBlockChain_writeBlockLogsToRemoteCache(t *testing.T) {
 
 	assert.Equal(t, log, logs[0])
 }
+
+// TestBlockChain_StateSnapshot tests if the state snapshot follows the imported blocks,
+// and it is persisted on stop to be loaded on the next start.
+func TestBlockChain_StateSnapshot(t *testing.T) {
+	var (
+		key, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr      = crypto.PubkeyToAddress(key.PublicKey)
+		recipient = common.HexToAddress("0xaaaa")
+		db        = database.NewMemoryDBManager()
+		gspec     = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{addr: {Balance: big.NewInt(10000000000000)}}}
+		genesis   = gspec.MustCommit(db)
+		signer    = types.NewEIP155Signer(gspec.Config.ChainID)
+	)
+	cacheConfig := &CacheConfig{
+		CacheSize:           512,
+		BlockInterval:       DefaultBlockInterval,
+		TriesInMemory:       DefaultTriesInMemory,
+		TrieNodeCacheConfig: statedb.GetEmptyTrieNodeCacheConfig(),
+		SnapshotCacheSize:   16,
+	}
+	bc, err := NewBlockChain(db, cacheConfig, gspec.Config, gxhash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain, _ := GenerateChain(gspec.Config, genesis, gxhash.NewFaker(), db, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), recipient, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+		gen.AddTx(tx)
+	})
+	if _, err := bc.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+	head := bc.CurrentBlock()
+	assert.NotNil(t, bc.snaps.Snapshot(head.Root()))
+
+	stateDB, err := bc.State()
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(10000), stateDB.GetBalance(recipient))
+
+	// The snapshot is flattened into the disk on stop and loaded without regeneration
+	bc.Stop()
+	assert.Equal(t, head.Root(), db.ReadSnapshotRoot())
+
+	bc, err = NewBlockChain(db, cacheConfig, gspec.Config, gxhash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+	assert.Equal(t, head.Root(), bc.snaps.DiskRoot())
+	stateDB, err = bc.State()
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(10000), stateDB.GetBalance(recipient))
+}
@@ -0,0 +1,225 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/storage/database"
+)
+
+// MinBodyRetention is the minimum number of recent blocks whose bodies and receipts are kept
+// when the old ones are pruned, so that the recent blocks can still be relayed to the peers.
+const MinBodyRetention = 128
+
+// bodyPruneBatchBlocks is the number of blocks pruned between the updates of the body tail.
+const bodyPruneBatchBlocks = 1000
+
+var ErrBodyRetentionTooSmall = fmt.Errorf("body retention should be 0 or at least %d", MinBodyRetention)
+
+// BlockRange is a range of block numbers, both inclusive.
+type BlockRange struct {
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+}
+
+// BlockStoreStatus shows the ranges of the blocks whose data are kept in the database.
+// The body and the receipts of the genesis block are always kept.
+type BlockStoreStatus struct {
+	Retention uint64     `json:"retention"` // number of recent blocks whose bodies and receipts are kept, 0 for the entire chain
+	Headers   BlockRange `json:"headers"`
+	Bodies    BlockRange `json:"bodies"`
+	Receipts  BlockRange `json:"receipts"`
+	Pruning   bool       `json:"pruning"` // true if the old bodies and receipts are being pruned
+}
+
+// bodyTail returns the oldest block whose body and receipts should be kept
+// when the head block is head and the retention is retention.
+func bodyTail(head, retention uint64) uint64 {
+	if retention == 0 || head+1 <= retention {
+		return 0
+	}
+	return head - retention + 1
+}
+
+// ValidateBodyRetention returns an error if the body retention is too small.
+func ValidateBodyRetention(retention uint64) error {
+	if retention != 0 && retention < MinBodyRetention {
+		return ErrBodyRetentionTooSmall
+	}
+	return nil
+}
+
+// SetBodyRetention changes the number of recent blocks whose bodies and receipts are kept.
+// The older ones are pruned in background, and 0 stops pruning. The pruned data are not restored
+// even if the retention grows, so the range of the bodies grows as the new blocks are inserted.
+func (bc *BlockChain) SetBodyRetention(retention uint64) error {
+	if err := ValidateBodyRetention(retention); err != nil {
+		return err
+	}
+	select {
+	case bc.bodyRetentionCh <- retention:
+	case <-bc.quit:
+	}
+	return nil
+}
+
+// BlockStoreStatus returns the ranges of the blocks whose headers, bodies and receipts are kept.
+func (bc *BlockChain) BlockStoreStatus() BlockStoreStatus {
+	tail, err := bc.db.ReadBodyTail()
+	if err != nil {
+		logger.Error("Failed to read the block body tail", "err", err)
+	}
+	head := bc.CurrentBlock().NumberU64()
+	return BlockStoreStatus{
+		Retention: atomic.LoadUint64(&bc.bodyRetention),
+		Headers:   BlockRange{From: 0, To: head},
+		Bodies:    BlockRange{From: tail, To: head},
+		Receipts:  BlockRange{From: tail, To: head},
+		Pruning:   atomic.LoadInt32(&bc.bodyPruning) == 1,
+	}
+}
+
+// startBodyPruner starts pruning the old block bodies and receipts in background.
+func (bc *BlockChain) startBodyPruner() {
+	headCh := make(chan ChainHeadEvent, 10)
+	sub := bc.SubscribeChainHeadEvent(headCh)
+
+	bc.wg.Add(1)
+	go bc.maintainBodies(headCh, sub)
+}
+
+// maintainBodies prunes the bodies and the receipts of the blocks older than the retention
+// whenever a new head block is inserted or the retention is changed.
+func (bc *BlockChain) maintainBodies(headCh chan ChainHeadEvent, sub event.Subscription) {
+	defer bc.wg.Done()
+	defer sub.Unsubscribe()
+
+	var (
+		head  = bc.CurrentBlock().NumberU64()
+		done  chan struct{} // non-nil while the bodies are being pruned
+		abort chan struct{} // closed to stop pruning the bodies
+	)
+	run := func() {
+		if done != nil || atomic.LoadUint64(&bc.bodyRetention) == 0 {
+			return
+		}
+		done, abort = make(chan struct{}), make(chan struct{})
+		go bc.pruneBodies(head, atomic.LoadUint64(&bc.bodyRetention), abort, done)
+	}
+	stop := func() {
+		if done != nil {
+			close(abort)
+			<-done
+			done = nil
+		}
+	}
+	defer stop()
+
+	run()
+	for {
+		select {
+		case ev := <-headCh:
+			head = ev.Block.NumberU64()
+			run()
+		case retention := <-bc.bodyRetentionCh:
+			logger.Info("Changing the block body retention", "old", atomic.LoadUint64(&bc.bodyRetention), "new", retention)
+			atomic.StoreUint64(&bc.bodyRetention, retention)
+			stop()
+			run()
+		case <-done:
+			done = nil
+		case <-sub.Err():
+			return
+		case <-bc.quit:
+			return
+		}
+	}
+}
+
+// pruneBodies removes the bodies, the receipts and the transaction indices of the blocks from the
+// body tail to the tail determined by the given head and retention, keeping their headers.
+func (bc *BlockChain) pruneBodies(head, retention uint64, abort, done chan struct{}) {
+	defer close(done)
+
+	tail, err := bc.db.ReadBodyTail()
+	if err != nil {
+		logger.Error("Failed to read the block body tail", "err", err)
+		return
+	}
+	target := bodyTail(head, retention)
+	if tail >= target {
+		return
+	}
+
+	atomic.StoreInt32(&bc.bodyPruning, 1)
+	defer atomic.StoreInt32(&bc.bodyPruning, 0)
+
+	var (
+		start   = time.Now()
+		from    = tail
+		batch   = bc.db.NewBatch(database.TxLookUpEntryDB)
+		blocks  = 0
+		current = tail
+	)
+	// The genesis block is kept since it is read at the startup
+	if current == 0 {
+		current = 1
+	}
+	// flush moves the tail after the data are removed, so the stored tail never passes the kept blocks
+	flush := func() bool {
+		if err := batch.Write(); err != nil {
+			logger.Error("Failed to remove transaction indices", "err", err)
+			return false
+		}
+		batch.Reset()
+		if err := bc.db.WriteBodyTail(current); err != nil {
+			logger.Error("Failed to write the block body tail", "err", err)
+			return false
+		}
+		return true
+	}
+	for current < target {
+		select {
+		case <-abort:
+			flush()
+			logger.Info("Block body pruning is aborted", "tail", current, "blocks", blocks, "elapsed", time.Since(start))
+			return
+		default:
+		}
+		if hash := bc.db.ReadCanonicalHash(current); hash != (common.Hash{}) {
+			if block := bc.GetBlock(hash, current); block != nil {
+				bc.db.DeleteTxLookupEntriesFromBatch(batch, block)
+			}
+			bc.db.DeleteBodyAndReceipts(hash, current)
+		}
+		current++
+		blocks++
+		if blocks%bodyPruneBatchBlocks == 0 || batch.ValueSize() >= database.IdealBatchSize {
+			if !flush() {
+				return
+			}
+		}
+	}
+	if flush() {
+		logger.Info("Pruned block bodies and receipts", "from", from, "to", target-1, "blocks", blocks, "elapsed", time.Since(start))
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyTail(t *testing.T) {
+	assert.Equal(t, uint64(0), bodyTail(10, 0))
+	assert.Equal(t, uint64(0), bodyTail(10, 11))
+	assert.Equal(t, uint64(1), bodyTail(10, 10))
+	assert.Equal(t, uint64(8), bodyTail(10, 3))
+}
+
+func TestBlockChain_BodyRetention(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		db      = database.NewMemoryDBManager()
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{addr: {Balance: big.NewInt(10000000000000)}}}
+		genesis = gspec.MustCommit(db)
+		signer  = types.NewEIP155Signer(gspec.Config.ChainID)
+	)
+	// The retention smaller than the minimum is only allowed in the tests
+	cacheConfig := &CacheConfig{
+		CacheSize:           512,
+		BlockInterval:       DefaultBlockInterval,
+		TriesInMemory:       DefaultTriesInMemory,
+		TrieNodeCacheConfig: statedb.GetEmptyTrieNodeCacheConfig(),
+		BodyRetention:       3,
+	}
+	bc, err := NewBlockChain(db, cacheConfig, gspec.Config, gxhash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+
+	var txs types.Transactions
+	chain, _ := GenerateChain(gspec.Config, genesis, gxhash.NewFaker(), db, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), addr, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+		gen.AddTx(tx)
+		txs = append(txs, tx)
+	})
+	if _, err := bc.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100 && (bc.BlockStoreStatus().Bodies.From != 8 || bc.BlockStoreStatus().Pruning); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, BlockStoreStatus{
+		Retention: 3,
+		Headers:   BlockRange{From: 0, To: 10},
+		Bodies:    BlockRange{From: 8, To: 10},
+		Receipts:  BlockRange{From: 8, To: 10},
+	}, bc.BlockStoreStatus())
+
+	// The headers are kept while the bodies, the receipts and the transaction indices are pruned
+	assert.NotNil(t, bc.GetBlockByNumber(0))
+	for _, block := range chain {
+		number := block.NumberU64()
+		assert.NotNil(t, bc.GetHeaderByNumber(number))
+		blockHash, _, _ := db.ReadTxLookupEntry(txs[number-1].Hash())
+		if number < 8 {
+			assert.Nil(t, bc.GetBlockByNumber(number))
+			assert.Nil(t, bc.GetReceiptsByBlockHash(block.Hash()))
+			assert.Equal(t, common.Hash{}, blockHash)
+		} else {
+			assert.NotNil(t, bc.GetBlockByNumber(number))
+			assert.NotNil(t, bc.GetReceiptsByBlockHash(block.Hash()))
+			assert.Equal(t, block.Hash(), blockHash)
+		}
+	}
+
+	assert.Equal(t, ErrBodyRetentionTooSmall, bc.SetBodyRetention(MinBodyRetention-1))
+	assert.NoError(t, bc.SetBodyRetention(0))
+	for i := 0; i < 100 && bc.BlockStoreStatus().Retention != 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, uint64(0), bc.BlockStoreStatus().Retention)
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/klaytn/klaytn/event"
+)
+
+const (
+	// DefaultAncientThreshold is the default number of recent blocks kept in the database
+	// when the older ones are moved to the ancient store.
+	DefaultAncientThreshold = 90000
+
+	// MinAncientThreshold is the minimum number of recent blocks kept in the database, so that
+	// the blocks which can be rewound by SetHead or rewritten by the fetcher stay mutable.
+	MinAncientThreshold = DefaultTriesInMemory
+
+	// freezeBatchBlocks is the maximum number of blocks moved to the ancient store at once.
+	freezeBatchBlocks = 30000
+)
+
+var ErrAncientThresholdTooSmall = fmt.Errorf("ancient threshold should be at least %d", MinAncientThreshold)
+
+// ValidateAncientThreshold returns an error if the ancient threshold is too small.
+func ValidateAncientThreshold(threshold uint64) error {
+	if threshold < MinAncientThreshold {
+		return ErrAncientThresholdTooSmall
+	}
+	return nil
+}
+
+// ancientLimit returns the number of blocks from the genesis which should be frozen
+// when the head block is head and the threshold is threshold.
+func ancientLimit(head, threshold uint64) uint64 {
+	if head+1 <= threshold {
+		return 0
+	}
+	return head - threshold + 1
+}
+
+// startChainFreezer starts moving the old blocks to the ancient store in background
+// if the ancient store is enabled.
+func (bc *BlockChain) startChainFreezer() {
+	if !bc.db.HasAncients() {
+		return
+	}
+	headCh := make(chan ChainHeadEvent, 10)
+	sub := bc.SubscribeChainHeadEvent(headCh)
+
+	bc.wg.Add(1)
+	go bc.freezeBlocks(bc.cacheConfig.AncientThreshold, headCh, sub)
+}
+
+// freezeBlocks moves the blocks older than the threshold to the ancient store whenever
+// a new head block is inserted.
+func (bc *BlockChain) freezeBlocks(threshold uint64, headCh chan ChainHeadEvent, sub event.Subscription) {
+	defer bc.wg.Done()
+	defer sub.Unsubscribe()
+
+	freeze := func(head uint64) {
+		var (
+			start  = time.Now()
+			from   = bc.db.AncientBlocks()
+			frozen = from
+			limit  = ancientLimit(head, threshold)
+			err    error
+		)
+		for frozen < limit {
+			next := frozen + freezeBatchBlocks
+			if next > limit {
+				next = limit
+			}
+			if frozen, err = bc.db.FreezeBlocks(next); err != nil {
+				logger.Error("Failed to move blocks to the ancient store", "frozen", frozen, "limit", next, "err", err)
+				break
+			}
+			select {
+			case <-bc.quit:
+				return
+			default:
+			}
+		}
+		if frozen > from {
+			logger.Info("Moved blocks to the ancient store", "from", from, "to", frozen-1, "blocks", frozen-from, "elapsed", time.Since(start))
+		}
+	}
+
+	freeze(bc.CurrentBlock().NumberU64())
+	for {
+		select {
+		case ev := <-headCh:
+			freeze(ev.Block.NumberU64())
+		case <-sub.Err():
+			return
+		case <-bc.quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAncientLimit(t *testing.T) {
+	assert.Equal(t, uint64(0), ancientLimit(10, 11))
+	assert.Equal(t, uint64(1), ancientLimit(10, 10))
+	assert.Equal(t, uint64(8), ancientLimit(10, 3))
+
+	assert.Equal(t, ErrAncientThresholdTooSmall, ValidateAncientThreshold(MinAncientThreshold-1))
+	assert.NoError(t, ValidateAncientThreshold(MinAncientThreshold))
+}
+
+func TestBlockChain_ChainFreezer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "klaytn-test-chain-freezer")
+	if err != nil {
+		t.Fatalf("cannot create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		db     = database.NewDBManager(&database.DBConfig{Dir: dir, DBType: database.LevelDB,
+			LevelDBCacheSize: 32, OpenFilesLimit: 32, AncientDir: "ancient"})
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{addr: {Balance: big.NewInt(10000000000000)}}}
+		genesis = gspec.MustCommit(db)
+		signer  = types.NewEIP155Signer(gspec.Config.ChainID)
+	)
+	defer db.Close()
+
+	// The threshold smaller than the minimum is only allowed in the tests
+	cacheConfig := &CacheConfig{
+		CacheSize:           512,
+		BlockInterval:       DefaultBlockInterval,
+		TriesInMemory:       DefaultTriesInMemory,
+		TrieNodeCacheConfig: statedb.GetEmptyTrieNodeCacheConfig(),
+		AncientThreshold:    3,
+	}
+	bc, err := NewBlockChain(db, cacheConfig, gspec.Config, gxhash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+
+	var txs types.Transactions
+	chain, _ := GenerateChain(gspec.Config, genesis, gxhash.NewFaker(), db, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), addr, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+		gen.AddTx(tx)
+		txs = append(txs, tx)
+	})
+	if _, err := bc.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100 && db.AncientBlocks() != 8; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, uint64(8), db.AncientBlocks())
+
+	// The frozen blocks are still served with their receipts and transactions
+	db.ClearBlockChainCache()
+	for _, block := range chain {
+		number := block.NumberU64()
+		assert.Equal(t, block.Hash(), bc.GetBlockByNumber(number).Hash())
+		assert.Len(t, bc.GetReceiptsByBlockHash(block.Hash()), 1)
+		tx, blockHash, _, _ := db.ReadTxAndLookupInfo(txs[number-1].Hash())
+		if assert.NotNil(t, tx) {
+			assert.Equal(t, block.Hash(), blockHash)
+		}
+	}
+}
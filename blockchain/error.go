@@ -86,14 +86,22 @@ var (
 
 	// ErrOversizedData is returned if the input data of a transaction is greater
 	// than some meaningful limit a user might use. This is not a consensus error
-	// making the transaction invalid, rather a DOS protection.
+	// making the transaction invalid, rather a DOS protection, unless the limit is
+	// set by the chain config.
 	ErrOversizedData = errors.New("oversized data")
 
 	// ErrInvlidUnitPrice is returned if gas price of transaction is not equal to UnitPrice
 	ErrInvalidUnitPrice = errors.New("invalid unit price")
 
 	// ErrInvalidChainId is returned if the chain id of transaction is not equal to the chain id of the chain config.
-	ErrInvalidChainId = errors.New("invalid chain id")
+	// It has a distinct JSON-RPC error code, so the clients can tell the transactions signed for another chain.
+	ErrInvalidChainId error = &codedError{"invalid chain id", InvalidChainIdErrorCode}
+
+	// ErrDeniedSender, ErrDeniedFeePayer and ErrDeniedRecipient are returned if the sender, the fee payer
+	// or the recipient of transaction is on the denylist of the node. They have a distinct JSON-RPC error code.
+	ErrDeniedSender    error = &codedError{"sender is on the denylist", DeniedTxErrorCode}
+	ErrDeniedFeePayer  error = &codedError{"fee payer is on the denylist", DeniedTxErrorCode}
+	ErrDeniedRecipient error = &codedError{"recipient is on the denylist", DeniedTxErrorCode}
 
 	// ErrNotYetImplementedAPI is returned if API is not yet implemented
 	ErrNotYetImplementedAPI = errors.New("not yet implemented API")
@@ -112,3 +120,18 @@ var (
 	// ErrInvalidTracer is returned if the tracer type is not vm.InternalTxTracer
 	ErrInvalidTracer = errors.New("tracer type is invalid for internal transaction tracing")
 )
+
+// InvalidChainIdErrorCode is the JSON-RPC error code of ErrInvalidChainId.
+const InvalidChainIdErrorCode = -32010
+
+// DeniedTxErrorCode is the JSON-RPC error code of the transactions refused by the denylist.
+const DeniedTxErrorCode = -32011
+
+// codedError is an error carrying a JSON-RPC error code.
+type codedError struct {
+	msg  string
+	code int
+}
+
+func (e *codedError) Error() string  { return e.msg }
+func (e *codedError) ErrorCode() int { return e.code }
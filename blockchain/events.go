@@ -56,3 +56,16 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// ChainReorgEvent is posted when the canonical chain is reorganized. The blocks are listed from
+// the head to the child of the common ancestor. The transactions included in both of the dropped
+// and the added blocks are listed in neither DroppedTxs nor AddedTxs.
+type ChainReorgEvent struct {
+	OldHead        *types.Header
+	NewHead        *types.Header
+	CommonAncestor *types.Header
+	DroppedBlocks  []common.Hash
+	AddedBlocks    []common.Hash
+	DroppedTxs     []common.Hash
+	AddedTxs       []common.Hash
+}
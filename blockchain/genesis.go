@@ -145,7 +145,7 @@ func findBlockWithState(db database.DBManager) *types.Block {
 	}
 
 	startBlock := headBlock
-	for _, err := state.New(headBlock.Root(), state.NewDatabase(db)); err != nil; {
+	for _, err := state.New(headBlock.Root(), state.NewDatabase(db), nil); err != nil; {
 		if headBlock.NumberU64() == 0 {
 			logger.Crit("failed to find state from the head block to the genesis block",
 				"headBlockNum", headBlock.NumberU64(),
@@ -288,7 +288,7 @@ func (g *Genesis) ToBlock(baseStateRoot common.Hash, db database.DBManager) *typ
 	if db == nil {
 		db = database.NewMemoryDBManager()
 	}
-	stateDB, _ := state.New(baseStateRoot, state.NewDatabase(db))
+	stateDB, _ := state.New(baseStateRoot, state.NewDatabase(db), nil)
 	for addr, account := range g.Alloc {
 		if len(account.Code) != 0 {
 			originalCode := stateDB.GetCode(addr)
@@ -393,6 +393,43 @@ func DefaultBaobabGenesisBlock() *Genesis {
 	return ret
 }
 
+// DeveloperGenesisBlock returns the genesis block of a single-validator network
+// used by the developer mode. The given accounts are prefunded with the given balance.
+// The genesis block is deterministic so that a persistent developer network can be restarted.
+func DeveloperGenesisBlock(validator common.Address, prefunded []common.Address, balance *big.Int) *Genesis {
+	config := &params.ChainConfig{
+		ChainID:       new(big.Int).SetUint64(params.DeveloperNetworkId),
+		UnitPrice:     params.DefaultUnitPrice,
+		DeriveShaImpl: 0,
+		Istanbul:      params.GetDefaultIstanbulConfig(),
+		Governance:    params.GetDefaultGovernanceConfig(params.UseIstanbul),
+	}
+	config.Governance.GoverningNode = validator
+
+	extra, err := rlp.EncodeToBytes(&types.IstanbulExtra{
+		Validators:    []common.Address{validator},
+		Seal:          make([]byte, types.IstanbulExtraSeal),
+		CommittedSeal: [][]byte{},
+	})
+	if err != nil {
+		logger.Crit("Failed to encode istanbul extra data", "err", err)
+	}
+
+	alloc := make(GenesisAlloc, len(prefunded))
+	for _, addr := range prefunded {
+		alloc[addr] = GenesisAccount{Balance: new(big.Int).Set(balance)}
+	}
+
+	genesis := &Genesis{
+		Config:     config,
+		ExtraData:  append(make([]byte, types.IstanbulExtraVanity), extra...),
+		BlockScore: common.Big1,
+		Alloc:      alloc,
+	}
+	genesis.Governance = SetGenesisGovernance(genesis)
+	return genesis
+}
+
 func decodePrealloc(data string) GenesisAlloc {
 	var p []struct{ Addr, Balance *big.Int }
 	if err := rlp.NewStream(strings.NewReader(data), 0).Decode(&p); err != nil {
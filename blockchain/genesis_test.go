@@ -26,6 +26,8 @@ import (
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/storage/database"
@@ -169,3 +171,40 @@ func TestSetupGenesis(t *testing.T) {
 		}
 	}
 }
+
+func TestDeveloperGenesisBlock(t *testing.T) {
+	validator := common.HexToAddress("0x1")
+	prefunded := []common.Address{common.HexToAddress("0x2"), common.HexToAddress("0x3")}
+	balance := big.NewInt(params.KLAY)
+
+	genesis := DeveloperGenesisBlock(validator, prefunded, balance)
+	block := genesis.ToBlock(common.Hash{}, nil)
+
+	// The developer genesis block should be deterministic to restart a persistent developer network.
+	if hash := DeveloperGenesisBlock(validator, prefunded, balance).ToBlock(common.Hash{}, nil).Hash(); hash != block.Hash() {
+		t.Errorf("developer genesis hash mismatch: have %v, want %v", hash, block.Hash())
+	}
+
+	istanbulExtra, err := types.ExtractIstanbulExtra(block.Header())
+	if err != nil {
+		t.Fatalf("failed to extract istanbul extra: %v", err)
+	}
+	if !reflect.DeepEqual(istanbulExtra.Validators, []common.Address{validator}) {
+		t.Errorf("validators mismatch: have %v, want %v", istanbulExtra.Validators, []common.Address{validator})
+	}
+	if genesis.Config.ChainID.Uint64() != params.DeveloperNetworkId {
+		t.Errorf("chain id mismatch: have %v, want %v", genesis.Config.ChainID, params.DeveloperNetworkId)
+	}
+
+	db := database.NewMemoryDBManager()
+	genesis.MustCommit(db)
+	statedb, err := state.New(block.Root(), state.NewDatabase(db), nil)
+	if err != nil {
+		t.Fatalf("failed to open genesis state: %v", err)
+	}
+	for _, addr := range prefunded {
+		if statedb.GetBalance(addr).Cmp(balance) != 0 {
+			t.Errorf("balance mismatch of %v: have %v, want %v", addr.String(), statedb.GetBalance(addr), balance)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus"
+	"github.com/klaytn/klaytn/params"
+	"github.com/rcrowley/go-metrics"
+)
+
+var (
+	parallelTxMeter           = metrics.NewRegisteredMeter("chain/parallel/txs", nil)
+	parallelReexecutedTxMeter = metrics.NewRegisteredMeter("chain/parallel/reexecutions", nil)
+	parallelFallbackMeter     = metrics.NewRegisteredMeter("chain/parallel/fallbacks", nil)
+	parallelNoFallbackMeter   = metrics.NewRegisteredMeter("chain/parallel/nofallbacks", nil)
+	parallelDependentTxMeter  = metrics.NewRegisteredMeter("chain/parallel/dependents", nil)
+)
+
+// ParallelStateProcessor is a Processor which executes the transactions of a block optimistically in parallel.
+//
+// Every transaction is speculatively executed on the state at the beginning of the block
+// while the pieces of state read and written by the transaction are recorded.
+// The results are committed in the order of the transactions. If a transaction did not read
+// any state written by the preceding transactions, its recorded state modifications are
+// replayed on the canonical state. Otherwise the transaction is re-executed on the canonical state.
+// Therefore the result is always the same as the one of StateProcessor.
+//
+// A transaction whose sender or fee payer pays for a preceding transaction of the block almost always
+// conflicts with it, so it is executed only on the canonical state without the speculation.
+//
+// ParallelStateProcessor implements Processor.
+type ParallelStateProcessor struct {
+	config  *params.ChainConfig // Chain configuration options
+	bc      *BlockChain         // Canonical block chain
+	engine  consensus.Engine    // Consensus engine used for block rewards
+	workers int                 // Number of goroutines executing transactions speculatively
+
+	serial *StateProcessor // Processor used if the parallel execution is not applicable
+}
+
+// txSpeculationTask is a transaction to be executed speculatively.
+type txSpeculationTask struct {
+	index   int
+	statedb *state.StateDB
+}
+
+// txSpeculation is the result of a speculative execution of a transaction.
+type txSpeculation struct {
+	db     *recordingStateDB
+	msg    *types.Transaction
+	status uint
+	gas    uint64
+	err    error
+}
+
+// NewParallelStateProcessor initialises a new ParallelStateProcessor.
+// If workers is not positive, the number of CPUs is used.
+func NewParallelStateProcessor(config *params.ChainConfig, bc *BlockChain, engine consensus.Engine, workers int) *ParallelStateProcessor {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return &ParallelStateProcessor{
+		config:  config,
+		bc:      bc,
+		engine:  engine,
+		workers: workers,
+		serial:  NewStateProcessor(config, bc, engine),
+	}
+}
+
+// Process processes the state changes according to the Klaytn rules by running
+// the transaction messages in parallel using the statedb and applying any rewards to the processor.
+//
+// Blocks are processed serially if the VM is traced, since tracers are not safe for concurrent use.
+func (p *ParallelStateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg vm.Config) (types.Receipts, []*types.Log, uint64, []*vm.InternalTxTrace, ProcessStats, error) {
+	txs := block.Transactions()
+	if cfg.Debug || cfg.EnableInternalTxTracing || len(txs) < 2 {
+		return p.serial.Process(block, statedb, cfg)
+	}
+
+	var (
+		receipts     types.Receipts
+		usedGas      uint64
+		header       = block.Header()
+		allLogs      []*types.Log
+		processStats ProcessStats
+	)
+
+	// Enable the opcode computation cost limit
+	cfg.UseOpcodeComputationCost = true
+
+	// Extract author from the header
+	author, _ := p.bc.Engine().Author(header) // Ignore error, we're past header validation
+
+	processStats.BeforeApplyTxs = time.Now()
+
+	// Execute the independent transactions speculatively on the copies of the state at the beginning of the block.
+	// A copy is made only if a slot of the window is available, and the slot is released after the result is
+	// committed, so that at most about p.workers copies of the state exist at once.
+	var (
+		dependent = dependentTxs(types.MakeSigner(p.config, header.Number), txs)
+		base      = statedb.Copy()
+		jobs      = make(chan *txSpeculationTask)
+		window    = make(chan struct{}, p.workers)
+		results   = make([]chan *txSpeculation, len(txs))
+		quit      = make(chan struct{})
+	)
+	defer close(quit)
+
+	for i := range results {
+		results[i] = make(chan *txSpeculation, 1)
+	}
+	for th := 0; th < p.workers; th++ {
+		go func() {
+			for task := range jobs {
+				select {
+				case <-quit:
+					return
+				default:
+				}
+				task.statedb.Prepare(txs[task.index].Hash(), block.Hash(), task.index)
+				results[task.index] <- p.execute(block, task.index, newRecordingStateDB(task.statedb), author, cfg)
+			}
+		}()
+	}
+	// Copying a state is not thread-safe, so the copies are made by a single goroutine
+	go func() {
+		defer close(jobs)
+		for i := range txs {
+			if dependent[i] {
+				results[i] <- nil
+				continue
+			}
+			select {
+			case <-quit:
+				return
+			case window <- struct{}{}:
+			}
+			select {
+			case <-quit:
+				return
+			case jobs <- &txSpeculationTask{index: i, statedb: base.Copy()}:
+			}
+		}
+	}()
+
+	// Commit the results in order
+	written := make(stateKeySet)
+	for i, tx := range txs {
+		spec := <-results[i]
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
+
+		if spec != nil && spec.err == nil && !spec.db.conflicts(written) {
+			spec.db.replay(statedb)
+		} else {
+			if spec == nil {
+				parallelDependentTxMeter.Mark(1)
+			} else {
+				parallelReexecutedTxMeter.Mark(1)
+			}
+			spec = p.execute(block, i, newRecordingStateDB(statedb), author, cfg)
+			if spec.err != nil {
+				return nil, nil, 0, nil, processStats, spec.err
+			}
+		}
+		written.merge(spec.db.writes)
+		if !dependent[i] {
+			<-window
+		}
+
+		// Update the state with pending changes
+		statedb.Finalise(true, false)
+		usedGas += spec.gas
+
+		receipt := types.NewReceipt(spec.status, tx.Hash(), spec.gas)
+		// if the transaction created a contract, store the creation address in the receipt.
+		spec.msg.FillContractAddress(spec.msg.ValidatedSender(), receipt)
+		// Set the receipt logs and create a bloom for filtering
+		receipt.Logs = statedb.GetLogs(tx.Hash())
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+
+		receipts = append(receipts, receipt)
+		allLogs = append(allLogs, receipt.Logs...)
+	}
+	parallelTxMeter.Mark(int64(len(txs)))
+	processStats.AfterApplyTxs = time.Now()
+
+	// Finalize the block, applying any consensus engine specific extras (e.g. block rewards)
+	if _, err := p.engine.Finalize(p.bc, header, statedb, txs, receipts); err != nil {
+		return nil, nil, 0, nil, processStats, err
+	}
+	processStats.AfterFinalize = time.Now()
+
+	// Internal transactions are not traced since the traced blocks are processed serially
+	return receipts, allLogs, usedGas, nil, processStats, nil
+}
+
+// dependentTxs returns whether each transaction is sent or paid by the sender or the fee payer of
+// a preceding transaction. Such a transaction mostly reads the nonce or the balance written by the preceding one.
+// The transactions whose senders are not recovered are considered independent.
+func dependentTxs(signer types.Signer, txs types.Transactions) []bool {
+	var (
+		dependent = make([]bool, len(txs))
+		payers    = make(map[common.Address]struct{})
+	)
+	for i, tx := range txs {
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			continue
+		}
+		feePayer, err := types.SenderFeePayer(signer, tx)
+		if err != nil {
+			continue
+		}
+		for _, addr := range []common.Address{from, feePayer} {
+			if _, ok := payers[addr]; ok {
+				dependent[i] = true
+			}
+		}
+		payers[from] = struct{}{}
+		payers[feePayer] = struct{}{}
+	}
+	return dependent
+}
+
+// execute executes the i-th transaction of the block on the given state.
+// It is the same as BlockChain.ApplyTransaction except that the state is not finalised.
+func (p *ParallelStateProcessor) execute(block *types.Block, i int, statedb *recordingStateDB, author common.Address, cfg vm.Config) *txSpeculation {
+	var (
+		tx          = block.Transactions()[i]
+		header      = block.Header()
+		blockNumber = header.Number.Uint64()
+		spec        = &txSpeculation{db: statedb}
+	)
+
+	// validation for each transaction before execution
+	if spec.err = tx.Validate(statedb, blockNumber); spec.err != nil {
+		return spec
+	}
+	spec.msg, spec.err = tx.AsMessageWithAccountKeyPicker(types.MakeSigner(p.config, header.Number), statedb, blockNumber)
+	if spec.err != nil {
+		return spec
+	}
+	context := NewEVMContext(spec.msg, header, p.bc, &author)
+	vmenv := vm.NewEVM(context, statedb, p.config, &cfg)
+
+	_, gas, kerr := ApplyMessage(vmenv, spec.msg)
+	spec.status, spec.gas, spec.err = kerr.Status, gas, kerr.ErrTxInvalid
+	return spec
+}
+
+// serialFallbackInterval is the minimum interval between the serial executions of the blocks whose parallel
+// results are rejected, so that peers cannot make every block executed twice by sending invalid blocks.
+const serialFallbackInterval = time.Second
+
+// allowSerialFallback returns whether a block whose parallel result is rejected can be processed serially
+// again now. It should be called with chainmu held.
+func (bc *BlockChain) allowSerialFallback() bool {
+	now := time.Now()
+	if now.Sub(bc.lastSerialFallback) < serialFallbackInterval {
+		parallelNoFallbackMeter.Mark(1)
+		logger.Warn("Skipped the serial processing of a block rejected by the parallel processing",
+			"interval", serialFallbackInterval)
+		return false
+	}
+	bc.lastSerialFallback = now
+	return true
+}
+
+// processSerially processes the block on the parent state again with StateProcessor and validates the result.
+// It is used if the state of ParallelStateProcessor is rejected, so that a block is rarely rejected
+// because of a flaw of the parallel execution.
+func (bc *BlockChain) processSerially(block, parent *types.Block) (*state.StateDB, types.Receipts, []*types.Log, uint64, []*vm.InternalTxTrace, ProcessStats, error) {
+	parallelFallbackMeter.Mark(1)
+
+	stateDB, err := bc.StateAt(parent.Root())
+	if err != nil {
+		return nil, nil, nil, 0, nil, ProcessStats{}, err
+	}
+	receipts, logs, usedGas, internalTxTraces, procStats, err := NewStateProcessor(bc.chainConfig, bc, bc.engine).Process(block, stateDB, bc.vmConfig)
+	if err != nil {
+		return stateDB, receipts, logs, usedGas, internalTxTraces, procStats, err
+	}
+	err = bc.validator.ValidateState(block, parent, stateDB, receipts, usedGas)
+	return stateDB, receipts, logs, usedGas, internalTxTraces, procStats, err
+}
@@ -0,0 +1,218 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests that ParallelStateProcessor produces the same result as StateProcessor
+// for blocks containing both independent and dependent transactions.
+func TestParallelStateProcessor(t *testing.T) {
+	var (
+		db    = database.NewMemoryDBManager()
+		keys  = make([]*ecdsa.PrivateKey, 4)
+		addrs = make([]common.Address, 4)
+		alloc = GenesisAlloc{}
+		// this code generates a log
+		code = common.Hex2Bytes("60606040525b7f24ec1d3ff24c2f6ff210738839dbc339cd45a5294d85c79361016243157aae7b60405180905060405180910390a15b600a8060416000396000f360606040526008565b00")
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+		alloc[addrs[i]] = GenesisAccount{Balance: big.NewInt(10000000000000)}
+	}
+	var (
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: alloc}
+		genesis = gspec.MustCommit(db)
+		signer  = types.NewEIP155Signer(gspec.Config.ChainID)
+	)
+	newTx := func(gen *BlockGen, from int, to common.Address, amount int64) *types.Transaction {
+		tx, err := types.SignTx(types.NewTransaction(gen.TxNonce(addrs[from]), to, big.NewInt(amount), params.TxGas, nil, nil), signer, keys[from])
+		if err != nil {
+			t.Fatalf("failed to create tx: %v", err)
+		}
+		return tx
+	}
+	blocks, receipts := GenerateChain(gspec.Config, genesis, gxhash.NewFaker(), db, 8, func(i int, gen *BlockGen) {
+		// Independent transfers
+		gen.AddTx(newTx(gen, 0, common.Address{0x01}, 1000))
+		gen.AddTx(newTx(gen, 1, common.Address{0x02}, 1000))
+		// Consecutive transactions of the same sender
+		gen.AddTx(newTx(gen, 2, common.Address{0x03}, 1000))
+		gen.AddTx(newTx(gen, 2, common.Address{0x03}, 1000))
+		// A transaction spending the balance transferred by a preceding transaction
+		key, _ := crypto.GenerateKey()
+		gen.AddTx(newTx(gen, 3, crypto.PubkeyToAddress(key.PublicKey), 10000))
+		tx, err := types.SignTx(types.NewTransaction(0, common.Address{0x04}, big.NewInt(5000), params.TxGas, nil, nil), signer, key)
+		if err != nil {
+			t.Fatalf("failed to create tx: %v", err)
+		}
+		gen.AddTx(tx)
+		// A contract creation emitting a log
+		tx, err = types.SignTx(types.NewContractCreation(gen.TxNonce(addrs[0]), new(big.Int), 1000000, new(big.Int), code), signer, keys[0])
+		if err != nil {
+			t.Fatalf("failed to create tx: %v", err)
+		}
+		gen.AddTx(tx)
+	})
+
+	bc, _ := NewBlockChain(db, nil, gspec.Config, gxhash.NewFaker(), vm.Config{})
+	defer bc.Stop()
+
+	processor := NewParallelStateProcessor(gspec.Config, bc, bc.engine, 4)
+	parent := genesis
+	for i, block := range blocks {
+		statedb, err := state.New(parent.Root(), bc.stateCache, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blockReceipts, _, usedGas, internalTxTraces, _, err := processor.Process(block, statedb, vm.Config{})
+		if err != nil {
+			t.Fatalf("block #%d: failed to process: %v", block.NumberU64(), err)
+		}
+		assert.Nil(t, internalTxTraces)
+		if err := bc.validator.ValidateState(block, parent, statedb, blockReceipts, usedGas); err != nil {
+			t.Fatalf("block #%d: invalid state: %v", block.NumberU64(), err)
+		}
+		assert.Equal(t, types.DeriveSha(receipts[i]), types.DeriveSha(blockReceipts))
+
+		if _, err := bc.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("block #%d: failed to insert: %v", block.NumberU64(), err)
+		}
+		parent = block
+	}
+}
+
+// Tests that the transactions sent or paid by the payers of the preceding transactions are dependent.
+func TestDependentTxs(t *testing.T) {
+	var (
+		signer            = types.NewEIP155Signer(params.TestChainConfig.ChainID)
+		key1, _           = crypto.GenerateKey()
+		key2, _           = crypto.GenerateKey()
+		addr1             = crypto.PubkeyToAddress(key1.PublicKey)
+		feePayer          = common.Address{0x0b}
+		unrelated         = common.Address{0x0c}
+		newFeeDelegatedTx = func(from, feePayer common.Address) *types.Transaction {
+			tx, err := types.NewTransactionWithMap(types.TxTypeFeeDelegatedValueTransfer, map[types.TxValueKeyType]interface{}{
+				types.TxValueKeyNonce:    uint64(0),
+				types.TxValueKeyTo:       unrelated,
+				types.TxValueKeyAmount:   big.NewInt(1),
+				types.TxValueKeyGasLimit: uint64(100000),
+				types.TxValueKeyGasPrice: big.NewInt(1),
+				types.TxValueKeyFrom:     from,
+				types.TxValueKeyFeePayer: feePayer,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return tx
+		}
+	)
+	tx1, _ := types.SignTx(types.NewTransaction(0, feePayer, big.NewInt(1), params.TxGas, nil, nil), signer, key1)
+	tx2, _ := types.SignTx(types.NewTransaction(0, addr1, big.NewInt(1), params.TxGas, nil, nil), signer, key2)
+	tx3, _ := types.SignTx(types.NewTransaction(1, unrelated, big.NewInt(1), params.TxGas, nil, nil), signer, key1)
+
+	txs := types.Transactions{
+		tx1,                                    // independent
+		tx2,                                    // independent even if it transfers to the sender of tx1
+		newFeeDelegatedTx(unrelated, feePayer), // independent even if the fee payer received from tx1
+		tx3,                                    // sent by the sender of tx1
+		newFeeDelegatedTx(common.Address{0x0d}, addr1),    // paid by the sender of tx1
+		newFeeDelegatedTx(common.Address{0x0e}, feePayer), // paid by the fee payer of the third transaction
+	}
+	assert.Equal(t, []bool{false, false, false, true, true, true}, dependentTxs(signer, txs))
+}
+
+// Tests that recordingStateDB detects the reads of the state written by preceding transactions.
+func TestRecordingStateDBConflicts(t *testing.T) {
+	var (
+		addr1 = common.Address{0x01}
+		addr2 = common.Address{0x02}
+		slot  = common.Hash{0x03}
+	)
+	newDB := func() *recordingStateDB {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
+		return newRecordingStateDB(statedb)
+	}
+
+	// Balance increments do not conflict with each other
+	tx1, tx2 := newDB(), newDB()
+	tx1.AddBalance(addr1, big.NewInt(1))
+	tx2.AddBalance(addr1, big.NewInt(1))
+	assert.False(t, tx2.conflicts(tx1.writes))
+
+	// Reading a written balance conflicts
+	tx2 = newDB()
+	tx2.GetBalance(addr1)
+	assert.True(t, tx2.conflicts(tx1.writes))
+
+	// Creating an account invalidates all the fields of the account
+	tx1, tx2 = newDB(), newDB()
+	tx1.CreateAccount(addr1)
+	tx2.GetState(addr1, slot)
+	assert.True(t, tx2.conflicts(tx1.writes))
+
+	// Storage slots are tracked separately
+	tx2 = newDB()
+	tx2.GetState(addr2, common.Hash{0x04})
+	written := stateKeySet{stateKey{addr: addr2, field: fieldStorage, slot: slot}: {}}
+	assert.False(t, tx2.conflicts(written))
+	tx2.GetState(addr2, slot)
+	assert.True(t, tx2.conflicts(written))
+
+	// Iterating the storage is always a conflict
+	tx2 = newDB()
+	tx2.ForEachStorage(addr2, func(common.Hash, common.Hash) bool { return true })
+	assert.True(t, tx2.conflicts(make(stateKeySet)))
+
+	// Replaying the recorded modifications reproduces the state
+	tx1 = newDB()
+	tx1.AddBalance(addr1, big.NewInt(10))
+	snapshot := tx1.Snapshot()
+	tx1.SetNonce(addr1, 5)
+	tx1.RevertToSnapshot(snapshot)
+	tx1.IncNonce(addr1)
+	target := newDB()
+	tx1.replay(target.StateDB)
+	assert.Equal(t, big.NewInt(10), target.StateDB.GetBalance(addr1))
+	assert.Equal(t, uint64(1), target.StateDB.GetNonce(addr1))
+}
+
+// Tests that the blocks rejected by the parallel processing are processed serially again at most once an interval.
+func TestBlockChain_allowSerialFallback(t *testing.T) {
+	bc := &BlockChain{}
+	assert.True(t, bc.allowSerialFallback())
+	assert.False(t, bc.allowSerialFallback())
+
+	bc.lastSerialFallback = time.Now().Add(-serialFallbackInterval)
+	assert.True(t, bc.allowSerialFallback())
+	assert.False(t, bc.allowSerialFallback())
+}
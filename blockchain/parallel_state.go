@@ -0,0 +1,341 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"math/big"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/types/accountkey"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+)
+
+// stateField is a part of an account which is tracked separately by recordingStateDB.
+type stateField uint8
+
+const (
+	// fieldAccount covers the existence, the type, the key and the suicide flag of an account.
+	// Writing it invalidates all the other fields of the account.
+	fieldAccount stateField = iota
+	fieldBalance
+	fieldNonce
+	fieldCode
+	fieldStorage
+)
+
+// stateKey identifies a piece of state read or written by a transaction.
+type stateKey struct {
+	addr  common.Address
+	field stateField
+	slot  common.Hash // only used by fieldStorage
+}
+
+// stateKeySet is a set of stateKeys.
+type stateKeySet map[stateKey]struct{}
+
+func (s stateKeySet) add(addr common.Address, field stateField) {
+	s[stateKey{addr: addr, field: field}] = struct{}{}
+}
+
+func (s stateKeySet) merge(other stateKeySet) {
+	for key := range other {
+		s[key] = struct{}{}
+	}
+}
+
+// stateOp is a state modification recorded during the speculative execution.
+// It is replayed on the canonical state if the execution turns out to be valid.
+// revisions maps the snapshot ids of the speculative state to the ones of the canonical state.
+type stateOp func(db vm.StateDB, revisions map[int]int)
+
+// recordingStateDB wraps a StateDB and records the keys read and written by a transaction
+// as well as the state modifications in the order they are made.
+//
+// Balance increments are recorded as writes without reads, so transactions only crediting
+// the same account (e.g., the transaction fee paid to the block proposer) do not conflict.
+type recordingStateDB struct {
+	*state.StateDB
+
+	reads  stateKeySet
+	writes stateKeySet
+	ops    []stateOp
+
+	// unsafe is set if the transaction accessed the state in a way which cannot be tracked.
+	unsafe bool
+}
+
+func newRecordingStateDB(db *state.StateDB) *recordingStateDB {
+	return &recordingStateDB{
+		StateDB: db,
+		reads:   make(stateKeySet),
+		writes:  make(stateKeySet),
+	}
+}
+
+// conflicts returns true if the transaction read a piece of state written by the given keys.
+func (db *recordingStateDB) conflicts(written stateKeySet) bool {
+	if db.unsafe {
+		return true
+	}
+	for key := range db.reads {
+		if _, ok := written[key]; ok {
+			return true
+		}
+		if _, ok := written[stateKey{addr: key.addr, field: fieldAccount}]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// replay applies the recorded state modifications to the given StateDB.
+func (db *recordingStateDB) replay(target vm.StateDB) {
+	revisions := make(map[int]int)
+	for _, op := range db.ops {
+		op(target, revisions)
+	}
+}
+
+func (db *recordingStateDB) read(addr common.Address, fields ...stateField) {
+	for _, field := range fields {
+		db.reads.add(addr, field)
+	}
+}
+
+func (db *recordingStateDB) write(addr common.Address, fields ...stateField) {
+	for _, field := range fields {
+		db.writes.add(addr, field)
+	}
+}
+
+// writeNew marks the account as written if it does not exist, since the modification creates it.
+func (db *recordingStateDB) writeNew(addr common.Address) {
+	if !db.StateDB.Exist(addr) {
+		db.write(addr, fieldAccount)
+	}
+}
+
+func (db *recordingStateDB) record(op stateOp) {
+	db.ops = append(db.ops, op)
+}
+
+func (db *recordingStateDB) CreateAccount(addr common.Address) {
+	// The balance of the previous account is carried over to the new account.
+	db.read(addr, fieldAccount, fieldBalance)
+	db.write(addr, fieldAccount)
+	db.StateDB.CreateAccount(addr)
+	db.record(func(target vm.StateDB, _ map[int]int) { target.CreateAccount(addr) })
+}
+
+func (db *recordingStateDB) CreateSmartContractAccount(addr common.Address, format params.CodeFormat, r params.Rules) {
+	db.read(addr, fieldAccount, fieldBalance)
+	db.write(addr, fieldAccount)
+	db.StateDB.CreateSmartContractAccount(addr, format, r)
+	db.record(func(target vm.StateDB, _ map[int]int) { target.CreateSmartContractAccount(addr, format, r) })
+}
+
+func (db *recordingStateDB) CreateSmartContractAccountWithKey(addr common.Address, humanReadable bool, key accountkey.AccountKey, format params.CodeFormat, r params.Rules) {
+	db.read(addr, fieldAccount, fieldBalance)
+	db.write(addr, fieldAccount)
+	db.StateDB.CreateSmartContractAccountWithKey(addr, humanReadable, key, format, r)
+	db.record(func(target vm.StateDB, _ map[int]int) {
+		target.CreateSmartContractAccountWithKey(addr, humanReadable, key, format, r)
+	})
+}
+
+func (db *recordingStateDB) CreateEOA(addr common.Address, humanReadable bool, key accountkey.AccountKey) {
+	db.read(addr, fieldAccount, fieldBalance)
+	db.write(addr, fieldAccount)
+	db.StateDB.CreateEOA(addr, humanReadable, key)
+	db.record(func(target vm.StateDB, _ map[int]int) { target.CreateEOA(addr, humanReadable, key) })
+}
+
+func (db *recordingStateDB) SubBalance(addr common.Address, amount *big.Int) {
+	// The amount is copied since the interpreter may reuse the big.Int.
+	amount = new(big.Int).Set(amount)
+	db.writeNew(addr)
+	db.write(addr, fieldBalance)
+	db.StateDB.SubBalance(addr, amount)
+	db.record(func(target vm.StateDB, _ map[int]int) { target.SubBalance(addr, amount) })
+}
+
+func (db *recordingStateDB) AddBalance(addr common.Address, amount *big.Int) {
+	amount = new(big.Int).Set(amount)
+	db.writeNew(addr)
+	db.write(addr, fieldBalance)
+	db.StateDB.AddBalance(addr, amount)
+	db.record(func(target vm.StateDB, _ map[int]int) { target.AddBalance(addr, amount) })
+}
+
+func (db *recordingStateDB) GetBalance(addr common.Address) *big.Int {
+	db.read(addr, fieldBalance)
+	return db.StateDB.GetBalance(addr)
+}
+
+func (db *recordingStateDB) GetNonce(addr common.Address) uint64 {
+	db.read(addr, fieldNonce)
+	return db.StateDB.GetNonce(addr)
+}
+
+func (db *recordingStateDB) IncNonce(addr common.Address) {
+	db.read(addr, fieldNonce)
+	db.writeNew(addr)
+	db.write(addr, fieldNonce)
+	db.StateDB.IncNonce(addr)
+	db.record(func(target vm.StateDB, _ map[int]int) { target.IncNonce(addr) })
+}
+
+func (db *recordingStateDB) SetNonce(addr common.Address, nonce uint64) {
+	db.writeNew(addr)
+	db.write(addr, fieldNonce)
+	db.StateDB.SetNonce(addr, nonce)
+	db.record(func(target vm.StateDB, _ map[int]int) { target.SetNonce(addr, nonce) })
+}
+
+func (db *recordingStateDB) GetCodeHash(addr common.Address) common.Hash {
+	db.read(addr, fieldCode)
+	return db.StateDB.GetCodeHash(addr)
+}
+
+func (db *recordingStateDB) GetCode(addr common.Address) []byte {
+	db.read(addr, fieldCode)
+	return db.StateDB.GetCode(addr)
+}
+
+func (db *recordingStateDB) SetCode(addr common.Address, code []byte) error {
+	// SetCode fails if the account is not a program account.
+	db.read(addr, fieldAccount)
+	db.writeNew(addr)
+	db.write(addr, fieldCode)
+	code = common.CopyBytes(code)
+	if err := db.StateDB.SetCode(addr, code); err != nil {
+		return err
+	}
+	db.record(func(target vm.StateDB, _ map[int]int) { target.SetCode(addr, code) })
+	return nil
+}
+
+func (db *recordingStateDB) GetCodeSize(addr common.Address) int {
+	db.read(addr, fieldCode)
+	return db.StateDB.GetCodeSize(addr)
+}
+
+func (db *recordingStateDB) GetVmVersion(addr common.Address) (params.VmVersion, bool) {
+	db.read(addr, fieldCode)
+	return db.StateDB.GetVmVersion(addr)
+}
+
+func (db *recordingStateDB) AddRefund(gas uint64) {
+	db.StateDB.AddRefund(gas)
+	db.record(func(target vm.StateDB, _ map[int]int) { target.AddRefund(gas) })
+}
+
+func (db *recordingStateDB) GetState(addr common.Address, hash common.Hash) common.Hash {
+	db.reads[stateKey{addr: addr, field: fieldStorage, slot: hash}] = struct{}{}
+	return db.StateDB.GetState(addr, hash)
+}
+
+func (db *recordingStateDB) SetState(addr common.Address, key, value common.Hash) {
+	db.writeNew(addr)
+	db.writes[stateKey{addr: addr, field: fieldStorage, slot: key}] = struct{}{}
+	db.StateDB.SetState(addr, key, value)
+	db.record(func(target vm.StateDB, _ map[int]int) { target.SetState(addr, key, value) })
+}
+
+func (db *recordingStateDB) Suicide(addr common.Address) bool {
+	db.read(addr, fieldAccount)
+	db.write(addr, fieldAccount)
+	suicided := db.StateDB.Suicide(addr)
+	db.record(func(target vm.StateDB, _ map[int]int) { target.Suicide(addr) })
+	return suicided
+}
+
+func (db *recordingStateDB) HasSuicided(addr common.Address) bool {
+	db.read(addr, fieldAccount)
+	return db.StateDB.HasSuicided(addr)
+}
+
+func (db *recordingStateDB) UpdateKey(addr common.Address, newKey accountkey.AccountKey, currentBlockNumber uint64) error {
+	db.read(addr, fieldAccount)
+	db.write(addr, fieldAccount)
+	if err := db.StateDB.UpdateKey(addr, newKey, currentBlockNumber); err != nil {
+		return err
+	}
+	db.record(func(target vm.StateDB, _ map[int]int) { target.UpdateKey(addr, newKey, currentBlockNumber) })
+	return nil
+}
+
+func (db *recordingStateDB) Exist(addr common.Address) bool {
+	db.read(addr, fieldAccount)
+	return db.StateDB.Exist(addr)
+}
+
+func (db *recordingStateDB) Empty(addr common.Address) bool {
+	db.read(addr, fieldAccount, fieldBalance, fieldNonce, fieldCode)
+	return db.StateDB.Empty(addr)
+}
+
+func (db *recordingStateDB) Snapshot() int {
+	id := db.StateDB.Snapshot()
+	db.record(func(target vm.StateDB, revisions map[int]int) { revisions[id] = target.Snapshot() })
+	return id
+}
+
+func (db *recordingStateDB) RevertToSnapshot(revid int) {
+	db.StateDB.RevertToSnapshot(revid)
+	db.record(func(target vm.StateDB, revisions map[int]int) { target.RevertToSnapshot(revisions[revid]) })
+}
+
+func (db *recordingStateDB) AddLog(log *types.Log) {
+	db.StateDB.AddLog(log)
+	db.record(func(target vm.StateDB, _ map[int]int) { target.AddLog(log) })
+}
+
+func (db *recordingStateDB) AddPreimage(hash common.Hash, preimage []byte) {
+	preimage = common.CopyBytes(preimage)
+	db.StateDB.AddPreimage(hash, preimage)
+	db.record(func(target vm.StateDB, _ map[int]int) { target.AddPreimage(hash, preimage) })
+}
+
+func (db *recordingStateDB) IsProgramAccount(addr common.Address) bool {
+	db.read(addr, fieldAccount)
+	return db.StateDB.IsProgramAccount(addr)
+}
+
+func (db *recordingStateDB) IsContractAvailable(addr common.Address) bool {
+	db.read(addr, fieldCode)
+	return db.StateDB.IsContractAvailable(addr)
+}
+
+func (db *recordingStateDB) IsValidCodeFormat(addr common.Address) bool {
+	db.read(addr, fieldCode)
+	return db.StateDB.IsValidCodeFormat(addr)
+}
+
+func (db *recordingStateDB) ForEachStorage(addr common.Address, cb func(common.Hash, common.Hash) bool) {
+	// The set of the iterated storage slots cannot be tracked.
+	db.unsafe = true
+	db.StateDB.ForEachStorage(addr, cb)
+}
+
+func (db *recordingStateDB) GetKey(addr common.Address) accountkey.AccountKey {
+	db.read(addr, fieldAccount)
+	return db.StateDB.GetKey(addr)
+}
@@ -40,6 +40,7 @@ Related functions and variables are defined in the files listed below
   - state_object.go          : Implementation of stateObject
   - state_object_encoder.go  : stateObjectEncoder is used to encode stateObject in parallel manner
   - statedb.go               : Implementation of StateDB
+  - statediff.go             : Functions to collect the account and storage changes of a transaction from the journal
   - sync.go                  : Functions to schedule a state trie download
 */
 package state
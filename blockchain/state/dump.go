@@ -23,9 +23,11 @@ package state
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
 
 	"github.com/klaytn/klaytn/blockchain/types/account"
 	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
 	"github.com/klaytn/klaytn/rlp"
 	"github.com/klaytn/klaytn/storage/statedb"
 )
@@ -49,6 +51,13 @@ type Dump struct {
 	Accounts map[string]DumpAccount `json:"accounts"`
 }
 
+// RangeDump is a part of the accounts of the state, which is sorted by the hashes of the addresses.
+type RangeDump struct {
+	Root     string                 `json:"root"`
+	Accounts map[string]DumpAccount `json:"accounts"`
+	Next     hexutil.Bytes          `json:"next,omitempty"` // hash of the address of the next account, nil if it is the last part
+}
+
 func (self *StateDB) RawDump() Dump {
 	dump := Dump{
 		Root:     fmt.Sprintf("%x", self.trie.Hash()),
@@ -58,37 +67,80 @@ func (self *StateDB) RawDump() Dump {
 	it := statedb.NewIterator(self.trie.NodeIterator(nil))
 	for it.Next() {
 		addr := self.trie.GetKey(it.Key)
+		dump.Accounts[common.Bytes2Hex(addr)] = self.dumpAccount(addr, it.Value, false, false)
+	}
+	return dump
+}
+
+// RangeDump returns at most maxResults accounts starting from the given hash of an address.
+// The code and the storage of the accounts are omitted if excludeCode and excludeStorage are set.
+func (self *StateDB) RangeDump(start []byte, maxResults int, excludeCode, excludeStorage bool) RangeDump {
+	dump := RangeDump{
+		Root:     fmt.Sprintf("%x", self.trie.Hash()),
+		Accounts: make(map[string]DumpAccount),
+	}
+
+	it := statedb.NewIterator(self.trie.NodeIterator(start))
+	for i := 0; i < maxResults && it.Next(); i++ {
+		addr := self.trie.GetKey(it.Key)
+		dump.Accounts[common.Bytes2Hex(addr)] = self.dumpAccount(addr, it.Value, excludeCode, excludeStorage)
+	}
+	// Add the next key so clients can continue iterating.
+	if it.Next() {
+		dump.Next = common.CopyBytes(it.Key)
+	}
+	return dump
+}
+
+// TotalBalance returns the sum of the balances of all the accounts in the state.
+func (self *StateDB) TotalBalance() (*big.Int, error) {
+	total := new(big.Int)
+	it := statedb.NewIterator(self.trie.NodeIterator(nil))
+	for it.Next() {
 		serializer := account.NewAccountSerializer()
 		if err := rlp.DecodeBytes(it.Value, serializer); err != nil {
-			panic(err)
-		}
-		data := serializer.GetAccount()
-
-		obj := self.getStateObject(common.BytesToAddress(addr))
-		acc := DumpAccount{
-			Balance:  data.GetBalance().String(),
-			Nonce:    data.GetNonce(),
-			Root:     common.Bytes2Hex([]byte{}),
-			CodeHash: common.Bytes2Hex([]byte{}),
-			Code:     common.Bytes2Hex([]byte{}),
-			Storage:  make(map[string]string),
+			return nil, err
 		}
-		if pa := account.GetProgramAccount(data); pa != nil {
-			acc.Root = common.Bytes2Hex(pa.GetStorageRoot().Bytes())
-			acc.CodeHash = common.Bytes2Hex(pa.GetCodeHash())
+		total.Add(total, serializer.GetAccount().GetBalance())
+	}
+	return total, it.Err
+}
+
+// dumpAccount returns the DumpAccount of the given address and its RLP-encoded account.
+func (self *StateDB) dumpAccount(addr []byte, enc []byte, excludeCode, excludeStorage bool) DumpAccount {
+	serializer := account.NewAccountSerializer()
+	if err := rlp.DecodeBytes(enc, serializer); err != nil {
+		panic(err)
+	}
+	data := serializer.GetAccount()
+
+	obj := self.getStateObject(common.BytesToAddress(addr))
+	acc := DumpAccount{
+		Balance:  data.GetBalance().String(),
+		Nonce:    data.GetNonce(),
+		Root:     common.Bytes2Hex([]byte{}),
+		CodeHash: common.Bytes2Hex([]byte{}),
+		Code:     common.Bytes2Hex([]byte{}),
+		Storage:  make(map[string]string),
+	}
+	if pa := account.GetProgramAccount(data); pa != nil {
+		acc.Root = common.Bytes2Hex(pa.GetStorageRoot().Bytes())
+		acc.CodeHash = common.Bytes2Hex(pa.GetCodeHash())
+		if !excludeCode {
 			acc.Code = common.Bytes2Hex(obj.Code(self.db))
-		} else {
-			acc.Root = common.Bytes2Hex(emptyRoot.Bytes())
-			acc.CodeHash = common.Bytes2Hex(emptyCodeHash)
 		}
+	} else {
+		acc.Root = common.Bytes2Hex(emptyRoot.Bytes())
+		acc.CodeHash = common.Bytes2Hex(emptyCodeHash)
+	}
+	if !excludeStorage {
 		storageTrie := obj.getStorageTrie(self.db)
 		storageIt := statedb.NewIterator(storageTrie.NodeIterator(nil))
 		for storageIt.Next() {
 			acc.Storage[common.Bytes2Hex(storageTrie.GetKey(storageIt.Key))] = common.Bytes2Hex(storageIt.Value)
 		}
-		dump.Accounts[common.Bytes2Hex(addr)] = acc
 	}
-	return dump
+	return acc
 }
 
 func (self *StateDB) Dump() []byte {
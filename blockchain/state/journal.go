@@ -94,7 +94,9 @@ type (
 		account *common.Address
 	}
 	resetObjectChange struct {
-		prev *stateObject
+		prev         *stateObject
+		prevdestruct bool
+		prevStorage  map[common.Hash][]byte
 	}
 	suicideChange struct {
 		account     *common.Address
@@ -148,6 +150,14 @@ func (ch createObjectChange) dirtied() *common.Address {
 
 func (ch resetObjectChange) revert(s *StateDB) {
 	s.setStateObject(ch.prev)
+	if s.snap != nil {
+		if !ch.prevdestruct {
+			delete(s.snapDestructs, ch.prev.addrHash)
+		}
+		if ch.prevStorage != nil {
+			s.snapStorage[ch.prev.addrHash] = ch.prevStorage
+		}
+	}
 }
 
 func (ch resetObjectChange) dirtied() *common.Address {
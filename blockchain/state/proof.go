@@ -0,0 +1,68 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"errors"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/storage/database"
+)
+
+var errNoStorageTrie = errors.New("storage trie for requested address does not exist")
+
+// proofList collects the encoded nodes of a Merkle proof from the root in order.
+// Only WriteMerkleProof of the embedded DBManager is implemented, which is the only
+// method called by Trie.Prove.
+type proofList struct {
+	database.DBManager
+	nodes [][]byte
+}
+
+func (l *proofList) WriteMerkleProof(key, value []byte) {
+	l.nodes = append(l.nodes, value)
+}
+
+// GetProof returns the Merkle proof of the account at the address in the state trie.
+// The proof of a non-existent account proves its absence.
+func (self *StateDB) GetProof(addr common.Address) ([][]byte, error) {
+	proof := &proofList{}
+	err := self.trie.Prove(crypto.Keccak256(addr.Bytes()), 0, proof)
+	return proof.nodes, err
+}
+
+// GetStorageProof returns the Merkle proof of the storage slot in the storage trie of the account.
+func (self *StateDB) GetStorageProof(addr common.Address, key common.Hash) ([][]byte, error) {
+	trie := self.StorageTrie(addr)
+	if trie == nil {
+		return nil, errNoStorageTrie
+	}
+	proof := &proofList{}
+	err := trie.Prove(crypto.Keccak256(key.Bytes()), 0, proof)
+	return proof.nodes, err
+}
+
+// GetStorageHash returns the root hash of the storage trie of the account. It returns the root hash
+// of the empty trie for the accounts without storage and the non-existent accounts.
+func (self *StateDB) GetStorageHash(addr common.Address) common.Hash {
+	trie := self.StorageTrie(addr)
+	if trie == nil {
+		return emptyRoot
+	}
+	return trie.Hash()
+}
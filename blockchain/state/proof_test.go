@@ -0,0 +1,94 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types/account"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/stretchr/testify/assert"
+)
+
+// verifyProof verifies the proof nodes against the root hash and returns the proven value.
+func verifyProof(t *testing.T, root common.Hash, key []byte, proof [][]byte) []byte {
+	proofDB := database.NewMemoryDBManager()
+	for _, node := range proof {
+		proofDB.WriteMerkleProof(crypto.Keccak256(node), node)
+	}
+	value, err, _ := statedb.VerifyProof(root, crypto.Keccak256(key), proofDB)
+	assert.NoError(t, err)
+	return value
+}
+
+func TestStateDBProof(t *testing.T) {
+	db := NewDatabase(database.NewMemoryDBManager())
+	s, _ := New(common.Hash{}, db, nil)
+
+	eoa, contract, missing := common.Address{1}, common.Address{2}, common.Address{3}
+	s.AddBalance(eoa, big.NewInt(100))
+	s.CreateSmartContractAccount(contract, params.CodeFormatEVM, params.Rules{})
+	s.SetCode(contract, []byte{0x60, 0x00})
+	for i := int64(1); i <= 20; i++ {
+		s.SetState(contract, common.BigToHash(big.NewInt(i)), common.BigToHash(big.NewInt(i*2)))
+	}
+	root, err := s.Commit(false)
+	assert.NoError(t, err)
+	s, err = New(root, db, nil)
+	assert.NoError(t, err)
+
+	// The accounts are proven in their serialized format
+	for _, addr := range []common.Address{eoa, contract} {
+		proof, err := s.GetProof(addr)
+		assert.NoError(t, err)
+		value := verifyProof(t, root, addr.Bytes(), proof)
+		serializer := account.NewAccountSerializer()
+		assert.NoError(t, rlp.DecodeBytes(value, serializer))
+		assert.Equal(t, s.GetBalance(addr), serializer.GetAccount().GetBalance())
+	}
+
+	// The absence of the account is proven
+	proof, err := s.GetProof(missing)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, proof)
+	assert.Nil(t, verifyProof(t, root, missing.Bytes(), proof))
+	_, err = s.GetStorageProof(missing, common.Hash{})
+	assert.Equal(t, errNoStorageTrie, err)
+	assert.Equal(t, emptyRoot, s.GetStorageHash(missing))
+	assert.Equal(t, emptyRoot, s.GetStorageHash(eoa))
+
+	// The storage slots are proven against the storage root of the contract
+	storageRoot := s.GetStorageHash(contract)
+	assert.NotEqual(t, emptyRoot, storageRoot)
+	key := common.BigToHash(big.NewInt(7))
+	proof, err = s.GetStorageProof(contract, key)
+	assert.NoError(t, err)
+	value := verifyProof(t, storageRoot, key.Bytes(), proof)
+	expected, _ := rlp.EncodeToBytes(big.NewInt(14).Bytes())
+	assert.Equal(t, expected, value)
+
+	key = common.BigToHash(big.NewInt(100))
+	proof, err = s.GetStorageProof(contract, key)
+	assert.NoError(t, err)
+	assert.Nil(t, verifyProof(t, storageRoot, key.Bytes(), proof))
+}
@@ -0,0 +1,353 @@
+// Modifications Copyright 2021 The klaytn Authors
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+//
+// This file is derived from core/state/snapshot/generate.go (2021/03/02).
+// Modified and improved for the klaytn development.
+
+package snapshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"time"
+
+	"github.com/VictoriaMetrics/fastcache"
+	"github.com/klaytn/klaytn/blockchain/types/account"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+)
+
+// emptyRoot is the known root hash of an empty trie.
+var emptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+
+// journalGenerator is a disk layer entry containing the generator progress marker.
+type journalGenerator struct {
+	Wiping   bool // Whether the database was in progress of being wiped
+	Done     bool // Whether the generator finished creating the snapshot
+	Marker   []byte
+	Accounts uint64
+	Slots    uint64
+	Storage  uint64
+}
+
+// generatorStats is a collection of statistics gathered by the snapshot generator
+// for logging purposes.
+type generatorStats struct {
+	wiping   bool               // Whether the previous snapshot is being wiped
+	origin   uint64             // Origin prefix where generation started
+	start    time.Time          // Timestamp when generation started
+	accounts uint64             // Number of accounts indexed
+	slots    uint64             // Number of storage slots indexed
+	storage  common.StorageSize // Account and storage slot size
+}
+
+// Log creates an contextual log with the given message and the context pulled
+// from the internally maintained statistics.
+func (gs *generatorStats) Log(msg string, root common.Hash, marker []byte) {
+	var ctx []interface{}
+	if root != (common.Hash{}) {
+		ctx = append(ctx, []interface{}{"root", root}...)
+	}
+	// Figure out whether we're after or within an account
+	switch len(marker) {
+	case common.HashLength:
+		ctx = append(ctx, []interface{}{"at", common.BytesToHash(marker)}...)
+	case 2 * common.HashLength:
+		ctx = append(ctx, []interface{}{
+			"in", common.BytesToHash(marker[:common.HashLength]),
+			"at", common.BytesToHash(marker[common.HashLength:]),
+		}...)
+	}
+	// Add the usual measurements
+	ctx = append(ctx, []interface{}{
+		"accounts", gs.accounts,
+		"slots", gs.slots,
+		"storage", gs.storage,
+		"elapsed", common.PrettyDuration(time.Since(gs.start)),
+	}...)
+	// Calculate the estimated indexing time based on current stats
+	if len(marker) > 0 {
+		if done := binary.BigEndian.Uint64(marker[:8]) - gs.origin; done > 0 {
+			left := math.MaxUint64 - binary.BigEndian.Uint64(marker[:8])
+
+			speed := done/uint64(time.Since(gs.start)/time.Millisecond+1) + 1 // +1s to avoid division by zero
+			ctx = append(ctx, []interface{}{
+				"eta", common.PrettyDuration(time.Duration(left/speed) * time.Millisecond),
+			}...)
+		}
+	}
+	logger.Info(msg, ctx...)
+}
+
+// originOf returns the 8 byte prefix of the marker from which the generation
+// progress is estimated.
+func originOf(marker []byte) uint64 {
+	if len(marker) < 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(marker[:8])
+}
+
+// generateSnapshot regenerates a brand new snapshot based on an existing state
+// database and head block asynchronously. The snapshot is returned immediately
+// and generation is continued in the background until done.
+func generateSnapshot(diskdb database.DBManager, triedb *statedb.Database, cache int, root common.Hash) *diskLayer {
+	// Wipe any previously existing snapshot from the database, and create a new
+	// generator with the wiping flag on so that the wiping is resumed on restart.
+	var (
+		stats = &generatorStats{wiping: true, start: time.Now()}
+		batch = diskdb.NewSnapshotDBBatch()
+	)
+	diskdb.PutSnapshotRootToBatch(batch, root)
+	journalProgress(diskdb, batch, []byte{}, stats)
+	if err := batch.Write(); err != nil {
+		logger.Crit("Failed to write initialized state marker", "err", err)
+	}
+	base := &diskLayer{
+		diskdb:     diskdb,
+		triedb:     triedb,
+		root:       root,
+		cache:      fastcache.New(cache * 1024 * 1024),
+		genMarker:  []byte{}, // Initialized but empty!
+		genPending: make(chan struct{}),
+		genAbort:   make(chan chan *generatorStats),
+	}
+	go base.generate(stats)
+	logger.Debug("Start snapshot generation", "root", root)
+	return base
+}
+
+// journalProgress persists the generator stats into a batch.
+func journalProgress(diskdb database.DBManager, batch database.Batch, marker []byte, stats *generatorStats) {
+	// Write out the generator marker. Note it's a standalone disk layer generator
+	// which is not mixed with journal. It's ok if the generator is persisted while
+	// journal is not.
+	entry := journalGenerator{
+		Done:   marker == nil,
+		Marker: marker,
+	}
+	if stats != nil {
+		entry.Wiping = stats.wiping
+		entry.Accounts = stats.accounts
+		entry.Slots = stats.slots
+		entry.Storage = uint64(stats.storage)
+	}
+	blob, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		panic(err) // Cannot happen, here to catch dev errors
+	}
+	diskdb.PutSnapshotGeneratorToBatch(batch, blob)
+}
+
+// wipe deletes all the accounts and storage slots of the previous snapshot. It
+// returns the abort request if the wiping was interrupted.
+func (dl *diskLayer) wipe() chan *generatorStats {
+	for _, w := range []struct {
+		prefix []byte
+		keylen int
+	}{
+		{database.SnapshotAccountPrefix, len(database.SnapshotAccountPrefix) + common.HashLength},
+		{database.SnapshotStoragePrefix, len(database.SnapshotStoragePrefix) + 2*common.HashLength},
+	} {
+		batch := dl.diskdb.NewSnapshotDBBatch()
+		it := dl.diskdb.NewSnapshotDBIterator(w.prefix, nil)
+		for it.Next() {
+			// Skip any keys with the correct prefix but wrong length (trie nodes)
+			key := it.Key()
+			if len(key) != w.keylen {
+				continue
+			}
+			batch.Delete(key)
+			if batch.ValueSize() > database.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					logger.Crit("Failed to wipe state snapshot", "err", err)
+				}
+				batch.Reset()
+
+				select {
+				case abort := <-dl.genAbort:
+					it.Release()
+					return abort
+				default:
+				}
+			}
+		}
+		it.Release()
+
+		if err := batch.Write(); err != nil {
+			logger.Crit("Failed to wipe state snapshot", "err", err)
+		}
+	}
+	return nil
+}
+
+// generate is a background thread that iterates over the state and storage tries
+// and constructs a state snapshot. All the arguments are purely for statistics
+// gathering and logging, since the method surfs the blocks as they arrive, often
+// being restarted.
+func (dl *diskLayer) generate(stats *generatorStats) {
+	// If a database wipe is in operation, finish it before generating
+	if stats.wiping {
+		if abort := dl.wipe(); abort != nil {
+			stats.Log("Aborting state snapshot wiping", dl.root, nil)
+			abort <- stats
+			return
+		}
+		stats.wiping = false
+	}
+	// Create an account and state iterator pointing to the current generator marker
+	accTrie, err := statedb.NewSecureTrie(dl.root, dl.triedb)
+	if err != nil {
+		// The account trie is missing (GC), surf the chain until one becomes available
+		stats.Log("Trie missing, state snapshotting paused", dl.root, dl.genMarker)
+
+		abort := <-dl.genAbort
+		abort <- stats
+		return
+	}
+	stats.Log("Resuming state snapshot generation", dl.root, dl.genMarker)
+
+	dl.lock.RLock()
+	genMarker := dl.genMarker
+	dl.lock.RUnlock()
+
+	var accMarker []byte
+	if len(genMarker) > 0 { // []byte{} is the start, use nil for that
+		accMarker = genMarker[:common.HashLength]
+	}
+	var (
+		accIt  = statedb.NewIterator(accTrie.NodeIterator(accMarker))
+		batch  = dl.diskdb.NewSnapshotDBBatch()
+		logged = time.Now()
+	)
+	// flush writes out the batch together with the progress and advances the
+	// marker of the disk layer. If the generation was requested to be aborted,
+	// it replies the stats and returns true.
+	flush := func(marker []byte) bool {
+		var abort chan *generatorStats
+		select {
+		case abort = <-dl.genAbort:
+		default:
+		}
+		if batch.ValueSize() > database.IdealBatchSize || abort != nil {
+			// Only set the marker if we actually did something useful
+			if batch.ValueSize() > 0 {
+				dl.lock.Lock()
+				dl.genMarker = marker
+				dl.lock.Unlock()
+			} else {
+				marker = genMarker
+			}
+			// Ensure the generator entry is in sync with the data
+			journalProgress(dl.diskdb, batch, marker, stats)
+			if err := batch.Write(); err != nil {
+				logger.Crit("Failed to write state snapshot", "err", err)
+			}
+			batch.Reset()
+			genMarker = marker
+		}
+		if abort != nil {
+			stats.Log("Aborting state snapshot generation", dl.root, marker)
+			abort <- stats
+			return true
+		}
+		return false
+	}
+	for accIt.Next() {
+		accountHash := common.BytesToHash(accIt.Key)
+
+		serializer := account.NewAccountSerializer()
+		if err := rlp.DecodeBytes(accIt.Value, serializer); err != nil {
+			logger.Crit("Invalid account encountered during snapshot creation", "err", err)
+		}
+		dl.diskdb.PutAccountSnapshotToBatch(batch, accountHash, accIt.Value)
+		stats.storage += common.StorageSize(1 + common.HashLength + len(accIt.Value))
+		stats.accounts++
+
+		// If we've exceeded our batch allowance or termination was requested, flush to disk
+		if flush(accountHash[:]) {
+			return
+		}
+		// If the account is in-progress, continue where we left off (otherwise iterate all)
+		if pa := account.GetProgramAccount(serializer.GetAccount()); pa != nil && pa.GetStorageRoot() != emptyRoot && pa.GetStorageRoot() != (common.Hash{}) {
+			storeTrie, err := statedb.NewSecureTrie(pa.GetStorageRoot(), dl.triedb)
+			if err != nil {
+				logger.Error("Generator failed to access storage trie", "root", dl.root, "account", accountHash, "stroot", pa.GetStorageRoot(), "err", err)
+
+				abort := <-dl.genAbort
+				abort <- stats
+				return
+			}
+			var storeMarker []byte
+			if accMarker != nil && bytes.Equal(accountHash[:], accMarker) && len(genMarker) > common.HashLength {
+				storeMarker = genMarker[common.HashLength:]
+			}
+			storeIt := statedb.NewIterator(storeTrie.NodeIterator(storeMarker))
+			for storeIt.Next() {
+				storageHash := common.BytesToHash(storeIt.Key)
+
+				dl.diskdb.PutStorageSnapshotToBatch(batch, accountHash, storageHash, storeIt.Value)
+				stats.storage += common.StorageSize(1 + 2*common.HashLength + len(storeIt.Value))
+				stats.slots++
+
+				// If we've exceeded our batch allowance or termination was requested, flush to disk
+				if flush(append(accountHash[:], storageHash[:]...)) {
+					return
+				}
+			}
+			if err := storeIt.Err; err != nil {
+				logger.Error("Generator failed to iterate storage trie", "accroot", dl.root, "acchash", accountHash, "stroot", pa.GetStorageRoot(), "err", err)
+
+				abort := <-dl.genAbort
+				abort <- stats
+				return
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			stats.Log("Generating state snapshot", dl.root, accIt.Key)
+			logged = time.Now()
+		}
+		// Some account processed, unmark the marker
+		accMarker = nil
+	}
+	if err := accIt.Err; err != nil {
+		logger.Error("Generator failed to iterate account trie", "root", dl.root, "err", err)
+
+		abort := <-dl.genAbort
+		abort <- stats
+		return
+	}
+	// Snapshot fully generated, set the marker to nil
+	journalProgress(dl.diskdb, batch, nil, stats)
+	if err := batch.Write(); err != nil {
+		logger.Crit("Failed to write state snapshot", "err", err)
+	}
+	logger.Info("Generated state snapshot", "accounts", stats.accounts, "slots", stats.slots,
+		"storage", stats.storage, "elapsed", common.PrettyDuration(time.Since(stats.start)))
+
+	dl.lock.Lock()
+	dl.genMarker = nil
+	close(dl.genPending)
+	dl.lock.Unlock()
+
+	// Someone will be looking for us, wait it out
+	abort := <-dl.genAbort
+	abort <- nil
+}
@@ -0,0 +1,304 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/storage/database"
+)
+
+// AccountIterator is an iterator to step over all the accounts in a snapshot,
+// which may or may not be composed of multiple layers. The accounts are
+// returned in the ascending order of their hashes.
+type AccountIterator interface {
+	// Next steps the iterator forward one element, returning false if exhausted,
+	// or an error if iteration failed for some reason.
+	Next() bool
+
+	// Error returns any failure that occurred during iteration, which might have
+	// caused a premature iteration exit.
+	Error() error
+
+	// Hash returns the hash of the account the iterator is currently at.
+	Hash() common.Hash
+
+	// Account returns the RLP encoded account the iterator is currently at,
+	// which is the same as the value in the account trie.
+	Account() []byte
+
+	// Release releases associated resources. Release should always succeed and
+	// can be called multiple times without causing error.
+	Release()
+}
+
+// StorageIterator is an iterator to step over all the storage slots of an
+// account in a snapshot, which may or may not be composed of multiple layers.
+// The slots are returned in the ascending order of their hashes.
+type StorageIterator interface {
+	// Next steps the iterator forward one element, returning false if exhausted,
+	// or an error if iteration failed for some reason.
+	Next() bool
+
+	// Error returns any failure that occurred during iteration, which might have
+	// caused a premature iteration exit.
+	Error() error
+
+	// Hash returns the hash of the storage slot the iterator is currently at.
+	Hash() common.Hash
+
+	// Slot returns the RLP encoded storage slot the iterator is currently at,
+	// which is the same as the value in the storage trie.
+	Slot() []byte
+
+	// Release releases associated resources. Release should always succeed and
+	// can be called multiple times without causing error.
+	Release()
+}
+
+// hashes is a helper to implement sort.Interface.
+type hashes []common.Hash
+
+func (hs hashes) Len() int           { return len(hs) }
+func (hs hashes) Less(i, j int) bool { return bytes.Compare(hs[i][:], hs[j][:]) < 0 }
+func (hs hashes) Swap(i, j int)      { hs[i], hs[j] = hs[j], hs[i] }
+
+// hashSource is a sorted stream of the hashes of the accounts or the storage
+// slots touched by a single snapshot layer.
+type hashSource interface {
+	// next returns the next hash of the stream, or false if exhausted.
+	next() (common.Hash, bool)
+
+	// release releases associated resources.
+	release()
+}
+
+// diffSource is a hashSource over the sorted list of a diff layer.
+type diffSource struct {
+	list []common.Hash
+}
+
+// newDiffSource creates a hashSource over the sorted list, starting at the
+// first hash not less than seek.
+func newDiffSource(list []common.Hash, seek common.Hash) *diffSource {
+	index := sort.Search(len(list), func(i int) bool {
+		return bytes.Compare(seek[:], list[i][:]) <= 0
+	})
+	return &diffSource{list: list[index:]}
+}
+
+func (s *diffSource) next() (common.Hash, bool) {
+	if len(s.list) == 0 {
+		return common.Hash{}, false
+	}
+	hash := s.list[0]
+	s.list = s.list[1:]
+	return hash, true
+}
+
+func (s *diffSource) release() {}
+
+// diskSource is a hashSource over the entries of the disk layer.
+type diskSource struct {
+	it     database.Iterator
+	keylen int
+}
+
+func (s *diskSource) next() (common.Hash, bool) {
+	for s.it.Next() {
+		// Skip any keys with the correct prefix but wrong length
+		key := s.it.Key()
+		if len(key) != s.keylen {
+			continue
+		}
+		return common.BytesToHash(key[len(key)-common.HashLength:]), true
+	}
+	return common.Hash{}, false
+}
+
+func (s *diskSource) release() { s.it.Release() }
+
+// layerIterator merges the hash streams of all the layers below a snapshot,
+// resolving the values of the merged hashes through the top snapshot. The
+// entries deleted in any layer are resolved as empty and skipped.
+type layerIterator struct {
+	snap    Snapshot     // Top snapshot to resolve the values through
+	sources []hashSource // Hash streams of all the layers
+	heads   []*common.Hash
+
+	storage bool        // Whether the storage slots are iterated instead of the accounts
+	account common.Hash // Account whose storage slots are iterated
+
+	hash  common.Hash
+	value []byte
+	err   error
+}
+
+// newLayerIterator creates an iterator over the given hash streams.
+func newLayerIterator(snap Snapshot, sources []hashSource, storage bool, account common.Hash) *layerIterator {
+	it := &layerIterator{
+		snap:    snap,
+		sources: sources,
+		heads:   make([]*common.Hash, len(sources)),
+		storage: storage,
+		account: account,
+	}
+	for i := range sources {
+		it.advance(i)
+	}
+	return it
+}
+
+// advance moves the given hash stream to its next hash.
+func (it *layerIterator) advance(i int) {
+	if hash, ok := it.sources[i].next(); ok {
+		it.heads[i] = &hash
+	} else {
+		it.heads[i] = nil
+	}
+}
+
+// Next steps the iterator forward one element, returning false if exhausted.
+func (it *layerIterator) Next() bool {
+	for it.err == nil {
+		// Find the smallest hash among all the streams
+		var next *common.Hash
+		for _, head := range it.heads {
+			if head != nil && (next == nil || bytes.Compare(head[:], next[:]) < 0) {
+				next = head
+			}
+		}
+		if next == nil {
+			return false
+		}
+		hash := *next
+		for i, head := range it.heads {
+			if head != nil && *head == hash {
+				it.advance(i)
+			}
+		}
+		// Resolve the value through the top snapshot, skipping deleted entries
+		var value []byte
+		if it.storage {
+			value, it.err = it.snap.Storage(it.account, hash)
+		} else {
+			value, it.err = it.snap.AccountRLP(hash)
+		}
+		if it.err != nil || len(value) == 0 {
+			continue
+		}
+		it.hash, it.value = hash, value
+		return true
+	}
+	return false
+}
+
+// Error returns any failure that occurred during iteration.
+func (it *layerIterator) Error() error { return it.err }
+
+// Hash returns the hash of the entry the iterator is currently at.
+func (it *layerIterator) Hash() common.Hash { return it.hash }
+
+// Account returns the RLP encoded account the iterator is currently at.
+func (it *layerIterator) Account() []byte { return it.value }
+
+// Slot returns the RLP encoded storage slot the iterator is currently at.
+func (it *layerIterator) Slot() []byte { return it.value }
+
+// Release releases the resources of all the hash streams.
+func (it *layerIterator) Release() {
+	for _, source := range it.sources {
+		source.release()
+	}
+	it.sources, it.heads = nil, nil
+}
+
+// layerSources collects the hash streams of the given snapshot and all its
+// parents. It fails if the disk layer is still being generated.
+func layerSources(snap snapshot, diffList func(*diffLayer) []common.Hash, diskSource func(*diskLayer) hashSource) ([]hashSource, error) {
+	var sources []hashSource
+	for layer := snap; layer != nil; layer = layer.Parent() {
+		switch layer := layer.(type) {
+		case *diffLayer:
+			sources = append(sources, &diffSource{list: diffList(layer)})
+		case *diskLayer:
+			layer.lock.RLock()
+			generating := layer.genMarker != nil
+			layer.lock.RUnlock()
+			if generating {
+				for _, source := range sources {
+					source.release()
+				}
+				return nil, ErrNotConstructed
+			}
+			sources = append(sources, diskSource(layer))
+		}
+	}
+	return sources, nil
+}
+
+// AccountIterator creates a new account iterator over the snapshot of the given
+// root, starting at the account with the given hash (or the next one if it
+// does not exist).
+func (t *Tree) AccountIterator(root common.Hash, seek common.Hash) (AccountIterator, error) {
+	snap := t.snapshot(root)
+	if snap == nil {
+		return nil, fmt.Errorf("snapshot [%#x] missing", root)
+	}
+	sources, err := layerSources(snap,
+		func(dl *diffLayer) []common.Hash {
+			return newDiffSource(dl.AccountList(), seek).list
+		},
+		func(dl *diskLayer) hashSource {
+			return &diskSource{
+				it:     dl.diskdb.NewSnapshotDBIterator(database.SnapshotAccountPrefix, seek[:]),
+				keylen: len(database.SnapshotAccountPrefix) + common.HashLength,
+			}
+		})
+	if err != nil {
+		return nil, err
+	}
+	return newLayerIterator(snap, sources, false, common.Hash{}), nil
+}
+
+// StorageIterator creates a new storage iterator over the storage slots of the
+// given account in the snapshot of the given root, starting at the slot with
+// the given hash (or the next one if it does not exist).
+func (t *Tree) StorageIterator(root common.Hash, account common.Hash, seek common.Hash) (StorageIterator, error) {
+	snap := t.snapshot(root)
+	if snap == nil {
+		return nil, fmt.Errorf("snapshot [%#x] missing", root)
+	}
+	prefix := append(append([]byte{}, database.SnapshotStoragePrefix...), account[:]...)
+	sources, err := layerSources(snap,
+		func(dl *diffLayer) []common.Hash {
+			return newDiffSource(dl.StorageList(account), seek).list
+		},
+		func(dl *diskLayer) hashSource {
+			return &diskSource{
+				it:     dl.diskdb.NewSnapshotDBIterator(prefix, seek[:]),
+				keylen: len(prefix) + common.HashLength,
+			}
+		})
+	if err != nil {
+		return nil, err
+	}
+	return newLayerIterator(snap, sources, true, account), nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// collectAccounts iterates all the accounts of the snapshot from the seek position.
+func collectAccounts(t *testing.T, snaps *Tree, root, seek common.Hash) ([]common.Hash, [][]byte) {
+	it, err := snaps.AccountIterator(root, seek)
+	assert.NoError(t, err)
+	defer it.Release()
+
+	var (
+		hashes []common.Hash
+		blobs  [][]byte
+	)
+	for it.Next() {
+		hashes = append(hashes, it.Hash())
+		blobs = append(blobs, it.Account())
+	}
+	assert.NoError(t, it.Error())
+	return hashes, blobs
+}
+
+// collectSlots iterates all the storage slots of the account in the snapshot.
+func collectSlots(t *testing.T, snaps *Tree, root, account common.Hash) map[common.Hash][]byte {
+	it, err := snaps.StorageIterator(root, account, common.Hash{})
+	assert.NoError(t, err)
+	defer it.Release()
+
+	slots := make(map[common.Hash][]byte)
+	var prev *common.Hash
+	for it.Next() {
+		hash := it.Hash()
+		if prev != nil {
+			assert.True(t, hashes{*prev, hash}.Less(0, 1))
+		}
+		prev = &hash
+		slots[hash] = it.Slot()
+	}
+	assert.NoError(t, it.Error())
+	return slots
+}
+
+func TestAccountIterator(t *testing.T) {
+	s := newTestState(t)
+	sorted := s.sortedAccounts()
+	snaps := New(s.diskdb, s.triedb, 16, s.root, false)
+
+	// The disk layer is iterated in order
+	accounts, blobs := collectAccounts(t, snaps, s.root, common.Hash{})
+	assert.Equal(t, sorted, accounts)
+	for i, hash := range accounts {
+		assert.Equal(t, s.accounts[hash], blobs[i])
+	}
+	// Iteration starts at the seek position
+	accounts, _ = collectAccounts(t, snaps, s.root, sorted[3])
+	assert.Equal(t, sorted[3:], accounts)
+
+	// Diff layers are merged with the disk layer, skipping deleted accounts
+	var (
+		root1   = common.HexToHash("0x01")
+		created = crypto.Keccak256Hash([]byte("created"))
+	)
+	assert.NoError(t, snaps.Update(root1, s.root,
+		map[common.Hash]struct{}{sorted[0]: {}},
+		map[common.Hash][]byte{sorted[1]: {0x1}, created: {0x2}},
+		map[common.Hash]map[common.Hash][]byte{}))
+
+	accounts, blobs = collectAccounts(t, snaps, root1, common.Hash{})
+	expected := append([]common.Hash{created}, sorted[1:]...)
+	sort.Sort(hashes(expected))
+	assert.Equal(t, expected, accounts)
+	for i, hash := range accounts {
+		switch hash {
+		case sorted[1]:
+			assert.Equal(t, []byte{0x1}, blobs[i])
+		case created:
+			assert.Equal(t, []byte{0x2}, blobs[i])
+		default:
+			assert.Equal(t, s.accounts[hash], blobs[i])
+		}
+	}
+	// Missing snapshots can't be iterated
+	_, err := snaps.AccountIterator(common.HexToHash("0x02"), common.Hash{})
+	assert.Error(t, err)
+}
+
+func TestStorageIterator(t *testing.T) {
+	s := newTestState(t)
+	snaps := New(s.diskdb, s.triedb, 16, s.root, false)
+
+	var contract common.Hash
+	for hash := range s.storage {
+		contract = hash
+		break
+	}
+	assert.Equal(t, s.storage[contract], collectSlots(t, snaps, s.root, contract))
+	assert.Empty(t, collectSlots(t, snaps, s.root, common.HexToHash("0xff")))
+
+	// A destructed and recreated contract only has the new slots
+	var (
+		root1 = common.HexToHash("0x01")
+		slot  = crypto.Keccak256Hash([]byte("slot"))
+	)
+	assert.NoError(t, snaps.Update(root1, s.root,
+		map[common.Hash]struct{}{contract: {}},
+		map[common.Hash][]byte{contract: {0x1}},
+		map[common.Hash]map[common.Hash][]byte{contract: {slot: {0x2}}}))
+	assert.Equal(t, map[common.Hash][]byte{slot: {0x2}}, collectSlots(t, snaps, root1, contract))
+}
+
+func TestIteratorNotConstructed(t *testing.T) {
+	s := newTestState(t)
+	snaps := New(s.diskdb, s.triedb, 16, s.root, false)
+
+	// Pretend the generation is still in progress
+	base := snaps.Snapshot(s.root).(*diskLayer)
+	base.genMarker = []byte{}
+
+	_, err := snaps.AccountIterator(s.root, common.Hash{})
+	assert.Equal(t, ErrNotConstructed, err)
+	_, err = snaps.StorageIterator(s.root, common.Hash{}, common.Hash{})
+	assert.Equal(t, ErrNotConstructed, err)
+}
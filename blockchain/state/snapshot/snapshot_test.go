@@ -0,0 +1,293 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types/account"
+	"github.com/klaytn/klaytn/blockchain/types/accountkey"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/stretchr/testify/assert"
+)
+
+// testState is a state trie with an externally owned account and a smart contract
+// account having storage slots, committed to an in-memory trie database.
+type testState struct {
+	diskdb database.DBManager
+	triedb *statedb.Database
+	root   common.Hash
+
+	accounts map[common.Hash][]byte                 // account hash -> account RLP
+	storage  map[common.Hash]map[common.Hash][]byte // account hash -> slot hash -> slot RLP
+}
+
+func encodeAccount(t *testing.T, acc account.Account) []byte {
+	enc, err := rlp.EncodeToBytes(account.NewAccountSerializerWithAccount(acc))
+	assert.NoError(t, err)
+	return enc
+}
+
+func newTestState(t *testing.T) *testState {
+	diskdb := database.NewMemoryDBManager()
+	s := &testState{
+		diskdb:   diskdb,
+		triedb:   statedb.NewDatabase(diskdb),
+		accounts: make(map[common.Hash][]byte),
+		storage:  make(map[common.Hash]map[common.Hash][]byte),
+	}
+	accTrie, _ := statedb.NewSecureTrie(common.Hash{}, s.triedb)
+	for i := byte(1); i <= 10; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		values := map[account.AccountValueKeyType]interface{}{
+			account.AccountValueKeyNonce:         uint64(i),
+			account.AccountValueKeyBalance:       big.NewInt(int64(i)),
+			account.AccountValueKeyHumanReadable: false,
+			account.AccountValueKeyAccountKey:    accountkey.NewAccountKeyLegacy(),
+		}
+		accType := account.ExternallyOwnedAccountType
+		if i%2 == 0 {
+			// Contracts have storage slots
+			stTrie, _ := statedb.NewSecureTrie(common.Hash{}, s.triedb)
+			slots := make(map[common.Hash][]byte)
+			for j := byte(1); j <= i; j++ {
+				enc, _ := rlp.EncodeToBytes([]byte{i, j})
+				stTrie.Update(common.BytesToHash([]byte{j}).Bytes(), enc)
+				slots[crypto.Keccak256Hash(common.BytesToHash([]byte{j}).Bytes())] = enc
+			}
+			stRoot, err := stTrie.Commit(nil)
+			assert.NoError(t, err)
+
+			accType = account.SmartContractAccountType
+			values[account.AccountValueKeyStorageRoot] = stRoot
+			values[account.AccountValueKeyCodeHash] = crypto.Keccak256([]byte{i})
+			s.storage[crypto.Keccak256Hash(addr[:])] = slots
+		}
+		acc, err := account.NewAccountWithMap(accType, values)
+		assert.NoError(t, err)
+
+		enc := encodeAccount(t, acc)
+		accTrie.Update(addr[:], enc)
+		s.accounts[crypto.Keccak256Hash(addr[:])] = enc
+	}
+	root, err := accTrie.Commit(nil)
+	assert.NoError(t, err)
+	s.root = root
+	return s
+}
+
+// checkSnapshot checks that the snapshot has the same accounts and storage slots
+// with the test state.
+func (s *testState) checkSnapshot(t *testing.T, snap Snapshot) {
+	for accHash, enc := range s.accounts {
+		blob, err := snap.AccountRLP(accHash)
+		assert.NoError(t, err)
+		assert.Equal(t, enc, blob)
+
+		acc, err := snap.Account(accHash)
+		assert.NoError(t, err)
+		assert.NotNil(t, acc)
+	}
+	for accHash, slots := range s.storage {
+		for slotHash, enc := range slots {
+			blob, err := snap.Storage(accHash, slotHash)
+			assert.NoError(t, err)
+			assert.Equal(t, enc, blob)
+		}
+	}
+	// Missing items are empty without errors
+	blob, err := snap.AccountRLP(common.HexToHash("0xff"))
+	assert.NoError(t, err)
+	assert.Empty(t, blob)
+}
+
+// sortedAccounts returns the hashes of the accounts in the test state in order.
+func (s *testState) sortedAccounts() []common.Hash {
+	var hashes []common.Hash
+	for hash := range s.accounts {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return bytes.Compare(hashes[i][:], hashes[j][:]) < 0 })
+	return hashes
+}
+
+func TestGenerateSnapshot(t *testing.T) {
+	s := newTestState(t)
+
+	// Leftovers of a previous snapshot are wiped before generation
+	batch := s.diskdb.NewSnapshotDBBatch()
+	s.diskdb.PutAccountSnapshotToBatch(batch, common.HexToHash("0xff"), []byte{0x1})
+	assert.NoError(t, batch.Write())
+
+	snaps := New(s.diskdb, s.triedb, 16, s.root, false)
+	assert.Equal(t, s.root, snaps.DiskRoot())
+	assert.Equal(t, s.root, s.diskdb.ReadSnapshotRoot())
+	s.checkSnapshot(t, snaps.Snapshot(s.root))
+	assert.Nil(t, snaps.Snapshot(common.HexToHash("0x1")))
+
+	var generator journalGenerator
+	assert.NoError(t, rlp.DecodeBytes(s.diskdb.ReadSnapshotGenerator(), &generator))
+	assert.True(t, generator.Done)
+	assert.False(t, generator.Wiping)
+	assert.Equal(t, uint64(len(s.accounts)), generator.Accounts)
+
+	// The generated snapshot is loaded without regeneration
+	assert.NoError(t, snaps.Persist(s.root))
+	base, err := loadSnapshot(s.diskdb, s.triedb, 16, s.root)
+	assert.NoError(t, err)
+	assert.Nil(t, base.genMarker)
+	s.checkSnapshot(t, base)
+}
+
+func TestGenerateSnapshotResume(t *testing.T) {
+	s := newTestState(t)
+	hashes := s.sortedAccounts()
+
+	// Persist a partially generated snapshot covering the accounts up to the third
+	// one, but only the first two of them are written. The generation is resumed
+	// from the marker including the account at the marker.
+	batch := s.diskdb.NewSnapshotDBBatch()
+	for _, hash := range hashes[:2] {
+		s.diskdb.PutAccountSnapshotToBatch(batch, hash, s.accounts[hash])
+		for slotHash, enc := range s.storage[hash] {
+			s.diskdb.PutStorageSnapshotToBatch(batch, hash, slotHash, enc)
+		}
+	}
+	s.diskdb.PutSnapshotRootToBatch(batch, s.root)
+	journalProgress(s.diskdb, batch, hashes[2][:], &generatorStats{accounts: 2})
+	assert.NoError(t, batch.Write())
+
+	base, err := loadSnapshot(s.diskdb, s.triedb, 16, s.root)
+	assert.NoError(t, err)
+	<-base.genPending
+	s.checkSnapshot(t, base)
+
+	abort := make(chan *generatorStats)
+	base.genAbort <- abort
+	assert.Nil(t, <-abort)
+}
+
+func TestDiffLayers(t *testing.T) {
+	s := newTestState(t)
+	hashes := s.sortedAccounts()
+	snaps := New(s.diskdb, s.triedb, 16, s.root, false)
+
+	var contract common.Hash
+	for hash := range s.storage {
+		contract = hash
+		break
+	}
+	var (
+		root1   = common.HexToHash("0x01")
+		root2   = common.HexToHash("0x02")
+		updated = hashes[0]
+		slot    = crypto.Keccak256Hash([]byte("slot"))
+	)
+	if updated == contract {
+		updated = hashes[1]
+	}
+	// The first layer updates an account and destructs the contract
+	assert.NoError(t, snaps.Update(root1, s.root,
+		map[common.Hash]struct{}{contract: {}},
+		map[common.Hash][]byte{updated: {0x1}},
+		map[common.Hash]map[common.Hash][]byte{}))
+	// The second layer recreates the contract with a new slot
+	assert.NoError(t, snaps.Update(root2, root1,
+		map[common.Hash]struct{}{},
+		map[common.Hash][]byte{contract: {0x2}},
+		map[common.Hash]map[common.Hash][]byte{contract: {slot: {0x3}}}))
+	assert.Equal(t, errSnapshotCycle, snaps.Update(root2, root2, nil, nil, nil))
+	assert.Error(t, snaps.Update(common.HexToHash("0x03"), common.HexToHash("0x04"), nil, nil, nil))
+
+	// The layers keep their own views and the disk layer is left intact
+	s.checkSnapshot(t, snaps.Snapshot(s.root))
+
+	snap1 := snaps.Snapshot(root1)
+	blob, err := snap1.AccountRLP(updated)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x1}, blob)
+	blob, err = snap1.AccountRLP(contract)
+	assert.NoError(t, err)
+	assert.Nil(t, blob)
+	for slotHash := range s.storage[contract] {
+		blob, err = snap1.Storage(contract, slotHash)
+		assert.NoError(t, err)
+		assert.Nil(t, blob)
+	}
+
+	snap2 := snaps.Snapshot(root2)
+	blob, err = snap2.AccountRLP(updated)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x1}, blob)
+	blob, err = snap2.AccountRLP(contract)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x2}, blob)
+	blob, err = snap2.Storage(contract, slot)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x3}, blob)
+
+	// Capping keeps the view of the head layer
+	base := snaps.Snapshot(s.root)
+	assert.NoError(t, snaps.Cap(root2, 1))
+	blob, err = snaps.Snapshot(root2).Storage(contract, slot)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x3}, blob)
+
+	// Flattening all the layers into the disk makes the previous disk layer stale
+	assert.NoError(t, snaps.Cap(root2, 0))
+	assert.Equal(t, root2, snaps.DiskRoot())
+	assert.Equal(t, root2, s.diskdb.ReadSnapshotRoot())
+	assert.Nil(t, snaps.Snapshot(root1))
+	_, err = base.AccountRLP(updated)
+	assert.Equal(t, ErrSnapshotStale, err)
+
+	assert.Equal(t, []byte{0x1}, s.diskdb.ReadAccountSnapshot(updated))
+	assert.Equal(t, []byte{0x2}, s.diskdb.ReadAccountSnapshot(contract))
+	assert.Equal(t, []byte{0x3}, s.diskdb.ReadStorageSnapshot(contract, slot))
+	for slotHash := range s.storage[contract] {
+		assert.Empty(t, s.diskdb.ReadStorageSnapshot(contract, slotHash))
+	}
+}
+
+func TestRebuild(t *testing.T) {
+	s := newTestState(t)
+	snaps := New(s.diskdb, s.triedb, 16, s.root, false)
+
+	root1 := common.HexToHash("0x01")
+	assert.NoError(t, snaps.Update(root1, s.root, nil, map[common.Hash][]byte{}, nil))
+	snap1 := snaps.Snapshot(root1)
+
+	// Rebuilding discards all the layers
+	snaps.Rebuild(s.root)
+	snaps.waitBuild()
+	assert.Nil(t, snaps.Snapshot(root1))
+	_, err := snap1.AccountRLP(common.Hash{})
+	assert.Equal(t, ErrSnapshotStale, err)
+	s.checkSnapshot(t, snaps.Snapshot(s.root))
+
+	// A snapshot persisted at another root is rebuilt on load
+	other := newTestState(t)
+	snaps = New(s.diskdb, other.triedb, 16, common.HexToHash("0x02"), true)
+	assert.Equal(t, common.HexToHash("0x02"), snaps.DiskRoot())
+}
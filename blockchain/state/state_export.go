@@ -0,0 +1,291 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/klaytn/klaytn/blockchain/types/account"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+)
+
+// StateExportVersion is the version of the state export format written by ExportState.
+//
+// A state export is an RLP stream which starts with a StateExportHeader, followed by the accounts
+// in the ascending order of the hashes of their addresses. An account whose storage trie is not
+// empty is followed by the chunks of its storage slots in the ascending order of the hashes of the
+// slot keys, the last of which is marked. The stream of the same state is always the same, and
+// the state can be rebuilt from the stream without the preimages of the hashes.
+const StateExportVersion = 1
+
+// stateExportChunkSize is the maximum number of the storage slots in a chunk.
+const stateExportChunkSize = 1024
+
+var (
+	errUnsupportedStateExport = errors.New("unsupported state export version")
+	errNonCanonicalStateOrder = errors.New("entries of the state export are not in the canonical order")
+)
+
+// StateExportHeader describes the exported state.
+type StateExportHeader struct {
+	Version     uint64
+	ChainID     *big.Int
+	BlockNumber uint64
+	BlockHash   common.Hash
+	Root        common.Hash
+	Governance  []byte // governance items at the block in JSON, empty if the chain has no governance
+}
+
+// StateExportStats is the number of the entries exported or imported.
+type StateExportStats struct {
+	Accounts uint64 `json:"accounts"`
+	Codes    uint64 `json:"codes"`
+	Slots    uint64 `json:"slots"`
+}
+
+// stateExportAccount is an account entry of the state export.
+type stateExportAccount struct {
+	Hash    common.Hash // hash of the address, the key of the account in the state trie
+	Address []byte      // address of the account, empty if its preimage is unknown
+	Account []byte      // RLP-encoded account including its key, as stored in the state trie
+	Code    []byte      // code of a program account, empty if it has no code
+}
+
+// stateExportSlot is a storage slot entry of the state export.
+type stateExportSlot struct {
+	Hash  common.Hash // hash of the slot key, the key of the slot in the storage trie
+	Value []byte      // RLP-encoded value as stored in the storage trie
+}
+
+// stateExportStorage is a chunk of the storage slots of an account.
+type stateExportStorage struct {
+	Slots []stateExportSlot
+	Last  bool
+}
+
+func isEmptyStorageRoot(root common.Hash) bool {
+	return root == emptyRoot || root == (common.Hash{})
+}
+
+// decodeExportedAccount decodes the RLP-encoded account, and returns its code hash and storage root
+// if it is a program account.
+func decodeExportedAccount(enc []byte) (codeHash, storageRoot common.Hash, isProgram bool, err error) {
+	serializer := account.NewAccountSerializer()
+	if err := rlp.DecodeBytes(enc, serializer); err != nil {
+		return common.Hash{}, common.Hash{}, false, err
+	}
+	if pa := account.GetProgramAccount(serializer.GetAccount()); pa != nil {
+		return common.BytesToHash(pa.GetCodeHash()), pa.GetStorageRoot(), true, nil
+	}
+	return common.Hash{}, common.Hash{}, false, nil
+}
+
+// ExportState writes the state of header.Root to w in the canonical state export format.
+func ExportState(db Database, header *StateExportHeader, w io.Writer) (*StateExportStats, error) {
+	tr, err := db.OpenTrie(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	header.Version = StateExportVersion
+	if err := rlp.Encode(w, header); err != nil {
+		return nil, err
+	}
+
+	stats := new(StateExportStats)
+	it := statedb.NewIterator(tr.NodeIterator(nil))
+	for it.Next() {
+		entry := &stateExportAccount{
+			Hash:    common.BytesToHash(it.Key),
+			Address: tr.GetKey(it.Key),
+			Account: it.Value,
+		}
+		codeHash, storageRoot, isProgram, err := decodeExportedAccount(it.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode account %x: %v", it.Key, err)
+		}
+		if isProgram && codeHash != emptyCode {
+			if entry.Code, err = db.ContractCode(codeHash); err != nil {
+				return nil, fmt.Errorf("failed to read code %x: %v", codeHash, err)
+			}
+			stats.Codes++
+		}
+		if err := rlp.Encode(w, entry); err != nil {
+			return nil, err
+		}
+		stats.Accounts++
+
+		if !isProgram || isEmptyStorageRoot(storageRoot) {
+			continue
+		}
+		storageTrie, err := db.OpenStorageTrie(storageRoot)
+		if err != nil {
+			return nil, err
+		}
+		chunk := &stateExportStorage{Slots: make([]stateExportSlot, 0, stateExportChunkSize)}
+		storageIt := statedb.NewIterator(storageTrie.NodeIterator(nil))
+		for storageIt.Next() {
+			chunk.Slots = append(chunk.Slots, stateExportSlot{Hash: common.BytesToHash(storageIt.Key), Value: storageIt.Value})
+			stats.Slots++
+			if len(chunk.Slots) == stateExportChunkSize {
+				if err := rlp.Encode(w, chunk); err != nil {
+					return nil, err
+				}
+				chunk.Slots = chunk.Slots[:0]
+			}
+		}
+		if storageIt.Err != nil {
+			return nil, storageIt.Err
+		}
+		chunk.Last = true
+		if err := rlp.Encode(w, chunk); err != nil {
+			return nil, err
+		}
+	}
+	return stats, it.Err
+}
+
+// ImportState rebuilds the state from the stream written by ExportState, and writes it to the
+// database only if the recomputed state root is the same as the root in the header.
+func ImportState(db database.DBManager, r io.Reader) (*StateExportHeader, *StateExportStats, error) {
+	stream := rlp.NewStream(r, 0)
+	header := new(StateExportHeader)
+	if err := stream.Decode(header); err != nil {
+		return nil, nil, fmt.Errorf("failed to read the header: %v", err)
+	}
+	if header.Version != StateExportVersion {
+		return nil, nil, fmt.Errorf("%w: %d", errUnsupportedStateExport, header.Version)
+	}
+
+	// The state is rebuilt in a separate trie database not to leave the nodes of a wrong state in memory
+	triedb := statedb.NewDatabase(db)
+	accounts, err := statedb.NewTrie(common.Hash{}, triedb)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		stats = new(StateExportStats)
+		prev  []byte
+	)
+	for {
+		entry := new(stateExportAccount)
+		if err := stream.Decode(entry); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, fmt.Errorf("failed to read account %d: %v", stats.Accounts, err)
+		}
+		if prev != nil && bytes.Compare(prev, entry.Hash[:]) >= 0 {
+			return nil, nil, errNonCanonicalStateOrder
+		}
+		prev = entry.Hash[:]
+		if len(entry.Address) > 0 && crypto.Keccak256Hash(entry.Address) != entry.Hash {
+			return nil, nil, fmt.Errorf("address %x does not match its hash %x", entry.Address, entry.Hash)
+		}
+
+		codeHash, storageRoot, isProgram, err := decodeExportedAccount(entry.Account)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode account %x: %v", entry.Hash, err)
+		}
+		if isProgram && codeHash != emptyCode {
+			if crypto.Keccak256Hash(entry.Code) != codeHash {
+				return nil, nil, fmt.Errorf("code of account %x does not match its code hash %x", entry.Hash, codeHash)
+			}
+			triedb.InsertBlob(codeHash, entry.Code)
+			stats.Codes++
+		} else if len(entry.Code) > 0 {
+			return nil, nil, fmt.Errorf("account %x has code without a code hash", entry.Hash)
+		}
+		if isProgram && !isEmptyStorageRoot(storageRoot) {
+			root, slots, err := importStorageTrie(stream, triedb)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to import storage of account %x: %v", entry.Hash, err)
+			}
+			if root != storageRoot {
+				return nil, nil, fmt.Errorf("storage root of account %x mismatch: expected %x, recomputed %x", entry.Hash, storageRoot, root)
+			}
+			stats.Slots += slots
+		}
+		if err := accounts.TryUpdate(entry.Hash[:], entry.Account); err != nil {
+			return nil, nil, err
+		}
+		stats.Accounts++
+	}
+
+	root, err := accounts.Commit(func(leaf []byte, parent common.Hash, parentDepth int) error {
+		codeHash, storageRoot, isProgram, err := decodeExportedAccount(leaf)
+		if err != nil || !isProgram {
+			return err
+		}
+		if !isEmptyStorageRoot(storageRoot) {
+			triedb.Reference(storageRoot, parent)
+		}
+		if codeHash != emptyCode {
+			triedb.Reference(codeHash, parent)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if root != header.Root {
+		return nil, nil, fmt.Errorf("state root mismatch: expected %x, recomputed %x", header.Root, root)
+	}
+	if err := triedb.Commit(root, false, header.BlockNumber); err != nil {
+		return nil, nil, err
+	}
+	return header, stats, nil
+}
+
+// importStorageTrie rebuilds a storage trie from the chunks of its slots, and returns its root.
+func importStorageTrie(stream *rlp.Stream, triedb *statedb.Database) (common.Hash, uint64, error) {
+	tr, err := statedb.NewTrie(common.Hash{}, triedb)
+	if err != nil {
+		return common.Hash{}, 0, err
+	}
+	var (
+		slots uint64
+		prev  []byte
+	)
+	for {
+		chunk := new(stateExportStorage)
+		if err := stream.Decode(chunk); err != nil {
+			return common.Hash{}, 0, err
+		}
+		for _, slot := range chunk.Slots {
+			if prev != nil && bytes.Compare(prev, slot.Hash[:]) >= 0 {
+				return common.Hash{}, 0, errNonCanonicalStateOrder
+			}
+			prev = common.CopyBytes(slot.Hash[:])
+			if err := tr.TryUpdate(slot.Hash[:], slot.Value); err != nil {
+				return common.Hash{}, 0, err
+			}
+			slots++
+		}
+		if chunk.Last {
+			break
+		}
+	}
+	root, err := tr.Commit(nil)
+	return root, slots, err
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func newExportTestState(t *testing.T) (Database, common.Hash) {
+	db := NewDatabase(database.NewMemoryDBManager())
+	s, _ := New(common.Hash{}, db, nil)
+
+	eoa, contract := common.Address{1}, common.Address{2}
+	s.AddBalance(eoa, big.NewInt(100))
+	s.SetNonce(eoa, 3)
+	s.CreateSmartContractAccount(contract, params.CodeFormatEVM, params.Rules{})
+	s.SetCode(contract, []byte{0x60, 0x00})
+	// More slots than a chunk to be exported in multiple chunks
+	for i := 1; i <= stateExportChunkSize+10; i++ {
+		s.SetState(contract, common.BigToHash(big.NewInt(int64(i))), common.BigToHash(big.NewInt(int64(i*2))))
+	}
+	root, err := s.Commit(false)
+	assert.NoError(t, err)
+	assert.NoError(t, db.TrieDB().Commit(root, false, 0))
+	return db, root
+}
+
+func TestStateExportImport(t *testing.T) {
+	srcDB, root := newExportTestState(t)
+	header := &StateExportHeader{ChainID: big.NewInt(1000), BlockNumber: 10, Root: root, Governance: []byte(`{"governance.unitprice":25000000000}`)}
+
+	var exported bytes.Buffer
+	stats, err := ExportState(srcDB, header, &exported)
+	assert.NoError(t, err)
+	assert.Equal(t, &StateExportStats{Accounts: 2, Codes: 1, Slots: stateExportChunkSize + 10}, stats)
+
+	// The export of the same state is the same
+	var again bytes.Buffer
+	_, err = ExportState(srcDB, &StateExportHeader{ChainID: big.NewInt(1000), BlockNumber: 10, Root: root, Governance: header.Governance}, &again)
+	assert.NoError(t, err)
+	assert.Equal(t, exported.Bytes(), again.Bytes())
+
+	dstDBManager := database.NewMemoryDBManager()
+	imported, importStats, err := ImportState(dstDBManager, bytes.NewReader(exported.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, stats, importStats)
+	assert.Equal(t, header, imported)
+
+	s, err := New(root, NewDatabase(dstDBManager), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(100), s.GetBalance(common.Address{1}))
+	assert.Equal(t, uint64(3), s.GetNonce(common.Address{1}))
+	assert.Equal(t, []byte{0x60, 0x00}, s.GetCode(common.Address{2}))
+	assert.Equal(t, common.BigToHash(big.NewInt(2000)), s.GetState(common.Address{2}, common.BigToHash(big.NewInt(1000))))
+}
+
+func TestStateImport_Invalid(t *testing.T) {
+	srcDB, root := newExportTestState(t)
+	var exported bytes.Buffer
+	_, err := ExportState(srcDB, &StateExportHeader{ChainID: big.NewInt(1000), Root: root}, &exported)
+	assert.NoError(t, err)
+
+	// The state is not written if the recomputed root is different
+	_, _, body, err := rlp.Split(exported.Bytes())
+	assert.NoError(t, err)
+	header := &StateExportHeader{Version: StateExportVersion, ChainID: big.NewInt(1000), Root: common.Hash{1}}
+	enc, _ := rlp.EncodeToBytes(header)
+	dstDBManager := database.NewMemoryDBManager()
+	_, _, err = ImportState(dstDBManager, bytes.NewReader(append(enc, body...)))
+	assert.Error(t, err)
+	assert.Empty(t, dstDBManager.GetMemDB().Keys())
+
+	// The unsupported version is rejected
+	header.Version = StateExportVersion + 1
+	enc, _ = rlp.EncodeToBytes(header)
+	_, _, err = ImportState(database.NewMemoryDBManager(), bytes.NewReader(enc))
+	assert.Error(t, err)
+}
@@ -75,9 +75,10 @@ func (self Storage) Copy() Storage {
 // Account values can be accessed and modified through the object.
 // Finally, call CommitStorageTrie to write the modified storage trie into a database.
 type stateObject struct {
-	address common.Address
-	account account.Account
-	db      *StateDB
+	address  common.Address
+	addrHash common.Hash // hash of the address of the account
+	account  account.Account
+	db       *StateDB
 
 	// DB error.
 	// State objects are used by the consensus core and VM which are
@@ -92,6 +93,7 @@ type stateObject struct {
 
 	originStorage Storage // Storage cache of original entries to dedup rewrites
 	dirtyStorage  Storage // Storage entries that need to be flushed to disk
+	fakeStorage   Storage // Storage replacing the entire storage, which is used only to override the state of a call
 
 	// Cache flags.
 	// When an object is marked suicided it will be delete from the trie
@@ -120,6 +122,7 @@ func newObject(db *StateDB, address common.Address, data account.Account) *state
 	return &stateObject{
 		db:            db,
 		address:       address,
+		addrHash:      crypto.Keccak256Hash(address[:]),
 		account:       data,
 		originStorage: make(Storage),
 		dirtyStorage:  make(Storage),
@@ -180,6 +183,10 @@ func (c *stateObject) getStorageTrie(db Database) Trie {
 
 // GetState retrieves a value from the account storage trie.
 func (self *stateObject) GetState(db Database, key common.Hash) common.Hash {
+	// If the fake storage is set, the state is looked up only in the fake storage
+	if self.fakeStorage != nil {
+		return self.fakeStorage[key]
+	}
 	// If we have a dirty value for this state entry, return it
 	value, dirty := self.dirtyStorage[key]
 	if dirty {
@@ -191,6 +198,9 @@ func (self *stateObject) GetState(db Database, key common.Hash) common.Hash {
 
 // GetCommittedState retrieves a value from the committed account storage trie.
 func (self *stateObject) GetCommittedState(db Database, key common.Hash) common.Hash {
+	if self.fakeStorage != nil {
+		return self.fakeStorage[key]
+	}
 	// If we have the original value cached, return that
 	value, cached := self.originStorage[key]
 	if cached {
@@ -200,11 +210,29 @@ func (self *stateObject) GetCommittedState(db Database, key common.Hash) common.
 	if EnabledExpensive {
 		defer func(start time.Time) { self.db.StorageReads += time.Since(start) }(time.Now())
 	}
-	// Load from DB in case it is missing.
-	enc, err := self.getStorageTrie(db).TryGet(key[:])
-	if err != nil {
-		self.setError(err)
-		return common.Hash{}
+	// If the account was destructed in this block, the storage slots in the snapshot
+	// are not of this object.
+	if self.db.snap != nil {
+		if _, destructed := self.db.snapDestructs[self.addrHash]; destructed {
+			self.originStorage[key] = common.Hash{}
+			return common.Hash{}
+		}
+	}
+	// Load from the snapshot if available, or from DB in case it is missing.
+	var (
+		enc []byte
+		err error
+	)
+	if self.db.snap != nil {
+		enc, err = self.db.snap.Storage(self.addrHash, crypto.Keccak256Hash(key[:]))
+	}
+	// If the snapshot is unavailable or reading from it failed, load from the database
+	if self.db.snap == nil || err != nil {
+		enc, err = self.getStorageTrie(db).TryGet(key[:])
+		if err != nil {
+			self.setError(err)
+			return common.Hash{}
+		}
 	}
 	if len(enc) > 0 {
 		_, content, _, err := rlp.Split(enc)
@@ -219,6 +247,11 @@ func (self *stateObject) GetCommittedState(db Database, key common.Hash) common.
 
 // SetState updates a value in account trie.
 func (self *stateObject) SetState(db Database, key, value common.Hash) {
+	// If the fake storage is set, the state is updated only in the fake storage
+	if self.fakeStorage != nil {
+		self.fakeStorage[key] = value
+		return
+	}
 	// If the new value is the same as old, don't set
 	prev := self.GetState(db, key)
 	if prev == value {
@@ -267,6 +300,18 @@ func (self *stateObject) setState(key, value common.Hash) {
 	self.dirtyStorage[key] = value
 }
 
+// SetStorage replaces the entire storage with the given one. After it is called, the original
+// storage is ignored and the state is looked up only in the given storage. The replaced storage
+// is neither journaled nor committed, so it should be used only to override the state of a call.
+func (self *stateObject) SetStorage(storage map[common.Hash]common.Hash) {
+	if self.fakeStorage == nil {
+		self.fakeStorage = make(Storage)
+	}
+	for key, value := range storage {
+		self.fakeStorage[key] = value
+	}
+}
+
 func (self *stateObject) UpdateKey(newKey accountkey.AccountKey, currentBlockNumber uint64) error {
 	return self.account.UpdateKey(newKey, currentBlockNumber)
 }
@@ -278,6 +323,9 @@ func (self *stateObject) updateStorageTrie(db Database) Trie {
 		defer func(start time.Time) { self.db.StorageUpdates += time.Since(start) }(time.Now())
 	}
 	tr := self.getStorageTrie(db)
+
+	// The storage slots to be recorded to the snapshot
+	var storage map[common.Hash][]byte
 	for key, value := range self.dirtyStorage {
 		delete(self.dirtyStorage, key)
 
@@ -287,41 +335,40 @@ func (self *stateObject) updateStorageTrie(db Database) Trie {
 		}
 		self.originStorage[key] = value
 
+		var v []byte
 		if (value == common.Hash{}) {
 			self.setError(tr.TryDelete(key[:]))
-			continue
+		} else {
+			// Encoding []byte cannot fail, ok to ignore the error.
+			v, _ = rlp.EncodeToBytes(bytes.TrimLeft(value[:], "\x00"))
+			self.setError(tr.TryUpdate(key[:], v))
+		}
+		// If state snapshotting is active, cache the data til commit
+		if self.db.snap != nil {
+			if storage == nil {
+				// Retrieve the old storage map, if available, create a new one otherwise
+				if storage = self.db.snapStorage[self.addrHash]; storage == nil {
+					storage = make(map[common.Hash][]byte)
+					self.db.snapStorage[self.addrHash] = storage
+				}
+			}
+			storage[crypto.Keccak256Hash(key[:])] = v // v will be nil if value is 0x00
 		}
-		// Encoding []byte cannot fail, ok to ignore the error.
-		v, _ := rlp.EncodeToBytes(bytes.TrimLeft(value[:], "\x00"))
-		self.setError(tr.TryUpdate(key[:], v))
 	}
 	return tr
 }
 
 // updateStorageRoot sets the storage trie root to the newly updated one.
-func (self *stateObject) updateStorageRoot(db Database) {
+// It can be called for different objects concurrently.
+func (self *stateObject) updateStorageRoot() {
 	if acc := account.GetProgramAccount(self.account); acc != nil {
-		// Track the amount of time wasted on hashing the storage trie
-		if EnabledExpensive {
-			defer func(start time.Time) { self.db.StorageHashes += time.Since(start) }(time.Now())
-		}
 		acc.SetStorageRoot(self.storageTrie.Hash())
 	}
 }
 
-// setStorageRoot calls SetStorageRoot if updateStorageRoot flag is given true.
-// Otherwise, it just marks the object and update their root hash later.
-func (self *stateObject) setStorageRoot(updateStorageRoot bool, objectsToUpdate map[common.Address]struct{}) {
+// markStorageRoot marks the object to update its storage root later.
+func (self *stateObject) markStorageRoot(objectsToUpdate map[common.Address]struct{}) {
 	if acc := account.GetProgramAccount(self.account); acc != nil {
-		if updateStorageRoot {
-			// Track the amount of time wasted on hashing the storage trie
-			if EnabledExpensive {
-				defer func(start time.Time) { self.db.StorageHashes += time.Since(start) }(time.Now())
-			}
-			acc.SetStorageRoot(self.storageTrie.Hash())
-			return
-		}
-		// If updateStorageRoot == false, it just marks the object and updates its storage root later.
 		objectsToUpdate[self.Address()] = struct{}{}
 	}
 }
@@ -337,6 +384,12 @@ func (self *stateObject) CommitStorageTrie(db Database) error {
 	if EnabledExpensive {
 		defer func(start time.Time) { self.db.StorageCommits += time.Since(start) }(time.Now())
 	}
+	return self.commitStorageTrie()
+}
+
+// commitStorageTrie commits the updated storage trie of the object and sets its storage root.
+// It can be called for different objects concurrently.
+func (self *stateObject) commitStorageTrie() error {
 	if acc := account.GetProgramAccount(self.account); acc != nil {
 		root, err := self.storageTrie.Commit(nil)
 		if err != nil {
@@ -394,6 +447,9 @@ func (self *stateObject) deepCopy(db *StateDB) *stateObject {
 	stateObject.code = self.code
 	stateObject.dirtyStorage = self.dirtyStorage.Copy()
 	stateObject.originStorage = self.originStorage.Copy()
+	if self.fakeStorage != nil {
+		stateObject.fakeStorage = self.fakeStorage.Copy()
+	}
 	stateObject.suicided = self.suicided
 	stateObject.dirtyCode = self.dirtyCode
 	stateObject.deleted = self.deleted
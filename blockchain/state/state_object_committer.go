@@ -0,0 +1,92 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"runtime"
+	"sync"
+)
+
+const (
+	storageTrieMaxWorkers = 16
+
+	// storageTrieBatchSize is the number of the state objects a worker takes at once,
+	// which bounds the nodes a worker writes to the trie database in a row.
+	storageTrieBatchSize = 16
+)
+
+var storageTrieWorkers = calcNumStorageTrieWorkers()
+
+func calcNumStorageTrieWorkers() int {
+	numWorkers := runtime.NumCPU() / 2
+	if numWorkers < 1 {
+		return 1
+	}
+	if numWorkers > storageTrieMaxWorkers {
+		return storageTrieMaxWorkers
+	}
+	return numWorkers
+}
+
+// forEachStorageTrie runs fn for each of the given state objects on a bounded number of goroutines,
+// and returns the error of the first object in the slice failed. fn is called at most once for each
+// object, and it should touch only the storage trie and the account of the given object since the
+// objects are processed concurrently.
+func forEachStorageTrie(objs []*stateObject, fn func(so *stateObject) error) error {
+	if len(objs) == 0 {
+		return nil
+	}
+	numWorkers := (len(objs) + storageTrieBatchSize - 1) / storageTrieBatchSize
+	if numWorkers > storageTrieWorkers {
+		numWorkers = storageTrieWorkers
+	}
+	errs := make([]error, len(objs))
+	if numWorkers <= 1 {
+		for i, so := range objs {
+			errs[i] = fn(so)
+		}
+	} else {
+		batchCh := make(chan int, numWorkers)
+		var wg sync.WaitGroup
+		wg.Add(numWorkers)
+		for w := 0; w < numWorkers; w++ {
+			go func() {
+				defer wg.Done()
+				for start := range batchCh {
+					end := start + storageTrieBatchSize
+					if end > len(objs) {
+						end = len(objs)
+					}
+					for i := start; i < end; i++ {
+						errs[i] = fn(objs[i])
+					}
+				}
+			}()
+		}
+		for start := 0; start < len(objs); start += storageTrieBatchSize {
+			batchCh <- start
+		}
+		close(batchCh)
+		wg.Wait()
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -92,9 +92,39 @@ func (s *StateSuite) TestDump(c *checker.C) {
 	}
 }
 
+func (s *StateSuite) TestRangeDump(c *checker.C) {
+	for i := byte(1); i <= 5; i++ {
+		s.state.GetOrNewStateObject(toAddr([]byte{i})).AddBalance(big.NewInt(int64(i)))
+	}
+	obj := s.state.GetOrNewSmartContract(toAddr([]byte{0x01, 0x02}))
+	obj.SetCode(crypto.Keccak256Hash([]byte{3, 3, 3}), []byte{3, 3, 3})
+	s.state.Commit(false)
+
+	// iterate the accounts by two
+	accounts := make(map[string]DumpAccount)
+	var start []byte
+	for pages := 0; ; pages++ {
+		dump := s.state.RangeDump(start, 2, true, true)
+		c.Assert(len(dump.Accounts) <= 2, checker.Equals, true)
+		for addr, acc := range dump.Accounts {
+			accounts[addr] = acc
+		}
+		if dump.Next == nil {
+			c.Assert(pages, checker.Equals, 2)
+			break
+		}
+		start = dump.Next
+	}
+	c.Assert(len(accounts), checker.Equals, 6)
+	c.Assert(accounts["0000000000000000000000000000000000000003"].Balance, checker.Equals, "3")
+	// the code is excluded
+	c.Assert(accounts["0000000000000000000000000000000000000102"].Code, checker.Equals, "")
+	c.Assert(accounts["0000000000000000000000000000000000000102"].CodeHash, checker.Equals, common.Bytes2Hex(crypto.Keccak256([]byte{3, 3, 3})))
+}
+
 func (s *StateSuite) SetUpTest(c *checker.C) {
 	s.db = database.NewMemoryDBManager()
-	s.state, _ = New(common.Hash{}, NewDatabase(s.db))
+	s.state, _ = New(common.Hash{}, NewDatabase(s.db), nil)
 }
 
 func (s *StateSuite) TestNull(c *checker.C) {
@@ -151,7 +181,7 @@ func (s *StateSuite) TestSnapshotEmpty(c *checker.C) {
 // This test is to compare deleted/non-deleted stateObject after restoring.
 func TestSnapshotForDeletedObject(t *testing.T) {
 	memDB := database.NewMemoryDBManager()
-	state, _ := New(common.Hash{}, NewDatabase(memDB))
+	state, _ := New(common.Hash{}, NewDatabase(memDB), nil)
 
 	stateObjAddr0 := toAddr([]byte("so0"))
 	stateObjAddr1 := toAddr([]byte("so1"))
@@ -27,6 +27,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/klaytn/klaytn/blockchain/state/snapshot"
 	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/blockchain/types/account"
 	"github.com/klaytn/klaytn/blockchain/types/accountkey"
@@ -54,6 +55,10 @@ var (
 
 	// TODO-Klaytn EnabledExpensive and DBConfig.EnableDBPerfMetrics will be merged
 	EnabledExpensive = false
+
+	// StorageTrieOwnerIndexing enables indexing the contract accounts owning the storage trie roots
+	// when the states are committed, so the owner of a storage trie can be found by its root.
+	StorageTrieOwnerIndexing = false
 )
 
 // StateDBs within the Klaytn protocol are used to cache stateObjects from Merkle Patricia Trie
@@ -62,6 +67,12 @@ type StateDB struct {
 	db   Database
 	trie Trie
 
+	snaps         *snapshot.Tree
+	snap          snapshot.Snapshot
+	snapDestructs map[common.Hash]struct{}
+	snapAccounts  map[common.Hash][]byte
+	snapStorage   map[common.Hash]map[common.Hash][]byte
+
 	// This map holds 'live' objects, which will get modified while processing a state transition.
 	stateObjects             map[common.Address]*stateObject
 	stateObjectsDirty        map[common.Address]struct{}
@@ -104,21 +115,40 @@ type StateDB struct {
 }
 
 // Create a new state from a given trie.
-func New(root common.Hash, db Database) (*StateDB, error) {
+// If the snapshot tree is given, the accounts and storage slots are read from
+// the snapshot of the root if available, and the changes are recorded to it on Commit.
+func New(root common.Hash, db Database, snaps *snapshot.Tree) (*StateDB, error) {
 	tr, err := db.OpenTrie(root)
 	if err != nil {
 		return nil, err
 	}
-	return &StateDB{
+	sdb := &StateDB{
 		db:                       db,
 		trie:                     tr,
+		snaps:                    snaps,
 		stateObjects:             make(map[common.Address]*stateObject),
 		stateObjectsDirtyStorage: make(map[common.Address]struct{}),
 		stateObjectsDirty:        make(map[common.Address]struct{}),
 		logs:                     make(map[common.Hash][]*types.Log),
 		preimages:                make(map[common.Hash][]byte),
 		journal:                  newJournal(),
-	}, nil
+	}
+	sdb.setSnapshot(root)
+	return sdb, nil
+}
+
+// setSnapshot sets the snapshot of the given root to read the states from,
+// and resets the changes to be recorded to the snapshot tree.
+func (self *StateDB) setSnapshot(root common.Hash) {
+	self.snap, self.snapDestructs, self.snapAccounts, self.snapStorage = nil, nil, nil, nil
+	if self.snaps == nil {
+		return
+	}
+	if self.snap = self.snaps.Snapshot(root); self.snap != nil {
+		self.snapDestructs = make(map[common.Hash]struct{})
+		self.snapAccounts = make(map[common.Hash][]byte)
+		self.snapStorage = make(map[common.Hash]map[common.Hash][]byte)
+	}
 }
 
 // Create a new state from a given trie with prefetching
@@ -178,6 +208,7 @@ func (self *StateDB) Reset(root common.Hash) error {
 	self.logSize = 0
 	self.preimages = make(map[common.Hash][]byte)
 	self.clearJournalAndRefund()
+	self.setSnapshot(root)
 	return nil
 }
 
@@ -461,6 +492,15 @@ func (self *StateDB) SetState(addr common.Address, key, value common.Hash) {
 	}
 }
 
+// SetStorage replaces the entire storage of the account with the given one.
+// It should be used only to override the state of a call since the storage is not committed.
+func (self *StateDB) SetStorage(addr common.Address, storage map[common.Hash]common.Hash) {
+	stateObject := self.GetOrNewSmartContract(addr)
+	if stateObject != nil {
+		stateObject.SetStorage(storage)
+	}
+}
+
 // UpdateKey updates the account's key with the given key.
 func (self *StateDB) UpdateKey(addr common.Address, newKey accountkey.AccountKey, currentBlockNumber uint64) error {
 	stateObject := self.getStateObject(addr)
@@ -511,12 +551,18 @@ func (self *StateDB) updateStateObject(stateObject *stateObject) {
 		self.setError(self.trie.TryUpdateWithKeys(addr[:],
 			encodedData.trieHashKey, encodedData.trieHexKey, encodedData.data))
 		stateObject.encoded = atomic.Value{}
+		if self.snap != nil {
+			self.snapAccounts[stateObject.addrHash] = encodedData.data
+		}
 	} else {
 		data, err := rlp.EncodeToBytes(stateObject)
 		if err != nil {
 			panic(fmt.Errorf("can't encode object at %x: %v", addr[:], err))
 		}
 		self.setError(self.trie.TryUpdate(addr[:], data))
+		if self.snap != nil {
+			self.snapAccounts[stateObject.addrHash] = data
+		}
 	}
 }
 
@@ -529,6 +575,13 @@ func (self *StateDB) deleteStateObject(stateObject *stateObject) {
 	stateObject.deleted = true
 	addr := stateObject.Address()
 	self.setError(self.trie.TryDelete(addr[:]))
+
+	// The account and its storage slots are deleted from the snapshot as well
+	if self.snap != nil {
+		self.snapDestructs[stateObject.addrHash] = struct{}{}
+		delete(self.snapAccounts, stateObject.addrHash)
+		delete(self.snapStorage, stateObject.addrHash)
+	}
 }
 
 // Retrieve a state object given by the address. Returns nil if not found.
@@ -545,8 +598,18 @@ func (self *StateDB) getStateObject(addr common.Address) *stateObject {
 		defer func(start time.Time) { self.AccountReads += time.Since(start) }(time.Now())
 	}
 	// Second, the object for given address is not cached.
-	// Load the object from the database.
-	enc, err := self.trie.TryGet(addr[:])
+	// Load the object from the snapshot if available, or from the database.
+	var (
+		enc []byte
+		err error
+	)
+	if self.snap != nil {
+		enc, err = self.snap.AccountRLP(crypto.Keccak256Hash(addr[:]))
+	}
+	// If the snapshot is unavailable or reading from it failed, load from the database
+	if self.snap == nil || err != nil {
+		enc, err = self.trie.TryGet(addr[:])
+	}
 	if len(enc) == 0 {
 		self.setError(err)
 		return nil
@@ -590,6 +653,21 @@ func (self *StateDB) GetOrNewSmartContract(addr common.Address) *stateObject {
 	return stateObject
 }
 
+// resetObject marks the account of the overwritten object as destructed in the snapshot,
+// and returns the journal entry to restore the object.
+func (self *StateDB) resetObject(prev *stateObject) resetObjectChange {
+	change := resetObjectChange{prev: prev}
+	if self.snap != nil {
+		// The storage slots of the previous account recorded so far are dropped
+		// as they don't belong to the new account.
+		_, change.prevdestruct = self.snapDestructs[prev.addrHash]
+		change.prevStorage = self.snapStorage[prev.addrHash]
+		self.snapDestructs[prev.addrHash] = struct{}{}
+		delete(self.snapStorage, prev.addrHash)
+	}
+	return change
+}
+
 // createObject creates a new state object. If there is an existing account with
 // the given address, it is overwritten and returned as the second return value.
 func (self *StateDB) createObject(addr common.Address) (newobj, prev *stateObject) {
@@ -603,7 +681,7 @@ func (self *StateDB) createObject(addr common.Address) (newobj, prev *stateObjec
 	if prev == nil {
 		self.journal.append(createObjectChange{account: &addr})
 	} else {
-		self.journal.append(resetObjectChange{prev: prev})
+		self.journal.append(self.resetObject(prev))
 	}
 	self.setStateObject(newobj)
 	return newobj, prev
@@ -624,7 +702,7 @@ func (self *StateDB) createObjectWithMap(addr common.Address, accountType accoun
 	if prev == nil {
 		self.journal.append(createObjectChange{account: &addr})
 	} else {
-		self.journal.append(resetObjectChange{prev: prev})
+		self.journal.append(self.resetObject(prev))
 	}
 	self.setStateObject(newobj)
 	return newobj, prev
@@ -705,6 +783,8 @@ func (self *StateDB) Copy() *StateDB {
 		logSize:           self.logSize,
 		preimages:         make(map[common.Hash][]byte),
 		journal:           newJournal(),
+
+		stateObjectsDirtyStorage: make(map[common.Address]struct{}, len(self.stateObjectsDirtyStorage)),
 	}
 	// Copy the dirty states, logs, and preimages
 	for addr := range self.journal.dirties {
@@ -727,12 +807,47 @@ func (self *StateDB) Copy() *StateDB {
 		}
 	}
 
+	// The storage roots of the objects are updated later, so the copy should update them too
+	for addr := range self.stateObjectsDirtyStorage {
+		if _, exist := state.stateObjects[addr]; !exist {
+			state.stateObjects[addr] = self.stateObjects[addr].deepCopy(state)
+		}
+		state.stateObjectsDirtyStorage[addr] = struct{}{}
+	}
+
 	deepCopyLogs(self, state)
 
 	for hash, preimage := range self.preimages {
 		state.preimages[hash] = preimage
 	}
 
+	if self.snaps != nil {
+		// In order for the miner to be able to use and make additions
+		// to the snapshot tree, we need to copy that as well.
+		// Otherwise, any block mined by ourselves will cause gaps in the tree,
+		// and force the miner to operate trie-backed only
+		state.snaps = self.snaps
+		state.snap = self.snap
+		// deep copy needed
+		if self.snap != nil {
+			state.snapDestructs = make(map[common.Hash]struct{}, len(self.snapDestructs))
+			for k, v := range self.snapDestructs {
+				state.snapDestructs[k] = v
+			}
+			state.snapAccounts = make(map[common.Hash][]byte, len(self.snapAccounts))
+			for k, v := range self.snapAccounts {
+				state.snapAccounts[k] = v
+			}
+			state.snapStorage = make(map[common.Hash]map[common.Hash][]byte, len(self.snapStorage))
+			for k, v := range self.snapStorage {
+				temp := make(map[common.Hash][]byte, len(v))
+				for kk, vv := range v {
+					temp[kk] = vv
+				}
+				state.snapStorage[k] = temp
+			}
+		}
+	}
 	return state
 }
 
@@ -796,8 +911,11 @@ func (stateDB *StateDB) Finalise(deleteEmptyObjects bool, setStorageRoot bool) {
 			stateDB.deleteStateObject(so)
 		} else {
 			so.updateStorageTrie(stateDB.db)
-			so.setStorageRoot(setStorageRoot, stateDB.stateObjectsDirtyStorage)
-			stateDB.updateStateObject(so)
+			so.markStorageRoot(stateDB.stateObjectsDirtyStorage)
+			// The objects whose storage roots are hashed below are updated after the hashing.
+			if _, marked := stateDB.stateObjectsDirtyStorage[addr]; !setStorageRoot || !marked {
+				stateDB.updateStateObject(so)
+			}
 		}
 		stateDB.stateObjectsDirty[addr] = struct{}{}
 	}
@@ -805,13 +923,24 @@ func (stateDB *StateDB) Finalise(deleteEmptyObjects bool, setStorageRoot bool) {
 	stateDB.clearJournalAndRefund()
 
 	if setStorageRoot && len(stateDB.stateObjectsDirtyStorage) > 0 {
+		objs := make([]*stateObject, 0, len(stateDB.stateObjectsDirtyStorage))
 		for addr := range stateDB.stateObjectsDirtyStorage {
-			so, exist := stateDB.stateObjects[addr]
-			if exist {
-				so.updateStorageRoot(stateDB.db)
-				stateDB.updateStateObject(so)
+			if so, exist := stateDB.stateObjects[addr]; exist {
+				objs = append(objs, so)
 			}
 		}
+		// Hash the storage tries concurrently, tracking the amount of time wasted on it
+		start := time.Now()
+		forEachStorageTrie(objs, func(so *stateObject) error {
+			so.updateStorageRoot()
+			return nil
+		})
+		if EnabledExpensive {
+			stateDB.StorageHashes += time.Since(start)
+		}
+		for _, so := range objs {
+			stateDB.updateStateObject(so)
+		}
 		stateDB.stateObjectsDirtyStorage = make(map[common.Address]struct{})
 	}
 }
@@ -851,7 +980,11 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (root common.Hash, err error)
 	}
 
 	objectEncoder := getStateObjectEncoder(len(s.stateObjects))
-	var stateObjectsToUpdate []*stateObject
+	var stateObjectsToUpdate, programAccounts []*stateObject
+	var storageRoots map[common.Address]common.Hash
+	if StorageTrieOwnerIndexing {
+		storageRoots = make(map[common.Address]common.Hash)
+	}
 	// Commit objects to the trie.
 	for addr, stateObject := range s.stateObjects {
 		_, isDirty := s.stateObjectsDirty[addr]
@@ -868,21 +1001,54 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (root common.Hash, err error)
 					stateObject.dirtyCode = false
 				}
 				// Write any storage changes in the state object to its storage trie.
-				if err := stateObject.CommitStorageTrie(s.db); err != nil {
-					return common.Hash{}, err
+				stateObject.updateStorageTrie(s.db)
+				if stateObject.dbErr != nil {
+					return common.Hash{}, stateObject.dbErr
 				}
+				// The object is encoded after its storage trie is committed below.
+				programAccounts = append(programAccounts, stateObject)
+			} else {
+				objectEncoder.encode(stateObject)
 			}
 			// Update the object in the main account trie.
 			stateObjectsToUpdate = append(stateObjectsToUpdate, stateObject)
-			objectEncoder.encode(stateObject)
 		}
 		delete(s.stateObjectsDirty, addr)
 	}
 
+	// Commit the storage tries concurrently, tracking the amount of time wasted on it
+	start := time.Now()
+	err = forEachStorageTrie(programAccounts, func(so *stateObject) error {
+		if err := so.commitStorageTrie(); err != nil {
+			return err
+		}
+		objectEncoder.encode(so)
+		return nil
+	})
+	if EnabledExpensive {
+		s.StorageCommits += time.Since(start)
+	}
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if storageRoots != nil {
+		for _, so := range programAccounts {
+			if pa := account.GetProgramAccount(so.account); pa != nil && pa.GetStorageRoot() != emptyRoot {
+				storageRoots[so.address] = pa.GetStorageRoot()
+			}
+		}
+	}
+
 	for _, so := range stateObjectsToUpdate {
 		s.updateStateObject(so)
 	}
 
+	if len(storageRoots) > 0 {
+		if err := s.db.TrieDB().DiskDB().WriteStorageTrieOwners(storageRoots); err != nil {
+			logger.Error("Failed to write storage trie owners", "err", err)
+		}
+	}
+
 	// Write the account trie changes, measuring the amount of wasted time
 	if EnabledExpensive {
 		defer func(start time.Time) { s.AccountCommits += time.Since(start) }(time.Now())
@@ -905,6 +1071,20 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (root common.Hash, err error)
 		}
 		return nil
 	})
+	// If snapshotting is enabled, update the snapshot tree with this new version
+	if s.snap != nil {
+		// Only update if there's a state transition
+		if parent := s.snap.Root(); err == nil && parent != root {
+			if err := s.snaps.Update(root, parent, s.snapDestructs, s.snapAccounts, s.snapStorage); err != nil {
+				logger.Warn("Failed to update snapshot tree", "from", parent, "to", root, "err", err)
+			}
+			// Keep 128 diff layers in the memory, persistent layer is 129th.
+			if err := s.snaps.Cap(root, 128); err != nil {
+				logger.Warn("Failed to cap snapshot tree", "root", root, "layers", 128, "err", err)
+			}
+		}
+		s.snap, s.snapDestructs, s.snapAccounts, s.snapStorage = nil, nil, nil, nil
+	}
 	return root, err
 }
 
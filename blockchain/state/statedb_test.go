@@ -32,8 +32,10 @@ import (
 	"testing"
 	"testing/quick"
 
+	"github.com/klaytn/klaytn/blockchain/state/snapshot"
 	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/storage/database"
 	"github.com/klaytn/klaytn/storage/statedb"
@@ -46,7 +48,7 @@ func TestUpdateLeaks(t *testing.T) {
 	// Create an empty state database
 	memDBManager := database.NewMemoryDBManager()
 	db := memDBManager.GetMemDB()
-	state, _ := New(common.Hash{}, NewDatabase(memDBManager))
+	state, _ := New(common.Hash{}, NewDatabase(memDBManager), nil)
 
 	// Update it with some accounts
 	for i := byte(0); i < 255; i++ {
@@ -79,8 +81,8 @@ func TestIntermediateLeaks(t *testing.T) {
 	transDb := transDBManager.GetMemDB()
 	finalDb := finalDBManager.GetMemDB()
 
-	transState, _ := New(common.Hash{}, NewDatabase(transDBManager))
-	finalState, _ := New(common.Hash{}, NewDatabase(finalDBManager))
+	transState, _ := New(common.Hash{}, NewDatabase(transDBManager), nil)
+	finalState, _ := New(common.Hash{}, NewDatabase(finalDBManager), nil)
 
 	modify := func(state *StateDB, addr common.Address, i, tweak byte) {
 		if i%2 == 0 {
@@ -133,7 +135,7 @@ func TestIntermediateLeaks(t *testing.T) {
 // https://github.com/ethereum/go-ethereum/pull/15549.
 func TestCopy(t *testing.T) {
 	// Create a random state test to copy and modify "independently"
-	orig, _ := New(common.Hash{}, NewDatabase(database.NewMemoryDBManager()))
+	orig, _ := New(common.Hash{}, NewDatabase(database.NewMemoryDBManager()), nil)
 
 	for i := byte(0); i < 255; i++ {
 		obj := orig.GetOrNewStateObject(common.BytesToAddress([]byte{i}))
@@ -195,7 +197,7 @@ func TestSnapshotRandom(t *testing.T) {
 // TestStateObjects tests basic functional operations of StateObjects.
 // It will be updated by StateDB.Commit() with state objects in StateDB.stateObjects.
 func TestStateObjects(t *testing.T) {
-	stateDB, _ := New(common.Hash{}, NewDatabase(database.NewMemoryDBManager()))
+	stateDB, _ := New(common.Hash{}, NewDatabase(database.NewMemoryDBManager()), nil)
 
 	// Update each account, it will update StateDB.stateObjects.
 	for i := byte(0); i < 128; i++ {
@@ -364,7 +366,7 @@ func (test *snapshotTest) String() string {
 func (test *snapshotTest) run() bool {
 	// Run all actions and create snapshots.
 	var (
-		state, _     = New(common.Hash{}, NewDatabase(database.NewMemoryDBManager()))
+		state, _     = New(common.Hash{}, NewDatabase(database.NewMemoryDBManager()), nil)
 		snapshotRevs = make([]int, len(test.snapshots))
 		sindex       = 0
 	)
@@ -378,7 +380,7 @@ func (test *snapshotTest) run() bool {
 	// Revert all snapshots in reverse order. Each revert must yield a state
 	// that is equivalent to fresh state with all actions up the snapshot applied.
 	for sindex--; sindex >= 0; sindex-- {
-		checkstate, _ := New(common.Hash{}, state.Database())
+		checkstate, _ := New(common.Hash{}, state.Database(), nil)
 		for _, action := range test.actions[:test.snapshots[sindex]] {
 			action.fn(action, checkstate)
 		}
@@ -457,7 +459,7 @@ func (s *StateSuite) TestSnapshotWithJournalDirties(c *check.C) {
 // TestCopyOfCopy tests that modified objects are carried over to the copy, and the copy of the copy.
 // See https://github.com/ethereum/go-ethereum/pull/15225#issuecomment-380191512
 func TestCopyOfCopy(t *testing.T) {
-	sdb, _ := New(common.Hash{}, NewDatabase(database.NewMemoryDBManager()))
+	sdb, _ := New(common.Hash{}, NewDatabase(database.NewMemoryDBManager()), nil)
 	addr := common.HexToAddress("aaaa")
 	sdb.SetBalance(addr, big.NewInt(42))
 
@@ -484,3 +486,151 @@ func TestZeroHashNode(t *testing.T) {
 		t.Fatalf("node should return nil value for zero hash")
 	}
 }
+
+// TestStorageTrieOwnerIndexing tests if the contract accounts owning the storage trie roots are indexed
+// when the state is committed.
+func TestStorageTrieOwnerIndexing(t *testing.T) {
+	defer func() { StorageTrieOwnerIndexing = false }()
+
+	var (
+		dbm      = database.NewMemoryDBManager()
+		sdb, _   = New(common.Hash{}, NewDatabase(dbm), nil)
+		key      = common.HexToHash("0x01")
+		c1       = common.HexToAddress("0xc1")
+		c2       = common.HexToAddress("0xc2")
+		c3       = common.HexToAddress("0xc3")
+		noStore  = common.HexToAddress("0xc4")
+		rules    = params.Rules{IsIstanbul: true}
+		rootOf   = func(addr common.Address) common.Hash { root, _ := sdb.GetContractStorageRoot(addr); return root }
+		ownersOf = func(addr common.Address) []common.Address { return dbm.ReadStorageTrieOwners(rootOf(addr)) }
+	)
+	for _, addr := range []common.Address{c1, c2, c3, noStore} {
+		sdb.CreateSmartContractAccount(addr, params.CodeFormatEVM, rules)
+	}
+	sdb.SetState(c1, key, common.HexToHash("0x0a"))
+	sdb.SetState(c2, key, common.HexToHash("0x0a"))
+	sdb.SetState(c3, key, common.HexToHash("0x0b"))
+
+	// The roots are not indexed if the indexing is disabled
+	_, err := sdb.Commit(false)
+	assert.NoError(t, err)
+	assert.Nil(t, ownersOf(c1))
+
+	StorageTrieOwnerIndexing = true
+	sdb.SetState(c1, key, common.HexToHash("0x0c"))
+	sdb.SetState(c2, key, common.HexToHash("0x0c"))
+	sdb.SetState(c3, key, common.HexToHash("0x0d"))
+	_, err = sdb.Commit(false)
+	assert.NoError(t, err)
+
+	// The contracts having the same storage share the root
+	assert.ElementsMatch(t, []common.Address{c1, c2}, ownersOf(c1))
+	assert.Equal(t, []common.Address{c3}, ownersOf(c3))
+
+	// The previous root is still owned after the storage is changed
+	previous := rootOf(c3)
+	sdb.SetState(c3, key, common.HexToHash("0x0e"))
+	_, err = sdb.Commit(false)
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Address{c3}, dbm.ReadStorageTrieOwners(previous))
+	assert.Equal(t, []common.Address{c3}, ownersOf(c3))
+
+	// The empty storage root is not indexed
+	assert.Nil(t, ownersOf(noStore))
+}
+
+// TestStateDBWithSnapshot tests if the states are read from the snapshot tree,
+// and the changes are recorded to the snapshot tree when the state is committed.
+func TestStateDBWithSnapshot(t *testing.T) {
+	var (
+		dbm    = database.NewMemoryDBManager()
+		db     = NewDatabase(dbm)
+		sdb, _ = New(common.Hash{}, db, nil)
+		key    = common.HexToHash("0x01")
+		eoa    = common.HexToAddress("0xe1")
+		c1     = common.HexToAddress("0xc1")
+		c2     = common.HexToAddress("0xc2")
+		rules  = params.Rules{IsIstanbul: true}
+	)
+	sdb.AddBalance(eoa, big.NewInt(1))
+	for _, addr := range []common.Address{c1, c2} {
+		sdb.CreateSmartContractAccount(addr, params.CodeFormatEVM, rules)
+		sdb.SetNonce(addr, 1)
+		sdb.SetState(addr, key, common.HexToHash("0x0a"))
+	}
+	root, err := sdb.Commit(false)
+	assert.NoError(t, err)
+
+	snaps := snapshot.New(dbm, db.TrieDB(), 16, root, false)
+
+	// The states are read from the snapshot
+	sdb, _ = New(root, db, snaps)
+	assert.NotNil(t, sdb.snap)
+	assert.Equal(t, big.NewInt(1), sdb.GetBalance(eoa))
+	assert.Equal(t, common.HexToHash("0x0a"), sdb.GetState(c1, key))
+	assert.Equal(t, common.HexToHash("0x0a"), sdb.GetState(c2, key))
+
+	// The changes are recorded to a new layer on commit
+	sdb.AddBalance(eoa, big.NewInt(1))
+	sdb.SetState(c1, key, common.HexToHash("0x0b"))
+	sdb.Suicide(c2)
+	newRoot, err := sdb.Commit(true)
+	assert.NoError(t, err)
+
+	snap := snaps.Snapshot(newRoot)
+	if !assert.NotNil(t, snap) {
+		return
+	}
+	enc, err := snap.Storage(crypto.Keccak256Hash(c2[:]), crypto.Keccak256Hash(key[:]))
+	assert.NoError(t, err)
+	assert.Nil(t, enc)
+
+	// The states read from the snapshot are the same as the ones from the trie
+	for _, snaps := range []*snapshot.Tree{snaps, nil} {
+		sdb, _ = New(newRoot, db, snaps)
+		assert.Equal(t, big.NewInt(2), sdb.GetBalance(eoa))
+		assert.Equal(t, common.HexToHash("0x0b"), sdb.GetState(c1, key))
+		assert.False(t, sdb.Exist(c2))
+		assert.Equal(t, common.Hash{}, sdb.GetState(c2, key))
+	}
+}
+
+// TestCommitStorageTriesConcurrently tests if the storage tries committed concurrently result in
+// the same state as the ones committed serially.
+func TestCommitStorageTriesConcurrently(t *testing.T) {
+	defer func(workers int) { storageTrieWorkers = workers }(storageTrieWorkers)
+
+	rules := params.Rules{IsIstanbul: true}
+	commit := func(workers int) (common.Hash, common.Hash, *StateDB) {
+		storageTrieWorkers = workers
+		db := NewDatabase(database.NewMemoryDBManager())
+		sdb, _ := New(common.Hash{}, db, nil)
+		for i := 0; i < 10*storageTrieBatchSize; i++ {
+			addr := common.BigToAddress(big.NewInt(int64(i + 1)))
+			sdb.CreateSmartContractAccount(addr, params.CodeFormatEVM, rules)
+			for j := 0; j <= i%8; j++ {
+				sdb.SetState(addr, common.BigToHash(big.NewInt(int64(j))), common.BigToHash(big.NewInt(int64(i+1))))
+			}
+			sdb.AddBalance(common.BigToAddress(big.NewInt(int64(0x10000+i))), big.NewInt(int64(i+1)))
+		}
+		intermediate := sdb.IntermediateRoot(false)
+		root, err := sdb.Commit(false)
+		assert.NoError(t, err)
+		assert.NoError(t, db.TrieDB().Commit(root, false, 0))
+		committed, err := New(root, db, nil)
+		assert.NoError(t, err)
+		return intermediate, root, committed
+	}
+
+	serialIntermediate, serialRoot, _ := commit(1)
+	intermediate, root, committed := commit(4)
+	assert.Equal(t, serialIntermediate, intermediate)
+	assert.Equal(t, serialRoot, root)
+	assert.Equal(t, root, intermediate)
+
+	// The storage of the contracts can be read from the committed state
+	for i := 0; i < 10*storageTrieBatchSize; i++ {
+		addr := common.BigToAddress(big.NewInt(int64(i + 1)))
+		assert.Equal(t, common.BigToHash(big.NewInt(int64(i+1))), committed.GetState(addr, common.BigToHash(big.NewInt(int64(i%8)))))
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/klaytn/klaytn/common"
+)
+
+// StorageDiff is a change of a storage slot.
+type StorageDiff struct {
+	Key   common.Hash
+	Prev  common.Hash
+	Value common.Hash
+}
+
+// AccountDiff is a change of an account. The previous and current values of a field
+// are set only if the field is changed.
+type AccountDiff struct {
+	Address  common.Address
+	Created  bool
+	Suicided bool
+
+	BalanceChanged bool
+	PrevBalance    *big.Int
+	Balance        *big.Int
+
+	NonceChanged bool
+	PrevNonce    uint64
+	Nonce        uint64
+
+	CodeChanged  bool
+	PrevCodeHash common.Hash
+	CodeHash     common.Hash
+
+	Storage []StorageDiff
+}
+
+// Diff returns the changes of the accounts made since the last Finalise, in the order of
+// the first change of each account. The previous values are taken from the journal, so it
+// should be called before the state is finalised. Reverted changes are not included.
+func (self *StateDB) Diff() []*AccountDiff {
+	var (
+		diffs       []*AccountDiff
+		accounts    = make(map[common.Address]*AccountDiff)
+		balanceSeen = make(map[common.Address]struct{})
+		nonceSeen   = make(map[common.Address]struct{})
+		codeSeen    = make(map[common.Address]struct{})
+		storageSeen = make(map[common.Address]map[common.Hash]common.Hash)
+	)
+	get := func(addr common.Address) *AccountDiff {
+		diff, ok := accounts[addr]
+		if !ok {
+			diff = &AccountDiff{Address: addr}
+			accounts[addr] = diff
+			diffs = append(diffs, diff)
+		}
+		return diff
+	}
+
+	for _, entry := range self.journal.entries {
+		switch ch := entry.(type) {
+		case createObjectChange:
+			get(*ch.account).Created = true
+		case resetObjectChange:
+			diff := get(ch.prev.address)
+			diff.Created = true
+			if _, ok := balanceSeen[diff.Address]; !ok {
+				balanceSeen[diff.Address] = struct{}{}
+				diff.PrevBalance = new(big.Int).Set(ch.prev.Balance())
+			}
+		case suicideChange:
+			diff := get(*ch.account)
+			diff.Suicided = true
+			if _, ok := balanceSeen[diff.Address]; !ok {
+				balanceSeen[diff.Address] = struct{}{}
+				diff.PrevBalance = new(big.Int).Set(ch.prevbalance)
+			}
+		case balanceChange:
+			diff := get(*ch.account)
+			if _, ok := balanceSeen[diff.Address]; !ok {
+				balanceSeen[diff.Address] = struct{}{}
+				diff.PrevBalance = new(big.Int).Set(ch.prev)
+			}
+		case nonceChange:
+			diff := get(*ch.account)
+			if _, ok := nonceSeen[diff.Address]; !ok {
+				nonceSeen[diff.Address] = struct{}{}
+				diff.PrevNonce = ch.prev
+			}
+		case codeChange:
+			diff := get(*ch.account)
+			if _, ok := codeSeen[diff.Address]; !ok {
+				codeSeen[diff.Address] = struct{}{}
+				diff.PrevCodeHash = common.BytesToHash(ch.prevhash)
+			}
+		case storageChange:
+			get(*ch.account)
+			slots, ok := storageSeen[*ch.account]
+			if !ok {
+				slots = make(map[common.Hash]common.Hash)
+				storageSeen[*ch.account] = slots
+			}
+			if _, ok := slots[ch.key]; !ok {
+				slots[ch.key] = ch.prevalue
+			}
+		}
+	}
+
+	result := diffs[:0]
+	for _, diff := range diffs {
+		addr := diff.Address
+		if _, ok := balanceSeen[addr]; ok {
+			diff.Balance = self.GetBalance(addr)
+			diff.BalanceChanged = diff.PrevBalance.Cmp(diff.Balance) != 0
+		}
+		if _, ok := nonceSeen[addr]; ok {
+			diff.Nonce = self.GetNonce(addr)
+			diff.NonceChanged = diff.PrevNonce != diff.Nonce
+		}
+		if _, ok := codeSeen[addr]; ok {
+			diff.CodeHash = self.GetCodeHash(addr)
+			diff.CodeChanged = diff.PrevCodeHash != diff.CodeHash
+		}
+		for key, prev := range storageSeen[addr] {
+			if value := self.GetState(addr, key); value != prev {
+				diff.Storage = append(diff.Storage, StorageDiff{Key: key, Prev: prev, Value: value})
+			}
+		}
+		sort.Slice(diff.Storage, func(i, j int) bool {
+			return bytes.Compare(diff.Storage[i].Key[:], diff.Storage[j].Key[:]) < 0
+		})
+
+		if !diff.BalanceChanged {
+			diff.PrevBalance, diff.Balance = nil, nil
+		}
+		if diff.Created || diff.Suicided || diff.BalanceChanged || diff.NonceChanged || diff.CodeChanged || len(diff.Storage) > 0 {
+			result = append(result, diff)
+		}
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateDB_Diff(t *testing.T) {
+	var (
+		addr1 = common.Address{1}
+		addr2 = common.Address{2}
+		addr3 = common.Address{3}
+		key1  = common.Hash{1}
+		key2  = common.Hash{2}
+	)
+	s, _ := New(common.Hash{}, NewDatabase(database.NewMemoryDBManager()), nil)
+	s.AddBalance(addr1, big.NewInt(10))
+	s.SetNonce(addr2, 1) // not to be deleted as an empty account
+	s.SetState(addr2, key1, common.Hash{1})
+	s.SetState(addr2, key2, common.Hash{2})
+	s.Finalise(true, true)
+
+	// changes of the previous transaction are not included
+	s.SubBalance(addr1, big.NewInt(3))
+	s.SetNonce(addr1, 1)
+	s.SetState(addr2, key1, common.Hash{3})
+	s.SetState(addr2, key1, common.Hash{4})
+	s.SetState(addr2, key2, common.Hash{5})
+	s.SetState(addr2, key2, common.Hash{2}) // restored to the previous value
+
+	// reverted changes are not included
+	snapshot := s.Snapshot()
+	s.AddBalance(addr3, big.NewInt(1))
+	s.RevertToSnapshot(snapshot)
+
+	diffs := s.Diff()
+	assert.Equal(t, 2, len(diffs))
+
+	assert.Equal(t, addr1, diffs[0].Address)
+	assert.True(t, diffs[0].BalanceChanged)
+	assert.Equal(t, big.NewInt(10), diffs[0].PrevBalance)
+	assert.Equal(t, big.NewInt(7), diffs[0].Balance)
+	assert.True(t, diffs[0].NonceChanged)
+	assert.Equal(t, uint64(0), diffs[0].PrevNonce)
+	assert.Equal(t, uint64(1), diffs[0].Nonce)
+	assert.False(t, diffs[0].CodeChanged)
+
+	assert.Equal(t, addr2, diffs[1].Address)
+	assert.False(t, diffs[1].BalanceChanged)
+	assert.Nil(t, diffs[1].Balance)
+	assert.Equal(t, []StorageDiff{{Key: key1, Prev: common.Hash{1}, Value: common.Hash{4}}}, diffs[1].Storage)
+
+	s.Finalise(true, true)
+	assert.Empty(t, s.Diff())
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+)
+
+// livePruner prunes the states of the past blocks while the node is running,
+// bounding the size of the state trie database without stopping the node.
+//
+// A pruning cycle starts when a state is committed to disk at least interval
+// blocks after the previous cycle started. The committed state is marked in a
+// bloom filter, and every trie node written to disk from then on is added to
+// it as well, so that the states of the following blocks, which are derived
+// from the marked state, are kept entirely. All the other entries of the state
+// trie database are deleted in background while the blocks are processed.
+//
+// The states older than the marked one may become unreadable even if they are
+// still cached in memory, which is safe as the blocks of Klaytn are final. The
+// cycles are skipped while the state migration is in progress or the state
+// snapshot is being generated, since they read the past states from disk.
+type livePruner struct {
+	bc        *BlockChain
+	interval  uint64 // number of blocks between the starts of the cycles
+	bloomSize uint64 // size of the bloom filter in megabytes
+	last      uint64 // block number where the last cycle started
+
+	lock    sync.Mutex // held while the bloom is updated by the trie writes, to be checked again before deleting
+	running int32      // 1 while a cycle is in progress, must be atomically accessed
+}
+
+func newLivePruner(bc *BlockChain, interval, bloomSize uint64) *livePruner {
+	if bloomSize == 0 {
+		bloomSize = DefaultStatePruningBloomSize
+	}
+	logger.Info("Live state pruning is enabled", "interval", interval, "bloomSize", bloomSize)
+	return &livePruner{
+		bc:        bc,
+		interval:  interval,
+		bloomSize: bloomSize,
+		last:      bc.CurrentBlock().NumberU64(),
+	}
+}
+
+// isRunning returns true if a pruning cycle is in progress.
+func (p *livePruner) isRunning() bool {
+	return atomic.LoadInt32(&p.running) == 1
+}
+
+// committed is called after the state of the given root at the given block is
+// committed to disk, and starts a pruning cycle keeping the state if it is due.
+func (p *livePruner) committed(number uint64, root common.Hash) {
+	if p.isRunning() || number < p.last+p.interval {
+		return
+	}
+	if p.bc.db.InMigration() || p.bc.prepareStateMigration {
+		logger.Debug("Live state pruning is skipped during the state migration", "block", number)
+		return
+	}
+	if p.bc.snaps != nil && p.bc.snaps.Generating() {
+		logger.Debug("Live state pruning is skipped while generating the state snapshot", "block", number)
+		return
+	}
+	bloom, err := newStateBloom(p.bloomSize)
+	if err != nil {
+		logger.Error("Failed to create the state bloom for the live state pruning", "err", err)
+		return
+	}
+	p.last = number
+	atomic.StoreInt32(&p.running, 1)
+
+	// No trie node is written between the commit and here, since both run while processing the block
+	p.bc.stateCache.TrieDB().SetWriteObserver(func(hash common.Hash) {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		bloom.add(hash)
+	})
+
+	p.bc.wg.Add(1)
+	go p.prune(number, root, bloom)
+}
+
+// prune marks the committed state of the given root in the bloom, and deletes
+// the entries of the state trie database which are not in the bloom.
+func (p *livePruner) prune(number uint64, root common.Hash, bloom *stateBloom) {
+	defer p.bc.wg.Done()
+	defer atomic.StoreInt32(&p.running, 0)
+
+	triedb := p.bc.stateCache.TrieDB()
+	defer triedb.SetWriteObserver(nil)
+
+	start := time.Now()
+	logger.Info("Started pruning the past states", "block", number, "root", root)
+
+	err := markState(triedb, nil, root, bloom, p.bc.quit)
+	if err == nil {
+		err = sweepState(p.bc.db.GetStateTrieDB(), bloom, &p.lock, false, p.bc.quit)
+	}
+	switch err {
+	case nil:
+		logger.Info("Pruned the past states", "block", number, "elapsed", common.PrettyDuration(time.Since(start)))
+	case ErrQuitBySignal:
+		logger.Info("Stopped pruning the past states", "block", number)
+	default:
+		logger.Error("Failed to prune the past states", "block", number, "err", err)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockChain_LiveStatePruning(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		gspec  = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{addr: {Balance: big.NewInt(10000000000000)}}}
+		signer = types.NewEIP155Signer(gspec.Config.ChainID)
+	)
+	dir, err := ioutil.TempDir("", "klaytn-live-pruning")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db := database.NewDBManager(&database.DBConfig{Dir: dir, DBType: database.LevelDB, NumStateTrieShards: 1})
+	defer db.Close()
+	genesis := gspec.MustCommit(db)
+
+	cacheConfig := &CacheConfig{
+		CacheSize:            512,
+		BlockInterval:        4,
+		TriesInMemory:        DefaultTriesInMemory,
+		TrieNodeCacheConfig:  statedb.GetEmptyTrieNodeCacheConfig(),
+		LivePruningInterval:  8,
+		LivePruningBloomSize: 1,
+	}
+	bc, err := NewBlockChain(db, cacheConfig, gspec.Config, gxhash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+
+	// The states of all the blocks are written to the database by the chain generation
+	chain, _ := GenerateChain(gspec.Config, genesis, gxhash.NewFaker(), db, 12, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), common.Address{byte(i + 1)}, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+		gen.AddTx(tx)
+	})
+	for _, block := range chain {
+		ok, _ := db.HasStateTrieNode(block.Root().Bytes())
+		assert.True(t, ok)
+	}
+	// The pruning starts when the state of block 8 is committed
+	if _, err := bc.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100 && bc.livePruner.isRunning(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.False(t, bc.livePruner.isRunning())
+	assert.Equal(t, uint64(8), bc.livePruner.last)
+
+	// Only the states committed since block 8 are kept in the database
+	for _, block := range chain {
+		number := block.NumberU64()
+		ok, _ := db.HasStateTrieNode(block.Root().Bytes())
+		assert.Equal(t, number == 8 || number == 12, ok, "block %d", number)
+	}
+	// The state of the head block is kept entirely
+	head, err := bc.State()
+	if assert.NoError(t, err) {
+		for i := range chain {
+			assert.Equal(t, big.NewInt(1000), head.GetBalance(common.Address{byte(i + 1)}))
+		}
+	}
+	tr, err := statedb.NewTrie(bc.CurrentBlock().Root(), bc.stateCache.TrieDB())
+	if assert.NoError(t, err) {
+		it := tr.NodeIterator(nil)
+		for it.Next(true) {
+		}
+		assert.NoError(t, it.Error())
+	}
+	// The state migration is not started while pruning
+	atomic.StoreInt32(&bc.livePruner.running, 1)
+	assert.Error(t, bc.StartStateMigration(bc.CurrentBlock().NumberU64(), bc.CurrentBlock().Root()))
+	atomic.StoreInt32(&bc.livePruner.running, 0)
+}
@@ -40,6 +40,7 @@ import (
 var (
 	stopWarmUpErr           = errors.New("warm-up terminate by StopWarmUp")
 	blockChainStopWarmUpErr = errors.New("warm-up terminate as blockchain stopped")
+	errStopStateMigration   = errors.New("stop state migration")
 )
 
 type stateTrieMigrationDB struct {
@@ -65,7 +66,7 @@ func (td *stateTrieMigrationDB) ReadPreimage(hash common.Hash) []byte {
 	return td.ReadPreimageFromNew(hash)
 }
 
-func (bc *BlockChain) stateMigrationCommit(s *statedb.TrieSync, batch database.Batch) (int, error) {
+func stateMigrationCommit(s *statedb.TrieSync, batch database.Batch) (int, error) {
 	written, err := s.Commit(batch)
 	if written == 0 || err != nil {
 		return written, err
@@ -81,7 +82,7 @@ func (bc *BlockChain) stateMigrationCommit(s *statedb.TrieSync, batch database.B
 	return written, nil
 }
 
-func (bc *BlockChain) concurrentRead(db *statedb.Database, quitCh chan struct{}, hashCh chan common.Hash, resultCh chan statedb.SyncResult) {
+func concurrentRead(db *statedb.Database, quitCh chan struct{}, hashCh chan common.Hash, resultCh chan statedb.SyncResult) {
 	for {
 		select {
 		case <-quitCh:
@@ -116,18 +117,27 @@ func (bc *BlockChain) migrateState(rootHash common.Hash) (returnErr error) {
 		}
 	}()
 
+	return migrateStateTrie(bc.db, bc.StateCache(), rootHash, bc.stopStateMigration, bc.quit, func(stats *migrationStats, pending int) {
+		bc.readCnt, bc.committedCnt, bc.pendingCnt, bc.progress = stats.totalRead, stats.totalCommitted, pending, stats.progress
+	})
+}
+
+// migrateStateTrie copies the state trie of the given root from StateTrieDB to StateTrieMigrationDB,
+// and checks the consistency of the copied one. The progress is passed to the given function.
+// It stops with an error if stopCh receives, or with ErrQuitBySignal if quitCh is closed.
+func migrateStateTrie(db database.DBManager, srcState state.Database, rootHash common.Hash,
+	stopCh <-chan struct{}, quitCh chan struct{}, progress func(stats *migrationStats, pending int)) error {
 	start := time.Now()
 
-	srcState := bc.StateCache()
-	dstState := state.NewDatabase(&stateTrieMigrationDB{bc.db})
+	dstState := state.NewDatabase(&stateTrieMigrationDB{db})
 
 	// NOTE: lruCache is mandatory when state migration and block processing are executed simultaneously
 	lruCache, _ := lru.New(int(2 * units.Giga / common.HashLength)) // 2GB for 62,500,000 common.Hash key values
 	trieSync := state.NewStateSync(rootHash, dstState.TrieDB().DiskDB(), nil, lruCache)
 	var queue []common.Hash
 
-	quitCh := make(chan struct{})
-	defer close(quitCh)
+	readQuitCh := make(chan struct{})
+	defer close(readQuitCh)
 
 	// Prepare concurrent read goroutines
 	threads := runtime.NumCPU()
@@ -135,7 +145,7 @@ func (bc *BlockChain) migrateState(rootHash common.Hash) (returnErr error) {
 	resultCh := make(chan statedb.SyncResult, threads)
 
 	for th := 0; th < threads; th++ {
-		go bc.concurrentRead(srcState.TrieDB(), quitCh, hashCh, resultCh)
+		go concurrentRead(srcState.TrieDB(), readQuitCh, hashCh, resultCh)
 	}
 
 	stateTrieBatch := dstState.TrieDB().DiskDB().NewBatch(database.StateTrieDB)
@@ -176,7 +186,7 @@ func (bc *BlockChain) migrateState(rootHash common.Hash) (returnErr error) {
 
 		// Commit trie nodes
 		startWrite := time.Now()
-		written, err := bc.stateMigrationCommit(trieSync, stateTrieBatch)
+		written, err := stateMigrationCommit(trieSync, stateTrieBatch)
 		if err != nil {
 			logger.Error("State migration is failed by commit error", "err", err)
 			return fmt.Errorf("failed to commit data #%d: %v", written, err)
@@ -188,16 +198,18 @@ func (bc *BlockChain) migrateState(rootHash common.Hash) (returnErr error) {
 		stats.stateMigrationReport(false, trieSync.Pending(), trieSync.CalcProgressPercentage())
 
 		select {
-		case <-bc.stopStateMigration:
+		case <-stopCh:
 			logger.Info("State migration terminated by request")
-			return errors.New("stop state migration")
-		case <-bc.quit:
+			return errStopStateMigration
+		case <-quitCh:
 			logger.Info("State migration stopped by quit signal; should continue on node restart")
 			return ErrQuitBySignal
 		default:
 		}
 
-		bc.readCnt, bc.committedCnt, bc.pendingCnt, bc.progress = stats.totalRead, stats.totalCommitted, trieSync.Pending(), stats.progress
+		if progress != nil {
+			progress(&stats, trieSync.Pending())
+		}
 	}
 
 	// Flush trie nodes which is not written yet.
@@ -207,7 +219,9 @@ func (bc *BlockChain) migrateState(rootHash common.Hash) (returnErr error) {
 	}
 
 	stats.stateMigrationReport(true, trieSync.Pending(), trieSync.CalcProgressPercentage())
-	bc.readCnt, bc.committedCnt, bc.pendingCnt, bc.progress = stats.totalRead, stats.totalCommitted, trieSync.Pending(), stats.progress
+	if progress != nil {
+		progress(&stats, trieSync.Pending())
+	}
 
 	// Clear memory of trieSync
 	trieSync = nil
@@ -219,7 +233,15 @@ func (bc *BlockChain) migrateState(rootHash common.Hash) (returnErr error) {
 		"totalElapsed", elapsed, "committed per second", speed)
 
 	startCheck := time.Now()
-	if err := state.CheckStateConsistencyParallel(srcState, dstState, rootHash, bc.quit); err != nil {
+	err := state.CheckStateConsistencyParallel(srcState, dstState, rootHash, quitCh)
+	select {
+	case <-quitCh:
+		// The check is stopped without an error, so the migration should be checked again
+		logger.Info("State migration stopped by quit signal while checking; should continue on node restart")
+		return ErrQuitBySignal
+	default:
+	}
+	if err != nil {
 		logger.Error("State migration : copied stateDB is invalid", "err", err)
 		return err
 	}
@@ -330,6 +352,10 @@ func (bc *BlockChain) PrepareStateMigration() error {
 
 func (bc *BlockChain) checkStartStateMigration(number uint64, root common.Hash) bool {
 	if bc.prepareStateMigration {
+		if bc.livePruner != nil && bc.livePruner.isRunning() {
+			logger.Info("State migration is postponed until the live state pruning is done", "block", number)
+			return false
+		}
 		logger.Info("State migration is started", "block", number, "root", root)
 
 		if err := bc.StartStateMigration(number, root); err != nil {
@@ -358,6 +384,9 @@ func (bc *BlockChain) StartStateMigration(number uint64, root common.Hash) error
 	if bc.db.InMigration() {
 		return errors.New("migration already started")
 	}
+	if bc.livePruner != nil && bc.livePruner.isRunning() {
+		return errors.New("live state pruning is in progress")
+	}
 
 	for _, f := range migrationPrerequisites {
 		if err := f(number); err != nil {
@@ -394,13 +423,52 @@ func (bc *BlockChain) StateMigrationStatus() (bool, uint64, int, int, int, float
 	return bc.db.InMigration(), bc.db.MigrationBlockNumber(), bc.readCnt, bc.committedCnt, bc.pendingCnt, bc.progress, bc.migrationErr
 }
 
+// PruneArchiveState converts the state of an archive node into the state of a full node in place,
+// keeping the state trie of the head block only. The state trie is copied to a new database and the
+// old one is removed by the state migration, so the conversion is resumed by calling it again if it
+// is stopped by quit or by a crash. It returns the number of the block whose state is kept.
+//
+// It should be called while the node is not running.
+func PruneArchiveState(db database.DBManager, quit chan struct{}) (uint64, error) {
+	if !db.InMigration() {
+		head := db.ReadHeadBlockHash()
+		number := db.ReadHeaderNumber(head)
+		if number == nil {
+			return 0, errors.New("head block is not found")
+		}
+		header := db.ReadHeader(head, *number)
+		if _, err := state.New(header.Root, state.NewDatabase(db), nil); err != nil {
+			return 0, fmt.Errorf("state of the head block %d is not available: %v", *number, err)
+		}
+		if err := db.CreateMigrationDBAndSetStatus(*number); err != nil {
+			return 0, err
+		}
+		logger.Info("Archive state pruning is started", "block", *number, "root", header.Root)
+	} else {
+		logger.Warn("Archive state pruning is resumed", "block", db.MigrationBlockNumber())
+	}
+
+	number := db.MigrationBlockNumber()
+	header := db.ReadHeader(db.ReadCanonicalHash(number), number)
+	if header == nil {
+		return number, fmt.Errorf("block %d of the state migration is not found", number)
+	}
+	err := migrateStateTrie(db, state.NewDatabase(db), header.Root, nil, quit, nil)
+	if err == ErrQuitBySignal {
+		return number, err
+	}
+	// Wait until the old state trie database is removed
+	<-db.FinishStateMigration(err == nil)
+	return number, err
+}
+
 // iterateStateTrie runs state.Iterator, generated from the given state trie node hash,
 // until it reaches end. If it reaches end, it will send a nil error to errCh to indicate that
 // it has been finished.
 func (bc *BlockChain) iterateStateTrie(root common.Hash, db state.Database, resultCh chan struct{}, errCh chan error) (resultErr error) {
 	defer func() { errCh <- resultErr }()
 
-	stateDB, err := state.New(root, db)
+	stateDB, err := state.New(root, db, nil)
 	if err != nil {
 		return err
 	}
@@ -635,7 +703,7 @@ func printDepthStats(depthCounter map[int]int) {
 
 // GetContractStorageRoot returns the storage root of a contract based on the given block.
 func (bc *BlockChain) GetContractStorageRoot(block *types.Block, db state.Database, contractAddr common.Address) (common.Hash, error) {
-	stateDB, err := state.New(block.Root(), db)
+	stateDB, err := state.New(block.Root(), db, nil)
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to get StateDB - %w", err)
 	}
@@ -684,7 +752,7 @@ func (bc *BlockChain) iterateStorageTrie(child common.Hash, storageTrie state.Tr
 }
 
 func prepareContractWarmUp(block *types.Block, db state.Database, contractAddr common.Address) (common.Hash, state.Trie, error) {
-	stateDB, err := state.New(block.Root(), db)
+	stateDB, err := state.New(block.Root(), db, nil)
 	if err != nil {
 		return common.Hash{}, nil, fmt.Errorf("failed to get StateDB, err: %w", err)
 	}
@@ -0,0 +1,86 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+package blockchain
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPruneArchiveState tests if the states except the head block are removed, and
+// the conversion stopped by quit is resumed.
+func TestPruneArchiveState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "klaytn-prune-archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db := database.NewDBManager(&database.DBConfig{Dir: dir, DBType: database.LevelDB, NumStateTrieShards: 1})
+	defer db.Close()
+
+	var (
+		addrs = []common.Address{{0x01}, {0x02}, {0x03}}
+		roots []common.Hash
+	)
+	stateDB, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	assert.NoError(t, err)
+	for i, addr := range addrs {
+		stateDB.AddBalance(addr, big.NewInt(int64(i+1)))
+		root, err := stateDB.Commit(true)
+		assert.NoError(t, err)
+		assert.NoError(t, stateDB.Database().TrieDB().Commit(root, false, uint64(i)))
+		roots = append(roots, root)
+
+		header := &types.Header{Number: big.NewInt(int64(i)), Root: root, BlockScore: big.NewInt(1), Time: big.NewInt(0)}
+		db.WriteHeader(header)
+		db.WriteCanonicalHash(header.Hash(), header.Number.Uint64())
+		db.WriteHeadBlockHash(header.Hash())
+	}
+
+	// The conversion stopped by quit is resumed
+	quit := make(chan struct{})
+	close(quit)
+	number, err := PruneArchiveState(db, quit)
+	assert.Equal(t, ErrQuitBySignal, err)
+	assert.Equal(t, uint64(2), number)
+	assert.True(t, db.InMigration())
+
+	number, err = PruneArchiveState(db, make(chan struct{}))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), number)
+	assert.False(t, db.InMigration())
+
+	// Only the state of the head block is kept
+	stateDB, err = state.New(roots[2], state.NewDatabase(db), nil)
+	if assert.NoError(t, err) {
+		for i, addr := range addrs {
+			assert.Equal(t, big.NewInt(int64(i+1)), stateDB.GetBalance(addr))
+		}
+	}
+	for _, root := range roots[:2] {
+		_, err := state.New(root, state.NewDatabase(db), nil)
+		assert.Error(t, err)
+	}
+}
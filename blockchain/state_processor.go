@@ -88,7 +88,9 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 		}
 		receipts = append(receipts, receipt)
 		allLogs = append(allLogs, receipt.Logs...)
-		internalTxTraces = append(internalTxTraces, internalTxTrace)
+		if cfg.EnableInternalTxTracing {
+			internalTxTraces = append(internalTxTraces, internalTxTrace)
+		}
 	}
 	processStats.AfterApplyTxs = time.Now()
 
@@ -0,0 +1,337 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/state/snapshot"
+	"github.com/klaytn/klaytn/blockchain/types/account"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/steakknife/bloomfilter"
+)
+
+// DefaultStatePruningBloomSize is the default size of the bloom filter marking
+// the state to keep by the state pruning in megabytes.
+const DefaultStatePruningBloomSize = 2048
+
+var (
+	// pruningEmptyRoot is the known root hash of an empty trie.
+	pruningEmptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+
+	// pruningEmptyCode is the known hash of the empty contract code.
+	pruningEmptyCode = crypto.Keccak256Hash(nil)
+)
+
+// stateBloomHasher is a wrapper around a byte blob to satisfy the interface API
+// requirements of the bloom library used. It's used to convert a trie hash or a
+// contract code hash into a 64 bit mini hash.
+type stateBloomHasher []byte
+
+func (f stateBloomHasher) Write(p []byte) (n int, err error) { panic("not implemented") }
+func (f stateBloomHasher) Sum(b []byte) []byte               { panic("not implemented") }
+func (f stateBloomHasher) Reset()                            { panic("not implemented") }
+func (f stateBloomHasher) BlockSize() int                    { panic("not implemented") }
+func (f stateBloomHasher) Size() int                         { return 8 }
+func (f stateBloomHasher) Sum64() uint64                     { return binary.BigEndian.Uint64(f) }
+
+// stateBloom is a bloom filter of the hashes of the live trie nodes and contract
+// codes, which are kept by the state pruning. A false positive only leaves a dead
+// entry in the database, so the filter never causes a live entry to be deleted.
+type stateBloom struct {
+	bloom *bloomfilter.Filter
+}
+
+// newStateBloom creates a state bloom of the given size in megabytes, which is
+// hard coded to use 4 filters.
+func newStateBloom(size uint64) (*stateBloom, error) {
+	bloom, err := bloomfilter.New(size*1024*1024*8, 4)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Allocated state bloom", "size", common.StorageSize(size*1024*1024))
+	return &stateBloom{bloom: bloom}, nil
+}
+
+func (b *stateBloom) add(hash common.Hash) {
+	b.bloom.Add(stateBloomHasher(hash[:]))
+}
+
+func (b *stateBloom) contains(key []byte) bool {
+	return b.bloom.Contains(stateBloomHasher(key))
+}
+
+// errStateNotPrunable is returned if the state trie database shares a database
+// with the other data, whose entries might be deleted by the state pruning.
+var errStateNotPrunable = errors.New("state pruning requires the state trie database separated from the other data")
+
+// checkStatePrunable returns an error if the state of the database cannot be pruned.
+func checkStatePrunable(db database.DBManager) error {
+	if db.IsSingle() || db.GetDBConfig().DBType == database.MemoryDB {
+		return errStateNotPrunable
+	}
+	return nil
+}
+
+// PruneState deletes the state trie nodes and the contract codes which are not
+// reachable from the state of the head block, so that a full node can reclaim
+// the space taken by the states of the past blocks without syncing the chain
+// again. It returns the number of the block whose state is kept.
+//
+// The state of the head block is marked in a bloom filter of the given size in
+// megabytes, and all the other entries of the state trie database are deleted.
+// The state snapshot of the head block is required, which is persisted when the
+// node is stopped gracefully with the snapshot enabled; it is checked against
+// the kept state, so that the node keeps using it after the pruning. If the
+// pruning is stopped by quit or by a crash, calling it again resumes it.
+//
+// It should be called while the node is not running.
+func PruneState(db database.DBManager, bloomSize uint64, quit chan struct{}) (uint64, error) {
+	if err := checkStatePrunable(db); err != nil {
+		return 0, err
+	}
+	if db.InMigration() {
+		return 0, errors.New("state migration is in progress")
+	}
+	head := db.ReadHeadBlockHash()
+	number := db.ReadHeaderNumber(head)
+	if number == nil {
+		return 0, errors.New("head block is not found")
+	}
+	root := db.ReadHeader(head, *number).Root
+	if snapRoot := db.ReadSnapshotRoot(); snapRoot != root {
+		return *number, fmt.Errorf("state snapshot of the head block is not found (snapshot root %x, head root %x)", snapRoot, root)
+	}
+	bloom, err := newStateBloom(bloomSize)
+	if err != nil {
+		return *number, err
+	}
+	triedb := statedb.NewDatabase(db)
+	snaps := snapshot.New(db, triedb, 16, root, true)
+	snapIt, err := snaps.AccountIterator(root, common.Hash{})
+	if err == snapshot.ErrNotConstructed {
+		// Pause the generation resumed by loading the snapshot
+		snaps.Persist(root)
+		return *number, errors.New("state snapshot of the head block is not fully generated")
+	}
+	if err != nil {
+		return *number, err
+	}
+	err = markState(triedb, snapIt, root, bloom, quit)
+	snapIt.Release()
+	if err != nil {
+		return *number, err
+	}
+	logger.Info("Pruning the state", "block", *number, "root", root)
+	if err := sweepState(db.GetStateTrieDB(), bloom, nil, true, quit); err != nil {
+		return *number, err
+	}
+	return *number, nil
+}
+
+// markState adds the hashes of all the trie nodes and the contract codes of the
+// state of the given root to the bloom. It fails if any of them is missing, or
+// if the accounts of the state do not match the snapshot iterator if given.
+func markState(triedb *statedb.Database, snapIt snapshot.AccountIterator, root common.Hash, bloom *stateBloom, quit chan struct{}) error {
+	tr, err := statedb.NewTrie(root, triedb)
+	if err != nil {
+		return err
+	}
+	var (
+		start    = time.Now()
+		logged   = time.Now()
+		accounts int
+		nodes    int
+	)
+	it := tr.NodeIterator(nil)
+	for it.Next(true) {
+		select {
+		case <-quit:
+			return ErrQuitBySignal
+		default:
+		}
+		if hash := it.Hash(); hash != (common.Hash{}) {
+			bloom.add(hash)
+			nodes++
+		}
+		if !it.Leaf() {
+			continue
+		}
+		key, blob := it.LeafKey(), it.LeafBlob()
+		if snapIt != nil {
+			if !snapIt.Next() || !bytes.Equal(snapIt.Hash().Bytes(), key) || !bytes.Equal(snapIt.Account(), blob) {
+				if err := snapIt.Error(); err != nil {
+					return err
+				}
+				return fmt.Errorf("state snapshot does not match the state at account %x", key)
+			}
+		}
+		serializer := account.NewAccountSerializer()
+		if err := rlp.DecodeBytes(blob, serializer); err != nil {
+			return fmt.Errorf("failed to decode account %x: %v", key, err)
+		}
+		if pa := account.GetProgramAccount(serializer.GetAccount()); pa != nil {
+			n, err := markStorage(triedb, pa, bloom)
+			if err != nil {
+				return fmt.Errorf("failed to mark the storage of account %x: %v", key, err)
+			}
+			nodes += n
+		}
+		accounts++
+		if time.Since(logged) > log.StatsReportLimit {
+			logger.Info("Marking the state to keep", "accounts", accounts, "nodes", nodes, "at", common.BytesToHash(key), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if snapIt != nil {
+		if snapIt.Next() {
+			return fmt.Errorf("state snapshot does not match the state at account %x", snapIt.Hash())
+		}
+		if err := snapIt.Error(); err != nil {
+			return err
+		}
+	}
+	logger.Info("Marked the state to keep", "accounts", accounts, "nodes", nodes, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// markStorage adds the hashes of the storage trie nodes and the contract code of
+// the account to the bloom, returning the number of the entries added.
+func markStorage(triedb *statedb.Database, pa account.ProgramAccount, bloom *stateBloom) (int, error) {
+	nodes := 0
+	if codeHash := common.BytesToHash(pa.GetCodeHash()); codeHash != pruningEmptyCode {
+		if _, err := triedb.Node(codeHash); err != nil {
+			return 0, fmt.Errorf("missing code %x: %v", codeHash, err)
+		}
+		bloom.add(codeHash)
+		nodes++
+	}
+	root := pa.GetStorageRoot()
+	if root == pruningEmptyRoot || root == (common.Hash{}) {
+		return nodes, nil
+	}
+	tr, err := statedb.NewTrie(root, triedb)
+	if err != nil {
+		return 0, err
+	}
+	it := tr.NodeIterator(nil)
+	for it.Next(true) {
+		if hash := it.Hash(); hash != (common.Hash{}) {
+			bloom.add(hash)
+			nodes++
+		}
+	}
+	return nodes, it.Error()
+}
+
+// sweepState deletes the entries of the state trie database keyed by hash which
+// are not in the bloom, and compacts the database to reclaim the space if compact
+// is set. If the state is written while sweeping, the lock should be held while
+// the bloom is updated by the writer, so that the entries are checked against
+// the bloom again and deleted while holding it.
+func sweepState(db database.Database, bloom *stateBloom, lock sync.Locker, compact bool, quit chan struct{}) error {
+	var (
+		start   = time.Now()
+		logged  = time.Now()
+		batch   = db.NewBatch()
+		pending [][]byte
+		kept    int
+		deleted int
+		size    common.StorageSize
+	)
+	// flush deletes the pending entries which are still not in the bloom
+	flush := func() error {
+		if lock != nil {
+			lock.Lock()
+			defer lock.Unlock()
+		}
+		for _, key := range pending {
+			if bloom.contains(key) {
+				kept++
+				continue
+			}
+			if err := batch.Delete(key); err != nil {
+				return err
+			}
+			deleted++
+		}
+		pending = pending[:0]
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+		return nil
+	}
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+
+	for it.Next() {
+		// Only the trie nodes and the contract codes are keyed by their hashes
+		key := it.Key()
+		if len(key) != common.HashLength || bloom.contains(key) {
+			kept++
+			continue
+		}
+		pending = append(pending, common.CopyBytes(key))
+		size += common.StorageSize(len(key) + len(it.Value()))
+
+		if len(pending)*common.HashLength >= database.IdealBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+			select {
+			case <-quit:
+				return ErrQuitBySignal
+			default:
+			}
+		}
+		if time.Since(logged) > log.StatsReportLimit {
+			logger.Info("Pruning the state", "deleted", deleted, "size", size, "kept", kept, "at", common.BytesToHash(key), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	logger.Info("Pruned the state", "deleted", deleted, "size", size, "kept", kept, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	if compacter, ok := db.(database.Compacter); ok && compact {
+		cstart := time.Now()
+		logger.Info("Compacting the state database")
+		if err := compacter.Compact(nil, nil); err != nil {
+			return err
+		}
+		logger.Info("Compacted the state database", "elapsed", common.PrettyDuration(time.Since(cstart)))
+	}
+	return nil
+}
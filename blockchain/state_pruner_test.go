@@ -0,0 +1,150 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/state/snapshot"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPruneState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "klaytn-prune-state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db := database.NewDBManager(&database.DBConfig{Dir: dir, DBType: database.LevelDB, NumStateTrieShards: 1})
+	defer db.Close()
+
+	var (
+		contract = common.Address{0xc0}
+		code     = []byte{0x60, 0x01, 0x60, 0x00, 0x55}
+		addrs    = []common.Address{{0x01}, {0x02}, {0x03}}
+		roots    []common.Hash
+	)
+	for i, addr := range addrs {
+		parent := common.Hash{}
+		if i > 0 {
+			parent = roots[i-1]
+		}
+		stateDB, err := state.New(parent, state.NewDatabase(db), nil)
+		assert.NoError(t, err)
+		if i == 0 {
+			stateDB.CreateSmartContractAccount(contract, params.CodeFormatEVM, params.Rules{})
+			stateDB.SetCode(contract, code)
+		}
+		stateDB.AddBalance(addr, big.NewInt(int64(i+1)))
+		stateDB.SetState(contract, common.Hash{byte(i)}, common.Hash{byte(i + 1)})
+		root, err := stateDB.Commit(true)
+		assert.NoError(t, err)
+		assert.NoError(t, stateDB.Database().TrieDB().Commit(root, false, uint64(i)))
+		roots = append(roots, root)
+
+		header := &types.Header{Number: big.NewInt(int64(i)), Root: root, BlockScore: big.NewInt(1), Time: big.NewInt(0)}
+		db.WriteHeader(header)
+		db.WriteCanonicalHash(header.Hash(), header.Number.Uint64())
+		db.WriteHeadBlockHash(header.Hash())
+	}
+
+	// The state trie database separated from the other data is required
+	_, err = PruneState(database.NewMemoryDBManager(), 1, make(chan struct{}))
+	assert.Equal(t, errStateNotPrunable, err)
+
+	// The snapshot of the head block is required
+	_, err = PruneState(db, 1, make(chan struct{}))
+	assert.Error(t, err)
+
+	snaps := snapshot.New(db, state.NewDatabase(db).TrieDB(), 16, roots[2], false)
+	assert.NoError(t, snaps.Persist(roots[2]))
+
+	// The pruning stopped by quit is resumed
+	quit := make(chan struct{})
+	close(quit)
+	number, err := PruneState(db, 1, quit)
+	assert.Equal(t, ErrQuitBySignal, err)
+	assert.Equal(t, uint64(2), number)
+
+	number, err = PruneState(db, 1, make(chan struct{}))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), number)
+
+	// Only the state of the head block is kept
+	stateDB, err := state.New(roots[2], state.NewDatabase(db), nil)
+	if assert.NoError(t, err) {
+		for i, addr := range addrs {
+			assert.Equal(t, big.NewInt(int64(i+1)), stateDB.GetBalance(addr))
+			assert.Equal(t, common.Hash{byte(i + 1)}, stateDB.GetState(contract, common.Hash{byte(i)}))
+		}
+		assert.Equal(t, code, stateDB.GetCode(contract))
+	}
+	for _, root := range roots[:2] {
+		_, err := state.New(root, state.NewDatabase(db), nil)
+		assert.Error(t, err)
+	}
+	// The state can be pruned again, keeping the snapshot usable
+	_, err = PruneState(db, 1, make(chan struct{}))
+	assert.NoError(t, err)
+}
+
+func TestPruneStateSnapshotMismatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "klaytn-prune-state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db := database.NewDBManager(&database.DBConfig{Dir: dir, DBType: database.LevelDB, NumStateTrieShards: 1})
+	defer db.Close()
+
+	stateDB, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	assert.NoError(t, err)
+	stateDB.AddBalance(common.Address{0x01}, big.NewInt(1))
+	root, err := stateDB.Commit(true)
+	assert.NoError(t, err)
+	assert.NoError(t, stateDB.Database().TrieDB().Commit(root, false, 0))
+
+	header := &types.Header{Number: big.NewInt(0), Root: root, BlockScore: big.NewInt(1), Time: big.NewInt(0)}
+	db.WriteHeader(header)
+	db.WriteCanonicalHash(header.Hash(), 0)
+	db.WriteHeadBlockHash(header.Hash())
+
+	snaps := snapshot.New(db, stateDB.Database().TrieDB(), 16, root, false)
+	assert.NoError(t, snaps.Persist(root))
+
+	// An account missing in the state is left in the snapshot
+	batch := db.NewSnapshotDBBatch()
+	assert.NoError(t, db.PutAccountSnapshotToBatch(batch, common.HexToHash("0xff"), []byte{0x01}))
+	assert.NoError(t, batch.Write())
+
+	_, err = PruneState(db, 1, make(chan struct{}))
+	assert.Error(t, err)
+
+	// Nothing is pruned
+	_, err = state.New(root, state.NewDatabase(db), nil)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,103 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+)
+
+const (
+	// txAccessHintsCacheSize is the number of recipients whose recent accesses are remembered.
+	txAccessHintsCacheSize = 4096
+	// maxTxAccessHintsPerRecipient limits the number of accounts remembered per recipient.
+	maxTxAccessHintsPerRecipient = 16
+)
+
+// TxAccessHints predicts the accounts accessed by a transaction before executing it.
+// The prediction consists of the sender, the fee payer and the recipient of the transaction
+// and the accounts recently accessed by the executed transactions to the same recipient.
+//
+// The prediction is a hint for scheduling and it is not guaranteed to be complete.
+type TxAccessHints struct {
+	recent *lru.Cache // recipient -> []common.Address
+}
+
+// NewTxAccessHints returns a new TxAccessHints remembering the accesses of up to size recipients.
+func NewTxAccessHints(size int) *TxAccessHints {
+	recent, _ := lru.New(size)
+	return &TxAccessHints{recent: recent}
+}
+
+// Record remembers the accounts accessed by the executed transaction, which are the
+// accounts emitting the logs and the created contract in the receipt.
+func (h *TxAccessHints) Record(tx *types.Transaction, receipt *types.Receipt) {
+	if tx.To() == nil || receipt == nil {
+		return
+	}
+	to := *tx.To()
+
+	var accessed []common.Address
+	if cached, ok := h.recent.Get(to); ok {
+		accessed = cached.([]common.Address)
+	}
+	seen := make(map[common.Address]struct{}, len(accessed))
+	for _, addr := range accessed {
+		seen[addr] = struct{}{}
+	}
+	updated := append([]common.Address{}, accessed...)
+	add := func(addr common.Address) {
+		if _, ok := seen[addr]; ok || addr == to || addr == (common.Address{}) {
+			return
+		}
+		seen[addr] = struct{}{}
+		updated = append(updated, addr)
+	}
+	add(receipt.ContractAddress)
+	for _, log := range receipt.Logs {
+		add(log.Address)
+	}
+	if len(updated) == len(accessed) {
+		return
+	}
+	// Keep the most recent accesses
+	if len(updated) > maxTxAccessHintsPerRecipient {
+		updated = updated[len(updated)-maxTxAccessHintsPerRecipient:]
+	}
+	h.recent.Add(to, updated)
+}
+
+// Predict returns the accounts predicted to be accessed by the transaction.
+func (h *TxAccessHints) Predict(signer types.Signer, tx *types.Transaction) []common.Address {
+	var accounts []common.Address
+	if from, err := types.Sender(signer, tx); err == nil {
+		accounts = append(accounts, from)
+	}
+	if tx.IsFeeDelegatedTransaction() {
+		if feePayer, err := tx.FeePayer(); err == nil {
+			accounts = append(accounts, feePayer)
+		}
+	}
+	if to := tx.To(); to != nil {
+		accounts = append(accounts, *to)
+		if cached, ok := h.recent.Get(*to); ok {
+			accounts = append(accounts, cached.([]common.Address)...)
+		}
+	}
+	return accounts
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxAccessHints(t *testing.T) {
+	var (
+		key, _   = crypto.GenerateKey()
+		from     = crypto.PubkeyToAddress(key.PublicKey)
+		signer   = types.NewEIP155Signer(big.NewInt(1))
+		contract = common.Address{0x01}
+		token    = common.Address{0x02}
+		hints    = NewTxAccessHints(txAccessHintsCacheSize)
+	)
+	tx, err := types.SignTx(types.NewTransaction(0, contract, big.NewInt(0), 100000, big.NewInt(1), nil), signer, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without history, the sender and the recipient are predicted
+	assert.Equal(t, []common.Address{from, contract}, hints.Predict(signer, tx))
+
+	// The accounts emitting logs are learned from the receipt
+	receipt := types.NewReceipt(types.ReceiptStatusSuccessful, tx.Hash(), 50000)
+	receipt.Logs = []*types.Log{{Address: token}, {Address: contract}, {Address: token}}
+	hints.Record(tx, receipt)
+	assert.Equal(t, []common.Address{from, contract, token}, hints.Predict(signer, tx))
+
+	// The number of the accounts remembered per recipient is limited
+	for i := 0; i < 2*maxTxAccessHintsPerRecipient; i++ {
+		receipt.Logs = []*types.Log{{Address: common.BigToAddress(big.NewInt(int64(100 + i)))}}
+		hints.Record(tx, receipt)
+	}
+	predicted := hints.Predict(signer, tx)
+	assert.Equal(t, 2+maxTxAccessHintsPerRecipient, len(predicted))
+	assert.Equal(t, common.BigToAddress(big.NewInt(int64(100+2*maxTxAccessHintsPerRecipient-1))), predicted[len(predicted)-1])
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"math"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/klaytn/klaytn/common"
+)
+
+// txArrivalsCacheSize is the number of transactions whose arrival orders are remembered.
+const txArrivalsCacheSize = 1 << 17
+
+// TxArrivals remembers the order in which the transactions arrived at the transaction pool.
+// It is used to build a block in the arrival order of the transactions.
+type TxArrivals struct {
+	mu     sync.Mutex
+	seq    uint64
+	orders *lru.Cache // tx hash -> uint64
+}
+
+// NewTxArrivals returns a new TxArrivals remembering the arrival orders of up to size transactions.
+func NewTxArrivals(size int) *TxArrivals {
+	orders, _ := lru.New(size)
+	return &TxArrivals{orders: orders}
+}
+
+// Record remembers the arrival of the transaction if it has not arrived before.
+func (a *TxArrivals) Record(hash common.Hash) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.orders.Contains(hash) {
+		return
+	}
+	a.seq++
+	a.orders.Add(hash, a.seq)
+}
+
+// Order returns the arrival order of the transaction. The transactions whose arrivals are not
+// remembered, such as the ones reinjected by a chain reorganization, are regarded as the latest.
+func (a *TxArrivals) Order(hash common.Hash) uint64 {
+	if order, ok := a.orders.Peek(hash); ok {
+		return order.(uint64)
+	}
+	return math.MaxUint64
+}
@@ -25,10 +25,25 @@ import (
 	"runtime"
 
 	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/params"
+	"github.com/rcrowley/go-metrics"
 )
 
-// senderCacher is a concurrent transaction sender recoverer and cacher.
-var senderCacher = newTxSenderCacher(calcNumSenderCachers())
+// senderCacheLimit is the number of the transactions whose senders are kept in senderCache.
+// It is large enough to hold the senders of every transaction in a full default txpool.
+const senderCacheLimit = 16384
+
+var (
+	// senderCacher is a concurrent transaction sender recoverer and cacher.
+	senderCacher = newTxSenderCacher(calcNumSenderCachers())
+
+	// senderCache keeps the senders recovered by the txpool, which are reused when the
+	// same transactions are received in blocks, instead of recovering them again.
+	senderCache = types.NewSenderCache(senderCacheLimit)
+
+	senderCacheHitCounter  = metrics.NewRegisteredCounter("chain/sendercache/hit", nil)
+	senderCacheMissCounter = metrics.NewRegisteredCounter("chain/sendercache/miss", nil)
+)
 
 func calcNumSenderCachers() int {
 	numWorkers := math.Ceil(float64(runtime.NumCPU()) * 2.0 / 3.0)
@@ -116,9 +131,21 @@ func (cacher *txSenderCacher) recover(signer types.Signer, txs []*types.Transact
 	}
 }
 
+// RecoverSenders starts recovering the senders of the transactions in the blocks in background,
+// including the public keys of the multi-sig senders and the fee payers, and caches them into
+// the transactions. It is used to recover the senders of the blocks ahead of InsertChain,
+// which does not recover the cached senders again.
+func RecoverSenders(config *params.ChainConfig, blocks types.Blocks) {
+	if len(blocks) == 0 {
+		return
+	}
+	senderCacher.recoverFromBlocks(types.MakeSigner(config, blocks[0].Number()), blocks)
+}
+
 // recoverFromBlocks recovers the senders from a batch of blocks and caches them
-// back into the same data structures. There is no validation being done, nor
-// any reaction to invalid signatures. That is up to calling code later.
+// back into the same data structures. The senders already recovered or found in
+// senderCache are not recovered again. There is no validation being done, nor any
+// reaction to invalid signatures. That is up to calling code later.
 func (cacher *txSenderCacher) recoverFromBlocks(signer types.Signer, blocks []*types.Block) {
 	count := 0
 	for _, block := range blocks {
@@ -126,7 +153,13 @@ func (cacher *txSenderCacher) recoverFromBlocks(signer types.Signer, blocks []*t
 	}
 	txs := make([]*types.Transaction, 0, count)
 	for _, block := range blocks {
-		txs = append(txs, block.Transactions()...)
+		for _, tx := range block.Transactions() {
+			if !types.HasSenders(signer, tx) && !senderCache.Load(signer, tx) {
+				txs = append(txs, tx)
+			}
+		}
 	}
+	senderCacheHitCounter.Inc(int64(count - len(txs)))
+	senderCacheMissCounter.Inc(int64(len(txs)))
 	cacher.recover(signer, txs)
 }
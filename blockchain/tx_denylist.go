@@ -0,0 +1,154 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+)
+
+// TxDenylist is the set of the accounts whose transactions are refused by the transaction pool
+// and the block building of the node. A transaction is refused if its sender, its fee payer or
+// its recipient is on the denylist. The denylist is stored to the given file on every change,
+// so it survives node restarts, and it is kept in memory only if no file is given.
+type TxDenylist struct {
+	path string // Filesystem path to store the denylist at
+
+	mu    sync.RWMutex
+	addrs map[common.Address]struct{}
+}
+
+// NewTxDenylist creates a denylist loading the accounts stored in the given file, if any.
+func NewTxDenylist(path string) (*TxDenylist, error) {
+	l := &TxDenylist{path: path, addrs: make(map[common.Address]struct{})}
+	if path == "" {
+		return l, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var addrs []common.Address
+	if err := json.Unmarshal(data, &addrs); err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		l.addrs[addr] = struct{}{}
+	}
+	return l, nil
+}
+
+// Add puts the given accounts on the denylist.
+func (l *TxDenylist) Add(addrs ...common.Address) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, addr := range addrs {
+		l.addrs[addr] = struct{}{}
+	}
+	return l.save()
+}
+
+// Remove takes the given accounts off the denylist.
+func (l *TxDenylist) Remove(addrs ...common.Address) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, addr := range addrs {
+		delete(l.addrs, addr)
+	}
+	return l.save()
+}
+
+// Contains returns true if the account is on the denylist.
+func (l *TxDenylist) Contains(addr common.Address) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	_, ok := l.addrs[addr]
+	return ok
+}
+
+// List returns the accounts on the denylist in ascending order.
+func (l *TxDenylist) List() []common.Address {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.list()
+}
+
+func (l *TxDenylist) list() []common.Address {
+	addrs := make([]common.Address, 0, len(l.addrs))
+	for addr := range l.addrs {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+	return addrs
+}
+
+// Check returns the error telling the role of the account on the denylist, if the transaction sent
+// by the given sender is refused by the denylist.
+func (l *TxDenylist) Check(from common.Address, tx *types.Transaction) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if len(l.addrs) == 0 {
+		return nil
+	}
+	if _, ok := l.addrs[from]; ok {
+		return ErrDeniedSender
+	}
+	if tx.IsFeeDelegatedTransaction() {
+		if feePayer, err := tx.FeePayer(); err == nil {
+			if _, ok := l.addrs[feePayer]; ok {
+				return ErrDeniedFeePayer
+			}
+		}
+	}
+	if to := tx.To(); to != nil {
+		if _, ok := l.addrs[*to]; ok {
+			return ErrDeniedRecipient
+		}
+	}
+	return nil
+}
+
+// save writes the denylist to the file atomically, so a crash does not leave a broken file.
+func (l *TxDenylist) save() error {
+	if l.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(l.list(), "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := l.path + ".new"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
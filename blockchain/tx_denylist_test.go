@@ -0,0 +1,80 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxDenylist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "klaytn-test-denylist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		path     = filepath.Join(dir, "denylist.json")
+		from     = common.Address{0x0a}
+		feePayer = common.Address{0x0b}
+		to       = common.Address{0x0c}
+	)
+	tx, err := types.NewTransactionWithMap(types.TxTypeFeeDelegatedValueTransfer, map[types.TxValueKeyType]interface{}{
+		types.TxValueKeyNonce:    uint64(0),
+		types.TxValueKeyTo:       to,
+		types.TxValueKeyAmount:   big.NewInt(1),
+		types.TxValueKeyGasLimit: uint64(100000),
+		types.TxValueKeyGasPrice: big.NewInt(1),
+		types.TxValueKeyFrom:     from,
+		types.TxValueKeyFeePayer: feePayer,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	denylist, err := NewTxDenylist(path)
+	assert.NoError(t, err)
+	assert.NoError(t, denylist.Check(from, tx))
+
+	// The role of the account on the denylist is told by the error
+	assert.NoError(t, denylist.Add(to, feePayer))
+	assert.Equal(t, ErrDeniedFeePayer, denylist.Check(from, tx))
+	assert.NoError(t, denylist.Remove(feePayer))
+	assert.Equal(t, ErrDeniedRecipient, denylist.Check(from, tx))
+	assert.NoError(t, denylist.Add(from))
+	assert.Equal(t, ErrDeniedSender, denylist.Check(from, tx))
+
+	// The denylist survives restarts
+	denylist, err = NewTxDenylist(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Address{from, to}, denylist.List())
+	assert.True(t, denylist.Contains(to))
+	assert.False(t, denylist.Contains(feePayer))
+
+	// A broken file is not ignored
+	assert.NoError(t, ioutil.WriteFile(path, []byte("[broken"), 0644))
+	_, err = NewTxDenylist(path)
+	assert.Error(t, err)
+}
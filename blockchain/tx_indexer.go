@@ -0,0 +1,253 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/storage/database"
+)
+
+// txIndexBatchBlocks is the maximum number of blocks indexed or unindexed in a database batch.
+const txIndexBatchBlocks = 1000
+
+// TxIndexStatus is the status of the transaction lookup index.
+type TxIndexStatus struct {
+	Limit    uint64 // number of recent blocks whose transactions are indexed, 0 for the entire chain
+	Tail     uint64 // oldest block whose transactions are indexed
+	Head     uint64 // current block
+	Indexing bool   // true if the index is being extended or shrunk toward the limit
+}
+
+// Building returns true if the transactions of the older blocks are being indexed, so a transaction
+// not found in the index may be in the blocks not indexed yet.
+func (s TxIndexStatus) Building() bool {
+	return s.Indexing && s.Tail > txIndexTail(s.Head, s.Limit)
+}
+
+// txIndexTail returns the oldest block whose transactions should be indexed
+// when the head block is head and the lookup limit is limit.
+func txIndexTail(head, limit uint64) uint64 {
+	if limit == 0 || head+1 <= limit {
+		return 0
+	}
+	return head - limit + 1
+}
+
+// SetTxLookupLimit changes the number of recent blocks whose transactions are indexed.
+// The index is extended or shrunk in background, and 0 indexes the entire chain.
+func (bc *BlockChain) SetTxLookupLimit(limit uint64) {
+	select {
+	case bc.txLookupLimitCh <- limit:
+	case <-bc.quit:
+	}
+}
+
+// TxIndexStatus returns the status of the transaction lookup index.
+func (bc *BlockChain) TxIndexStatus() TxIndexStatus {
+	tail, err := bc.db.ReadTxIndexTail()
+	if err != nil {
+		logger.Error("Failed to read the transaction index tail", "err", err)
+	}
+	return TxIndexStatus{
+		Limit:    atomic.LoadUint64(&bc.txLookupLimit),
+		Tail:     tail,
+		Head:     bc.CurrentBlock().NumberU64(),
+		Indexing: atomic.LoadInt32(&bc.txIndexing) == 1,
+	}
+}
+
+// startTxIndexer starts maintaining the transaction lookup index in background.
+func (bc *BlockChain) startTxIndexer() {
+	headCh := make(chan ChainHeadEvent, 10)
+	sub := bc.SubscribeChainHeadEvent(headCh)
+
+	bc.wg.Add(1)
+	go bc.maintainTxIndex(headCh, sub)
+}
+
+// maintainTxIndex keeps the transactions of the recent blocks within the lookup limit
+// indexed, and unindexes the older ones. The index is updated whenever a new head block
+// is inserted or the limit is changed.
+func (bc *BlockChain) maintainTxIndex(headCh chan ChainHeadEvent, sub event.Subscription) {
+	defer bc.wg.Done()
+	defer sub.Unsubscribe()
+
+	var (
+		head  = bc.CurrentBlock().NumberU64()
+		done  chan struct{} // non-nil while the index is being updated
+		abort chan struct{} // closed to stop updating the index
+	)
+	run := func() {
+		if done != nil {
+			return
+		}
+		done, abort = make(chan struct{}), make(chan struct{})
+		go bc.updateTxIndex(head, atomic.LoadUint64(&bc.txLookupLimit), abort, done)
+	}
+	stop := func() {
+		if done != nil {
+			close(abort)
+			<-done
+			done = nil
+		}
+	}
+	defer stop()
+
+	run()
+	for {
+		select {
+		case ev := <-headCh:
+			head = ev.Block.NumberU64()
+			run()
+		case limit := <-bc.txLookupLimitCh:
+			logger.Info("Changing the transaction lookup limit", "old", atomic.LoadUint64(&bc.txLookupLimit), "new", limit)
+			atomic.StoreUint64(&bc.txLookupLimit, limit)
+			stop()
+			run()
+		case <-done:
+			done = nil
+		case <-sub.Err():
+			return
+		case <-bc.quit:
+			return
+		}
+	}
+}
+
+// updateTxIndex indexes or unindexes the transactions of the blocks until the index tail
+// reaches the tail determined by the given head and limit.
+func (bc *BlockChain) updateTxIndex(head, limit uint64, abort, done chan struct{}) {
+	defer close(done)
+
+	tail, err := bc.db.ReadTxIndexTail()
+	if err != nil {
+		logger.Error("Failed to read the transaction index tail", "err", err)
+		return
+	}
+	target := txIndexTail(head, limit)
+	if tail == target {
+		return
+	}
+
+	atomic.StoreInt32(&bc.txIndexing, 1)
+	defer atomic.StoreInt32(&bc.txIndexing, 0)
+
+	if tail < target {
+		bc.unindexTxs(tail, target, abort)
+	} else {
+		bc.indexTxs(target, tail, abort)
+	}
+}
+
+// indexTxs indexes the transactions of the blocks in [from, to) from the newest one,
+// moving the index tail backward as the blocks are indexed.
+func (bc *BlockChain) indexTxs(from, to uint64, abort chan struct{}) {
+	var (
+		start   = time.Now()
+		batch   = bc.db.NewBatch(database.TxLookUpEntryDB)
+		txs     = 0
+		blocks  = 0
+		current = to
+	)
+	// flush writes the batch first so that the stored tail never passes the indexed blocks
+	flush := func() bool {
+		if err := batch.Write(); err != nil {
+			logger.Error("Failed to write transaction indices", "err", err)
+			return false
+		}
+		batch.Reset()
+		if err := bc.db.WriteTxIndexTail(current); err != nil {
+			logger.Error("Failed to write the transaction index tail", "err", err)
+			return false
+		}
+		return true
+	}
+	for current > from {
+		select {
+		case <-abort:
+			flush()
+			logger.Info("Transaction indexing is aborted", "tail", current, "blocks", blocks, "txs", txs, "elapsed", time.Since(start))
+			return
+		default:
+		}
+		if block := bc.GetBlockByNumber(current - 1); block != nil {
+			bc.db.PutTxLookupEntriesToBatch(batch, block)
+			txs += len(block.Transactions())
+		}
+		current--
+		blocks++
+		if blocks%txIndexBatchBlocks == 0 || batch.ValueSize() >= database.IdealBatchSize {
+			if !flush() {
+				return
+			}
+		}
+	}
+	if flush() {
+		logger.Info("Indexed transactions", "from", from, "to", to-1, "blocks", blocks, "txs", txs, "elapsed", time.Since(start))
+	}
+}
+
+// unindexTxs removes the transaction indices of the blocks in [from, to) from the oldest one,
+// moving the index tail forward as the blocks are unindexed.
+func (bc *BlockChain) unindexTxs(from, to uint64, abort chan struct{}) {
+	var (
+		start   = time.Now()
+		batch   = bc.db.NewBatch(database.TxLookUpEntryDB)
+		txs     = 0
+		blocks  = 0
+		current = from
+	)
+	// flush writes the batch first so that the stored tail never passes the unindexed blocks
+	flush := func() bool {
+		if err := batch.Write(); err != nil {
+			logger.Error("Failed to remove transaction indices", "err", err)
+			return false
+		}
+		batch.Reset()
+		if err := bc.db.WriteTxIndexTail(current); err != nil {
+			logger.Error("Failed to write the transaction index tail", "err", err)
+			return false
+		}
+		return true
+	}
+	for current < to {
+		select {
+		case <-abort:
+			flush()
+			logger.Info("Transaction unindexing is aborted", "tail", current, "blocks", blocks, "txs", txs, "elapsed", time.Since(start))
+			return
+		default:
+		}
+		if block := bc.GetBlockByNumber(current); block != nil {
+			bc.db.DeleteTxLookupEntriesFromBatch(batch, block)
+			txs += len(block.Transactions())
+		}
+		current++
+		blocks++
+		if blocks%txIndexBatchBlocks == 0 || batch.ValueSize() >= database.IdealBatchSize {
+			if !flush() {
+				return
+			}
+		}
+	}
+	if flush() {
+		logger.Info("Unindexed transactions", "from", from, "to", to-1, "blocks", blocks, "txs", txs, "elapsed", time.Since(start))
+	}
+}
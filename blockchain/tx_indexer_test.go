@@ -0,0 +1,120 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxIndexTail(t *testing.T) {
+	assert.Equal(t, uint64(0), txIndexTail(10, 0))
+	assert.Equal(t, uint64(0), txIndexTail(10, 11))
+	assert.Equal(t, uint64(0), txIndexTail(10, 12))
+	assert.Equal(t, uint64(1), txIndexTail(10, 10))
+	assert.Equal(t, uint64(8), txIndexTail(10, 3))
+}
+
+func TestTxIndexStatus_Building(t *testing.T) {
+	// Extending the index toward the older blocks
+	assert.True(t, TxIndexStatus{Limit: 0, Tail: 5, Head: 10, Indexing: true}.Building())
+	// Shrinking the index, the transactions of the indexed blocks are all found
+	assert.False(t, TxIndexStatus{Limit: 3, Tail: 5, Head: 10, Indexing: true}.Building())
+	assert.False(t, TxIndexStatus{Limit: 0, Tail: 5, Head: 10, Indexing: false}.Building())
+	assert.False(t, TxIndexStatus{Limit: 0, Tail: 0, Head: 10, Indexing: true}.Building())
+}
+
+func TestBlockChain_TxLookupLimit(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		db      = database.NewMemoryDBManager()
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{addr: {Balance: big.NewInt(10000000000000)}}}
+		genesis = gspec.MustCommit(db)
+		signer  = types.NewEIP155Signer(gspec.Config.ChainID)
+	)
+	cacheConfig := &CacheConfig{
+		CacheSize:           512,
+		BlockInterval:       DefaultBlockInterval,
+		TriesInMemory:       DefaultTriesInMemory,
+		TrieNodeCacheConfig: statedb.GetEmptyTrieNodeCacheConfig(),
+		TxLookupLimit:       3,
+	}
+	bc, err := NewBlockChain(db, cacheConfig, gspec.Config, gxhash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+
+	// Every block has a transaction
+	var txs types.Transactions
+	chain, _ := GenerateChain(gspec.Config, genesis, gxhash.NewFaker(), db, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), addr, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+		gen.AddTx(tx)
+		txs = append(txs, tx)
+	})
+	if _, err := bc.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+
+	waitTail := func(tail uint64) {
+		for i := 0; i < 100 && (bc.TxIndexStatus().Tail != tail || bc.TxIndexStatus().Indexing); i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.Equal(t, TxIndexStatus{Limit: bc.TxIndexStatus().Limit, Tail: tail, Head: 10}, bc.TxIndexStatus())
+	}
+	checkIndexed := func(tail uint64) {
+		for i, tx := range txs {
+			_, number, _ := db.ReadTxLookupEntry(tx.Hash())
+			if uint64(i+1) < tail {
+				assert.Zero(t, number, "tx of block %d should be unindexed", i+1)
+			} else {
+				assert.Equal(t, uint64(i+1), number, "tx of block %d should be indexed", i+1)
+			}
+		}
+	}
+
+	// Only the transactions of the last 3 blocks are indexed
+	waitTail(8)
+	checkIndexed(8)
+
+	// Extend the index
+	bc.SetTxLookupLimit(5)
+	waitTail(6)
+	checkIndexed(6)
+	assert.Equal(t, uint64(5), bc.TxIndexStatus().Limit)
+
+	// Shrink the index
+	bc.SetTxLookupLimit(1)
+	waitTail(10)
+	checkIndexed(10)
+
+	// Index the entire chain
+	bc.SetTxLookupLimit(0)
+	waitTail(0)
+	checkIndexed(0)
+}
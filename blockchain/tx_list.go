@@ -321,20 +321,7 @@ func (l *txList) Filter(senderBalance *big.Int, pool *TxPool) (types.Transaction
 		if tx.ValidateMutableValue(pool.currentState, pool.signer, pool.currentBlockNumber) != nil {
 			return true
 		}
-		// In case of fee-delegated transactions, the comparison value should consider tx fee and fee ratio.
-		if tx.IsFeeDelegatedTransaction() {
-			feePayer, _ := tx.FeePayer()
-			feePayerBalance := pool.getBalance(feePayer)
-			feeRatio, isRatioTx := tx.FeeRatio()
-			if isRatioTx {
-				feeByFeePayer, feeBySender := types.CalcFeeWithRatio(feeRatio, tx.Fee())
-				return senderBalance.Cmp(new(big.Int).Add(tx.Value(), feeBySender)) < 0 || feePayerBalance.Cmp(feeByFeePayer) < 0
-			} else {
-				return senderBalance.Cmp(tx.Value()) < 0 || feePayerBalance.Cmp(tx.Fee()) < 0
-			}
-		}
-		// For other transactions, all tx cost should be payable by the sender.
-		return senderBalance.Cmp(tx.Cost()) < 0
+		return pool.lacksFunds(tx, senderBalance)
 	})
 
 	// If the list was strict, filter anything above the lowest nonce
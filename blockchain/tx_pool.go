@@ -27,6 +27,7 @@ import (
 	"math/big"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/klaytn/klaytn/blockchain/state"
@@ -46,8 +47,9 @@ const (
 	demoteUnexecutablesFullValidationTxLimit = 1000
 	// txMsgCh is the number of list of transactions can be queued.
 	txMsgChSize = 100
-	// MaxTxDataSize is a heuristic limit of tx data size, and txPool rejects transactions over 32KB to prevent DOS attacks.
-	MaxTxDataSize = 32 * 1024
+	// MaxTxDataSize is the default limit of tx data size, and txPool rejects transactions over 32KB to prevent DOS attacks.
+	// The limit can be changed by the TxData of the chain config.
+	MaxTxDataSize = params.MaxTxDataSize
 )
 
 var (
@@ -76,6 +78,9 @@ var (
 	invalidTxCounter     = metrics.NewRegisteredCounter("txpool/invalid", nil)
 	underpricedTxCounter = metrics.NewRegisteredCounter("txpool/underpriced", nil)
 	refusedTxCounter     = metrics.NewRegisteredCounter("txpool/refuse", nil)
+
+	invalidChainIdTxCounter = metrics.NewRegisteredCounter("txpool/invalid/chainid", nil)
+	deniedTxCounter         = metrics.NewRegisteredCounter("txpool/denied", nil)
 )
 
 // TxStatus is the current status of a transaction as seen by the pool.
@@ -105,6 +110,7 @@ type TxPoolConfig struct {
 	AllowLocalAnchorTx bool          // if this is true, the txpool allow locally submitted anchor transactions
 	Journal            string        // Journal of local transactions to survive node restarts
 	JournalInterval    time.Duration // Time interval to regenerate the local transaction journal
+	Denylist           string        // Denylist of the accounts whose transactions are refused, to survive node restarts
 
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
@@ -125,6 +131,7 @@ type TxPoolConfig struct {
 var DefaultTxPoolConfig = TxPoolConfig{
 	Journal:         "transactions.rlp",
 	JournalInterval: time.Hour,
+	Denylist:        "denylist.json",
 
 	PriceLimit: 1,
 	PriceBump:  10,
@@ -192,6 +199,12 @@ type TxPool struct {
 	all     map[common.Hash]*types.Transaction // All transactions to allow lookups
 	priced  *txPricedList                      // All transactions sorted by price
 
+	accessHints *TxAccessHints // Predicts the accounts accessed by transactions for scheduling
+	arrivals    *TxArrivals    // Remembers the arrival order of transactions for scheduling
+	denylist    *TxDenylist    // Accounts whose transactions are refused by the pool and the block building
+
+	invalidChainIdTxs int64 // Number of the transactions rejected for another chain id, accessed atomically
+
 	wg sync.WaitGroup // for shutdown sync
 
 	txMsgCh chan types.Transactions
@@ -217,11 +230,19 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		chainHeadCh:  make(chan ChainHeadEvent, chainHeadChanSize),
 		// TODO-Klaytn We use ChainConfig.UnitPrice to initialize TxPool.gasPrice,
 		//         later we have to change this rule when governance of UnitPrice is determined.
-		gasPrice: new(big.Int).SetUint64(chainconfig.UnitPrice),
-		txMsgCh:  make(chan types.Transactions, txMsgChSize),
+		gasPrice:    new(big.Int).SetUint64(chainconfig.UnitPrice),
+		txMsgCh:     make(chan types.Transactions, txMsgChSize),
+		accessHints: NewTxAccessHints(txAccessHintsCacheSize),
+		arrivals:    NewTxArrivals(txArrivalsCacheSize),
 	}
 	pool.locals = newAccountSet(pool.signer)
 	pool.priced = newTxPricedList(&pool.all)
+
+	denylist, err := NewTxDenylist(config.Denylist)
+	if err != nil {
+		logger.Crit("Failed to load transaction denylist", "path", config.Denylist, "err", err)
+	}
+	pool.denylist = denylist
 	pool.reset(nil, chain.CurrentBlock().Header())
 
 	// If local transactions and journaling is enabled, load from disk
@@ -470,6 +491,28 @@ func (pool *TxPool) GasPrice() *big.Int {
 	return new(big.Int).Set(pool.gasPrice)
 }
 
+// AccessHints returns the predictor of the accounts accessed by transactions, which is used
+// to schedule the pending transactions when building a block.
+func (pool *TxPool) AccessHints() *TxAccessHints {
+	return pool.accessHints
+}
+
+// Arrivals returns the arrival order of transactions, which is used to schedule the pending
+// transactions when building a block.
+func (pool *TxPool) Arrivals() *TxArrivals {
+	return pool.arrivals
+}
+
+// Denylist returns the accounts whose transactions are refused by the pool and the block building.
+func (pool *TxPool) Denylist() *TxDenylist {
+	return pool.denylist
+}
+
+// InvalidChainIdTxs returns the number of the transactions rejected since they are signed for another chain.
+func (pool *TxPool) InvalidChainIdTxs() int64 {
+	return atomic.LoadInt64(&pool.invalidChainIdTxs)
+}
+
 // SetGasPrice updates the gas price of the transaction pool for new transactions, and drops all old transactions.
 func (pool *TxPool) SetGasPrice(price *big.Int) {
 	if pool.gasPrice.Cmp(price) != 0 {
@@ -532,6 +575,25 @@ func (pool *TxPool) Content() (map[common.Address]types.Transactions, map[common
 	return pending, queued
 }
 
+// ContentFrom retrieves the data content of the transaction pool, returning the
+// pending as well as queued transactions of the given account, sorted by nonce.
+func (pool *TxPool) ContentFrom(addr common.Address) (types.Transactions, types.Transactions) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.txMu.Lock()
+	defer pool.txMu.Unlock()
+
+	var pending types.Transactions
+	if list, ok := pool.pending[addr]; ok {
+		pending = list.Flatten()
+	}
+	var queued types.Transactions
+	if list, ok := pool.queue[addr]; ok {
+		queued = list.Flatten()
+	}
+	return pending, queued
+}
+
 // Pending retrieves all currently processable transactions, groupped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
 // freely modified by calling code.
@@ -613,6 +675,9 @@ func (pool *TxPool) validateTx(tx *types.Transaction) error {
 
 	// Check chain Id first.
 	if tx.ChainId().Cmp(pool.chainconfig.ChainID) != 0 {
+		logger.Debug("Rejected a transaction signed for another chain", "hash", tx.Hash(), "chainId", tx.ChainId(), "expected", pool.chainconfig.ChainID)
+		invalidChainIdTxCounter.Inc(1)
+		atomic.AddInt64(&pool.invalidChainIdTxs, 1)
 		return ErrInvalidChainId
 	}
 
@@ -622,9 +687,9 @@ func (pool *TxPool) validateTx(tx *types.Transaction) error {
 		return ErrInvalidUnitPrice
 	}
 
-	// Heuristic limit, reject transactions over 32KB to prevent DOS attacks
-	if tx.Size() > MaxTxDataSize {
-		return ErrOversizedData
+	// Heuristic limit, reject transactions over 32KB by default to prevent DOS attacks
+	if err := validateTxSize(pool.chainconfig, tx); err != nil {
+		return err
 	}
 
 	// Transactions can't be negative. This may never happen using RLP decoded
@@ -640,6 +705,13 @@ func (pool *TxPool) validateTx(tx *types.Transaction) error {
 	}
 	from := tx.ValidatedSender()
 
+	// Refuse the transaction if any of its accounts is on the denylist
+	if err := pool.denylist.Check(from, tx); err != nil {
+		logger.Debug("Rejected a transaction on the denylist", "hash", tx.Hash(), "from", from, "err", err)
+		deniedTxCounter.Inc(1)
+		return err
+	}
+
 	// Ensure the transaction adheres to nonce ordering
 	if pool.getNonce(from) > tx.Nonce() {
 		return ErrNonceTooLow
@@ -753,6 +825,9 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		invalidTxCounter.Inc(1)
 		return false, err
 	}
+	pool.arrivals.Record(hash)
+	// Share the senders recovered by the validation with the block processing
+	senderCache.Add(tx)
 
 	// If the transaction pool is full and new Tx is valid,
 	// (1) discard a new Tx if there is no room for the account of the Tx
@@ -1381,6 +1456,24 @@ func (pool *TxPool) getBalance(addr common.Address) *big.Int {
 	return pool.currentState.GetBalance(addr)
 }
 
+// lacksFunds returns true if the sender or the fee payer of the transaction cannot afford it.
+func (pool *TxPool) lacksFunds(tx *types.Transaction, senderBalance *big.Int) bool {
+	// In case of fee-delegated transactions, the comparison value should consider tx fee and fee ratio.
+	if tx.IsFeeDelegatedTransaction() {
+		feePayer, _ := tx.FeePayer()
+		feePayerBalance := pool.getBalance(feePayer)
+		feeRatio, isRatioTx := tx.FeeRatio()
+		if isRatioTx {
+			feeByFeePayer, feeBySender := types.CalcFeeWithRatio(feeRatio, tx.Fee())
+			return senderBalance.Cmp(new(big.Int).Add(tx.Value(), feeBySender)) < 0 || feePayerBalance.Cmp(feeByFeePayer) < 0
+		} else {
+			return senderBalance.Cmp(tx.Value()) < 0 || feePayerBalance.Cmp(tx.Fee()) < 0
+		}
+	}
+	// For other transactions, all tx cost should be payable by the sender.
+	return senderBalance.Cmp(tx.Cost()) < 0
+}
+
 // GetPendingNonce is a method to check the last nonce value of pending in external API.
 // Use getPendingNonce to get the nonce value inside txpool because it catches the lock.
 func (pool *TxPool) GetPendingNonce(addr common.Address) uint64 {
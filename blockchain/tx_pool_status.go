@@ -0,0 +1,136 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+)
+
+// Reasons why a transaction of an account is stuck in the pool.
+const (
+	StuckReasonNonceGap            = "nonce gap"             // a lower nonce is missing in the pool
+	StuckReasonInsufficientFunds   = "insufficient funds"    // the sender or the fee payer cannot afford the transaction
+	StuckReasonUnderpriced         = "underpriced"           // the gas price is lower than the unit price
+	StuckReasonInvalidMutableValue = "invalid mutable value" // the account key or the account type has been changed
+	StuckReasonUnexecutable        = "unexecutable"          // the transaction has failed on block generation
+	StuckReasonNotPromoted         = "not promoted yet"      // executable, but waiting for the next promotion
+)
+
+// NonceGap is a range of the nonces missing in the pool, both inclusive.
+type NonceGap struct {
+	From uint64
+	To   uint64
+}
+
+// StuckTx is the transaction of an account blocking the other transactions of the account.
+type StuckTx struct {
+	Tx     *types.Transaction
+	Reason string
+}
+
+// AccountQueueStatus is the status of the transactions of an account in the pool.
+type AccountQueueStatus struct {
+	Nonce         uint64     // nonce of the account in the current state
+	PendingNonce  uint64     // nonce following the pending transactions
+	Pending       int        // number of the executable transactions
+	Queued        int        // number of the non-executable transactions
+	LowestPending *uint64    // lowest nonce of the pending transactions, nil if none
+	LowestQueued  *uint64    // lowest nonce of the queued transactions, nil if none
+	Gaps          []NonceGap // nonces missing before the queued transactions
+	Stuck         *StuckTx   // the oldest transaction which cannot be executed, nil if none
+	LastActivity  time.Time  // last time a transaction of the account is queued, zero if none
+}
+
+// AccountQueueStatus returns the status of the transactions of the given account in the pool,
+// diagnosing the nonce gaps and the transaction that blocks the others.
+func (pool *TxPool) AccountQueueStatus(addr common.Address) *AccountQueueStatus {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.txMu.Lock()
+	defer pool.txMu.Unlock()
+
+	status := &AccountQueueStatus{
+		Nonce:        pool.getNonce(addr),
+		PendingNonce: pool.getPendingNonce(addr),
+		LastActivity: pool.beats[addr],
+	}
+
+	var pending, queued types.Transactions
+	if list := pool.pending[addr]; list != nil {
+		pending = list.Flatten()
+	}
+	if list := pool.queue[addr]; list != nil {
+		queued = list.Flatten()
+	}
+	status.Pending, status.Queued = len(pending), len(queued)
+	if len(pending) > 0 {
+		nonce := pending[0].Nonce()
+		status.LowestPending = &nonce
+	}
+	if len(queued) > 0 {
+		nonce := queued[0].Nonce()
+		status.LowestQueued = &nonce
+	}
+
+	// The pending transactions are contiguous, so the gaps are only before the queued ones
+	next := status.PendingNonce
+	for _, tx := range queued {
+		if tx.Nonce() > next {
+			status.Gaps = append(status.Gaps, NonceGap{From: next, To: tx.Nonce() - 1})
+		}
+		if tx.Nonce() >= next {
+			next = tx.Nonce() + 1
+		}
+	}
+
+	// The lowest pending transaction blocks all the others if it cannot be executed
+	if len(pending) > 0 {
+		if reason := pool.stuckReason(addr, pending[0]); reason != "" {
+			status.Stuck = &StuckTx{Tx: pending[0], Reason: reason}
+			return status
+		}
+	}
+	if len(queued) > 0 {
+		reason := StuckReasonNotPromoted
+		if len(status.Gaps) > 0 {
+			reason = StuckReasonNonceGap
+		} else if r := pool.stuckReason(addr, queued[0]); r != "" {
+			reason = r
+		}
+		status.Stuck = &StuckTx{Tx: queued[0], Reason: reason}
+	}
+	return status
+}
+
+// stuckReason returns the reason why the transaction cannot be executed in the current state,
+// or an empty string if it can be executed.
+func (pool *TxPool) stuckReason(addr common.Address, tx *types.Transaction) string {
+	switch {
+	case tx.IsMarkedUnexecutable():
+		return StuckReasonUnexecutable
+	case tx.GasPrice().Cmp(pool.gasPrice) < 0:
+		return StuckReasonUnderpriced
+	case tx.ValidateMutableValue(pool.currentState, pool.signer, pool.currentBlockNumber) != nil:
+		return StuckReasonInvalidMutableValue
+	case pool.lacksFunds(tx, pool.getBalance(addr)):
+		return StuckReasonInsufficientFunds
+	}
+	return ""
+}
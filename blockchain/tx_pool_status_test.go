@@ -0,0 +1,71 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxPool_AccountQueueStatus(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	tx0 := transaction(0, 100, key)
+	from, _ := deriveSender(tx0)
+	pool.currentState.AddBalance(from, big.NewInt(1000))
+	pool.lockedReset(nil, nil)
+
+	// No transaction
+	status := pool.AccountQueueStatus(from)
+	assert.Equal(t, 0, status.Pending+status.Queued)
+	assert.Nil(t, status.LowestPending)
+	assert.Nil(t, status.LowestQueued)
+	assert.Empty(t, status.Gaps)
+	assert.Nil(t, status.Stuck)
+
+	// Nonce 1, 2 and 4 are missing
+	tx3, tx5 := transaction(3, 100, key), transaction(5, 100, key)
+	for _, tx := range []*types.Transaction{tx0, tx3, tx5} {
+		pool.enqueueTx(tx.Hash(), tx)
+	}
+	pool.promoteExecutables([]common.Address{from})
+
+	status = pool.AccountQueueStatus(from)
+	assert.Equal(t, uint64(0), status.Nonce)
+	assert.Equal(t, uint64(1), status.PendingNonce)
+	assert.Equal(t, 1, status.Pending)
+	assert.Equal(t, 2, status.Queued)
+	assert.Equal(t, uint64(0), *status.LowestPending)
+	assert.Equal(t, uint64(3), *status.LowestQueued)
+	assert.Equal(t, []NonceGap{{From: 1, To: 2}, {From: 4, To: 4}}, status.Gaps)
+	assert.Equal(t, tx3.Hash(), status.Stuck.Tx.Hash())
+	assert.Equal(t, StuckReasonNonceGap, status.Stuck.Reason)
+	assert.False(t, status.LastActivity.IsZero())
+
+	// The pending transaction cannot be afforded anymore
+	pool.currentState.SubBalance(from, big.NewInt(1000))
+	status = pool.AccountQueueStatus(from)
+	assert.Equal(t, tx0.Hash(), status.Stuck.Tx.Hash())
+	assert.Equal(t, StuckReasonInsufficientFunds, status.Stuck.Reason)
+}
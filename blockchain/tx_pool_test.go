@@ -35,7 +35,9 @@ import (
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/fork"
 	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
 	"github.com/klaytn/klaytn/storage/database"
 	"github.com/stretchr/testify/assert"
 )
@@ -47,6 +49,7 @@ var testTxPoolConfig TxPoolConfig
 func init() {
 	testTxPoolConfig = DefaultTxPoolConfig
 	testTxPoolConfig.Journal = ""
+	testTxPoolConfig.Denylist = ""
 }
 
 type testBlockChain struct {
@@ -82,7 +85,7 @@ func pricedTransaction(nonce uint64, gaslimit uint64, gasprice *big.Int, key *ec
 }
 
 func setupTxPool() (*TxPool, *ecdsa.PrivateKey) {
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	key, _ := crypto.GenerateKey()
@@ -168,7 +171,7 @@ func (c *testChain) State() (*state.StateDB, error) {
 	// a state change between those fetches.
 	stdb := c.statedb
 	if *c.trigger {
-		c.statedb, _ = state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()))
+		c.statedb, _ = state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
 		// simulate that the new head block included tx0 and tx1
 		c.statedb.SetNonce(c.address, 2)
 		c.statedb.SetBalance(c.address, new(big.Int).SetUint64(params.KLAY))
@@ -186,7 +189,7 @@ func TestStateChangeDuringTransactionPoolReset(t *testing.T) {
 	var (
 		key, _     = crypto.GenerateKey()
 		address    = crypto.PubkeyToAddress(key.PublicKey)
-		statedb, _ = state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()))
+		statedb, _ = state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
 		trigger    = false
 	)
 
@@ -227,6 +230,31 @@ func TestStateChangeDuringTransactionPoolReset(t *testing.T) {
 	}
 }
 
+// TestOversizedTransactions tests if the size limit of the transactions can be changed by the chain config.
+func TestOversizedTransactions(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
+	key, _ := crypto.GenerateKey()
+	data := make([]byte, MaxTxDataSize)
+	tx, _ := types.SignTx(types.NewTransaction(0, common.HexToAddress("0xAAAA"), big.NewInt(100), 10000000, big.NewInt(1), data),
+		types.NewEIP155Signer(params.TestChainConfig.ChainID), key)
+
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, &testBlockChain{statedb, 1000000, new(event.Feed)})
+	defer pool.Stop()
+	if err := pool.AddRemote(tx); err != ErrOversizedData {
+		t.Error("expected", ErrOversizedData, "got", err)
+	}
+
+	config := *params.TestChainConfig
+	config.TxData = &params.TxDataConfig{MaxTxSize: 2 * MaxTxDataSize}
+	largePool := NewTxPool(testTxPoolConfig, &config, &testBlockChain{statedb, 1000000, new(event.Feed)})
+	defer largePool.Stop()
+	if err := largePool.AddRemote(tx); err == ErrOversizedData {
+		t.Error("expected the transaction not to be oversized")
+	}
+}
+
 func TestInvalidTransactions(t *testing.T) {
 	t.Parallel()
 
@@ -267,6 +295,95 @@ func TestInvalidTransactions(t *testing.T) {
 	}
 }
 
+// Tests that the transactions signed for another chain are rejected with a distinct error code.
+func TestInvalidChainIdTransactions(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	tx, _ := types.SignTx(types.NewTransaction(0, common.HexToAddress("0xAAAA"), big.NewInt(100), 100000, big.NewInt(1), nil),
+		types.NewEIP155Signer(big.NewInt(params.TestChainConfig.ChainID.Int64()+1)), key)
+	if err := pool.AddRemote(tx); err != ErrInvalidChainId {
+		t.Fatal("expected", ErrInvalidChainId, "got", err)
+	}
+	if code := ErrInvalidChainId.(interface{ ErrorCode() int }).ErrorCode(); code != InvalidChainIdErrorCode {
+		t.Error("expected error code", InvalidChainIdErrorCode, "got", code)
+	}
+	if count := pool.InvalidChainIdTxs(); count != 1 {
+		t.Error("expected 1 rejected transaction, got", count)
+	}
+}
+
+// Tests that the transactions of the accounts on the denylist are rejected with a distinct error code.
+func TestDeniedTransactions(t *testing.T) {
+	t.Parallel()
+	fork.SetHardForkBlockNumberConfig(params.TestChainConfig)
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(from, big.NewInt(0xffffffffffffff))
+
+	if err := pool.Denylist().Add(from); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.AddRemote(transaction(0, 100000, key)); err != ErrDeniedSender {
+		t.Fatal("expected", ErrDeniedSender, "got", err)
+	}
+	if code := ErrDeniedSender.(interface{ ErrorCode() int }).ErrorCode(); code != DeniedTxErrorCode {
+		t.Error("expected error code", DeniedTxErrorCode, "got", code)
+	}
+
+	// The transactions sent to the account on the denylist are rejected as well
+	if err := pool.Denylist().Remove(from); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Denylist().Add(common.HexToAddress("0xAAAA")); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.AddLocal(transaction(0, 100000, key)); err != ErrDeniedRecipient {
+		t.Fatal("expected", ErrDeniedRecipient, "got", err)
+	}
+
+	if err := pool.Denylist().Remove(common.HexToAddress("0xAAAA")); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.AddRemote(transaction(0, 100000, key)); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+}
+
+// Tests that the pending and the queued transactions of an account are returned without the others.
+func TestContentFrom(t *testing.T) {
+	t.Parallel()
+	fork.SetHardForkBlockNumberConfig(params.TestChainConfig)
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	other, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(from, big.NewInt(0xffffffffffffff))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(other.PublicKey), big.NewInt(0xffffffffffffff))
+
+	txs := types.Transactions{transaction(0, 100000, key), transaction(1, 100000, key), transaction(3, 100000, key)}
+	for _, tx := range append(txs, transaction(0, 100000, other)) {
+		if err := pool.AddRemote(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pending, queued := pool.ContentFrom(from)
+	assert.Equal(t, types.Transactions{txs[0], txs[1]}, pending)
+	assert.Equal(t, types.Transactions{txs[2]}, queued)
+
+	pending, queued = pool.ContentFrom(common.HexToAddress("0xAAAA"))
+	assert.Empty(t, pending)
+	assert.Empty(t, queued)
+}
+
 func genAnchorTx(nonce uint64) *types.Transaction {
 	key, _ := crypto.HexToECDSA("45a915e4d060149eb4365960e6a7a45f334393093061116b197e3240065ff2d8")
 	from := crypto.PubkeyToAddress(key.PublicKey)
@@ -404,7 +521,7 @@ func TestTransactionChainFork(t *testing.T) {
 
 	addr := crypto.PubkeyToAddress(key.PublicKey)
 	resetState := func() {
-		statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()))
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
 		statedb.AddBalance(addr, big.NewInt(100000000000000))
 
 		pool.chain = &testBlockChain{statedb, 1000000, new(event.Feed)}
@@ -433,7 +550,7 @@ func TestTransactionDoubleNonce(t *testing.T) {
 
 	addr := crypto.PubkeyToAddress(key.PublicKey)
 	resetState := func() {
-		statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()))
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
 		statedb.AddBalance(addr, big.NewInt(100000000000000))
 
 		pool.chain = &testBlockChain{statedb, 1000000, new(event.Feed)}
@@ -604,7 +721,7 @@ func TestTransactionPostponing(t *testing.T) {
 	t.Parallel()
 
 	// Create the pool to test the postponing with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
@@ -819,7 +936,7 @@ func testTransactionQueueGlobalLimiting(t *testing.T, nolocals bool) {
 	t.Parallel()
 
 	// Create the pool to test the limit enforcement with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	config := testTxPoolConfig
@@ -917,7 +1034,7 @@ func testTransactionQueueTimeLimiting(t *testing.T, nolocals, keepLocals bool) {
 	evictionInterval = time.Second
 
 	// Create the pool to test the non-expiration enforcement
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	config := testTxPoolConfig
@@ -1081,7 +1198,7 @@ func TestTransactionPendingGlobalLimiting(t *testing.T) {
 	t.Parallel()
 
 	// Create the pool to test the limit enforcement with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	config := testTxPoolConfig
@@ -1127,7 +1244,7 @@ func TestTransactionCapClearsFromAll(t *testing.T) {
 	t.Parallel()
 
 	// Create the pool to test the limit enforcement with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	config := testTxPoolConfig
@@ -1161,7 +1278,7 @@ func TestTransactionPendingMinimumAllowance(t *testing.T) {
 	t.Parallel()
 
 	// Create the pool to test the limit enforcement with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	config := testTxPoolConfig
@@ -1211,7 +1328,7 @@ func TestTransactionPoolRepricing(t *testing.T) {
 	t.Parallel()
 
 	// Create the pool to test the pricing enforcement with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemDB()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemDB()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
@@ -1339,7 +1456,7 @@ func TestTransactionPoolRepricingKeepsLocals(t *testing.T) {
 	t.Parallel()
 
 	// Create the pool to test the pricing enforcement with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemDB()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemDB()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
@@ -1405,7 +1522,7 @@ func TestTransactionPoolUnderpricing(t *testing.T) {
 	t.Parallel()
 
 	// Create the pool to test the pricing enforcement with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemDB()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemDB()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	config := testTxPoolConfig
@@ -1515,7 +1632,7 @@ func TestTransactionPoolStableUnderpricing(t *testing.T) {
 	t.Parallel()
 
 	// Create the pool to test the pricing enforcement with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemDB()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemDB()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	config := testTxPoolConfig
@@ -1584,7 +1701,7 @@ func TestTransactionReplacement(t *testing.T) {
 	t.Parallel()
 
 	// Create the pool to test the pricing enforcement with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemDB()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemDB()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
@@ -1679,7 +1796,7 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	os.Remove(journal)
 
 	// Create the original pool to inject transaction into the journal
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	config := testTxPoolConfig
@@ -1777,7 +1894,7 @@ func TestTransactionStatusCheck(t *testing.T) {
 	t.Parallel()
 
 	// Create the pool to test the status retrievals with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
@@ -1897,6 +2014,45 @@ func BenchmarkPoolInsert(b *testing.B) {
 	}
 }
 
+// TestSenderCacheFromTxPool tests if the senders recovered by the pool are reused when
+// the same transactions are received in a block.
+func TestSenderCacheFromTxPool(t *testing.T) {
+	fork.SetHardForkBlockNumberConfig(params.TestChainConfig)
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	account, _ := deriveSender(transaction(0, 0, key))
+	pool.currentState.AddBalance(account, big.NewInt(1000000))
+
+	pooled, unknown := transaction(0, 100000, key), transaction(1, 100000, key)
+	if err := pool.AddRemote(pooled); err != nil {
+		t.Fatal(err)
+	}
+
+	// The transactions of the block are decoded separately from the pooled ones
+	var txs types.Transactions
+	for _, tx := range []*types.Transaction{pooled, unknown} {
+		enc, _ := rlp.EncodeToBytes(tx)
+		decoded := new(types.Transaction)
+		if err := rlp.DecodeBytes(enc, decoded); err != nil {
+			t.Fatal(err)
+		}
+		txs = append(txs, decoded)
+	}
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)}).WithBody(txs)
+
+	hits, misses := senderCacheHitCounter.Count(), senderCacheMissCounter.Count()
+	senderCacher.recoverFromBlocks(pool.signer, []*types.Block{block})
+	assert.Equal(t, hits+1, senderCacheHitCounter.Count())
+	assert.Equal(t, misses+1, senderCacheMissCounter.Count())
+
+	for _, tx := range block.Transactions() {
+		from, err := types.Sender(pool.signer, tx)
+		assert.NoError(t, err)
+		assert.Equal(t, account, from)
+	}
+}
+
 // Benchmarks the speed of batched transaction insertion.
 func BenchmarkPoolBatchInsert100(b *testing.B)   { benchmarkPoolBatchInsert(b, 100) }
 func BenchmarkPoolBatchInsert1000(b *testing.B)  { benchmarkPoolBatchInsert(b, 1000) }
@@ -0,0 +1,105 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// senderCacheEntry contains the senders recovered from the signatures of a transaction.
+type senderCacheEntry struct {
+	from     interface{} // sigCache of a legacy transaction, or sigCachePubkey of the sender
+	feePayer interface{} // sigCachePubkey of the fee payer, nil if not recovered
+}
+
+// SenderCache is a bounded cache of the senders recovered from the signatures of the
+// transactions, keyed by the transaction hashes. Since the hash covers the signatures,
+// the senders can be shared by the transactions decoded separately from the same bytes,
+// e.g. a transaction in the pool and the same transaction in a received block.
+// For the transactions signed by multiple keys, every recovered public key is cached,
+// and only the validation against the account key is done again.
+type SenderCache struct {
+	cache *lru.Cache
+}
+
+// NewSenderCache creates a SenderCache keeping the senders of up to the given number of transactions.
+func NewSenderCache(size int) *SenderCache {
+	cache, _ := lru.New(size)
+	return &SenderCache{cache: cache}
+}
+
+// Add stores the senders already recovered from the signatures of the transaction.
+func (c *SenderCache) Add(tx *Transaction) {
+	from := tx.from.Load()
+	if from == nil {
+		return
+	}
+	entry := &senderCacheEntry{from: from, feePayer: tx.feePayer.Load()}
+	c.cache.Add(tx.Hash(), entry)
+}
+
+// Load sets the cached senders to the transaction if they are recovered with the given signer.
+// It returns true if every sender of the transaction is loaded.
+func (c *SenderCache) Load(signer Signer, tx *Transaction) bool {
+	cached, ok := c.cache.Get(tx.Hash())
+	if !ok {
+		return false
+	}
+	entry := cached.(*senderCacheEntry)
+	if !sigCacheSignedBy(entry.from, signer) {
+		return false
+	}
+	tx.from.Store(entry.from)
+
+	if !tx.IsFeeDelegatedTransaction() {
+		return true
+	}
+	if entry.feePayer == nil || !sigCacheSignedBy(entry.feePayer, signer) {
+		return false
+	}
+	tx.feePayer.Store(entry.feePayer)
+	return true
+}
+
+// HasSenders returns true if every sender of the transaction is already recovered with the given signer.
+func HasSenders(signer Signer, tx *Transaction) bool {
+	from := tx.from.Load()
+	if from == nil || !sigCacheSignedBy(from, signer) {
+		return false
+	}
+	if !tx.IsFeeDelegatedTransaction() {
+		return true
+	}
+	feePayer := tx.feePayer.Load()
+	return feePayer != nil && sigCacheSignedBy(feePayer, signer)
+}
+
+// Len returns the number of the transactions in the cache.
+func (c *SenderCache) Len() int {
+	return c.cache.Len()
+}
+
+// sigCacheSignedBy returns true if the cached sender is derived by the given signer.
+func sigCacheSignedBy(sc interface{}, signer Signer) bool {
+	switch sc := sc.(type) {
+	case sigCache:
+		return sc.signer.Equal(signer)
+	case sigCachePubkey:
+		return sc.signer.Equal(signer)
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+// decodeTxCopy returns a copy of the transaction without any cached sender.
+func decodeTxCopy(t *testing.T, tx *Transaction) *Transaction {
+	enc, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cpy := new(Transaction)
+	if err := rlp.DecodeBytes(enc, cpy); err != nil {
+		t.Fatal(err)
+	}
+	return cpy
+}
+
+func TestSenderCache_Legacy(t *testing.T) {
+	var (
+		key, _ = crypto.GenerateKey()
+		signer = NewEIP155Signer(big.NewInt(1))
+		cache  = NewSenderCache(2)
+	)
+	tx, err := SignTx(NewTransaction(0, common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing is cached before the sender is recovered
+	cache.Add(tx)
+	assert.Equal(t, 0, cache.Len())
+	assert.False(t, cache.Load(signer, decodeTxCopy(t, tx)))
+
+	from, _ := Sender(signer, tx)
+	cache.Add(tx)
+	cpy := decodeTxCopy(t, tx)
+	assert.True(t, cache.Load(signer, cpy))
+	assert.NotNil(t, cpy.from.Load())
+	sender, err := Sender(signer, cpy)
+	assert.NoError(t, err)
+	assert.Equal(t, from, sender)
+
+	// The sender recovered by another signer is not loaded
+	cpy = decodeTxCopy(t, tx)
+	assert.False(t, cache.Load(NewEIP155Signer(big.NewInt(2)), cpy))
+	assert.Nil(t, cpy.from.Load())
+}
+
+func TestSenderCache_FeeDelegatedMultiSig(t *testing.T) {
+	var (
+		senderKeys   = make([]*ecdsa.PrivateKey, 3)
+		feePayerKey  = make([]*ecdsa.PrivateKey, 1)
+		signer       = NewEIP155Signer(big.NewInt(1))
+		cache        = NewSenderCache(2)
+		from         = common.Address{0x0a}
+		feePayerAddr = common.Address{0x0b}
+	)
+	for i := range senderKeys {
+		senderKeys[i], _ = crypto.GenerateKey()
+	}
+	feePayerKey[0], _ = crypto.GenerateKey()
+
+	tx, err := NewTransactionWithMap(TxTypeFeeDelegatedValueTransfer, map[TxValueKeyType]interface{}{
+		TxValueKeyNonce:    uint64(0),
+		TxValueKeyTo:       common.Address{0x01},
+		TxValueKeyAmount:   big.NewInt(1),
+		TxValueKeyGasLimit: uint64(100000),
+		TxValueKeyGasPrice: big.NewInt(1),
+		TxValueKeyFrom:     from,
+		TxValueKeyFeePayer: feePayerAddr,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, tx.SignWithKeys(signer, senderKeys))
+
+	// The sender without the fee payer is not enough to load
+	pubkeys, err := SenderPubkey(signer, tx)
+	assert.NoError(t, err)
+	assert.False(t, HasSenders(signer, tx))
+	cache.Add(tx)
+	assert.False(t, cache.Load(signer, decodeTxCopy(t, tx)))
+
+	// The transaction with the fee payer signature is cached separately
+	assert.NoError(t, tx.SignFeePayerWithKeys(signer, feePayerKey))
+	tx = decodeTxCopy(t, tx)
+	pubkeys, _ = SenderPubkey(signer, tx)
+	feePayerPubkeys, _ := SenderFeePayerPubkey(signer, tx)
+	cache.Add(tx)
+	assert.True(t, HasSenders(signer, tx))
+	assert.False(t, HasSenders(NewEIP155Signer(big.NewInt(2)), tx))
+
+	cpy := decodeTxCopy(t, tx)
+	assert.False(t, HasSenders(signer, cpy))
+	assert.True(t, cache.Load(signer, cpy))
+	cached, err := SenderPubkey(signer, cpy)
+	assert.NoError(t, err)
+	assert.Equal(t, pubkeys, cached)
+	assert.Len(t, cached, len(senderKeys))
+	cached, err = SenderFeePayerPubkey(signer, cpy)
+	assert.NoError(t, err)
+	assert.Equal(t, feePayerPubkeys, cached)
+
+	// The least recently used transaction is evicted from the bounded cache
+	for nonce := uint64(1); nonce <= 2; nonce++ {
+		other, _ := SignTx(NewTransaction(nonce, common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, feePayerKey[0])
+		Sender(signer, other)
+		cache.Add(other)
+	}
+	assert.Equal(t, 2, cache.Len())
+	assert.False(t, cache.Load(signer, decodeTxCopy(t, tx)))
+}
@@ -21,6 +21,7 @@
 package types
 
 import (
+	"bytes"
 	"container/heap"
 	"crypto/ecdsa"
 	"encoding/json"
@@ -699,9 +700,18 @@ func (s TxByNonce) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
 // for all at once sorting as well as individually adding and removing elements.
 type TxByPrice Transactions
 
-func (s TxByPrice) Len() int           { return len(s) }
-func (s TxByPrice) Less(i, j int) bool { return s[i].data.GetPrice().Cmp(s[j].data.GetPrice()) > 0 }
-func (s TxByPrice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s TxByPrice) Len() int      { return len(s) }
+func (s TxByPrice) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+// Less orders the transactions by price. Transactions of the same price are ordered by hash,
+// so that the order of the transactions does not depend on the order of insertion.
+func (s TxByPrice) Less(i, j int) bool {
+	if cmp := s[i].data.GetPrice().Cmp(s[j].data.GetPrice()); cmp != 0 {
+		return cmp > 0
+	}
+	hi, hj := s[i].Hash(), s[j].Hash()
+	return bytes.Compare(hi[:], hj[:]) < 0
+}
 
 func (s *TxByPrice) Push(x interface{}) {
 	*s = append(*s, x.(*Transaction))
@@ -304,3 +304,23 @@ func TestIntrinsicGas(t *testing.T) {
 		assert.Equal(t, nil, err)
 	}
 }
+
+// TestIntrinsicGasWithTxDataConfig tests if the data gas prices are changed by the chain config.
+func TestIntrinsicGasWithTxDataConfig(t *testing.T) {
+	data := []byte{0x00, 0x01, 0x00, 0x02}
+	config := &params.TxDataConfig{Gas: 10, ZeroGas: 1, NonZeroGas: 5}
+
+	gas, err := IntrinsicGas(data, false, params.Rules{IsIstanbul: true, TxData: config})
+	assert.NoError(t, err)
+	assert.Equal(t, params.TxGas+4*10, gas)
+
+	gas, err = IntrinsicGas(data, false, params.Rules{IsIstanbul: false, TxData: config})
+	assert.NoError(t, err)
+	assert.Equal(t, params.TxGas+2*1+2*5, gas)
+
+	// The zero values are replaced with the defaults
+	gas, err = IntrinsicGasPayload(0, data, &params.TxDataConfig{MaxTxSize: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 4*params.TxDataGas, gas)
+	assert.Equal(t, uint64(params.MaxTxDataSize), (*params.TxDataConfig)(nil).TxSizeLimit())
+}
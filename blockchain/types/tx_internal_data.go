@@ -25,6 +25,7 @@ import (
 
 	"github.com/klaytn/klaytn/blockchain/types/accountkey"
 	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/fork"
 	"github.com/klaytn/klaytn/kerrors"
 	"github.com/klaytn/klaytn/params"
 )
@@ -198,6 +199,10 @@ func (t TxType) IsContractDeploy() bool {
 	return (t &^ ((1 << SubTxTypeBits) - 1)) == TxTypeSmartContractDeploy
 }
 
+func (t TxType) IsContractExecution() bool {
+	return (t &^ ((1 << SubTxTypeBits) - 1)) == TxTypeSmartContractExecution
+}
+
 func (t TxType) IsCancelTransaction() bool {
 	return (t &^ ((1 << SubTxTypeBits) - 1)) == TxTypeCancel
 }
@@ -471,19 +476,35 @@ func NewTxInternalDataWithMap(t TxType, values map[TxValueKeyType]interface{}) (
 	return nil, errUndefinedTxType
 }
 
-func IntrinsicGasPayload(gas uint64, data []byte) (uint64, error) {
+// txDataConfig returns the transaction data config of the chain at the given block. It returns nil,
+// meaning the default config, if the chain config is not initialized, e.g. without a running blockchain.
+func txDataConfig(blockNumber uint64) *params.TxDataConfig {
+	rules, err := fork.Rules(new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return nil
+	}
+	return rules.TxData
+}
+
+// IntrinsicGasPayload returns the gas increased by the data, priced by the given transaction data config.
+// The default gas price is used if the config is nil.
+func IntrinsicGasPayload(gas uint64, data []byte, config *params.TxDataConfig) (uint64, error) {
 	// Bump the required gas by the amount of transactional data
+	dataGas := config.TxDataGas()
 	length := uint64(len(data))
 	if length > 0 {
 		// Make sure we don't exceed uint64 for all data combinations
-		if (math.MaxUint64-gas)/params.TxDataGas < length {
+		if (math.MaxUint64-gas)/dataGas < length {
 			return 0, kerrors.ErrOutOfGas
 		}
 	}
-	return gas + length*params.TxDataGas, nil
+	return gas + length*dataGas, nil
 }
 
-func IntrinsicGasPayloadLegacy(gas uint64, data []byte) (uint64, error) {
+// IntrinsicGasPayloadLegacy returns the gas increased by the data of a legacy transaction before
+// the istanbul hard fork, priced by the given transaction data config.
+// The default gas prices are used if the config is nil.
+func IntrinsicGasPayloadLegacy(gas uint64, data []byte, config *params.TxDataConfig) (uint64, error) {
 	if len(data) > 0 {
 		// Zero and non-zero bytes are priced differently
 		var nz uint64
@@ -493,16 +514,18 @@ func IntrinsicGasPayloadLegacy(gas uint64, data []byte) (uint64, error) {
 			}
 		}
 		// Make sure we don't exceed uint64 for all data combinations
-		if (math.MaxUint64-gas)/params.TxDataNonZeroGas < nz {
+		nonZeroGas := config.TxDataNonZeroGas()
+		if (math.MaxUint64-gas)/nonZeroGas < nz {
 			return 0, kerrors.ErrOutOfGas
 		}
-		gas += nz * params.TxDataNonZeroGas
+		gas += nz * nonZeroGas
 
 		z := uint64(len(data)) - nz
-		if (math.MaxUint64-gas)/params.TxDataZeroGas < z {
+		zeroGas := config.TxDataZeroGas()
+		if (math.MaxUint64-gas)/zeroGas < z {
 			return 0, kerrors.ErrOutOfGas
 		}
-		gas += z * params.TxDataZeroGas
+		gas += z * zeroGas
 	}
 
 	return gas, nil
@@ -521,9 +544,9 @@ func IntrinsicGas(data []byte, contractCreation bool, r params.Rules) (uint64, e
 	var gasPayloadWithGas uint64
 	var err error
 	if r.IsIstanbul {
-		gasPayloadWithGas, err = IntrinsicGasPayload(gas, data)
+		gasPayloadWithGas, err = IntrinsicGasPayload(gas, data, r.TxData)
 	} else {
-		gasPayloadWithGas, err = IntrinsicGasPayloadLegacy(gas, data)
+		gasPayloadWithGas, err = IntrinsicGasPayloadLegacy(gas, data, r.TxData)
 	}
 	if err != nil {
 		return 0, err
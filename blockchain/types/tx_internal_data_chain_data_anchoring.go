@@ -260,7 +260,7 @@ func (t *TxInternalDataChainDataAnchoring) SetSignature(s TxSignatures) {
 func (t *TxInternalDataChainDataAnchoring) IntrinsicGas(currentBlockNumber uint64) (uint64, error) {
 	gas := params.TxChainDataAnchoringGas
 
-	gasPayloadWithGas, err := IntrinsicGasPayload(gas, t.Payload)
+	gasPayloadWithGas, err := IntrinsicGasPayload(gas, t.Payload, txDataConfig(currentBlockNumber))
 	if err != nil {
 		return 0, err
 	}
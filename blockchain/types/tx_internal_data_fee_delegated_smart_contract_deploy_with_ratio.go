@@ -328,7 +328,7 @@ func (t *TxInternalDataFeeDelegatedSmartContractDeployWithRatio) IntrinsicGas(cu
 		gas += params.TxGasHumanReadable
 	}
 
-	gasPayloadWithGas, err := IntrinsicGasPayload(gas, t.Payload)
+	gasPayloadWithGas, err := IntrinsicGasPayload(gas, t.Payload, txDataConfig(currentBlockNumber))
 	if err != nil {
 		return 0, err
 	}
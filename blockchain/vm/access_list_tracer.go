@@ -0,0 +1,117 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+)
+
+// AccessListTracer is a Tracer collecting the accounts and the storage slots accessed by
+// a transaction. The precompiled contracts are not collected, and the sender and the recipient
+// of the transaction are collected only if their storage slots are accessed.
+type AccessListTracer struct {
+	excluded map[common.Address]struct{}
+	list     map[common.Address]map[common.Hash]struct{}
+}
+
+// NewAccessListTracer returns a new AccessListTracer.
+func NewAccessListTracer() *AccessListTracer {
+	return &AccessListTracer{
+		excluded: make(map[common.Address]struct{}),
+		list:     make(map[common.Address]map[common.Hash]struct{}),
+	}
+}
+
+func (a *AccessListTracer) addAddress(addr common.Address) {
+	if common.IsPrecompiledContractAddress(addr) {
+		return
+	}
+	if _, ok := a.list[addr]; !ok {
+		a.list[addr] = make(map[common.Hash]struct{})
+	}
+}
+
+func (a *AccessListTracer) addSlot(addr common.Address, slot common.Hash) {
+	a.addAddress(addr)
+	if slots, ok := a.list[addr]; ok {
+		slots[slot] = struct{}{}
+	}
+}
+
+func (a *AccessListTracer) CaptureStart(from common.Address, to common.Address, call bool, input []byte, gas uint64, value *big.Int) error {
+	a.excluded[from] = struct{}{}
+	a.excluded[to] = struct{}{}
+	return nil
+}
+
+// CaptureState collects the storage slots read or written by the contract, and the accounts
+// whose balances or codes are read or which are called by the contract.
+func (a *AccessListTracer) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	switch {
+	case (op == SLOAD || op == SSTORE) && stack.len() >= 1:
+		a.addSlot(contract.Address(), common.BigToHash(stack.Back(0)))
+	case (op == EXTCODECOPY || op == EXTCODEHASH || op == EXTCODESIZE || op == BALANCE || op == SELFDESTRUCT) && stack.len() >= 1:
+		a.addAddress(common.BigToAddress(stack.Back(0)))
+	case (op == DELEGATECALL || op == CALL || op == STATICCALL || op == CALLCODE) && stack.len() >= 5:
+		a.addAddress(common.BigToAddress(stack.Back(1)))
+	}
+	return nil
+}
+
+func (a *AccessListTracer) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+func (a *AccessListTracer) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
+	return nil
+}
+
+func (a *AccessListTracer) CaptureEnter(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+func (a *AccessListTracer) CaptureExit(output []byte, gasUsed uint64, err error) error {
+	return nil
+}
+
+// AccessList returns the collected access list sorted by the addresses and the storage keys.
+func (a *AccessListTracer) AccessList() types.AccessList {
+	acl := make(types.AccessList, 0, len(a.list))
+	for addr, slots := range a.list {
+		if _, ok := a.excluded[addr]; ok && len(slots) == 0 {
+			continue
+		}
+		tuple := types.AccessTuple{Address: addr, StorageKeys: make([]common.Hash, 0, len(slots))}
+		for slot := range slots {
+			tuple.StorageKeys = append(tuple.StorageKeys, slot)
+		}
+		sort.Slice(tuple.StorageKeys, func(i, j int) bool {
+			return bytes.Compare(tuple.StorageKeys[i][:], tuple.StorageKeys[j][:]) < 0
+		})
+		acl = append(acl, tuple)
+	}
+	sort.Slice(acl, func(i, j int) bool {
+		return bytes.Compare(acl[i].Address[:], acl[j].Address[:]) < 0
+	})
+	return acl
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessListTracer(t *testing.T) {
+	var (
+		from      = common.HexToAddress("0x000000000000000000000000000000000000a000")
+		to        = common.HexToAddress("0x000000000000000000000000000000000000c000")
+		other     = common.HexToAddress("0x000000000000000000000000000000000000d000")
+		ecrecover = common.BytesToAddress([]byte{1})
+		tracer    = NewAccessListTracer()
+		contract  = NewContract(AccountRef(from), AccountRef(to), new(big.Int), 0)
+		callee    = NewContract(AccountRef(to), AccountRef(other), new(big.Int), 0)
+	)
+	// capture executes the opcode with the stack items given from the top
+	capture := func(contract *Contract, op OpCode, items ...*big.Int) {
+		stack := newstack()
+		for i := len(items) - 1; i >= 0; i-- {
+			stack.push(items[i])
+		}
+		assert.NoError(t, tracer.CaptureState(nil, 0, op, 0, 0, nil, stack, contract, 0, nil))
+	}
+	addrToBig := func(addr common.Address) *big.Int { return new(big.Int).SetBytes(addr.Bytes()) }
+	zero := new(big.Int)
+
+	assert.NoError(t, tracer.CaptureStart(from, to, false, nil, 0, nil))
+	assert.Equal(t, types.AccessList{}, tracer.AccessList())
+
+	capture(contract, SLOAD, big.NewInt(2))
+	capture(contract, SSTORE, big.NewInt(1), big.NewInt(7))
+	capture(contract, SLOAD, big.NewInt(2))
+	capture(contract, BALANCE, addrToBig(from))
+	// The precompiled contracts are not collected
+	capture(contract, STATICCALL, zero, addrToBig(ecrecover), zero, zero, zero, zero)
+	capture(contract, CALL, zero, addrToBig(other), zero, zero, zero, zero, zero)
+	capture(callee, EXTCODESIZE, addrToBig(common.HexToAddress("0x000000000000000000000000000000000000e000")))
+
+	expected := types.AccessList{
+		{Address: to, StorageKeys: []common.Hash{common.BigToHash(big.NewInt(1)), common.BigToHash(big.NewInt(2))}},
+		{Address: other, StorageKeys: []common.Hash{}},
+		{Address: common.HexToAddress("0x000000000000000000000000000000000000e000"), StorageKeys: []common.Hash{}},
+	}
+	assert.Equal(t, expected, tracer.AccessList())
+	assert.Equal(t, 2, expected.StorageKeys())
+}
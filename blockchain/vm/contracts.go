@@ -66,37 +66,20 @@ type PrecompiledContract interface {
 
 // PrecompiledContractsConstantinople contains the default set of pre-compiled Klaytn
 // contracts based on Ethereum Constantinople.
-var PrecompiledContractsConstantinople = map[common.Address]PrecompiledContract{
-	common.BytesToAddress([]byte{1}):  &ecrecover{},
-	common.BytesToAddress([]byte{2}):  &sha256hash{},
-	common.BytesToAddress([]byte{3}):  &ripemd160hash{},
-	common.BytesToAddress([]byte{4}):  &dataCopy{},
-	common.BytesToAddress([]byte{5}):  &bigModExp{},
-	common.BytesToAddress([]byte{6}):  &bn256AddConstantinople{},
-	common.BytesToAddress([]byte{7}):  &bn256ScalarMulConstantinople{},
-	common.BytesToAddress([]byte{8}):  &bn256PairingConstantinople{},
-	common.BytesToAddress([]byte{9}):  &vmLog{},
-	common.BytesToAddress([]byte{10}): &feePayer{},
-	common.BytesToAddress([]byte{11}): &validateSender{},
-}
+var PrecompiledContractsConstantinople = precompiledContractsOf(forkConstantinople)
 
 // DO NOT USE 0x3FD, 0x3FE, 0x3FF ADDRESSES BEFORE ISTANBUL CHANGE ACTIVATED.
 // PrecompiledContractsIstanbul contains the default set of pre-compiled Klaytn
 // contracts based on Ethereum Istanbul.
-var PrecompiledContractsIstanbul = map[common.Address]PrecompiledContract{
-	common.BytesToAddress([]byte{1}):      &ecrecover{},
-	common.BytesToAddress([]byte{2}):      &sha256hash{},
-	common.BytesToAddress([]byte{3}):      &ripemd160hash{},
-	common.BytesToAddress([]byte{4}):      &dataCopy{},
-	common.BytesToAddress([]byte{5}):      &bigModExp{},
-	common.BytesToAddress([]byte{6}):      &bn256AddIstanbul{},
-	common.BytesToAddress([]byte{7}):      &bn256ScalarMulIstanbul{},
-	common.BytesToAddress([]byte{8}):      &bn256PairingIstanbul{},
-	common.BytesToAddress([]byte{9}):      &blake2F{},
-	common.BytesToAddress([]byte{3, 253}): &vmLog{},
-	common.BytesToAddress([]byte{3, 254}): &feePayer{},
-	common.BytesToAddress([]byte{3, 255}): &validateSender{},
-}
+var PrecompiledContractsIstanbul = precompiledContractsOf(forkConstantinople, forkIstanbul)
+
+// PrecompiledContractsBls12381 contains the default set of pre-compiled Klaytn
+// contracts with the BLS12-381 contracts of EIP-2537.
+var PrecompiledContractsBls12381 = precompiledContractsOf(forkConstantinople, forkIstanbul, forkBls12381)
+
+// PrecompiledContractsKZG contains the default set of pre-compiled Klaytn
+// contracts with the KZG point evaluation contract of EIP-4844.
+var PrecompiledContractsKZG = precompiledContractsOf(forkConstantinople, forkIstanbul, forkKZG)
 
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
 func RunPrecompiledContract(p PrecompiledContract, input []byte, contract *Contract, evm *EVM) (ret []byte, computationCost uint64, err error) {
@@ -549,14 +532,16 @@ func (c *vmLog) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64)
 
 // Runs the vmLog contract.
 func (c *vmLog) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
-	if (params.VMLogTarget & params.VMLogToFile) != 0 {
+	target := debug.Handler.VMLogTargetOf(contract.CallerAddress)
+	if (target & params.VMLogToFile) != 0 {
 		prefix := "tx=" + evm.StateDB.GetTxHash().String() + " caller=" + contract.CallerAddress.String() + " msg="
-		debug.Handler.WriteVMLog(prefix + string(input))
+		debug.Handler.WriteContractVMLog(contract.CallerAddress, prefix+string(input))
 	}
-	if (params.VMLogTarget & params.VMLogToStdout) != 0 {
+	if (target & params.VMLogToStdout) != 0 {
 		logger.Debug("vmlog", "tx", evm.StateDB.GetTxHash().String(),
 			"caller", contract.CallerAddress.String(), "msg", strconv.QuoteToASCII(string(input)))
 	}
+	debug.Handler.SendVMLog(evm.StateDB.GetTxHash(), contract.CallerAddress, string(input))
 	return nil, nil
 }
 
@@ -0,0 +1,468 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/klaytn/klaytn/params"
+)
+
+// The BLS12-381 precompiled contracts of EIP-2537.
+// Field elements are encoded as 64 bytes with 16 zero top bytes, Fp2 elements as (c0, c1),
+// G1 points as (x, y) of 128 bytes and G2 points as (x, y) of 256 bytes. The point at infinity
+// is encoded as zeros. Scalars are 32-byte big-endian integers.
+
+const (
+	bls12381FieldElementLength = 64
+	bls12381G1PointLength      = 128
+	bls12381G2PointLength      = 256
+	bls12381ScalarLength       = 32
+
+	bls12381G1MulInputLength     = bls12381G1PointLength + bls12381ScalarLength
+	bls12381G2MulInputLength     = bls12381G2PointLength + bls12381ScalarLength
+	bls12381PairingPairLength    = bls12381G1PointLength + bls12381G2PointLength
+	bls12381FieldElementByteSize = 48
+)
+
+var (
+	errBLS12381InvalidInputLength          = errors.New("invalid input length")
+	errBLS12381InvalidFieldElementTopBytes = errors.New("invalid field element top bytes")
+	errBLS12381G1PointSubgroup             = errors.New("g1 point is not on correct subgroup")
+	errBLS12381G2PointSubgroup             = errors.New("g2 point is not on correct subgroup")
+)
+
+// bls12381MultiExpGas returns the gas of a multi exponentiation of k points with the discount of EIP-2537.
+func bls12381MultiExpGas(k, mulGas uint64) uint64 {
+	if k == 0 {
+		return 0
+	}
+	var discount uint64
+	if dLen := uint64(len(params.Bls12381MultiExpDiscountTable)); k < dLen {
+		discount = params.Bls12381MultiExpDiscountTable[k-1]
+	} else {
+		discount = params.Bls12381MultiExpDiscountTable[dLen-1]
+	}
+	return (k * mulGas * discount) / 1000
+}
+
+// bls12381G1Add implements EIP-2537 G1Add precompile.
+type bls12381G1Add struct{}
+
+func (c *bls12381G1Add) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64) {
+	return params.Bls12381G1AddGas, params.Bls12381G1AddComputationCost
+}
+
+func (c *bls12381G1Add) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	// Implements EIP-2537 G1Add precompile.
+	// > G1 addition call expects `256` bytes as an input that is interpreted as byte concatenation of two G1 points (`128` bytes each).
+	// > Output is an encoding of addition operation result - single G1 point (`128` bytes).
+	if len(input) != 2*bls12381G1PointLength {
+		return nil, errBLS12381InvalidInputLength
+	}
+	g := bls12381.NewG1()
+
+	p0, err := decodeBLS12381G1Point(g, input[:bls12381G1PointLength])
+	if err != nil {
+		return nil, err
+	}
+	p1, err := decodeBLS12381G1Point(g, input[bls12381G1PointLength:])
+	if err != nil {
+		return nil, err
+	}
+
+	r := g.New()
+	g.Add(r, p0, p1)
+	return encodeBLS12381G1Point(g, r), nil
+}
+
+// bls12381G1Mul implements EIP-2537 G1Mul precompile.
+type bls12381G1Mul struct{}
+
+func (c *bls12381G1Mul) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64) {
+	return params.Bls12381G1MulGas, params.Bls12381G1MulComputationCost
+}
+
+func (c *bls12381G1Mul) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	// Implements EIP-2537 G1Mul precompile.
+	// > G1 multiplication call expects `160` bytes as an input that is interpreted as byte concatenation of encoding of G1 point (`128` bytes) and encoding of a scalar value (`32` bytes).
+	// > Output is an encoding of multiplication operation result - single G1 point (`128` bytes).
+	if len(input) != bls12381G1MulInputLength {
+		return nil, errBLS12381InvalidInputLength
+	}
+	g := bls12381.NewG1()
+
+	p0, err := decodeBLS12381G1Point(g, input[:bls12381G1PointLength])
+	if err != nil {
+		return nil, err
+	}
+	e := new(big.Int).SetBytes(input[bls12381G1PointLength:])
+
+	r := g.New()
+	g.MulScalarBig(r, p0, e)
+	return encodeBLS12381G1Point(g, r), nil
+}
+
+// bls12381G1MultiExp implements EIP-2537 G1MultiExp precompile.
+type bls12381G1MultiExp struct{}
+
+func (c *bls12381G1MultiExp) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64) {
+	k := uint64(len(input) / bls12381G1MulInputLength)
+	return bls12381MultiExpGas(k, params.Bls12381G1MulGas),
+		bls12381MultiExpGas(k, params.Bls12381G1MulComputationCost)
+}
+
+func (c *bls12381G1MultiExp) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	// Implements EIP-2537 G1MultiExp precompile.
+	// G1 multiplication call expects `160*k` bytes as an input that is interpreted as byte concatenation of `k` slices each of them being a byte concatenation of encoding of G1 point (`128` bytes) and encoding of a scalar value (`32` bytes).
+	// Output is an encoding of multiexponentiation operation result - single G1 point (`128` bytes).
+	k := len(input) / bls12381G1MulInputLength
+	if len(input) == 0 || len(input)%bls12381G1MulInputLength != 0 {
+		return nil, errBLS12381InvalidInputLength
+	}
+	g := bls12381.NewG1()
+	points := make([]*bls12381.PointG1, k)
+	scalars := make([]*big.Int, k)
+
+	for i := 0; i < k; i++ {
+		off := bls12381G1MulInputLength * i
+		t0, t1 := off, off+bls12381G1PointLength
+		p, err := decodeBLS12381G1Point(g, input[t0:t1])
+		if err != nil {
+			return nil, err
+		}
+		points[i] = p
+		scalars[i] = new(big.Int).SetBytes(input[t1 : t1+bls12381ScalarLength])
+	}
+
+	r := g.New()
+	if _, err := g.MultiExpBig(r, points, scalars); err != nil {
+		return nil, err
+	}
+	return encodeBLS12381G1Point(g, r), nil
+}
+
+// bls12381G2Add implements EIP-2537 G2Add precompile.
+type bls12381G2Add struct{}
+
+func (c *bls12381G2Add) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64) {
+	return params.Bls12381G2AddGas, params.Bls12381G2AddComputationCost
+}
+
+func (c *bls12381G2Add) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	// Implements EIP-2537 G2Add precompile.
+	// > G2 addition call expects `512` bytes as an input that is interpreted as byte concatenation of two G2 points (`256` bytes each).
+	// > Output is an encoding of addition operation result - single G2 point (`256` bytes).
+	if len(input) != 2*bls12381G2PointLength {
+		return nil, errBLS12381InvalidInputLength
+	}
+	g := bls12381.NewG2()
+
+	p0, err := decodeBLS12381G2Point(g, input[:bls12381G2PointLength])
+	if err != nil {
+		return nil, err
+	}
+	p1, err := decodeBLS12381G2Point(g, input[bls12381G2PointLength:])
+	if err != nil {
+		return nil, err
+	}
+
+	r := g.New()
+	g.Add(r, p0, p1)
+	return encodeBLS12381G2Point(g, r), nil
+}
+
+// bls12381G2Mul implements EIP-2537 G2Mul precompile.
+type bls12381G2Mul struct{}
+
+func (c *bls12381G2Mul) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64) {
+	return params.Bls12381G2MulGas, params.Bls12381G2MulComputationCost
+}
+
+func (c *bls12381G2Mul) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	// Implements EIP-2537 G2Mul precompile.
+	// > G2 multiplication call expects `288` bytes as an input that is interpreted as byte concatenation of encoding of G2 point (`256` bytes) and encoding of a scalar value (`32` bytes).
+	// > Output is an encoding of multiplication operation result - single G2 point (`256` bytes).
+	if len(input) != bls12381G2MulInputLength {
+		return nil, errBLS12381InvalidInputLength
+	}
+	g := bls12381.NewG2()
+
+	p0, err := decodeBLS12381G2Point(g, input[:bls12381G2PointLength])
+	if err != nil {
+		return nil, err
+	}
+	e := new(big.Int).SetBytes(input[bls12381G2PointLength:])
+
+	r := g.New()
+	g.MulScalarBig(r, p0, e)
+	return encodeBLS12381G2Point(g, r), nil
+}
+
+// bls12381G2MultiExp implements EIP-2537 G2MultiExp precompile.
+type bls12381G2MultiExp struct{}
+
+func (c *bls12381G2MultiExp) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64) {
+	k := uint64(len(input) / bls12381G2MulInputLength)
+	return bls12381MultiExpGas(k, params.Bls12381G2MulGas),
+		bls12381MultiExpGas(k, params.Bls12381G2MulComputationCost)
+}
+
+func (c *bls12381G2MultiExp) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	// Implements EIP-2537 G2MultiExp precompile logic
+	// > G2 multiplication call expects `288*k` bytes as an input that is interpreted as byte concatenation of `k` slices each of them being a byte concatenation of encoding of G2 point (`256` bytes) and encoding of a scalar value (`32` bytes).
+	// > Output is an encoding of multiexponentiation operation result - single G2 point (`256` bytes).
+	k := len(input) / bls12381G2MulInputLength
+	if len(input) == 0 || len(input)%bls12381G2MulInputLength != 0 {
+		return nil, errBLS12381InvalidInputLength
+	}
+	g := bls12381.NewG2()
+	points := make([]*bls12381.PointG2, k)
+	scalars := make([]*big.Int, k)
+
+	for i := 0; i < k; i++ {
+		off := bls12381G2MulInputLength * i
+		t0, t1 := off, off+bls12381G2PointLength
+		p, err := decodeBLS12381G2Point(g, input[t0:t1])
+		if err != nil {
+			return nil, err
+		}
+		points[i] = p
+		scalars[i] = new(big.Int).SetBytes(input[t1 : t1+bls12381ScalarLength])
+	}
+
+	r := g.New()
+	if _, err := g.MultiExpBig(r, points, scalars); err != nil {
+		return nil, err
+	}
+	return encodeBLS12381G2Point(g, r), nil
+}
+
+// bls12381Pairing implements EIP-2537 Pairing precompile.
+type bls12381Pairing struct{}
+
+func (c *bls12381Pairing) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64) {
+	k := uint64(len(input) / bls12381PairingPairLength)
+	return params.Bls12381PairingBaseGas + k*params.Bls12381PairingPerPairGas,
+		params.Bls12381PairingBaseComputationCost + k*params.Bls12381PerPairComputationCost
+}
+
+func (c *bls12381Pairing) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	// Implements EIP-2537 Pairing precompile logic.
+	// > Pairing call expects `384*k` bytes as an inputs that is interpreted as byte concatenation of `k` slices. Each slice has the following structure:
+	// > - `128` bytes of G1 point encoding
+	// > - `256` bytes of G2 point encoding
+	// > Output is a `32` bytes where last single byte is `0x01` if pairing result is equal to multiplicative identity in a pairing target field and `0x00` otherwise
+	// > (which is equivalent of Big Endian encoding of Solidity values `uint256(1)` and `uin256(0)` respectively).
+	k := len(input) / bls12381PairingPairLength
+	if len(input) == 0 || len(input)%bls12381PairingPairLength != 0 {
+		return nil, errBLS12381InvalidInputLength
+	}
+
+	// Initialize BLS12-381 pairing engine
+	e := bls12381.NewEngine()
+	g1, g2 := e.G1, e.G2
+
+	// Decode pairs
+	for i := 0; i < k; i++ {
+		off := bls12381PairingPairLength * i
+		t0, t1, t2 := off, off+bls12381G1PointLength, off+bls12381PairingPairLength
+
+		// Decode G1 point
+		p1, err := decodeBLS12381G1Point(g1, input[t0:t1])
+		if err != nil {
+			return nil, err
+		}
+		// Decode G2 point
+		p2, err := decodeBLS12381G2Point(g2, input[t1:t2])
+		if err != nil {
+			return nil, err
+		}
+
+		// 'point is on curve' check already done,
+		// Here we need to apply subgroup checks.
+		if !g1.InCorrectSubgroup(p1) {
+			return nil, errBLS12381G1PointSubgroup
+		}
+		if !g2.InCorrectSubgroup(p2) {
+			return nil, errBLS12381G2PointSubgroup
+		}
+
+		// Update pairing engine with G1 and G2 points
+		e.AddPair(p1, p2)
+	}
+
+	// Prepare 32 byte output
+	out := make([]byte, 32)
+
+	// Compute pairing and set the result
+	if e.Check() {
+		out[31] = 1
+	}
+	return out, nil
+}
+
+// bls12381MapG1 implements EIP-2537 MapG1 precompile.
+type bls12381MapG1 struct{}
+
+func (c *bls12381MapG1) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64) {
+	return params.Bls12381MapG1Gas, params.Bls12381MapG1ComputationCost
+}
+
+func (c *bls12381MapG1) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	// Implements EIP-2537 Map_To_G1 precompile.
+	// > Field-to-curve call expects `64` bytes an an input that is interpreted as a an element of the base field.
+	// > Output of this call is `128` bytes and is G1 point following respective encoding rules.
+	if len(input) != bls12381FieldElementLength {
+		return nil, errBLS12381InvalidInputLength
+	}
+
+	// Decode input field element
+	fe, err := decodeBLS12381FieldElement(input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize G1
+	g := bls12381.NewG1()
+
+	// Compute mapping
+	r, err := g.MapToCurve(fe)
+	if err != nil {
+		return nil, err
+	}
+	return encodeBLS12381G1Point(g, r), nil
+}
+
+// bls12381MapG2 implements EIP-2537 MapG2 precompile.
+type bls12381MapG2 struct{}
+
+func (c *bls12381MapG2) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64) {
+	return params.Bls12381MapG2Gas, params.Bls12381MapG2ComputationCost
+}
+
+func (c *bls12381MapG2) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	// Implements EIP-2537 Map_FP2_TO_G2 precompile logic.
+	// > Field-to-curve call expects `128` bytes an an input that is interpreted as a an element of the quadratic extension field.
+	// > Output of this call is `256` bytes and is G2 point following respective encoding rules.
+	if len(input) != 2*bls12381FieldElementLength {
+		return nil, errBLS12381InvalidInputLength
+	}
+
+	// Decode input field element; the library expects (c1, c0)
+	c0, err := decodeBLS12381FieldElement(input[:bls12381FieldElementLength])
+	if err != nil {
+		return nil, err
+	}
+	c1, err := decodeBLS12381FieldElement(input[bls12381FieldElementLength:])
+	if err != nil {
+		return nil, err
+	}
+	fe := make([]byte, 2*bls12381FieldElementByteSize)
+	copy(fe[:bls12381FieldElementByteSize], c1)
+	copy(fe[bls12381FieldElementByteSize:], c0)
+
+	// Initialize G2
+	g := bls12381.NewG2()
+
+	// Compute mapping
+	r, err := g.MapToCurve(fe)
+	if err != nil {
+		return nil, err
+	}
+	return encodeBLS12381G2Point(g, r), nil
+}
+
+// decodeBLS12381FieldElement decodes a 64-byte field element into the 48-byte form
+// after checking that the top 16 bytes are zero.
+func decodeBLS12381FieldElement(in []byte) ([]byte, error) {
+	if len(in) != bls12381FieldElementLength {
+		return nil, errBLS12381InvalidInputLength
+	}
+	// check top bytes
+	padding := bls12381FieldElementLength - bls12381FieldElementByteSize
+	for i := 0; i < padding; i++ {
+		if in[i] != byte(0x00) {
+			return nil, errBLS12381InvalidFieldElementTopBytes
+		}
+	}
+	out := make([]byte, bls12381FieldElementByteSize)
+	copy(out, in[padding:])
+	return out, nil
+}
+
+// decodeBLS12381G1Point decodes a G1 point and checks that it is on the curve.
+func decodeBLS12381G1Point(g *bls12381.G1, in []byte) (*bls12381.PointG1, error) {
+	if len(in) != bls12381G1PointLength {
+		return nil, errBLS12381InvalidInputLength
+	}
+	x, err := decodeBLS12381FieldElement(in[:bls12381FieldElementLength])
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeBLS12381FieldElement(in[bls12381FieldElementLength:])
+	if err != nil {
+		return nil, err
+	}
+	return g.FromBytes(append(x, y...))
+}
+
+// decodeBLS12381G2Point decodes a G2 point and checks that it is on the curve.
+func decodeBLS12381G2Point(g *bls12381.G2, in []byte) (*bls12381.PointG2, error) {
+	if len(in) != bls12381G2PointLength {
+		return nil, errBLS12381InvalidInputLength
+	}
+	// The coordinates are encoded as (c0, c1) while the library expects (c1, c0).
+	var elems [4][]byte
+	for i := range elems {
+		fe, err := decodeBLS12381FieldElement(in[i*bls12381FieldElementLength : (i+1)*bls12381FieldElementLength])
+		if err != nil {
+			return nil, err
+		}
+		elems[i] = fe
+	}
+	b := make([]byte, 0, 4*bls12381FieldElementByteSize)
+	b = append(b, elems[1]...)
+	b = append(b, elems[0]...)
+	b = append(b, elems[3]...)
+	b = append(b, elems[2]...)
+	return g.FromBytes(b)
+}
+
+// encodeBLS12381G1Point encodes a G1 point into 128 bytes.
+func encodeBLS12381G1Point(g *bls12381.G1, p *bls12381.PointG1) []byte {
+	b := g.ToBytes(p)
+	out := make([]byte, bls12381G1PointLength)
+	padding := bls12381FieldElementLength - bls12381FieldElementByteSize
+	copy(out[padding:bls12381FieldElementLength], b[:bls12381FieldElementByteSize])
+	copy(out[bls12381FieldElementLength+padding:], b[bls12381FieldElementByteSize:])
+	return out
+}
+
+// encodeBLS12381G2Point encodes a G2 point into 256 bytes.
+func encodeBLS12381G2Point(g *bls12381.G2, p *bls12381.PointG2) []byte {
+	// The library returns (x.c1, x.c0, y.c1, y.c0) while they are encoded as (x.c0, x.c1, y.c0, y.c1).
+	b := g.ToBytes(p)
+	out := make([]byte, bls12381G2PointLength)
+	padding := bls12381FieldElementLength - bls12381FieldElementByteSize
+	for i, j := range []int{1, 0, 3, 2} {
+		copy(out[i*bls12381FieldElementLength+padding:(i+1)*bls12381FieldElementLength], b[j*bls12381FieldElementByteSize:(j+1)*bls12381FieldElementByteSize])
+	}
+	return out
+}
@@ -0,0 +1,175 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	bls12381G1GeneratorX   = common.Hex2Bytes("17f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb")
+	bls12381G2GeneratorXC0 = common.Hex2Bytes("024aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb8")
+	bls12381G2GeneratorXC1 = common.Hex2Bytes("13e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e")
+)
+
+func runBLS12381(t *testing.T, p PrecompiledContract, input []byte) []byte {
+	out, err := p.Run(input, nil, nil)
+	require.NoError(t, err)
+	return out
+}
+
+func bls12381Scalar(n int64) []byte {
+	return common.LeftPadBytes(big.NewInt(n).Bytes(), bls12381ScalarLength)
+}
+
+func concat(bs ...[]byte) []byte {
+	return bytes.Join(bs, nil)
+}
+
+func TestBLS12381Encoding(t *testing.T) {
+	g1, g2 := bls12381.NewG1(), bls12381.NewG2()
+
+	p1 := encodeBLS12381G1Point(g1, g1.One())
+	assert.Equal(t, make([]byte, 16), p1[:16])
+	assert.Equal(t, bls12381G1GeneratorX, p1[16:64])
+	decoded1, err := decodeBLS12381G1Point(g1, p1)
+	require.NoError(t, err)
+	assert.True(t, g1.Equal(g1.One(), decoded1))
+
+	p2 := encodeBLS12381G2Point(g2, g2.One())
+	assert.Equal(t, bls12381G2GeneratorXC0, p2[16:64])
+	assert.Equal(t, bls12381G2GeneratorXC1, p2[80:128])
+	decoded2, err := decodeBLS12381G2Point(g2, p2)
+	require.NoError(t, err)
+	assert.True(t, g2.Equal(g2.One(), decoded2))
+
+	// the point at infinity is encoded as zeros
+	assert.Equal(t, make([]byte, bls12381G1PointLength), encodeBLS12381G1Point(g1, g1.Zero()))
+
+	// invalid top bytes
+	invalid := common.CopyBytes(p1)
+	invalid[0] = 1
+	_, err = decodeBLS12381G1Point(g1, invalid)
+	assert.Equal(t, errBLS12381InvalidFieldElementTopBytes, err)
+
+	// not on the curve
+	invalid = common.CopyBytes(p1)
+	invalid[127] ^= 1
+	_, err = decodeBLS12381G1Point(g1, invalid)
+	assert.Error(t, err)
+}
+
+func TestBLS12381G1(t *testing.T) {
+	g := bls12381.NewG1()
+	p := encodeBLS12381G1Point(g, g.One())
+
+	double := runBLS12381(t, &bls12381G1Add{}, concat(p, p))
+	assert.Equal(t, double, runBLS12381(t, &bls12381G1Mul{}, concat(p, bls12381Scalar(2))))
+
+	five := runBLS12381(t, &bls12381G1Mul{}, concat(p, bls12381Scalar(5)))
+	assert.Equal(t, five, runBLS12381(t, &bls12381G1MultiExp{}, concat(p, bls12381Scalar(2), p, bls12381Scalar(3))))
+
+	_, err := (&bls12381G1Add{}).Run(p, nil, nil)
+	assert.Equal(t, errBLS12381InvalidInputLength, err)
+	_, err = (&bls12381G1MultiExp{}).Run(nil, nil, nil)
+	assert.Equal(t, errBLS12381InvalidInputLength, err)
+}
+
+func TestBLS12381G2(t *testing.T) {
+	g := bls12381.NewG2()
+	p := encodeBLS12381G2Point(g, g.One())
+
+	double := runBLS12381(t, &bls12381G2Add{}, concat(p, p))
+	assert.Equal(t, double, runBLS12381(t, &bls12381G2Mul{}, concat(p, bls12381Scalar(2))))
+
+	five := runBLS12381(t, &bls12381G2Mul{}, concat(p, bls12381Scalar(5)))
+	assert.Equal(t, five, runBLS12381(t, &bls12381G2MultiExp{}, concat(p, bls12381Scalar(2), p, bls12381Scalar(3))))
+
+	_, err := (&bls12381G2Mul{}).Run(p, nil, nil)
+	assert.Equal(t, errBLS12381InvalidInputLength, err)
+}
+
+func TestBLS12381Pairing(t *testing.T) {
+	g1, g2 := bls12381.NewG1(), bls12381.NewG2()
+	p1 := encodeBLS12381G1Point(g1, g1.One())
+	negP1 := encodeBLS12381G1Point(g1, g1.Neg(g1.New(), g1.One()))
+	p2 := encodeBLS12381G2Point(g2, g2.One())
+
+	// e(P, Q) * e(-P, Q) == 1
+	out := runBLS12381(t, &bls12381Pairing{}, concat(p1, p2, negP1, p2))
+	assert.Equal(t, common.LeftPadBytes([]byte{1}, 32), out)
+
+	// e(P, Q) != 1
+	out = runBLS12381(t, &bls12381Pairing{}, concat(p1, p2))
+	assert.Equal(t, make([]byte, 32), out)
+
+	_, err := (&bls12381Pairing{}).Run(p1, nil, nil)
+	assert.Equal(t, errBLS12381InvalidInputLength, err)
+}
+
+func TestBLS12381MapToCurve(t *testing.T) {
+	fe := common.LeftPadBytes([]byte{1, 2, 3}, bls12381FieldElementLength)
+
+	g1 := bls12381.NewG1()
+	out := runBLS12381(t, &bls12381MapG1{}, fe)
+	p1, err := decodeBLS12381G1Point(g1, out)
+	require.NoError(t, err)
+	assert.True(t, g1.InCorrectSubgroup(p1))
+
+	g2 := bls12381.NewG2()
+	out = runBLS12381(t, &bls12381MapG2{}, concat(fe, fe))
+	p2, err := decodeBLS12381G2Point(g2, out)
+	require.NoError(t, err)
+	assert.True(t, g2.InCorrectSubgroup(p2))
+
+	// the field element should be less than the modulus
+	_, err = (&bls12381MapG1{}).Run(concat(make([]byte, 16), bytes.Repeat([]byte{0xff}, 48)), nil, nil)
+	assert.Error(t, err)
+}
+
+func TestBLS12381Gas(t *testing.T) {
+	gas, _ := (&bls12381G1MultiExp{}).GetRequiredGasAndComputationCost(make([]byte, 2*bls12381G1MulInputLength))
+	assert.Equal(t, 2*params.Bls12381G1MulGas*888/1000, gas)
+
+	gas, _ = (&bls12381G2MultiExp{}).GetRequiredGasAndComputationCost(make([]byte, 200*bls12381G2MulInputLength))
+	assert.Equal(t, 200*params.Bls12381G2MulGas*174/1000, gas)
+
+	gas, _ = (&bls12381Pairing{}).GetRequiredGasAndComputationCost(make([]byte, 3*bls12381PairingPairLength))
+	assert.Equal(t, params.Bls12381PairingBaseGas+3*params.Bls12381PairingPerPairGas, gas)
+}
+
+func TestBLS12381Activation(t *testing.T) {
+	assert.Nil(t, PrecompiledContractsIstanbul[common.BytesToAddress([]byte{11})])
+	assert.IsType(t, &bls12381G1Add{}, PrecompiledContractsBls12381[common.BytesToAddress([]byte{11})])
+	assert.IsType(t, &bls12381MapG2{}, PrecompiledContractsBls12381[common.BytesToAddress([]byte{19})])
+
+	rules := params.Rules{IsIstanbul: true, IsBls12381: true}
+	forks := enabledPrecompiledContractsForks(rules)
+	assert.Equal(t, PrecompiledContractsBls12381, precompiledContractsOfForks(forks))
+	// BLS12-381 contracts are not enabled without Istanbul
+	assert.Equal(t, PrecompiledContractsConstantinople, precompiledContractsOfForks(enabledPrecompiledContractsForks(params.Rules{IsBls12381: true})))
+	// contracts deployed before Istanbul do not see the BLS12-381 contracts
+	assert.Equal(t, PrecompiledContractsConstantinople, precompiledContractsOfForks(precompiledContractsForksOfVmVersion(forks, params.VmVersion0)))
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto/kzg4844"
+	"github.com/klaytn/klaytn/params"
+)
+
+const kzgPointEvaluationInputLength = 192
+
+var (
+	errKZGPointEvaluationInputLength = errors.New("invalid input length")
+	errKZGPointEvaluationMismatch    = errors.New("mismatched versioned hash")
+)
+
+// kzgPointEvaluationReturnValue is FIELD_ELEMENTS_PER_BLOB and BLS_MODULUS as 32-byte big-endian values.
+var kzgPointEvaluationReturnValue = append(
+	common.LeftPadBytes(new(big.Int).SetUint64(params.KZGFieldElementsPerBlob).Bytes(), 32),
+	common.LeftPadBytes(kzg4844.BLSModulus.Bytes(), 32)...,
+)
+
+// kzgPointEvaluation implements the EIP-4844 point evaluation precompile.
+type kzgPointEvaluation struct{}
+
+func (c *kzgPointEvaluation) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64) {
+	return params.KZGPointEvaluationGas, params.KZGPointEvaluationComputationCost
+}
+
+func (c *kzgPointEvaluation) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	// The input is the concatenation of
+	// versioned_hash (32 bytes), z (32 bytes), y (32 bytes), commitment (48 bytes) and proof (48 bytes).
+	if len(input) != kzgPointEvaluationInputLength {
+		return nil, errKZGPointEvaluationInputLength
+	}
+	var (
+		commitment kzg4844.Commitment
+		point      kzg4844.Point
+		claim      kzg4844.Claim
+		proof      kzg4844.Proof
+	)
+	copy(point[:], input[32:64])
+	copy(claim[:], input[64:96])
+	copy(commitment[:], input[96:144])
+	copy(proof[:], input[144:192])
+
+	// Verify the commitment matches the versioned hash
+	if vh := kzg4844.CalcBlobHashV1(&commitment); !bytes.Equal(vh[:], input[:32]) {
+		return nil, errKZGPointEvaluationMismatch
+	}
+
+	// Verify the KZG proof with z and y
+	if err := kzg4844.VerifyProof(commitment, point, claim, proof); err != nil {
+		return nil, fmt.Errorf("error verifying kzg proof: %v", err)
+	}
+	return common.CopyBytes(kzgPointEvaluationReturnValue), nil
+}
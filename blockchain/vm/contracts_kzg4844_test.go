@@ -0,0 +1,72 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	kzgPointEvaluationTestInput    = "01e798154708fe7789429634053cbf9f99b619f9f084048927333fce637f549b564c0a11a0f704f4fc3e8acfe0f8245f0ad1347b378fbf96e206da11a5d3630624d25032e67a7e6a4910df5834b8fe70e6bcfeeac0352434196bdf4b2485d5a18f59a8d2a1a625a17f3fea0fe5eb8c896db3764f3185481bc22f91b4aaffcca25f26936857bc3a7c2539ea8ec3a952b7873033e038326e87ed3e1276fd140253fa08e9fc25fb2d9a98527fc22a2c9612fbeafdad446cbc7bcdbdcd780af2c16a"
+	kzgPointEvaluationTestExpected = "000000000000000000000000000000000000000000000000000000000000100073eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001"
+)
+
+func TestKZGPointEvaluation(t *testing.T) {
+	p := &kzgPointEvaluation{}
+	input := common.Hex2Bytes(kzgPointEvaluationTestInput)
+
+	out, err := p.Run(input, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, kzgPointEvaluationTestExpected, common.Bytes2Hex(out))
+
+	gas, _ := p.GetRequiredGasAndComputationCost(input)
+	assert.Equal(t, params.KZGPointEvaluationGas, gas)
+
+	// mismatched versioned hash
+	invalid := common.CopyBytes(input)
+	invalid[1] ^= 1
+	_, err = p.Run(invalid, nil, nil)
+	assert.Equal(t, errKZGPointEvaluationMismatch, err)
+
+	// wrong claim
+	invalid = common.CopyBytes(input)
+	invalid[95] ^= 1
+	_, err = p.Run(invalid, nil, nil)
+	assert.Error(t, err)
+
+	_, err = p.Run(input[:191], nil, nil)
+	assert.Equal(t, errKZGPointEvaluationInputLength, err)
+}
+
+func TestKZGPointEvaluationActivation(t *testing.T) {
+	addr := common.BytesToAddress([]byte{10})
+	assert.IsType(t, &kzgPointEvaluation{}, PrecompiledContractsKZG[addr])
+	assert.Nil(t, PrecompiledContractsKZG[common.BytesToAddress([]byte{11})])
+
+	forks := enabledPrecompiledContractsForks(params.Rules{IsIstanbul: true, IsBls12381: true, IsKZG: true})
+	contracts := precompiledContractsOfForks(forks)
+	assert.IsType(t, &kzgPointEvaluation{}, contracts[addr])
+	assert.IsType(t, &bls12381G1Add{}, contracts[common.BytesToAddress([]byte{11})])
+
+	// contracts deployed before Istanbul keep using feePayer at 0x0a
+	contracts = precompiledContractsOfForks(precompiledContractsForksOfVmVersion(forks, params.VmVersion0))
+	assert.IsType(t, &feePayer{}, contracts[addr])
+}
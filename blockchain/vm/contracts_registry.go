@@ -0,0 +1,289 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/math"
+	"github.com/klaytn/klaytn/params"
+)
+
+const (
+	forkConstantinople = "constantinople"
+	forkIstanbul       = "istanbul"
+	forkBls12381       = "bls12381"
+	forkKZG            = "kzg"
+)
+
+// precompiledContractsFork is the change of the precompiled contracts made by a hardfork.
+// The set of the precompiled contracts is built by applying the changes of the enabled hardforks in order;
+// the contracts of `added` are added or replace the previous ones at the same address,
+// and the ones at `removed` are removed.
+type precompiledContractsFork struct {
+	name string
+	// vmVersion is the version of the contracts deployed after the hardfork.
+	// The contracts deployed with an older vmVersion do not see the changes of the hardfork.
+	vmVersion params.VmVersion
+	enabled   func(rules params.Rules) bool
+	added     map[common.Address]PrecompiledContract
+	removed   []common.Address
+}
+
+// precompiledContractsForks registers the precompiled contracts per hardfork in the order of the hardforks.
+// If a new precompiled contract is added with a hardfork, add a new entry below.
+var precompiledContractsForks = []*precompiledContractsFork{
+	{
+		name:      forkConstantinople,
+		vmVersion: params.VmVersion0,
+		enabled:   func(rules params.Rules) bool { return true },
+		added: map[common.Address]PrecompiledContract{
+			common.BytesToAddress([]byte{1}):  &ecrecover{},
+			common.BytesToAddress([]byte{2}):  &sha256hash{},
+			common.BytesToAddress([]byte{3}):  &ripemd160hash{},
+			common.BytesToAddress([]byte{4}):  &dataCopy{},
+			common.BytesToAddress([]byte{5}):  &bigModExp{},
+			common.BytesToAddress([]byte{6}):  &bn256AddConstantinople{},
+			common.BytesToAddress([]byte{7}):  &bn256ScalarMulConstantinople{},
+			common.BytesToAddress([]byte{8}):  &bn256PairingConstantinople{},
+			common.BytesToAddress([]byte{9}):  &vmLog{},
+			common.BytesToAddress([]byte{10}): &feePayer{},
+			common.BytesToAddress([]byte{11}): &validateSender{},
+		},
+	},
+	{
+		name:      forkIstanbul,
+		vmVersion: params.VmVersion1,
+		enabled:   func(rules params.Rules) bool { return rules.IsIstanbul },
+		added: map[common.Address]PrecompiledContract{
+			common.BytesToAddress([]byte{6}):      &bn256AddIstanbul{},
+			common.BytesToAddress([]byte{7}):      &bn256ScalarMulIstanbul{},
+			common.BytesToAddress([]byte{8}):      &bn256PairingIstanbul{},
+			common.BytesToAddress([]byte{9}):      &blake2F{},
+			common.BytesToAddress([]byte{3, 253}): &vmLog{},
+			common.BytesToAddress([]byte{3, 254}): &feePayer{},
+			common.BytesToAddress([]byte{3, 255}): &validateSender{},
+		},
+		removed: []common.Address{
+			common.BytesToAddress([]byte{10}),
+			common.BytesToAddress([]byte{11}),
+		},
+	},
+	{
+		name:      forkBls12381,
+		vmVersion: params.VmVersion1,
+		enabled:   func(rules params.Rules) bool { return rules.IsIstanbul && rules.IsBls12381 },
+		added: map[common.Address]PrecompiledContract{
+			common.BytesToAddress([]byte{11}): &bls12381G1Add{},
+			common.BytesToAddress([]byte{12}): &bls12381G1Mul{},
+			common.BytesToAddress([]byte{13}): &bls12381G1MultiExp{},
+			common.BytesToAddress([]byte{14}): &bls12381G2Add{},
+			common.BytesToAddress([]byte{15}): &bls12381G2Mul{},
+			common.BytesToAddress([]byte{16}): &bls12381G2MultiExp{},
+			common.BytesToAddress([]byte{17}): &bls12381Pairing{},
+			common.BytesToAddress([]byte{18}): &bls12381MapG1{},
+			common.BytesToAddress([]byte{19}): &bls12381MapG2{},
+		},
+	},
+	{
+		name:      forkKZG,
+		vmVersion: params.VmVersion1,
+		enabled:   func(rules params.Rules) bool { return rules.IsIstanbul && rules.IsKZG },
+		added: map[common.Address]PrecompiledContract{
+			common.BytesToAddress([]byte{10}): &kzgPointEvaluation{},
+		},
+	},
+}
+
+// precompiledContractsCache caches the sets of the precompiled contracts by the bitmask of the hardforks.
+var precompiledContractsCache sync.Map
+
+// precompiledContractsOfForks returns the set of the precompiled contracts built from the hardforks
+// of the bitmask. The returned map is shared and must not be modified.
+func precompiledContractsOfForks(forks uint64) map[common.Address]PrecompiledContract {
+	if contracts, ok := precompiledContractsCache.Load(forks); ok {
+		return contracts.(map[common.Address]PrecompiledContract)
+	}
+	contracts := make(map[common.Address]PrecompiledContract)
+	for i, fork := range precompiledContractsForks {
+		if forks&(1<<uint(i)) == 0 {
+			continue
+		}
+		for _, addr := range fork.removed {
+			delete(contracts, addr)
+		}
+		for addr, p := range fork.added {
+			contracts[addr] = p
+		}
+	}
+	actual, _ := precompiledContractsCache.LoadOrStore(forks, contracts)
+	return actual.(map[common.Address]PrecompiledContract)
+}
+
+// precompiledContractsOf returns the set of the precompiled contracts built from the given hardforks.
+func precompiledContractsOf(names ...string) map[common.Address]PrecompiledContract {
+	var forks uint64
+	for _, name := range names {
+		found := false
+		for i, fork := range precompiledContractsForks {
+			if fork.name == name {
+				forks |= 1 << uint(i)
+				found = true
+			}
+		}
+		if !found {
+			panic("unknown hardfork of precompiled contracts: " + name)
+		}
+	}
+	return precompiledContractsOfForks(forks)
+}
+
+// enabledPrecompiledContractsForks returns the bitmask of the hardforks enabled by the rules.
+func enabledPrecompiledContractsForks(rules params.Rules) uint64 {
+	var forks uint64
+	for i, fork := range precompiledContractsForks {
+		if fork.enabled(rules) {
+			forks |= 1 << uint(i)
+		}
+	}
+	return forks
+}
+
+// precompiledContractsForksOfVmVersion excludes the hardforks later than the vmVersion from the bitmask,
+// so that the contracts deployed before a hardfork keep using the precompiled contracts of their deployment time.
+func precompiledContractsForksOfVmVersion(forks uint64, vmVersion params.VmVersion) uint64 {
+	for i, fork := range precompiledContractsForks {
+		if fork.vmVersion > vmVersion {
+			forks &^= 1 << uint(i)
+		}
+	}
+	return forks
+}
+
+// chainPrecompileImpls are the native implementations which can be used as the chain-specific
+// precompiled contracts configured by params.ChainConfig.Precompiles.
+var chainPrecompileImpls = map[string]PrecompiledContract{
+	"ecrecover": &ecrecover{},
+	"sha256":    &sha256hash{},
+	"ripemd160": &ripemd160hash{},
+	"dataCopy":  &dataCopy{},
+	"bigModExp": &bigModExp{},
+	"bn256Add":  &bn256AddIstanbul{},
+	"bn256Mul":  &bn256ScalarMulIstanbul{},
+	"bn256Pair": &bn256PairingIstanbul{},
+	"blake2F":   &blake2F{},
+}
+
+// RegisterChainPrecompile registers a native implementation which can be used as a chain-specific
+// precompiled contract of a service chain. The gas prices of the implementation are ignored
+// and the ones of the chain config are used. It should be called before the node is started.
+func RegisterChainPrecompile(name string, p PrecompiledContract) {
+	if _, ok := chainPrecompileImpls[name]; ok {
+		panic("chain precompile already registered: " + name)
+	}
+	chainPrecompileImpls[name] = p
+}
+
+// chainPrecompile is a chain-specific precompiled contract charged with the gas prices of its config.
+type chainPrecompile struct {
+	PrecompiledContract
+	config *params.PrecompileConfig
+}
+
+func (c *chainPrecompile) GetRequiredGasAndComputationCost(input []byte) (uint64, uint64) {
+	words := (uint64(len(input)) + 31) / 32
+	perWordGas, overflow := math.SafeMul(words, c.config.PerWordGas)
+	if overflow {
+		return math.MaxUint64, c.config.ComputationCost
+	}
+	gas, overflow := math.SafeAdd(c.config.BaseGas, perWordGas)
+	if overflow {
+		return math.MaxUint64, c.config.ComputationCost
+	}
+	return gas, c.config.ComputationCost
+}
+
+// ValidateChainPrecompiles checks whether the chain-specific precompiled contracts of the chain config
+// have registered implementations and distinct addresses in the precompiled contract address range
+// which are not used by the precompiled contracts of any hardfork.
+func ValidateChainPrecompiles(config *params.ChainConfig) error {
+	seen := make(map[common.Address]string)
+	for _, pc := range config.Precompiles {
+		if _, ok := chainPrecompileImpls[pc.Name]; !ok {
+			return fmt.Errorf("unknown chain precompile %q", pc.Name)
+		}
+		if !common.IsPrecompiledContractAddress(pc.Address) {
+			return fmt.Errorf("chain precompile %q has an address out of the precompiled contract range: %s", pc.Name, pc.Address.String())
+		}
+		for _, fork := range precompiledContractsForks {
+			if _, ok := fork.added[pc.Address]; ok {
+				return fmt.Errorf("chain precompile %q uses the address of a %s precompiled contract: %s", pc.Name, fork.name, pc.Address.String())
+			}
+		}
+		if name, ok := seen[pc.Address]; ok {
+			return fmt.Errorf("chain precompiles %q and %q use the same address: %s", name, pc.Name, pc.Address.String())
+		}
+		seen[pc.Address] = pc.Name
+	}
+	return nil
+}
+
+// activeChainPrecompiles returns the chain-specific precompiled contracts activated at the block number.
+// Unknown implementations are skipped; they are rejected by ValidateChainPrecompiles on startup.
+func activeChainPrecompiles(config *params.ChainConfig, num *big.Int) map[common.Address]PrecompiledContract {
+	var contracts map[common.Address]PrecompiledContract
+	for _, pc := range config.Precompiles {
+		impl, ok := chainPrecompileImpls[pc.Name]
+		if !ok || !pc.IsActivated(num) {
+			continue
+		}
+		if contracts == nil {
+			contracts = make(map[common.Address]PrecompiledContract)
+		}
+		contracts[pc.Address] = &chainPrecompile{PrecompiledContract: impl, config: pc}
+	}
+	return contracts
+}
+
+// precompiledContracts returns the precompiled contracts of the hardforks including the chain-specific ones,
+// excluding the ones disabled by the chain config.
+func (evm *EVM) precompiledContracts(forks uint64) map[common.Address]PrecompiledContract {
+	contracts := precompiledContractsOfForks(forks)
+	if len(evm.chainPrecompiles) == 0 && len(evm.disabledPrecompiles) == 0 {
+		return contracts
+	}
+	if merged, ok := evm.mergedPrecompiles[forks]; ok {
+		return merged
+	}
+	merged := make(map[common.Address]PrecompiledContract, len(contracts)+len(evm.chainPrecompiles))
+	for addr, p := range contracts {
+		if !evm.disabledPrecompiles[addr] {
+			merged[addr] = p
+		}
+	}
+	for addr, p := range evm.chainPrecompiles {
+		merged[addr] = p
+	}
+	if evm.mergedPrecompiles == nil {
+		evm.mergedPrecompiles = make(map[uint64]map[common.Address]PrecompiledContract)
+	}
+	evm.mergedPrecompiles[forks] = merged
+	return merged
+}
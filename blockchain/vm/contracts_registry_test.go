@@ -0,0 +1,96 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrecompiledContractsForks(t *testing.T) {
+	assert.Equal(t, 11, len(PrecompiledContractsConstantinople))
+	assert.IsType(t, &vmLog{}, PrecompiledContractsConstantinople[common.BytesToAddress([]byte{9})])
+	assert.IsType(t, &validateSender{}, PrecompiledContractsConstantinople[common.BytesToAddress([]byte{11})])
+
+	assert.Equal(t, 12, len(PrecompiledContractsIstanbul))
+	assert.IsType(t, &blake2F{}, PrecompiledContractsIstanbul[common.BytesToAddress([]byte{9})])
+	assert.IsType(t, &bn256AddIstanbul{}, PrecompiledContractsIstanbul[common.BytesToAddress([]byte{6})])
+	assert.Nil(t, PrecompiledContractsIstanbul[common.BytesToAddress([]byte{10})])
+	assert.IsType(t, &validateSender{}, PrecompiledContractsIstanbul[common.BytesToAddress([]byte{3, 255})])
+
+	constantinople, istanbul := uint64(1<<0), uint64(1<<1)
+	assert.Equal(t, constantinople, enabledPrecompiledContractsForks(params.Rules{}))
+	assert.Equal(t, constantinople|istanbul, enabledPrecompiledContractsForks(params.Rules{IsIstanbul: true}))
+	assert.Equal(t, constantinople, precompiledContractsForksOfVmVersion(constantinople|istanbul, params.VmVersion0))
+	assert.Equal(t, constantinople|istanbul, precompiledContractsForksOfVmVersion(constantinople|istanbul, params.VmVersion1))
+}
+
+func TestChainPrecompiles(t *testing.T) {
+	addr := common.BytesToAddress([]byte{3, 0})
+	config := *params.TestChainConfig
+	config.Precompiles = []*params.PrecompileConfig{
+		{Name: "sha256", Address: addr, ActivationBlock: big.NewInt(10), BaseGas: 10, PerWordGas: 2, ComputationCost: 100},
+	}
+	assert.NoError(t, ValidateChainPrecompiles(&config))
+
+	stateDb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
+
+	// not activated yet
+	evm := NewEVM(Context{BlockNumber: big.NewInt(9)}, stateDb, &config, &Config{})
+	assert.Nil(t, evm.GetPrecompiledContractMap(common.Address{})[addr])
+
+	evm = NewEVM(Context{BlockNumber: big.NewInt(10)}, stateDb, &config, &Config{})
+	precompiles := evm.GetPrecompiledContractMap(common.Address{})
+	assert.Equal(t, len(PrecompiledContractsConstantinople)+1, len(precompiles))
+	p := precompiles[addr]
+	if assert.NotNil(t, p) {
+		gas, computationCost := p.GetRequiredGasAndComputationCost(make([]byte, 33))
+		assert.Equal(t, uint64(10+2*2), gas)
+		assert.Equal(t, uint64(100), computationCost)
+	}
+	// the default maps are not modified
+	assert.Nil(t, PrecompiledContractsConstantinople[addr])
+}
+
+func TestValidateChainPrecompiles(t *testing.T) {
+	tests := []struct {
+		precompiles []*params.PrecompileConfig
+		valid       bool
+	}{
+		{[]*params.PrecompileConfig{{Name: "sha256", Address: common.BytesToAddress([]byte{3, 0})}}, true},
+		{[]*params.PrecompileConfig{{Name: "unknown", Address: common.BytesToAddress([]byte{3, 0})}}, false},
+		{[]*params.PrecompileConfig{{Name: "sha256", Address: common.BytesToAddress([]byte{4, 0})}}, false},
+		{[]*params.PrecompileConfig{{Name: "sha256", Address: common.BytesToAddress([]byte{10})}}, false},
+		{[]*params.PrecompileConfig{{Name: "sha256", Address: common.BytesToAddress([]byte{3, 253})}}, false},
+		{[]*params.PrecompileConfig{
+			{Name: "sha256", Address: common.BytesToAddress([]byte{3, 0})},
+			{Name: "ripemd160", Address: common.BytesToAddress([]byte{3, 0})},
+		}, false},
+	}
+	for i, tc := range tests {
+		config := *params.TestChainConfig
+		config.Precompiles = tc.precompiles
+		err := ValidateChainPrecompiles(&config)
+		assert.Equal(t, tc.valid, err == nil, "test %d: %v", i, err)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+)
+
+// ValidateDisabledEVMFeatures checks whether the disabled EVM features of the chain config are known
+// opcodes or the addresses of the precompiled contracts of the hardforks.
+// STOP cannot be disabled since every execution ends with it at the end of the code.
+func ValidateDisabledEVMFeatures(config *params.ChainConfig) error {
+	for _, f := range config.DisabledEVMFeatures {
+		switch {
+		case f.Opcode != "" && f.Precompile != nil:
+			return fmt.Errorf("disabled EVM feature has both opcode %q and precompile %s", f.Opcode, f.Precompile.String())
+		case f.Opcode != "":
+			op := StringToOp(f.Opcode)
+			if op == STOP || IstanbulInstructionSet[op] == nil {
+				return fmt.Errorf("opcode %q cannot be disabled", f.Opcode)
+			}
+		case f.Precompile != nil:
+			if !isHardforkPrecompile(*f.Precompile) {
+				return fmt.Errorf("%s is not a precompiled contract of the hardforks", f.Precompile.String())
+			}
+		default:
+			return errors.New("disabled EVM feature has neither opcode nor precompile")
+		}
+	}
+	return nil
+}
+
+// isHardforkPrecompile returns whether a precompiled contract of any hardfork is at the address.
+func isHardforkPrecompile(addr common.Address) bool {
+	for _, fork := range precompiledContractsForks {
+		if _, ok := fork.added[addr]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// activeDisabledEVMFeatures returns the opcodes and the precompiled contract addresses disabled at the
+// block number. The opcodes are nil if no opcode is disabled.
+// Unknown opcodes are skipped; they are rejected by ValidateDisabledEVMFeatures on startup.
+func activeDisabledEVMFeatures(config *params.ChainConfig, num *big.Int) (*[256]bool, map[common.Address]bool) {
+	var (
+		opcodes     *[256]bool
+		precompiles map[common.Address]bool
+	)
+	for _, f := range config.DisabledEVMFeatures {
+		if !f.IsActivated(num) {
+			continue
+		}
+		if f.Opcode != "" {
+			if op := StringToOp(f.Opcode); op != STOP {
+				if opcodes == nil {
+					opcodes = new([256]bool)
+				}
+				opcodes[op] = true
+			}
+		}
+		if f.Precompile != nil {
+			if precompiles == nil {
+				precompiles = make(map[common.Address]bool)
+			}
+			precompiles[*f.Precompile] = true
+		}
+	}
+	return opcodes, precompiles
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDisabledEVMFeatures(t *testing.T) {
+	sha256Addr := common.BytesToAddress([]byte{2})
+	chainAddr := common.BytesToAddress([]byte{3, 0})
+	tests := []struct {
+		features []*params.DisabledEVMFeature
+		valid    bool
+	}{
+		{[]*params.DisabledEVMFeature{{Opcode: "SELFDESTRUCT"}, {Precompile: &sha256Addr}}, true},
+		{[]*params.DisabledEVMFeature{{Opcode: "UNKNOWN"}}, false},
+		{[]*params.DisabledEVMFeature{{Opcode: "STOP"}}, false},
+		{[]*params.DisabledEVMFeature{{Precompile: &chainAddr}}, false},
+		{[]*params.DisabledEVMFeature{{Opcode: "SELFDESTRUCT", Precompile: &sha256Addr}}, false},
+		{[]*params.DisabledEVMFeature{{}}, false},
+	}
+	for i, tc := range tests {
+		config := *params.TestChainConfig
+		config.DisabledEVMFeatures = tc.features
+		err := ValidateDisabledEVMFeatures(&config)
+		assert.Equal(t, tc.valid, err == nil, "test %d: %v", i, err)
+	}
+}
+
+func TestDisabledEVMFeatures(t *testing.T) {
+	var (
+		caller     = common.BytesToAddress([]byte("caller"))
+		contract   = common.BytesToAddress([]byte("contract"))
+		sha256Addr = common.BytesToAddress([]byte{2})
+		config     = *params.TestChainConfig
+	)
+	config.DisabledEVMFeatures = []*params.DisabledEVMFeature{
+		{Opcode: "SELFDESTRUCT", ActivationBlock: big.NewInt(10)},
+		{Precompile: &sha256Addr, ActivationBlock: big.NewInt(10)},
+	}
+	assert.NoError(t, ValidateDisabledEVMFeatures(&config))
+
+	newEVM := func(number int64) *EVM {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
+		statedb.CreateSmartContractAccount(caller, params.CodeFormatEVM, params.Rules{})
+		statedb.SetCode(contract, common.FromHex("0x33ff")) // CALLER SELFDESTRUCT
+		ctx := Context{
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+			BlockNumber: big.NewInt(number),
+		}
+		return NewEVM(ctx, statedb, &config, &Config{})
+	}
+
+	// The features work before the activation block
+	evm := newEVM(9)
+	_, _, err := evm.Call(AccountRef(caller), contract, nil, 100000, new(big.Int))
+	assert.NoError(t, err)
+	assert.True(t, evm.StateDB.HasSuicided(contract))
+	_, _, err = evm.Call(AccountRef(caller), sha256Addr, []byte{0x01}, 100000, new(big.Int))
+	assert.NoError(t, err)
+
+	evm = newEVM(10)
+	_, _, err = evm.Call(AccountRef(caller), contract, nil, 100000, new(big.Int))
+	assert.EqualError(t, err, "disabled opcode SELFDESTRUCT")
+	assert.False(t, evm.StateDB.HasSuicided(contract))
+	assert.Nil(t, evm.GetPrecompiledContractMap(caller)[sha256Addr])
+	assert.NotNil(t, evm.GetPrecompiledContractMap(caller)[common.BytesToAddress([]byte{1})])
+	_, _, err = evm.Call(AccountRef(caller), sha256Addr, []byte{0x01}, 100000, new(big.Int))
+	assert.Error(t, err)
+
+	// the default maps are not modified
+	assert.NotNil(t, PrecompiledContractsConstantinople[sha256Addr])
+}
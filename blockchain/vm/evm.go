@@ -150,6 +150,17 @@ type EVM struct {
 
 	// opcodeComputationCostSum is the sum of computation cost of opcodes.
 	opcodeComputationCostSum uint64
+
+	// precompiledForks is the bitmask of the enabled hardforks of the precompiled contracts.
+	precompiledForks uint64
+	// chainPrecompiles are the activated chain-specific precompiled contracts.
+	chainPrecompiles map[common.Address]PrecompiledContract
+	// mergedPrecompiles caches the precompiled contracts of the hardforks merged with chainPrecompiles.
+	mergedPrecompiles map[uint64]map[common.Address]PrecompiledContract
+	// disabledOpcodes are the opcodes disabled by the chain config, or nil if no opcode is disabled.
+	disabledOpcodes *[256]bool
+	// disabledPrecompiles are the addresses of the precompiled contracts disabled by the chain config.
+	disabledPrecompiles map[common.Address]bool
 }
 
 // NewEVM returns a new EVM. The returned EVM is not thread safe and should
@@ -162,6 +173,9 @@ func NewEVM(ctx Context, statedb StateDB, chainConfig *params.ChainConfig, vmCon
 		chainConfig: chainConfig,
 		chainRules:  chainConfig.Rules(ctx.BlockNumber),
 	}
+	evm.precompiledForks = enabledPrecompiledContractsForks(evm.chainRules)
+	evm.chainPrecompiles = activeChainPrecompiles(chainConfig, ctx.BlockNumber)
+	evm.disabledOpcodes, evm.disabledPrecompiles = activeDisabledEVMFeatures(chainConfig, ctx.BlockNumber)
 
 	if vmConfig.RunningEVM != nil {
 		vmConfig.RunningEVM <- evm
@@ -226,9 +240,14 @@ func (evm *EVM) Call(caller types.ContractRef, addr common.Address, input []byte
 		precompiles := evm.GetPrecompiledContractMap(caller.Address())
 		if precompiles[addr] == nil || value.Sign() != 0 {
 			// Return an error if an enabled precompiled address is called or a value is transferred to a precompiled address.
-			if evm.vmConfig.Debug && evm.depth == 0 {
-				evm.vmConfig.Tracer.CaptureStart(caller.Address(), addr, false, input, gas, value)
-				evm.vmConfig.Tracer.CaptureEnd(ret, 0, 0, nil)
+			if evm.vmConfig.Debug {
+				if evm.depth == 0 {
+					evm.vmConfig.Tracer.CaptureStart(caller.Address(), addr, false, input, gas, value)
+					evm.vmConfig.Tracer.CaptureEnd(ret, 0, 0, nil)
+				} else {
+					evm.vmConfig.Tracer.CaptureEnter(CALL, caller.Address(), addr, input, gas, value)
+					evm.vmConfig.Tracer.CaptureExit(ret, 0, kerrors.ErrPrecompiledContractAddress)
+				}
 			}
 			return nil, gas, kerrors.ErrPrecompiledContractAddress
 		}
@@ -243,9 +262,14 @@ func (evm *EVM) Call(caller types.ContractRef, addr common.Address, input []byte
 	if !evm.StateDB.Exist(addr) {
 		if value.Sign() == 0 {
 			// Calling a non-existing account (probably contract), don't do anything, but ping the tracer
-			if evm.vmConfig.Debug && evm.depth == 0 {
-				evm.vmConfig.Tracer.CaptureStart(caller.Address(), addr, false, input, gas, value)
-				evm.vmConfig.Tracer.CaptureEnd(ret, 0, 0, nil)
+			if evm.vmConfig.Debug {
+				if evm.depth == 0 {
+					evm.vmConfig.Tracer.CaptureStart(caller.Address(), addr, false, input, gas, value)
+					evm.vmConfig.Tracer.CaptureEnd(ret, 0, 0, nil)
+				} else {
+					evm.vmConfig.Tracer.CaptureEnter(CALL, caller.Address(), addr, input, gas, value)
+					evm.vmConfig.Tracer.CaptureExit(ret, 0, nil)
+				}
 			}
 			return nil, gas, nil
 		}
@@ -254,6 +278,12 @@ func (evm *EVM) Call(caller types.ContractRef, addr common.Address, input []byte
 	}
 	evm.Transfer(evm.StateDB, caller.Address(), to.Address(), value)
 
+	// Capture the internal call including a value transfer to an EOA
+	if evm.vmConfig.Debug && evm.depth > 0 {
+		evm.vmConfig.Tracer.CaptureEnter(CALL, caller.Address(), addr, input, gas, value)
+		defer func(startGas uint64) { evm.vmConfig.Tracer.CaptureExit(ret, startGas-leftOverGas, err) }(gas)
+	}
+
 	if !isProgramAccount(evm, caller.Address(), addr, evm.StateDB) {
 		return ret, gas, nil
 	}
@@ -322,6 +352,10 @@ func (evm *EVM) CallCode(caller types.ContractRef, addr common.Address, input []
 	contract := NewContract(caller, to, value, gas)
 	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
 
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(CALLCODE, caller.Address(), addr, input, gas, value)
+		defer func() { evm.vmConfig.Tracer.CaptureExit(ret, gas-contract.Gas, err) }()
+	}
 	ret, err = run(evm, contract, input)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
@@ -360,6 +394,10 @@ func (evm *EVM) DelegateCall(caller types.ContractRef, addr common.Address, inpu
 	contract := NewContract(caller, to, nil, gas).AsDelegate()
 	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
 
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(DELEGATECALL, caller.Address(), addr, input, gas, nil)
+		defer func() { evm.vmConfig.Tracer.CaptureExit(ret, gas-contract.Gas, err) }()
+	}
 	ret, err = run(evm, contract, input)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
@@ -404,6 +442,11 @@ func (evm *EVM) StaticCall(caller types.ContractRef, addr common.Address, input
 	contract := NewContract(caller, to, new(big.Int), gas)
 	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
 
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(STATICCALL, caller.Address(), addr, input, gas, nil)
+		defer func() { evm.vmConfig.Tracer.CaptureExit(ret, gas-contract.Gas, err) }()
+	}
+
 	// When an error was returned by the EVM or when setting the creation code
 	// above we revert to the snapshot and consume any gas remaining. Additionally
 	// when we're in Homestead this also counts for code storage gas errors.
@@ -430,7 +473,7 @@ func (c *codeAndHash) Hash() common.Hash {
 }
 
 // Create creates a new contract using code as deployment code.
-func (evm *EVM) create(caller types.ContractRef, codeAndHash *codeAndHash, gas uint64, value *big.Int, address common.Address, humanReadable bool, codeFormat params.CodeFormat) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
+func (evm *EVM) create(caller types.ContractRef, codeAndHash *codeAndHash, gas uint64, value *big.Int, address common.Address, humanReadable bool, codeFormat params.CodeFormat, typ OpCode) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
 
 	// Depth check execution. Fail if we're trying to execute above the
 	// limit.
@@ -470,8 +513,12 @@ func (evm *EVM) create(caller types.ContractRef, codeAndHash *codeAndHash, gas u
 		return nil, address, gas, nil
 	}
 
-	if evm.vmConfig.Debug && evm.depth == 0 {
-		evm.vmConfig.Tracer.CaptureStart(caller.Address(), address, true, codeAndHash.code, gas, value)
+	if evm.vmConfig.Debug {
+		if evm.depth == 0 {
+			evm.vmConfig.Tracer.CaptureStart(caller.Address(), address, true, codeAndHash.code, gas, value)
+		} else {
+			evm.vmConfig.Tracer.CaptureEnter(typ, caller.Address(), address, codeAndHash.code, gas, value)
+		}
 	}
 	start := time.Now()
 
@@ -512,8 +559,12 @@ func (evm *EVM) create(caller types.ContractRef, codeAndHash *codeAndHash, gas u
 	if maxCodeSizeExceeded && err == nil {
 		err = ErrMaxCodeSizeExceeded // TODO-Klaytn-Issue615
 	}
-	if evm.vmConfig.Debug && evm.depth == 0 {
-		evm.vmConfig.Tracer.CaptureEnd(ret, gas-contract.Gas, time.Since(start), err)
+	if evm.vmConfig.Debug {
+		if evm.depth == 0 {
+			evm.vmConfig.Tracer.CaptureEnd(ret, gas-contract.Gas, time.Since(start), err)
+		} else {
+			evm.vmConfig.Tracer.CaptureExit(ret, gas-contract.Gas, err)
+		}
 	}
 	return ret, address, contract.Gas, err
 }
@@ -522,7 +573,7 @@ func (evm *EVM) create(caller types.ContractRef, codeAndHash *codeAndHash, gas u
 func (evm *EVM) Create(caller types.ContractRef, code []byte, gas uint64, value *big.Int, codeFormat params.CodeFormat) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
 	codeAndHash := &codeAndHash{code: code}
 	contractAddr = crypto.CreateAddress(caller.Address(), evm.StateDB.GetNonce(caller.Address()))
-	return evm.create(caller, codeAndHash, gas, value, contractAddr, false, codeFormat)
+	return evm.create(caller, codeAndHash, gas, value, contractAddr, false, codeFormat, CREATE)
 }
 
 // Create2 creates a new contract using code as deployment code.
@@ -532,45 +583,26 @@ func (evm *EVM) Create(caller types.ContractRef, code []byte, gas uint64, value
 func (evm *EVM) Create2(caller types.ContractRef, code []byte, gas uint64, endowment *big.Int, salt *big.Int, codeFormat params.CodeFormat) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
 	codeAndHash := &codeAndHash{code: code}
 	contractAddr = crypto.CreateAddress2(caller.Address(), common.BigToHash(salt), codeAndHash.Hash().Bytes())
-	return evm.create(caller, codeAndHash, gas, endowment, contractAddr, false, codeFormat)
+	return evm.create(caller, codeAndHash, gas, endowment, contractAddr, false, codeFormat, CREATE2)
 }
 
 // CreateWithAddress creates a new contract using code as deployment code with given address and humanReadable.
 func (evm *EVM) CreateWithAddress(caller types.ContractRef, code []byte, gas uint64, value *big.Int, contractAddr common.Address, humanReadable bool, codeFormat params.CodeFormat) ([]byte, common.Address, uint64, error) {
 	codeAndHash := &codeAndHash{code: code}
 	codeAndHash.Hash()
-	return evm.create(caller, codeAndHash, gas, value, contractAddr, humanReadable, codeFormat)
+	return evm.create(caller, codeAndHash, gas, value, contractAddr, humanReadable, codeFormat, CREATE)
 }
 
+// GetPrecompiledContractMap returns the precompiled contracts available to the contract at addr.
+// The contracts deployed before the latest hardfork use the precompiled contracts of their vmVersion
+// (the gas price policy also follows the old map's rule), and the others use the latest ones.
 func (evm *EVM) GetPrecompiledContractMap(addr common.Address) map[common.Address]PrecompiledContract {
-	getPrecompiledContractMapWithVmVersion := func() (bool, map[common.Address]PrecompiledContract) {
-		// Get vmVersion from addr. If there's no vmVersion, it returns false and use latest precompiled contract map
-		vmVersion, ok := evm.StateDB.GetVmVersion(addr)
-		if !ok {
-			return false, nil
-		}
-
-		// Return precompiled contract map according to the VmVersion (use the map at deployment time of addr contract)
-		//      (gas price policy also follows old map's rule)
-		// If new "VmVersion" is added, add new if clause below
-		if vmVersion == params.VmVersion0 {
-			// Without this version, 0x09-0x0b won't work properly with contracts deployed before istanbulHF
-			return true, PrecompiledContractsConstantinople
-		}
-		return false, nil
-	}
-
-	// There are contracts which uses latest precompiled contract map (regardless of deployment time)
-	// If new HF is added, please add new case below
-	switch {
-	case evm.chainRules.IsIstanbul:
-		if ok, mapWithVmVersion := getPrecompiledContractMapWithVmVersion(); ok {
-			return mapWithVmVersion
-		}
-		return PrecompiledContractsIstanbul
-	default:
-		return PrecompiledContractsConstantinople
+	forks := evm.precompiledForks
+	if vmVersion, ok := evm.StateDB.GetVmVersion(addr); ok {
+		// Without this, 0x09-0x0b won't work properly with contracts deployed before istanbulHF
+		forks = precompiledContractsForksOfVmVersion(forks, vmVersion)
 	}
+	return evm.precompiledContracts(forks)
 }
 
 // ChainConfig returns the environment's chain configuration
@@ -410,6 +410,17 @@ func (this *InternalTxTracer) CaptureEnd(output []byte, gasUsed uint64, t time.D
 	return nil
 }
 
+// CaptureEnter is called when an internal call or creation starts.
+// The internal calls are already traced by CaptureState.
+func (this *InternalTxTracer) CaptureEnter(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureExit is called when an internal call or creation finishes.
+func (this *InternalTxTracer) CaptureExit(output []byte, gasUsed uint64, err error) error {
+	return nil
+}
+
 func (this *InternalTxTracer) GetResult() (*InternalTxTrace, error) {
 	result, err := this.result()
 	if err != nil {
@@ -222,6 +222,9 @@ func (in *Interpreter) Run(contract *Contract, input []byte) (ret []byte, err er
 		if operation == nil {
 			return nil, fmt.Errorf("invalid opcode 0x%x", int(op)) // TODO-Klaytn-Issue615
 		}
+		if in.evm.disabledOpcodes != nil && in.evm.disabledOpcodes[op] {
+			return nil, fmt.Errorf("disabled opcode %v", op)
+		}
 		// Validate stack
 		if sLen := stack.len(); sLen < operation.minStack {
 			return nil, fmt.Errorf("stack underflow (%d <=> %d)", sLen, operation.minStack)
@@ -85,3 +85,13 @@ func (l *JSONLogger) CaptureEnd(output []byte, gasUsed uint64, t time.Duration,
 	}
 	return l.encoder.Encode(endLog{common.Bytes2Hex(output), math.HexOrDecimal64(gasUsed), t, ""})
 }
+
+// CaptureEnter is triggered when an internal call or creation starts.
+func (l *JSONLogger) CaptureEnter(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureExit is triggered when an internal call or creation finishes.
+func (l *JSONLogger) CaptureExit(output []byte, gasUsed uint64, err error) error {
+	return nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+)
+
+// OpcodeStat is the aggregated execution statistics of an opcode.
+type OpcodeStat struct {
+	Op              string        `json:"op"`
+	Count           uint64        `json:"count"`
+	Gas             uint64        `json:"gas"`
+	ComputationCost uint64        `json:"computationCost"`
+	Time            time.Duration `json:"time"`
+}
+
+// OpcodeProfile is the result of OpcodeProfiler.
+type OpcodeProfile struct {
+	// Gas and ComputationCost are the sums over the executed opcodes.
+	// Computation costs of precompiled contracts are not included.
+	Gas                  uint64        `json:"gas"`
+	ComputationCost      uint64        `json:"computationCost"`
+	ComputationCostLimit uint64        `json:"computationCostLimit"`
+	Time                 time.Duration `json:"time"`
+	// Opcodes are sorted by the computation cost in descending order.
+	Opcodes []*OpcodeStat `json:"opcodes"`
+}
+
+// OpcodeProfiler is a Tracer aggregating the gas, the computation cost and the wall-time per opcode.
+// The wall-time of an opcode is measured until the next opcode is captured,
+// so the time of a call includes the time to set up the callee.
+// It can be reused for multiple transactions to aggregate the statistics of a block.
+type OpcodeProfiler struct {
+	stats map[OpCode]*OpcodeStat
+
+	lastOp   *OpcodeStat
+	lastTime time.Time
+}
+
+// NewOpcodeProfiler returns a new OpcodeProfiler.
+func NewOpcodeProfiler() *OpcodeProfiler {
+	return &OpcodeProfiler{stats: make(map[OpCode]*OpcodeStat)}
+}
+
+// CaptureStart implements the Tracer interface.
+func (p *OpcodeProfiler) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	p.lastOp = nil
+	return nil
+}
+
+// CaptureState implements the Tracer interface to aggregate a single step of VM execution.
+func (p *OpcodeProfiler) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	now := time.Now()
+	p.flush(now)
+
+	stat, ok := p.stats[op]
+	if !ok {
+		stat = &OpcodeStat{Op: op.String()}
+		p.stats[op] = stat
+	}
+	stat.Count++
+	stat.Gas += cost
+	if operation := env.interpreter.cfg.JumpTable[op]; operation != nil {
+		stat.ComputationCost += operation.computationCost
+	}
+
+	p.lastOp, p.lastTime = stat, now
+	return nil
+}
+
+// CaptureFault implements the Tracer interface. The faulted opcode has already been captured by CaptureState.
+func (p *OpcodeProfiler) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd implements the Tracer interface.
+func (p *OpcodeProfiler) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
+	p.flush(time.Now())
+	return nil
+}
+
+// CaptureEnter implements the Tracer interface.
+func (p *OpcodeProfiler) CaptureEnter(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureExit implements the Tracer interface.
+func (p *OpcodeProfiler) CaptureExit(output []byte, gasUsed uint64, err error) error {
+	return nil
+}
+
+// flush adds the time elapsed since the last opcode was captured to the opcode.
+func (p *OpcodeProfiler) flush(now time.Time) {
+	if p.lastOp != nil {
+		p.lastOp.Time += now.Sub(p.lastTime)
+		p.lastOp = nil
+	}
+}
+
+// GetResult returns the aggregated statistics of the captured opcodes.
+func (p *OpcodeProfiler) GetResult() *OpcodeProfile {
+	profile := &OpcodeProfile{
+		ComputationCostLimit: params.OpcodeComputationCostLimit,
+		Opcodes:              make([]*OpcodeStat, 0, len(p.stats)),
+	}
+	for _, stat := range p.stats {
+		profile.Gas += stat.Gas
+		profile.ComputationCost += stat.ComputationCost
+		profile.Time += stat.Time
+		profile.Opcodes = append(profile.Opcodes, stat)
+	}
+	sort.Slice(profile.Opcodes, func(i, j int) bool {
+		if profile.Opcodes[i].ComputationCost != profile.Opcodes[j].ComputationCost {
+			return profile.Opcodes[i].ComputationCost > profile.Opcodes[j].ComputationCost
+		}
+		return profile.Opcodes[i].Op < profile.Opcodes[j].Op
+	})
+	return profile
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpcodeProfiler(t *testing.T) {
+	var (
+		env      = NewEVM(Context{}, &dummyStatedb{}, params.TestChainConfig, &Config{})
+		profiler = NewOpcodeProfiler()
+		contract = NewContract(&dummyContractRef{}, &dummyContractRef{}, new(big.Int), 0)
+		jt       = env.interpreter.cfg.JumpTable
+	)
+
+	profiler.CaptureStart(common.Address{}, common.Address{}, false, nil, 0, new(big.Int))
+	profiler.CaptureState(env, 0, PUSH1, 0, 3, nil, nil, contract, 1, nil)
+	profiler.CaptureState(env, 2, PUSH1, 0, 3, nil, nil, contract, 1, nil)
+	profiler.CaptureState(env, 4, SSTORE, 0, 20000, nil, nil, contract, 1, nil)
+	profiler.CaptureFault(env, 4, SSTORE, 0, 20000, nil, nil, contract, 1, ErrOpcodeComputationCostLimitReached)
+	profiler.CaptureEnd(nil, 0, 0, nil)
+
+	result := profiler.GetResult()
+	assert.Equal(t, uint64(20006), result.Gas)
+	assert.Equal(t, 2*jt[PUSH1].computationCost+jt[SSTORE].computationCost, result.ComputationCost)
+	assert.Equal(t, params.OpcodeComputationCostLimit, result.ComputationCostLimit)
+
+	// sorted by the computation cost in descending order
+	if assert.Equal(t, 2, len(result.Opcodes)) {
+		sstore, push1 := result.Opcodes[0], result.Opcodes[1]
+		assert.Equal(t, "SSTORE", sstore.Op)
+		assert.Equal(t, uint64(1), sstore.Count)
+		assert.Equal(t, "PUSH1", push1.Op)
+		assert.Equal(t, uint64(2), push1.Count)
+		assert.Equal(t, uint64(6), push1.Gas)
+		assert.Equal(t, result.Time, sstore.Time+push1.Time)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+)
+
+var errMissingWitnessNode = errors.New("trie node not found in the witness")
+
+// Witness is the execution witness of a block. It contains the trie nodes and the contract codes
+// accessed while executing the block, and the ancestor headers read by the BLOCKHASH opcode, so
+// the block can be re-executed without the state database.
+type Witness struct {
+	Block   *types.Block
+	Headers []*types.Header // the parent header and the ancestors read by the execution, in descending order
+	Nodes   [][]byte        // the trie nodes and the contract codes, identified by their hashes
+}
+
+// witnessRecorder is a database which records the trie nodes and the contract codes read from
+// the trie database of the blockchain.
+type witnessRecorder struct {
+	database.DBManager
+	trieDB *statedb.Database
+
+	mu    sync.Mutex
+	nodes map[common.Hash][]byte
+}
+
+func (r *witnessRecorder) ReadCachedTrieNode(hash common.Hash) ([]byte, error) {
+	enc, err := r.trieDB.Node(hash)
+	if err == nil && len(enc) > 0 {
+		r.mu.Lock()
+		r.nodes[hash] = common.CopyBytes(enc)
+		r.mu.Unlock()
+	}
+	return enc, err
+}
+
+// witnessDatabase is a database which serves the trie nodes and the contract codes of a witness only.
+type witnessDatabase struct {
+	database.DBManager
+	nodes map[common.Hash][]byte
+}
+
+func (db *witnessDatabase) ReadCachedTrieNode(hash common.Hash) ([]byte, error) {
+	if enc, ok := db.nodes[hash]; ok {
+		return enc, nil
+	}
+	return nil, errMissingWitnessNode
+}
+
+// witnessChain is a chain context which records or serves the ancestor headers of a witness.
+type witnessChain struct {
+	engine    consensus.Engine
+	getHeader func(hash common.Hash, number uint64) *types.Header
+	headers   map[common.Hash]*types.Header
+}
+
+func (c *witnessChain) Engine() consensus.Engine {
+	return c.engine
+}
+
+func (c *witnessChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header, ok := c.headers[hash]; ok {
+		if header.Number.Uint64() != number {
+			return nil
+		}
+		return header
+	}
+	if c.getHeader == nil {
+		return nil
+	}
+	header := c.getHeader(hash, number)
+	if header != nil {
+		c.headers[hash] = header
+	}
+	return header
+}
+
+// executeWitnessBlock executes the block on the state with the ancestor headers of the chain,
+// and validates the result against the block.
+func (bc *BlockChain) executeWitnessBlock(block *types.Block, parent *types.Header, statedb *state.StateDB, chain *witnessChain) error {
+	var (
+		receipts types.Receipts
+		usedGas  = new(uint64)
+		header   = block.Header()
+		cfg      = vm.Config{UseOpcodeComputationCost: true}
+	)
+	author, _ := bc.engine.Author(header)
+	for i, tx := range block.Transactions() {
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
+		receipt, _, _, err := applyTransaction(bc.chainConfig, chain, &author, statedb, header, tx, usedGas, &cfg)
+		if err != nil {
+			return err
+		}
+		receipts = append(receipts, receipt)
+	}
+	// The block reward is distributed with the governance and the staking information of the local chain
+	if _, err := bc.engine.Finalize(bc, header, statedb, block.Transactions(), receipts); err != nil {
+		return err
+	}
+	if err := statedb.Error(); err != nil {
+		return err
+	}
+	return bc.validator.ValidateState(block, types.NewBlockWithHeader(parent), statedb, receipts, *usedGas)
+}
+
+// GenerateWitness executes the block on the state of its parent, and returns the execution witness
+// recording the trie nodes, the contract codes and the ancestor headers read by the execution.
+func (bc *BlockChain) GenerateWitness(block *types.Block) (*Witness, error) {
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	// A new trie database without the cache reads every node through the recorder
+	recorder := &witnessRecorder{DBManager: bc.db, trieDB: bc.stateCache.TrieDB(), nodes: make(map[common.Hash][]byte)}
+	statedb, err := state.New(parent.Root, state.NewDatabase(recorder), nil)
+	if err != nil {
+		return nil, err
+	}
+	chain := &witnessChain{
+		engine:    bc.engine,
+		getHeader: bc.GetHeader,
+		headers:   map[common.Hash]*types.Header{parent.Hash(): parent},
+	}
+	if err := bc.executeWitnessBlock(block, parent, statedb, chain); err != nil {
+		return nil, fmt.Errorf("failed to execute block %d: %v", block.NumberU64(), err)
+	}
+
+	witness := &Witness{Block: block}
+	for _, header := range chain.headers {
+		witness.Headers = append(witness.Headers, header)
+	}
+	sort.Slice(witness.Headers, func(i, j int) bool {
+		return witness.Headers[i].Number.Cmp(witness.Headers[j].Number) > 0
+	})
+	for _, enc := range recorder.nodes {
+		witness.Nodes = append(witness.Nodes, enc)
+	}
+	sort.Slice(witness.Nodes, func(i, j int) bool {
+		return bytes.Compare(witness.Nodes[i], witness.Nodes[j]) < 0
+	})
+	return witness, nil
+}
+
+// VerifyWitness re-executes the block of the witness only with the trie nodes, the contract codes
+// and the headers of the witness, and checks the result against the block. The consensus rules
+// and the block reward are applied by the local chain, and the seal of the block is not verified.
+func (bc *BlockChain) VerifyWitness(witness *Witness) error {
+	if witness.Block == nil {
+		return errors.New("no block in the witness")
+	}
+	block := witness.Block
+
+	// The headers and the nodes are identified by their hashes, so they cannot be forged
+	chain := &witnessChain{engine: bc.engine, headers: make(map[common.Hash]*types.Header)}
+	for _, header := range witness.Headers {
+		chain.headers[header.Hash()] = header
+	}
+	parent := chain.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	db := &witnessDatabase{DBManager: database.NewMemoryDBManager(), nodes: make(map[common.Hash][]byte)}
+	for _, enc := range witness.Nodes {
+		db.nodes[crypto.Keccak256Hash(enc)] = enc
+	}
+
+	statedb, err := state.New(parent.Root, state.NewDatabase(db), nil)
+	if err != nil {
+		return err
+	}
+	if err := bc.executeWitnessBlock(block, parent, statedb, chain); err != nil {
+		return fmt.Errorf("failed to execute block %d: %v", block.NumberU64(), err)
+	}
+	return nil
+}
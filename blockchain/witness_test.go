@@ -0,0 +1,114 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockChain_Witness(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0xc0de")
+		db       = database.NewMemoryDBManager()
+		// The contract stores blockhash(number - 3) to the slot 0
+		code  = []byte{byte(vm.PUSH1), 0x03, byte(vm.NUMBER), byte(vm.SUB), byte(vm.BLOCKHASH), byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.STOP)}
+		gspec = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{
+			addr:     {Balance: big.NewInt(10000000000000)},
+			contract: {Balance: common.Big0, Code: code},
+		}}
+		genesis = gspec.MustCommit(db)
+		signer  = types.NewEIP155Signer(gspec.Config.ChainID)
+	)
+	bc, err := NewBlockChain(db, nil, gspec.Config, gxhash.NewFaker(), vm.Config{})
+	require.NoError(t, err)
+	defer bc.Stop()
+
+	// The blocks are inserted one by one to serve the ancestor headers to the contract calls
+	parent := genesis
+	for i := 0; i < 4; i++ {
+		blocks, _ := GenerateChain(gspec.Config, parent, gxhash.NewFaker(), db, 1, func(i int, gen *BlockGen) {
+			transfer, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), common.Address{0x01}, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+			gen.AddTxWithChain(bc, transfer)
+			call, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), contract, common.Big0, 100000, nil, nil), signer, key)
+			gen.AddTxWithChain(bc, call)
+		})
+		_, err := bc.InsertChain(blocks)
+		require.NoError(t, err)
+		parent = blocks[0]
+	}
+	block := bc.GetBlockByNumber(4)
+
+	witness, err := bc.GenerateWitness(block)
+	require.NoError(t, err)
+	// The parent header and the header read by BLOCKHASH are included
+	require.Len(t, witness.Headers, 2)
+	assert.Equal(t, bc.GetHeaderByNumber(3).Hash(), witness.Headers[0].Hash())
+	assert.Equal(t, bc.GetHeaderByNumber(2).Hash(), witness.Headers[1].Hash())
+	assert.NotEmpty(t, witness.Nodes)
+
+	// The witness is self-contained after the round trip of encoding
+	enc, err := rlp.EncodeToBytes(witness)
+	require.NoError(t, err)
+	decoded := new(Witness)
+	require.NoError(t, rlp.DecodeBytes(enc, decoded))
+	assert.Equal(t, block.Hash(), decoded.Block.Hash())
+	assert.NoError(t, bc.VerifyWitness(decoded))
+
+	// Every node is needed to execute the block
+	for i := range witness.Nodes {
+		truncated := *witness
+		truncated.Nodes = append(append([][]byte{}, witness.Nodes[:i]...), witness.Nodes[i+1:]...)
+		assert.Error(t, bc.VerifyWitness(&truncated), "node %d is removed", i)
+	}
+
+	// The tampered node is not identified by its hash
+	tampered := *witness
+	tampered.Nodes = append([][]byte{}, witness.Nodes...)
+	tampered.Nodes[0] = append(common.CopyBytes(witness.Nodes[0]), 0x00)
+	assert.Error(t, bc.VerifyWitness(&tampered))
+
+	// The block hash read by BLOCKHASH differs without the ancestor header
+	noAncestor := *witness
+	noAncestor.Headers = witness.Headers[:1]
+	assert.Error(t, bc.VerifyWitness(&noAncestor))
+
+	// The parent header is needed
+	noParent := *witness
+	noParent.Headers = witness.Headers[1:]
+	assert.Error(t, bc.VerifyWitness(&noParent))
+
+	// The block not matching the execution result is rejected
+	header := block.Header()
+	header.Root = common.Hash{0x01}
+	forged := *witness
+	forged.Block = block.WithSeal(header)
+	assert.Error(t, bc.VerifyWitness(&forged))
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package genesis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	specFlag = cli.StringFlag{
+		Name:  "spec",
+		Usage: "JSON file of the genesis spec",
+	}
+	outputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "Directory to write genesis.json",
+		Value: ".",
+	}
+	networkIdFlag = cli.Uint64Flag{
+		Name:  "networkid",
+		Usage: "Network ID which the chain ID of the genesis should match (0 = not checked)",
+	}
+
+	GenesisCommand = cli.Command{
+		Name:  "genesis",
+		Usage: "Genesis block construction and validation",
+		Subcommands: []cli.Command{
+			{
+				Action:    build,
+				Name:      "build",
+				Usage:     "To build a genesis.json from a genesis spec",
+				ArgsUsage: "--spec <spec file>",
+				Flags: []cli.Flag{
+					specFlag,
+					outputFlag,
+				},
+				Description: `
+		This command assembles validators, governance parameters, prefunded accounts and hardfork options
+		given in a JSON spec file into a genesis.json after validating its consistency.
+		`,
+			},
+			{
+				Action:    validate,
+				Name:      "validate",
+				Usage:     "To validate a genesis.json",
+				ArgsUsage: "<genesis file>",
+				Flags: []cli.Flag{
+					networkIdFlag,
+				},
+				Description: `
+		This command validates the consistency of a genesis.json such as the extra data and
+		the validators, the governance config, and the chain ID against the network ID.
+		`,
+			},
+		},
+	}
+)
+
+func build(ctx *cli.Context) error {
+	path := ctx.String(specFlag.Name)
+	if len(path) == 0 {
+		return cli.NewExitError("Must supply spec file", 1)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Failed to read spec file: %v", err), 1)
+	}
+	defer file.Close()
+
+	var spec Spec
+	if err := json.NewDecoder(file).Decode(&spec); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Failed to parse spec file: %v", err), 2)
+	}
+
+	g, err := spec.Build()
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Invalid genesis spec: %v", err), 3)
+	}
+
+	dir := ctx.String(outputFlag.Name)
+	if err := Save(dir, g); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Failed to save genesis: %v", err), 4)
+	}
+	fmt.Println("Genesis is written to", filepath.Join(dir, FileName))
+	return nil
+}
+
+func validate(ctx *cli.Context) error {
+	path := ctx.Args().First()
+	if len(path) == 0 {
+		return cli.NewExitError("Must supply genesis file", 1)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Failed to read genesis file: %v", err), 1)
+	}
+	defer file.Close()
+
+	g := new(blockchain.Genesis)
+	if err := json.NewDecoder(file).Decode(g); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Failed to parse genesis file: %v", err), 2)
+	}
+
+	if err := Validate(g, ctx.Uint64(networkIdFlag.Name)); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Invalid genesis: %v", err), 3)
+	}
+	fmt.Println("Genesis is valid")
+	return nil
+}
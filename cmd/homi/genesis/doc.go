@@ -27,5 +27,7 @@ Source Files
 Each file contains following contents
  - genesis.go : Provides functions to make a new genesis object
  - options.go : Provides utility functions to generate each part in a genesis file such as a list of validators
+ - spec.go : Provides a high-level genesis spec and the validation of a genesis
+ - cmd.go : Provides the genesis command to build a genesis file from a spec and to validate a genesis file
 */
 package genesis
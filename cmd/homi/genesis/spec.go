@@ -0,0 +1,230 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package genesis
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/clique"
+	"github.com/klaytn/klaytn/governance"
+	"github.com/klaytn/klaytn/params"
+)
+
+var (
+	errNoConfig            = errors.New("genesis has no chain config")
+	errNoChainID           = errors.New("chain ID is not set")
+	errNoValidators        = errors.New("no validators are given")
+	errDuplicatedValidator = errors.New("validators are duplicated")
+	errNoConsensusEngine   = errors.New("exactly one of istanbul and clique should be configured")
+	errNoGovernance        = errors.New("governance config with a reward config is required for istanbul")
+	errNegativeBalance     = errors.New("negative balance is allocated")
+)
+
+// Spec is a high-level specification of a genesis block. A genesis built from a spec
+// always uses the istanbul consensus engine and contains a valid governance data.
+type Spec struct {
+	ChainID       uint64                      `json:"chainId"`
+	Validators    []common.Address            `json:"validators"`
+	Alloc         map[common.Address]*big.Int `json:"alloc"` // Balances of the prefunded accounts
+	UnitPrice     uint64                      `json:"unitPrice"`
+	DeriveShaImpl int                         `json:"deriveShaImpl"`
+
+	Governance *params.GovernanceConfig `json:"governance,omitempty"` // nil = default with the first validator as the governing node
+	Istanbul   *params.IstanbulConfig   `json:"istanbul,omitempty"`   // nil = default
+
+	// AllHardforks enables all hardforks from the genesis block.
+	AllHardforks bool `json:"allHardforks"`
+}
+
+// Build assembles a genesis block from the spec and validates it.
+func (s *Spec) Build() (*blockchain.Genesis, error) {
+	if s.ChainID == 0 {
+		return nil, errNoChainID
+	}
+	if len(s.Validators) == 0 {
+		return nil, errNoValidators
+	}
+
+	governanceConfig := s.Governance
+	if governanceConfig == nil {
+		governanceConfig = params.GetDefaultGovernanceConfig(params.UseIstanbul)
+		governanceConfig.GoverningNode = s.Validators[0]
+	}
+	istanbulConfig := s.Istanbul
+	if istanbulConfig == nil {
+		istanbulConfig = params.GetDefaultIstanbulConfig()
+	}
+
+	alloc := make(map[common.Address]blockchain.GenesisAccount, len(s.Alloc))
+	for addr, balance := range s.Alloc {
+		alloc[addr] = blockchain.GenesisAccount{Balance: balance}
+	}
+
+	options := []Option{
+		Validators(s.Validators...),
+		ChainID(new(big.Int).SetUint64(s.ChainID)),
+		UnitPrice(s.UnitPrice),
+		DeriveShaImpl(s.DeriveShaImpl),
+		Governance(governanceConfig),
+		Istanbul(istanbulConfig),
+		func(genesis *blockchain.Genesis) {
+			genesis.Alloc = alloc
+		},
+	}
+	if s.AllHardforks {
+		options = append(options, func(genesis *blockchain.Genesis) {
+			genesis.Config.IstanbulCompatibleBlock = big.NewInt(0)
+		})
+	}
+
+	g := New(options...)
+	g.Governance = blockchain.SetGenesisGovernance(g)
+
+	if err := Validate(g, 0); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Validate checks the consistency of the given genesis. If networkId is not zero,
+// the chain ID of the genesis should be the same as the network ID.
+func Validate(g *blockchain.Genesis, networkId uint64) error {
+	config := g.Config
+	if config == nil {
+		return errNoConfig
+	}
+	if config.ChainID == nil || config.ChainID.Sign() <= 0 {
+		return errNoChainID
+	}
+	if networkId != 0 && config.ChainID.Uint64() != networkId {
+		return fmt.Errorf("chain ID %v does not match the network ID %v", config.ChainID, networkId)
+	}
+
+	for addr, account := range g.Alloc {
+		if account.Balance != nil && account.Balance.Sign() < 0 {
+			return fmt.Errorf("%v: %s", errNegativeBalance, addr.String())
+		}
+	}
+
+	switch config.GetConsensusEngine() {
+	case params.UseIstanbul:
+		return validateIstanbul(g)
+	case params.UseClique:
+		return validateClique(g)
+	default:
+		return errNoConsensusEngine
+	}
+}
+
+// validateIstanbul checks the validators in the extra data and the governance config.
+func validateIstanbul(g *blockchain.Genesis) error {
+	istanbulExtra, err := types.ExtractIstanbulExtra(&types.Header{Extra: g.ExtraData})
+	if err != nil {
+		return fmt.Errorf("invalid istanbul extra data: %v", err)
+	}
+	if err := checkValidators(istanbulExtra.Validators); err != nil {
+		return err
+	}
+
+	gov := g.Config.Governance
+	if gov == nil || gov.Reward == nil {
+		return errNoGovernance
+	}
+	mode, ok := governance.GovernanceModeMap[gov.GovernanceMode]
+	if !ok {
+		return fmt.Errorf("invalid governance mode: %q", gov.GovernanceMode)
+	}
+	if mode == params.GovernanceMode_Single && !containsAddress(istanbulExtra.Validators, gov.GoverningNode) {
+		return fmt.Errorf("governing node %s is not a validator", gov.GoverningNode.String())
+	}
+	if err := checkRewardRatio(gov.Reward.Ratio); err != nil {
+		return err
+	}
+	if gov.Reward.MintingAmount == nil || gov.Reward.MintingAmount.Sign() < 0 {
+		return errors.New("invalid minting amount")
+	}
+	if _, ok := governance.ProposerPolicyMapReverse[int(g.Config.Istanbul.ProposerPolicy)]; !ok {
+		return fmt.Errorf("invalid proposer policy: %d", g.Config.Istanbul.ProposerPolicy)
+	}
+	if g.Config.Istanbul.SubGroupSize == 0 {
+		return errors.New("committee size should be positive")
+	}
+	return nil
+}
+
+// validateClique checks the signers in the extra data.
+func validateClique(g *blockchain.Genesis) error {
+	signersLen := len(g.ExtraData) - clique.ExtraVanity - clique.ExtraSeal
+	if signersLen <= 0 || signersLen%common.AddressLength != 0 {
+		return fmt.Errorf("invalid clique extra data length: %d", len(g.ExtraData))
+	}
+	signers := make([]common.Address, signersLen/common.AddressLength)
+	for i := range signers {
+		copy(signers[i][:], g.ExtraData[clique.ExtraVanity+i*common.AddressLength:])
+	}
+	return checkValidators(signers)
+}
+
+func checkValidators(validators []common.Address) error {
+	if len(validators) == 0 {
+		return errNoValidators
+	}
+	seen := make(map[common.Address]bool, len(validators))
+	for _, v := range validators {
+		if seen[v] {
+			return fmt.Errorf("%v: %s", errDuplicatedValidator, v.String())
+		}
+		seen[v] = true
+	}
+	return nil
+}
+
+// checkRewardRatio checks whether the ratio is in the form of "cn/poc/kir" whose sum is 100.
+func checkRewardRatio(ratio string) error {
+	items := strings.Split(ratio, "/")
+	if len(items) != params.RewardSliceCount {
+		return fmt.Errorf("invalid reward ratio: %q", ratio)
+	}
+	var sum uint64
+	for _, item := range items {
+		v, err := strconv.ParseUint(item, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid reward ratio: %q", ratio)
+		}
+		sum += v
+	}
+	if sum != 100 {
+		return fmt.Errorf("sum of reward ratio should be 100: %q", ratio)
+	}
+	return nil
+}
+
+func containsAddress(addrs []common.Address, target common.Address) bool {
+	for _, addr := range addrs {
+		if addr == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package genesis
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpec_Build(t *testing.T) {
+	validators := []common.Address{common.HexToAddress("0x1"), common.HexToAddress("0x2")}
+	spec := &Spec{
+		ChainID:      1000,
+		Validators:   validators,
+		Alloc:        map[common.Address]*big.Int{validators[0]: big.NewInt(1)},
+		AllHardforks: true,
+	}
+
+	g, err := spec.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, validators[0], g.Config.Governance.GoverningNode)
+	assert.Equal(t, big.NewInt(0), g.Config.IstanbulCompatibleBlock)
+	assert.NotNil(t, g.Governance)
+
+	assert.NoError(t, Validate(g, 1000))
+	assert.Error(t, Validate(g, 1001))
+
+	// Extra data which does not match the validators
+	g.ExtraData = g.ExtraData[:len(g.ExtraData)-1]
+	assert.Error(t, Validate(g, 1000))
+}
+
+func TestSpec_BuildInvalid(t *testing.T) {
+	v := common.HexToAddress("0x1")
+
+	_, err := (&Spec{Validators: []common.Address{v}}).Build()
+	assert.Equal(t, errNoChainID, err)
+
+	_, err = (&Spec{ChainID: 1}).Build()
+	assert.Equal(t, errNoValidators, err)
+
+	_, err = (&Spec{ChainID: 1, Validators: []common.Address{v, v}}).Build()
+	assert.Error(t, err)
+
+	_, err = (&Spec{ChainID: 1, Validators: []common.Address{v}, Alloc: map[common.Address]*big.Int{v: big.NewInt(-1)}}).Build()
+	assert.Error(t, err)
+}
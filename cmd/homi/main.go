@@ -23,6 +23,7 @@ import (
 	"path/filepath"
 
 	"github.com/klaytn/klaytn/cmd/homi/extra"
+	"github.com/klaytn/klaytn/cmd/homi/genesis"
 	"github.com/klaytn/klaytn/cmd/homi/setup"
 	"github.com/klaytn/klaytn/cmd/utils/nodecmd"
 	"gopkg.in/urfave/cli.v1"
@@ -40,6 +41,7 @@ func main() {
 	app.Commands = []cli.Command{
 		setup.SetupCommand,
 		extra.ExtraCommand,
+		genesis.GenesisCommand,
 	}
 
 	app.CommandNotFound = nodecmd.CommandNotExist
@@ -70,6 +70,12 @@ func init() {
 
 		// See utils/nodecmd/db_migration.go:
 		nodecmd.MigrationCommand,
+
+		// See utils/nodecmd/prunecmd.go:
+		nodecmd.PruneArchiveCommand,
+
+		// See utils/nodecmd/snapshotcmd.go:
+		nodecmd.SnapshotCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
@@ -46,6 +46,9 @@ var FlagGroups = []FlagGroup{
 			LightKDFFlag,
 			SrvTypeFlag,
 			ExtraDataFlag,
+			TxOrderingFlag,
+			TxBudgetGasFlag,
+			TxBudgetClassesFlag,
 			ConfigFileFlag,
 			OverwriteGenesisFlag,
 			StartBlockNumberFlag,
@@ -65,6 +68,7 @@ var FlagGroups = []FlagGroup{
 			TxPoolAllowLocalAnchorTxFlag,
 			TxPoolJournalFlag,
 			TxPoolJournalIntervalFlag,
+			TxPoolDenylistFlag,
 			TxPoolPriceLimitFlag,
 			TxPoolPriceBumpFlag,
 			TxPoolExecSlotsAccountFlag,
@@ -86,6 +90,12 @@ var FlagGroups = []FlagGroup{
 			NumStateTrieShardsFlag,
 			LevelDBCompressionTypeFlag,
 			LevelDBNoBufferPoolFlag,
+			ChainDataCompressionFlag,
+			RecompressChainDataFlag,
+			AncientDirFlag,
+			AncientThresholdFlag,
+			SnapshotURLFlag,
+			SnapshotSignerFlag,
 			DynamoDBTableNameFlag,
 			DynamoDBRegionFlag,
 			DynamoDBIsProvisionedFlag,
@@ -93,6 +103,13 @@ var FlagGroups = []FlagGroup{
 			DynamoDBWriteCapacityFlag,
 			NoParallelDBWriteFlag,
 			SenderTxHashIndexingFlag,
+			TxLookupLimitFlag,
+			BodyRetentionFlag,
+			StorageOwnerIndexingFlag,
+			FeePayerIndexingFlag,
+			BalanceHistoryIndexingFlag,
+			TraceIndexingFlag,
+			LogIndexingFlag,
 			DBNoPerformanceMetricsFlag,
 		},
 	},
@@ -123,6 +140,7 @@ var FlagGroups = []FlagGroup{
 		Flags: []cli.Flag{
 			EnableChainDataFetcherFlag,
 			ChainDataFetcherMode,
+			ChainDataFetcherDatasetsFlag,
 			ChainDataFetcherNoDefault,
 			ChainDataFetcherNumHandlers,
 			ChainDataFetcherJobChannelSize,
@@ -146,6 +164,55 @@ var FlagGroups = []FlagGroup{
 			ChainDataFetcherKafkaRequiredAcksFlag,
 			ChainDataFetcherKafkaMessageVersionFlag,
 			ChainDataFetcherKafkaProducerIdFlag,
+			ChainDataFetcherNATSURLFlag,
+			ChainDataFetcherNATSUserFlag,
+			ChainDataFetcherNATSPasswordFlag,
+			ChainDataFetcherNATSTokenFlag,
+			ChainDataFetcherNATSNoJetStreamFlag,
+			ChainDataFetcherNATSAckTimeoutFlag,
+			ChainDataFetcherNATSSubjectEnvironmentFlag,
+			ChainDataFetcherNATSSubjectResourceFlag,
+			ChainDataFetcherNATSSegmentSizeBytesFlag,
+			ChainDataFetcherPubSubProjectFlag,
+			ChainDataFetcherPubSubEndpointFlag,
+			ChainDataFetcherPubSubAccessTokenFlag,
+			ChainDataFetcherPubSubTopicEnvironmentFlag,
+			ChainDataFetcherPubSubTopicResourceFlag,
+			ChainDataFetcherPubSubNoCreateTopicsFlag,
+			ChainDataFetcherPubSubSegmentSizeBytesFlag,
+		},
+	},
+	{
+		Name: "FIREHOSE",
+		Flags: []cli.Flag{
+			EnableFirehoseFlag,
+			FirehoseNoStateDiffFlag,
+			FirehoseChainEventSizeFlag,
+		},
+	},
+	{
+		Name: "FEEPAYER",
+		Flags: []cli.Flag{
+			EnableFeePayerFlag,
+			FeePayerKeyFileFlag,
+			FeePayerContractsFlag,
+			FeePayerMaxGasFlag,
+			FeePayerDailyBudgetFlag,
+		},
+	},
+	{
+		Name: "ADMINUI",
+		Flags: []cli.Flag{
+			EnableAdminUIFlag,
+			AdminUIHostFlag,
+			AdminUIPortFlag,
+			AdminUIVirtualHostsFlag,
+		},
+	},
+	{
+		Name: "PLUGIN",
+		Flags: []cli.Flag{
+			PluginsFlag,
 		},
 	},
 	{
@@ -169,6 +236,10 @@ var FlagGroups = []FlagGroup{
 			TrieMemoryCacheSizeFlag,
 			TrieBlockIntervalFlag,
 			TriesInMemoryFlag,
+			StateSnapshotCacheSizeFlag,
+			StateLivePruningIntervalFlag,
+			StatePruningBloomSizeFlag,
+			StateReexecLimitFlag,
 		},
 	},
 	{
@@ -217,6 +288,13 @@ var FlagGroups = []FlagGroup{
 			CypressFlag,
 		},
 	},
+	{
+		Name: "DEVELOPER",
+		Flags: []cli.Flag{
+			DeveloperFlag,
+			DeveloperAccountsFlag,
+		},
+	},
 	{
 		Name: "METRICS",
 		Flags: []cli.Flag{
@@ -231,6 +309,8 @@ var FlagGroups = []FlagGroup{
 			VMEnableDebugFlag,
 			VMLogTargetFlag,
 			VMTraceInternalTxFlag,
+			VMParallelTxFlag,
+			VMParallelTxWorkersFlag,
 		},
 	},
 	{
@@ -243,7 +323,20 @@ var FlagGroups = []FlagGroup{
 			RPCVirtualHostsFlag,
 			RPCApiFlag,
 			RPCGlobalGasCap,
+			RPCEstimateGasToleranceFlag,
+			RPCTxErrorCodesFlag,
+			RPCReceiptRevertReasonFlag,
 			RPCConcurrencyLimit,
+			RPCAPIKeysFlag,
+			RPCMethodLimitsFlag,
+			RPCExecutionTimeoutsFlag,
+			AuthRPCEnabledFlag,
+			AuthRPCListenAddrFlag,
+			AuthRPCPortFlag,
+			AuthRPCVirtualHostsFlag,
+			AuthRPCApiFlag,
+			AuthRPCExclusiveFlag,
+			AuthRPCJWTSecretFlag,
 			IPCDisabledFlag,
 			IPCPathFlag,
 			WSEnabledFlag,
@@ -254,12 +347,15 @@ var FlagGroups = []FlagGroup{
 			GRPCEnabledFlag,
 			GRPCListenAddrFlag,
 			GRPCPortFlag,
+			GRPCApiFlag,
 			JSpathFlag,
 			ExecFlag,
 			PreloadJSFlag,
 			MaxRequestContentLengthFlag,
 			APIFilterGetLogsDeadlineFlag,
 			APIFilterGetLogsMaxItemsFlag,
+			SupplyBurnAddressesFlag,
+			SupplyTreasuryAddressesFlag,
 		},
 	},
 	{
@@ -33,6 +33,7 @@ import (
 
 	"github.com/klaytn/klaytn/accounts"
 	"github.com/klaytn/klaytn/accounts/keystore"
+	"github.com/klaytn/klaytn/api"
 	"github.com/klaytn/klaytn/api/debug"
 	"github.com/klaytn/klaytn/blockchain"
 	"github.com/klaytn/klaytn/common"
@@ -40,8 +41,11 @@ import (
 	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/datasync/chaindatafetcher"
 	"github.com/klaytn/klaytn/datasync/chaindatafetcher/kafka"
+	"github.com/klaytn/klaytn/datasync/chaindatafetcher/nats"
+	"github.com/klaytn/klaytn/datasync/chaindatafetcher/pubsub"
 	"github.com/klaytn/klaytn/datasync/dbsyncer"
 	"github.com/klaytn/klaytn/datasync/downloader"
+	"github.com/klaytn/klaytn/datasync/firehose"
 	"github.com/klaytn/klaytn/log"
 	metricutils "github.com/klaytn/klaytn/metrics/utils"
 	"github.com/klaytn/klaytn/networks/p2p"
@@ -50,12 +54,16 @@ import (
 	"github.com/klaytn/klaytn/networks/p2p/netutil"
 	"github.com/klaytn/klaytn/networks/rpc"
 	"github.com/klaytn/klaytn/node"
+	"github.com/klaytn/klaytn/node/adminui"
 	"github.com/klaytn/klaytn/node/cn"
 	"github.com/klaytn/klaytn/node/cn/filters"
+	"github.com/klaytn/klaytn/node/feepayer"
+	"github.com/klaytn/klaytn/node/plugin"
 	"github.com/klaytn/klaytn/node/sc"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/storage/database"
 	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/klaytn/klaytn/work"
 	"gopkg.in/urfave/cli.v1"
 )
 
@@ -123,7 +131,7 @@ var (
 	defaultSyncMode = cn.GetDefaultConfig().SyncMode
 	SyncModeFlag    = TextMarshalerFlag{
 		Name:  "syncmode",
-		Usage: `Blockchain sync mode (only "full" is supported)`,
+		Usage: `Blockchain sync mode ("full" or "snap")`,
 		Value: &defaultSyncMode,
 	}
 	GCModeFlag = cli.StringFlag{
@@ -162,6 +170,11 @@ var (
 		Usage: "Time interval to regenerate the local transaction journal",
 		Value: blockchain.DefaultTxPoolConfig.JournalInterval,
 	}
+	TxPoolDenylistFlag = cli.StringFlag{
+		Name:  "txpool.denylist",
+		Usage: "Disk path of the denylist of the accounts whose transactions are refused by the txpool and the block building",
+		Value: blockchain.DefaultTxPoolConfig.Denylist,
+	}
 	TxPoolPriceLimitFlag = cli.Uint64Flag{
 		Name:  "txpool.pricelimit",
 		Usage: "Minimum gas price limit to enforce for acceptance into the pool",
@@ -226,6 +239,32 @@ var (
 		Usage: "Determines the compression method for LevelDB. 0=AllNoCompression, 1=ReceiptOnlySnappyCompression, 2=StateTrieOnlyNoCompression, 3=AllSnappyCompression",
 		Value: 0,
 	}
+	ChainDataCompressionFlag = cli.StringFlag{
+		Name:  "db.chaindata.compression",
+		Usage: "Compression method for block bodies and receipts written to the database (none, snappy, zstd)",
+		Value: database.NoChainDataCompression.String(),
+	}
+	RecompressChainDataFlag = cli.BoolFlag{
+		Name:  "db.chaindata.recompress",
+		Usage: "Rewrites existing block bodies and receipts with the compression method in background",
+	}
+	AncientDirFlag = DirectoryFlag{
+		Name:  "db.ancient.dir",
+		Usage: "Directory of the ancient store moving the old blocks out of the database into flat files (default = disabled, relative to the chaindata directory)",
+	}
+	AncientThresholdFlag = cli.Uint64Flag{
+		Name:  "db.ancient.threshold",
+		Usage: "Number of recent blocks kept in the database when the older ones are moved to the ancient store",
+		Value: blockchain.DefaultAncientThreshold,
+	}
+	SnapshotURLFlag = cli.StringFlag{
+		Name:  "snapshot.url",
+		Usage: "Location of the signed manifest of a chaindata snapshot to bootstrap an empty node from (https:// or s3://)",
+	}
+	SnapshotSignerFlag = cli.StringFlag{
+		Name:  "snapshot.signer",
+		Usage: "Address of the trusted signer of the snapshot manifest",
+	}
 	LevelDBNoBufferPoolFlag = cli.BoolFlag{
 		Name:  "db.leveldb.no-buffer-pool",
 		Usage: "Disables using buffer pool for LevelDB's block allocation",
@@ -280,6 +319,26 @@ var (
 		Usage: "The number of recent state tries residing in the memory",
 		Value: blockchain.DefaultTriesInMemory,
 	}
+	StateSnapshotCacheSizeFlag = cli.IntFlag{
+		Name:  "state.snapshot-cache-size",
+		Usage: "Size of in-memory cache of the flat state snapshot (in MiB) serving account and storage reads without trie traversal (0 = disabled)",
+		Value: 0,
+	}
+	StateLivePruningIntervalFlag = cli.Uint64Flag{
+		Name:  "state.live-pruning-interval",
+		Usage: "Number of blocks between the cycles pruning the states of the past blocks while the node is running (0 = disabled)",
+		Value: 0,
+	}
+	StatePruningBloomSizeFlag = cli.Uint64Flag{
+		Name:  "state.pruning-bloom-size",
+		Usage: "Size of the bloom filter (in MiB) marking the state to keep while pruning the states of the past blocks",
+		Value: blockchain.DefaultStatePruningBloomSize,
+	}
+	StateReexecLimitFlag = cli.Uint64Flag{
+		Name:  "state.reexec-limit",
+		Usage: "Maximum number of blocks re-executed to regenerate a missing historical state for API requests such as klay_call (0 = disabled)",
+		Value: 0,
+	}
 	CacheTypeFlag = cli.IntFlag{
 		Name:  "cache.type",
 		Usage: "Cache Type: 0=LRUCache, 1=LRUShardCache, 2=FIFOCache",
@@ -338,14 +397,43 @@ var (
 		Name:  "sendertxhashindexing",
 		Usage: "Enables storing mapping information of senderTxHash to txHash",
 	}
+	TxLookupLimitFlag = cli.Uint64Flag{
+		Name:  "txlookuplimit",
+		Usage: "Number of recent blocks to maintain transaction lookup indices for (0 = entire chain)",
+		Value: 0,
+	}
+	BodyRetentionFlag = cli.Uint64Flag{
+		Name:  "bodyretention",
+		Usage: "Number of recent blocks whose bodies and receipts are kept by a PN, pruning the older ones while keeping all the headers (0 = entire chain)",
+		Value: 0,
+	}
+	StorageOwnerIndexingFlag = cli.BoolFlag{
+		Name:  "storageownerindexing",
+		Usage: "Enables storing mapping information of storage trie roots to the contract accounts owning them",
+	}
+	FeePayerIndexingFlag = cli.BoolFlag{
+		Name:  "feepayerindexing",
+		Usage: "Enables indexing the transactions paid by fee payers for fast fee payer statistics",
+	}
+	BalanceHistoryIndexingFlag = cli.BoolFlag{
+		Name:  "balancehistoryindexing",
+		Usage: "Enables indexing the balance changes of the accounts for fast balance history queries",
+	}
+	TraceIndexingFlag = cli.BoolFlag{
+		Name:  "traceindexing",
+		Usage: "Enables indexing the accounts appearing in the call traces of the blocks for fast trace_filter queries",
+	}
+	LogIndexingFlag = cli.BoolFlag{
+		Name:  "logindexing",
+		Usage: "Enables indexing the logs by their emitting addresses and first topics for fast klay_getLogs queries filtered by addresses",
+	}
 	ChildChainIndexingFlag = cli.BoolFlag{
 		Name:  "childchainindexing",
 		Usage: "Enables storing transaction hash of child chain transaction for fast access to child chain data",
 	}
 	TargetGasLimitFlag = cli.Uint64Flag{
 		Name:  "targetgaslimit",
-		Usage: "Target gas limit sets the artificial target gas floor for the blocks to mine",
-		Value: params.GenesisGasLimit,
+		Usage: "Target gas limit of the blocks to mine, approached by 1/1024 of the limit per block (0 = no limit)",
 	}
 	ServiceChainSignerFlag = cli.StringFlag{
 		Name:  "scsigner",
@@ -361,6 +449,19 @@ var (
 		Name:  "extradata",
 		Usage: "Block extra data set by the work (default = client version)",
 	}
+	TxOrderingFlag = cli.StringFlag{
+		Name:  "miner.txordering",
+		Usage: "Policy ordering the transactions in a block (" + strings.Join(work.TxOrderingPolicies, ", ") + ")",
+		Value: work.DefaultTxOrderingPolicy,
+	}
+	TxBudgetGasFlag = cli.Uint64Flag{
+		Name:  "miner.txbudget.gas",
+		Usage: "Gas budget of a block partitioned by tx class (0 = no partitioning)",
+	}
+	TxBudgetClassesFlag = cli.StringFlag{
+		Name:  "miner.txbudget.classes",
+		Usage: "Shares of the block gas budget in percent for tx classes (" + strings.Join(work.TxClasses, ", ") + "), e.g. \"user:30:100,anchoring:0:10\" reserves 30% for user txs and caps anchoring txs to 10%",
+	}
 
 	TxResendIntervalFlag = cli.Uint64Flag{
 		Name:  "txresend.interval",
@@ -402,6 +503,15 @@ var (
 		Name:  "vm.internaltx",
 		Usage: "Collect internal transaction data while processing a block",
 	}
+	VMParallelTxFlag = cli.BoolFlag{
+		Name:  "vm.parallel",
+		Usage: "Execute the transactions of a block optimistically in parallel while processing a block",
+	}
+	VMParallelTxWorkersFlag = cli.IntFlag{
+		Name:  "vm.parallel.workers",
+		Usage: "Number of workers for the parallel transaction execution (0 = number of CPUs)",
+		Value: 0,
+	}
 
 	// Logging and debug settings
 	MetricsEnabledFlag = cli.BoolFlag{
@@ -451,11 +561,77 @@ var (
 		Name:  "rpc.gascap",
 		Usage: "Sets a cap on gas that can be used in klay_call/estimateGas",
 	}
+	RPCEstimateGasToleranceFlag = cli.Float64Flag{
+		Name:  "rpc.estimategas.tolerance",
+		Usage: "Ratio of the gas estimated by klay_estimateGas which may exceed the required gas to finish the estimation early (0 for the exact gas)",
+		Value: 0,
+	}
+	RPCTxErrorCodesFlag = cli.StringFlag{
+		Name:  "rpc.txerror.codes",
+		Usage: "Comma separated list of txError:code pairs overriding the JSON-RPC error codes of the failed executions in klay_call/estimateGas (e.g. 0x09:3,0x07:-32010)",
+	}
+	RPCReceiptRevertReasonFlag = cli.BoolFlag{
+		Name:  "rpc.receipt.revertreason",
+		Usage: "Re-executes the reverted transactions to add their revert reasons to klay_getTransactionReceipt (the states of the blocks should be available)",
+	}
+	SupplyBurnAddressesFlag = cli.StringFlag{
+		Name:  "supply.burn-addresses",
+		Usage: "Comma separated list of the accounts whose balances are excluded from the total supply in klay_getTotalSupply",
+		Value: "0x0000000000000000000000000000000000000000,0x000000000000000000000000000000000000dEaD",
+	}
+	SupplyTreasuryAddressesFlag = cli.StringFlag{
+		Name:  "supply.treasury-addresses",
+		Usage: "Comma separated list of the accounts whose balances are excluded from the circulating supply in klay_getTotalSupply, in addition to the PoC and KIR",
+	}
 	RPCConcurrencyLimit = cli.IntFlag{
 		Name:  "rpc.concurrencylimit",
 		Usage: "Sets a limit of concurrent connection number of HTTP-RPC server",
 		Value: rpc.ConcurrencyLimit,
 	}
+	RPCAPIKeysFlag = cli.StringFlag{
+		Name:  "rpc.apikeys",
+		Usage: "JSON file of the API keys required for HTTP-RPC and WS-RPC requests, with their allowed methods and rate limits",
+	}
+	AuthRPCEnabledFlag = cli.BoolFlag{
+		Name:  "authrpc",
+		Usage: "Enable the HTTP-RPC server authenticated by JWTs",
+	}
+	AuthRPCListenAddrFlag = cli.StringFlag{
+		Name:  "authrpc.addr",
+		Usage: "Authenticated HTTP-RPC server listening interface",
+		Value: node.DefaultAuthHost,
+	}
+	AuthRPCPortFlag = cli.IntFlag{
+		Name:  "authrpc.port",
+		Usage: "Authenticated HTTP-RPC server listening port",
+		Value: node.DefaultAuthPort,
+	}
+	AuthRPCVirtualHostsFlag = cli.StringFlag{
+		Name:  "authrpc.vhosts",
+		Usage: "Comma separated list of virtual hostnames from which to accept authenticated requests (server enforced). Accepts '*' wildcard.",
+		Value: strings.Join(node.DefaultConfig.AuthVirtualHosts, ","),
+	}
+	AuthRPCApiFlag = cli.StringFlag{
+		Name:  "authrpc.api",
+		Usage: "API's offered over the authenticated HTTP-RPC interface, including the private ones",
+		Value: strings.Join(node.DefaultConfig.AuthModules, ","),
+	}
+	AuthRPCExclusiveFlag = cli.BoolFlag{
+		Name:  "authrpc.exclusive",
+		Usage: "Offer the API's of the authenticated HTTP-RPC interface only over it, removing them from the HTTP-RPC, WS-RPC and gRPC interfaces",
+	}
+	AuthRPCJWTSecretFlag = cli.StringFlag{
+		Name:  "authrpc.jwtsecret",
+		Usage: "Path to the hex-encoded 32 bytes secret signing the JWTs of the authenticated HTTP-RPC requests (generated if it does not exist, default: <datadir>/jwtsecret)",
+	}
+	RPCExecutionTimeoutsFlag = cli.StringFlag{
+		Name:  "rpc.exectimeouts",
+		Usage: "Comma separated list of namespace:timeout pairs cancelling the HTTP-RPC and WS-RPC requests of the namespaces exceeding the timeouts, where * is the other namespaces (e.g. debug:1m,klay:5s,*:10s)",
+	}
+	RPCMethodLimitsFlag = cli.StringFlag{
+		Name:  "rpc.methodlimits",
+		Usage: "JSON file of the rate and concurrency limits of HTTP-RPC and WS-RPC requests per method (e.g. klay_call) or namespace (e.g. debug_*)",
+	}
 	WSEnabledFlag = cli.BoolFlag{
 		Name:  "ws",
 		Usage: "Enable the WS-RPC server",
@@ -514,6 +690,11 @@ var (
 		Usage: "gRPC server listening port",
 		Value: node.DefaultGRPCPort,
 	}
+	GRPCApiFlag = cli.StringFlag{
+		Name:  "grpcapi",
+		Usage: "API's offered over the gRPC interface",
+		Value: "",
+	}
 	IPCDisabledFlag = cli.BoolFlag{
 		Name:  "ipcdisable",
 		Usage: "Disable the IPC-RPC server",
@@ -638,6 +819,16 @@ var (
 		Name:  "baobab",
 		Usage: "Pre-configured Klaytn baobab network",
 	}
+	// Developer mode settings
+	DeveloperFlag = cli.BoolFlag{
+		Name:  "dev",
+		Usage: "Ephemeral single-validator network with prefunded developer accounts and instant sealing",
+	}
+	DeveloperAccountsFlag = cli.IntFlag{
+		Name:  "dev.accounts",
+		Usage: "Number of prefunded developer accounts unlocked in the developer mode",
+		Value: 1,
+	}
 	// Bootnode's settings
 	AuthorizedNodesFlag = cli.StringFlag{
 		Name:  "authorized-nodes",
@@ -742,9 +933,13 @@ var (
 	}
 	ChainDataFetcherMode = cli.StringFlag{
 		Name:  "chaindatafetcher.mode",
-		Usage: "The mode of chaindatafetcher (\"kas\", \"kafka\")",
+		Usage: "The comma-separated modes of chaindatafetcher (\"kas\", \"kafka\", \"nats\", \"pubsub\"). Each mode keeps its own checkpoint",
 		Value: "kas",
 	}
+	ChainDataFetcherDatasetsFlag = cli.StringFlag{
+		Name:  "chaindatafetcher.datasets",
+		Usage: "The comma-separated datasets to load (\"transaction\", \"tokentransfer\", \"contract\", \"trace\" for kas, \"blockgroup\", \"tracegroup\" for the others). All datasets are loaded if not set",
+	}
 	ChainDataFetcherNoDefault = cli.BoolFlag{
 		Name:  "chaindatafetcher.no.default",
 		Usage: "Turn off the starting of the chaindatafetcher",
@@ -850,6 +1045,139 @@ var (
 		Usage: "The identifier of kafka message producer",
 		Value: kafka.GetDefaultProducerId(),
 	}
+	ChainDataFetcherNATSURLFlag = cli.StringFlag{
+		Name:  "chaindatafetcher.nats.url",
+		Usage: "NATS server URL",
+		Value: nats.DefaultURL,
+	}
+	ChainDataFetcherNATSUserFlag = cli.StringFlag{
+		Name:  "chaindatafetcher.nats.user",
+		Usage: "NATS user name",
+	}
+	ChainDataFetcherNATSPasswordFlag = cli.StringFlag{
+		Name:  "chaindatafetcher.nats.password",
+		Usage: "NATS user password",
+	}
+	ChainDataFetcherNATSTokenFlag = cli.StringFlag{
+		Name:  "chaindatafetcher.nats.token",
+		Usage: "NATS authentication token",
+	}
+	ChainDataFetcherNATSNoJetStreamFlag = cli.BoolFlag{
+		Name:  "chaindatafetcher.nats.no.jetstream",
+		Usage: "Publish to NATS without waiting for the JetStream acknowledgement",
+	}
+	ChainDataFetcherNATSAckTimeoutFlag = cli.DurationFlag{
+		Name:  "chaindatafetcher.nats.ack.timeout",
+		Usage: "The timeout for the JetStream acknowledgement",
+		Value: nats.DefaultAckTimeout,
+	}
+	ChainDataFetcherNATSSubjectEnvironmentFlag = cli.StringFlag{
+		Name:  "chaindatafetcher.nats.subject.environment",
+		Usage: "NATS subject environment prefix",
+		Value: nats.DefaultSubjectEnvironmentName,
+	}
+	ChainDataFetcherNATSSubjectResourceFlag = cli.StringFlag{
+		Name:  "chaindatafetcher.nats.subject.resource",
+		Usage: "NATS subject resource name",
+		Value: nats.DefaultSubjectResourceName,
+	}
+	ChainDataFetcherNATSSegmentSizeBytesFlag = cli.IntFlag{
+		Name:  "chaindatafetcher.nats.segment.size",
+		Usage: "The NATS message segment size (in byte)",
+		Value: nats.DefaultSegmentSizeBytes,
+	}
+	ChainDataFetcherPubSubProjectFlag = cli.StringFlag{
+		Name:  "chaindatafetcher.pubsub.project",
+		Usage: "Google Cloud project ID of Pub/Sub",
+	}
+	ChainDataFetcherPubSubEndpointFlag = cli.StringFlag{
+		Name:  "chaindatafetcher.pubsub.endpoint",
+		Usage: "Pub/Sub API endpoint (set to the emulator address for testing)",
+		Value: pubsub.DefaultEndpoint,
+	}
+	ChainDataFetcherPubSubAccessTokenFlag = cli.StringFlag{
+		Name:  "chaindatafetcher.pubsub.access.token",
+		Usage: "OAuth2 access token for Pub/Sub (the GCE metadata server is used if not set)",
+	}
+	ChainDataFetcherPubSubTopicEnvironmentFlag = cli.StringFlag{
+		Name:  "chaindatafetcher.pubsub.topic.environment",
+		Usage: "Pub/Sub topic environment prefix",
+		Value: pubsub.DefaultTopicEnvironmentName,
+	}
+	ChainDataFetcherPubSubTopicResourceFlag = cli.StringFlag{
+		Name:  "chaindatafetcher.pubsub.topic.resource",
+		Usage: "Pub/Sub topic resource name",
+		Value: pubsub.DefaultTopicResourceName,
+	}
+	ChainDataFetcherPubSubNoCreateTopicsFlag = cli.BoolFlag{
+		Name:  "chaindatafetcher.pubsub.no.create.topics",
+		Usage: "Do not create Pub/Sub topics on start",
+	}
+	ChainDataFetcherPubSubSegmentSizeBytesFlag = cli.IntFlag{
+		Name:  "chaindatafetcher.pubsub.segment.size",
+		Usage: "The Pub/Sub message segment size (in byte)",
+		Value: pubsub.DefaultSegmentSizeBytes,
+	}
+	// Firehose
+	EnableFirehoseFlag = cli.BoolFlag{
+		Name:  "firehose",
+		Usage: "Enable the Firehose Service which streams blocks, receipts and state changes (needs --ws, --ipc or --grpc)",
+	}
+	FirehoseNoStateDiffFlag = cli.BoolFlag{
+		Name:  "firehose.nostatediff",
+		Usage: "Do not stream the state changes of the transactions which are computed by re-executing the blocks",
+	}
+	FirehoseChainEventSizeFlag = cli.IntFlag{
+		Name:  "firehose.block.channel.size",
+		Usage: "Block channel size of a firehose stream",
+		Value: firehose.DefaultBlockChannelSize,
+	}
+	// FeePayer
+	EnableFeePayerFlag = cli.BoolFlag{
+		Name:  "feepayer",
+		Usage: "Enable the FeePayer Service which pays the fees of the fee-delegated transactions sent by the users",
+	}
+	FeePayerKeyFileFlag = cli.StringFlag{
+		Name:  "feepayer.keyfile",
+		Usage: "File of the hex encoded private key of the fee payer",
+	}
+	FeePayerContractsFlag = cli.StringFlag{
+		Name:  "feepayer.contracts",
+		Usage: "Comma-separated addresses of the contracts allowed as the recipients (default = any recipient)",
+	}
+	FeePayerMaxGasFlag = cli.Uint64Flag{
+		Name:  "feepayer.maxgas",
+		Usage: "Maximum gas limit of a transaction whose fee is paid (0 = no limit)",
+	}
+	FeePayerDailyBudgetFlag = cli.StringFlag{
+		Name:  "feepayer.dailybudget",
+		Usage: "Maximum fee paid for a sender per day (UTC) in peb (default = no limit)",
+	}
+	// AdminUI
+	EnableAdminUIFlag = cli.BoolFlag{
+		Name:  "adminui",
+		Usage: "Enable the AdminUI Service which serves a web-based admin console of the node",
+	}
+	AdminUIHostFlag = cli.StringFlag{
+		Name:  "adminui.addr",
+		Usage: "Admin UI server listening interface",
+		Value: adminui.DefaultAdminUIConfig.Host,
+	}
+	AdminUIPortFlag = cli.IntFlag{
+		Name:  "adminui.port",
+		Usage: "Admin UI server listening port",
+		Value: adminui.DefaultAdminUIConfig.Port,
+	}
+	AdminUIVirtualHostsFlag = cli.StringFlag{
+		Name:  "adminui.vhosts",
+		Usage: "Comma separated list of virtual hostnames from which to accept requests to the admin UI (server enforced). Accepts '*' wildcard.",
+		Value: strings.Join(adminui.DefaultAdminUIConfig.VirtualHosts, ","),
+	}
+	// Plugin
+	PluginsFlag = cli.StringFlag{
+		Name:  "plugins",
+		Usage: "Comma-separated names of the plugins to enable. All the plugins linked into the binary are enabled if not set",
+	}
 	// DBSyncer
 	EnableDBSyncerFlag = cli.BoolFlag{
 		Name:  "dbsyncer",
@@ -1070,6 +1398,8 @@ func setBootstrapNodes(ctx *cli.Context, cfg *p2p.Config) {
 		logger.Info("Baobab bootnodes are set")
 		// set pre-configured bootnodes when 'baobab' option was enabled
 		urls = params.BaobabBootnodes[cfg.ConnectionType].Addrs
+	case ctx.GlobalIsSet(DeveloperFlag.Name):
+		logger.Info("No bootnodes are set in the developer mode")
 	case cfg.BootstrapNodes != nil:
 		return // already set, don't apply defaults.
 	case !ctx.GlobalIsSet(NetworkIdFlag.Name):
@@ -1120,6 +1450,21 @@ func setNAT(ctx *cli.Context, cfg *p2p.Config) {
 	}
 }
 
+// parseAddressList parses the comma separated addresses of the given flag.
+func parseAddressList(ctx *cli.Context, name string) []common.Address {
+	var addrs []common.Address
+	for _, hex := range splitAndTrim(ctx.GlobalString(name)) {
+		if hex == "" {
+			continue
+		}
+		if !common.IsHexAddress(hex) {
+			logger.Crit("Invalid address", "flag", name, "address", hex)
+		}
+		addrs = append(addrs, common.HexToAddress(hex))
+	}
+	return addrs
+}
+
 // splitAndTrim splits input separated by a comma
 // and trims excessive white space from the substrings.
 func splitAndTrim(input string) []string {
@@ -1156,6 +1501,22 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 		rpc.ConcurrencyLimit = ctx.GlobalInt(RPCConcurrencyLimit.Name)
 		logger.Info("Set the concurrency limit of RPC-HTTP server", "limit", rpc.ConcurrencyLimit)
 	}
+	if ctx.GlobalIsSet(RPCAPIKeysFlag.Name) {
+		store, err := rpc.LoadAPIKeyStore(ctx.GlobalString(RPCAPIKeysFlag.Name))
+		if err != nil {
+			log.Fatalf("Failed to load API keys: %v", err)
+		}
+		rpc.SetAPIKeyStore(store)
+		logger.Info("Enabled API keys of RPC servers", "path", store.Path(), "keys", len(store.Usage()))
+	}
+	if ctx.GlobalIsSet(RPCMethodLimitsFlag.Name) {
+		limiter, err := rpc.LoadMethodLimiter(ctx.GlobalString(RPCMethodLimitsFlag.Name))
+		if err != nil {
+			log.Fatalf("Failed to load method limits: %v", err)
+		}
+		rpc.SetMethodLimiter(limiter)
+		logger.Info("Enabled method limits of RPC servers", "path", limiter.Path(), "limits", len(limiter.Usage()))
+	}
 }
 
 // setWS creates the WebSocket RPC listener interface string from the set
@@ -1183,6 +1544,33 @@ func setWS(ctx *cli.Context, cfg *node.Config) {
 	rpc.MaxWebsocketConnections = int32(ctx.GlobalInt(WSMaxConnections.Name))
 }
 
+// setAuthHTTP creates the authenticated HTTP RPC listener interface string from the set
+// command line flags, returning empty if the authenticated HTTP endpoint is disabled.
+func setAuthHTTP(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalBool(AuthRPCEnabledFlag.Name) && cfg.AuthHost == "" {
+		cfg.AuthHost = "127.0.0.1"
+		if ctx.GlobalIsSet(AuthRPCListenAddrFlag.Name) {
+			cfg.AuthHost = ctx.GlobalString(AuthRPCListenAddrFlag.Name)
+		}
+	}
+
+	if ctx.GlobalIsSet(AuthRPCPortFlag.Name) {
+		cfg.AuthPort = ctx.GlobalInt(AuthRPCPortFlag.Name)
+	}
+	if ctx.GlobalIsSet(AuthRPCVirtualHostsFlag.Name) {
+		cfg.AuthVirtualHosts = splitAndTrim(ctx.GlobalString(AuthRPCVirtualHostsFlag.Name))
+	}
+	if ctx.GlobalIsSet(AuthRPCApiFlag.Name) {
+		cfg.AuthModules = splitAndTrim(ctx.GlobalString(AuthRPCApiFlag.Name))
+	}
+	if ctx.GlobalIsSet(AuthRPCExclusiveFlag.Name) {
+		cfg.AuthExclusive = ctx.GlobalBool(AuthRPCExclusiveFlag.Name)
+	}
+	if ctx.GlobalIsSet(AuthRPCJWTSecretFlag.Name) {
+		cfg.JWTSecret = ctx.GlobalString(AuthRPCJWTSecretFlag.Name)
+	}
+}
+
 // setIPC creates an IPC path configuration from the set command line flags,
 // returning an empty string if IPC was explicitly disabled, or the set path.
 func setIPC(ctx *cli.Context, cfg *node.Config) {
@@ -1208,12 +1596,35 @@ func setgRPC(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(GRPCPortFlag.Name) {
 		cfg.GRPCPort = ctx.GlobalInt(GRPCPortFlag.Name)
 	}
+	if ctx.GlobalIsSet(GRPCApiFlag.Name) {
+		cfg.GRPCModules = splitAndTrim(ctx.GlobalString(GRPCApiFlag.Name))
+	}
 }
 
 // setAPIConfig sets configurations for specific APIs.
 func setAPIConfig(ctx *cli.Context) {
 	filters.GetLogsDeadline = ctx.GlobalDuration(APIFilterGetLogsDeadlineFlag.Name)
 	filters.GetLogsMaxItems = ctx.GlobalInt(APIFilterGetLogsMaxItemsFlag.Name)
+
+	if ctx.GlobalIsSet(RPCTxErrorCodesFlag.Name) {
+		codes, err := api.ParseTxErrorCodes(ctx.GlobalString(RPCTxErrorCodesFlag.Name))
+		if err != nil {
+			log.Fatalf("Option %q: %v", RPCTxErrorCodesFlag.Name, err)
+		}
+		for status, code := range codes {
+			api.TxErrorCodes[status] = code
+		}
+	}
+	api.ReceiptRevertReason = ctx.GlobalBool(RPCReceiptRevertReasonFlag.Name)
+	if ctx.GlobalIsSet(RPCExecutionTimeoutsFlag.Name) {
+		timeouts, err := rpc.ParseExecutionTimeouts(ctx.GlobalString(RPCExecutionTimeoutsFlag.Name))
+		if err != nil {
+			log.Fatalf("Option %q: %v", RPCExecutionTimeoutsFlag.Name, err)
+		}
+		for namespace, timeout := range timeouts {
+			rpc.SetExecutionTimeout(namespace, timeout)
+		}
+	}
 }
 
 // MakeAddress converts an account specified directly as a hex encoded string or
@@ -1261,6 +1672,50 @@ func setRewardbase(ctx *cli.Context, ks *keystore.KeyStore, cfg *cn.Config) {
 	}
 }
 
+// setDeveloperMode configures a single-validator network whose validator is the node itself.
+// The developer accounts are created in the keystore if needed, unlocked and prefunded in the genesis block.
+func setDeveloperMode(ctx *cli.Context, stack *node.Node, ks *keystore.KeyStore, cfg *cn.Config) {
+	if !ctx.GlobalIsSet(DeveloperFlag.Name) {
+		return
+	}
+	numAccounts := ctx.GlobalInt(DeveloperAccountsFlag.Name)
+	if numAccounts < 1 {
+		log.Fatalf("--%s should be a positive number", DeveloperAccountsFlag.Name)
+	}
+
+	// Use the first password of the password file if any, otherwise an empty one.
+	passphrase := ""
+	if passwords := MakePasswordList(ctx); len(passwords) > 0 {
+		passphrase = passwords[0]
+	}
+
+	devAccounts := ks.Accounts()
+	for len(devAccounts) < numAccounts {
+		account, err := ks.NewAccount(passphrase)
+		if err != nil {
+			log.Fatalf("Failed to create developer account: %v", err)
+		}
+		devAccounts = append(devAccounts, account)
+	}
+	prefunded := make([]common.Address, numAccounts)
+	for i, account := range devAccounts[:numAccounts] {
+		if err := ks.Unlock(account, passphrase); err != nil {
+			log.Fatalf("Failed to unlock developer account: %v", err)
+		}
+		prefunded[i] = account.Address
+		logger.Info("Using developer account", "address", account.Address)
+	}
+	if !ctx.GlobalIsSet(RewardbaseFlag.Name) {
+		cfg.Rewardbase = prefunded[0]
+	}
+
+	validator := crypto.PubkeyToAddress(stack.NodeKey().PublicKey)
+	balance := new(big.Int).Mul(big.NewInt(1000000000), big.NewInt(params.KLAY))
+	cfg.Genesis = blockchain.DeveloperGenesisBlock(validator, prefunded, balance)
+	cfg.InstantSeal = true
+	logger.Info("Developer mode is enabled", "validator", validator, "networkid", cfg.NetworkId)
+}
+
 // MakePasswordList reads password lines from the file specified by the global --password flag.
 func MakePasswordList(ctx *cli.Context) []string {
 	path := ctx.GlobalString(PasswordFileFlag.Name)
@@ -1309,7 +1764,7 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 		cfg.MaxPendingPeers = ctx.GlobalInt(MaxPendingPeersFlag.Name)
 	}
 
-	cfg.NoDiscovery = ctx.GlobalIsSet(NoDiscoverFlag.Name)
+	cfg.NoDiscovery = ctx.GlobalIsSet(NoDiscoverFlag.Name) || ctx.GlobalIsSet(DeveloperFlag.Name)
 
 	cfg.RWTimerConfig = p2p.RWTimerConfig{}
 	cfg.RWTimerConfig.Interval = ctx.GlobalUint64(RWTimerIntervalFlag.Name)
@@ -1354,6 +1809,7 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setHTTP(ctx, cfg)
 	setWS(ctx, cfg)
 	setgRPC(ctx, cfg)
+	setAuthHTTP(ctx, cfg)
 	setAPIConfig(ctx)
 	setNodeUserIdent(ctx, cfg)
 
@@ -1364,6 +1820,25 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	}
 	cfg.DataDir = ctx.GlobalString(DataDirFlag.Name)
 
+	// The developer mode uses an ephemeral node unless a datadir is given explicitly.
+	// The node key is fixed here since it determines the validator of the developer network.
+	if ctx.GlobalIsSet(DeveloperFlag.Name) {
+		if !ctx.GlobalIsSet(DataDirFlag.Name) {
+			cfg.DataDir = ""
+		}
+		if cfg.P2P.PrivateKey == nil {
+			if cfg.DataDir == "" {
+				key, err := crypto.GenerateKey()
+				if err != nil {
+					log.Fatalf("Failed to generate node key: %v", err)
+				}
+				cfg.P2P.PrivateKey = key
+			} else {
+				cfg.P2P.PrivateKey = cfg.NodeKey()
+			}
+		}
+	}
+
 	if ctx.GlobalIsSet(KeyStoreDirFlag.Name) {
 		cfg.KeyStoreDir = ctx.GlobalString(KeyStoreDirFlag.Name)
 	}
@@ -1385,6 +1860,9 @@ func setTxPool(ctx *cli.Context, cfg *blockchain.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolJournalIntervalFlag.Name) {
 		cfg.JournalInterval = ctx.GlobalDuration(TxPoolJournalIntervalFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolDenylistFlag.Name) {
+		cfg.Denylist = ctx.GlobalString(TxPoolDenylistFlag.Name)
+	}
 	if ctx.GlobalIsSet(TxPoolPriceLimitFlag.Name) {
 		cfg.PriceLimit = ctx.GlobalUint64(TxPoolPriceLimitFlag.Name)
 	}
@@ -1477,8 +1955,8 @@ func SetKlayConfig(ctx *cli.Context, stack *node.Node, cfg *cn.Config) {
 
 	if ctx.GlobalIsSet(SyncModeFlag.Name) {
 		cfg.SyncMode = *GlobalTextMarshaler(ctx, SyncModeFlag.Name).(*downloader.SyncMode)
-		if cfg.SyncMode != downloader.FullSync {
-			log.Fatalf("only syncmode=full can be used for syncmode!")
+		if cfg.SyncMode != downloader.FullSync && cfg.SyncMode != downloader.SnapSync {
+			log.Fatalf("only syncmode=full or syncmode=snap can be used for syncmode!")
 		}
 	}
 
@@ -1489,6 +1967,7 @@ func SetKlayConfig(ctx *cli.Context, stack *node.Node, cfg *cn.Config) {
 	}
 
 	cfg.NetworkId, cfg.IsPrivate = getNetworkId(ctx)
+	setDeveloperMode(ctx, stack, ks, cfg)
 
 	if dbtype := database.DBType(ctx.GlobalString(DbTypeFlag.Name)).ToValid(); len(dbtype) != 0 {
 		cfg.DBType = dbtype
@@ -1508,6 +1987,27 @@ func SetKlayConfig(ctx *cli.Context, stack *node.Node, cfg *cn.Config) {
 	cfg.LevelDBBufferPool = !ctx.GlobalIsSet(LevelDBNoBufferPoolFlag.Name)
 	cfg.EnableDBPerfMetrics = !ctx.GlobalIsSet(DBNoPerformanceMetricsFlag.Name)
 	cfg.LevelDBCacheSize = ctx.GlobalInt(LevelDBCacheSizeFlag.Name)
+	if ct, err := database.ParseChainDataCompressionType(ctx.GlobalString(ChainDataCompressionFlag.Name)); err == nil {
+		cfg.ChainDataCompression = ct
+	} else {
+		logger.Crit("invalid chain data compression", "err", err)
+	}
+	cfg.RecompressChainData = ctx.GlobalBool(RecompressChainDataFlag.Name)
+	cfg.AncientDir = ctx.GlobalString(AncientDirFlag.Name)
+	cfg.AncientThreshold = ctx.GlobalUint64(AncientThresholdFlag.Name)
+	if cfg.AncientDir != "" {
+		if err := blockchain.ValidateAncientThreshold(cfg.AncientThreshold); err != nil {
+			log.Fatalf("--%s: %v", AncientThresholdFlag.Name, err)
+		}
+	}
+	if ctx.GlobalIsSet(SnapshotURLFlag.Name) {
+		cfg.SnapshotURL = ctx.GlobalString(SnapshotURLFlag.Name)
+		signer := ctx.GlobalString(SnapshotSignerFlag.Name)
+		if !common.IsHexAddress(signer) {
+			logger.Crit("invalid snapshot signer", "signer", signer)
+		}
+		cfg.SnapshotSigner = common.HexToAddress(signer)
+	}
 
 	cfg.DynamoDBConfig.TableName = ctx.GlobalString(DynamoDBTableNameFlag.Name)
 	cfg.DynamoDBConfig.Region = ctx.GlobalString(DynamoDBRegionFlag.Name)
@@ -1529,6 +2029,13 @@ func SetKlayConfig(ctx *cli.Context, stack *node.Node, cfg *cn.Config) {
 	common.DefaultCacheType = common.CacheType(ctx.GlobalInt(CacheTypeFlag.Name))
 	cfg.TrieBlockInterval = ctx.GlobalUint(TrieBlockIntervalFlag.Name)
 	cfg.TriesInMemory = ctx.GlobalUint64(TriesInMemoryFlag.Name)
+	cfg.StateSnapshotCacheSize = ctx.GlobalInt(StateSnapshotCacheSizeFlag.Name)
+	cfg.StateReexecLimit = ctx.GlobalUint64(StateReexecLimitFlag.Name)
+	cfg.StateLivePruningInterval = ctx.GlobalUint64(StateLivePruningIntervalFlag.Name)
+	cfg.StatePruningBloomSize = ctx.GlobalUint64(StatePruningBloomSizeFlag.Name)
+	if cfg.NoPruning && cfg.StateLivePruningInterval != 0 {
+		log.Fatalf("--%s cannot be used with --%s archive", StateLivePruningIntervalFlag.Name, GCModeFlag.Name)
+	}
 
 	if ctx.GlobalIsSet(CacheScaleFlag.Name) {
 		common.CacheScale = ctx.GlobalInt(CacheScaleFlag.Name)
@@ -1555,8 +2062,28 @@ func SetKlayConfig(ctx *cli.Context, stack *node.Node, cfg *cn.Config) {
 	if ctx.GlobalIsSet(ExtraDataFlag.Name) {
 		cfg.ExtraData = []byte(ctx.GlobalString(ExtraDataFlag.Name))
 	}
+	cfg.TxOrderingPolicy = ctx.GlobalString(TxOrderingFlag.Name)
+	cfg.TxBudget.BlockGas = ctx.GlobalUint64(TxBudgetGasFlag.Name)
+	if ctx.GlobalIsSet(TxBudgetClassesFlag.Name) {
+		budgets, err := work.ParseTxClassBudgets(ctx.GlobalString(TxBudgetClassesFlag.Name))
+		if err != nil {
+			log.Fatalf("Option %q: %v", TxBudgetClassesFlag.Name, err)
+		}
+		cfg.TxBudget.Classes = budgets
+	}
+	cfg.TargetGasLimit = ctx.GlobalUint64(TargetGasLimitFlag.Name)
 
 	cfg.SenderTxHashIndexing = ctx.GlobalIsSet(SenderTxHashIndexingFlag.Name)
+	cfg.TxLookupLimit = ctx.GlobalUint64(TxLookupLimitFlag.Name)
+	cfg.BodyRetention = ctx.GlobalUint64(BodyRetentionFlag.Name)
+	cfg.StorageOwnerIndexing = ctx.GlobalIsSet(StorageOwnerIndexingFlag.Name)
+	cfg.FeePayerIndexing = ctx.GlobalIsSet(FeePayerIndexingFlag.Name)
+	cfg.BalanceHistoryIndexing = ctx.GlobalIsSet(BalanceHistoryIndexingFlag.Name)
+	cfg.TraceIndexing = ctx.GlobalIsSet(TraceIndexingFlag.Name)
+	cfg.LogIndexing = ctx.GlobalIsSet(LogIndexingFlag.Name)
+	if err := blockchain.ValidateBodyRetention(cfg.BodyRetention); err != nil {
+		log.Fatalf("--%s: %v", BodyRetentionFlag.Name, err)
+	}
 	cfg.ParallelDBWrite = !ctx.GlobalIsSet(NoParallelDBWriteFlag.Name)
 	cfg.TrieNodeCacheConfig = statedb.TrieNodeCacheConfig{
 		CacheType: statedb.TrieNodeCacheType(ctx.GlobalString(TrieNodeCacheTypeFlag.
@@ -1581,6 +2108,8 @@ func SetKlayConfig(ctx *cli.Context, stack *node.Node, cfg *cn.Config) {
 		}
 	}
 	cfg.EnableInternalTxTracing = ctx.GlobalIsSet(VMTraceInternalTxFlag.Name)
+	cfg.ParallelTxExecution = ctx.GlobalIsSet(VMParallelTxFlag.Name)
+	cfg.ParallelTxWorkers = ctx.GlobalInt(VMParallelTxWorkersFlag.Name)
 
 	cfg.AutoRestartFlag = ctx.GlobalBool(AutoRestartFlag.Name)
 	cfg.RestartTimeOutFlag = ctx.GlobalDuration(RestartTimeOutFlag.Name)
@@ -1589,6 +2118,12 @@ func SetKlayConfig(ctx *cli.Context, stack *node.Node, cfg *cn.Config) {
 	if ctx.GlobalIsSet(RPCGlobalGasCap.Name) {
 		cfg.RPCGasCap = new(big.Int).SetUint64(ctx.GlobalUint64(RPCGlobalGasCap.Name))
 	}
+	cfg.RPCEstimateGasTolerance = ctx.GlobalFloat64(RPCEstimateGasToleranceFlag.Name)
+	if cfg.RPCEstimateGasTolerance < 0 || cfg.RPCEstimateGasTolerance >= 1 {
+		log.Fatalf("%s should be in [0, 1): %v", RPCEstimateGasToleranceFlag.Name, cfg.RPCEstimateGasTolerance)
+	}
+	cfg.SupplyBurnAddresses = parseAddressList(ctx, SupplyBurnAddressesFlag.Name)
+	cfg.SupplyTreasuryAddresses = parseAddressList(ctx, SupplyTreasuryAddressesFlag.Name)
 
 	// Override any default configs for hard coded network.
 	// TODO-Klaytn-Bootnode: Discuss and add `baobab` test network's genesis block
@@ -1652,6 +2187,55 @@ func RegisterChainDataFetcherService(stack *node.Node, cfg *chaindatafetcher.Cha
 	}
 }
 
+// RegisterFirehoseService adds a Firehose to the stack
+func RegisterFirehoseService(stack *node.Node, cfg *firehose.FirehoseConfig) {
+	if cfg.EnabledFirehose {
+		err := stack.RegisterSubService(func(ctx *node.ServiceContext) (node.Service, error) {
+			return firehose.NewFirehose(ctx, cfg)
+		})
+		if err != nil {
+			log.Fatalf("Failed to register the service: %v", err)
+		}
+	}
+}
+
+// RegisterFeePayerService adds a FeePayer to the stack
+func RegisterFeePayerService(stack *node.Node, cfg *feepayer.FeePayerConfig) {
+	if cfg.EnabledFeePayer {
+		err := stack.RegisterSubService(func(ctx *node.ServiceContext) (node.Service, error) {
+			return feepayer.NewFeePayer(ctx, cfg)
+		})
+		if err != nil {
+			log.Fatalf("Failed to register the service: %v", err)
+		}
+	}
+}
+
+// RegisterAdminUIService adds an AdminUI to the stack
+func RegisterAdminUIService(stack *node.Node, cfg *adminui.AdminUIConfig) {
+	if cfg.EnabledAdminUI {
+		err := stack.RegisterSubService(func(ctx *node.ServiceContext) (node.Service, error) {
+			return adminui.NewAdminUI(ctx, cfg, stack.Attach)
+		})
+		if err != nil {
+			log.Fatalf("Failed to register the service: %v", err)
+		}
+	}
+}
+
+// RegisterPluginService adds the service running the plugins to the stack
+func RegisterPluginService(stack *node.Node, enabled []string) {
+	if len(enabled) == 0 && len(plugin.Names()) == 0 {
+		return
+	}
+	err := stack.RegisterSubService(func(ctx *node.ServiceContext) (node.Service, error) {
+		return plugin.NewService(ctx, enabled)
+	})
+	if err != nil {
+		log.Fatalf("Failed to register the service: %v", err)
+	}
+}
+
 // RegisterDBSyncerService adds a DBSyncer to the stack
 func RegisterDBSyncerService(stack *node.Node, cfg *dbsyncer.DBConfig) {
 	if cfg.EnabledDBSyncer {
@@ -1738,6 +2322,9 @@ func getNetworkId(ctx *cli.Context) (uint64, bool) {
 	if ctx.GlobalIsSet(CypressFlag.Name) && ctx.GlobalIsSet(NetworkIdFlag.Name) {
 		log.Fatalf("--cypress and --networkid must not be set together")
 	}
+	if ctx.GlobalIsSet(DeveloperFlag.Name) && (ctx.GlobalIsSet(BaobabFlag.Name) || ctx.GlobalIsSet(CypressFlag.Name) || ctx.GlobalIsSet(NetworkIdFlag.Name)) {
+		log.Fatalf("--dev must not be set together with --baobab, --cypress or --networkid")
+	}
 
 	switch {
 	case ctx.GlobalIsSet(CypressFlag.Name):
@@ -1746,6 +2333,9 @@ func getNetworkId(ctx *cli.Context) (uint64, bool) {
 	case ctx.GlobalIsSet(BaobabFlag.Name):
 		logger.Info("Baobab network ID is set", "networkid", params.BaobabNetworkId)
 		return params.BaobabNetworkId, false
+	case ctx.GlobalIsSet(DeveloperFlag.Name):
+		logger.Info("Developer network ID is set", "networkid", params.DeveloperNetworkId)
+		return params.DeveloperNetworkId, true
 	case ctx.GlobalIsSet(NetworkIdFlag.Name):
 		networkId := ctx.GlobalUint64(NetworkIdFlag.Name)
 		logger.Info("A private network ID is set", "networkid", networkId)
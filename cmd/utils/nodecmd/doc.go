@@ -27,6 +27,8 @@ Each file contains following contents
  - defaultcmd.go		: Provides functions to start a node
  - dumpconfigcmd.go		: Provides functions to dump and print current config to stdout
  - nodeflags.go		: Defines various flags that configure the node
+ - prunecmd.go		: Provides functions to convert an archive node datadir into a full node datadir
+ - snapshotcmd.go		: Provides functions based on the state snapshot such as the state pruning
  - versioncmd.go		: Provides functions to print application's version
 */
 package nodecmd
@@ -25,6 +25,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"os"
 	"reflect"
 	"strings"
@@ -32,13 +33,19 @@ import (
 
 	"github.com/Shopify/sarama"
 	"github.com/klaytn/klaytn/cmd/utils"
+	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/datasync/chaindatafetcher"
 	"github.com/klaytn/klaytn/datasync/chaindatafetcher/kafka"
 	"github.com/klaytn/klaytn/datasync/chaindatafetcher/kas"
+	"github.com/klaytn/klaytn/datasync/chaindatafetcher/nats"
+	"github.com/klaytn/klaytn/datasync/chaindatafetcher/pubsub"
 	"github.com/klaytn/klaytn/datasync/dbsyncer"
+	"github.com/klaytn/klaytn/datasync/firehose"
 	"github.com/klaytn/klaytn/log"
 	"github.com/klaytn/klaytn/node"
+	"github.com/klaytn/klaytn/node/adminui"
 	"github.com/klaytn/klaytn/node/cn"
+	"github.com/klaytn/klaytn/node/feepayer"
 	"github.com/klaytn/klaytn/node/sc"
 	"github.com/klaytn/klaytn/params"
 	"github.com/naoina/toml"
@@ -152,17 +159,32 @@ func makeChainDataFetcherConfig(ctx *cli.Context) chaindatafetcher.ChainDataFetc
 			cfg.BlockChannelSize = ctx.GlobalInt(utils.ChainDataFetcherChainEventSizeFlag.Name)
 		}
 
-		mode := ctx.GlobalString(utils.ChainDataFetcherMode.Name)
-		mode = strings.ToLower(mode)
-		switch mode {
-		case "kas":
-			cfg.Mode = chaindatafetcher.ModeKAS
-			cfg.KasConfig = makeKASConfig(ctx)
-		case "kafka":
-			cfg.Mode = chaindatafetcher.ModeKafka
-			cfg.KafkaConfig = makeKafkaConfig(ctx)
-		default:
-			logger.Crit("unsupported chaindatafetcher mode (\"kas\", \"kafka\")", "mode", cfg.Mode)
+		if datasets := ctx.GlobalString(utils.ChainDataFetcherDatasetsFlag.Name); datasets != "" {
+			cfg.Datasets = strings.Split(datasets, ",")
+		}
+
+		cfg.AdditionalModes = nil
+		for i, name := range strings.Split(ctx.GlobalString(utils.ChainDataFetcherMode.Name), ",") {
+			mode, err := chaindatafetcher.ParseChainDataFetcherMode(name)
+			if err != nil {
+				logger.Crit("unsupported chaindatafetcher mode (\"kas\", \"kafka\", \"nats\", \"pubsub\")", "mode", name)
+			}
+			if i == 0 {
+				cfg.Mode = mode
+			} else {
+				cfg.AdditionalModes = append(cfg.AdditionalModes, mode)
+			}
+
+			switch mode {
+			case chaindatafetcher.ModeKAS:
+				cfg.KasConfig = makeKASConfig(ctx)
+			case chaindatafetcher.ModeKafka:
+				cfg.KafkaConfig = makeKafkaConfig(ctx)
+			case chaindatafetcher.ModeNATS:
+				cfg.NATSConfig = makeNATSConfig(ctx)
+			case chaindatafetcher.ModePubSub:
+				cfg.PubSubConfig = makePubSubConfig(ctx)
+			}
 		}
 	}
 
@@ -401,9 +423,83 @@ func MakeFullNode(ctx *cli.Context) *node.Node {
 	chaindataFetcherConfig := makeChainDataFetcherConfig(ctx)
 	utils.RegisterChainDataFetcherService(stack, &chaindataFetcherConfig)
 
+	firehoseConfig := makeFirehoseConfig(ctx)
+	utils.RegisterFirehoseService(stack, &firehoseConfig)
+
+	feePayerConfig := makeFeePayerConfig(ctx)
+	utils.RegisterFeePayerService(stack, &feePayerConfig)
+
+	adminUIConfig := makeAdminUIConfig(ctx)
+	utils.RegisterAdminUIService(stack, &adminUIConfig)
+
+	var plugins []string
+	if names := ctx.GlobalString(utils.PluginsFlag.Name); names != "" {
+		for _, name := range strings.Split(names, ",") {
+			plugins = append(plugins, strings.TrimSpace(name))
+		}
+	}
+	utils.RegisterPluginService(stack, plugins)
+
 	return stack
 }
 
+func makeFirehoseConfig(ctx *cli.Context) firehose.FirehoseConfig {
+	cfg := *firehose.DefaultFirehoseConfig
+
+	if ctx.GlobalBool(utils.EnableFirehoseFlag.Name) {
+		cfg.EnabledFirehose = true
+		cfg.NoStateDiff = ctx.GlobalBool(utils.FirehoseNoStateDiffFlag.Name)
+		cfg.BlockChannelSize = ctx.GlobalInt(utils.FirehoseChainEventSizeFlag.Name)
+	}
+	return cfg
+}
+
+func makeFeePayerConfig(ctx *cli.Context) feepayer.FeePayerConfig {
+	cfg := *feepayer.DefaultFeePayerConfig
+
+	if ctx.GlobalBool(utils.EnableFeePayerFlag.Name) {
+		cfg.EnabledFeePayer = true
+		cfg.KeyFile = ctx.GlobalString(utils.FeePayerKeyFileFlag.Name)
+		if cfg.KeyFile == "" {
+			logger.Crit("The fee payer key file is not set", "key", utils.FeePayerKeyFileFlag.Name)
+		}
+		if contracts := ctx.GlobalString(utils.FeePayerContractsFlag.Name); contracts != "" {
+			for _, addr := range strings.Split(contracts, ",") {
+				addr = strings.TrimSpace(addr)
+				if !common.IsHexAddress(addr) {
+					logger.Crit("Invalid contract address", "key", utils.FeePayerContractsFlag.Name, "address", addr)
+				}
+				cfg.AllowedContracts = append(cfg.AllowedContracts, common.HexToAddress(addr))
+			}
+		}
+		cfg.MaxGas = ctx.GlobalUint64(utils.FeePayerMaxGasFlag.Name)
+		if budget := ctx.GlobalString(utils.FeePayerDailyBudgetFlag.Name); budget != "" {
+			var ok bool
+			if cfg.SenderDailyBudget, ok = new(big.Int).SetString(budget, 10); !ok {
+				logger.Crit("Invalid daily budget", "key", utils.FeePayerDailyBudgetFlag.Name, "budget", budget)
+			}
+		}
+	}
+	return cfg
+}
+
+func makeAdminUIConfig(ctx *cli.Context) adminui.AdminUIConfig {
+	cfg := *adminui.DefaultAdminUIConfig
+
+	if ctx.GlobalBool(utils.EnableAdminUIFlag.Name) {
+		cfg.EnabledAdminUI = true
+		cfg.Host = ctx.GlobalString(utils.AdminUIHostFlag.Name)
+		cfg.Port = ctx.GlobalInt(utils.AdminUIPortFlag.Name)
+		cfg.VirtualHosts = nil
+		for _, vhost := range strings.Split(ctx.GlobalString(utils.AdminUIVirtualHostsFlag.Name), ",") {
+			if vhost = strings.TrimSpace(vhost); vhost != "" {
+				cfg.VirtualHosts = append(cfg.VirtualHosts, vhost)
+			}
+		}
+	}
+	return cfg
+}
+
 func dumpConfig(ctx *cli.Context) error {
 	_, cfg := makeConfigNode(ctx)
 	comment := ""
@@ -421,3 +517,39 @@ func dumpConfig(ctx *cli.Context) error {
 	os.Stdout.Write(out)
 	return nil
 }
+
+func makeNATSConfig(ctx *cli.Context) *nats.NATSConfig {
+	natsConfig := nats.GetDefaultNATSConfig()
+	natsConfig.URL = ctx.GlobalString(utils.ChainDataFetcherNATSURLFlag.Name)
+	natsConfig.User = ctx.GlobalString(utils.ChainDataFetcherNATSUserFlag.Name)
+	natsConfig.Password = ctx.GlobalString(utils.ChainDataFetcherNATSPasswordFlag.Name)
+	natsConfig.Token = ctx.GlobalString(utils.ChainDataFetcherNATSTokenFlag.Name)
+	natsConfig.JetStream = !ctx.GlobalBool(utils.ChainDataFetcherNATSNoJetStreamFlag.Name)
+	natsConfig.AckTimeout = ctx.GlobalDuration(utils.ChainDataFetcherNATSAckTimeoutFlag.Name)
+	natsConfig.SubjectEnvironmentName = ctx.GlobalString(utils.ChainDataFetcherNATSSubjectEnvironmentFlag.Name)
+	natsConfig.SubjectResourceName = ctx.GlobalString(utils.ChainDataFetcherNATSSubjectResourceFlag.Name)
+	natsConfig.SegmentSizeBytes = ctx.GlobalInt(utils.ChainDataFetcherNATSSegmentSizeBytesFlag.Name)
+	if natsConfig.SegmentSizeBytes <= 0 {
+		logger.Crit("The NATS segment size must be positive", "given", natsConfig.SegmentSizeBytes)
+	}
+	return natsConfig
+}
+
+func makePubSubConfig(ctx *cli.Context) *pubsub.PubSubConfig {
+	pubsubConfig := pubsub.GetDefaultPubSubConfig()
+	if ctx.GlobalIsSet(utils.ChainDataFetcherPubSubProjectFlag.Name) {
+		pubsubConfig.ProjectID = ctx.GlobalString(utils.ChainDataFetcherPubSubProjectFlag.Name)
+	} else {
+		logger.Crit("The Pub/Sub project must be set")
+	}
+	pubsubConfig.Endpoint = ctx.GlobalString(utils.ChainDataFetcherPubSubEndpointFlag.Name)
+	pubsubConfig.AccessToken = ctx.GlobalString(utils.ChainDataFetcherPubSubAccessTokenFlag.Name)
+	pubsubConfig.TopicEnvironmentName = ctx.GlobalString(utils.ChainDataFetcherPubSubTopicEnvironmentFlag.Name)
+	pubsubConfig.TopicResourceName = ctx.GlobalString(utils.ChainDataFetcherPubSubTopicResourceFlag.Name)
+	pubsubConfig.CreateTopics = !ctx.GlobalBool(utils.ChainDataFetcherPubSubNoCreateTopicsFlag.Name)
+	pubsubConfig.SegmentSizeBytes = ctx.GlobalInt(utils.ChainDataFetcherPubSubSegmentSizeBytesFlag.Name)
+	if pubsubConfig.SegmentSizeBytes <= 0 {
+		logger.Crit("The Pub/Sub segment size must be positive", "given", pubsubConfig.SegmentSizeBytes)
+	}
+	return pubsubConfig
+}
@@ -142,6 +142,13 @@ var flagsWithValues = []struct {
 		wrongValues: commonThreeErrors,
 		errors:      []int{ErrorInvalidValue, ErrorInvalidValue, ErrorInvalidValue},
 	},
+	{
+		flag:        "--txpool.denylist",
+		flagType:    FlagTypeArgument,
+		values:      []string{"denylist.json"},
+		wrongValues: []string{},
+		errors:      []int{},
+	},
 	{
 		flag:        "--txpool.pricelimit",
 		flagType:    FlagTypeArgument,
@@ -42,6 +42,7 @@ var CommonNodeFlags = []cli.Flag{
 	utils.TxPoolAllowLocalAnchorTxFlag,
 	utils.TxPoolJournalFlag,
 	utils.TxPoolJournalIntervalFlag,
+	utils.TxPoolDenylistFlag,
 	utils.TxPoolPriceLimitFlag,
 	utils.TxPoolPriceBumpFlag,
 	utils.TxPoolExecSlotsAccountFlag,
@@ -57,6 +58,12 @@ var CommonNodeFlags = []cli.Flag{
 	utils.NumStateTrieShardsFlag,
 	utils.LevelDBCompressionTypeFlag,
 	utils.LevelDBNoBufferPoolFlag,
+	utils.ChainDataCompressionFlag,
+	utils.RecompressChainDataFlag,
+	utils.AncientDirFlag,
+	utils.AncientThresholdFlag,
+	utils.SnapshotURLFlag,
+	utils.SnapshotSignerFlag,
 	utils.DBNoPerformanceMetricsFlag,
 	utils.DynamoDBTableNameFlag,
 	utils.DynamoDBRegionFlag,
@@ -67,9 +74,19 @@ var CommonNodeFlags = []cli.Flag{
 	utils.LevelDBCacheSizeFlag,
 	utils.NoParallelDBWriteFlag,
 	utils.SenderTxHashIndexingFlag,
+	utils.TxLookupLimitFlag,
+	utils.StorageOwnerIndexingFlag,
+	utils.FeePayerIndexingFlag,
+	utils.BalanceHistoryIndexingFlag,
+	utils.TraceIndexingFlag,
+	utils.LogIndexingFlag,
 	utils.TrieMemoryCacheSizeFlag,
 	utils.TrieBlockIntervalFlag,
 	utils.TriesInMemoryFlag,
+	utils.StateSnapshotCacheSizeFlag,
+	utils.StateReexecLimitFlag,
+	utils.StateLivePruningIntervalFlag,
+	utils.StatePruningBloomSizeFlag,
 	utils.CacheTypeFlag,
 	utils.CacheScaleFlag,
 	utils.CacheUsageLevelFlag,
@@ -99,6 +116,8 @@ var CommonNodeFlags = []cli.Flag{
 	utils.VMEnableDebugFlag,
 	utils.VMLogTargetFlag,
 	utils.VMTraceInternalTxFlag,
+	utils.VMParallelTxFlag,
+	utils.VMParallelTxWorkersFlag,
 	utils.NetworkIdFlag,
 	utils.RPCCORSDomainFlag,
 	utils.RPCVirtualHostsFlag,
@@ -106,6 +125,9 @@ var CommonNodeFlags = []cli.Flag{
 	utils.PrometheusExporterFlag,
 	utils.PrometheusExporterPortFlag,
 	utils.ExtraDataFlag,
+	utils.TxOrderingFlag,
+	utils.TxBudgetGasFlag,
+	utils.TxBudgetClassesFlag,
 	utils.SrvTypeFlag,
 	utils.AutoRestartFlag,
 	utils.RestartTimeOutFlag,
@@ -113,6 +135,8 @@ var CommonNodeFlags = []cli.Flag{
 	utils.ConfigFileFlag,
 	utils.APIFilterGetLogsMaxItemsFlag,
 	utils.APIFilterGetLogsDeadlineFlag,
+	utils.SupplyBurnAddressesFlag,
+	utils.SupplyTreasuryAddressesFlag,
 }
 
 // Common RPC flags
@@ -122,13 +146,27 @@ var CommonRPCFlags = []cli.Flag{
 	utils.RPCPortFlag,
 	utils.RPCApiFlag,
 	utils.RPCGlobalGasCap,
+	utils.RPCEstimateGasToleranceFlag,
+	utils.RPCTxErrorCodesFlag,
+	utils.RPCReceiptRevertReasonFlag,
 	utils.WSEnabledFlag,
 	utils.WSListenAddrFlag,
 	utils.WSPortFlag,
 	utils.GRPCEnabledFlag,
 	utils.GRPCListenAddrFlag,
 	utils.GRPCPortFlag,
+	utils.GRPCApiFlag,
 	utils.RPCConcurrencyLimit,
+	utils.RPCAPIKeysFlag,
+	utils.RPCMethodLimitsFlag,
+	utils.RPCExecutionTimeoutsFlag,
+	utils.AuthRPCEnabledFlag,
+	utils.AuthRPCListenAddrFlag,
+	utils.AuthRPCPortFlag,
+	utils.AuthRPCVirtualHostsFlag,
+	utils.AuthRPCApiFlag,
+	utils.AuthRPCExclusiveFlag,
+	utils.AuthRPCJWTSecretFlag,
 	utils.WSApiFlag,
 	utils.WSAllowedOriginsFlag,
 	utils.WSMaxSubscriptionPerConn,
@@ -143,12 +181,15 @@ var KCNFlags = []cli.Flag{
 	utils.RewardbaseFlag,
 	utils.CypressFlag,
 	utils.BaobabFlag,
+	utils.DeveloperFlag,
+	utils.DeveloperAccountsFlag,
 }
 
 var KPNFlags = []cli.Flag{
 	utils.TxResendIntervalFlag,
 	utils.TxResendCountFlag,
 	utils.TxResendUseLegacyFlag,
+	utils.BodyRetentionFlag,
 	utils.CypressFlag,
 	utils.BaobabFlag,
 }
@@ -164,6 +205,7 @@ var KENFlags = []cli.Flag{
 	// ChainDataFetcher
 	utils.EnableChainDataFetcherFlag,
 	utils.ChainDataFetcherMode,
+	utils.ChainDataFetcherDatasetsFlag,
 	utils.ChainDataFetcherNoDefault,
 	utils.ChainDataFetcherNumHandlers,
 	utils.ChainDataFetcherJobChannelSize,
@@ -187,6 +229,39 @@ var KENFlags = []cli.Flag{
 	utils.ChainDataFetcherKafkaRequiredAcksFlag,
 	utils.ChainDataFetcherKafkaMessageVersionFlag,
 	utils.ChainDataFetcherKafkaProducerIdFlag,
+	utils.ChainDataFetcherNATSURLFlag,
+	utils.ChainDataFetcherNATSUserFlag,
+	utils.ChainDataFetcherNATSPasswordFlag,
+	utils.ChainDataFetcherNATSTokenFlag,
+	utils.ChainDataFetcherNATSNoJetStreamFlag,
+	utils.ChainDataFetcherNATSAckTimeoutFlag,
+	utils.ChainDataFetcherNATSSubjectEnvironmentFlag,
+	utils.ChainDataFetcherNATSSubjectResourceFlag,
+	utils.ChainDataFetcherNATSSegmentSizeBytesFlag,
+	utils.ChainDataFetcherPubSubProjectFlag,
+	utils.ChainDataFetcherPubSubEndpointFlag,
+	utils.ChainDataFetcherPubSubAccessTokenFlag,
+	utils.ChainDataFetcherPubSubTopicEnvironmentFlag,
+	utils.ChainDataFetcherPubSubTopicResourceFlag,
+	utils.ChainDataFetcherPubSubNoCreateTopicsFlag,
+	utils.ChainDataFetcherPubSubSegmentSizeBytesFlag,
+	// Firehose
+	utils.EnableFirehoseFlag,
+	utils.FirehoseNoStateDiffFlag,
+	utils.FirehoseChainEventSizeFlag,
+	// FeePayer
+	utils.EnableFeePayerFlag,
+	utils.FeePayerKeyFileFlag,
+	utils.FeePayerContractsFlag,
+	utils.FeePayerMaxGasFlag,
+	utils.FeePayerDailyBudgetFlag,
+	// AdminUI
+	utils.EnableAdminUIFlag,
+	utils.AdminUIHostFlag,
+	utils.AdminUIPortFlag,
+	utils.AdminUIVirtualHostsFlag,
+	// Plugin
+	utils.PluginsFlag,
 	// DBSyncer
 	utils.EnableDBSyncerFlag,
 	utils.DBHostFlag,
@@ -239,6 +314,7 @@ var KSPNFlags = []cli.Flag{
 	utils.TxResendIntervalFlag,
 	utils.TxResendCountFlag,
 	utils.TxResendUseLegacyFlag,
+	utils.BodyRetentionFlag,
 	utils.ServiceChainSignerFlag,
 	utils.AnchoringPeriodFlag,
 	utils.SentChainTxsLimit,
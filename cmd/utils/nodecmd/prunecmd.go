@@ -0,0 +1,137 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+package nodecmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/cmd/utils"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var PruneArchiveCommand = cli.Command{
+	Action:    utils.MigrateFlags(pruneArchive),
+	Name:      "prune-archive",
+	Usage:     "Convert an archive node datadir into a full node datadir in place",
+	ArgsUsage: " ",
+	Flags: []cli.Flag{
+		utils.DataDirFlag,
+		utils.ConfigFileFlag,
+		utils.DbTypeFlag,
+		utils.SingleDBFlag,
+		utils.NumStateTrieShardsFlag,
+		utils.LevelDBCacheSizeFlag,
+		utils.LevelDBCompressionTypeFlag,
+	},
+	Category: "DB MIGRATION COMMANDS",
+	Description: `
+The prune-archive command removes the states of all the blocks except the head block
+from the datadir of a node which has been run with --gcmode archive, so the node can
+be restarted as a full node without syncing the chain again.
+
+It copies the state of the head block to a new state database and removes the old one
+by the state migration, whose progress is logged periodically. If it is interrupted or
+crashed, running it again resumes the conversion. The node should not be running, and
+it should be restarted without --gcmode archive after the conversion.
+
+Note: This feature is not provided when the database is a single database.`,
+}
+
+func pruneArchive(ctx *cli.Context) error {
+	chainDB := openChainDatabase(ctx)
+	defer chainDB.Close()
+
+	number := chainDB.MigrationBlockNumber()
+	if !chainDB.InMigration() {
+		head := chainDB.ReadHeaderNumber(chainDB.ReadHeadBlockHash())
+		if head == nil {
+			return fmt.Errorf("head block is not found in chaindata")
+		}
+		number = *head
+	}
+	if err := checkStakingInfoStored(chainDB, number); err != nil {
+		return err
+	}
+
+	quit, stop := quitOnInterrupt("Got interrupt, stopping the conversion; run the command again to resume it")
+	defer stop()
+
+	number, err := blockchain.PruneArchiveState(chainDB, quit)
+	if err != nil {
+		return fmt.Errorf("failed to prune the archive state at block %d: %v", number, err)
+	}
+	logger.Info("The archive datadir is converted into a full node datadir; restart the node without --gcmode archive",
+		"block", number)
+	return nil
+}
+
+// openChainDatabase opens the chain database of the node configured by the flags.
+func openChainDatabase(ctx *cli.Context) database.DBManager {
+	stack, cfg := makeConfigNode(ctx)
+
+	dbc := &database.DBConfig{Dir: "chaindata", DBType: cfg.CN.DBType, SingleDB: cfg.CN.SingleDB,
+		NumStateTrieShards: cfg.CN.NumStateTrieShards, LevelDBCacheSize: cfg.CN.LevelDBCacheSize,
+		OpenFilesLimit: database.GetOpenFilesLimit(), LevelDBCompression: cfg.CN.LevelDBCompression,
+		LevelDBBufferPool: cfg.CN.LevelDBBufferPool, DynamoDBConfig: &cfg.CN.DynamoDBConfig,
+		ChainDataCompression: cfg.CN.ChainDataCompression}
+	return stack.OpenDatabase(dbc)
+}
+
+// quitOnInterrupt returns a channel closed when the process is interrupted, logging the
+// given message, and a function to stop watching the interrupt.
+func quitOnInterrupt(msg string) (chan struct{}, func()) {
+	quit := make(chan struct{})
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		if _, ok := <-sigc; ok {
+			logger.Info(msg)
+			close(quit)
+		}
+	}()
+	return quit, func() {
+		signal.Stop(sigc)
+		close(sigc)
+	}
+}
+
+// checkStakingInfoStored checks if the staking information needed after the given block is stored
+// in the database, because it cannot be computed from the state after the conversion.
+func checkStakingInfoStored(db database.DBManager, number uint64) error {
+	config := db.ReadChainConfig(db.ReadCanonicalHash(0))
+	if config == nil {
+		return fmt.Errorf("chain config is not found")
+	}
+	if config.Istanbul == nil || config.Istanbul.ProposerPolicy != uint64(params.WeightedRandom) ||
+		config.Governance == nil || config.Governance.Reward == nil {
+		return nil
+	}
+
+	params.SetStakingUpdateInterval(config.Governance.Reward.StakingUpdateInterval)
+	for _, n := range []uint64{number, number + params.StakingUpdateInterval()} {
+		stakingNumber := params.CalcStakingBlockNumber(n)
+		if _, err := db.ReadStakingInfo(stakingNumber); err != nil {
+			return fmt.Errorf("staking info of block %d is not stored; run the node until it is stored before the conversion", stakingNumber)
+		}
+	}
+	return nil
+}
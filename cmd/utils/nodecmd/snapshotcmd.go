@@ -0,0 +1,81 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+package nodecmd
+
+import (
+	"fmt"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/cmd/utils"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var SnapshotCommand = cli.Command{
+	Name:     "snapshot",
+	Usage:    "A set of commands based on the state snapshot",
+	Category: "DB MIGRATION COMMANDS",
+	Description: `
+The snapshot commands work on the flat state snapshot, which is maintained by the node
+run with --state.snapshot-cache-size.
+Note: Do not use the snapshot commands while a node is executing.
+`,
+	Subcommands: []cli.Command{
+		{
+			Name:      "prune-state",
+			Usage:     "Prune the states of the past blocks with the state snapshot",
+			ArgsUsage: " ",
+			Action:    utils.MigrateFlags(pruneState),
+			Flags: []cli.Flag{
+				utils.DataDirFlag,
+				utils.ConfigFileFlag,
+				utils.DbTypeFlag,
+				utils.SingleDBFlag,
+				utils.NumStateTrieShardsFlag,
+				utils.LevelDBCacheSizeFlag,
+				utils.LevelDBCompressionTypeFlag,
+				utils.StatePruningBloomSizeFlag,
+			},
+			Description: `
+The prune-state command deletes the state trie nodes and the contract codes which are
+not reachable from the state of the head block, so a full node can reclaim the space
+taken by the states of the past blocks without syncing the chain again.
+
+The state of the head block is marked in a bloom filter whose size is given by
+--state.pruning-bloom-size, and all the other entries of the state trie database are
+deleted. A larger bloom filter leaves less dead entries by false positives. The state
+snapshot of the head block is required, which is persisted when the node run with the
+snapshot enabled is stopped gracefully, and it is checked against the kept state.
+
+If it is interrupted or crashed, running it again resumes the pruning. The node should
+not be running, and it keeps using the snapshot after the pruning.`,
+		},
+	},
+}
+
+func pruneState(ctx *cli.Context) error {
+	chainDB := openChainDatabase(ctx)
+	defer chainDB.Close()
+
+	quit, stop := quitOnInterrupt("Got interrupt, stopping the pruning; run the command again to resume it")
+	defer stop()
+
+	number, err := blockchain.PruneState(chainDB, ctx.Uint64(utils.StatePruningBloomSizeFlag.Name), quit)
+	if err != nil {
+		return fmt.Errorf("failed to prune the state at block %d: %v", number, err)
+	}
+	logger.Info("The states of the past blocks are pruned", "block", number)
+	return nil
+}
@@ -30,9 +30,13 @@ import (
 	"github.com/klaytn/klaytn/blockchain"
 	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
 	"github.com/klaytn/klaytn/consensus"
 	"github.com/klaytn/klaytn/consensus/istanbul"
+	istanbulCore "github.com/klaytn/klaytn/consensus/istanbul/core"
+	"github.com/klaytn/klaytn/consensus/istanbul/validator"
 	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/rlp"
 )
 
 // API is a user facing RPC API to dump Istanbul state
@@ -149,6 +153,7 @@ var (
 	errExtractIstanbulExtra    = errors.New("extract Istanbul Extra from block header of the given block number")
 	errNoBlockExist            = errors.New("block with the given block number is not existed")
 	errNoBlockNumber           = errors.New("block number is not assigned")
+	errGenesisNoCommittee      = errors.New("the genesis block has no committee proof")
 )
 
 // GetCouncil retrieves the list of authorized validators at the specified block.
@@ -267,26 +272,26 @@ type ConsensusInfo struct {
 	round          byte
 }
 
-func (api *APIExtension) getConsensusInfo(block *types.Block) (ConsensusInfo, error) {
-	blockNumber := block.NumberU64()
+func (api *APIExtension) getConsensusInfo(header *types.Header) (ConsensusInfo, error) {
+	blockNumber := header.Number.Uint64()
 	if blockNumber == 0 {
 		return ConsensusInfo{}, nil
 	}
 
-	round := block.Header().Round()
+	round := header.Round()
 	view := &istanbul.View{
-		Sequence: new(big.Int).Set(block.Number()),
+		Sequence: new(big.Int).Set(header.Number),
 		Round:    new(big.Int).SetInt64(int64(round)),
 	}
 
 	// get the proposer of this block.
-	proposer, err := ecrecover(block.Header())
+	proposer, err := ecrecover(header)
 	if err != nil {
 		return ConsensusInfo{}, err
 	}
 
 	// get the snapshot of the previous block.
-	parentHash := block.ParentHash()
+	parentHash := header.ParentHash
 	snap, err := api.istanbul.snapshot(api.chain, blockNumber-1, parentHash, nil)
 	if err != nil {
 		return ConsensusInfo{}, err
@@ -407,7 +412,7 @@ func (api *APIExtension) GetBlockWithConsensusInfoByNumber(number *rpc.BlockNumb
 	}
 	blockHash := block.Hash()
 
-	cInfo, err := api.getConsensusInfo(block)
+	cInfo, err := api.getConsensusInfo(block.Header())
 	if err != nil {
 		logger.Error("Getting the proposer and validators failed.", "blockHash", blockHash, "err", err)
 		return nil, errInternalError
@@ -470,6 +475,248 @@ func (api *APIExtension) GetBlockWithConsensusInfoByNumberRange(start *rpc.Block
 	return blocks, nil
 }
 
+// maxConsensusInfoPageSize is the maximum number of blocks returned by GetConsensusInfoByNumberRange in a call.
+const maxConsensusInfoPageSize = 1000
+
+// BlockConsensusInfo is the consensus information of a block without its transactions.
+type BlockConsensusInfo struct {
+	Number         hexutil.Uint64   `json:"number"`
+	Hash           common.Hash      `json:"hash"`
+	Round          byte             `json:"round"`
+	Proposer       common.Address   `json:"proposer"`
+	OriginProposer common.Address   `json:"originProposer"`
+	Committee      []common.Address `json:"committee"`
+	Signers        []common.Address `json:"signers"` // validators whose committed seals are in the block
+}
+
+// ConsensusInfoPage is a page of the consensus information of consecutive blocks.
+type ConsensusInfoPage struct {
+	Blocks []*BlockConsensusInfo `json:"blocks"`
+	Next   *hexutil.Uint64       `json:"next"` // first block of the next page, nil if the range is done
+}
+
+// GetConsensusInfoByNumberRange returns the proposers, the committees and the signers of the blocks
+// from start to end without their transactions. At most limit blocks, up to 1000, are returned in a call,
+// and the rest of the range can be requested again from the returned next block.
+func (api *APIExtension) GetConsensusInfoByNumberRange(start, end rpc.BlockNumber, limit *uint64) (*ConsensusInfoPage, error) {
+	if start == rpc.PendingBlockNumber || end == rpc.PendingBlockNumber {
+		return nil, errPendingNotAllowed
+	}
+	latest := api.chain.CurrentHeader().Number.Uint64()
+	if start == rpc.LatestBlockNumber {
+		start = rpc.BlockNumber(latest)
+	}
+	if end == rpc.LatestBlockNumber {
+		end = rpc.BlockNumber(latest)
+	}
+	if start < 0 {
+		return nil, errStartNotPositive
+	}
+	if uint64(end) > latest {
+		return nil, errEndLargetThanLatest
+	}
+	if start > end {
+		return nil, errStartLargerThanEnd
+	}
+
+	pageSize := uint64(maxConsensusInfoPageSize)
+	if limit != nil && *limit > 0 && *limit < pageSize {
+		pageSize = *limit
+	}
+	from, to := uint64(start), uint64(end)
+	if to-from+1 > pageSize {
+		to = from + pageSize - 1
+	}
+
+	page := &ConsensusInfoPage{Blocks: make([]*BlockConsensusInfo, 0, to-from+1)}
+	for number := from; number <= to; number++ {
+		header := api.chain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, fmt.Errorf("the block does not exist (block number: %d)", number)
+		}
+		info, err := api.getBlockConsensusInfo(header)
+		if err != nil {
+			logger.Error("Getting the consensus information failed.", "blockNum", number, "err", err)
+			return nil, errInternalError
+		}
+		page.Blocks = append(page.Blocks, info)
+	}
+	if to < uint64(end) {
+		next := hexutil.Uint64(to + 1)
+		page.Next = &next
+	}
+	return page, nil
+}
+
+func (api *APIExtension) getBlockConsensusInfo(header *types.Header) (*BlockConsensusInfo, error) {
+	cInfo, err := api.getConsensusInfo(header)
+	if err != nil {
+		return nil, err
+	}
+	info := &BlockConsensusInfo{
+		Number:         hexutil.Uint64(header.Number.Uint64()),
+		Hash:           header.Hash(),
+		Round:          cInfo.round,
+		Proposer:       cInfo.proposer,
+		OriginProposer: cInfo.originProposer,
+		Committee:      cInfo.committee,
+		Signers:        []common.Address{},
+	}
+	if info.Number == 0 {
+		return info, nil
+	}
+
+	extra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		return nil, err
+	}
+	proposalSeal := istanbulCore.PrepareCommittedSeal(header.Hash())
+	for _, seal := range extra.CommittedSeal {
+		signer, err := cacheSignatureAddresses(proposalSeal, seal)
+		if err != nil {
+			return nil, err
+		}
+		info.Signers = append(info.Signers, signer)
+	}
+	return info, nil
+}
+
+// CommittedSealProof is a committed seal in the extraData of a block with its signer.
+type CommittedSealProof struct {
+	Signer    common.Address `json:"signer"`
+	Signature hexutil.Bytes  `json:"signature"`
+}
+
+// CommitteeDerivation is the input of the committee selection of a block. The committee is the
+// whole council if the committee size is not smaller than the council size. Otherwise, it starts
+// with the proposer and the next proposer, and the rest are picked from the council by a random
+// source seeded with the first 15 hex digits of the parent hash.
+type CommitteeDerivation struct {
+	ParentHash     common.Hash      `json:"parentHash"`
+	Round          byte             `json:"round"`
+	ProposerPolicy uint64           `json:"proposerPolicy"`
+	CommitteeSize  uint64           `json:"committeeSize"`
+	Council        []common.Address `json:"council"` // validators in the order of the selection
+	Demoted        []common.Address `json:"demoted"` // validators excluded from the selection
+	Seed           int64            `json:"seed"`
+	NextProposer   *common.Address  `json:"nextProposer"` // nil if the committee is the whole council
+}
+
+// CommitteeProof is the committee of a block with the signatures in its extraData, which can be
+// checked without trusting the node.
+//   - the proposer seal signs the keccak256 hash of SigHash, which is the keccak256 hash of the
+//     RLP of the header without the seal and the committed seals in the extraData.
+//   - the committed seals sign the keccak256 hash of CommitMessage, the block hash followed by the
+//     commit message code, and a block is final with at least Quorum seals from distinct committee members.
+type CommitteeProof struct {
+	Number         hexutil.Uint64        `json:"number"`
+	Hash           common.Hash           `json:"hash"`
+	Header         hexutil.Bytes         `json:"header"` // RLP-encoded header
+	SigHash        common.Hash           `json:"sigHash"`
+	Proposer       common.Address        `json:"proposer"`
+	ProposerSeal   hexutil.Bytes         `json:"proposerSeal"`
+	CommitMessage  hexutil.Bytes         `json:"commitMessage"`
+	CommittedSeals []*CommittedSealProof `json:"committedSeals"`
+	Committee      []common.Address      `json:"committee"`
+	Quorum         int                   `json:"quorum"`
+	Derivation     *CommitteeDerivation  `json:"derivation"`
+}
+
+// GetCommitteeProof returns the committee of the given block with the proposer seal and the
+// committed seals in its extraData and the inputs of the committee selection, so that a light
+// client can verify the block independently with the council of the parent block.
+func (api *APIExtension) GetCommitteeProof(number rpc.BlockNumber) (*CommitteeProof, error) {
+	var header *types.Header
+	switch number {
+	case rpc.LatestBlockNumber:
+		header = api.chain.CurrentHeader()
+	case rpc.PendingBlockNumber:
+		return nil, errPendingNotAllowed
+	default:
+		header = api.chain.GetHeaderByNumber(uint64(number.Int64()))
+	}
+	if header == nil {
+		return nil, errNoBlockExist
+	}
+	// The committee of the genesis block is not elected and its block has no seals.
+	if header.Number.Sign() == 0 {
+		return nil, errGenesisNoCommittee
+	}
+
+	extra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		return nil, errExtractIstanbulExtra
+	}
+	encoded, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		return nil, err
+	}
+	proposer, err := ecrecover(header)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := api.istanbul.snapshot(api.chain, header.Number.Uint64()-1, header.ParentHash, nil)
+	if err != nil {
+		logger.Error("Failed to get snapshot.", "hash", header.ParentHash, "err", err)
+		return nil, errInternalError
+	}
+	seed, err := validator.ConvertHashToSeed(header.ParentHash)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &istanbul.View{
+		Sequence: new(big.Int).Set(header.Number),
+		Round:    new(big.Int).SetUint64(uint64(header.Round())),
+	}
+	committee := snap.ValSet.SubListWithProposer(header.ParentHash, proposer, view)
+	derivation := &CommitteeDerivation{
+		ParentHash:     header.ParentHash,
+		Round:          header.Round(),
+		ProposerPolicy: uint64(snap.ValSet.Policy()),
+		CommitteeSize:  snap.ValSet.SubGroupSize(),
+		Council:        validatorAddresses(snap.ValSet.List()),
+		Demoted:        validatorAddresses(snap.ValSet.DemotedList()),
+		Seed:           seed,
+	}
+	if len(committee) > 1 && uint64(len(committee)) < snap.ValSet.Size() {
+		next := committee[1].Address()
+		derivation.NextProposer = &next
+	}
+
+	proposalSeal := istanbulCore.PrepareCommittedSeal(header.Hash())
+	seals := make([]*CommittedSealProof, len(extra.CommittedSeal))
+	for i, seal := range extra.CommittedSeal {
+		signer, err := cacheSignatureAddresses(proposalSeal, seal)
+		if err != nil {
+			return nil, err
+		}
+		seals[i] = &CommittedSealProof{Signer: signer, Signature: seal}
+	}
+
+	return &CommitteeProof{
+		Number:         hexutil.Uint64(header.Number.Uint64()),
+		Hash:           header.Hash(),
+		Header:         encoded,
+		SigHash:        sigHash(header),
+		Proposer:       proposer,
+		ProposerSeal:   extra.Seal,
+		CommitMessage:  proposalSeal,
+		CommittedSeals: seals,
+		Committee:      validatorAddresses(committee),
+		Quorum:         2*snap.ValSet.F() + 1,
+		Derivation:     derivation,
+	}, nil
+}
+
+func validatorAddresses(validators []istanbul.Validator) []common.Address {
+	addrs := make([]common.Address, len(validators))
+	for i, v := range validators {
+		addrs[i] = v.Address()
+	}
+	return addrs
+}
+
 func (api *APIExtension) GetBlockWithConsensusInfoByHash(blockHash common.Hash) (map[string]interface{}, error) {
 	b, ok := api.chain.(*blockchain.BlockChain)
 	if !ok {
@@ -483,7 +730,7 @@ func (api *APIExtension) GetBlockWithConsensusInfoByHash(blockHash common.Hash)
 		return nil, fmt.Errorf("the block does not exist (block hash: %s)", blockHash.String())
 	}
 
-	cInfo, err := api.getConsensusInfo(block)
+	cInfo, err := api.getConsensusInfo(block.Header())
 	if err != nil {
 		logger.Error("Getting the proposer and validators failed.", "blockHash", blockHash, "err", err)
 		return nil, errInternalError
@@ -0,0 +1,143 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	istanbulCore "github.com/klaytn/klaytn/consensus/istanbul/core"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetConsensusInfoByNumberRange(t *testing.T) {
+	chain, engine := newBlockChain(1)
+	defer engine.Stop()
+
+	// The blocks have the same timestamp as the genesis not to be future blocks
+	engine.config.BlockPeriod = 0
+	parent := chain.Genesis()
+	for i := 0; i < 5; i++ {
+		block, err := engine.updateBlock(nil, makeBlockWithoutSeal(chain, engine, parent))
+		if err != nil {
+			t.Fatal(err)
+		}
+		header := block.Header()
+		if err := writeCommittedSeals(header, makeCommittedSeals(block.Hash())); err != nil {
+			t.Fatal(err)
+		}
+		block = block.WithSeal(header)
+		if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatal(err)
+		}
+		parent = block
+	}
+	api := &APIExtension{chain: chain, istanbul: engine}
+
+	// The first page ends at the limit
+	limit := uint64(2)
+	page, err := api.GetConsensusInfoByNumberRange(0, 5, &limit)
+	assert.NoError(t, err)
+	assert.Len(t, page.Blocks, 2)
+	assert.Equal(t, chain.Genesis().Hash(), page.Blocks[0].Hash)
+	assert.Empty(t, page.Blocks[0].Signers)
+	assert.Equal(t, hexutil.Uint64(2), *page.Next)
+
+	// The last page has no next block
+	page, err = api.GetConsensusInfoByNumberRange(2, rpc.LatestBlockNumber, nil)
+	assert.NoError(t, err)
+	assert.Len(t, page.Blocks, 4)
+	assert.Nil(t, page.Next)
+	for i, info := range page.Blocks {
+		header := chain.GetHeaderByNumber(uint64(i + 2))
+		assert.Equal(t, hexutil.Uint64(i+2), info.Number)
+		assert.Equal(t, header.Hash(), info.Hash)
+		assert.Equal(t, addrs[0], info.Proposer)
+		assert.Equal(t, []common.Address{addrs[0]}, info.Committee)
+		assert.Equal(t, []common.Address{addrs[0]}, info.Signers)
+	}
+
+	_, err = api.GetConsensusInfoByNumberRange(3, 2, nil)
+	assert.Equal(t, errStartLargerThanEnd, err)
+	_, err = api.GetConsensusInfoByNumberRange(0, 6, nil)
+	assert.Equal(t, errEndLargetThanLatest, err)
+	_, err = api.GetConsensusInfoByNumberRange(0, rpc.PendingBlockNumber, nil)
+	assert.Equal(t, errPendingNotAllowed, err)
+}
+
+func TestGetCommitteeProof(t *testing.T) {
+	chain, engine := newBlockChain(1)
+	defer engine.Stop()
+
+	engine.config.BlockPeriod = 0
+	block, err := engine.updateBlock(nil, makeBlockWithoutSeal(chain, engine, chain.Genesis()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := block.Header()
+	if err := writeCommittedSeals(header, makeCommittedSeals(block.Hash())); err != nil {
+		t.Fatal(err)
+	}
+	block = block.WithSeal(header)
+	if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+		t.Fatal(err)
+	}
+	api := &APIExtension{chain: chain, istanbul: engine}
+
+	proof, err := api.GetCommitteeProof(rpc.LatestBlockNumber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, hexutil.Uint64(1), proof.Number)
+	assert.Equal(t, block.Hash(), proof.Hash)
+	assert.Equal(t, []common.Address{addrs[0]}, proof.Committee)
+	assert.Equal(t, 1, proof.Quorum)
+	assert.Equal(t, chain.Genesis().Hash(), proof.Derivation.ParentHash)
+	assert.Equal(t, []common.Address{addrs[0]}, proof.Derivation.Council)
+	assert.Nil(t, proof.Derivation.NextProposer)
+
+	// The proof can be verified only with the header and the signatures
+	decoded := new(types.Header)
+	assert.NoError(t, rlp.DecodeBytes(proof.Header, decoded))
+	assert.Equal(t, proof.Hash, decoded.Hash())
+	assert.Equal(t, istanbulCore.PrepareCommittedSeal(decoded.Hash()), []byte(proof.CommitMessage))
+
+	proposer, err := istanbul.GetSignatureAddress(proof.SigHash.Bytes(), proof.ProposerSeal)
+	assert.NoError(t, err)
+	assert.Equal(t, addrs[0], proposer)
+	assert.Equal(t, proposer, proof.Proposer)
+
+	assert.Len(t, proof.CommittedSeals, 1)
+	for _, seal := range proof.CommittedSeals {
+		pubkey, err := crypto.SigToPub(crypto.Keccak256(proof.CommitMessage), seal.Signature)
+		assert.NoError(t, err)
+		assert.Equal(t, seal.Signer, crypto.PubkeyToAddress(*pubkey))
+	}
+
+	_, err = api.GetCommitteeProof(0)
+	assert.Equal(t, errGenesisNoCommittee, err)
+	_, err = api.GetCommitteeProof(2)
+	assert.Equal(t, errNoBlockExist, err)
+	_, err = api.GetCommitteeProof(rpc.PendingBlockNumber)
+	assert.Equal(t, errPendingNotAllowed, err)
+}
@@ -44,7 +44,7 @@ var (
 	istanbulProtocol = consensus.Protocol{
 		Name:     "istanbul",
 		Versions: []uint{64},
-		Lengths:  []uint64{21},
+		Lengths:  []uint64{27},
 	}
 )
 
@@ -36,7 +36,7 @@ var (
 	KlayProtocol = Protocol{
 		Name:     "klay",
 		Versions: []uint{Klay63, Klay62},
-		Lengths:  []uint64{17, 8},
+		Lengths:  []uint64{27, 8},
 	}
 )
 
@@ -35,6 +35,8 @@ var Modules = map[string]string{
 	"governance":       Governance_JS,
 	"bootnode":         Bootnode_JS,
 	"chaindatafetcher": ChainDataFetcher_JS,
+	"feepayer":         FeePayer_JS,
+	"trace":            Trace_JS,
 }
 
 const ChainDataFetcher_JS = `
@@ -80,6 +82,84 @@ web3._extend({
 			name: 'getConfig',
 			call: 'chaindatafetcher_getConfig',
 			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getCheckpoints',
+			call: 'chaindatafetcher_getCheckpoints',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'readSinkCheckpoint',
+			call: 'chaindatafetcher_readSinkCheckpoint',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'writeSinkCheckpoint',
+			call: 'chaindatafetcher_writeSinkCheckpoint',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'rewindSinkCheckpoint',
+			call: 'chaindatafetcher_rewindSinkCheckpoint',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'startReexport',
+			call: 'chaindatafetcher_startReexport',
+			params: 4
+		}),
+		new web3._extend.Method({
+			name: 'stopReexport',
+			call: 'chaindatafetcher_stopReexport',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'reexportStatus',
+			call: 'chaindatafetcher_reexportStatus',
+			params: 0
+		})
+	],
+	properties: []
+});
+`
+
+const FeePayer_JS = `
+web3._extend({
+	property: 'feepayer',
+	methods: [
+		new web3._extend.Method({
+			name: 'sendTransaction',
+			call: 'feepayer_sendTransaction',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'signTransaction',
+			call: 'feepayer_signTransaction',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'senderUsage',
+			call: 'feepayer_senderUsage',
+			params: 1
+		})
+	],
+	properties: [
+		new web3._extend.Property({
+			name: 'address',
+			getter: 'feepayer_address'
+		})
+	]
+});
+`
+
+const Trace_JS = `
+web3._extend({
+	property: 'trace',
+	methods: [
+		new web3._extend.Method({
+			name: 'filter',
+			call: 'trace_filter',
+			params: 1
 		})
 	],
 	properties: []
@@ -254,16 +334,59 @@ web3._extend({
 			params: 1,
 			inputFormatter: [null]
 		}),
+		new web3._extend.Method({
+			name: 'exportChainData',
+			call: 'admin_exportChainData',
+			params: 5,
+			inputFormatter: [null, null, web3._extend.formatters.inputDefaultBlockNumberFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'exportHistory',
+			call: 'admin_exportHistory',
+			params: 4,
+			inputFormatter: [null, web3._extend.formatters.inputDefaultBlockNumberFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'exportState',
+			call: 'admin_exportState',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'importChain',
 			call: 'admin_importChain',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'importHistory',
+			call: 'admin_importHistory',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'importState',
+			call: 'admin_importState',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'importChainFromString',
 			call: 'admin_importChainFromString',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'importChainAsync',
+			call: 'admin_importChainAsync',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'importStatus',
+			call: 'admin_importStatus',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'importAbort',
+			call: 'admin_importAbort',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'sleepBlocks',
 			call: 'admin_sleepBlocks',
@@ -301,6 +424,26 @@ web3._extend({
 			name: 'stopWS',
 			call: 'admin_stopWS'
 		}),
+		new web3._extend.Method({
+			name: 'startPprof',
+			call: 'admin_startPprof',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'stopPprof',
+			call: 'admin_stopPprof'
+		}),
+		new web3._extend.Method({
+			name: 'setVerbosity',
+			call: 'admin_setVerbosity',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'setVmodule',
+			call: 'admin_setVmodule',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'startStateMigration',
 			call: 'admin_startStateMigration',
@@ -309,15 +452,107 @@ web3._extend({
 			name: 'stopStateMigration',
 			call: 'admin_stopStateMigration',
 		}),
+		new web3._extend.Method({
+			name: 'startIndexRebuild',
+			call: 'admin_startIndexRebuild',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'stopIndexRebuild',
+			call: 'admin_stopIndexRebuild',
+		}),
 		new web3._extend.Method({
 			name: 'saveTrieNodeCacheToDisk',
 			call: 'admin_saveTrieNodeCacheToDisk',
 		}),
+		new web3._extend.Method({
+			name: 'dumpTrieNodeCacheToDisk',
+			call: 'admin_dumpTrieNodeCacheToDisk',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'loadTrieNodeCache',
+			call: 'admin_loadTrieNodeCache',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'setTxLookupLimit',
+			call: 'admin_setTxLookupLimit',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'chainDataCompressionStats',
+			call: 'admin_chainDataCompressionStats',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'setBodyRetention',
+			call: 'admin_setBodyRetention',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'setTxOrderingPolicy',
+			call: 'admin_setTxOrderingPolicy',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'setTxBudget',
+			call: 'admin_setTxBudget',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'setTargetGasLimit',
+			call: 'admin_setTargetGasLimit',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'addToDenylist',
+			call: 'admin_addToDenylist',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'removeFromDenylist',
+			call: 'admin_removeFromDenylist',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'registerABI',
+			call: 'admin_registerABI',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'unregisterABI',
+			call: 'admin_unregisterABI',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'setMaxSubscriptionPerWSConn',
 			call: 'admin_setMaxSubscriptionPerWSConn',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'reloadAPIKeys',
+			call: 'admin_reloadAPIKeys',
+		}),
+		new web3._extend.Method({
+			name: 'reloadMethodLimits',
+			call: 'admin_reloadMethodLimits',
+		}),
+		new web3._extend.Method({
+			name: 'setExecutionTimeout',
+			call: 'admin_setExecutionTimeout',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'rotateNodeKey',
+			call: 'admin_rotateNodeKey',
+			params: 1,
+			inputFormatter: [null]
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -336,6 +571,54 @@ web3._extend({
 			name: 'stateMigrationStatus',
 			getter: 'admin_stateMigrationStatus'
 		}),
+		new web3._extend.Property({
+			name: 'bloomIndexStatus',
+			getter: 'admin_bloomIndexStatus'
+		}),
+		new web3._extend.Property({
+			name: 'indexRebuildStatus',
+			getter: 'admin_indexRebuildStatus'
+		}),
+		new web3._extend.Property({
+			name: 'txIndexStatus',
+			getter: 'admin_txIndexStatus'
+		}),
+		new web3._extend.Property({
+			name: 'txOrderingPolicy',
+			getter: 'admin_txOrderingPolicy'
+		}),
+		new web3._extend.Property({
+			name: 'txBudget',
+			getter: 'admin_txBudget'
+		}),
+		new web3._extend.Property({
+			name: 'gasLimit',
+			getter: 'admin_gasLimit'
+		}),
+		new web3._extend.Property({
+			name: 'denylist',
+			getter: 'admin_denylist'
+		}),
+		new web3._extend.Property({
+			name: 'registeredABIs',
+			getter: 'admin_registeredABIs'
+		}),
+		new web3._extend.Property({
+			name: 'chainIDAudit',
+			getter: 'admin_chainIDAudit'
+		}),
+		new web3._extend.Property({
+			name: 'apiKeyUsage',
+			getter: 'admin_apiKeyUsage'
+		}),
+		new web3._extend.Property({
+			name: 'methodLimitUsage',
+			getter: 'admin_methodLimitUsage'
+		}),
+		new web3._extend.Property({
+			name: 'executionTimeouts',
+			getter: 'admin_executionTimeouts'
+		}),
 	]
 });
 `
@@ -359,6 +642,18 @@ web3._extend({
 			call: 'debug_setHead',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'chaindbProperty',
+			call: 'debug_chaindbProperty',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'chaindbCompact',
+			call: 'debug_chaindbCompact',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 		new web3._extend.Method({
 			name: 'dumpBlock',
 			call: 'debug_dumpBlock',
@@ -369,6 +664,11 @@ web3._extend({
 			call: 'debug_dumpStateTrie',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getStorageTrieOwners',
+			call: 'debug_getStorageTrieOwners',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'startWarmUp',
 			call: 'debug_startWarmUp',
@@ -576,12 +876,69 @@ web3._extend({
 			params: 2,
 			inputFormatter: [null, null]
 		}),
+		new web3._extend.Method({
+			name: 'stateDiffBlockByNumber',
+			call: 'debug_stateDiffBlockByNumber',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'stateDiffBlockByHash',
+			call: 'debug_stateDiffBlockByHash',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'executionWitnessByNumber',
+			call: 'debug_executionWitnessByNumber',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'executionWitnessByHash',
+			call: 'debug_executionWitnessByHash',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'verifyExecutionWitness',
+			call: 'debug_verifyExecutionWitness',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'profileTransaction',
+			call: 'debug_profileTransaction',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'profileBlockByNumber',
+			call: 'debug_profileBlockByNumber',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'profileBlockByHash',
+			call: 'debug_profileBlockByHash',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 		new web3._extend.Method({
 			name: 'traceTransaction',
 			call: 'debug_traceTransaction',
 			params: 2,
 			inputFormatter: [null, null]
 		}),
+		new web3._extend.Method({
+			name: 'intermediateRoots',
+			call: 'debug_intermediateRoots',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'traceCall',
+			call: 'debug_traceCall',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'preimage',
 			call: 'debug_preimage',
@@ -593,11 +950,39 @@ web3._extend({
 			call: 'debug_getBadBlocks',
 			params: 0,
 		}),
+		new web3._extend.Method({
+			name: 'getSlowBlockImports',
+			call: 'debug_getSlowBlockImports',
+			params: 1,
+			inputFormatter: [null]
+		}),
 		new web3._extend.Method({
 			name: 'storageRangeAt',
 			call: 'debug_storageRangeAt',
 			params: 5,
 		}),
+		new web3._extend.Method({
+			name: 'openStateSession',
+			call: 'debug_openStateSession',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'closeStateSession',
+			call: 'debug_closeStateSession',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'accountRange',
+			call: 'debug_accountRange',
+			params: 6,
+			inputFormatter: [null, null, null, null, null, null],
+		}),
+		new web3._extend.Method({
+			name: 'storageRange',
+			call: 'debug_storageRange',
+			params: 5,
+			inputFormatter: [null, null, null, null, null],
+		}),
 		new web3._extend.Method({
 			name: 'getModifiedAccountsByNumber',
 			call: 'debug_getModifiedAccountsByNumber',
@@ -616,6 +1001,28 @@ web3._extend({
 			params: 4,
 			inputFormatter: [null, null, null, null],
 		}),
+		new web3._extend.Method({
+			name: 'getContractStats',
+			call: 'debug_getContractStats',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, null],
+		}),
+		new web3._extend.Method({
+			name: 'enableContractVMLog',
+			call: 'debug_enableContractVMLog',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'disableContractVMLog',
+			call: 'debug_disableContractVMLog',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'contractVMLogTargets',
+			call: 'debug_contractVMLogTargets',
+		}),
 		new web3._extend.Method({
 			name: 'setVMLogTarget',
 			call: 'debug_setVMLogTarget',
@@ -650,6 +1057,12 @@ web3._extend({
 				return formatted;
 			}
 		}),
+		new web3._extend.Method({
+			name: 'getLogsPage',
+			call: 'klay_getLogsPage',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'sign',
 			call: 'klay_sign',
@@ -680,6 +1093,41 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'getAccountQueueStatus',
+			call: 'klay_getAccountQueueStatus',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getAvailableBlockRanges',
+			call: 'klay_getAvailableBlockRanges',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getTotalSupply',
+			call: 'klay_getTotalSupply',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getSupplyStats',
+			call: 'klay_getSupplyStats',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getFeePayerStats',
+			call: 'klay_getFeePayerStats',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getBalanceHistory',
+			call: 'klay_getBalanceHistory',
+			params: 4,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.utils.fromDecimal]
+		}),
 		new web3._extend.Method({
 			name: 'getCouncil',
 			call: 'klay_getCouncil',
@@ -698,6 +1146,12 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'getCommitteeProof',
+			call: 'klay_getCommitteeProof',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'getCommitteeSize',
 			call: 'klay_getCommitteeSize',
@@ -723,6 +1177,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter],
 		}),
+		new web3._extend.Method({
+			name: 'getProof',
+			call: 'klay_getProof',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'getHeaderByNumber',
 			call: 'klay_getHeaderByNumber',
@@ -744,7 +1204,13 @@ web3._extend({
 			name: 'getBlockWithConsensusInfoRange',
 			call: 'klay_getBlockWithConsensusInfoByNumberRange',
 			params: 2,
-			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getConsensusInfoRange',
+			call: 'klay_getConsensusInfoByNumberRange',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter, null]
 		}),
 		new web3._extend.Method({
 			name: 'isContractAccount',
@@ -769,6 +1235,18 @@ web3._extend({
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputCallFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'createAccessList',
+			call: 'klay_createAccessList',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputCallFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'simulateV1',
+			call: 'klay_simulateV1',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'getAccountKey',
 			call: 'klay_getAccountKey',
@@ -950,7 +1428,14 @@ web3._extend({
 const TxPool_JS = `
 web3._extend({
 	property: 'txpool',
-	methods: [],
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'contentFrom',
+			call: 'txpool_contentFrom',
+			params: 1
+		}),
+	],
 	properties:
 	[
 		new web3._extend.Property({
@@ -0,0 +1,31 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package kzg4844 implements the verification of the KZG proofs of EIP-4844,
+which is used by the point evaluation precompiled contract.
+
+Only the verification is supported, so only the G2 points of the trusted setup are needed.
+The setup of the Ethereum KZG ceremony is embedded. Since the precompiled contract is part of
+the consensus, the setup cannot be replaced by a node.
+
+# Source Files
+
+Each source file contains the following contents.
+  - kzg4844.go       : Provides the types and the verification of a KZG proof
+  - trusted_setup.go : Provides the embedded trusted setup
+*/
+package kzg4844
@@ -0,0 +1,109 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package kzg4844
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// VersionedHashVersionKZG is the version byte of the versioned hash of a KZG commitment.
+const VersionedHashVersionKZG = byte(0x01)
+
+// BLSModulus is the order of the BLS12-381 scalar field.
+var BLSModulus, _ = new(big.Int).SetString("52435875175126190479447740508185965837690552500527637822603658699938581184513", 10)
+
+var (
+	errInvalidFieldElement = errors.New("field element is not canonical")
+	errInvalidProof        = errors.New("invalid kzg proof")
+)
+
+// Commitment is a serialized commitment to a polynomial.
+type Commitment [48]byte
+
+// Proof is a serialized commitment to the quotient polynomial.
+type Proof [48]byte
+
+// Point is a BLS field element.
+type Point [32]byte
+
+// Claim is a claimed evaluation value in a specific point.
+type Claim [32]byte
+
+// CalcBlobHashV1 calculates the versioned hash of the commitment.
+func CalcBlobHashV1(commitment *Commitment) (vh [32]byte) {
+	vh = sha256.Sum256(commitment[:])
+	vh[0] = VersionedHashVersionKZG
+	return vh
+}
+
+// VerifyProof verifies the KZG proof that the polynomial represented by the commitment
+// evaluates to the claim at the point.
+func VerifyProof(commitment Commitment, point Point, claim Claim, proof Proof) error {
+	z, err := toFieldElement(point[:])
+	if err != nil {
+		return err
+	}
+	y, err := toFieldElement(claim[:])
+	if err != nil {
+		return err
+	}
+
+	g1 := bls12381.NewG1()
+	c, err := g1.FromCompressed(commitment[:])
+	if err != nil {
+		return fmt.Errorf("invalid commitment: %v", err)
+	}
+	pi, err := g1.FromCompressed(proof[:])
+	if err != nil {
+		return fmt.Errorf("invalid proof: %v", err)
+	}
+
+	e := bls12381.NewEngine()
+	g2 := e.G2
+
+	// [s - z]G2
+	xMinusZ := g2.New()
+	g2.MulScalarBig(xMinusZ, g2.One(), z)
+	g2.Sub(xMinusZ, currentSetup().tauG2, xMinusZ)
+
+	// [p(s) - y]G1
+	pMinusY := g1.New()
+	g1.MulScalarBig(pMinusY, g1.One(), y)
+	g1.Sub(pMinusY, c, pMinusY)
+
+	// e([p(s) - y]G1, -G2) * e(proof, [s - z]G2) == 1
+	e.AddPairInv(pMinusY, g2.One())
+	e.AddPair(pi, xMinusZ)
+	if !e.Check() {
+		return errInvalidProof
+	}
+	return nil
+}
+
+// toFieldElement converts the big-endian bytes to a canonical BLS field element.
+func toFieldElement(b []byte) (*big.Int, error) {
+	v := new(big.Int).SetBytes(b)
+	if v.Cmp(BLSModulus) >= 0 {
+		return nil, errInvalidFieldElement
+	}
+	return v, nil
+}
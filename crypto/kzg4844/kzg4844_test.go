@@ -0,0 +1,90 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package kzg4844
+
+import (
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// A valid proof of the point evaluation, verified with the trusted setup of the Ethereum KZG ceremony.
+var (
+	testVersionedHash = common.FromHex("01e798154708fe7789429634053cbf9f99b619f9f084048927333fce637f549b")
+	testPoint         = common.FromHex("564c0a11a0f704f4fc3e8acfe0f8245f0ad1347b378fbf96e206da11a5d36306")
+	testClaim         = common.FromHex("24d25032e67a7e6a4910df5834b8fe70e6bcfeeac0352434196bdf4b2485d5a1")
+	testCommitment    = common.FromHex("8f59a8d2a1a625a17f3fea0fe5eb8c896db3764f3185481bc22f91b4aaffcca25f26936857bc3a7c2539ea8ec3a952b7")
+	testProof         = common.FromHex("873033e038326e87ed3e1276fd140253fa08e9fc25fb2d9a98527fc22a2c9612fbeafdad446cbc7bcdbdcd780af2c16a")
+)
+
+func testInputs() (Commitment, Point, Claim, Proof) {
+	var (
+		commitment Commitment
+		point      Point
+		claim      Claim
+		proof      Proof
+	)
+	copy(commitment[:], testCommitment)
+	copy(point[:], testPoint)
+	copy(claim[:], testClaim)
+	copy(proof[:], testProof)
+	return commitment, point, claim, proof
+}
+
+func TestVerifyProof(t *testing.T) {
+	commitment, point, claim, proof := testInputs()
+
+	vh := CalcBlobHashV1(&commitment)
+	assert.Equal(t, testVersionedHash, vh[:])
+	assert.NoError(t, VerifyProof(commitment, point, claim, proof))
+
+	// wrong claim
+	wrongClaim := claim
+	wrongClaim[31] ^= 1
+	assert.Equal(t, errInvalidProof, VerifyProof(commitment, point, wrongClaim, proof))
+
+	// non-canonical point
+	var nonCanonical Point
+	copy(nonCanonical[:], common.LeftPadBytes(BLSModulus.Bytes(), 32))
+	assert.Equal(t, errInvalidFieldElement, VerifyProof(commitment, nonCanonical, claim, proof))
+
+	// invalid commitment
+	invalidCommitment := commitment
+	invalidCommitment[0] = 0
+	assert.Error(t, VerifyProof(invalidCommitment, point, claim, proof))
+}
+
+func TestTrustedSetup(t *testing.T) {
+	commitment, point, claim, proof := testInputs()
+	assert.NoError(t, VerifyProof(commitment, point, claim, proof))
+
+	// another setup whose [s]G2 is the generator fails to verify the proof
+	generator := "0x93e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e024aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb8"
+	other, err := newTrustedSetup(generator)
+	assert.NoError(t, err)
+	embedded := currentSetup()
+	setup = other
+	defer func() { setup = embedded }()
+	assert.Equal(t, errInvalidProof, VerifyProof(commitment, point, claim, proof))
+
+	// invalid points
+	_, err = newTrustedSetup("0x00")
+	assert.Error(t, err)
+	_, err = newTrustedSetup(defaultTauG2[:len(defaultTauG2)-2])
+	assert.Error(t, err)
+}
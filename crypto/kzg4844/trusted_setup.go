@@ -0,0 +1,64 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package kzg4844
+
+import (
+	"fmt"
+	"sync"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/klaytn/klaytn/common/hexutil"
+)
+
+// defaultTauG2 is [s]G2 of the trusted setup of the Ethereum KZG ceremony,
+// the second point of g2_monomial in the setup file.
+// The point evaluation precompiled contract depends on it, so it is part of the consensus
+// and all nodes should use the same one; it cannot be replaced by a local file.
+const defaultTauG2 = "0xb5bfd7dd8cdeb128843bc287230af38926187075cbfbefa81009a2ce615ac53d2914e5870cb452d2afaaab24f3499f72185cbfee53492714734429b7b38608e23926c911cceceac9a36851477ba4c60b087041de621000edc98edada20c1def2"
+
+type trustedSetup struct {
+	tauG2 *bls12381.PointG2
+}
+
+var (
+	setupOnce sync.Once
+	setup     *trustedSetup
+)
+
+// currentSetup returns the embedded trusted setup, which is decoded on the first use.
+func currentSetup() *trustedSetup {
+	setupOnce.Do(func() {
+		s, err := newTrustedSetup(defaultTauG2)
+		if err != nil {
+			panic(fmt.Sprintf("invalid embedded kzg trusted setup: %v", err))
+		}
+		setup = s
+	})
+	return setup
+}
+
+func newTrustedSetup(tauG2 string) (*trustedSetup, error) {
+	b, err := hexutil.Decode(tauG2)
+	if err != nil {
+		return nil, err
+	}
+	p, err := bls12381.NewG2().FromCompressed(b)
+	if err != nil {
+		return nil, err
+	}
+	return &trustedSetup{tauG2: p}, nil
+}
@@ -0,0 +1,330 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package chainarchive
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/golang/snappy"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+// An archive file is a sequence of entries, each of which has an 8-byte header of the entry type
+// (uint16), the data length (uint32) and a reserved field (uint16) in little endian, followed by
+// the data. The file starts with a version entry, has the header, the body, the receipts and the
+// total blockscore entries of each block in order, and ends with the accumulator entry and the
+// block index entry. The header, the body and the receipts are snappy-compressed RLP.
+//
+// The block index has the number of the first block (uint64), the file offsets of the header
+// entries of the blocks (int64 each) and the number of the blocks (uint64) in little endian, so
+// that a reader can find a block by reading the end of the file.
+const (
+	typeVersion     uint16 = 0x3265
+	typeHeader      uint16 = 0x03
+	typeBody        uint16 = 0x04
+	typeReceipts    uint16 = 0x05
+	typeBlockScore  uint16 = 0x06
+	typeAccumulator uint16 = 0x07
+	typeBlockIndex  uint16 = 0x3266
+
+	entryHeaderSize = 8
+)
+
+var (
+	errUnexpectedEntry   = errors.New("unexpected archive entry")
+	errInvalidBlockIndex = errors.New("invalid archive block index")
+	errEmptyArchive      = errors.New("archive has no block")
+)
+
+// ComputeAccumulator returns the accumulator root committing to the blocks of the given hashes
+// and total blockscores, which is the root of the binary merkle tree whose leaves are the keccak256
+// hashes of the block hash and the 32-byte total blockscore, padded with the empty hashes to
+// a power of two.
+func ComputeAccumulator(hashes []common.Hash, tds []*big.Int) common.Hash {
+	if len(hashes) == 0 {
+		return common.Hash{}
+	}
+	size := 1
+	for size < len(hashes) {
+		size *= 2
+	}
+	nodes := make([]common.Hash, size)
+	for i, hash := range hashes {
+		nodes[i] = crypto.Keccak256Hash(hash[:], common.LeftPadBytes(tds[i].Bytes(), 32))
+	}
+	for ; size > 1; size /= 2 {
+		for i := 0; i < size/2; i++ {
+			nodes[i] = crypto.Keccak256Hash(nodes[2*i][:], nodes[2*i+1][:])
+		}
+	}
+	return nodes[0]
+}
+
+// Writer writes the blocks of an epoch into an archive file.
+type Writer struct {
+	w       io.Writer
+	offset  int64
+	start   uint64
+	offsets []int64
+	hashes  []common.Hash
+	tds     []*big.Int
+}
+
+// NewWriter returns a writer of an archive file to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+func (w *Writer) writeEntry(typ uint16, data []byte) error {
+	var header [entryHeaderSize]byte
+	binary.LittleEndian.PutUint16(header[0:], typ)
+	binary.LittleEndian.PutUint32(header[2:], uint32(len(data)))
+	if _, err := w.w.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(data); err != nil {
+		return err
+	}
+	w.offset += int64(entryHeaderSize + len(data))
+	return nil
+}
+
+func (w *Writer) writeCompressed(typ uint16, val interface{}) error {
+	data, err := rlp.EncodeToBytes(val)
+	if err != nil {
+		return err
+	}
+	return w.writeEntry(typ, snappy.Encode(nil, data))
+}
+
+// Add appends a block with its receipts and total blockscore. The blocks should be added in
+// the order of their numbers without a gap.
+func (w *Writer) Add(block *types.Block, receipts types.Receipts, td *big.Int) error {
+	if len(w.offsets) == 0 {
+		if err := w.writeEntry(typeVersion, nil); err != nil {
+			return err
+		}
+		w.start = block.NumberU64()
+	} else if expected := w.start + uint64(len(w.offsets)); block.NumberU64() != expected {
+		return fmt.Errorf("non contiguous block: have %d, want %d", block.NumberU64(), expected)
+	}
+	w.offsets = append(w.offsets, w.offset)
+
+	storageReceipts := make([]*types.ReceiptForStorage, len(receipts))
+	for i, receipt := range receipts {
+		storageReceipts[i] = (*types.ReceiptForStorage)(receipt)
+	}
+	if err := w.writeCompressed(typeHeader, block.Header()); err != nil {
+		return err
+	}
+	if err := w.writeCompressed(typeBody, block.Body()); err != nil {
+		return err
+	}
+	if err := w.writeCompressed(typeReceipts, storageReceipts); err != nil {
+		return err
+	}
+	if err := w.writeEntry(typeBlockScore, common.LeftPadBytes(td.Bytes(), 32)); err != nil {
+		return err
+	}
+	w.hashes = append(w.hashes, block.Hash())
+	w.tds = append(w.tds, td)
+	return nil
+}
+
+// Finalize writes the accumulator and the block index, and returns the accumulator root.
+func (w *Writer) Finalize() (common.Hash, error) {
+	if len(w.offsets) == 0 {
+		return common.Hash{}, errEmptyArchive
+	}
+	root := ComputeAccumulator(w.hashes, w.tds)
+	if err := w.writeEntry(typeAccumulator, root[:]); err != nil {
+		return common.Hash{}, err
+	}
+	index := make([]byte, 16+8*len(w.offsets))
+	binary.LittleEndian.PutUint64(index, w.start)
+	for i, offset := range w.offsets {
+		binary.LittleEndian.PutUint64(index[8+8*i:], uint64(offset))
+	}
+	binary.LittleEndian.PutUint64(index[len(index)-8:], uint64(len(w.offsets)))
+	if err := w.writeEntry(typeBlockIndex, index); err != nil {
+		return common.Hash{}, err
+	}
+	return root, nil
+}
+
+// Reader reads the blocks of an archive file.
+type Reader struct {
+	r       io.ReaderAt
+	start   uint64
+	offsets []int64
+	root    common.Hash
+}
+
+// NewReader returns a reader of the archive file of the given size, reading its block index
+// and accumulator.
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	var buf [8]byte
+	if size < entryHeaderSize+16 {
+		return nil, errInvalidBlockIndex
+	}
+	if _, err := r.ReadAt(buf[:], size-8); err != nil {
+		return nil, err
+	}
+	count := binary.LittleEndian.Uint64(buf[:])
+	if count == 0 || count > uint64(size)/8 {
+		return nil, errInvalidBlockIndex
+	}
+	indexSize := int64(16 + 8*count)
+	if indexSize+2*entryHeaderSize+common.HashLength > size {
+		return nil, errInvalidBlockIndex
+	}
+	indexOffset := size - indexSize - entryHeaderSize
+	typ, index, err := readEntry(r, indexOffset)
+	if err != nil {
+		return nil, err
+	}
+	if typ != typeBlockIndex || int64(len(index)) != indexSize {
+		return nil, errInvalidBlockIndex
+	}
+	reader := &Reader{r: r, start: binary.LittleEndian.Uint64(index), offsets: make([]int64, count)}
+	for i := range reader.offsets {
+		reader.offsets[i] = int64(binary.LittleEndian.Uint64(index[8+8*i:]))
+		if reader.offsets[i] < 0 || reader.offsets[i] >= indexOffset {
+			return nil, errInvalidBlockIndex
+		}
+	}
+	typ, root, err := readEntry(r, indexOffset-entryHeaderSize-common.HashLength)
+	if err != nil {
+		return nil, err
+	}
+	if typ != typeAccumulator || len(root) != common.HashLength {
+		return nil, fmt.Errorf("%w: accumulator not found", errUnexpectedEntry)
+	}
+	reader.root = common.BytesToHash(root)
+	return reader, nil
+}
+
+func readEntry(r io.ReaderAt, offset int64) (uint16, []byte, error) {
+	var header [entryHeaderSize]byte
+	if _, err := r.ReadAt(header[:], offset); err != nil {
+		return 0, nil, err
+	}
+	data := make([]byte, binary.LittleEndian.Uint32(header[2:]))
+	if _, err := r.ReadAt(data, offset+entryHeaderSize); err != nil {
+		return 0, nil, err
+	}
+	return binary.LittleEndian.Uint16(header[0:]), data, nil
+}
+
+// Start returns the number of the first block in the archive.
+func (r *Reader) Start() uint64 { return r.start }
+
+// Count returns the number of the blocks in the archive.
+func (r *Reader) Count() uint64 { return uint64(len(r.offsets)) }
+
+// Accumulator returns the accumulator root written in the archive.
+func (r *Reader) Accumulator() common.Hash { return r.root }
+
+// Block returns the block of the given number with its receipts and total blockscore.
+func (r *Reader) Block(number uint64) (*types.Block, types.Receipts, *big.Int, error) {
+	if number < r.start || number-r.start >= r.Count() {
+		return nil, nil, nil, fmt.Errorf("block %d is not in the archive", number)
+	}
+	var (
+		offset   = r.offsets[number-r.start]
+		header   = new(types.Header)
+		body     = new(types.Body)
+		receipts []*types.ReceiptForStorage
+		td       []byte
+	)
+	for _, entry := range []struct {
+		typ uint16
+		val interface{}
+	}{{typeHeader, header}, {typeBody, body}, {typeReceipts, &receipts}, {typeBlockScore, nil}} {
+		t, data, err := readEntry(r.r, offset)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if t != entry.typ {
+			return nil, nil, nil, fmt.Errorf("%w: have type %#x, want %#x", errUnexpectedEntry, t, entry.typ)
+		}
+		if entry.val == nil {
+			td = data
+		} else if err := decodeCompressed(data, entry.val); err != nil {
+			return nil, nil, nil, err
+		}
+		offset += entryHeaderSize + int64(len(data))
+	}
+
+	block := types.NewBlockWithHeader(header).WithBody(body.Transactions)
+	result := make(types.Receipts, len(receipts))
+	for i, receipt := range receipts {
+		result[i] = (*types.Receipt)(receipt)
+	}
+	return block, result, new(big.Int).SetBytes(td), nil
+}
+
+func decodeCompressed(data []byte, val interface{}) error {
+	data, err := snappy.Decode(nil, data)
+	if err != nil {
+		return err
+	}
+	return rlp.DecodeBytes(data, val)
+}
+
+// Verify checks that the blocks of the archive are linked by their parent hashes, that their
+// transactions and receipts match their headers, and that they are committed to by the
+// accumulator root.
+func (r *Reader) Verify() error {
+	var (
+		hashes = make([]common.Hash, 0, r.Count())
+		tds    = make([]*big.Int, 0, r.Count())
+		parent common.Hash
+	)
+	for number := r.start; number < r.start+r.Count(); number++ {
+		block, receipts, td, err := r.Block(number)
+		if err != nil {
+			return fmt.Errorf("block %d: %v", number, err)
+		}
+		if block.NumberU64() != number {
+			return fmt.Errorf("block %d: unexpected number %d", number, block.NumberU64())
+		}
+		if number > r.start && block.ParentHash() != parent {
+			return fmt.Errorf("block %d: parent hash mismatch", number)
+		}
+		if hash := types.DeriveSha(block.Transactions()); hash != block.Header().TxHash {
+			return fmt.Errorf("block %d: transaction root mismatch", number)
+		}
+		if hash := types.DeriveSha(receipts); hash != block.Header().ReceiptHash {
+			return fmt.Errorf("block %d: receipt root mismatch", number)
+		}
+		parent = block.Hash()
+		hashes = append(hashes, parent)
+		tds = append(tds, td)
+	}
+	if root := ComputeAccumulator(hashes, tds); root != r.root {
+		return fmt.Errorf("accumulator mismatch: have %x, want %x", root, r.root)
+	}
+	return nil
+}
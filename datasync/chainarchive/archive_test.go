@@ -0,0 +1,168 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package chainarchive
+
+import (
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
+	testGenesis = &blockchain.Genesis{Config: params.TestChainConfig, Alloc: blockchain.GenesisAlloc{testAddr: {Balance: big.NewInt(10000000000000)}}}
+)
+
+// newTestBlockChain returns a new blockchain with the test genesis and the blocks inserted.
+func newTestBlockChain(t *testing.T, blocks types.Blocks) *blockchain.BlockChain {
+	db := database.NewMemoryDBManager()
+	testGenesis.MustCommit(db)
+	bc, err := blockchain.NewBlockChain(db, nil, testGenesis.Config, gxhash.NewFaker(), vm.Config{})
+	require.NoError(t, err)
+	if len(blocks) > 0 {
+		_, err = bc.InsertChain(blocks)
+		require.NoError(t, err)
+	}
+	return bc
+}
+
+func generateBlocks(n int) types.Blocks {
+	db := database.NewMemoryDBManager()
+	genesis := testGenesis.MustCommit(db)
+	signer := types.NewEIP155Signer(testGenesis.Config.ChainID)
+	blocks, _ := blockchain.GenerateChain(testGenesis.Config, genesis, gxhash.NewFaker(), db, n, func(i int, gen *blockchain.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(testAddr), testAddr, big.NewInt(1000), params.TxGas, nil, nil), signer, testKey)
+		gen.AddTx(tx)
+	})
+	return blocks
+}
+
+func TestComputeAccumulator(t *testing.T) {
+	hashes := []common.Hash{{1}, {2}, {3}}
+	tds := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+	root := ComputeAccumulator(hashes, tds)
+	assert.NotEqual(t, common.Hash{}, root)
+
+	tds[2] = big.NewInt(4)
+	assert.NotEqual(t, root, ComputeAccumulator(hashes, tds))
+	assert.Equal(t, common.Hash{}, ComputeAccumulator(nil, nil))
+}
+
+func TestExportImport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chainarchive")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	blocks := generateBlocks(10)
+	src := newTestBlockChain(t, blocks)
+	defer src.Stop()
+
+	exporter, err := NewExporter(src, &Config{Dir: dir, EpochSize: 4})
+	require.NoError(t, err)
+
+	// The epoch of the blocks 8~11 is not complete
+	_, err = exporter.Export(0, 10)
+	assert.Equal(t, errIncompleteEpoch, err)
+
+	names, err := exporter.Export(1, 7)
+	require.NoError(t, err)
+	require.Len(t, names, 2)
+	for i, name := range names {
+		epoch, _, ok := parseFileName(name)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(i), epoch)
+	}
+
+	// The exported epochs are skipped
+	again, err := exporter.Export(0, 7)
+	require.NoError(t, err)
+	assert.Equal(t, names, again)
+
+	f, err := os.Open(filepath.Join(dir, names[1]))
+	require.NoError(t, err)
+	stat, _ := f.Stat()
+	reader, err := NewReader(f, stat.Size())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), reader.Start())
+	assert.Equal(t, uint64(4), reader.Count())
+	assert.NoError(t, reader.Verify())
+	block, receipts, td, err := reader.Block(5)
+	require.NoError(t, err)
+	assert.Equal(t, blocks[4].Hash(), block.Hash())
+	assert.Len(t, receipts, 1)
+	assert.Equal(t, src.GetTd(block.Hash(), 5), td)
+	f.Close()
+
+	// Import from the local directory
+	dst := newTestBlockChain(t, nil)
+	defer dst.Stop()
+	var inserted int
+	require.NoError(t, Import(dst, dir, nil, func(n int, number uint64) { inserted += n }))
+	assert.Equal(t, 7, inserted)
+	assert.Equal(t, blocks[6].Hash(), dst.CurrentBlock().Hash())
+
+	// Import over HTTP into another chain
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+	remote := newTestBlockChain(t, nil)
+	defer remote.Stop()
+	require.NoError(t, Import(remote, server.URL+"/", nil, nil))
+	assert.Equal(t, blocks[6].Hash(), remote.CurrentBlock().Hash())
+}
+
+func TestImport_Tampered(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chainarchive")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	src := newTestBlockChain(t, generateBlocks(4))
+	defer src.Stop()
+	exporter, err := NewExporter(src, &Config{Dir: dir, EpochSize: 4})
+	require.NoError(t, err)
+	names, err := exporter.Export(0, 3)
+	require.NoError(t, err)
+
+	// A modified file does not match its checksum
+	path := filepath.Join(dir, names[0])
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	data[len(data)/2] ^= 0xff
+	require.NoError(t, ioutil.WriteFile(path, data, 0644))
+
+	dst := newTestBlockChain(t, nil)
+	defer dst.Stop()
+	err = Import(dst, dir, nil, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+	assert.Equal(t, uint64(0), dst.CurrentBlock().NumberU64())
+}
@@ -0,0 +1,29 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package chainarchive implements the export and the import of immutable chain archives, which
+keep the headers, the bodies, the receipts and the total blockscores of the blocks in epochs of
+a fixed number of blocks. Each archive file is committed to by the accumulator root of its blocks,
+which is a part of the file name, and the files of a directory are listed with their checksums in
+an index file, so that the chain history can be distributed over HTTP or an object storage
+instead of the p2p network and verified on fresh nodes.
+Source Files
+  - archive.go  : implements the archive file format, its writer and its reader
+  - exporter.go : implements exporting the epochs of the chain into the archive files
+  - importer.go : implements importing the archive files of a local or a remote directory
+*/
+package chainarchive
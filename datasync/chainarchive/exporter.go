@@ -0,0 +1,259 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package chainarchive
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/params"
+)
+
+var logger = log.NewModuleLogger(log.ChainArchive)
+
+const (
+	// DefaultEpochSize is the default number of blocks in an archive file.
+	DefaultEpochSize = 8192
+
+	// IndexFileName is the name of the file listing the archive files of a directory with
+	// their SHA-256 checksums.
+	IndexFileName = "checksums.txt"
+)
+
+var (
+	errInvalidRange     = errors.New("invalid block range")
+	errInvalidEpochSize = errors.New("epoch size should be positive")
+	errIncompleteEpoch  = errors.New("last epoch is not complete yet")
+
+	// fileNamePattern matches the archive file name, klaytn-<chain id>-<epoch>-<accumulator prefix>.era
+	fileNamePattern = regexp.MustCompile(`^klaytn-(\d+)-(\d+)-([0-9a-f]{8})\.era$`)
+)
+
+// BlockChain is the interface of the blockchain used by the exporter.
+type BlockChain interface {
+	Config() *params.ChainConfig
+	CurrentBlock() *types.Block
+	GetBlockByNumber(number uint64) *types.Block
+	GetReceiptsByBlockHash(blockHash common.Hash) types.Receipts
+	GetTd(hash common.Hash, number uint64) *big.Int
+}
+
+// Config is the configuration of the exporter.
+type Config struct {
+	Dir       string // Directory where the archive files are written
+	EpochSize uint64 // Number of blocks in an archive file
+}
+
+// FileName returns the name of the archive file of the given epoch and accumulator root.
+func FileName(chainID *big.Int, epoch uint64, root common.Hash) string {
+	return fmt.Sprintf("klaytn-%d-%05d-%x.era", chainID, epoch, root[:4])
+}
+
+// parseFileName returns the epoch and the accumulator prefix of the archive file name.
+func parseFileName(name string) (uint64, string, bool) {
+	match := fileNamePattern.FindStringSubmatch(name)
+	if match == nil {
+		return 0, "", false
+	}
+	epoch, err := strconv.ParseUint(match[2], 10, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return epoch, match[3], true
+}
+
+// Exporter exports the epochs of the chain into the archive files.
+//
+// The epochs are aligned to the multiples of the epoch size, and only the complete epochs are
+// exported since an archive is immutable. An archive file is written to a temporary file first
+// and renamed when it is completed, and the epochs whose files exist are skipped, so an
+// interrupted export can be resumed by running it again. The index file of the directory is
+// rewritten after the export.
+type Exporter struct {
+	bc     BlockChain
+	config *Config
+}
+
+// NewExporter returns a new exporter.
+func NewExporter(bc BlockChain, config *Config) (*Exporter, error) {
+	if config.EpochSize == 0 {
+		return nil, errInvalidEpochSize
+	}
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Exporter{bc: bc, config: config}, nil
+}
+
+// Export writes the archive files of the epochs containing the blocks from first to last, and
+// returns the names of the archive files of the epochs.
+func (e *Exporter) Export(first, last uint64) ([]string, error) {
+	if first > last {
+		return nil, errInvalidRange
+	}
+	var (
+		size      = e.config.EpochSize
+		fromEpoch = first / size
+		toEpoch   = last / size
+	)
+	if (toEpoch+1)*size-1 > e.bc.CurrentBlock().NumberU64() {
+		return nil, errIncompleteEpoch
+	}
+	existing, err := e.existingFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for epoch := fromEpoch; epoch <= toEpoch; epoch++ {
+		if name, ok := existing[epoch]; ok {
+			logger.Debug("Skip the epoch already exported", "epoch", epoch, "file", name)
+			names = append(names, name)
+			continue
+		}
+		start := time.Now()
+		name, err := e.exportEpoch(epoch)
+		if err != nil {
+			return nil, fmt.Errorf("epoch %d: %v", epoch, err)
+		}
+		logger.Info("Exported chain archive", "epoch", epoch, "file", name, "elapsed", common.PrettyDuration(time.Since(start)))
+		names = append(names, name)
+	}
+	return names, e.writeIndex()
+}
+
+// existingFiles returns the names of the archive files in the directory by their epochs.
+func (e *Exporter) existingFiles() (map[uint64]string, error) {
+	entries, err := ioutil.ReadDir(e.config.Dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[uint64]string)
+	for _, entry := range entries {
+		if epoch, _, ok := parseFileName(entry.Name()); ok && !entry.IsDir() {
+			files[epoch] = entry.Name()
+		}
+	}
+	return files, nil
+}
+
+func (e *Exporter) exportEpoch(epoch uint64) (string, error) {
+	tmp := filepath.Join(e.config.Dir, fmt.Sprintf("epoch-%d.era.tmp", epoch))
+	f, err := os.Create(tmp)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp)
+
+	buf := bufio.NewWriter(f)
+	w := NewWriter(buf)
+	for number := epoch * e.config.EpochSize; number < (epoch+1)*e.config.EpochSize; number++ {
+		block := e.bc.GetBlockByNumber(number)
+		if block == nil {
+			f.Close()
+			return "", fmt.Errorf("block %d not found", number)
+		}
+		td := e.bc.GetTd(block.Hash(), number)
+		if td == nil {
+			f.Close()
+			return "", fmt.Errorf("total blockscore of block %d not found", number)
+		}
+		if err := w.Add(block, e.bc.GetReceiptsByBlockHash(block.Hash()), td); err != nil {
+			f.Close()
+			return "", err
+		}
+	}
+	root, err := w.Finalize()
+	if err == nil {
+		err = buf.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+	name := FileName(e.bc.Config().ChainID, epoch, root)
+	return name, os.Rename(tmp, filepath.Join(e.config.Dir, name))
+}
+
+// writeIndex rewrites the index file listing the archive files of the directory in the order
+// of their epochs with their SHA-256 checksums.
+func (e *Exporter) writeIndex() error {
+	files, err := e.existingFiles()
+	if err != nil {
+		return err
+	}
+	epochs := make([]uint64, 0, len(files))
+	for epoch := range files {
+		epochs = append(epochs, epoch)
+	}
+	sort.Slice(epochs, func(i, j int) bool { return epochs[i] < epochs[j] })
+
+	tmp := filepath.Join(e.config.Dir, IndexFileName+".tmp")
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	for _, epoch := range epochs {
+		sum, err := checksum(filepath.Join(e.config.Dir, files[epoch]))
+		if err != nil {
+			out.Close()
+			return err
+		}
+		if _, err := fmt.Fprintf(out, "%x %s\n", sum, files[epoch]); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(e.config.Dir, IndexFileName))
+}
+
+func checksum(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return nil, err
+	}
+	return hasher.Sum(nil), nil
+}
@@ -0,0 +1,273 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package chainarchive
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/datasync/snapshot"
+)
+
+// importBatchSize is the number of blocks inserted into the chain at once.
+const importBatchSize = 2500
+
+var (
+	errImportAborted = errors.New("import is aborted")
+	errInvalidIndex  = errors.New("invalid archive index")
+)
+
+// ChainInserter is the interface of the blockchain used by the importer.
+type ChainInserter interface {
+	HasBlock(hash common.Hash, number uint64) bool
+	InsertChain(chain types.Blocks) (int, error)
+}
+
+// indexEntry is an archive file listed in the index file.
+type indexEntry struct {
+	name   string
+	sha256 []byte
+}
+
+// Import inserts the blocks of the archive files listed in the index file of the given location,
+// which is a local directory or the http(s):// or s3:// URL of a remote directory. Each archive
+// file is verified against its checksum and its accumulator before its blocks are inserted, and
+// the batches of blocks already in the chain are skipped, so an interrupted import can be resumed
+// by running it again. The number of the inserted blocks and the number of the last block of each
+// batch are reported, and the import stops before the next batch if quit is closed.
+func Import(chain ChainInserter, location string, quit <-chan struct{}, report func(inserted int, number uint64)) error {
+	remote := isRemote(location)
+	index, err := readIndex(location, remote)
+	if err != nil {
+		return err
+	}
+	for _, entry := range index {
+		select {
+		case <-quit:
+			return errImportAborted
+		default:
+		}
+		start := time.Now()
+		if err := importFile(chain, location, remote, entry, quit, report); err != nil {
+			return fmt.Errorf("%s: %w", entry.name, err)
+		}
+		logger.Info("Imported chain archive", "file", entry.name, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
+
+func isRemote(location string) bool {
+	u, err := url.Parse(location)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https" || u.Scheme == "s3")
+}
+
+// resolve returns the location of the named file in the directory of the given location.
+func resolve(location, name string, remote bool) (string, error) {
+	if path.IsAbs(name) || strings.Contains(name, "..") || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid file name %q", name)
+	}
+	if !remote {
+		return filepath.Join(location, name), nil
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(u.Path, name)
+	return u.String(), nil
+}
+
+func openLocation(location string, remote bool) (io.ReadCloser, error) {
+	if remote {
+		return snapshot.Open(location)
+	}
+	return os.Open(location)
+}
+
+// readIndex reads the entries of the index file of the given location.
+func readIndex(location string, remote bool) ([]indexEntry, error) {
+	indexLocation, err := resolve(location, IndexFileName, remote)
+	if err != nil {
+		return nil, err
+	}
+	body, err := openLocation(indexLocation, remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the archive index: %v", err)
+	}
+	defer body.Close()
+
+	var entries []indexEntry
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%w: %q", errInvalidIndex, line)
+		}
+		sum, err := hex.DecodeString(fields[0])
+		if err != nil || len(sum) != sha256.Size {
+			return nil, fmt.Errorf("%w: invalid checksum %q", errInvalidIndex, fields[0])
+		}
+		if _, _, ok := parseFileName(fields[1]); !ok {
+			return nil, fmt.Errorf("%w: invalid file name %q", errInvalidIndex, fields[1])
+		}
+		entries = append(entries, indexEntry{name: fields[1], sha256: sum})
+	}
+	return entries, scanner.Err()
+}
+
+// fetch returns the path of the local copy of the archive file verified against its checksum,
+// downloading it into a temporary file if it is remote. The returned function removes the
+// temporary file.
+func fetch(location string, remote bool, entry indexEntry) (string, func(), error) {
+	fileLocation, err := resolve(location, entry.name, remote)
+	if err != nil {
+		return "", nil, err
+	}
+	if !remote {
+		sum, err := checksum(fileLocation)
+		if err != nil {
+			return "", nil, err
+		}
+		if !bytes.Equal(sum, entry.sha256) {
+			return "", nil, fmt.Errorf("checksum mismatch: have %x, want %x", sum, entry.sha256)
+		}
+		return fileLocation, func() {}, nil
+	}
+
+	body, err := openLocation(fileLocation, remote)
+	if err != nil {
+		return "", nil, err
+	}
+	defer body.Close()
+
+	out, err := ioutil.TempFile("", "klaytn-chainarchive-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.Remove(out.Name()) }
+	hasher := sha256.New()
+	_, err = io.Copy(io.MultiWriter(out, hasher), body)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	if sum := hasher.Sum(nil); !bytes.Equal(sum, entry.sha256) {
+		cleanup()
+		return "", nil, fmt.Errorf("checksum mismatch: have %x, want %x", sum, entry.sha256)
+	}
+	return out.Name(), cleanup, nil
+}
+
+func importFile(chain ChainInserter, location string, remote bool, entry indexEntry, quit <-chan struct{}, report func(inserted int, number uint64)) error {
+	file, cleanup, err := fetch(location, remote, entry)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	reader, err := NewReader(f, stat.Size())
+	if err != nil {
+		return err
+	}
+	// The accumulator root should match the file name, which is committed to by the publisher
+	if _, prefix, _ := parseFileName(entry.name); hex.EncodeToString(reader.Accumulator().Bytes()[:4]) != prefix {
+		return fmt.Errorf("accumulator mismatch: have %x, want %s", reader.Accumulator(), prefix)
+	}
+	if err := reader.Verify(); err != nil {
+		return err
+	}
+
+	blocks := make(types.Blocks, 0, importBatchSize)
+	flush := func() error {
+		if len(blocks) == 0 {
+			return nil
+		}
+		inserted := 0
+		if !hasAllBlocks(chain, blocks) {
+			if _, err := chain.InsertChain(blocks); err != nil {
+				return fmt.Errorf("failed to insert: %v", err)
+			}
+			inserted = len(blocks)
+		}
+		if report != nil {
+			report(inserted, blocks[len(blocks)-1].NumberU64())
+		}
+		blocks = blocks[:0]
+		return nil
+	}
+	for number := reader.Start(); number < reader.Start()+reader.Count(); number++ {
+		// The genesis block is not imported since it is committed at the initialization
+		if number == 0 {
+			continue
+		}
+		block, _, _, err := reader.Block(number)
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, block)
+		if len(blocks) == importBatchSize {
+			select {
+			case <-quit:
+				return errImportAborted
+			default:
+			}
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+func hasAllBlocks(chain ChainInserter, blocks types.Blocks) bool {
+	for _, block := range blocks {
+		if !chain.HasBlock(block.Hash(), block.NumberU64()) {
+			return false
+		}
+	}
+	return true
+}
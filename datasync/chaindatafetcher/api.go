@@ -18,6 +18,8 @@ package chaindatafetcher
 
 import (
 	"errors"
+	"fmt"
+	"math"
 	"sync/atomic"
 
 	"github.com/klaytn/klaytn/datasync/chaindatafetcher/types"
@@ -51,18 +53,110 @@ func (api *PublicChainDataFetcherAPI) Status() string {
 	return api.f.status()
 }
 
+// ReadCheckpoint returns the checkpoint of the sink of the primary mode.
 func (api *PublicChainDataFetcherAPI) ReadCheckpoint() (int64, error) {
-	return api.f.checkpointDB.ReadCheckpoint()
+	return api.f.sinks[0].checkpointDB.ReadCheckpoint()
 }
 
+// WriteCheckpoint writes the checkpoint of the sink of the primary mode.
 func (api *PublicChainDataFetcherAPI) WriteCheckpoint(checkpoint int64) error {
+	if err := api.checkFetchingStopped(); err != nil {
+		return err
+	}
+	return api.f.sinks[0].writeCheckpoint(checkpoint)
+}
+
+// GetCheckpoints returns the checkpoints of all the sinks by their modes.
+func (api *PublicChainDataFetcherAPI) GetCheckpoints() map[string]int64 {
+	checkpoints := make(map[string]int64, len(api.f.sinks))
+	for _, s := range api.f.sinks {
+		checkpoints[s.mode.String()] = s.getCheckpoint()
+	}
+	return checkpoints
+}
+
+// ReadSinkCheckpoint returns the checkpoint of the sink of the given mode.
+func (api *PublicChainDataFetcherAPI) ReadSinkCheckpoint(mode string) (int64, error) {
+	s, err := api.sink(mode)
+	if err != nil {
+		return 0, err
+	}
+	return s.checkpointDB.ReadCheckpoint()
+}
+
+// WriteSinkCheckpoint writes the checkpoint of the sink of the given mode.
+// The blocks from the checkpoint are handled by the sink when fetching is started.
+func (api *PublicChainDataFetcherAPI) WriteSinkCheckpoint(mode string, checkpoint int64) error {
+	if err := api.checkFetchingStopped(); err != nil {
+		return err
+	}
+	s, err := api.sink(mode)
+	if err != nil {
+		return err
+	}
+	return s.writeCheckpoint(checkpoint)
+}
+
+// RewindSinkCheckpoint moves the checkpoint of the sink of the given mode back by the given number of blocks
+// and returns the new checkpoint. The checkpoint is not rewound below zero.
+func (api *PublicChainDataFetcherAPI) RewindSinkCheckpoint(mode string, blocks uint64) (int64, error) {
+	if err := api.checkFetchingStopped(); err != nil {
+		return 0, err
+	}
+	s, err := api.sink(mode)
+	if err != nil {
+		return 0, err
+	}
+	checkpoint := s.getCheckpoint() - int64(blocks)
+	if blocks > math.MaxInt64 || checkpoint < 0 {
+		checkpoint = 0
+	}
+	return checkpoint, s.writeCheckpoint(checkpoint)
+}
+
+// StartReexport exports the blocks from startBlock to endBlock (inclusive) to the sink of the given mode again,
+// e.g., after the data is lost in the downstream. The checkpoint of the sink is not changed.
+// Only the given datasets are exported if they are specified, otherwise all the datasets of the sink are exported.
+func (api *PublicChainDataFetcherAPI) StartReexport(mode string, startBlock, endBlock uint64, datasets *[]string) error {
+	m, err := ParseChainDataFetcherMode(mode)
+	if err != nil {
+		return err
+	}
+	var names []string
+	if datasets != nil {
+		names = *datasets
+	}
+	return api.f.startReexport(m, startBlock, endBlock, names)
+}
+
+// StopReexport stops the running re-export.
+func (api *PublicChainDataFetcherAPI) StopReexport() error {
+	return api.f.stopReexport()
+}
+
+// ReexportStatus returns the progress of the last re-export. It returns nil if no re-export has been started.
+func (api *PublicChainDataFetcherAPI) ReexportStatus() *ReexportStatus {
+	return api.f.reexportStatus()
+}
+
+func (api *PublicChainDataFetcherAPI) checkFetchingStopped() error {
 	isRunning := atomic.LoadUint32(&api.f.fetchingStarted)
 	if isRunning == running {
 		return errors.New("call stopFetching before writing checkpoint manually")
 	}
+	return nil
+}
 
-	api.f.checkpoint = checkpoint
-	return api.f.checkpointDB.WriteCheckpoint(checkpoint)
+func (api *PublicChainDataFetcherAPI) sink(mode string) (*sink, error) {
+	m, err := ParseChainDataFetcherMode(mode)
+	if err != nil {
+		return nil, err
+	}
+	s := api.f.sinkOf(m)
+	if s == nil {
+		return nil, fmt.Errorf("no sink of the chaindatafetcher mode: %v", m)
+	}
+	return s, nil
 }
 
 // GetConfig returns the configuration setting of the launched chaindata fetcher.
@@ -30,6 +30,8 @@ import (
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/datasync/chaindatafetcher/kafka"
 	"github.com/klaytn/klaytn/datasync/chaindatafetcher/kas"
+	"github.com/klaytn/klaytn/datasync/chaindatafetcher/nats"
+	"github.com/klaytn/klaytn/datasync/chaindatafetcher/pubsub"
 	cfTypes "github.com/klaytn/klaytn/datasync/chaindatafetcher/types"
 	"github.com/klaytn/klaytn/event"
 	"github.com/klaytn/klaytn/log"
@@ -47,6 +49,8 @@ const (
 
 var logger = log.NewModuleLogger(log.ChainDataFetcher)
 var errUnsupportedMode = errors.New("the given chaindatafetcher mode is not supported")
+var errDuplicatedMode = errors.New("the given chaindatafetcher mode is duplicated")
+var errNoDataset = errors.New("no dataset is selected for the chaindatafetcher sink")
 var errMaxRetryExceeded = errors.New("the number of retries is exceeded over max")
 
 //go:generate mockgen -destination=./mocks/blockchain_mock.go -package=mocks github.com/klaytn/klaytn/datasync/chaindatafetcher BlockChain
@@ -71,15 +75,10 @@ type ChainDataFetcher struct {
 
 	numHandlers int
 
-	checkpointMu  sync.RWMutex
-	checkpoint    int64
-	checkpointMap map[int64]struct{}
-
 	wg sync.WaitGroup
 
-	repo         Repository
-	checkpointDB CheckpointDB
-	setters      []ComponentSetter
+	sinks   []*sink // the first sink is the one of config.Mode
+	setters []ComponentSetter
 
 	fetchingStarted      uint32
 	fetchingStopCh       chan struct{}
@@ -87,40 +86,79 @@ type ChainDataFetcher struct {
 	rangeFetchingStarted uint32
 	rangeFetchingStopCh  chan struct{}
 	rangeFetchingWg      sync.WaitGroup
+
+	reexportMu sync.Mutex
+	reexport   *reexportJob // the last re-export job
 }
 
 func NewChainDataFetcher(ctx *node.ServiceContext, cfg *ChainDataFetcherConfig) (*ChainDataFetcher, error) {
+	selected, err := cfTypes.ParseRequestTypes(cfg.Datasets)
+	if err != nil {
+		return nil, err
+	}
+
 	var (
-		repo         Repository
-		checkpointDB CheckpointDB
-		setters      []ComponentSetter
-		err          error
+		sinks   []*sink
+		setters []ComponentSetter
+		modes   = make(map[ChainDataFetcherMode]struct{})
 	)
-	switch cfg.Mode {
-	case ModeKAS:
-		repo, checkpointDB, setters, err = getKasComponents(cfg.KasConfig)
-		if err != nil {
-			return nil, err
+	for i, mode := range cfg.Modes() {
+		if _, exist := modes[mode]; exist {
+			logger.Error("the chaindatafetcher mode is duplicated", "mode", mode)
+			return nil, errDuplicatedMode
 		}
-	case ModeKafka:
-		repo, checkpointDB, setters, err = getKafkaComponents(cfg.KafkaConfig)
-		if err != nil {
-			return nil, err
+		modes[mode] = struct{}{}
+
+		reqType := mode.supportedRequestTypes()
+		if len(cfg.Datasets) > 0 {
+			reqType &= selected
 		}
-	default:
-		logger.Error("the chaindatafetcher mode is not supported", "mode", cfg.Mode)
-		return nil, errUnsupportedMode
+		if reqType == 0 {
+			logger.Error("no dataset is selected for the sink", "mode", mode, "datasets", cfg.Datasets)
+			return nil, errNoDataset
+		}
+
+		var (
+			repo          Repository
+			checkpointDB  CheckpointDB
+			sinkSetters   []ComponentSetter
+			componentsErr error
+		)
+		switch mode {
+		case ModeKAS:
+			repo, checkpointDB, sinkSetters, componentsErr = getKasComponents(cfg.KasConfig)
+		case ModeKafka:
+			repo, checkpointDB, sinkSetters, componentsErr = getKafkaComponents(cfg.KafkaConfig)
+		case ModeNATS:
+			repo, checkpointDB, sinkSetters, componentsErr = getNATSComponents(cfg.NATSConfig)
+		case ModePubSub:
+			repo, checkpointDB, sinkSetters, componentsErr = getPubSubComponents(cfg.PubSubConfig)
+		default:
+			logger.Error("the chaindatafetcher mode is not supported", "mode", mode)
+			return nil, errUnsupportedMode
+		}
+		if componentsErr != nil {
+			return nil, componentsErr
+		}
+
+		// the first sink keeps the original metric name
+		gauge := checkpointGauge
+		if i > 0 {
+			gauge = metrics.GetOrRegisterGauge("chaindatafetcher/checkpoint/"+mode.String()+"/gauge", nil)
+		}
+		sinks = append(sinks, newSink(mode, reqType, repo, checkpointDB, gauge))
+		setters = append(setters, sinkSetters...)
+		logger.Info("chaindatafetcher sink is configured", "mode", mode, "reqType", reqType)
 	}
+
 	return &ChainDataFetcher{
-		config:        cfg,
-		chainCh:       make(chan blockchain.ChainEvent, cfg.BlockChannelSize),
-		reqCh:         make(chan *cfTypes.Request, cfg.JobChannelSize),
-		stopCh:        make(chan struct{}),
-		numHandlers:   cfg.NumHandlers,
-		checkpointMap: make(map[int64]struct{}),
-		repo:          repo,
-		checkpointDB:  checkpointDB,
-		setters:       setters,
+		config:      cfg,
+		chainCh:     make(chan blockchain.ChainEvent, cfg.BlockChannelSize),
+		reqCh:       make(chan *cfTypes.Request, cfg.JobChannelSize),
+		stopCh:      make(chan struct{}),
+		numHandlers: cfg.NumHandlers,
+		sinks:       sinks,
+		setters:     setters,
 	}, nil
 }
 
@@ -141,6 +179,57 @@ func getKafkaComponents(cfg *kafka.KafkaConfig) (Repository, CheckpointDB, []Com
 	return repo, checkpointDB, []ComponentSetter{repo, checkpointDB}, nil
 }
 
+func getNATSComponents(cfg *nats.NATSConfig) (Repository, CheckpointDB, []ComponentSetter, error) {
+	repo, err := nats.NewRepository(cfg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	checkpointDB := newSinkCheckpointDB(ModeNATS.String())
+	return repo, checkpointDB, []ComponentSetter{repo, checkpointDB}, nil
+}
+
+func getPubSubComponents(cfg *pubsub.PubSubConfig) (Repository, CheckpointDB, []ComponentSetter, error) {
+	repo, err := pubsub.NewRepository(cfg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	checkpointDB := newSinkCheckpointDB(ModePubSub.String())
+	return repo, checkpointDB, []ComponentSetter{repo, checkpointDB}, nil
+}
+
+// requestTypes returns the request types handled by any of the sinks.
+func (f *ChainDataFetcher) requestTypes() cfTypes.RequestType {
+	var reqType cfTypes.RequestType
+	for _, s := range f.sinks {
+		reqType |= s.reqType
+	}
+	return reqType
+}
+
+// minCheckpoint returns the lowest checkpoint among the sinks, from which the fetching starts.
+// sinkOf returns the sink of the given mode. It returns nil if there is no sink of the mode.
+func (f *ChainDataFetcher) sinkOf(mode ChainDataFetcherMode) *sink {
+	for _, s := range f.sinks {
+		if s.mode == mode {
+			return s
+		}
+	}
+	return nil
+}
+
+func (f *ChainDataFetcher) minCheckpoint() int64 {
+	var checkpoint int64 = -1
+	for _, s := range f.sinks {
+		if c := s.getCheckpoint(); checkpoint < 0 || c < checkpoint {
+			checkpoint = c
+		}
+	}
+	if checkpoint < 0 {
+		return 0
+	}
+	return checkpoint
+}
+
 func (f *ChainDataFetcher) Protocols() []p2p.Protocol {
 	return []p2p.Protocol{}
 }
@@ -175,6 +264,7 @@ func (f *ChainDataFetcher) Start(server p2p.Server) error {
 func (f *ChainDataFetcher) Stop() error {
 	f.stopFetching()
 	f.stopRangeFetching()
+	f.stopReexport()
 	logger.Info("wait for all goroutines to be terminated...", "numGoroutines", f.config.NumHandlers)
 	close(f.stopCh)
 	f.wg.Wait()
@@ -202,23 +292,16 @@ func (f *ChainDataFetcher) startFetching() error {
 
 	// subscribe chain event in order to handle new blocks.
 	f.chainSub = f.blockchain.SubscribeChainEvent(f.chainCh)
-	checkpoint := uint64(f.checkpoint)
+	checkpoint := uint64(f.minCheckpoint())
 	currentBlock := f.blockchain.CurrentHeader().Number.Uint64()
 
 	f.fetchingStopCh = make(chan struct{})
 	f.fetchingWg.Add(1)
 
-	// lanuch a goroutine to handle from checkpoint to the head block.
+	// lanuch a goroutine to handle from the lowest checkpoint of the sinks to the head block.
 	go func() {
 		defer f.fetchingWg.Done()
-		switch f.config.Mode {
-		case ModeKAS:
-			f.sendRequests(uint64(f.checkpoint), currentBlock, cfTypes.RequestTypeAll, true, f.fetchingStopCh)
-		case ModeKafka:
-			f.sendRequests(uint64(f.checkpoint), currentBlock, cfTypes.RequestTypeGroupAll, true, f.fetchingStopCh)
-		default:
-			logger.Error("the chaindatafetcher mode is not supported", "mode", f.config.Mode, "checkpoint", f.checkpoint, "currentBlock", currentBlock)
-		}
+		f.sendRequests(checkpoint, currentBlock, f.requestTypes(), true, f.fetchingStopCh)
 	}()
 	logger.Info("fetching is started", "startedCheckpoint", checkpoint, "currentBlock", currentBlock)
 	return nil
@@ -322,16 +405,10 @@ func (f *ChainDataFetcher) setDebugAPI(apis []rpc.API) {
 }
 
 func (f *ChainDataFetcher) setCheckpoint() {
-	checkpoint, err := f.checkpointDB.ReadCheckpoint()
-	if err != nil {
-		logger.Crit("ReadCheckpoint is failed", "err", err)
-	}
-
-	if checkpoint == 0 {
-		checkpoint = f.blockchain.CurrentHeader().Number.Int64()
+	currentBlock := f.blockchain.CurrentHeader().Number.Int64()
+	for _, s := range f.sinks {
+		s.setCheckpoint(currentBlock)
 	}
-	f.checkpoint = checkpoint
-	logger.Info("Chaindatafetcher initial checkpoint is set", "checkpoint", f.checkpoint)
 }
 
 func (f *ChainDataFetcher) setComponent(component interface{}) {
@@ -353,8 +430,30 @@ func (f *ChainDataFetcher) SetComponents(components []interface{}) {
 	f.setCheckpoint()
 }
 
+// handleRequestByType loads the data of the request types to the sinks. Each sink handles only the types
+// it is configured for, and a block already covered by the checkpoint of a sink is skipped for the sink
+// when the checkpoint should be updated. A failure of a sink does not prevent the others from handling the block.
 func (f *ChainDataFetcher) handleRequestByType(reqType cfTypes.RequestType, shouldUpdateCheckpoint bool, ev blockchain.ChainEvent) error {
 	now := time.Now()
+	var firstErr error
+	for _, s := range f.sinks {
+		if shouldUpdateCheckpoint && ev.Block.Number().Int64() < s.getCheckpoint() {
+			continue
+		}
+		if err := f.handleSinkRequest(s, reqType&s.reqType, shouldUpdateCheckpoint, ev); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+	elapsed := time.Since(now)
+	totalInsertionTimeGauge.Update(elapsed.Milliseconds())
+	handledBlockNumberGauge.Update(ev.Block.Number().Int64())
+	return nil
+}
+
+func (f *ChainDataFetcher) handleSinkRequest(s *sink, reqType cfTypes.RequestType, shouldUpdateCheckpoint bool, ev blockchain.ChainEvent) error {
 	// TODO-ChainDataFetcher parallelize handling data
 
 	// iterate over all types of requests
@@ -366,19 +465,18 @@ func (f *ChainDataFetcher) handleRequestByType(reqType cfTypes.RequestType, shou
 	// - RequestTypeTraceGroup
 	for targetType := cfTypes.RequestTypeTransaction; targetType < cfTypes.RequestTypeLength; targetType = targetType << 1 {
 		if cfTypes.CheckRequestType(reqType, targetType) {
-			if err := f.updateInsertionTimeGauge(f.retryFunc(f.repo.HandleChainEvent))(ev, targetType); err != nil {
-				logger.Error("handling chain event is failed", "blockNumber", ev.Block.NumberU64(), "err", err, "reqType", reqType, "targetType", targetType)
+			if err := f.updateInsertionTimeGauge(f.retryFunc(s.repo.HandleChainEvent))(ev, targetType); err != nil {
+				logger.Error("handling chain event is failed", "mode", s.mode, "blockNumber", ev.Block.NumberU64(), "err", err, "reqType", reqType, "targetType", targetType)
 				return err
 			}
 		}
 	}
-	elapsed := time.Since(now)
-	totalInsertionTimeGauge.Update(elapsed.Milliseconds())
 
 	if shouldUpdateCheckpoint {
-		f.updateCheckpoint(ev.Block.Number().Int64())
+		if err := s.updateCheckpoint(ev.Block.Number().Int64()); err != nil {
+			logger.Error("updating checkpoint is failed", "mode", s.mode, "blockNumber", ev.Block.NumberU64(), "err", err)
+		}
 	}
-	handledBlockNumberGauge.Update(ev.Block.Number().Int64())
 	return nil
 }
 
@@ -419,15 +517,7 @@ func (f *ChainDataFetcher) handleRequest() {
 			return
 		case ev := <-f.chainCh:
 			numChainEventGauge.Update(int64(len(f.chainCh)))
-			var err error
-			switch f.config.Mode {
-			case ModeKAS:
-				err = f.handleRequestByType(cfTypes.RequestTypeAll, true, ev)
-			case ModeKafka:
-				err = f.handleRequestByType(cfTypes.RequestTypeGroupAll, true, ev)
-			default:
-				logger.Error("the chaindatafetcher mode is not supported", "mode", f.config.Mode, "blockNumber", ev.Block.NumberU64())
-			}
+			err := f.handleRequestByType(f.requestTypes(), true, ev)
 
 			if err != nil && err == errMaxRetryExceeded {
 				logger.Error("the chaindatafetcher reaches the maximum retries. it pauses fetching and clear the channels", "blockNum", ev.Block.NumberU64())
@@ -450,30 +540,6 @@ func (f *ChainDataFetcher) handleRequest() {
 	}
 }
 
-func (f *ChainDataFetcher) updateCheckpoint(num int64) error {
-	f.checkpointMu.Lock()
-	defer f.checkpointMu.Unlock()
-	f.checkpointMap[num] = struct{}{}
-
-	updated := false
-	newCheckpoint := f.checkpoint
-	for {
-		if _, ok := f.checkpointMap[newCheckpoint]; !ok {
-			break
-		}
-		delete(f.checkpointMap, newCheckpoint)
-		newCheckpoint++
-		updated = true
-	}
-
-	if updated {
-		f.checkpoint = newCheckpoint
-		checkpointGauge.Update(f.checkpoint)
-		return f.checkpointDB.WriteCheckpoint(newCheckpoint)
-	}
-	return nil
-}
-
 func getInsertionTimeGauge(reqType cfTypes.RequestType) metrics.Gauge {
 	switch reqType {
 	case cfTypes.RequestTypeTransaction:
@@ -30,19 +30,22 @@ import (
 	"github.com/klaytn/klaytn/datasync/chaindatafetcher/mocks"
 	cfTypes "github.com/klaytn/klaytn/datasync/chaindatafetcher/types"
 	eventMocks "github.com/klaytn/klaytn/event/mocks"
+	"github.com/rcrowley/go-metrics"
 	"github.com/stretchr/testify/assert"
 )
 
+func newTestSink(repo Repository, checkpointDB CheckpointDB) *sink {
+	return newSink(ModeKAS, cfTypes.RequestTypeAll, repo, checkpointDB, metrics.NilGauge{})
+}
+
 func newTestChainDataFetcher() *ChainDataFetcher {
 	return &ChainDataFetcher{
-		config:        DefaultChainDataFetcherConfig,
-		chainCh:       make(chan blockchain.ChainEvent),
-		reqCh:         make(chan *cfTypes.Request),
-		stopCh:        make(chan struct{}),
-		numHandlers:   3,
-		checkpoint:    0,
-		checkpointMap: make(map[int64]struct{}),
-		repo:          nil,
+		config:      DefaultChainDataFetcherConfig,
+		chainCh:     make(chan blockchain.ChainEvent),
+		reqCh:       make(chan *cfTypes.Request),
+		stopCh:      make(chan struct{}),
+		numHandlers: 3,
+		sinks:       []*sink{newTestSink(nil, nil)},
 	}
 }
 
@@ -154,11 +157,7 @@ func TestChainDataFetcher_updateCheckpoint(t *testing.T) {
 
 	m := mocks.NewMockCheckpointDB(ctrl)
 
-	fetcher := &ChainDataFetcher{
-		checkpoint:    0,
-		checkpointMap: make(map[int64]struct{}),
-		checkpointDB:  m,
-	}
+	fetcher := newTestSink(nil, m)
 
 	// update checkpoint as follows.
 	// done order: 1, 0, 2, 3, 5, 7, 9, 8, 4, 6, 10
@@ -213,10 +212,8 @@ func TestChainDataFetcher_retryFunc(t *testing.T) {
 
 func TestChainDataFetcher_handleRequestByType_WhileRetrying(t *testing.T) {
 	fetcher := &ChainDataFetcher{
-		config:        &ChainDataFetcherConfig{NumHandlers: 1}, // prevent panic with nil reference
-		checkpoint:    1,
-		checkpointMap: make(map[int64]struct{}), // in order to call CheckpointDB WriteCheckpoint method
-		stopCh:        make(chan struct{}),      // in order to stop retrying
+		config: &ChainDataFetcherConfig{NumHandlers: 1}, // prevent panic with nil reference
+		stopCh: make(chan struct{}),                     // in order to stop retrying
 	}
 	header1 := &types.Header{Number: big.NewInt(1)} // next block to be handled is 1
 	block1 := blockchain.ChainEvent{Block: types.NewBlockWithHeader(header1)}
@@ -245,12 +242,14 @@ func TestChainDataFetcher_handleRequestByType_WhileRetrying(t *testing.T) {
 		fetcher.Stop()
 	}()
 
-	fetcher.repo, fetcher.checkpointDB = mockRepo, checkpointDB
+	s := newTestSink(mockRepo, checkpointDB)
+	s.checkpoint = 1
+	fetcher.sinks = []*sink{s}
 	fetcher.handleRequestByType(cfTypes.RequestTypeAll, true, block1)
 	fetcher.handleRequestByType(cfTypes.RequestTypeAll, true, block2)
 
 	wg.Wait()
-	assert.Equal(t, int64(2), fetcher.checkpoint)
+	assert.Equal(t, int64(2), s.checkpoint)
 }
 
 func TestChainDataFetcher_setComponents(t *testing.T) {
@@ -260,8 +259,8 @@ func TestChainDataFetcher_setComponents(t *testing.T) {
 	bc, db := mocks.NewMockBlockChain(ctrl), mocks.NewMockCheckpointDB(ctrl)
 
 	fetcher := &ChainDataFetcher{
-		blockchain:   bc,
-		checkpointDB: db,
+		blockchain: bc,
+		sinks:      []*sink{newTestSink(nil, db)},
 	}
 
 	// if checkpoint no exist
@@ -270,11 +269,152 @@ func TestChainDataFetcher_setComponents(t *testing.T) {
 	testHeader := &types.Header{Number: testBlockNumber}
 	bc.EXPECT().CurrentHeader().Return(testHeader).Times(1)
 	fetcher.setCheckpoint()
-	assert.Equal(t, testBlockNumber.Int64(), fetcher.checkpoint)
+	assert.Equal(t, testBlockNumber.Int64(), fetcher.sinks[0].checkpoint)
 
 	// if checkpoint exist
 	testCheckpoint := int64(10)
 	db.EXPECT().ReadCheckpoint().Return(int64(10), nil).Times(1)
+	bc.EXPECT().CurrentHeader().Return(testHeader).Times(1)
 	fetcher.setCheckpoint()
-	assert.Equal(t, testCheckpoint, fetcher.checkpoint)
+	assert.Equal(t, testCheckpoint, fetcher.sinks[0].checkpoint)
+}
+
+func TestChainDataFetcher_handleRequestByType_MultipleSinks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	kasRepo, kasCheckpointDB := mocks.NewMockRepository(ctrl), mocks.NewMockCheckpointDB(ctrl)
+	natsRepo, natsCheckpointDB := mocks.NewMockRepository(ctrl), mocks.NewMockCheckpointDB(ctrl)
+
+	// the kas sink only loads transactions and is ahead of the nats sink
+	kasSink := newSink(ModeKAS, cfTypes.RequestTypeTransaction, kasRepo, kasCheckpointDB, metrics.NilGauge{})
+	kasSink.checkpoint = 2
+	natsSink := newSink(ModeNATS, cfTypes.RequestTypeBlockGroup, natsRepo, natsCheckpointDB, metrics.NilGauge{})
+	natsSink.checkpoint = 1
+
+	fetcher := &ChainDataFetcher{
+		config: &ChainDataFetcherConfig{NumHandlers: 1},
+		stopCh: make(chan struct{}),
+		sinks:  []*sink{kasSink, natsSink},
+	}
+	assert.Equal(t, cfTypes.RequestTypeTransaction|cfTypes.RequestTypeBlockGroup, fetcher.requestTypes())
+	assert.Equal(t, int64(1), fetcher.minCheckpoint())
+
+	block1 := blockchain.ChainEvent{Block: types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})}
+	block2 := blockchain.ChainEvent{Block: types.NewBlockWithHeader(&types.Header{Number: big.NewInt(2)})}
+
+	// block 1 is handled only by the nats sink
+	natsRepo.EXPECT().HandleChainEvent(gomock.Any(), gomock.Eq(cfTypes.RequestTypeBlockGroup)).Return(nil).Times(2)
+	natsCheckpointDB.EXPECT().WriteCheckpoint(gomock.Eq(int64(2))).Return(nil).Times(1)
+	assert.NoError(t, fetcher.handleRequestByType(fetcher.requestTypes(), true, block1))
+
+	// block 2 is handled by both sinks with their own types
+	kasRepo.EXPECT().HandleChainEvent(gomock.Any(), gomock.Eq(cfTypes.RequestTypeTransaction)).Return(nil).Times(1)
+	kasCheckpointDB.EXPECT().WriteCheckpoint(gomock.Eq(int64(3))).Return(nil).Times(1)
+	natsCheckpointDB.EXPECT().WriteCheckpoint(gomock.Eq(int64(3))).Return(nil).Times(1)
+	assert.NoError(t, fetcher.handleRequestByType(fetcher.requestTypes(), true, block2))
+
+	assert.Equal(t, int64(3), kasSink.checkpoint)
+	assert.Equal(t, int64(3), natsSink.checkpoint)
+}
+
+func TestChainDataFetcher_Reexport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bc := mocks.NewMockBlockChain(ctrl)
+	kasRepo, natsRepo := mocks.NewMockRepository(ctrl), mocks.NewMockRepository(ctrl)
+	natsCheckpointDB := mocks.NewMockCheckpointDB(ctrl)
+
+	stopCh := make(chan struct{})
+	close(stopCh) // prevent retrying the failed block
+	fetcher := &ChainDataFetcher{
+		config:     &ChainDataFetcherConfig{NumHandlers: 1},
+		blockchain: bc,
+		stopCh:     stopCh,
+		sinks: []*sink{
+			newSink(ModeKAS, cfTypes.RequestTypeAll, kasRepo, mocks.NewMockCheckpointDB(ctrl), metrics.NilGauge{}),
+			newSink(ModeNATS, cfTypes.RequestTypeGroupAll, natsRepo, natsCheckpointDB, metrics.NilGauge{}),
+		},
+	}
+
+	blocks := make([]*types.Block, 4)
+	for i := range blocks {
+		blocks[i] = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(int64(i))})
+		bc.EXPECT().GetBlockByNumber(gomock.Eq(uint64(i))).Return(blocks[i]).AnyTimes()
+		bc.EXPECT().GetReceiptsByBlockHash(gomock.Eq(blocks[i].Hash())).Return(types.Receipts{}).AnyTimes()
+	}
+	bc.EXPECT().CurrentHeader().Return(blocks[3].Header()).AnyTimes()
+
+	assert.Error(t, fetcher.startReexport(ModePubSub, 1, 3, nil))
+	assert.Error(t, fetcher.startReexport(ModeNATS, 1, 4, nil))
+	assert.Error(t, fetcher.startReexport(ModeNATS, 1, 3, []string{"transaction"}))
+	assert.Nil(t, fetcher.reexportStatus())
+
+	// only the block group of the nats sink is exported and block 3 fails.
+	natsRepo.EXPECT().HandleChainEvent(gomock.Any(), gomock.Eq(cfTypes.RequestTypeBlockGroup)).Return(nil).Times(2)
+	natsRepo.EXPECT().HandleChainEvent(gomock.Any(), gomock.Eq(cfTypes.RequestTypeBlockGroup)).Return(errors.New("test")).Times(1)
+	assert.NoError(t, fetcher.startReexport(ModeNATS, 1, 3, []string{"blockgroup"}))
+	<-fetcher.reexport.doneCh
+
+	status := fetcher.reexportStatus()
+	assert.Equal(t, "nats", status.Mode)
+	assert.Equal(t, []string{"blockgroup"}, status.Datasets)
+	assert.Equal(t, ReexportStateFailed, status.State)
+	assert.Equal(t, uint64(2), status.Exported)
+	assert.Equal(t, uint64(3), status.NextBlock)
+	assert.True(t, strings.Contains(status.Error, "block 3"))
+	assert.NotNil(t, status.FinishedAt)
+	assert.Equal(t, errReexportNotRunning, fetcher.stopReexport())
+
+	// resume from the failed block
+	natsRepo.EXPECT().HandleChainEvent(gomock.Any(), gomock.Eq(cfTypes.RequestTypeBlockGroup)).Return(nil).Times(1)
+	assert.NoError(t, fetcher.startReexport(ModeNATS, status.NextBlock, 3, []string{"blockgroup"}))
+	<-fetcher.reexport.doneCh
+	assert.Equal(t, ReexportStateFinished, fetcher.reexportStatus().State)
+}
+
+func TestPublicChainDataFetcherAPI_SinkCheckpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	kasCheckpointDB, natsCheckpointDB := mocks.NewMockCheckpointDB(ctrl), mocks.NewMockCheckpointDB(ctrl)
+	kasSink := newSink(ModeKAS, cfTypes.RequestTypeAll, nil, kasCheckpointDB, metrics.NilGauge{})
+	kasSink.checkpoint = 10
+	natsSink := newSink(ModeNATS, cfTypes.RequestTypeGroupAll, nil, natsCheckpointDB, metrics.NilGauge{})
+	natsSink.checkpoint = 5
+	natsSink.checkpointMap[7] = struct{}{}
+
+	api := NewPublicChainDataFetcherAPI(&ChainDataFetcher{sinks: []*sink{kasSink, natsSink}})
+	assert.Equal(t, map[string]int64{"kas": 10, "nats": 5}, api.GetCheckpoints())
+
+	natsCheckpointDB.EXPECT().ReadCheckpoint().Return(int64(5), nil).Times(1)
+	checkpoint, err := api.ReadSinkCheckpoint("nats")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), checkpoint)
+
+	_, err = api.ReadSinkCheckpoint("pubsub")
+	assert.Error(t, err)
+
+	natsCheckpointDB.EXPECT().WriteCheckpoint(gomock.Eq(int64(3))).Return(nil).Times(1)
+	checkpoint, err = api.RewindSinkCheckpoint("nats", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), checkpoint)
+	assert.Equal(t, int64(3), natsSink.getCheckpoint())
+	assert.Empty(t, natsSink.checkpointMap)
+
+	kasCheckpointDB.EXPECT().WriteCheckpoint(gomock.Eq(int64(0))).Return(nil).Times(1)
+	checkpoint, err = api.RewindSinkCheckpoint("kas", 100)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), checkpoint)
+
+	kasCheckpointDB.EXPECT().WriteCheckpoint(gomock.Eq(int64(20))).Return(nil).Times(1)
+	assert.NoError(t, api.WriteSinkCheckpoint("kas", 20))
+	assert.Equal(t, int64(20), kasSink.getCheckpoint())
+
+	// the checkpoint cannot be written while fetching
+	api.f.fetchingStarted = running
+	assert.Error(t, api.WriteSinkCheckpoint("kas", 30))
+	_, err = api.RewindSinkCheckpoint("kas", 1)
+	assert.Error(t, err)
 }
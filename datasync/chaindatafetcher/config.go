@@ -17,17 +17,59 @@
 package chaindatafetcher
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/klaytn/klaytn/datasync/chaindatafetcher/kafka"
 	"github.com/klaytn/klaytn/datasync/chaindatafetcher/kas"
+	"github.com/klaytn/klaytn/datasync/chaindatafetcher/nats"
+	"github.com/klaytn/klaytn/datasync/chaindatafetcher/pubsub"
+	"github.com/klaytn/klaytn/datasync/chaindatafetcher/types"
 )
 
+// ChainDataFetcherMode is the kind of a sink where the chaindata is loaded.
 type ChainDataFetcherMode int
 
 const (
 	ModeKAS = ChainDataFetcherMode(iota)
 	ModeKafka
+	ModeNATS
+	ModePubSub
 )
 
+var modeNames = map[ChainDataFetcherMode]string{
+	ModeKAS:    "kas",
+	ModeKafka:  "kafka",
+	ModeNATS:   "nats",
+	ModePubSub: "pubsub",
+}
+
+func (m ChainDataFetcherMode) String() string {
+	if name, ok := modeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", int(m))
+}
+
+// ParseChainDataFetcherMode returns the mode of the given name. ("kas", "kafka", "nats", "pubsub")
+func ParseChainDataFetcherMode(name string) (ChainDataFetcherMode, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for mode, modeName := range modeNames {
+		if modeName == name {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported chaindatafetcher mode: %q", name)
+}
+
+// supportedRequestTypes returns the request types which the sink of the mode can handle.
+func (m ChainDataFetcherMode) supportedRequestTypes() types.RequestType {
+	if m == ModeKAS {
+		return types.RequestTypeAll
+	}
+	return types.RequestTypeGroupAll
+}
+
 const (
 	DefaultNumHandlers      = 10
 	DefaultJobChannelSize   = 50
@@ -39,13 +81,17 @@ const (
 type ChainDataFetcherConfig struct {
 	EnabledChainDataFetcher bool
 	Mode                    ChainDataFetcherMode
+	AdditionalModes         []ChainDataFetcherMode // AdditionalModes are the sinks loading the chaindata alongside the one of Mode.
+	Datasets                []string               // Datasets are the names of the data types to load. All supported types of a sink are loaded if empty.
 	NoDefaultStart          bool
 	NumHandlers             int
 	JobChannelSize          int
 	BlockChannelSize        int
 
-	KasConfig   *kas.KASConfig `json:"-"` // Deprecated: This configuration is not used anymore.
-	KafkaConfig *kafka.KafkaConfig
+	KasConfig    *kas.KASConfig `json:"-"` // Deprecated: This configuration is not used anymore.
+	KafkaConfig  *kafka.KafkaConfig
+	NATSConfig   *nats.NATSConfig
+	PubSubConfig *pubsub.PubSubConfig
 }
 
 var DefaultChainDataFetcherConfig = &ChainDataFetcherConfig{
@@ -56,6 +102,13 @@ var DefaultChainDataFetcherConfig = &ChainDataFetcherConfig{
 	JobChannelSize:          DefaultJobChannelSize,
 	BlockChannelSize:        DefaultBlockChannelSize,
 
-	KasConfig:   kas.DefaultKASConfig,
-	KafkaConfig: kafka.GetDefaultKafkaConfig(),
+	KasConfig:    kas.DefaultKASConfig,
+	KafkaConfig:  kafka.GetDefaultKafkaConfig(),
+	NATSConfig:   nats.GetDefaultNATSConfig(),
+	PubSubConfig: pubsub.GetDefaultPubSubConfig(),
+}
+
+// Modes returns the modes of all sinks. The mode of the first sink is Mode.
+func (c *ChainDataFetcherConfig) Modes() []ChainDataFetcherMode {
+	return append([]ChainDataFetcherMode{c.Mode}, c.AdditionalModes...)
 }
@@ -15,13 +15,16 @@
 // along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
 
 /*
-Package chaindatafetcher implements blockchain data load to KAS-specific database, or kafka.
+Package chaindatafetcher implements blockchain data load to KAS-specific database, kafka, NATS, or Google Pub/Sub.
+Several sinks can be used at the same time and each of them keeps its own checkpoint.
 Source Files
   - api.go                   : includes chaindatafetcher-related APIs
   - chaindata_fetcher.go     : implements chaindatafetcher main operations
   - config.go                : includes chaindatafetcher configurations
   - metrics.go               : includes chaindatafetcher metrics
+  - reexport.go              : implements the re-export of a block range to a sink
   - repository.go            : implements repository interface
+  - sink.go                  : implements a sink which loads the selected data and keeps its checkpoint
 */
 
 package chaindatafetcher
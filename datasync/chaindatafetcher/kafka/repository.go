@@ -44,44 +44,59 @@ func (r *blockGroupResult) Key() string {
 	return r.BlockNumber.String()
 }
 
-type repository struct {
-	blockchain *blockchain.BlockChain
-	kafka      *Kafka
+// Publisher publishes a message to the given topic.
+type Publisher interface {
+	Publish(topic string, data interface{}) error
 }
 
-func NewRepository(config *KafkaConfig) (*repository, error) {
+// GroupRepository publishes the block group and trace group outputs of chain events.
+type GroupRepository struct {
+	blockchain   *blockchain.BlockChain
+	publisher    Publisher
+	getTopicName func(event string) string
+}
+
+func NewRepository(config *KafkaConfig) (*GroupRepository, error) {
 	kafka, err := NewKafka(config)
 	if err != nil {
 		logger.Error("Failed to create a new Kafka structure", "err", err, "config", config)
 		return nil, err
 	}
-	return &repository{
-		kafka: kafka,
-	}, nil
+	return NewGroupRepository(kafka, config.GetTopicName), nil
+}
+
+// NewGroupRepository returns a repository which publishes the block group and trace group
+// outputs through the given publisher. The message format is the same as the one of Kafka,
+// so the other message queue sinks can share the consumers.
+func NewGroupRepository(publisher Publisher, getTopicName func(event string) string) *GroupRepository {
+	return &GroupRepository{
+		publisher:    publisher,
+		getTopicName: getTopicName,
+	}
 }
 
-func (r *repository) SetComponent(component interface{}) {
+func (r *GroupRepository) SetComponent(component interface{}) {
 	switch c := component.(type) {
 	case *blockchain.BlockChain:
 		r.blockchain = c
 	}
 }
 
-func (r *repository) HandleChainEvent(event blockchain.ChainEvent, dataType types.RequestType) error {
+func (r *GroupRepository) HandleChainEvent(event blockchain.ChainEvent, dataType types.RequestType) error {
 	switch dataType {
 	case types.RequestTypeBlockGroup:
 		result := &blockGroupResult{
 			BlockNumber: event.Block.Number(),
 			Result:      makeBlockGroupOutput(r.blockchain, event.Block, event.Receipts),
 		}
-		return r.kafka.Publish(r.kafka.getTopicName(EventBlockGroup), result)
+		return r.publisher.Publish(r.getTopicName(EventBlockGroup), result)
 	case types.RequestTypeTraceGroup:
 		if len(event.InternalTxTraces) > 0 {
 			result := &traceGroupResult{
 				BlockNumber:      event.Block.Number(),
 				InternalTxTraces: event.InternalTxTraces,
 			}
-			return r.kafka.Publish(r.kafka.getTopicName(EventTraceGroup), result)
+			return r.publisher.Publish(r.getTopicName(EventTraceGroup), result)
 		}
 		return nil
 	default:
@@ -57,7 +57,7 @@ var (
 // BlockchainAPI interface is for testing purpose.
 type BlockchainAPI interface {
 	GetCode(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error)
-	Call(ctx context.Context, args api.CallArgs, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error)
+	Call(ctx context.Context, args api.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *api.StateOverride, blockOverrides *api.BlockOverrides) (hexutil.Bytes, error)
 }
 
 // contractCaller performs kip13 method `supportsInterface` to detect the deployed contracts are KIP7 or KIP17.
@@ -88,7 +88,7 @@ func (f *contractCaller) CallContract(ctx context.Context, call klaytn.CallMsg,
 		To:   call.To,
 		Data: hexutil.Bytes(call.Data),
 	}
-	return f.blockchainAPI.Call(ctx, callArgs, rpc.NewBlockNumberOrHashWithNumber(num))
+	return f.blockchainAPI.Call(ctx, callArgs, rpc.NewBlockNumberOrHashWithNumber(num), nil, nil)
 }
 
 func getCallOpts(blockNumber *big.Int, timeout time.Duration) (*bind.CallOpts, context.CancelFunc) {
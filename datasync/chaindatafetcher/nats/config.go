@@ -0,0 +1,69 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package nats
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	topicProjectName = "klaytn"
+	topicServiceName = "chaindatafetcher"
+	topicVersion     = "v1"
+)
+
+const (
+	DefaultURL                    = "nats://127.0.0.1:4222"
+	DefaultSubjectEnvironmentName = "local"
+	DefaultSubjectResourceName    = "en-0"
+	DefaultSegmentSizeBytes       = 1000000 // 1 MB
+	DefaultAckTimeout             = 5 * time.Second
+)
+
+type NATSConfig struct {
+	URL                    string        // URL is the address of the NATS server. e.g. nats://127.0.0.1:4222
+	User                   string        `json:"-"`
+	Password               string        `json:"-"`
+	Token                  string        `json:"-"`
+	JetStream              bool          // JetStream makes publishing wait for the acknowledgement of the stream.
+	AckTimeout             time.Duration // AckTimeout is the timeout for the acknowledgement of JetStream.
+	SubjectEnvironmentName string
+	SubjectResourceName    string
+	SegmentSizeBytes       int // SegmentSizeBytes is the size of a message segment. It is limited by the max payload of the server.
+}
+
+func GetDefaultNATSConfig() *NATSConfig {
+	return &NATSConfig{
+		URL:                    DefaultURL,
+		JetStream:              true,
+		AckTimeout:             DefaultAckTimeout,
+		SubjectEnvironmentName: DefaultSubjectEnvironmentName,
+		SubjectResourceName:    DefaultSubjectResourceName,
+		SegmentSizeBytes:       DefaultSegmentSizeBytes,
+	}
+}
+
+// GetSubjectName returns the subject name of the given event. It has the same form as the kafka topic name.
+func (c *NATSConfig) GetSubjectName(event string) string {
+	return fmt.Sprintf("%v.%v.%v.%v.%v.%v", c.SubjectEnvironmentName, topicProjectName, topicServiceName, c.SubjectResourceName, event, topicVersion)
+}
+
+func (c *NATSConfig) String() string {
+	return fmt.Sprintf("url: %v, jetStream: %v, ackTimeout: %v, subjectEnvironment: %v, subjectResourceName: %v, segmentSize: %v",
+		c.URL, c.JetStream, c.AckTimeout, c.SubjectEnvironmentName, c.SubjectResourceName, c.SegmentSizeBytes)
+}
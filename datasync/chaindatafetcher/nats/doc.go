@@ -0,0 +1,27 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package nats implements a NATS client in order to load chaindata to NATS or NATS JetStream.
+The messages have the same format as the ones of the kafka package and the segments of
+a message are distinguished by the headers.
+Source Files
+  - config.go     : includes NATS configurations
+  - nats.go       : implements a minimal NATS client which publishes messages
+  - repository.go : implements the repository publishing chaindata to NATS
+*/
+
+package nats
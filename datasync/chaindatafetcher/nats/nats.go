@@ -0,0 +1,448 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package nats
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/datasync/chaindatafetcher/kafka"
+	"github.com/klaytn/klaytn/log"
+)
+
+var logger = log.NewModuleLogger(log.ChainDataFetcher)
+
+const (
+	HeaderKey           = "Klaytn-Key"
+	HeaderTotalSegments = "Klaytn-Total-Segments"
+	HeaderSegmentIdx    = "Klaytn-Segment-Idx"
+	headerMsgId         = "Nats-Msg-Id" // used by JetStream to de-duplicate the retried messages
+
+	headerVersion     = "NATS/1.0"
+	statusNoResponder = "503"
+
+	connectTimeout = 5 * time.Second
+	clientName     = "klaytn-chaindatafetcher"
+	headerReserve  = 512 // room for the headers of a segment in the max payload
+)
+
+var (
+	errNoHeaderSupport = errors.New("the NATS server does not support headers")
+	errNoResponders    = errors.New("no JetStream stream is bound to the subject")
+	errAckTimeout      = errors.New("timed out waiting for the JetStream acknowledgement")
+	errClosed          = errors.New("the NATS connection is closed")
+)
+
+// serverInfo is the INFO message of the NATS server.
+type serverInfo struct {
+	ServerId     string `json:"server_id"`
+	Version      string `json:"version"`
+	Headers      bool   `json:"headers"`
+	MaxPayload   int    `json:"max_payload"`
+	AuthRequired bool   `json:"auth_required"`
+}
+
+// connectInfo is the CONNECT message of the client.
+type connectInfo struct {
+	Verbose      bool   `json:"verbose"`
+	Pedantic     bool   `json:"pedantic"`
+	Name         string `json:"name"`
+	Lang         string `json:"lang"`
+	Protocol     int    `json:"protocol"`
+	Headers      bool   `json:"headers"`
+	NoResponders bool   `json:"no_responders"`
+	User         string `json:"user,omitempty"`
+	Pass         string `json:"pass,omitempty"`
+	AuthToken    string `json:"auth_token,omitempty"`
+}
+
+// pubAck is the acknowledgement of JetStream.
+type pubAck struct {
+	Stream    string `json:"stream"`
+	Sequence  uint64 `json:"seq"`
+	Duplicate bool   `json:"duplicate"`
+	Error     *struct {
+		Code        int    `json:"code"`
+		Description string `json:"description"`
+	} `json:"error"`
+}
+
+// message is a message delivered to the inbox subscription.
+type message struct {
+	subject string
+	status  string
+	data    []byte
+}
+
+// conn is a connection to a NATS server.
+type conn struct {
+	nc     net.Conn
+	info   serverInfo
+	inbox  string
+	msgCh  chan *message
+	pongCh chan struct{}
+
+	writeMu sync.Mutex
+
+	closeOnce sync.Once
+	closed    chan struct{}
+	err       error
+}
+
+// NATS publishes messages to a NATS server. Only publishing is supported;
+// the messages are consumed by the NATS clients of the data consumers.
+// If the connection is lost, it is re-established on the next publish.
+type NATS struct {
+	config *NATSConfig
+
+	mu    sync.Mutex // serializes publishing, so at most one acknowledgement is awaited
+	conn  *conn
+	seqNo uint64
+}
+
+func NewNATS(config *NATSConfig) (*NATS, error) {
+	n := &NATS{config: config}
+	c, err := n.connect()
+	if err != nil {
+		return nil, err
+	}
+	n.conn = c
+	logger.Info("connected to NATS server", "server", c.info.ServerId, "version", c.info.Version, "maxPayload", c.info.MaxPayload)
+	return n, nil
+}
+
+func (n *NATS) connect() (*conn, error) {
+	u, err := url.Parse(n.config.URL)
+	if err != nil {
+		return nil, err
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	nc, err := net.DialTimeout("tcp", host, connectTimeout)
+	if err != nil {
+		return nil, err
+	}
+	c := &conn{
+		nc:     nc,
+		inbox:  "_INBOX." + hexutil.Encode(common.MakeRandomBytes(8))[2:],
+		msgCh:  make(chan *message, 1),
+		pongCh: make(chan struct{}, 1),
+		closed: make(chan struct{}),
+	}
+	if err := c.handshake(n.config, bufio.NewReader(nc)); err != nil {
+		nc.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *conn) handshake(config *NATSConfig, r *bufio.Reader) error {
+	c.nc.SetDeadline(time.Now().Add(connectTimeout))
+	defer c.nc.SetDeadline(time.Time{})
+
+	line, err := readLine(r)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("unexpected message from the NATS server: %q", line)
+	}
+	if err := json.Unmarshal([]byte(line[len("INFO "):]), &c.info); err != nil {
+		return err
+	}
+	if !c.info.Headers {
+		return errNoHeaderSupport
+	}
+
+	user, pass := config.User, config.Password
+	if u, err := url.Parse(config.URL); err == nil && u.User != nil && user == "" {
+		user = u.User.Username()
+		pass, _ = u.User.Password()
+	}
+	connect, err := json.Marshal(&connectInfo{
+		Name:         clientName,
+		Lang:         "go",
+		Protocol:     1,
+		Headers:      true,
+		NoResponders: true,
+		User:         user,
+		Pass:         pass,
+		AuthToken:    config.Token,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(c.nc, "CONNECT %s\r\nPING\r\nSUB %s.* 1\r\n", connect, c.inbox); err != nil {
+		return err
+	}
+
+	// the server replies PONG to the PING if CONNECT is accepted, or -ERR otherwise
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			go c.readLoop(r)
+			return nil
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("NATS server error: %s", line)
+		case strings.HasPrefix(line, "INFO "), line == "+OK":
+		default:
+			return fmt.Errorf("unexpected message from the NATS server: %q", line)
+		}
+	}
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readLoop handles the messages from the server until the connection is closed.
+func (c *conn) readLoop(r *bufio.Reader) {
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			c.close(err)
+			return
+		}
+		args := strings.Fields(line)
+		if len(args) == 0 {
+			continue
+		}
+		switch strings.ToUpper(args[0]) {
+		case "PING":
+			if err := c.write([]byte("PONG\r\n")); err != nil {
+				c.close(err)
+				return
+			}
+		case "PONG":
+			select {
+			case c.pongCh <- struct{}{}:
+			default:
+			}
+		case "MSG", "HMSG":
+			msg, err := readMessage(r, args)
+			if err != nil {
+				c.close(err)
+				return
+			}
+			select {
+			case c.msgCh <- msg:
+			default:
+				// the acknowledgement which nobody waits for anymore is dropped
+			}
+		case "-ERR":
+			c.close(fmt.Errorf("NATS server error: %s", line))
+			return
+		}
+	}
+}
+
+// readMessage reads the payload of MSG <subject> <sid> [reply] <size>
+// or HMSG <subject> <sid> [reply] <header size> <total size>.
+func readMessage(r *bufio.Reader, args []string) (*message, error) {
+	hasHeaders := strings.ToUpper(args[0]) == "HMSG"
+	if len(args) < 4 || (hasHeaders && len(args) < 5) {
+		return nil, fmt.Errorf("invalid message: %q", strings.Join(args, " "))
+	}
+	total, err := strconv.Atoi(args[len(args)-1])
+	if err != nil {
+		return nil, err
+	}
+	hdrLen := 0
+	if hasHeaders {
+		if hdrLen, err = strconv.Atoi(args[len(args)-2]); err != nil {
+			return nil, err
+		}
+	}
+	if hdrLen > total {
+		return nil, fmt.Errorf("invalid message: %q", strings.Join(args, " "))
+	}
+	buf := make([]byte, total+2) // including CRLF
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+
+	msg := &message{subject: args[1], data: buf[hdrLen:total]}
+	if hasHeaders {
+		// the status follows the version in the first line of the headers. e.g. NATS/1.0 503
+		statusLine := strings.SplitN(string(buf[:hdrLen]), "\r\n", 2)[0]
+		if fields := strings.Fields(statusLine); len(fields) > 1 {
+			msg.status = fields[1]
+		}
+	}
+	return msg, nil
+}
+
+func (c *conn) write(data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := c.nc.Write(data)
+	return err
+}
+
+func (c *conn) close(err error) {
+	c.closeOnce.Do(func() {
+		c.err = err
+		close(c.closed)
+		c.nc.Close()
+	})
+}
+
+func (c *conn) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (n *NATS) Close() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.conn != nil {
+		n.conn.close(errClosed)
+		n.conn = nil
+	}
+}
+
+func (n *NATS) split(data []byte, maxPayload int) [][]byte {
+	size := n.config.SegmentSizeBytes
+	if maxPayload > headerReserve && size > maxPayload-headerReserve {
+		size = maxPayload - headerReserve
+	}
+	var segments [][]byte
+	for len(data) > size {
+		segments = append(segments, data[:size])
+		data = data[size:]
+	}
+	return append(segments, data)
+}
+
+// makeHeaders returns the headers of a segment.
+func makeHeaders(key string, subject string, segmentIdx, totalSegments int) []byte {
+	var b strings.Builder
+	b.WriteString(headerVersion + "\r\n")
+	fmt.Fprintf(&b, "%s: %s\r\n", HeaderKey, key)
+	fmt.Fprintf(&b, "%s: %d\r\n", HeaderTotalSegments, totalSegments)
+	fmt.Fprintf(&b, "%s: %d\r\n", HeaderSegmentIdx, segmentIdx)
+	fmt.Fprintf(&b, "%s: %s.%s.%d\r\n", headerMsgId, subject, key, segmentIdx)
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
+
+// Publish publishes the data to the given subject. If JetStream is enabled,
+// it waits for the acknowledgement of each segment.
+func (n *NATS) Publish(subject string, data interface{}) error {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	key := ""
+	if v, ok := data.(kafka.IKey); ok {
+		key = v.Key()
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.conn == nil || n.conn.isClosed() {
+		c, err := n.connect()
+		if err != nil {
+			return err
+		}
+		n.conn = c
+	}
+	c := n.conn
+
+	segments := n.split(dataBytes, c.info.MaxPayload)
+	for idx, segment := range segments {
+		if err := n.publishSegment(c, subject, makeHeaders(key, subject, idx, len(segments)), segment); err != nil {
+			logger.Error("sending NATS message is failed", "err", err, "segmentIdx", idx, "key", key)
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *NATS) publishSegment(c *conn, subject string, headers, payload []byte) error {
+	reply := ""
+	if n.config.JetStream {
+		n.seqNo++
+		reply = fmt.Sprintf("%s.%d", c.inbox, n.seqNo)
+	}
+
+	cmd := fmt.Sprintf("HPUB %s %s %d %d\r\n", subject, reply, len(headers), len(headers)+len(payload))
+	if reply == "" {
+		cmd = fmt.Sprintf("HPUB %s %d %d\r\n", subject, len(headers), len(headers)+len(payload))
+	}
+	buf := make([]byte, 0, len(cmd)+len(headers)+len(payload)+2)
+	buf = append(append(append(append(buf, cmd...), headers...), payload...), "\r\n"...)
+	if err := c.write(buf); err != nil {
+		c.close(err)
+		return err
+	}
+	if reply == "" {
+		return nil
+	}
+
+	timer := time.NewTimer(n.config.AckTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case msg := <-c.msgCh:
+			if msg.subject != reply {
+				continue // stale acknowledgement of a timed out segment
+			}
+			if msg.status == statusNoResponder {
+				return errNoResponders
+			}
+			var ack pubAck
+			if err := json.Unmarshal(msg.data, &ack); err != nil {
+				return fmt.Errorf("invalid JetStream acknowledgement: %v", err)
+			}
+			if ack.Error != nil {
+				return fmt.Errorf("JetStream error (code: %d): %s", ack.Error.Code, ack.Error.Description)
+			}
+			return nil
+		case <-c.closed:
+			return c.err
+		case <-timer.C:
+			return errAckTimeout
+		}
+	}
+}
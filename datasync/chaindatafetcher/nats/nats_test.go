@@ -0,0 +1,133 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package nats
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testData struct {
+	Value string `json:"value"`
+}
+
+func (d *testData) Key() string { return "1" }
+
+// published is a message received by the fake server.
+type published struct {
+	subject string
+	headers string
+	payload []byte
+}
+
+// runFakeServer serves a NATS connection which acknowledges the messages by the given function.
+func runFakeServer(t *testing.T, maxPayload int, ack func(p *published) string) (string, chan *published) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	msgs := make(chan *published, 100)
+
+	go func() {
+		defer ln.Close()
+		nc, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer nc.Close()
+		fmt.Fprintf(nc, "INFO {\"server_id\":\"test\",\"headers\":true,\"max_payload\":%d}\r\n", maxPayload)
+
+		r := bufio.NewReader(nc)
+		for {
+			line, err := readLine(r)
+			if err != nil {
+				return
+			}
+			args := strings.Fields(line)
+			switch args[0] {
+			case "PING":
+				fmt.Fprint(nc, "PONG\r\n")
+			case "HPUB":
+				hdrLen, _ := strconv.Atoi(args[len(args)-2])
+				total, _ := strconv.Atoi(args[len(args)-1])
+				buf := make([]byte, total+2)
+				if _, err := io.ReadFull(r, buf); err != nil {
+					return
+				}
+				p := &published{subject: args[1], headers: string(buf[:hdrLen]), payload: buf[hdrLen:total]}
+				msgs <- p
+				if len(args) == 5 {
+					reply := ack(p)
+					fmt.Fprintf(nc, "MSG %s 1 %d\r\n%s\r\n", args[2], len(reply), reply)
+				}
+			}
+		}
+	}()
+	return "nats://" + ln.Addr().String(), msgs
+}
+
+func TestNATS_PublishWithJetStream(t *testing.T) {
+	url, msgs := runFakeServer(t, 1024, func(p *published) string { return `{"stream":"test","seq":1}` })
+
+	config := GetDefaultNATSConfig()
+	config.URL = url
+	n, err := NewNATS(config)
+	require.NoError(t, err)
+	defer n.Close()
+
+	// the message is split into the segments fitting in the max payload
+	data := &testData{Value: strings.Repeat("a", 1500)}
+	require.NoError(t, n.Publish(config.GetSubjectName("blockgroup"), data))
+
+	var payload []byte
+	for i := 0; i < 3; i++ {
+		p := <-msgs
+		assert.Equal(t, "local.klaytn.chaindatafetcher.en-0.blockgroup.v1", p.subject)
+		assert.Contains(t, p.headers, HeaderKey+": 1\r\n")
+		assert.Contains(t, p.headers, HeaderTotalSegments+": 3\r\n")
+		assert.Contains(t, p.headers, HeaderSegmentIdx+": "+strconv.Itoa(i)+"\r\n")
+		payload = append(payload, p.payload...)
+	}
+	var decoded testData
+	require.NoError(t, json.Unmarshal(payload, &decoded))
+	assert.Equal(t, data.Value, decoded.Value)
+}
+
+func TestNATS_PublishError(t *testing.T) {
+	url, _ := runFakeServer(t, 1024*1024, func(p *published) string {
+		return `{"error":{"code":503,"description":"no stream"}}`
+	})
+
+	config := GetDefaultNATSConfig()
+	config.URL = url
+	config.AckTimeout = time.Second
+	n, err := NewNATS(config)
+	require.NoError(t, err)
+	defer n.Close()
+
+	err = n.Publish("test", &testData{Value: "a"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no stream")
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package pubsub
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	topicProjectName = "klaytn"
+	topicServiceName = "chaindatafetcher"
+	topicVersion     = "v1"
+)
+
+const (
+	DefaultEndpoint             = "https://pubsub.googleapis.com"
+	DefaultTopicEnvironmentName = "local"
+	DefaultTopicResourceName    = "en-0"
+	DefaultSegmentSizeBytes     = 5000000 // 5 MB, the max size of a Pub/Sub message is 10 MB
+	DefaultRequestTimeout       = 30 * time.Second
+)
+
+type PubSubConfig struct {
+	ProjectID string
+	// Endpoint is the URL of the Pub/Sub API. It can be set to the address of the Pub/Sub emulator.
+	Endpoint string
+	// AccessToken is an OAuth2 access token. If it is empty and the default endpoint is used,
+	// the token of the default service account is taken from the GCE metadata server.
+	AccessToken          string `json:"-"`
+	TopicEnvironmentName string
+	TopicResourceName    string
+	CreateTopics         bool // CreateTopics creates the topics if they do not exist.
+	SegmentSizeBytes     int
+	RequestTimeout       time.Duration
+}
+
+func GetDefaultPubSubConfig() *PubSubConfig {
+	return &PubSubConfig{
+		Endpoint:             DefaultEndpoint,
+		TopicEnvironmentName: DefaultTopicEnvironmentName,
+		TopicResourceName:    DefaultTopicResourceName,
+		CreateTopics:         true,
+		SegmentSizeBytes:     DefaultSegmentSizeBytes,
+		RequestTimeout:       DefaultRequestTimeout,
+	}
+}
+
+// GetTopicName returns the topic ID of the given event. It has the same form as the kafka topic name.
+func (c *PubSubConfig) GetTopicName(event string) string {
+	return fmt.Sprintf("%v.%v.%v.%v.%v.%v", c.TopicEnvironmentName, topicProjectName, topicServiceName, c.TopicResourceName, event, topicVersion)
+}
+
+func (c *PubSubConfig) String() string {
+	return fmt.Sprintf("projectID: %v, endpoint: %v, topicEnvironment: %v, topicResourceName: %v, createTopics: %v, segmentSize: %v",
+		c.ProjectID, c.Endpoint, c.TopicEnvironmentName, c.TopicResourceName, c.CreateTopics, c.SegmentSizeBytes)
+}
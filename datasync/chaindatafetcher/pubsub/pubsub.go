@@ -0,0 +1,214 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package pubsub
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/datasync/chaindatafetcher/kafka"
+	"github.com/klaytn/klaytn/log"
+)
+
+var logger = log.NewModuleLogger(log.ChainDataFetcher)
+
+const (
+	AttributeKey           = "key"
+	AttributeTotalSegments = "totalSegments"
+	AttributeSegmentIdx    = "segmentIdx"
+
+	metadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	tokenExpiryDelta = time.Minute // refresh the token before it expires
+)
+
+var errNoProjectID = errors.New("the project ID of Pub/Sub is not set")
+
+type pubsubMessage struct {
+	Data       []byte            `json:"data"` // encoded in base64
+	Attributes map[string]string `json:"attributes"`
+}
+
+type publishRequest struct {
+	Messages []*pubsubMessage `json:"messages"`
+}
+
+// PubSub publishes messages with the Pub/Sub REST API.
+type PubSub struct {
+	config *PubSubConfig
+	client *http.Client
+
+	tokenMu     sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+func NewPubSub(config *PubSubConfig) (*PubSub, error) {
+	if config.ProjectID == "" {
+		return nil, errNoProjectID
+	}
+	p := &PubSub{
+		config: config,
+		client: &http.Client{Timeout: config.RequestTimeout},
+	}
+	if config.CreateTopics {
+		for _, event := range []string{kafka.EventBlockGroup, kafka.EventTraceGroup} {
+			if err := p.CreateTopic(config.GetTopicName(event)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return p, nil
+}
+
+func (p *PubSub) topicURL(topic string) string {
+	return fmt.Sprintf("%s/v1/projects/%s/topics/%s", strings.TrimRight(p.config.Endpoint, "/"), p.config.ProjectID, topic)
+}
+
+// CreateTopic creates the topic. It succeeds if the topic already exists.
+func (p *PubSub) CreateTopic(topic string) error {
+	status, body, err := p.do(http.MethodPut, p.topicURL(topic), []byte("{}"))
+	if err != nil {
+		return err
+	}
+	switch status {
+	case http.StatusOK:
+		logger.Info("Pub/Sub topic is created", "topic", topic)
+		return nil
+	case http.StatusConflict:
+		logger.Info("Pub/Sub topic already exists", "topic", topic)
+		return nil
+	default:
+		return fmt.Errorf("creating Pub/Sub topic %s is failed (status: %d): %s", topic, status, body)
+	}
+}
+
+func (p *PubSub) split(data []byte) [][]byte {
+	size := p.config.SegmentSizeBytes
+	var segments [][]byte
+	for len(data) > size {
+		segments = append(segments, data[:size])
+		data = data[size:]
+	}
+	return append(segments, data)
+}
+
+// Publish publishes the data to the given topic. All segments of the data are published in a request.
+func (p *PubSub) Publish(topic string, data interface{}) error {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	key := ""
+	if v, ok := data.(kafka.IKey); ok {
+		key = v.Key()
+	}
+
+	segments := p.split(dataBytes)
+	req := &publishRequest{Messages: make([]*pubsubMessage, len(segments))}
+	for idx, segment := range segments {
+		req.Messages[idx] = &pubsubMessage{
+			Data: segment,
+			Attributes: map[string]string{
+				AttributeKey:           key,
+				AttributeTotalSegments: strconv.Itoa(len(segments)),
+				AttributeSegmentIdx:    strconv.Itoa(idx),
+			},
+		}
+	}
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	status, body, err := p.do(http.MethodPost, p.topicURL(topic)+":publish", reqBytes)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		logger.Error("sending Pub/Sub message is failed", "status", status, "key", key, "response", string(body))
+		return fmt.Errorf("publishing Pub/Sub message is failed (status: %d): %s", status, body)
+	}
+	return nil
+}
+
+func (p *PubSub) do(method, url string, body []byte) (int, []byte, error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	token, err := p.accessToken()
+	if err != nil {
+		return 0, nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	return resp.StatusCode, respBody, err
+}
+
+// accessToken returns the access token for the requests. The emulator does not require a token.
+func (p *PubSub) accessToken() (string, error) {
+	if p.config.AccessToken != "" || p.config.Endpoint != DefaultEndpoint {
+		return p.config.AccessToken, nil
+	}
+
+	p.tokenMu.Lock()
+	defer p.tokenMu.Unlock()
+	if p.token != "" && time.Now().Before(p.tokenExpiry) {
+		return p.token, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, metadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("getting the access token from the metadata server is failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("getting the access token from the metadata server is failed (status: %d)", resp.StatusCode)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	p.token = token.AccessToken
+	p.tokenExpiry = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - tokenExpiryDelta)
+	return p.token, nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package pubsub
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testData struct {
+	Value string `json:"value"`
+}
+
+func (d *testData) Key() string { return "1" }
+
+func TestPubSub_Publish(t *testing.T) {
+	var (
+		created  []string
+		messages []*pubsubMessage
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		switch {
+		case r.Method == http.MethodPut:
+			created = append(created, r.URL.Path)
+			if len(created) > 1 {
+				w.WriteHeader(http.StatusConflict) // already exists
+			}
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, ".v1:publish"):
+			var req publishRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			messages = append(messages, req.Messages...)
+			w.Write([]byte(`{"messageIds":["1"]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	config := GetDefaultPubSubConfig()
+	config.Endpoint = server.URL
+	config.ProjectID = "test"
+	config.AccessToken = "token"
+	config.SegmentSizeBytes = 10
+
+	p, err := NewPubSub(config)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"/v1/projects/test/topics/local.klaytn.chaindatafetcher.en-0.blockgroup.v1",
+		"/v1/projects/test/topics/local.klaytn.chaindatafetcher.en-0.tracegroup.v1",
+	}, created)
+
+	data := &testData{Value: "0123456789"}
+	require.NoError(t, p.Publish(config.GetTopicName("blockgroup"), data))
+
+	// {"value":"0123456789"} is split into 3 segments
+	require.Equal(t, 3, len(messages))
+	var payload []byte
+	for i, msg := range messages {
+		assert.Equal(t, "1", msg.Attributes[AttributeKey])
+		assert.Equal(t, "3", msg.Attributes[AttributeTotalSegments])
+		assert.Equal(t, string(rune('0'+i)), msg.Attributes[AttributeSegmentIdx])
+		payload = append(payload, msg.Data...)
+	}
+	assert.Equal(t, `{"value":"0123456789"}`, string(payload))
+
+	// publishing to an unknown topic fails
+	assert.Error(t, p.Publish("unknown", data))
+}
+
+func TestNewPubSub_NoProjectID(t *testing.T) {
+	_, err := NewPubSub(GetDefaultPubSubConfig())
+	assert.Equal(t, errNoProjectID, err)
+}
@@ -0,0 +1,194 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package chaindatafetcher
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	cfTypes "github.com/klaytn/klaytn/datasync/chaindatafetcher/types"
+)
+
+const (
+	ReexportStateRunning  = "running"
+	ReexportStateFinished = "finished"
+	ReexportStateStopped  = "stopped"
+	ReexportStateFailed   = "failed"
+)
+
+var (
+	errReexportRunning    = errors.New("re-export is already running")
+	errReexportNotRunning = errors.New("re-export is not running")
+	errInvalidBlockRange  = errors.New("invalid block range")
+)
+
+// ReexportStatus is the progress of a re-export of a block range to a sink.
+type ReexportStatus struct {
+	Mode       string     `json:"mode"`
+	Datasets   []string   `json:"datasets"`
+	StartBlock uint64     `json:"startBlock"`
+	EndBlock   uint64     `json:"endBlock"`
+	NextBlock  uint64     `json:"nextBlock"` // the block which will be exported next
+	Exported   uint64     `json:"exported"`  // the number of the exported blocks
+	State      string     `json:"state"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// reexportJob exports the blocks of a range to a single sink again without touching its checkpoint.
+// The blocks are exported in order and the job stops at the first block which fails,
+// so it can be resumed from the failed block (NextBlock of the status).
+type reexportJob struct {
+	sink    *sink
+	reqType cfTypes.RequestType
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+
+	mu     sync.RWMutex
+	status ReexportStatus
+}
+
+func (j *reexportJob) getStatus() *ReexportStatus {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	status := j.status
+	return &status
+}
+
+func (j *reexportJob) finish(state string, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	j.status.State = state
+	j.status.FinishedAt = &now
+	if err != nil {
+		j.status.Error = err.Error()
+	}
+}
+
+func (j *reexportJob) run(f *ChainDataFetcher) {
+	defer close(j.doneCh)
+
+	start, end := j.status.StartBlock, j.status.EndBlock
+	logger.Info("re-export is started", "mode", j.sink.mode, "startBlock", start, "endBlock", end, "reqType", j.reqType)
+	for num := start; num <= end; num++ {
+		select {
+		case <-j.stopCh:
+			logger.Info("re-export is stopped", "mode", j.sink.mode, "stoppedBlock", num)
+			j.finish(ReexportStateStopped, nil)
+			return
+		default:
+		}
+
+		ev, err := f.makeChainEvent(num)
+		if err == nil {
+			err = f.handleSinkRequest(j.sink, j.reqType, false, ev)
+		}
+		if err != nil {
+			logger.Error("re-export is failed", "mode", j.sink.mode, "blockNumber", num, "err", err)
+			j.finish(ReexportStateFailed, fmt.Errorf("block %d: %v", num, err))
+			return
+		}
+
+		j.mu.Lock()
+		j.status.NextBlock = num + 1
+		j.status.Exported++
+		j.mu.Unlock()
+
+		if num == end { // prevent overflow
+			break
+		}
+	}
+	logger.Info("re-export is finished", "mode", j.sink.mode, "startBlock", start, "endBlock", end)
+	j.finish(ReexportStateFinished, nil)
+}
+
+// startReexport exports the blocks from startBlock to endBlock (inclusive) to the sink of the given mode again.
+// Only the given datasets are exported if they are specified, otherwise all the datasets of the sink are exported.
+func (f *ChainDataFetcher) startReexport(mode ChainDataFetcherMode, startBlock, endBlock uint64, datasets []string) error {
+	s := f.sinkOf(mode)
+	if s == nil {
+		return fmt.Errorf("no sink of the chaindatafetcher mode: %v", mode)
+	}
+
+	reqType := s.reqType
+	if len(datasets) > 0 {
+		selected, err := cfTypes.ParseRequestTypes(datasets)
+		if err != nil {
+			return err
+		}
+		if reqType &= selected; reqType == 0 {
+			return errNoDataset
+		}
+	}
+
+	if currentBlock := f.blockchain.CurrentHeader().Number.Uint64(); startBlock > endBlock || endBlock > currentBlock {
+		return fmt.Errorf("%v: startBlock=%d, endBlock=%d, currentBlock=%d", errInvalidBlockRange, startBlock, endBlock, currentBlock)
+	}
+
+	f.reexportMu.Lock()
+	defer f.reexportMu.Unlock()
+	if f.reexport != nil && f.reexport.getStatus().State == ReexportStateRunning {
+		return errReexportRunning
+	}
+
+	job := &reexportJob{
+		sink:    s,
+		reqType: reqType,
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+		status: ReexportStatus{
+			Mode:       s.mode.String(),
+			Datasets:   reqType.Names(),
+			StartBlock: startBlock,
+			EndBlock:   endBlock,
+			NextBlock:  startBlock,
+			State:      ReexportStateRunning,
+			StartedAt:  time.Now(),
+		},
+	}
+	f.reexport = job
+	go job.run(f)
+	return nil
+}
+
+// stopReexport stops the running re-export and waits until it is stopped.
+func (f *ChainDataFetcher) stopReexport() error {
+	f.reexportMu.Lock()
+	defer f.reexportMu.Unlock()
+	job := f.reexport
+	if job == nil || job.getStatus().State != ReexportStateRunning {
+		return errReexportNotRunning
+	}
+	close(job.stopCh)
+	<-job.doneCh
+	return nil
+}
+
+// reexportStatus returns the status of the last re-export. It returns nil if no re-export has been started.
+func (f *ChainDataFetcher) reexportStatus() *ReexportStatus {
+	f.reexportMu.Lock()
+	defer f.reexportMu.Unlock()
+	if f.reexport == nil {
+		return nil
+	}
+	return f.reexport.getStatus()
+}
@@ -0,0 +1,136 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package chaindatafetcher
+
+import (
+	"sync"
+
+	"github.com/klaytn/klaytn/datasync/chaindatafetcher/types"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/rcrowley/go-metrics"
+)
+
+// sink is a destination of the chaindata. Each sink handles its own set of request types and
+// keeps its own checkpoint, so a sink lagging behind does not hold back or duplicate the others.
+type sink struct {
+	mode         ChainDataFetcherMode
+	reqType      types.RequestType
+	repo         Repository
+	checkpointDB CheckpointDB
+
+	checkpointMu    sync.RWMutex
+	checkpoint      int64
+	checkpointMap   map[int64]struct{}
+	checkpointGauge metrics.Gauge
+}
+
+func newSink(mode ChainDataFetcherMode, reqType types.RequestType, repo Repository, checkpointDB CheckpointDB, gauge metrics.Gauge) *sink {
+	return &sink{
+		mode:            mode,
+		reqType:         reqType,
+		repo:            repo,
+		checkpointDB:    checkpointDB,
+		checkpointMap:   make(map[int64]struct{}),
+		checkpointGauge: gauge,
+	}
+}
+
+func (s *sink) getCheckpoint() int64 {
+	s.checkpointMu.RLock()
+	defer s.checkpointMu.RUnlock()
+	return s.checkpoint
+}
+
+// setCheckpoint reads the checkpoint of the sink. If it does not exist, the given current block is used.
+func (s *sink) setCheckpoint(currentBlock int64) {
+	checkpoint, err := s.checkpointDB.ReadCheckpoint()
+	if err != nil {
+		logger.Crit("ReadCheckpoint is failed", "mode", s.mode, "err", err)
+	}
+
+	if checkpoint == 0 {
+		checkpoint = currentBlock
+	}
+	s.checkpointMu.Lock()
+	s.checkpoint = checkpoint
+	s.checkpointMu.Unlock()
+	logger.Info("Chaindatafetcher initial checkpoint is set", "mode", s.mode, "checkpoint", checkpoint)
+}
+
+func (s *sink) updateCheckpoint(num int64) error {
+	s.checkpointMu.Lock()
+	defer s.checkpointMu.Unlock()
+	s.checkpointMap[num] = struct{}{}
+
+	updated := false
+	newCheckpoint := s.checkpoint
+	for {
+		if _, ok := s.checkpointMap[newCheckpoint]; !ok {
+			break
+		}
+		delete(s.checkpointMap, newCheckpoint)
+		newCheckpoint++
+		updated = true
+	}
+
+	if updated {
+		s.checkpoint = newCheckpoint
+		s.checkpointGauge.Update(s.checkpoint)
+		return s.checkpointDB.WriteCheckpoint(newCheckpoint)
+	}
+	return nil
+}
+
+// writeCheckpoint overwrites the checkpoint of the sink. The blocks handled beyond the previous checkpoint
+// are forgotten, so the blocks from the new checkpoint are handled again when fetching is started.
+func (s *sink) writeCheckpoint(checkpoint int64) error {
+	s.checkpointMu.Lock()
+	defer s.checkpointMu.Unlock()
+	if err := s.checkpointDB.WriteCheckpoint(checkpoint); err != nil {
+		return err
+	}
+	s.checkpoint = checkpoint
+	s.checkpointMap = make(map[int64]struct{})
+	s.checkpointGauge.Update(checkpoint)
+	return nil
+}
+
+// sinkCheckpointDB stores the checkpoint of a sink in the misc database of the node.
+type sinkCheckpointDB struct {
+	name    string
+	manager database.DBManager
+}
+
+func newSinkCheckpointDB(name string) *sinkCheckpointDB {
+	return &sinkCheckpointDB{name: name}
+}
+
+func (db *sinkCheckpointDB) ReadCheckpoint() (int64, error) {
+	checkpoint, err := db.manager.ReadChainDataFetcherSinkCheckpoint(db.name)
+	return int64(checkpoint), err
+}
+
+func (db *sinkCheckpointDB) WriteCheckpoint(checkpoint int64) error {
+	return db.manager.WriteChainDataFetcherSinkCheckpoint(db.name, uint64(checkpoint))
+}
+
+func (db *sinkCheckpointDB) SetComponent(component interface{}) {
+	switch c := component.(type) {
+	case database.DBManager:
+		db.manager = c
+	}
+}
@@ -16,6 +16,11 @@
 
 package types
 
+import (
+	"fmt"
+	"strings"
+)
+
 // RequestType informs which data should be exported such as block, transaction, transaction log, etc.
 type RequestType uint
 
@@ -38,6 +43,47 @@ const (
 	RequestTypeGroupAll = RequestTypeBlockGroup | RequestTypeTraceGroup
 )
 
+// requestTypeNames maps the dataset names used in the configuration to the request types.
+var requestTypeNames = map[string]RequestType{
+	"transaction":   RequestTypeTransaction,
+	"tokentransfer": RequestTypeTokenTransfer,
+	"contract":      RequestTypeContract,
+	"trace":         RequestTypeTrace,
+	"blockgroup":    RequestTypeBlockGroup,
+	"tracegroup":    RequestTypeTraceGroup,
+}
+
+// ParseRequestTypes returns the request type composed of the given dataset names.
+// The names are case-insensitive: "transaction", "tokentransfer", "contract", "trace",
+// "blockgroup" and "tracegroup".
+func ParseRequestTypes(names []string) (RequestType, error) {
+	var reqType RequestType
+	for _, name := range names {
+		t, ok := requestTypeNames[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return 0, fmt.Errorf("unsupported dataset: %q", name)
+		}
+		reqType |= t
+	}
+	return reqType, nil
+}
+
+// Names returns the dataset names of the request type in the order of the request types.
+func (rt RequestType) Names() []string {
+	var names []string
+	for targetType := RequestTypeTransaction; targetType < RequestTypeLength; targetType = targetType << 1 {
+		if !CheckRequestType(rt, targetType) {
+			continue
+		}
+		for name, t := range requestTypeNames {
+			if t == targetType {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
 // Request contains a blockNumber which should be handled and the type of data which should be exported.
 type Request struct {
 	ReqType                RequestType
@@ -84,3 +84,26 @@ func TestHasDataType(t *testing.T) {
 		}
 	}
 }
+
+func TestParseRequestTypes(t *testing.T) {
+	reqType, err := ParseRequestTypes([]string{"transaction", "Trace", " tokentransfer"})
+	assert.NoError(t, err)
+	assert.Equal(t, RequestTypeTransaction|RequestTypeTrace|RequestTypeTokenTransfer, reqType)
+
+	reqType, err = ParseRequestTypes([]string{"blockgroup", "tracegroup"})
+	assert.NoError(t, err)
+	assert.Equal(t, RequestTypeGroupAll, reqType)
+
+	reqType, err = ParseRequestTypes(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, RequestType(0), reqType)
+
+	_, err = ParseRequestTypes([]string{"block"})
+	assert.Error(t, err)
+}
+
+func TestRequestType_Names(t *testing.T) {
+	assert.Equal(t, []string{"transaction", "contract", "trace"}, (RequestTypeTransaction | RequestTypeContract | RequestTypeTrace).Names())
+	assert.Equal(t, []string{"blockgroup", "tracegroup"}, RequestTypeGroupAll.Names())
+	assert.Nil(t, RequestType(0).Names())
+}
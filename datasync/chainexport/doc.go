@@ -0,0 +1,27 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package chainexport implements the export of blocks, transactions, receipts and logs
+into CSV or Parquet files with a stable schema, so that chain history can be loaded into
+data warehouses for analytics.
+Source Files
+  - exporter.go : implements the exporter which writes a block range in resumable chunks
+  - parquet.go  : implements a minimal Parquet writer for flat tables
+  - schema.go   : defines the exported tables and their columns
+  - writer.go   : defines the row writer interface and the CSV writer
+*/
+package chainexport
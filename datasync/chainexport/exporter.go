@@ -0,0 +1,226 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package chainexport
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/params"
+)
+
+var logger = log.NewModuleLogger(log.ChainExport)
+
+const DefaultChunkSize = 10000
+
+var (
+	errInvalidRange     = errors.New("invalid block range")
+	errInvalidChunkSize = errors.New("chunk size should be positive")
+)
+
+// BlockChain is the interface of the blockchain used by the exporter.
+type BlockChain interface {
+	Config() *params.ChainConfig
+	CurrentBlock() *types.Block
+	GetBlockByNumber(number uint64) *types.Block
+	GetReceiptsByBlockHash(blockHash common.Hash) types.Receipts
+}
+
+// Config is the configuration of the exporter.
+type Config struct {
+	Dir       string // Directory where the table files are written
+	Format    Format
+	ChunkSize uint64 // Number of blocks in a file
+}
+
+// Exporter exports blocks, transactions, receipts and logs of a block range into files.
+//
+// The range is split into chunks aligned to the multiples of the chunk size and each
+// chunk is written into a file per table, named <dir>/<table>/<table>_<first>_<last>.<ext>.
+// A file is written to a temporary file first and renamed when it is completed,
+// so an interrupted export can be resumed by running it again with the same range;
+// the chunks whose files are all present are skipped.
+type Exporter struct {
+	bc     BlockChain
+	config *Config
+}
+
+// NewExporter returns a new exporter.
+func NewExporter(bc BlockChain, config *Config) (*Exporter, error) {
+	if !config.Format.IsValid() {
+		return nil, fmt.Errorf("unsupported format: %q", config.Format)
+	}
+	if config.ChunkSize == 0 {
+		return nil, errInvalidChunkSize
+	}
+	for _, table := range Tables {
+		if err := os.MkdirAll(filepath.Join(config.Dir, table.Name), 0755); err != nil {
+			return nil, err
+		}
+	}
+	return &Exporter{bc: bc, config: config}, nil
+}
+
+// Export exports the blocks from `from` to `to` (inclusive).
+func (e *Exporter) Export(from, to uint64) error {
+	if from > to || to > e.bc.CurrentBlock().NumberU64() {
+		return fmt.Errorf("%v: from=%d, to=%d, current=%d", errInvalidRange, from, to, e.bc.CurrentBlock().NumberU64())
+	}
+
+	size := e.config.ChunkSize
+	for start := from - from%size; ; start += size {
+		first, last := start, start+size-1
+		if first < from {
+			first = from
+		}
+		if last > to || last < start {
+			last = to
+		}
+
+		if e.exported(first, last) {
+			logger.Info("Skip the exported chunk", "first", first, "last", last)
+		} else {
+			begin := time.Now()
+			if err := e.exportChunk(first, last); err != nil {
+				return err
+			}
+			logger.Info("Exported a chunk", "first", first, "last", last, "elapsed", time.Since(begin))
+		}
+
+		if last == to {
+			return nil
+		}
+	}
+}
+
+// FilePath returns the path of the file of the table containing the given chunk.
+func (e *Exporter) FilePath(table *Table, first, last uint64) string {
+	name := fmt.Sprintf("%s_%012d_%012d.%s", table.Name, first, last, e.config.Format.Extension())
+	return filepath.Join(e.config.Dir, table.Name, name)
+}
+
+func (e *Exporter) exported(first, last uint64) bool {
+	for _, table := range Tables {
+		if _, err := os.Stat(e.FilePath(table, first, last)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// tableFile is a temporary file of a table being written.
+type tableFile struct {
+	table  *Table
+	file   *os.File
+	writer RowWriter
+	path   string
+}
+
+func (e *Exporter) exportChunk(first, last uint64) error {
+	files := make(map[*Table]*tableFile, len(Tables))
+	defer func() {
+		// remove the temporary files if the chunk is not completed
+		for _, f := range files {
+			if f.file != nil {
+				f.file.Close()
+				os.Remove(f.file.Name())
+			}
+		}
+	}()
+
+	for _, table := range Tables {
+		path := e.FilePath(table, first, last)
+		file, err := os.Create(path + ".tmp")
+		if err != nil {
+			return err
+		}
+		f := &tableFile{table: table, file: file, path: path}
+		files[table] = f
+		if f.writer, err = NewRowWriter(e.config.Format, file, table); err != nil {
+			return err
+		}
+	}
+
+	for number := first; number <= last; number++ {
+		if err := e.exportBlock(files, number); err != nil {
+			return err
+		}
+		if number == last { // prevent overflow
+			break
+		}
+	}
+
+	for _, f := range files {
+		if err := f.writer.Close(); err != nil {
+			return err
+		}
+		if err := f.file.Close(); err != nil {
+			return err
+		}
+		tmp := f.file.Name()
+		f.file = nil
+		if err := os.Rename(tmp, f.path); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Exporter) exportBlock(files map[*Table]*tableFile, number uint64) error {
+	block := e.bc.GetBlockByNumber(number)
+	if block == nil {
+		return fmt.Errorf("block %d is not found", number)
+	}
+	txs := block.Transactions()
+	receipts := e.bc.GetReceiptsByBlockHash(block.Hash())
+	if len(receipts) != len(txs) {
+		return fmt.Errorf("block %d: receipts count %d does not match transactions count %d", number, len(receipts), len(txs))
+	}
+
+	if err := files[BlocksTable].writer.Write(blockRow(block)); err != nil {
+		return err
+	}
+
+	signer := types.MakeSigner(e.bc.Config(), block.Number())
+	logIndex := 0
+	for i, tx := range txs {
+		row, err := transactionRow(signer, block, i, tx)
+		if err != nil {
+			return fmt.Errorf("block %d: failed to export tx %s: %v", number, tx.Hash().String(), err)
+		}
+		if err := files[TransactionsTable].writer.Write(row); err != nil {
+			return err
+		}
+		if err := files[ReceiptsTable].writer.Write(receiptRow(block, i, receipts[i])); err != nil {
+			return err
+		}
+		for _, l := range receipts[i].Logs {
+			if err := files[LogsTable].writer.Write(logRow(block, i, tx.Hash(), logIndex, l)); err != nil {
+				return err
+			}
+			logIndex++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package chainexport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testChain struct {
+	blocks   []*types.Block
+	receipts map[common.Hash]types.Receipts
+}
+
+func newTestChain(t *testing.T, n int) *testChain {
+	blockchain.InitDeriveSha(params.TestChainConfig.DeriveShaImpl)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := types.MakeSigner(params.TestChainConfig, big.NewInt(0))
+
+	chain := &testChain{receipts: make(map[common.Hash]types.Receipts)}
+	for i := 0; i < n; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i)), Time: big.NewInt(int64(i)), BlockScore: big.NewInt(1)}
+
+		// every odd block has a transaction with a log
+		var txs types.Transactions
+		var receipts types.Receipts
+		if i%2 == 1 {
+			tx, err := types.SignTx(types.NewTransaction(uint64(i/2), common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), []byte{1}), signer, key)
+			require.NoError(t, err)
+			receipt := types.NewReceipt(types.ReceiptStatusSuccessful, tx.Hash(), 21000)
+			receipt.Logs = []*types.Log{{Address: common.Address{2}, Topics: []common.Hash{{3}}, Data: []byte{4}}}
+			txs, receipts = types.Transactions{tx}, types.Receipts{receipt}
+		}
+		block := types.NewBlock(header, txs, receipts)
+		chain.blocks = append(chain.blocks, block)
+		chain.receipts[block.Hash()] = receipts
+	}
+	return chain
+}
+
+func (c *testChain) Config() *params.ChainConfig { return params.TestChainConfig }
+func (c *testChain) CurrentBlock() *types.Block  { return c.blocks[len(c.blocks)-1] }
+func (c *testChain) GetBlockByNumber(number uint64) *types.Block {
+	if number >= uint64(len(c.blocks)) {
+		return nil
+	}
+	return c.blocks[number]
+}
+func (c *testChain) GetReceiptsByBlockHash(hash common.Hash) types.Receipts { return c.receipts[hash] }
+
+func readCSV(t *testing.T, path string) [][]string {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	return records
+}
+
+func TestExporter_CSV(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chainexport")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	chain := newTestChain(t, 10)
+	exporter, err := NewExporter(chain, &Config{Dir: dir, Format: FormatCSV, ChunkSize: 4})
+	require.NoError(t, err)
+	require.NoError(t, exporter.Export(2, 9))
+
+	// chunks are aligned to the chunk size: [2, 3], [4, 7], [8, 9]
+	blocks := readCSV(t, exporter.FilePath(BlocksTable, 4, 7))
+	assert.Equal(t, 5, len(blocks))
+	assert.Equal(t, "number", blocks[0][0])
+	assert.Equal(t, "4", blocks[1][0])
+	assert.Equal(t, chain.blocks[4].Hash().Hex(), blocks[1][1])
+
+	txs := readCSV(t, exporter.FilePath(TransactionsTable, 2, 3))
+	require.Equal(t, 2, len(txs))
+	assert.Equal(t, chain.blocks[3].Transactions()[0].Hash().Hex(), txs[1][3])
+	assert.Equal(t, "0x01", txs[1][13])
+
+	logs := readCSV(t, exporter.FilePath(LogsTable, 8, 9))
+	require.Equal(t, 2, len(logs))
+	assert.Equal(t, common.Address{2}.Hex(), logs[1][4])
+	assert.Equal(t, "", logs[1][6])
+
+	// the exported chunks are not written again
+	path := exporter.FilePath(ReceiptsTable, 4, 7)
+	require.NoError(t, os.Remove(path))
+	before, err := os.Stat(exporter.FilePath(ReceiptsTable, 2, 3))
+	require.NoError(t, err)
+
+	require.NoError(t, exporter.Export(2, 9))
+	_, err = os.Stat(path)
+	assert.NoError(t, err)
+	after, err := os.Stat(exporter.FilePath(ReceiptsTable, 2, 3))
+	require.NoError(t, err)
+	assert.Equal(t, before.ModTime(), after.ModTime())
+
+	assert.Error(t, exporter.Export(5, 10))
+}
+
+func TestParquetWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewRowWriter(FormatParquet, &buf, ReceiptsTable)
+	require.NoError(t, err)
+
+	assert.Error(t, w.Write(Row{int64(1)}))
+	require.NoError(t, w.Write(Row{int64(1), "0x1", int64(0), int64(1), int64(21000), "", int64(0)}))
+	require.NoError(t, w.Close())
+
+	data := buf.Bytes()
+	assert.Equal(t, parquetMagic, string(data[:4]))
+	assert.Equal(t, parquetMagic, string(data[len(data)-4:]))
+
+	// the footer length points to the file metadata following the column chunks
+	footerLen := binary.LittleEndian.Uint32(data[len(data)-8:])
+	metadata := data[len(data)-8-int(footerLen) : len(data)-8]
+	assert.Equal(t, byte(0x15), metadata[0]) // field 1 (version), i32
+	assert.True(t, bytes.Contains(metadata, []byte("contract_address")))
+}
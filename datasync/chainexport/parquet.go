@@ -0,0 +1,293 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package chainexport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// parquetWriter is a minimal Parquet writer for flat tables. All columns are
+// REQUIRED, PLAIN encoded and uncompressed, and all rows are written into a single
+// row group with one data page per column when the writer is closed.
+// The file metadata is serialized with the Thrift compact protocol as the format requires.
+type parquetWriter struct {
+	table   *Table
+	w       io.Writer
+	columns []bytes.Buffer
+	numRows int64
+}
+
+const parquetMagic = "PAR1"
+
+// Parquet format constants. See parquet.thrift of the Apache Parquet format.
+const (
+	parquetTypeInt64     = 2
+	parquetTypeByteArray = 6
+
+	parquetRepetitionRequired = 0
+	parquetConvertedTypeUTF8  = 0
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+
+	parquetCodecUncompressed = 0
+	parquetPageTypeData      = 0
+)
+
+func newParquetWriter(w io.Writer, table *Table) *parquetWriter {
+	return &parquetWriter{table: table, w: w, columns: make([]bytes.Buffer, len(table.Columns))}
+}
+
+func (pw *parquetWriter) Write(row Row) error {
+	if err := checkRow(pw.table, row); err != nil {
+		return err
+	}
+	var b [8]byte
+	for i, v := range row {
+		switch v := v.(type) {
+		case int64:
+			binary.LittleEndian.PutUint64(b[:], uint64(v))
+			pw.columns[i].Write(b[:8])
+		case string:
+			binary.LittleEndian.PutUint32(b[:], uint32(len(v)))
+			pw.columns[i].Write(b[:4])
+			pw.columns[i].WriteString(v)
+		}
+	}
+	pw.numRows++
+	return nil
+}
+
+func (pw *parquetWriter) Close() error {
+	out := &countingWriter{w: pw.w}
+	if _, err := io.WriteString(out, parquetMagic); err != nil {
+		return err
+	}
+
+	type chunk struct {
+		offset int64
+		size   int64
+	}
+	chunks := make([]chunk, len(pw.columns))
+	var totalSize int64
+	if pw.numRows > 0 {
+		for i := range pw.columns {
+			data := pw.columns[i].Bytes()
+			header := newThriftWriter()
+			header.i32(1, parquetPageTypeData)
+			header.i32(2, int32(len(data)))
+			header.i32(3, int32(len(data)))
+			header.structBegin(5)
+			header.i32(1, int32(pw.numRows))
+			header.i32(2, parquetEncodingPlain)
+			header.i32(3, parquetEncodingRLE)
+			header.i32(4, parquetEncodingRLE)
+			header.structEnd()
+			header.stop()
+
+			chunks[i].offset = out.n
+			if _, err := out.Write(header.Bytes()); err != nil {
+				return err
+			}
+			if _, err := out.Write(data); err != nil {
+				return err
+			}
+			chunks[i].size = out.n - chunks[i].offset
+			totalSize += chunks[i].size
+		}
+	}
+
+	meta := newThriftWriter()
+	meta.i32(1, 1) // version
+	meta.listBegin(2, thriftStruct, len(pw.table.Columns)+1)
+	meta.elemBegin()
+	meta.binary(4, "schema")
+	meta.i32(5, int32(len(pw.table.Columns)))
+	meta.elemEnd()
+	for _, col := range pw.table.Columns {
+		meta.elemBegin()
+		meta.i32(1, parquetType(col.Type))
+		meta.i32(3, parquetRepetitionRequired)
+		meta.binary(4, col.Name)
+		if col.Type == String {
+			meta.i32(6, parquetConvertedTypeUTF8)
+		}
+		meta.elemEnd()
+	}
+	meta.i64(3, pw.numRows)
+	if pw.numRows > 0 {
+		meta.listBegin(4, thriftStruct, 1)
+		meta.elemBegin()
+		meta.listBegin(1, thriftStruct, len(pw.table.Columns))
+		for i, col := range pw.table.Columns {
+			meta.elemBegin()
+			meta.i64(2, chunks[i].offset)
+			meta.structBegin(3)
+			meta.i32(1, parquetType(col.Type))
+			meta.listBegin(2, thriftI32, 2)
+			meta.i32Elem(parquetEncodingPlain)
+			meta.i32Elem(parquetEncodingRLE)
+			meta.listBegin(3, thriftBinary, 1)
+			meta.binaryElem(col.Name)
+			meta.i32(4, parquetCodecUncompressed)
+			meta.i64(5, pw.numRows)
+			meta.i64(6, chunks[i].size)
+			meta.i64(7, chunks[i].size)
+			meta.i64(9, chunks[i].offset)
+			meta.structEnd()
+			meta.elemEnd()
+		}
+		meta.i64(2, totalSize)
+		meta.i64(3, pw.numRows)
+		meta.elemEnd()
+	} else {
+		meta.listBegin(4, thriftStruct, 0)
+	}
+	meta.binary(6, "klaytn chainexport")
+	meta.stop()
+
+	if _, err := out.Write(meta.Bytes()); err != nil {
+		return err
+	}
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(meta.Len()))
+	if _, err := out.Write(b[:]); err != nil {
+		return err
+	}
+	_, err := io.WriteString(out, parquetMagic)
+	return err
+}
+
+func parquetType(t ColumnType) int32 {
+	if t == Int64 {
+		return parquetTypeInt64
+	}
+	return parquetTypeByteArray
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// Thrift compact protocol types.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes a struct with the Thrift compact protocol.
+type thriftWriter struct {
+	bytes.Buffer
+	lastField  int16
+	fieldStack []int16
+}
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{}
+}
+
+func (tw *thriftWriter) uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	tw.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func (tw *thriftWriter) zigzag(v int64) {
+	tw.uvarint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (tw *thriftWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - tw.lastField; delta > 0 && delta <= 15 {
+		tw.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		tw.WriteByte(typ)
+		tw.zigzag(int64(id))
+	}
+	tw.lastField = id
+}
+
+func (tw *thriftWriter) i32(id int16, v int32) {
+	tw.fieldHeader(id, thriftI32)
+	tw.zigzag(int64(v))
+}
+
+func (tw *thriftWriter) i64(id int16, v int64) {
+	tw.fieldHeader(id, thriftI64)
+	tw.zigzag(v)
+}
+
+func (tw *thriftWriter) binary(id int16, v string) {
+	tw.fieldHeader(id, thriftBinary)
+	tw.binaryElem(v)
+}
+
+func (tw *thriftWriter) listBegin(id int16, elemType byte, size int) {
+	tw.fieldHeader(id, thriftList)
+	if size < 15 {
+		tw.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		tw.WriteByte(0xf0 | elemType)
+		tw.uvarint(uint64(size))
+	}
+}
+
+func (tw *thriftWriter) i32Elem(v int32) {
+	tw.zigzag(int64(v))
+}
+
+func (tw *thriftWriter) binaryElem(v string) {
+	tw.uvarint(uint64(len(v)))
+	tw.WriteString(v)
+}
+
+// structBegin starts a struct field. It should be paired with structEnd.
+func (tw *thriftWriter) structBegin(id int16) {
+	tw.fieldHeader(id, thriftStruct)
+	tw.elemBegin()
+}
+
+func (tw *thriftWriter) structEnd() {
+	tw.elemEnd()
+}
+
+// elemBegin starts a struct element of a list. It should be paired with elemEnd.
+func (tw *thriftWriter) elemBegin() {
+	tw.fieldStack = append(tw.fieldStack, tw.lastField)
+	tw.lastField = 0
+}
+
+func (tw *thriftWriter) elemEnd() {
+	tw.stop()
+	tw.lastField = tw.fieldStack[len(tw.fieldStack)-1]
+	tw.fieldStack = tw.fieldStack[:len(tw.fieldStack)-1]
+}
+
+// stop writes the end of a struct.
+func (tw *thriftWriter) stop() {
+	tw.WriteByte(0)
+}
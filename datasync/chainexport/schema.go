@@ -0,0 +1,210 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package chainexport
+
+import (
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+)
+
+// ColumnType is the type of a column. Only two types are used to keep the schema
+// simple to load: integers which fit in int64 and strings. Big integers such as
+// values and gas prices are exported as decimal strings.
+type ColumnType int
+
+const (
+	Int64 ColumnType = iota
+	String
+)
+
+// Column describes a column of a table.
+type Column struct {
+	Name string
+	Type ColumnType
+}
+
+// Row is a record of a table. Each value is either an int64 or a string
+// in the order of the columns of the table.
+type Row []interface{}
+
+// Table describes an exported table. The columns of a table must not be reordered
+// or removed so that the exported files keep a stable schema; new columns should
+// be appended at the end.
+type Table struct {
+	Name    string
+	Columns []Column
+}
+
+var (
+	BlocksTable = &Table{
+		Name: "blocks",
+		Columns: []Column{
+			{"number", Int64},
+			{"hash", String},
+			{"parent_hash", String},
+			{"timestamp", Int64},
+			{"timestamp_fos", Int64},
+			{"block_score", String},
+			{"rewardbase", String},
+			{"gas_used", Int64},
+			{"state_root", String},
+			{"transactions_root", String},
+			{"receipts_root", String},
+			{"transaction_count", Int64},
+			{"size", Int64},
+		},
+	}
+
+	TransactionsTable = &Table{
+		Name: "transactions",
+		Columns: []Column{
+			{"block_number", Int64},
+			{"block_hash", String},
+			{"transaction_index", Int64},
+			{"hash", String},
+			{"type", String},
+			{"from", String},
+			{"to", String},
+			{"value", String},
+			{"gas", Int64},
+			{"gas_price", String},
+			{"nonce", Int64},
+			{"fee_payer", String},
+			{"fee_ratio", Int64},
+			{"input", String},
+		},
+	}
+
+	ReceiptsTable = &Table{
+		Name: "receipts",
+		Columns: []Column{
+			{"block_number", Int64},
+			{"transaction_hash", String},
+			{"transaction_index", Int64},
+			{"status", Int64},
+			{"gas_used", Int64},
+			{"contract_address", String},
+			{"log_count", Int64},
+		},
+	}
+
+	LogsTable = &Table{
+		Name: "logs",
+		Columns: []Column{
+			{"block_number", Int64},
+			{"transaction_hash", String},
+			{"transaction_index", Int64},
+			{"log_index", Int64},
+			{"address", String},
+			{"topic0", String},
+			{"topic1", String},
+			{"topic2", String},
+			{"topic3", String},
+			{"data", String},
+		},
+	}
+
+	// Tables is the list of all exported tables.
+	Tables = []*Table{BlocksTable, TransactionsTable, ReceiptsTable, LogsTable}
+)
+
+func blockRow(block *types.Block) Row {
+	header := block.Header()
+	return Row{
+		int64(block.NumberU64()),
+		block.Hash().Hex(),
+		header.ParentHash.Hex(),
+		header.Time.Int64(),
+		int64(header.TimeFoS),
+		header.BlockScore.String(),
+		header.Rewardbase.Hex(),
+		int64(header.GasUsed),
+		header.Root.Hex(),
+		header.TxHash.Hex(),
+		header.ReceiptHash.Hex(),
+		int64(len(block.Transactions())),
+		int64(block.Size()),
+	}
+}
+
+func transactionRow(signer types.Signer, block *types.Block, index int, tx *types.Transaction) (Row, error) {
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return nil, err
+	}
+	feePayer, err := types.SenderFeePayer(signer, tx)
+	if err != nil {
+		return nil, err
+	}
+	to := ""
+	if tx.To() != nil {
+		to = tx.To().Hex()
+	}
+	feeRatio, _ := tx.FeeRatio()
+	return Row{
+		int64(block.NumberU64()),
+		block.Hash().Hex(),
+		int64(index),
+		tx.Hash().Hex(),
+		tx.Type().String(),
+		from.Hex(),
+		to,
+		tx.Value().String(),
+		int64(tx.Gas()),
+		tx.GasPrice().String(),
+		int64(tx.Nonce()),
+		feePayer.Hex(),
+		int64(feeRatio),
+		hexutil.Encode(tx.Data()),
+	}, nil
+}
+
+func receiptRow(block *types.Block, index int, receipt *types.Receipt) Row {
+	contractAddress := ""
+	if receipt.ContractAddress != (common.Address{}) {
+		contractAddress = receipt.ContractAddress.Hex()
+	}
+	return Row{
+		int64(block.NumberU64()),
+		receipt.TxHash.Hex(),
+		int64(index),
+		int64(receipt.Status),
+		int64(receipt.GasUsed),
+		contractAddress,
+		int64(len(receipt.Logs)),
+	}
+}
+
+func logRow(block *types.Block, txIndex int, txHash common.Hash, logIndex int, log *types.Log) Row {
+	var topics [4]string
+	for i := 0; i < len(log.Topics) && i < len(topics); i++ {
+		topics[i] = log.Topics[i].Hex()
+	}
+	return Row{
+		int64(block.NumberU64()),
+		txHash.Hex(),
+		int64(txIndex),
+		int64(logIndex),
+		log.Address.Hex(),
+		topics[0],
+		topics[1],
+		topics[2],
+		topics[3],
+		hexutil.Encode(log.Data),
+	}
+}
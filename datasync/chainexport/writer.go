@@ -0,0 +1,118 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package chainexport
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Format is the file format of the exported tables.
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatParquet Format = "parquet"
+)
+
+// Extension returns the file extension of the format.
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// IsValid returns true if the format is supported.
+func (f Format) IsValid() bool {
+	return f == FormatCSV || f == FormatParquet
+}
+
+// RowWriter writes rows of a table into an underlying writer.
+// Close must be called to flush the rows; it does not close the underlying writer.
+type RowWriter interface {
+	Write(row Row) error
+	Close() error
+}
+
+// NewRowWriter returns a RowWriter writing the rows of the given table in the given format.
+func NewRowWriter(format Format, w io.Writer, table *Table) (RowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, table)
+	case FormatParquet:
+		return newParquetWriter(w, table), nil
+	default:
+		return nil, fmt.Errorf("unsupported format: %q", format)
+	}
+}
+
+func checkRow(table *Table, row Row) error {
+	if len(row) != len(table.Columns) {
+		return fmt.Errorf("%s: expected %d columns, got %d", table.Name, len(table.Columns), len(row))
+	}
+	for i, col := range table.Columns {
+		var ok bool
+		switch col.Type {
+		case Int64:
+			_, ok = row[i].(int64)
+		case String:
+			_, ok = row[i].(string)
+		}
+		if !ok {
+			return fmt.Errorf("%s: invalid value type %T for column %s", table.Name, row[i], col.Name)
+		}
+	}
+	return nil
+}
+
+// csvWriter writes a header line followed by the rows.
+type csvWriter struct {
+	table  *Table
+	writer *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer, table *Table) (*csvWriter, error) {
+	cw := &csvWriter{table: table, writer: csv.NewWriter(w), record: make([]string, len(table.Columns))}
+	for i, col := range table.Columns {
+		cw.record[i] = col.Name
+	}
+	if err := cw.writer.Write(cw.record); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) Write(row Row) error {
+	if err := checkRow(cw.table, row); err != nil {
+		return err
+	}
+	for i, v := range row {
+		switch v := v.(type) {
+		case int64:
+			cw.record[i] = strconv.FormatInt(v, 10)
+		case string:
+			cw.record[i] = v
+		}
+	}
+	return cw.writer.Write(cw.record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.writer.Flush()
+	return cw.writer.Error()
+}
@@ -41,6 +41,13 @@ func (*FakeDownloader) DeliverBodies(id string, transactions [][]*types.Transact
 func (*FakeDownloader) DeliverHeaders(id string, headers []*types.Header) error      { return nil }
 func (*FakeDownloader) DeliverNodeData(id string, data [][]byte) error               { return nil }
 func (*FakeDownloader) DeliverReceipts(id string, receipts [][]*types.Receipt) error { return nil }
+func (*FakeDownloader) DeliverAccountRange(id string, reqID uint64, hashes []common.Hash, accounts [][]byte, proof [][]byte) error {
+	return nil
+}
+func (*FakeDownloader) DeliverStorageRanges(id string, reqID uint64, hashes [][]common.Hash, slots [][][]byte, proof [][]byte) error {
+	return nil
+}
+func (*FakeDownloader) DeliverByteCodes(id string, reqID uint64, codes [][]byte) error { return nil }
 
 func (*FakeDownloader) Terminate() {}
 func (*FakeDownloader) Synchronise(id string, head common.Hash, td *big.Int, mode SyncMode) error {
@@ -91,6 +91,15 @@ type Peer interface {
 	RequestNodeData([]common.Hash) error
 }
 
+// SnapPeer encapsulates the methods required to snap sync the state with a remote
+// peer serving the state snapshot.
+type SnapPeer interface {
+	ServesSnapshot() bool
+	RequestAccountRange(id uint64, root, origin, limit common.Hash, bytes uint64) error
+	RequestStorageRanges(id uint64, root common.Hash, accounts []common.Hash, origin, limit []byte, bytes uint64) error
+	RequestByteCodes(id uint64, hashes []common.Hash, bytes uint64) error
+}
+
 // lightPeerWrapper wraps a LightPeer struct, stubbing out the Peer-only methods.
 type lightPeerWrapper struct {
 	peer LightPeer
@@ -223,6 +232,52 @@ func (p *peerConnection) FetchNodeData(hashes []common.Hash) error {
 	return nil
 }
 
+// ServesSnapshot returns whether the peer serves the state snapshot for snap sync.
+func (p *peerConnection) ServesSnapshot() bool {
+	snap, ok := p.peer.(SnapPeer)
+	return ok && snap.ServesSnapshot()
+}
+
+// FetchAccountRange sends an account range retrieval request to the remote peer.
+// The peer is marked busy for the node data retrieval until the response arrives.
+func (p *peerConnection) FetchAccountRange(id uint64, root, origin, limit common.Hash) error {
+	// Short circuit if the peer is already fetching
+	if !atomic.CompareAndSwapInt32(&p.stateIdle, 0, 1) {
+		return errAlreadyFetching
+	}
+	p.stateStarted = time.Now()
+
+	go p.peer.(SnapPeer).RequestAccountRange(id, root, origin, limit, snapResponseBytes)
+
+	return nil
+}
+
+// FetchStorageRanges sends a storage ranges retrieval request to the remote peer.
+func (p *peerConnection) FetchStorageRanges(id uint64, root common.Hash, accounts []common.Hash, origin []byte) error {
+	// Short circuit if the peer is already fetching
+	if !atomic.CompareAndSwapInt32(&p.stateIdle, 0, 1) {
+		return errAlreadyFetching
+	}
+	p.stateStarted = time.Now()
+
+	go p.peer.(SnapPeer).RequestStorageRanges(id, root, accounts, origin, nil, snapResponseBytes)
+
+	return nil
+}
+
+// FetchByteCodes sends a contract code retrieval request to the remote peer.
+func (p *peerConnection) FetchByteCodes(id uint64, hashes []common.Hash) error {
+	// Short circuit if the peer is already fetching
+	if !atomic.CompareAndSwapInt32(&p.stateIdle, 0, 1) {
+		return errAlreadyFetching
+	}
+	p.stateStarted = time.Now()
+
+	go p.peer.(SnapPeer).RequestByteCodes(id, hashes, snapResponseBytes)
+
+	return nil
+}
+
 // SetHeadersIdle sets the peer to idle, allowing it to execute new header retrieval
 // requests. Its estimated header retrieval throughput is updated with that measured
 // just now.
@@ -525,6 +580,20 @@ func (ps *peerSet) NodeDataIdlePeers() ([]*peerConnection, int) {
 	return ps.idlePeers(63, 64, idleCheck, throughput)
 }
 
+// SnapIdlePeers retrieves a flat list of all the currently node-data-idle peers
+// serving the state snapshot within the active peer set, ordered by their reputation.
+func (ps *peerSet) SnapIdlePeers() ([]*peerConnection, int) {
+	idleCheck := func(p *peerConnection) bool {
+		return atomic.LoadInt32(&p.stateIdle) == 0 && p.ServesSnapshot()
+	}
+	throughput := func(p *peerConnection) float64 {
+		p.lock.RLock()
+		defer p.lock.RUnlock()
+		return p.stateThroughput
+	}
+	return ps.idlePeers(63, 64, idleCheck, throughput)
+}
+
 // idlePeers retrieves a flat list of all currently idle peers satisfying the
 // protocol version constraints, using the provided function to check idleness.
 // The resulting set of peers are sorted by their measure throughput.
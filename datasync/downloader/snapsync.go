@@ -0,0 +1,801 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package downloader
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types/account"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+)
+
+const (
+	snapAccountChunks   = 16              // Number of chunks of the account hash space fetched concurrently
+	snapStorageAccounts = 128             // Maximum number of accounts whose storage slots are requested at once
+	snapCodeHashes      = 128             // Maximum number of contract codes requested at once
+	snapResponseBytes   = 512 * 1024      // Soft limit of the response size requested to the peers
+	snapTrieCommitItems = 100000          // Number of entries inserted to a trie being built before flushing its nodes
+	snapLogInterval     = 8 * time.Second // Interval between the progress logs of snap sync
+)
+
+var (
+	emptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+	emptyCode = crypto.Keccak256Hash(nil)
+)
+
+// snapAccountTask is a chunk of the account hash space to fetch.
+type snapAccountTask struct {
+	next common.Hash // Hash of the next account to fetch
+	last common.Hash // Hash of the last account of the chunk
+	busy bool        // Whether a request for the chunk is in flight
+}
+
+// snapStorageTask is a storage trie of an account to fetch.
+type snapStorageTask struct {
+	account   common.Hash // Hash of the account owning the storage trie
+	root      common.Hash // Root hash of the storage trie
+	stateRoot common.Hash // State root the account was fetched at, which the storage is requested at
+	next      common.Hash // Hash of the next storage slot to fetch
+	busy      bool        // Whether a request for the storage is in flight
+}
+
+// snapReq is a snap sync request in flight. Only one of account, storages and
+// codes is set depending on the type of the request.
+type snapReq struct {
+	id   uint64          // Request ID to match up the response with
+	peer *peerConnection // Peer that we're requesting from
+	root common.Hash     // State root requested at
+
+	account  *snapAccountTask   // Account chunk requested
+	storages []*snapStorageTask // Storage tries requested
+	codes    []common.Hash      // Contract codes requested
+
+	timer *time.Timer // Timer to fire when the RTT timeout expires
+}
+
+// snapSyncer fetches the state in ranges of accounts and storage slots with the
+// Merkle proofs from the peers serving the state snapshot, and builds the state
+// tries from the fetched ranges. As the pivot block may move during the sync, the
+// progress is kept over the state roots. The ranges fetched at the different
+// roots are stitched together into the tries, and the trie node sync heals them
+// into the state of the final pivot block afterwards.
+//
+// The fetched ranges are staged in the snapshot database until all the ranges
+// are fetched, and are wiped out after the state tries are built.
+type snapSyncer struct {
+	d *Downloader // Downloader instance to access and manage current peerset
+
+	accountTasks []*snapAccountTask               // Chunks of the account hash space being fetched
+	storageTasks map[common.Hash]*snapStorageTask // Storage tries being fetched, keyed by account hash
+	codeTasks    map[common.Hash]bool             // Contract codes being fetched, true if requested
+	skipped      map[common.Hash]struct{}         // Accounts whose storage is left to the trie node sync
+	done         bool                             // Whether the state tries are built
+
+	// Status of the sync at the current state root
+	root      common.Hash         // State root currently being synced
+	reqID     uint64              // Last request ID issued, kept over the roots to discard stale responses
+	requests  map[uint64]*snapReq // Requests in flight, keyed by request ID
+	stateless map[string]struct{} // Peers not able to serve the current state root
+
+	accounts, slots, codes uint64             // Number of items fetched
+	bytes                  common.StorageSize // Size of the items fetched
+	logged                 time.Time          // Time of the last progress log
+}
+
+// newSnapSyncer creates a snap syncer splitting the account hash space into the
+// chunks to fetch. Any ranges left in the staging area by an interrupted sync are
+// wiped out, as the tasks are not persisted.
+func newSnapSyncer(d *Downloader) *snapSyncer {
+	ss := &snapSyncer{
+		d:            d,
+		storageTasks: make(map[common.Hash]*snapStorageTask),
+		codeTasks:    make(map[common.Hash]bool),
+		skipped:      make(map[common.Hash]struct{}),
+		logged:       time.Now(),
+	}
+	if err := ss.wipe(); err != nil {
+		logger.Error("Failed to wipe snap sync staging data", "err", err)
+	}
+	var (
+		next = common.Hash{}
+		step = new(big.Int).Div(new(big.Int).Lsh(common.Big1, 256), big.NewInt(snapAccountChunks))
+	)
+	for i := 1; i <= snapAccountChunks; i++ {
+		end := new(big.Int).Mul(step, big.NewInt(int64(i)))
+		last := common.BigToHash(new(big.Int).Sub(end, common.Big1))
+		if i == snapAccountChunks {
+			last = common.HexToHash("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+		}
+		ss.accountTasks = append(ss.accountTasks, &snapAccountTask{next: next, last: last})
+		next = common.BigToHash(end)
+	}
+	return ss
+}
+
+// snap fetches the state of the sync in ranges and builds the state tries from
+// them, unless it is already done by a previous sync.
+func (s *stateSync) snap() error {
+	if s.d.snapSyncer == nil {
+		s.d.snapSyncer = newSnapSyncer(s.d)
+	}
+	if s.d.snapSyncer.done {
+		return nil
+	}
+	return s.d.snapSyncer.sync(s)
+}
+
+// sync is the main event loop of the snap sync at the state root of the given
+// state sync. It assigns the tasks to the peers serving the state snapshot and
+// processes the responses, until all the ranges are fetched or no peer is able
+// to serve them. The state tries are built from the fetched ranges afterwards.
+func (ss *snapSyncer) sync(s *stateSync) error {
+	ss.root = s.root
+	ss.requests = make(map[uint64]*snapReq)
+	ss.stateless = make(map[string]struct{})
+
+	timeout := make(chan *snapReq) // Timed out active requests
+	quit := make(chan struct{})    // Channel to stop the timers from firing
+	defer func() {
+		// Revert the requests in flight for the next sync, setting the peers idle
+		close(quit)
+		for _, req := range ss.requests {
+			req.timer.Stop()
+			ss.revert(req)
+			req.peer.SetNodeDataIdle(0, time.Now())
+		}
+		ss.requests = nil
+	}()
+	// Listen for peer events to assign and revoke tasks
+	newPeer := make(chan *peerConnection, 1024)
+	newPeerSub := s.d.peers.SubscribeNewPeers(newPeer)
+	defer newPeerSub.Unsubscribe()
+
+	peerDrop := make(chan *peerConnection, 1024)
+	peerDropSub := s.d.peers.SubscribePeerDrops(peerDrop)
+	defer peerDropSub.Unsubscribe()
+
+	logger.Info("Starting snap sync", "root", ss.root, "pendingAccountChunks", len(ss.accountTasks), "pendingStorage", len(ss.storageTasks), "pendingCodes", len(ss.codeTasks))
+	for !ss.finished() {
+		ss.assignTasks(timeout, quit)
+		if len(ss.requests) == 0 && !ss.servable() {
+			logger.Warn("No peers to snap sync the state from, leaving the rest to trie node sync", "root", ss.root)
+			break
+		}
+		select {
+		case <-newPeer:
+			// New peer arrived, try to assign it download tasks
+
+		case <-s.cancel:
+			return errCancelStateFetch
+
+		case <-s.d.cancelCh:
+			return errCanceled
+
+		case p := <-peerDrop:
+			// Revert the request of the dropped peer, if any
+			for id, req := range ss.requests {
+				if req.peer.id == p.id {
+					req.timer.Stop()
+					ss.revert(req)
+					delete(ss.requests, id)
+				}
+			}
+
+		case req := <-timeout:
+			// Skip if the request has been answered simultaneously
+			if ss.requests[req.id] != req {
+				continue
+			}
+			delete(ss.requests, req.id)
+			ss.revert(req)
+			req.peer.SetNodeDataIdle(0, time.Now())
+
+		case pack := <-s.snapDeliver:
+			// Discard any data not requested (or previously timed out)
+			req := ss.requests[snapPackID(pack)]
+			if req == nil || req.peer.id != pack.PeerId() {
+				logger.Debug("Unrequested snap response", "peer", pack.PeerId(), "len", pack.Items())
+				continue
+			}
+			req.timer.Stop()
+			delete(ss.requests, req.id)
+
+			deliveryTime := time.Now()
+			delivered, err := ss.process(req, pack)
+			if err != nil {
+				logger.Error("Snap sync data write error", "err", err)
+				return err
+			}
+			req.peer.SetNodeDataIdle(delivered, deliveryTime)
+			ss.report(false)
+		}
+	}
+	ss.report(true)
+	return ss.build()
+}
+
+// finished returns whether all the ranges are fetched.
+func (ss *snapSyncer) finished() bool {
+	return len(ss.accountTasks) == 0 && len(ss.storageTasks) == 0 && len(ss.codeTasks) == 0
+}
+
+// servable returns whether any peer is able to serve the current state root.
+func (ss *snapSyncer) servable() bool {
+	for _, p := range ss.d.peers.AllPeers() {
+		if _, ok := ss.stateless[p.id]; !ok && p.ServesSnapshot() {
+			return true
+		}
+	}
+	return false
+}
+
+// assignTasks assigns the tasks to all the idle peers serving the state snapshot,
+// sending the requests to them.
+func (ss *snapSyncer) assignTasks(timeout chan *snapReq, quit chan struct{}) {
+	peers, _ := ss.d.peers.SnapIdlePeers()
+	for _, p := range peers {
+		if _, ok := ss.stateless[p.id]; ok {
+			continue
+		}
+		req := ss.nextRequest(p)
+		if req == nil {
+			return
+		}
+		var err error
+		switch {
+		case req.account != nil:
+			err = p.FetchAccountRange(req.id, req.root, req.account.next, req.account.last)
+		case len(req.storages) > 0:
+			accounts := make([]common.Hash, len(req.storages))
+			for i, task := range req.storages {
+				accounts[i] = task.account
+			}
+			var origin []byte
+			if next := req.storages[0].next; next != (common.Hash{}) {
+				origin = next[:]
+			}
+			err = p.FetchStorageRanges(req.id, req.root, accounts, origin)
+		default:
+			err = p.FetchByteCodes(req.id, req.codes)
+		}
+		if err != nil {
+			ss.revert(req)
+			continue
+		}
+		req.timer = time.AfterFunc(ss.d.requestTTL(), func() {
+			select {
+			case timeout <- req:
+			case <-quit:
+				// Prevent leaking of timer goroutines after the sync exits.
+			}
+		})
+		ss.requests[req.id] = req
+	}
+}
+
+// nextRequest creates the next request to send to the peer, preferring the
+// contract codes and the storage tries to the accounts to keep the pending tasks
+// small. It returns nil if there is no task left to assign.
+func (ss *snapSyncer) nextRequest(p *peerConnection) *snapReq {
+	ss.reqID++
+	req := &snapReq{id: ss.reqID, peer: p, root: ss.root}
+
+	for hash, busy := range ss.codeTasks {
+		if len(req.codes) == snapCodeHashes {
+			break
+		}
+		if !busy {
+			ss.codeTasks[hash] = true
+			req.codes = append(req.codes, hash)
+		}
+	}
+	if len(req.codes) > 0 {
+		return req
+	}
+	// A storage trie continuing from a partial range is requested alone, as the
+	// origin applies only to the first account of the request.
+	for _, task := range ss.storageTasks {
+		if task.busy {
+			continue
+		}
+		if len(req.storages) > 0 && (task.stateRoot != req.root || task.next != (common.Hash{})) {
+			continue
+		}
+		task.busy = true
+		req.root = task.stateRoot
+		req.storages = append(req.storages, task)
+		if task.next != (common.Hash{}) || len(req.storages) == snapStorageAccounts {
+			break
+		}
+	}
+	if len(req.storages) > 0 {
+		return req
+	}
+	for _, task := range ss.accountTasks {
+		if !task.busy {
+			task.busy = true
+			req.account = task
+			return req
+		}
+	}
+	return nil
+}
+
+// revert puts the tasks of the request back to be assigned again.
+func (ss *snapSyncer) revert(req *snapReq) {
+	if req.account != nil {
+		req.account.busy = false
+	}
+	for _, task := range req.storages {
+		task.busy = false
+	}
+	for _, hash := range req.codes {
+		if _, ok := ss.codeTasks[hash]; ok {
+			ss.codeTasks[hash] = false
+		}
+	}
+}
+
+// process verifies and stages the response to the request, returning the number
+// of the items delivered. An invalid or empty response marks the peer as unable to
+// serve the current state root. Only the database failures are returned as errors.
+func (ss *snapSyncer) process(req *snapReq, pack dataPack) (int, error) {
+	ss.revert(req)
+
+	var (
+		delivered int
+		err       error
+	)
+	switch pack := pack.(type) {
+	case *accountRangePack:
+		if req.account == nil {
+			break
+		}
+		delivered, err = ss.processAccounts(req, pack)
+	case *storageRangesPack:
+		if len(req.storages) == 0 {
+			break
+		}
+		delivered, err = ss.processStorage(req, pack)
+	case *byteCodesPack:
+		if len(req.codes) == 0 {
+			break
+		}
+		delivered, err = ss.processCodes(req, pack)
+	}
+	if delivered == 0 && err == nil {
+		ss.stateless[req.peer.id] = struct{}{}
+	}
+	return delivered, err
+}
+
+// processAccounts verifies a range of accounts with the Merkle proofs against the
+// state root, staging the accounts and scheduling their storage tries and codes.
+func (ss *snapSyncer) processAccounts(req *snapReq, pack *accountRangePack) (int, error) {
+	task := req.account
+	if len(pack.hashes) == 0 && len(pack.proof) == 0 {
+		// The peer doesn't have the state in its snapshot
+		return 0, nil
+	}
+	keys := make([][]byte, len(pack.hashes))
+	for i := range pack.hashes {
+		keys[i] = pack.hashes[i][:]
+	}
+	var last []byte
+	if len(keys) > 0 {
+		last = keys[len(keys)-1]
+	}
+	cont, err := statedb.VerifyRangeProof(req.root, task.next[:], last, keys, pack.accounts, newProofDB(pack.proof))
+	if err != nil {
+		logger.Warn("Invalid account range", "peer", req.peer.id, "root", req.root, "origin", task.next, "err", err)
+		return 0, nil
+	}
+	batch := ss.d.stateDB.NewSnapshotDBBatch()
+	for i, hash := range pack.hashes {
+		// Accounts beyond the chunk are given only to prove the range
+		if bytes.Compare(hash[:], task.last[:]) > 0 {
+			cont = false
+			break
+		}
+		if err := ss.schedule(req.root, hash, pack.accounts[i]); err != nil {
+			return 0, err
+		}
+		if err := ss.d.stateDB.PutSnapSyncAccountToBatch(batch, hash, pack.accounts[i]); err != nil {
+			return 0, err
+		}
+		ss.accounts++
+		ss.bytes += common.StorageSize(common.HashLength + len(pack.accounts[i]))
+	}
+	if err := batch.Write(); err != nil {
+		return 0, fmt.Errorf("DB write error: %v", err)
+	}
+	// Move the chunk forward, or remove it if fully fetched
+	if cont {
+		if next, ok := incHash(pack.hashes[len(pack.hashes)-1]); ok && bytes.Compare(next[:], task.last[:]) <= 0 {
+			task.next = next
+			return len(pack.hashes) + 1, nil
+		}
+	}
+	for i, t := range ss.accountTasks {
+		if t == task {
+			ss.accountTasks = append(ss.accountTasks[:i], ss.accountTasks[i+1:]...)
+			break
+		}
+	}
+	return len(pack.hashes) + 1, nil
+}
+
+// schedule adds the storage trie and the code of the fetched account to fetch.
+func (ss *snapSyncer) schedule(stateRoot common.Hash, hash common.Hash, blob []byte) error {
+	serializer := account.NewAccountSerializer()
+	if err := rlp.DecodeBytes(blob, serializer); err != nil {
+		return fmt.Errorf("invalid account %x: %v", hash, err)
+	}
+	pa := account.GetProgramAccount(serializer.GetAccount())
+	if pa == nil {
+		return nil
+	}
+	if root := pa.GetStorageRoot(); root != emptyRoot && root != (common.Hash{}) {
+		ss.storageTasks[hash] = &snapStorageTask{account: hash, root: root, stateRoot: stateRoot}
+	}
+	if code := common.BytesToHash(pa.GetCodeHash()); code != emptyCode {
+		if _, ok := ss.codeTasks[code]; !ok {
+			if has, _ := ss.d.stateDB.HasStateTrieNode(code[:]); !has {
+				ss.codeTasks[code] = false
+			}
+		}
+	}
+	return nil
+}
+
+// processStorage verifies the ranges of storage slots against the storage roots,
+// staging the slots. Only the last range may be partial with the Merkle proofs.
+func (ss *snapSyncer) processStorage(req *snapReq, pack *storageRangesPack) (int, error) {
+	if len(pack.hashes) == 0 && len(pack.proof) == 0 {
+		if req.root != ss.root {
+			// The state of a previous pivot is likely gone from the snapshot of
+			// the peers, so leave the storage tries to the trie node sync.
+			for _, task := range req.storages {
+				ss.skipped[task.account] = struct{}{}
+				delete(ss.storageTasks, task.account)
+			}
+			return 1, nil
+		}
+		// The peer doesn't have the state in its snapshot
+		return 0, nil
+	}
+	if len(pack.hashes) > len(req.storages) || len(pack.slots) != len(pack.hashes) {
+		logger.Warn("Invalid storage ranges", "peer", req.peer.id, "requested", len(req.storages), "ranges", len(pack.hashes))
+		return 0, nil
+	}
+	// Verify all the ranges before staging any of them
+	conts := make([]bool, len(pack.hashes))
+	for i, hashes := range pack.hashes {
+		task := req.storages[i]
+		keys := make([][]byte, len(hashes))
+		for j := range hashes {
+			keys[j] = hashes[j][:]
+		}
+		var (
+			last    []byte
+			proofDB database.DBManager
+		)
+		if len(keys) > 0 {
+			last = keys[len(keys)-1]
+		}
+		if i == len(pack.hashes)-1 && len(pack.proof) > 0 {
+			proofDB = newProofDB(pack.proof)
+		}
+		cont, err := statedb.VerifyRangeProof(task.root, task.next[:], last, keys, pack.slots[i], proofDB)
+		if err != nil {
+			logger.Warn("Invalid storage range", "peer", req.peer.id, "account", task.account, "origin", task.next, "err", err)
+			return 0, nil
+		}
+		conts[i] = cont
+	}
+	batch := ss.d.stateDB.NewSnapshotDBBatch()
+	delivered := 0
+	for i, hashes := range pack.hashes {
+		task := req.storages[i]
+		for j, hash := range hashes {
+			if err := ss.d.stateDB.PutSnapSyncStorageToBatch(batch, task.account, hash, pack.slots[i][j]); err != nil {
+				return 0, err
+			}
+			if batch.ValueSize() >= database.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					return 0, fmt.Errorf("DB write error: %v", err)
+				}
+				batch.Reset()
+			}
+			ss.slots++
+			ss.bytes += common.StorageSize(common.HashLength + len(pack.slots[i][j]))
+		}
+		delivered += len(hashes) + 1
+
+		// Move the storage trie forward, or remove it if fully fetched
+		if conts[i] && len(hashes) > 0 {
+			if next, ok := incHash(hashes[len(hashes)-1]); ok {
+				task.next = next
+				continue
+			}
+		}
+		delete(ss.storageTasks, task.account)
+	}
+	if err := batch.Write(); err != nil {
+		return 0, fmt.Errorf("DB write error: %v", err)
+	}
+	return delivered, nil
+}
+
+// processCodes verifies the contract codes against the requested hashes, writing
+// them to the database. The codes not delivered are requested again.
+func (ss *snapSyncer) processCodes(req *snapReq, pack *byteCodesPack) (int, error) {
+	requested := make(map[common.Hash]struct{}, len(req.codes))
+	for _, hash := range req.codes {
+		requested[hash] = struct{}{}
+	}
+	batch := ss.d.stateDB.NewBatch(database.StateTrieDB)
+	delivered := 0
+	for _, code := range pack.codes {
+		hash := crypto.Keccak256Hash(code)
+		if _, ok := requested[hash]; !ok {
+			continue
+		}
+		delete(requested, hash)
+		if _, ok := ss.codeTasks[hash]; !ok {
+			continue
+		}
+		if err := batch.Put(hash[:], code); err != nil {
+			return 0, err
+		}
+		delete(ss.codeTasks, hash)
+		delivered++
+		ss.codes++
+		ss.bytes += common.StorageSize(len(code))
+	}
+	if err := batch.Write(); err != nil {
+		return 0, fmt.Errorf("DB write error: %v", err)
+	}
+	return delivered, nil
+}
+
+// build builds the state tries from the staged ranges, and wipes them out. The
+// accounts whose storage tries or codes are not fully fetched are left out of the
+// account trie, as the trie node sync doesn't descend into the existing trie
+// nodes. They are fetched along with their storage by the trie node sync.
+func (ss *snapSyncer) build() error {
+	start := time.Now()
+	var (
+		triedb   = statedb.NewDatabase(ss.d.stateDB)
+		accTrie  = newTrieBuilder(triedb)
+		inserted int
+		omitted  int
+		keylen   = len(database.SnapSyncAccountPrefix) + common.HashLength
+	)
+	it := ss.d.stateDB.NewSnapshotDBIterator(database.SnapSyncAccountPrefix, nil)
+	for it.Next() {
+		key := it.Key()
+		if len(key) != keylen {
+			continue
+		}
+		hash := common.BytesToHash(key[len(database.SnapSyncAccountPrefix):])
+		ok, err := ss.buildAccount(triedb, hash, it.Value())
+		if err != nil {
+			it.Release()
+			return err
+		}
+		if !ok {
+			omitted++
+			continue
+		}
+		if err := accTrie.update(hash[:], common.CopyBytes(it.Value())); err != nil {
+			it.Release()
+			return err
+		}
+		inserted++
+	}
+	it.Release()
+	if err := it.Error(); err != nil {
+		return err
+	}
+	root, err := accTrie.commit()
+	if err != nil {
+		return err
+	}
+	if err := ss.wipe(); err != nil {
+		return err
+	}
+	ss.done = true
+	ss.accountTasks, ss.storageTasks, ss.codeTasks, ss.skipped = nil, nil, nil, nil
+
+	logger.Info("Built state tries from snap sync", "root", root, "target", ss.root, "accounts", inserted, "omitted", omitted, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// buildAccount builds the storage trie of the staged account, and checks its code.
+// It returns false if the account should be left out of the account trie.
+func (ss *snapSyncer) buildAccount(triedb *statedb.Database, hash common.Hash, blob []byte) (bool, error) {
+	serializer := account.NewAccountSerializer()
+	if err := rlp.DecodeBytes(blob, serializer); err != nil {
+		return false, fmt.Errorf("invalid account %x: %v", hash, err)
+	}
+	pa := account.GetProgramAccount(serializer.GetAccount())
+	if pa == nil {
+		return true, nil
+	}
+	if code := common.BytesToHash(pa.GetCodeHash()); code != emptyCode {
+		if has, _ := ss.d.stateDB.HasStateTrieNode(code[:]); !has {
+			return false, nil
+		}
+	}
+	root := pa.GetStorageRoot()
+	if root == emptyRoot || root == (common.Hash{}) {
+		return true, nil
+	}
+	if _, ok := ss.storageTasks[hash]; ok {
+		return false, nil
+	}
+	if _, ok := ss.skipped[hash]; ok {
+		return false, nil
+	}
+	var (
+		prefix = append(append([]byte{}, database.SnapSyncStoragePrefix...), hash[:]...)
+		keylen = len(prefix) + common.HashLength
+		stTrie = newTrieBuilder(triedb)
+	)
+	it := ss.d.stateDB.NewSnapshotDBIterator(prefix, nil)
+	defer it.Release()
+	for it.Next() {
+		if key := it.Key(); len(key) == keylen {
+			if err := stTrie.update(common.CopyBytes(key[len(prefix):]), common.CopyBytes(it.Value())); err != nil {
+				return false, err
+			}
+		}
+	}
+	if err := it.Error(); err != nil {
+		return false, err
+	}
+	built, err := stTrie.commit()
+	if err != nil {
+		return false, err
+	}
+	return built == root, nil
+}
+
+// wipe deletes all the ranges in the staging area.
+func (ss *snapSyncer) wipe() error {
+	batch := ss.d.stateDB.NewSnapshotDBBatch()
+	for _, prefix := range [][]byte{database.SnapSyncAccountPrefix, database.SnapSyncStoragePrefix} {
+		keylen := len(prefix) + common.HashLength
+		if bytes.Equal(prefix, database.SnapSyncStoragePrefix) {
+			keylen += common.HashLength
+		}
+		it := ss.d.stateDB.NewSnapshotDBIterator(prefix, nil)
+		for it.Next() {
+			if len(it.Key()) != keylen {
+				continue
+			}
+			if err := batch.Delete(common.CopyBytes(it.Key())); err != nil {
+				it.Release()
+				return err
+			}
+			if batch.ValueSize() >= database.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					it.Release()
+					return err
+				}
+				batch.Reset()
+			}
+		}
+		it.Release()
+		if err := it.Error(); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// report logs the progress of the sync every once in a while.
+func (ss *snapSyncer) report(force bool) {
+	if !force && time.Since(ss.logged) < snapLogInterval {
+		return
+	}
+	ss.logged = time.Now()
+	logger.Info("Snap syncing state", "accounts", ss.accounts, "slots", ss.slots, "codes", ss.codes, "size", ss.bytes,
+		"pendingAccountChunks", len(ss.accountTasks), "pendingStorage", len(ss.storageTasks), "pendingCodes", len(ss.codeTasks))
+}
+
+// trieBuilder builds a trie from the entries inserted, flushing the trie nodes
+// to the disk every once in a while to bound the memory usage.
+type trieBuilder struct {
+	triedb  *statedb.Database
+	trie    *statedb.Trie
+	pending int
+}
+
+func newTrieBuilder(triedb *statedb.Database) *trieBuilder {
+	tr, _ := statedb.NewTrie(common.Hash{}, triedb)
+	return &trieBuilder{triedb: triedb, trie: tr}
+}
+
+// update inserts an entry to the trie.
+func (b *trieBuilder) update(key, value []byte) error {
+	if err := b.trie.TryUpdate(key, value); err != nil {
+		return err
+	}
+	if b.pending++; b.pending >= snapTrieCommitItems {
+		_, err := b.commit()
+		return err
+	}
+	return nil
+}
+
+// commit flushes the trie nodes to the disk, returning the root hash of the trie.
+func (b *trieBuilder) commit() (common.Hash, error) {
+	root, err := b.trie.Commit(nil)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if root != emptyRoot {
+		if err := b.triedb.Commit(root, false, 0); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	b.pending = 0
+	b.trie, err = statedb.NewTrie(root, b.triedb)
+	return root, err
+}
+
+// newProofDB returns a database holding the trie nodes of the Merkle proofs.
+func newProofDB(proof [][]byte) database.DBManager {
+	db := database.NewMemoryDBManager()
+	for _, node := range proof {
+		db.WriteMerkleProof(crypto.Keccak256(node), node)
+	}
+	return db
+}
+
+// snapPackID returns the request ID of a snap response.
+func snapPackID(pack dataPack) uint64 {
+	switch pack := pack.(type) {
+	case *accountRangePack:
+		return pack.reqID
+	case *storageRangesPack:
+		return pack.reqID
+	case *byteCodesPack:
+		return pack.reqID
+	}
+	return 0
+}
+
+// incHash returns the hash next to the given one, or false if it overflows.
+func incHash(h common.Hash) (common.Hash, bool) {
+	for i := len(h) - 1; i >= 0; i-- {
+		h[i]++
+		if h[i] != 0 {
+			return h, true
+		}
+	}
+	return h, false
+}
@@ -0,0 +1,344 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package downloader
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/types/account"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+)
+
+// snapTestItems is the maximum number of the accounts or the storage slots
+// served by the tester peers at once, to force the ranges to be split.
+const snapTestItems = 16
+
+// newSnapTester creates a download tester whose genesis state contains contracts
+// with storage slots, to be fetched in ranges by snap sync.
+func newSnapTester() *downloadTester {
+	tester := newTester()
+
+	alloc := blockchain.GenesisAlloc{testAddress: {Balance: big.NewInt(1000000000)}}
+	for i := 1; i <= 64; i++ {
+		storage := make(map[common.Hash]common.Hash)
+		for j := 1; j <= i; j++ {
+			storage[common.BigToHash(big.NewInt(int64(j)))] = common.BigToHash(big.NewInt(int64(i * j)))
+		}
+		alloc[common.BytesToAddress([]byte{0xc0, byte(i)})] = blockchain.GenesisAccount{
+			Code:    []byte{0x60, byte(i), 0x60, 0x00, 0x55},
+			Storage: storage,
+			Balance: big.NewInt(0),
+		}
+	}
+	genesis := (&blockchain.Genesis{Alloc: alloc}).MustCommit(tester.peerDb)
+
+	tester.genesis = genesis
+	tester.ownHashes = []common.Hash{genesis.Hash()}
+	tester.ownHeaders = map[common.Hash]*types.Header{genesis.Hash(): genesis.Header()}
+	tester.ownBlocks = map[common.Hash]*types.Block{genesis.Hash(): genesis}
+	tester.ownReceipts = map[common.Hash]types.Receipts{genesis.Hash(): nil}
+	tester.ownChainTd = map[common.Hash]*big.Int{genesis.Hash(): genesis.BlockScore()}
+	tester.stateDb.GetMemDB().Put(genesis.Root().Bytes(), []byte{0x00})
+
+	// The bloom is not used by snap sync, as the state tries are built locally
+	tester.downloader.stateBloom = nil
+
+	return tester
+}
+
+// testProofCollector collects the trie nodes of Merkle proofs.
+type testProofCollector struct {
+	database.DBManager
+	nodes [][]byte
+}
+
+func (c *testProofCollector) WriteMerkleProof(key, value []byte) {
+	c.nodes = append(c.nodes, value)
+}
+
+// prove returns the Merkle proofs of the origin and the last key of a range.
+func (dlp *downloadTesterPeer) prove(tr *statedb.Trie, origin common.Hash, keys []common.Hash) [][]byte {
+	proof := new(testProofCollector)
+	tr.Prove(origin[:], 0, proof)
+	if len(keys) > 0 {
+		tr.Prove(keys[len(keys)-1][:], 0, proof)
+	}
+	return proof.nodes
+}
+
+// storageTrie opens the storage trie of the account in the state of the root.
+func (dlp *downloadTesterPeer) storageTrie(root, accountHash common.Hash) (*statedb.Trie, error) {
+	triedb := statedb.NewDatabase(dlp.dl.peerDb)
+	accTrie, err := statedb.NewTrie(root, triedb)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := accTrie.TryGet(accountHash[:])
+	if err != nil {
+		return nil, err
+	}
+	storageRoot := emptyRoot
+	if len(blob) > 0 {
+		serializer := account.NewAccountSerializer()
+		if err := rlp.DecodeBytes(blob, serializer); err != nil {
+			return nil, err
+		}
+		if pa := account.GetProgramAccount(serializer.GetAccount()); pa != nil {
+			storageRoot = pa.GetStorageRoot()
+		}
+	}
+	return statedb.NewTrie(storageRoot, triedb)
+}
+
+// ServesSnapshot returns whether the peer serves the state ranges for snap sync.
+func (dlp *downloadTesterPeer) ServesSnapshot() bool {
+	return dlp.snap
+}
+
+// RequestAccountRange serves a range of the account trie of the peer database,
+// with at most snapTestItems accounts.
+func (dlp *downloadTesterPeer) RequestAccountRange(id uint64, root, origin, limit common.Hash, bytes uint64) error {
+	dlp.waitDelay()
+
+	dlp.dl.lock.RLock()
+	defer dlp.dl.lock.RUnlock()
+
+	tr, err := statedb.NewTrie(root, statedb.NewDatabase(dlp.dl.peerDb))
+	if err != nil {
+		go dlp.dl.downloader.DeliverAccountRange(dlp.id, id, nil, nil, nil)
+		return nil
+	}
+	var (
+		hashes   []common.Hash
+		accounts [][]byte
+	)
+	it := statedb.NewIterator(tr.NodeIterator(origin[:]))
+	for len(hashes) < snapTestItems && it.Next() {
+		hash := common.BytesToHash(it.Key)
+		hashes = append(hashes, hash)
+		accounts = append(accounts, common.CopyBytes(it.Value))
+		if hash.Big().Cmp(limit.Big()) >= 0 {
+			break
+		}
+	}
+	go dlp.dl.downloader.DeliverAccountRange(dlp.id, id, hashes, accounts, dlp.prove(tr, origin, hashes))
+
+	return nil
+}
+
+// RequestStorageRanges serves the ranges of the storage tries of the peer
+// database, with at most snapTestItems slots in total.
+func (dlp *downloadTesterPeer) RequestStorageRanges(id uint64, root common.Hash, accounts []common.Hash, origin, limit []byte, bytes uint64) error {
+	dlp.waitDelay()
+
+	dlp.dl.lock.RLock()
+	defer dlp.dl.lock.RUnlock()
+
+	var (
+		hashes [][]common.Hash
+		slots  [][][]byte
+		proof  [][]byte
+		served int
+	)
+	for i, accountHash := range accounts {
+		if served >= snapTestItems {
+			break
+		}
+		tr, err := dlp.storageTrie(root, accountHash)
+		if err != nil {
+			go dlp.dl.downloader.DeliverStorageRanges(dlp.id, id, nil, nil, nil)
+			return nil
+		}
+		var start common.Hash
+		if i == 0 && len(origin) > 0 {
+			start = common.BytesToHash(origin)
+		}
+		var (
+			keys    []common.Hash
+			values  [][]byte
+			aborted bool
+		)
+		it := statedb.NewIterator(tr.NodeIterator(start[:]))
+		for it.Next() {
+			if served >= snapTestItems {
+				aborted = true
+				break
+			}
+			keys = append(keys, common.BytesToHash(it.Key))
+			values = append(values, common.CopyBytes(it.Value))
+			served++
+		}
+		hashes = append(hashes, keys)
+		slots = append(slots, values)
+
+		// A partial range of the storage trie needs to be proven, which ends the response
+		if start != (common.Hash{}) || aborted {
+			proof = dlp.prove(tr, start, keys)
+			break
+		}
+	}
+	go dlp.dl.downloader.DeliverStorageRanges(dlp.id, id, hashes, slots, proof)
+
+	return nil
+}
+
+// RequestByteCodes serves the contract codes of the peer database.
+func (dlp *downloadTesterPeer) RequestByteCodes(id uint64, hashes []common.Hash, bytes uint64) error {
+	dlp.waitDelay()
+
+	dlp.dl.lock.RLock()
+	defer dlp.dl.lock.RUnlock()
+
+	codes := make([][]byte, 0, len(hashes))
+	for _, hash := range hashes {
+		if code, err := dlp.dl.peerDb.GetMemDB().Get(hash.Bytes()); err == nil {
+			codes = append(codes, code)
+		}
+	}
+	go dlp.dl.downloader.DeliverByteCodes(dlp.id, id, codes)
+
+	return nil
+}
+
+// assertSnapState checks that the state of the given root is complete in the
+// tester database, including all the storage tries and the contract codes, and
+// returns the number of the contracts found.
+func assertSnapState(t *testing.T, tester *downloadTester, root common.Hash) int {
+	triedb := statedb.NewDatabase(tester.stateDb)
+	tr, err := statedb.NewTrie(root, triedb)
+	if err != nil {
+		t.Fatalf("failed to open the account trie: %v", err)
+	}
+	contracts := 0
+	it := statedb.NewIterator(tr.NodeIterator(nil))
+	for it.Next() {
+		serializer := account.NewAccountSerializer()
+		if err := rlp.DecodeBytes(it.Value, serializer); err != nil {
+			t.Fatalf("failed to decode the account %x: %v", it.Key, err)
+		}
+		pa := account.GetProgramAccount(serializer.GetAccount())
+		if pa == nil {
+			continue
+		}
+		contracts++
+		stTrie, err := statedb.NewTrie(pa.GetStorageRoot(), triedb)
+		if err != nil {
+			t.Fatalf("failed to open the storage trie of %x: %v", it.Key, err)
+		}
+		stIt := stTrie.NodeIterator(nil)
+		for stIt.Next(true) {
+		}
+		if err := stIt.Error(); err != nil {
+			t.Fatalf("incomplete storage trie of %x: %v", it.Key, err)
+		}
+		if codeHash := pa.GetCodeHash(); !bytes.Equal(codeHash, emptyCode[:]) {
+			code, err := tester.stateDb.GetMemDB().Get(codeHash)
+			if err != nil || crypto.Keccak256Hash(code) != common.BytesToHash(codeHash) {
+				t.Fatalf("missing code of %x: %v", it.Key, err)
+			}
+		}
+	}
+	if it.Err != nil {
+		t.Fatalf("incomplete account trie: %v", it.Err)
+	}
+	return contracts
+}
+
+// assertSnapStagingWiped checks that no ranges are left in the staging area.
+func assertSnapStagingWiped(t *testing.T, tester *downloadTester) {
+	for _, prefix := range [][]byte{database.SnapSyncAccountPrefix, database.SnapSyncStoragePrefix} {
+		it := tester.stateDb.GetMemDB().NewIterator(prefix, nil)
+		for it.Next() {
+			if len(it.Key()) == len(prefix)+common.HashLength || len(it.Key()) == len(prefix)+2*common.HashLength {
+				t.Fatalf("staged range left: %x", it.Key())
+			}
+		}
+		it.Release()
+	}
+}
+
+// Tests that snap sync fetches the state of the pivot block in ranges from the
+// peers serving the snapshot, and builds the complete state tries from them.
+func TestSnapSync63(t *testing.T) { testSnapSync(t, 63) }
+func TestSnapSync64(t *testing.T) { testSnapSync(t, 64) }
+
+func testSnapSync(t *testing.T, protocol int) {
+	t.Parallel()
+
+	tester := newSnapTester()
+	defer tester.terminate()
+
+	targetBlocks := 3 * fsMinFullBlocks
+	hashes, headers, blocks, receipts := tester.makeChain(targetBlocks, 0, tester.genesis, nil, false)
+	tester.newPeer("peer", protocol, hashes, headers, blocks, receipts)
+	tester.downloader.peers.Peer("peer").peer.(*downloadTesterPeer).snap = true
+
+	if err := tester.sync("peer", nil, SnapSync); err != nil {
+		t.Fatalf("failed to synchronise blocks: %v", err)
+	}
+	assertOwnChain(t, tester, targetBlocks+1)
+
+	syncer := tester.downloader.snapSyncer
+	if syncer == nil || !syncer.done {
+		t.Fatalf("state tries not built by snap sync")
+	}
+	if syncer.accounts == 0 || syncer.slots == 0 || syncer.codes == 0 {
+		t.Fatalf("state ranges not fetched: accounts %d, slots %d, codes %d", syncer.accounts, syncer.slots, syncer.codes)
+	}
+	pivot := targetBlocks - fsMinFullBlocks
+	if contracts := assertSnapState(t, tester, headers[hashes[len(hashes)-1-pivot]].Root); contracts != 64 {
+		t.Fatalf("contracts mismatch: have %d, want %d", contracts, 64)
+	}
+	assertSnapStagingWiped(t, tester)
+}
+
+// Tests that snap sync falls back to the trie node sync if no peer serves the
+// state ranges.
+func TestSnapSyncNoSnapPeers63(t *testing.T) { testSnapSyncNoSnapPeers(t, 63) }
+func TestSnapSyncNoSnapPeers64(t *testing.T) { testSnapSyncNoSnapPeers(t, 64) }
+
+func testSnapSyncNoSnapPeers(t *testing.T, protocol int) {
+	t.Parallel()
+
+	tester := newSnapTester()
+	defer tester.terminate()
+
+	targetBlocks := 3 * fsMinFullBlocks
+	hashes, headers, blocks, receipts := tester.makeChain(targetBlocks, 0, tester.genesis, nil, false)
+	tester.newPeer("peer", protocol, hashes, headers, blocks, receipts)
+
+	if err := tester.sync("peer", nil, SnapSync); err != nil {
+		t.Fatalf("failed to synchronise blocks: %v", err)
+	}
+	assertOwnChain(t, tester, targetBlocks+1)
+
+	if syncer := tester.downloader.snapSyncer; syncer != nil && syncer.accounts != 0 {
+		t.Fatalf("state ranges fetched without snap peers: %d accounts", syncer.accounts)
+	}
+	pivot := targetBlocks - fsMinFullBlocks
+	if contracts := assertSnapState(t, tester, headers[hashes[len(hashes)-1-pivot]].Root); contracts != 64 {
+		t.Fatalf("contracts mismatch: have %d, want %d", contracts, 64)
+	}
+}
@@ -82,6 +82,8 @@ func (d *Downloader) stateFetcher() {
 			}
 		case <-d.stateCh:
 			// Ignore state responses while no sync is running.
+		case <-d.snapCh:
+			// Ignore snap responses while no sync is running.
 		case <-d.quitCh:
 			return
 		}
@@ -92,9 +94,10 @@ func (d *Downloader) stateFetcher() {
 // hash is requested to be switched over to.
 func (d *Downloader) runStateSync(s *stateSync) *stateSync {
 	var (
-		active   = make(map[string]*stateReq) // Currently in-flight requests
-		finished []*stateReq                  // Completed or failed requests
-		timeout  = make(chan *stateReq)       // Timed out active requests
+		active    = make(map[string]*stateReq) // Currently in-flight requests
+		finished  []*stateReq                  // Completed or failed requests
+		timeout   = make(chan *stateReq)       // Timed out active requests
+		snapPacks []dataPack                   // Snap responses not yet passed to the sync
 	)
 	defer func() {
 		// Cancel active request timers on exit. Also set peers to idle so they're
@@ -123,6 +126,14 @@ func (d *Downloader) runStateSync(s *stateSync) *stateSync {
 			deliverReq = finished[0]
 			deliverReqCh = s.deliver
 		}
+		var (
+			snapPack   dataPack
+			snapPackCh chan dataPack
+		)
+		if len(snapPacks) > 0 {
+			snapPack = snapPacks[0]
+			snapPackCh = s.snapDeliver
+		}
 
 		select {
 		// The stateSync lifecycle:
@@ -139,6 +150,15 @@ func (d *Downloader) runStateSync(s *stateSync) *stateSync {
 			finished[len(finished)-1] = nil
 			finished = finished[:len(finished)-1]
 
+			// Send the next snap response to the current sync:
+		case snapPackCh <- snapPack:
+			snapPacks[0] = nil
+			snapPacks = snapPacks[1:]
+
+			// Buffer incoming snap packs, which are matched up with the requests by the sync:
+		case pack := <-d.snapCh:
+			snapPacks = append(snapPacks, pack)
+
 			// Handle incoming state packs:
 		case pack := <-d.stateCh:
 			// Discard any data not requested (or previously timed out)
@@ -214,7 +234,8 @@ func (d *Downloader) runStateSync(s *stateSync) *stateSync {
 // stateSync schedules requests for downloading a particular state trie defined
 // by a given state root.
 type stateSync struct {
-	d *Downloader // Downloader instance to access and manage current peerset
+	d    *Downloader // Downloader instance to access and manage current peerset
+	root common.Hash // State root currently being synced
 
 	sched  *statedb.TrieSync          // State trie sync scheduler defining the tasks
 	keccak hash.Hash                  // Keccak256 hasher to verify deliveries with
@@ -223,11 +244,12 @@ type stateSync struct {
 	numUncommitted   int
 	bytesUncommitted int
 
-	deliver    chan *stateReq // Delivery channel multiplexing peer responses
-	cancel     chan struct{}  // Channel to signal a termination request
-	cancelOnce sync.Once      // Ensures cancel only ever gets called once
-	done       chan struct{}  // Channel to signal termination completion
-	err        error          // Any error hit during sync (set before completion)
+	deliver     chan *stateReq // Delivery channel multiplexing peer responses
+	snapDeliver chan dataPack  // Delivery channel of the snap responses
+	cancel      chan struct{}  // Channel to signal a termination request
+	cancelOnce  sync.Once      // Ensures cancel only ever gets called once
+	done        chan struct{}  // Channel to signal termination completion
+	err         error          // Any error hit during sync (set before completion)
 }
 
 // stateTask represents a single trie node download task, containing a set of
@@ -240,21 +262,32 @@ type stateTask struct {
 // yet start the sync. The user needs to call run to initiate.
 func newStateSync(d *Downloader, root common.Hash) *stateSync {
 	return &stateSync{
-		d:       d,
-		sched:   state.NewStateSync(root, d.stateDB, d.stateBloom, nil),
-		keccak:  sha3.NewKeccak256(),
-		tasks:   make(map[common.Hash]*stateTask),
-		deliver: make(chan *stateReq),
-		cancel:  make(chan struct{}),
-		done:    make(chan struct{}),
+		d:           d,
+		root:        root,
+		sched:       state.NewStateSync(root, d.stateDB, d.stateBloom, nil),
+		keccak:      sha3.NewKeccak256(),
+		tasks:       make(map[common.Hash]*stateTask),
+		deliver:     make(chan *stateReq),
+		snapDeliver: make(chan dataPack),
+		cancel:      make(chan struct{}),
+		done:        make(chan struct{}),
 	}
 }
 
 // run starts the task assignment and response processing loop, blocking until
 // it finishes, and finally notifying any goroutines waiting for the loop to
-// finish.
+// finish. In snap sync, the state is fetched in ranges first, and the loop only
+// heals the state tries afterwards.
 func (s *stateSync) run() {
-	s.err = s.loop()
+	if s.d.getMode() == SnapSync {
+		if s.err = s.snap(); s.err == nil {
+			// Reschedule the trie sync over the state tries built from the ranges
+			s.sched = state.NewStateSync(s.root, s.d.stateDB, s.d.stateBloom, nil)
+		}
+	}
+	if s.err == nil {
+		s.err = s.loop()
+	}
 	close(s.done)
 }
 
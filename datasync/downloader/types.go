@@ -24,6 +24,7 @@ import (
 	"fmt"
 
 	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
 )
 
 // peerDropFn is a callback type for dropping a peer detected as malicious.
@@ -77,3 +78,41 @@ type statePack struct {
 func (p *statePack) PeerId() string { return p.peerId }
 func (p *statePack) Items() int     { return len(p.states) }
 func (p *statePack) Stats() string  { return fmt.Sprintf("%d", len(p.states)) }
+
+// accountRangePack is a range of accounts with the Merkle proofs returned by a peer.
+type accountRangePack struct {
+	peerId   string
+	reqID    uint64
+	hashes   []common.Hash
+	accounts [][]byte
+	proof    [][]byte
+}
+
+func (p *accountRangePack) PeerId() string { return p.peerId }
+func (p *accountRangePack) Items() int     { return len(p.accounts) }
+func (p *accountRangePack) Stats() string  { return fmt.Sprintf("%d", len(p.accounts)) }
+
+// storageRangesPack is a batch of storage slot ranges with the Merkle proofs
+// returned by a peer.
+type storageRangesPack struct {
+	peerId string
+	reqID  uint64
+	hashes [][]common.Hash
+	slots  [][][]byte
+	proof  [][]byte
+}
+
+func (p *storageRangesPack) PeerId() string { return p.peerId }
+func (p *storageRangesPack) Items() int     { return len(p.slots) }
+func (p *storageRangesPack) Stats() string  { return fmt.Sprintf("%d", len(p.slots)) }
+
+// byteCodesPack is a batch of contract codes returned by a peer.
+type byteCodesPack struct {
+	peerId string
+	reqID  uint64
+	codes  [][]byte
+}
+
+func (p *byteCodesPack) PeerId() string { return p.peerId }
+func (p *byteCodesPack) Items() int     { return len(p.codes) }
+func (p *byteCodesPack) Stats() string  { return fmt.Sprintf("%d", len(p.codes)) }
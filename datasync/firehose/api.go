@@ -0,0 +1,72 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package firehose
+
+import (
+	"context"
+
+	"github.com/klaytn/klaytn/networks/rpc"
+)
+
+type PublicFirehoseAPI struct {
+	f *Firehose
+}
+
+func NewPublicFirehoseAPI(f *Firehose) *PublicFirehoseAPI {
+	return &PublicFirehoseAPI{f: f}
+}
+
+// Blocks streams the messages of the blocks in order. If a cursor is given, the stream is resumed
+// from the block following the cursor. Otherwise, it starts from the next new block.
+func (api *PublicFirehoseAPI) Blocks(ctx context.Context, cursor *string) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	from, err := api.f.startBlock(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	quit := make(chan struct{})
+	go func() {
+		select {
+		case <-rpcSub.Err():
+		case <-notifier.Closed():
+		}
+		close(quit)
+	}()
+	api.f.wg.Add(1)
+	go func() {
+		defer api.f.wg.Done()
+		send := func(msg *BlockMessage) error {
+			return notifier.Notify(rpcSub.ID, msg)
+		}
+		if err := api.f.stream(from, send, quit); err != nil {
+			logger.Error("firehose stream is stopped", "subscription", rpcSub.ID, "err", err)
+		}
+	}()
+	return rpcSub, nil
+}
+
+// Head returns the cursor of the head block.
+func (api *PublicFirehoseAPI) Head() string {
+	head := api.f.blockchain.CurrentBlock()
+	return Cursor{Number: head.NumberU64(), Hash: head.Hash()}.String()
+}
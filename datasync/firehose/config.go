@@ -0,0 +1,31 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package firehose
+
+const DefaultBlockChannelSize = 500
+
+type FirehoseConfig struct {
+	EnabledFirehose  bool
+	NoStateDiff      bool // Do not re-execute the blocks to stream the state changes of the transactions
+	BlockChannelSize int  // Size of the channel receiving the chain events
+}
+
+var DefaultFirehoseConfig = &FirehoseConfig{
+	EnabledFirehose:  false,
+	NoStateDiff:      false,
+	BlockChannelSize: DefaultBlockChannelSize,
+}
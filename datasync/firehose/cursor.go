@@ -0,0 +1,53 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package firehose
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+)
+
+// Cursor identifies a block message of the stream. It is encoded as "<block number>:<block hash>".
+type Cursor struct {
+	Number uint64
+	Hash   common.Hash
+}
+
+func (c Cursor) String() string {
+	return fmt.Sprintf("%d:%s", c.Number, c.Hash.Hex())
+}
+
+// ParseCursor decodes the given cursor string.
+func ParseCursor(s string) (Cursor, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return Cursor{}, fmt.Errorf("invalid cursor: %q", s)
+	}
+	number, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor: %q", s)
+	}
+	hash, err := hexutil.Decode(parts[1])
+	if err != nil || len(hash) != common.HashLength {
+		return Cursor{}, fmt.Errorf("invalid cursor: %q", s)
+	}
+	return Cursor{Number: number, Hash: common.BytesToHash(hash)}, nil
+}
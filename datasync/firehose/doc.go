@@ -0,0 +1,31 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package firehose implements a streaming service which pushes the fully decoded blocks,
+receipts and per-transaction state changes to indexers in real time.
+
+The stream is served as the "blocks" subscription of the "firehose" namespace, so it can be
+consumed over the Subscribe method of the gRPC endpoint as well as over WebSocket and IPC.
+Each message carries a cursor, and a subscriber can resume the stream right after the last
+message it processed by subscribing with the cursor of the message.
+Source Files
+  - api.go      : includes the firehose APIs
+  - config.go   : includes the firehose configurations
+  - cursor.go   : implements the cursor which identifies a position of the stream
+  - firehose.go : implements the firehose service and the stream of the block messages
+*/
+package firehose
@@ -0,0 +1,229 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package firehose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	klaytnApi "github.com/klaytn/klaytn/api"
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/networks/p2p"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/node"
+	"github.com/klaytn/klaytn/node/cn"
+)
+
+var logger = log.NewModuleLogger(log.Firehose)
+
+var errNonCanonicalCursor = errors.New("the cursor is not on the canonical chain")
+
+type BlockChain interface {
+	SubscribeChainEvent(ch chan<- blockchain.ChainEvent) event.Subscription
+	CurrentBlock() *types.Block
+	GetBlockByNumber(number uint64) *types.Block
+	GetReceiptsByBlockHash(blockHash common.Hash) types.Receipts
+	GetTd(hash common.Hash, number uint64) *big.Int
+}
+
+// StateDiffer returns the state changes made by the transactions of a block.
+type StateDiffer interface {
+	StateDiffBlockByNumber(ctx context.Context, number rpc.BlockNumber) ([]*cn.TxStateDiff, error)
+}
+
+// BlockMessage is a message of the stream. The transactions of the block contain their receipts.
+type BlockMessage struct {
+	Cursor     string                 `json:"cursor"`
+	Block      map[string]interface{} `json:"block"`
+	StateDiffs []*cn.TxStateDiff      `json:"stateDiffs,omitempty"`
+}
+
+// Firehose streams the blocks, the receipts and the state changes of the transactions to the subscribers.
+type Firehose struct {
+	config *FirehoseConfig
+
+	blockchain  BlockChain
+	stateDiffer StateDiffer
+
+	quitCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+func NewFirehose(ctx *node.ServiceContext, cfg *FirehoseConfig) (*Firehose, error) {
+	return &Firehose{
+		config: cfg,
+		quitCh: make(chan struct{}),
+	}, nil
+}
+
+func (f *Firehose) Protocols() []p2p.Protocol {
+	return []p2p.Protocol{}
+}
+
+func (f *Firehose) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "firehose",
+			Version:   "1.0",
+			Service:   NewPublicFirehoseAPI(f),
+			Public:    true,
+		},
+	}
+}
+
+func (f *Firehose) Start(server p2p.Server) error {
+	logger.Info("firehose is started", "stateDiff", !f.config.NoStateDiff)
+	return nil
+}
+
+func (f *Firehose) Stop() error {
+	close(f.quitCh)
+	f.wg.Wait()
+	logger.Info("firehose is stopped")
+	return nil
+}
+
+func (f *Firehose) Components() []interface{} {
+	return nil
+}
+
+func (f *Firehose) SetComponents(components []interface{}) {
+	for _, component := range components {
+		switch v := component.(type) {
+		case *blockchain.BlockChain:
+			f.blockchain = v
+		case []rpc.API:
+			for _, a := range v {
+				if s, ok := a.Service.(*cn.PrivateDebugAPI); ok {
+					f.stateDiffer = s
+				}
+			}
+		}
+	}
+	if f.stateDiffer == nil && !f.config.NoStateDiff {
+		logger.Warn("the state changes are not streamed since the debug API is not available")
+	}
+}
+
+// startBlock returns the first block of a stream resumed from the given cursor.
+// If the cursor is not given, the stream starts from the next block of the head.
+func (f *Firehose) startBlock(cursor *string) (uint64, error) {
+	if cursor == nil {
+		return f.blockchain.CurrentBlock().NumberU64() + 1, nil
+	}
+	c, err := ParseCursor(*cursor)
+	if err != nil {
+		return 0, err
+	}
+	block := f.blockchain.GetBlockByNumber(c.Number)
+	if block == nil || block.Hash() != c.Hash {
+		return 0, errNonCanonicalCursor
+	}
+	return c.Number + 1, nil
+}
+
+// stream sends the messages of the blocks in order, starting from the given block number, to the head
+// and then the following new blocks. It returns when sending a message fails or the quit channel is closed.
+func (f *Firehose) stream(from uint64, send func(*BlockMessage) error, quit <-chan struct{}) error {
+	// The chain events only wake up the stream, and the blocks are read from the database.
+	// So a slow subscriber never blocks the block insertion.
+	chainCh := make(chan blockchain.ChainEvent, f.config.BlockChannelSize)
+	chainSub := f.blockchain.SubscribeChainEvent(chainCh)
+	defer chainSub.Unsubscribe()
+
+	wakeCh := make(chan struct{}, 1)
+	go func() {
+		for {
+			select {
+			case <-chainCh:
+				select {
+				case wakeCh <- struct{}{}:
+				default:
+				}
+			case <-chainSub.Err():
+				return
+			}
+		}
+	}()
+
+	next := from
+	for {
+		for head := f.blockchain.CurrentBlock().NumberU64(); next <= head; next++ {
+			select {
+			case <-quit:
+				return nil
+			case <-f.quitCh:
+				return nil
+			default:
+			}
+
+			block := f.blockchain.GetBlockByNumber(next)
+			if block == nil {
+				return fmt.Errorf("block %d is not found", next)
+			}
+			msg, err := f.makeMessage(block)
+			if err != nil {
+				return err
+			}
+			if err := send(msg); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-wakeCh:
+		case <-quit:
+			return nil
+		case <-f.quitCh:
+			return nil
+		}
+	}
+}
+
+func (f *Firehose) makeMessage(block *types.Block) (*BlockMessage, error) {
+	hash, number := block.Hash(), block.NumberU64()
+	txs := block.Transactions()
+	receipts := f.blockchain.GetReceiptsByBlockHash(hash)
+	if len(receipts) != len(txs) {
+		return nil, fmt.Errorf("block %d: receipts count %d does not match transactions count %d", number, len(receipts), len(txs))
+	}
+
+	r, err := klaytnApi.RpcOutputBlock(block, f.blockchain.GetTd(hash, number), false, false)
+	if err != nil {
+		return nil, err
+	}
+	rpcTransactions := make([]map[string]interface{}, len(txs))
+	for i, tx := range txs {
+		rpcTransactions[i] = klaytnApi.RpcOutputReceipt(tx, hash, number, uint64(i), receipts[i])
+	}
+	r["transactions"] = rpcTransactions
+
+	msg := &BlockMessage{Cursor: Cursor{Number: number, Hash: hash}.String(), Block: r}
+	if !f.config.NoStateDiff && f.stateDiffer != nil {
+		if msg.StateDiffs, err = f.stateDiffer.StateDiffBlockByNumber(context.Background(), rpc.BlockNumber(number)); err != nil {
+			return nil, fmt.Errorf("block %d: failed to get the state changes: %v", number, err)
+		}
+	}
+	return msg, nil
+}
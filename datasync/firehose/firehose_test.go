@@ -0,0 +1,176 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package firehose
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/node/cn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testChain struct {
+	mu     sync.RWMutex
+	blocks []*types.Block
+	feed   event.Feed
+}
+
+func (c *testChain) addBlock() *types.Block {
+	c.mu.Lock()
+	header := &types.Header{Number: big.NewInt(int64(len(c.blocks))), BlockScore: big.NewInt(1), Time: big.NewInt(0)}
+	if len(c.blocks) > 0 {
+		header.ParentHash = c.blocks[len(c.blocks)-1].Hash()
+	}
+	block := types.NewBlockWithHeader(header)
+	c.blocks = append(c.blocks, block)
+	c.mu.Unlock()
+
+	c.feed.Send(blockchain.ChainEvent{Block: block, Hash: block.Hash()})
+	return block
+}
+
+func (c *testChain) SubscribeChainEvent(ch chan<- blockchain.ChainEvent) event.Subscription {
+	return c.feed.Subscribe(ch)
+}
+
+func (c *testChain) CurrentBlock() *types.Block {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.blocks[len(c.blocks)-1]
+}
+
+func (c *testChain) GetBlockByNumber(number uint64) *types.Block {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if number >= uint64(len(c.blocks)) {
+		return nil
+	}
+	return c.blocks[number]
+}
+
+func (c *testChain) GetReceiptsByBlockHash(blockHash common.Hash) types.Receipts {
+	return types.Receipts{}
+}
+
+func (c *testChain) GetTd(hash common.Hash, number uint64) *big.Int {
+	return big.NewInt(int64(number))
+}
+
+type testStateDiffer struct{}
+
+func (testStateDiffer) StateDiffBlockByNumber(ctx context.Context, number rpc.BlockNumber) ([]*cn.TxStateDiff, error) {
+	return []*cn.TxStateDiff{{TxHash: common.Hash{byte(number)}}}, nil
+}
+
+func newTestFirehose(n int) (*Firehose, *testChain) {
+	chain := &testChain{}
+	for i := 0; i < n; i++ {
+		chain.addBlock()
+	}
+	f, _ := NewFirehose(nil, &FirehoseConfig{BlockChannelSize: 1})
+	f.blockchain, f.stateDiffer = chain, testStateDiffer{}
+	return f, chain
+}
+
+func TestCursor(t *testing.T) {
+	c := Cursor{Number: 10, Hash: common.Hash{1}}
+	parsed, err := ParseCursor(c.String())
+	assert.NoError(t, err)
+	assert.Equal(t, c, parsed)
+
+	for _, s := range []string{"", "10", "a:" + c.Hash.Hex(), "10:0x01", "10:" + c.Hash.Hex() + ":1"} {
+		_, err := ParseCursor(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestFirehose_startBlock(t *testing.T) {
+	f, chain := newTestFirehose(3)
+
+	from, err := f.startBlock(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), from)
+
+	cursor := Cursor{Number: 1, Hash: chain.blocks[1].Hash()}.String()
+	from, err = f.startBlock(&cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), from)
+
+	cursor = Cursor{Number: 1, Hash: chain.blocks[2].Hash()}.String()
+	_, err = f.startBlock(&cursor)
+	assert.Equal(t, errNonCanonicalCursor, err)
+
+	cursor = Cursor{Number: 5, Hash: chain.blocks[2].Hash()}.String()
+	_, err = f.startBlock(&cursor)
+	assert.Equal(t, errNonCanonicalCursor, err)
+}
+
+func TestFirehose_stream(t *testing.T) {
+	f, chain := newTestFirehose(3)
+
+	msgCh := make(chan *BlockMessage)
+	quit := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- f.stream(1, func(msg *BlockMessage) error {
+			msgCh <- msg
+			return nil
+		}, quit)
+	}()
+
+	receive := func(number uint64) {
+		select {
+		case msg := <-msgCh:
+			block := chain.GetBlockByNumber(number)
+			assert.Equal(t, Cursor{Number: number, Hash: block.Hash()}.String(), msg.Cursor)
+			assert.Equal(t, block.Hash(), msg.Block["hash"])
+			assert.Equal(t, common.Hash{byte(number)}, msg.StateDiffs[0].TxHash)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout while waiting for block %d", number)
+		}
+	}
+
+	// the existing blocks are sent first
+	receive(1)
+	receive(2)
+
+	// the new blocks are sent in order, without blocking the chain
+	chain.addBlock()
+	chain.addBlock()
+	chain.addBlock()
+	receive(3)
+	receive(4)
+	receive(5)
+
+	close(quit)
+	select {
+	case err := <-errCh:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("timeout while waiting for the stream to be stopped")
+	}
+}
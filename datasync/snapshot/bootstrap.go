@@ -0,0 +1,290 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/log"
+)
+
+var logger = log.NewModuleLogger(log.Snapshot)
+
+// maxManifestSize limits the size of a manifest to be downloaded.
+const maxManifestSize = 16 * 1024 * 1024
+
+var (
+	errNoSigner        = errors.New("trusted signer of the snapshot is not set")
+	errUnsupportedURL  = errors.New("unsupported snapshot location, only http(s):// and s3:// are supported")
+	errInvalidFilePath = errors.New("invalid file path in the snapshot archive")
+)
+
+// Config is the configuration of the snapshot bootstrap.
+type Config struct {
+	URL    string         // location of the manifest, https://host/path/manifest.json or s3://bucket/path/manifest.json
+	Signer common.Address // trusted signer of the manifest
+}
+
+// Bootstrap downloads the snapshot of the manifest at the configured location and unpacks it
+// into dir, which is the chaindata directory of the node. It returns the verified manifest,
+// or nil if dir already has data so that the node keeps its own database.
+//
+// The archives are unpacked into a temporary directory, which is renamed to dir only when all
+// the archives are verified and unpacked, so a failed bootstrap leaves dir untouched.
+func Bootstrap(config *Config, dir string) (*Manifest, error) {
+	if config.Signer == (common.Address{}) {
+		return nil, errNoSigner
+	}
+	if entries, err := ioutil.ReadDir(dir); err == nil && len(entries) > 0 {
+		logger.Info("Skip bootstrapping from the snapshot since chaindata exists", "dir", dir)
+		return nil, nil
+	}
+
+	manifest, err := fetchManifest(config.URL)
+	if err != nil {
+		return nil, err
+	}
+	if err := manifest.Verify(config.Signer); err != nil {
+		return nil, err
+	}
+	logger.Info("Bootstrapping from the snapshot", "url", config.URL, "head", manifest.HeadBlockNumber,
+		"hash", manifest.HeadBlockHash, "files", len(manifest.Files))
+
+	staging := dir + ".snapshot"
+	if err := os.RemoveAll(staging); err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	unpacked := filepath.Join(staging, "chaindata")
+	if err := os.MkdirAll(unpacked, 0700); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	for i, file := range manifest.Files {
+		location, err := resolve(config.URL, file.Name)
+		if err != nil {
+			return nil, err
+		}
+		archive := filepath.Join(staging, fmt.Sprintf("archive-%d", i))
+		if err := download(location, archive, file); err != nil {
+			return nil, fmt.Errorf("failed to download %s: %v", file.Name, err)
+		}
+		if err := unpack(archive, unpacked, strings.HasSuffix(file.Name, ".gz") || strings.HasSuffix(file.Name, ".tgz")); err != nil {
+			return nil, fmt.Errorf("failed to unpack %s: %v", file.Name, err)
+		}
+		if err := os.Remove(archive); err != nil {
+			return nil, err
+		}
+		logger.Info("Unpacked a snapshot archive", "file", file.Name, "size", common.StorageSize(file.Size),
+			"progress", fmt.Sprintf("%d/%d", i+1, len(manifest.Files)), "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0700); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(unpacked, dir); err != nil {
+		return nil, err
+	}
+	logger.Info("Bootstrapped from the snapshot", "dir", dir, "head", manifest.HeadBlockNumber,
+		"elapsed", common.PrettyDuration(time.Since(start)))
+	return manifest, nil
+}
+
+// fetchManifest downloads and decodes the manifest at the given location.
+func fetchManifest(location string) (*Manifest, error) {
+	body, err := Open(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the manifest: %v", err)
+	}
+	defer body.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(body, maxManifestSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the manifest: %v", err)
+	}
+	manifest := new(Manifest)
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %v", err)
+	}
+	return manifest, nil
+}
+
+// resolve returns the location of a file relative to the manifest location.
+func resolve(manifestURL, name string) (string, error) {
+	u, err := url.Parse(manifestURL)
+	if err != nil {
+		return "", err
+	}
+	if path.IsAbs(name) || strings.Contains(name, "..") {
+		return "", fmt.Errorf("invalid file name %q", name)
+	}
+	u.Path = path.Join(path.Dir(u.Path), name)
+	return u.String(), nil
+}
+
+// Open returns the content at the given location, which is an http(s):// or s3:// URL.
+func Open(location string) (io.ReadCloser, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https":
+		resp, err := http.Get(location)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		return resp.Body, nil
+
+	case "s3":
+		// The region and the credentials are given by the environment, such as AWS_REGION
+		sess, err := session.NewSession()
+		if err != nil {
+			return nil, err
+		}
+		out, err := s3.New(sess).GetObject(&s3.GetObjectInput{
+			Bucket: aws.String(u.Host),
+			Key:    aws.String(strings.TrimPrefix(u.Path, "/")),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return out.Body, nil
+
+	default:
+		return nil, errUnsupportedURL
+	}
+}
+
+// download writes the file at the given location into dst, verifying its size and checksum.
+func download(location, dst string, file File) error {
+	body, err := Open(location)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	hasher := sha256.New()
+	written, err := io.Copy(io.MultiWriter(out, hasher), io.LimitReader(body, file.Size+1))
+	if err != nil {
+		return err
+	}
+	if written != file.Size {
+		return fmt.Errorf("size mismatch: have %d, want %d", written, file.Size)
+	}
+	if sum := hasher.Sum(nil); !bytes.Equal(sum, file.SHA256) {
+		return fmt.Errorf("checksum mismatch: have %x, want %x", sum, []byte(file.SHA256))
+	}
+	return out.Sync()
+}
+
+// unpack extracts the regular files and the directories of the tar archive into dir.
+func unpack(archive, dir string, gzipped bool) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if gzipped {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("%w: %s", errInvalidFilePath, header.Name)
+		}
+		target := filepath.Join(dir, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0700); err != nil {
+				return err
+			}
+		case tar.TypeReg, tar.TypeRegA:
+			if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+				return err
+			}
+			if err := writeFile(target, tr); err != nil {
+				return err
+			}
+		default:
+			logger.Warn("Skip an unsupported entry in the snapshot archive", "name", header.Name, "type", header.Typeflag)
+		}
+	}
+}
+
+func writeFile(target string, r io.Reader) error {
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,181 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// makeArchive returns a tar.gz archive of the given files.
+func makeArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// serveSnapshot serves the manifest and the archive, returning the URL of the manifest.
+func serveSnapshot(t *testing.T, manifest *Manifest, archive []byte) *httptest.Server {
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/snapshot/manifest.json", func(w http.ResponseWriter, r *http.Request) { w.Write(manifestJSON) })
+	mux.HandleFunc("/snapshot/chaindata.tar.gz", func(w http.ResponseWriter, r *http.Request) { w.Write(archive) })
+	return httptest.NewServer(mux)
+}
+
+func TestBootstrap(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+
+	archive := makeArchive(t, map[string]string{"header/CURRENT": "MANIFEST-000001", "body/000001.log": "body"})
+	sum := sha256.Sum256(archive)
+	manifest := &Manifest{
+		Version:         ManifestVersion,
+		HeadBlockNumber: 100,
+		HeadBlockHash:   common.HexToHash("0x01"),
+		Files:           []File{{Name: "chaindata.tar.gz", Size: int64(len(archive)), SHA256: sum[:]}},
+	}
+	assert.NoError(t, manifest.Sign(key))
+
+	server := serveSnapshot(t, manifest, archive)
+	defer server.Close()
+
+	tempDir, err := ioutil.TempDir("", "klaytn-snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+	dir := filepath.Join(tempDir, "klay", "chaindata")
+	config := &Config{URL: server.URL + "/snapshot/manifest.json", Signer: signer}
+
+	// Untrusted signer
+	_, err = Bootstrap(&Config{URL: config.URL, Signer: common.Address{0x01}}, dir)
+	assert.Error(t, err)
+	_, err = os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+
+	// Trusted signer
+	verified, err := Bootstrap(config, dir)
+	assert.NoError(t, err)
+	assert.Equal(t, manifest.HeadBlockHash, verified.HeadBlockHash)
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "header", "CURRENT"))
+	assert.NoError(t, err)
+	assert.Equal(t, "MANIFEST-000001", string(content))
+	content, err = ioutil.ReadFile(filepath.Join(dir, "body", "000001.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, "body", string(content))
+
+	_, err = os.Stat(dir + ".snapshot")
+	assert.True(t, os.IsNotExist(err))
+
+	// Skipped if chaindata exists
+	verified, err = Bootstrap(config, dir)
+	assert.NoError(t, err)
+	assert.Nil(t, verified)
+}
+
+func TestBootstrap_ChecksumMismatch(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+
+	archive := makeArchive(t, map[string]string{"header/CURRENT": "MANIFEST-000001"})
+	manifest := &Manifest{
+		Version:         ManifestVersion,
+		HeadBlockNumber: 100,
+		Files:           []File{{Name: "chaindata.tar.gz", Size: int64(len(archive)), SHA256: make([]byte, 32)}},
+	}
+	assert.NoError(t, manifest.Sign(key))
+
+	server := serveSnapshot(t, manifest, archive)
+	defer server.Close()
+
+	tempDir, err := ioutil.TempDir("", "klaytn-snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+	dir := filepath.Join(tempDir, "chaindata")
+
+	_, err = Bootstrap(&Config{URL: server.URL + "/snapshot/manifest.json", Signer: crypto.PubkeyToAddress(key.PublicKey)}, dir)
+	assert.Error(t, err)
+	_, err = os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(dir + ".snapshot")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestManifest_Verify(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+
+	manifest := &Manifest{Version: ManifestVersion, Files: []File{{Name: "a.tar", Size: 1, SHA256: make([]byte, 32)}}}
+	assert.Equal(t, errNoSignature, manifest.Verify(signer))
+
+	assert.NoError(t, manifest.Sign(key))
+	assert.NoError(t, manifest.Verify(signer))
+
+	// Tampered manifest
+	manifest.HeadBlockNumber = 1
+	assert.Error(t, manifest.Verify(signer))
+}
+
+func TestUnpack_InvalidPath(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "klaytn-snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	archive := filepath.Join(tempDir, "archive.tar.gz")
+	if err := ioutil.WriteFile(archive, makeArchive(t, map[string]string{"../evil": "evil"}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	err = unpack(archive, filepath.Join(tempDir, "chaindata"), true)
+	assert.True(t, errors.Is(err, errInvalidFilePath))
+}
@@ -0,0 +1,26 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package snapshot implements bootstrapping a node from a chaindata snapshot stored in S3 or
+served over HTTPS. The snapshot consists of a manifest signed by a trusted signer and the tar
+archives of the chaindata directory listed in the manifest. The node downloads and verifies the
+archives, unpacks them into the datadir and synchronizes the remaining blocks from its peers.
+Source Files
+  - bootstrap.go : implements downloading, verifying and unpacking a snapshot
+  - manifest.go  : defines the signed manifest of a snapshot
+*/
+package snapshot
@@ -0,0 +1,113 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/storage/database"
+)
+
+// ManifestVersion is the version of the manifest format.
+const ManifestVersion = 1
+
+var (
+	errUnsupportedVersion = errors.New("unsupported manifest version")
+	errNoFiles            = errors.New("manifest has no file")
+	errNoSignature        = errors.New("manifest is not signed")
+)
+
+// File is an archive of a snapshot. It is a tar archive of the files in the chaindata directory,
+// which is compressed by gzip if the name ends with ".gz" or ".tgz".
+type File struct {
+	Name   string        `json:"name"` // path relative to the location of the manifest
+	Size   int64         `json:"size"`
+	SHA256 hexutil.Bytes `json:"sha256"`
+}
+
+// Manifest describes a chaindata snapshot. It is signed by the producer of the snapshot,
+// and a node bootstraps only from the snapshots signed by the configured signer.
+type Manifest struct {
+	Version         uint64        `json:"version"`
+	HeadBlockNumber uint64        `json:"headBlockNumber"`
+	HeadBlockHash   common.Hash   `json:"headBlockHash"`
+	Files           []File        `json:"files"`
+	Signature       hexutil.Bytes `json:"signature,omitempty"`
+}
+
+// SigningHash returns the hash of the manifest signed by the producer,
+// which is the hash of the JSON encoding of the manifest without the signature.
+func (m *Manifest) SigningHash() common.Hash {
+	unsigned := *m
+	unsigned.Signature = nil
+	data, _ := json.Marshal(&unsigned)
+	return crypto.Keccak256Hash(data)
+}
+
+// Sign signs the manifest with the given key.
+func (m *Manifest) Sign(key *ecdsa.PrivateKey) error {
+	sig, err := crypto.Sign(m.SigningHash().Bytes(), key)
+	if err != nil {
+		return err
+	}
+	m.Signature = sig
+	return nil
+}
+
+// Verify checks the format of the manifest and that it is signed by the signer.
+func (m *Manifest) Verify(signer common.Address) error {
+	if m.Version != ManifestVersion {
+		return fmt.Errorf("%w: %d", errUnsupportedVersion, m.Version)
+	}
+	if len(m.Files) == 0 {
+		return errNoFiles
+	}
+	for _, file := range m.Files {
+		if file.Name == "" || len(file.SHA256) != 32 {
+			return fmt.Errorf("invalid file entry %q", file.Name)
+		}
+	}
+	if len(m.Signature) == 0 {
+		return errNoSignature
+	}
+	pub, err := crypto.SigToPub(m.SigningHash().Bytes(), m.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid manifest signature: %v", err)
+	}
+	if recovered := crypto.PubkeyToAddress(*pub); recovered != signer {
+		return fmt.Errorf("manifest is signed by %s, not by the trusted signer %s", recovered.String(), signer.String())
+	}
+	return nil
+}
+
+// VerifyHead checks that the head block of the unpacked chaindata is the head block of the manifest.
+func (m *Manifest) VerifyHead(db database.DBManager) error {
+	head := db.ReadHeadBlockHash()
+	if head != m.HeadBlockHash {
+		return fmt.Errorf("head block of the snapshot mismatch: have %s, want %s", head.String(), m.HeadBlockHash.String())
+	}
+	if db.ReadHeader(head, m.HeadBlockNumber) == nil {
+		return fmt.Errorf("missing head block #%d of the snapshot", m.HeadBlockNumber)
+	}
+	return nil
+}
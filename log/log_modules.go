@@ -122,6 +122,14 @@ const (
 	CMDKSEN
 	ChainDataFetcher
 	KAS
+	ChainExport
+	Firehose
+	Plugin
+	Snapshot
+	FeePayer
+	AdminUI
+	BlockchainStateSnapshot
+	ChainArchive
 
 	// ModuleNameLen should be placed at the end of the list.
 	ModuleNameLen
@@ -195,4 +203,12 @@ var moduleNames = [ModuleNameLen]string{
 	"cmd/ksen",
 	"datasync/chaindatafetcher",
 	"kas",
+	"datasync/chainexport",
+	"datasync/firehose",
+	"node/plugin",
+	"datasync/snapshot",
+	"node/feepayer",
+	"node/adminui",
+	"blockchain/state/snapshot",
+	"datasync/chainarchive",
 }
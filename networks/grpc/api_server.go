@@ -0,0 +1,391 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package grpc
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"sync"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// errcodeMethodNotFound is the JSON-RPC error code of an unknown method.
+	errcodeMethodNotFound = -32601
+
+	// subscriptionBufferSize is the number of the notifications buffered for a subscription.
+	subscriptionBufferSize = 128
+)
+
+// NewRPCServer returns an RPC server with the APIs of the given modules registered. If no module is
+// given, the public APIs are registered. The typed services as well as the generic ones of the gRPC
+// server serve only the APIs registered in the RPC server.
+func NewRPCServer(apis []rpc.API, modules []string) (*rpc.Server, error) {
+	whitelist := make(map[string]bool)
+	for _, module := range modules {
+		whitelist[module] = true
+	}
+	handler := rpc.NewServer()
+	for _, api := range apis {
+		if whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
+			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
+				return nil, err
+			}
+			logger.Debug("gRPC registered", "namespace", api.Namespace)
+		}
+	}
+	return handler, nil
+}
+
+// apiServer is an implementation of KlayServer, TxPoolServer and DebugServer. It calls the APIs
+// registered in the RPC server through an in-process client, and converts their results into the
+// typed messages.
+type apiServer struct {
+	client  *rpc.Client
+	modules map[string]string
+	subs    sync.WaitGroup // the running subscriptions
+}
+
+func newAPIServer(handler *rpc.Server) (*apiServer, error) {
+	client := rpc.DialInProc(handler)
+	modules, err := client.SupportedModules()
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &apiServer{client: client, modules: modules}, nil
+}
+
+// close closes the client after the subscriptions are terminated, which should be called after
+// the gRPC server is stopped to cancel the subscriptions.
+func (s *apiServer) close() {
+	s.subs.Wait()
+	s.client.Close()
+}
+
+// call calls the method of the given namespace, returning a gRPC status error if the namespace is
+// not exposed or the call fails.
+func (s *apiServer) call(ctx context.Context, namespace string, result interface{}, method string, args ...interface{}) error {
+	if _, ok := s.modules[namespace]; !ok {
+		return status.Errorf(codes.PermissionDenied, "the %s APIs are not exposed over gRPC", namespace)
+	}
+	if err := s.client.CallContext(ctx, result, namespace+"_"+method, args...); err != nil {
+		return toStatusError(err)
+	}
+	return nil
+}
+
+// toStatusError converts an error of the RPC client into a gRPC status error.
+func toStatusError(err error) error {
+	var rpcErr rpc.Error
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &rpcErr) && rpcErr.ErrorCode() == errcodeMethodNotFound:
+		return status.Error(codes.Unimplemented, err.Error())
+	case err == context.Canceled:
+		return status.Error(codes.Canceled, err.Error())
+	case err == context.DeadlineExceeded:
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Unknown, err.Error())
+	}
+}
+
+func blockArg(block string) string {
+	if block == "" {
+		return "latest"
+	}
+	return block
+}
+
+func addressArg(address []byte) (common.Address, error) {
+	if len(address) != common.AddressLength {
+		return common.Address{}, status.Errorf(codes.InvalidArgument, "invalid address length %d", len(address))
+	}
+	return common.BytesToAddress(address), nil
+}
+
+func hashArg(hash []byte) (common.Hash, error) {
+	if len(hash) != common.HashLength {
+		return common.Hash{}, status.Errorf(codes.InvalidArgument, "invalid hash length %d", len(hash))
+	}
+	return common.BytesToHash(hash), nil
+}
+
+func toHeader(h *types.Header) *Header {
+	return &Header{
+		Hash:             h.Hash().Bytes(),
+		ParentHash:       h.ParentHash.Bytes(),
+		Number:           h.Number.Uint64(),
+		Timestamp:        h.Time.Uint64(),
+		GasUsed:          h.GasUsed,
+		StateRoot:        h.Root.Bytes(),
+		TransactionsRoot: h.TxHash.Bytes(),
+		ReceiptsRoot:     h.ReceiptHash.Bytes(),
+		Rewardbase:       h.Rewardbase.Bytes(),
+		ExtraData:        h.Extra,
+	}
+}
+
+func toLog(l *types.Log) *Log {
+	topics := make([][]byte, len(l.Topics))
+	for i, topic := range l.Topics {
+		topics[i] = topic.Bytes()
+	}
+	return &Log{
+		Address:          l.Address.Bytes(),
+		Topics:           topics,
+		Data:             l.Data,
+		BlockNumber:      l.BlockNumber,
+		TransactionHash:  l.TxHash.Bytes(),
+		TransactionIndex: uint32(l.TxIndex),
+		BlockHash:        l.BlockHash.Bytes(),
+		LogIndex:         uint32(l.Index),
+		Removed:          l.Removed,
+	}
+}
+
+// toFilterCriteria converts the log filter into the criteria of klay_subscribe.
+func toFilterCriteria(filter *LogFilter) (map[string]interface{}, error) {
+	criteria := make(map[string]interface{})
+	if filter == nil {
+		return criteria, nil
+	}
+	addresses := make([]common.Address, len(filter.Addresses))
+	for i, address := range filter.Addresses {
+		addr, err := addressArg(address)
+		if err != nil {
+			return nil, err
+		}
+		addresses[i] = addr
+	}
+	topics := make([][]common.Hash, len(filter.Topics))
+	for i, topicFilter := range filter.Topics {
+		// An empty filter is left nil to match all topics
+		for _, topic := range topicFilter.Topics {
+			hash, err := hashArg(topic)
+			if err != nil {
+				return nil, err
+			}
+			topics[i] = append(topics[i], hash)
+		}
+	}
+	criteria["address"] = addresses
+	criteria["topics"] = topics
+	return criteria, nil
+}
+
+// BlockNumber returns the number of the latest block.
+func (s *apiServer) BlockNumber(ctx context.Context, _ *empty.Empty) (*wrappers.UInt64Value, error) {
+	var number hexutil.Uint64
+	if err := s.call(ctx, "klay", &number, "blockNumber"); err != nil {
+		return nil, err
+	}
+	return &wrappers.UInt64Value{Value: uint64(number)}, nil
+}
+
+// ChainID returns the chain ID.
+func (s *apiServer) ChainID(ctx context.Context, _ *empty.Empty) (*wrappers.BytesValue, error) {
+	var chainID hexutil.Big
+	if err := s.call(ctx, "klay", &chainID, "chainID"); err != nil {
+		return nil, err
+	}
+	return &wrappers.BytesValue{Value: chainID.ToInt().Bytes()}, nil
+}
+
+// GetBalance returns the balance of the account at the given block.
+func (s *apiServer) GetBalance(ctx context.Context, req *AccountRequest) (*wrappers.BytesValue, error) {
+	addr, err := addressArg(req.Address)
+	if err != nil {
+		return nil, err
+	}
+	var balance hexutil.Big
+	if err := s.call(ctx, "klay", &balance, "getBalance", addr, blockArg(req.Block)); err != nil {
+		return nil, err
+	}
+	return &wrappers.BytesValue{Value: balance.ToInt().Bytes()}, nil
+}
+
+// GetTransactionCount returns the nonce of the account at the given block.
+func (s *apiServer) GetTransactionCount(ctx context.Context, req *AccountRequest) (*wrappers.UInt64Value, error) {
+	addr, err := addressArg(req.Address)
+	if err != nil {
+		return nil, err
+	}
+	var nonce hexutil.Uint64
+	if err := s.call(ctx, "klay", &nonce, "getTransactionCount", addr, blockArg(req.Block)); err != nil {
+		return nil, err
+	}
+	return &wrappers.UInt64Value{Value: uint64(nonce)}, nil
+}
+
+// GetHeaderByNumber returns the header of the given block.
+func (s *apiServer) GetHeaderByNumber(ctx context.Context, req *BlockRequest) (*Header, error) {
+	var header *types.Header
+	if err := s.call(ctx, "klay", &header, "getHeaderByNumber", blockArg(req.Block)); err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, status.Errorf(codes.NotFound, "block %s not found", blockArg(req.Block))
+	}
+	return toHeader(header), nil
+}
+
+// SendRawTransaction submits the RLP-encoded transaction and returns its hash.
+func (s *apiServer) SendRawTransaction(ctx context.Context, req *wrappers.BytesValue) (*wrappers.BytesValue, error) {
+	var hash common.Hash
+	if err := s.call(ctx, "klay", &hash, "sendRawTransaction", hexutil.Bytes(req.Value)); err != nil {
+		return nil, err
+	}
+	return &wrappers.BytesValue{Value: hash.Bytes()}, nil
+}
+
+// GetTransactionReceipt returns the receipt of the transaction of the given hash.
+func (s *apiServer) GetTransactionReceipt(ctx context.Context, req *wrappers.BytesValue) (*Receipt, error) {
+	txHash, err := hashArg(req.Value)
+	if err != nil {
+		return nil, err
+	}
+	var receipt *struct {
+		TxHash          common.Hash     `json:"transactionHash"`
+		BlockHash       common.Hash     `json:"blockHash"`
+		BlockNumber     hexutil.Big     `json:"blockNumber"`
+		Status          hexutil.Uint    `json:"status"`
+		GasUsed         hexutil.Uint64  `json:"gasUsed"`
+		ContractAddress *common.Address `json:"contractAddress"`
+		Logs            []*types.Log    `json:"logs"`
+	}
+	if err := s.call(ctx, "klay", &receipt, "getTransactionReceipt", txHash); err != nil {
+		return nil, err
+	}
+	if receipt == nil {
+		return nil, status.Errorf(codes.NotFound, "receipt of transaction %x not found", txHash)
+	}
+	result := &Receipt{
+		TransactionHash: receipt.TxHash.Bytes(),
+		BlockHash:       receipt.BlockHash.Bytes(),
+		BlockNumber:     receipt.BlockNumber.ToInt().Uint64(),
+		Status:          uint64(receipt.Status),
+		GasUsed:         uint64(receipt.GasUsed),
+		Logs:            make([]*Log, len(receipt.Logs)),
+	}
+	if receipt.ContractAddress != nil {
+		result.ContractAddress = receipt.ContractAddress.Bytes()
+	}
+	for i, l := range receipt.Logs {
+		result.Logs[i] = toLog(l)
+	}
+	return result, nil
+}
+
+// Subscribe streams the events of the given subscription until the client cancels it.
+func (s *apiServer) Subscribe(req *SubscribeRequest, stream Klay_SubscribeServer) error {
+	if _, ok := s.modules["klay"]; !ok {
+		return status.Error(codes.PermissionDenied, "the klay APIs are not exposed over gRPC")
+	}
+	s.subs.Add(1)
+	defer s.subs.Done()
+
+	var (
+		ctx     = stream.Context()
+		headers chan *types.Header
+		logs    chan types.Log
+		txs     chan common.Hash
+		sub     *rpc.ClientSubscription
+		err     error
+	)
+	switch req.Type {
+	case SubscriptionType_NEW_HEADS:
+		headers = make(chan *types.Header, subscriptionBufferSize)
+		sub, err = s.client.KlaySubscribe(ctx, headers, "newHeads")
+	case SubscriptionType_LOGS:
+		criteria, cerr := toFilterCriteria(req.Filter)
+		if cerr != nil {
+			return cerr
+		}
+		logs = make(chan types.Log, subscriptionBufferSize)
+		sub, err = s.client.KlaySubscribe(ctx, logs, "logs", criteria)
+	case SubscriptionType_PENDING_TRANSACTIONS:
+		txs = make(chan common.Hash, subscriptionBufferSize)
+		sub, err = s.client.KlaySubscribe(ctx, txs, "newPendingTransactions")
+	default:
+		return status.Errorf(codes.InvalidArgument, "unknown subscription type %v", req.Type)
+	}
+	if err != nil {
+		return toStatusError(err)
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		var event *Event
+		select {
+		case header := <-headers:
+			event = &Event{Event: &Event_Header{Header: toHeader(header)}}
+		case l := <-logs:
+			event = &Event{Event: &Event_Log{Log: toLog(&l)}}
+		case hash := <-txs:
+			event = &Event{Event: &Event_PendingTransaction{PendingTransaction: hash.Bytes()}}
+		case err := <-sub.Err():
+			return toStatusError(err)
+		case <-ctx.Done():
+			return nil
+		}
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+	}
+}
+
+// Status returns the number of the pending and the queued transactions in the transaction pool.
+func (s *apiServer) Status(ctx context.Context, _ *empty.Empty) (*TxPoolStatus, error) {
+	var result map[string]hexutil.Uint
+	if err := s.call(ctx, "txpool", &result, "status"); err != nil {
+		return nil, err
+	}
+	return &TxPoolStatus{Pending: uint64(result["pending"]), Queued: uint64(result["queued"])}, nil
+}
+
+// GetBlockRlp returns the RLP encoding of the given block.
+func (s *apiServer) GetBlockRlp(ctx context.Context, req *BlockRequest) (*wrappers.BytesValue, error) {
+	var encoded string
+	if err := s.call(ctx, "debug", &encoded, "getBlockRlp", blockArg(req.Block)); err != nil {
+		return nil, err
+	}
+	data, err := hex.DecodeString(encoded)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &wrappers.BytesValue{Value: data}, nil
+}
+
+// Verbosity sets the log verbosity ceiling.
+func (s *apiServer) Verbosity(ctx context.Context, req *VerbosityRequest) (*empty.Empty, error) {
+	if err := s.call(ctx, "debug", nil, "verbosity", req.Level); err != nil {
+		return nil, err
+	}
+	return &empty.Empty{}, nil
+}
@@ -0,0 +1,234 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var testHeaders = func() []*types.Header {
+	headers := make([]*types.Header, 3)
+	for i := range headers {
+		headers[i] = &types.Header{
+			Number:     big.NewInt(int64(i)),
+			BlockScore: big.NewInt(1),
+			Time:       big.NewInt(int64(1000 + i)),
+			GasUsed:    uint64(21000 * i),
+			Extra:      []byte{},
+			Governance: []byte{},
+		}
+		if i > 0 {
+			headers[i].ParentHash = headers[i-1].Hash()
+		}
+	}
+	return headers
+}()
+
+type KlayTestAPI struct{}
+
+func (api *KlayTestAPI) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(len(testHeaders) - 1)
+}
+
+func (api *KlayTestAPI) GetHeaderByNumber(number rpc.BlockNumber) *types.Header {
+	if number == rpc.LatestBlockNumber {
+		return testHeaders[len(testHeaders)-1]
+	}
+	if number < 0 || int(number) >= len(testHeaders) {
+		return nil
+	}
+	return testHeaders[number]
+}
+
+func (api *KlayTestAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	for _, header := range testHeaders {
+		notifier.Notify(sub.ID, header)
+	}
+	return sub, nil
+}
+
+type TxPoolTestAPI struct{}
+
+func (api *TxPoolTestAPI) Status() map[string]hexutil.Uint {
+	return map[string]hexutil.Uint{"pending": 2, "queued": 1}
+}
+
+type DebugTestAPI struct {
+	level int32
+}
+
+func (api *DebugTestAPI) Verbosity(level int) {
+	atomic.StoreInt32(&api.level, int32(level))
+}
+
+// startTestListener starts a gRPC server serving the test APIs of the given modules, and returns
+// a connection to it.
+func startTestListener(t *testing.T, modules []string, debugAPI *DebugTestAPI) (*grpc.ClientConn, func()) {
+	apis := []rpc.API{
+		{Namespace: "klay", Version: "1.0", Service: &KlayTestAPI{}, Public: true},
+		{Namespace: "txpool", Version: "1.0", Service: &TxPoolTestAPI{}, Public: true},
+		{Namespace: "debug", Version: "1.0", Service: debugAPI},
+	}
+	handler, err := NewRPCServer(apis, modules)
+	require.NoError(t, err)
+
+	// Pick a free port for the listener
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	l.Close()
+
+	listener := &Listener{Addr: addr}
+	listener.SetRPCServer(handler)
+	go listener.Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, addr, grpc.WithInsecure(), grpc.WithBlock())
+	require.NoError(t, err)
+	return conn, func() {
+		conn.Close()
+		listener.Stop()
+	}
+}
+
+func TestAPIServer_Call(t *testing.T) {
+	conn, stop := startTestListener(t, nil, &DebugTestAPI{})
+	defer stop()
+	klay := NewKlayClient(conn)
+	ctx := context.Background()
+
+	number, err := klay.BlockNumber(ctx, &empty.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(len(testHeaders)-1), number.Value)
+
+	header, err := klay.GetHeaderByNumber(ctx, &BlockRequest{Block: "0x1"})
+	require.NoError(t, err)
+	assert.Equal(t, testHeaders[1].Hash().Bytes(), header.Hash)
+	assert.Equal(t, testHeaders[0].Hash().Bytes(), header.ParentHash)
+	assert.Equal(t, uint64(1), header.Number)
+	assert.Equal(t, uint64(1001), header.Timestamp)
+
+	// The latest block is given if no block is specified
+	header, err = klay.GetHeaderByNumber(ctx, &BlockRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, testHeaders[len(testHeaders)-1].Hash().Bytes(), header.Hash)
+
+	_, err = klay.GetHeaderByNumber(ctx, &BlockRequest{Block: "0x10"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = klay.GetBalance(ctx, &AccountRequest{Address: []byte{1}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	txpool, err := NewTxPoolClient(conn).Status(ctx, &empty.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), txpool.Pending)
+	assert.Equal(t, uint64(1), txpool.Queued)
+}
+
+func TestAPIServer_Subscribe(t *testing.T) {
+	conn, stop := startTestListener(t, nil, &DebugTestAPI{})
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := NewKlayClient(conn).Subscribe(ctx, &SubscribeRequest{Type: SubscriptionType_NEW_HEADS})
+	require.NoError(t, err)
+
+	for _, expected := range testHeaders {
+		event, err := stream.Recv()
+		require.NoError(t, err)
+		header := event.GetHeader()
+		require.NotNil(t, header)
+		assert.Equal(t, expected.Hash().Bytes(), header.Hash)
+		assert.Equal(t, expected.Number.Uint64(), header.Number)
+	}
+
+	// The subscriptions not provided by the APIs fail
+	stream, err = NewKlayClient(conn).Subscribe(ctx, &SubscribeRequest{Type: SubscriptionType_PENDING_TRANSACTIONS})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Error(t, err)
+}
+
+func TestAPIServer_Modules(t *testing.T) {
+	testcases := []struct {
+		modules   []string
+		klay      codes.Code
+		txpool    codes.Code
+		verbosity codes.Code
+	}{
+		// Only the public APIs are exposed by default
+		{nil, codes.OK, codes.OK, codes.PermissionDenied},
+		{[]string{"klay"}, codes.OK, codes.PermissionDenied, codes.PermissionDenied},
+		{[]string{"txpool", "debug"}, codes.PermissionDenied, codes.OK, codes.OK},
+	}
+	for _, tc := range testcases {
+		debugAPI := &DebugTestAPI{}
+		conn, stop := startTestListener(t, tc.modules, debugAPI)
+		ctx := context.Background()
+
+		_, err := NewKlayClient(conn).BlockNumber(ctx, &empty.Empty{})
+		assert.Equal(t, tc.klay, status.Code(err), tc.modules)
+
+		stream, err := NewKlayClient(conn).Subscribe(ctx, &SubscribeRequest{Type: SubscriptionType_NEW_HEADS})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, tc.klay, status.Code(err), tc.modules)
+
+		_, err = NewTxPoolClient(conn).Status(ctx, &empty.Empty{})
+		assert.Equal(t, tc.txpool, status.Code(err), tc.modules)
+
+		_, err = NewDebugClient(conn).Verbosity(ctx, &VerbosityRequest{Level: 4})
+		assert.Equal(t, tc.verbosity, status.Code(err), tc.modules)
+		if tc.verbosity == codes.OK {
+			assert.Equal(t, int32(4), atomic.LoadInt32(&debugAPI.level))
+		}
+
+		// The APIs not exposed are not served by the generic service either
+		request, err := (&gKlaytnClient{}).makeRPCRequest("txpool", "txpool_status", nil)
+		require.NoError(t, err)
+		response, err := NewKlaytnNodeClient(conn).Call(ctx, request)
+		require.NoError(t, err)
+		var out jsonSuccessResponse
+		require.NoError(t, json.Unmarshal(response.Payload, &out))
+		assert.Equal(t, tc.txpool == codes.OK, out.Result != nil, tc.modules)
+
+		stop()
+	}
+}
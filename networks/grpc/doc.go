@@ -27,5 +27,8 @@ Each file provides the following features
  - gServer.go : gRPC server implementation.
  - klaytn.proto : Define a interface and messages to use in gRPC server and clients.
  - klaytn.pb.go : the generated Go file from klaytn.proto by protoc-gen-go.
+ - api_server.go : the typed services calling the APIs of the RPC server.
+ - api.proto : Define the typed services and messages of the klay, txpool and debug APIs.
+ - api.pb.go : the generated Go file from api.proto by protoc-gen-go.
 */
 package grpc
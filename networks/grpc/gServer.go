@@ -41,6 +41,7 @@ type Listener struct {
 	Addr       string
 	handler    *rpc.Server
 	grpcServer *grpc.Server
+	apiServer  *apiServer
 }
 
 // grpcReadWriteNopCloser wraps an io.Reader and io.Writer with a NOP Close method.
@@ -260,6 +261,15 @@ func (gs *Listener) Start() {
 
 	RegisterKlaytnNodeServer(gs.grpcServer, &klaytnServer{handler: gs.handler})
 
+	// Register the typed services calling the same APIs as the generic ones.
+	if gs.apiServer, err = newAPIServer(gs.handler); err != nil {
+		logger.Error("failed to start the typed services", "err", err)
+	} else {
+		RegisterKlayServer(gs.grpcServer, gs.apiServer)
+		RegisterTxPoolServer(gs.grpcServer, gs.apiServer)
+		RegisterDebugServer(gs.grpcServer, gs.apiServer)
+	}
+
 	// Register reflection service on gRPC server.
 	reflection.Register(gs.grpcServer)
 	if err := gs.grpcServer.Serve(lis); err != nil {
@@ -272,4 +282,7 @@ func (gs *Listener) Stop() {
 	if gs.grpcServer != nil {
 		gs.grpcServer.Stop()
 	}
+	if gs.apiServer != nil {
+		gs.apiServer.close()
+	}
 }
@@ -268,6 +268,21 @@ func (s *dialstate) removeStatic(n *discover.Node) {
 
 }
 
+// replaceStatic replaces the static node of the old identity with the new identity at the
+// same endpoint. It returns false if the old identity is not a static node.
+func (s *dialstate) replaceStatic(oldID, newID discover.NodeID) bool {
+	t, ok := s.static[oldID]
+	if !ok {
+		return false
+	}
+	delete(s.static, oldID)
+	s.hist.remove(oldID)
+
+	dest := discover.NewNode(newID, t.dest.IP, t.dest.UDP, t.dest.TCP, t.dest.TCPs, t.dest.NType)
+	s.static[newID] = &dialTask{flags: t.flags, dest: dest, dialType: t.dialType}
+	return true
+}
+
 func (s *dialstate) newTasks(nRunning int, peers map[discover.NodeID]*Peer, now time.Time) []task {
 	if s.start.IsZero() {
 		s.start = now
@@ -0,0 +1,197 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"time"
+
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/networks/p2p/discover"
+)
+
+// DefaultIdentityGracePeriod is the default period in which the inbound connections to the
+// old identity are still accepted after the node key is rotated.
+const DefaultIdentityGracePeriod = time.Hour
+
+var errInvalidIdentityRotation = errors.New("invalid identity rotation")
+
+// identityRotation is announced to the peers when the node key is rotated. It is signed by
+// the new key, while the old identity is authenticated by the session it is sent through.
+type identityRotation struct {
+	NewID     discover.NodeID
+	Signature []byte
+}
+
+// retiredKey is an old node key accepted for the inbound connections until it expires.
+type retiredKey struct {
+	key   *ecdsa.PrivateKey
+	until time.Time
+}
+
+// peerRotation is an identity rotation announced by a connected peer.
+type peerRotation struct {
+	oldID, newID discover.NodeID
+}
+
+func identityRotationHash(oldID, newID discover.NodeID) []byte {
+	return crypto.Keccak256([]byte("klaytn identity rotation"), oldID[:], newID[:])
+}
+
+func newIdentityRotation(oldID discover.NodeID, newKey *ecdsa.PrivateKey) (*identityRotation, error) {
+	newID := discover.PubkeyID(&newKey.PublicKey)
+	sig, err := crypto.Sign(identityRotationHash(oldID, newID), newKey)
+	if err != nil {
+		return nil, err
+	}
+	return &identityRotation{NewID: newID, Signature: sig}, nil
+}
+
+// verify checks that the announcement is signed by the new key for the old identity.
+func (r *identityRotation) verify(oldID discover.NodeID) error {
+	pub, err := crypto.SigToPub(identityRotationHash(oldID, r.NewID), r.Signature)
+	if err != nil {
+		return err
+	}
+	if discover.PubkeyID(pub) != r.NewID || r.NewID == oldID {
+		return errInvalidIdentityRotation
+	}
+	return nil
+}
+
+// handshakeKeys returns the current node key and the retired keys which are not expired.
+func (srv *BaseServer) handshakeKeys() (*ecdsa.PrivateKey, []*ecdsa.PrivateKey) {
+	srv.identityMu.Lock()
+	defer srv.identityMu.Unlock()
+
+	var (
+		now     = time.Now()
+		retired []*ecdsa.PrivateKey
+		alive   = srv.retiredKeys[:0]
+	)
+	for _, k := range srv.retiredKeys {
+		if now.Before(k.until) {
+			retired = append(retired, k.key)
+			alive = append(alive, k)
+		}
+	}
+	srv.retiredKeys = alive
+	return srv.PrivateKey, retired
+}
+
+// protoHandshakeFor returns the protocol handshake of the identity of the given key, which may
+// be a retired one if the remote peer dialed the old identity.
+func (srv *BaseServer) protoHandshakeFor(key *ecdsa.PrivateKey) *protoHandshake {
+	srv.identityMu.RLock()
+	defer srv.identityMu.RUnlock()
+
+	id := discover.PubkeyID(&key.PublicKey)
+	if srv.ourHandshake.ID == id {
+		return srv.ourHandshake
+	}
+	hs := *srv.ourHandshake
+	hs.ID = id
+	return &hs
+}
+
+// nodeID returns the identity of the current node key.
+func (srv *BaseServer) nodeID() discover.NodeID {
+	srv.identityMu.RLock()
+	defer srv.identityMu.RUnlock()
+	return discover.PubkeyID(&srv.PrivateKey.PublicKey)
+}
+
+// RotateKey replaces the node key with the given one. The connected peers keep their sessions
+// and are informed of the new identity, so the peers having this node as a static or trusted
+// node can replace the old identity. The inbound connections to the old identity are accepted
+// for the grace period, and the discovery keeps advertising the old identity until restart.
+func (srv *BaseServer) RotateKey(key *ecdsa.PrivateKey, grace time.Duration) error {
+	srv.lock.Lock()
+	running := srv.running
+	srv.lock.Unlock()
+	if !running {
+		return errServerStopped
+	}
+
+	srv.identityMu.Lock()
+	oldKey := srv.PrivateKey
+	oldID := discover.PubkeyID(&oldKey.PublicKey)
+	announce, err := newIdentityRotation(oldID, key)
+	if err != nil {
+		srv.identityMu.Unlock()
+		return err
+	}
+	if announce.NewID == oldID {
+		srv.identityMu.Unlock()
+		return errors.New("the new node key is the same as the current one")
+	}
+	srv.PrivateKey = key
+	srv.retiredKeys = append(srv.retiredKeys, retiredKey{key: oldKey, until: time.Now().Add(grace)})
+	hs := *srv.ourHandshake
+	hs.ID = announce.NewID
+	srv.ourHandshake = &hs
+	srv.identityMu.Unlock()
+
+	srv.logger.Info("Rotated the node key", "old", oldID, "new", announce.NewID, "grace", grace)
+	for _, p := range srv.Peers() {
+		go func(p *Peer) {
+			if err := SendItems(p.rws[ConnDefault], identityMsg, announce.NewID, announce.Signature); err != nil {
+				p.logger.Debug("Failed to announce the new identity", "err", err)
+			}
+		}(p)
+	}
+	return nil
+}
+
+// announceRotation passes the identity rotation announced by a peer to the run loop.
+func (srv *BaseServer) announceRotation(oldID, newID discover.NodeID) {
+	select {
+	case srv.rotatepeer <- peerRotation{oldID, newID}:
+	case <-srv.quit:
+	}
+}
+
+// identityRotations keeps the new identities announced by the connected peers. The static and
+// trusted nodes are replaced by the new identities when the sessions with the old ones end,
+// so the sessions are not disturbed and the peers are redialed with the new identities.
+type identityRotations map[discover.NodeID]discover.NodeID
+
+// add records the announced identity, which is trusted from now on if the old one is trusted.
+func (rs identityRotations) add(r peerRotation, trusted map[discover.NodeID]bool) {
+	rs[r.oldID] = r.newID
+	if trusted[r.oldID] {
+		trusted[r.newID] = true
+	}
+}
+
+// apply replaces the old identity of the disconnected peer in the static and trusted nodes.
+func (rs identityRotations) apply(id discover.NodeID, dialstate dialer, trusted map[discover.NodeID]bool) {
+	newID, ok := rs[id]
+	if !ok {
+		return
+	}
+	delete(rs, id)
+
+	wasTrusted := trusted[id]
+	delete(trusted, id)
+	wasStatic := dialstate.replaceStatic(id, newID)
+	if wasTrusted || wasStatic {
+		logger.Warn("Replaced the identity of a peer which rotated its node key, update the node lists to keep it",
+			"old", id, "new", newID, "static", wasStatic, "trusted", wasTrusted)
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/networks/p2p/discover"
+)
+
+func TestIdentityRotationVerify(t *testing.T) {
+	oldKey, newKey := newkey(), newkey()
+	oldID := discover.PubkeyID(&oldKey.PublicKey)
+
+	announce, err := newIdentityRotation(oldID, newKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if announce.NewID != discover.PubkeyID(&newKey.PublicKey) {
+		t.Fatalf("new identity mismatch: got %v", announce.NewID)
+	}
+	if err := announce.verify(oldID); err != nil {
+		t.Fatalf("valid announcement rejected: %v", err)
+	}
+	// The announcement cannot be replayed by another peer
+	if err := announce.verify(randomID()); err == nil {
+		t.Fatal("announcement of another identity accepted")
+	}
+	// The announcement must be signed by the new key
+	forged, _ := newIdentityRotation(oldID, newkey())
+	forged.NewID = announce.NewID
+	if err := forged.verify(oldID); err == nil {
+		t.Fatal("announcement signed by another key accepted")
+	}
+}
+
+// TestEncHandshakeRetiredKey tests if the inbound connections to the old identity are accepted
+// with the retired key.
+func TestEncHandshakeRetiredKey(t *testing.T) {
+	var (
+		dialKey        = newkey()
+		oldKey, newKey = newkey(), newkey()
+		fd0, fd1       = net.Pipe()
+		c0, c1         = newRLPX(fd0).(*rlpx), newRLPX(fd1).(*rlpx)
+		dialErr        = make(chan error, 1)
+	)
+	go func() {
+		defer fd0.Close()
+		_, _, err := c0.doEncHandshake(dialKey, &discover.Node{ID: discover.PubkeyID(&oldKey.PublicKey)})
+		dialErr <- err
+	}()
+	id, key, err := c1.doEncHandshake(newKey, nil, oldKey)
+	fd1.Close()
+	if err != nil {
+		t.Fatalf("listen side enc handshake failed: %v", err)
+	}
+	if err := <-dialErr; err != nil {
+		t.Fatalf("dial side enc handshake failed: %v", err)
+	}
+	if id != discover.PubkeyID(&dialKey.PublicKey) {
+		t.Errorf("remote id mismatch: got %v", id)
+	}
+	if key != oldKey {
+		t.Errorf("the retired key is not used")
+	}
+}
+
+// TestPeerIdentityRotation tests if the verified identity rotations are passed to the hook.
+func TestPeerIdentityRotation(t *testing.T) {
+	fd1, fd2 := net.Pipe()
+	oldKey, newKey := newkey(), newkey()
+	oldID := discover.PubkeyID(&oldKey.PublicKey)
+	c1 := &conn{fd: fd1, transport: newTestTransport(oldID, fd1, false), id: oldID}
+	c2 := &conn{fd: fd2, transport: newTestTransport(randomID(), fd2, false)}
+	defer c2.close(errors.New("test done"))
+
+	rotated := make(chan peerRotation, 1)
+	peer, _ := newPeer([]*conn{c1}, nil, defaultRWTimerConfig)
+	peer.rotated = func(oldID, newID discover.NodeID) { rotated <- peerRotation{oldID, newID} }
+	go peer.run()
+
+	// An announcement signed by another key is ignored
+	forged, _ := newIdentityRotation(oldID, newkey())
+	if err := SendItems(c2, identityMsg, randomID(), forged.Signature); err != nil {
+		t.Fatal(err)
+	}
+	announce, _ := newIdentityRotation(oldID, newKey)
+	if err := SendItems(c2, identityMsg, announce.NewID, announce.Signature); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case r := <-rotated:
+		if r.oldID != oldID || r.newID != announce.NewID {
+			t.Errorf("rotation mismatch: got %v -> %v", r.oldID, r.newID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("identity rotation is not passed to the hook")
+	}
+}
+
+func TestIdentityRotationsApply(t *testing.T) {
+	var (
+		staticID  = randomID()
+		trustedID = randomID()
+		newIDs    = []discover.NodeID{randomID(), randomID()}
+		static    = &discover.Node{ID: staticID, IP: net.IP{127, 0, 0, 1}, TCP: 32323}
+		dialstate = newDialState([]*discover.Node{static}, nil, nil, 0, nil, nil, nil)
+		trusted   = map[discover.NodeID]bool{trustedID: true}
+		rotations = make(identityRotations)
+	)
+	rotations.add(peerRotation{staticID, newIDs[0]}, trusted)
+	rotations.add(peerRotation{trustedID, newIDs[1]}, trusted)
+	if !trusted[trustedID] || !trusted[newIDs[1]] || trusted[newIDs[0]] {
+		t.Fatalf("trusted nodes mismatch before the sessions end: %v", trusted)
+	}
+
+	// The identities are replaced when the sessions with the old ones end
+	rotations.apply(staticID, dialstate, trusted)
+	if _, ok := dialstate.static[staticID]; ok {
+		t.Error("old identity is still a static node")
+	}
+	if task, ok := dialstate.static[newIDs[0]]; !ok || task.dest.TCP != static.TCP || !task.dest.IP.Equal(static.IP) {
+		t.Errorf("new identity is not a static node at the same endpoint: %v", task)
+	}
+	rotations.apply(trustedID, dialstate, trusted)
+	if trusted[trustedID] || !trusted[newIDs[1]] {
+		t.Errorf("trusted nodes mismatch after the sessions end: %v", trusted)
+	}
+	if len(rotations) != 0 {
+		t.Errorf("rotations are not cleared: %v", rotations)
+	}
+}
+
+func TestServerRotateKey(t *testing.T) {
+	connected := make(chan *Peer, 1)
+	srv := startTestServer(t, randomID(), func(p *Peer) { connected <- p }, &Config{})
+	defer srv.Stop()
+	base := srv.(*SingleChannelServer).BaseServer
+	oldKey := base.PrivateKey
+	oldID := base.Self().ID
+
+	fd, err := net.DialTimeout("tcp", srv.GetListenAddress()[ConnDefault], 5*time.Second)
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+	defer fd.Close()
+	c := makeconn(fd, randomID())
+	c.doConnTypeHandshake(c.conntype)
+	select {
+	case <-connected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not accept within five seconds")
+	}
+
+	if err := srv.RotateKey(oldKey, time.Hour); err == nil {
+		t.Error("rotation to the current key is accepted")
+	}
+	newKey := newkey()
+	if err := srv.RotateKey(newKey, time.Hour); err != nil {
+		t.Fatalf("could not rotate the key: %v", err)
+	}
+	if id := base.Self().ID; id != discover.PubkeyID(&newKey.PublicKey) {
+		t.Errorf("self id mismatch: got %v", id)
+	}
+	key, retired := base.handshakeKeys()
+	if key != newKey || len(retired) != 1 || retired[0] != oldKey {
+		t.Errorf("handshake keys mismatch: got %v, retired %v", key, retired)
+	}
+	if hs := base.protoHandshakeFor(oldKey); hs.ID != oldID {
+		t.Errorf("protocol handshake id of the retired key mismatch: got %v", hs.ID)
+	}
+
+	// The connected peer is informed of the new identity
+	fd.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		msg, err := c.ReadMsg()
+		if err != nil {
+			t.Fatalf("could not read the announcement: %v", err)
+		}
+		if msg.Code != identityMsg {
+			msg.Discard()
+			continue
+		}
+		var announce identityRotation
+		if err := msg.Decode(&announce); err != nil {
+			t.Fatal(err)
+		}
+		if err := announce.verify(oldID); err != nil || announce.NewID != discover.PubkeyID(&newKey.PublicKey) {
+			t.Errorf("invalid announcement: %v, %v", announce.NewID, err)
+		}
+		break
+	}
+
+	// The retired key expires after the grace period
+	base.identityMu.Lock()
+	base.retiredKeys[0].until = time.Now()
+	base.identityMu.Unlock()
+	if _, retired := base.handshakeKeys(); len(retired) != 0 {
+		t.Errorf("expired key is still accepted: %v", retired)
+	}
+}
@@ -56,6 +56,7 @@ const (
 	discMsg      = 0x01
 	pingMsg      = 0x02
 	pongMsg      = 0x03
+	identityMsg  = 0x04 // announces the new identity of a rotated node key
 )
 
 const (
@@ -73,9 +74,53 @@ type protoHandshake struct {
 	Multichannel bool
 
 	// Ignore additional fields (for forward compatibility).
+	// The first additional field carries the optional features of the protocols.
 	Rest []rlp.RawValue `rlp:"tail"`
 }
 
+// setFeatures sets the optional features of the protocols to the first additional field,
+// which is ignored by the peers not supporting feature negotiation.
+func (hs *protoHandshake) setFeatures(features []string) error {
+	if len(features) == 0 {
+		hs.Rest = nil
+		return nil
+	}
+	enc, err := rlp.EncodeToBytes(features)
+	if err != nil {
+		return err
+	}
+	hs.Rest = []rlp.RawValue{enc}
+	return nil
+}
+
+// features returns the optional features advertised in the handshake.
+func (hs *protoHandshake) features() []string {
+	if len(hs.Rest) == 0 {
+		return nil
+	}
+	var features []string
+	if err := rlp.DecodeBytes(hs.Rest[0], &features); err != nil {
+		return nil
+	}
+	return features
+}
+
+// protocolFeatures returns the sorted optional features of the protocols without duplicates.
+func protocolFeatures(protocols []Protocol) []string {
+	var features []string
+	seen := make(map[string]bool)
+	for _, proto := range protocols {
+		for _, feature := range proto.Features {
+			if !seen[feature] {
+				seen[feature] = true
+				features = append(features, feature)
+			}
+		}
+	}
+	sort.Strings(features)
+	return features
+}
+
 // PeerEventType is the type of peer events emitted by a p2p.Server
 type PeerEventType string
 
@@ -120,8 +165,14 @@ type Peer struct {
 	closed   chan struct{}
 	disc     chan DiscReason
 
+	// features are the optional features enabled for the peer
+	features map[string]bool
+
 	// events receives message send / receive events if set
 	events *event.Feed
+
+	// rotated receives the new identity announced by the peer if set
+	rotated func(oldID, newID discover.NodeID)
 }
 
 // NewPeer returns a peer for testing purposes.
@@ -149,6 +200,22 @@ func (p *Peer) Caps() []Cap {
 	return p.rws[ConnDefault].caps
 }
 
+// Features returns the sorted optional features enabled for the peer, which are supported
+// by both of the host node and the remote peer.
+func (p *Peer) Features() []string {
+	features := make([]string, 0, len(p.features))
+	for feature := range p.features {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+	return features
+}
+
+// HasFeature returns true if the optional feature is enabled for the peer.
+func (p *Peer) HasFeature(feature string) bool {
+	return p.features[feature]
+}
+
 // RemoteAddr returns the remote address of the network connection.
 func (p *Peer) RemoteAddr() net.Addr {
 	return p.rws[ConnDefault].fd.RemoteAddr()
@@ -205,6 +272,7 @@ func newPeer(conns []*conn, protocols []Protocol, tc RWTimerConfig) (*Peer, erro
 	p := &Peer{
 		rws:      conns,
 		running:  protomap,
+		features: matchFeatures(protocols, conns[ConnDefault].features),
 		created:  mclock.Now(),
 		disc:     make(chan DiscReason),
 		protoErr: make(chan error, len(protomap)+len(conns)), // protocols + pingLoop
@@ -412,6 +480,18 @@ func (p *Peer) handle(connectionOrder int, rw *conn, msg Msg) error {
 		// check errors because, the connection will be closed after it.
 		rlp.Decode(msg.Payload, &reason)
 		return reason[0]
+	case msg.Code == identityMsg:
+		var announce identityRotation
+		if err := msg.Decode(&announce); err != nil {
+			return err
+		}
+		if err := announce.verify(p.ID()); err != nil {
+			p.logger.Debug("Ignored an invalid identity rotation", "new", announce.NewID, "err", err)
+			return nil
+		}
+		if p.rotated != nil {
+			go p.rotated(p.ID(), announce.NewID)
+		}
 	case msg.Code < baseProtocolLength:
 		// ignore other base protocol messages
 		return msg.Discard()
@@ -444,6 +524,21 @@ func countMatchingProtocols(protocols []Protocol, caps []Cap) int {
 }
 
 // matchProtocols creates structures for matching named subprotocols.
+// matchFeatures returns the optional features of the protocols advertised by the remote peer.
+func matchFeatures(protocols []Protocol, remote []string) map[string]bool {
+	advertised := make(map[string]bool, len(remote))
+	for _, feature := range remote {
+		advertised[feature] = true
+	}
+	features := make(map[string]bool)
+	for _, feature := range protocolFeatures(protocols) {
+		if advertised[feature] {
+			features[feature] = true
+		}
+	}
+	return features
+}
+
 func matchProtocols(protocols []Protocol, caps []Cap, rws []MsgReadWriter, tc RWTimerConfig) map[string][]*protoRW {
 	sort.Sort(capsByNameAndVersion(caps))
 	offset := baseProtocolLength
@@ -648,6 +743,7 @@ type PeerInfo struct {
 	ID        string                 `json:"id"`        // Unique node identifier (also the encryption key)
 	Name      string                 `json:"name"`      // Name of the node, including client type, version, OS, custom data
 	Caps      []string               `json:"caps"`      // Sum-protocols advertised by this particular peer
+	Features  []string               `json:"features"`  // Optional features of the sub-protocols enabled for this peer
 	Networks  []NetworkInfo          `json:"networks"`  // Networks is all the NetworkInfo associated with the peer
 	Protocols map[string]interface{} `json:"protocols"` // Sub-protocol specific metadata fields
 }
@@ -664,6 +760,7 @@ func (p *Peer) Info() *PeerInfo {
 		ID:        p.ID().String(),
 		Name:      p.Name(),
 		Caps:      caps,
+		Features:  p.Features(),
 		Protocols: make(map[string]interface{}),
 	}
 
@@ -505,3 +505,49 @@ func TestMatchProtocols(t *testing.T) {
 		}
 	}
 }
+
+func TestMatchFeatures(t *testing.T) {
+	tests := []struct {
+		Remote []string
+		Local  []Protocol
+		Match  []string
+	}{
+		{
+			// No remote features
+			Local: []Protocol{{Name: "a", Features: []string{"a/x"}}},
+		},
+		{
+			// No local features
+			Remote: []string{"a/x"},
+			Local:  []Protocol{{Name: "a"}},
+		},
+		{
+			// Some matches, some differences
+			Remote: []string{"a/x", "a/y", "b/z", "unknown"},
+			Local:  []Protocol{{Name: "a", Features: []string{"a/y", "a/w", "a/x"}}, {Name: "b", Features: []string{"b/z"}}},
+			Match:  []string{"a/x", "a/y", "b/z"},
+		},
+		{
+			// Duplicated features
+			Remote: []string{"a/x", "a/x"},
+			Local:  []Protocol{{Name: "a", Version: 1, Features: []string{"a/x"}}, {Name: "a", Version: 2, Features: []string{"a/x"}}},
+			Match:  []string{"a/x"},
+		},
+	}
+
+	for i, tt := range tests {
+		pipe, _ := net.Pipe()
+		p, _ := newPeer([]*conn{{fd: pipe, features: tt.Remote}}, tt.Local, defaultRWTimerConfig)
+		if features := p.Features(); len(features) != len(tt.Match) || (len(features) > 0 && !reflect.DeepEqual(features, tt.Match)) {
+			t.Errorf("test %d: features mismatch: have %v, want %v", i, features, tt.Match)
+		}
+		for _, feature := range tt.Match {
+			if !p.HasFeature(feature) {
+				t.Errorf("test %d: feature %q not enabled", i, feature)
+			}
+		}
+		if p.HasFeature("unknown") {
+			t.Errorf("test %d: unknown feature enabled", i)
+		}
+	}
+}
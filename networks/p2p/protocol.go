@@ -65,6 +65,12 @@ type Protocol struct {
 	// about a certain peer in the network. If an info retrieval function is set,
 	// but returns nil, it is assumed that the protocol handshake is still running.
 	PeerInfo func(id discover.NodeID) interface{}
+
+	// Features are the optional features of the protocol supported by the host node.
+	// They are advertised in the protocol handshake, and a feature is enabled for a peer
+	// only if the peer advertises it as well. Unlike the version, adding a feature does
+	// not affect the peers not knowing it.
+	Features []string
 }
 
 func (p Protocol) cap() Cap {
@@ -221,23 +221,25 @@ func readProtocolHandshake(rw MsgReader, our *protoHandshake) (*protoHandshake,
 // messages. the protocol handshake is the first authenticated message
 // and also verifies whether the encryption handshake 'worked' and the
 // remote side actually provided the right public key.
-func (t *rlpx) doEncHandshake(prv *ecdsa.PrivateKey, dial *discover.Node) (discover.NodeID, error) {
+// The retired keys are tried in order if the remote side dialed an old identity of the
+// listening side, and the local key used for the handshake is returned.
+func (t *rlpx) doEncHandshake(prv *ecdsa.PrivateKey, dial *discover.Node, retired ...*ecdsa.PrivateKey) (discover.NodeID, *ecdsa.PrivateKey, error) {
 	var (
 		sec secrets
 		err error
 	)
 	if dial == nil {
-		sec, err = receiverEncHandshake(t.fd, prv, nil)
+		sec, prv, err = receiverEncHandshake(t.fd, append([]*ecdsa.PrivateKey{prv}, retired...), nil)
 	} else {
 		sec, err = initiatorEncHandshake(t.fd, prv, dial.ID)
 	}
 	if err != nil {
-		return discover.NodeID{}, err
+		return discover.NodeID{}, nil, err
 	}
 	t.wmu.Lock()
 	t.rw = newRLPXFrameRW(t.fd, sec)
 	t.wmu.Unlock()
-	return sec.RemoteID, nil
+	return sec.RemoteID, prv, nil
 }
 
 // encHandshake contains the state of the encryption handshake.
@@ -397,22 +399,22 @@ func (h *encHandshake) handleAuthResp(msg *authRespV4) (err error) {
 // receiverEncHandshake negotiates a session token on conn.
 // it should be called on the listening side of the connection.
 //
-// prv is the local client's private key.
+// keys are the local client's private keys, the first of which is the current one.
 // token is the token from a previous session with this node.
-func receiverEncHandshake(conn io.ReadWriter, prv *ecdsa.PrivateKey, token []byte) (s secrets, err error) {
+func receiverEncHandshake(conn io.ReadWriter, keys []*ecdsa.PrivateKey, token []byte) (s secrets, prv *ecdsa.PrivateKey, err error) {
 	authMsg := new(authMsgV4)
-	authPacket, err := readHandshakeMsg(authMsg, encAuthMsgLen, prv, conn)
+	authPacket, prv, err := readHandshakeMsgWithKeys(authMsg, encAuthMsgLen, keys, conn)
 	if err != nil {
-		return s, err
+		return s, nil, err
 	}
 	h := new(encHandshake)
 	if err := h.handleAuthMsg(authMsg, prv); err != nil {
-		return s, err
+		return s, nil, err
 	}
 
 	authRespMsg, err := h.makeAuthResp()
 	if err != nil {
-		return s, err
+		return s, nil, err
 	}
 	var authRespPacket []byte
 	if authMsg.gotPlain {
@@ -421,12 +423,13 @@ func receiverEncHandshake(conn io.ReadWriter, prv *ecdsa.PrivateKey, token []byt
 		authRespPacket, err = sealEIP8(authRespMsg, h)
 	}
 	if err != nil {
-		return s, err
+		return s, nil, err
 	}
 	if _, err = conn.Write(authRespPacket); err != nil {
-		return s, err
+		return s, nil, err
 	}
-	return h.secrets(authPacket, authRespPacket)
+	s, err = h.secrets(authPacket, authRespPacket)
+	return s, prv, err
 }
 
 func (h *encHandshake) handleAuthMsg(msg *authMsgV4, prv *ecdsa.PrivateKey) error {
@@ -531,34 +534,49 @@ type plainDecoder interface {
 }
 
 func readHandshakeMsg(msg plainDecoder, plainSize int, prv *ecdsa.PrivateKey, r io.Reader) ([]byte, error) {
+	buf, _, err := readHandshakeMsgWithKeys(msg, plainSize, []*ecdsa.PrivateKey{prv}, r)
+	return buf, err
+}
+
+// readHandshakeMsgWithKeys reads a handshake message encrypted with any of the keys,
+// and returns the key which decrypted the message.
+func readHandshakeMsgWithKeys(msg plainDecoder, plainSize int, prvs []*ecdsa.PrivateKey, r io.Reader) ([]byte, *ecdsa.PrivateKey, error) {
 	buf := make([]byte, plainSize)
 	if _, err := io.ReadFull(r, buf); err != nil {
-		return buf, err
+		return buf, nil, err
+	}
+	keys := make([]*ecies.PrivateKey, len(prvs))
+	for i, prv := range prvs {
+		keys[i] = ecies.ImportECDSA(prv)
 	}
 	// Attempt decoding pre-EIP-8 "plain" format.
-	key := ecies.ImportECDSA(prv)
-	if dec, err := key.Decrypt(buf, nil, nil); err == nil {
-		msg.decodePlain(dec)
-		return buf, nil
+	for i, key := range keys {
+		if dec, err := key.Decrypt(buf, nil, nil); err == nil {
+			msg.decodePlain(dec)
+			return buf, prvs[i], nil
+		}
 	}
 	// Could be EIP-8 format, try that.
 	prefix := buf[:2]
 	size := binary.BigEndian.Uint16(prefix)
 	if size < uint16(plainSize) {
-		return buf, fmt.Errorf("size underflow, need at least %d bytes", plainSize)
+		return buf, nil, fmt.Errorf("size underflow, need at least %d bytes", plainSize)
 	}
 	buf = append(buf, make([]byte, size-uint16(plainSize)+2)...)
 	if _, err := io.ReadFull(r, buf[plainSize:]); err != nil {
-		return buf, err
-	}
-	dec, err := key.Decrypt(buf[2:], nil, prefix)
-	if err != nil {
-		return buf, err
+		return buf, nil, err
+	}
+	var err error
+	for i, key := range keys {
+		var dec []byte
+		if dec, err = key.Decrypt(buf[2:], nil, prefix); err == nil {
+			// Can't use rlp.DecodeBytes here because it rejects
+			// trailing data (forward-compatibility).
+			s := rlp.NewStream(bytes.NewReader(dec), 0)
+			return buf, prvs[i], s.Decode(msg)
+		}
 	}
-	// Can't use rlp.DecodeBytes here because it rejects
-	// trailing data (forward-compatibility).
-	s := rlp.NewStream(bytes.NewReader(dec), 0)
-	return buf, s.Decode(msg)
+	return buf, nil, err
 }
 
 // importPublicKey unmarshals 512 bit public keys.
@@ -101,7 +101,7 @@ func testEncHandshake(token []byte) error {
 		defer fd0.Close()
 
 		dest := &discover.Node{ID: discover.PubkeyID(&prv1.PublicKey)}
-		r.id, r.err = c0.doEncHandshake(prv0, dest)
+		r.id, _, r.err = c0.doEncHandshake(prv0, dest)
 		if r.err != nil {
 			return
 		}
@@ -115,7 +115,7 @@ func testEncHandshake(token []byte) error {
 		defer func() { output <- r }()
 		defer fd1.Close()
 
-		r.id, r.err = c1.doEncHandshake(prv1, nil)
+		r.id, _, r.err = c1.doEncHandshake(prv1, nil)
 		if r.err != nil {
 			return
 		}
@@ -173,7 +173,7 @@ func TestProtocolHandshake(t *testing.T) {
 		defer wg.Done()
 		defer fd0.Close()
 		rlpx := newRLPX(fd0)
-		remid, err := rlpx.doEncHandshake(prv0, node1)
+		remid, _, err := rlpx.doEncHandshake(prv0, node1)
 		if err != nil {
 			t.Errorf("dial side enc handshake failed: %v", err)
 			return
@@ -199,7 +199,7 @@ func TestProtocolHandshake(t *testing.T) {
 		defer wg.Done()
 		defer fd1.Close()
 		rlpx := newRLPX(fd1)
-		remid, err := rlpx.doEncHandshake(prv1, nil)
+		remid, _, err := rlpx.doEncHandshake(prv1, nil)
 		if err != nil {
 			t.Errorf("listen side enc handshake failed: %v", err)
 			return
@@ -227,6 +227,45 @@ func TestProtocolHandshake(t *testing.T) {
 	wg.Wait()
 }
 
+// TestProtocolHandshakeFeatures tests if the optional features are carried in the additional fields
+// of the handshake, so the handshakes with and without the features are compatible.
+func TestProtocolHandshakeFeatures(t *testing.T) {
+	var (
+		features = []string{"a/x", "b/y"}
+		with     = &protoHandshake{Version: 3, Name: "new", Caps: []Cap{{"a", 1}}}
+		without  = &protoHandshake{Version: 3, Name: "old", Caps: []Cap{{"a", 1}}}
+	)
+	if err := with.setFeatures(features); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		hs   *protoHandshake
+		want []string
+	}{
+		{with, features},
+		{without, nil},
+	} {
+		enc, err := rlp.EncodeToBytes(test.hs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var hs protoHandshake
+		if err := rlp.DecodeBytes(enc, &hs); err != nil {
+			t.Fatalf("%s: failed to decode handshake: %v", test.hs.Name, err)
+		}
+		if got := hs.features(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: features mismatch: got %v, want %v", test.hs.Name, got, test.want)
+		}
+	}
+
+	// The unknown additional fields are not regarded as features
+	invalid := &protoHandshake{Version: 3, Rest: []rlp.RawValue{{0x06}}}
+	if got := invalid.features(); got != nil {
+		t.Errorf("invalid features decoded: %v", got)
+	}
+}
+
 func TestProtocolHandshakeErrors(t *testing.T) {
 	our := &protoHandshake{Version: 3, Caps: []Cap{{"foo", 2}, {"bar", 3}}, Name: "quux"}
 	tests := []struct {
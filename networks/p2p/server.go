@@ -272,6 +272,9 @@ type Server interface {
 	// Peers returns all connected peers.
 	Peers() []*Peer
 
+	// RotateKey replaces the node key, accepting the old identity for the grace period.
+	RotateKey(key *ecdsa.PrivateKey, grace time.Duration) error
+
 	// NodeDialer is used to connect to nodes in the network, typically by using
 	// an underlying net.Dialer but also using net.Pipe in tests.
 	NodeDialer
@@ -321,6 +324,7 @@ func (srv *MultiChannelServer) Start() (err error) {
 	srv.posthandshake = make(chan *conn)
 	srv.addstatic = make(chan *discover.Node)
 	srv.removestatic = make(chan *discover.Node)
+	srv.rotatepeer = make(chan peerRotation)
 	srv.peerOp = make(chan peerOpFunc)
 	srv.peerOpDone = make(chan struct{})
 	srv.discpeer = make(chan discover.NodeID)
@@ -382,6 +386,9 @@ func (srv *MultiChannelServer) Start() (err error) {
 	for _, p := range srv.Protocols {
 		srv.ourHandshake.Caps = append(srv.ourHandshake.Caps, p.cap())
 	}
+	if err := srv.ourHandshake.setFeatures(protocolFeatures(srv.Protocols)); err != nil {
+		return err
+	}
 	for _, l := range srv.ListenAddrs {
 		s := strings.Split(l, ":")
 		if len(s) == 2 {
@@ -543,7 +550,8 @@ func (srv *MultiChannelServer) setupConn(c *conn, flags connFlag, dialDest *disc
 	srv.logger.Trace("Connection Type Trace", "addr", c.fd.RemoteAddr(), "conn", c.flags, "ConnType", c.conntype.String())
 
 	// Run the encryption handshake.
-	if c.id, err = c.doEncHandshake(srv.PrivateKey, dialDest); err != nil {
+	key, retired := srv.handshakeKeys()
+	if c.id, key, err = c.doEncHandshake(key, dialDest, retired...); err != nil {
 		srv.logger.Trace("Failed RLPx handshake", "addr", c.fd.RemoteAddr(), "conn", c.flags, "err", err)
 		return err
 	}
@@ -560,7 +568,7 @@ func (srv *MultiChannelServer) setupConn(c *conn, flags connFlag, dialDest *disc
 		return err
 	}
 	// Run the protocol handshake
-	phs, err := c.doProtoHandshake(srv.ourHandshake)
+	phs, err := c.doProtoHandshake(srv.protoHandshakeFor(key))
 	if err != nil {
 		clog.Trace("Failed protobuf handshake", "err", err)
 		return err
@@ -569,7 +577,7 @@ func (srv *MultiChannelServer) setupConn(c *conn, flags connFlag, dialDest *disc
 		clog.Trace("Wrong devp2p handshake identity", "err", phs.ID)
 		return DiscUnexpectedIdentity
 	}
-	c.caps, c.name, c.multiChannel = phs.Caps, phs.Name, phs.Multichannel
+	c.caps, c.name, c.multiChannel, c.features = phs.Caps, phs.Name, phs.Multichannel, phs.features()
 
 	if c.multiChannel && dialDest != nil && (dialDest.TCPs == nil || len(dialDest.TCPs) < 2) && len(dialDest.TCPs) < len(phs.ListenPort) {
 		logger.Debug("[Dial] update and retry the dial candidate as a multichannel",
@@ -602,6 +610,7 @@ func (srv *MultiChannelServer) run(dialstate dialer) {
 		inboundCount  = 0
 		outboundCount = 0
 		trusted       = make(map[discover.NodeID]bool, len(srv.TrustedNodes))
+		rotations     = make(identityRotations)
 		taskdone      = make(chan task, maxActiveDialTasks)
 		runningTasks  []task
 		queuedTasks   []task // tasks that can't run yet
@@ -666,6 +675,10 @@ running:
 			if p, ok := peers[n.ID]; ok {
 				p.Disconnect(DiscRequested)
 			}
+		case r := <-srv.rotatepeer:
+			// A peer announced its new identity, which replaces the old one after the session ends.
+			srv.logger.Debug("Peer rotated its node key", "old", r.oldID, "new", r.newID)
+			rotations.add(r, trusted)
 		case op := <-srv.peerOp:
 			// This channel is used by Peers and PeerCount.
 			op(peers)
@@ -732,6 +745,7 @@ running:
 					if srv.EnableMsgEvents {
 						p.events = &srv.peerFeed
 					}
+					p.rotated = srv.announceRotation
 					name := truncateName(c.name)
 					srv.logger.Debug("Adding p2p peer", "name", name, "addr", c.fd.RemoteAddr(), "peers", len(peers)+1)
 					go srv.runPeer(p)
@@ -754,6 +768,7 @@ running:
 			d := common.PrettyDuration(mclock.Now() - pd.created)
 			pd.logger.Debug("Removing p2p peer", "duration", d, "peers", len(peers)-1, "req", pd.requested, "err", pd.err)
 			delete(peers, pd.ID())
+			rotations.apply(pd.ID(), dialstate, trusted)
 
 			peerCountGauge.Update(int64(len(peers)))
 			inboundCount, outboundCount = decreasesConnectionMetric(inboundCount, outboundCount, pd.Peer)
@@ -914,6 +929,8 @@ type BaseServer struct {
 	ntab         discover.Discovery
 	listener     net.Listener
 	ourHandshake *protoHandshake
+	identityMu   sync.RWMutex // protects PrivateKey, ourHandshake and retiredKeys after start
+	retiredKeys  []retiredKey
 	lastLookup   time.Time
 	lastLookupMu sync.Mutex
 	//DiscV5       *discv5.Network
@@ -925,6 +942,7 @@ type BaseServer struct {
 	quit          chan struct{}
 	addstatic     chan *discover.Node
 	removestatic  chan *discover.Node
+	rotatepeer    chan peerRotation
 	posthandshake chan *conn
 	addpeer       chan *conn
 	delpeer       chan peerDrop
@@ -967,6 +985,7 @@ type conn struct {
 	cont         chan error      // The run loop uses cont to signal errors to SetupConn.
 	id           discover.NodeID // valid after the encryption handshake
 	caps         []Cap           // valid after the protocol handshake
+	features     []string        // valid after the protocol handshake
 	name         string          // valid after the protocol handshake
 	portOrder    PortOrder       // portOrder is the order of the ports that should be connected in multi-channel.
 	multiChannel bool            // multiChannel is whether the peer is using multi-channel.
@@ -975,7 +994,7 @@ type conn struct {
 type transport interface {
 	doConnTypeHandshake(myConnType common.ConnType) (common.ConnType, error)
 	// The two handshakes.
-	doEncHandshake(prv *ecdsa.PrivateKey, dialDest *discover.Node) (discover.NodeID, error)
+	doEncHandshake(prv *ecdsa.PrivateKey, dialDest *discover.Node, retired ...*ecdsa.PrivateKey) (discover.NodeID, *ecdsa.PrivateKey, error)
 	doProtoHandshake(our *protoHandshake) (*protoHandshake, error)
 	// The MsgReadWriter can only be used after the encryption
 	// handshake has completed. The code uses conn.id to track this
@@ -1121,18 +1140,22 @@ func (srv *BaseServer) makeSelf(listener net.Listener, discovery discover.Discov
 	if discovery == nil {
 		// Inbound connections disabled, use zero address.
 		if listener == nil {
-			return &discover.Node{IP: net.ParseIP("0.0.0.0"), ID: discover.PubkeyID(&srv.PrivateKey.PublicKey)}
+			return &discover.Node{IP: net.ParseIP("0.0.0.0"), ID: srv.nodeID()}
 		}
 		// Otherwise inject the listener address too
 		addr := listener.Addr().(*net.TCPAddr)
 		return &discover.Node{
-			ID:  discover.PubkeyID(&srv.PrivateKey.PublicKey),
+			ID:  srv.nodeID(),
 			IP:  addr.IP,
 			TCP: uint16(addr.Port),
 		}
 	}
-	// Otherwise return the discovery node.
-	return discovery.Self()
+	// Otherwise return the discovery node, with the new identity if the node key is rotated.
+	self := discovery.Self()
+	if id := srv.nodeID(); self.ID != id {
+		return discover.NewNode(id, self.IP, self.UDP, self.TCP, self.TCPs, self.NType)
+	}
+	return self
 }
 
 // Stop terminates the server and all active peer connections.
@@ -1219,6 +1242,7 @@ func (srv *BaseServer) Start() (err error) {
 	srv.posthandshake = make(chan *conn)
 	srv.addstatic = make(chan *discover.Node)
 	srv.removestatic = make(chan *discover.Node)
+	srv.rotatepeer = make(chan peerRotation)
 	srv.peerOp = make(chan peerOpFunc)
 	srv.peerOpDone = make(chan struct{})
 	srv.discpeer = make(chan discover.NodeID)
@@ -1285,6 +1309,9 @@ func (srv *BaseServer) Start() (err error) {
 	for _, p := range srv.Protocols {
 		srv.ourHandshake.Caps = append(srv.ourHandshake.Caps, p.cap())
 	}
+	if err := srv.ourHandshake.setFeatures(protocolFeatures(srv.Protocols)); err != nil {
+		return err
+	}
 	// listen/dial
 	if srv.NoDial && srv.NoListen {
 		srv.logger.Error("P2P server will be useless, neither dialing nor listening")
@@ -1333,6 +1360,7 @@ type dialer interface {
 	taskDone(task, time.Time)
 	addStatic(*discover.Node)
 	removeStatic(*discover.Node)
+	replaceStatic(oldID, newID discover.NodeID) bool
 }
 
 func (srv *BaseServer) run(dialstate dialer) {
@@ -1341,6 +1369,7 @@ func (srv *BaseServer) run(dialstate dialer) {
 		peers        = make(map[discover.NodeID]*Peer)
 		inboundCount = 0
 		trusted      = make(map[discover.NodeID]bool, len(srv.TrustedNodes))
+		rotations    = make(identityRotations)
 		taskdone     = make(chan task, maxActiveDialTasks)
 		runningTasks []task
 		queuedTasks  []task // tasks that can't run yet
@@ -1405,6 +1434,10 @@ running:
 			if p, ok := peers[n.ID]; ok {
 				p.Disconnect(DiscRequested)
 			}
+		case r := <-srv.rotatepeer:
+			// A peer announced its new identity, which replaces the old one after the session ends.
+			srv.logger.Debug("Peer rotated its node key", "old", r.oldID, "new", r.newID)
+			rotations.add(r, trusted)
 		case op := <-srv.peerOp:
 			// This channel is used by Peers and PeerCount.
 			op(peers)
@@ -1445,6 +1478,7 @@ running:
 					if srv.EnableMsgEvents {
 						p.events = &srv.peerFeed
 					}
+					p.rotated = srv.announceRotation
 					name := truncateName(c.name)
 					srv.logger.Debug("Adding p2p peer", "name", name, "addr", c.fd.RemoteAddr(), "peers", len(peers)+1)
 					go srv.runPeer(p)
@@ -1471,6 +1505,7 @@ running:
 			d := common.PrettyDuration(mclock.Now() - pd.created)
 			pd.logger.Debug("Removing p2p peer", "duration", d, "peers", len(peers)-1, "req", pd.requested, "err", pd.err)
 			delete(peers, pd.ID())
+			rotations.apply(pd.ID(), dialstate, trusted)
 
 			if pd.Inbound() {
 				inboundCount--
@@ -1679,7 +1714,8 @@ func (srv *BaseServer) setupConn(c *conn, flags connFlag, dialDest *discover.Nod
 	srv.logger.Trace("Connection Type Trace", "addr", c.fd.RemoteAddr(), "conn", c.flags, "ConnType", c.conntype.String())
 
 	// Run the encryption handshake.
-	if c.id, err = c.doEncHandshake(srv.PrivateKey, dialDest); err != nil {
+	key, retired := srv.handshakeKeys()
+	if c.id, key, err = c.doEncHandshake(key, dialDest, retired...); err != nil {
 		srv.logger.Trace("Failed RLPx handshake", "addr", c.fd.RemoteAddr(), "conn", c.flags, "err", err)
 		return err
 	}
@@ -1696,7 +1732,7 @@ func (srv *BaseServer) setupConn(c *conn, flags connFlag, dialDest *discover.Nod
 		return err
 	}
 	// Run the protocol handshake
-	phs, err := c.doProtoHandshake(srv.ourHandshake)
+	phs, err := c.doProtoHandshake(srv.protoHandshakeFor(key))
 	if err != nil {
 		clog.Trace("Failed protobuf handshake", "err", err)
 		return err
@@ -1705,7 +1741,7 @@ func (srv *BaseServer) setupConn(c *conn, flags connFlag, dialDest *discover.Nod
 		clog.Trace("Wrong devp2p handshake identity", "err", phs.ID)
 		return DiscUnexpectedIdentity
 	}
-	c.caps, c.name, c.multiChannel = phs.Caps, phs.Name, phs.Multichannel
+	c.caps, c.name, c.multiChannel, c.features = phs.Caps, phs.Name, phs.Multichannel, phs.features()
 
 	err = srv.checkpoint(c, srv.addpeer)
 	if err != nil {
@@ -58,8 +58,8 @@ func newTestTransport(id discover.NodeID, fd net.Conn, mutichannel bool) transpo
 	return &testTransport{id: id, rlpx: wrapped, mutichannel: mutichannel}
 }
 
-func (c *testTransport) doEncHandshake(prv *ecdsa.PrivateKey, dialDest *discover.Node) (discover.NodeID, error) {
-	return c.id, nil
+func (c *testTransport) doEncHandshake(prv *ecdsa.PrivateKey, dialDest *discover.Node, retired ...*ecdsa.PrivateKey) (discover.NodeID, *ecdsa.PrivateKey, error) {
+	return c.id, prv, nil
 }
 
 func (c *testTransport) doProtoHandshake(our *protoHandshake) (*protoHandshake, error) {
@@ -444,6 +444,9 @@ func (tg taskgen) addStatic(*discover.Node) {
 }
 func (tg taskgen) removeStatic(*discover.Node) {
 }
+func (tg taskgen) replaceStatic(oldID, newID discover.NodeID) bool {
+	return false
+}
 
 type testTask struct {
 	index  int
@@ -608,9 +611,9 @@ func (c *setupTransport) doConnTypeHandshake(myConnType common.ConnType) (common
 	return 1, nil
 }
 
-func (c *setupTransport) doEncHandshake(prv *ecdsa.PrivateKey, dialDest *discover.Node) (discover.NodeID, error) {
+func (c *setupTransport) doEncHandshake(prv *ecdsa.PrivateKey, dialDest *discover.Node, retired ...*ecdsa.PrivateKey) (discover.NodeID, *ecdsa.PrivateKey, error) {
 	c.calls += "doEncHandshake,"
-	return c.id, c.encHandshakeErr
+	return c.id, prv, c.encHandshakeErr
 }
 func (c *setupTransport) doProtoHandshake(our *protoHandshake) (*protoHandshake, error) {
 	c.calls += "doProtoHandshake,"
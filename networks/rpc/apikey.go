@@ -0,0 +1,271 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// APIKeyHeader is the HTTP header carrying the API key of a request.
+	// The API key can be also given as the path of the URL, e.g. http://localhost:8551/<key>.
+	APIKeyHeader = "X-Api-Key"
+
+	apiKeyContextKey = "apikey"
+)
+
+// ErrAPIKeysDisabled is returned if API keys are not enabled.
+var ErrAPIKeysDisabled = errors.New("API keys are not enabled")
+
+var (
+	apiKeyStoreMu sync.RWMutex
+	apiKeyStore   *APIKeyStore // API keys applied to HTTP and WebSocket requests. nil disables API keys.
+)
+
+// SetAPIKeyStore sets the API keys required for HTTP and WebSocket requests.
+// API keys are disabled if store is nil.
+func SetAPIKeyStore(store *APIKeyStore) {
+	apiKeyStoreMu.Lock()
+	defer apiKeyStoreMu.Unlock()
+	apiKeyStore = store
+}
+
+// GetAPIKeyStore returns the API keys required for HTTP and WebSocket requests.
+func GetAPIKeyStore() *APIKeyStore {
+	apiKeyStoreMu.RLock()
+	defer apiKeyStoreMu.RUnlock()
+	return apiKeyStore
+}
+
+// APIKeyConfig is the configuration of an API key.
+type APIKeyConfig struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+
+	// Methods is the list of the methods allowed to the key such as "klay_getBalance".
+	// "klay_*" allows all methods of the namespace. All methods are allowed if it is empty.
+	Methods []string `json:"methods"`
+
+	// RateLimit is the number of requests allowed per second and Burst is the maximum number of
+	// requests allowed at once. The requests are not limited if RateLimit is zero.
+	RateLimit float64 `json:"rateLimit"`
+	Burst     int     `json:"burst"`
+}
+
+// APIKeyUsage is the usage of an API key since it is loaded.
+type APIKeyUsage struct {
+	Name        string            `json:"name"`
+	Requests    uint64            `json:"requests"`    // the number of the served requests
+	Denied      uint64            `json:"denied"`      // the number of the requests calling disallowed methods
+	RateLimited uint64            `json:"rateLimited"` // the number of the requests rejected by the rate limit
+	Methods     map[string]uint64 `json:"methods"`     // the number of the served requests per method
+}
+
+type apiKey struct {
+	config     APIKeyConfig
+	methods    map[string]struct{}
+	namespaces map[string]struct{}
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	usage  APIKeyUsage
+}
+
+func newAPIKey(config APIKeyConfig) (*apiKey, error) {
+	if config.Key == "" {
+		return nil, fmt.Errorf("empty key of API key %q", config.Name)
+	}
+	if config.RateLimit < 0 {
+		return nil, fmt.Errorf("negative rate limit of API key %q", config.Name)
+	}
+	if config.Burst <= 0 {
+		config.Burst = int(math.Max(1, math.Ceil(config.RateLimit)))
+	}
+	key := &apiKey{
+		config:     config,
+		methods:    make(map[string]struct{}),
+		namespaces: make(map[string]struct{}),
+		tokens:     float64(config.Burst),
+		last:       time.Now(),
+		usage:      APIKeyUsage{Name: config.Name, Methods: make(map[string]uint64)},
+	}
+	for _, method := range config.Methods {
+		if strings.HasSuffix(method, serviceMethodSeparator+"*") {
+			key.namespaces[strings.TrimSuffix(method, serviceMethodSeparator+"*")] = struct{}{}
+		} else {
+			key.methods[method] = struct{}{}
+		}
+	}
+	return key, nil
+}
+
+// allowed returns true if the key is allowed to call the method.
+func (k *apiKey) allowed(namespace, method string) bool {
+	if len(k.config.Methods) == 0 {
+		return true
+	}
+	if _, ok := k.namespaces[namespace]; ok {
+		return true
+	}
+	_, ok := k.methods[method]
+	return ok
+}
+
+// take takes a token from the bucket of the key, returning false if the rate limit is exceeded.
+// The caller should hold the lock.
+func (k *apiKey) take(now time.Time) bool {
+	if k.config.RateLimit == 0 {
+		return true
+	}
+	k.tokens = math.Min(float64(k.config.Burst), k.tokens+now.Sub(k.last).Seconds()*k.config.RateLimit)
+	k.last = now
+	if k.tokens < 1 {
+		return false
+	}
+	k.tokens--
+	return true
+}
+
+// APIKeyStore holds the API keys allowed to call RPC methods over HTTP and WebSocket,
+// and accounts the usage of each key.
+type APIKeyStore struct {
+	path string             // the file the keys are loaded from
+	keys map[string]*apiKey // key -> apiKey
+}
+
+// NewAPIKeyStore returns an APIKeyStore of the given API keys.
+func NewAPIKeyStore(configs []APIKeyConfig) (*APIKeyStore, error) {
+	store := &APIKeyStore{keys: make(map[string]*apiKey, len(configs))}
+	names := make(map[string]struct{}, len(configs))
+	for _, config := range configs {
+		if _, ok := names[config.Name]; ok {
+			return nil, fmt.Errorf("duplicated API key name %q", config.Name)
+		}
+		if _, ok := store.keys[config.Key]; ok {
+			return nil, fmt.Errorf("duplicated key of API key %q", config.Name)
+		}
+		key, err := newAPIKey(config)
+		if err != nil {
+			return nil, err
+		}
+		names[config.Name] = struct{}{}
+		store.keys[config.Key] = key
+	}
+	return store, nil
+}
+
+// LoadAPIKeyStore returns an APIKeyStore of the API keys in the given JSON file,
+// which is a list of APIKeyConfig.
+func LoadAPIKeyStore(path string) (*APIKeyStore, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var configs []APIKeyConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("invalid API key file %s: %v", path, err)
+	}
+	store, err := NewAPIKeyStore(configs)
+	if err != nil {
+		return nil, err
+	}
+	store.path = path
+	return store, nil
+}
+
+// Path returns the file the keys are loaded from.
+func (s *APIKeyStore) Path() string {
+	return s.path
+}
+
+// authorize accounts the request of the method with the key, returning an error
+// if the key is unknown, not allowed to call the method or exceeds the rate limit.
+func (s *APIKeyStore) authorize(key, namespace, method string) Error {
+	k, ok := s.keys[key]
+	if !ok {
+		if key == "" {
+			return &unauthorizedError{"missing API key"}
+		}
+		return &unauthorizedError{"unknown API key"}
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if !k.allowed(namespace, method) {
+		k.usage.Denied++
+		return &unauthorizedError{fmt.Sprintf("the API key is not allowed to call %s", method)}
+	}
+	if !k.take(time.Now()) {
+		k.usage.RateLimited++
+		return &limitExceededError{fmt.Sprintf("rate limit of the API key is exceeded (%v requests/s)", k.config.RateLimit)}
+	}
+	k.usage.Requests++
+	k.usage.Methods[method]++
+	return nil
+}
+
+// Usage returns the usage of the API keys sorted by their names.
+func (s *APIKeyStore) Usage() []APIKeyUsage {
+	usages := make([]APIKeyUsage, 0, len(s.keys))
+	for _, k := range s.keys {
+		k.mu.Lock()
+		usage := k.usage
+		usage.Methods = make(map[string]uint64, len(k.usage.Methods))
+		for method, count := range k.usage.Methods {
+			usage.Methods[method] = count
+		}
+		k.mu.Unlock()
+		usages = append(usages, usage)
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].Name < usages[j].Name })
+	return usages
+}
+
+// withAPIKey returns a context carrying the API key of the request.
+// The requests without the context value, such as IPC and in-process requests, are not subject to API keys.
+func withAPIKey(ctx context.Context, header, path string) context.Context {
+	key := header
+	if key == "" {
+		key = strings.Trim(path, "/")
+	}
+	return context.WithValue(ctx, apiKeyContextKey, key)
+}
+
+// authorizeAPIKey checks the API key of the context for the method if API keys are enabled.
+// The requests authenticated by a JWT are not subject to API keys.
+func authorizeAPIKey(ctx context.Context, namespace, method string) Error {
+	key, ok := ctx.Value(apiKeyContextKey).(string)
+	if !ok || jwtAuthenticated(ctx) {
+		return nil
+	}
+	store := GetAPIKeyStore()
+	if store == nil {
+		return nil
+	}
+	return store.authorize(key, namespace, method)
+}
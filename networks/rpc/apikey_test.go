@@ -0,0 +1,119 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyStore(t *testing.T) {
+	store, err := NewAPIKeyStore([]APIKeyConfig{
+		{Name: "all", Key: "key1"},
+		{Name: "restricted", Key: "key2", Methods: []string{"klay_*", "net_version"}, RateLimit: 1, Burst: 2},
+	})
+	assert.NoError(t, err)
+
+	// Unknown keys
+	assert.IsType(t, &unauthorizedError{}, store.authorize("", "klay", "klay_blockNumber"))
+	assert.IsType(t, &unauthorizedError{}, store.authorize("key3", "klay", "klay_blockNumber"))
+
+	// Method allowlist
+	assert.Nil(t, store.authorize("key1", "admin", "admin_peers"))
+	assert.IsType(t, &unauthorizedError{}, store.authorize("key2", "admin", "admin_peers"))
+	assert.Nil(t, store.authorize("key2", "net", "net_version"))
+
+	// Rate limit, the second request consumes the burst
+	assert.Nil(t, store.authorize("key2", "klay", "klay_blockNumber"))
+	assert.IsType(t, &limitExceededError{}, store.authorize("key2", "klay", "klay_blockNumber"))
+
+	// Tokens are refilled by the rate
+	store.keys["key2"].last = time.Now().Add(-time.Second)
+	assert.Nil(t, store.authorize("key2", "klay", "klay_blockNumber"))
+
+	assert.Equal(t, []APIKeyUsage{
+		{Name: "all", Requests: 1, Methods: map[string]uint64{"admin_peers": 1}},
+		{Name: "restricted", Requests: 3, Denied: 1, RateLimited: 1, Methods: map[string]uint64{"net_version": 1, "klay_blockNumber": 2}},
+	}, store.Usage())
+
+	// Invalid configurations
+	_, err = NewAPIKeyStore([]APIKeyConfig{{Name: "a", Key: "key"}, {Name: "a", Key: "key2"}})
+	assert.Error(t, err)
+	_, err = NewAPIKeyStore([]APIKeyConfig{{Name: "a", Key: "key"}, {Name: "b", Key: "key"}})
+	assert.Error(t, err)
+	_, err = NewAPIKeyStore([]APIKeyConfig{{Name: "a"}})
+	assert.Error(t, err)
+}
+
+func TestAPIKeyHTTP(t *testing.T) {
+	store, err := NewAPIKeyStore([]APIKeyConfig{{Name: "test", Key: "secret", Methods: []string{"test_echo"}}})
+	assert.NoError(t, err)
+	SetAPIKeyStore(store)
+	defer SetAPIKeyStore(nil)
+
+	server := NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("test", new(Service)); err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	call := func(path, key, method, params string) *jsonError {
+		body := `{"jsonrpc":"2.0","id":1,"method":"` + method + `","params":` + params + `}`
+		req, _ := http.NewRequest(http.MethodPost, httpsrv.URL+path, strings.NewReader(body))
+		req.Header.Set("content-type", contentType)
+		if key != "" {
+			req.Header.Set(APIKeyHeader, key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var msg jsonErrResponse
+		if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.Error.Code == 0 {
+			return nil
+		}
+		return &msg.Error
+	}
+
+	echoParams := `["hello",1,{"S":"world"}]`
+	assert.Nil(t, call("", "secret", "test_echo", echoParams))
+	assert.Nil(t, call("/secret", "", "test_echo", echoParams))
+	assert.Equal(t, -32001, call("", "", "test_echo", echoParams).Code)
+	assert.Equal(t, -32001, call("/wrong", "", "test_echo", echoParams).Code)
+	assert.Equal(t, -32001, call("", "secret", "test_rets", "[]").Code)
+
+	// In-process requests are not subject to API keys
+	client := DialInProc(server)
+	defer client.Close()
+	var result string
+	assert.NoError(t, client.Call(&result, "test_rets"))
+
+	assert.Equal(t, uint64(2), store.Usage()[0].Requests)
+	assert.Equal(t, uint64(1), store.Usage()[0].Denied)
+}
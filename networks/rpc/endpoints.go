@@ -21,6 +21,7 @@
 package rpc
 
 import (
+	"fmt"
 	"net"
 )
 
@@ -82,6 +83,39 @@ func StartFastHTTPEndpoint(endpoint string, apis []API, modules []string, cors [
 	return listener, handler, err
 }
 
+// StartAuthHTTPEndpoint starts the HTTP RPC endpoint authenticated by JWTs signed with the secret.
+// Only the given modules are exposed, including the private ones.
+func StartAuthHTTPEndpoint(endpoint string, apis []API, modules []string, vhosts []string, timeouts HTTPTimeouts, secret []byte) (net.Listener, *Server, error) {
+	if len(secret) != JWTSecretLength {
+		return nil, nil, fmt.Errorf("invalid JWT secret length %d, want %d", len(secret), JWTSecretLength)
+	}
+	// Generate the whitelist based on the allowed modules
+	whitelist := make(map[string]bool)
+	for _, module := range modules {
+		whitelist[module] = true
+	}
+	// Register all the APIs exposed by the services
+	handler := NewServer()
+	for _, api := range apis {
+		if whitelist[api.Namespace] {
+			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
+				return nil, nil, err
+			}
+			logger.Debug("Authenticated HTTP registered", "namespace", api.Namespace)
+		}
+	}
+	// All APIs registered, start the HTTP listener
+	var (
+		listener net.Listener
+		err      error
+	)
+	if listener, err = net.Listen("tcp", endpoint); err != nil {
+		return nil, nil, err
+	}
+	go NewAuthHTTPServer(vhosts, timeouts, secret, handler).Serve(listener)
+	return listener, handler, err
+}
+
 // StartWSEndpoint starts a websocket endpoint
 func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []string, exposeAll bool) (net.Listener, *Server, error) {
 
@@ -51,6 +51,27 @@ func (e *invalidParamsError) ErrorCode() int { return -32602 }
 
 func (e *invalidParamsError) Error() string { return e.message }
 
+// request with a missing or unknown API key, or calling a method not allowed to the API key
+type unauthorizedError struct{ message string }
+
+func (e *unauthorizedError) ErrorCode() int { return -32001 }
+
+func (e *unauthorizedError) Error() string { return e.message }
+
+// request exceeding the rate limit of the API key or the limits of the method
+type limitExceededError struct{ message string }
+
+func (e *limitExceededError) ErrorCode() int { return -32005 }
+
+func (e *limitExceededError) Error() string { return e.message }
+
+// request exceeding the execution timeout of its namespace
+type timeoutError struct{ message string }
+
+func (e *timeoutError) ErrorCode() int { return -32002 }
+
+func (e *timeoutError) Error() string { return e.message }
+
 // logic error, callback returned an error
 type callbackError struct{ message string }
 
@@ -203,6 +203,23 @@ func NewHTTPServer(cors []string, vhosts []string, timeouts HTTPTimeouts, srv *S
 	// Wrap the CORS-handler within a host-handler
 	handler := newCorsHandler(srv, cors)
 	handler = newVHostHandler(vhosts, handler)
+	handler = newGzipHandler(handler)
+	return &http.Server{
+		Handler:      handler,
+		ReadTimeout:  timeouts.ReadTimeout,
+		WriteTimeout: timeouts.WriteTimeout,
+		IdleTimeout:  timeouts.IdleTimeout,
+	}
+}
+
+// NewAuthHTTPServer creates a new HTTP RPC server around an API provider,
+// which serves only the requests with a JWT signed with the secret.
+func NewAuthHTTPServer(vhosts []string, timeouts HTTPTimeouts, secret []byte, srv *Server) *http.Server {
+	timeouts = sanitizeTimeouts(timeouts)
+	// Check the hosts of the requests before authenticating them
+	handler := newJWTHandler(secret, srv)
+	handler = newVHostHandler(vhosts, handler)
+	handler = newGzipHandler(handler)
 	return &http.Server{
 		Handler:      handler,
 		ReadTimeout:  timeouts.ReadTimeout,
@@ -218,7 +235,7 @@ func NewFastHTTPServer(cors []string, vhosts []string, timeouts HTTPTimeouts, sr
 			if vhost == "*" {
 				return &fasthttp.Server{
 					Concurrency:  ConcurrencyLimit,
-					Handler:      srv.HandleFastHTTP,
+					Handler:      fasthttp.CompressHandler(srv.HandleFastHTTP),
 					ReadTimeout:  timeouts.ReadTimeout,
 					WriteTimeout: timeouts.WriteTimeout,
 					IdleTimeout:  timeouts.IdleTimeout,
@@ -236,7 +253,8 @@ func NewFastHTTPServer(cors []string, vhosts []string, timeouts HTTPTimeouts, sr
 		handler = newNewRelicHTTPHandler(nrApp, handler)
 	}
 
-	fhandler := fasthttpadaptor.NewFastHTTPHandler(handler)
+	// Compress the responses if the requests accept it
+	fhandler := fasthttp.CompressHandler(fasthttpadaptor.NewFastHTTPHandler(handler))
 
 	// TODO-Klaytn concurreny default (256 * 1024), goroutine limit (8192)
 	return &fasthttp.Server{
@@ -265,6 +283,7 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx = context.WithValue(ctx, "remote", r.RemoteAddr)
 	ctx = context.WithValue(ctx, "scheme", r.Proto)
 	ctx = context.WithValue(ctx, "local", r.Host)
+	ctx = withAPIKey(ctx, r.Header.Get(APIKeyHeader), r.URL.Path)
 
 	body := io.LimitReader(r.Body, int64(common.MaxRequestContentLength))
 	codec := NewJSONCodec(&httpReadWriteNopCloser{body, w})
@@ -298,6 +317,7 @@ func (srv *Server) HandleFastHTTP(requestCtx *fasthttp.RequestCtx) {
 	ctx = context.WithValue(ctx, "remote", requestCtx.RemoteAddr().String())
 	ctx = context.WithValue(ctx, "scheme", string(requestCtx.URI().Scheme()))
 	ctx = context.WithValue(ctx, "local", requestCtx.LocalAddr().String())
+	ctx = withAPIKey(ctx, string(requestCtx.Request.Header.Peek(APIKeyHeader)), string(requestCtx.Path()))
 
 	reader := bufio.NewReaderSize(bytes.NewReader(r.Body()), common.MaxRequestContentLength)
 	codec := NewJSONCodec(&httpReadWriteNopCloser{reader, w.BodyWriter()})
@@ -403,6 +423,12 @@ func (h *virtualHostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	http.Error(w, "invalid host specified", http.StatusForbidden)
 }
 
+// NewVHostHandler returns a handler serving the requests to the given virtual hosts only,
+// which can be used by the other HTTP services of the node to prevent DNS rebinding attacks.
+func NewVHostHandler(vhosts []string, next http.Handler) http.Handler {
+	return newVHostHandler(vhosts, next)
+}
+
 func newVHostHandler(vhosts []string, next http.Handler) http.Handler {
 	vhostMap := make(map[string]struct{})
 	for _, allowedHost := range vhosts {
@@ -17,12 +17,16 @@
 package rpc
 
 import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestHTTPErrorResponseWithDelete(t *testing.T) {
@@ -54,3 +58,43 @@ func testHTTPErrorResponse(t *testing.T, method, contentType, body string, expec
 		t.Fatalf("response code should be %d not %d", expected, code)
 	}
 }
+
+func TestHTTPGzipResponse(t *testing.T) {
+	server := NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("test", new(Service)); err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(NewHTTPServer(nil, []string{"*"}, DefaultHTTPTimeouts, server).Handler)
+	defer httpsrv.Close()
+
+	call := func(acceptEncoding string) (*http.Response, []byte) {
+		body := `{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["hello",1,{"S":"world"}]}`
+		req, _ := http.NewRequest(http.MethodPost, httpsrv.URL, strings.NewReader(body))
+		req.Header.Set("content-type", contentType)
+		req.Header.Set("accept-encoding", acceptEncoding)
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var reader io.Reader = resp.Body
+		if resp.Header.Get("content-encoding") == "gzip" {
+			if reader, err = gzip.NewReader(resp.Body); err != nil {
+				t.Fatal(err)
+			}
+		}
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, data
+	}
+
+	resp, compressed := call("gzip")
+	assert.Equal(t, "gzip", resp.Header.Get("content-encoding"))
+	resp, plain := call("")
+	assert.Equal(t, "", resp.Header.Get("content-encoding"))
+	assert.Equal(t, plain, compressed)
+	assert.Contains(t, string(plain), `"result"`)
+}
@@ -0,0 +1,151 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// JWTSecretLength is the length of the shared secret of JWT-authenticated endpoints.
+	JWTSecretLength = 32
+
+	// jwtExpiryTimeout is the maximum difference between the issued-at time of a token
+	// and the local time, which limits the replay of the captured tokens.
+	jwtExpiryTimeout = 60 * time.Second
+
+	jwtContextKey = "jwt"
+)
+
+var (
+	errMissingJWT        = errors.New("missing token")
+	errMalformedJWT      = errors.New("malformed token")
+	errUnsupportedJWTAlg = errors.New("unsupported signing algorithm, only HS256 is supported")
+	errInvalidJWTSig     = errors.New("invalid token signature")
+	errMissingJWTIat     = errors.New("missing issued-at")
+	errStaleJWT          = errors.New("stale token")
+
+	jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+)
+
+type jwtClaims struct {
+	Iat *int64 `json:"iat"`
+}
+
+// NewJWTToken returns an HS256 token issued at the given time and signed with the secret,
+// which authenticates a request to a JWT-authenticated endpoint given as
+// "Authorization: Bearer <token>".
+func NewJWTToken(secret []byte, iat time.Time) string {
+	claims, _ := json.Marshal(map[string]int64{"iat": iat.Unix()})
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(claims)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(jwtSignature(secret, unsigned))
+}
+
+func jwtSignature(secret []byte, unsigned string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return mac.Sum(nil)
+}
+
+// verifyJWTToken checks if the token is signed with the secret by HS256
+// and issued within jwtExpiryTimeout from now.
+func verifyJWTToken(secret []byte, token string, now time.Time) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return errMalformedJWT
+	}
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return errMalformedJWT
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return errMalformedJWT
+	}
+	if header.Alg != "HS256" {
+		return errUnsupportedJWTAlg
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return errMalformedJWT
+	}
+	if !hmac.Equal(sig, jwtSignature(secret, parts[0]+"."+parts[1])) {
+		return errInvalidJWTSig
+	}
+	rawClaims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return errMalformedJWT
+	}
+	var claims jwtClaims
+	if err := json.Unmarshal(rawClaims, &claims); err != nil {
+		return errMalformedJWT
+	}
+	if claims.Iat == nil {
+		return errMissingJWTIat
+	}
+	diff := now.Sub(time.Unix(*claims.Iat, 0))
+	if diff > jwtExpiryTimeout || diff < -jwtExpiryTimeout {
+		return errStaleJWT
+	}
+	return nil
+}
+
+// jwtHandler is an http.Handler which rejects the requests without a valid token.
+type jwtHandler struct {
+	secret []byte
+	next   http.Handler
+}
+
+func newJWTHandler(secret []byte, next http.Handler) http.Handler {
+	return &jwtHandler{secret: secret, next: next}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *jwtHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		http.Error(w, errMissingJWT.Error(), http.StatusUnauthorized)
+		return
+	}
+	if !strings.HasPrefix(auth, "Bearer ") {
+		http.Error(w, errMalformedJWT.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err := verifyJWTToken(h.secret, strings.TrimPrefix(auth, "Bearer "), time.Now()); err != nil {
+		http.Error(w, fmt.Sprintf("invalid token: %v", err), http.StatusUnauthorized)
+		return
+	}
+	h.next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), jwtContextKey, true)))
+}
+
+// jwtAuthenticated returns true if the request of the context is authenticated by a token,
+// which is not subject to API keys.
+func jwtAuthenticated(ctx context.Context) bool {
+	authenticated, _ := ctx.Value(jwtContextKey).(bool)
+	return authenticated
+}
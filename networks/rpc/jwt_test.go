@@ -0,0 +1,94 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyJWTToken(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x01}, JWTSecretLength)
+	now := time.Unix(time.Now().Unix(), 0)
+
+	assert.NoError(t, verifyJWTToken(secret, NewJWTToken(secret, now), now))
+	assert.NoError(t, verifyJWTToken(secret, NewJWTToken(secret, now.Add(-jwtExpiryTimeout)), now))
+	assert.Equal(t, errStaleJWT, verifyJWTToken(secret, NewJWTToken(secret, now.Add(-jwtExpiryTimeout-time.Second)), now))
+	assert.Equal(t, errStaleJWT, verifyJWTToken(secret, NewJWTToken(secret, now.Add(jwtExpiryTimeout+time.Second)), now))
+
+	other := bytes.Repeat([]byte{0x02}, JWTSecretLength)
+	assert.Equal(t, errInvalidJWTSig, verifyJWTToken(other, NewJWTToken(secret, now), now))
+	assert.Equal(t, errMalformedJWT, verifyJWTToken(secret, "abc.def", now))
+
+	sign := func(header, claims string) string {
+		unsigned := base64.RawURLEncoding.EncodeToString([]byte(header)) + "." + base64.RawURLEncoding.EncodeToString([]byte(claims))
+		return unsigned + "." + base64.RawURLEncoding.EncodeToString(jwtSignature(secret, unsigned))
+	}
+	assert.Equal(t, errUnsupportedJWTAlg, verifyJWTToken(secret, sign(`{"alg":"none"}`, `{}`), now))
+	assert.Equal(t, errMissingJWTIat, verifyJWTToken(secret, sign(`{"alg":"HS256"}`, `{}`), now))
+}
+
+func TestJWTHandler(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x01}, JWTSecretLength)
+
+	// API keys are not required for the authenticated requests
+	store, err := NewAPIKeyStore([]APIKeyConfig{{Name: "test", Key: "key"}})
+	assert.NoError(t, err)
+	SetAPIKeyStore(store)
+	defer SetAPIKeyStore(nil)
+
+	server := NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("test", new(Service)); err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(NewAuthHTTPServer([]string{"*"}, DefaultHTTPTimeouts, secret, server).Handler)
+	defer httpsrv.Close()
+
+	call := func(auth string) int {
+		body := `{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["hello",1,{"S":"world"}]}`
+		req, _ := http.NewRequest(http.MethodPost, httpsrv.URL, strings.NewReader(body))
+		req.Header.Set("content-type", contentType)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var out bytes.Buffer
+		out.ReadFrom(resp.Body)
+		if resp.StatusCode == http.StatusOK {
+			assert.NotContains(t, out.String(), `"error"`)
+		}
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, call("Bearer "+NewJWTToken(secret, time.Now())))
+	assert.Equal(t, http.StatusUnauthorized, call(""))
+	assert.Equal(t, http.StatusUnauthorized, call(NewJWTToken(secret, time.Now())))
+	assert.Equal(t, http.StatusUnauthorized, call("Bearer "+NewJWTToken(secret, time.Now().Add(-time.Hour))))
+	assert.Equal(t, http.StatusUnauthorized, call("Bearer "+NewJWTToken(bytes.Repeat([]byte{0x02}, JWTSecretLength), time.Now())))
+}
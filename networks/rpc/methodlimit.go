@@ -0,0 +1,265 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+// ErrMethodLimitsDisabled is returned if method limits are not enabled.
+var ErrMethodLimitsDisabled = errors.New("method limits are not enabled")
+
+var (
+	methodLimiterMu sync.RWMutex
+	methodLimiter   *MethodLimiter // limits applied to HTTP and WebSocket requests. nil disables the limits.
+)
+
+// SetMethodLimiter sets the limits of the methods called by HTTP and WebSocket requests.
+// The limits are disabled if limiter is nil.
+func SetMethodLimiter(limiter *MethodLimiter) {
+	methodLimiterMu.Lock()
+	defer methodLimiterMu.Unlock()
+	methodLimiter = limiter
+}
+
+// GetMethodLimiter returns the limits of the methods called by HTTP and WebSocket requests.
+func GetMethodLimiter() *MethodLimiter {
+	methodLimiterMu.RLock()
+	defer methodLimiterMu.RUnlock()
+	return methodLimiter
+}
+
+// MethodLimitConfig is the configuration of the limit of a method or a namespace.
+type MethodLimitConfig struct {
+	// Name is the method such as "klay_call", or the namespace such as "klay_*".
+	// A request is subject to both of the limits of its method and namespace.
+	Name string `json:"name"`
+
+	// RateLimit is the number of requests allowed per second and Burst is the maximum number of
+	// requests allowed at once. The requests are not rate limited if RateLimit is zero.
+	RateLimit float64 `json:"rateLimit"`
+	Burst     int     `json:"burst"`
+
+	// MaxConcurrent is the maximum number of the requests executed at the same time.
+	// The concurrent executions are not limited if it is zero.
+	MaxConcurrent int `json:"maxConcurrent"`
+}
+
+// MethodLimitUsage is the usage of the limit of a method or a namespace since it is loaded.
+type MethodLimitUsage struct {
+	Name               string `json:"name"`
+	Requests           uint64 `json:"requests"`           // the number of the served requests
+	RateLimited        uint64 `json:"rateLimited"`        // the number of the requests rejected by the rate limit
+	ConcurrencyLimited uint64 `json:"concurrencyLimited"` // the number of the requests rejected by the concurrency limit
+	Running            int    `json:"running"`            // the number of the requests being executed
+}
+
+type methodLimit struct {
+	config MethodLimitConfig
+
+	mu      sync.Mutex
+	tokens  float64
+	last    time.Time
+	running int
+	usage   MethodLimitUsage
+
+	rejectedCounter metrics.Counter
+}
+
+func newMethodLimit(config MethodLimitConfig) (*methodLimit, error) {
+	if config.Name == "" {
+		return nil, errors.New("empty name of method limit")
+	}
+	if config.RateLimit < 0 {
+		return nil, fmt.Errorf("negative rate limit of %q", config.Name)
+	}
+	if config.MaxConcurrent < 0 {
+		return nil, fmt.Errorf("negative concurrency limit of %q", config.Name)
+	}
+	if config.Burst <= 0 {
+		config.Burst = int(math.Max(1, math.Ceil(config.RateLimit)))
+	}
+	return &methodLimit{
+		config:          config,
+		tokens:          float64(config.Burst),
+		last:            time.Now(),
+		usage:           MethodLimitUsage{Name: config.Name},
+		rejectedCounter: metrics.GetOrRegisterCounter("rpc/limits/"+config.Name+"/rejected", nil),
+	}, nil
+}
+
+// acquire takes a token from the bucket and a concurrent execution slot of the limit.
+// The caller should hold the lock.
+func (l *methodLimit) acquire(now time.Time) Error {
+	if l.config.MaxConcurrent > 0 && l.running >= l.config.MaxConcurrent {
+		l.usage.ConcurrencyLimited++
+		l.rejectedCounter.Inc(1)
+		return &limitExceededError{fmt.Sprintf("concurrency limit of %s is exceeded (%d executions)", l.config.Name, l.config.MaxConcurrent)}
+	}
+	if l.config.RateLimit > 0 {
+		l.tokens = math.Min(float64(l.config.Burst), l.tokens+now.Sub(l.last).Seconds()*l.config.RateLimit)
+		l.last = now
+		if l.tokens < 1 {
+			l.usage.RateLimited++
+			l.rejectedCounter.Inc(1)
+			return &limitExceededError{fmt.Sprintf("rate limit of %s is exceeded (%v requests/s)", l.config.Name, l.config.RateLimit)}
+		}
+		l.tokens--
+	}
+	l.running++
+	l.usage.Requests++
+	return nil
+}
+
+func (l *methodLimit) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.running--
+}
+
+// cancel releases the execution slot of a request rejected by another limit.
+func (l *methodLimit) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.running--
+	l.usage.Requests--
+}
+
+// MethodLimiter holds the rate and concurrency limits of the methods and namespaces
+// called by HTTP and WebSocket requests, and accounts their usage.
+type MethodLimiter struct {
+	path   string                  // the file the limits are loaded from
+	limits map[string]*methodLimit // method or "namespace_*" -> methodLimit
+}
+
+// NewMethodLimiter returns a MethodLimiter of the given limits.
+func NewMethodLimiter(configs []MethodLimitConfig) (*MethodLimiter, error) {
+	limiter := &MethodLimiter{limits: make(map[string]*methodLimit, len(configs))}
+	for _, config := range configs {
+		if _, ok := limiter.limits[config.Name]; ok {
+			return nil, fmt.Errorf("duplicated method limit %q", config.Name)
+		}
+		limit, err := newMethodLimit(config)
+		if err != nil {
+			return nil, err
+		}
+		limiter.limits[config.Name] = limit
+	}
+	return limiter, nil
+}
+
+// LoadMethodLimiter returns a MethodLimiter of the limits in the given JSON file,
+// which is a list of MethodLimitConfig.
+func LoadMethodLimiter(path string) (*MethodLimiter, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var configs []MethodLimitConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("invalid method limit file %s: %v", path, err)
+	}
+	limiter, err := NewMethodLimiter(configs)
+	if err != nil {
+		return nil, err
+	}
+	limiter.path = path
+	return limiter, nil
+}
+
+// Path returns the file the limits are loaded from.
+func (l *MethodLimiter) Path() string {
+	return l.path
+}
+
+// acquire checks the limits of the method and its namespace, returning a function releasing
+// the concurrent execution slots taken by the request, or an error if a limit is exceeded.
+func (l *MethodLimiter) acquire(namespace, method string) (func(), Error) {
+	var acquired []*methodLimit
+	now := time.Now()
+	for _, name := range []string{method, namespace + serviceMethodSeparator + "*"} {
+		limit, ok := l.limits[name]
+		if !ok {
+			continue
+		}
+		limit.mu.Lock()
+		err := limit.acquire(now)
+		limit.mu.Unlock()
+		if err != nil {
+			for _, limit := range acquired {
+				limit.cancel()
+			}
+			rpcLimitedRequestsCounter.Inc(1)
+			return nil, err
+		}
+		acquired = append(acquired, limit)
+	}
+	return func() {
+		for _, limit := range acquired {
+			limit.release()
+		}
+	}, nil
+}
+
+// Usage returns the usage of the limits sorted by their names.
+func (l *MethodLimiter) Usage() []MethodLimitUsage {
+	usages := make([]MethodLimitUsage, 0, len(l.limits))
+	for _, limit := range l.limits {
+		limit.mu.Lock()
+		usage := limit.usage
+		usage.Running = limit.running
+		limit.mu.Unlock()
+		usages = append(usages, usage)
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].Name < usages[j].Name })
+	return usages
+}
+
+// limitedRequest returns true if the request of the context is subject to API keys,
+// which is an HTTP or WebSocket request not authenticated by a JWT.
+func limitedRequest(ctx context.Context) bool {
+	_, ok := ctx.Value(apiKeyContextKey).(string)
+	return ok && !jwtAuthenticated(ctx)
+}
+
+// acquireMethodLimit checks the limits of the method if method limits are enabled.
+// Only the requests subject to API keys are limited.
+func acquireMethodLimit(ctx context.Context, namespace, method string) (func(), Error) {
+	noop := func() {}
+	if !limitedRequest(ctx) {
+		return noop, nil
+	}
+	limiter := GetMethodLimiter()
+	if limiter == nil {
+		return noop, nil
+	}
+	release, err := limiter.acquire(namespace, method)
+	if err != nil {
+		return noop, err
+	}
+	return release, nil
+}
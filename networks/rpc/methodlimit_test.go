@@ -0,0 +1,121 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMethodLimiter(t *testing.T) {
+	limiter, err := NewMethodLimiter([]MethodLimitConfig{
+		{Name: "klay_call", MaxConcurrent: 1},
+		{Name: "klay_*", RateLimit: 1, Burst: 2},
+	})
+	assert.NoError(t, err)
+
+	// Methods without limits
+	release, err2 := limiter.acquire("net", "net_version")
+	assert.Nil(t, err2)
+	release()
+
+	// Concurrency limit of the method
+	release, err2 = limiter.acquire("klay", "klay_call")
+	assert.Nil(t, err2)
+	_, err2 = limiter.acquire("klay", "klay_call")
+	assert.IsType(t, &limitExceededError{}, err2)
+	release()
+
+	// Rate limit of the namespace, the second request consumes the burst
+	release, err2 = limiter.acquire("klay", "klay_blockNumber")
+	assert.Nil(t, err2)
+	release()
+
+	// A request rejected by the namespace does not hold the slot of the method
+	_, err2 = limiter.acquire("klay", "klay_call")
+	assert.IsType(t, &limitExceededError{}, err2)
+
+	// Tokens are refilled by the rate
+	limiter.limits["klay_*"].last = time.Now().Add(-time.Second)
+	release, err2 = limiter.acquire("klay", "klay_call")
+	assert.Nil(t, err2)
+	release()
+
+	assert.Equal(t, []MethodLimitUsage{
+		{Name: "klay_*", Requests: 3, RateLimited: 1},
+		{Name: "klay_call", Requests: 2, ConcurrencyLimited: 1},
+	}, limiter.Usage())
+
+	// Invalid configurations
+	_, err = NewMethodLimiter([]MethodLimitConfig{{Name: "klay_call"}, {Name: "klay_call"}})
+	assert.Error(t, err)
+	_, err = NewMethodLimiter([]MethodLimitConfig{{Name: ""}})
+	assert.Error(t, err)
+	_, err = NewMethodLimiter([]MethodLimitConfig{{Name: "klay_*", MaxConcurrent: -1}})
+	assert.Error(t, err)
+}
+
+func TestMethodLimitHTTP(t *testing.T) {
+	limiter, err := NewMethodLimiter([]MethodLimitConfig{{Name: "test_echo", RateLimit: 1}})
+	assert.NoError(t, err)
+	SetMethodLimiter(limiter)
+	defer SetMethodLimiter(nil)
+
+	server := NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("test", new(Service)); err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	call := func(method, params string) *jsonError {
+		body := `{"jsonrpc":"2.0","id":1,"method":"` + method + `","params":` + params + `}`
+		resp, err := http.Post(httpsrv.URL, contentType, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var msg jsonErrResponse
+		if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.Error.Code == 0 {
+			return nil
+		}
+		return &msg.Error
+	}
+
+	echoParams := `["hello",1,{"S":"world"}]`
+	assert.Nil(t, call("test_echo", echoParams))
+	assert.Equal(t, -32005, call("test_echo", echoParams).Code)
+	assert.Nil(t, call("test_rets", "[]"))
+
+	// In-process requests are not subject to method limits
+	client := DialInProc(server)
+	defer client.Close()
+	var result echoResult
+	assert.NoError(t, client.Call(&result, "test_echo", "hello", 1, &echoArgs{"world"}))
+
+	assert.Equal(t, []MethodLimitUsage{{Name: "test_echo", Requests: 1, RateLimited: 1}}, limiter.Usage())
+}
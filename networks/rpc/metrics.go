@@ -7,6 +7,8 @@ var (
 	rpcSuccessResponsesCounter = metrics.NewRegisteredCounter("rpc/counts/success", nil)
 	rpcErrorResponsesCounter   = metrics.NewRegisteredCounter("rpc/counts/errors", nil)
 	rpcPendingRequestsCount    = metrics.NewRegisteredCounter("rpc/counts/pending", nil)
+	rpcLimitedRequestsCounter  = metrics.NewRegisteredCounter("rpc/counts/limited", nil)
+	rpcTimedOutRequestsCounter = metrics.NewRegisteredCounter("rpc/counts/timedout", nil)
 
 	wsSubscriptionReqCounter   = metrics.NewRegisteredCounter("ws/counts/subscription/request", nil)
 	wsUnsubscriptionReqCounter = metrics.NewRegisteredCounter("ws/counts/unsubscription/request", nil)
@@ -288,8 +288,13 @@ func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot
 // response back using the given codec. It will block until the codec is closed or the server is
 // stopped. In either case the codec is closed.
 func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
+	s.serveCodec(context.Background(), codec, options)
+}
+
+// serveCodec is ServeCodec with the context of the connection.
+func (s *Server) serveCodec(ctx context.Context, codec ServerCodec, options CodecOption) {
 	defer codec.Close()
-	s.serveRequest(context.Background(), codec, false, options)
+	s.serveRequest(ctx, codec, false, options)
 }
 
 // ServeSingleRequest reads and processes a single RPC request from the given codec. It will not
@@ -360,6 +365,21 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 		return codec.CreateErrorResponse(&req.id, &invalidParamsError{"Expected subscription id as first argument"}), nil
 	}
 
+	method := req.svcname + serviceMethodSeparator + formatName(req.callb.method.Name)
+	if req.callb.isSubscribe {
+		method = req.svcname + subscribeMethodSuffix
+	}
+	if err := authorizeAPIKey(ctx, req.svcname, method); err != nil {
+		rpcErrorResponsesCounter.Inc(1)
+		return codec.CreateErrorResponse(&req.id, err), nil
+	}
+	release, err := acquireMethodLimit(ctx, req.svcname, method)
+	if err != nil {
+		rpcErrorResponsesCounter.Inc(1)
+		return codec.CreateErrorResponse(&req.id, err), nil
+	}
+	defer release()
+
 	if req.callb.isSubscribe {
 		if atomic.LoadInt32(subCnt) >= MaxSubscriptionPerWSConn {
 			return codec.CreateErrorResponse(&req.id, &callbackError{
@@ -394,6 +414,14 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 		return codec.CreateErrorResponse(&req.id, rpcErr), nil
 	}
 
+	// The context of the method is cancelled if the execution timeout of its namespace expires
+	timeout, hasTimeout := executionTimeout(ctx, req.svcname)
+	if hasTimeout {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	arguments := []reflect.Value{req.callb.rcvr}
 	if req.callb.hasCtx {
 		arguments = append(arguments, reflect.ValueOf(ctx))
@@ -420,6 +448,18 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 		if !reply[req.callb.errPos].IsNil() {
 			e := reply[req.callb.errPos].Interface().(error)
 			rpcErrorResponsesCounter.Inc(1)
+			if hasTimeout && ctx.Err() == context.DeadlineExceeded {
+				rpcTimedOutRequestsCounter.Inc(1)
+				return codec.CreateErrorResponse(&req.id, &timeoutError{
+					fmt.Sprintf("execution timeout of %s (%v) exceeded: %v", req.svcname, timeout, e)}), nil
+			}
+			// Keep the error code if the callback returned an error with its own code
+			if ec, ok := e.(Error); ok {
+				if de, ok := e.(DataError); ok {
+					return codec.CreateErrorResponseWithInfo(&req.id, ec, de.ErrorData()), nil
+				}
+				return codec.CreateErrorResponse(&req.id, ec), nil
+			}
 			res := codec.CreateErrorResponse(&req.id, &callbackError{e.Error()})
 			return res, nil
 		}
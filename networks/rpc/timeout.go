@@ -0,0 +1,94 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeoutNamespace is the namespace of the execution timeout applied to
+// the namespaces without their own timeouts.
+const DefaultTimeoutNamespace = "*"
+
+var (
+	executionTimeoutsMu sync.RWMutex
+	executionTimeouts   = make(map[string]time.Duration) // namespace -> timeout
+)
+
+// SetExecutionTimeout sets the execution timeout of the methods of the namespace called
+// by HTTP and WebSocket requests. The context of a method is cancelled when the timeout
+// expires. A zero timeout removes the timeout of the namespace.
+func SetExecutionTimeout(namespace string, timeout time.Duration) {
+	executionTimeoutsMu.Lock()
+	defer executionTimeoutsMu.Unlock()
+	if timeout <= 0 {
+		delete(executionTimeouts, namespace)
+	} else {
+		executionTimeouts[namespace] = timeout
+	}
+}
+
+// ExecutionTimeouts returns the execution timeouts of the namespaces.
+func ExecutionTimeouts() map[string]time.Duration {
+	executionTimeoutsMu.RLock()
+	defer executionTimeoutsMu.RUnlock()
+	timeouts := make(map[string]time.Duration, len(executionTimeouts))
+	for namespace, timeout := range executionTimeouts {
+		timeouts[namespace] = timeout
+	}
+	return timeouts
+}
+
+// ParseExecutionTimeouts parses comma separated namespace:timeout pairs such as
+// "debug:1m,klay:5s,*:10s", where "*" is the timeout of the other namespaces.
+func ParseExecutionTimeouts(s string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		kv := strings.Split(pair, ":")
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("invalid namespace timeout pair %q", pair)
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(kv[1]))
+		if err != nil || timeout < 0 {
+			return nil, fmt.Errorf("invalid timeout %q", kv[1])
+		}
+		timeouts[strings.TrimSpace(kv[0])] = timeout
+	}
+	return timeouts, nil
+}
+
+// executionTimeout returns the execution timeout of the namespace for the request of the context.
+// Only the requests subject to API keys have execution timeouts.
+func executionTimeout(ctx context.Context, namespace string) (time.Duration, bool) {
+	if !limitedRequest(ctx) {
+		return 0, false
+	}
+	executionTimeoutsMu.RLock()
+	defer executionTimeoutsMu.RUnlock()
+	if timeout, ok := executionTimeouts[namespace]; ok {
+		return timeout, true
+	}
+	timeout, ok := executionTimeouts[DefaultTimeoutNamespace]
+	return timeout, ok
+}
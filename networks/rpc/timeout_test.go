@@ -0,0 +1,80 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type WaitService struct{}
+
+// Wait blocks until the context is cancelled.
+func (s *WaitService) Wait(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestParseExecutionTimeouts(t *testing.T) {
+	timeouts, err := ParseExecutionTimeouts("debug:1m, klay:5s,*:10s,")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{"debug": time.Minute, "klay": 5 * time.Second, "*": 10 * time.Second}, timeouts)
+
+	for _, s := range []string{"debug", "debug:1m:2s", ":1m", "debug:abc", "debug:-1s"} {
+		_, err := ParseExecutionTimeouts(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestExecutionTimeoutHTTP(t *testing.T) {
+	SetExecutionTimeout("slow", 100*time.Millisecond)
+	defer SetExecutionTimeout("slow", 0)
+
+	server := NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("slow", new(WaitService)); err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"slow_wait","params":[]}`
+	resp, err := http.Post(httpsrv.URL, contentType, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var msg jsonErrResponse
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, -32002, msg.Error.Code)
+
+	// Namespaces without timeouts and in-process requests are not subject to timeouts
+	_, ok := executionTimeout(withAPIKey(context.Background(), "", ""), "other")
+	assert.False(t, ok)
+	_, ok = executionTimeout(context.Background(), "slow")
+	assert.False(t, ok)
+	assert.Equal(t, map[string]time.Duration{"slow": 100 * time.Millisecond}, ExecutionTimeouts())
+}
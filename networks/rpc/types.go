@@ -101,6 +101,12 @@ type Error interface {
 	ErrorCode() int // returns the code
 }
 
+// DataError is an RPC error carrying the data which explains the error in detail.
+type DataError interface {
+	Error() string          // returns the message
+	ErrorData() interface{} // returns the error data
+}
+
 // ServerCodec implements reading, parsing and writing RPC messages for the server side of
 // a RPC session. Implementations must be go-routine safe since the codec can be called in
 // multiple go-routines concurrently.
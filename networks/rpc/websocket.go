@@ -93,7 +93,8 @@ func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			decoder := func(v interface{}) error {
 				return websocketJSONCodec.Receive(conn, v)
 			}
-			srv.ServeCodec(NewCodec(conn, encoder, decoder), OptionMethodInvocation|OptionSubscriptions)
+			ctx := withAPIKey(context.Background(), conn.Request().Header.Get(APIKeyHeader), conn.Request().URL.Path)
+			srv.serveCodec(ctx, NewCodec(conn, encoder, decoder), OptionMethodInvocation|OptionSubscriptions)
 		},
 	}
 }
@@ -111,6 +112,7 @@ func (srv *Server) FastWebsocketHandler(ctx *fasthttp.RequestCtx) {
 		ctx.Response.Header.Set("Sec-WebSocket-Protocol", string(protocol))
 	}
 
+	apiKeyCtx := withAPIKey(context.Background(), string(ctx.Request.Header.Peek(APIKeyHeader)), string(ctx.Path()))
 	err := upgrader.Upgrade(ctx, func(conn *fastws.Conn) {
 		if atomic.LoadInt32(&srv.wsConnCount) >= MaxWebsocketConnections {
 			return
@@ -152,7 +154,7 @@ func (srv *Server) FastWebsocketHandler(ctx *fasthttp.RequestCtx) {
 		}
 
 		reader := bufio.NewReaderSize(bytes.NewReader(ctx.Request.Body()), common.MaxRequestContentLength)
-		srv.ServeCodec(NewCodec(&httpReadWriteNopCloser{reader, ctx.Response.BodyWriter()}, encoder, decoder), OptionMethodInvocation|OptionSubscriptions)
+		srv.serveCodec(apiKeyCtx, NewCodec(&httpReadWriteNopCloser{reader, ctx.Response.BodyWriter()}, encoder, decoder), OptionMethodInvocation|OptionSubscriptions)
 	})
 	if err != nil {
 		logger.Error("FastWebsocketHandler fail to upgrade message", "err", err)
@@ -0,0 +1,287 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+package adminui
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/networks/p2p"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/node"
+)
+
+var logger = log.NewModuleLogger(log.AdminUI)
+
+const (
+	tokenHeader = "X-Admin-Token"
+	callTimeout = 10 * time.Second
+)
+
+var (
+	errInvalidToken     = errors.New("invalid admin token")
+	errActionNotAllowed = errors.New("the method is not allowed in the admin UI")
+)
+
+// statusCalls are the RPC methods whose results are shown in the console, keyed by their sections.
+var statusCalls = []struct {
+	section string
+	method  string
+}{
+	{"nodeInfo", "admin_nodeInfo"},
+	{"peers", "admin_peers"},
+	{"blockNumber", "klay_blockNumber"},
+	{"syncing", "klay_syncing"},
+	{"txpool", "txpool_status"},
+	{"stateMigration", "admin_stateMigrationStatus"},
+}
+
+// actions are the admin APIs which can be called from the console, with their numbers of parameters.
+var actions = map[string]int{
+	"admin_addPeer":                 1,
+	"admin_removePeer":              1,
+	"admin_startStateMigration":     0,
+	"admin_stopStateMigration":      0,
+	"admin_saveTrieNodeCacheToDisk": 0,
+}
+
+// statusResult is the status of the node, whose sections are the results of statusCalls.
+// The sections failed to be fetched are omitted with their errors.
+type statusResult struct {
+	Sections map[string]json.RawMessage `json:"sections"`
+	Errors   map[string]string          `json:"errors,omitempty"`
+}
+
+type actionRequest struct {
+	Method string   `json:"method"`
+	Params []string `json:"params"`
+}
+
+type actionResult struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// AdminUI serves the web-based admin console of the node. The status of the node is fetched and
+// the admin APIs are called through the RPC client attached to the node in process.
+type AdminUI struct {
+	config *AdminUIConfig
+	attach func() (*rpc.Client, error)
+	token  string // guards the admin API calls against the requests from the other web sites
+	page   []byte
+
+	mu       sync.Mutex
+	client   *rpc.Client
+	listener net.Listener
+	server   *http.Server
+}
+
+// NewAdminUI creates an admin UI which attaches to the node with the given function when the
+// first request is served, because the RPC APIs are started after the services.
+func NewAdminUI(ctx *node.ServiceContext, cfg *AdminUIConfig, attach func() (*rpc.Client, error)) (*AdminUI, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+	return newAdminUI(cfg, attach, hex.EncodeToString(token)), nil
+}
+
+func newAdminUI(cfg *AdminUIConfig, attach func() (*rpc.Client, error), token string) *AdminUI {
+	return &AdminUI{
+		config: cfg,
+		attach: attach,
+		token:  token,
+		page:   []byte(strings.Replace(consolePage, "{{TOKEN}}", token, 1)),
+	}
+}
+
+func (a *AdminUI) Protocols() []p2p.Protocol {
+	return []p2p.Protocol{}
+}
+
+func (a *AdminUI) APIs() []rpc.API {
+	return []rpc.API{}
+}
+
+func (a *AdminUI) Start(server p2p.Server) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	endpoint := net.JoinHostPort(a.config.Host, fmt.Sprint(a.config.Port))
+	listener, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to listen on the admin UI endpoint: %v", err)
+	}
+	a.listener = listener
+	a.server = &http.Server{Handler: a.handler(), ReadTimeout: callTimeout, WriteTimeout: 2 * callTimeout}
+	go a.server.Serve(listener)
+
+	if ip := net.ParseIP(a.config.Host); ip == nil || !ip.IsLoopback() {
+		logger.Warn("admin UI is exposed beyond localhost", "host", a.config.Host)
+	}
+	logger.Info("admin UI is started", "url", fmt.Sprintf("http://%s", listener.Addr()), "vhosts", strings.Join(a.config.VirtualHosts, ","))
+	return nil
+}
+
+func (a *AdminUI) Stop() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.server != nil {
+		a.server.Close()
+		a.server, a.listener = nil, nil
+	}
+	if a.client != nil {
+		a.client.Close()
+		a.client = nil
+	}
+	logger.Info("admin UI is stopped")
+	return nil
+}
+
+func (a *AdminUI) Components() []interface{} {
+	return nil
+}
+
+func (a *AdminUI) SetComponents(components []interface{}) {
+	// do nothing
+}
+
+func (a *AdminUI) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", a.servePage)
+	mux.HandleFunc("/api/status", a.serveStatus)
+	mux.HandleFunc("/api/action", a.serveAction)
+	return rpc.NewVHostHandler(a.config.VirtualHosts, mux)
+}
+
+// rpcClient returns the RPC client attached to the node, attaching it on the first call.
+func (a *AdminUI) rpcClient() (*rpc.Client, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.client == nil {
+		client, err := a.attach()
+		if err != nil {
+			return nil, err
+		}
+		a.client = client
+	}
+	return a.client, nil
+}
+
+func (a *AdminUI) servePage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'self' 'unsafe-inline'; frame-ancestors 'none'")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Write(a.page)
+}
+
+func (a *AdminUI) serveStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	client, err := a.rpcClient()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), callTimeout)
+	defer cancel()
+
+	result := &statusResult{Sections: make(map[string]json.RawMessage), Errors: make(map[string]string)}
+	for _, call := range statusCalls {
+		var section json.RawMessage
+		if err := client.CallContext(ctx, &section, call.method); err != nil {
+			result.Errors[call.section] = err.Error()
+			continue
+		}
+		result.Sections[call.section] = section
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (a *AdminUI) serveAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// The custom header cannot be sent by the other web sites without a CORS preflight, which is not allowed
+	if token := r.Header.Get(tokenHeader); subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+		writeJSON(w, http.StatusForbidden, &actionResult{Error: errInvalidToken.Error()})
+		return
+	}
+	var req actionRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, &actionResult{Error: err.Error()})
+		return
+	}
+	numParams, ok := actions[req.Method]
+	if !ok {
+		writeJSON(w, http.StatusForbidden, &actionResult{Error: errActionNotAllowed.Error()})
+		return
+	}
+	if len(req.Params) != numParams {
+		writeJSON(w, http.StatusBadRequest, &actionResult{Error: fmt.Sprintf("%s takes %d parameters", req.Method, numParams)})
+		return
+	}
+	client, err := a.rpcClient()
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, &actionResult{Error: err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), callTimeout)
+	defer cancel()
+
+	params := make([]interface{}, len(req.Params))
+	for i, param := range req.Params {
+		params[i] = param
+	}
+	var result json.RawMessage
+	if err := client.CallContext(ctx, &result, req.Method, params...); err != nil {
+		writeJSON(w, http.StatusOK, &actionResult{Error: err.Error()})
+		return
+	}
+	logger.Info("admin API is called from the admin UI", "method", req.Method, "params", req.Params, "remote", r.RemoteAddr)
+	writeJSON(w, http.StatusOK, &actionResult{Result: result})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Debug("failed to write the admin UI response", "err", err)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+package adminui
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+const testToken = "0123456789abcdef"
+
+type AdminAPIStub struct {
+	added []string
+}
+
+func (api *AdminAPIStub) NodeInfo() map[string]string {
+	return map[string]string{"name": "test"}
+}
+
+func (api *AdminAPIStub) Peers() []interface{} {
+	return []interface{}{}
+}
+
+func (api *AdminAPIStub) AddPeer(url string) (bool, error) {
+	if url == "" {
+		return false, errors.New("invalid kni")
+	}
+	api.added = append(api.added, url)
+	return true, nil
+}
+
+func (api *AdminAPIStub) ExportChain(file string) (bool, error) {
+	return true, nil
+}
+
+type KlayAPIStub struct{}
+
+func (KlayAPIStub) BlockNumber() hexutil.Uint64 {
+	return 7
+}
+
+func newTestAdminUI(t *testing.T) (*AdminUI, *AdminAPIStub) {
+	server := rpc.NewServer()
+	admin := new(AdminAPIStub)
+	assert.NoError(t, server.RegisterName("admin", admin))
+	assert.NoError(t, server.RegisterName("klay", KlayAPIStub{}))
+
+	attach := func() (*rpc.Client, error) { return rpc.DialInProc(server), nil }
+	return newAdminUI(DefaultAdminUIConfig, attach, testToken), admin
+}
+
+func serve(a *AdminUI, method, target, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Host = "localhost:8554"
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	a.handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdminUI_Page(t *testing.T) {
+	a, _ := newTestAdminUI(t)
+
+	rec := serve(a, http.MethodGet, "/", "", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `<meta name="admin-token" content="`+testToken+`">`)
+
+	assert.Equal(t, http.StatusNotFound, serve(a, http.MethodGet, "/unknown", "", nil).Code)
+
+	// The requests to the hostnames not in the virtual hosts are rejected
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Host = "attacker.example:8554"
+	rec = httptest.NewRecorder()
+	a.handler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestAdminUI_Status(t *testing.T) {
+	a, _ := newTestAdminUI(t)
+
+	rec := serve(a, http.MethodGet, "/api/status", "", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var status statusResult
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.JSONEq(t, `{"name":"test"}`, string(status.Sections["nodeInfo"]))
+	assert.JSONEq(t, `[]`, string(status.Sections["peers"]))
+	assert.JSONEq(t, `"0x7"`, string(status.Sections["blockNumber"]))
+
+	// The sections of the unavailable APIs are reported as errors
+	for _, section := range []string{"syncing", "txpool", "stateMigration"} {
+		assert.NotContains(t, status.Sections, section)
+		assert.Contains(t, status.Errors, section)
+	}
+
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(a, http.MethodPost, "/api/status", "", nil).Code)
+}
+
+func TestAdminUI_Action(t *testing.T) {
+	a, admin := newTestAdminUI(t)
+	header := map[string]string{tokenHeader: testToken}
+	action := func(body string, header map[string]string) (int, *actionResult) {
+		rec := serve(a, http.MethodPost, "/api/action", body, header)
+		result := new(actionResult)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), result))
+		return rec.Code, result
+	}
+
+	code, result := action(`{"method":"admin_addPeer","params":["kni://peer"]}`, header)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, &actionResult{Result: json.RawMessage("true")}, result)
+	assert.Equal(t, []string{"kni://peer"}, admin.added)
+
+	// The errors of the admin APIs are returned in the results
+	code, result = action(`{"method":"admin_addPeer","params":[""]}`, header)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "invalid kni", result.Error)
+
+	// The calls without the token, the methods not allowed and the wrong parameters are rejected
+	code, result = action(`{"method":"admin_addPeer","params":["kni://other"]}`, nil)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, errInvalidToken.Error(), result.Error)
+	code, _ = action(`{"method":"admin_addPeer","params":["kni://other"]}`, map[string]string{tokenHeader: "wrong"})
+	assert.Equal(t, http.StatusForbidden, code)
+	code, result = action(`{"method":"admin_exportChain","params":["/tmp/chain"]}`, header)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, errActionNotAllowed.Error(), result.Error)
+	code, _ = action(`{"method":"admin_addPeer","params":[]}`, header)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = action(`invalid`, header)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, []string{"kni://peer"}, admin.added)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(a, http.MethodGet, "/api/action", "", header).Code)
+}
+
+func TestAdminUI_StartStop(t *testing.T) {
+	a, _ := newTestAdminUI(t)
+	a.config = &AdminUIConfig{EnabledAdminUI: true, Host: "127.0.0.1", Port: 0, VirtualHosts: []string{"localhost"}}
+
+	assert.NoError(t, a.Start(nil))
+	res, err := http.Get("http://" + a.listener.Addr().String() + "/api/status")
+	if assert.NoError(t, err) {
+		res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	}
+	assert.NoError(t, a.Stop())
+}
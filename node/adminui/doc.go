@@ -0,0 +1,32 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+/*
+Package adminui implements a service which serves a web-based admin console of the node.
+
+The console shows the status of the node, its peers, the transaction pool, the sync progress and
+the state migration, which are fetched from the RPC APIs of the node in process, so they do not
+need to be exposed over HTTP. It also has buttons calling a few admin APIs, such as adding a peer
+and starting the state migration.
+
+The service listens on localhost by default. The requests to the hostnames not in the virtual hosts
+are rejected to prevent DNS rebinding attacks, and the admin API calls should have the token
+embedded in the console page, so they cannot be sent by the other web sites.
+Source Files
+  - adminui.go : implements the admin UI service and its HTTP handlers
+  - config.go  : includes the admin UI configurations
+  - page.go    : includes the page of the admin console
+*/
+package adminui
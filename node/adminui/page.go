@@ -0,0 +1,163 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+package adminui
+
+// consolePage is the page of the admin console. {{TOKEN}} is replaced with the token of the
+// admin API calls when the service is created.
+const consolePage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="admin-token" content="{{TOKEN}}">
+<title>Klaytn Admin</title>
+<style>
+  body { font-family: sans-serif; margin: 20px; color: #222; }
+  h1 { font-size: 20px; }
+  h2 { font-size: 16px; margin-top: 24px; border-bottom: 1px solid #ccc; }
+  table { border-collapse: collapse; font-size: 13px; }
+  td, th { border: 1px solid #ddd; padding: 4px 8px; text-align: left; vertical-align: top; }
+  pre { margin: 0; white-space: pre-wrap; word-break: break-all; }
+  .error { color: #b00; }
+  button { margin-right: 8px; }
+</style>
+</head>
+<body>
+<h1>Klaytn Admin <small id="updated"></small></h1>
+<div id="errors" class="error"></div>
+
+<h2>Node</h2>
+<table id="node"></table>
+
+<h2>Sync</h2>
+<table id="syncing"></table>
+
+<h2>Transaction Pool</h2>
+<table id="txpool"></table>
+
+<h2>State Migration</h2>
+<table id="stateMigration"></table>
+<p>
+  <button onclick="act('admin_startStateMigration', [])">Start state migration</button>
+  <button onclick="act('admin_stopStateMigration', [])">Stop state migration</button>
+  <button onclick="act('admin_saveTrieNodeCacheToDisk', [])">Save trie node cache</button>
+</p>
+
+<h2>Peers (<span id="peerCount">0</span>)</h2>
+<p>
+  <input id="peerURL" size="80" placeholder="kni://...">
+  <button onclick="act('admin_addPeer', [document.getElementById('peerURL').value])">Add peer</button>
+  <button onclick="act('admin_removePeer', [document.getElementById('peerURL').value])">Remove peer</button>
+</p>
+<table id="peers"></table>
+
+<script>
+var token = document.querySelector('meta[name="admin-token"]').content;
+
+function cell(row, value, header) {
+  var td = document.createElement(header ? 'th' : 'td');
+  if (typeof value === 'object' && value !== null) {
+    var pre = document.createElement('pre');
+    pre.textContent = JSON.stringify(value, null, 2);
+    td.appendChild(pre);
+  } else {
+    td.textContent = String(value);
+  }
+  row.appendChild(td);
+}
+
+function fillTable(id, obj) {
+  var table = document.getElementById(id);
+  table.innerHTML = '';
+  if (typeof obj !== 'object' || obj === null) {
+    obj = {value: obj};
+  }
+  Object.keys(obj).forEach(function(key) {
+    var row = table.insertRow();
+    cell(row, key, true);
+    cell(row, obj[key]);
+  });
+}
+
+function fillPeers(peers) {
+  var table = document.getElementById('peers');
+  table.innerHTML = '';
+  peers = peers || [];
+  document.getElementById('peerCount').textContent = peers.length;
+  var header = table.insertRow();
+  ['ID', 'Name', 'Remote address', 'Inbound', 'Node type', 'Protocols'].forEach(function(h) { cell(header, h, true); });
+  peers.forEach(function(p) {
+    var network = (p.networks && p.networks[0]) || {};
+    var row = table.insertRow();
+    cell(row, p.id.substring(0, 16) + '...');
+    cell(row, p.name);
+    cell(row, network.remoteAddress || '');
+    cell(row, network.inbound || false);
+    cell(row, network.nodeType || '');
+    cell(row, p.protocols);
+  });
+}
+
+function refresh() {
+  fetch('/api/status').then(function(res) { return res.json(); }).then(function(status) {
+    var s = status.sections;
+    if (s.nodeInfo) {
+      fillTable('node', {name: s.nodeInfo.name, kni: s.nodeInfo.kni, listenAddr: s.nodeInfo.listenAddr,
+        blockNumber: s.blockNumber !== undefined ? parseInt(s.blockNumber, 16) : 'unknown'});
+    }
+    if ('syncing' in s) {
+      fillTable('syncing', s.syncing === false ? {syncing: false} : s.syncing);
+    }
+    if (s.txpool) {
+      fillTable('txpool', {pending: parseInt(s.txpool.pending, 16), queued: parseInt(s.txpool.queued, 16)});
+    }
+    if (s.stateMigration) {
+      fillTable('stateMigration', s.stateMigration);
+    }
+    if (s.peers) {
+      fillPeers(s.peers);
+    }
+    var errors = status.errors || {};
+    document.getElementById('errors').textContent = Object.keys(errors).map(function(k) {
+      return k + ': ' + errors[k];
+    }).join('; ');
+    document.getElementById('updated').textContent = 'updated at ' + new Date().toLocaleTimeString();
+  }).catch(function(err) {
+    document.getElementById('errors').textContent = 'failed to fetch the status: ' + err;
+  });
+}
+
+function act(method, params) {
+  if (!confirm('Call ' + method + '(' + params.join(', ') + ')?')) {
+    return;
+  }
+  fetch('/api/action', {
+    method: 'POST',
+    headers: {'Content-Type': 'application/json', 'X-Admin-Token': token},
+    body: JSON.stringify({method: method, params: params})
+  }).then(function(res) { return res.json(); }).then(function(res) {
+    alert(res.error ? method + ' failed: ' + res.error : method + ' returned ' + JSON.stringify(res.result));
+    refresh();
+  }).catch(function(err) {
+    alert(method + ' failed: ' + err);
+  });
+}
+
+refresh();
+setInterval(refresh, 3000);
+</script>
+</body>
+</html>
+`
@@ -22,12 +22,15 @@ package node
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/klaytn/klaytn/api/debug"
 	"github.com/klaytn/klaytn/common/hexutil"
 	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/log"
 	"github.com/klaytn/klaytn/networks/p2p"
 	"github.com/klaytn/klaytn/networks/p2p/discover"
 	"github.com/klaytn/klaytn/networks/rpc"
@@ -277,12 +280,164 @@ func (api *PrivateAdminAPI) StopWS() (bool, error) {
 	return true, nil
 }
 
+// StartPprof starts the pprof HTTP server on the given address and port, or on the ones of the
+// pprof flags if they are not given, so a running node can be profiled without a restart.
+func (api *PrivateAdminAPI) StartPprof(addr *string, port *int) (bool, error) {
+	if err := debug.Handler.StartPProf(addr, port); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// StopPprof stops the running pprof HTTP server.
+func (api *PrivateAdminAPI) StopPprof() (bool, error) {
+	if err := debug.Handler.StopPProf(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetVerbosity sets the global log verbosity ceiling at runtime, from 0 (crit) to 5 (trace).
+func (api *PrivateAdminAPI) SetVerbosity(level int) (bool, error) {
+	if level < int(log.LvlCrit) || level >= int(log.LvlEnd) {
+		return false, fmt.Errorf("invalid verbosity %d, want [%d, %d]", level, log.LvlCrit, log.LvlEnd-1)
+	}
+	if err := debug.Handler.Verbosity(level); err != nil {
+		return false, err
+	}
+	logger.Info("Changed the log verbosity", "level", log.Lvl(level))
+	return true, nil
+}
+
+// SetVmodule sets the per-module log verbosity pattern at runtime such as "blockchain/*=5,consensus/*=4".
+// An empty pattern resets the per-module verbosity. See package log for details on the pattern syntax.
+func (api *PrivateAdminAPI) SetVmodule(pattern string) (bool, error) {
+	if err := debug.Handler.Vmodule(pattern); err != nil {
+		return false, err
+	}
+	logger.Info("Changed the log vmodule", "pattern", pattern)
+	return true, nil
+}
+
 func (api *PrivateAdminAPI) SetMaxSubscriptionPerWSConn(num int32) {
 	logger.Info("Change the max subscription number for a websocket connection",
 		"old", rpc.MaxSubscriptionPerWSConn, "new", num)
 	rpc.MaxSubscriptionPerWSConn = num
 }
 
+// SetExecutionTimeout sets the execution timeout of the namespace for HTTP and WebSocket RPC,
+// where "*" is the other namespaces. A zero timeout such as "0s" removes the timeout.
+func (api *PrivateAdminAPI) SetExecutionTimeout(namespace string, timeout string) (bool, error) {
+	duration, err := time.ParseDuration(timeout)
+	if err != nil {
+		return false, err
+	}
+	logger.Info("Change the execution timeout of RPC", "namespace", namespace, "timeout", duration)
+	rpc.SetExecutionTimeout(namespace, duration)
+	return true, nil
+}
+
+// ExecutionTimeouts returns the execution timeouts of the namespaces for HTTP and WebSocket RPC.
+func (api *PrivateAdminAPI) ExecutionTimeouts() map[string]string {
+	timeouts := make(map[string]string)
+	for namespace, timeout := range rpc.ExecutionTimeouts() {
+		timeouts[namespace] = timeout.String()
+	}
+	return timeouts
+}
+
+// APIKeyUsage returns the usage of the API keys of HTTP and WebSocket RPC since they are loaded.
+func (api *PrivateAdminAPI) APIKeyUsage() ([]rpc.APIKeyUsage, error) {
+	store := rpc.GetAPIKeyStore()
+	if store == nil {
+		return nil, rpc.ErrAPIKeysDisabled
+	}
+	return store.Usage(), nil
+}
+
+// ReloadAPIKeys reloads the API keys of HTTP and WebSocket RPC from the file they are loaded from.
+// The usage of the API keys is reset.
+func (api *PrivateAdminAPI) ReloadAPIKeys() (bool, error) {
+	store := rpc.GetAPIKeyStore()
+	if store == nil {
+		return false, rpc.ErrAPIKeysDisabled
+	}
+	reloaded, err := rpc.LoadAPIKeyStore(store.Path())
+	if err != nil {
+		return false, err
+	}
+	rpc.SetAPIKeyStore(reloaded)
+	logger.Info("Reloaded API keys", "path", store.Path())
+	return true, nil
+}
+
+// MethodLimitUsage returns the usage of the method limits of HTTP and WebSocket RPC since they are loaded.
+func (api *PrivateAdminAPI) MethodLimitUsage() ([]rpc.MethodLimitUsage, error) {
+	limiter := rpc.GetMethodLimiter()
+	if limiter == nil {
+		return nil, rpc.ErrMethodLimitsDisabled
+	}
+	return limiter.Usage(), nil
+}
+
+// ReloadMethodLimits reloads the method limits of HTTP and WebSocket RPC from the file they are loaded from.
+// The usage of the limits is reset, and the requests being executed are not counted by the new limits.
+func (api *PrivateAdminAPI) ReloadMethodLimits() (bool, error) {
+	limiter := rpc.GetMethodLimiter()
+	if limiter == nil {
+		return false, rpc.ErrMethodLimitsDisabled
+	}
+	reloaded, err := rpc.LoadMethodLimiter(limiter.Path())
+	if err != nil {
+		return false, err
+	}
+	rpc.SetMethodLimiter(reloaded)
+	logger.Info("Reloaded method limits", "path", limiter.Path())
+	return true, nil
+}
+
+// NodeKeyRotation is the result of RotateNodeKey.
+type NodeKeyRotation struct {
+	OldID string `json:"oldId"`
+	NewID string `json:"newId"`
+	KNI   string `json:"kni"`
+}
+
+// RotateNodeKey replaces the node key with a newly generated one, which is persisted in the
+// data directory. The connected peers are informed of the new identity to replace it in their
+// static and trusted nodes, and the inbound connections to the old identity are accepted for
+// the grace period in seconds. Note that the consensus keeps using the old key until restart.
+func (api *PrivateAdminAPI) RotateNodeKey(gracePeriod *uint64) (*NodeKeyRotation, error) {
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	if api.node.config.P2P.PrivateKey != nil {
+		return nil, errors.New("the node key given by the flags cannot be rotated")
+	}
+	grace := p2p.DefaultIdentityGracePeriod
+	if gracePeriod != nil {
+		grace = time.Duration(*gracePeriod) * time.Second
+	}
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	// The new key is persisted first, so the node is not restarted with the old one
+	if keyfile := api.node.config.ResolvePath(datadirPrivateKey); keyfile != "" {
+		if err := crypto.SaveECDSA(keyfile, key); err != nil {
+			return nil, fmt.Errorf("failed to persist the node key: %v", err)
+		}
+	}
+	oldID := server.NodeInfo().ID
+	if err := server.RotateKey(key, grace); err != nil {
+		return nil, err
+	}
+	info := server.NodeInfo()
+	return &NodeKeyRotation{OldID: oldID, NewID: info.ID, KNI: info.Enode}, nil
+}
+
 // PublicAdminAPI is the collection of administrative API methods exposed over
 // both secure and unsecure RPC channels.
 type PublicAdminAPI struct {
@@ -22,14 +22,23 @@ package node
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"testing"
+	"time"
 
+	"github.com/klaytn/klaytn/api/debug"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/networks/p2p"
+	"github.com/klaytn/klaytn/networks/p2p/discover"
 	"github.com/klaytn/klaytn/networks/rpc"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // This test uses the admin_startRPC and admin_startWS APIs,
@@ -287,3 +296,154 @@ func not(ok bool) string {
 	}
 	return "not "
 }
+
+// TestRotateNodeKey tests if the rotated node key is persisted in the data directory.
+func TestRotateNodeKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "klaytn-rotate-node-key")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	stack, err := New(&Config{DataDir: dir, P2P: p2p.Config{NoDiscovery: true, ListenAddr: "127.0.0.1:0"}})
+	assert.NoError(t, err)
+	assert.NoError(t, stack.Start())
+	defer stack.Stop()
+
+	oldKey := stack.NodeKey()
+	grace := uint64(60)
+	result, err := (&PrivateAdminAPI{stack}).RotateNodeKey(&grace)
+	assert.NoError(t, err)
+	assert.Equal(t, discover.PubkeyID(&oldKey.PublicKey).String(), result.OldID)
+	assert.NotEqual(t, result.OldID, result.NewID)
+	assert.Equal(t, stack.Server().NodeInfo().Enode, result.KNI)
+	assert.Equal(t, result.NewID, discover.PubkeyID(&stack.NodeKey().PublicKey).String())
+
+	// The node key given by the flags cannot be persisted
+	fixed, err := New(&Config{P2P: p2p.Config{NoDiscovery: true, ListenAddr: "127.0.0.1:0", PrivateKey: testNodeKey}})
+	assert.NoError(t, err)
+	assert.NoError(t, fixed.Start())
+	defer fixed.Stop()
+	_, err = (&PrivateAdminAPI{fixed}).RotateNodeKey(nil)
+	assert.Error(t, err)
+}
+
+// TestStartPprof tests the admin_startPprof and admin_stopPprof APIs, checking whether
+// the pprof server is started and stopped once even if they are called again.
+func TestStartPprof(t *testing.T) {
+	type call struct {
+		start   bool // StartPprof if true, StopPprof otherwise
+		wantErr bool
+	}
+	tests := []struct {
+		name          string
+		calls         []call
+		wantReachable bool
+	}{
+		{"start", []call{{true, false}}, true},
+		{"start then stop", []call{{true, false}, {false, false}}, false},
+		{"start twice", []call{{true, false}, {true, true}}, true},
+		{"stop twice", []call{{true, false}, {false, false}, {false, true}}, false},
+		{"stop without start", []call{{false, true}}, false},
+		{"start again after stop", []call{{true, false}, {false, false}, {true, false}}, true},
+	}
+
+	api := &PrivateAdminAPI{}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Listen on a random port to pick a free one.
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			port := listener.Addr().(*net.TCPAddr).Port
+			listener.Close()
+			defer stopPprof(t)
+
+			for _, c := range test.calls {
+				if c.start {
+					ok, err := api.StartPprof(sp("127.0.0.1"), ip(port))
+					assert.Equal(t, c.wantErr, err != nil, err)
+					assert.Equal(t, !c.wantErr, ok)
+				} else {
+					ok, err := api.StopPprof()
+					assert.Equal(t, c.wantErr, err != nil, err)
+					assert.Equal(t, !c.wantErr, ok)
+					waitPprofStopped(t)
+				}
+			}
+
+			// The server is started in background, so it may not be listening right away.
+			url := fmt.Sprintf("http://127.0.0.1:%d", port)
+			reachable := checkReachable(url)
+			for i := 0; i < 100 && !reachable && test.wantReachable; i++ {
+				time.Sleep(10 * time.Millisecond)
+				reachable = checkReachable(url)
+			}
+			assert.Equal(t, test.wantReachable, reachable)
+			assert.Equal(t, test.wantReachable, debug.Handler.IsPProfRunning())
+		})
+	}
+}
+
+// stopPprof stops the pprof server if it is running, and waits until it is stopped.
+func stopPprof(t *testing.T) {
+	if debug.Handler.IsPProfRunning() {
+		assert.NoError(t, debug.Handler.StopPProf())
+	}
+	waitPprofStopped(t)
+}
+
+// waitPprofStopped waits until the pprof server is stopped, which is done in background.
+func waitPprofStopped(t *testing.T) {
+	for i := 0; i < 100 && debug.Handler.IsPProfRunning(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.False(t, debug.Handler.IsPProfRunning(), "pprof server is not stopped")
+}
+
+// TestSetVerbosity tests if admin_setVerbosity accepts the log levels from crit to trace only.
+func TestSetVerbosity(t *testing.T) {
+	tests := []struct {
+		level   int
+		wantErr bool
+	}{
+		{int(log.LvlCrit), false},
+		{int(log.LvlInfo), false},
+		{int(log.LvlTrace), false},
+		{int(log.LvlCrit) - 1, true},
+		{int(log.LvlEnd), true},
+		{100, true},
+	}
+
+	api := &PrivateAdminAPI{}
+	defer api.SetVerbosity(int(log.LvlInfo))
+	for _, test := range tests {
+		ok, err := api.SetVerbosity(test.level)
+		assert.Equal(t, test.wantErr, err != nil, "level %d: %v", test.level, err)
+		assert.Equal(t, !test.wantErr, ok, "level %d", test.level)
+	}
+}
+
+// TestSetVmodule tests if admin_setVmodule accepts the valid patterns only.
+func TestSetVmodule(t *testing.T) {
+	tests := []struct {
+		pattern string
+		wantErr bool
+	}{
+		{"", false},
+		{"node/*=5", false},
+		{"blockchain/*=5,consensus/*=4", false},
+		{"api.go=4,", false},
+		{"node=0", false},
+		{"node", true},
+		{"=5", true},
+		{"node/*=", true},
+		{"node/*=trace", true},
+		{"node=1=2", true},
+	}
+
+	api := &PrivateAdminAPI{}
+	defer api.SetVmodule("")
+	for _, test := range tests {
+		ok, err := api.SetVmodule(test.pattern)
+		assert.Equal(t, test.wantErr, err != nil, "pattern %q: %v", test.pattern, err)
+		assert.Equal(t, !test.wantErr, ok, "pattern %q", test.pattern)
+	}
+}
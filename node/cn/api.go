@@ -27,6 +27,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"strings"
 	"time"
@@ -36,10 +37,13 @@ import (
 	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/datasync/chainarchive"
+	"github.com/klaytn/klaytn/datasync/chainexport"
 	"github.com/klaytn/klaytn/kerrors"
 	"github.com/klaytn/klaytn/networks/rpc"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
 	"github.com/klaytn/klaytn/storage/statedb"
 	"github.com/klaytn/klaytn/work"
 )
@@ -60,6 +64,161 @@ func (api *PublicKlayAPI) Rewardbase() (common.Address, error) {
 	return api.cn.Rewardbase()
 }
 
+// SupplyStatsResult is the amount of KLAY minted, collected as transaction fees and burnt
+// in a range of blocks.
+type SupplyStatsResult struct {
+	FromBlock hexutil.Uint64 `json:"fromBlock"`
+	ToBlock   hexutil.Uint64 `json:"toBlock"`
+	Minted    *hexutil.Big   `json:"minted"`
+	TxFee     *hexutil.Big   `json:"txFee"`
+	BurntFee  *hexutil.Big   `json:"burntFee"`
+}
+
+// GetSupplyStats returns the amount of KLAY minted, collected as transaction fees and burnt
+// in the blocks from the given range, both inclusive. The cumulative amounts are returned
+// if the range starts from the genesis block.
+func (api *PublicKlayAPI) GetSupplyStats(from, to rpc.BlockNumber) (*SupplyStatsResult, error) {
+	if api.cn.supplyTracker == nil {
+		return nil, errSupplyNotTracked
+	}
+
+	current := api.cn.BlockChain().CurrentBlock().NumberU64()
+	resolve := func(number rpc.BlockNumber) uint64 {
+		if number == rpc.LatestBlockNumber || number == rpc.PendingBlockNumber {
+			return current
+		}
+		return uint64(number.Int64())
+	}
+
+	stats, err := api.cn.supplyTracker.Stats(resolve(from), resolve(to))
+	if err != nil {
+		return nil, err
+	}
+	return &SupplyStatsResult{
+		FromBlock: hexutil.Uint64(stats.FromBlock),
+		ToBlock:   hexutil.Uint64(stats.ToBlock),
+		Minted:    (*hexutil.Big)(stats.Minted),
+		TxFee:     (*hexutil.Big)(stats.TxFee),
+		BurntFee:  (*hexutil.Big)(stats.BurntFee),
+	}, nil
+}
+
+// GetFeePayerStats returns how many transactions the given fee payer paid for, their gas used
+// and the fee paid by the fee payer in the blocks from the given range, both inclusive, in total
+// and per sender and per contract. The blocks not covered by the fee payer index, enabled by
+// --feepayerindexing, are scanned up to a limited number of blocks.
+func (api *PublicKlayAPI) GetFeePayerStats(feePayer common.Address, from, to rpc.BlockNumber) (*FeePayerStatsResult, error) {
+	fromNum, toNum, err := api.cn.resolveBlockRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return api.cn.feePayerStats(feePayer, fromNum, toNum)
+}
+
+// GetBalanceHistory returns the balances of the account at every step blocks from the given range,
+// both inclusive. The balances are read from the balance history index, enabled by
+// --balancehistoryindexing, or from the states of the blocks not covered by the index.
+func (api *PublicKlayAPI) GetBalanceHistory(address common.Address, from, to rpc.BlockNumber, step hexutil.Uint64) ([]*BalanceHistoryEntry, error) {
+	fromNum, toNum, err := api.cn.resolveBlockRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return api.cn.balanceHistory(address, fromNum, toNum, uint64(step))
+}
+
+// resolveBlockRange resolves the latest and the pending block numbers of the range to the current block.
+func (s *CN) resolveBlockRange(from, to rpc.BlockNumber) (uint64, uint64, error) {
+	current := s.blockchain.CurrentBlock().NumberU64()
+	resolve := func(number rpc.BlockNumber) uint64 {
+		if number == rpc.LatestBlockNumber || number == rpc.PendingBlockNumber {
+			return current
+		}
+		return uint64(number.Int64())
+	}
+	if resolve(to) > current {
+		return 0, 0, fmt.Errorf("block %d is not yet imported", resolve(to))
+	}
+	return resolve(from), resolve(to), nil
+}
+
+// GetTotalSupply returns the total and the circulating supply of KLAY at the given block
+// with their components. The supply is computed from the genesis allocation, the minted KLAY,
+// the burnt fees and the balances of the burn and the treasury addresses.
+func (api *PublicKlayAPI) GetTotalSupply(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*TotalSupplyResult, error) {
+	if blockNr, ok := blockNrOrHash.Number(); ok && blockNr == rpc.PendingBlockNumber {
+		return nil, kerrors.ErrPendingBlockNotSupported
+	}
+	stateDB, header, err := api.cn.APIBackend.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	return api.cn.totalSupply(header, stateDB)
+}
+
+// NonceGapResult is a range of the nonces missing in the pool, both inclusive.
+type NonceGapResult struct {
+	From hexutil.Uint64 `json:"from"`
+	To   hexutil.Uint64 `json:"to"`
+}
+
+// StuckTxResult is the transaction blocking the other transactions of an account.
+type StuckTxResult struct {
+	Hash   common.Hash    `json:"hash"`
+	Nonce  hexutil.Uint64 `json:"nonce"`
+	Reason string         `json:"reason"`
+}
+
+// AccountQueueStatusResult is the status of the transactions of an account in the pool.
+type AccountQueueStatusResult struct {
+	Nonce              hexutil.Uint64   `json:"nonce"`
+	PendingNonce       hexutil.Uint64   `json:"pendingNonce"`
+	Pending            hexutil.Uint     `json:"pending"`
+	Queued             hexutil.Uint     `json:"queued"`
+	LowestPendingNonce *hexutil.Uint64  `json:"lowestPendingNonce"`
+	LowestQueuedNonce  *hexutil.Uint64  `json:"lowestQueuedNonce"`
+	Gaps               []NonceGapResult `json:"gaps"`
+	StuckTx            *StuckTxResult   `json:"stuckTx"`
+	LastActivity       *time.Time       `json:"lastActivity"`
+}
+
+// GetAccountQueueStatus returns the status of the transactions of the given account in the
+// transaction pool: the nonce in the state, the lowest pending and queued nonces, the missing
+// nonces, and the oldest transaction which cannot be executed with its reason.
+func (api *PublicKlayAPI) GetAccountQueueStatus(address common.Address) *AccountQueueStatusResult {
+	status := api.cn.TxPool().AccountQueueStatus(address)
+
+	result := &AccountQueueStatusResult{
+		Nonce:              hexutil.Uint64(status.Nonce),
+		PendingNonce:       hexutil.Uint64(status.PendingNonce),
+		Pending:            hexutil.Uint(status.Pending),
+		Queued:             hexutil.Uint(status.Queued),
+		LowestPendingNonce: (*hexutil.Uint64)(status.LowestPending),
+		LowestQueuedNonce:  (*hexutil.Uint64)(status.LowestQueued),
+		Gaps:               make([]NonceGapResult, 0, len(status.Gaps)),
+	}
+	for _, gap := range status.Gaps {
+		result.Gaps = append(result.Gaps, NonceGapResult{From: hexutil.Uint64(gap.From), To: hexutil.Uint64(gap.To)})
+	}
+	if status.Stuck != nil {
+		result.StuckTx = &StuckTxResult{
+			Hash:   status.Stuck.Tx.Hash(),
+			Nonce:  hexutil.Uint64(status.Stuck.Tx.Nonce()),
+			Reason: status.Stuck.Reason,
+		}
+	}
+	if !status.LastActivity.IsZero() {
+		result.LastActivity = &status.LastActivity
+	}
+	return result
+}
+
+// GetAvailableBlockRanges returns the ranges of the blocks whose headers, bodies and receipts
+// are kept by the node. A PN keeping only the recent bodies and receipts serves the headers
+// of the entire chain, but the bodies and the receipts of the recent blocks only.
+func (api *PublicKlayAPI) GetAvailableBlockRanges() blockchain.BlockStoreStatus {
+	return api.cn.BlockChain().BlockStoreStatus()
+}
+
 // PrivateAdminAPI is the collection of CN full node-related APIs
 // exposed over the private admin endpoint.
 type PrivateAdminAPI struct {
@@ -100,6 +259,73 @@ func (api *PrivateAdminAPI) ExportChain(file string) (bool, error) {
 	return true, nil
 }
 
+// ExportChainData exports blocks, transactions, receipts and logs of the given block range
+// into CSV or Parquet files per table under the given directory. The files are written
+// in chunks of chunkSize blocks and the chunks already exported are skipped,
+// so an interrupted export can be resumed by calling it again with the same range.
+func (api *PrivateAdminAPI) ExportChainData(dir string, format string, from, to rpc.BlockNumber, chunkSize *uint64) (bool, error) {
+	config := &chainexport.Config{
+		Dir:       dir,
+		Format:    chainexport.Format(strings.ToLower(format)),
+		ChunkSize: chainexport.DefaultChunkSize,
+	}
+	if chunkSize != nil {
+		config.ChunkSize = *chunkSize
+	}
+
+	current := api.cn.BlockChain().CurrentBlock().NumberU64()
+	resolve := func(number rpc.BlockNumber) uint64 {
+		if number == rpc.LatestBlockNumber || number == rpc.PendingBlockNumber {
+			return current
+		}
+		return uint64(number.Int64())
+	}
+
+	exporter, err := chainexport.NewExporter(api.cn.BlockChain(), config)
+	if err != nil {
+		return false, err
+	}
+	if err := exporter.Export(resolve(from), resolve(to)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ExportHistory exports the epochs of the chain containing the blocks from first to last into
+// the immutable archive files under the given directory, and returns the names of the files.
+// Only the complete epochs of epochSize blocks are exported, and the epochs already exported
+// are skipped. The directory can be served over HTTP or uploaded to an object storage, from
+// which fresh nodes import the chain history by ImportHistory.
+func (api *PrivateAdminAPI) ExportHistory(dir string, first, last rpc.BlockNumber, epochSize *uint64) ([]string, error) {
+	config := &chainarchive.Config{Dir: dir, EpochSize: chainarchive.DefaultEpochSize}
+	if epochSize != nil {
+		config.EpochSize = *epochSize
+	}
+
+	current := api.cn.BlockChain().CurrentBlock().NumberU64()
+	resolve := func(number rpc.BlockNumber) uint64 {
+		if number == rpc.LatestBlockNumber || number == rpc.PendingBlockNumber {
+			return current
+		}
+		return uint64(number.Int64())
+	}
+
+	exporter, err := chainarchive.NewExporter(api.cn.BlockChain(), config)
+	if err != nil {
+		return nil, err
+	}
+	return exporter.Export(resolve(first), resolve(last))
+}
+
+// ImportHistory imports the chain history from the archive files listed in the index of the
+// given location, which is a local directory or the http(s):// or s3:// URL of a directory.
+func (api *PrivateAdminAPI) ImportHistory(location string) (bool, error) {
+	if err := chainarchive.Import(api.cn.BlockChain(), location, nil, nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func hasAllBlocks(chain work.BlockChain, bs []*types.Block) bool {
 	for _, b := range bs {
 		if !chain.HasBlock(b.Hash(), b.NumberU64()) {
@@ -138,36 +364,28 @@ func (api *PrivateAdminAPI) ImportChainFromString(blockRlp string) (bool, error)
 }
 
 func (api *PrivateAdminAPI) importChain(stream *rlp.Stream) (bool, error) {
-	blocks, index := make([]*types.Block, 0, 2500), 0
-	for batch := 0; ; batch++ {
-		// Load a batch of blocks from the input file
-		for len(blocks) < cap(blocks) {
-			block := new(types.Block)
-			if err := stream.Decode(block); err == io.EOF {
-				break
-			} else if err != nil {
-				return false, fmt.Errorf("block %d: failed to parse: %v", index, err)
-			}
-			blocks = append(blocks, block)
-			index++
-		}
-		if len(blocks) == 0 {
-			break
-		}
-
-		if hasAllBlocks(api.cn.BlockChain(), blocks) {
-			blocks = blocks[:0]
-			continue
-		}
-		// Import the batch and reset the buffer
-		if _, err := api.cn.BlockChain().InsertChain(blocks); err != nil {
-			return false, fmt.Errorf("batch %d: failed to insert: %v", batch, err)
-		}
-		blocks = blocks[:0]
+	if err := importBlocks(api.cn.BlockChain(), stream, nil, nil); err != nil {
+		return false, err
 	}
 	return true, nil
 }
 
+// ImportChainAsync starts importing a blockchain from a local file in background, and returns
+// the ID of the import job whose progress is given by ImportStatus.
+func (api *PrivateAdminAPI) ImportChainAsync(file string) (uint64, error) {
+	return api.cn.chainImporter.start(file)
+}
+
+// ImportStatus returns the progress of the running or a finished import job.
+func (api *PrivateAdminAPI) ImportStatus(id uint64) (ImportChainStatus, error) {
+	return api.cn.chainImporter.getStatus(id)
+}
+
+// ImportAbort aborts the running import job after the batch of blocks being inserted.
+func (api *PrivateAdminAPI) ImportAbort(id uint64) error {
+	return api.cn.chainImporter.abort(id)
+}
+
 // StartStateMigration starts state migration.
 func (api *PrivateAdminAPI) StartStateMigration() error {
 	return api.cn.blockchain.PrepareStateMigration()
@@ -198,10 +416,239 @@ func (api *PrivateAdminAPI) StateMigrationStatus() map[string]interface{} {
 	}
 }
 
+// StartIndexRebuild starts rebuilding the tx lookup entries, the senderTxHash mappings and the bloombits
+// of the given block range from the stored blocks and receipts in background.
+func (api *PrivateAdminAPI) StartIndexRebuild(from, to rpc.BlockNumber) error {
+	current := api.cn.BlockChain().CurrentBlock().NumberU64()
+	resolve := func(number rpc.BlockNumber) uint64 {
+		if number == rpc.LatestBlockNumber || number == rpc.PendingBlockNumber {
+			return current
+		}
+		return uint64(number.Int64())
+	}
+	if resolve(to) > current {
+		return fmt.Errorf("block %d is beyond the current block %d", resolve(to), current)
+	}
+	return api.cn.indexRebuilder.start(resolve(from), resolve(to))
+}
+
+// StopIndexRebuild stops the running index rebuild.
+func (api *PrivateAdminAPI) StopIndexRebuild() error {
+	return api.cn.indexRebuilder.stop()
+}
+
+// IndexRebuildStatus returns the progress of the running or the last index rebuild.
+func (api *PrivateAdminAPI) IndexRebuildStatus() IndexRebuildStatus {
+	return api.cn.indexRebuilder.getStatus()
+}
+
 func (api *PrivateAdminAPI) SaveTrieNodeCacheToDisk() error {
 	return api.cn.BlockChain().SaveTrieNodeCacheToDisk()
 }
 
+// DumpTrieNodeCacheToDisk saves the trie node cache to disk, and writes its portable dump to the
+// given path in background. The dump can be loaded by another node with LoadTrieNodeCache.
+func (api *PrivateAdminAPI) DumpTrieNodeCacheToDisk(dumpPath string) error {
+	return api.cn.BlockChain().DumpTrieNodeCacheToDisk(dumpPath)
+}
+
+// LoadTrieNodeCache replaces the trie node cache with the one in the portable dump at the given
+// path or HTTP(S) URL in background, so that a new node can start with the warm cache of a sibling.
+// The dump should be taken from a cache of the same size.
+func (api *PrivateAdminAPI) LoadTrieNodeCache(source string) error {
+	r, err := openTrieNodeCacheDump(source)
+	if err != nil {
+		return err
+	}
+	return api.cn.BlockChain().LoadTrieNodeCache(r)
+}
+
+func openTrieNodeCacheDump(source string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return os.Open(source)
+	}
+	resp, err := http.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// SetTxLookupLimit changes the number of recent blocks whose transactions are indexed.
+// The index is extended or shrunk in background, and 0 indexes the entire chain.
+func (api *PrivateAdminAPI) SetTxLookupLimit(limit uint64) bool {
+	api.cn.BlockChain().SetTxLookupLimit(limit)
+	return true
+}
+
+// TxIndexStatus returns the status of the transaction lookup index.
+func (api *PrivateAdminAPI) TxIndexStatus() map[string]interface{} {
+	status := api.cn.BlockChain().TxIndexStatus()
+	return map[string]interface{}{
+		"limit":    status.Limit,
+		"tail":     status.Tail,
+		"head":     status.Head,
+		"indexing": status.Indexing,
+	}
+}
+
+// BloomIndexStatus returns the status of the bloombits index, which klay_getLogs uses to scan the
+// section-level bloom filters of the indexed blocks instead of reading their headers one by one.
+// The blocks after the last indexed one are scanned block by block.
+func (api *PrivateAdminAPI) BloomIndexStatus() map[string]interface{} {
+	sections, lastIndexed, sectionHead := api.cn.bloomIndexer.Sections()
+	status := map[string]interface{}{
+		"sectionSize": params.BloomBitsBlocks,
+		"sections":    sections,
+		"head":        api.cn.BlockChain().CurrentBlock().NumberU64(),
+	}
+	if sections > 0 {
+		status["lastIndexed"] = lastIndexed
+		status["lastSectionHead"] = sectionHead
+	}
+	return status
+}
+
+// ChainDataCompressionStats returns the number of the block bodies and the receipts of the canonical
+// blocks in the given range stored with each compression type (--db.chaindata.compression), and their
+// stored and original sizes in bytes. The entries written before enabling the compression are counted
+// as "none" until they are rewritten by --db.chaindata.recompress.
+func (api *PrivateAdminAPI) ChainDataCompressionStats(ctx context.Context, from, to rpc.BlockNumber) (map[string]interface{}, error) {
+	current := api.cn.BlockChain().CurrentBlock().NumberU64()
+	resolve := func(number rpc.BlockNumber) uint64 {
+		if number == rpc.LatestBlockNumber || number == rpc.PendingBlockNumber {
+			return current
+		}
+		return uint64(number.Int64())
+	}
+	if resolve(from) > resolve(to) {
+		return nil, fmt.Errorf("invalid block range: from %d > to %d", resolve(from), resolve(to))
+	}
+	stats, err := api.cn.ChainDB().ChainDataCompressionStats(resolve(from), resolve(to), ctx.Done())
+	if err != nil {
+		return nil, err
+	}
+	entries := make(map[string]uint64)
+	storedSize := make(map[string]uint64)
+	rawSize := make(map[string]uint64)
+	for ct := range stats.Entries {
+		name := database.ChainDataCompressionType(ct).String()
+		entries[name] = stats.Entries[ct]
+		storedSize[name] = stats.StoredSize[ct]
+		rawSize[name] = stats.RawSize[ct]
+	}
+	return map[string]interface{}{
+		"compression": api.cn.ChainDB().GetDBConfig().ChainDataCompression.String(),
+		"entries":     entries,
+		"storedSize":  storedSize,
+		"rawSize":     rawSize,
+	}, nil
+}
+
+// SetBodyRetention changes the number of recent blocks whose bodies and receipts are kept by a PN.
+// The older ones are pruned in background, and 0 stops pruning.
+func (api *PrivateAdminAPI) SetBodyRetention(retention uint64) (bool, error) {
+	if retention != 0 && api.cn.protocolManager.NodeType() != common.PROXYNODE {
+		return false, errBodyRetentionNotPN
+	}
+	if err := api.cn.BlockChain().SetBodyRetention(retention); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetTxOrderingPolicy changes the policy ordering the transactions in the blocks built afterwards.
+// The available policies are "price", "price-time", "fifo" and "round-robin".
+func (api *PrivateAdminAPI) SetTxOrderingPolicy(policy string) (bool, error) {
+	if err := api.cn.Miner().SetTxOrderingPolicy(policy); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// TxOrderingPolicy returns the policy ordering the transactions in a block.
+func (api *PrivateAdminAPI) TxOrderingPolicy() string {
+	return api.cn.Miner().TxOrderingPolicy()
+}
+
+// SetTxBudget changes the partitioning of the block gas budget by tx class for the blocks built afterwards.
+// The shares of the classes are given in percent, e.g. {"blockGas": 100000000, "classes": {"user": {"minShare": 30, "maxShare": 100}}}.
+func (api *PrivateAdminAPI) SetTxBudget(config work.TxBudgetConfig) (bool, error) {
+	if err := api.cn.Miner().SetTxBudget(config); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// TxBudget returns the partitioning of the block gas budget by tx class.
+func (api *PrivateAdminAPI) TxBudget() work.TxBudgetConfig {
+	return api.cn.Miner().TxBudget()
+}
+
+// SetTargetGasLimit changes the target gas limit of the blocks built afterwards. The gas limit of the blocks
+// approaches the target by less than 1/1024 of the limit per block, and the blocks are not limited if it is 0.
+func (api *PrivateAdminAPI) SetTargetGasLimit(target uint64) bool {
+	api.cn.Miner().SetTargetGasLimit(target)
+	return true
+}
+
+// GasLimitStatus is the target gas limit and the effective gas limit of the blocks built by the node.
+type GasLimitStatus struct {
+	Target   uint64 `json:"target"`   // target gas limit, 0 if the blocks are not limited
+	GasLimit uint64 `json:"gasLimit"` // gas limit of the last block built, 0 if not limited
+}
+
+// GasLimit returns the target gas limit and the effective gas limit of the blocks built by the node.
+func (api *PrivateAdminAPI) GasLimit() GasLimitStatus {
+	miner := api.cn.Miner()
+	return GasLimitStatus{Target: miner.TargetGasLimit(), GasLimit: miner.GasLimit()}
+}
+
+// AddToDenylist puts the accounts on the denylist. The transactions sent by, fee-delegated by or sent to
+// the accounts are refused by the txpool and are not included in the blocks proposed by the node.
+func (api *PrivateAdminAPI) AddToDenylist(addrs []common.Address) (bool, error) {
+	if err := api.cn.TxPool().Denylist().Add(addrs...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RemoveFromDenylist takes the accounts off the denylist.
+func (api *PrivateAdminAPI) RemoveFromDenylist(addrs []common.Address) (bool, error) {
+	if err := api.cn.TxPool().Denylist().Remove(addrs...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Denylist returns the accounts on the denylist.
+func (api *PrivateAdminAPI) Denylist() []common.Address {
+	return api.cn.TxPool().Denylist().List()
+}
+
+// RegisterABI registers the ABI of the contract, so that klay_getLogs, the log filters and the log
+// subscriptions return the logs of the contract with their decoded events.
+func (api *PrivateAdminAPI) RegisterABI(address common.Address, abi string) (bool, error) {
+	if err := api.cn.abiRegistry.Register(address, abi); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UnregisterABI removes the ABI of the contract. It returns false if the ABI is not registered.
+func (api *PrivateAdminAPI) UnregisterABI(address common.Address) (bool, error) {
+	return api.cn.abiRegistry.Unregister(address)
+}
+
+// RegisteredABIs returns the addresses of the contracts whose ABIs are registered.
+func (api *PrivateAdminAPI) RegisteredABIs() []common.Address {
+	return api.cn.abiRegistry.Addresses()
+}
+
 // PublicDebugAPI is the collection of Klaytn full node APIs exposed
 // over the public debugging endpoint.
 type PublicDebugAPI struct {
@@ -215,6 +662,8 @@ func NewPublicDebugAPI(cn *CN) *PublicDebugAPI {
 }
 
 // DumpBlock retrieves the entire state of the database at a given block.
+// The whole state is built in memory, so a large state should be retrieved in pages by AccountRange
+// of the private debug API, which can also omit the code and the storage of the accounts.
 func (api *PublicDebugAPI) DumpBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (state.Dump, error) {
 	if *blockNrOrHash.BlockNumber == rpc.PendingBlockNumber {
 		return state.Dump{}, kerrors.ErrPendingBlockNotSupported
@@ -263,7 +712,7 @@ func (api *PublicDebugAPI) DumpStateTrie(ctx context.Context, blockNrOrHash rpc.
 	}
 
 	db := state.NewDatabaseWithExistingCache(api.cn.chainDB, api.cn.blockchain.StateCache().TrieDB().TrieNodeCache())
-	stateDB, err := state.New(block.Root(), db)
+	stateDB, err := state.New(block.Root(), db, nil)
 	if err != nil {
 		return DumpStateTrieResult{}, err
 	}
@@ -281,6 +730,19 @@ func (api *PublicDebugAPI) DumpStateTrie(ctx context.Context, blockNrOrHash rpc.
 	return result, nil
 }
 
+// GetStorageTrieOwners returns the contract accounts whose storage tries have had the given root.
+// It requires the storage owner indexing enabled, and the roots committed before enabling it are not indexed.
+func (api *PublicDebugAPI) GetStorageTrieOwners(root common.Hash) ([]common.Address, error) {
+	if !api.cn.config.StorageOwnerIndexing {
+		return nil, errStorageOwnerIndexingDisabled
+	}
+	owners := api.cn.chainDB.ReadStorageTrieOwners(root)
+	if owners == nil {
+		owners = []common.Address{}
+	}
+	return owners, nil
+}
+
 // StartWarmUp retrieves all state/storage tries of the latest committed state root and caches the tries.
 func (api *PublicDebugAPI) StartWarmUp() error {
 	return api.cn.blockchain.StartWarmUp()
@@ -329,6 +791,131 @@ func (api *PrivateDebugAPI) GetBadBlocks(ctx context.Context) ([]blockchain.BadB
 	return api.cn.BlockChain().BadBlocks()
 }
 
+// defaultSlowBlockImports is the number of the slowest block imports returned by default.
+const defaultSlowBlockImports = 10
+
+// GetSlowBlockImports returns the per-stage timings of the block imports taking the longest
+// among the recently imported blocks, slowest first.
+func (api *PrivateDebugAPI) GetSlowBlockImports(count *int) []*blockchain.BlockImportStats {
+	n := defaultSlowBlockImports
+	if count != nil {
+		n = *count
+	}
+	return api.cn.BlockChain().SlowestBlockImports(n)
+}
+
+// SetHead rewinds the canonical chain to the given block, deleting the headers, the bodies, the receipts
+// and the tx lookup entries of the blocks after it. If the state of the block is not available, the chain
+// is rewound further to the nearest block with its state. It returns the number of the new head block.
+// The rewind is refused while the node is mining or importing a chain.
+func (api *PrivateDebugAPI) SetHead(number hexutil.Uint64) (hexutil.Uint64, error) {
+	bc := api.cn.blockchain
+	if current := bc.CurrentBlock().NumberU64(); uint64(number) >= current {
+		return 0, fmt.Errorf("block %d is not below the current block %d", number, current)
+	}
+	if bc.GetHeaderByNumber(uint64(number)) == nil {
+		return 0, fmt.Errorf("block %d not found", number)
+	}
+	if api.cn.IsMining() {
+		return 0, errors.New("the chain cannot be rewound while mining")
+	}
+	if api.cn.chainImporter.running() {
+		return 0, errImportRunning
+	}
+	if err := bc.SetHead(uint64(number)); err != nil {
+		return 0, err
+	}
+	head := bc.CurrentBlock()
+	logger.Warn("Rewound the chain by debug_setHead", "target", number, "head", head.NumberU64(), "hash", head.Hash())
+	return hexutil.Uint64(head.NumberU64()), nil
+}
+
+// chaindbTargets returns the entry types of the given chain database and its shard, or of all the open
+// databases if the database is not given. The shard is -1 if it is not given.
+func (api *PrivateDebugAPI) chaindbTargets(db *string, shard *int) ([]database.DBEntryType, int, error) {
+	dbm := api.cn.ChainDB()
+	index := -1
+	if shard != nil {
+		if db == nil {
+			return nil, 0, errors.New("the database of the shard is not given")
+		}
+		if *shard < 0 {
+			return nil, 0, fmt.Errorf("invalid shard %d", *shard)
+		}
+		index = *shard
+	}
+	if db != nil {
+		et, err := database.ParseDBEntryType(*db)
+		if err != nil {
+			return nil, 0, err
+		}
+		return []database.DBEntryType{et}, index, nil
+	}
+	// Every entry type shares a database in a single database
+	if dbm.IsSingle() || dbm.GetDBConfig().DBType == database.MemoryDB {
+		return []database.DBEntryType{database.MiscDB}, index, nil
+	}
+	var ets []database.DBEntryType
+	for _, et := range database.DBEntryTypes() {
+		if et == database.StateTrieMigrationDB && !dbm.InMigration() {
+			continue
+		}
+		ets = append(ets, et)
+	}
+	return ets, index, nil
+}
+
+// ChaindbProperty returns the given property of the chain database, e.g. "leveldb.stats" or
+// "leveldb.iostats", or of its shard if the database is sharded. The property of every database
+// is returned if the database is not given.
+func (api *PrivateDebugAPI) ChaindbProperty(property string, db *string, shard *int) (string, error) {
+	ets, index, err := api.chaindbTargets(db, shard)
+	if err != nil {
+		return "", err
+	}
+	if len(ets) == 1 {
+		return api.cn.ChainDB().Stat(ets[0], index, property)
+	}
+	var buf bytes.Buffer
+	for _, et := range ets {
+		stat, err := api.cn.ChainDB().Stat(et, index, property)
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", et, err)
+		}
+		fmt.Fprintf(&buf, "%s:\n%s\n", et, stat)
+	}
+	return buf.String(), nil
+}
+
+// ChaindbCompact compacts the entire key range of the chain database, or of its shard if the database
+// is sharded, to reclaim the space of the deleted data. Every database is compacted if the database is
+// not given. The compaction may take long and slows down the node until it is done.
+func (api *PrivateDebugAPI) ChaindbCompact(db *string, shard *int) error {
+	ets, index, err := api.chaindbTargets(db, shard)
+	if err != nil {
+		return err
+	}
+	for _, et := range ets {
+		start := time.Now()
+		for b := 0; b <= 255; b++ {
+			var from, to []byte
+			if b > 0 {
+				from = []byte{byte(b)}
+			}
+			if b < 255 {
+				to = []byte{byte(b + 1)}
+			}
+			logger.Info("Compacting chain database", "db", et, "shard", index, "range", fmt.Sprintf("%#X-%#X", from, to))
+			if err := api.cn.ChainDB().Compact(et, index, from, to); err != nil {
+				logger.Error("Failed to compact the chain database", "db", et, "shard", index, "err", err)
+				return fmt.Errorf("%s: %v", et, err)
+			}
+		}
+		logger.Info("Compacted chain database", "db", et, "shard", index, "elapsed", time.Since(start))
+	}
+	return nil
+}
+
 // StorageRangeResult is the result of a debug_storageRangeAt API call.
 type StorageRangeResult struct {
 	Storage storageMap   `json:"storage"`
@@ -342,9 +929,13 @@ type storageEntry struct {
 	Value common.Hash  `json:"value"`
 }
 
-//StorageRangeAt returns the storage at the given block height and transaction index.
+// StorageRangeAt returns the storage at the given block height and transaction index, which is the
+// storage right before the transaction is executed. The transactions of the block are replayed up to
+// the index, and the state of the parent block is regenerated if it is not available.
+// The storage is returned from the hashed key keyStart, and NextKey of the result can be used as
+// keyStart of the next call to retrieve the following entries.
 func (api *PrivateDebugAPI) StorageRangeAt(ctx context.Context, blockHash common.Hash, txIndex int, contractAddress common.Address, keyStart hexutil.Bytes, maxResult int) (StorageRangeResult, error) {
-	_, _, statedb, err := api.computeTxEnv(blockHash, txIndex, 0)
+	_, _, statedb, err := api.computeTxEnv(blockHash, txIndex, defaultTraceReexec)
 	if err != nil {
 		return StorageRangeResult{}, err
 	}
@@ -378,6 +969,117 @@ func storageRangeAt(st state.Trie, start []byte, maxResult int) (StorageRangeRes
 	return result, nil
 }
 
+// maxAccountRangeResults is the maximum number of accounts returned by AccountRange.
+const maxAccountRangeResults = 256
+
+// AccountRangeResult is a page of the accounts with the state session to retrieve the next pages.
+type AccountRangeResult struct {
+	state.RangeDump
+	Session string `json:"session,omitempty"` // empty if the page includes the last account
+}
+
+// StorageRangeSessionResult is a page of the storage with the state session to retrieve the next pages.
+type StorageRangeSessionResult struct {
+	StorageRangeResult
+	Session string `json:"session,omitempty"` // empty if the page includes the last key
+}
+
+// OpenStateSession pins the state of the given block so that the paginated AccountRange and
+// StorageRange calls with the session see the same state while the blocks are imported.
+// The session is released if it is not used for a while or CloseStateSession is called.
+func (api *PrivateDebugAPI) OpenStateSession(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (StateSession, error) {
+	block, err := api.cn.APIBackend.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return StateSession{}, err
+	}
+	return api.cn.stateSessions.open(block)
+}
+
+// CloseStateSession releases the state session.
+func (api *PrivateDebugAPI) CloseStateSession(session string) error {
+	return api.cn.stateSessions.close(session)
+}
+
+// stateOfSession returns the state of the given session. If the session is not given, a new session
+// of the state of the given block is opened. The returned bool is true if the session is newly opened.
+func (api *PrivateDebugAPI) stateOfSession(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, session *string) (*state.StateDB, string, bool, error) {
+	var (
+		s      StateSession
+		err    error
+		opened bool
+	)
+	if session != nil && *session != "" {
+		s, err = api.cn.stateSessions.get(*session)
+	} else {
+		s, err = api.OpenStateSession(ctx, blockNrOrHash)
+		opened = true
+	}
+	if err != nil {
+		return nil, "", false, err
+	}
+	stateDB, err := state.New(s.Root, api.cn.blockchain.StateCache(), nil)
+	if err != nil {
+		if opened {
+			api.cn.stateSessions.close(s.Token)
+		}
+		return nil, "", false, err
+	}
+	return stateDB, s.Token, opened, nil
+}
+
+// AccountRange returns at most maxResults accounts of the state of the given block, starting from
+// the given hash of an address. If the accounts are not returned at once, the result includes the
+// session pinning the state and the next pages are retrieved with the session. The block is ignored
+// if the session is given.
+func (api *PrivateDebugAPI) AccountRange(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, start hexutil.Bytes, maxResults int, noCode, noStorage bool, session *string) (AccountRangeResult, error) {
+	if maxResults <= 0 || maxResults > maxAccountRangeResults {
+		maxResults = maxAccountRangeResults
+	}
+	stateDB, token, opened, err := api.stateOfSession(ctx, blockNrOrHash, session)
+	if err != nil {
+		return AccountRangeResult{}, err
+	}
+	result := AccountRangeResult{RangeDump: stateDB.RangeDump(start, maxResults, noCode, noStorage), Session: token}
+	if result.Next == nil && opened {
+		// All the accounts are returned, the session is not needed
+		api.cn.stateSessions.close(token)
+		result.Session = ""
+	}
+	return result, nil
+}
+
+// StorageRange returns at most maxResult storage slots of the contract in the state of the given block,
+// starting from the given hash of a key. If the slots are not returned at once, the result includes the
+// session pinning the state and the next pages are retrieved with the session. The block is ignored
+// if the session is given.
+func (api *PrivateDebugAPI) StorageRange(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, contractAddress common.Address, keyStart hexutil.Bytes, maxResult int, session *string) (StorageRangeSessionResult, error) {
+	stateDB, token, opened, err := api.stateOfSession(ctx, blockNrOrHash, session)
+	if err != nil {
+		return StorageRangeSessionResult{}, err
+	}
+	closeOpened := func() {
+		if opened {
+			api.cn.stateSessions.close(token)
+		}
+	}
+	st := stateDB.StorageTrie(contractAddress)
+	if st == nil {
+		closeOpened()
+		return StorageRangeSessionResult{}, fmt.Errorf("account %x doesn't exist", contractAddress)
+	}
+	storage, err := storageRangeAt(st, keyStart, maxResult)
+	if err != nil {
+		closeOpened()
+		return StorageRangeSessionResult{}, err
+	}
+	result := StorageRangeSessionResult{StorageRangeResult: storage, Session: token}
+	if result.NextKey == nil && opened {
+		closeOpened()
+		result.Session = ""
+	}
+	return result, nil
+}
+
 // GetModifiedAccountsByNumber returns all accounts that have changed between the
 // two blocks specified. A change is defined as a difference in nonce, balance,
 // code hash, or storage hash.
@@ -39,12 +39,17 @@ import (
 	"github.com/klaytn/klaytn/node/cn/gasprice"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
 )
 
 // CNAPIBackend implements api.Backend for full nodes
 type CNAPIBackend struct {
 	cn  *CN
 	gpo *gasprice.Oracle
+
+	// stateReexecLimit is the maximum number of blocks re-executed to regenerate
+	// a missing historical state. Zero disables the regeneration.
+	stateReexecLimit uint64
 }
 
 // GetTxLookupInfoAndReceipt retrieves a tx and lookup info and receipt for a given transaction hash.
@@ -157,7 +162,7 @@ func (b *CNAPIBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.B
 	if header == nil || err != nil {
 		return nil, nil, err
 	}
-	stateDb, err := b.cn.BlockChain().StateAt(header.Root)
+	stateDb, err := b.stateAt(header)
 	return stateDb, header, err
 }
 
@@ -170,12 +175,30 @@ func (b *CNAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNr
 		if header == nil {
 			return nil, nil, fmt.Errorf("header for hash not found")
 		}
-		stateDb, err := b.cn.BlockChain().StateAt(header.Root)
+		stateDb, err := b.stateAt(header)
 		return stateDb, header, err
 	}
 	return nil, nil, fmt.Errorf("invalid arguments; neither block nor hash specified")
 }
 
+// stateAt returns the state of the given header. If the state is missing and the regeneration
+// is enabled, the state is regenerated by re-executing at most stateReexecLimit blocks.
+func (b *CNAPIBackend) stateAt(header *types.Header) (*state.StateDB, error) {
+	stateDb, err := b.cn.BlockChain().StateAt(header.Root)
+	if err == nil || b.stateReexecLimit == 0 {
+		return stateDb, err
+	}
+	if _, ok := err.(*statedb.MissingNodeError); !ok {
+		return nil, err
+	}
+	block := b.cn.blockchain.GetBlock(header.Hash(), header.Number.Uint64())
+	if block == nil {
+		return nil, fmt.Errorf("the block does not exist (block hash: %s)", header.Hash().String())
+	}
+	stateReexecMeter.Mark(1)
+	return b.cn.stateAtBlock(block, b.stateReexecLimit)
+}
+
 func (b *CNAPIBackend) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
 	block := b.cn.blockchain.GetBlockByHash(hash)
 	if block == nil {
@@ -215,6 +238,10 @@ func (b *CNAPIBackend) SubscribeRemovedLogsEvent(ch chan<- blockchain.RemovedLog
 	return b.cn.BlockChain().SubscribeRemovedLogsEvent(ch)
 }
 
+func (b *CNAPIBackend) SubscribeChainReorgEvent(ch chan<- blockchain.ChainReorgEvent) event.Subscription {
+	return b.cn.BlockChain().SubscribeChainReorgEvent(ch)
+}
+
 func (b *CNAPIBackend) SubscribeChainEvent(ch chan<- blockchain.ChainEvent) event.Subscription {
 	return b.cn.BlockChain().SubscribeChainEvent(ch)
 }
@@ -263,6 +290,10 @@ func (b *CNAPIBackend) TxPoolContent() (map[common.Address]types.Transactions, m
 	return b.cn.TxPool().Content()
 }
 
+func (b *CNAPIBackend) TxPoolContentFrom(addr common.Address) (types.Transactions, types.Transactions) {
+	return b.cn.TxPool().ContentFrom(addr)
+}
+
 func (b *CNAPIBackend) SubscribeNewTxsEvent(ch chan<- blockchain.NewTxsEvent) event.Subscription {
 	return b.cn.TxPool().SubscribeNewTxsEvent(ch)
 }
@@ -302,6 +333,23 @@ func (b *CNAPIBackend) ServiceFilter(ctx context.Context, session *bloombits.Mat
 	}
 }
 
+// LogIndexStatus returns the first and the last block numbers covered by the log index.
+// It returns false if the log index is not enabled.
+func (b *CNAPIBackend) LogIndexStatus() (uint64, uint64, bool) {
+	if !b.cn.config.LogIndexing {
+		return 0, 0, false
+	}
+	tail, err := b.cn.chainDB.ReadLogIndexTail()
+	if err != nil || tail == 0 {
+		return 0, 0, false
+	}
+	head, err := b.cn.chainDB.ReadLogIndexHead()
+	if err != nil {
+		return 0, 0, false
+	}
+	return tail, head, true
+}
+
 func (b *CNAPIBackend) IsParallelDBWrite() bool {
 	return b.cn.BlockChain().IsParallelDBWrite()
 }
@@ -310,6 +358,14 @@ func (b *CNAPIBackend) IsSenderTxHashIndexingEnabled() bool {
 	return b.cn.BlockChain().IsSenderTxHashIndexingEnabled()
 }
 
+func (b *CNAPIBackend) TxIndexStatus() blockchain.TxIndexStatus {
+	return b.cn.BlockChain().TxIndexStatus()
+}
+
 func (b *CNAPIBackend) RPCGasCap() *big.Int {
 	return b.cn.config.RPCGasCap
 }
+
+func (b *CNAPIBackend) RPCEstimateGasTolerance() float64 {
+	return b.cn.config.RPCEstimateGasTolerance
+}
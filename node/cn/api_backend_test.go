@@ -32,6 +32,7 @@ import (
 	mocks2 "github.com/klaytn/klaytn/node/cn/mocks"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
 	"github.com/klaytn/klaytn/work/mocks"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/context"
@@ -374,7 +375,7 @@ func TestCNAPIBackend_StateAndHeaderByNumber(t *testing.T) {
 	blockNum := uint64(123)
 	block := newBlock(int(blockNum))
 
-	stateDB, err := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()))
+	stateDB, err := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -420,6 +421,77 @@ func TestCNAPIBackend_StateAndHeaderByNumber(t *testing.T) {
 	}
 }
 
+// Tests that a missing state is regenerated only if the re-execution limit is set.
+func TestCNAPIBackend_StateAndHeaderByNumber_Reexec(t *testing.T) {
+	db := database.NewMemoryDBManager()
+	stateDB, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stateDB.SetNonce(addrs[0], 123)
+	root, err := stateDB.Commit(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stateDB.Database().TrieDB().Commit(root, false, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	blockNum := uint64(123)
+	block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(blockNum), Root: root})
+	missingNodeErr := &statedb.MissingNodeError{NodeHash: root}
+
+	// The regeneration is disabled
+	{
+		mockCtrl, mockBlockChain, _, api := newCNAPIBackend(t)
+
+		mockBlockChain.EXPECT().GetHeaderByNumber(blockNum).Return(block.Header()).Times(1)
+		mockBlockChain.EXPECT().StateAt(root).Return(nil, missingNodeErr).Times(1)
+		returnedStateDB, _, err := api.StateAndHeaderByNumber(context.Background(), rpc.BlockNumber(blockNum))
+
+		assert.Nil(t, returnedStateDB)
+		assert.Equal(t, missingNodeErr, err)
+
+		mockCtrl.Finish()
+	}
+	// The state is regenerated from the chain database
+	{
+		mockCtrl, mockBlockChain, _, api := newCNAPIBackend(t)
+		api.cn.chainDB = db
+		api.stateReexecLimit = 2
+
+		mockBlockChain.EXPECT().GetHeaderByNumber(blockNum).Return(block.Header()).Times(1)
+		mockBlockChain.EXPECT().StateAt(root).Return(nil, missingNodeErr).Times(1)
+		mockBlockChain.EXPECT().GetBlock(block.Hash(), blockNum).Return(block).Times(1)
+		mockBlockChain.EXPECT().StateCache().Return(state.NewDatabase(db)).Times(1)
+		returnedStateDB, header, err := api.StateAndHeaderByNumber(context.Background(), rpc.BlockNumber(blockNum))
+
+		assert.NoError(t, err)
+		assert.Equal(t, block.Header(), header)
+		assert.Equal(t, uint64(123), returnedStateDB.GetNonce(addrs[0]))
+
+		mockCtrl.Finish()
+	}
+	// No ancestor has the state within the limit
+	{
+		mockCtrl, mockBlockChain, _, api := newCNAPIBackend(t)
+		api.cn.chainDB = database.NewMemoryDBManager()
+		api.stateReexecLimit = 2
+
+		mockBlockChain.EXPECT().GetHeaderByNumber(blockNum).Return(block.Header()).Times(1)
+		mockBlockChain.EXPECT().StateAt(root).Return(nil, missingNodeErr).Times(1)
+		mockBlockChain.EXPECT().GetBlock(block.Hash(), blockNum).Return(block).Times(1)
+		mockBlockChain.EXPECT().StateCache().Return(state.NewDatabase(api.cn.chainDB)).Times(1)
+		mockBlockChain.EXPECT().GetBlock(block.ParentHash(), blockNum-1).Return(nil).Times(1)
+		returnedStateDB, _, err := api.StateAndHeaderByNumber(context.Background(), rpc.BlockNumber(blockNum))
+
+		assert.Nil(t, returnedStateDB)
+		assert.Error(t, err)
+
+		mockCtrl.Finish()
+	}
+}
+
 func TestCNAPIBackend_GetBlock(t *testing.T) {
 	block := newBlock(123)
 	hash := hashes[0]
@@ -0,0 +1,181 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/storage/statedb"
+)
+
+// ContractStats is the size of the code and the storage of a contract at a block.
+type ContractStats struct {
+	Address      common.Address         `json:"address"`
+	Number       hexutil.Uint64         `json:"number"`
+	CodeSize     int                    `json:"codeSize"`
+	StorageRoot  common.Hash            `json:"storageRoot"`
+	StorageSlots int                    `json:"storageSlots"`
+	TrieNodes    int                    `json:"trieNodes"` // number of the storage trie nodes stored in the database, embedded nodes are not counted
+	Growth       *ContractStorageGrowth `json:"growth,omitempty"`
+}
+
+// ContractStorageGrowth is the change of the storage of a contract over a block range.
+type ContractStorageGrowth struct {
+	From         hexutil.Uint64 `json:"from"`
+	To           hexutil.Uint64 `json:"to"`
+	SlotsAdded   int            `json:"slotsAdded"`
+	SlotsUpdated int            `json:"slotsUpdated"`
+	SlotsDeleted int            `json:"slotsDeleted"`
+	NodesAdded   int            `json:"nodesAdded"`   // nodes in the storage trie of the last block, but not in the first one
+	NodesRemoved int            `json:"nodesRemoved"` // nodes in the storage trie of the first block, but not in the last one
+}
+
+// GetContractStats returns the code size, the number of the storage slots and the number of the
+// storage trie nodes of a contract at the end block, or the latest block if it is not given.
+// If the start block is given, the growth of the storage from the start block to the end block
+// is returned together, which is computed by comparing the two storage tries.
+func (api *PrivateDebugAPI) GetContractStats(ctx context.Context, contractAddr common.Address, startNum *rpc.BlockNumber, endNum *rpc.BlockNumber) (*ContractStats, error) {
+	end := rpc.LatestBlockNumber
+	if endNum != nil {
+		end = *endNum
+	}
+	endState, endHeader, err := api.cn.APIBackend.StateAndHeaderByNumber(ctx, end)
+	if err != nil {
+		return nil, err
+	}
+	if endState == nil || endHeader == nil {
+		return nil, fmt.Errorf("block #%d not found", end.Int64())
+	}
+	endRoot, err := endState.GetContractStorageRoot(contractAddr)
+	if err != nil {
+		return nil, err
+	}
+	endTrie, err := statedb.NewSecureTrie(endRoot, endState.Database().TrieDB())
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Start collecting the contract stats", "contractAddr", contractAddr.String(), "block", endHeader.Number)
+	start := time.Now()
+
+	stats := &ContractStats{
+		Address:     contractAddr,
+		Number:      hexutil.Uint64(endHeader.Number.Uint64()),
+		CodeSize:    endState.GetCodeSize(contractAddr),
+		StorageRoot: endRoot,
+	}
+	stats.TrieNodes, stats.StorageSlots, err = countTrieNodes(ctx, endTrie.NodeIterator(nil), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if startNum != nil {
+		startState, startHeader, err := api.cn.APIBackend.StateAndHeaderByNumber(ctx, *startNum)
+		if err != nil {
+			return nil, err
+		}
+		if startState == nil || startHeader == nil {
+			return nil, fmt.Errorf("block #%d not found", startNum.Int64())
+		}
+		if startHeader.Number.Uint64() > endHeader.Number.Uint64() {
+			return nil, fmt.Errorf("start block height (%d) must be less than or equal to end block height (%d)",
+				startHeader.Number.Uint64(), endHeader.Number.Uint64())
+		}
+		if stats.Growth, err = contractStorageGrowth(ctx, contractAddr, startState, endTrie); err != nil {
+			return nil, err
+		}
+		stats.Growth.From = hexutil.Uint64(startHeader.Number.Uint64())
+		stats.Growth.To = stats.Number
+	}
+
+	logger.Info("Finished collecting the contract stats", "contractAddr", contractAddr.String(), "block", endHeader.Number,
+		"slots", stats.StorageSlots, "trieNodes", stats.TrieNodes, "elapsed", time.Since(start))
+	return stats, nil
+}
+
+// contractStorageGrowth compares the storage trie of the contract in startState with endTrie.
+// If the contract does not exist in startState, its storage is regarded as empty.
+func contractStorageGrowth(ctx context.Context, contractAddr common.Address, startState *state.StateDB, endTrie *statedb.SecureTrie) (*ContractStorageGrowth, error) {
+	var startRoot common.Hash
+	if startState.Exist(contractAddr) {
+		root, err := startState.GetContractStorageRoot(contractAddr)
+		if err != nil {
+			return nil, err
+		}
+		startRoot = root
+	}
+	startTrie, err := statedb.NewSecureTrie(startRoot, startState.Database().TrieDB())
+	if err != nil {
+		return nil, err
+	}
+
+	// Slots only in the start trie or updated later
+	prevSlots := make(map[common.Hash]struct{})
+	removed, _ := statedb.NewDifferenceIterator(endTrie.NodeIterator(nil), startTrie.NodeIterator(nil))
+	nodesRemoved, _, err := countTrieNodes(ctx, removed, func(key []byte) {
+		prevSlots[common.BytesToHash(key)] = struct{}{}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Slots only in the end trie or updated later
+	growth := &ContractStorageGrowth{NodesRemoved: nodesRemoved}
+	added, _ := statedb.NewDifferenceIterator(startTrie.NodeIterator(nil), endTrie.NodeIterator(nil))
+	growth.NodesAdded, _, err = countTrieNodes(ctx, added, func(key []byte) {
+		if _, ok := prevSlots[common.BytesToHash(key)]; ok {
+			delete(prevSlots, common.BytesToHash(key))
+			growth.SlotsUpdated++
+		} else {
+			growth.SlotsAdded++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	growth.SlotsDeleted = len(prevSlots)
+	return growth, nil
+}
+
+// countTrieNodes iterates the given node iterator and returns the number of the hashed nodes
+// and the leaves. onLeaf is called with the key of each leaf if it is not nil.
+func countTrieNodes(ctx context.Context, it statedb.NodeIterator, onLeaf func(key []byte)) (int, int, error) {
+	nodes, leaves := 0, 0
+	for i := 1; it.Next(true); i++ {
+		if it.Hash() != (common.Hash{}) {
+			nodes++
+		}
+		if it.Leaf() {
+			leaves++
+			if onLeaf != nil {
+				onLeaf(it.LeafKey())
+			}
+		}
+		if i%10000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+	return nodes, leaves, it.Error()
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"context"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContractStorageGrowth(t *testing.T) {
+	var (
+		db           = state.NewDatabase(database.NewMemoryDBManager())
+		contractAddr = common.Address{0x01}
+	)
+	commit := func(stateDB *state.StateDB) *state.StateDB {
+		root, err := stateDB.Commit(true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.TrieDB().Commit(root, false, 0); err != nil {
+			t.Fatal(err)
+		}
+		stateDB, err = state.New(root, db, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return stateDB
+	}
+
+	// Slots 1, 2 and 3 at the start
+	emptyState, _ := state.New(common.Hash{}, db, nil)
+	startState, _ := state.New(common.Hash{}, db, nil)
+	startState.CreateSmartContractAccount(contractAddr, params.CodeFormatEVM, params.Rules{})
+	startState.SetCode(contractAddr, []byte{0x60, 0x00})
+	for i := byte(1); i <= 3; i++ {
+		startState.SetState(contractAddr, common.Hash{i}, common.Hash{i})
+	}
+	startState = commit(startState)
+
+	// Slot 2 is updated, slot 3 is deleted and slot 4 is added at the end
+	endState := startState.Copy()
+	endState.SetState(contractAddr, common.Hash{2}, common.Hash{0x22})
+	endState.SetState(contractAddr, common.Hash{3}, common.Hash{})
+	endState.SetState(contractAddr, common.Hash{4}, common.Hash{4})
+	endState = commit(endState)
+
+	endRoot, err := endState.GetContractStorageRoot(contractAddr)
+	assert.NoError(t, err)
+	endTrie, err := statedb.NewSecureTrie(endRoot, db.TrieDB())
+	assert.NoError(t, err)
+
+	nodes, slots, err := countTrieNodes(context.Background(), endTrie.NodeIterator(nil), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, slots)
+	assert.True(t, nodes > 0)
+
+	growth, err := contractStorageGrowth(context.Background(), contractAddr, startState, endTrie)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, growth.SlotsAdded)
+	assert.Equal(t, 1, growth.SlotsUpdated)
+	assert.Equal(t, 1, growth.SlotsDeleted)
+	assert.True(t, growth.NodesAdded > 0)
+	assert.True(t, growth.NodesRemoved > 0)
+
+	// The contract does not exist at the start
+	growth, err = contractStorageGrowth(context.Background(), contractAddr, emptyState, endTrie)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, growth.SlotsAdded)
+	assert.Equal(t, 0, growth.SlotsUpdated+growth.SlotsDeleted+growth.NodesRemoved)
+	assert.Equal(t, nodes, growth.NodesAdded)
+
+	// The storage is not changed
+	growth, err = contractStorageGrowth(context.Background(), contractAddr, endState, endTrie)
+	assert.NoError(t, err)
+	assert.Equal(t, ContractStorageGrowth{}, *growth)
+}
@@ -0,0 +1,177 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/kerrors"
+	"github.com/klaytn/klaytn/networks/rpc"
+)
+
+// ProfileConfig holds extra parameters to profile functions.
+type ProfileConfig struct {
+	Reexec *uint64
+}
+
+// TxOpcodeProfile is the opcode profile of a transaction.
+type TxOpcodeProfile struct {
+	*vm.OpcodeProfile
+	TxHash common.Hash `json:"txHash"`
+	Failed bool        `json:"failed"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// BlockOpcodeProfile is the opcode profile aggregated over the transactions of a block.
+type BlockOpcodeProfile struct {
+	*vm.OpcodeProfile
+	TxCount   int           `json:"txCount"`
+	FailedTxs []common.Hash `json:"failedTxs,omitempty"`
+}
+
+// ProfileTransaction re-executes the transaction and returns the gas, the computation cost
+// and the wall-time aggregated per opcode. The opcode computation cost limit is applied
+// in the same way as the block processing.
+func (api *PrivateDebugAPI) ProfileTransaction(ctx context.Context, hash common.Hash, config *ProfileConfig) (*TxOpcodeProfile, error) {
+	tx, blockHash, _, index := api.cn.ChainDB().ReadTxAndLookupInfo(hash)
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %#x not found", hash)
+	}
+	msg, vmctx, statedb, err := api.computeTxEnv(blockHash, int(index), profileReexec(config))
+	if err != nil {
+		return nil, err
+	}
+	profiler := vm.NewOpcodeProfiler()
+	status, err := api.profileTx(ctx, profiler, msg, vmctx, statedb)
+	if err != nil {
+		return nil, err
+	}
+	result := &TxOpcodeProfile{
+		OpcodeProfile: profiler.GetResult(),
+		TxHash:        hash,
+		Failed:        status != types.ReceiptStatusSuccessful,
+	}
+	if result.Failed {
+		result.Error = blockchain.GetVMerrFromReceiptStatus(status).Error()
+	}
+	return result, nil
+}
+
+// ProfileBlockByNumber re-executes the transactions of the block and returns the gas,
+// the computation cost and the wall-time aggregated per opcode over the transactions.
+func (api *PrivateDebugAPI) ProfileBlockByNumber(ctx context.Context, number rpc.BlockNumber, config *ProfileConfig) (*BlockOpcodeProfile, error) {
+	var block *types.Block
+
+	switch number {
+	case rpc.PendingBlockNumber:
+		return nil, kerrors.ErrPendingBlockNotSupported
+	case rpc.LatestBlockNumber:
+		block = api.cn.blockchain.CurrentBlock()
+	default:
+		block = api.cn.blockchain.GetBlockByNumber(uint64(number))
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	return api.profileBlock(ctx, block, config)
+}
+
+// ProfileBlockByHash re-executes the transactions of the block and returns the gas,
+// the computation cost and the wall-time aggregated per opcode over the transactions.
+func (api *PrivateDebugAPI) ProfileBlockByHash(ctx context.Context, hash common.Hash, config *ProfileConfig) (*BlockOpcodeProfile, error) {
+	block := api.cn.blockchain.GetBlockByHash(hash)
+	if block == nil {
+		return nil, fmt.Errorf("block #%x not found", hash)
+	}
+	return api.profileBlock(ctx, block, config)
+}
+
+func (api *PrivateDebugAPI) profileBlock(ctx context.Context, block *types.Block, config *ProfileConfig) (*BlockOpcodeProfile, error) {
+	profiler := vm.NewOpcodeProfiler()
+	txs := block.Transactions()
+	result := &BlockOpcodeProfile{TxCount: len(txs)}
+	if len(txs) == 0 {
+		result.OpcodeProfile = profiler.GetResult()
+		return result, nil
+	}
+
+	parent := api.cn.blockchain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent %x not found", block.ParentHash())
+	}
+	statedb, deferFn, err := api.stateAt(parent, profileReexec(config))
+	defer deferFn()
+	if err != nil {
+		return nil, fmt.Errorf("can not get the state of block %#x: %v", parent.Root(), err)
+	}
+
+	signer := types.MakeSigner(api.config, block.Number())
+	for i, tx := range txs {
+		msg, err := tx.AsMessageWithAccountKeyPicker(signer, statedb, block.NumberU64())
+		if err != nil {
+			return nil, fmt.Errorf("tx %#x: %v", tx.Hash(), err)
+		}
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
+
+		vmctx := blockchain.NewEVMContext(msg, block.Header(), api.cn.blockchain, nil)
+		status, err := api.profileTx(ctx, profiler, msg, vmctx, statedb)
+		if err != nil {
+			return nil, fmt.Errorf("tx %#x: %v", tx.Hash(), err)
+		}
+		if status != types.ReceiptStatusSuccessful {
+			result.FailedTxs = append(result.FailedTxs, tx.Hash())
+		}
+		statedb.Finalise(true, true)
+	}
+	result.OpcodeProfile = profiler.GetResult()
+	return result, nil
+}
+
+// profileTx executes the message with the profiler and returns the receipt status.
+// The execution is aborted if the context is done.
+func (api *PrivateDebugAPI) profileTx(ctx context.Context, profiler *vm.OpcodeProfiler, msg blockchain.Message, vmctx vm.Context, statedb *state.StateDB) (uint, error) {
+	vmenv := vm.NewEVM(vmctx, statedb, api.config, &vm.Config{Debug: true, Tracer: profiler, UseOpcodeComputationCost: true})
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		vmenv.Cancel(vm.CancelByCtxDone)
+	}()
+
+	_, _, kerr := blockchain.ApplyMessage(vmenv, msg)
+	if vmenv.Cancelled() {
+		return kerr.Status, ctx.Err()
+	}
+	if kerr.ErrTxInvalid != nil {
+		return kerr.Status, fmt.Errorf("profiling failed: %v", kerr.ErrTxInvalid)
+	}
+	return kerr.Status, nil
+}
+
+func profileReexec(config *ProfileConfig) uint64 {
+	if config != nil && config.Reexec != nil {
+		return *config.Reexec
+	}
+	return defaultTraceReexec
+}
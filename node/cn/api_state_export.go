@@ -0,0 +1,143 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/networks/rpc"
+)
+
+// StateImportResult is the state imported by admin_importState. The governance items are the
+// ones at the exported block, which are not applied to the node.
+type StateImportResult struct {
+	ChainID     *hexutil.Big    `json:"chainId"`
+	BlockNumber hexutil.Uint64  `json:"blockNumber"`
+	BlockHash   common.Hash     `json:"blockHash"`
+	Root        common.Hash     `json:"root"`
+	Governance  json.RawMessage `json:"governance,omitempty"`
+	*state.StateExportStats
+}
+
+// ExportState exports the state of the given block, including the accounts with their keys, the
+// codes, the storage slots and the governance items at the block, into a local file in the
+// canonical state export format. The file is compressed if its name ends with ".gz".
+func (api *PrivateAdminAPI) ExportState(ctx context.Context, file string, number rpc.BlockNumber) (*state.StateExportStats, error) {
+	if _, err := os.Stat(file); err == nil {
+		// File already exists. Allowing overwrite could be a DoS vecotor,
+		// since the 'file' may point to arbitrary paths on the drive
+		return nil, errors.New("location would overwrite an existing file")
+	}
+	stateDB, header, err := api.cn.APIBackend.StateAndHeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if stateDB == nil || header == nil {
+		return nil, fmt.Errorf("block #%d not found", number.Int64())
+	}
+
+	exportHeader := &state.StateExportHeader{
+		ChainID:     api.cn.chainConfig.ChainID,
+		BlockNumber: header.Number.Uint64(),
+		BlockHash:   header.Hash(),
+		Root:        header.Root,
+	}
+	if api.cn.chainConfig.Istanbul != nil && api.cn.governance != nil {
+		_, items, err := api.cn.governance.ReadGovernance(header.Number.Uint64())
+		if err != nil {
+			return nil, err
+		}
+		if exportHeader.Governance, err = json.Marshal(items); err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+	var writer io.WriteCloser = out
+	if strings.HasSuffix(file, ".gz") {
+		writer = gzip.NewWriter(out)
+	}
+
+	logger.Info("Start exporting the state", "block", exportHeader.BlockNumber, "root", exportHeader.Root, "file", file)
+	start := time.Now()
+
+	stats, err := state.ExportState(stateDB.Database(), exportHeader, writer)
+	if writer != out {
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file)
+		return nil, err
+	}
+	logger.Info("Finished exporting the state", "block", exportHeader.BlockNumber, "accounts", stats.Accounts,
+		"slots", stats.Slots, "elapsed", time.Since(start))
+	return stats, nil
+}
+
+// ImportState imports the state exported by ExportState from a local file. The state is written
+// to the database only if the state root recomputed from the file is the same as the exported one.
+func (api *PrivateAdminAPI) ImportState(file string) (*StateImportResult, error) {
+	in, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	var reader io.Reader = in
+	if strings.HasSuffix(file, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Info("Start importing the state", "file", file)
+	start := time.Now()
+
+	header, stats, err := state.ImportState(api.cn.ChainDB(), reader)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Finished importing the state", "block", header.BlockNumber, "root", header.Root,
+		"accounts", stats.Accounts, "slots", stats.Slots, "elapsed", time.Since(start))
+	return &StateImportResult{
+		ChainID:          (*hexutil.Big)(header.ChainID),
+		BlockNumber:      hexutil.Uint64(header.BlockNumber),
+		BlockHash:        header.BlockHash,
+		Root:             header.Root,
+		Governance:       header.Governance,
+		StateExportStats: stats,
+	}, nil
+}
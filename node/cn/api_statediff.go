@@ -0,0 +1,159 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/kerrors"
+	"github.com/klaytn/klaytn/networks/rpc"
+)
+
+// StorageStateDiff is a change of a storage slot made by a transaction.
+type StorageStateDiff struct {
+	Key   common.Hash `json:"key"`
+	Prev  common.Hash `json:"prev"`
+	Value common.Hash `json:"value"`
+}
+
+// AccountStateDiff is a change of an account made by a transaction.
+// The previous and current values of a field are given only if the field is changed.
+type AccountStateDiff struct {
+	Address      common.Address     `json:"address"`
+	Created      bool               `json:"created,omitempty"`
+	Suicided     bool               `json:"suicided,omitempty"`
+	PrevBalance  *hexutil.Big       `json:"prevBalance,omitempty"`
+	Balance      *hexutil.Big       `json:"balance,omitempty"`
+	PrevNonce    *hexutil.Uint64    `json:"prevNonce,omitempty"`
+	Nonce        *hexutil.Uint64    `json:"nonce,omitempty"`
+	PrevCodeHash *common.Hash       `json:"prevCodeHash,omitempty"`
+	CodeHash     *common.Hash       `json:"codeHash,omitempty"`
+	Storage      []StorageStateDiff `json:"storage,omitempty"`
+}
+
+// TxStateDiff contains the state changes made by a transaction.
+type TxStateDiff struct {
+	TxHash   common.Hash         `json:"txHash"`
+	Accounts []*AccountStateDiff `json:"accounts"`
+}
+
+// StateDiffBlockByNumber re-executes the transactions of the block and returns the state changes
+// made by each transaction. The changes made by the block reward are not included.
+func (api *PrivateDebugAPI) StateDiffBlockByNumber(ctx context.Context, number rpc.BlockNumber) ([]*TxStateDiff, error) {
+	var block *types.Block
+
+	switch number {
+	case rpc.PendingBlockNumber:
+		return nil, kerrors.ErrPendingBlockNotSupported
+	case rpc.LatestBlockNumber:
+		block = api.cn.blockchain.CurrentBlock()
+	default:
+		block = api.cn.blockchain.GetBlockByNumber(uint64(number))
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	return api.stateDiffBlock(ctx, block)
+}
+
+// StateDiffBlockByHash re-executes the transactions of the block and returns the state changes
+// made by each transaction. The changes made by the block reward are not included.
+func (api *PrivateDebugAPI) StateDiffBlockByHash(ctx context.Context, hash common.Hash) ([]*TxStateDiff, error) {
+	block := api.cn.blockchain.GetBlockByHash(hash)
+	if block == nil {
+		return nil, fmt.Errorf("block #%x not found", hash)
+	}
+	return api.stateDiffBlock(ctx, block)
+}
+
+func (api *PrivateDebugAPI) stateDiffBlock(ctx context.Context, block *types.Block) ([]*TxStateDiff, error) {
+	txs := block.Transactions()
+	results := make([]*TxStateDiff, 0, len(txs))
+	if len(txs) == 0 {
+		return results, nil
+	}
+
+	parent := api.cn.blockchain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent %x not found", block.ParentHash())
+	}
+	statedb, deferFn, err := api.stateAt(parent, defaultTraceReexec)
+	defer deferFn()
+	if err != nil {
+		return nil, fmt.Errorf("can not get the state of block %#x: %v", parent.Root(), err)
+	}
+
+	signer := types.MakeSigner(api.config, block.Number())
+	for i, tx := range txs {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		msg, err := tx.AsMessageWithAccountKeyPicker(signer, statedb, block.NumberU64())
+		if err != nil {
+			return nil, fmt.Errorf("tx %#x: %v", tx.Hash(), err)
+		}
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
+
+		vmctx := blockchain.NewEVMContext(msg, block.Header(), api.cn.blockchain, nil)
+		vmenv := vm.NewEVM(vmctx, statedb, api.config, &vm.Config{})
+		if _, _, kerr := blockchain.ApplyMessage(vmenv, msg); kerr.ErrTxInvalid != nil {
+			return nil, fmt.Errorf("tx %#x: %v", tx.Hash(), kerr.ErrTxInvalid)
+		}
+
+		results = append(results, &TxStateDiff{TxHash: tx.Hash(), Accounts: formatAccountDiffs(statedb.Diff())})
+		// Finalize the state so that the next diff contains only the changes of the next transaction
+		statedb.Finalise(true, true)
+	}
+	return results, nil
+}
+
+func formatAccountDiffs(diffs []*state.AccountDiff) []*AccountStateDiff {
+	result := make([]*AccountStateDiff, len(diffs))
+	for i, diff := range diffs {
+		r := &AccountStateDiff{
+			Address:  diff.Address,
+			Created:  diff.Created,
+			Suicided: diff.Suicided,
+		}
+		if diff.BalanceChanged {
+			r.PrevBalance, r.Balance = (*hexutil.Big)(diff.PrevBalance), (*hexutil.Big)(diff.Balance)
+		}
+		if diff.NonceChanged {
+			prev, nonce := hexutil.Uint64(diff.PrevNonce), hexutil.Uint64(diff.Nonce)
+			r.PrevNonce, r.Nonce = &prev, &nonce
+		}
+		if diff.CodeChanged {
+			prev, codeHash := diff.PrevCodeHash, diff.CodeHash
+			r.PrevCodeHash, r.CodeHash = &prev, &codeHash
+		}
+		for _, s := range diff.Storage {
+			r.Storage = append(r.Storage, StorageStateDiff{Key: s.Key, Prev: s.Prev, Value: s.Value})
+		}
+		result[i] = r
+	}
+	return result
+}
@@ -20,13 +20,28 @@
 package cn
 
 import (
+	"context"
+	"math/big"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/golang/mock/gomock"
+	"github.com/klaytn/klaytn/blockchain"
 	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
 	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/fork"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/node/cn/mocks"
+	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
 )
 
 var dumper = spew.ConfigState{Indent: "    "}
@@ -34,7 +49,7 @@ var dumper = spew.ConfigState{Indent: "    "}
 func TestStorageRangeAt(t *testing.T) {
 	// Create a state where account 0x010000... has a few storage entries.
 	var (
-		state, _ = state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()))
+		state, _ = state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
 		addr     = common.Address{0x01}
 		keys     = []common.Hash{ // hashes of Keys of storage
 			common.HexToHash("340dd630ad21bf010b4e676dbfa9ba9a02175262d1fa356232cfde6cb5b47ef2"),
@@ -91,3 +106,210 @@ func TestStorageRangeAt(t *testing.T) {
 		}
 	}
 }
+
+// TestPrivateDebugAPI_StorageRangeAt tests if the storage is retrieved page by page at the state
+// where the preceding transactions of the block are replayed.
+func TestPrivateDebugAPI_StorageRangeAt(t *testing.T) {
+	mockCtrl, api, mockEngine, mockBlockChain, _ := createCNMocks(t)
+	defer mockCtrl.Finish()
+	api.config = params.TestChainConfig
+	fork.SetHardForkBlockNumberConfig(params.TestChainConfig)
+
+	// The contract stores 5 at the slot 0 when it is called
+	var (
+		key, _   = crypto.GenerateKey()
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0x2000")
+		slots    = []common.Hash{{0x01}, {0x02}, {0x03}}
+	)
+	db := state.NewDatabase(database.NewMemoryDBManager())
+	statedb, err := state.New(common.Hash{}, db, nil)
+	assert.NoError(t, err)
+	statedb.AddBalance(sender, big.NewInt(params.KLAY))
+	assert.NoError(t, statedb.SetCode(contract, common.FromHex("0x600560005500")))
+	for _, slot := range slots {
+		statedb.SetState(contract, slot, slot)
+	}
+	root, err := statedb.Commit(true)
+	assert.NoError(t, err)
+
+	parent := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Root: root, Time: big.NewInt(1), BlockScore: big.NewInt(1)})
+	signer := types.MakeSigner(params.TestChainConfig, big.NewInt(2))
+	call, err := types.SignTx(types.NewTransaction(0, contract, big.NewInt(0), 100000, big.NewInt(0), nil), signer, key)
+	assert.NoError(t, err)
+	transfer, err := types.SignTx(types.NewTransaction(1, sender, big.NewInt(1), params.TxGas, big.NewInt(0), nil), signer, key)
+	assert.NoError(t, err)
+	header := &types.Header{Number: big.NewInt(2), ParentHash: parent.Hash(), Time: big.NewInt(2), BlockScore: big.NewInt(1)}
+	block := types.NewBlockWithHeader(header).WithBody(types.Transactions{call, transfer})
+
+	mockBlockChain.EXPECT().GetBlockByHash(block.Hash()).Return(block).AnyTimes()
+	mockBlockChain.EXPECT().GetBlock(parent.Hash(), parent.NumberU64()).Return(parent).AnyTimes()
+	mockBlockChain.EXPECT().StateAtWithGCLock(root).Return(nil, expectedErr).AnyTimes()
+	mockBlockChain.EXPECT().StateAt(root).DoAndReturn(func(root common.Hash) (*state.StateDB, error) {
+		return state.New(root, db, nil)
+	}).AnyTimes()
+	mockBlockChain.EXPECT().Engine().Return(mockEngine).AnyTimes()
+	mockEngine.EXPECT().Author(gomock.Any()).Return(common.Address{}, nil).AnyTimes()
+
+	// Before the call, the slot 0 is not set yet
+	result, err := api.StorageRangeAt(context.Background(), block.Hash(), 0, contract, nil, 10)
+	assert.NoError(t, err)
+	assert.Len(t, result.Storage, 3)
+	assert.Nil(t, result.NextKey)
+
+	// After the call is replayed, the storage is retrieved page by page with NextKey
+	storage := storageMap{}
+	var start hexutil.Bytes
+	for pages := 0; ; pages++ {
+		assert.True(t, pages < 2)
+		result, err = api.StorageRangeAt(context.Background(), block.Hash(), 1, contract, start, 2)
+		assert.NoError(t, err)
+		for hash, entry := range result.Storage {
+			storage[hash] = entry
+		}
+		if result.NextKey == nil {
+			break
+		}
+		start = result.NextKey.Bytes()
+	}
+	assert.Len(t, storage, 4)
+	assert.Equal(t, common.BigToHash(big.NewInt(5)), storage[crypto.Keccak256Hash(common.Hash{}.Bytes())].Value)
+
+	_, err = api.StorageRangeAt(context.Background(), block.Hash(), 2, contract, nil, 10)
+	assert.Error(t, err)
+}
+
+// TestPrivateDebugAPI_SetHead tests if the blocks after the target are deleted with their receipts
+// and tx lookup entries, and the rewind is refused while the node is mining.
+func TestPrivateDebugAPI_SetHead(t *testing.T) {
+	var (
+		mockCtrl = gomock.NewController(t)
+		miner    = mocks.NewMockMiner(mockCtrl)
+		key, _   = crypto.GenerateKey()
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		db       = database.NewMemoryDBManager()
+		gspec    = &blockchain.Genesis{Config: params.TestChainConfig, Alloc: blockchain.GenesisAlloc{addr: {Balance: big.NewInt(10000000000000)}}}
+		genesis  = gspec.MustCommit(db)
+		signer   = types.NewEIP155Signer(gspec.Config.ChainID)
+		engine   = gxhash.NewFaker()
+	)
+	defer mockCtrl.Finish()
+
+	bc, err := blockchain.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+
+	blocks, _ := blockchain.GenerateChain(gspec.Config, genesis, engine, db, 5, func(i int, gen *blockchain.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), common.Address{0x01}, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+		gen.AddTx(tx)
+	})
+	if _, err := bc.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	api := NewPrivateDebugAPI(gspec.Config, &CN{blockchain: bc, chainDB: db, miner: miner, chainImporter: newChainImporter(bc)})
+
+	miner.EXPECT().Mining().Return(true)
+	_, err = api.SetHead(2)
+	assert.Error(t, err)
+	assert.Equal(t, uint64(5), bc.CurrentBlock().NumberU64())
+
+	miner.EXPECT().Mining().Return(false)
+	head, err := api.SetHead(2)
+	assert.NoError(t, err)
+	assert.Equal(t, hexutil.Uint64(2), head)
+	assert.Equal(t, blocks[1].Hash(), bc.CurrentBlock().Hash())
+	assert.Equal(t, blocks[1].Hash(), bc.CurrentHeader().Hash())
+	for _, block := range blocks[2:] {
+		assert.Nil(t, bc.GetHeaderByNumber(block.NumberU64()))
+		assert.Nil(t, db.ReadBody(block.Hash(), block.NumberU64()))
+		assert.Nil(t, db.ReadReceipts(block.Hash(), block.NumberU64()))
+		tx, _, _, _ := db.ReadTxAndLookupInfo(block.Transactions()[0].Hash())
+		assert.Nil(t, tx)
+	}
+	tx, _, _, _ := db.ReadTxAndLookupInfo(blocks[1].Transactions()[0].Hash())
+	assert.NotNil(t, tx)
+
+	// The target should be below the current block
+	_, err = api.SetHead(2)
+	assert.Error(t, err)
+}
+
+// TestPrivateDebugAPI_AccountRange tests if all the accounts are retrieved page by page with the session
+// and if the code and the storage are excluded by noCode and noStorage.
+func TestPrivateDebugAPI_AccountRange(t *testing.T) {
+	mockCtrl, api, _, mockBlockChain, _ := createCNMocks(t)
+	defer mockCtrl.Finish()
+
+	var (
+		contract = common.HexToAddress("0x2000")
+		code     = common.FromHex("0x600560005500")
+		slot     = common.Hash{0x01}
+	)
+	db := state.NewDatabase(database.NewMemoryDBManager())
+	statedb, err := state.New(common.Hash{}, db, nil)
+	assert.NoError(t, err)
+	for i := int64(1); i <= 4; i++ {
+		statedb.AddBalance(common.BigToAddress(big.NewInt(i)), big.NewInt(i))
+	}
+	assert.NoError(t, statedb.SetCode(contract, code))
+	statedb.SetState(contract, slot, slot)
+	root, err := statedb.Commit(true)
+	assert.NoError(t, err)
+	db.TrieDB().Reference(root, common.Hash{}) // referenced by the blockchain
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Root: root})
+	mockBlockChain.EXPECT().GetBlockByHash(block.Hash()).Return(block).AnyTimes()
+	mockBlockChain.EXPECT().StateCache().Return(db).AnyTimes()
+	api.cn.APIBackend = &CNAPIBackend{cn: api.cn}
+	api.cn.stateSessions = newStateSessions(db, time.Minute)
+	defer api.cn.stateSessions.closeAll()
+
+	blockNrOrHash := rpc.NewBlockNumberOrHashWithHash(block.Hash(), false)
+
+	// All the accounts are returned at once without a session
+	all, err := api.AccountRange(context.Background(), blockNrOrHash, nil, 0, false, false, nil)
+	assert.NoError(t, err)
+	assert.Len(t, all.Accounts, 5)
+	assert.Nil(t, all.Next)
+	assert.Equal(t, "", all.Session)
+
+	contractAccount := all.Accounts[common.Bytes2Hex(contract.Bytes())]
+	assert.Equal(t, common.Bytes2Hex(code), contractAccount.Code)
+	assert.Contains(t, contractAccount.Storage, common.Bytes2Hex(slot.Bytes()))
+
+	// The accounts are returned page by page until the next key is nil
+	var (
+		start    hexutil.Bytes
+		session  *string
+		accounts = make(map[string]state.DumpAccount)
+		pages    int
+	)
+	for {
+		result, err := api.AccountRange(context.Background(), blockNrOrHash, start, 2, true, true, session)
+		assert.NoError(t, err)
+		assert.NotEqual(t, "", result.Session)
+		for addr, account := range result.Accounts {
+			assert.NotContains(t, accounts, addr)
+			accounts[addr] = account
+		}
+		pages++
+		if result.Next == nil {
+			break
+		}
+		start, session = result.Next, &result.Session
+	}
+	assert.Equal(t, 3, pages)
+	assert.Len(t, accounts, len(all.Accounts))
+
+	// The code and the storage are excluded, but the other fields are the same
+	for addr, account := range accounts {
+		want := all.Accounts[addr]
+		assert.Equal(t, common.Bytes2Hex([]byte{}), account.Code)
+		assert.Empty(t, account.Storage)
+		want.Code, want.Storage = account.Code, account.Storage
+		assert.Equal(t, want, account)
+	}
+	assert.NoError(t, api.CloseStateSession(*session))
+}
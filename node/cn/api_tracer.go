@@ -27,6 +27,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"os"
 	"reflect"
 	"runtime"
@@ -57,10 +58,6 @@ const (
 	// and reexecute to produce missing historical state necessary to run a specific
 	// trace.
 	defaultTraceReexec = uint64(128)
-
-	// fastCallTracer is the go-version callTracer which is lighter and faster than
-	// Javascript version.
-	fastCallTracer = "fastCallTracer"
 )
 
 // TraceConfig holds extra parameters to trace functions.
@@ -71,6 +68,13 @@ type TraceConfig struct {
 	Reexec  *uint64
 }
 
+// TraceCallConfig holds extra parameters to trace a call.
+type TraceCallConfig struct {
+	TraceConfig
+	StateOverrides *klaytnapi.StateOverride
+	BlockOverrides *klaytnapi.BlockOverrides
+}
+
 // StdTraceConfig holds extra parameters to standard-json trace functions.
 type StdTraceConfig struct {
 	*vm.LogConfig
@@ -111,6 +115,9 @@ type txTraceTask struct {
 
 // TraceChain returns the structured logs created during the execution of EVM
 // between two blocks (excluding start) and returns them as a JSON object.
+// The results are streamed block by block over a subscription, and the state
+// of the start block is regenerated if it is not available. Tracing stops when
+// the subscription is cancelled or the connection is closed.
 func (api *PrivateDebugAPI) TraceChain(ctx context.Context, start, end rpc.BlockNumber, config *TraceConfig) (*rpc.Subscription, error) {
 	// Fetch the block interval that we want to trace
 	var from, to *types.Block
@@ -165,7 +172,7 @@ func (api *PrivateDebugAPI) traceChain(ctx context.Context, start, end *types.Bl
 			return nil, fmt.Errorf("parent block #%d not found", number-1)
 		}
 	}
-	statedb, err := state.New(start.Root(), database)
+	statedb, err := state.New(start.Root(), database, nil)
 	if err != nil {
 		// If the starting state is missing, allow some number of blocks to be reexecuted
 		reexec := defaultTraceReexec
@@ -178,7 +185,7 @@ func (api *PrivateDebugAPI) traceChain(ctx context.Context, start, end *types.Bl
 			if start == nil {
 				break
 			}
-			if statedb, err = state.New(start.Root(), database); err == nil {
+			if statedb, err = state.New(start.Root(), database, nil); err == nil {
 				break
 			}
 		}
@@ -224,7 +231,12 @@ func (api *PrivateDebugAPI) traceChain(ctx context.Context, start, end *types.Bl
 
 					vmctx := blockchain.NewEVMContext(msg, task.block.Header(), api.cn.blockchain, nil)
 
-					res, err := api.traceTx(ctx, msg, vmctx, task.statedb, config)
+					txCtx := &tracers.Context{
+						BlockHash: task.block.Hash(),
+						TxIndex:   i,
+						TxHash:    tx.Hash(),
+					}
+					res, err := api.traceTx(ctx, msg, txCtx, vmctx, task.statedb, config)
 					if err != nil {
 						task.results[i] = &txTraceResult{TxHash: tx.Hash(), Error: err.Error()}
 						logger.Warn("Tracing failed", "hash", tx.Hash(), "block", task.block.NumberU64(), "err", err)
@@ -238,6 +250,8 @@ func (api *PrivateDebugAPI) traceChain(ctx context.Context, start, end *types.Bl
 				case results <- task:
 				case <-notifier.Closed():
 					return
+				case <-sub.Err():
+					return
 				}
 			}
 		}()
@@ -270,10 +284,12 @@ func (api *PrivateDebugAPI) traceChain(ctx context.Context, start, end *types.Bl
 		}()
 		// Feed all the blocks both into the tracer, as well as fast process concurrently
 		for number = start.NumberU64() + 1; number <= end.NumberU64(); number++ {
-			// Stop tracing if interruption was requested
+			// Stop tracing if the connection is closed or the subscription is cancelled
 			select {
 			case <-notifier.Closed():
 				return
+			case <-sub.Err():
+				return
 			default:
 			}
 			// Print progress logs if long enough time elapsed
@@ -300,6 +316,8 @@ func (api *PrivateDebugAPI) traceChain(ctx context.Context, start, end *types.Bl
 				case tasks <- &blockTraceTask{statedb: statedb.Copy(), block: block, rootref: proot, results: make([]*txTraceResult, len(txs))}:
 				case <-notifier.Closed():
 					return
+				case <-sub.Err():
+					return
 				}
 				traced += uint64(len(txs))
 			}
@@ -457,6 +475,64 @@ func (api *PrivateDebugAPI) StandardTraceBadBlockToFile(ctx context.Context, has
 	return nil, fmt.Errorf("bad block %#x not found", hash)
 }
 
+// IntermediateRoots replays the transactions of the block, which can be a bad block, and returns the
+// state root after each transaction. The last root differs from the state root of the block since
+// the block rewards are distributed after the transactions.
+func (api *PrivateDebugAPI) IntermediateRoots(ctx context.Context, hash common.Hash, config *TraceConfig) ([]common.Hash, error) {
+	block := api.cn.blockchain.GetBlockByHash(hash)
+	if block == nil {
+		// Check the bad blocks since the roots are mainly used to diagnose the state root mismatches
+		if blocks, err := api.cn.blockchain.BadBlocks(); err == nil {
+			for _, bad := range blocks {
+				if bad.Hash == hash {
+					block = bad.Block
+					break
+				}
+			}
+		}
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %#x not found", hash)
+	}
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis is not traceable")
+	}
+	parent := api.cn.blockchain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent %#x not found", block.ParentHash())
+	}
+	reexec := defaultTraceReexec
+	if config != nil && config.Reexec != nil {
+		reexec = *config.Reexec
+	}
+	statedb, deferFn, err := api.stateAt(parent, reexec)
+	defer deferFn()
+	if err != nil {
+		return nil, fmt.Errorf("can not get the state of block %#x: %v", parent.Root(), err)
+	}
+
+	var (
+		signer = types.MakeSigner(api.config, block.Number())
+		roots  = make([]common.Hash, 0, len(block.Transactions()))
+	)
+	for _, tx := range block.Transactions() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		msg, err := tx.AsMessageWithAccountKeyPicker(signer, statedb, block.NumberU64())
+		if err != nil {
+			return nil, fmt.Errorf("transaction %#x failed: %v", tx.Hash(), err)
+		}
+		vmctx := blockchain.NewEVMContext(msg, block.Header(), api.cn.blockchain, nil)
+		vmenv := vm.NewEVM(vmctx, statedb, api.config, &vm.Config{})
+		if _, _, kerr := blockchain.ApplyMessage(vmenv, msg); kerr.ErrTxInvalid != nil {
+			return nil, fmt.Errorf("transaction %#x failed: %v", tx.Hash(), kerr.ErrTxInvalid)
+		}
+		roots = append(roots, statedb.IntermediateRoot(true))
+	}
+	return roots, nil
+}
+
 // traceBlock configures a new tracer according to the provided configuration, and
 // executes all the transactions contained within. The return value will be one item
 // per transaction, dependent on the requestd tracer.
@@ -482,7 +558,8 @@ func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block,
 
 	// Execute all the transaction contained within the block concurrently
 	var (
-		signer = types.MakeSigner(api.config, block.Number())
+		signer    = types.MakeSigner(api.config, block.Number())
+		blockHash = block.Hash()
 
 		txs     = block.Transactions()
 		results = make([]*txTraceResult, len(txs))
@@ -501,6 +578,10 @@ func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block,
 
 			// Fetch and execute the next transaction trace tasks
 			for task := range jobs {
+				if err := ctx.Err(); err != nil {
+					results[task.index] = &txTraceResult{TxHash: txs[task.index].Hash(), Error: err.Error()}
+					continue
+				}
 				msg, err := txs[task.index].AsMessageWithAccountKeyPicker(signer, task.statedb, block.NumberU64())
 				if err != nil {
 					logger.Warn("Tracing failed", "tx idx", task.index, "block", block.NumberU64(), "err", err)
@@ -510,7 +591,12 @@ func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block,
 
 				vmctx := blockchain.NewEVMContext(msg, block.Header(), api.cn.blockchain, nil)
 
-				res, err := api.traceTx(ctx, msg, vmctx, task.statedb, config)
+				txCtx := &tracers.Context{
+					BlockHash: blockHash,
+					TxIndex:   task.index,
+					TxHash:    txs[task.index].Hash(),
+				}
+				res, err := api.traceTx(ctx, msg, txCtx, vmctx, task.statedb, config)
 				if err != nil {
 					results[task.index] = &txTraceResult{TxHash: txs[task.index].Hash(), Error: err.Error()}
 					continue
@@ -522,6 +608,11 @@ func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block,
 	// Feed the transactions into the tracers and return
 	var failed error
 	for i, tx := range txs {
+		// Stop tracing the remaining transactions if the request is cancelled
+		if err := ctx.Err(); err != nil {
+			failed = err
+			break
+		}
 		// Send the trace task over for execution
 		jobs <- &txTraceTask{statedb: statedb.Copy(), index: i}
 
@@ -669,68 +760,7 @@ func containsTx(block *types.Block, hash common.Hash) bool {
 // computeStateDB retrieves the state database associated with a certain block.
 // A number of blocks are attempted to be reexecuted to generate the desired state.
 func (api *PrivateDebugAPI) computeStateDB(block *types.Block, reexec uint64) (*state.StateDB, error) {
-	// try to reexec blocks until we find a state or reach our limit
-	origin := block.NumberU64()
-	database := state.NewDatabaseWithExistingCache(api.cn.ChainDB(), api.cn.blockchain.StateCache().TrieDB().TrieNodeCache())
-
-	var statedb *state.StateDB
-	var err error
-
-	for i := uint64(0); i < reexec; i++ {
-		if statedb, err = state.New(block.Root(), database); err == nil {
-			break
-		}
-		blockNumber := block.NumberU64()
-		block = api.cn.blockchain.GetBlock(block.ParentHash(), blockNumber-1)
-		if block == nil {
-			return nil, fmt.Errorf("block #%d not found", blockNumber-1)
-		}
-	}
-	if err != nil {
-		switch err.(type) {
-		case *statedb2.MissingNodeError:
-			return nil, fmt.Errorf("required historical state unavailable (reexec=%d)", reexec)
-		default:
-			return nil, err
-		}
-	}
-	// State was available at historical point, regenerate
-	var (
-		start  = time.Now()
-		logged time.Time
-		proot  common.Hash
-	)
-	for block.NumberU64() < origin {
-		// Print progress logs if long enough time elapsed
-		if time.Since(logged) > log.StatsReportLimit {
-			logger.Info("Regenerating historical state", "block", block.NumberU64()+1, "target", origin, "remaining", origin-block.NumberU64()-1, "elapsed", time.Since(start))
-			logged = time.Now()
-		}
-		// Retrieve the next block to regenerate and process it
-		if block = api.cn.blockchain.GetBlockByNumber(block.NumberU64() + 1); block == nil {
-			return nil, fmt.Errorf("block #%d not found", block.NumberU64()+1)
-		}
-		_, _, _, _, _, err := api.cn.blockchain.Processor().Process(block, statedb, vm.Config{})
-		if err != nil {
-			return nil, fmt.Errorf("processing block %d failed: %v", block.NumberU64(), err)
-		}
-		// Finalize the state so any modifications are written to the trie
-		root, err := statedb.Commit(true)
-		if err != nil {
-			return nil, err
-		}
-		if err := statedb.Reset(root); err != nil {
-			return nil, fmt.Errorf("state reset after block %d failed: %v", block.NumberU64(), err)
-		}
-		database.TrieDB().Reference(root, common.Hash{})
-		if !common.EmptyHash(proot) {
-			database.TrieDB().Dereference(proot)
-		}
-		proot = root
-	}
-	nodeSize, preimageSize := database.TrieDB().Size()
-	logger.Info("Historical state regenerated", "block", block.NumberU64(), "elapsed", time.Since(start), "nodeSize", nodeSize, "preimageSize", preimageSize)
-	return statedb, nil
+	return api.cn.stateAtBlock(block, reexec)
 }
 
 // TraceTransaction returns the structured logs created during the execution of EVM
@@ -749,14 +779,73 @@ func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, hash common.Ha
 	if err != nil {
 		return nil, err
 	}
+	txCtx := &tracers.Context{
+		BlockHash: blockHash,
+		TxIndex:   int(index),
+		TxHash:    hash,
+	}
 	// Trace the transaction and return
-	return api.traceTx(ctx, msg, vmctx, statedb, config)
+	return api.traceTx(ctx, msg, txCtx, vmctx, statedb, config)
+}
+
+// TraceCall traces the call message on top of the state of the given block, as if it were
+// executed in the block. The state of the accounts and the header of the block can be overridden
+// by the config, and the call is traced in the same way as TraceTransaction.
+func (api *PrivateDebugAPI) TraceCall(ctx context.Context, args klaytnapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) (interface{}, error) {
+	// Fetch the block on which the call is traced
+	var block *types.Block
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		block = api.cn.blockchain.GetBlockByHash(hash)
+	} else if number, ok := blockNrOrHash.Number(); ok {
+		switch number {
+		case rpc.PendingBlockNumber:
+			return nil, kerrors.ErrPendingBlockNotSupported
+		case rpc.LatestBlockNumber:
+			block = api.cn.blockchain.CurrentBlock()
+		default:
+			block = api.cn.blockchain.GetBlockByNumber(uint64(number))
+		}
+	}
+	if block == nil {
+		str, _ := blockNrOrHash.NumberOrHashString()
+		return nil, fmt.Errorf("block %v not found", str)
+	}
+	reexec := defaultTraceReexec
+	if config != nil && config.Reexec != nil {
+		reexec = *config.Reexec
+	}
+	statedb, deferFn, err := api.stateAt(block, reexec)
+	defer deferFn()
+	if err != nil {
+		return nil, fmt.Errorf("can not get the state of block %#x: %v", block.Root(), err)
+	}
+
+	// Apply the overrides of the state and the header
+	header := types.CopyHeader(block.Header())
+	var traceConfig *TraceConfig
+	if config != nil {
+		if err := config.StateOverrides.Apply(statedb); err != nil {
+			return nil, err
+		}
+		config.BlockOverrides.Apply(header)
+		traceConfig = &config.TraceConfig
+	}
+
+	msg, err := args.ToMessage(api.cn.config.RPCGasCap, api.config.Rules(header.Number))
+	if err != nil {
+		return nil, err
+	}
+	// The sender is credited with the gas fee as the calls of the klay namespace
+	statedb.AddBalance(msg.ValidatedSender(), new(big.Int).Mul(new(big.Int).SetUint64(msg.Gas()), msg.GasPrice()))
+
+	vmctx := blockchain.NewEVMContext(msg, header, api.cn.blockchain, nil)
+	return api.traceTx(ctx, msg, new(tracers.Context), vmctx, statedb, traceConfig)
 }
 
 // traceTx configures a new tracer according to the provided configuration, and
 // executes the given message in the provided environment. The return value will
-// be tracer dependent.
-func (api *PrivateDebugAPI) traceTx(ctx context.Context, message blockchain.Message, vmctx vm.Context, statedb *state.StateDB, config *TraceConfig) (interface{}, error) {
+// be tracer dependent. txCtx is exposed to the JavaScript tracer through its ctx object.
+func (api *PrivateDebugAPI) traceTx(ctx context.Context, message blockchain.Message, txCtx *tracers.Context, vmctx vm.Context, statedb *state.StateDB, config *TraceConfig) (interface{}, error) {
 	// Assemble the structured logger or the JavaScript tracer
 	var (
 		tracer vm.Tracer
@@ -772,11 +861,11 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message blockchain.Mess
 			}
 		}
 
-		if *config.Tracer == fastCallTracer {
-			tracer = vm.NewInternalTxTracer()
+		if native, ok := tracers.NewNativeTracer(*config.Tracer); ok {
+			tracer = native
 		} else {
 			// Constuct the JavaScript tracer to execute with
-			if tracer, err = tracers.New(*config.Tracer); err != nil {
+			if tracer, err = tracers.New(*config.Tracer, txCtx); err != nil {
 				return nil, err
 			}
 		}
@@ -787,7 +876,7 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message blockchain.Mess
 			switch t := tracer.(type) {
 			case *tracers.Tracer:
 				t.Stop(errors.New("execution timeout"))
-			case *vm.InternalTxTracer:
+			case tracers.NativeTracer:
 				t.Stop(errors.New("execution timeout"))
 			default:
 				logger.Warn("unknown tracer type", "type", reflect.TypeOf(t).String())
@@ -820,7 +909,7 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message blockchain.Mess
 
 	case *tracers.Tracer:
 		return tracer.GetResult()
-	case *vm.InternalTxTracer:
+	case tracers.NativeTracer:
 		return tracer.GetResult()
 
 	default:
@@ -895,7 +984,7 @@ func (api *PrivateDebugAPI) computeTxEnv(blockHash common.Hash, txIndex int, ree
 		// Not yet the searched for transaction, execute on top of the current state
 		vmenv := vm.NewEVM(context, statedb, api.config, &vm.Config{})
 		if _, _, kerr := blockchain.ApplyMessage(vmenv, msg); kerr.ErrTxInvalid != nil {
-			return nil, vm.Context{}, nil, fmt.Errorf("transaction %#x failed: %v", tx.Hash(), err)
+			return nil, vm.Context{}, nil, fmt.Errorf("transaction %#x failed: %v", tx.Hash(), kerr.ErrTxInvalid)
 		}
 		// Ensure any modifications are committed to the state
 		statedb.Finalise(true, true)
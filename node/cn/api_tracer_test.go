@@ -19,16 +19,27 @@ package cn
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	klaytnapi "github.com/klaytn/klaytn/api"
 	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/consensus/gxhash"
 	mocks2 "github.com/klaytn/klaytn/consensus/mocks"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/fork"
 	"github.com/klaytn/klaytn/kerrors"
 	"github.com/klaytn/klaytn/networks/rpc"
 	mocks3 "github.com/klaytn/klaytn/node/cn/mocks"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
 	"github.com/klaytn/klaytn/work/mocks"
 	"github.com/stretchr/testify/assert"
 )
@@ -93,6 +104,70 @@ func TestPrivateDebugAPI_TraceChain(t *testing.T) {
 	}
 }
 
+// TestPrivateDebugAPI_TraceChainSubscription tests if the traces of the blocks are streamed in order
+// over a subscription.
+func TestPrivateDebugAPI_TraceChainSubscription(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		db      = database.NewMemoryDBManager()
+		gspec   = &blockchain.Genesis{Config: params.TestChainConfig, Alloc: blockchain.GenesisAlloc{addr: {Balance: big.NewInt(10000000000000)}}}
+		genesis = gspec.MustCommit(db)
+		signer  = types.NewEIP155Signer(gspec.Config.ChainID)
+		engine  = gxhash.NewFaker()
+	)
+	bc, err := blockchain.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+
+	// Every block but block 3 has a transaction
+	blocks, _ := blockchain.GenerateChain(gspec.Config, genesis, engine, db, 5, func(i int, gen *blockchain.BlockGen) {
+		if i == 2 {
+			return
+		}
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), common.Address{0x01}, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+		gen.AddTx(tx)
+	})
+	if _, err := bc.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+
+	server := rpc.NewServer()
+	cn := &CN{blockchain: bc, chainDB: db, engine: engine, chainConfig: gspec.Config}
+	if err := server.RegisterName("debug", NewPrivateDebugAPI(gspec.Config, cn)); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	results := make(chan *blockTraceResult, len(blocks))
+	sub, err := client.Subscribe(context.Background(), "debug", results, "traceChain", hexutil.Uint64(1), hexutil.Uint64(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	// The start block is excluded, and the block without transactions is skipped
+	for _, number := range []uint64{2, 4, 5} {
+		select {
+		case result := <-results:
+			block := blocks[number-1]
+			assert.Equal(t, hexutil.Uint64(number), result.Block)
+			assert.Equal(t, block.Hash(), result.Hash)
+			if assert.Len(t, result.Traces, 1) {
+				assert.Equal(t, block.Transactions()[0].Hash(), result.Traces[0].TxHash)
+				assert.Empty(t, result.Traces[0].Error)
+			}
+		case err := <-sub.Err():
+			t.Fatal(err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("block %d is not traced", number)
+		}
+	}
+}
+
 func TestPrivateDebugAPI_TraceBlockByNumber(t *testing.T) {
 	blockNumber := rpc.BlockNumber(123)
 	{
@@ -129,6 +204,142 @@ func TestPrivateDebugAPI_TraceBlockByHash(t *testing.T) {
 	mockCtrl.Finish()
 }
 
+// TestPrivateDebugAPI_TraceBlockCancelled tests if tracing a block stops when the request is cancelled.
+func TestPrivateDebugAPI_TraceBlockCancelled(t *testing.T) {
+	mockCtrl, api, mockEngine, mockBlockChain, _ := createCNMocks(t)
+	defer mockCtrl.Finish()
+	api.config = params.TestChainConfig
+
+	parent := newBlock(122)
+	tx := types.NewTransaction(0, addrs[0], big.NewInt(1), params.TxGas, big.NewInt(0), nil)
+	block := newBlockWithParentHash(123, parent.Hash()).WithBody(types.Transactions{tx})
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
+	assert.NoError(t, err)
+
+	mockEngine.EXPECT().VerifyHeader(mockBlockChain, block.Header(), true).Return(nil)
+	mockBlockChain.EXPECT().GetBlock(parent.Hash(), parent.NumberU64()).Return(parent)
+	mockBlockChain.EXPECT().StateAtWithGCLock(parent.Root()).Return(nil, expectedErr)
+	mockBlockChain.EXPECT().StateAt(parent.Root()).Return(statedb, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := api.traceBlock(ctx, block, nil)
+	assert.Nil(t, results)
+	assert.Equal(t, context.Canceled, err)
+}
+
+// TestPrivateDebugAPI_TraceCall tests if a call is traced with the overridden state and header.
+func TestPrivateDebugAPI_TraceCall(t *testing.T) {
+	mockCtrl, api, mockEngine, mockBlockChain, _ := createCNMocks(t)
+	defer mockCtrl.Finish()
+	api.config = params.TestChainConfig
+	api.cn.config = &Config{}
+
+	header := &types.Header{Number: big.NewInt(123), Time: big.NewInt(1), BlockScore: big.NewInt(1)}
+	block := types.NewBlockWithHeader(header)
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
+	assert.NoError(t, err)
+
+	mockBlockChain.EXPECT().GetBlockByNumber(uint64(123)).Return(block).AnyTimes()
+	mockBlockChain.EXPECT().StateAtWithGCLock(block.Root()).Return(nil, expectedErr).AnyTimes()
+	mockBlockChain.EXPECT().StateAt(block.Root()).DoAndReturn(func(common.Hash) (*state.StateDB, error) {
+		return statedb.Copy(), nil
+	}).AnyTimes()
+	mockBlockChain.EXPECT().Engine().Return(mockEngine).AnyTimes()
+	mockEngine.EXPECT().Author(gomock.Any()).Return(addrs[0], nil).AnyTimes()
+
+	// The contract returns the sum of the storage slot 0, the block number and the block time
+	var (
+		contract = common.HexToAddress("0x2000")
+		code     = hexutil.Bytes(common.FromHex("0x6000544301420160005260206000f3"))
+		args     = klaytnapi.CallArgs{From: addrs[1], To: &contract}
+		number   = rpc.NewBlockNumberOrHashWithNumber(123)
+		config   = &TraceCallConfig{
+			StateOverrides: &klaytnapi.StateOverride{contract: {
+				Code:      &code,
+				StateDiff: &map[common.Hash]common.Hash{{}: common.BigToHash(big.NewInt(1))},
+			}},
+			BlockOverrides: &klaytnapi.BlockOverrides{Number: (*hexutil.Big)(big.NewInt(1000)), Time: (*hexutil.Big)(big.NewInt(20))},
+		}
+	)
+	result, err := api.TraceCall(context.Background(), args, number, config)
+	assert.NoError(t, err)
+	if assert.IsType(t, &klaytnapi.ExecutionResult{}, result) {
+		res := result.(*klaytnapi.ExecutionResult)
+		assert.False(t, res.Failed)
+		assert.Equal(t, fmt.Sprintf("%x", common.BigToHash(big.NewInt(1021))), res.ReturnValue)
+		assert.NotEmpty(t, res.StructLogs)
+	}
+
+	// The contract does not exist without the state overrides
+	result, err = api.TraceCall(context.Background(), args, number, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "", result.(*klaytnapi.ExecutionResult).ReturnValue)
+
+	_, err = api.TraceCall(context.Background(), args, rpc.NewBlockNumberOrHashWithNumber(rpc.PendingBlockNumber), config)
+	assert.Equal(t, kerrors.ErrPendingBlockNotSupported, err)
+}
+
+// TestPrivateDebugAPI_IntermediateRoots tests if the state roots after the transactions are returned.
+func TestPrivateDebugAPI_IntermediateRoots(t *testing.T) {
+	mockCtrl, api, mockEngine, mockBlockChain, _ := createCNMocks(t)
+	defer mockCtrl.Finish()
+	api.config = params.TestChainConfig
+	fork.SetHardForkBlockNumberConfig(params.TestChainConfig)
+
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	db := state.NewDatabase(database.NewMemoryDBManager())
+	statedb, err := state.New(common.Hash{}, db, nil)
+	assert.NoError(t, err)
+	statedb.AddBalance(sender, big.NewInt(params.KLAY))
+	root, err := statedb.Commit(true)
+	assert.NoError(t, err)
+
+	parent := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Root: root, Time: big.NewInt(1), BlockScore: big.NewInt(1)})
+	signer := types.MakeSigner(params.TestChainConfig, big.NewInt(2))
+	var txs types.Transactions
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		tx, err := types.SignTx(types.NewTransaction(nonce, addrs[1], big.NewInt(1), params.TxGas, big.NewInt(0), nil), signer, key)
+		assert.NoError(t, err)
+		txs = append(txs, tx)
+	}
+	header := &types.Header{Number: big.NewInt(2), ParentHash: parent.Hash(), Time: big.NewInt(2), BlockScore: big.NewInt(1)}
+	block := types.NewBlockWithHeader(header).WithBody(txs)
+
+	mockBlockChain.EXPECT().GetBlockByHash(block.Hash()).Return(block).AnyTimes()
+	mockBlockChain.EXPECT().GetBlockByHash(hashes[0]).Return(nil).AnyTimes()
+	mockBlockChain.EXPECT().BadBlocks().Return(nil, nil).AnyTimes()
+	mockBlockChain.EXPECT().GetBlock(parent.Hash(), parent.NumberU64()).Return(parent).AnyTimes()
+	mockBlockChain.EXPECT().StateAtWithGCLock(root).Return(nil, expectedErr).AnyTimes()
+	mockBlockChain.EXPECT().StateAt(root).DoAndReturn(func(root common.Hash) (*state.StateDB, error) {
+		return state.New(root, db, nil)
+	}).AnyTimes()
+	mockBlockChain.EXPECT().Engine().Return(mockEngine).AnyTimes()
+	mockEngine.EXPECT().Author(gomock.Any()).Return(common.Address{}, nil).AnyTimes()
+
+	// The roots are the same as the ones of the transactions applied one by one
+	roots, err := api.IntermediateRoots(context.Background(), block.Hash(), nil)
+	assert.NoError(t, err)
+	if assert.Len(t, roots, 2) {
+		statedb, _ = state.New(root, db, nil)
+		for i := range roots {
+			statedb.AddBalance(addrs[1], big.NewInt(1))
+			statedb.SubBalance(sender, big.NewInt(1))
+			statedb.SetNonce(sender, uint64(i+1))
+			assert.Equal(t, statedb.IntermediateRoot(true), roots[i], "tx %d", i)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = api.IntermediateRoots(ctx, block.Hash(), nil)
+	assert.Equal(t, context.Canceled, err)
+
+	_, err = api.IntermediateRoots(context.Background(), hashes[0], nil)
+	assert.Error(t, err)
+}
+
 func TestPrivateDebugAPI_TraceBlock(t *testing.T) {
 	mockCtrl, api, _, _, _ := createCNMocks(t)
 	sub, err := api.TraceBlock(context.Background(), hexutil.Bytes{}, nil)
@@ -0,0 +1,80 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/kerrors"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+// ExecutionWitnessByNumber re-executes the block on the state of its parent, and returns the
+// RLP-encoded execution witness of the block. The state of the parent should be available.
+func (api *PrivateDebugAPI) ExecutionWitnessByNumber(ctx context.Context, number rpc.BlockNumber) (hexutil.Bytes, error) {
+	var block *types.Block
+
+	switch number {
+	case rpc.PendingBlockNumber:
+		return nil, kerrors.ErrPendingBlockNotSupported
+	case rpc.LatestBlockNumber:
+		block = api.cn.blockchain.CurrentBlock()
+	default:
+		block = api.cn.blockchain.GetBlockByNumber(uint64(number))
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	return api.executionWitness(block)
+}
+
+// ExecutionWitnessByHash re-executes the block on the state of its parent, and returns the
+// RLP-encoded execution witness of the block. The state of the parent should be available.
+func (api *PrivateDebugAPI) ExecutionWitnessByHash(ctx context.Context, hash common.Hash) (hexutil.Bytes, error) {
+	block := api.cn.blockchain.GetBlockByHash(hash)
+	if block == nil {
+		return nil, fmt.Errorf("block #%x not found", hash)
+	}
+	return api.executionWitness(block)
+}
+
+func (api *PrivateDebugAPI) executionWitness(block *types.Block) (hexutil.Bytes, error) {
+	if block.NumberU64() == 0 {
+		return nil, fmt.Errorf("genesis block has no execution witness")
+	}
+	witness, err := api.cn.blockchain.GenerateWitness(block)
+	if err != nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(witness)
+}
+
+// VerifyExecutionWitness re-executes the block of the RLP-encoded execution witness without
+// the local state, and returns an error if the result does not match the block.
+func (api *PrivateDebugAPI) VerifyExecutionWitness(ctx context.Context, blob hexutil.Bytes) error {
+	witness := new(blockchain.Witness)
+	if err := rlp.DecodeBytes(blob, witness); err != nil {
+		return fmt.Errorf("could not decode witness: %v", err)
+	}
+	return api.cn.blockchain.VerifyWitness(witness)
+}
@@ -43,6 +43,7 @@ import (
 	istanbulBackend "github.com/klaytn/klaytn/consensus/istanbul/backend"
 	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/datasync/downloader"
+	"github.com/klaytn/klaytn/datasync/snapshot"
 	"github.com/klaytn/klaytn/event"
 	"github.com/klaytn/klaytn/governance"
 	"github.com/klaytn/klaytn/networks/p2p"
@@ -58,6 +59,9 @@ import (
 )
 
 var errCNLightSync = errors.New("can't run cn.CN in light sync mode")
+var errSupplyNotTracked = errors.New("supply changes are not tracked by the consensus engine")
+var errBodyRetentionNotPN = errors.New("block bodies and receipts can be pruned only on a proxy node")
+var errStorageOwnerIndexingDisabled = errors.New("storage owner indexing is not enabled (--storageownerindexing)")
 
 //go:generate mockgen -destination=node/cn/mocks/lesserver_mock.go -package=mocks github.com/klaytn/klaytn/node/cn LesServer
 type LesServer interface {
@@ -76,6 +80,13 @@ type Miner interface {
 	Mining() bool
 	HashRate() (tot int64)
 	SetExtra(extra []byte) error
+	SetTxOrderingPolicy(policy string) error
+	TxOrderingPolicy() string
+	SetTxBudget(config work.TxBudgetConfig) error
+	TxBudget() work.TxBudgetConfig
+	SetTargetGasLimit(target uint64)
+	TargetGasLimit() uint64
+	GasLimit() uint64
 	Pending() (*types.Block, *state.StateDB)
 	PendingBlock() *types.Block
 }
@@ -118,6 +129,16 @@ type CN struct {
 	bloomIndexer      *blockchain.ChainIndexer       // Bloom indexer operating during block imports
 	closeBloomHandler chan struct{}
 
+	indexRebuilder *indexRebuilder       // Rebuilds the indexes of the stored blocks on request
+	chainImporter  *chainImporter        // Imports the blocks in files in background on request
+	stateSessions  *stateSessions        // States pinned for paginated iterations
+	supplyTracker  *reward.SupplyTracker // Tracks the minted KLAY and the fees of the blocks, nil if not Istanbul
+	totalSupplies  *totalSupplies        // Caches the total supply of KLAY per block
+	abiRegistry    *filters.ABIRegistry  // ABIs of the contracts whose logs are decoded by the filter APIs
+
+	closeRecompression chan struct{}  // Channel aborting the recompression of chain data
+	recompressionWg    sync.WaitGroup // Waits for the recompression before closing chainDB
+
 	APIBackend *CNAPIBackend
 
 	miner    Miner
@@ -177,6 +198,52 @@ func senderTxHashIndexer(db database.DBManager, chainEvent <-chan blockchain.Cha
 	}
 }
 
+// indexBounds has the functions reading and writing the tail and the head of an index stored in
+// the database. The head functions are nil if the index does not keep its head.
+type indexBounds struct {
+	readTail  func() (uint64, error)
+	writeTail func(blockNum uint64) error
+	readHead  func() (uint64, error)
+	writeHead func(blockNum uint64) error
+}
+
+// indexerFunc runs an indexer from the next block to be indexed up to the current block, receiving
+// the chain events and the chain reorg events.
+type indexerFunc func(next, current uint64,
+	chainEvent <-chan blockchain.ChainEvent, chainSub event.Subscription,
+	reorgEvent <-chan blockchain.ChainReorgEvent, reorgSub event.Subscription)
+
+// startIndexer starts an indexer subscribing the chain events and the chain reorg events of bc.
+// The blocks after the current block are indexed if the index is enabled for the first time, and
+// the indexer resumes from the block after the stored head otherwise.
+func startIndexer(bc work.BlockChain, bounds indexBounds, indexer indexerFunc) error {
+	current := bc.CurrentBlock().NumberU64()
+	if tail, err := bounds.readTail(); err != nil {
+		return err
+	} else if tail == 0 {
+		if err := bounds.writeTail(current + 1); err != nil {
+			return err
+		}
+		if bounds.writeHead != nil {
+			if err := bounds.writeHead(current); err != nil {
+				return err
+			}
+		}
+	}
+	next := current + 1
+	if bounds.readHead != nil {
+		head, err := bounds.readHead()
+		if err != nil {
+			return err
+		}
+		next = head + 1
+	}
+	chainCh := make(chan blockchain.ChainEvent, 255)
+	reorgCh := make(chan blockchain.ChainReorgEvent, 16)
+	go indexer(next, current, chainCh, bc.SubscribeChainEvent(chainCh), reorgCh, bc.SubscribeChainReorgEvent(reorgCh))
+	return nil
+}
+
 func checkSyncMode(config *Config) error {
 	if !config.SyncMode.IsValid() {
 		return fmt.Errorf("invalid sync mode %d", config.SyncMode)
@@ -203,8 +270,29 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 		return nil, err
 	}
 
+	var snapshotManifest *snapshot.Manifest
+	if config.SnapshotURL != "" {
+		dir := ctx.ResolvePath("chaindata")
+		if dir == "" || config.DBType == database.MemoryDB || config.DBType == database.DynamoDB {
+			return nil, fmt.Errorf("snapshot bootstrap requires a file-based database in the data directory (dbtype: %s)", config.DBType)
+		}
+		manifest, err := snapshot.Bootstrap(&snapshot.Config{URL: config.SnapshotURL, Signer: config.SnapshotSigner}, dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bootstrap from the snapshot: %v", err)
+		}
+		snapshotManifest = manifest
+	}
+
 	chainDB := CreateDB(ctx, config, "chaindata")
 
+	// The head of the unpacked snapshot should be the signed one, then the rest is synced from the peers
+	if snapshotManifest != nil {
+		if err := snapshotManifest.VerifyHead(chainDB); err != nil {
+			chainDB.Close()
+			return nil, err
+		}
+	}
+
 	chainConfig, genesisHash, genesisErr := blockchain.SetupGenesisBlock(chainDB, config.Genesis, config.NetworkId, config.IsPrivate, false)
 	if _, ok := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !ok {
 		return nil, genesisErr
@@ -217,6 +305,9 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 	config.GasPrice = new(big.Int).SetUint64(chainConfig.UnitPrice)
 
 	logger.Info("Initialised chain configuration", "config", chainConfig)
+	for _, issue := range auditChainConfig(config.NetworkId, config.IsPrivate, chainConfig) {
+		logger.Warn("Inconsistent chain ID configuration", "issue", issue)
+	}
 	governance := governance.NewGovernanceInitialize(chainConfig, chainDB)
 
 	cn := &CN{
@@ -233,6 +324,8 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 		bloomIndexer:      NewBloomIndexer(chainDB, params.BloomBitsBlocks),
 		closeBloomHandler: make(chan struct{}),
 		governance:        governance,
+
+		closeRecompression: make(chan struct{}),
 	}
 
 	// istanbul BFT. Derive and set node's address using nodekey
@@ -251,8 +344,25 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 		vmConfig    = config.getVMConfig()
 		cacheConfig = &blockchain.CacheConfig{ArchiveMode: config.NoPruning, CacheSize: config.TrieCacheSize,
 			BlockInterval: config.TrieBlockInterval, TriesInMemory: config.TriesInMemory,
-			TrieNodeCacheConfig: &config.TrieNodeCacheConfig, SenderTxHashIndexing: config.SenderTxHashIndexing}
+			TrieNodeCacheConfig: &config.TrieNodeCacheConfig, SenderTxHashIndexing: config.SenderTxHashIndexing,
+			ParallelTxExecution: config.ParallelTxExecution, ParallelTxWorkers: config.ParallelTxWorkers,
+			TxLookupLimit: config.TxLookupLimit, BodyRetention: config.BodyRetention,
+			AncientThreshold:     config.AncientThreshold,
+			StorageOwnerIndexing: config.StorageOwnerIndexing,
+			SnapshotCacheSize:    config.StateSnapshotCacheSize, SnapshotAsyncGen: true,
+			LivePruningInterval: config.StateLivePruningInterval, LivePruningBloomSize: config.StatePruningBloomSize}
 	)
+	if config.BodyRetention != 0 && ctx.NodeType() != common.PROXYNODE {
+		return nil, errBodyRetentionNotPN
+	}
+	if err := blockchain.ValidateBodyRetention(config.BodyRetention); err != nil {
+		return nil, err
+	}
+	if config.AncientDir != "" {
+		if err := blockchain.ValidateAncientThreshold(config.AncientThreshold); err != nil {
+			return nil, err
+		}
+	}
 
 	bc, err := blockchain.NewBlockChain(chainDB, cacheConfig, cn.chainConfig, cn.engine, vmConfig)
 	if err != nil {
@@ -276,6 +386,57 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 		go senderTxHashIndexer(chainDB, ch, chainEventSubscription)
 	}
 
+	if config.FeePayerIndexing {
+		bounds := indexBounds{readTail: chainDB.ReadFeePayerIndexTail, writeTail: chainDB.WriteFeePayerIndexTail}
+		if err := startIndexer(cn.blockchain, bounds, func(_, _ uint64,
+			chainCh <-chan blockchain.ChainEvent, chainSub event.Subscription,
+			reorgCh <-chan blockchain.ChainReorgEvent, reorgSub event.Subscription) {
+			feePayerIndexer(chainDB, chainCh, chainSub, reorgCh, reorgSub)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.BalanceHistoryIndexing {
+		bounds := indexBounds{readTail: chainDB.ReadBalanceIndexTail, writeTail: chainDB.WriteBalanceIndexTail}
+		if err := startIndexer(cn.blockchain, bounds, func(_, _ uint64,
+			chainCh <-chan blockchain.ChainEvent, chainSub event.Subscription,
+			reorgCh <-chan blockchain.ChainReorgEvent, reorgSub event.Subscription) {
+			balanceIndexer(chainDB, cn.blockchain.StateCache(), chainCh, chainSub, reorgCh, reorgSub)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.TraceIndexing {
+		bounds := indexBounds{
+			readTail: chainDB.ReadTraceIndexTail, writeTail: chainDB.WriteTraceIndexTail,
+			readHead: chainDB.ReadTraceIndexHead, writeHead: chainDB.WriteTraceIndexHead,
+		}
+		trace := NewPrivateDebugAPI(cn.chainConfig, cn).traceBlockCalls
+		if err := startIndexer(cn.blockchain, bounds, func(next, current uint64,
+			chainCh <-chan blockchain.ChainEvent, chainSub event.Subscription,
+			reorgCh <-chan blockchain.ChainReorgEvent, reorgSub event.Subscription) {
+			traceIndexer(chainDB, trace, next, current, chainCh, chainSub, reorgCh, reorgSub)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.LogIndexing {
+		bounds := indexBounds{
+			readTail: chainDB.ReadLogIndexTail, writeTail: chainDB.WriteLogIndexTail,
+			readHead: chainDB.ReadLogIndexHead, writeHead: chainDB.WriteLogIndexHead,
+		}
+		if err := startIndexer(cn.blockchain, bounds, func(next, current uint64,
+			chainCh <-chan blockchain.ChainEvent, chainSub event.Subscription,
+			reorgCh <-chan blockchain.ChainReorgEvent, reorgSub event.Subscription) {
+			logIndexer(chainDB, next, current, chainCh, chainSub, reorgCh, reorgSub)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
 	// Rewind the chain in case of an incompatible config upgrade.
 	if compat, ok := genesisErr.(*params.ConfigCompatError); ok {
 		logger.Error("Rewinding chain to upgrade configuration", "err", compat)
@@ -283,10 +444,31 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 		chainDB.WriteChainConfig(genesisHash, cn.chainConfig)
 	}
 	cn.bloomIndexer.Start(cn.blockchain)
+	cn.indexRebuilder = newIndexRebuilder(chainDB, cn.bloomIndexer, config.SenderTxHashIndexing)
+	cn.chainImporter = newChainImporter(cn.blockchain)
+	cn.stateSessions = newStateSessions(cn.blockchain.StateCache(), stateSessionTTL)
+	cn.totalSupplies = newTotalSupplies(config.SupplyBurnAddresses, config.SupplyTreasuryAddresses)
+	if cn.abiRegistry, err = filters.NewABIRegistry(ctx.ResolvePath("abis")); err != nil {
+		return nil, err
+	}
+
+	if config.RecompressChainData {
+		head := cn.blockchain.CurrentBlock().NumberU64()
+		cn.recompressionWg.Add(1)
+		go func() {
+			defer cn.recompressionWg.Done()
+			if _, err := chainDB.RecompressChainData(0, head, cn.closeRecompression); err != nil {
+				logger.Error("Failed to recompress chain data", "err", err)
+			}
+		}()
+	}
 
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = ctx.ResolvePath(config.TxPool.Journal)
 	}
+	if config.TxPool.Denylist != "" {
+		config.TxPool.Denylist = ctx.ResolvePath(config.TxPool.Denylist)
+	}
 	// TODO-Klaytn-ServiceChain: add account creation prevention in the txPool if TxTypeAccountCreation is supported.
 	config.TxPool.NoAccountCreation = config.NoAccountCreation
 	cn.txPool = blockchain.NewTxPool(config.TxPool, cn.chainConfig, bc)
@@ -315,6 +497,11 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 		reward.NewStakingManager(cn.blockchain, governance, cn.chainDB)
 	}
 
+	// Only Istanbul engine mints KLAY and collects the transaction fees by the governance parameters
+	if _, ok := cn.engine.(consensus.Istanbul); ok {
+		cn.supplyTracker = reward.NewSupplyTracker(cn.blockchain, governance, cn.chainDB)
+	}
+
 	// set worker
 	if config.WorkerDisable {
 		cn.miner = work.NewFakeWorker()
@@ -326,13 +513,22 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 		}
 	} else {
 		// TODO-Klaytn improve to handle drop transaction on network traffic in PN and EN
-		cn.miner = work.New(cn, cn.chainConfig, cn.EventMux(), cn.engine, ctx.NodeType(), crypto.PubkeyToAddress(ctx.NodeKey().PublicKey), cn.config.TxResendUseLegacy)
+		cn.miner = work.New(cn, cn.chainConfig, cn.EventMux(), cn.engine, ctx.NodeType(), crypto.PubkeyToAddress(ctx.NodeKey().PublicKey), cn.config.TxResendUseLegacy, cn.config.InstantSeal)
 	}
 
 	// istanbul BFT
 	cn.miner.SetExtra(makeExtraData(config.ExtraData))
+	if config.TxOrderingPolicy != "" {
+		if err := cn.miner.SetTxOrderingPolicy(config.TxOrderingPolicy); err != nil {
+			return nil, err
+		}
+	}
+	if err := cn.miner.SetTxBudget(config.TxBudget); err != nil {
+		return nil, err
+	}
+	cn.miner.SetTargetGasLimit(config.TargetGasLimit)
 
-	cn.APIBackend = &CNAPIBackend{cn, nil}
+	cn.APIBackend = &CNAPIBackend{cn: cn, stateReexecLimit: config.StateReexecLimit}
 
 	gpoParams := config.GPO
 
@@ -449,7 +645,8 @@ func makeExtraData(extra []byte) []byte {
 func CreateDB(ctx *node.ServiceContext, config *Config, name string) database.DBManager {
 	dbc := &database.DBConfig{Dir: name, DBType: config.DBType, ParallelDBWrite: config.ParallelDBWrite, SingleDB: config.SingleDB, NumStateTrieShards: config.NumStateTrieShards,
 		LevelDBCacheSize: config.LevelDBCacheSize, OpenFilesLimit: database.GetOpenFilesLimit(), LevelDBCompression: config.LevelDBCompression,
-		LevelDBBufferPool: config.LevelDBBufferPool, EnableDBPerfMetrics: config.EnableDBPerfMetrics, DynamoDBConfig: &config.DynamoDBConfig}
+		LevelDBBufferPool: config.LevelDBBufferPool, EnableDBPerfMetrics: config.EnableDBPerfMetrics, DynamoDBConfig: &config.DynamoDBConfig,
+		ChainDataCompression: config.ChainDataCompression, AncientDir: config.AncientDir}
 	return ctx.OpenDatabase(dbc)
 }
 
@@ -467,6 +664,9 @@ func CreateConsensusEngine(ctx *node.ServiceContext, config *Config, chainConfig
 func (s *CN) APIs() []rpc.API {
 	apis := api.GetAPIs(s.APIBackend)
 
+	filterAPI := filters.NewPublicFilterAPI(s.APIBackend, false)
+	filterAPI.SetABIRegistry(s.abiRegistry)
+
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
@@ -485,7 +685,7 @@ func (s *CN) APIs() []rpc.API {
 		}, {
 			Namespace: "klay",
 			Version:   "1.0",
-			Service:   filters.NewPublicFilterAPI(s.APIBackend, false),
+			Service:   filterAPI,
 			Public:    true,
 		}, {
 			Namespace: "admin",
@@ -500,6 +700,10 @@ func (s *CN) APIs() []rpc.API {
 			Namespace: "debug",
 			Version:   "1.0",
 			Service:   NewPrivateDebugAPI(s.chainConfig, s),
+		}, {
+			Namespace: "trace",
+			Version:   "1.0",
+			Service:   NewPrivateTraceAPI(s.chainConfig, s),
 		}, {
 			Namespace: "net",
 			Version:   "1.0",
@@ -633,6 +837,12 @@ func (s *CN) Start(srvr p2p.Server) error {
 
 	reward.StakingManagerSubscribe()
 
+	if s.supplyTracker != nil {
+		if err := s.supplyTracker.Start(); err != nil {
+			logger.Error("Failed to start the supply tracker", "err", err)
+		}
+	}
+
 	return nil
 }
 
@@ -651,7 +861,15 @@ func (s *CN) Stop() error {
 	s.txPool.Stop()
 	s.miner.Stop()
 	reward.StakingManagerUnsubscribe()
+	if s.supplyTracker != nil {
+		s.supplyTracker.Stop()
+	}
+	s.indexRebuilder.close()
+	s.chainImporter.close()
+	s.stateSessions.closeAll()
 	s.blockchain.Stop()
+	close(s.closeRecompression)
+	s.recompressionWg.Wait()
 	s.chainDB.Close()
 	s.eventMux.Stop()
 
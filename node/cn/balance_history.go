@@ -0,0 +1,270 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+)
+
+// balanceHistoryMaxSamples is the maximum number of the balances returned by klay_getBalanceHistory.
+const balanceHistoryMaxSamples = 1000
+
+// balanceChange is a change of the balance of an account in a block, stored as an entry of the
+// balance history index. The previous balance gives the balance of the account at the blocks
+// before the change, which may not be covered by the index.
+type balanceChange struct {
+	BlockHash   common.Hash
+	PrevBalance *big.Int
+	Balance     *big.Int
+}
+
+// changedAccounts returns the accounts added, updated or deleted between the two state tries.
+func changedAccounts(stateDB state.Database, parentRoot, root common.Hash) ([]common.Address, error) {
+	oldTrie, err := statedb.NewSecureTrie(parentRoot, stateDB.TrieDB())
+	if err != nil {
+		return nil, err
+	}
+	newTrie, err := statedb.NewSecureTrie(root, stateDB.TrieDB())
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		addrs []common.Address
+		seen  = make(map[common.Address]struct{})
+	)
+	collect := func(a, b statedb.NodeIterator, trie *statedb.SecureTrie) error {
+		diff, _ := statedb.NewDifferenceIterator(a, b)
+		iter := statedb.NewIterator(diff)
+		for iter.Next() {
+			key := trie.GetKey(iter.Key)
+			if key == nil {
+				return fmt.Errorf("no preimage found for hash %x", iter.Key)
+			}
+			addr := common.BytesToAddress(key)
+			if _, ok := seen[addr]; !ok {
+				seen[addr] = struct{}{}
+				addrs = append(addrs, addr)
+			}
+		}
+		return iter.Err
+	}
+	if err := collect(oldTrie.NodeIterator(nil), newTrie.NodeIterator(nil), newTrie); err != nil {
+		return nil, err
+	}
+	// The accounts only in the parent trie are the deleted ones
+	if err := collect(newTrie.NodeIterator(nil), oldTrie.NodeIterator(nil), oldTrie); err != nil {
+		return nil, err
+	}
+	return addrs, nil
+}
+
+// indexBalanceChanges stores the balance changes made by the block to the balance history index.
+// The changed accounts are found by comparing the state trie of the block with the one of its parent,
+// so the changes by the internal transactions and the block rewards are indexed as well.
+func indexBalanceChanges(db database.DBManager, stateDB state.Database, block *types.Block, parentRoot common.Hash) error {
+	prevState, err := state.New(parentRoot, stateDB, nil)
+	if err != nil {
+		return err
+	}
+	currState, err := state.New(block.Root(), stateDB, nil)
+	if err != nil {
+		return err
+	}
+	addrs, err := changedAccounts(stateDB, parentRoot, block.Root())
+	if err != nil {
+		return err
+	}
+
+	batch := db.NewBalanceChangeBatch()
+	for _, addr := range addrs {
+		prev, curr := prevState.GetBalance(addr), currState.GetBalance(addr)
+		if prev.Cmp(curr) == 0 {
+			continue
+		}
+		data, err := rlp.EncodeToBytes(&balanceChange{BlockHash: block.Hash(), PrevBalance: prev, Balance: curr})
+		if err != nil {
+			return err
+		}
+		if err := db.PutBalanceChangeToBatch(batch, addr, block.NumberU64(), data); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// balanceIndexer subscribes chainEvent and chainReorgEvent, and stores the balance changes of the
+// new canonical blocks to the balance history index. The entries of the blocks dropped by a reorg
+// are left in the index, and skipped by the canonical hashes when read.
+func balanceIndexer(db database.DBManager, stateDB state.Database, chainEvent <-chan blockchain.ChainEvent, chainSub event.Subscription,
+	reorgEvent <-chan blockchain.ChainReorgEvent, reorgSub event.Subscription) {
+	defer chainSub.Unsubscribe()
+	defer reorgSub.Unsubscribe()
+
+	index := func(block *types.Block) {
+		parent := db.ReadHeader(block.ParentHash(), block.NumberU64()-1)
+		if parent == nil {
+			logger.Error("Failed to read the parent of the block to index balance changes", "blockNum", block.Number())
+			return
+		}
+		if err := indexBalanceChanges(db, stateDB, block, parent.Root); err != nil {
+			logger.Error("Failed to store balance history index to database", "blockNum", block.Number(), "err", err)
+		}
+	}
+	for {
+		select {
+		case ev := <-chainEvent:
+			index(ev.Block)
+
+		case ev := <-reorgEvent:
+			// The blocks of the new chain except its head are not sent as chain events
+			for _, hash := range ev.AddedBlocks {
+				block := db.ReadBlockByHash(hash)
+				if block == nil {
+					logger.Error("Failed to read the block added by a reorg", "hash", hash)
+					continue
+				}
+				index(block)
+			}
+
+		case <-chainSub.Err():
+			return
+		case <-reorgSub.Err():
+			return
+		}
+	}
+}
+
+// BalanceHistoryEntry is the balance of an account at a block.
+type BalanceHistoryEntry struct {
+	Number  hexutil.Uint64 `json:"number"`
+	Balance *hexutil.Big   `json:"balance"`
+}
+
+// balanceHistory returns the balances of the account at every step blocks from the given range, both
+// inclusive. The balances at the blocks covered by the balance history index are read from the index,
+// and the others are read from the states of the blocks, which are available on archive nodes.
+func (s *CN) balanceHistory(addr common.Address, from, to, step uint64) ([]*BalanceHistoryEntry, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid block range: from %d > to %d", from, to)
+	}
+	if step == 0 {
+		return nil, fmt.Errorf("invalid step: 0")
+	}
+	if samples := (to-from)/step + 1; samples > balanceHistoryMaxSamples {
+		return nil, fmt.Errorf("too many balances requested: %d > %d", samples, balanceHistoryMaxSamples)
+	}
+
+	// The blocks from indexFrom are read from the index
+	indexFrom := to + 1
+	if s.config.BalanceHistoryIndexing {
+		tail, err := s.chainDB.ReadBalanceIndexTail()
+		if err != nil {
+			return nil, err
+		}
+		if tail != 0 && tail < indexFrom {
+			indexFrom = tail
+		}
+		if indexFrom < from {
+			indexFrom = from
+		}
+	}
+
+	var (
+		result  []*BalanceHistoryEntry
+		pending []uint64
+	)
+	add := func(number uint64, balance *big.Int) {
+		result = append(result, &BalanceHistoryEntry{Number: hexutil.Uint64(number), Balance: (*hexutil.Big)(balance)})
+	}
+	for number := from; ; number += step {
+		if number >= indexFrom {
+			pending = append(pending, number)
+		} else {
+			header := s.blockchain.GetHeaderByNumber(number)
+			if header == nil {
+				return nil, fmt.Errorf("block %d is not available", number)
+			}
+			statedb, err := s.blockchain.StateAt(header.Root)
+			if err != nil {
+				return nil, fmt.Errorf("state of block %d not covered by the balance history index (--balancehistoryindexing) is not available: %v", number, err)
+			}
+			add(number, statedb.GetBalance(addr))
+		}
+		if to-number < step {
+			break
+		}
+	}
+	if len(pending) == 0 {
+		return result, nil
+	}
+
+	// The balance at a block is the balance after the last change at or before the block,
+	// or the balance before the first change after the block
+	var (
+		err       error
+		last      *big.Int
+		canonical = make(map[uint64]common.Hash)
+	)
+	s.chainDB.IterateBalanceChanges(addr, indexFrom, func(blockNum uint64, data []byte) bool {
+		entry := new(balanceChange)
+		if err = rlp.DecodeBytes(data, entry); err != nil {
+			return false
+		}
+		hash, ok := canonical[blockNum]
+		if !ok {
+			hash = s.chainDB.ReadCanonicalHash(blockNum)
+			canonical[blockNum] = hash
+		}
+		// The entries of the blocks dropped by reorgs are skipped
+		if entry.BlockHash != hash {
+			return true
+		}
+		for len(pending) > 0 && pending[0] < blockNum {
+			add(pending[0], entry.PrevBalance)
+			pending = pending[1:]
+		}
+		last = entry.Balance
+		return len(pending) > 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) > 0 && last == nil {
+		// The balance has not changed since the index started, so it is the current balance
+		statedb, err := s.blockchain.State()
+		if err != nil {
+			return nil, err
+		}
+		last = statedb.GetBalance(addr)
+	}
+	for _, number := range pending {
+		add(number, last)
+	}
+	return result, nil
+}
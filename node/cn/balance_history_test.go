@@ -0,0 +1,144 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCN_BalanceHistory(t *testing.T) {
+	mockCtrl, _, mockBlockChain, _ := newMocks(t)
+	defer mockCtrl.Finish()
+
+	var (
+		db      = database.NewMemoryDBManager()
+		stateDB = state.NewDatabase(database.NewMemoryDBManager())
+		cn      = &CN{config: &Config{BalanceHistoryIndexing: true}, chainDB: db, blockchain: mockBlockChain}
+		addrA   = common.Address{0x0a}
+		addrB   = common.Address{0x0b}
+		addrC   = common.Address{0x0c}
+		headers []*types.Header
+	)
+	// commit applies the changes to the state of the last block and writes a new block with the state
+	commit := func(extra byte, canonical bool, change func(*state.StateDB)) *types.Block {
+		var parent common.Hash
+		if len(headers) > 0 {
+			parent = headers[len(headers)-1].Root
+		}
+		statedb, err := state.New(parent, stateDB, nil)
+		assert.NoError(t, err)
+		change(statedb)
+		root, err := statedb.Commit(true)
+		assert.NoError(t, err)
+		assert.NoError(t, stateDB.TrieDB().Commit(root, false, 0))
+
+		header := &types.Header{Number: big.NewInt(int64(len(headers))), Root: root, Extra: []byte{extra}}
+		block := types.NewBlockWithHeader(header)
+		db.WriteHeader(header)
+		if canonical {
+			db.WriteCanonicalHash(block.Hash(), block.NumberU64())
+			headers = append(headers, header)
+		}
+		return block
+	}
+	index := func(block *types.Block) {
+		assert.NoError(t, indexBalanceChanges(db, stateDB, block, headers[block.NumberU64()-1].Root))
+	}
+
+	// Block 0 is not indexed, and read from the state
+	commit(0, true, func(s *state.StateDB) {
+		s.AddBalance(addrA, big.NewInt(100))
+		s.AddBalance(addrB, big.NewInt(50))
+	})
+	assert.NoError(t, db.WriteBalanceIndexTail(1))
+
+	index(commit(0, true, func(s *state.StateDB) {
+		s.SubBalance(addrA, big.NewInt(10))
+		s.AddBalance(addrB, big.NewInt(10))
+	}))
+	// The entries of the block dropped by a reorg are not read
+	dropped := commit(1, false, func(s *state.StateDB) {
+		s.AddBalance(addrA, big.NewInt(1000))
+	})
+	index(dropped)
+	index(commit(0, true, func(s *state.StateDB) {
+		s.AddBalance(addrC, big.NewInt(5))
+	}))
+	index(commit(0, true, func(s *state.StateDB) {
+		s.Suicide(addrA)
+	}))
+
+	mockBlockChain.EXPECT().GetHeaderByNumber(uint64(0)).Return(headers[0]).AnyTimes()
+	mockBlockChain.EXPECT().StateAt(headers[0].Root).DoAndReturn(func(root common.Hash) (*state.StateDB, error) {
+		return state.New(root, stateDB, nil)
+	}).AnyTimes()
+	mockBlockChain.EXPECT().State().DoAndReturn(func() (*state.StateDB, error) {
+		return state.New(headers[len(headers)-1].Root, stateDB, nil)
+	}).AnyTimes()
+
+	balances := func(values ...int64) []*BalanceHistoryEntry {
+		var entries []*BalanceHistoryEntry
+		for i := 0; i < len(values); i += 2 {
+			entries = append(entries, &BalanceHistoryEntry{Number: hexutil.Uint64(values[i]), Balance: (*hexutil.Big)(big.NewInt(values[i+1]))})
+		}
+		return entries
+	}
+	for _, indexing := range []bool{true, false} {
+		cn.config.BalanceHistoryIndexing = indexing
+		if !indexing {
+			// The states of all the blocks are read without the index
+			for _, header := range headers[1:] {
+				mockBlockChain.EXPECT().GetHeaderByNumber(header.Number.Uint64()).Return(header).AnyTimes()
+				mockBlockChain.EXPECT().StateAt(header.Root).DoAndReturn(func(root common.Hash) (*state.StateDB, error) {
+					return state.New(root, stateDB, nil)
+				}).AnyTimes()
+			}
+		}
+
+		history, err := cn.balanceHistory(addrA, 0, 3, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, balances(0, 100, 1, 90, 2, 90, 3, 0), history)
+
+		history, err = cn.balanceHistory(addrB, 0, 3, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, balances(0, 50, 2, 60), history)
+
+		// The balance of the account not changed since the index started is the current balance
+		history, err = cn.balanceHistory(addrB, 2, 3, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, balances(2, 60, 3, 60), history)
+
+		history, err = cn.balanceHistory(addrC, 1, 3, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, balances(1, 0, 2, 5, 3, 5), history)
+	}
+
+	_, err := cn.balanceHistory(addrA, 3, 1, 1)
+	assert.Error(t, err)
+	_, err = cn.balanceHistory(addrA, 0, 3, 0)
+	assert.Error(t, err)
+	_, err = cn.balanceHistory(addrA, 0, balanceHistoryMaxSamples, 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,132 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/params"
+	"github.com/rcrowley/go-metrics"
+)
+
+// The reasons of rejecting a peer at the handshake.
+const (
+	mismatchNetworkId = "networkId"
+	mismatchChainId   = "chainId"
+	mismatchGenesis   = "genesis"
+)
+
+// maxMismatchValues is the maximum number of the distinct advertised values remembered for
+// each reason, so the peers advertising random values cannot grow the memory without bound.
+const maxMismatchValues = 64
+
+var mismatchCounters = map[string]metrics.Counter{
+	mismatchNetworkId: metrics.NewRegisteredCounter("klay/handshake/mismatch/networkid", nil),
+	mismatchChainId:   metrics.NewRegisteredCounter("klay/handshake/mismatch/chainid", nil),
+	mismatchGenesis:   metrics.NewRegisteredCounter("klay/handshake/mismatch/genesis", nil),
+}
+
+// handshakeMismatches counts the peers rejected at the handshake by the reason and the advertised value.
+var handshakeMismatches = &peerMismatches{counts: make(map[string]map[string]int64)}
+
+type peerMismatches struct {
+	mu     sync.Mutex
+	counts map[string]map[string]int64
+}
+
+func (m *peerMismatches) record(reason, advertised string) {
+	mismatchCounters[reason].Inc(1)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	values, ok := m.counts[reason]
+	if !ok {
+		values = make(map[string]int64)
+		m.counts[reason] = values
+	}
+	if _, ok := values[advertised]; !ok && len(values) >= maxMismatchValues {
+		advertised = "others"
+	}
+	values[advertised]++
+}
+
+func (m *peerMismatches) snapshot() map[string]map[string]int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counts := make(map[string]map[string]int64, len(m.counts))
+	for reason, values := range m.counts {
+		counts[reason] = make(map[string]int64, len(values))
+		for value, count := range values {
+			counts[reason][value] = count
+		}
+	}
+	return counts
+}
+
+// ChainIDAudit is the result of checking the network ID, the chain ID in the genesis and
+// the chain IDs advertised by the peers are consistent.
+type ChainIDAudit struct {
+	NetworkId     uint64                      `json:"networkId"`
+	ChainId       *hexutil.Big                `json:"chainId"`
+	GenesisHash   common.Hash                 `json:"genesisHash"`
+	Private       bool                        `json:"private"`
+	Consistent    bool                        `json:"consistent"`
+	Issues        []string                    `json:"issues"`
+	RejectedPeers map[string]map[string]int64 `json:"rejectedPeers"` // reason -> advertised value -> number of the peers
+	RejectedTxs   int64                       `json:"rejectedTxs"`   // transactions signed for another chain
+}
+
+// auditChainConfig returns the inconsistencies between the network ID and the chain ID in the genesis.
+func auditChainConfig(networkId uint64, isPrivate bool, chainConfig *params.ChainConfig) []string {
+	if chainConfig.ChainID == nil {
+		return []string{"chain ID is not set in the genesis"}
+	}
+	var issues []string
+	if !chainConfig.ChainID.IsUint64() || chainConfig.ChainID.Uint64() != networkId {
+		issue := fmt.Sprintf("network ID %d differs from chain ID %v in the genesis", networkId, chainConfig.ChainID)
+		if !isPrivate {
+			issue += ", the genesis may not belong to the network"
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// ChainIDAudit checks the network ID, the chain ID in the genesis and the chain IDs advertised by
+// the peers are consistent. It also reports the peers and the transactions rejected for mismatches.
+func (api *PrivateAdminAPI) ChainIDAudit() *ChainIDAudit {
+	cn := api.cn
+	audit := &ChainIDAudit{
+		NetworkId:     cn.networkId,
+		ChainId:       (*hexutil.Big)(cn.chainConfig.ChainID),
+		GenesisHash:   cn.blockchain.Genesis().Hash(),
+		Private:       cn.config.IsPrivate,
+		Issues:        auditChainConfig(cn.networkId, cn.config.IsPrivate, cn.chainConfig),
+		RejectedPeers: handshakeMismatches.snapshot(),
+		RejectedTxs:   cn.txPool.InvalidChainIdTxs(),
+	}
+	if peers := audit.RejectedPeers[mismatchChainId]; len(peers) > 0 {
+		audit.Issues = append(audit.Issues, fmt.Sprintf("peers advertising %d other chain IDs were rejected", len(peers)))
+	}
+	audit.Consistent = len(audit.Issues) == 0
+	return audit
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditChainConfig(t *testing.T) {
+	assert.Empty(t, auditChainConfig(1000, false, &params.ChainConfig{ChainID: big.NewInt(1000)}))
+	assert.Len(t, auditChainConfig(1000, false, &params.ChainConfig{}), 1)
+
+	issues := auditChainConfig(1000, false, &params.ChainConfig{ChainID: big.NewInt(1001)})
+	assert.Len(t, issues, 1)
+	assert.Contains(t, issues[0], "may not belong to the network")
+
+	issues = auditChainConfig(1000, true, &params.ChainConfig{ChainID: big.NewInt(1001)})
+	assert.Len(t, issues, 1)
+	assert.NotContains(t, issues[0], "may not belong to the network")
+}
+
+func TestPeerMismatches(t *testing.T) {
+	m := &peerMismatches{counts: make(map[string]map[string]int64)}
+	m.record(mismatchChainId, "1001")
+	m.record(mismatchChainId, "1001")
+	m.record(mismatchNetworkId, "8217")
+	assert.Equal(t, map[string]map[string]int64{
+		mismatchChainId:   {"1001": 2},
+		mismatchNetworkId: {"8217": 1},
+	}, m.snapshot())
+
+	// The distinct values over the limit are counted together
+	for i := 0; i < maxMismatchValues+2; i++ {
+		m.record(mismatchGenesis, fmt.Sprint(i))
+	}
+	counts := m.snapshot()[mismatchGenesis]
+	assert.Len(t, counts, maxMismatchValues+1)
+	assert.Equal(t, int64(2), counts["others"])
+}
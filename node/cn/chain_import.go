@@ -0,0 +1,333 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/work"
+)
+
+const (
+	// maxImportJobs is the maximum number of finished import jobs whose status is kept.
+	maxImportJobs = 16
+
+	// importBatchSize is the number of blocks inserted into the chain at once.
+	importBatchSize = 2500
+)
+
+var (
+	errImportRunning    = errors.New("chain import is already running")
+	errImportNotRunning = errors.New("chain import is not running")
+	errImportNotFound   = errors.New("chain import job not found")
+	errImportAborted    = errors.New("chain import is aborted")
+)
+
+// ImportChainStatus is the progress of a chain import job.
+type ImportChainStatus struct {
+	ID       uint64  `json:"id"`
+	File     string  `json:"file"`
+	Running  bool    `json:"running"`
+	Imported int     `json:"imported"` // the number of the inserted blocks
+	Current  uint64  `json:"current"`  // the number of the last block read from the file
+	Progress float64 `json:"progress"` // the percentage of the file read
+	Elapsed  string  `json:"elapsed"`
+	ETA      string  `json:"eta"` // the estimated time left, empty if unknown
+	Err      string  `json:"err"`
+}
+
+// countingReader counts the bytes read from the underlying reader.
+type countingReader struct {
+	r    io.Reader
+	read int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	atomic.AddInt64(&c.read, int64(n))
+	return n, err
+}
+
+type importJob struct {
+	status  ImportChainStatus
+	size    int64
+	reader  *countingReader
+	started time.Time
+	quit    chan struct{}
+	done    chan struct{}
+}
+
+// chainImporter runs the imports of the blocks in files in background, so a long import does not
+// block the RPC call. An import runs at a time, and the status of the last finished imports is kept.
+type chainImporter struct {
+	chain work.BlockChain
+
+	mu     sync.Mutex
+	jobs   map[uint64]*importJob
+	lastID uint64
+}
+
+func newChainImporter(chain work.BlockChain) *chainImporter {
+	return &chainImporter{chain: chain, jobs: make(map[uint64]*importJob)}
+}
+
+// start starts importing the blocks in the given file, which is gzipped if it ends with ".gz",
+// and returns the ID of the import job.
+func (c *chainImporter) start(file string) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, job := range c.jobs {
+		if job.status.Running {
+			return 0, errImportRunning
+		}
+	}
+	in, err := os.Open(file)
+	if err != nil {
+		return 0, err
+	}
+	info, err := in.Stat()
+	if err != nil {
+		in.Close()
+		return 0, err
+	}
+	counter := &countingReader{r: in}
+	var reader io.Reader = counter
+	if strings.HasSuffix(file, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			in.Close()
+			return 0, err
+		}
+	}
+
+	c.lastID++
+	job := &importJob{
+		status:  ImportChainStatus{ID: c.lastID, File: file, Running: true},
+		size:    info.Size(),
+		reader:  counter,
+		started: time.Now(),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	c.jobs[job.status.ID] = job
+	c.prune()
+
+	go func() {
+		defer close(job.done)
+		defer in.Close()
+		err := importBlocks(c.chain, rlp.NewStream(reader, 0), job.quit, func(inserted int, number uint64) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			job.status.Imported += inserted
+			job.status.Current = number
+		})
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		job.status.Running = false
+		job.status.Elapsed = time.Since(job.started).String()
+		if err != nil {
+			job.status.Err = err.Error()
+			logger.Error("Failed to import chain", "id", job.status.ID, "file", file, "err", err)
+			return
+		}
+		logger.Info("Imported chain", "id", job.status.ID, "file", file, "imported", job.status.Imported,
+			"current", job.status.Current, "elapsed", job.status.Elapsed)
+	}()
+	return job.status.ID, nil
+}
+
+// prune removes the oldest finished jobs beyond maxImportJobs. It should be called under the lock.
+func (c *chainImporter) prune() {
+	for id := uint64(1); id < c.lastID && len(c.jobs) > maxImportJobs; id++ {
+		if job, ok := c.jobs[id]; ok && !job.status.Running {
+			delete(c.jobs, id)
+		}
+	}
+}
+
+// abort stops the given import job after the batch being inserted.
+func (c *chainImporter) abort(id uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	job, ok := c.jobs[id]
+	if !ok {
+		return errImportNotFound
+	}
+	if !job.status.Running {
+		return errImportNotRunning
+	}
+	select {
+	case <-job.quit:
+	default:
+		close(job.quit)
+	}
+	return nil
+}
+
+// getStatus returns the status of the given import job. The progress and the time left are
+// estimated from the bytes read from the file.
+func (c *chainImporter) getStatus(id uint64) (ImportChainStatus, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	job, ok := c.jobs[id]
+	if !ok {
+		return ImportChainStatus{}, errImportNotFound
+	}
+	status := job.status
+	read := atomic.LoadInt64(&job.reader.read)
+	if job.size > 0 {
+		status.Progress = float64(read) / float64(job.size) * 100
+	}
+	if status.Running {
+		elapsed := time.Since(job.started)
+		status.Elapsed = elapsed.String()
+		if read > 0 && read <= job.size {
+			status.ETA = time.Duration(float64(elapsed) * float64(job.size-read) / float64(read)).String()
+		}
+	}
+	return status, nil
+}
+
+// running returns true if an import job is running.
+func (c *chainImporter) running() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, job := range c.jobs {
+		if job.status.Running {
+			return true
+		}
+	}
+	return false
+}
+
+// close aborts the running import job and waits for it to be terminated.
+func (c *chainImporter) close() {
+	c.mu.Lock()
+	var running *importJob
+	for _, job := range c.jobs {
+		if job.status.Running {
+			running = job
+		}
+	}
+	c.mu.Unlock()
+
+	if running == nil {
+		return
+	}
+	c.abort(running.status.ID)
+	<-running.done
+}
+
+// importBatch is a batch of blocks read from the stream to be inserted.
+type importBatch struct {
+	blocks []*types.Block
+	err    error
+}
+
+// readBatches reads the blocks in the stream in batches, and sends them after starting to recover
+// the senders of their transactions, so that the senders of a batch are recovered while the
+// preceding batch is inserted. The channel is closed at the end of the stream or after an error.
+func readBatches(chain work.BlockChain, stream *rlp.Stream, batches chan<- importBatch, stop <-chan struct{}) {
+	defer close(batches)
+
+	send := func(batch importBatch) bool {
+		select {
+		case batches <- batch:
+			return true
+		case <-stop:
+			return false
+		}
+	}
+	for index := 0; ; {
+		blocks := make([]*types.Block, 0, importBatchSize)
+		for len(blocks) < cap(blocks) {
+			block := new(types.Block)
+			if err := stream.Decode(block); err == io.EOF {
+				break
+			} else if err != nil {
+				send(importBatch{err: fmt.Errorf("block %d: failed to parse: %v", index, err)})
+				return
+			}
+			blocks = append(blocks, block)
+			index++
+		}
+		if len(blocks) == 0 {
+			return
+		}
+		blockchain.RecoverSenders(chain.Config(), blocks)
+		if !send(importBatch{blocks: blocks}) {
+			return
+		}
+	}
+}
+
+// importBlocks inserts the blocks in the stream into the chain in batches, skipping the batches
+// already in the chain. The number of the inserted blocks and the number of the last block of
+// each batch are reported, and the import stops before the next batch if quit is closed.
+func importBlocks(chain work.BlockChain, stream *rlp.Stream, quit <-chan struct{}, report func(inserted int, number uint64)) error {
+	var (
+		batches = make(chan importBatch, 1)
+		stop    = make(chan struct{})
+	)
+	defer close(stop)
+	go readBatches(chain, stream, batches, stop)
+
+	for batch := 0; ; batch++ {
+		select {
+		case <-quit:
+			return errImportAborted
+		default:
+		}
+		// Load a batch of blocks from the input file
+		next, ok := <-batches
+		if !ok {
+			break
+		}
+		if next.err != nil {
+			return next.err
+		}
+		blocks := next.blocks
+
+		inserted := 0
+		if !hasAllBlocks(chain, blocks) {
+			// Import the batch
+			if _, err := chain.InsertChain(blocks); err != nil {
+				return fmt.Errorf("batch %d: failed to insert: %v", batch, err)
+			}
+			inserted = len(blocks)
+		}
+		if report != nil {
+			report(inserted, blocks[len(blocks)-1].NumberU64())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests that the blocks in a file are imported in background with the progress of the import job.
+func TestChainImporter(t *testing.T) {
+	var (
+		gspec  = &blockchain.Genesis{Config: params.TestChainConfig}
+		engine = gxhash.NewFaker()
+		srcDB  = database.NewMemoryDBManager()
+		db     = database.NewMemoryDBManager()
+	)
+	blocks, _ := blockchain.GenerateChain(gspec.Config, gspec.MustCommit(srcDB), engine, srcDB, 5, nil)
+	gspec.MustCommit(db)
+	bc, err := blockchain.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+
+	dir, err := ioutil.TempDir("", "klay-chain-import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	for _, block := range blocks {
+		if err := rlp.Encode(gz, block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gz.Close()
+	file := filepath.Join(dir, "chain.gz")
+	if err := ioutil.WriteFile(file, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	importer := newChainImporter(bc)
+	defer importer.close()
+	wait := func(id uint64) ImportChainStatus {
+		for {
+			status, err := importer.getStatus(id)
+			assert.NoError(t, err)
+			if !status.Running {
+				return status
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	id, err := importer.start(file)
+	assert.NoError(t, err)
+	status := wait(id)
+	assert.Empty(t, status.Err)
+	assert.Equal(t, 5, status.Imported)
+	assert.Equal(t, uint64(5), status.Current)
+	assert.Equal(t, float64(100), status.Progress)
+	assert.Equal(t, blocks[4].Hash(), bc.CurrentBlock().Hash())
+	assert.Equal(t, errImportNotRunning, importer.abort(id))
+
+	// The blocks already in the chain are skipped
+	next, err := importer.start(file)
+	assert.NoError(t, err)
+	assert.Equal(t, id+1, next)
+	status = wait(next)
+	assert.Empty(t, status.Err)
+	assert.Zero(t, status.Imported)
+	assert.Equal(t, uint64(5), status.Current)
+
+	_, err = importer.start(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+	_, err = importer.getStatus(next + 1)
+	assert.Equal(t, errImportNotFound, err)
+	assert.Equal(t, errImportNotFound, importer.abort(next+1))
+
+	// An aborted import stops before the next batch
+	quit := make(chan struct{})
+	close(quit)
+	stream := rlp.NewStream(bytes.NewReader(nil), 0)
+	assert.Equal(t, errImportAborted, importBlocks(bc, stream, quit, nil))
+}
+
+// Tests that the blocks with transactions are inserted in batches while the following batches are read,
+// and that a broken stream stops the import after the preceding batches.
+func TestImportBlocks(t *testing.T) {
+	var (
+		key, _ = crypto.GenerateKey()
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		gspec  = &blockchain.Genesis{Config: params.TestChainConfig, Alloc: blockchain.GenesisAlloc{addr: {Balance: big.NewInt(1000000000000000)}}}
+		engine = gxhash.NewFaker()
+		srcDB  = database.NewMemoryDBManager()
+		db     = database.NewMemoryDBManager()
+		signer = types.MakeSigner(gspec.Config, big.NewInt(0))
+	)
+	blocks, _ := blockchain.GenerateChain(gspec.Config, gspec.MustCommit(srcDB), engine, srcDB, importBatchSize+10, func(i int, gen *blockchain.BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(gen.TxNonce(addr), common.Address{0x01}, big.NewInt(1), params.TxGas, nil, nil), signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gen.AddTx(tx)
+	})
+	gspec.MustCommit(db)
+	bc, err := blockchain.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+
+	var buf bytes.Buffer
+	for _, block := range blocks {
+		if err := rlp.Encode(&buf, block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The last block is truncated
+	data := buf.Bytes()[:buf.Len()-1]
+
+	var batches []int
+	err = importBlocks(bc, rlp.NewStream(bytes.NewReader(data), 0), nil, func(inserted int, number uint64) {
+		batches = append(batches, inserted)
+	})
+	assert.Error(t, err)
+	assert.Equal(t, []int{importBatchSize}, batches)
+	assert.Equal(t, blocks[importBatchSize-1].Hash(), bc.CurrentBlock().Hash())
+
+	// The inserted batch is skipped
+	batches = nil
+	assert.NoError(t, importBlocks(bc, rlp.NewStream(bytes.NewReader(buf.Bytes()), 0), nil, func(inserted int, number uint64) {
+		batches = append(batches, inserted)
+	}))
+	assert.Equal(t, []int{0, 10}, batches)
+	assert.Equal(t, blocks[len(blocks)-1].Hash(), bc.CurrentBlock().Hash())
+}
@@ -67,6 +67,13 @@ func NewChannelManager(channelSize int) *ChannelManager {
 	channelMgr.RegisterMsgCode(MiscChannel, NodeDataRequestMsg)
 	channelMgr.RegisterMsgCode(MiscChannel, NodeDataMsg)
 
+	channelMgr.RegisterMsgCode(MiscChannel, GetAccountRangeMsg)
+	channelMgr.RegisterMsgCode(MiscChannel, AccountRangeMsg)
+	channelMgr.RegisterMsgCode(MiscChannel, GetStorageRangesMsg)
+	channelMgr.RegisterMsgCode(MiscChannel, StorageRangesMsg)
+	channelMgr.RegisterMsgCode(MiscChannel, GetByteCodesMsg)
+	channelMgr.RegisterMsgCode(MiscChannel, ByteCodesMsg)
+
 	return channelMgr
 }
 
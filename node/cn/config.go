@@ -38,6 +38,7 @@ import (
 	"github.com/klaytn/klaytn/node/cn/gasprice"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/work"
 )
 
 var logger = log.NewModuleLogger(log.NodeCN)
@@ -52,6 +53,7 @@ func GetDefaultConfig() *Config {
 		TrieTimeout:       5 * time.Minute,
 		TrieBlockInterval: blockchain.DefaultBlockInterval,
 		TriesInMemory:     blockchain.DefaultTriesInMemory,
+		AncientThreshold:  blockchain.DefaultAncientThreshold,
 		GasPrice:          big.NewInt(18 * params.Ston),
 
 		TxPool: blockchain.DefaultTxPoolConfig,
@@ -104,27 +106,48 @@ type Config struct {
 	StartBlockNumber uint64
 
 	// Database options
-	DBType               database.DBType
-	SkipBcVersionCheck   bool `toml:"-"`
-	SingleDB             bool
-	NumStateTrieShards   uint
-	EnableDBPerfMetrics  bool
-	LevelDBCompression   database.LevelDBCompressionType
-	LevelDBBufferPool    bool
-	LevelDBCacheSize     int
-	DynamoDBConfig       database.DynamoDBConfig
-	TrieCacheSize        int
-	TrieTimeout          time.Duration
-	TrieBlockInterval    uint
-	TriesInMemory        uint64
-	SenderTxHashIndexing bool
-	ParallelDBWrite      bool
-	TrieNodeCacheConfig  statedb.TrieNodeCacheConfig
+	DBType                   database.DBType
+	SkipBcVersionCheck       bool `toml:"-"`
+	SingleDB                 bool
+	NumStateTrieShards       uint
+	EnableDBPerfMetrics      bool
+	LevelDBCompression       database.LevelDBCompressionType
+	LevelDBBufferPool        bool
+	LevelDBCacheSize         int
+	ChainDataCompression     database.ChainDataCompressionType
+	RecompressChainData      bool
+	AncientDir               string // directory of the ancient store keeping the old blocks in flat files (empty = disabled)
+	AncientThreshold         uint64 // number of recent blocks kept in the database out of the ancient store
+	DynamoDBConfig           database.DynamoDBConfig
+	SnapshotURL              string         // location of the signed manifest of the chaindata snapshot to bootstrap from
+	SnapshotSigner           common.Address // trusted signer of the snapshot manifest
+	TrieCacheSize            int
+	TrieTimeout              time.Duration
+	TrieBlockInterval        uint
+	TriesInMemory            uint64
+	StateSnapshotCacheSize   int    // size of in-memory cache of the state snapshot in MiB (0 = disabled)
+	StateReexecLimit         uint64 // maximum number of blocks re-executed to regenerate a missing state for API requests
+	StateLivePruningInterval uint64 // number of blocks between the cycles pruning the past states while running (0 = disabled)
+	StatePruningBloomSize    uint64 // size of the bloom filter marking the state to keep by the state pruning in MiB
+	SenderTxHashIndexing     bool
+	TxLookupLimit            uint64 // number of recent blocks whose transactions are indexed (0 = entire chain)
+	BodyRetention            uint64 // number of recent blocks whose bodies and receipts are kept by a PN (0 = entire chain)
+	StorageOwnerIndexing     bool   // indexes the contract accounts owning the storage trie roots
+	FeePayerIndexing         bool   // indexes the transactions paid by the fee payers
+	BalanceHistoryIndexing   bool   // indexes the balance changes of the accounts
+	TraceIndexing            bool   // indexes the accounts appearing in the call traces of the blocks
+	LogIndexing              bool   // indexes the logs by their emitting addresses and first topics
+	ParallelDBWrite          bool
+	TrieNodeCacheConfig      statedb.TrieNodeCacheConfig
 
 	// Mining-related options
 	ServiceChainSigner common.Address `toml:",omitempty"`
 	ExtraData          []byte         `toml:",omitempty"`
 	GasPrice           *big.Int
+	InstantSeal        bool                // seals a block on tx arrival and does not seal empty blocks (developer mode)
+	TxOrderingPolicy   string              `toml:",omitempty"` // policy ordering the transactions in a block (default = price)
+	TxBudget           work.TxBudgetConfig // partitioning of the block gas budget by tx class
+	TargetGasLimit     uint64              // target gas limit of the blocks built by the node (0 = no limit)
 
 	// Reward
 	Rewardbase common.Address `toml:",omitempty"`
@@ -139,6 +162,10 @@ type Config struct {
 	EnablePreimageRecording bool
 	// Enables collecting internal transaction data during processing a block
 	EnableInternalTxTracing bool
+	// Enables executing the transactions of a block in parallel
+	ParallelTxExecution bool
+	// Number of workers for the parallel transaction execution (0 = number of CPUs)
+	ParallelTxWorkers int
 	// Istanbul options
 	Istanbul istanbul.Config
 
@@ -165,6 +192,14 @@ type Config struct {
 
 	// RPCGasCap is the global gas cap for eth-call variants.
 	RPCGasCap *big.Int `toml:",omitempty"`
+
+	// RPCEstimateGasTolerance is the ratio of the gas estimated by klay_estimateGas which may exceed
+	// the required gas. The estimation stops early within the tolerance, and 0 finds the exact gas.
+	RPCEstimateGasTolerance float64 `toml:",omitempty"`
+
+	// Supply options
+	SupplyBurnAddresses     []common.Address `toml:",omitempty"` // accounts whose balances are excluded from the total supply
+	SupplyTreasuryAddresses []common.Address `toml:",omitempty"` // accounts whose balances are excluded from the circulating supply
 }
 
 type configMarshaling struct {
@@ -25,6 +25,8 @@ Source Files
 
   - api.go              : provides private debug API related to block and state
   - api_backend.go      : implements CNAPIBackend which is a wrapper of CN to serve API requests
+  - api_contract_stats.go : provides private debug API related to the code and storage size of contracts
+  - api_statediff.go    : provides private debug API related to the state changes of transactions
   - api_tracer.go       : provides private debug API related to trace chain, block and state
   - backend.go          : implements CN struct used for the Klaytn consensus node service
   - bloombits.go        : implements BloomIndexer, an indexer built with bloom bits for fast filtering
@@ -36,6 +38,9 @@ Source Files
   - peer.go             : provides the interface and implementation of Peer interface
   - peer_set.go         : provides the interface and implementation of PeerSet interface
   - protocol.go         : defines the protocol version of Klaytn network and includes errors in cn package
+  - state_accessor.go   : implements regenerating historical states by re-executing blocks
+  - state_session.go    : implements the state sessions pinning states for paginated iterations
   - sync.go             : includes syncing features of ProtocolManager
+  - total_supply.go     : implements computing the total and the circulating supply of KLAY per block
 */
 package cn
@@ -0,0 +1,253 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+)
+
+// feePayerStatsMaxScanBlocks is the maximum number of blocks whose receipts are scanned by
+// klay_getFeePayerStats for the blocks not covered by the fee payer index.
+const feePayerStatsMaxScanBlocks = 1000
+
+// feePayerTx is a transaction paid by a fee payer, stored as an entry of the fee payer index.
+type feePayerTx struct {
+	BlockHash common.Hash
+	Sender    common.Address
+	Contract  common.Address // deployed or executed contract, or zero for the other transactions
+	GasUsed   uint64
+	Fee       *big.Int // part of the fee paid by the fee payer
+}
+
+// newFeePayerTx returns the fee payer of the given fee delegated transaction and the usage of
+// the fee payer. It returns false if the transaction is not fee delegated.
+func newFeePayerTx(blockHash common.Hash, tx *types.Transaction, receipt *types.Receipt) (common.Address, *feePayerTx, bool) {
+	if !tx.Type().IsFeeDelegatedTransaction() {
+		return common.Address{}, nil, false
+	}
+	feePayer, err := tx.FeePayer()
+	if err != nil {
+		return common.Address{}, nil, false
+	}
+	sender, err := tx.From()
+	if err != nil {
+		return common.Address{}, nil, false
+	}
+
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), tx.GasPrice())
+	feeRatio, _ := tx.FeeRatio()
+	feePayerFee, _ := types.CalcFeeWithRatio(feeRatio, fee)
+
+	var contract common.Address
+	if tx.Type().IsContractDeploy() {
+		contract = receipt.ContractAddress
+	} else if tx.Type().IsContractExecution() && tx.To() != nil {
+		contract = *tx.To()
+	}
+	return feePayer, &feePayerTx{BlockHash: blockHash, Sender: sender, Contract: contract, GasUsed: receipt.GasUsed, Fee: feePayerFee}, true
+}
+
+// indexFeePayerTxs stores the fee delegated transactions of the block to the fee payer index.
+func indexFeePayerTxs(db database.DBManager, block *types.Block, receipts types.Receipts) error {
+	if len(receipts) != len(block.Transactions()) {
+		return fmt.Errorf("the number of receipts %d does not match the number of transactions %d", len(receipts), len(block.Transactions()))
+	}
+	batch := db.NewFeePayerTxBatch()
+	for i, tx := range block.Transactions() {
+		feePayer, entry, ok := newFeePayerTx(block.Hash(), tx, receipts[i])
+		if !ok {
+			continue
+		}
+		data, err := rlp.EncodeToBytes(entry)
+		if err != nil {
+			return err
+		}
+		if err := db.PutFeePayerTxToBatch(batch, feePayer, block.NumberU64(), uint32(i), data); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// feePayerIndexer subscribes chainEvent and chainReorgEvent, and stores the fee delegated
+// transactions of the new canonical blocks to the fee payer index. The entries of the blocks
+// dropped by a reorg are left in the index, and skipped by the canonical hashes when read.
+func feePayerIndexer(db database.DBManager, chainEvent <-chan blockchain.ChainEvent, chainSub event.Subscription,
+	reorgEvent <-chan blockchain.ChainReorgEvent, reorgSub event.Subscription) {
+	defer chainSub.Unsubscribe()
+	defer reorgSub.Unsubscribe()
+
+	for {
+		select {
+		case ev := <-chainEvent:
+			if err := indexFeePayerTxs(db, ev.Block, ev.Receipts); err != nil {
+				logger.Error("Failed to store fee payer index to database", "blockNum", ev.Block.Number(), "err", err)
+			}
+
+		case ev := <-reorgEvent:
+			// The blocks of the new chain except its head are not sent as chain events
+			for _, hash := range ev.AddedBlocks {
+				block := db.ReadBlockByHash(hash)
+				if block == nil {
+					logger.Error("Failed to read the block added by a reorg", "hash", hash)
+					continue
+				}
+				if err := indexFeePayerTxs(db, block, db.ReadReceipts(hash, block.NumberU64())); err != nil {
+					logger.Error("Failed to store fee payer index to database", "blockNum", block.Number(), "err", err)
+				}
+			}
+
+		case <-chainSub.Err():
+			return
+		case <-reorgSub.Err():
+			return
+		}
+	}
+}
+
+// FeePayerUsage is the number of the transactions paid by a fee payer, their gas used and the fee
+// paid by the fee payer.
+type FeePayerUsage struct {
+	TxCount hexutil.Uint64 `json:"txCount"`
+	GasUsed hexutil.Uint64 `json:"gasUsed"`
+	Fee     *hexutil.Big   `json:"fee"`
+}
+
+func (u *FeePayerUsage) add(tx *feePayerTx) {
+	u.TxCount++
+	u.GasUsed += hexutil.Uint64(tx.GasUsed)
+	(*big.Int)(u.Fee).Add((*big.Int)(u.Fee), tx.Fee)
+}
+
+// FeePayerStatsResult is the usage of a fee payer in a range of blocks, in total and
+// per sender and per contract. The transactions not deploying or executing a contract
+// are not counted in the contracts.
+type FeePayerStatsResult struct {
+	FeePayer  common.Address                    `json:"feePayer"`
+	FromBlock hexutil.Uint64                    `json:"fromBlock"`
+	ToBlock   hexutil.Uint64                    `json:"toBlock"`
+	Total     *FeePayerUsage                    `json:"total"`
+	Senders   map[common.Address]*FeePayerUsage `json:"senders"`
+	Contracts map[common.Address]*FeePayerUsage `json:"contracts"`
+}
+
+func (r *FeePayerStatsResult) add(tx *feePayerTx) {
+	usage := func(m map[common.Address]*FeePayerUsage, addr common.Address) *FeePayerUsage {
+		if m[addr] == nil {
+			m[addr] = &FeePayerUsage{Fee: new(hexutil.Big)}
+		}
+		return m[addr]
+	}
+	r.Total.add(tx)
+	usage(r.Senders, tx.Sender).add(tx)
+	if tx.Contract != (common.Address{}) {
+		usage(r.Contracts, tx.Contract).add(tx)
+	}
+}
+
+// feePayerStats aggregates the usage of the fee payer in the blocks from the given range, both
+// inclusive. The blocks covered by the fee payer index are read from the index, and the others
+// are scanned up to feePayerStatsMaxScanBlocks.
+func (s *CN) feePayerStats(feePayer common.Address, from, to uint64) (*FeePayerStatsResult, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid block range: from %d > to %d", from, to)
+	}
+
+	// The blocks from indexFrom are read from the index
+	indexFrom := to + 1
+	if s.config.FeePayerIndexing {
+		tail, err := s.chainDB.ReadFeePayerIndexTail()
+		if err != nil {
+			return nil, err
+		}
+		if tail != 0 && tail < indexFrom {
+			indexFrom = tail
+		}
+		if indexFrom < from {
+			indexFrom = from
+		}
+	}
+	if indexFrom-from > feePayerStatsMaxScanBlocks {
+		return nil, fmt.Errorf("too many blocks not covered by the fee payer index (--feepayerindexing): %d > %d",
+			indexFrom-from, feePayerStatsMaxScanBlocks)
+	}
+
+	result := &FeePayerStatsResult{
+		FeePayer:  feePayer,
+		FromBlock: hexutil.Uint64(from),
+		ToBlock:   hexutil.Uint64(to),
+		Total:     &FeePayerUsage{Fee: new(hexutil.Big)},
+		Senders:   make(map[common.Address]*FeePayerUsage),
+		Contracts: make(map[common.Address]*FeePayerUsage),
+	}
+	for number := from; number < indexFrom; number++ {
+		hash := s.chainDB.ReadCanonicalHash(number)
+		block := s.chainDB.ReadBlock(hash, number)
+		if block == nil {
+			return nil, fmt.Errorf("block %d is not available", number)
+		}
+		receipts := s.chainDB.ReadReceipts(hash, number)
+		if len(receipts) != len(block.Transactions()) {
+			return nil, fmt.Errorf("receipts of block %d are not available", number)
+		}
+		for i, tx := range block.Transactions() {
+			if payer, entry, ok := newFeePayerTx(hash, tx, receipts[i]); ok && payer == feePayer {
+				result.add(entry)
+			}
+		}
+	}
+	if indexFrom > to {
+		return result, nil
+	}
+
+	var (
+		err       error
+		canonical = make(map[uint64]common.Hash)
+	)
+	s.chainDB.IterateFeePayerTxs(feePayer, indexFrom, func(blockNum uint64, txIndex uint32, data []byte) bool {
+		if blockNum > to {
+			return false
+		}
+		entry := new(feePayerTx)
+		if err = rlp.DecodeBytes(data, entry); err != nil {
+			return false
+		}
+		hash, ok := canonical[blockNum]
+		if !ok {
+			hash = s.chainDB.ReadCanonicalHash(blockNum)
+			canonical[blockNum] = hash
+		}
+		// The entries of the blocks dropped by reorgs are skipped
+		if entry.BlockHash == hash {
+			result.add(entry)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFeePayerTestTx(t *testing.T, txType types.TxType, from, feePayer common.Address, to *common.Address) *types.Transaction {
+	values := map[types.TxValueKeyType]interface{}{
+		types.TxValueKeyNonce:    uint64(0),
+		types.TxValueKeyAmount:   big.NewInt(0),
+		types.TxValueKeyGasLimit: uint64(100000),
+		types.TxValueKeyGasPrice: big.NewInt(10),
+		types.TxValueKeyFrom:     from,
+		types.TxValueKeyFeePayer: feePayer,
+	}
+	switch txType {
+	case types.TxTypeFeeDelegatedValueTransfer:
+		values[types.TxValueKeyTo] = *to
+	case types.TxTypeFeeDelegatedSmartContractExecutionWithRatio:
+		values[types.TxValueKeyTo] = *to
+		values[types.TxValueKeyData] = []byte{}
+		values[types.TxValueKeyFeeRatioOfFeePayer] = types.FeeRatio(30)
+	case types.TxTypeFeeDelegatedSmartContractDeploy:
+		values[types.TxValueKeyTo] = to
+		values[types.TxValueKeyData] = []byte{0x00}
+		values[types.TxValueKeyHumanReadable] = false
+		values[types.TxValueKeyCodeFormat] = params.CodeFormatEVM
+	}
+	tx, err := types.NewTransactionWithMap(txType, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestCN_FeePayerStats(t *testing.T) {
+	var (
+		db        = database.NewMemoryDBManager()
+		cn        = &CN{config: &Config{FeePayerIndexing: true}, chainDB: db}
+		feePayer  = common.Address{0x01}
+		other     = common.Address{0x02}
+		senderA   = common.Address{0x0a}
+		senderB   = common.Address{0x0b}
+		contractC = common.Address{0x0c}
+		contractD = common.Address{0x0d}
+	)
+	newBlock := func(number int64, extra byte, txs types.Transactions, gasUsed []uint64) (*types.Block, types.Receipts) {
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(number), Extra: []byte{extra}}).WithBody(txs)
+		receipts := make(types.Receipts, len(txs))
+		for i, tx := range txs {
+			receipts[i] = types.NewReceipt(types.ReceiptStatusSuccessful, tx.Hash(), gasUsed[i])
+			if tx.Type().IsContractDeploy() {
+				receipts[i].ContractAddress = contractD
+			}
+		}
+		return block, receipts
+	}
+	writeBlock := func(block *types.Block, receipts types.Receipts, canonical bool) {
+		db.WriteBlock(block)
+		db.WriteReceipts(block.Hash(), block.NumberU64(), receipts)
+		if canonical {
+			db.WriteCanonicalHash(block.Hash(), block.NumberU64())
+		}
+	}
+
+	// Block 1 is not indexed, and scanned by the receipts
+	legacy := types.NewTransaction(0, contractC, big.NewInt(0), 21000, big.NewInt(10), nil)
+	block1, receipts1 := newBlock(1, 0, types.Transactions{
+		legacy,
+		newFeePayerTestTx(t, types.TxTypeFeeDelegatedSmartContractExecutionWithRatio, senderA, feePayer, &contractC),
+	}, []uint64{21000, 100})
+	writeBlock(block1, receipts1, true)
+	assert.NoError(t, db.WriteFeePayerIndexTail(2))
+
+	block2, receipts2 := newBlock(2, 0, types.Transactions{
+		newFeePayerTestTx(t, types.TxTypeFeeDelegatedValueTransfer, senderB, feePayer, &senderA),
+		newFeePayerTestTx(t, types.TxTypeFeeDelegatedValueTransfer, senderB, other, &senderA),
+	}, []uint64{21000, 21000})
+	writeBlock(block2, receipts2, true)
+	assert.NoError(t, indexFeePayerTxs(db, block2, receipts2))
+
+	// The entries of the block dropped by a reorg are not counted
+	dropped, droppedReceipts := newBlock(3, 1, types.Transactions{
+		newFeePayerTestTx(t, types.TxTypeFeeDelegatedValueTransfer, senderB, feePayer, &senderA),
+		newFeePayerTestTx(t, types.TxTypeFeeDelegatedValueTransfer, senderB, feePayer, &senderA),
+	}, []uint64{21000, 21000})
+	writeBlock(dropped, droppedReceipts, false)
+	assert.NoError(t, indexFeePayerTxs(db, dropped, droppedReceipts))
+
+	block3, receipts3 := newBlock(3, 0, types.Transactions{
+		newFeePayerTestTx(t, types.TxTypeFeeDelegatedSmartContractDeploy, senderA, feePayer, nil),
+	}, []uint64{500})
+	writeBlock(block3, receipts3, true)
+	assert.NoError(t, indexFeePayerTxs(db, block3, receipts3))
+
+	usage := func(txCount, gasUsed, fee int64) *FeePayerUsage {
+		return &FeePayerUsage{TxCount: hexutil.Uint64(txCount), GasUsed: hexutil.Uint64(gasUsed), Fee: (*hexutil.Big)(big.NewInt(fee))}
+	}
+	expected := &FeePayerStatsResult{
+		FeePayer:  feePayer,
+		FromBlock: 1,
+		ToBlock:   3,
+		Total:     usage(3, 21600, 215300),
+		Senders: map[common.Address]*FeePayerUsage{
+			senderA: usage(2, 600, 5300),
+			senderB: usage(1, 21000, 210000),
+		},
+		Contracts: map[common.Address]*FeePayerUsage{
+			contractC: usage(1, 100, 300),
+			contractD: usage(1, 500, 5000),
+		},
+	}
+	stats, err := cn.feePayerStats(feePayer, 1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, stats)
+
+	// The same stats are aggregated by scanning all the blocks without the index
+	cn.config.FeePayerIndexing = false
+	stats, err = cn.feePayerStats(feePayer, 1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, stats)
+
+	stats, err = cn.feePayerStats(other, 2, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, usage(1, 21000, 210000), stats.Total)
+
+	_, err = cn.feePayerStats(feePayer, 3, 1)
+	assert.Error(t, err)
+	_, err = cn.feePayerStats(feePayer, 1, feePayerStatsMaxScanBlocks+1)
+	assert.Error(t, err)
+}
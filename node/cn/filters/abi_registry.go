@@ -0,0 +1,260 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/klaytn/klaytn/accounts/abi"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+)
+
+var errNoEventInABI = errors.New("abi has no event")
+
+// ABIRegistry keeps the ABIs of the contracts registered by the node operator, so that the logs of
+// the contracts are returned with their decoded events by the filter APIs. The registered ABIs are
+// saved in a directory, one file per contract, and loaded when the node restarts.
+type ABIRegistry struct {
+	dir string // directory saving the registered ABIs, or empty not to save them
+
+	mu   sync.RWMutex
+	abis map[common.Address]*abi.ABI
+}
+
+// DecodedEvent is an event log decoded by the ABI of the contract which emitted the log.
+// The parameters are keyed by their names in the ABI, or by argN if a parameter is unnamed.
+// The indexed parameters of dynamic types are the hashes of their values.
+type DecodedEvent struct {
+	Name      string                 `json:"name"`
+	Signature string                 `json:"signature"`
+	Params    map[string]interface{} `json:"params"`
+}
+
+// DecodedLog is a log returned with its decoded event if the ABI of the contract is registered.
+type DecodedLog struct {
+	*types.Log
+	Event *DecodedEvent
+}
+
+// MarshalJSON marshals the log with its decoded event in the "event" field.
+func (l *DecodedLog) MarshalJSON() ([]byte, error) {
+	enc, err := json.Marshal(l.Log)
+	if err != nil || l.Event == nil {
+		return enc, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(enc, &fields); err != nil {
+		return nil, err
+	}
+	if fields["event"], err = json.Marshal(l.Event); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// NewABIRegistry creates an ABI registry loading the ABIs saved in the given directory.
+// If the directory is empty, the registered ABIs are kept only in memory.
+func NewABIRegistry(dir string) (*ABIRegistry, error) {
+	r := &ABIRegistry{dir: dir, abis: make(map[common.Address]*abi.ABI)}
+	if dir == "" {
+		return r, nil
+	}
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return r, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(file.Name(), ".json")
+		if file.IsDir() || name == file.Name() || !common.IsHexAddress(name) {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := parseEventABI(string(data))
+		if err != nil {
+			logger.Warn("Failed to load a registered ABI", "file", file.Name(), "err", err)
+			continue
+		}
+		r.abis[common.HexToAddress(name)] = parsed
+	}
+	return r, nil
+}
+
+func parseEventABI(abiJSON string) (*abi.ABI, error) {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, err
+	}
+	if len(parsed.Events) == 0 {
+		return nil, errNoEventInABI
+	}
+	return &parsed, nil
+}
+
+// Register registers the ABI of the contract, replacing the one registered before.
+func (r *ABIRegistry) Register(addr common.Address, abiJSON string) error {
+	parsed, err := parseEventABI(abiJSON)
+	if err != nil {
+		return fmt.Errorf("invalid abi: %v", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.dir != "" {
+		if err := os.MkdirAll(r.dir, 0700); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(r.path(addr), []byte(abiJSON), 0600); err != nil {
+			return err
+		}
+	}
+	r.abis[addr] = parsed
+	return nil
+}
+
+// Unregister removes the ABI of the contract. It returns false if the ABI is not registered.
+func (r *ABIRegistry) Unregister(addr common.Address) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.abis[addr]; !ok {
+		return false, nil
+	}
+	if r.dir != "" {
+		if err := os.Remove(r.path(addr)); err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+	}
+	delete(r.abis, addr)
+	return true, nil
+}
+
+// Addresses returns the sorted addresses of the contracts whose ABIs are registered.
+func (r *ABIRegistry) Addresses() []common.Address {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	addrs := make([]common.Address, 0, len(r.abis))
+	for addr := range r.abis {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].Hex() < addrs[j].Hex() })
+	return addrs
+}
+
+// Len returns the number of the registered ABIs.
+func (r *ABIRegistry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.abis)
+}
+
+func (r *ABIRegistry) path(addr common.Address) string {
+	return filepath.Join(r.dir, strings.ToLower(addr.Hex())+".json")
+}
+
+// Decode decodes the event of the log. It returns nil if the ABI of the contract is not registered,
+// or the log does not match any non-anonymous event of the ABI.
+func (r *ABIRegistry) Decode(log *types.Log) *DecodedEvent {
+	if len(log.Topics) == 0 {
+		return nil
+	}
+	r.mu.RLock()
+	parsed, ok := r.abis[log.Address]
+	r.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	event, err := parsed.EventByID(log.Topics[0])
+	if err != nil || event.Anonymous {
+		return nil
+	}
+
+	var indexed abi.Arguments
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	values := make(map[string]interface{})
+	if err := abi.ParseTopicsIntoMap(values, indexed, log.Topics[1:]); err != nil {
+		return nil
+	}
+	if err := event.Inputs.NonIndexed().UnpackIntoMap(values, log.Data); err != nil {
+		return nil
+	}
+
+	params := make(map[string]interface{}, len(values))
+	for i, arg := range event.Inputs {
+		name := arg.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		params[name] = formatABIValue(values[arg.Name])
+	}
+	return &DecodedEvent{Name: event.RawName, Signature: event.Sig, Params: params}
+}
+
+// formatABIValue converts an unpacked value to be readable in JSON. The integers of more than
+// 64 bits are converted to decimal strings and the byte arrays are converted to hex strings.
+func formatABIValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *big.Int:
+		return v.String()
+	case []byte:
+		return hexutil.Bytes(v)
+	case common.Address, common.Hash, string, bool:
+		return v
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Array, reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return hexutil.Bytes(b)
+		}
+		values := make([]interface{}, rv.Len())
+		for i := range values {
+			values[i] = formatABIValue(rv.Index(i).Interface())
+		}
+		return values
+	case reflect.Struct:
+		fields := make(map[string]interface{}, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			fields[rv.Type().Field(i).Name] = formatABIValue(rv.Field(i).Interface())
+		}
+		return fields
+	}
+	return value
+}
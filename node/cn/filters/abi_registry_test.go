@@ -0,0 +1,196 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+const testTokenABI = `[
+	{"type":"event","name":"Transfer","anonymous":false,"inputs":[
+		{"indexed":true,"name":"from","type":"address"},
+		{"indexed":true,"name":"to","type":"address"},
+		{"indexed":false,"name":"value","type":"uint256"}]},
+	{"type":"event","name":"Memo","anonymous":false,"inputs":[
+		{"indexed":true,"name":"","type":"uint8"},
+		{"indexed":false,"name":"memo","type":"bytes"}]}
+]`
+
+var (
+	testToken     = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	testFrom      = common.HexToAddress("0x2000000000000000000000000000000000000002")
+	testTo        = common.HexToAddress("0x3000000000000000000000000000000000000003")
+	testTransfer  = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	testMemoEvent = crypto.Keccak256Hash([]byte("Memo(uint8,bytes)"))
+)
+
+func newTestTransferLog() *types.Log {
+	return &types.Log{
+		Address: testToken,
+		Topics:  []common.Hash{testTransfer, common.BytesToHash(testFrom.Bytes()), common.BytesToHash(testTo.Bytes())},
+		Data:    common.LeftPadBytes(big.NewInt(1000).Bytes(), 32),
+	}
+}
+
+func TestABIRegistry_Decode(t *testing.T) {
+	registry, err := NewABIRegistry("")
+	assert.NoError(t, err)
+	assert.Error(t, registry.Register(testToken, "invalid"))
+	assert.Error(t, registry.Register(testToken, `[{"type":"function","name":"f","inputs":[]}]`))
+	assert.NoError(t, registry.Register(testToken, testTokenABI))
+
+	event := registry.Decode(newTestTransferLog())
+	if assert.NotNil(t, event) {
+		assert.Equal(t, "Transfer", event.Name)
+		assert.Equal(t, "Transfer(address,address,uint256)", event.Signature)
+		assert.Equal(t, map[string]interface{}{"from": testFrom, "to": testTo, "value": "1000"}, event.Params)
+	}
+
+	// The unnamed parameters are keyed by their positions
+	memo := &types.Log{
+		Address: testToken,
+		Topics:  []common.Hash{testMemoEvent, common.BigToHash(big.NewInt(7))},
+		Data:    common.FromHex("0x000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000020102000000000000000000000000000000000000000000000000000000000000"),
+	}
+	event = registry.Decode(memo)
+	if assert.NotNil(t, event) {
+		assert.Equal(t, map[string]interface{}{"arg0": uint8(7), "memo": hexutil.Bytes{0x01, 0x02}}, event.Params)
+	}
+
+	// The logs of the unknown contracts or events, and the malformed logs are not decoded
+	unknown := newTestTransferLog()
+	unknown.Address = testTo
+	assert.Nil(t, registry.Decode(unknown))
+	unknown = newTestTransferLog()
+	unknown.Topics[0] = common.Hash{}
+	assert.Nil(t, registry.Decode(unknown))
+	unknown = newTestTransferLog()
+	unknown.Data = nil
+	assert.Nil(t, registry.Decode(unknown))
+	assert.Nil(t, registry.Decode(&types.Log{Address: testToken}))
+
+	removed, err := registry.Unregister(testToken)
+	assert.True(t, removed)
+	assert.NoError(t, err)
+	assert.Nil(t, registry.Decode(newTestTransferLog()))
+	removed, err = registry.Unregister(testToken)
+	assert.False(t, removed)
+	assert.NoError(t, err)
+}
+
+// TestABIRegistry_Persistence tests if the registered ABIs are loaded by a new registry.
+func TestABIRegistry_Persistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "klaytn-abi-registry")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	registry, err := NewABIRegistry(dir)
+	assert.NoError(t, err)
+	assert.NoError(t, registry.Register(testToken, testTokenABI))
+	assert.NoError(t, registry.Register(testTo, testTokenABI))
+	removed, err := registry.Unregister(testTo)
+	assert.True(t, removed)
+	assert.NoError(t, err)
+
+	reloaded, err := NewABIRegistry(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Address{testToken}, reloaded.Addresses())
+	assert.NotNil(t, reloaded.Decode(newTestTransferLog()))
+}
+
+// TestDecodedLogFilter tests if the filter changes have the decoded events only if an ABI is registered.
+func TestDecodedLogFilter(t *testing.T) {
+	var (
+		mux      = new(event.TypeMux)
+		db       = database.NewMemoryDBManager()
+		logsFeed = new(event.Feed)
+		backend  = &testBackend{mux, db, 0, new(event.Feed), new(event.Feed), logsFeed, new(event.Feed), new(event.Feed)}
+		api      = NewPublicFilterAPI(backend, false)
+		transfer = newTestTransferLog()
+		other    = &types.Log{Address: testTo, Topics: []common.Hash{testTransfer}}
+	)
+	registry, err := NewABIRegistry("")
+	assert.NoError(t, err)
+	api.SetABIRegistry(registry)
+
+	fetch := func(id rpc.ID, n int) interface{} {
+		var results interface{}
+		for timeout := time.Now().Add(time.Second); time.Now().Before(timeout); time.Sleep(10 * time.Millisecond) {
+			results, err = api.GetFilterChanges(id)
+			assert.NoError(t, err)
+			switch logs := results.(type) {
+			case []*types.Log:
+				if len(logs) == n {
+					return results
+				}
+			case []*DecodedLog:
+				if len(logs) == n {
+					return results
+				}
+			}
+		}
+		t.Fatalf("failed to fetch %d logs", n)
+		return nil
+	}
+
+	// The logs are returned as they are if no ABI is registered
+	id, err := api.NewFilter(FilterCriteria{})
+	assert.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	logsFeed.Send([]*types.Log{transfer, other})
+	assert.Equal(t, []*types.Log{transfer, other}, fetch(id, 2))
+
+	assert.NoError(t, registry.Register(testToken, testTokenABI))
+	logsFeed.Send([]*types.Log{transfer, other})
+	decoded, ok := fetch(id, 2).([]*DecodedLog)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, transfer, decoded[0].Log)
+	assert.Equal(t, "Transfer", decoded[0].Event.Name)
+	assert.Equal(t, other, decoded[1].Log)
+	assert.Nil(t, decoded[1].Event)
+
+	// The decoded event is added to the fields of the log
+	var fields map[string]interface{}
+	enc, err := json.Marshal(decoded[0])
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(enc, &fields))
+	assert.Equal(t, testToken.Hex(), fields["address"])
+	assert.Equal(t, map[string]interface{}{"name": "Transfer", "signature": "Transfer(address,address,uint256)",
+		"params": map[string]interface{}{"from": testFrom.Hex(), "to": testTo.Hex(), "value": "1000"}}, fields["event"])
+
+	fields = nil
+	enc, err = json.Marshal(decoded[1])
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(enc, &fields))
+	assert.NotContains(t, fields, "event")
+}
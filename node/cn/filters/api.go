@@ -30,6 +30,8 @@ import (
 	"time"
 
 	"github.com/klaytn/klaytn"
+	klayapi "github.com/klaytn/klaytn/api"
+	"github.com/klaytn/klaytn/blockchain"
 	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/common/hexutil"
@@ -45,8 +47,18 @@ var (
 	getLogsCxtKeyMaxItems = "maxItems"       // the value of the context key should have the type of GetLogsMaxItems
 	GetLogsDeadline       = 10 * time.Second // execution deadlines for getLogs and getFilterLogs APIs
 	GetLogsMaxItems       = int(10000)       // maximum allowed number of return items for getLogs and getFilterLogs APIs
+
+	defaultLogsPageLimit = 1000 // the default number of logs of a page of getLogsPage
 )
 
+var errInvalidLogsCursor = errors.New("invalid cursor")
+
+// LogsPage is a page of the logs returned by getLogsPage.
+type LogsPage struct {
+	Logs   interface{} `json:"logs"`
+	Cursor *string     `json:"cursor"` // nil if the page includes the last logs of the range
+}
+
 // filter is a helper struct that holds meta information over the filter type
 // and associated subscription in the event system.
 type filter struct {
@@ -68,6 +80,8 @@ type PublicFilterAPI struct {
 	events    *EventSystem
 	filtersMu sync.Mutex
 	filters   map[rpc.ID]*filter
+
+	abiRegistry *ABIRegistry // decodes the logs of the registered contracts if set
 }
 
 // NewPublicFilterAPI returns a new PublicFilterAPI instance.
@@ -84,6 +98,11 @@ func NewPublicFilterAPI(backend Backend, lightMode bool) *PublicFilterAPI {
 	return api
 }
 
+// SetABIRegistry sets the ABI registry by which the returned logs are decoded.
+func (api *PublicFilterAPI) SetABIRegistry(registry *ABIRegistry) {
+	api.abiRegistry = registry
+}
+
 // timeoutLoop runs every 5 minutes and deletes filters that have not been recently used.
 // Tt is started when the api is created.
 func (api *PublicFilterAPI) timeoutLoop() {
@@ -111,7 +130,7 @@ func (api *PublicFilterAPI) timeoutLoop() {
 // `klay_getFilterChanges` polling method that is also used for log filters.
 func (api *PublicFilterAPI) NewPendingTransactionFilter() rpc.ID {
 	var (
-		pendingTxs   = make(chan []common.Hash)
+		pendingTxs   = make(chan []*types.Transaction)
 		pendingTxSub = api.events.SubscribePendingTxs(pendingTxs)
 	)
 
@@ -122,10 +141,12 @@ func (api *PublicFilterAPI) NewPendingTransactionFilter() rpc.ID {
 	go func() {
 		for {
 			select {
-			case ph := <-pendingTxs:
+			case txs := <-pendingTxs:
 				api.filtersMu.Lock()
 				if f, found := api.filters[pendingTxSub.ID]; found {
-					f.hashes = append(f.hashes, ph...)
+					for _, tx := range txs {
+						f.hashes = append(f.hashes, tx.Hash())
+					}
 				}
 				api.filtersMu.Unlock()
 			case <-pendingTxSub.Err():
@@ -142,7 +163,8 @@ func (api *PublicFilterAPI) NewPendingTransactionFilter() rpc.ID {
 
 // NewPendingTransactions creates a subscription that is triggered each time a transaction
 // enters the transaction pool and was signed from one of the transactions this nodes manages.
-func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context) (*rpc.Subscription, error) {
+// The whole transactions are notified instead of their hashes if fullTx is true.
+func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
@@ -151,16 +173,20 @@ func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context) (*rpc.Su
 	rpcSub := notifier.CreateSubscription()
 
 	go func() {
-		txHashes := make(chan []common.Hash, 128)
-		pendingTxSub := api.events.SubscribePendingTxs(txHashes)
+		txsCh := make(chan []*types.Transaction, 128)
+		pendingTxSub := api.events.SubscribePendingTxs(txsCh)
 
 		for {
 			select {
-			case hashes := <-txHashes:
+			case txs := <-txsCh:
 				// To keep the original behaviour, send a single tx hash in one notification.
 				// TODO(rjl493456442) Send a batch of tx hashes in one notification
-				for _, h := range hashes {
-					notifier.Notify(rpcSub.ID, h)
+				for _, tx := range txs {
+					if fullTx != nil && *fullTx {
+						notifier.Notify(rpcSub.ID, klayapi.RpcOutputPendingTransaction(tx))
+					} else {
+						notifier.Notify(rpcSub.ID, tx.Hash())
+					}
 				}
 			case <-rpcSub.Err():
 				pendingTxSub.Unsubscribe()
@@ -238,6 +264,69 @@ func (api *PublicFilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, er
 	return rpcSub, nil
 }
 
+// ReorgResult is the notification of a chain reorg. The blocks are listed from the head to the child
+// of the common ancestor, and the transactions included in both chains are listed in neither of them.
+type ReorgResult struct {
+	OldHead        common.Hash    `json:"oldHead"`
+	OldNumber      hexutil.Uint64 `json:"oldNumber"`
+	NewHead        common.Hash    `json:"newHead"`
+	NewNumber      hexutil.Uint64 `json:"newNumber"`
+	CommonAncestor common.Hash    `json:"commonAncestor"`
+	CommonNumber   hexutil.Uint64 `json:"commonNumber"`
+	Depth          hexutil.Uint64 `json:"depth"`
+	DroppedBlocks  []common.Hash  `json:"droppedBlocks"`
+	AddedBlocks    []common.Hash  `json:"addedBlocks"`
+	DroppedTxs     []common.Hash  `json:"droppedTxs"`
+	AddedTxs       []common.Hash  `json:"addedTxs"`
+}
+
+func newReorgResult(ev blockchain.ChainReorgEvent) *ReorgResult {
+	return &ReorgResult{
+		OldHead:        ev.OldHead.Hash(),
+		OldNumber:      hexutil.Uint64(ev.OldHead.Number.Uint64()),
+		NewHead:        ev.NewHead.Hash(),
+		NewNumber:      hexutil.Uint64(ev.NewHead.Number.Uint64()),
+		CommonAncestor: ev.CommonAncestor.Hash(),
+		CommonNumber:   hexutil.Uint64(ev.CommonAncestor.Number.Uint64()),
+		Depth:          hexutil.Uint64(len(ev.DroppedBlocks)),
+		DroppedBlocks:  returnHashes(ev.DroppedBlocks),
+		AddedBlocks:    returnHashes(ev.AddedBlocks),
+		DroppedTxs:     returnHashes(ev.DroppedTxs),
+		AddedTxs:       returnHashes(ev.AddedTxs),
+	}
+}
+
+// Reorgs sends a notification each time the canonical chain is reorganized, so that the clients
+// can roll back exactly the dropped blocks and transactions.
+func (api *PublicFilterAPI) Reorgs(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		reorgs := make(chan blockchain.ChainReorgEvent)
+		reorgsSub := api.events.SubscribeReorgs(reorgs)
+
+		for {
+			select {
+			case ev := <-reorgs:
+				notifier.Notify(rpcSub.ID, newReorgResult(ev))
+			case <-rpcSub.Err():
+				reorgsSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				reorgsSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
 func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
@@ -261,7 +350,11 @@ func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc
 			select {
 			case logs := <-matchedLogs:
 				for _, log := range logs {
-					notifier.Notify(rpcSub.ID, &log)
+					if event := api.decodeEvent(log); event != nil {
+						notifier.Notify(rpcSub.ID, &DecodedLog{Log: log, Event: event})
+					} else {
+						notifier.Notify(rpcSub.ID, &log)
+					}
 				}
 			case <-rpcSub.Err(): // client send an unsubscribe request
 				logsSub.Unsubscribe()
@@ -324,7 +417,8 @@ func (api *PublicFilterAPI) NewFilter(crit FilterCriteria) (rpc.ID, error) {
 }
 
 // GetLogs returns logs matching the given argument that are stored within the state.
-func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*types.Log, error) {
+// The logs of the contracts whose ABIs are registered are returned with their decoded events.
+func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) (interface{}, error) {
 	ctx = context.WithValue(ctx, getLogsCxtKeyMaxItems, GetLogsMaxItems)
 	ctx, cancelFnc := context.WithTimeout(ctx, GetLogsDeadline)
 	defer cancelFnc()
@@ -344,7 +438,57 @@ func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([
 	if err != nil {
 		return nil, err
 	}
-	return returnLogs(logs), err
+	return api.decodeLogs(logs), err
+}
+
+// GetLogsPage returns a page of the logs matching the given criteria. A page contains at least
+// limit logs unless it is the last page, and ends at a block boundary. Cursor of the page is given
+// to the next call with the same criteria to retrieve the following logs, so the logs of a range
+// too large for getLogs can be retrieved over multiple calls.
+func (api *PublicFilterAPI) GetLogsPage(ctx context.Context, crit FilterCriteria, cursor *string, limit *int) (*LogsPage, error) {
+	ctx, cancelFnc := context.WithTimeout(ctx, GetLogsDeadline)
+	defer cancelFnc()
+
+	pageLimit := defaultLogsPageLimit
+	if limit != nil {
+		if *limit <= 0 || *limit > GetLogsMaxItems {
+			return nil, fmt.Errorf("limit should be in (0, %d]", GetLogsMaxItems)
+		}
+		pageLimit = *limit
+	}
+
+	// Resolve the range so that the cursors point to the same range
+	header, err := api.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if header == nil {
+		return nil, err
+	}
+	head := header.Number.Int64()
+	begin, end := head, head
+	if crit.FromBlock != nil && crit.FromBlock.Int64() >= 0 {
+		begin = crit.FromBlock.Int64()
+	}
+	if crit.ToBlock != nil && crit.ToBlock.Int64() >= 0 {
+		end = crit.ToBlock.Int64()
+	}
+	if cursor != nil {
+		next, err := hexutil.DecodeUint64(*cursor)
+		if err != nil || int64(next) < begin || int64(next) > end {
+			return nil, errInvalidLogsCursor
+		}
+		begin = int64(next)
+	}
+
+	filter := NewRangeFilter(api.backend, begin, end, crit.Addresses, crit.Topics)
+	logs, next, err := filter.LogsPage(ctx, pageLimit)
+	if err != nil {
+		return nil, err
+	}
+	page := &LogsPage{Logs: api.decodeLogs(logs)}
+	if next != nil {
+		encoded := hexutil.EncodeUint64(*next)
+		page.Cursor = &encoded
+	}
+	return page, nil
 }
 
 // UninstallFilter removes the filter with the given filter id.
@@ -364,7 +508,7 @@ func (api *PublicFilterAPI) UninstallFilter(id rpc.ID) bool {
 
 // GetFilterLogs returns the logs for the filter with the given id.
 // If the filter could not be found an empty array of logs is returned.
-func (api *PublicFilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*types.Log, error) {
+func (api *PublicFilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) (interface{}, error) {
 	ctx = context.WithValue(ctx, getLogsCxtKeyMaxItems, GetLogsMaxItems)
 	ctx, cancelFnc := context.WithTimeout(ctx, GetLogsDeadline)
 	defer cancelFnc()
@@ -392,7 +536,7 @@ func (api *PublicFilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*ty
 	if err != nil {
 		return nil, err
 	}
-	return returnLogs(logs), nil
+	return api.decodeLogs(logs), nil
 }
 
 // GetFilterChanges returns the logs for the filter with the given id since
@@ -420,7 +564,7 @@ func (api *PublicFilterAPI) GetFilterChanges(id rpc.ID) (interface{}, error) {
 		case LogsSubscription:
 			logs := f.logs
 			f.logs = nil
-			return returnLogs(logs), nil
+			return api.decodeLogs(logs), nil
 		}
 	}
 
@@ -445,6 +589,27 @@ func returnLogs(logs []*types.Log) []*types.Log {
 	return logs
 }
 
+// decodeEvent returns the decoded event of the log, or nil if it cannot be decoded.
+func (api *PublicFilterAPI) decodeEvent(log *types.Log) *DecodedEvent {
+	if api.abiRegistry == nil {
+		return nil
+	}
+	return api.abiRegistry.Decode(log)
+}
+
+// decodeLogs returns the logs as they are if no ABI is registered. Otherwise, it returns the logs
+// with the decoded events of the contracts whose ABIs are registered.
+func (api *PublicFilterAPI) decodeLogs(logs []*types.Log) interface{} {
+	if api.abiRegistry == nil || api.abiRegistry.Len() == 0 {
+		return returnLogs(logs)
+	}
+	decoded := make([]*DecodedLog, len(logs))
+	for i, log := range logs {
+		decoded[i] = &DecodedLog{Log: log, Event: api.abiRegistry.Decode(log)}
+	}
+	return decoded
+}
+
 // UnmarshalJSON sets *args fields with given data.
 func (args *FilterCriteria) UnmarshalJSON(data []byte) error {
 	type input struct {
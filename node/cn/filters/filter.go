@@ -25,6 +25,7 @@ import (
 	"errors"
 	"math"
 	"math/big"
+	"sort"
 	"strconv"
 
 	"github.com/klaytn/klaytn/blockchain"
@@ -47,10 +48,15 @@ type Backend interface {
 	SubscribeNewTxsEvent(chan<- blockchain.NewTxsEvent) event.Subscription
 	SubscribeChainEvent(ch chan<- blockchain.ChainEvent) event.Subscription
 	SubscribeRemovedLogsEvent(ch chan<- blockchain.RemovedLogsEvent) event.Subscription
+	SubscribeChainReorgEvent(ch chan<- blockchain.ChainReorgEvent) event.Subscription
 	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
 
 	BloomStatus() (uint64, uint64)
 	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
+
+	// LogIndexStatus returns the first and the last block numbers covered by the log index,
+	// or false if the log index is not available.
+	LogIndexStatus() (uint64, uint64, bool)
 }
 
 // Filter can be used to retrieve and filter logs.
@@ -62,6 +68,9 @@ type Filter struct {
 	topics     [][]common.Hash
 
 	matcher *bloombits.Matcher
+
+	limit int // the number of logs stopping the search at the block reaching it (0 = no limit)
+	found int // the number of logs found so far, compared with limit
 }
 
 // NewRangeFilter creates a new filter which uses a bloom filter on blocks to
@@ -125,6 +134,32 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	if f.end == -1 {
 		end = head
 	}
+	var (
+		logs []*types.Log
+		err  error
+	)
+	// Gather the logs of the blocks covered by the log index if the addresses are filtered,
+	// and search the rest with the bloom filters
+	if tail, head, ok := f.logIndexRange(end); ok {
+		if uint64(f.begin) < tail {
+			if logs, err = f.bloomLogs(ctx, tail-1); err != nil || f.limitReached() {
+				return logs, err
+			}
+		}
+		found, err := f.addressIndexedLogs(ctx, head)
+		logs = append(logs, found...)
+		if err != nil || f.limitReached() || f.begin > int64(end) {
+			return logs, err
+		}
+	}
+	rest, err := f.bloomLogs(ctx, end)
+	logs = append(logs, rest...)
+	return logs, err
+}
+
+// bloomLogs searches the blocks from the beginning of the filter to end with the bloombits
+// indexed by the bloom indexer, and finishes with the bloom filters of the non indexed blocks.
+func (f *Filter) bloomLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
 	// Gather all indexed logs, and finish with non indexed ones
 	var (
 		logs []*types.Log
@@ -137,7 +172,7 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 		} else {
 			logs, err = f.indexedLogs(ctx, indexed-1)
 		}
-		if err != nil {
+		if err != nil || f.limitReached() {
 			return logs, err
 		}
 	}
@@ -146,6 +181,123 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	return logs, err
 }
 
+// LogsPage searches the range of the filter for matching log entries like Logs, but stops
+// at the end of the block where the number of the logs reaches limit. It returns the number
+// of the block to continue the search from, or nil if the whole range is searched.
+// The end of the range should be a block number rather than the latest block.
+func (f *Filter) LogsPage(ctx context.Context, limit int) ([]*types.Log, *uint64, error) {
+	f.limit, f.found = limit, 0
+	logs, err := f.Logs(ctx)
+	if err != nil {
+		return logs, nil, err
+	}
+	if f.limitReached() && f.end >= 0 && f.begin <= f.end {
+		next := uint64(f.begin)
+		return logs, &next, nil
+	}
+	return logs, nil, nil
+}
+
+// logIndexRange returns the range of the blocks from the beginning of the filter to end covered
+// by the log index. It returns false if the addresses are not filtered or no block is covered.
+func (f *Filter) logIndexRange(end uint64) (uint64, uint64, bool) {
+	if len(f.addresses) == 0 {
+		return 0, 0, false
+	}
+	tail, head, ok := f.backend.LogIndexStatus()
+	if !ok || tail > end || head < uint64(f.begin) || tail > head {
+		return 0, 0, false
+	}
+	if head > end {
+		head = end
+	}
+	return tail, head, true
+}
+
+// indexedBlocks returns the numbers of the canonical blocks from the beginning of the filter to
+// end containing the logs emitted by the filtered addresses, read from the log index. If the
+// first topics are filtered, the blocks are looked up by the pairs of the addresses and the topics.
+func (f *Filter) indexedBlocks(end uint64) []uint64 {
+	var (
+		db        = f.backend.ChainDB()
+		numbers   []uint64
+		seen      = make(map[uint64]struct{})
+		canonical = make(map[uint64]common.Hash)
+		topics    = []*common.Hash{nil}
+	)
+	if len(f.topics) > 0 && len(f.topics[0]) > 0 {
+		topics = make([]*common.Hash, len(f.topics[0]))
+		for i := range f.topics[0] {
+			topics[i] = &f.topics[0][i]
+		}
+	}
+	for _, addr := range f.addresses {
+		for _, topic := range topics {
+			db.IterateLogBlocks(addr, topic, uint64(f.begin), func(blockNum uint64, blockHash common.Hash) bool {
+				if blockNum > end {
+					return false
+				}
+				hash, ok := canonical[blockNum]
+				if !ok {
+					hash = db.ReadCanonicalHash(blockNum)
+					canonical[blockNum] = hash
+				}
+				// The entries of the blocks dropped by reorgs are skipped
+				if _, ok := seen[blockNum]; !ok && blockHash == hash {
+					seen[blockNum] = struct{}{}
+					numbers = append(numbers, blockNum)
+				}
+				return true
+			})
+		}
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+	return numbers
+}
+
+// addressIndexedLogs returns the logs matching the filter criteria in the blocks found by
+// the log index from the beginning of the filter to end.
+func (f *Filter) addressIndexedLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
+	var logs []*types.Log
+
+	maxItems := getMaxItems(ctx)
+
+	for _, number := range f.indexedBlocks(end) {
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return logs, errors.New("query timeout exceeded")
+			}
+			return logs, errors.New("query is canceled. " + ctx.Err().Error())
+		default:
+		}
+		f.begin = int64(number) + 1
+
+		header, err := f.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if header == nil || err != nil {
+			return logs, err
+		}
+		found, err := f.checkMatches(ctx, header)
+		if err != nil {
+			return logs, err
+		}
+		logs = append(logs, found...)
+		if len(logs) > maxItems {
+			return logs, errors.New("query returned more than " + strconv.Itoa(maxItems) + " results")
+		}
+		if f.found += len(found); f.limitReached() {
+			return logs, nil
+		}
+	}
+	f.begin = int64(end) + 1
+	return logs, nil
+}
+
+// limitReached returns true if the found logs reach the limit of the filter.
+func (f *Filter) limitReached() bool {
+	return f.limit > 0 && f.found >= f.limit
+}
+
 // indexedLogs returns the logs matching the filter criteria based on the bloom
 // bits indexed available locally or via the network.
 func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
@@ -191,6 +343,9 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, err
 			if len(logs) > maxItems {
 				return logs, errors.New("query returned more than " + strconv.Itoa(maxItems) + " results")
 			}
+			if f.found += len(found); f.limitReached() {
+				return logs, nil
+			}
 		case <-ctx.Done():
 			if ctx.Err() == context.DeadlineExceeded {
 				return logs, errors.New("query timeout exceeded")
@@ -221,6 +376,10 @@ func (f *Filter) unindexedLogs(ctx context.Context, end uint64) ([]*types.Log, e
 			if len(logs) > maxItems {
 				return logs, errors.New("query returned more than " + strconv.Itoa(maxItems) + " results")
 			}
+			if f.found += len(found); f.limitReached() {
+				f.begin++
+				return logs, nil
+			}
 		}
 		select {
 		case <-ctx.Done():
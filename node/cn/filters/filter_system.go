@@ -49,11 +49,13 @@ const (
 	PendingLogsSubscription
 	// MinedAndPendingLogsSubscription queries for logs in mined and pending blocks.
 	MinedAndPendingLogsSubscription
-	// PendingTransactionsSubscription queries tx hashes for pending
+	// PendingTransactionsSubscription queries txs for pending
 	// transactions entering the pending state
 	PendingTransactionsSubscription
 	// BlocksSubscription queries hashes for blocks that are imported
 	BlocksSubscription
+	// ReorgsSubscription queries the dropped and added blocks and transactions of chain reorgs
+	ReorgsSubscription
 	// LastSubscription keeps track of the last index
 	LastIndexSubscription
 )
@@ -69,6 +71,8 @@ const (
 	logsChanSize = 10
 	// chainEvChanSize is the size of channel listening to ChainEvent.
 	chainEvChanSize = 10
+	// reorgChanSize is the size of channel listening to ChainReorgEvent.
+	reorgChanSize = 10
 )
 
 var (
@@ -82,8 +86,9 @@ type subscription struct {
 	created   time.Time
 	logsCrit  klaytn.FilterQuery
 	logs      chan []*types.Log
-	hashes    chan []common.Hash
+	txs       chan []*types.Transaction
 	headers   chan *types.Header
+	reorgs    chan blockchain.ChainReorgEvent
 	installed chan struct{} // closed when the filter is installed
 	err       chan error    // closed when the filter is uninstalled
 }
@@ -101,6 +106,7 @@ type EventSystem struct {
 	logsSub       event.Subscription         // Subscription for new log event
 	rmLogsSub     event.Subscription         // Subscription for removed log event
 	chainSub      event.Subscription         // Subscription for new chain event
+	reorgSub      event.Subscription         // Subscription for chain reorg event
 	pendingLogSub *event.TypeMuxSubscription // Subscription for pending log event
 
 	// Channels
@@ -110,6 +116,7 @@ type EventSystem struct {
 	logsCh    chan []*types.Log                // Channel to receive new log event
 	rmLogsCh  chan blockchain.RemovedLogsEvent // Channel to receive removed log event
 	chainCh   chan blockchain.ChainEvent       // Channel to receive new chain event
+	reorgCh   chan blockchain.ChainReorgEvent  // Channel to receive chain reorg event
 }
 
 // NewEventSystem creates a new manager that listens for event on the given mux,
@@ -129,6 +136,7 @@ func NewEventSystem(mux *event.TypeMux, backend Backend, lightMode bool) *EventS
 		logsCh:    make(chan []*types.Log, logsChanSize),
 		rmLogsCh:  make(chan blockchain.RemovedLogsEvent, rmLogsChanSize),
 		chainCh:   make(chan blockchain.ChainEvent, chainEvChanSize),
+		reorgCh:   make(chan blockchain.ChainReorgEvent, reorgChanSize),
 	}
 
 	// Subscribe events
@@ -136,12 +144,13 @@ func NewEventSystem(mux *event.TypeMux, backend Backend, lightMode bool) *EventS
 	m.logsSub = m.backend.SubscribeLogsEvent(m.logsCh)
 	m.rmLogsSub = m.backend.SubscribeRemovedLogsEvent(m.rmLogsCh)
 	m.chainSub = m.backend.SubscribeChainEvent(m.chainCh)
+	m.reorgSub = m.backend.SubscribeChainReorgEvent(m.reorgCh)
 	// TODO(rjl493456442): use feed to subscribe pending log event
 	m.pendingLogSub = m.mux.Subscribe(blockchain.PendingLogsEvent{})
 
 	// Make sure none of the subscriptions are empty
 	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil ||
-		m.pendingLogSub.Closed() {
+		m.reorgSub == nil || m.pendingLogSub.Closed() {
 		logger.Crit("Subscribe for event system failed")
 	}
 
@@ -175,8 +184,9 @@ func (sub *Subscription) Unsubscribe() {
 			case sub.es.uninstall <- sub.f:
 				break uninstallLoop
 			case <-sub.f.logs:
-			case <-sub.f.hashes:
+			case <-sub.f.txs:
 			case <-sub.f.headers:
+			case <-sub.f.reorgs:
 			}
 		}
 
@@ -242,8 +252,9 @@ func (es *EventSystem) subscribeMinedPendingLogs(crit klaytn.FilterQuery, logs c
 		logsCrit:  crit,
 		created:   time.Now(),
 		logs:      logs,
-		hashes:    make(chan []common.Hash),
+		txs:       make(chan []*types.Transaction),
 		headers:   make(chan *types.Header),
+		reorgs:    make(chan blockchain.ChainReorgEvent),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -259,8 +270,9 @@ func (es *EventSystem) subscribeLogs(crit klaytn.FilterQuery, logs chan []*types
 		logsCrit:  crit,
 		created:   time.Now(),
 		logs:      logs,
-		hashes:    make(chan []common.Hash),
+		txs:       make(chan []*types.Transaction),
 		headers:   make(chan *types.Header),
+		reorgs:    make(chan blockchain.ChainReorgEvent),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -276,8 +288,9 @@ func (es *EventSystem) subscribePendingLogs(crit klaytn.FilterQuery, logs chan [
 		logsCrit:  crit,
 		created:   time.Now(),
 		logs:      logs,
-		hashes:    make(chan []common.Hash),
+		txs:       make(chan []*types.Transaction),
 		headers:   make(chan *types.Header),
+		reorgs:    make(chan blockchain.ChainReorgEvent),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -292,24 +305,43 @@ func (es *EventSystem) SubscribeNewHeads(headers chan *types.Header) *Subscripti
 		typ:       BlocksSubscription,
 		created:   time.Now(),
 		logs:      make(chan []*types.Log),
-		hashes:    make(chan []common.Hash),
+		txs:       make(chan []*types.Transaction),
 		headers:   headers,
+		reorgs:    make(chan blockchain.ChainReorgEvent),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
 	return es.subscribe(sub)
 }
 
-// SubscribePendingTxs creates a subscription that writes transaction hashes for
+// SubscribePendingTxs creates a subscription that writes transactions for
 // transactions that enter the transaction pool.
-func (es *EventSystem) SubscribePendingTxs(hashes chan []common.Hash) *Subscription {
+func (es *EventSystem) SubscribePendingTxs(txs chan []*types.Transaction) *Subscription {
 	sub := &subscription{
 		id:        rpc.NewID(),
 		typ:       PendingTransactionsSubscription,
 		created:   time.Now(),
 		logs:      make(chan []*types.Log),
-		hashes:    hashes,
+		txs:       txs,
 		headers:   make(chan *types.Header),
+		reorgs:    make(chan blockchain.ChainReorgEvent),
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+// SubscribeReorgs creates a subscription that writes the dropped and added blocks and
+// transactions whenever the canonical chain is reorganized.
+func (es *EventSystem) SubscribeReorgs(reorgs chan blockchain.ChainReorgEvent) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       ReorgsSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		txs:       make(chan []*types.Transaction),
+		headers:   make(chan *types.Header),
+		reorgs:    reorgs,
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -351,12 +383,8 @@ func (es *EventSystem) broadcast(filters filterIndex, ev interface{}) {
 			}
 		}
 	case blockchain.NewTxsEvent:
-		hashes := make([]common.Hash, 0, len(e.Txs))
-		for _, tx := range e.Txs {
-			hashes = append(hashes, tx.Hash())
-		}
 		for _, f := range filters[PendingTransactionsSubscription] {
-			f.hashes <- hashes
+			f.txs <- e.Txs
 		}
 	case blockchain.ChainEvent:
 		for _, f := range filters[BlocksSubscription] {
@@ -371,6 +399,10 @@ func (es *EventSystem) broadcast(filters filterIndex, ev interface{}) {
 				}
 			})
 		}
+	case blockchain.ChainReorgEvent:
+		for _, f := range filters[ReorgsSubscription] {
+			f.reorgs <- e
+		}
 	}
 }
 
@@ -453,6 +485,7 @@ func (es *EventSystem) eventLoop() {
 		es.logsSub.Unsubscribe()
 		es.rmLogsSub.Unsubscribe()
 		es.chainSub.Unsubscribe()
+		es.reorgSub.Unsubscribe()
 	}()
 
 	index := make(filterIndex)
@@ -471,6 +504,8 @@ func (es *EventSystem) eventLoop() {
 			es.broadcast(index, ev)
 		case ev := <-es.chainCh:
 			es.broadcast(index, ev)
+		case ev := <-es.reorgCh:
+			es.broadcast(index, ev)
 		case ev, active := <-es.pendingLogSub.Chan():
 			if !active { // system stopped
 				return
@@ -506,6 +541,8 @@ func (es *EventSystem) eventLoop() {
 			return
 		case <-es.chainSub.Err():
 			return
+		case <-es.reorgSub.Err():
+			return
 		}
 	}
 }
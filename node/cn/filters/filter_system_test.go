@@ -34,6 +34,7 @@ import (
 	"github.com/klaytn/klaytn/blockchain/bloombits"
 	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
 	"github.com/klaytn/klaytn/consensus/gxhash"
 	"github.com/klaytn/klaytn/event"
 	"github.com/klaytn/klaytn/networks/rpc"
@@ -49,6 +50,7 @@ type testBackend struct {
 	rmLogsFeed *event.Feed
 	logsFeed   *event.Feed
 	chainFeed  *event.Feed
+	reorgFeed  *event.Feed
 }
 
 /*
@@ -131,10 +133,20 @@ func (b *testBackend) SubscribeChainEvent(ch chan<- blockchain.ChainEvent) event
 	return b.chainFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeChainReorgEvent(ch chan<- blockchain.ChainReorgEvent) event.Subscription {
+	return b.reorgFeed.Subscribe(ch)
+}
+
 func (b *testBackend) BloomStatus() (uint64, uint64) {
 	return params.BloomBitsBlocks, b.sections
 }
 
+func (b *testBackend) LogIndexStatus() (uint64, uint64, bool) {
+	tail, _ := b.db.ReadLogIndexTail()
+	head, _ := b.db.ReadLogIndexHead()
+	return tail, head, tail != 0
+}
+
 func (b *testBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
 	requests := make(chan chan *bloombits.Retrieval)
 
@@ -177,7 +189,7 @@ func TestBlockSubscription(t *testing.T) {
 		rmLogsFeed  = new(event.Feed)
 		logsFeed    = new(event.Feed)
 		chainFeed   = new(event.Feed)
-		backend     = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend     = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api         = NewPublicFilterAPI(backend, false)
 		genesis     = new(blockchain.Genesis).MustCommit(db)
 		chain, _    = blockchain.GenerateChain(params.TestChainConfig, genesis, gxhash.NewFaker(), db, 10, func(i int, gen *blockchain.BlockGen) {})
@@ -223,6 +235,60 @@ func TestBlockSubscription(t *testing.T) {
 	<-sub1.Err()
 }
 
+// TestReorgSubscription tests if a reorg subscription returns the posted chain reorg events
+// and stops receiving them after it is unsubscribed.
+func TestReorgSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mux         = new(event.TypeMux)
+		db          = database.NewMemoryDBManager()
+		reorgFeed   = new(event.Feed)
+		backend     = &testBackend{mux, db, 0, new(event.Feed), new(event.Feed), new(event.Feed), new(event.Feed), reorgFeed}
+		api         = NewPublicFilterAPI(backend, false)
+		genesis     = new(blockchain.Genesis).MustCommit(db)
+		oldChain, _ = blockchain.GenerateChain(params.TestChainConfig, genesis, gxhash.NewFaker(), db, 2, func(i int, gen *blockchain.BlockGen) {})
+		newChain, _ = blockchain.GenerateChain(params.TestChainConfig, genesis, gxhash.NewFaker(), db, 3, func(i int, gen *blockchain.BlockGen) {
+			gen.SetExtra([]byte("fork"))
+		})
+		reorgEvent = blockchain.ChainReorgEvent{
+			OldHead:        oldChain[1].Header(),
+			NewHead:        newChain[2].Header(),
+			CommonAncestor: genesis.Header(),
+			DroppedBlocks:  []common.Hash{oldChain[1].Hash(), oldChain[0].Hash()},
+			AddedBlocks:    []common.Hash{newChain[2].Hash(), newChain[1].Hash(), newChain[0].Hash()},
+		}
+	)
+
+	reorgs := make(chan blockchain.ChainReorgEvent)
+	sub := api.events.SubscribeReorgs(reorgs)
+
+	time.Sleep(1 * time.Second)
+	reorgFeed.Send(reorgEvent)
+
+	select {
+	case ev := <-reorgs:
+		if !reflect.DeepEqual(reorgEvent, ev) {
+			t.Errorf("received invalid reorg event, want %v, got %v", reorgEvent, ev)
+		}
+		result := newReorgResult(ev)
+		if result.Depth != 2 || result.CommonNumber != 0 || result.NewNumber != 3 || result.OldHead != oldChain[1].Hash() {
+			t.Errorf("invalid reorg result %+v", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("reorg event is not received")
+	}
+
+	// The reorg events after unsubscribing should not block the event loop
+	sub.Unsubscribe()
+	reorgFeed.Send(reorgEvent)
+	select {
+	case ev := <-reorgs:
+		t.Errorf("received reorg event after unsubscribing %v", ev)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
 // TestPendingTxFilter tests whether pending tx filters retrieve all pending transactions that are posted to the event mux.
 func TestPendingTxFilter(t *testing.T) {
 	t.Parallel()
@@ -234,7 +300,7 @@ func TestPendingTxFilter(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 
 		transactions = []*types.Transaction{
@@ -284,6 +350,68 @@ func TestPendingTxFilter(t *testing.T) {
 	}
 }
 
+// TestPendingTxSubscription tests whether pending tx subscriptions notify the hashes of the pending
+// transactions, or the whole transactions if it is requested.
+func TestPendingTxSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mux     = new(event.TypeMux)
+		db      = database.NewMemoryDBManager()
+		txFeed  = new(event.Feed)
+		backend = &testBackend{mux, db, 0, txFeed, new(event.Feed), new(event.Feed), new(event.Feed), new(event.Feed)}
+		api     = NewPublicFilterAPI(backend, false)
+
+		transactions = []*types.Transaction{
+			types.NewTransaction(0, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, new(big.Int), nil),
+			types.NewTransaction(1, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, new(big.Int), nil),
+		}
+	)
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("klay", api); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	hashes := make(chan common.Hash, len(transactions))
+	hashSub, err := client.KlaySubscribe(context.Background(), hashes, "newPendingTransactions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hashSub.Unsubscribe()
+	txs := make(chan map[string]interface{}, len(transactions))
+	txSub, err := client.KlaySubscribe(context.Background(), txs, "newPendingTransactions", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer txSub.Unsubscribe()
+
+	time.Sleep(1 * time.Second)
+	txFeed.Send(blockchain.NewTxsEvent{Txs: transactions})
+
+	for i, tx := range transactions {
+		select {
+		case hash := <-hashes:
+			if hash != tx.Hash() {
+				t.Errorf("hashes[%d] invalid, want %x, got %x", i, tx.Hash(), hash)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("hash %d is not received", i)
+		}
+		select {
+		case output := <-txs:
+			if output["hash"] != tx.Hash().Hex() || output["nonce"] != hexutil.EncodeUint64(tx.Nonce()) {
+				t.Errorf("txs[%d] invalid, want %x, got %v", i, tx.Hash(), output)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("transaction %d is not received", i)
+		}
+	}
+}
+
 // TestLogFilterCreation test whether a given filter criteria makes sense.
 // If not it must return an error.
 func TestLogFilterCreation(t *testing.T) {
@@ -294,7 +422,7 @@ func TestLogFilterCreation(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 
 		testCases = []struct {
@@ -343,7 +471,7 @@ func TestInvalidLogFilterCreation(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 	)
 
@@ -372,7 +500,7 @@ func TestInvalidGetLogsRequest(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 		blockHash  = common.HexToHash("0x1111111111111111111111111111111111111111111111111111111111111111")
 	)
@@ -403,7 +531,7 @@ func TestLogFilter(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 
 		firstAddr      = common.HexToAddress("0x1111111111111111111111111111111111111111")
@@ -522,7 +650,7 @@ func TestPendingLogsSubscription(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 
 		firstAddr      = common.HexToAddress("0x1111111111111111111111111111111111111111")
@@ -169,7 +169,7 @@ func BenchmarkFilters(b *testing.B) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		key1, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
 		addr1      = crypto.PubkeyToAddress(key1.PublicKey)
 		addr2      = common.BytesToAddress([]byte("jeff"))
@@ -232,7 +232,7 @@ func TestFilters(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		key1, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
 		addr       = crypto.PubkeyToAddress(key1.PublicKey)
 
@@ -350,4 +350,111 @@ func TestFilters(t *testing.T) {
 	if len(logs) != 0 {
 		t.Error("expected 0 log, got", len(logs))
 	}
+
+	// The pages end at the blocks where the number of the logs reaches the limit
+	var (
+		pages  [][]*types.Log
+		cursor = uint64(0)
+	)
+	for {
+		filter = NewRangeFilter(backend, int64(cursor), 1000, []common.Address{addr}, nil)
+		logs, next, err := filter.LogsPage(context.Background(), 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, logs)
+		if next == nil {
+			break
+		}
+		if *next != 1000 {
+			t.Errorf("expected the next block 1000, got %d", *next)
+		}
+		cursor = *next
+	}
+	if len(pages) != 2 || len(pages[0]) != 3 || len(pages[1]) != 1 {
+		t.Errorf("expected pages of 3 and 1 logs, got %d pages", len(pages))
+	}
+	if len(pages) == 2 && len(pages[1]) == 1 && pages[1][0].Topics[0] != hash4 {
+		t.Errorf("expected the last log to be %x, got %x", hash4, pages[1][0].Topics[0])
+	}
+}
+
+func TestFilters_LogIndex(t *testing.T) {
+	var (
+		db         = database.NewMemoryDBManager()
+		mux        = new(event.TypeMux)
+		txFeed     = new(event.Feed)
+		rmLogsFeed = new(event.Feed)
+		logsFeed   = new(event.Feed)
+		chainFeed  = new(event.Feed)
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
+		key1, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr       = crypto.PubkeyToAddress(key1.PublicKey)
+
+		hash1 = common.BytesToHash([]byte("topic1"))
+		hash2 = common.BytesToHash([]byte("topic2"))
+		hash3 = common.BytesToHash([]byte("topic3"))
+	)
+	defer db.Close()
+
+	logBlocks := map[int]common.Hash{10: hash1, 20: hash2, 30: hash1, 90: hash3}
+	genesis := blockchain.GenesisBlockForTesting(db, addr, big.NewInt(1000000))
+	chain, receipts := blockchain.GenerateChain(params.TestChainConfig, genesis, gxhash.NewFaker(), db, 100, func(i int, gen *blockchain.BlockGen) {
+		if topic, ok := logBlocks[i+1]; ok {
+			receipt := genReceipt(false, 0)
+			receipt.Logs = []*types.Log{{Address: addr, Topics: []common.Hash{topic}, Data: []byte{byte(i + 1)}}}
+			gen.AddUncheckedReceipt(receipt)
+			gen.AddUncheckedTx(types.NewTransaction(uint64(i), common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), nil))
+		}
+	})
+	for i, block := range chain {
+		db.WriteBlock(block)
+		db.WriteCanonicalHash(block.Hash(), block.NumberU64())
+		db.WriteHeadBlockHash(block.Hash())
+		db.WriteReceipts(block.Hash(), block.NumberU64(), receipts[i])
+	}
+
+	// The blocks from 5 to 50 are indexed, where the entry of the block 20 is dropped by a reorg
+	batch := db.NewLogIndexBatch()
+	for _, number := range []uint64{10, 30} {
+		assert.NoError(t, db.PutLogAddressToBatch(batch, addr, number, chain[number-1].Hash()))
+		assert.NoError(t, db.PutLogTopicToBatch(batch, addr, logBlocks[int(number)], number, chain[number-1].Hash()))
+	}
+	assert.NoError(t, db.PutLogAddressToBatch(batch, addr, 20, common.Hash{1}))
+	assert.NoError(t, batch.Write())
+	assert.NoError(t, db.WriteLogIndexTail(5))
+	assert.NoError(t, db.WriteLogIndexHead(50))
+
+	// The data of a log is the number of its block
+	blockNumbers := func(logs []*types.Log) []uint64 {
+		var numbers []uint64
+		for _, l := range logs {
+			numbers = append(numbers, uint64(l.Data[0]))
+		}
+		return numbers
+	}
+
+	// The blocks not found by the index are skipped
+	logs, err := NewRangeFilter(backend, 0, -1, []common.Address{addr}, nil).Logs(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{10, 30, 90}, blockNumbers(logs))
+
+	// The blocks are looked up with the first topic
+	logs, err = NewRangeFilter(backend, 0, 100, []common.Address{addr}, [][]common.Hash{{hash1}}).Logs(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{10, 30}, blockNumbers(logs))
+
+	// The blocks are searched with the bloom filters if the addresses are not filtered
+	logs, err = NewRangeFilter(backend, 0, 100, nil, [][]common.Hash{{hash2}}).Logs(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{20}, blockNumbers(logs))
+
+	// The pages end at the blocks found by the index
+	filter := NewRangeFilter(backend, 0, 100, []common.Address{addr}, nil)
+	logs, next, err := filter.LogsPage(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{10}, blockNumbers(logs))
+	if assert.NotNil(t, next) {
+		assert.Equal(t, uint64(11), *next)
+	}
 }
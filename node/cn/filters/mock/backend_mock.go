@@ -128,6 +128,22 @@ func (mr *MockBackendMockRecorder) HeaderByNumber(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeaderByNumber", reflect.TypeOf((*MockBackend)(nil).HeaderByNumber), arg0, arg1)
 }
 
+// LogIndexStatus mocks base method
+func (m *MockBackend) LogIndexStatus() (uint64, uint64, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogIndexStatus")
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(uint64)
+	ret2, _ := ret[2].(bool)
+	return ret0, ret1, ret2
+}
+
+// LogIndexStatus indicates an expected call of LogIndexStatus
+func (mr *MockBackendMockRecorder) LogIndexStatus() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogIndexStatus", reflect.TypeOf((*MockBackend)(nil).LogIndexStatus))
+}
+
 // ServiceFilter mocks base method
 func (m *MockBackend) ServiceFilter(arg0 context.Context, arg1 *bloombits.MatcherSession) {
 	m.ctrl.T.Helper()
@@ -154,6 +170,20 @@ func (mr *MockBackendMockRecorder) SubscribeChainEvent(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeChainEvent", reflect.TypeOf((*MockBackend)(nil).SubscribeChainEvent), arg0)
 }
 
+// SubscribeChainReorgEvent mocks base method
+func (m *MockBackend) SubscribeChainReorgEvent(arg0 chan<- blockchain.ChainReorgEvent) event.Subscription {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeChainReorgEvent", arg0)
+	ret0, _ := ret[0].(event.Subscription)
+	return ret0
+}
+
+// SubscribeChainReorgEvent indicates an expected call of SubscribeChainReorgEvent
+func (mr *MockBackendMockRecorder) SubscribeChainReorgEvent(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeChainReorgEvent", reflect.TypeOf((*MockBackend)(nil).SubscribeChainReorgEvent), arg0)
+}
+
 // SubscribeLogsEvent mocks base method
 func (m *MockBackend) SubscribeLogsEvent(arg0 chan<- []*types.Log) event.Subscription {
 	m.ctrl.T.Helper()
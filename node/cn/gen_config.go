@@ -14,6 +14,7 @@ import (
 	"github.com/klaytn/klaytn/node/cn/gasprice"
 	"github.com/klaytn/klaytn/storage/database"
 	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/klaytn/klaytn/work"
 )
 
 var _ = (*configMarshaling)(nil)
@@ -21,48 +22,74 @@ var _ = (*configMarshaling)(nil)
 // MarshalTOML marshals as TOML.
 func (c Config) MarshalTOML() (interface{}, error) {
 	type Config struct {
-		Genesis                 *blockchain.Genesis `toml:",omitempty"`
-		NetworkId               uint64
-		SyncMode                downloader.SyncMode
-		NoPruning               bool
-		ParentOperatorAddr      *common.Address `toml:",omitempty"`
-		AnchoringPeriod         uint64
-		SentChainTxsLimit       uint64
-		OverwriteGenesis        bool
-		DBType                  database.DBType
-		SkipBcVersionCheck      bool `toml:"-"`
-		SingleDB                bool
-		NumStateTrieShards      uint
-		LevelDBCompression      database.LevelDBCompressionType
-		LevelDBBufferPool       bool
-		LevelDBCacheSize        int
-		DynamoDBConfig          database.DynamoDBConfig
-		TrieCacheSize           int
-		TrieTimeout             time.Duration
-		TrieBlockInterval       uint
-		TriesInMemory           uint64
-		SenderTxHashIndexing    bool
-		ParallelDBWrite         bool
-		TrieNodeCacheConfig     statedb.TrieNodeCacheConfig
-		ServiceChainSigner      common.Address `toml:",omitempty"`
-		ExtraData               hexutil.Bytes  `toml:",omitempty"`
-		GasPrice                *big.Int
-		Rewardbase              common.Address `toml:",omitempty"`
-		TxPool                  blockchain.TxPoolConfig
-		GPO                     gasprice.Config
-		EnablePreimageRecording bool
-		EnableInternalTxTracing bool
-		Istanbul                istanbul.Config
-		DocRoot                 string `toml:"-"`
-		WsEndpoint              string `toml:",omitempty"`
-		TxResendInterval        uint64
-		TxResendCount           int
-		TxResendUseLegacy       bool
-		NoAccountCreation       bool
-		IsPrivate               bool
-		AutoRestartFlag         bool
-		RestartTimeOutFlag      time.Duration
-		DaemonPathFlag          string
+		Genesis                  *blockchain.Genesis `toml:",omitempty"`
+		NetworkId                uint64
+		SyncMode                 downloader.SyncMode
+		NoPruning                bool
+		ParentOperatorAddr       *common.Address `toml:",omitempty"`
+		AnchoringPeriod          uint64
+		SentChainTxsLimit        uint64
+		OverwriteGenesis         bool
+		DBType                   database.DBType
+		SkipBcVersionCheck       bool `toml:"-"`
+		SingleDB                 bool
+		NumStateTrieShards       uint
+		LevelDBCompression       database.LevelDBCompressionType
+		LevelDBBufferPool        bool
+		LevelDBCacheSize         int
+		ChainDataCompression     database.ChainDataCompressionType
+		RecompressChainData      bool
+		AncientDir               string
+		AncientThreshold         uint64
+		DynamoDBConfig           database.DynamoDBConfig
+		SnapshotURL              string
+		SnapshotSigner           common.Address
+		TrieCacheSize            int
+		TrieTimeout              time.Duration
+		TrieBlockInterval        uint
+		TriesInMemory            uint64
+		StateSnapshotCacheSize   int
+		StateReexecLimit         uint64
+		StateLivePruningInterval uint64
+		StatePruningBloomSize    uint64
+		SenderTxHashIndexing     bool
+		TxLookupLimit            uint64
+		BodyRetention            uint64
+		StorageOwnerIndexing     bool
+		FeePayerIndexing         bool
+		BalanceHistoryIndexing   bool
+		TraceIndexing            bool
+		LogIndexing              bool
+		ParallelDBWrite          bool
+		TrieNodeCacheConfig      statedb.TrieNodeCacheConfig
+		ServiceChainSigner       common.Address `toml:",omitempty"`
+		ExtraData                hexutil.Bytes  `toml:",omitempty"`
+		GasPrice                 *big.Int
+		InstantSeal              bool
+		TxOrderingPolicy         string `toml:",omitempty"`
+		TxBudget                 work.TxBudgetConfig
+		TargetGasLimit           uint64
+		Rewardbase               common.Address `toml:",omitempty"`
+		TxPool                   blockchain.TxPoolConfig
+		GPO                      gasprice.Config
+		EnablePreimageRecording  bool
+		EnableInternalTxTracing  bool
+		ParallelTxExecution      bool
+		ParallelTxWorkers        int
+		Istanbul                 istanbul.Config
+		DocRoot                  string `toml:"-"`
+		WsEndpoint               string `toml:",omitempty"`
+		TxResendInterval         uint64
+		TxResendCount            int
+		TxResendUseLegacy        bool
+		NoAccountCreation        bool
+		IsPrivate                bool
+		AutoRestartFlag          bool
+		RestartTimeOutFlag       time.Duration
+		DaemonPathFlag           string
+		RPCEstimateGasTolerance  float64          `toml:",omitempty"`
+		SupplyBurnAddresses      []common.Address `toml:",omitempty"`
+		SupplyTreasuryAddresses  []common.Address `toml:",omitempty"`
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
@@ -80,22 +107,45 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.LevelDBCompression = c.LevelDBCompression
 	enc.LevelDBBufferPool = c.LevelDBBufferPool
 	enc.LevelDBCacheSize = c.LevelDBCacheSize
+	enc.ChainDataCompression = c.ChainDataCompression
+	enc.RecompressChainData = c.RecompressChainData
+	enc.AncientDir = c.AncientDir
+	enc.AncientThreshold = c.AncientThreshold
 	enc.DynamoDBConfig = c.DynamoDBConfig
+	enc.SnapshotURL = c.SnapshotURL
+	enc.SnapshotSigner = c.SnapshotSigner
 	enc.TrieCacheSize = c.TrieCacheSize
 	enc.TrieTimeout = c.TrieTimeout
 	enc.TrieBlockInterval = c.TrieBlockInterval
 	enc.TriesInMemory = c.TriesInMemory
+	enc.StateSnapshotCacheSize = c.StateSnapshotCacheSize
+	enc.StateReexecLimit = c.StateReexecLimit
+	enc.StateLivePruningInterval = c.StateLivePruningInterval
+	enc.StatePruningBloomSize = c.StatePruningBloomSize
 	enc.SenderTxHashIndexing = c.SenderTxHashIndexing
+	enc.TxLookupLimit = c.TxLookupLimit
+	enc.BodyRetention = c.BodyRetention
+	enc.StorageOwnerIndexing = c.StorageOwnerIndexing
+	enc.FeePayerIndexing = c.FeePayerIndexing
+	enc.BalanceHistoryIndexing = c.BalanceHistoryIndexing
+	enc.TraceIndexing = c.TraceIndexing
+	enc.LogIndexing = c.LogIndexing
 	enc.ParallelDBWrite = c.ParallelDBWrite
 	enc.TrieNodeCacheConfig = c.TrieNodeCacheConfig
 	enc.ServiceChainSigner = c.ServiceChainSigner
 	enc.ExtraData = c.ExtraData
 	enc.GasPrice = c.GasPrice
+	enc.InstantSeal = c.InstantSeal
+	enc.TxOrderingPolicy = c.TxOrderingPolicy
+	enc.TxBudget = c.TxBudget
+	enc.TargetGasLimit = c.TargetGasLimit
 	enc.Rewardbase = c.Rewardbase
 	enc.TxPool = c.TxPool
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
 	enc.EnableInternalTxTracing = c.EnableInternalTxTracing
+	enc.ParallelTxExecution = c.ParallelTxExecution
+	enc.ParallelTxWorkers = c.ParallelTxWorkers
 	enc.Istanbul = c.Istanbul
 	enc.DocRoot = c.DocRoot
 	enc.WsEndpoint = c.WsEndpoint
@@ -107,54 +157,83 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.AutoRestartFlag = c.AutoRestartFlag
 	enc.RestartTimeOutFlag = c.RestartTimeOutFlag
 	enc.DaemonPathFlag = c.DaemonPathFlag
+	enc.RPCEstimateGasTolerance = c.RPCEstimateGasTolerance
+	enc.SupplyBurnAddresses = c.SupplyBurnAddresses
+	enc.SupplyTreasuryAddresses = c.SupplyTreasuryAddresses
 	return &enc, nil
 }
 
 // UnmarshalTOML unmarshals from TOML.
 func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	type Config struct {
-		Genesis                 *blockchain.Genesis `toml:",omitempty"`
-		NetworkId               *uint64
-		SyncMode                *downloader.SyncMode
-		NoPruning               *bool
-		ParentOperatorAddr      *common.Address `toml:",omitempty"`
-		AnchoringPeriod         *uint64
-		SentChainTxsLimit       *uint64
-		OverwriteGenesis        *bool
-		DBType                  *database.DBType
-		SkipBcVersionCheck      *bool `toml:"-"`
-		SingleDB                *bool
-		NumStateTrieShards      *uint
-		LevelDBCompression      *database.LevelDBCompressionType
-		LevelDBBufferPool       *bool
-		LevelDBCacheSize        *int
-		DynamoDBConfig          *database.DynamoDBConfig
-		TrieCacheSize           *int
-		TrieTimeout             *time.Duration
-		TrieBlockInterval       *uint
-		TriesInMemory           *uint64
-		SenderTxHashIndexing    *bool
-		ParallelDBWrite         *bool
-		TrieNodeCacheConfig     *statedb.TrieNodeCacheConfig
-		ServiceChainSigner      *common.Address `toml:",omitempty"`
-		ExtraData               *hexutil.Bytes  `toml:",omitempty"`
-		GasPrice                *big.Int
-		Rewardbase              *common.Address `toml:",omitempty"`
-		TxPool                  *blockchain.TxPoolConfig
-		GPO                     *gasprice.Config
-		EnablePreimageRecording *bool
-		EnableInternalTxTracing *bool
-		Istanbul                *istanbul.Config
-		DocRoot                 *string `toml:"-"`
-		WsEndpoint              *string `toml:",omitempty"`
-		TxResendInterval        *uint64
-		TxResendCount           *int
-		TxResendUseLegacy       *bool
-		NoAccountCreation       *bool
-		IsPrivate               *bool
-		AutoRestartFlag         *bool
-		RestartTimeOutFlag      *time.Duration
-		DaemonPathFlag          *string
+		Genesis                  *blockchain.Genesis `toml:",omitempty"`
+		NetworkId                *uint64
+		SyncMode                 *downloader.SyncMode
+		NoPruning                *bool
+		ParentOperatorAddr       *common.Address `toml:",omitempty"`
+		AnchoringPeriod          *uint64
+		SentChainTxsLimit        *uint64
+		OverwriteGenesis         *bool
+		DBType                   *database.DBType
+		SkipBcVersionCheck       *bool `toml:"-"`
+		SingleDB                 *bool
+		NumStateTrieShards       *uint
+		LevelDBCompression       *database.LevelDBCompressionType
+		LevelDBBufferPool        *bool
+		LevelDBCacheSize         *int
+		ChainDataCompression     *database.ChainDataCompressionType
+		RecompressChainData      *bool
+		AncientDir               *string
+		AncientThreshold         *uint64
+		DynamoDBConfig           *database.DynamoDBConfig
+		SnapshotURL              *string
+		SnapshotSigner           *common.Address
+		TrieCacheSize            *int
+		TrieTimeout              *time.Duration
+		TrieBlockInterval        *uint
+		TriesInMemory            *uint64
+		StateSnapshotCacheSize   *int
+		StateReexecLimit         *uint64
+		StateLivePruningInterval *uint64
+		StatePruningBloomSize    *uint64
+		SenderTxHashIndexing     *bool
+		TxLookupLimit            *uint64
+		BodyRetention            *uint64
+		StorageOwnerIndexing     *bool
+		FeePayerIndexing         *bool
+		BalanceHistoryIndexing   *bool
+		TraceIndexing            *bool
+		LogIndexing              *bool
+		ParallelDBWrite          *bool
+		TrieNodeCacheConfig      *statedb.TrieNodeCacheConfig
+		ServiceChainSigner       *common.Address `toml:",omitempty"`
+		ExtraData                *hexutil.Bytes  `toml:",omitempty"`
+		GasPrice                 *big.Int
+		InstantSeal              *bool
+		TxOrderingPolicy         *string `toml:",omitempty"`
+		TxBudget                 *work.TxBudgetConfig
+		TargetGasLimit           *uint64
+		Rewardbase               *common.Address `toml:",omitempty"`
+		TxPool                   *blockchain.TxPoolConfig
+		GPO                      *gasprice.Config
+		EnablePreimageRecording  *bool
+		EnableInternalTxTracing  *bool
+		ParallelTxExecution      *bool
+		ParallelTxWorkers        *int
+		Istanbul                 *istanbul.Config
+		DocRoot                  *string `toml:"-"`
+		WsEndpoint               *string `toml:",omitempty"`
+		TxResendInterval         *uint64
+		TxResendCount            *int
+		TxResendUseLegacy        *bool
+		NoAccountCreation        *bool
+		IsPrivate                *bool
+		AutoRestartFlag          *bool
+		RestartTimeOutFlag       *time.Duration
+		DaemonPathFlag           *string
+		RPCEstimateGasTolerance  *float64         `toml:",omitempty"`
+		SupplyBurnAddresses      []common.Address `toml:",omitempty"`
+		SupplyTreasuryAddresses  []common.Address `toml:",omitempty"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -205,9 +284,27 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.LevelDBCacheSize != nil {
 		c.LevelDBCacheSize = *dec.LevelDBCacheSize
 	}
+	if dec.ChainDataCompression != nil {
+		c.ChainDataCompression = *dec.ChainDataCompression
+	}
+	if dec.RecompressChainData != nil {
+		c.RecompressChainData = *dec.RecompressChainData
+	}
+	if dec.AncientDir != nil {
+		c.AncientDir = *dec.AncientDir
+	}
+	if dec.AncientThreshold != nil {
+		c.AncientThreshold = *dec.AncientThreshold
+	}
 	if dec.DynamoDBConfig != nil {
 		c.DynamoDBConfig = *dec.DynamoDBConfig
 	}
+	if dec.SnapshotURL != nil {
+		c.SnapshotURL = *dec.SnapshotURL
+	}
+	if dec.SnapshotSigner != nil {
+		c.SnapshotSigner = *dec.SnapshotSigner
+	}
 	if dec.TrieCacheSize != nil {
 		c.TrieCacheSize = *dec.TrieCacheSize
 	}
@@ -220,9 +317,42 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.TriesInMemory != nil {
 		c.TriesInMemory = *dec.TriesInMemory
 	}
+	if dec.StateSnapshotCacheSize != nil {
+		c.StateSnapshotCacheSize = *dec.StateSnapshotCacheSize
+	}
+	if dec.StateReexecLimit != nil {
+		c.StateReexecLimit = *dec.StateReexecLimit
+	}
+	if dec.StateLivePruningInterval != nil {
+		c.StateLivePruningInterval = *dec.StateLivePruningInterval
+	}
+	if dec.StatePruningBloomSize != nil {
+		c.StatePruningBloomSize = *dec.StatePruningBloomSize
+	}
 	if dec.SenderTxHashIndexing != nil {
 		c.SenderTxHashIndexing = *dec.SenderTxHashIndexing
 	}
+	if dec.TxLookupLimit != nil {
+		c.TxLookupLimit = *dec.TxLookupLimit
+	}
+	if dec.BodyRetention != nil {
+		c.BodyRetention = *dec.BodyRetention
+	}
+	if dec.StorageOwnerIndexing != nil {
+		c.StorageOwnerIndexing = *dec.StorageOwnerIndexing
+	}
+	if dec.FeePayerIndexing != nil {
+		c.FeePayerIndexing = *dec.FeePayerIndexing
+	}
+	if dec.BalanceHistoryIndexing != nil {
+		c.BalanceHistoryIndexing = *dec.BalanceHistoryIndexing
+	}
+	if dec.TraceIndexing != nil {
+		c.TraceIndexing = *dec.TraceIndexing
+	}
+	if dec.LogIndexing != nil {
+		c.LogIndexing = *dec.LogIndexing
+	}
 	if dec.ParallelDBWrite != nil {
 		c.ParallelDBWrite = *dec.ParallelDBWrite
 	}
@@ -238,6 +368,18 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.GasPrice != nil {
 		c.GasPrice = dec.GasPrice
 	}
+	if dec.InstantSeal != nil {
+		c.InstantSeal = *dec.InstantSeal
+	}
+	if dec.TxOrderingPolicy != nil {
+		c.TxOrderingPolicy = *dec.TxOrderingPolicy
+	}
+	if dec.TxBudget != nil {
+		c.TxBudget = *dec.TxBudget
+	}
+	if dec.TargetGasLimit != nil {
+		c.TargetGasLimit = *dec.TargetGasLimit
+	}
 	if dec.Rewardbase != nil {
 		c.Rewardbase = *dec.Rewardbase
 	}
@@ -253,6 +395,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.EnableInternalTxTracing != nil {
 		c.EnableInternalTxTracing = *dec.EnableInternalTxTracing
 	}
+	if dec.ParallelTxExecution != nil {
+		c.ParallelTxExecution = *dec.ParallelTxExecution
+	}
+	if dec.ParallelTxWorkers != nil {
+		c.ParallelTxWorkers = *dec.ParallelTxWorkers
+	}
 	if dec.Istanbul != nil {
 		c.Istanbul = *dec.Istanbul
 	}
@@ -286,5 +434,14 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.DaemonPathFlag != nil {
 		c.DaemonPathFlag = *dec.DaemonPathFlag
 	}
+	if dec.RPCEstimateGasTolerance != nil {
+		c.RPCEstimateGasTolerance = *dec.RPCEstimateGasTolerance
+	}
+	if dec.SupplyBurnAddresses != nil {
+		c.SupplyBurnAddresses = dec.SupplyBurnAddresses
+	}
+	if dec.SupplyTreasuryAddresses != nil {
+		c.SupplyTreasuryAddresses = dec.SupplyTreasuryAddresses
+	}
 	return nil
 }
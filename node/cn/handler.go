@@ -84,6 +84,7 @@ type ProtocolManager struct {
 	networkId uint64
 
 	fastSync  uint32 // Flag whether fast sync is enabled (gets disabled if we already have blocks)
+	snapSync  uint32 // Flag whether fast sync should fetch the state in ranges from the state snapshot of the peers
 	acceptTxs uint32 // Flag whether we're considered synchronised (enables transaction processing)
 
 	txpool      work.TxPool
@@ -153,28 +154,32 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 	}
 
 	// Figure out whether to allow fast sync or not
-	if mode == downloader.FastSync && blockchain.CurrentBlock().NumberU64() > 0 {
+	if (mode == downloader.FastSync || mode == downloader.SnapSync) && blockchain.CurrentBlock().NumberU64() > 0 {
 		logger.Error("Blockchain not empty, fast sync disabled")
 		mode = downloader.FullSync
 	}
-	if mode == downloader.FastSync {
+	if mode == downloader.FastSync || mode == downloader.SnapSync {
 		manager.fastSync = uint32(1)
 	}
+	if mode == downloader.SnapSync {
+		manager.snapSync = uint32(1)
+	}
 	// istanbul BFT
 	protocol := engine.Protocol()
 	// Initiate a sub-protocol for every implemented version we can handle
 	manager.SubProtocols = make([]p2p.Protocol, 0, len(protocol.Versions))
 	for i, version := range protocol.Versions {
 		// Skip protocol version if incompatible with the mode of operation
-		if mode == downloader.FastSync && version < klay63 {
+		if (mode == downloader.FastSync || mode == downloader.SnapSync) && version < klay63 {
 			continue
 		}
 		// Compatible; initialise the sub-protocol
 		version := version
 		manager.SubProtocols = append(manager.SubProtocols, p2p.Protocol{
-			Name:    protocol.Name,
-			Version: version,
-			Length:  protocol.Lengths[i],
+			Name:     protocol.Name,
+			Version:  version,
+			Length:   protocol.Lengths[i],
+			Features: ProtocolFeatures,
 			Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
 				peer := manager.newPeer(int(version), p, rw)
 				pubKey, err := p.ID().Pubkey()
@@ -243,9 +248,10 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 		// Construct the downloader (long sync) and its backing state bloom if fast
 		// sync is requested. The downloader is responsible for deallocating the state
 
-		// bloom when it's done.
+		// bloom when it's done. Snap sync doesn't use the bloom, as the state tries are
+		// built from the fetched ranges without passing through it.
 		var stateBloom *statedb.SyncBloom
-		if atomic.LoadUint32(&manager.fastSync) == 1 {
+		if atomic.LoadUint32(&manager.fastSync) == 1 && atomic.LoadUint32(&manager.snapSync) == 0 {
 			stateBloom = statedb.NewSyncBloom(uint64(cacheLimit), chainDB.GetStateTrieDB())
 		}
 		manager.downloader = downloader.New(mode, chainDB, stateBloom, manager.eventMux, blockchain, nil, manager.removePeer)
@@ -588,6 +594,36 @@ func (pm *ProtocolManager) handleMsg(p Peer, addr common.Address, msg p2p.Msg) e
 			return err
 		}
 
+	case msg.Code == GetAccountRangeMsg && p.HasFeature(FeatureSnapshotServing):
+		if err := handleGetAccountRangeMsg(pm, p, msg); err != nil {
+			return err
+		}
+
+	case msg.Code == AccountRangeMsg && p.HasFeature(FeatureSnapshotServing):
+		if err := handleAccountRangeMsg(pm, p, msg); err != nil {
+			return err
+		}
+
+	case msg.Code == GetStorageRangesMsg && p.HasFeature(FeatureSnapshotServing):
+		if err := handleGetStorageRangesMsg(pm, p, msg); err != nil {
+			return err
+		}
+
+	case msg.Code == StorageRangesMsg && p.HasFeature(FeatureSnapshotServing):
+		if err := handleStorageRangesMsg(pm, p, msg); err != nil {
+			return err
+		}
+
+	case msg.Code == GetByteCodesMsg && p.HasFeature(FeatureSnapshotServing):
+		if err := handleGetByteCodesMsg(pm, p, msg); err != nil {
+			return err
+		}
+
+	case msg.Code == ByteCodesMsg && p.HasFeature(FeatureSnapshotServing):
+		if err := handleByteCodesMsg(pm, p, msg); err != nil {
+			return err
+		}
+
 	case msg.Code == NewBlockHashesMsg:
 		if err := handleNewBlockHashesMsg(pm, p, msg); err != nil {
 			return err
@@ -0,0 +1,313 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+)
+
+var (
+	errIndexRebuildRunning    = errors.New("index rebuild is already running")
+	errIndexRebuildNotRunning = errors.New("index rebuild is not running")
+	errIndexRebuildStopped    = errors.New("index rebuild is stopped")
+)
+
+// IndexRebuildStatus is the progress of the index rebuild.
+type IndexRebuildStatus struct {
+	Running         bool    `json:"running"`
+	From            uint64  `json:"from"`
+	To              uint64  `json:"to"`
+	Current         uint64  `json:"current"`  // the block number being processed
+	Progress        float64 `json:"progress"` // the percentage of the processed blocks
+	TxLookupEntries int     `json:"txLookupEntries"`
+	SenderTxHashes  int     `json:"senderTxHashes"`
+	BloomSections   int     `json:"bloomSections"`
+	BloomMismatches int     `json:"bloomMismatches"` // the number of blocks whose receipts do not match the header bloom
+	MissingBlocks   int     `json:"missingBlocks"`
+	Elapsed         string  `json:"elapsed"`
+	Err             string  `json:"err"`
+}
+
+// indexRebuilder rebuilds the tx lookup entries, the senderTxHash mappings and the bloombits
+// of a range of canonical blocks from the stored blocks and receipts, so a node recovering from
+// a partially corrupted database can restore them without a full resync.
+type indexRebuilder struct {
+	db                   database.DBManager
+	bloomIndexer         *blockchain.ChainIndexer
+	senderTxHashIndexing bool
+
+	mu      sync.Mutex
+	status  IndexRebuildStatus
+	started time.Time
+	quit    chan struct{}
+	done    chan struct{}
+}
+
+func newIndexRebuilder(db database.DBManager, bloomIndexer *blockchain.ChainIndexer, senderTxHashIndexing bool) *indexRebuilder {
+	return &indexRebuilder{
+		db:                   db,
+		bloomIndexer:         bloomIndexer,
+		senderTxHashIndexing: senderTxHashIndexing,
+	}
+}
+
+// start starts rebuilding the indexes of the blocks in [from, to] in background.
+func (r *indexRebuilder) start(from, to uint64) error {
+	if from > to {
+		return fmt.Errorf("invalid range: from %d > to %d", from, to)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.status.Running {
+		return errIndexRebuildRunning
+	}
+	r.status = IndexRebuildStatus{Running: true, From: from, To: to, Current: from}
+	r.started = time.Now()
+	r.quit, r.done = make(chan struct{}), make(chan struct{})
+
+	go func(quit, done chan struct{}) {
+		defer close(done)
+		err := r.rebuild(from, to, quit)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.status.Running = false
+		r.status.Elapsed = time.Since(r.started).String()
+		if err != nil {
+			r.status.Err = err.Error()
+			logger.Error("Failed to rebuild indexes", "from", from, "to", to, "err", err)
+			return
+		}
+		logger.Info("Rebuilt indexes", "from", from, "to", to, "txLookupEntries", r.status.TxLookupEntries,
+			"senderTxHashes", r.status.SenderTxHashes, "bloomSections", r.status.BloomSections,
+			"bloomMismatches", r.status.BloomMismatches, "missingBlocks", r.status.MissingBlocks, "elapsed", r.status.Elapsed)
+	}(r.quit, r.done)
+	return nil
+}
+
+// stop stops the running index rebuild.
+func (r *indexRebuilder) stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.status.Running {
+		return errIndexRebuildNotRunning
+	}
+	select {
+	case <-r.quit:
+	default:
+		close(r.quit)
+	}
+	return nil
+}
+
+// close stops the running index rebuild and waits for it to be terminated.
+func (r *indexRebuilder) close() {
+	r.mu.Lock()
+	done := r.done
+	r.mu.Unlock()
+
+	if done == nil {
+		return
+	}
+	r.stop()
+	<-done
+}
+
+// getStatus returns the status of the running or the last index rebuild.
+func (r *indexRebuilder) getStatus() IndexRebuildStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := r.status
+	if status.Running {
+		status.Elapsed = time.Since(r.started).String()
+	}
+	return status
+}
+
+// update applies fn to the status under the lock.
+func (r *indexRebuilder) update(fn func(status *IndexRebuildStatus)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(&r.status)
+}
+
+// rebuild rebuilds the tx indexes of the blocks in [from, to] and the bloombits of the sections
+// overlapping the range. Only the sections already processed by the bloom indexer are rebuilt,
+// since the others are going to be processed by the bloom indexer.
+func (r *indexRebuilder) rebuild(from, to uint64, quit chan struct{}) error {
+	var (
+		sectionSize  = params.BloomBitsBlocks
+		firstSection = from / sectionSize
+		lastSection  = to / sectionSize
+		hasSections  = false
+	)
+	if r.bloomIndexer != nil {
+		if stored, _, _ := r.bloomIndexer.Sections(); stored > 0 {
+			if lastSection >= stored {
+				lastSection = stored - 1
+			}
+			hasSections = firstSection <= lastSection
+		}
+	}
+	total := float64(to - from + 1)
+	if hasSections {
+		total += float64((lastSection - firstSection + 1) * sectionSize)
+	}
+	processed := 0
+	report := func(number uint64) {
+		processed++
+		r.update(func(status *IndexRebuildStatus) {
+			status.Current = number
+			status.Progress = float64(processed) / total * 100
+		})
+	}
+
+	logger.Info("Rebuilding indexes", "from", from, "to", to, "senderTxHashIndexing", r.senderTxHashIndexing)
+	if err := r.rebuildTxIndexes(from, to, quit, report); err != nil {
+		return err
+	}
+	if !hasSections {
+		return nil
+	}
+	for section := firstSection; section <= lastSection; section++ {
+		if err := r.rebuildBloomSection(section, quit, report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuildTxIndexes writes the tx lookup entries and the senderTxHash mappings of the blocks in [from, to].
+func (r *indexRebuilder) rebuildTxIndexes(from, to uint64, quit chan struct{}, report func(uint64)) error {
+	var (
+		lookupBatch       = r.db.NewBatch(database.TxLookUpEntryDB)
+		senderTxHashBatch = r.db.NewSenderTxHashToTxHashBatch()
+		logged            = time.Now()
+	)
+	flush := func(force bool) error {
+		for _, batch := range []database.Batch{lookupBatch, senderTxHashBatch} {
+			if force || batch.ValueSize() >= database.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					return err
+				}
+				batch.Reset()
+			}
+		}
+		return nil
+	}
+
+	for number := from; number <= to; number++ {
+		select {
+		case <-quit:
+			return errIndexRebuildStopped
+		default:
+		}
+		block := r.db.ReadBlockByNumber(number)
+		if block == nil {
+			r.update(func(status *IndexRebuildStatus) { status.MissingBlocks++ })
+			report(number)
+			continue
+		}
+		r.db.PutTxLookupEntriesToBatch(lookupBatch, block)
+
+		senderTxHashes := 0
+		if r.senderTxHashIndexing {
+			for _, tx := range block.Transactions() {
+				senderTxHash, ok := tx.SenderTxHash()
+				if !ok {
+					continue
+				}
+				if err := r.db.PutSenderTxHashToTxHashToBatch(senderTxHashBatch, senderTxHash, tx.Hash()); err != nil {
+					return err
+				}
+				senderTxHashes++
+			}
+		}
+		if err := flush(false); err != nil {
+			return err
+		}
+		r.update(func(status *IndexRebuildStatus) {
+			status.TxLookupEntries += len(block.Transactions())
+			status.SenderTxHashes += senderTxHashes
+		})
+		report(number)
+
+		if time.Since(logged) > 8*time.Second {
+			logger.Info("Rebuilding tx indexes", "number", number, "to", to)
+			logged = time.Now()
+		}
+	}
+	return flush(true)
+}
+
+// rebuildBloomSection regenerates the bloombits of the given section from the stored receipts.
+// The bloom of the header is used if the receipts of a block are missing.
+func (r *indexRebuilder) rebuildBloomSection(section uint64, quit chan struct{}, report func(uint64)) error {
+	sectionSize := params.BloomBitsBlocks
+	bloomIndexer := &BloomIndexer{db: r.db, size: sectionSize}
+	if err := bloomIndexer.Reset(section, common.Hash{}); err != nil {
+		return err
+	}
+
+	mismatches := 0
+	for number := section * sectionSize; number < (section+1)*sectionSize; number++ {
+		select {
+		case <-quit:
+			return errIndexRebuildStopped
+		default:
+		}
+		hash := r.db.ReadCanonicalHash(number)
+		header := r.db.ReadHeader(hash, number)
+		if header == nil {
+			return fmt.Errorf("missing header of block %d in bloom section %d", number, section)
+		}
+		bloom := header.Bloom
+		if receipts := r.db.ReadReceipts(hash, number); receipts != nil {
+			if bloom = types.CreateBloom(receipts); bloom != header.Bloom {
+				mismatches++
+				logger.Warn("Receipts do not match the header bloom", "number", number, "hash", hash)
+			}
+		}
+		// The section head is the hash of the header, so the bloom is added without modifying the header
+		if err := bloomIndexer.gen.AddBloom(uint(number-section*sectionSize), bloom); err != nil {
+			return err
+		}
+		bloomIndexer.head = hash
+		report(number)
+	}
+	if err := bloomIndexer.Commit(); err != nil {
+		return err
+	}
+	r.update(func(status *IndexRebuildStatus) {
+		status.BloomSections++
+		status.BloomMismatches += mismatches
+	})
+	logger.Info("Rebuilt bloom section", "section", section, "head", bloomIndexer.head, "mismatches", mismatches)
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/bitutil"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests that the tx lookup entries and the bloombits are rebuilt from the stored blocks and receipts.
+func TestIndexRebuilder(t *testing.T) {
+	var (
+		db       = database.NewMemoryDBManager()
+		key, _   = crypto.GenerateKey()
+		signer   = types.NewEIP155Signer(big.NewInt(1))
+		emitter  = common.Address{0x01}
+		txBlocks = map[uint64]*types.Transaction{}
+		logBloom types.Bloom
+	)
+	blockchain.InitDeriveSha(params.TestChainConfig.DeriveShaImpl)
+
+	// Write a bloom section of blocks, some of which contain a transaction emitting a log
+	for number := uint64(0); number < params.BloomBitsBlocks; number++ {
+		header := &types.Header{Number: new(big.Int).SetUint64(number)}
+		var (
+			txs      types.Transactions
+			receipts types.Receipts
+		)
+		if number%1000 == 1 {
+			tx, err := types.SignTx(types.NewTransaction(number, emitter, big.NewInt(0), 100000, big.NewInt(1), nil), signer, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			receipt := types.NewReceipt(types.ReceiptStatusSuccessful, tx.Hash(), 21000)
+			receipt.Logs = []*types.Log{{Address: emitter}}
+			receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+			logBloom = receipt.Bloom
+			txs, receipts = types.Transactions{tx}, types.Receipts{receipt}
+			txBlocks[number] = tx
+		}
+		block := types.NewBlock(header, txs, receipts)
+		db.WriteBlock(block)
+		db.WriteCanonicalHash(block.Hash(), number)
+		db.WriteReceipts(block.Hash(), number, receipts)
+	}
+
+	r := newIndexRebuilder(db, nil, false)
+	quit := make(chan struct{})
+	reported := 0
+	report := func(uint64) { reported++ }
+
+	// Tx lookup entries
+	for _, tx := range txBlocks {
+		hash, _, _ := db.ReadTxLookupEntry(tx.Hash())
+		assert.Equal(t, common.Hash{}, hash)
+	}
+	assert.NoError(t, r.rebuildTxIndexes(0, params.BloomBitsBlocks-1, quit, report))
+	for number, tx := range txBlocks {
+		hash, blockNumber, _ := db.ReadTxLookupEntry(tx.Hash())
+		assert.Equal(t, db.ReadCanonicalHash(number), hash)
+		assert.Equal(t, number, blockNumber)
+	}
+	assert.Equal(t, len(txBlocks), r.getStatus().TxLookupEntries)
+	assert.Equal(t, int(params.BloomBitsBlocks), reported)
+
+	// Bloom bits of the section
+	assert.NoError(t, r.rebuildBloomSection(0, quit, report))
+	assert.Equal(t, 1, r.getStatus().BloomSections)
+	assert.Equal(t, 0, r.getStatus().BloomMismatches)
+
+	head := db.ReadCanonicalHash(params.BloomBitsBlocks - 1)
+	for i := 0; i < types.BloomBitLength; i++ {
+		compVector, err := db.ReadBloomBits(database.BloomBitsKey(uint(i), 0, head))
+		assert.NoError(t, err)
+		bits, err := bitutil.DecompressBytes(compVector, int(params.BloomBitsBlocks)/8)
+		assert.NoError(t, err)
+
+		// The bit of the log address is set for the blocks emitting the log
+		byteIndex := types.BloomByteLength - 1 - i/8
+		expected := logBloom[byteIndex]&(1<<uint(i%8)) != 0
+		for number := range txBlocks {
+			assert.Equal(t, expected, bits[number/8]&(1<<(7-number%8)) != 0)
+		}
+	}
+
+	// Stopped by the quit channel
+	close(quit)
+	assert.Equal(t, errIndexRebuildStopped, r.rebuildTxIndexes(0, 1, quit, report))
+}
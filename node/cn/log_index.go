@@ -0,0 +1,137 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"fmt"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/storage/database"
+)
+
+// logIndexKey is an entry of the log index, which is an emitting address with the first topic
+// of its logs, or with no topic for the entry of the address itself.
+type logIndexKey struct {
+	addr     common.Address
+	topic    common.Hash
+	hasTopic bool
+}
+
+// indexLogs stores the addresses emitting the logs of the block with their first topics to the log index.
+func indexLogs(db database.DBManager, block *types.Block, receipts types.Receipts) error {
+	if len(receipts) != len(block.Transactions()) {
+		return fmt.Errorf("the number of receipts %d does not match the number of transactions %d", len(receipts), len(block.Transactions()))
+	}
+	var (
+		batch = db.NewLogIndexBatch()
+		seen  = make(map[logIndexKey]struct{})
+	)
+	put := func(key logIndexKey) error {
+		if _, ok := seen[key]; ok {
+			return nil
+		}
+		seen[key] = struct{}{}
+		if key.hasTopic {
+			return db.PutLogTopicToBatch(batch, key.addr, key.topic, block.NumberU64(), block.Hash())
+		}
+		return db.PutLogAddressToBatch(batch, key.addr, block.NumberU64(), block.Hash())
+	}
+	for _, receipt := range receipts {
+		for _, log := range receipt.Logs {
+			if err := put(logIndexKey{addr: log.Address}); err != nil {
+				return err
+			}
+			if len(log.Topics) == 0 {
+				continue
+			}
+			if err := put(logIndexKey{addr: log.Address, topic: log.Topics[0], hasTopic: true}); err != nil {
+				return err
+			}
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	return db.WriteLogIndexHead(block.NumberU64())
+}
+
+// logIndexer subscribes chainEvent and chainReorgEvent, and stores the addresses emitting the
+// logs of the canonical blocks to the log index from the given block. The blocks inserted while
+// the node was not running are indexed from the database in the background of receiving the
+// events. The entries of the blocks dropped by a reorg are left in the index, and skipped by the
+// canonical hashes when read.
+func logIndexer(db database.DBManager, next, head uint64,
+	chainEvent <-chan blockchain.ChainEvent, chainSub event.Subscription,
+	reorgEvent <-chan blockchain.ChainReorgEvent, reorgSub event.Subscription) {
+	defer chainSub.Unsubscribe()
+	defer reorgSub.Unsubscribe()
+
+	// ready is selected while there are blocks to be indexed
+	ready := make(chan struct{})
+	close(ready)
+
+	for {
+		var pending <-chan struct{}
+		if next <= head {
+			pending = ready
+		}
+		select {
+		case ev := <-chainEvent:
+			if number := ev.Block.NumberU64(); number > head {
+				head = number
+			}
+
+		case ev := <-reorgEvent:
+			// The blocks of the new chain are indexed again
+			rewound := false
+			for _, hash := range ev.AddedBlocks {
+				if number := db.ReadHeaderNumber(hash); number != nil && *number < next {
+					next, rewound = *number, true
+				}
+			}
+			// The stored head is rewound as well, so that the blocks are read without the index
+			// until they are indexed again.
+			if rewound && next > 0 {
+				if err := db.WriteLogIndexHead(next - 1); err != nil {
+					logger.Error("Failed to rewind the log index head", "blockNum", next-1, "err", err)
+				}
+			}
+
+		case <-pending:
+			block := db.ReadBlockByNumber(next)
+			if block == nil {
+				logger.Error("Failed to read the block to index logs", "blockNum", next)
+				head = next - 1 // retried by the next chain event
+				continue
+			}
+			if err := indexLogs(db, block, db.ReadReceipts(block.Hash(), next)); err != nil {
+				logger.Error("Failed to store log index to database", "blockNum", next, "err", err)
+				head = next - 1 // retried by the next chain event
+				continue
+			}
+			next++
+
+		case <-chainSub.Err():
+			return
+		case <-reorgSub.Err():
+			return
+		}
+	}
+}
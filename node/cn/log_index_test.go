@@ -0,0 +1,104 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLogIndexer tests if the addresses emitting the logs of the canonical blocks are indexed
+// with their first topics in order by the chain events and the reorg events.
+func TestLogIndexer(t *testing.T) {
+	var (
+		db        = database.NewMemoryDBManager()
+		chainFeed = new(event.Feed)
+		reorgFeed = new(event.Feed)
+		chainCh   = make(chan blockchain.ChainEvent, 16)
+		reorgCh   = make(chan blockchain.ChainReorgEvent, 16)
+		addrA     = common.Address{0x0a}
+		addrB     = common.Address{0x0b}
+		topic     = common.Hash{0x01}
+		blocks    []*types.Block
+	)
+	// Each block has a transaction emitting a log of addrA with the topic, and a log of the block number
+	writeBlock := func(header *types.Header) *types.Block {
+		tx := types.NewTransaction(header.Number.Uint64(), addrA, big.NewInt(0), 0, big.NewInt(0), nil)
+		block := types.NewBlockWithHeader(header).WithBody(types.Transactions{tx})
+		receipt := &types.Receipt{Logs: []*types.Log{
+			{Address: addrA, Topics: []common.Hash{topic}},
+			{Address: common.Address{byte(header.Number.Uint64())}},
+		}}
+		db.WriteBlock(block)
+		db.WriteReceipts(block.Hash(), block.NumberU64(), types.Receipts{receipt})
+		db.WriteCanonicalHash(block.Hash(), block.NumberU64())
+		return block
+	}
+	for i := 0; i < 4; i++ {
+		blocks = append(blocks, writeBlock(&types.Header{Number: big.NewInt(int64(i))}))
+	}
+	chainSub := chainFeed.Subscribe(chainCh)
+	defer chainSub.Unsubscribe()
+	go logIndexer(db, 2, 1, chainCh, chainSub, reorgCh, reorgFeed.Subscribe(reorgCh))
+
+	waitHead := func(head uint64) {
+		for timeout := time.Now().Add(time.Second); time.Now().Before(timeout); time.Sleep(10 * time.Millisecond) {
+			if indexed, _ := db.ReadLogIndexHead(); indexed == head {
+				return
+			}
+		}
+		t.Fatalf("block %d is not indexed", head)
+	}
+	logBlocks := func(addr common.Address, topic *common.Hash) []uint64 {
+		var numbers []uint64
+		db.IterateLogBlocks(addr, topic, 0, func(blockNum uint64, blockHash common.Hash) bool {
+			assert.Equal(t, blocks[blockNum].Hash(), blockHash)
+			numbers = append(numbers, blockNum)
+			return true
+		})
+		return numbers
+	}
+
+	chainFeed.Send(blockchain.ChainEvent{Block: blocks[3]})
+	waitHead(3)
+	assert.Equal(t, []uint64{2, 3}, logBlocks(addrA, nil))
+	assert.Equal(t, []uint64{2, 3}, logBlocks(addrA, &topic))
+	assert.Equal(t, []uint64{3}, logBlocks(common.Address{3}, nil))
+	assert.Empty(t, logBlocks(common.Address{3}, &topic))
+
+	// The head is rewound by a reorg until the blocks of the new chain are indexed again.
+	// The body of the new block is written later to keep the head rewound.
+	header := &types.Header{Number: big.NewInt(2), Extra: []byte{1}}
+	db.WriteHeader(header)
+	db.WriteCanonicalHash(header.Hash(), 2)
+	reorgFeed.Send(blockchain.ChainReorgEvent{AddedBlocks: []common.Hash{header.Hash()}})
+	waitHead(1)
+	blocks[2] = writeBlock(header)
+	chainFeed.Send(blockchain.ChainEvent{Block: blocks[3]})
+	waitHead(3)
+	assert.Equal(t, []uint64{2, 3}, logBlocks(addrA, nil))
+	assert.Equal(t, []uint64{2}, logBlocks(common.Address{2}, nil))
+	assert.Empty(t, logBlocks(addrB, nil))
+}
@@ -72,6 +72,7 @@ var (
 	propConsensusIstanbulInTrafficMeter  = metrics.NewRegisteredMeter("klay/prop/consensus/istanbul/in/traffic", nil)
 	propConsensusIstanbulOutPacketsMeter = metrics.NewRegisteredMeter("klay/prop/consensus/istanbul/out/packets", nil)
 	propConsensusIstanbulOutTrafficMeter = metrics.NewRegisteredMeter("klay/prop/consensus/istanbul/out/traffic", nil)
+	stateReexecMeter                     = metrics.NewRegisteredMeter("klay/api/state/reexec", nil)
 )
 
 // meteredMsgReadWriter is a wrapper around a p2p.MsgReadWriter, capable of
@@ -36,6 +36,20 @@ func (m *MockMiner) EXPECT() *MockMinerMockRecorder {
 	return m.recorder
 }
 
+// GasLimit mocks base method
+func (m *MockMiner) GasLimit() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GasLimit")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// GasLimit indicates an expected call of GasLimit
+func (mr *MockMinerMockRecorder) GasLimit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GasLimit", reflect.TypeOf((*MockMiner)(nil).GasLimit))
+}
+
 // HashRate mocks base method
 func (m *MockMiner) HashRate() int64 {
 	m.ctrl.T.Helper()
@@ -119,6 +133,46 @@ func (mr *MockMinerMockRecorder) SetExtra(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetExtra", reflect.TypeOf((*MockMiner)(nil).SetExtra), arg0)
 }
 
+// SetTargetGasLimit mocks base method
+func (m *MockMiner) SetTargetGasLimit(arg0 uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTargetGasLimit", arg0)
+}
+
+// SetTargetGasLimit indicates an expected call of SetTargetGasLimit
+func (mr *MockMinerMockRecorder) SetTargetGasLimit(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTargetGasLimit", reflect.TypeOf((*MockMiner)(nil).SetTargetGasLimit), arg0)
+}
+
+// SetTxBudget mocks base method
+func (m *MockMiner) SetTxBudget(arg0 work.TxBudgetConfig) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTxBudget", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTxBudget indicates an expected call of SetTxBudget
+func (mr *MockMinerMockRecorder) SetTxBudget(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTxBudget", reflect.TypeOf((*MockMiner)(nil).SetTxBudget), arg0)
+}
+
+// SetTxOrderingPolicy mocks base method
+func (m *MockMiner) SetTxOrderingPolicy(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTxOrderingPolicy", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTxOrderingPolicy indicates an expected call of SetTxOrderingPolicy
+func (mr *MockMinerMockRecorder) SetTxOrderingPolicy(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTxOrderingPolicy", reflect.TypeOf((*MockMiner)(nil).SetTxOrderingPolicy), arg0)
+}
+
 // Start mocks base method
 func (m *MockMiner) Start() {
 	m.ctrl.T.Helper()
@@ -142,3 +196,45 @@ func (mr *MockMinerMockRecorder) Stop() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockMiner)(nil).Stop))
 }
+
+// TargetGasLimit mocks base method
+func (m *MockMiner) TargetGasLimit() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TargetGasLimit")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// TargetGasLimit indicates an expected call of TargetGasLimit
+func (mr *MockMinerMockRecorder) TargetGasLimit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TargetGasLimit", reflect.TypeOf((*MockMiner)(nil).TargetGasLimit))
+}
+
+// TxBudget mocks base method
+func (m *MockMiner) TxBudget() work.TxBudgetConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxBudget")
+	ret0, _ := ret[0].(work.TxBudgetConfig)
+	return ret0
+}
+
+// TxBudget indicates an expected call of TxBudget
+func (mr *MockMinerMockRecorder) TxBudget() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxBudget", reflect.TypeOf((*MockMiner)(nil).TxBudget))
+}
+
+// TxOrderingPolicy mocks base method
+func (m *MockMiner) TxOrderingPolicy() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxOrderingPolicy")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// TxOrderingPolicy indicates an expected call of TxOrderingPolicy
+func (mr *MockMinerMockRecorder) TxOrderingPolicy() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxOrderingPolicy", reflect.TypeOf((*MockMiner)(nil).TxOrderingPolicy))
+}
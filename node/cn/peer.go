@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strconv"
 	"sync"
 	"time"
 
@@ -162,6 +163,17 @@ type Peer interface {
 	// ones requested from an already RLP encoded format.
 	SendReceiptsRLP(receipts []rlp.RawValue) error
 
+	// SendAccountRange sends a range of accounts in the state snapshot, corresponding
+	// to the account range requested.
+	SendAccountRange(res *accountRangeData) error
+
+	// SendStorageRanges sends ranges of storage slots in the state snapshot,
+	// corresponding to the storage ranges requested.
+	SendStorageRanges(res *storageRangesData) error
+
+	// SendByteCodes sends a batch of contract codes, corresponding to the hashes requested.
+	SendByteCodes(res *byteCodesData) error
+
 	// FetchBlockHeader is a wrapper around the header query functions to fetch a
 	// single header. It is used solely by the fetcher.
 	FetchBlockHeader(hash common.Hash) error
@@ -196,6 +208,9 @@ type Peer interface {
 	// GetVersion returns the version of the peer.
 	GetVersion() int
 
+	// HasFeature returns true if the optional feature of the protocol is enabled for the peer.
+	HasFeature(feature string) bool
+
 	// KnowsBlock returns if the peer is known to have the block, based on knownBlocksCache.
 	KnowsBlock(hash common.Hash) bool
 
@@ -221,6 +236,9 @@ type Peer interface {
 	// Peer encapsulates the methods required to synchronise with a remote full peer.
 	downloader.Peer
 
+	// SnapPeer encapsulates the methods required to snap sync the state with a remote peer.
+	downloader.SnapPeer
+
 	// RegisterConsensusMsgCode registers the channel of consensus msg.
 	RegisterConsensusMsgCode(msgCode uint64) error
 }
@@ -301,6 +319,14 @@ var ChannelOfMessage = map[uint64]int{
 	NodeDataMsg:        p2p.ConnDefault,
 	ReceiptsRequestMsg: p2p.ConnDefault,
 	ReceiptsMsg:        p2p.ConnDefault,
+
+	// Protocol messages belonging to FeatureSnapshotServing
+	GetAccountRangeMsg:  p2p.ConnDefault,
+	AccountRangeMsg:     p2p.ConnDefault,
+	GetStorageRangesMsg: p2p.ConnDefault,
+	StorageRangesMsg:    p2p.ConnDefault,
+	GetByteCodesMsg:     p2p.ConnDefault,
+	ByteCodesMsg:        p2p.ConnDefault,
 }
 
 var ConcurrentOfChannel = []int{
@@ -583,6 +609,48 @@ func (p *basePeer) RequestReceipts(hashes []common.Hash) error {
 	return p2p.Send(p.rw, ReceiptsRequestMsg, hashes)
 }
 
+// SendAccountRange sends a range of accounts in the state snapshot, corresponding
+// to the account range requested.
+func (p *basePeer) SendAccountRange(res *accountRangeData) error {
+	return p2p.Send(p.rw, AccountRangeMsg, res)
+}
+
+// SendStorageRanges sends ranges of storage slots in the state snapshot,
+// corresponding to the storage ranges requested.
+func (p *basePeer) SendStorageRanges(res *storageRangesData) error {
+	return p2p.Send(p.rw, StorageRangesMsg, res)
+}
+
+// SendByteCodes sends a batch of contract codes, corresponding to the hashes requested.
+func (p *basePeer) SendByteCodes(res *byteCodesData) error {
+	return p2p.Send(p.rw, ByteCodesMsg, res)
+}
+
+// ServesSnapshot returns true if the peer serves the state snapshot for snap sync.
+func (p *basePeer) ServesSnapshot() bool {
+	return p.HasFeature(FeatureSnapshotServing)
+}
+
+// RequestAccountRange fetches a range of accounts in the state of the given root
+// from a remote node, with the Merkle proofs of the range.
+func (p *basePeer) RequestAccountRange(id uint64, root, origin, limit common.Hash, bytes uint64) error {
+	p.Log().Debug("Fetching range of accounts", "reqid", id, "root", root, "origin", origin, "limit", limit, "bytes", common.StorageSize(bytes))
+	return p2p.Send(p.rw, GetAccountRangeMsg, &getAccountRangeData{ID: id, Root: root, Origin: origin, Limit: limit, Bytes: bytes})
+}
+
+// RequestStorageRanges fetches ranges of storage slots of the accounts in the state
+// of the given root from a remote node, with the Merkle proofs of the last range.
+func (p *basePeer) RequestStorageRanges(id uint64, root common.Hash, accounts []common.Hash, origin, limit []byte, bytes uint64) error {
+	p.Log().Debug("Fetching ranges of storage slots", "reqid", id, "root", root, "accounts", len(accounts), "bytes", common.StorageSize(bytes))
+	return p2p.Send(p.rw, GetStorageRangesMsg, &getStorageRangesData{ID: id, Root: root, Accounts: accounts, Origin: origin, Limit: limit, Bytes: bytes})
+}
+
+// RequestByteCodes fetches a batch of contract codes from a remote node.
+func (p *basePeer) RequestByteCodes(id uint64, hashes []common.Hash, bytes uint64) error {
+	p.Log().Debug("Fetching batch of byte codes", "reqid", id, "count", len(hashes))
+	return p2p.Send(p.rw, GetByteCodesMsg, &getByteCodesData{ID: id, Hashes: hashes, Bytes: bytes})
+}
+
 // Handshake executes the Klaytn protocol handshake, negotiating version number,
 // network IDs, difficulties, head and genesis blocks.
 func (p *basePeer) Handshake(network uint64, chainID, td *big.Int, head common.Hash, genesis common.Hash) error {
@@ -635,12 +703,15 @@ func (p *basePeer) readStatus(network uint64, status *statusData, genesis common
 		return errResp(ErrDecode, "msg %v: %v", msg, err)
 	}
 	if status.GenesisBlock != genesis {
+		handshakeMismatches.record(mismatchGenesis, status.GenesisBlock.Hex())
 		return errResp(ErrGenesisBlockMismatch, "%x (!= %x)", status.GenesisBlock[:8], genesis[:8])
 	}
 	if status.NetworkId != network {
+		handshakeMismatches.record(mismatchNetworkId, strconv.FormatUint(status.NetworkId, 10))
 		return errResp(ErrNetworkIdMismatch, "%d (!= %d)", status.NetworkId, network)
 	}
 	if status.ChainID.Cmp(chainID) != 0 {
+		handshakeMismatches.record(mismatchChainId, status.ChainID.String())
 		return errResp(ErrChainIDMismatch, "%v (!= %v)", status.ChainID.String(), chainID.String())
 	}
 	if int(status.ProtocolVersion) != p.version {
@@ -918,6 +989,43 @@ func (p *multiChannelPeer) RequestReceipts(hashes []common.Hash) error {
 	return p.msgSender(ReceiptsRequestMsg, hashes)
 }
 
+// SendAccountRange sends a range of accounts in the state snapshot, corresponding
+// to the account range requested.
+func (p *multiChannelPeer) SendAccountRange(res *accountRangeData) error {
+	return p.msgSender(AccountRangeMsg, res)
+}
+
+// SendStorageRanges sends ranges of storage slots in the state snapshot,
+// corresponding to the storage ranges requested.
+func (p *multiChannelPeer) SendStorageRanges(res *storageRangesData) error {
+	return p.msgSender(StorageRangesMsg, res)
+}
+
+// SendByteCodes sends a batch of contract codes, corresponding to the hashes requested.
+func (p *multiChannelPeer) SendByteCodes(res *byteCodesData) error {
+	return p.msgSender(ByteCodesMsg, res)
+}
+
+// RequestAccountRange fetches a range of accounts in the state of the given root
+// from a remote node, with the Merkle proofs of the range.
+func (p *multiChannelPeer) RequestAccountRange(id uint64, root, origin, limit common.Hash, bytes uint64) error {
+	p.Log().Debug("Fetching range of accounts", "reqid", id, "root", root, "origin", origin, "limit", limit, "bytes", common.StorageSize(bytes))
+	return p.msgSender(GetAccountRangeMsg, &getAccountRangeData{ID: id, Root: root, Origin: origin, Limit: limit, Bytes: bytes})
+}
+
+// RequestStorageRanges fetches ranges of storage slots of the accounts in the state
+// of the given root from a remote node, with the Merkle proofs of the last range.
+func (p *multiChannelPeer) RequestStorageRanges(id uint64, root common.Hash, accounts []common.Hash, origin, limit []byte, bytes uint64) error {
+	p.Log().Debug("Fetching ranges of storage slots", "reqid", id, "root", root, "accounts", len(accounts), "bytes", common.StorageSize(bytes))
+	return p.msgSender(GetStorageRangesMsg, &getStorageRangesData{ID: id, Root: root, Accounts: accounts, Origin: origin, Limit: limit, Bytes: bytes})
+}
+
+// RequestByteCodes fetches a batch of contract codes from a remote node.
+func (p *multiChannelPeer) RequestByteCodes(id uint64, hashes []common.Hash, bytes uint64) error {
+	p.Log().Debug("Fetching batch of byte codes", "reqid", id, "count", len(hashes))
+	return p.msgSender(GetByteCodesMsg, &getByteCodesData{ID: id, Hashes: hashes, Bytes: bytes})
+}
+
 // msgSender sends data to the peer.
 func (p *multiChannelPeer) msgSender(msgcode uint64, data interface{}) error {
 	if ch, ok := ChannelOfMessage[msgcode]; ok && len(p.rws) > ch {
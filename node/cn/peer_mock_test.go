@@ -303,6 +303,20 @@ func (mr *MockPeerMockRecorder) Handshake(arg0, arg1, arg2, arg3, arg4 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Handshake", reflect.TypeOf((*MockPeer)(nil).Handshake), arg0, arg1, arg2, arg3, arg4)
 }
 
+// HasFeature mocks base method
+func (m *MockPeer) HasFeature(arg0 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasFeature", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// HasFeature indicates an expected call of HasFeature
+func (mr *MockPeerMockRecorder) HasFeature(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasFeature", reflect.TypeOf((*MockPeer)(nil).HasFeature), arg0)
+}
+
 // Head mocks base method
 func (m *MockPeer) Head() (common.Hash, *big.Int) {
 	m.ctrl.T.Helper()
@@ -388,6 +402,20 @@ func (mr *MockPeerMockRecorder) RegisterConsensusMsgCode(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterConsensusMsgCode", reflect.TypeOf((*MockPeer)(nil).RegisterConsensusMsgCode), arg0)
 }
 
+// RequestAccountRange mocks base method
+func (m *MockPeer) RequestAccountRange(arg0 uint64, arg1, arg2, arg3 common.Hash, arg4 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestAccountRange", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestAccountRange indicates an expected call of RequestAccountRange
+func (mr *MockPeerMockRecorder) RequestAccountRange(arg0 interface{}, arg1 interface{}, arg2 interface{}, arg3 interface{}, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestAccountRange", reflect.TypeOf((*MockPeer)(nil).RequestAccountRange), arg0, arg1, arg2, arg3, arg4)
+}
+
 // RequestBodies mocks base method
 func (m *MockPeer) RequestBodies(arg0 []common.Hash) error {
 	m.ctrl.T.Helper()
@@ -402,6 +430,20 @@ func (mr *MockPeerMockRecorder) RequestBodies(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestBodies", reflect.TypeOf((*MockPeer)(nil).RequestBodies), arg0)
 }
 
+// RequestByteCodes mocks base method
+func (m *MockPeer) RequestByteCodes(arg0 uint64, arg1 []common.Hash, arg2 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestByteCodes", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestByteCodes indicates an expected call of RequestByteCodes
+func (mr *MockPeerMockRecorder) RequestByteCodes(arg0 interface{}, arg1 interface{}, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestByteCodes", reflect.TypeOf((*MockPeer)(nil).RequestByteCodes), arg0, arg1, arg2)
+}
+
 // RequestHeadersByHash mocks base method
 func (m *MockPeer) RequestHeadersByHash(arg0 common.Hash, arg1, arg2 int, arg3 bool) error {
 	m.ctrl.T.Helper()
@@ -458,6 +500,20 @@ func (mr *MockPeerMockRecorder) RequestReceipts(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestReceipts", reflect.TypeOf((*MockPeer)(nil).RequestReceipts), arg0)
 }
 
+// RequestStorageRanges mocks base method
+func (m *MockPeer) RequestStorageRanges(arg0 uint64, arg1 common.Hash, arg2 []common.Hash, arg3, arg4 []byte, arg5 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestStorageRanges", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestStorageRanges indicates an expected call of RequestStorageRanges
+func (mr *MockPeerMockRecorder) RequestStorageRanges(arg0 interface{}, arg1 interface{}, arg2 interface{}, arg3 interface{}, arg4 interface{}, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestStorageRanges", reflect.TypeOf((*MockPeer)(nil).RequestStorageRanges), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
 // Send mocks base method
 func (m *MockPeer) Send(arg0 uint64, arg1 interface{}) error {
 	m.ctrl.T.Helper()
@@ -472,6 +528,20 @@ func (mr *MockPeerMockRecorder) Send(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockPeer)(nil).Send), arg0, arg1)
 }
 
+// SendAccountRange mocks base method
+func (m *MockPeer) SendAccountRange(arg0 *accountRangeData) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendAccountRange", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendAccountRange indicates an expected call of SendAccountRange
+func (mr *MockPeerMockRecorder) SendAccountRange(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendAccountRange", reflect.TypeOf((*MockPeer)(nil).SendAccountRange), arg0)
+}
+
 // SendBlockBodies mocks base method
 func (m *MockPeer) SendBlockBodies(arg0 []*blockBody) error {
 	m.ctrl.T.Helper()
@@ -514,6 +584,20 @@ func (mr *MockPeerMockRecorder) SendBlockHeaders(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendBlockHeaders", reflect.TypeOf((*MockPeer)(nil).SendBlockHeaders), arg0)
 }
 
+// SendByteCodes mocks base method
+func (m *MockPeer) SendByteCodes(arg0 *byteCodesData) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendByteCodes", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendByteCodes indicates an expected call of SendByteCodes
+func (mr *MockPeerMockRecorder) SendByteCodes(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendByteCodes", reflect.TypeOf((*MockPeer)(nil).SendByteCodes), arg0)
+}
+
 // SendFetchedBlockBodiesRLP mocks base method
 func (m *MockPeer) SendFetchedBlockBodiesRLP(arg0 []rlp.RawValue) error {
 	m.ctrl.T.Helper()
@@ -598,6 +682,20 @@ func (mr *MockPeerMockRecorder) SendReceiptsRLP(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendReceiptsRLP", reflect.TypeOf((*MockPeer)(nil).SendReceiptsRLP), arg0)
 }
 
+// SendStorageRanges mocks base method
+func (m *MockPeer) SendStorageRanges(arg0 *storageRangesData) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendStorageRanges", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendStorageRanges indicates an expected call of SendStorageRanges
+func (mr *MockPeerMockRecorder) SendStorageRanges(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendStorageRanges", reflect.TypeOf((*MockPeer)(nil).SendStorageRanges), arg0)
+}
+
 // SendTransactions mocks base method
 func (m *MockPeer) SendTransactions(arg0 types.Transactions) error {
 	m.ctrl.T.Helper()
@@ -612,6 +710,20 @@ func (mr *MockPeerMockRecorder) SendTransactions(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTransactions", reflect.TypeOf((*MockPeer)(nil).SendTransactions), arg0)
 }
 
+// ServesSnapshot mocks base method
+func (m *MockPeer) ServesSnapshot() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ServesSnapshot")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ServesSnapshot indicates an expected call of ServesSnapshot
+func (mr *MockPeerMockRecorder) ServesSnapshot() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServesSnapshot", reflect.TypeOf((*MockPeer)(nil).ServesSnapshot))
+}
+
 // SetAddr mocks base method
 func (m *MockPeer) SetAddr(arg0 common.Address) {
 	m.ctrl.T.Helper()
@@ -47,10 +47,23 @@ var ProtocolName = "klay"
 var ProtocolVersions = []uint{klay63, klay62}
 
 // ProtocolLengths are the number of implemented message corresponding to different protocol versions.
-var ProtocolLengths = []uint64{17, 8}
+var ProtocolLengths = []uint64{27, 8}
 
 const ProtocolMaxMsgSize = 10 * 1024 * 1024 // Maximum cap on the size of a protocol message
 
+// Optional features of the klay protocol. They are negotiated per peer in the handshake, so a
+// feature can be added without bumping the protocol version, and is used with a peer only if
+// Peer.HasFeature returns true.
+const (
+	FeatureHashFirstTxGossip = "klay/txhashes"    // announcing the hashes of transactions before sending them
+	FeatureCompression       = "klay/compression" // compressing the block bodies and receipts in the messages
+	FeatureSnapshotServing   = "klay/snapshot"    // serving the state snapshots
+)
+
+// ProtocolFeatures are the optional features of the klay protocol supported by this node.
+// A feature is added here once it is implemented.
+var ProtocolFeatures = []string{FeatureSnapshotServing}
+
 // Klaytn protocol message codes
 // TODO-Klaytn-Issue751 Protocol message should be refactored. Present code is not used.
 const (
@@ -77,6 +90,19 @@ const (
 	MsgCodeEnd = 0x10
 )
 
+// Klaytn protocol message codes of FeatureSnapshotServing. They are numbered after the
+// messages of the consensus engines, and exchanged only with the peers having the feature.
+const (
+	GetAccountRangeMsg  = 0x15
+	AccountRangeMsg     = 0x16
+	GetStorageRangesMsg = 0x17
+	StorageRangesMsg    = 0x18
+	GetByteCodesMsg     = 0x19
+	ByteCodesMsg        = 0x1a
+
+	SnapMsgCodeEnd = 0x1b
+)
+
 type errCode int
 
 const (
@@ -124,6 +150,9 @@ type ProtocolManagerDownloader interface {
 	DeliverHeaders(id string, headers []*types.Header) error
 	DeliverNodeData(id string, data [][]byte) error
 	DeliverReceipts(id string, receipts [][]*types.Receipt) error
+	DeliverAccountRange(id string, reqID uint64, hashes []common.Hash, accounts [][]byte, proof [][]byte) error
+	DeliverStorageRanges(id string, reqID uint64, hashes [][]common.Hash, slots [][][]byte, proof [][]byte) error
+	DeliverByteCodes(id string, reqID uint64, codes [][]byte) error
 
 	Terminate()
 	Synchronise(id string, head common.Hash, td *big.Int, mode downloader.SyncMode) error
@@ -0,0 +1,391 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"bytes"
+
+	"github.com/klaytn/klaytn/blockchain/types/account"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/networks/p2p"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/klaytn/klaytn/work"
+)
+
+// maxCodeLookups is the maximum number of contract codes to serve in a response.
+const maxCodeLookups = 1024
+
+var (
+	emptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+	emptyCode = crypto.Keccak256Hash(nil)
+)
+
+// getAccountRangeData is the network packet to request a range of accounts
+// in the state snapshot.
+type getAccountRangeData struct {
+	ID     uint64      // Request ID to match up the response with
+	Root   common.Hash // Root hash of the state to serve
+	Origin common.Hash // Hash of the first account to retrieve
+	Limit  common.Hash // Hash of the last account to retrieve
+	Bytes  uint64      // Soft limit at which to stop returning data
+}
+
+// accountRangeData is the network packet of a range of accounts, with the
+// Merkle proofs of the first and the last accounts.
+type accountRangeData struct {
+	ID       uint64         // ID of the request this is a response for
+	Accounts []*accountData // List of consecutive accounts from the trie
+	Proof    [][]byte       // List of trie nodes proving the account range
+}
+
+// accountData is an account of the state snapshot.
+type accountData struct {
+	Hash common.Hash // Hash of the account
+	Body []byte      // Account trie value
+}
+
+// getStorageRangesData is the network packet to request ranges of storage
+// slots of multiple accounts in the state snapshot.
+type getStorageRangesData struct {
+	ID       uint64        // Request ID to match up the response with
+	Root     common.Hash   // Root hash of the state to serve
+	Accounts []common.Hash // Account hashes of the storage tries to serve
+	Origin   []byte        // Hash of the first storage slot to retrieve (only for the first account)
+	Limit    []byte        // Hash of the last storage slot to retrieve (only for the last account)
+	Bytes    uint64        // Soft limit at which to stop returning data
+}
+
+// storageRangesData is the network packet of ranges of storage slots. The
+// Merkle proofs are given only for the last range, if it is partial.
+type storageRangesData struct {
+	ID    uint64           // ID of the request this is a response for
+	Slots [][]*storageData // Lists of consecutive storage slots for the requested accounts
+	Proof [][]byte         // Trie nodes proving the last slot range
+}
+
+// storageData is a storage slot of the state snapshot.
+type storageData struct {
+	Hash common.Hash // Hash of the storage slot
+	Body []byte      // Storage trie value
+}
+
+// getByteCodesData is the network packet to request a batch of contract codes.
+type getByteCodesData struct {
+	ID     uint64        // Request ID to match up the response with
+	Hashes []common.Hash // Code hashes to retrieve the code for
+	Bytes  uint64        // Soft limit at which to stop returning data
+}
+
+// byteCodesData is the network packet of a batch of contract codes.
+type byteCodesData struct {
+	ID    uint64   // ID of the request this is a response for
+	Codes [][]byte // Requested contract codes
+}
+
+// proofCollector collects the distinct trie nodes of Merkle proofs. Only
+// WriteMerkleProof of the embedded DBManager is implemented, which is the only
+// method called by Trie.Prove.
+type proofCollector struct {
+	database.DBManager
+	seen  map[string]struct{}
+	nodes [][]byte
+}
+
+func newProofCollector() *proofCollector {
+	return &proofCollector{seen: make(map[string]struct{})}
+}
+
+func (c *proofCollector) WriteMerkleProof(key, value []byte) {
+	if _, ok := c.seen[string(key)]; ok {
+		return
+	}
+	c.seen[string(key)] = struct{}{}
+	c.nodes = append(c.nodes, value)
+}
+
+// responseLimit returns the soft limit of the response size of a request.
+func responseLimit(requested uint64) int {
+	if requested > softResponseLimit {
+		return softResponseLimit
+	}
+	return int(requested)
+}
+
+// serviceGetAccountRange assembles the response to an account range query. An
+// empty response is returned if the state is not available in the snapshot.
+func serviceGetAccountRange(chain work.BlockChain, req *getAccountRangeData) *accountRangeData {
+	res := &accountRangeData{ID: req.ID}
+	snaps := chain.Snapshots()
+	if snaps == nil {
+		return res
+	}
+	it, err := snaps.AccountIterator(req.Root, req.Origin)
+	if err != nil {
+		return res
+	}
+	var (
+		size  int
+		limit = responseLimit(req.Bytes)
+	)
+	for it.Next() {
+		hash, blob := it.Hash(), common.CopyBytes(it.Account())
+		res.Accounts = append(res.Accounts, &accountData{Hash: hash, Body: blob})
+		size += common.HashLength + len(blob)
+
+		// Include the first account beyond the limit to prove the range
+		if bytes.Compare(hash[:], req.Limit[:]) >= 0 || size > limit {
+			break
+		}
+	}
+	err = it.Error()
+	it.Release()
+	if err != nil {
+		return &accountRangeData{ID: req.ID}
+	}
+	// Prove the boundaries of the range with the account trie
+	tr, err := statedb.NewTrie(req.Root, chain.StateCache().TrieDB())
+	if err != nil {
+		return &accountRangeData{ID: req.ID}
+	}
+	proof := newProofCollector()
+	if err := tr.Prove(req.Origin[:], 0, proof); err != nil {
+		logger.Warn("Failed to prove account range", "origin", req.Origin, "err", err)
+		return &accountRangeData{ID: req.ID}
+	}
+	if len(res.Accounts) > 0 {
+		last := res.Accounts[len(res.Accounts)-1].Hash
+		if err := tr.Prove(last[:], 0, proof); err != nil {
+			logger.Warn("Failed to prove account range", "last", last, "err", err)
+			return &accountRangeData{ID: req.ID}
+		}
+	}
+	res.Proof = proof.nodes
+	return res
+}
+
+// serviceGetStorageRanges assembles the response to a storage ranges query. An
+// empty response is returned if the state is not available in the snapshot.
+func serviceGetStorageRanges(chain work.BlockChain, req *getStorageRangesData) *storageRangesData {
+	res := &storageRangesData{ID: req.ID}
+	snaps := chain.Snapshots()
+	if snaps == nil {
+		return res
+	}
+	var (
+		size  int
+		limit = responseLimit(req.Bytes)
+	)
+	for i, accountHash := range req.Accounts {
+		if size >= limit {
+			break
+		}
+		var origin common.Hash
+		if i == 0 && len(req.Origin) > 0 {
+			origin = common.BytesToHash(req.Origin)
+		}
+		last := common.HexToHash("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+		if i == len(req.Accounts)-1 && len(req.Limit) > 0 {
+			last = common.BytesToHash(req.Limit)
+		}
+		it, err := snaps.StorageIterator(req.Root, accountHash, origin)
+		if err != nil {
+			return &storageRangesData{ID: req.ID}
+		}
+		var (
+			slots   []*storageData
+			aborted bool
+		)
+		for it.Next() {
+			if size >= limit {
+				aborted = true
+				break
+			}
+			hash, blob := it.Hash(), common.CopyBytes(it.Slot())
+			slots = append(slots, &storageData{Hash: hash, Body: blob})
+			size += common.HashLength + len(blob)
+
+			// Include the first slot beyond the limit to prove the range
+			if bytes.Compare(hash[:], last[:]) >= 0 {
+				aborted = true
+				break
+			}
+		}
+		err = it.Error()
+		it.Release()
+		if err != nil {
+			return &storageRangesData{ID: req.ID}
+		}
+		res.Slots = append(res.Slots, slots)
+
+		// A partial range of the storage trie needs to be proven, which ends the response
+		if origin != (common.Hash{}) || aborted {
+			proof, err := proveStorageRange(chain, req.Root, accountHash, origin, slots)
+			if err != nil {
+				logger.Warn("Failed to prove storage range", "account", accountHash, "origin", origin, "err", err)
+				return &storageRangesData{ID: req.ID}
+			}
+			res.Proof = proof
+			break
+		}
+	}
+	return res
+}
+
+// proveStorageRange returns the Merkle proofs of the first and the last storage
+// slots of a range in the storage trie of the account.
+func proveStorageRange(chain work.BlockChain, root common.Hash, accountHash common.Hash, origin common.Hash, slots []*storageData) ([][]byte, error) {
+	triedb := chain.StateCache().TrieDB()
+	accTrie, err := statedb.NewTrie(root, triedb)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := accTrie.TryGet(accountHash[:])
+	if err != nil {
+		return nil, err
+	}
+	storageRoot := emptyRoot
+	if len(blob) > 0 {
+		serializer := account.NewAccountSerializer()
+		if err := rlp.DecodeBytes(blob, serializer); err != nil {
+			return nil, err
+		}
+		if pa := account.GetProgramAccount(serializer.GetAccount()); pa != nil {
+			storageRoot = pa.GetStorageRoot()
+		}
+	}
+	stTrie, err := statedb.NewTrie(storageRoot, triedb)
+	if err != nil {
+		return nil, err
+	}
+	proof := newProofCollector()
+	if err := stTrie.Prove(origin[:], 0, proof); err != nil {
+		return nil, err
+	}
+	if len(slots) > 0 {
+		last := slots[len(slots)-1].Hash
+		if err := stTrie.Prove(last[:], 0, proof); err != nil {
+			return nil, err
+		}
+	}
+	return proof.nodes, nil
+}
+
+// serviceGetByteCodes assembles the response to a byte codes query. The codes
+// not available are omitted in the response.
+func serviceGetByteCodes(chain work.BlockChain, req *getByteCodesData) *byteCodesData {
+	res := &byteCodesData{ID: req.ID}
+	var (
+		size  int
+		limit = responseLimit(req.Bytes)
+	)
+	for i, hash := range req.Hashes {
+		if i >= maxCodeLookups || size >= limit {
+			break
+		}
+		if hash == emptyCode {
+			// Peers should not request the empty code, but if they do, at
+			// least send them back a correct response without db lookups
+			res.Codes = append(res.Codes, []byte{})
+			continue
+		}
+		if code, err := chain.StateCache().ContractCode(hash); err == nil && len(code) > 0 {
+			res.Codes = append(res.Codes, code)
+			size += len(code)
+		}
+	}
+	return res
+}
+
+// handleGetAccountRangeMsg handles account range request message.
+func handleGetAccountRangeMsg(pm *ProtocolManager, p Peer, msg p2p.Msg) error {
+	var req getAccountRangeData
+	if err := msg.Decode(&req); err != nil {
+		return errResp(ErrDecode, "msg %v: %v", msg, err)
+	}
+	return p.SendAccountRange(serviceGetAccountRange(pm.blockchain, &req))
+}
+
+// handleAccountRangeMsg handles account range response message.
+func handleAccountRangeMsg(pm *ProtocolManager, p Peer, msg p2p.Msg) error {
+	var res accountRangeData
+	if err := msg.Decode(&res); err != nil {
+		return errResp(ErrDecode, "msg %v: %v", msg, err)
+	}
+	hashes := make([]common.Hash, len(res.Accounts))
+	accounts := make([][]byte, len(res.Accounts))
+	for i, acc := range res.Accounts {
+		hashes[i], accounts[i] = acc.Hash, acc.Body
+	}
+	if err := pm.downloader.DeliverAccountRange(p.GetID(), res.ID, hashes, accounts, res.Proof); err != nil {
+		logger.Debug("Failed to deliver account range", "err", err)
+	}
+	return nil
+}
+
+// handleGetStorageRangesMsg handles storage ranges request message.
+func handleGetStorageRangesMsg(pm *ProtocolManager, p Peer, msg p2p.Msg) error {
+	var req getStorageRangesData
+	if err := msg.Decode(&req); err != nil {
+		return errResp(ErrDecode, "msg %v: %v", msg, err)
+	}
+	return p.SendStorageRanges(serviceGetStorageRanges(pm.blockchain, &req))
+}
+
+// handleStorageRangesMsg handles storage ranges response message.
+func handleStorageRangesMsg(pm *ProtocolManager, p Peer, msg p2p.Msg) error {
+	var res storageRangesData
+	if err := msg.Decode(&res); err != nil {
+		return errResp(ErrDecode, "msg %v: %v", msg, err)
+	}
+	hashes := make([][]common.Hash, len(res.Slots))
+	slots := make([][][]byte, len(res.Slots))
+	for i, list := range res.Slots {
+		hashes[i] = make([]common.Hash, len(list))
+		slots[i] = make([][]byte, len(list))
+		for j, slot := range list {
+			hashes[i][j], slots[i][j] = slot.Hash, slot.Body
+		}
+	}
+	if err := pm.downloader.DeliverStorageRanges(p.GetID(), res.ID, hashes, slots, res.Proof); err != nil {
+		logger.Debug("Failed to deliver storage ranges", "err", err)
+	}
+	return nil
+}
+
+// handleGetByteCodesMsg handles byte codes request message.
+func handleGetByteCodesMsg(pm *ProtocolManager, p Peer, msg p2p.Msg) error {
+	var req getByteCodesData
+	if err := msg.Decode(&req); err != nil {
+		return errResp(ErrDecode, "msg %v: %v", msg, err)
+	}
+	return p.SendByteCodes(serviceGetByteCodes(pm.blockchain, &req))
+}
+
+// handleByteCodesMsg handles byte codes response message.
+func handleByteCodesMsg(pm *ProtocolManager, p Peer, msg p2p.Msg) error {
+	var res byteCodesData
+	if err := msg.Decode(&res); err != nil {
+		return errResp(ErrDecode, "msg %v: %v", msg, err)
+	}
+	if err := pm.downloader.DeliverByteCodes(p.GetID(), res.ID, res.Codes); err != nil {
+		logger.Debug("Failed to deliver byte codes", "err", err)
+	}
+	return nil
+}
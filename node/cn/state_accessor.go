@@ -0,0 +1,106 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/storage/statedb"
+)
+
+// stateAtBlock returns the state of the given block. If the state is missing, it is regenerated
+// by re-executing the blocks from the nearest ancestor whose state is available, going back
+// at most reexec blocks. The regenerated state is kept in a separate in-memory trie database,
+// so it does not affect the state of the blockchain.
+func (cn *CN) stateAtBlock(block *types.Block, reexec uint64) (*state.StateDB, error) {
+	// try to reexec blocks until we find a state or reach our limit
+	origin := block.NumberU64()
+	database := state.NewDatabaseWithExistingCache(cn.ChainDB(), cn.blockchain.StateCache().TrieDB().TrieNodeCache())
+
+	var stateDB *state.StateDB
+	var err error
+
+	for i := uint64(0); i < reexec; i++ {
+		if stateDB, err = state.New(block.Root(), database, nil); err == nil {
+			break
+		}
+		blockNumber := block.NumberU64()
+		if blockNumber == 0 {
+			break
+		}
+		block = cn.blockchain.GetBlock(block.ParentHash(), blockNumber-1)
+		if block == nil {
+			return nil, fmt.Errorf("block #%d not found", blockNumber-1)
+		}
+	}
+	if stateDB == nil {
+		if err == nil {
+			// reexec is zero, so the state is not even looked up
+			_, err = state.New(block.Root(), database, nil)
+		}
+		switch err.(type) {
+		case *statedb.MissingNodeError:
+			return nil, fmt.Errorf("required historical state unavailable (reexec=%d)", reexec)
+		default:
+			return nil, err
+		}
+	}
+	// State was available at historical point, regenerate
+	var (
+		start  = time.Now()
+		logged time.Time
+		proot  common.Hash
+	)
+	for block.NumberU64() < origin {
+		// Print progress logs if long enough time elapsed
+		if time.Since(logged) > log.StatsReportLimit {
+			logger.Info("Regenerating historical state", "block", block.NumberU64()+1, "target", origin, "remaining", origin-block.NumberU64()-1, "elapsed", time.Since(start))
+			logged = time.Now()
+		}
+		// Retrieve the next block to regenerate and process it
+		next := block.NumberU64() + 1
+		if block = cn.blockchain.GetBlockByNumber(next); block == nil {
+			return nil, fmt.Errorf("block #%d not found", next)
+		}
+		_, _, _, _, _, err := cn.blockchain.Processor().Process(block, stateDB, vm.Config{})
+		if err != nil {
+			return nil, fmt.Errorf("processing block %d failed: %v", block.NumberU64(), err)
+		}
+		// Finalize the state so any modifications are written to the trie
+		root, err := stateDB.Commit(true)
+		if err != nil {
+			return nil, err
+		}
+		if err := stateDB.Reset(root); err != nil {
+			return nil, fmt.Errorf("state reset after block %d failed: %v", block.NumberU64(), err)
+		}
+		database.TrieDB().Reference(root, common.Hash{})
+		if !common.EmptyHash(proot) {
+			database.TrieDB().Dereference(proot)
+		}
+		proot = root
+	}
+	nodeSize, preimageSize := database.TrieDB().Size()
+	logger.Info("Historical state regenerated", "block", block.NumberU64(), "elapsed", time.Since(start), "nodeSize", nodeSize, "preimageSize", preimageSize)
+	return stateDB, nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"crypto/rand"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+)
+
+const (
+	// stateSessionTTL is the time a state session is kept since it is used last.
+	stateSessionTTL = 5 * time.Minute
+
+	// maxStateSessions is the maximum number of the state sessions open at once.
+	maxStateSessions = 32
+)
+
+var (
+	errUnknownStateSession  = errors.New("unknown or expired state session")
+	errTooManyStateSessions = errors.New("too many state sessions")
+)
+
+// StateSession is a state of a block pinned for paginated iterations of accounts and storage.
+type StateSession struct {
+	Token       string      `json:"session"`
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   common.Hash `json:"blockHash"`
+	Root        common.Hash `json:"root"`
+	ExpiresAt   time.Time   `json:"expiresAt"`
+}
+
+type stateSession struct {
+	StateSession
+	referenced bool // true if the state root is referenced in the trie database
+	timer      *time.Timer
+}
+
+// stateSessions manages the state sessions. The root of a session is referenced in the
+// trie database, so the state is not garbage-collected while the blocks are imported.
+// A session is released if it is not used for stateSessionTTL.
+type stateSessions struct {
+	db  state.Database
+	ttl time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*stateSession
+}
+
+func newStateSessions(db state.Database, ttl time.Duration) *stateSessions {
+	return &stateSessions{
+		db:       db,
+		ttl:      ttl,
+		sessions: make(map[string]*stateSession),
+	}
+}
+
+// open pins the state of the given block and returns a new session of it.
+func (s *stateSessions) open(block *types.Block) (StateSession, error) {
+	root := block.Root()
+	if _, err := state.New(root, s.db, nil); err != nil {
+		return StateSession{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.sessions) >= maxStateSessions {
+		return StateSession{}, errTooManyStateSessions
+	}
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return StateSession{}, err
+	}
+
+	session := &stateSession{
+		StateSession: StateSession{
+			Token:       hexutil.Encode(token),
+			BlockNumber: block.NumberU64(),
+			BlockHash:   block.Hash(),
+			Root:        root,
+			ExpiresAt:   time.Now().Add(s.ttl),
+		},
+	}
+	// The nodes only in the memory are referenced. The persistent nodes are not garbage-collected.
+	trieDB := s.db.TrieDB()
+	s.db.RLockGCCachedNode()
+	if trieDB.DoesExistCachedNode(root) {
+		trieDB.Reference(root, common.Hash{})
+		session.referenced = true
+	}
+	s.db.RUnlockGCCachedNode()
+
+	session.timer = time.AfterFunc(s.ttl, func() { s.expire(session.Token) })
+	s.sessions[session.Token] = session
+
+	logger.Debug("Opened a state session", "number", session.BlockNumber, "root", root, "referenced", session.referenced)
+	return session.StateSession, nil
+}
+
+// get returns the session of the token and extends its expiration.
+func (s *stateSessions) get(token string) (StateSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[token]
+	if !ok {
+		return StateSession{}, errUnknownStateSession
+	}
+	session.timer.Reset(s.ttl)
+	session.ExpiresAt = time.Now().Add(s.ttl)
+	return session.StateSession, nil
+}
+
+// close releases the session of the token.
+func (s *stateSessions) close(token string) error {
+	s.mu.Lock()
+	session, ok := s.sessions[token]
+	delete(s.sessions, token)
+	s.mu.Unlock()
+
+	if !ok {
+		return errUnknownStateSession
+	}
+	session.timer.Stop()
+	s.release(session)
+	return nil
+}
+
+// expire releases the session of the token if it is not used for the TTL.
+func (s *stateSessions) expire(token string) {
+	s.mu.Lock()
+	session, ok := s.sessions[token]
+	if !ok || time.Now().Before(session.ExpiresAt) {
+		// closed or extended in the meantime
+		s.mu.Unlock()
+		return
+	}
+	delete(s.sessions, token)
+	s.mu.Unlock()
+
+	s.release(session)
+	logger.Debug("State session expired", "number", session.BlockNumber, "root", session.Root)
+}
+
+// closeAll releases all the sessions.
+func (s *stateSessions) closeAll() {
+	s.mu.Lock()
+	sessions := s.sessions
+	s.sessions = make(map[string]*stateSession)
+	s.mu.Unlock()
+
+	for _, session := range sessions {
+		session.timer.Stop()
+		s.release(session)
+	}
+}
+
+func (s *stateSessions) release(session *stateSession) {
+	if session.referenced {
+		s.db.TrieDB().Dereference(session.Root)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+// newSessionTestBlock returns a block whose state is committed only to the memory of the trie database.
+func newSessionTestBlock(t *testing.T, db state.Database, number int64) *types.Block {
+	stateDB, err := state.New(common.Hash{}, db, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := int64(0); i < 10; i++ {
+		stateDB.AddBalance(common.BigToAddress(big.NewInt(number*100+i)), big.NewInt(i+1))
+	}
+	root, err := stateDB.Commit(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return types.NewBlockWithHeader(&types.Header{Number: big.NewInt(number), Root: root})
+}
+
+// Tests that the state of a session is not garbage-collected until the session is closed.
+func TestStateSessions_Pin(t *testing.T) {
+	db := state.NewDatabase(database.NewMemoryDBManager())
+	sessions := newStateSessions(db, time.Minute)
+	defer sessions.closeAll()
+
+	block := newSessionTestBlock(t, db, 1)
+	db.TrieDB().Reference(block.Root(), common.Hash{}) // referenced by the blockchain
+
+	session, err := sessions.open(block)
+	assert.NoError(t, err)
+	assert.Equal(t, block.Root(), session.Root)
+	assert.Equal(t, uint64(1), session.BlockNumber)
+
+	// The blockchain releases the state, but the session still pins it
+	db.TrieDB().Dereference(block.Root())
+	_, err = state.New(block.Root(), db, nil)
+	assert.NoError(t, err)
+
+	got, err := sessions.get(session.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, session.Root, got.Root)
+
+	// The state is garbage-collected after the session is closed
+	assert.NoError(t, sessions.close(session.Token))
+	_, err = state.New(block.Root(), db, nil)
+	assert.Error(t, err)
+
+	_, err = sessions.get(session.Token)
+	assert.Equal(t, errUnknownStateSession, err)
+	assert.Equal(t, errUnknownStateSession, sessions.close(session.Token))
+}
+
+// Tests that a session is released if it is not used for the TTL.
+func TestStateSessions_Expire(t *testing.T) {
+	db := state.NewDatabase(database.NewMemoryDBManager())
+	sessions := newStateSessions(db, 50*time.Millisecond)
+	defer sessions.closeAll()
+
+	block := newSessionTestBlock(t, db, 1)
+	session, err := sessions.open(block)
+	assert.NoError(t, err)
+
+	// The expiration is extended by using the session
+	for i := 0; i < 3; i++ {
+		time.Sleep(30 * time.Millisecond)
+		_, err = sessions.get(session.Token)
+		assert.NoError(t, err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	_, err = sessions.get(session.Token)
+	assert.Equal(t, errUnknownStateSession, err)
+	_, err = state.New(block.Root(), db, nil)
+	assert.Error(t, err)
+}
+
+func TestStateSessions_Limit(t *testing.T) {
+	db := state.NewDatabase(database.NewMemoryDBManager())
+	sessions := newStateSessions(db, time.Minute)
+	defer sessions.closeAll()
+
+	block := newSessionTestBlock(t, db, 1)
+	for i := 0; i < maxStateSessions; i++ {
+		_, err := sessions.open(block)
+		assert.NoError(t, err)
+	}
+	_, err := sessions.open(block)
+	assert.Equal(t, errTooManyStateSessions, err)
+
+	// The missing state cannot be pinned
+	sessions.closeAll()
+	_, err = sessions.open(types.NewBlockWithHeader(&types.Header{Number: big.NewInt(2), Root: common.Hash{1}}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,163 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/reward"
+)
+
+// totalSupplyCacheSize is the number of the blocks whose total supply is cached.
+const totalSupplyCacheSize = 128
+
+// TotalSupplyResult is the total and the circulating supply of KLAY at a block with their components.
+//
+//	totalSupply       = genesisAlloc + minted - burntFee - burnAddresses
+//	circulatingSupply = totalSupply - treasury
+type TotalSupplyResult struct {
+	Number            hexutil.Uint64 `json:"number"`
+	TotalSupply       *hexutil.Big   `json:"totalSupply"`
+	CirculatingSupply *hexutil.Big   `json:"circulatingSupply"`
+	GenesisAlloc      *hexutil.Big   `json:"genesisAlloc"`  // sum of the balances allocated in the genesis block
+	Minted            *hexutil.Big   `json:"minted"`        // KLAY minted by the block rewards
+	BurntFee          *hexutil.Big   `json:"burntFee"`      // transaction fees burnt
+	BurnAddresses     *hexutil.Big   `json:"burnAddresses"` // sum of the balances of the burn addresses
+	Treasury          *hexutil.Big   `json:"treasury"`      // sum of the balances of the treasury addresses
+}
+
+// totalSupplies computes the total supply of KLAY at blocks and caches them per block.
+type totalSupplies struct {
+	burnAddrs     []common.Address
+	treasuryAddrs []common.Address
+	cache         *lru.Cache // block hash -> *TotalSupplyResult
+
+	mu           sync.Mutex
+	genesisAlloc *big.Int // computed on the first request since it iterates the genesis state
+}
+
+func newTotalSupplies(burnAddrs, treasuryAddrs []common.Address) *totalSupplies {
+	cache, _ := lru.New(totalSupplyCacheSize)
+	return &totalSupplies{
+		burnAddrs:     burnAddrs,
+		treasuryAddrs: treasuryAddrs,
+		cache:         cache,
+	}
+}
+
+// totalSupply returns the total supply of KLAY at the given block whose state is stateDB.
+func (cn *CN) totalSupply(header *types.Header, stateDB *state.StateDB) (*TotalSupplyResult, error) {
+	if cached, ok := cn.totalSupplies.cache.Get(header.Hash()); ok {
+		return cached.(*TotalSupplyResult), nil
+	}
+	if cn.supplyTracker == nil {
+		return nil, errSupplyNotTracked
+	}
+
+	genesisAlloc, err := cn.genesisAlloc()
+	if err != nil {
+		return nil, err
+	}
+	cumulative, err := cn.supplyTracker.Cumulative(header.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+
+	burnt := sumBalances(stateDB, cn.totalSupplies.burnAddrs)
+	treasury := sumBalances(stateDB, cn.treasuryAddrs(header.Number.Uint64()))
+
+	total := new(big.Int).Add(genesisAlloc, cumulative.Minted)
+	total.Sub(total, cumulative.BurntFee)
+	total.Sub(total, burnt)
+
+	result := &TotalSupplyResult{
+		Number:            hexutil.Uint64(header.Number.Uint64()),
+		TotalSupply:       (*hexutil.Big)(total),
+		CirculatingSupply: (*hexutil.Big)(new(big.Int).Sub(total, treasury)),
+		GenesisAlloc:      (*hexutil.Big)(genesisAlloc),
+		Minted:            (*hexutil.Big)(cumulative.Minted),
+		BurntFee:          (*hexutil.Big)(cumulative.BurntFee),
+		BurnAddresses:     (*hexutil.Big)(burnt),
+		Treasury:          (*hexutil.Big)(treasury),
+	}
+	cn.totalSupplies.cache.Add(header.Hash(), result)
+	return result, nil
+}
+
+// genesisAlloc returns the sum of the balances in the genesis state.
+func (cn *CN) genesisAlloc() (*big.Int, error) {
+	cn.totalSupplies.mu.Lock()
+	defer cn.totalSupplies.mu.Unlock()
+
+	if cn.totalSupplies.genesisAlloc != nil {
+		return cn.totalSupplies.genesisAlloc, nil
+	}
+	genesis := cn.blockchain.GetBlockByNumber(0)
+	if genesis == nil {
+		return nil, errors.New("genesis block not found")
+	}
+	stateDB, err := cn.blockchain.StateAt(genesis.Root())
+	if err != nil {
+		return nil, err
+	}
+	alloc, err := stateDB.TotalBalance()
+	if err != nil {
+		return nil, err
+	}
+	cn.totalSupplies.genesisAlloc = alloc
+	return alloc, nil
+}
+
+// treasuryAddrs returns the configured treasury addresses with the PoC and KIR of the given block.
+func (cn *CN) treasuryAddrs(number uint64) []common.Address {
+	addrs := cn.totalSupplies.treasuryAddrs
+	if reward.GetStakingManager() == nil {
+		return addrs
+	}
+	stakingInfo := reward.GetStakingInfo(number)
+	if stakingInfo == nil {
+		return addrs
+	}
+	addrs = append([]common.Address{}, addrs...)
+	for _, addr := range []common.Address{stakingInfo.PoCAddr, stakingInfo.KIRAddr} {
+		if !common.EmptyAddress(addr) {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// sumBalances returns the sum of the balances of the given addresses, each counted once.
+func sumBalances(stateDB *state.StateDB, addrs []common.Address) *big.Int {
+	sum := new(big.Int)
+	seen := make(map[common.Address]bool)
+	for _, addr := range addrs {
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		sum.Add(sum, stateDB.GetBalance(addr))
+	}
+	return sum
+}
@@ -0,0 +1,130 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/reward"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+// testSupplyGovernance mints 10 peb per block with the unit price of 1 peb.
+type testSupplyGovernance struct{}
+
+func (testSupplyGovernance) Epoch() uint64                 { return 100 }
+func (testSupplyGovernance) DeferredTxFee() bool           { return true }
+func (testSupplyGovernance) ProposerPolicy() uint64        { return params.RoundRobin }
+func (testSupplyGovernance) StakingUpdateInterval() uint64 { return 100 }
+func (testSupplyGovernance) GetItemAtNumberByIntKey(num uint64, key int) (interface{}, error) {
+	switch key {
+	case params.Epoch:
+		return uint64(100), nil
+	case params.MintingAmount:
+		return "10", nil
+	case params.Ratio:
+		return "34/54/12", nil
+	case params.UnitPrice:
+		return uint64(1), nil
+	}
+	return nil, errors.New("unknown key")
+}
+
+func newSupplyTestState(t *testing.T, db state.Database, balances map[common.Address]int64) *state.StateDB {
+	stateDB, err := state.New(common.Hash{}, db, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for addr, balance := range balances {
+		stateDB.AddBalance(addr, big.NewInt(balance))
+	}
+	root, err := stateDB.Commit(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stateDB, err = state.New(root, db, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stateDB
+}
+
+func TestCN_TotalSupply(t *testing.T) {
+	var (
+		burnAddr     = common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+		treasuryAddr = common.Address{0x02}
+		db           = state.NewDatabase(database.NewMemoryDBManager())
+	)
+	genesisState := newSupplyTestState(t, db, map[common.Address]int64{{0x01}: 1000, treasuryAddr: 200})
+	headState := newSupplyTestState(t, db, map[common.Address]int64{{0x01}: 900, treasuryAddr: 200, burnAddr: 100})
+
+	// Blocks 1 and 2 mint 20 peb in total, and the genesis block mints nothing
+	headers := make([]*types.Header, 3)
+	for i := range headers {
+		headers[i] = &types.Header{Number: big.NewInt(int64(i)), GasUsed: uint64(i), Root: genesisState.IntermediateRoot(false)}
+	}
+	headers[2].Root = headState.IntermediateRoot(false)
+	genesis := types.NewBlockWithHeader(headers[0])
+
+	mockCtrl, mockBlockChain, _, api := newCNAPIBackend(t)
+	defer mockCtrl.Finish()
+
+	var feed event.Feed
+	mockBlockChain.EXPECT().SubscribeChainHeadEvent(gomock.Any()).DoAndReturn(feed.Subscribe).Times(1)
+	mockBlockChain.EXPECT().CurrentHeader().Return(headers[2]).AnyTimes()
+	mockBlockChain.EXPECT().GetHeaderByNumber(gomock.Any()).DoAndReturn(func(number uint64) *types.Header { return headers[number] }).AnyTimes()
+	mockBlockChain.EXPECT().GetBlockByNumber(uint64(0)).Return(genesis).Times(1)
+	mockBlockChain.EXPECT().StateAt(genesis.Root()).Return(genesisState, nil).Times(1)
+
+	cn := api.cn
+	cn.totalSupplies = newTotalSupplies([]common.Address{{}, burnAddr}, []common.Address{treasuryAddr})
+
+	_, err := cn.totalSupply(headers[2], headState)
+	assert.Equal(t, errSupplyNotTracked, err)
+
+	cn.supplyTracker = reward.NewSupplyTracker(mockBlockChain, testSupplyGovernance{}, database.NewMemoryDBManager())
+	assert.NoError(t, cn.supplyTracker.Start())
+	defer cn.supplyTracker.Stop()
+	for i := 0; i < 100 && cn.supplyTracker.Head() < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	result, err := cn.totalSupply(headers[2], headState)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1200), result.GenesisAlloc.ToInt().Int64())
+	assert.Equal(t, int64(20), result.Minted.ToInt().Int64())
+	assert.Equal(t, int64(0), result.BurntFee.ToInt().Int64())
+	assert.Equal(t, int64(100), result.BurnAddresses.ToInt().Int64())
+	assert.Equal(t, int64(200), result.Treasury.ToInt().Int64())
+	assert.Equal(t, int64(1200+20-100), result.TotalSupply.ToInt().Int64())
+	assert.Equal(t, int64(1200+20-100-200), result.CirculatingSupply.ToInt().Int64())
+
+	// Cached per block, so neither the genesis state nor the head state is read again
+	cached, err := cn.totalSupply(headers[2], nil)
+	assert.NoError(t, err)
+	assert.Equal(t, result, cached)
+}
@@ -0,0 +1,352 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/node/cn/tracers"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+)
+
+// traceFilterMaxBlocks is the maximum number of the blocks not covered by the trace index
+// traced by trace_filter.
+const traceFilterMaxBlocks = 1000
+
+// blockCallTracer returns the parity-style call traces of the transactions of a block.
+type blockCallTracer func(ctx context.Context, block *types.Block) ([]*tracers.FlatCallFrame, error)
+
+// traceBlockCalls traces the block with flatCallTracer, and fills the block and the transaction
+// fields of the traces.
+func (api *PrivateDebugAPI) traceBlockCalls(ctx context.Context, block *types.Block) ([]*tracers.FlatCallFrame, error) {
+	tracer := "flatCallTracer"
+	results, err := api.traceBlock(ctx, block, &TraceConfig{Tracer: &tracer})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		traces    []*tracers.FlatCallFrame
+		blockHash = block.Hash()
+	)
+	for i, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("failed to trace transaction %#x: %s", result.TxHash, result.Error)
+		}
+		txHash := result.TxHash
+		for _, trace := range result.Result.([]*tracers.FlatCallFrame) {
+			trace.BlockHash = &blockHash
+			trace.BlockNumber = block.NumberU64()
+			trace.TransactionHash = &txHash
+			trace.TransactionPosition = uint64(i)
+			traces = append(traces, trace)
+		}
+	}
+	return traces, nil
+}
+
+// traceFromAddress returns the account which is the sender of the traced call, creation or
+// self-destruction, and traceToAddress returns its receiver, created contract or beneficiary.
+func traceFromAddress(trace *tracers.FlatCallFrame) *common.Address {
+	if trace.Action.Address != nil {
+		return trace.Action.Address
+	}
+	return trace.Action.From
+}
+
+func traceToAddress(trace *tracers.FlatCallFrame) *common.Address {
+	switch {
+	case trace.Action.RefundAddress != nil:
+		return trace.Action.RefundAddress
+	case trace.Result != nil && trace.Result.Address != nil:
+		return trace.Result.Address
+	}
+	return trace.Action.To
+}
+
+// indexTraces stores the accounts appearing in the call traces of the block to the trace index.
+func indexTraces(db database.DBManager, block *types.Block, traces []*tracers.FlatCallFrame) error {
+	var (
+		batch = db.NewTraceIndexBatch()
+		seen  = make(map[common.Address]struct{})
+	)
+	for _, trace := range traces {
+		for _, addr := range []*common.Address{traceFromAddress(trace), traceToAddress(trace)} {
+			if addr == nil {
+				continue
+			}
+			if _, ok := seen[*addr]; ok {
+				continue
+			}
+			seen[*addr] = struct{}{}
+			if err := db.PutTracedAddressToBatch(batch, *addr, block.NumberU64(), block.Hash()); err != nil {
+				return err
+			}
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	return db.WriteTraceIndexHead(block.NumberU64())
+}
+
+// traceIndexer subscribes chainEvent and chainReorgEvent, and stores the accounts appearing in the
+// call traces of the canonical blocks to the trace index from the given block. Since tracing is
+// much slower than receiving the events, the blocks are indexed in the background of receiving
+// the events, so the chain insertion is not blocked by the indexer. The entries of the blocks
+// dropped by a reorg are left in the index, and skipped by the canonical hashes when read.
+func traceIndexer(db database.DBManager, trace blockCallTracer, next, head uint64,
+	chainEvent <-chan blockchain.ChainEvent, chainSub event.Subscription,
+	reorgEvent <-chan blockchain.ChainReorgEvent, reorgSub event.Subscription) {
+	defer chainSub.Unsubscribe()
+	defer reorgSub.Unsubscribe()
+
+	// ready is selected while there are blocks to be indexed
+	ready := make(chan struct{})
+	close(ready)
+
+	for {
+		var pending <-chan struct{}
+		if next <= head {
+			pending = ready
+		}
+		select {
+		case ev := <-chainEvent:
+			if number := ev.Block.NumberU64(); number > head {
+				head = number
+			}
+
+		case ev := <-reorgEvent:
+			// The blocks of the new chain are indexed again
+			for _, hash := range ev.AddedBlocks {
+				if number := db.ReadHeaderNumber(hash); number != nil && *number < next {
+					next = *number
+				}
+			}
+
+		case <-pending:
+			block := db.ReadBlockByNumber(next)
+			if block == nil {
+				logger.Error("Failed to read the block to index traces", "blockNum", next)
+				head = next - 1 // retried by the next chain event
+				continue
+			}
+			traces, err := trace(context.Background(), block)
+			if err == nil {
+				err = indexTraces(db, block, traces)
+			}
+			if err != nil {
+				logger.Error("Failed to store trace index to database", "blockNum", next, "err", err)
+				head = next - 1 // retried by the next chain event
+				continue
+			}
+			next++
+
+		case <-chainSub.Err():
+			return
+		case <-reorgSub.Err():
+			return
+		}
+	}
+}
+
+// TraceFilterArgs is the filter of trace_filter. The traces are matched if their senders are
+// in FromAddress and their receivers are in ToAddress, where an empty list matches any account.
+type TraceFilterArgs struct {
+	FromBlock   *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock     *rpc.BlockNumber `json:"toBlock"`
+	FromAddress []common.Address `json:"fromAddress"`
+	ToAddress   []common.Address `json:"toAddress"`
+	After       *uint64          `json:"after"`
+	Count       *uint64          `json:"count"`
+}
+
+func containsAddress(addrs []common.Address, addr *common.Address) bool {
+	if len(addrs) == 0 {
+		return true
+	}
+	if addr == nil {
+		return false
+	}
+	for _, a := range addrs {
+		if a == *addr {
+			return true
+		}
+	}
+	return false
+}
+
+// tracedBlocks returns the numbers of the blocks to be traced for the filter in the given range.
+// The blocks covered by the trace index are the ones whose call traces contain the filtered accounts,
+// and the others are all the blocks not covered by the index.
+func (s *CN) tracedBlocks(from, to uint64, args *TraceFilterArgs) ([]uint64, error) {
+	// The blocks from indexFrom to indexTo are read from the index
+	indexFrom, indexTo := to+1, to
+	if s.config.TraceIndexing && len(args.FromAddress)+len(args.ToAddress) > 0 {
+		tail, err := s.chainDB.ReadTraceIndexTail()
+		if err != nil {
+			return nil, err
+		}
+		head, err := s.chainDB.ReadTraceIndexHead()
+		if err != nil {
+			return nil, err
+		}
+		if tail != 0 {
+			indexFrom, indexTo = tail, head
+		}
+		if indexFrom < from {
+			indexFrom = from
+		}
+		if indexTo > to {
+			indexTo = to
+		}
+	}
+
+	if indexFrom > indexTo {
+		// No block of the range is covered by the index
+		indexFrom, indexTo = to+1, to
+	}
+	if unindexed := (to - from + 1) - (indexTo + 1 - indexFrom); unindexed > traceFilterMaxBlocks {
+		return nil, fmt.Errorf("too many blocks to trace without the trace index (--traceindexing): %d > %d", unindexed, traceFilterMaxBlocks)
+	}
+
+	var numbers []uint64
+	for number := from; number < indexFrom; number++ {
+		numbers = append(numbers, number)
+	}
+	var (
+		seen      = make(map[uint64]struct{})
+		canonical = make(map[uint64]common.Hash)
+	)
+	for _, addr := range append(append([]common.Address{}, args.FromAddress...), args.ToAddress...) {
+		s.chainDB.IterateTracedBlocks(addr, indexFrom, func(blockNum uint64, blockHash common.Hash) bool {
+			if blockNum > indexTo {
+				return false
+			}
+			hash, ok := canonical[blockNum]
+			if !ok {
+				hash = s.chainDB.ReadCanonicalHash(blockNum)
+				canonical[blockNum] = hash
+			}
+			// The entries of the blocks dropped by reorgs are skipped
+			if _, ok := seen[blockNum]; !ok && blockHash == hash {
+				seen[blockNum] = struct{}{}
+				numbers = append(numbers, blockNum)
+			}
+			return true
+		})
+	}
+	for number := indexTo + 1; number <= to; number++ {
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+	return numbers, nil
+}
+
+// traceFilter returns the call traces matching the filter in the given range, both inclusive.
+// The blocks are traced by the given tracer, and the traces are returned in the order of execution.
+func (s *CN) traceFilter(ctx context.Context, trace blockCallTracer, from, to uint64, args *TraceFilterArgs) ([]*tracers.FlatCallFrame, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid block range: from %d > to %d", from, to)
+	}
+	result := []*tracers.FlatCallFrame{}
+	if from == 0 {
+		// The genesis block has no transaction to be traced
+		if to == 0 {
+			return result, nil
+		}
+		from = 1
+	}
+	numbers, err := s.tracedBlocks(from, to, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var after uint64
+	if args.After != nil {
+		after = *args.After
+	}
+	for _, number := range numbers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block := s.blockchain.GetBlockByNumber(number)
+		if block == nil {
+			return nil, fmt.Errorf("block %d is not available", number)
+		}
+		traces, err := trace(ctx, block)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range traces {
+			if !containsAddress(args.FromAddress, traceFromAddress(t)) || !containsAddress(args.ToAddress, traceToAddress(t)) {
+				continue
+			}
+			if after > 0 {
+				after--
+				continue
+			}
+			result = append(result, t)
+			if args.Count != nil && uint64(len(result)) >= *args.Count {
+				return result, nil
+			}
+		}
+	}
+	return result, nil
+}
+
+// PrivateTraceAPI is the collection of the parity-style trace APIs.
+type PrivateTraceAPI struct {
+	cn    *CN
+	debug *PrivateDebugAPI
+}
+
+// NewPrivateTraceAPI creates a new API definition for the parity-style trace APIs.
+func NewPrivateTraceAPI(config *params.ChainConfig, cn *CN) *PrivateTraceAPI {
+	return &PrivateTraceAPI{cn: cn, debug: NewPrivateDebugAPI(config, cn)}
+}
+
+// Filter returns the call traces matching the filter in the given range of blocks, which is from
+// the latest block to the latest block by default. The blocks covered by the trace index, enabled
+// by --traceindexing, are traced only if they contain the filtered accounts, and the other blocks
+// are all traced up to a limited number of blocks.
+func (api *PrivateTraceAPI) Filter(ctx context.Context, args TraceFilterArgs) ([]*tracers.FlatCallFrame, error) {
+	from, to := rpc.LatestBlockNumber, rpc.LatestBlockNumber
+	if args.FromBlock != nil {
+		from = *args.FromBlock
+	}
+	if args.ToBlock != nil {
+		to = *args.ToBlock
+	}
+	fromNum, toNum, err := api.cn.resolveBlockRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if args.Count != nil && *args.Count == 0 {
+		return nil, errors.New("invalid count: 0")
+	}
+	return api.cn.traceFilter(ctx, api.debug.traceBlockCalls, fromNum, toNum, &args)
+}
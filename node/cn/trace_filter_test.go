@@ -0,0 +1,208 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package cn
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/node/cn/tracers"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCallTrace(typ string, from, to common.Address) *tracers.FlatCallFrame {
+	trace := &tracers.FlatCallFrame{Type: typ, TraceAddress: []int{}}
+	switch typ {
+	case "create":
+		trace.Action.From = &from
+		trace.Result = &tracers.FlatCallResult{Address: &to}
+	case "suicide":
+		trace.Action.Address, trace.Action.RefundAddress = &from, &to
+	default:
+		trace.Action.From, trace.Action.To = &from, &to
+	}
+	return trace
+}
+
+func TestCN_TraceFilter(t *testing.T) {
+	mockCtrl, _, mockBlockChain, _ := newMocks(t)
+	defer mockCtrl.Finish()
+
+	var (
+		db     = database.NewMemoryDBManager()
+		cn     = &CN{config: &Config{TraceIndexing: true}, chainDB: db, blockchain: mockBlockChain}
+		addrA  = common.Address{0x0a}
+		addrB  = common.Address{0x0b}
+		addrC  = common.Address{0x0c}
+		blocks []*types.Block
+		traces = make(map[common.Hash][]*tracers.FlatCallFrame)
+		traced []uint64
+	)
+	trace := func(ctx context.Context, block *types.Block) ([]*tracers.FlatCallFrame, error) {
+		traced = append(traced, block.NumberU64())
+		return traces[block.Hash()], nil
+	}
+	// commit writes a new block with the given call traces
+	commit := func(extra byte, canonical bool, calls ...*tracers.FlatCallFrame) *types.Block {
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(int64(len(blocks))), Extra: []byte{extra}})
+		traces[block.Hash()] = calls
+		if canonical {
+			db.WriteCanonicalHash(block.Hash(), block.NumberU64())
+			mockBlockChain.EXPECT().GetBlockByNumber(block.NumberU64()).Return(block).AnyTimes()
+			blocks = append(blocks, block)
+		}
+		return block
+	}
+	index := func(block *types.Block) {
+		assert.NoError(t, indexTraces(db, block, traces[block.Hash()]))
+	}
+
+	commit(0, true)
+	// Block 1 is not covered by the index
+	commit(0, true, newTestCallTrace("call", addrA, addrB))
+	assert.NoError(t, db.WriteTraceIndexTail(2))
+	index(commit(0, true, newTestCallTrace("call", addrB, addrC), newTestCallTrace("create", addrB, addrA)))
+	// The entries of the block dropped by a reorg are not read
+	index(commit(1, false, newTestCallTrace("call", addrC, addrA)))
+	index(commit(0, true, newTestCallTrace("call", addrA, addrB)))
+	index(commit(0, true, newTestCallTrace("suicide", addrA, addrC)))
+	// Block 5 is not indexed yet
+	commit(0, true, newTestCallTrace("call", addrC, addrB))
+
+	filter := func(from, to uint64, args *TraceFilterArgs) []*tracers.FlatCallFrame {
+		traced = nil
+		result, err := cn.traceFilter(context.Background(), trace, from, to, args)
+		assert.NoError(t, err)
+		return result
+	}
+	calls := func(blockNum uint64, indices ...int) []*tracers.FlatCallFrame {
+		var result []*tracers.FlatCallFrame
+		for _, i := range indices {
+			result = append(result, traces[blocks[blockNum].Hash()][i])
+		}
+		return result
+	}
+	concat := func(traces ...[]*tracers.FlatCallFrame) []*tracers.FlatCallFrame {
+		result := []*tracers.FlatCallFrame{}
+		for _, t := range traces {
+			result = append(result, t...)
+		}
+		return result
+	}
+
+	// Only the blocks containing the filtered accounts are traced in the indexed range
+	assert.Equal(t, concat(calls(1, 0), calls(3, 0), calls(5, 0)), filter(0, 5, &TraceFilterArgs{ToAddress: []common.Address{addrB}}))
+	assert.Equal(t, []uint64{1, 2, 3, 5}, traced)
+
+	assert.Equal(t, concat(calls(2, 0), calls(4, 0)), filter(2, 4, &TraceFilterArgs{ToAddress: []common.Address{addrC}}))
+	assert.Equal(t, []uint64{2, 4}, traced)
+
+	assert.Equal(t, concat(calls(5, 0)), filter(0, 5, &TraceFilterArgs{FromAddress: []common.Address{addrC}}))
+	assert.Equal(t, []uint64{1, 2, 4, 5}, traced)
+
+	// Both the sender and the receiver are matched
+	assert.Equal(t, concat(calls(1, 0), calls(3, 0)), filter(0, 5, &TraceFilterArgs{
+		FromAddress: []common.Address{addrA}, ToAddress: []common.Address{addrB}}))
+
+	// All the blocks are traced without the filtered accounts or the index
+	all := concat(calls(1, 0), calls(2, 0, 1), calls(3, 0), calls(4, 0), calls(5, 0))
+	assert.Equal(t, all, filter(0, 5, &TraceFilterArgs{}))
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5}, traced)
+	cn.config.TraceIndexing = false
+	assert.Equal(t, concat(calls(1, 0), calls(3, 0), calls(4, 0)), filter(0, 5, &TraceFilterArgs{FromAddress: []common.Address{addrA}}))
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5}, traced)
+	cn.config.TraceIndexing = true
+
+	// The traces are paginated by after and count
+	after, count := uint64(1), uint64(3)
+	assert.Equal(t, all[1:4], filter(0, 5, &TraceFilterArgs{After: &after, Count: &count}))
+	assert.Equal(t, []uint64{1, 2, 3}, traced)
+	assert.Equal(t, concat(), filter(0, 0, &TraceFilterArgs{}))
+
+	_, err := cn.traceFilter(context.Background(), trace, 3, 1, &TraceFilterArgs{})
+	assert.Error(t, err)
+	// The blocks not covered by the index are traced up to a limited number of blocks
+	_, err = cn.traceFilter(context.Background(), trace, 6, 6+traceFilterMaxBlocks, &TraceFilterArgs{FromAddress: []common.Address{addrA}})
+	assert.Error(t, err)
+}
+
+// TestTraceIndexer tests if the canonical blocks are indexed in order by the chain events and the reorg events.
+func TestTraceIndexer(t *testing.T) {
+	var (
+		db        = database.NewMemoryDBManager()
+		chainFeed = new(event.Feed)
+		reorgFeed = new(event.Feed)
+		chainCh   = make(chan blockchain.ChainEvent, 16)
+		reorgCh   = make(chan blockchain.ChainReorgEvent, 16)
+		addrA     = common.Address{0x0a}
+		addrB     = common.Address{0x0b}
+		blocks    []*types.Block
+	)
+	for i := 0; i < 4; i++ {
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(int64(i))})
+		db.WriteBlock(block)
+		db.WriteCanonicalHash(block.Hash(), block.NumberU64())
+		blocks = append(blocks, block)
+	}
+	trace := func(ctx context.Context, block *types.Block) ([]*tracers.FlatCallFrame, error) {
+		return []*tracers.FlatCallFrame{newTestCallTrace("call", addrA, common.Address{byte(block.NumberU64())})}, nil
+	}
+	chainSub := chainFeed.Subscribe(chainCh)
+	defer chainSub.Unsubscribe()
+	go traceIndexer(db, trace, 2, 1, chainCh, chainSub, reorgCh, reorgFeed.Subscribe(reorgCh))
+
+	waitHead := func(head uint64) {
+		for timeout := time.Now().Add(time.Second); time.Now().Before(timeout); time.Sleep(10 * time.Millisecond) {
+			if indexed, _ := db.ReadTraceIndexHead(); indexed == head {
+				return
+			}
+		}
+		t.Fatalf("block %d is not indexed", head)
+	}
+	tracedBlocks := func(addr common.Address) []uint64 {
+		var numbers []uint64
+		db.IterateTracedBlocks(addr, 0, func(blockNum uint64, blockHash common.Hash) bool {
+			assert.Equal(t, blocks[blockNum].Hash(), blockHash)
+			numbers = append(numbers, blockNum)
+			return true
+		})
+		return numbers
+	}
+
+	chainFeed.Send(blockchain.ChainEvent{Block: blocks[3]})
+	waitHead(3)
+	assert.Equal(t, []uint64{2, 3}, tracedBlocks(addrA))
+
+	// The blocks of the new chain are indexed again
+	blocks[2] = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(2), Extra: []byte{1}})
+	db.WriteBlock(blocks[2])
+	db.WriteCanonicalHash(blocks[2].Hash(), 2)
+	assert.NoError(t, db.WriteTraceIndexHead(0))
+	reorgFeed.Send(blockchain.ChainReorgEvent{AddedBlocks: []common.Hash{blocks[2].Hash()}})
+	chainFeed.Send(blockchain.ChainEvent{Block: blocks[3]})
+	waitHead(3)
+	assert.Equal(t, []uint64{2, 3}, tracedBlocks(addrA))
+	assert.Equal(t, []uint64{2}, tracedBlocks(common.Address{2}))
+	assert.Empty(t, tracedBlocks(addrB))
+}
@@ -16,10 +16,12 @@
 
 /*
 Package tracers provides implementation of Tracer that evaluates a Javascript
-function for each VM execution step.
+function for each VM execution step, and a registry of the native tracers
+implemented in Go which can be used by name in the same way.
 
 Source Files
 
+  - native.go  : registry of the native tracers
   - tracer.go  : implementation of Tracer
   - tracers.go : provides managing functions of tracers
 */
@@ -0,0 +1,123 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/klaytn/klaytn/blockchain/vm"
+)
+
+// NativeTracer is a tracer implemented in Go. A registered native tracer can be used
+// by the debug_trace* APIs by its name in the same way as the JavaScript tracers.
+type NativeTracer interface {
+	vm.Tracer
+	// GetResult returns the result of the tracing. It is called once after the execution.
+	GetResult() (interface{}, error)
+	// Stop terminates the tracing at the first opportune moment.
+	Stop(err error)
+}
+
+// NativeTracerConstructor creates a new instance of a native tracer for a transaction.
+type NativeTracerConstructor func() NativeTracer
+
+var (
+	// nativeTracers contains the built-in native tracers and the ones registered by RegisterNativeTracer.
+	nativeTracers = map[string]NativeTracerConstructor{
+		// fastCallTracer is the go-version callTracer which is lighter and faster than the JavaScript version.
+		"fastCallTracer": newFastCallTracer,
+		"opcodeProfiler": newOpcodeProfiler,
+		// callTracer and prestateTracer replace the JavaScript tracers of the same names.
+		"callTracer":     newCallTracer,
+		"prestateTracer": newPrestateTracer,
+		// flatCallTracer reports the calls in the format of the parity-style trace APIs.
+		"flatCallTracer": newFlatCallTracer,
+	}
+	nativeTracersLock sync.RWMutex
+)
+
+// RegisterNativeTracer registers a native tracer by name. It panics if the name is already used
+// by another native tracer or a built-in JavaScript tracer.
+func RegisterNativeTracer(name string, ctor NativeTracerConstructor) {
+	nativeTracersLock.Lock()
+	defer nativeTracersLock.Unlock()
+
+	if _, ok := nativeTracers[name]; ok {
+		panic("native tracer already registered: " + name)
+	}
+	if _, ok := tracer(name); ok {
+		panic("native tracer name conflicts with a JavaScript tracer: " + name)
+	}
+	nativeTracers[name] = ctor
+}
+
+// NewNativeTracer creates a new instance of the native tracer registered by the name.
+func NewNativeTracer(name string) (NativeTracer, bool) {
+	nativeTracersLock.RLock()
+	ctor, ok := nativeTracers[name]
+	nativeTracersLock.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+	return ctor(), true
+}
+
+type fastCallTracer struct {
+	*vm.InternalTxTracer
+}
+
+func newFastCallTracer() NativeTracer {
+	return &fastCallTracer{vm.NewInternalTxTracer()}
+}
+
+func (t *fastCallTracer) GetResult() (interface{}, error) {
+	return t.InternalTxTracer.GetResult()
+}
+
+type opcodeProfiler struct {
+	*vm.OpcodeProfiler
+
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+func newOpcodeProfiler() NativeTracer {
+	return &opcodeProfiler{OpcodeProfiler: vm.NewOpcodeProfiler()}
+}
+
+func (t *opcodeProfiler) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	// The profiler does not keep partial results, so abort the execution on interruption
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		env.Cancel(vm.CancelByCtxDone)
+		return nil
+	}
+	return t.OpcodeProfiler.CaptureState(env, pc, op, gas, cost, memory, stack, contract, depth, err)
+}
+
+func (t *opcodeProfiler) GetResult() (interface{}, error) {
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		return nil, t.reason
+	}
+	return t.OpcodeProfiler.GetResult(), nil
+}
+
+func (t *opcodeProfiler) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
@@ -0,0 +1,223 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/klaytn/klaytn/accounts/abi"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+)
+
+// callFrame is a call or a creation reported by the native callTracer.
+// The fields are in the same format and order as the results of call_tracer.js.
+type callFrame struct {
+	Type     string          `json:"type"`
+	From     *common.Address `json:"from,omitempty"`
+	To       *common.Address `json:"to,omitempty"`
+	Value    *hexutil.Big    `json:"value,omitempty"`
+	Gas      *hexutil.Uint64 `json:"gas,omitempty"`
+	GasUsed  *hexutil.Uint64 `json:"gasUsed,omitempty"`
+	Input    *hexutil.Bytes  `json:"input,omitempty"`
+	Output   *hexutil.Bytes  `json:"output,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Time     string          `json:"time,omitempty"`
+	Calls    []*callFrame    `json:"calls,omitempty"`
+	Reverted *revertedFrame  `json:"reverted,omitempty"`
+
+	skipped bool // precompiled contract calls are not reported
+
+	// The self-destructed contract, its beneficiary and its balance, which are reported by
+	// flatCallTracer only
+	address       common.Address
+	refundAddress common.Address
+	balance       *big.Int
+}
+
+// revertedFrame is the contract which reverted the transaction first, and its revert reason.
+type revertedFrame struct {
+	Contract common.Address `json:"contract"`
+	Message  string         `json:"message,omitempty"`
+}
+
+// callTracer is the native version of call_tracer.js. It collects the internal calls
+// by CaptureEnter and CaptureExit instead of interpreting every opcode.
+type callTracer struct {
+	callstack        []*callFrame
+	revertedContract *common.Address
+
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+func newCallTracer() NativeTracer {
+	return &callTracer{}
+}
+
+func callErrorString(err error) string {
+	if errors.Is(err, vm.ErrExecutionReverted) {
+		return "execution reverted"
+	}
+	return err.Error()
+}
+
+func uint64Ptr(n uint64) *hexutil.Uint64 {
+	h := hexutil.Uint64(n)
+	return &h
+}
+
+func bytesPtr(b []byte) *hexutil.Bytes {
+	h := hexutil.Bytes(common.CopyBytes(b))
+	return &h
+}
+
+func (t *callTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	typ := vm.CALL
+	if create {
+		typ = vm.CREATE
+	}
+	if value == nil {
+		value = new(big.Int)
+	}
+	t.callstack = []*callFrame{{
+		Type:  typ.String(),
+		From:  &from,
+		To:    &to,
+		Value: (*hexutil.Big)(new(big.Int).Set(value)),
+		Gas:   uint64Ptr(gas),
+		Input: bytesPtr(input),
+	}}
+	return nil
+}
+
+func (t *callTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		env.Cancel(vm.CancelByCtxDone)
+		return nil
+	}
+	switch op {
+	case vm.SELFDESTRUCT:
+		top := t.callstack[len(t.callstack)-1]
+		top.Calls = append(top.Calls, &callFrame{
+			Type:          op.String(),
+			address:       contract.Address(),
+			refundAddress: common.BigToAddress(stack.Back(0)),
+			balance:       new(big.Int).Set(env.StateDB.GetBalance(contract.Address())),
+		})
+	case vm.REVERT:
+		// The first reverted contract is reported, which is the callee of a delegate call
+		// rather than the contract whose storage is used
+		if t.revertedContract == nil {
+			addr := contract.Address()
+			if top := t.callstack[len(t.callstack)-1]; top.To != nil {
+				addr = *top.To
+			}
+			t.revertedContract = &addr
+		}
+	}
+	return nil
+}
+
+func (t *callTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+func (t *callTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	if len(t.callstack) == 0 {
+		return nil
+	}
+	root := t.callstack[0]
+	root.GasUsed = uint64Ptr(gasUsed)
+	root.Time = d.String()
+	if err == nil {
+		root.Output = bytesPtr(output)
+		return nil
+	}
+	root.Error = callErrorString(err)
+	if errors.Is(err, vm.ErrExecutionReverted) {
+		root.Reverted = &revertedFrame{Contract: *root.To}
+		if t.revertedContract != nil {
+			root.Reverted.Contract = *t.revertedContract
+		}
+		if message, err := abi.UnpackRevert(output); err == nil {
+			root.Reverted.Message = message
+		}
+	}
+	return nil
+}
+
+func (t *callTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	frame := &callFrame{
+		Type:  typ.String(),
+		From:  &from,
+		To:    &to,
+		Gas:   uint64Ptr(gas),
+		Input: bytesPtr(input),
+		// The precompiled contracts are just fancy opcodes as call_tracer.js considers
+		skipped: typ != vm.CREATE && typ != vm.CREATE2 && common.IsPrecompiledContractAddress(to),
+	}
+	if value != nil {
+		frame.Value = (*hexutil.Big)(new(big.Int).Set(value))
+	}
+	t.callstack = append(t.callstack, frame)
+	return nil
+}
+
+func (t *callTracer) CaptureExit(output []byte, gasUsed uint64, err error) error {
+	if len(t.callstack) <= 1 {
+		return nil
+	}
+	frame := t.callstack[len(t.callstack)-1]
+	t.callstack = t.callstack[:len(t.callstack)-1]
+	if frame.skipped {
+		return nil
+	}
+
+	frame.GasUsed = uint64Ptr(gasUsed)
+	if err == nil {
+		frame.Output = bytesPtr(output)
+	} else {
+		frame.Error = callErrorString(err)
+		// The address of a failed creation is not reported
+		if frame.Type == vm.CREATE.String() || frame.Type == vm.CREATE2.String() {
+			frame.To = nil
+		}
+	}
+	parent := t.callstack[len(t.callstack)-1]
+	parent.Calls = append(parent.Calls, frame)
+	return nil
+}
+
+func (t *callTracer) GetResult() (interface{}, error) {
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		return nil, t.reason
+	}
+	if len(t.callstack) == 0 {
+		return nil, errors.New("no call is traced")
+	}
+	return t.callstack[0], nil
+}
+
+func (t *callTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
@@ -0,0 +1,140 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"strings"
+
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+)
+
+// FlatCallAction is the action of a parity-style trace. The fields are filled according to
+// the type of the trace, which is one of call, create and suicide.
+type FlatCallAction struct {
+	CallType       string          `json:"callType,omitempty"`
+	CreationMethod string          `json:"creationMethod,omitempty"`
+	From           *common.Address `json:"from,omitempty"`
+	To             *common.Address `json:"to,omitempty"`
+	Gas            *hexutil.Uint64 `json:"gas,omitempty"`
+	Input          *hexutil.Bytes  `json:"input,omitempty"`
+	Init           *hexutil.Bytes  `json:"init,omitempty"`
+	Value          *hexutil.Big    `json:"value,omitempty"`
+	Address        *common.Address `json:"address,omitempty"`
+	RefundAddress  *common.Address `json:"refundAddress,omitempty"`
+	Balance        *hexutil.Big    `json:"balance,omitempty"`
+}
+
+// FlatCallResult is the result of a successful call or creation of a parity-style trace.
+type FlatCallResult struct {
+	Address *common.Address `json:"address,omitempty"`
+	Code    *hexutil.Bytes  `json:"code,omitempty"`
+	GasUsed *hexutil.Uint64 `json:"gasUsed,omitempty"`
+	Output  *hexutil.Bytes  `json:"output,omitempty"`
+}
+
+// FlatCallFrame is a parity-style trace of a call, a creation or a self-destruction. The block
+// and the transaction fields are not filled by flatCallTracer, but by the callers knowing them.
+type FlatCallFrame struct {
+	Action              FlatCallAction  `json:"action"`
+	BlockHash           *common.Hash    `json:"blockHash,omitempty"`
+	BlockNumber         uint64          `json:"blockNumber"`
+	Error               string          `json:"error,omitempty"`
+	Result              *FlatCallResult `json:"result,omitempty"`
+	Subtraces           int             `json:"subtraces"`
+	TraceAddress        []int           `json:"traceAddress"`
+	TransactionHash     *common.Hash    `json:"transactionHash,omitempty"`
+	TransactionPosition uint64          `json:"transactionPosition"`
+	Type                string          `json:"type"`
+}
+
+// flatCallTracer reports the calls collected by callTracer as a list of parity-style traces,
+// ordered by the depth-first traversal of the call tree.
+type flatCallTracer struct {
+	*callTracer
+}
+
+func newFlatCallTracer() NativeTracer {
+	return &flatCallTracer{&callTracer{}}
+}
+
+func (t *flatCallTracer) GetResult() (interface{}, error) {
+	res, err := t.callTracer.GetResult()
+	if err != nil {
+		return nil, err
+	}
+	return flattenCallFrame(res.(*callFrame), []int{}, nil), nil
+}
+
+// flattenCallFrame appends the traces of the frame and its subcalls to the given traces.
+func flattenCallFrame(frame *callFrame, traceAddress []int, traces []*FlatCallFrame) []*FlatCallFrame {
+	flat := &FlatCallFrame{
+		Subtraces:    len(frame.Calls),
+		TraceAddress: traceAddress,
+	}
+	if frame.Error != "" {
+		flat.Error = frame.Error
+		if flat.Error == "execution reverted" {
+			flat.Error = "Reverted"
+		}
+	}
+
+	switch frame.Type {
+	case vm.CREATE.String(), vm.CREATE2.String():
+		flat.Type = "create"
+		flat.Action = FlatCallAction{
+			CreationMethod: strings.ToLower(frame.Type),
+			From:           frame.From,
+			Gas:            frame.Gas,
+			Init:           frame.Input,
+			Value:          frame.Value,
+		}
+		if frame.Error == "" {
+			flat.Result = &FlatCallResult{Address: frame.To, Code: frame.Output, GasUsed: frame.GasUsed}
+		}
+	case vm.OpCode(vm.SELFDESTRUCT).String():
+		flat.Type = "suicide"
+		address, refundAddress := frame.address, frame.refundAddress
+		flat.Action = FlatCallAction{
+			Address:       &address,
+			RefundAddress: &refundAddress,
+			Balance:       (*hexutil.Big)(frame.balance),
+		}
+	default:
+		flat.Type = "call"
+		flat.Action = FlatCallAction{
+			CallType: strings.ToLower(frame.Type),
+			From:     frame.From,
+			To:       frame.To,
+			Gas:      frame.Gas,
+			Input:    frame.Input,
+			Value:    frame.Value,
+		}
+		if frame.Error == "" {
+			flat.Result = &FlatCallResult{GasUsed: frame.GasUsed, Output: frame.Output}
+		}
+	}
+
+	traces = append(traces, flat)
+	for i, call := range frame.Calls {
+		// The trace addresses of the subcalls must not share the underlying array
+		subAddress := append(append(make([]int, 0, len(traceAddress)+1), traceAddress...), i)
+		traces = flattenCallFrame(call, subAddress, traces)
+	}
+	return traces
+}
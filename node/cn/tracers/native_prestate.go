@@ -0,0 +1,165 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+)
+
+// prestateAccount is the state of an account before the execution of a transaction.
+// Only the storage slots accessed by the transaction are reported.
+type prestateAccount struct {
+	Balance *hexutil.Big                `json:"balance"`
+	Nonce   uint64                      `json:"nonce"`
+	Code    hexutil.Bytes               `json:"code"`
+	Storage map[common.Hash]common.Hash `json:"storage"`
+}
+
+// prestateTracer is the native version of prestate_tracer.js. It reports the accounts and
+// the storage slots accessed by a transaction with their values before the execution.
+type prestateTracer struct {
+	db       vm.StateDB
+	prestate map[common.Address]*prestateAccount
+	create   bool
+	from     common.Address
+	to       common.Address
+	value    *big.Int
+
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+func newPrestateTracer() NativeTracer {
+	return &prestateTracer{prestate: make(map[common.Address]*prestateAccount)}
+}
+
+// lookupAccount adds the account into the prestate if it is not added yet.
+func (t *prestateTracer) lookupAccount(db vm.StateDB, addr common.Address) {
+	if _, ok := t.prestate[addr]; ok {
+		return
+	}
+	t.prestate[addr] = &prestateAccount{
+		Balance: (*hexutil.Big)(new(big.Int).Set(db.GetBalance(addr))),
+		Nonce:   db.GetNonce(addr),
+		Code:    common.CopyBytes(db.GetCode(addr)),
+		Storage: make(map[common.Hash]common.Hash),
+	}
+}
+
+// lookupStorage adds the storage slot of the account into the prestate if it is not added yet.
+func (t *prestateTracer) lookupStorage(db vm.StateDB, addr common.Address, key common.Hash) {
+	t.lookupAccount(db, addr)
+	if _, ok := t.prestate[addr].Storage[key]; ok {
+		return
+	}
+	t.prestate[addr].Storage[key] = db.GetState(addr, key)
+}
+
+func (t *prestateTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.create = create
+	t.from = from
+	t.to = to
+	t.value = new(big.Int)
+	if value != nil {
+		t.value.Set(value)
+	}
+	return nil
+}
+
+func (t *prestateTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		env.Cancel(vm.CancelByCtxDone)
+		return nil
+	}
+	db := env.StateDB
+	if t.db == nil {
+		// The balance of the recipient includes the value of the transaction, which is
+		// rolled back in GetResult
+		t.db = db
+		t.lookupAccount(db, contract.Address())
+	}
+	switch op {
+	case vm.EXTCODECOPY, vm.EXTCODESIZE, vm.EXTCODEHASH, vm.BALANCE:
+		t.lookupAccount(db, common.BigToAddress(stack.Back(0)))
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		t.lookupAccount(db, common.BigToAddress(stack.Back(1)))
+	case vm.CREATE:
+		from := contract.Address()
+		t.lookupAccount(db, crypto.CreateAddress(from, db.GetNonce(from)))
+	case vm.CREATE2:
+		from := contract.Address()
+		offset, size := stack.Back(1), stack.Back(2)
+		initCode := memory.GetCopy(offset.Int64(), size.Int64())
+		t.lookupAccount(db, crypto.CreateAddress2(from, common.BigToHash(stack.Back(3)), crypto.Keccak256(initCode)))
+	case vm.SLOAD, vm.SSTORE:
+		t.lookupStorage(db, contract.Address(), common.BigToHash(stack.Back(0)))
+	}
+	return nil
+}
+
+func (t *prestateTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+func (t *prestateTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	return nil
+}
+
+func (t *prestateTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+func (t *prestateTracer) CaptureExit(output []byte, gasUsed uint64, err error) error {
+	return nil
+}
+
+func (t *prestateTracer) GetResult() (interface{}, error) {
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		return nil, t.reason
+	}
+	if t.db == nil {
+		// No contract is executed
+		return t.prestate, nil
+	}
+	// Move the value back to the sender, and decrement the nonce of the sender
+	t.lookupAccount(t.db, t.from)
+	from := t.prestate[t.from]
+	from.Balance = (*hexutil.Big)(new(big.Int).Add(from.Balance.ToInt(), t.value))
+	if from.Nonce > 0 {
+		from.Nonce--
+	}
+	if to, ok := t.prestate[t.to]; ok {
+		to.Balance = (*hexutil.Big)(new(big.Int).Sub(to.Balance.ToInt(), t.value))
+	}
+	// The created contract did not exist before, otherwise the transaction would have been rejected
+	if t.create {
+		delete(t.prestate, t.to)
+	}
+	return t.prestate, nil
+}
+
+func (t *prestateTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
@@ -0,0 +1,351 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/math"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/fork"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// callTypeTracer records the types of the internal calls.
+type callTypeTracer struct {
+	depth int
+	types []vm.OpCode
+	err   error
+}
+
+func (t *callTypeTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+func (t *callTypeTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+func (t *callTypeTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+func (t *callTypeTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	return nil
+}
+
+func (t *callTypeTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	t.depth++
+	t.types = append(t.types, typ)
+	return nil
+}
+
+func (t *callTypeTracer) CaptureExit(output []byte, gasUsed uint64, err error) error {
+	t.depth--
+	return nil
+}
+
+func (t *callTypeTracer) GetResult() (interface{}, error) { return t.types, t.err }
+func (t *callTypeTracer) Stop(err error)                  { t.err = err }
+
+func TestNativeTracerRegistry(t *testing.T) {
+	tracer, ok := NewNativeTracer("fastCallTracer")
+	assert.True(t, ok)
+	assert.IsType(t, &fastCallTracer{}, tracer)
+
+	tracer, ok = NewNativeTracer("opcodeProfiler")
+	assert.True(t, ok)
+	assert.IsType(t, &opcodeProfiler{}, tracer)
+
+	// The native tracers are used instead of the JavaScript tracers of the same names
+	tracer, ok = NewNativeTracer("callTracer")
+	assert.True(t, ok)
+	assert.IsType(t, &callTracer{}, tracer)
+
+	tracer, ok = NewNativeTracer("prestateTracer")
+	assert.True(t, ok)
+	assert.IsType(t, &prestateTracer{}, tracer)
+
+	_, ok = NewNativeTracer("callTypeTracer")
+	assert.False(t, ok)
+
+	RegisterNativeTracer("callTypeTracer", func() NativeTracer { return &callTypeTracer{} })
+	defer func() {
+		nativeTracersLock.Lock()
+		delete(nativeTracers, "callTypeTracer")
+		nativeTracersLock.Unlock()
+	}()
+	tracer, ok = NewNativeTracer("callTypeTracer")
+	assert.True(t, ok)
+	assert.IsType(t, &callTypeTracer{}, tracer)
+
+	// duplicated names are not allowed
+	assert.Panics(t, func() { RegisterNativeTracer("callTypeTracer", func() NativeTracer { return &callTypeTracer{} }) })
+	assert.Panics(t, func() { RegisterNativeTracer("callTracer", func() NativeTracer { return &callTypeTracer{} }) })
+}
+
+// TestCaptureEnterExit checks that the internal calls are captured by CaptureEnter and CaptureExit.
+func TestCaptureEnterExit(t *testing.T) {
+	blob, err := ioutil.ReadFile(filepath.Join("testdata", "call_tracer_delegatecall.json"))
+	require.NoError(t, err)
+	test := new(callTracerTest)
+	require.NoError(t, json.Unmarshal(blob, test))
+
+	tx := new(types.Transaction)
+	require.NoError(t, rlp.DecodeBytes(common.FromHex(test.Input), tx))
+	signer := types.MakeSigner(test.Genesis.Config, new(big.Int).SetUint64(uint64(test.Context.Number)))
+	origin, _ := signer.Sender(tx)
+
+	context := vm.Context{
+		CanTransfer: blockchain.CanTransfer,
+		Transfer:    blockchain.Transfer,
+		Origin:      origin,
+		BlockNumber: new(big.Int).SetUint64(uint64(test.Context.Number)),
+		Time:        new(big.Int).SetUint64(uint64(test.Context.Time)),
+		BlockScore:  (*big.Int)(test.Context.BlockScore),
+		GasLimit:    uint64(test.Context.GasLimit),
+		GasPrice:    tx.GasPrice(),
+	}
+	statedb := tests.MakePreState(database.NewMemoryDBManager(), test.Genesis.Alloc)
+
+	tracer := &callTypeTracer{}
+	evm := vm.NewEVM(context, statedb, test.Genesis.Config, &vm.Config{Debug: true, Tracer: tracer})
+
+	fork.SetHardForkBlockNumberConfig(&params.ChainConfig{})
+	msg, err := tx.AsMessageWithAccountKeyPicker(signer, statedb, context.BlockNumber.Uint64())
+	require.NoError(t, err)
+	_, _, kerr := blockchain.NewStateTransition(evm, msg).TransitionDb()
+	require.NoError(t, kerr.ErrTxInvalid)
+
+	assert.Equal(t, 0, tracer.depth)
+	assert.Contains(t, tracer.types, vm.DELEGATECALL)
+}
+
+// runCallTracerTest executes the transaction of a callTracer test with the given tracer.
+func runCallTracerTest(t *testing.T, test *callTracerTest, tracer vm.Tracer) {
+	signer := types.MakeSigner(test.Genesis.Config, new(big.Int).SetUint64(uint64(test.Context.Number)))
+	tx := new(types.Transaction)
+	if test.Input != "" {
+		require.NoError(t, rlp.DecodeBytes(common.FromHex(test.Input), tx))
+	} else {
+		value := new(big.Int)
+		gasPrice := new(big.Int)
+		require.NoError(t, value.UnmarshalJSON([]byte(test.Transaction["value"])))
+		require.NoError(t, gasPrice.UnmarshalJSON([]byte(test.Transaction["gasPrice"])))
+		nonce, ok := math.ParseUint64(test.Transaction["nonce"])
+		require.True(t, ok)
+		gas, ok := math.ParseUint64(test.Transaction["gas"])
+		require.True(t, ok)
+
+		tx = types.NewTransaction(nonce, common.HexToAddress(test.Transaction["to"]), value, gas, gasPrice, common.FromHex(test.Transaction["input"]))
+		testKey, err := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		require.NoError(t, err)
+		require.NoError(t, tx.Sign(signer, testKey))
+	}
+	origin, _ := signer.Sender(tx)
+
+	context := vm.Context{
+		CanTransfer: blockchain.CanTransfer,
+		Transfer:    blockchain.Transfer,
+		Origin:      origin,
+		BlockNumber: new(big.Int).SetUint64(uint64(test.Context.Number)),
+		Time:        new(big.Int).SetUint64(uint64(test.Context.Time)),
+		BlockScore:  (*big.Int)(test.Context.BlockScore),
+		GasLimit:    uint64(test.Context.GasLimit),
+		GasPrice:    tx.GasPrice(),
+	}
+	statedb := tests.MakePreState(database.NewMemoryDBManager(), test.Genesis.Alloc)
+	evm := vm.NewEVM(context, statedb, test.Genesis.Config, &vm.Config{Debug: true, Tracer: tracer})
+
+	fork.SetHardForkBlockNumberConfig(&params.ChainConfig{})
+	msg, err := tx.AsMessageWithAccountKeyPicker(signer, statedb, context.BlockNumber.Uint64())
+	require.NoError(t, err)
+	_, _, kerr := blockchain.NewStateTransition(evm, msg).TransitionDb()
+	require.NoError(t, kerr.ErrTxInvalid)
+}
+
+// normalizeCallTrace clears the fields of the call trace which are reported differently by
+// the JavaScript and native tracers: the gas is measured in different ways, and the empty output
+// of a value transfer to an EOA is reported only by the native tracer.
+func normalizeCallTrace(call *callTrace) {
+	call.Gas, call.GasUsed = 0, 0
+	if len(call.Output) == 0 {
+		call.Output = nil
+	}
+	for i := range call.Calls {
+		normalizeCallTrace(&call.Calls[i])
+	}
+}
+
+// TestNativeCallTracer checks that the native callTracer reports the same calls as call_tracer.js.
+func TestNativeCallTracer(t *testing.T) {
+	files, err := ioutil.ReadDir("testdata")
+	require.NoError(t, err)
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name(), "call_tracer_") {
+			continue
+		}
+		file := file // capture range variable
+		t.Run(camel(strings.TrimSuffix(strings.TrimPrefix(file.Name(), "call_tracer_"), ".json")), func(t *testing.T) {
+			blob, err := ioutil.ReadFile(filepath.Join("testdata", file.Name()))
+			require.NoError(t, err)
+			test := new(callTracerTest)
+			require.NoError(t, json.Unmarshal(blob, test))
+
+			tracer := newCallTracer()
+			runCallTracerTest(t, test, tracer)
+			res, err := tracer.GetResult()
+			require.NoError(t, err)
+
+			enc, err := json.Marshal(res)
+			require.NoError(t, err)
+			ret := new(callTrace)
+			require.NoError(t, json.Unmarshal(enc, ret))
+			normalizeCallTrace(ret)
+			normalizeCallTrace(test.Result)
+			assert.Equal(t, test.Result, ret)
+		})
+	}
+}
+
+// TestNativePrestateTracer checks that the native prestateTracer reports the same accounts as prestate_tracer.js.
+func TestNativePrestateTracer(t *testing.T) {
+	for _, name := range []string{"create2", "delegatecall", "revert", "selfdestruct"} {
+		blob, err := ioutil.ReadFile(filepath.Join("testdata", "call_tracer_"+name+".json"))
+		require.NoError(t, err)
+		test := new(callTracerTest)
+		require.NoError(t, json.Unmarshal(blob, test))
+
+		jsTracer, err := New("prestateTracer", new(Context))
+		require.NoError(t, err)
+		runCallTracerTest(t, test, jsTracer)
+		expected, err := jsTracer.GetResult()
+		require.NoError(t, err)
+
+		tracer := newPrestateTracer()
+		runCallTracerTest(t, test, tracer)
+		res, err := tracer.GetResult()
+		require.NoError(t, err)
+		actual, err := json.Marshal(res)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, string(expected), string(actual), name)
+	}
+
+	// Nothing is reported if no contract is executed
+	tracer := newPrestateTracer()
+	assert.NoError(t, tracer.CaptureStart(common.HexToAddress("0x1"), common.HexToAddress("0x2"), false, nil, 0, big.NewInt(10)))
+	res, err := tracer.GetResult()
+	assert.NoError(t, err)
+	assert.Empty(t, res)
+}
+
+// flattenCallTrace returns the calls of the call trace ordered by the depth-first traversal with their trace addresses.
+func flattenCallTrace(call *callTrace, traceAddress []int) ([]*callTrace, [][]int) {
+	calls, addresses := []*callTrace{call}, [][]int{traceAddress}
+	for i := range call.Calls {
+		subCalls, subAddresses := flattenCallTrace(&call.Calls[i], append(append([]int{}, traceAddress...), i))
+		calls = append(calls, subCalls...)
+		addresses = append(addresses, subAddresses...)
+	}
+	return calls, addresses
+}
+
+// TestNativeFlatCallTracer checks that flatCallTracer reports the calls of call_tracer.js in the parity-style format.
+func TestNativeFlatCallTracer(t *testing.T) {
+	files, err := ioutil.ReadDir("testdata")
+	require.NoError(t, err)
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name(), "call_tracer_") {
+			continue
+		}
+		blob, err := ioutil.ReadFile(filepath.Join("testdata", file.Name()))
+		require.NoError(t, err)
+		test := new(callTracerTest)
+		require.NoError(t, json.Unmarshal(blob, test))
+
+		tracer := newFlatCallTracer()
+		runCallTracerTest(t, test, tracer)
+		res, err := tracer.GetResult()
+		require.NoError(t, err)
+		traces := res.([]*FlatCallFrame)
+
+		calls, addresses := flattenCallTrace(test.Result, []int{})
+		require.Len(t, traces, len(calls), file.Name())
+		for i, call := range calls {
+			trace := traces[i]
+			assert.Equal(t, addresses[i], trace.TraceAddress, file.Name())
+			assert.Equal(t, len(call.Calls), trace.Subtraces, file.Name())
+
+			switch call.Type {
+			case "CREATE", "CREATE2":
+				assert.Equal(t, "create", trace.Type, file.Name())
+				assert.Equal(t, strings.ToLower(call.Type), trace.Action.CreationMethod, file.Name())
+				assert.Equal(t, call.From, trace.Action.From, file.Name())
+				assert.Equal(t, call.Input, *trace.Action.Init, file.Name())
+				if call.Error == "" {
+					assert.Equal(t, call.To, trace.Result.Address, file.Name())
+				}
+			case "SELFDESTRUCT":
+				assert.Equal(t, "suicide", trace.Type, file.Name())
+				assert.NotNil(t, trace.Action.Address, file.Name())
+				assert.NotNil(t, trace.Action.RefundAddress, file.Name())
+				assert.NotNil(t, trace.Action.Balance, file.Name())
+			default:
+				assert.Equal(t, "call", trace.Type, file.Name())
+				assert.Equal(t, strings.ToLower(call.Type), trace.Action.CallType, file.Name())
+				assert.Equal(t, call.From, trace.Action.From, file.Name())
+				assert.Equal(t, call.To, trace.Action.To, file.Name())
+			}
+			if call.Error != "" {
+				assert.NotEmpty(t, trace.Error, file.Name())
+				assert.Nil(t, trace.Result, file.Name())
+			} else {
+				assert.Empty(t, trace.Error, file.Name())
+			}
+		}
+	}
+
+	// The self-destructed contract is reported with its beneficiary
+	blob, err := ioutil.ReadFile(filepath.Join("testdata", "call_tracer_selfdestruct.json"))
+	require.NoError(t, err)
+	test := new(callTracerTest)
+	require.NoError(t, json.Unmarshal(blob, test))
+	tracer := newFlatCallTracer()
+	runCallTracerTest(t, test, tracer)
+	res, err := tracer.GetResult()
+	require.NoError(t, err)
+	suicide := res.([]*FlatCallFrame)[1]
+	assert.Equal(t, test.Result.To, suicide.Action.Address)
+	assert.Equal(t, common.BytesToAddress(test.Result.Input[4:]), *suicide.Action.RefundAddress)
+	assert.Equal(t, big.NewInt(0), suicide.Action.Balance.ToInt())
+}
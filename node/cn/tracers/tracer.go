@@ -317,10 +317,19 @@ type Tracer struct {
 	reason    error  // Textual reason for the interruption
 }
 
+// Context contains some contextual infos for a transaction execution that is not
+// available from within the EVM object.
+type Context struct {
+	BlockHash common.Hash // Hash of the block the tx is contained within (zero if dangling tx or call)
+	TxIndex   int         // Index of the transaction within a block (zero if dangling tx or call)
+	TxHash    common.Hash // Hash of the transaction being traced (zero if dangling call)
+}
+
 // New instantiates a new tracer instance. code specifies a Javascript snippet,
 // which must evaluate to an expression returning an object with 'step', 'fault'
-// and 'result' functions.
-func New(code string) (*Tracer, error) {
+// and 'result' functions. The fields of txCtx are exposed to the 'result'
+// function through the ctx object, if any.
+func New(code string, txCtx *Context) (*Tracer, error) {
 	// Resolve any tracers by name and assemble the tracer object
 	if tracer, ok := tracer(code); ok {
 		code = tracer
@@ -339,6 +348,14 @@ func New(code string) (*Tracer, error) {
 		depthValue:      new(uint),
 		refundValue:     new(uint),
 	}
+	if txCtx != nil && txCtx.BlockHash != (common.Hash{}) {
+		tracer.ctx["blockHash"] = txCtx.BlockHash
+
+		if txCtx.TxHash != (common.Hash{}) {
+			tracer.ctx["txIndex"] = txCtx.TxIndex
+			tracer.ctx["txHash"] = txCtx.TxHash
+		}
+	}
 	// Set up builtins for this environment
 	tracer.vm.PushGlobalGoFunction("toHex", func(ctx *duktape.Context) int {
 		ctx.PushString(hexutil.Encode(popSlice(ctx)))
@@ -614,6 +631,17 @@ func (jst *Tracer) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, er
 	return nil
 }
 
+// CaptureEnter is called when an internal call or creation starts.
+// The JavaScript tracers observe the internal calls through their step function.
+func (jst *Tracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureExit is called when an internal call or creation finishes.
+func (jst *Tracer) CaptureExit(output []byte, gasUsed uint64, err error) error {
+	return nil
+}
+
 // GetResult calls the Javascript 'result' function and returns its value, or any accumulated error
 func (jst *Tracer) GetResult() (json.RawMessage, error) {
 	// Transform the context into a JavaScript object and inject into the state
@@ -624,6 +652,9 @@ func (jst *Tracer) GetResult() (json.RawMessage, error) {
 		case uint64:
 			jst.vm.PushUint(uint(val))
 
+		case int:
+			jst.vm.PushInt(val)
+
 		case string:
 			jst.vm.PushString(val)
 
@@ -635,6 +666,10 @@ func (jst *Tracer) GetResult() (json.RawMessage, error) {
 			ptr := jst.vm.PushFixedBuffer(20)
 			copy(makeSlice(ptr, 20), val[:])
 
+		case common.Hash:
+			ptr := jst.vm.PushFixedBuffer(32)
+			copy(makeSlice(ptr, 32), val[:])
+
 		case *big.Int:
 			pushBigInt(val, jst.vm)
 
@@ -46,6 +46,7 @@ const (
 	datadirStaticNodes     = "static-nodes.json"  // Path within the datadir to the static node list
 	datadirTrustedNodes    = "trusted-nodes.json" // Path within the datadir to the trusted node list
 	datadirNodeDatabase    = "nodes"              // Path within the datadir to store the node infos
+	datadirJWTSecret       = "jwtsecret"          // Path within the datadir to the secret of the authenticated RPC
 )
 
 // Config represents a small collection of configuration values to fine tune the
@@ -166,6 +167,35 @@ type Config struct {
 	// ephemeral nodes).
 	GRPCPort int `toml:",omitempty"`
 
+	// GRPCModules is a list of API modules to expose via the gRPC interface.
+	// If the module list is empty, all RPC API endpoints designated public will be
+	// exposed.
+	GRPCModules []string `toml:",omitempty"`
+
+	// AuthHost is the host interface on which to start the HTTP RPC server authenticated
+	// by JWTs. If this field is empty, no authenticated endpoint will be started.
+	AuthHost string `toml:",omitempty"`
+
+	// AuthPort is the TCP port number on which to start the authenticated HTTP RPC server.
+	AuthPort int `toml:",omitempty"`
+
+	// AuthVirtualHosts is the list of virtual hostnames which are allowed on incoming
+	// requests to the authenticated HTTP RPC server.
+	AuthVirtualHosts []string `toml:",omitempty"`
+
+	// AuthModules is a list of API modules to expose via the authenticated HTTP RPC
+	// server. Private modules are exposed as well.
+	AuthModules []string `toml:",omitempty"`
+
+	// AuthExclusive binds AuthModules only to the authenticated HTTP RPC server,
+	// excluding them from the HTTP, websocket and gRPC servers even if they are
+	// listed in their modules.
+	AuthExclusive bool `toml:",omitempty"`
+
+	// JWTSecret is the path to the hex-encoded 32 bytes secret signing the JWTs of the
+	// authenticated HTTP RPC server. A new secret is generated if the file does not exist.
+	JWTSecret string `toml:",omitempty"`
+
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:",omitempty"`
 }
@@ -259,6 +289,26 @@ func DefaultGRPCEndpoint() string {
 	return config.GRPCEndpoint()
 }
 
+// AuthEndpoint resolves the authenticated HTTP endpoint based on the configured
+// host interface and port parameters.
+func (c *Config) AuthEndpoint() string {
+	if c.AuthHost == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", c.AuthHost, c.AuthPort)
+}
+
+// JWTSecretPath returns the path to the secret of the authenticated HTTP endpoint.
+func (c *Config) JWTSecretPath() string {
+	if c.JWTSecret != "" {
+		return c.JWTSecret
+	}
+	if c.DataDir == "" {
+		return "" // ephemeral
+	}
+	return c.ResolvePath(datadirJWTSecret)
+}
+
 // NodeName returns the devp2p node identifier.
 func (c *Config) NodeName() string {
 	name := c.name()
@@ -43,6 +43,8 @@ const (
 	DefaultWSPort                 = 8552        // Default TCP port for the websocket RPC server
 	DefaultGRPCHost               = "localhost" // Default host interface for the gRPC server
 	DefaultGRPCPort               = 8553        // Default TCP port for the gRPC server
+	DefaultAuthHost               = "localhost" // Default host interface for the authenticated HTTP RPC server
+	DefaultAuthPort               = 8555        // Default TCP port for the authenticated HTTP RPC server
 	DefaultP2PPort                = 32323
 	DefaultP2PSubPort             = 32324
 	DefaultMaxPhysicalConnections = 10 // Default the max number of node's physical connections
@@ -59,6 +61,9 @@ var DefaultConfig = Config{
 	WSPort:           DefaultWSPort,
 	WSModules:        []string{"net", "web3"},
 	GRPCPort:         DefaultGRPCPort,
+	AuthPort:         DefaultAuthPort,
+	AuthVirtualHosts: []string{"localhost"},
+	AuthModules:      []string{"admin", "debug", "personal"},
 	P2P: p2p.Config{
 		ListenAddr:             fmt.Sprintf(":%d", DefaultP2PPort),
 		MaxPhysicalConnections: DefaultMaxPhysicalConnections,
@@ -0,0 +1,98 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package feepayer
+
+import (
+	"math/big"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+type PublicFeePayerAPI struct {
+	f *FeePayer
+}
+
+func NewPublicFeePayerAPI(f *FeePayer) *PublicFeePayerAPI {
+	return &PublicFeePayerAPI{f: f}
+}
+
+// Address returns the address of the fee payer, which should be set as the fee payer of the transactions.
+func (api *PublicFeePayerAPI) Address() common.Address {
+	return api.f.address
+}
+
+// SendTransaction signs the given fee-delegated transaction signed by the sender as the fee payer
+// and submits it to the transaction pool, if the transaction conforms to the policy of the fee payer.
+func (api *PublicFeePayerAPI) SendTransaction(encodedTx hexutil.Bytes) (common.Hash, error) {
+	tx, err := decodeTx(encodedTx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	signed, fee, err := api.f.sign(tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := api.f.txPool.AddLocal(signed); err != nil {
+		sender, _ := signed.From()
+		api.f.refund(sender, fee)
+		return common.Hash{}, err
+	}
+	logger.Debug("Paid the fee of a transaction", "hash", signed.Hash(), "fee", fee)
+	return signed.Hash(), nil
+}
+
+// SignTransaction signs the given fee-delegated transaction signed by the sender as the fee payer and
+// returns the RLP encoded transaction, if the transaction conforms to the policy of the fee payer.
+// The fee is charged to the daily budget of the sender even if the transaction is not submitted.
+func (api *PublicFeePayerAPI) SignTransaction(encodedTx hexutil.Bytes) (hexutil.Bytes, error) {
+	tx, err := decodeTx(encodedTx)
+	if err != nil {
+		return nil, err
+	}
+	signed, _, err := api.f.sign(tx)
+	if err != nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(signed)
+}
+
+// SenderUsage returns the fee paid for the sender today and the rest of the daily budget of the sender.
+func (api *PublicFeePayerAPI) SenderUsage(sender common.Address) map[string]interface{} {
+	spent := api.f.spentToday(sender)
+	usage := map[string]interface{}{
+		"spent": (*hexutil.Big)(spent),
+	}
+	if budget := api.f.config.SenderDailyBudget; budget != nil && budget.Sign() > 0 {
+		remaining := new(big.Int).Sub(budget, spent)
+		if remaining.Sign() < 0 {
+			remaining.SetUint64(0)
+		}
+		usage["remaining"] = (*hexutil.Big)(remaining)
+	}
+	return usage
+}
+
+func decodeTx(encodedTx hexutil.Bytes) (*types.Transaction, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(encodedTx, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
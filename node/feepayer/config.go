@@ -0,0 +1,37 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package feepayer
+
+import (
+	"math/big"
+
+	"github.com/klaytn/klaytn/common"
+)
+
+type FeePayerConfig struct {
+	EnabledFeePayer bool
+	KeyFile         string // File of the hex encoded private key of the fee payer
+
+	// Policy rules of the transactions to pay the fees for
+	AllowedContracts  []common.Address // Recipients allowed, or any recipient if empty
+	MaxGas            uint64           // Maximum gas limit of a transaction, 0 = no limit
+	SenderDailyBudget *big.Int         // Maximum fee paid for a sender per day (UTC) in peb, nil = no limit
+}
+
+var DefaultFeePayerConfig = &FeePayerConfig{
+	EnabledFeePayer: false,
+}
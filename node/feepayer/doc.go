@@ -0,0 +1,31 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package feepayer implements a service which pays the fees of the fee-delegated transactions
+sent by the users with the key of the fee payer configured in the node.
+
+A user signs a fee-delegated transaction whose fee payer is the address of the service, and
+sends it to the "sendTransaction" method of the "feepayer" namespace. If the transaction
+conforms to the policy of the service, which limits the recipients, the gas limit and the daily
+fee paid for a sender, the service signs it as the fee payer and submits it to the transaction pool.
+The fees paid for the senders are kept in memory, so they are reset when the node restarts.
+Source Files
+  - api.go      : includes the fee payer APIs
+  - config.go   : includes the fee payer configurations
+  - feepayer.go : implements the fee payer service and its policy
+*/
+package feepayer
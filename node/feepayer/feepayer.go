@@ -0,0 +1,232 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package feepayer
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/networks/p2p"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/node"
+	"github.com/klaytn/klaytn/params"
+)
+
+var logger = log.NewModuleLogger(log.FeePayer)
+
+var (
+	errNotFeeDelegated     = errors.New("the transaction is not fee-delegated")
+	errContractNotAllowed  = errors.New("the recipient is not allowed")
+	errGasLimitExceeded    = errors.New("the gas limit exceeds the maximum")
+	errDailyBudgetExceeded = errors.New("the daily budget of the sender is exceeded")
+)
+
+type BlockChain interface {
+	Config() *params.ChainConfig
+}
+
+type TxPool interface {
+	AddLocal(tx *types.Transaction) error
+}
+
+// FeePayer pays the fees of the fee-delegated transactions sent by the users. The transactions
+// signed by the senders are co-signed with the key of the fee payer if they conform to the policy.
+type FeePayer struct {
+	config  *FeePayerConfig
+	key     *ecdsa.PrivateKey
+	address common.Address
+	allowed map[common.Address]struct{}
+
+	blockchain BlockChain
+	txPool     TxPool
+
+	mu    sync.Mutex
+	day   int64                       // day of the budget usages, in days since the Unix epoch
+	spent map[common.Address]*big.Int // fees paid for each sender on the day
+	now   func() time.Time
+}
+
+func NewFeePayer(ctx *node.ServiceContext, cfg *FeePayerConfig) (*FeePayer, error) {
+	key, err := crypto.LoadECDSA(cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the fee payer key: %v", err)
+	}
+	return newFeePayer(cfg, key), nil
+}
+
+func newFeePayer(cfg *FeePayerConfig, key *ecdsa.PrivateKey) *FeePayer {
+	allowed := make(map[common.Address]struct{}, len(cfg.AllowedContracts))
+	for _, addr := range cfg.AllowedContracts {
+		allowed[addr] = struct{}{}
+	}
+	return &FeePayer{
+		config:  cfg,
+		key:     key,
+		address: crypto.PubkeyToAddress(key.PublicKey),
+		allowed: allowed,
+		spent:   make(map[common.Address]*big.Int),
+		now:     time.Now,
+	}
+}
+
+func (f *FeePayer) Protocols() []p2p.Protocol {
+	return []p2p.Protocol{}
+}
+
+func (f *FeePayer) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "feepayer",
+			Version:   "1.0",
+			Service:   NewPublicFeePayerAPI(f),
+			Public:    true,
+		},
+	}
+}
+
+func (f *FeePayer) Start(server p2p.Server) error {
+	logger.Info("fee payer is started", "address", f.address, "allowedContracts", len(f.allowed),
+		"maxGas", f.config.MaxGas, "senderDailyBudget", f.config.SenderDailyBudget)
+	return nil
+}
+
+func (f *FeePayer) Stop() error {
+	logger.Info("fee payer is stopped")
+	return nil
+}
+
+func (f *FeePayer) Components() []interface{} {
+	return nil
+}
+
+func (f *FeePayer) SetComponents(components []interface{}) {
+	for _, component := range components {
+		switch v := component.(type) {
+		case *blockchain.BlockChain:
+			f.blockchain = v
+		case *blockchain.TxPool:
+			f.txPool = v
+		}
+	}
+}
+
+// sign checks the transaction against the policy and signs it as the fee payer.
+// The fee is charged to the daily budget of the sender, and refund should be called
+// with the returned fee if the transaction is not submitted.
+func (f *FeePayer) sign(tx *types.Transaction) (*types.Transaction, *big.Int, error) {
+	if !tx.Type().IsFeeDelegatedTransaction() {
+		return nil, nil, errNotFeeDelegated
+	}
+	if feePayer, err := tx.FeePayer(); err != nil || feePayer != f.address {
+		return nil, nil, fmt.Errorf("the fee payer of the transaction should be %s", f.address.Hex())
+	}
+	if len(f.allowed) > 0 {
+		to := tx.To()
+		if to == nil {
+			return nil, nil, errContractNotAllowed
+		}
+		if _, ok := f.allowed[*to]; !ok {
+			return nil, nil, errContractNotAllowed
+		}
+	}
+	if f.config.MaxGas > 0 && tx.Gas() > f.config.MaxGas {
+		return nil, nil, errGasLimitExceeded
+	}
+	sender, err := tx.From()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The fee payer pays the share of the maximum fee given by the fee ratio
+	fee := tx.Fee()
+	if ratio, ok := tx.FeeRatio(); ok {
+		fee.Mul(fee, new(big.Int).SetUint64(uint64(ratio)))
+		fee.Div(fee, new(big.Int).SetUint64(uint64(types.MaxFeeRatio)))
+	}
+	if err := f.charge(sender, fee); err != nil {
+		return nil, nil, err
+	}
+
+	signed, err := types.SignTxAsFeePayer(tx, types.NewEIP155Signer(f.blockchain.Config().ChainID), f.key)
+	if err != nil {
+		f.refund(sender, fee)
+		return nil, nil, err
+	}
+	return signed, fee, nil
+}
+
+// charge adds the fee to the fees paid for the sender today,
+// or returns an error if it exceeds the daily budget of the sender.
+func (f *FeePayer) charge(sender common.Address, fee *big.Int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rollDay()
+	spent, ok := f.spent[sender]
+	if !ok {
+		spent = new(big.Int)
+	}
+	total := new(big.Int).Add(spent, fee)
+	if budget := f.config.SenderDailyBudget; budget != nil && budget.Sign() > 0 && total.Cmp(budget) > 0 {
+		return errDailyBudgetExceeded
+	}
+	f.spent[sender] = total
+	return nil
+}
+
+// refund subtracts the fee from the fees paid for the sender today.
+func (f *FeePayer) refund(sender common.Address, fee *big.Int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rollDay()
+	if spent, ok := f.spent[sender]; ok {
+		spent.Sub(spent, fee)
+		if spent.Sign() <= 0 {
+			delete(f.spent, sender)
+		}
+	}
+}
+
+// spentToday returns the fees paid for the sender today.
+func (f *FeePayer) spentToday(sender common.Address) *big.Int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rollDay()
+	if spent, ok := f.spent[sender]; ok {
+		return new(big.Int).Set(spent)
+	}
+	return new(big.Int)
+}
+
+// rollDay resets the fees paid for the senders when a day passes. It should be called with the lock held.
+func (f *FeePayer) rollDay() {
+	if day := f.now().Unix() / (24 * 60 * 60); day != f.day {
+		f.day = day
+		f.spent = make(map[common.Address]*big.Int)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package feepayer
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+type testBlockChain struct{}
+
+func (testBlockChain) Config() *params.ChainConfig { return params.TestChainConfig }
+
+type testTxPool struct {
+	txs []*types.Transaction
+	err error
+}
+
+func (p *testTxPool) AddLocal(tx *types.Transaction) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.txs = append(p.txs, tx)
+	return nil
+}
+
+func TestFeePayer(t *testing.T) {
+	var (
+		payerKey, _  = crypto.GenerateKey()
+		senderKey, _ = crypto.GenerateKey()
+		sender       = crypto.PubkeyToAddress(senderKey.PublicKey)
+		contract     = common.Address{0x01}
+		signer       = types.NewEIP155Signer(params.TestChainConfig.ChainID)
+		pool         = &testTxPool{}
+		now          = time.Date(2021, 6, 1, 23, 0, 0, 0, time.UTC)
+	)
+	f := newFeePayer(&FeePayerConfig{
+		EnabledFeePayer:   true,
+		AllowedContracts:  []common.Address{contract},
+		MaxGas:            100000,
+		SenderDailyBudget: big.NewInt(250000),
+	}, payerKey)
+	f.blockchain, f.txPool = testBlockChain{}, pool
+	f.now = func() time.Time { return now }
+	api := NewPublicFeePayerAPI(f)
+	assert.Equal(t, crypto.PubkeyToAddress(payerKey.PublicKey), api.Address())
+
+	encode := func(txType types.TxType, key *ecdsa.PrivateKey, nonce uint64, to common.Address, gas uint64) hexutil.Bytes {
+		values := map[types.TxValueKeyType]interface{}{
+			types.TxValueKeyNonce:    nonce,
+			types.TxValueKeyTo:       to,
+			types.TxValueKeyAmount:   big.NewInt(0),
+			types.TxValueKeyGasLimit: gas,
+			types.TxValueKeyGasPrice: big.NewInt(1),
+			types.TxValueKeyFrom:     crypto.PubkeyToAddress(key.PublicKey),
+		}
+		if txType.IsFeeDelegatedTransaction() {
+			values[types.TxValueKeyFeePayer] = f.address
+		}
+		tx, err := types.NewTransactionWithMap(txType, values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Sign(signer, key); err != nil {
+			t.Fatal(err)
+		}
+		data, err := rlp.EncodeToBytes(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	// The transaction is co-signed and submitted
+	hash, err := api.SendTransaction(encode(types.TxTypeFeeDelegatedValueTransfer, senderKey, 0, contract, 100000))
+	assert.NoError(t, err)
+	assert.Len(t, pool.txs, 1)
+	assert.Equal(t, hash, pool.txs[0].Hash())
+	pubkeys, err := types.SenderFeePayerPubkey(signer, pool.txs[0])
+	assert.NoError(t, err)
+	assert.Equal(t, f.address, crypto.PubkeyToAddress(*pubkeys[0]))
+	assert.Equal(t, map[string]interface{}{
+		"spent":     (*hexutil.Big)(big.NewInt(100000)),
+		"remaining": (*hexutil.Big)(big.NewInt(150000)),
+	}, api.SenderUsage(sender))
+
+	// The transactions violating the policy are rejected
+	_, err = api.SendTransaction(encode(types.TxTypeValueTransfer, senderKey, 1, contract, 100000))
+	assert.Equal(t, errNotFeeDelegated, err)
+	_, err = api.SendTransaction(encode(types.TxTypeFeeDelegatedValueTransfer, senderKey, 1, common.Address{0x02}, 100000))
+	assert.Equal(t, errContractNotAllowed, err)
+	_, err = api.SendTransaction(encode(types.TxTypeFeeDelegatedValueTransfer, senderKey, 1, contract, 100001))
+	assert.Equal(t, errGasLimitExceeded, err)
+
+	// The fee is refunded if the transaction is not submitted
+	pool.err = errors.New("rejected")
+	_, err = api.SendTransaction(encode(types.TxTypeFeeDelegatedValueTransfer, senderKey, 1, contract, 100000))
+	assert.Equal(t, pool.err, err)
+	assert.Equal(t, big.NewInt(100000), f.spentToday(sender))
+	pool.err = nil
+
+	// The daily budget of the sender is enforced, and it is reset on the next day
+	_, err = api.SignTransaction(encode(types.TxTypeFeeDelegatedValueTransfer, senderKey, 1, contract, 100000))
+	assert.NoError(t, err)
+	_, err = api.SendTransaction(encode(types.TxTypeFeeDelegatedValueTransfer, senderKey, 2, contract, 100000))
+	assert.Equal(t, errDailyBudgetExceeded, err)
+
+	otherKey, _ := crypto.GenerateKey()
+	_, err = api.SendTransaction(encode(types.TxTypeFeeDelegatedValueTransfer, otherKey, 0, contract, 100000))
+	assert.NoError(t, err)
+
+	now = now.Add(time.Hour)
+	_, err = api.SendTransaction(encode(types.TxTypeFeeDelegatedValueTransfer, senderKey, 2, contract, 100000))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(100000), f.spentToday(sender))
+}
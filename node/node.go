@@ -21,8 +21,11 @@
 package node
 
 import (
+	"crypto/ecdsa"
+	"crypto/rand"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"os"
 	"path/filepath"
@@ -32,6 +35,8 @@ import (
 
 	"github.com/klaytn/klaytn/accounts"
 	"github.com/klaytn/klaytn/api/debug"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
 	"github.com/klaytn/klaytn/event"
 	"github.com/klaytn/klaytn/log"
 	"github.com/klaytn/klaytn/networks/grpc"
@@ -51,6 +56,7 @@ type Node struct {
 
 	ephemeralKeystore string
 	instanceDirLock   flock.Releaser
+	uncleanShutdown   bool // whether the previous instance on the datadir was not shut down cleanly
 
 	serverConfig p2p.Config
 	server       p2p.Server
@@ -81,6 +87,10 @@ type Node struct {
 	grpcListener *grpc.Listener // gRPC listener socket to server API requests
 	grpcHandler  *rpc.Server    // gRPC request handler to process the API requests
 
+	authEndpoint string       // Authenticated HTTP endpoint (interface + port) to listen at (empty = disabled)
+	authListener net.Listener // Authenticated HTTP RPC listener socket to server API requests
+	authHandler  *rpc.Server  // Authenticated HTTP RPC request handler to process the API requests
+
 	stop chan struct{} // Channel to wait for termination notifications
 	lock sync.RWMutex
 
@@ -134,6 +144,7 @@ func New(conf *Config) (*Node, error) {
 		httpEndpoint:      conf.HTTPEndpoint(),
 		wsEndpoint:        conf.WSEndpoint(),
 		grpcEndpoint:      conf.GRPCEndpoint(),
+		authEndpoint:      conf.AuthEndpoint(),
 		eventmux:          new(event.TypeMux),
 		logger:            conf.Logger,
 	}, nil
@@ -301,11 +312,21 @@ func (n *Node) openDataDir() error {
 		return err
 	}
 
-	release, _, err := flock.New(filepath.Join(instdir, "LOCK"))
+	lockPath := filepath.Join(instdir, "LOCK")
+	release, existed, err := flock.New(lockPath)
 	if err != nil {
 		return convertFileLockError(err)
 	}
 	n.instanceDirLock = release
+
+	// The lock file is removed on a clean shutdown, so the existing one is a stale lock left by the
+	// previous instance which crashed or was killed. The lock itself has been released by the OS,
+	// so it is taken over, but the operator is notified of the unclean shutdown.
+	n.uncleanShutdown = existed
+	if existed {
+		n.logger.Warn("Recovered a stale datadir lock; the previous instance was not shut down cleanly, "+
+			"so the last written data may be rolled back", "lock", lockPath)
+	}
 	return nil
 }
 
@@ -357,6 +378,14 @@ func (n *Node) startRPC(services map[reflect.Type]Service) error {
 		n.stopInProc()
 		return err
 	}
+	if err := n.startAuthHTTP(n.authEndpoint, apis, n.config.AuthModules, n.config.AuthVirtualHosts, n.config.HTTPTimeouts); err != nil {
+		n.stopgRPC()
+		n.stopWS()
+		n.stopHTTP()
+		n.stopIPC()
+		n.stopInProc()
+		return err
+	}
 	// All API endpoints started successfully
 	n.rpcAPIs = apis
 
@@ -420,14 +449,9 @@ func (n *Node) startgRPC(apis []rpc.API) error {
 		return nil
 	}
 
-	handler := rpc.NewServer()
-	for _, api := range apis {
-		if api.Public {
-			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
-				return err
-			}
-			n.logger.Debug("gRPC registered", "namespace", api.Namespace)
-		}
+	handler, err := grpc.NewRPCServer(n.unauthenticatedAPIs(apis), n.config.GRPCModules)
+	if err != nil {
+		return err
 	}
 
 	listener := &grpc.Listener{Addr: n.grpcEndpoint}
@@ -446,7 +470,7 @@ func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts)
+	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, n.unauthenticatedAPIs(apis), modules, cors, vhosts, timeouts)
 	if err != nil {
 		return err
 	}
@@ -465,7 +489,7 @@ func (n *Node) startFastHTTP(endpoint string, apis []rpc.API, modules []string,
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := rpc.StartFastHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts)
+	listener, handler, err := rpc.StartFastHTTPEndpoint(endpoint, n.unauthenticatedAPIs(apis), modules, cors, vhosts, timeouts)
 	if err != nil {
 		return err
 	}
@@ -498,7 +522,7 @@ func (n *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrig
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll)
+	listener, handler, err := rpc.StartWSEndpoint(endpoint, n.unauthenticatedAPIs(apis), modules, wsOrigins, exposeAll)
 	if err != nil {
 		return err
 	}
@@ -517,7 +541,7 @@ func (n *Node) startFastWS(endpoint string, apis []rpc.API, modules []string, ws
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := rpc.StartFastWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll)
+	listener, handler, err := rpc.StartFastWSEndpoint(endpoint, n.unauthenticatedAPIs(apis), modules, wsOrigins, exposeAll)
 	if err != nil {
 		return err
 	}
@@ -544,6 +568,92 @@ func (n *Node) stopWS() {
 	}
 }
 
+// startAuthHTTP initializes and starts the HTTP RPC endpoint authenticated by JWTs.
+func (n *Node) startAuthHTTP(endpoint string, apis []rpc.API, modules []string, vhosts []string, timeouts rpc.HTTPTimeouts) error {
+	// Short circuit if the authenticated endpoint isn't being exposed
+	if endpoint == "" {
+		return nil
+	}
+	secret, err := obtainJWTSecret(n.config.JWTSecretPath())
+	if err != nil {
+		return err
+	}
+	listener, handler, err := rpc.StartAuthHTTPEndpoint(endpoint, apis, modules, vhosts, timeouts, secret)
+	if err != nil {
+		return err
+	}
+	n.logger.Info("Authenticated HTTP endpoint opened", "url", fmt.Sprintf("http://%s", endpoint), "modules", strings.Join(modules, ","), "vhosts", strings.Join(vhosts, ","))
+	// All listeners booted successfully
+	n.authEndpoint = endpoint
+	n.authListener = listener
+	n.authHandler = handler
+
+	return nil
+}
+
+// stopAuthHTTP terminates the authenticated HTTP RPC endpoint.
+func (n *Node) stopAuthHTTP() {
+	if n.authListener != nil {
+		n.authListener.Close()
+		n.authListener = nil
+
+		n.logger.Info("Authenticated HTTP endpoint closed", "url", fmt.Sprintf("http://%s", n.authEndpoint))
+	}
+	if n.authHandler != nil {
+		n.authHandler.Stop()
+		n.authHandler = nil
+	}
+}
+
+// unauthenticatedAPIs returns the APIs which can be exposed by the endpoints without
+// authentication, excluding the modules bound only to the authenticated endpoint.
+func (n *Node) unauthenticatedAPIs(apis []rpc.API) []rpc.API {
+	if !n.config.AuthExclusive || n.authEndpoint == "" {
+		return apis
+	}
+	exclusive := make(map[string]bool)
+	for _, module := range n.config.AuthModules {
+		exclusive[module] = true
+	}
+	filtered := make([]rpc.API, 0, len(apis))
+	for _, api := range apis {
+		if !exclusive[api.Namespace] {
+			filtered = append(filtered, api)
+		}
+	}
+	return filtered
+}
+
+// obtainJWTSecret loads the hex-encoded secret of the authenticated endpoint from the file,
+// or generates a new one into the file if it does not exist.
+func obtainJWTSecret(path string) ([]byte, error) {
+	if path == "" {
+		return nil, errors.New("a JWT secret file is required for the authenticated endpoint of an ephemeral node")
+	}
+	if data, err := ioutil.ReadFile(path); err == nil {
+		secret := common.FromHex(strings.TrimSpace(string(data)))
+		if len(secret) != rpc.JWTSecretLength {
+			return nil, fmt.Errorf("invalid JWT secret in %s: want %d bytes hex, got %d bytes", path, rpc.JWTSecretLength, len(secret))
+		}
+		logger.Info("Loaded JWT secret file", "path", path)
+		return secret, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	secret := make([]byte, rpc.JWTSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(path, []byte(hexutil.Encode(secret)), 0600); err != nil {
+		return nil, err
+	}
+	logger.Info("Generated JWT secret", "path", path)
+	return secret, nil
+}
+
 func (n *Node) stopgRPC() {
 	if n.grpcListener != nil {
 		n.grpcListener.Stop()
@@ -574,6 +684,7 @@ func (n *Node) Stop() error {
 	n.stopHTTP()
 	n.stopIPC()
 	n.stopgRPC()
+	n.stopAuthHTTP()
 	n.rpcAPIs = nil
 	failure := &StopError{
 		Services: make(map[reflect.Type]error),
@@ -597,6 +708,11 @@ func (n *Node) Stop() error {
 
 	// Release instance directory lock.
 	if n.instanceDirLock != nil {
+		// The lock file is removed before the lock is released, so another instance cannot lock it
+		// in the meantime. An existing lock file on startup means an unclean shutdown.
+		if err := os.Remove(filepath.Join(n.config.instanceDir(), "LOCK")); err != nil {
+			n.logger.Debug("Can't remove datadir lock file", "err", err)
+		}
 		if err := n.instanceDirLock.Release(); err != nil {
 			n.logger.Error("Can't release datadir lock", "err", err)
 		}
@@ -708,6 +824,11 @@ func (n *Node) InstanceDir() string {
 	return n.config.instanceDir()
 }
 
+// NodeKey retrieves the private key of the node used by the protocol stack.
+func (n *Node) NodeKey() *ecdsa.PrivateKey {
+	return n.config.NodeKey()
+}
+
 // AccountManager retrieves the account manager used by the protocol stack.
 func (n *Node) AccountManager() *accounts.Manager {
 	return n.accman
@@ -728,6 +849,11 @@ func (n *Node) WSEndpoint() string {
 	return n.wsEndpoint
 }
 
+// AuthEndpoint retrieves the current authenticated HTTP endpoint used by the protocol stack.
+func (n *Node) AuthEndpoint() string {
+	return n.authEndpoint
+}
+
 // EventMux retrieves the event multiplexer used by all the network services in
 // the current protocol stack.
 func (n *Node) EventMux() *event.TypeMux {
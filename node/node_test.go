@@ -23,11 +23,14 @@ package node
 import (
 	"errors"
 	"io/ioutil"
+	"net/http"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/networks/p2p"
 	"github.com/klaytn/klaytn/networks/rpc"
@@ -107,6 +110,50 @@ func TestNodeUsedDataDir(t *testing.T) {
 	}
 }
 
+// Tests that a stale lock file left by an unclean shutdown is taken over and reported.
+func TestNodeStaleDataDirLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary data directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The lock file is removed on a clean shutdown
+	stack, err := New(&Config{DataDir: dir})
+	if err != nil {
+		t.Fatalf("failed to create protocol stack: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start protocol stack: %v", err)
+	}
+	if stack.uncleanShutdown {
+		t.Fatalf("unclean shutdown reported on the first start")
+	}
+	lockPath := filepath.Join(stack.config.instanceDir(), "LOCK")
+	if err := stack.Stop(); err != nil {
+		t.Fatalf("failed to stop protocol stack: %v", err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Fatalf("lock file not removed on a clean shutdown: %v", err)
+	}
+
+	// The lock file left by a crashed instance is taken over
+	if err := ioutil.WriteFile(lockPath, nil, 0644); err != nil {
+		t.Fatalf("failed to create stale lock file: %v", err)
+	}
+	stack, err = New(&Config{DataDir: dir})
+	if err != nil {
+		t.Fatalf("failed to create protocol stack: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start protocol stack with a stale lock: %v", err)
+	}
+	defer stack.Stop()
+	if !stack.uncleanShutdown {
+		t.Fatalf("unclean shutdown not reported")
+	}
+}
+
 // Tests whether services can be registered and duplicates caught.
 func TestServiceRegistry(t *testing.T) {
 	stack, err := New(testNodeConfig())
@@ -577,3 +624,81 @@ func TestAPIGather(t *testing.T) {
 		}
 	}
 }
+
+type jwtRoundTripper struct {
+	secret []byte
+}
+
+func (rt *jwtRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.Header.Set("Authorization", "Bearer "+rpc.NewJWTToken(rt.secret, time.Now()))
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// Tests that the authenticated endpoint serves the requests with a valid JWT, and that
+// its modules are removed from the unauthenticated endpoints if it is exclusive.
+func TestAuthEndpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary data directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := testNodeConfig()
+	config.DataDir = dir
+	config.HTTPHost = "127.0.0.1"
+	config.HTTPModules = []string{"admin", "web3"}
+	config.AuthHost = "127.0.0.1"
+	config.AuthModules = []string{"admin"}
+	config.AuthVirtualHosts = []string{"*"}
+	config.AuthExclusive = true
+	stack, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create protocol stack: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start protocol stack: %v", err)
+	}
+	defer stack.Stop()
+
+	// The secret is generated into the data directory
+	data, err := ioutil.ReadFile(filepath.Join(dir, "test node", datadirJWTSecret))
+	if err != nil {
+		t.Fatalf("failed to read the JWT secret: %v", err)
+	}
+	secret := common.FromHex(string(data))
+	if len(secret) != rpc.JWTSecretLength {
+		t.Fatalf("JWT secret length mismatch: have %d, want %d", len(secret), rpc.JWTSecretLength)
+	}
+
+	modules := func(endpoint string, client *http.Client) (map[string]string, error) {
+		c, err := rpc.DialHTTPWithClient(endpoint, client)
+		if err != nil {
+			t.Fatalf("failed to dial %s: %v", endpoint, err)
+		}
+		defer c.Close()
+		return c.SupportedModules()
+	}
+
+	authURL := "http://" + stack.authListener.Addr().String()
+	have, err := modules(authURL, &http.Client{Transport: &jwtRoundTripper{secret}})
+	if err != nil {
+		t.Fatalf("failed to call the authenticated endpoint: %v", err)
+	}
+	if _, ok := have["admin"]; !ok {
+		t.Errorf("admin is not served by the authenticated endpoint: %v", have)
+	}
+	if _, err := modules(authURL, http.DefaultClient); err == nil {
+		t.Errorf("the authenticated endpoint served a request without a token")
+	}
+
+	have, err = modules("http://"+stack.httpListener.Addr().String(), http.DefaultClient)
+	if err != nil {
+		t.Fatalf("failed to call the HTTP endpoint: %v", err)
+	}
+	if _, ok := have["admin"]; ok {
+		t.Errorf("the exclusive admin is served by the HTTP endpoint: %v", have)
+	}
+	if _, ok := have["web3"]; !ok {
+		t.Errorf("web3 is not served by the HTTP endpoint: %v", have)
+	}
+}
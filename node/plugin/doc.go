@@ -0,0 +1,35 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package plugin implements an in-process extension framework which attaches custom services,
+such as bespoke indexers or monitors, to the node lifecycle without modifying the node.
+
+An external package registers its plugin in an init function and is linked into the node binary
+with a blank import:
+
+	func init() {
+		plugin.Register("myindexer", func() (plugin.Plugin, error) { return &myIndexer{}, nil })
+	}
+
+The registered plugins are started after the core services of the node. A plugin accesses
+the blockchain, the transaction pool, the chain database and their event feeds through the Host
+given to its Start method, and it can expose its own RPC APIs by registering them with the Host.
+Source Files
+  - plugin.go  : defines the Plugin and Host interfaces and the plugin registry
+  - service.go : implements the node service which runs the enabled plugins
+*/
+package plugin
@@ -0,0 +1,124 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package plugin
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+)
+
+// Plugin is a custom service attached to the node lifecycle.
+type Plugin interface {
+	// Start is called after the core services of the node are started. The RPC APIs of the plugin
+	// should be registered with the host here. An error aborts the start of the node.
+	Start(host Host) error
+
+	// Stop is called when the node is stopped. It should terminate all goroutines of the plugin.
+	Stop() error
+}
+
+// Constructor creates a plugin.
+type Constructor func() (Plugin, error)
+
+// BlockChain is the part of the blockchain accessible to the plugins.
+type BlockChain interface {
+	Config() *params.ChainConfig
+	CurrentBlock() *types.Block
+	GetBlockByHash(hash common.Hash) *types.Block
+	GetBlockByNumber(number uint64) *types.Block
+	GetReceiptsByBlockHash(blockHash common.Hash) types.Receipts
+	StateAt(root common.Hash) (*state.StateDB, error)
+
+	SubscribeChainEvent(ch chan<- blockchain.ChainEvent) event.Subscription
+	SubscribeChainHeadEvent(ch chan<- blockchain.ChainHeadEvent) event.Subscription
+	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
+	SubscribeRemovedLogsEvent(ch chan<- blockchain.RemovedLogsEvent) event.Subscription
+	SubscribeChainReorgEvent(ch chan<- blockchain.ChainReorgEvent) event.Subscription
+}
+
+// TxPool is the part of the transaction pool accessible to the plugins.
+type TxPool interface {
+	AddLocal(tx *types.Transaction) error
+	Get(hash common.Hash) *types.Transaction
+	Pending() (map[common.Address]types.Transactions, error)
+	Stats() (int, int)
+
+	SubscribeNewTxsEvent(ch chan<- blockchain.NewTxsEvent) event.Subscription
+}
+
+// Host provides a plugin with the access to the node.
+// BlockChain, TxPool and ChainDB return nil if the node does not run the corresponding component.
+type Host interface {
+	BlockChain() BlockChain
+	TxPool() TxPool
+	ChainDB() database.DBManager
+
+	// RegisterAPIs exposes the given RPC APIs of the plugin. It should be called in Plugin.Start.
+	RegisterAPIs(apis ...rpc.API)
+
+	// DataDir returns the directory dedicated to the plugin in the data directory of the node.
+	DataDir() string
+
+	// Logger returns a logger which annotates the logs with the name of the plugin.
+	Logger() log.Logger
+}
+
+var (
+	registryMu   sync.RWMutex
+	constructors = make(map[string]Constructor)
+	names        []string // registration order
+)
+
+// Register makes a plugin available by the given name. It is meant to be called in an init
+// function of the package implementing the plugin, and it panics if the name is already registered.
+func Register(name string, constructor Constructor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if name == "" || constructor == nil {
+		panic("plugin: invalid registration")
+	}
+	if _, exist := constructors[name]; exist {
+		panic(fmt.Sprintf("plugin: %q is already registered", name))
+	}
+	constructors[name] = constructor
+	names = append(names, name)
+}
+
+// Names returns the names of the registered plugins in the order of registration.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]string(nil), names...)
+}
+
+func lookup(name string) (Constructor, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	c, ok := constructors[name]
+	return c, ok
+}
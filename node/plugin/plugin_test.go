@@ -0,0 +1,109 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAPI struct{}
+
+func (testAPI) Hello() string { return "hello" }
+
+type testPlugin struct {
+	name     string
+	startErr error
+	events   *[]string
+	host     Host
+}
+
+func (p *testPlugin) Start(host Host) error {
+	if p.startErr != nil {
+		return p.startErr
+	}
+	p.host = host
+	host.RegisterAPIs(rpc.API{Namespace: p.name, Version: "1.0", Service: testAPI{}, Public: true})
+	*p.events = append(*p.events, "start "+p.name)
+	return nil
+}
+
+func (p *testPlugin) Stop() error {
+	*p.events = append(*p.events, "stop "+p.name)
+	return nil
+}
+
+func registerTestPlugin(name string, startErr error, events *[]string) *testPlugin {
+	p := &testPlugin{name: name, startErr: startErr, events: events}
+	Register(name, func() (Plugin, error) { return p, nil })
+	return p
+}
+
+func TestRegister(t *testing.T) {
+	var events []string
+	registerTestPlugin("testregister", nil, &events)
+	assert.Contains(t, Names(), "testregister")
+
+	assert.Panics(t, func() { registerTestPlugin("testregister", nil, &events) })
+	assert.Panics(t, func() { Register("", func() (Plugin, error) { return nil, nil }) })
+
+	_, err := NewService(nil, []string{"testunknown"})
+	assert.Error(t, err)
+}
+
+func TestService(t *testing.T) {
+	var events []string
+	a := registerTestPlugin("testservicea", nil, &events)
+	b := registerTestPlugin("testserviceb", nil, &events)
+
+	s, err := NewService(nil, []string{"testservicea", "testserviceb"})
+	require.NoError(t, err)
+
+	chainDB := database.NewMemoryDBManager()
+	s.SetComponents([]interface{}{chainDB})
+	require.NoError(t, s.Start(nil))
+
+	assert.Equal(t, chainDB, a.host.ChainDB())
+	assert.Nil(t, a.host.BlockChain())
+	assert.Nil(t, b.host.TxPool())
+
+	apis := s.APIs()
+	require.Equal(t, 2, len(apis))
+	assert.Equal(t, "testservicea", apis[0].Namespace)
+	assert.Equal(t, "testserviceb", apis[1].Namespace)
+
+	require.NoError(t, s.Stop())
+	assert.Equal(t, []string{"start testservicea", "start testserviceb", "stop testserviceb", "stop testservicea"}, events)
+}
+
+func TestService_StartFailure(t *testing.T) {
+	var events []string
+	registerTestPlugin("testfailurea", nil, &events)
+	registerTestPlugin("testfailureb", errors.New("test"), &events)
+
+	s, err := NewService(nil, []string{"testfailurea", "testfailureb"})
+	require.NoError(t, err)
+	assert.Error(t, s.Start(nil))
+
+	// the started plugins are stopped
+	assert.Equal(t, []string{"start testfailurea", "stop testfailurea"}, events)
+}
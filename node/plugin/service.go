@@ -0,0 +1,149 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package plugin
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/networks/p2p"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/node"
+	"github.com/klaytn/klaytn/storage/database"
+)
+
+var logger = log.NewModuleLogger(log.Plugin)
+
+// Service is a node service running the enabled plugins. The plugins are started in the given
+// order and stopped in the reverse order.
+type Service struct {
+	hosts   []*host
+	plugins []Plugin
+	started int
+}
+
+// NewService creates the plugins of the given names. If no name is given, all the registered plugins are created.
+func NewService(ctx *node.ServiceContext, enabled []string) (*Service, error) {
+	if len(enabled) == 0 {
+		enabled = Names()
+	}
+
+	s := &Service{}
+	for _, name := range enabled {
+		constructor, ok := lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("plugin %q is not registered", name)
+		}
+		p, err := constructor()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create plugin %q: %v", name, err)
+		}
+		s.hosts = append(s.hosts, newHost(ctx, name))
+		s.plugins = append(s.plugins, p)
+	}
+	return s, nil
+}
+
+func (s *Service) Protocols() []p2p.Protocol {
+	return []p2p.Protocol{}
+}
+
+// APIs returns the RPC APIs registered by the plugins. It is called after the plugins are started.
+func (s *Service) APIs() []rpc.API {
+	var apis []rpc.API
+	for _, h := range s.hosts {
+		apis = append(apis, h.apis...)
+	}
+	return apis
+}
+
+func (s *Service) Start(server p2p.Server) error {
+	for i, p := range s.plugins {
+		if err := p.Start(s.hosts[i]); err != nil {
+			s.Stop()
+			return fmt.Errorf("failed to start plugin %q: %v", s.hosts[i].name, err)
+		}
+		s.started++
+		logger.Info("plugin is started", "name", s.hosts[i].name, "numAPIs", len(s.hosts[i].apis))
+	}
+	return nil
+}
+
+func (s *Service) Stop() error {
+	for ; s.started > 0; s.started-- {
+		i := s.started - 1
+		if err := s.plugins[i].Stop(); err != nil {
+			logger.Error("failed to stop plugin", "name", s.hosts[i].name, "err", err)
+			continue
+		}
+		logger.Info("plugin is stopped", "name", s.hosts[i].name)
+	}
+	return nil
+}
+
+func (s *Service) Components() []interface{} {
+	return nil
+}
+
+func (s *Service) SetComponents(components []interface{}) {
+	for _, h := range s.hosts {
+		h.setComponents(components)
+	}
+}
+
+// host implements Host for a plugin.
+type host struct {
+	name   string
+	ctx    *node.ServiceContext
+	logger log.Logger
+
+	blockchain BlockChain
+	txPool     TxPool
+	chainDB    database.DBManager
+	apis       []rpc.API
+}
+
+func newHost(ctx *node.ServiceContext, name string) *host {
+	return &host{name: name, ctx: ctx, logger: logger.NewWith("plugin", name)}
+}
+
+func (h *host) setComponents(components []interface{}) {
+	for _, component := range components {
+		switch v := component.(type) {
+		case BlockChain:
+			h.blockchain = v
+		case TxPool:
+			h.txPool = v
+		case database.DBManager:
+			h.chainDB = v
+		}
+	}
+}
+
+func (h *host) BlockChain() BlockChain      { return h.blockchain }
+func (h *host) TxPool() TxPool              { return h.txPool }
+func (h *host) ChainDB() database.DBManager { return h.chainDB }
+func (h *host) Logger() log.Logger          { return h.logger }
+
+func (h *host) RegisterAPIs(apis ...rpc.API) {
+	h.apis = append(h.apis, apis...)
+}
+
+func (h *host) DataDir() string {
+	return h.ctx.ResolvePath(filepath.Join("plugins", h.name))
+}
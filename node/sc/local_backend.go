@@ -322,6 +322,10 @@ func (fb *filterLocalBackend) SubscribeRemovedLogsEvent(ch chan<- blockchain.Rem
 	return fb.subbridge.blockchain.SubscribeRemovedLogsEvent(ch)
 }
 
+func (fb *filterLocalBackend) SubscribeChainReorgEvent(ch chan<- blockchain.ChainReorgEvent) event.Subscription {
+	return fb.subbridge.blockchain.SubscribeChainReorgEvent(ch)
+}
+
 func (fb *filterLocalBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	return fb.subbridge.blockchain.SubscribeLogsEvent(ch)
 }
@@ -332,6 +336,10 @@ func (fb *filterLocalBackend) BloomStatus() (uint64, uint64) {
 	return 4096, 0
 }
 
+func (fb *filterLocalBackend) LogIndexStatus() (uint64, uint64, bool) {
+	return 0, 0, false
+}
+
 func (fb *filterLocalBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
 	// TODO-Klaytn this method should implmentation to support indexed tag in solidity
 	//for i := 0; i < bloomFilterThreads; i++ {
@@ -159,4 +159,13 @@ const (
 	ValidateSenderBaseComputationCost   = 10000
 	Blake2bBaseComputationCost          = 10000
 	Blake2bScaleComputationCost         = 10
+	Bls12381G1AddComputationCost        = 10000
+	Bls12381G1MulComputationCost        = 200000
+	Bls12381G2AddComputationCost        = 20000
+	Bls12381G2MulComputationCost        = 500000
+	Bls12381PairingBaseComputationCost  = 1000000
+	Bls12381PerPairComputationCost      = 600000
+	Bls12381MapG1ComputationCost        = 150000
+	Bls12381MapG2ComputationCost        = 800000
+	KZGPointEvaluationComputationCost   = 1500000
 )
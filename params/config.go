@@ -162,6 +162,8 @@ type ChainConfig struct {
 	ChainID *big.Int `json:"chainId"` // chainId identifies the current chain and is used for replay protection
 
 	IstanbulCompatibleBlock *big.Int `json:"istanbulCompatibleBlock,omitempty"` // IstanbulCompatibleBlock switch block (nil = no fork, 0 = already on istanbul)
+	Bls12381CompatibleBlock *big.Int `json:"bls12381CompatibleBlock,omitempty"` // Bls12381CompatibleBlock switch block (nil = no fork, 0 = already activated)
+	KZGCompatibleBlock      *big.Int `json:"kzgCompatibleBlock,omitempty"`      // KZGCompatibleBlock switch block (nil = no fork, 0 = already activated)
 
 	// Various consensus engines
 	Gxhash   *GxhashConfig   `json:"gxhash,omitempty"` // (deprecated) not supported engine
@@ -171,6 +173,103 @@ type ChainConfig struct {
 	UnitPrice     uint64            `json:"unitPrice"`
 	DeriveShaImpl int               `json:"deriveShaImpl"`
 	Governance    *GovernanceConfig `json:"governance"`
+
+	// Precompiles are the chain-specific precompiled contracts, mainly used by service chains.
+	Precompiles []*PrecompileConfig `json:"precompiles,omitempty"`
+
+	// DisabledEVMFeatures are the opcodes and the precompiled contracts disabled on the chain, mainly used by service chains.
+	DisabledEVMFeatures []*DisabledEVMFeature `json:"disabledEVMFeatures,omitempty"`
+
+	// TxData changes the size limit and the gas prices of the transaction data, mainly used by service chains.
+	// Since it is not a hard fork, it should be set in the genesis and never be changed; CheckCompatible
+	// rejects a change after the genesis block.
+	TxData *TxDataConfig `json:"txData,omitempty"`
+}
+
+// TxDataConfig is the config of the transaction size limit and the transaction data gas prices.
+// The zero values are replaced with the defaults of the params package.
+type TxDataConfig struct {
+	MaxTxSize  uint64 `json:"maxTxSize,omitempty"`  // Maximum size of a transaction in the tx pool and the blocks
+	Gas        uint64 `json:"gas,omitempty"`        // Gas per byte of the data after the istanbul hard fork and of the klaytn tx types
+	ZeroGas    uint64 `json:"zeroGas,omitempty"`    // Gas per zero byte of the data of the legacy txs before the istanbul hard fork
+	NonZeroGas uint64 `json:"nonZeroGas,omitempty"` // Gas per non-zero byte of the data of the legacy txs before the istanbul hard fork
+}
+
+// TxSizeLimit returns the maximum size of a transaction. It returns the default if c is nil.
+func (c *TxDataConfig) TxSizeLimit() uint64 {
+	if c == nil || c.MaxTxSize == 0 {
+		return MaxTxDataSize
+	}
+	return c.MaxTxSize
+}
+
+// TxDataGas returns the gas per byte of the transaction data. It returns the default if c is nil.
+func (c *TxDataConfig) TxDataGas() uint64 {
+	if c == nil || c.Gas == 0 {
+		return TxDataGas
+	}
+	return c.Gas
+}
+
+// TxDataZeroGas returns the gas per zero byte of the legacy transaction data. It returns the default if c is nil.
+func (c *TxDataConfig) TxDataZeroGas() uint64 {
+	if c == nil || c.ZeroGas == 0 {
+		return TxDataZeroGas
+	}
+	return c.ZeroGas
+}
+
+// TxDataNonZeroGas returns the gas per non-zero byte of the legacy transaction data. It returns the default if c is nil.
+func (c *TxDataConfig) TxDataNonZeroGas() uint64 {
+	if c == nil || c.NonZeroGas == 0 {
+		return TxDataNonZeroGas
+	}
+	return c.NonZeroGas
+}
+
+// equal returns whether c and other have the same size limit and gas prices, where nil has the defaults.
+func (c *TxDataConfig) equal(other *TxDataConfig) bool {
+	return c.TxSizeLimit() == other.TxSizeLimit() && c.TxDataGas() == other.TxDataGas() &&
+		c.TxDataZeroGas() == other.TxDataZeroGas() && c.TxDataNonZeroGas() == other.TxDataNonZeroGas()
+}
+
+// PrecompileConfig is the config of a chain-specific precompiled contract.
+// The contract runs the native implementation registered in the VM with the name,
+// and it is charged with the gas prices of the config instead of the ones of the implementation.
+type PrecompileConfig struct {
+	Name            string         `json:"name"`                      // Name of the native implementation registered in the VM
+	Address         common.Address `json:"address"`                   // Address of the contract, it should be in the precompiled contract address range
+	ActivationBlock *big.Int       `json:"activationBlock,omitempty"` // Block which the contract is activated at (nil = activated at genesis)
+	BaseGas         uint64         `json:"baseGas"`                   // Gas charged for every call
+	PerWordGas      uint64         `json:"perWordGas"`                // Gas charged for each 32-byte word of the input
+	ComputationCost uint64         `json:"computationCost"`           // Computation cost charged for every call
+}
+
+// IsActivated returns whether the precompiled contract is activated at the block number.
+func (p *PrecompileConfig) IsActivated(num *big.Int) bool {
+	return p.ActivationBlock == nil || isForked(p.ActivationBlock, num)
+}
+
+// DisabledEVMFeature is an opcode or a precompiled contract disabled on the chain from the activation block.
+// Exactly one of Opcode and Precompile should be set. A disabled opcode fails the execution as an undefined
+// opcode does, and a disabled precompiled contract cannot be called as an unused precompiled contract address.
+type DisabledEVMFeature struct {
+	Opcode          string          `json:"opcode,omitempty"`          // Name of the opcode, e.g. SELFDESTRUCT
+	Precompile      *common.Address `json:"precompile,omitempty"`      // Address of the precompiled contract
+	ActivationBlock *big.Int        `json:"activationBlock,omitempty"` // Block which the feature is disabled at (nil = disabled at genesis)
+}
+
+// IsActivated returns whether the feature is disabled at the block number.
+func (f *DisabledEVMFeature) IsActivated(num *big.Int) bool {
+	return f.ActivationBlock == nil || isForked(f.ActivationBlock, num)
+}
+
+// name returns the name of the feature used to find the same feature in another chain config.
+func (f *DisabledEVMFeature) name() string {
+	if f.Precompile != nil {
+		return "Precompile " + f.Precompile.String()
+	}
+	return "Opcode " + f.Opcode
 }
 
 // GovernanceConfig stores governance information for a network
@@ -241,18 +340,22 @@ func (c *ChainConfig) String() string {
 		engine = "unknown"
 	}
 	if c.Istanbul != nil {
-		return fmt.Sprintf("{ChainID: %v IstanbulCompatibleBlock: %v SubGroupSize: %d UnitPrice: %d DeriveShaImpl: %d Engine: %v}",
+		return fmt.Sprintf("{ChainID: %v IstanbulCompatibleBlock: %v Bls12381CompatibleBlock: %v KZGCompatibleBlock: %v SubGroupSize: %d UnitPrice: %d DeriveShaImpl: %d Engine: %v}",
 			c.ChainID,
 			c.IstanbulCompatibleBlock,
+			c.Bls12381CompatibleBlock,
+			c.KZGCompatibleBlock,
 			c.Istanbul.SubGroupSize,
 			c.UnitPrice,
 			c.DeriveShaImpl,
 			engine,
 		)
 	} else {
-		return fmt.Sprintf("{ChainID: %v IstanbulCompatibleBlock: %v UnitPrice: %d DeriveShaImpl: %d Engine: %v }",
+		return fmt.Sprintf("{ChainID: %v IstanbulCompatibleBlock: %v Bls12381CompatibleBlock: %v KZGCompatibleBlock: %v UnitPrice: %d DeriveShaImpl: %d Engine: %v }",
 			c.ChainID,
 			c.IstanbulCompatibleBlock,
+			c.Bls12381CompatibleBlock,
+			c.KZGCompatibleBlock,
 			c.UnitPrice,
 			c.DeriveShaImpl,
 			engine,
@@ -265,6 +368,16 @@ func (c *ChainConfig) IsIstanbul(num *big.Int) bool {
 	return isForked(c.IstanbulCompatibleBlock, num)
 }
 
+// IsBls12381 returns whether num is either equal to the BLS12-381 precompiles block or greater.
+func (c *ChainConfig) IsBls12381(num *big.Int) bool {
+	return isForked(c.Bls12381CompatibleBlock, num)
+}
+
+// IsKZG returns whether num is either equal to the KZG point evaluation precompile block or greater.
+func (c *ChainConfig) IsKZG(num *big.Int) bool {
+	return isForked(c.KZGCompatibleBlock, num)
+}
+
 // CheckCompatible checks whether scheduled fork transitions have been imported
 // with a mismatching chain configuration.
 func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64) *ConfigCompatError {
@@ -287,9 +400,87 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.IstanbulCompatibleBlock, newcfg.IstanbulCompatibleBlock, head) {
 		return newCompatError("Istanbul Block", c.IstanbulCompatibleBlock, newcfg.IstanbulCompatibleBlock)
 	}
+	if isForkIncompatible(c.Bls12381CompatibleBlock, newcfg.Bls12381CompatibleBlock, head) {
+		return newCompatError("BLS12-381 Block", c.Bls12381CompatibleBlock, newcfg.Bls12381CompatibleBlock)
+	}
+	if isForkIncompatible(c.KZGCompatibleBlock, newcfg.KZGCompatibleBlock, head) {
+		return newCompatError("KZG Block", c.KZGCompatibleBlock, newcfg.KZGCompatibleBlock)
+	}
+	if err := checkPrecompilesCompatible(c.Precompiles, newcfg.Precompiles, head); err != nil {
+		return err
+	}
+	if err := checkDisabledEVMFeaturesCompatible(c.DisabledEVMFeatures, newcfg.DisabledEVMFeatures, head); err != nil {
+		return err
+	}
+	// TxData is applied from the genesis block, so the chain should be rewound to the genesis to change it.
+	if head.Sign() > 0 && !c.TxData.equal(newcfg.TxData) {
+		return newCompatError("TxData", common.Big0, common.Big0)
+	}
 	return nil
 }
 
+// checkPrecompilesCompatible checks whether the chain-specific precompiled contracts are rescheduled as the hard forks
+// are checked, since they change the execution of the blocks after their activation blocks.
+// A contract missing in a config is never activated, and a contract without its activation block is activated at genesis.
+func checkPrecompilesCompatible(stored, newPrecompiles []*PrecompileConfig, head *big.Int) *ConfigCompatError {
+	storedBlocks, newBlocks := precompileBlocks(stored), precompileBlocks(newPrecompiles)
+	for _, precompiles := range [][]*PrecompileConfig{stored, newPrecompiles} {
+		for _, p := range precompiles {
+			storedBlock, newBlock := storedBlocks[p.Address], newBlocks[p.Address]
+			if isForkIncompatible(storedBlock, newBlock, head) {
+				return newCompatError("Precompile "+p.Address.String()+" Block", storedBlock, newBlock)
+			}
+		}
+	}
+	return nil
+}
+
+// precompileBlocks returns the earliest activation block of each chain-specific precompiled contract by its address.
+func precompileBlocks(precompiles []*PrecompileConfig) map[common.Address]*big.Int {
+	blocks := make(map[common.Address]*big.Int, len(precompiles))
+	for _, p := range precompiles {
+		block := p.ActivationBlock
+		if block == nil {
+			block = common.Big0
+		}
+		if prev, ok := blocks[p.Address]; !ok || block.Cmp(prev) < 0 {
+			blocks[p.Address] = block
+		}
+	}
+	return blocks
+}
+
+// checkDisabledEVMFeaturesCompatible checks whether the disabled EVM features are rescheduled as the hard forks
+// are checked, since they change the execution of the blocks after their activation blocks.
+// A feature missing in a config is never disabled, and a feature without its activation block is disabled at genesis.
+func checkDisabledEVMFeaturesCompatible(stored, newFeatures []*DisabledEVMFeature, head *big.Int) *ConfigCompatError {
+	storedBlocks, newBlocks := disabledEVMFeatureBlocks(stored), disabledEVMFeatureBlocks(newFeatures)
+	for _, features := range [][]*DisabledEVMFeature{stored, newFeatures} {
+		for _, f := range features {
+			storedBlock, newBlock := storedBlocks[f.name()], newBlocks[f.name()]
+			if isForkIncompatible(storedBlock, newBlock, head) {
+				return newCompatError("Disabled EVM Feature "+f.name()+" Block", storedBlock, newBlock)
+			}
+		}
+	}
+	return nil
+}
+
+// disabledEVMFeatureBlocks returns the earliest activation block of each disabled EVM feature by its name.
+func disabledEVMFeatureBlocks(features []*DisabledEVMFeature) map[string]*big.Int {
+	blocks := make(map[string]*big.Int, len(features))
+	for _, f := range features {
+		block := f.ActivationBlock
+		if block == nil {
+			block = common.Big0
+		}
+		if prev, ok := blocks[f.name()]; !ok || block.Cmp(prev) < 0 {
+			blocks[f.name()] = block
+		}
+	}
+	return blocks
+}
+
 // GetConsensusEngine returns the consensus engine type specified in ChainConfig.
 // It returns Unknown type if none of engine type is configured or more than one type is configured.
 func (c *ChainConfig) GetConsensusEngine() EngineType {
@@ -400,6 +591,9 @@ func (err *ConfigCompatError) Error() string {
 type Rules struct {
 	ChainID    *big.Int
 	IsIstanbul bool
+	IsBls12381 bool
+	IsKZG      bool
+	TxData     *TxDataConfig
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -411,6 +605,9 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 	return Rules{
 		ChainID:    new(big.Int).Set(chainID),
 		IsIstanbul: c.IsIstanbul(num),
+		IsBls12381: c.IsBls12381(num),
+		IsKZG:      c.IsKZG(num),
+		TxData:     c.TxData,
 	}
 }
 
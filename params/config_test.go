@@ -0,0 +1,183 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+)
+
+func TestCheckCompatible_DisabledEVMFeatures(t *testing.T) {
+	precompile := common.BytesToAddress([]byte{9})
+	configOf := func(features ...*DisabledEVMFeature) *ChainConfig {
+		return &ChainConfig{DisabledEVMFeatures: features}
+	}
+	selfdestructAt := func(block *big.Int) *DisabledEVMFeature {
+		return &DisabledEVMFeature{Opcode: "SELFDESTRUCT", ActivationBlock: block}
+	}
+
+	tests := []struct {
+		stored, new *ChainConfig
+		head        uint64
+		wantErr     *ConfigCompatError
+	}{
+		{stored: configOf(), new: configOf(), head: 10, wantErr: nil},
+		{stored: configOf(selfdestructAt(big.NewInt(5))), new: configOf(selfdestructAt(big.NewInt(5))), head: 10, wantErr: nil},
+		// Rescheduling a feature not activated yet
+		{stored: configOf(selfdestructAt(big.NewInt(20))), new: configOf(selfdestructAt(big.NewInt(30))), head: 10, wantErr: nil},
+		{stored: configOf(), new: configOf(selfdestructAt(big.NewInt(20))), head: 10, wantErr: nil},
+		// Rescheduling an activated feature retroactively
+		{
+			stored: configOf(selfdestructAt(big.NewInt(5))),
+			new:    configOf(selfdestructAt(big.NewInt(8))),
+			head:   10,
+			wantErr: &ConfigCompatError{
+				What:         "Disabled EVM Feature Opcode SELFDESTRUCT Block",
+				StoredConfig: big.NewInt(5),
+				NewConfig:    big.NewInt(8),
+				RewindTo:     4,
+			},
+		},
+		{
+			stored: configOf(selfdestructAt(big.NewInt(5))),
+			new:    configOf(),
+			head:   10,
+			wantErr: &ConfigCompatError{
+				What:         "Disabled EVM Feature Opcode SELFDESTRUCT Block",
+				StoredConfig: big.NewInt(5),
+				NewConfig:    nil,
+				RewindTo:     4,
+			},
+		},
+		{
+			stored: configOf(),
+			new:    configOf(&DisabledEVMFeature{Precompile: &precompile}),
+			head:   10,
+			wantErr: &ConfigCompatError{
+				What:         "Disabled EVM Feature Precompile " + precompile.String() + " Block",
+				StoredConfig: nil,
+				NewConfig:    common.Big0,
+				RewindTo:     0,
+			},
+		},
+	}
+	for _, test := range tests {
+		err := test.stored.CheckCompatible(test.new, test.head)
+		if !reflect.DeepEqual(err, test.wantErr) {
+			t.Errorf("error mismatch:\nstored: %v\nnew: %v\nhead: %v\nerr: %v\nwant: %v", test.stored.DisabledEVMFeatures, test.new.DisabledEVMFeatures, test.head, err, test.wantErr)
+		}
+	}
+}
+
+func TestCheckCompatible_Precompiles(t *testing.T) {
+	addr := common.BytesToAddress([]byte{4, 0})
+	configOf := func(precompiles ...*PrecompileConfig) *ChainConfig {
+		return &ChainConfig{Precompiles: precompiles}
+	}
+	precompileAt := func(block *big.Int) *PrecompileConfig {
+		return &PrecompileConfig{Name: "sha256", Address: addr, ActivationBlock: block, BaseGas: 60}
+	}
+
+	tests := []struct {
+		stored, new *ChainConfig
+		head        uint64
+		wantErr     *ConfigCompatError
+	}{
+		{stored: configOf(), new: configOf(), head: 10, wantErr: nil},
+		{stored: configOf(precompileAt(nil)), new: configOf(precompileAt(common.Big0)), head: 10, wantErr: nil},
+		// Rescheduling a contract not activated yet
+		{stored: configOf(precompileAt(big.NewInt(20))), new: configOf(precompileAt(big.NewInt(30))), head: 10, wantErr: nil},
+		{stored: configOf(), new: configOf(precompileAt(big.NewInt(20))), head: 10, wantErr: nil},
+		// Rescheduling an activated contract retroactively
+		{
+			stored: configOf(precompileAt(big.NewInt(5))),
+			new:    configOf(precompileAt(big.NewInt(8))),
+			head:   10,
+			wantErr: &ConfigCompatError{
+				What:         "Precompile " + addr.String() + " Block",
+				StoredConfig: big.NewInt(5),
+				NewConfig:    big.NewInt(8),
+				RewindTo:     4,
+			},
+		},
+		{
+			stored: configOf(),
+			new:    configOf(precompileAt(big.NewInt(8))),
+			head:   10,
+			wantErr: &ConfigCompatError{
+				What:         "Precompile " + addr.String() + " Block",
+				StoredConfig: nil,
+				NewConfig:    big.NewInt(8),
+				RewindTo:     7,
+			},
+		},
+		{
+			stored: configOf(precompileAt(nil)),
+			new:    configOf(),
+			head:   10,
+			wantErr: &ConfigCompatError{
+				What:         "Precompile " + addr.String() + " Block",
+				StoredConfig: common.Big0,
+				NewConfig:    nil,
+				RewindTo:     0,
+			},
+		},
+	}
+	for _, test := range tests {
+		err := test.stored.CheckCompatible(test.new, test.head)
+		if !reflect.DeepEqual(err, test.wantErr) {
+			t.Errorf("error mismatch:\nstored: %v\nnew: %v\nhead: %v\nerr: %v\nwant: %v", test.stored.Precompiles, test.new.Precompiles, test.head, err, test.wantErr)
+		}
+	}
+}
+
+func TestCheckCompatible_TxData(t *testing.T) {
+	tests := []struct {
+		stored, new *TxDataConfig
+		head        uint64
+		wantErr     bool
+	}{
+		{stored: nil, new: nil, head: 10, wantErr: false},
+		// The zero values are the defaults
+		{stored: nil, new: &TxDataConfig{}, head: 10, wantErr: false},
+		{stored: &TxDataConfig{MaxTxSize: MaxTxDataSize}, new: nil, head: 10, wantErr: false},
+		{stored: &TxDataConfig{MaxTxSize: 64 * 1024, Gas: 50}, new: &TxDataConfig{MaxTxSize: 64 * 1024, Gas: 50}, head: 10, wantErr: false},
+		// Changing it before any block is built on the genesis
+		{stored: nil, new: &TxDataConfig{MaxTxSize: 64 * 1024}, head: 0, wantErr: false},
+		// Changing it after the genesis block
+		{stored: nil, new: &TxDataConfig{MaxTxSize: 64 * 1024}, head: 10, wantErr: true},
+		{stored: &TxDataConfig{Gas: 50}, new: &TxDataConfig{Gas: 60}, head: 10, wantErr: true},
+		{stored: &TxDataConfig{ZeroGas: 2}, new: nil, head: 1, wantErr: true},
+		{stored: nil, new: &TxDataConfig{NonZeroGas: 50}, head: 10, wantErr: true},
+	}
+	for i, test := range tests {
+		err := (&ChainConfig{TxData: test.stored}).CheckCompatible(&ChainConfig{TxData: test.new}, test.head)
+		if !test.wantErr {
+			if err != nil {
+				t.Errorf("test %d: unexpected error %v", i, err)
+			}
+			continue
+		}
+		want := &ConfigCompatError{What: "TxData", StoredConfig: common.Big0, NewConfig: common.Big0, RewindTo: 0}
+		if !reflect.DeepEqual(err, want) {
+			t.Errorf("test %d: error mismatch\nerr: %v\nwant: %v", i, err, want)
+		}
+	}
+}
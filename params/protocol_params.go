@@ -114,6 +114,18 @@ const (
 	FeePayerGas                           uint64 = 300    // Gas needed for calculating the fee payer of the transaction in a smart contract.
 	ValidateSenderGas                     uint64 = 5000   // Gas needed for validating the signature of a message.
 
+	Bls12381G1AddGas          uint64 = 600    // Price for BLS12-381 elliptic curve G1 point addition
+	Bls12381G1MulGas          uint64 = 12000  // Price for BLS12-381 elliptic curve G1 point scalar multiplication
+	Bls12381G2AddGas          uint64 = 4500   // Price for BLS12-381 elliptic curve G2 point addition
+	Bls12381G2MulGas          uint64 = 55000  // Price for BLS12-381 elliptic curve G2 point scalar multiplication
+	Bls12381PairingBaseGas    uint64 = 115000 // Base gas price for BLS12-381 elliptic curve pairing check
+	Bls12381PairingPerPairGas uint64 = 23000  // Per-point pair gas price for BLS12-381 elliptic curve pairing check
+	Bls12381MapG1Gas          uint64 = 5500   // Gas price for BLS12-381 mapping field element to G1 operation
+	Bls12381MapG2Gas          uint64 = 110000 // Gas price for BLS12-381 mapping field element to G2 operation
+
+	KZGPointEvaluationGas   uint64 = 50000 // Gas price for the KZG point evaluation of EIP-4844
+	KZGFieldElementsPerBlob uint64 = 4096  // Number of field elements of a blob of EIP-4844, returned by the KZG point evaluation
+
 	GasLimitBoundDivisor uint64 = 1024    // The bound divisor of the gas limit, used in update calculations.
 	MinGasLimit          uint64 = 5000    // Minimum the gas limit may ever be.
 	GenesisGasLimit      uint64 = 4712388 // Gas limit of the Genesis block.
@@ -150,11 +162,16 @@ const (
 	BaobabNetworkId              uint64 = 1001
 	CypressNetworkId             uint64 = 8217
 	ServiceChainDefaultNetworkId uint64 = 3000
+	DeveloperNetworkId           uint64 = 2019
 
 	TxGasValueTransfer     uint64 = 21000
 	TxGasContractExecution uint64 = 21000
 
 	TxDataGas uint64 = 100
+
+	// MaxTxDataSize is the default limit of the transaction size. The tx pool rejects the transactions
+	// over 32KB to prevent DOS attacks.
+	MaxTxDataSize = 32 * 1024
 )
 
 var (
@@ -165,6 +182,9 @@ var (
 	DurationLimit                 = big.NewInt(13)     // The decision boundary on the blocktime duration used to determine whether blockscore should go up or not.
 )
 
+// Gas discount table for BLS12-381 G1 and G2 multi exponentiation operations
+var Bls12381MultiExpDiscountTable = [128]uint64{1200, 888, 764, 641, 594, 547, 500, 453, 438, 423, 408, 394, 379, 364, 349, 334, 330, 326, 322, 318, 314, 310, 306, 302, 298, 294, 289, 285, 281, 277, 273, 269, 268, 266, 265, 263, 262, 260, 259, 257, 256, 254, 253, 251, 250, 248, 247, 245, 244, 242, 241, 239, 238, 236, 235, 233, 232, 231, 229, 228, 226, 225, 223, 222, 221, 220, 219, 219, 218, 217, 216, 216, 215, 214, 213, 213, 212, 211, 211, 210, 209, 208, 208, 207, 206, 205, 205, 204, 203, 202, 202, 201, 200, 199, 199, 198, 197, 196, 196, 195, 194, 193, 193, 192, 191, 191, 190, 189, 188, 188, 187, 186, 185, 185, 184, 183, 182, 182, 181, 180, 179, 179, 178, 177, 176, 176, 175, 174}
+
 // Parameters for execution time limit
 var (
 	// TODO-Klaytn Determine more practical values through actual running experience
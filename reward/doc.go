@@ -78,5 +78,18 @@ Last, distribute reward to each address (proposer, PoC, KIR).
  related struct
  - RewardDistributor
  - rewardConfigCache
+
+
+Tracking Supply Changes
+
+A SupplyTracker accumulates the minted KLAY, the collected transaction fees and the burnt fees of every block.
+The cumulative amounts are saved as a SupplyCheckpoint on every 3,600 blocks (SupplyCheckpointInterval),
+so the changes in a day or an epoch are derived from the checkpoints instead of the external indexers.
+Transaction fees are not burnt yet, so the burnt fee is always zero for now.
+
+ related struct
+ - SupplyTracker
+ - SupplyCheckpoint
+ - SupplyStats
 */
 package reward
@@ -0,0 +1,324 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package reward
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/log"
+)
+
+// SupplyCheckpointInterval is the interval of the blocks at which the cumulative supply
+// changes are persisted. Days and governance epochs are multiples of the interval,
+// so the statistics of them are computed from the checkpoints without block iteration.
+const SupplyCheckpointInterval = 3600
+
+var errInvalidSupplyRange = errors.New("invalid block range")
+
+// supplyChain is an interface for blockchain.Blockchain used by SupplyTracker.
+type supplyChain interface {
+	SubscribeChainHeadEvent(ch chan<- blockchain.ChainHeadEvent) event.Subscription
+	CurrentHeader() *types.Header
+	GetHeaderByNumber(number uint64) *types.Header
+}
+
+type supplyCheckpointDB interface {
+	ReadSupplyCheckpoint(blockNum uint64) ([]byte, error)
+	WriteSupplyCheckpoint(blockNum uint64, checkpoint []byte) error
+	ReadLastSupplyCheckpointNumber() (uint64, error)
+	WriteLastSupplyCheckpointNumber(blockNum uint64) error
+}
+
+// SupplyCheckpoint is the cumulative amount of KLAY minted, collected as transaction fees
+// and burnt from the genesis block to the block of Number.
+type SupplyCheckpoint struct {
+	Number   uint64
+	Minted   *big.Int
+	TxFee    *big.Int
+	BurntFee *big.Int
+}
+
+func newSupplyCheckpoint(number uint64) *SupplyCheckpoint {
+	return &SupplyCheckpoint{Number: number, Minted: big.NewInt(0), TxFee: big.NewInt(0), BurntFee: big.NewInt(0)}
+}
+
+// SupplyStats is the amount of KLAY minted, collected as transaction fees and burnt
+// in the blocks from FromBlock to ToBlock, both inclusive.
+type SupplyStats struct {
+	FromBlock uint64
+	ToBlock   uint64
+	Minted    *big.Int
+	TxFee     *big.Int
+	BurntFee  *big.Int
+}
+
+// SupplyTracker accumulates the KLAY minted, the transaction fees collected and the fees
+// burnt by every block, and persists the cumulative amounts at every SupplyCheckpointInterval
+// blocks. Transaction fees are not burnt yet, so the burnt fee is always zero for now.
+type SupplyTracker struct {
+	rd *RewardDistributor
+	bc supplyChain
+	db supplyCheckpointDB
+
+	mu   sync.RWMutex
+	head *SupplyCheckpoint // cumulative amounts up to the last tracked block
+
+	chainHeadCh  chan blockchain.ChainHeadEvent
+	chainHeadSub event.Subscription
+	quit         chan struct{}
+	wg           sync.WaitGroup
+}
+
+// NewSupplyTracker creates a SupplyTracker. Call Start to begin tracking.
+func NewSupplyTracker(bc supplyChain, gh governanceHelper, db supplyCheckpointDB) *SupplyTracker {
+	return &SupplyTracker{
+		rd:          NewRewardDistributor(gh),
+		bc:          bc,
+		db:          db,
+		chainHeadCh: make(chan blockchain.ChainHeadEvent, chainHeadChanSize),
+		quit:        make(chan struct{}),
+	}
+}
+
+// Start loads the last checkpoint and starts tracking the blocks imported.
+// The blocks after the last checkpoint are tracked in the background.
+func (t *SupplyTracker) Start() error {
+	head, err := t.lastCheckpoint()
+	if err != nil {
+		return err
+	}
+	t.head = head
+	t.chainHeadSub = t.bc.SubscribeChainHeadEvent(t.chainHeadCh)
+
+	t.wg.Add(1)
+	go t.loop()
+	return nil
+}
+
+// Stop stops tracking the blocks.
+func (t *SupplyTracker) Stop() {
+	if t.chainHeadSub == nil {
+		return
+	}
+	t.chainHeadSub.Unsubscribe()
+	close(t.quit)
+	t.wg.Wait()
+}
+
+func (t *SupplyTracker) loop() {
+	defer t.wg.Done()
+
+	logger.Info("Start tracking the supply changes", "from", t.Head()+1)
+	if err := t.track(t.bc.CurrentHeader().Number.Uint64()); err != nil {
+		logger.Error("Failed to track the supply changes", "err", err)
+	}
+	for {
+		select {
+		case ev := <-t.chainHeadCh:
+			if err := t.track(ev.Block.NumberU64()); err != nil {
+				logger.Error("Failed to track the supply changes", "block", ev.Block.NumberU64(), "err", err)
+			}
+		case <-t.chainHeadSub.Err():
+			return
+		case <-t.quit:
+			return
+		}
+	}
+}
+
+// Head returns the number of the last tracked block.
+func (t *SupplyTracker) Head() uint64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.head.Number
+}
+
+// track accumulates the supply changes of the blocks up to the given number.
+// If the chain is rewound below the tracked block, the blocks are tracked again from the
+// checkpoint before the new head.
+func (t *SupplyTracker) track(number uint64) error {
+	t.mu.RLock()
+	cur := t.head
+	t.mu.RUnlock()
+
+	if number < cur.Number {
+		checkpoint, err := t.checkpoint(number - number%SupplyCheckpointInterval)
+		if err != nil {
+			return err
+		}
+		if err := t.db.WriteLastSupplyCheckpointNumber(checkpoint.Number); err != nil {
+			return err
+		}
+		logger.Warn("Rewound the supply changes", "from", cur.Number, "to", checkpoint.Number)
+
+		t.mu.Lock()
+		t.head = checkpoint
+		t.mu.Unlock()
+		cur = checkpoint
+	}
+
+	var (
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for cur.Number < number {
+		select {
+		case <-t.quit:
+			return nil
+		default:
+		}
+
+		header := t.bc.GetHeaderByNumber(cur.Number + 1)
+		if header == nil {
+			return fmt.Errorf("block #%d not found", cur.Number+1)
+		}
+		next, err := t.accumulate(cur, header)
+		if err != nil {
+			return err
+		}
+		if next.Number%SupplyCheckpointInterval == 0 {
+			if err := t.writeCheckpoint(next); err != nil {
+				return err
+			}
+		}
+
+		t.mu.Lock()
+		t.head = next
+		t.mu.Unlock()
+		cur = next
+
+		if time.Since(logged) > log.StatsReportLimit {
+			logger.Info("Tracking the supply changes", "block", cur.Number, "target", number, "elapsed", time.Since(start))
+			logged = time.Now()
+		}
+	}
+	return nil
+}
+
+// accumulate returns a new checkpoint adding the supply changes of the given block to cur.
+func (t *SupplyTracker) accumulate(cur *SupplyCheckpoint, header *types.Header) (*SupplyCheckpoint, error) {
+	rewardConfig, err := t.rd.rcc.get(header.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	return &SupplyCheckpoint{
+		Number:   header.Number.Uint64(),
+		Minted:   new(big.Int).Add(cur.Minted, rewardConfig.mintingAmount),
+		TxFee:    new(big.Int).Add(cur.TxFee, t.rd.getTotalTxFee(header, rewardConfig)),
+		BurntFee: new(big.Int).Set(cur.BurntFee), // no transaction fee is burnt yet
+	}, nil
+}
+
+// Cumulative returns the cumulative amounts from the genesis block to the given block.
+func (t *SupplyTracker) Cumulative(number uint64) (*SupplyCheckpoint, error) {
+	t.mu.RLock()
+	head := t.head
+	t.mu.RUnlock()
+
+	if head == nil || number > head.Number {
+		return nil, fmt.Errorf("supply changes of block #%d are not tracked yet", number)
+	}
+	if number == head.Number {
+		return head, nil
+	}
+
+	cur, err := t.checkpoint(number - number%SupplyCheckpointInterval)
+	if err != nil {
+		return nil, err
+	}
+	for cur.Number < number {
+		header := t.bc.GetHeaderByNumber(cur.Number + 1)
+		if header == nil {
+			return nil, fmt.Errorf("block #%d not found", cur.Number+1)
+		}
+		if cur, err = t.accumulate(cur, header); err != nil {
+			return nil, err
+		}
+	}
+	return cur, nil
+}
+
+// Stats returns the supply changes in the blocks from the given range, both inclusive.
+func (t *SupplyTracker) Stats(from, to uint64) (*SupplyStats, error) {
+	if from > to {
+		return nil, errInvalidSupplyRange
+	}
+	end, err := t.Cumulative(to)
+	if err != nil {
+		return nil, err
+	}
+	begin := newSupplyCheckpoint(0)
+	if from > 0 {
+		if begin, err = t.Cumulative(from - 1); err != nil {
+			return nil, err
+		}
+	}
+	return &SupplyStats{
+		FromBlock: from,
+		ToBlock:   to,
+		Minted:    new(big.Int).Sub(end.Minted, begin.Minted),
+		TxFee:     new(big.Int).Sub(end.TxFee, begin.TxFee),
+		BurntFee:  new(big.Int).Sub(end.BurntFee, begin.BurntFee),
+	}, nil
+}
+
+// lastCheckpoint returns the last checkpoint persisted, or an empty checkpoint of the
+// genesis block if there is none.
+func (t *SupplyTracker) lastCheckpoint() (*SupplyCheckpoint, error) {
+	number, err := t.db.ReadLastSupplyCheckpointNumber()
+	if err != nil {
+		return nil, err
+	}
+	return t.checkpoint(number)
+}
+
+// checkpoint reads the checkpoint of the given block number, which should be a multiple
+// of SupplyCheckpointInterval.
+func (t *SupplyTracker) checkpoint(number uint64) (*SupplyCheckpoint, error) {
+	if number == 0 {
+		// the genesis block mints nothing
+		return newSupplyCheckpoint(0), nil
+	}
+	data, err := t.db.ReadSupplyCheckpoint(number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the supply checkpoint of block #%d: %v", number, err)
+	}
+	checkpoint := new(SupplyCheckpoint)
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+func (t *SupplyTracker) writeCheckpoint(checkpoint *SupplyCheckpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	if err := t.db.WriteSupplyCheckpoint(checkpoint.Number, data); err != nil {
+		return err
+	}
+	return t.db.WriteLastSupplyCheckpointNumber(checkpoint.Number)
+}
@@ -0,0 +1,167 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package reward
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+type testSupplyChain struct {
+	mu      sync.Mutex
+	headers []*types.Header
+	feed    event.Feed
+}
+
+// newTestSupplyChain returns a chain of the given length whose block i uses i gas.
+func newTestSupplyChain(length uint64) *testSupplyChain {
+	chain := &testSupplyChain{}
+	for i := uint64(0); i <= length; i++ {
+		chain.headers = append(chain.headers, &types.Header{Number: new(big.Int).SetUint64(i), GasUsed: i})
+	}
+	return chain
+}
+
+func (c *testSupplyChain) SubscribeChainHeadEvent(ch chan<- blockchain.ChainHeadEvent) event.Subscription {
+	return c.feed.Subscribe(ch)
+}
+
+func (c *testSupplyChain) CurrentHeader() *types.Header {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.headers[len(c.headers)-1]
+}
+
+func (c *testSupplyChain) GetHeaderByNumber(number uint64) *types.Header {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if number >= uint64(len(c.headers)) {
+		return nil
+	}
+	return c.headers[number]
+}
+
+// setHead extends or rewinds the chain to the given number and sends a chain head event.
+func (c *testSupplyChain) setHead(number uint64) {
+	c.mu.Lock()
+	if number < uint64(len(c.headers)) {
+		c.headers = c.headers[:number+1]
+	}
+	for i := uint64(len(c.headers)); i <= number; i++ {
+		c.headers = append(c.headers, &types.Header{Number: new(big.Int).SetUint64(i), GasUsed: i})
+	}
+	head := c.headers[number]
+	c.mu.Unlock()
+
+	c.feed.Send(blockchain.ChainHeadEvent{Block: types.NewBlockWithHeader(head)})
+}
+
+func waitSupplyTracked(t *testing.T, tracker *SupplyTracker, number uint64) {
+	for i := 0; i < 500; i++ {
+		if tracker.Head() == number {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("supply changes are not tracked up to #%d, head: #%d", number, tracker.Head())
+}
+
+// expectedSupplyStats returns the supply changes from the default test governance
+// for the blocks of the test chain.
+func expectedSupplyStats(from, to uint64) *SupplyStats {
+	gh := newDefaultTestGovernance()
+	minting, _ := new(big.Int).SetString(gh.mintingAmount, 10)
+	gasUsed := (from + to) * (to - from + 1) / 2
+	return &SupplyStats{
+		FromBlock: from,
+		ToBlock:   to,
+		Minted:    new(big.Int).Mul(minting, new(big.Int).SetUint64(to-from+1)),
+		TxFee:     new(big.Int).Mul(new(big.Int).SetUint64(gh.unitPrice), new(big.Int).SetUint64(gasUsed)),
+		BurntFee:  big.NewInt(0),
+	}
+}
+
+func TestSupplyTracker(t *testing.T) {
+	const length = 2*SupplyCheckpointInterval + 10
+
+	db := database.NewMemoryDBManager()
+	chain := newTestSupplyChain(length)
+
+	tracker := NewSupplyTracker(chain, newDefaultTestGovernance(), db)
+	assert.NoError(t, tracker.Start())
+	waitSupplyTracked(t, tracker, length)
+
+	// Cumulative amounts and the amounts of a checkpoint interval
+	stats, err := tracker.Stats(0, length)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSupplyStats(1, length).Minted, stats.Minted)
+	assert.Equal(t, expectedSupplyStats(1, length).TxFee, stats.TxFee)
+	assert.Equal(t, 0, stats.BurntFee.Sign())
+
+	stats, err = tracker.Stats(SupplyCheckpointInterval+1, 2*SupplyCheckpointInterval)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSupplyStats(SupplyCheckpointInterval+1, 2*SupplyCheckpointInterval), stats)
+
+	// Arbitrary range between the checkpoints
+	stats, err = tracker.Stats(100, SupplyCheckpointInterval+100)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSupplyStats(100, SupplyCheckpointInterval+100), stats)
+
+	_, err = tracker.Stats(10, 9)
+	assert.Equal(t, errInvalidSupplyRange, err)
+	_, err = tracker.Stats(1, length+1)
+	assert.Error(t, err)
+
+	lastCheckpoint, err := db.ReadLastSupplyCheckpointNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2*SupplyCheckpointInterval), lastCheckpoint)
+
+	// New blocks are tracked
+	chain.setHead(length + 5)
+	waitSupplyTracked(t, tracker, length+5)
+	tracker.Stop()
+
+	// Restarted from the last checkpoint
+	tracker = NewSupplyTracker(chain, newDefaultTestGovernance(), db)
+	assert.NoError(t, tracker.Start())
+	defer tracker.Stop()
+	waitSupplyTracked(t, tracker, length+5)
+
+	stats, err = tracker.Stats(1, length+5)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSupplyStats(1, length+5), stats)
+
+	// Rewound below the last checkpoint
+	chain.setHead(2*SupplyCheckpointInterval - 1)
+	waitSupplyTracked(t, tracker, 2*SupplyCheckpointInterval-1)
+
+	lastCheckpoint, err = db.ReadLastSupplyCheckpointNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(SupplyCheckpointInterval), lastCheckpoint)
+
+	stats, err = tracker.Stats(1, 2*SupplyCheckpointInterval-1)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSupplyStats(1, 2*SupplyCheckpointInterval-1), stats)
+}
@@ -0,0 +1,283 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/klaytn/klaytn/common"
+	"github.com/rcrowley/go-metrics"
+)
+
+// ChainDataCompressionType is the compression method of the block bodies and the receipts
+// written to the database. It is independent from the block compression of LevelDB,
+// so it works for every DBType.
+type ChainDataCompressionType uint8
+
+const (
+	NoChainDataCompression ChainDataCompressionType = iota
+	SnappyChainDataCompression
+	ZstdChainDataCompression
+	chainDataCompressionTypeSize
+)
+
+var chainDataCompressionTypeNames = [chainDataCompressionTypeSize]string{"none", "snappy", "zstd"}
+
+func (ct ChainDataCompressionType) String() string {
+	if ct < chainDataCompressionTypeSize {
+		return chainDataCompressionTypeNames[ct]
+	}
+	return fmt.Sprintf("unknown(%d)", ct)
+}
+
+// ParseChainDataCompressionType returns the ChainDataCompressionType of the given name.
+func ParseChainDataCompressionType(name string) (ChainDataCompressionType, error) {
+	for ct, ctName := range chainDataCompressionTypeNames {
+		if strings.ToLower(name) == ctName {
+			return ChainDataCompressionType(ct), nil
+		}
+	}
+	return NoChainDataCompression, fmt.Errorf("unknown chain data compression type %q (available: %s)",
+		name, strings.Join(chainDataCompressionTypeNames[:], ", "))
+}
+
+// A compressed value is stored in an envelope: [version][compression type][compressed data].
+// An RLP encoded body or receipt list always starts with a byte not less than 0xc0,
+// so values without the envelope, which are written before enabling the compression, are read as they are.
+const (
+	chainDataEnvelopeVersion    = byte(0x01)
+	chainDataEnvelopeHeaderSize = 2
+)
+
+var errInvalidChainDataEnvelope = errors.New("invalid chain data envelope")
+
+var (
+	chainDataRawBytesCounter        = metrics.NewRegisteredCounter("klay/db/chaindata/compression/raw", nil)
+	chainDataCompressedBytesCounter = metrics.NewRegisteredCounter("klay/db/chaindata/compression/compressed", nil)
+
+	// zstd encoder and decoder are created on demand, since they hold goroutines and buffers.
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+func initZstd() {
+	zstdOnce.Do(func() {
+		zstdEncoder, _ = zstd.NewWriter(nil)
+		zstdDecoder, _ = zstd.NewReader(nil)
+	})
+}
+
+// compressChainData returns the value to be stored for the given data.
+// The data is stored as it is if the compression is disabled or it does not reduce the size.
+func compressChainData(ct ChainDataCompressionType, data []byte) []byte {
+	var compressed []byte
+	switch ct {
+	case SnappyChainDataCompression:
+		compressed = snappy.Encode(nil, data)
+	case ZstdChainDataCompression:
+		initZstd()
+		compressed = zstdEncoder.EncodeAll(data, nil)
+	default:
+		return data
+	}
+	if len(compressed)+chainDataEnvelopeHeaderSize >= len(data) {
+		return data
+	}
+	chainDataRawBytesCounter.Inc(int64(len(data)))
+	chainDataCompressedBytesCounter.Inc(int64(len(compressed) + chainDataEnvelopeHeaderSize))
+
+	return append([]byte{chainDataEnvelopeVersion, byte(ct)}, compressed...)
+}
+
+// decompressChainData returns the original data of the stored value.
+func decompressChainData(value []byte) ([]byte, error) {
+	ct, ok := chainDataCompressionTypeOf(value)
+	if !ok {
+		return nil, errInvalidChainDataEnvelope
+	}
+	switch ct {
+	case NoChainDataCompression:
+		return value, nil
+	case SnappyChainDataCompression:
+		return snappy.Decode(nil, value[chainDataEnvelopeHeaderSize:])
+	case ZstdChainDataCompression:
+		initZstd()
+		return zstdDecoder.DecodeAll(value[chainDataEnvelopeHeaderSize:], nil)
+	default:
+		return nil, fmt.Errorf("unsupported chain data compression type %v", ct)
+	}
+}
+
+// chainDataCompressionTypeOf returns the compression type of the stored value.
+func chainDataCompressionTypeOf(value []byte) (ChainDataCompressionType, bool) {
+	if len(value) == 0 || value[0] >= 0xc0 {
+		return NoChainDataCompression, true
+	}
+	if len(value) < chainDataEnvelopeHeaderSize || value[0] != chainDataEnvelopeVersion {
+		return NoChainDataCompression, false
+	}
+	return ChainDataCompressionType(value[1]), true
+}
+
+// encodeChainData returns the value of a block body or receipts to be stored
+// with the configured compression.
+func (dbm *databaseManager) encodeChainData(data []byte) []byte {
+	return compressChainData(dbm.config.ChainDataCompression, data)
+}
+
+// decodeChainData returns the RLP encoded block body or receipts of the stored value.
+func (dbm *databaseManager) decodeChainData(value []byte) []byte {
+	data, err := decompressChainData(value)
+	if err != nil {
+		logger.Error("Failed to decompress chain data", "err", err)
+		return nil
+	}
+	return data
+}
+
+// RecompressChainData rewrites the block bodies and the receipts of the canonical blocks in [from, to]
+// with the configured compression. The entries already stored with the configured compression are skipped.
+// It returns the number of the rewritten entries.
+func (dbm *databaseManager) RecompressChainData(from, to uint64, quit <-chan struct{}) (int, error) {
+	var (
+		start     = time.Now()
+		logged    = time.Now()
+		rewritten = 0
+		target    = dbm.config.ChainDataCompression
+	)
+	recompress := func(batch Batch, db Database, key []byte) error {
+		value, err := db.Get(key)
+		if err != nil || len(value) == 0 {
+			return nil // missing entries, such as the pruned ones, are skipped
+		}
+		if ct, ok := chainDataCompressionTypeOf(value); ok && ct == target {
+			return nil
+		}
+		data, err := decompressChainData(value)
+		if err != nil {
+			return err
+		}
+		// The data not reduced by the compression is kept as it is
+		recompressed := compressChainData(target, data)
+		if bytes.Equal(recompressed, value) {
+			return nil
+		}
+		if err := batch.Put(key, recompressed); err != nil {
+			return err
+		}
+		rewritten++
+		if batch.ValueSize() >= IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		return nil
+	}
+
+	bodyDB, receiptsDB := dbm.getDatabase(BodyDB), dbm.getDatabase(ReceiptsDB)
+	bodyBatch, receiptsBatch := dbm.NewBatch(BodyDB), dbm.NewBatch(ReceiptsDB)
+
+	for number := from; number <= to; number++ {
+		select {
+		case <-quit:
+			return rewritten, errors.New("chain data recompression is aborted")
+		default:
+		}
+		hash := dbm.ReadCanonicalHash(number)
+		if common.EmptyHash(hash) {
+			continue
+		}
+		if err := recompress(bodyBatch, bodyDB, blockBodyKey(number, hash)); err != nil {
+			return rewritten, fmt.Errorf("failed to recompress the body of block %d: %v", number, err)
+		}
+		if err := recompress(receiptsBatch, receiptsDB, blockReceiptsKey(number, hash)); err != nil {
+			return rewritten, fmt.Errorf("failed to recompress the receipts of block %d: %v", number, err)
+		}
+		if time.Since(logged) > 8*time.Second {
+			logger.Info("Recompressing chain data", "number", number, "to", to, "rewritten", rewritten, "elapsed", time.Since(start))
+			logged = time.Now()
+		}
+	}
+	for _, batch := range []Batch{bodyBatch, receiptsBatch} {
+		if err := batch.Write(); err != nil {
+			return rewritten, err
+		}
+	}
+	logger.Info("Recompressed chain data", "from", from, "to", to, "compression", target, "rewritten", rewritten, "elapsed", time.Since(start))
+	return rewritten, nil
+}
+
+// ChainDataCompressionStats is the number of the block bodies and the receipts stored with each
+// compression type, and their stored and original sizes in bytes, indexed by the compression type.
+type ChainDataCompressionStats struct {
+	Entries    [chainDataCompressionTypeSize]uint64
+	StoredSize [chainDataCompressionTypeSize]uint64
+	RawSize    [chainDataCompressionTypeSize]uint64
+}
+
+// ChainDataCompressionStats returns the statistics of the compression of the block bodies and the
+// receipts of the canonical blocks in [from, to]. The missing entries, such as the pruned ones, are skipped.
+func (dbm *databaseManager) ChainDataCompressionStats(from, to uint64, quit <-chan struct{}) (*ChainDataCompressionStats, error) {
+	stats := new(ChainDataCompressionStats)
+	add := func(db Database, key []byte) error {
+		value, err := db.Get(key)
+		if err != nil || len(value) == 0 {
+			return nil
+		}
+		ct, ok := chainDataCompressionTypeOf(value)
+		if !ok || ct >= chainDataCompressionTypeSize {
+			return errInvalidChainDataEnvelope
+		}
+		data, err := decompressChainData(value)
+		if err != nil {
+			return err
+		}
+		stats.Entries[ct]++
+		stats.StoredSize[ct] += uint64(len(value))
+		stats.RawSize[ct] += uint64(len(data))
+		return nil
+	}
+
+	bodyDB, receiptsDB := dbm.getDatabase(BodyDB), dbm.getDatabase(ReceiptsDB)
+	for number := from; number <= to; number++ {
+		select {
+		case <-quit:
+			return nil, errors.New("chain data compression stats is aborted")
+		default:
+		}
+		hash := dbm.ReadCanonicalHash(number)
+		if common.EmptyHash(hash) {
+			continue
+		}
+		if err := add(bodyDB, blockBodyKey(number, hash)); err != nil {
+			return nil, fmt.Errorf("failed to read the body of block %d: %v", number, err)
+		}
+		if err := add(receiptsDB, blockReceiptsKey(number, hash)); err != nil {
+			return nil, fmt.Errorf("failed to read the receipts of block %d: %v", number, err)
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestChainDataCompression tests that the compressed values are decompressed to the original data
+// and the values without the envelope are read as they are.
+func TestChainDataCompression(t *testing.T) {
+	receipts := make([]*types.ReceiptForStorage, 0, 100)
+	for i := 0; i < 100; i++ {
+		receipts = append(receipts, (*types.ReceiptForStorage)(genReceipt(111)))
+	}
+	data, err := rlp.EncodeToBytes(receipts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for ct := NoChainDataCompression; ct < chainDataCompressionTypeSize; ct++ {
+		value := compressChainData(ct, data)
+		if ct == NoChainDataCompression {
+			assert.Equal(t, data, value)
+		} else {
+			assert.True(t, len(value) < len(data), ct.String())
+		}
+		stored, ok := chainDataCompressionTypeOf(value)
+		assert.True(t, ok)
+		assert.Equal(t, ct, stored)
+
+		decompressed, err := decompressChainData(value)
+		assert.NoError(t, err)
+		assert.Equal(t, data, decompressed)
+	}
+
+	// Incompressible data is stored without the envelope
+	small := []byte{0xc1, 0x80}
+	assert.Equal(t, small, compressChainData(SnappyChainDataCompression, small))
+
+	// Unknown envelopes are rejected
+	_, err = decompressChainData([]byte{0x02, byte(SnappyChainDataCompression), 0x00})
+	assert.Equal(t, errInvalidChainDataEnvelope, err)
+	_, err = decompressChainData([]byte{chainDataEnvelopeVersion, byte(chainDataCompressionTypeSize), 0x00})
+	assert.Error(t, err)
+
+	for _, name := range []string{"none", "snappy", "ZSTD"} {
+		_, err := ParseChainDataCompressionType(name)
+		assert.NoError(t, err)
+	}
+	_, err = ParseChainDataCompressionType("lz4")
+	assert.Error(t, err)
+}
+
+// TestDBManager_RecompressChainData tests that the bodies and the receipts written without compression
+// are rewritten with the configured compression and still readable.
+func TestDBManager_RecompressChainData(t *testing.T) {
+	dbm := NewMemoryDBManager()
+	body := &types.Body{Transactions: types.Transactions{}}
+	receipts := types.Receipts{genReceipt(111), genReceipt(222), genReceipt(333)}
+
+	hashes := []common.Hash{hash1, hash2, hash3}
+	for i, hash := range hashes {
+		dbm.WriteCanonicalHash(hash, uint64(i))
+		dbm.WriteBody(hash, uint64(i), body)
+		dbm.WriteReceipts(hash, uint64(i), receipts)
+	}
+
+	dbm.GetDBConfig().ChainDataCompression = ZstdChainDataCompression
+	rewritten, err := dbm.RecompressChainData(0, uint64(len(hashes)), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, len(hashes), rewritten) // the empty bodies are too small to be compressed
+
+	for i, hash := range hashes {
+		value, _ := dbm.getDatabase(ReceiptsDB).Get(blockReceiptsKey(uint64(i), hash))
+		ct, _ := chainDataCompressionTypeOf(value)
+		assert.Equal(t, ZstdChainDataCompression, ct)
+		assert.Equal(t, receipts, dbm.ReadReceipts(hash, uint64(i)))
+		assert.Equal(t, body, dbm.ReadBody(hash, uint64(i)))
+	}
+
+	// The entries already compressed are not rewritten again
+	rewritten, err = dbm.RecompressChainData(0, uint64(len(hashes)), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, rewritten)
+
+	// Aborted by the quit channel
+	quit := make(chan struct{})
+	close(quit)
+	_, err = dbm.RecompressChainData(0, uint64(len(hashes)), quit)
+	assert.Error(t, err)
+}
+
+func TestDBManager_ChainDataCompressionStats(t *testing.T) {
+	dbm := NewMemoryDBManager()
+	body := &types.Body{Transactions: types.Transactions{}}
+	receipts := types.Receipts{genReceipt(111), genReceipt(222), genReceipt(333)}
+
+	// The receipts of the first block are written before enabling the compression
+	hashes := []common.Hash{hash1, hash2, hash3}
+	for i, hash := range hashes {
+		if i == 1 {
+			dbm.GetDBConfig().ChainDataCompression = SnappyChainDataCompression
+		}
+		dbm.WriteCanonicalHash(hash, uint64(i))
+		dbm.WriteBody(hash, uint64(i), body)
+		dbm.WriteReceipts(hash, uint64(i), receipts)
+	}
+
+	stats, err := dbm.ChainDataCompressionStats(0, 10, nil)
+	assert.NoError(t, err)
+	// The empty bodies are too small to be compressed
+	assert.Equal(t, uint64(len(hashes)+1), stats.Entries[NoChainDataCompression])
+	assert.Equal(t, uint64(2), stats.Entries[SnappyChainDataCompression])
+	assert.Equal(t, stats.StoredSize[NoChainDataCompression], stats.RawSize[NoChainDataCompression])
+	assert.True(t, stats.StoredSize[SnappyChainDataCompression] < stats.RawSize[SnappyChainDataCompression])
+	assert.Equal(t, uint64(0), stats.Entries[ZstdChainDataCompression])
+
+	quit := make(chan struct{})
+	close(quit)
+	_, err = dbm.ChainDataCompressionStats(0, 10, quit)
+	assert.Error(t, err)
+}
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/json"
+	"fmt"
 	"math/big"
 	"os"
 	"path/filepath"
@@ -58,6 +59,8 @@ type DBManager interface {
 	setStateTrieMigrationStatus(uint64)
 	GetMemDB() *MemDB
 	GetDBConfig() *DBConfig
+	Stat(dbEntry DBEntryType, shard int, property string) (string, error)
+	Compact(dbEntry DBEntryType, shard int, start, limit []byte) error
 	getDatabase(DBEntryType) Database
 	CreateMigrationDBAndSetStatus(blockNum uint64) error
 	FinishStateMigration(succeed bool) chan struct{}
@@ -110,12 +113,16 @@ type DBManager interface {
 	PutReceiptsToBatch(batch Batch, hash common.Hash, number uint64, receipts types.Receipts)
 	DeleteReceipts(hash common.Hash, number uint64)
 
+	RecompressChainData(from, to uint64, quit <-chan struct{}) (int, error)
+	ChainDataCompressionStats(from, to uint64, quit <-chan struct{}) (*ChainDataCompressionStats, error)
+
 	ReadBlock(hash common.Hash, number uint64) *types.Block
 	ReadBlockByHash(hash common.Hash) *types.Block
 	ReadBlockByNumber(number uint64) *types.Block
 	HasBlock(hash common.Hash, number uint64) bool
 	WriteBlock(block *types.Block)
 	DeleteBlock(hash common.Hash, number uint64)
+	DeleteBodyAndReceipts(hash common.Hash, number uint64)
 
 	FindCommonAncestor(a, b *types.Header) *types.Header
 
@@ -152,7 +159,18 @@ type DBManager interface {
 	WriteTxLookupEntries(block *types.Block)
 	WriteAndCacheTxLookupEntries(block *types.Block) error
 	PutTxLookupEntriesToBatch(batch Batch, block *types.Block)
+	DeleteTxLookupEntriesFromBatch(batch Batch, block *types.Block)
 	DeleteTxLookupEntry(hash common.Hash)
+	ReadTxIndexTail() (uint64, error)
+	WriteTxIndexTail(number uint64) error
+	ReadBodyTail() (uint64, error)
+	WriteBodyTail(number uint64) error
+
+	// Ancient store related functions
+	HasAncients() bool
+	AncientBlocks() uint64
+	FreezeBlocks(limit uint64) (uint64, error)
+	TruncateAncientBlocks(items uint64) error
 
 	ReadTxAndLookupInfo(hash common.Hash) (*types.Transaction, common.Hash, uint64, uint64)
 
@@ -223,12 +241,73 @@ type DBManager interface {
 	ReadStakingInfo(blockNum uint64) ([]byte, error)
 	WriteStakingInfo(blockNum uint64, stakingInfo []byte) error
 
+	// Supply checkpoint related functions
+	ReadSupplyCheckpoint(blockNum uint64) ([]byte, error)
+	WriteSupplyCheckpoint(blockNum uint64, checkpoint []byte) error
+	ReadLastSupplyCheckpointNumber() (uint64, error)
+	WriteLastSupplyCheckpointNumber(blockNum uint64) error
+
+	// Storage trie owner related functions
+	WriteStorageTrieOwners(roots map[common.Address]common.Hash) error
+	ReadStorageTrieOwners(root common.Hash) []common.Address
+
+	// Fee payer index related functions
+	NewFeePayerTxBatch() Batch
+	PutFeePayerTxToBatch(batch Batch, feePayer common.Address, blockNum uint64, txIndex uint32, entry []byte) error
+	IterateFeePayerTxs(feePayer common.Address, from uint64, fn func(blockNum uint64, txIndex uint32, entry []byte) bool)
+	ReadFeePayerIndexTail() (uint64, error)
+	WriteFeePayerIndexTail(blockNum uint64) error
+
+	// Balance history index related functions
+	NewBalanceChangeBatch() Batch
+	PutBalanceChangeToBatch(batch Batch, addr common.Address, blockNum uint64, entry []byte) error
+	IterateBalanceChanges(addr common.Address, from uint64, fn func(blockNum uint64, entry []byte) bool)
+	ReadBalanceIndexTail() (uint64, error)
+	WriteBalanceIndexTail(blockNum uint64) error
+
+	// Trace index related functions
+	NewTraceIndexBatch() Batch
+	PutTracedAddressToBatch(batch Batch, addr common.Address, blockNum uint64, blockHash common.Hash) error
+	IterateTracedBlocks(addr common.Address, from uint64, fn func(blockNum uint64, blockHash common.Hash) bool)
+	ReadTraceIndexTail() (uint64, error)
+	WriteTraceIndexTail(blockNum uint64) error
+	ReadTraceIndexHead() (uint64, error)
+	WriteTraceIndexHead(blockNum uint64) error
+
+	NewLogIndexBatch() Batch
+	PutLogAddressToBatch(batch Batch, addr common.Address, blockNum uint64, blockHash common.Hash) error
+	PutLogTopicToBatch(batch Batch, addr common.Address, topic common.Hash, blockNum uint64, blockHash common.Hash) error
+	IterateLogBlocks(addr common.Address, topic *common.Hash, from uint64, fn func(blockNum uint64, blockHash common.Hash) bool)
+	ReadLogIndexTail() (uint64, error)
+	WriteLogIndexTail(blockNum uint64) error
+	ReadLogIndexHead() (uint64, error)
+	WriteLogIndexHead(blockNum uint64) error
+
 	// DB migration related function
 	StartDBMigration(DBManager) error
 
 	// ChainDataFetcher checkpoint function
 	WriteChainDataFetcherCheckpoint(checkpoint uint64) error
 	ReadChainDataFetcherCheckpoint() (uint64, error)
+	WriteChainDataFetcherSinkCheckpoint(sink string, checkpoint uint64) error
+	ReadChainDataFetcherSinkCheckpoint(sink string) (uint64, error)
+
+	// Snapshot related functions
+	NewSnapshotDBBatch() Batch
+	NewSnapshotDBIterator(prefix []byte, start []byte) Iterator
+	ReadSnapshotRoot() common.Hash
+	PutSnapshotRootToBatch(batch Batch, root common.Hash) error
+	DeleteSnapshotRootFromBatch(batch Batch) error
+	ReadSnapshotGenerator() []byte
+	PutSnapshotGeneratorToBatch(batch Batch, generator []byte) error
+	ReadAccountSnapshot(hash common.Hash) []byte
+	PutAccountSnapshotToBatch(batch Batch, hash common.Hash, entry []byte) error
+	DeleteAccountSnapshotFromBatch(batch Batch, hash common.Hash) error
+	ReadStorageSnapshot(accountHash, storageHash common.Hash) []byte
+	PutStorageSnapshotToBatch(batch Batch, accountHash, storageHash common.Hash, entry []byte) error
+	DeleteStorageSnapshotFromBatch(batch Batch, accountHash, storageHash common.Hash) error
+	PutSnapSyncAccountToBatch(batch Batch, hash common.Hash, entry []byte) error
+	PutSnapSyncStorageToBatch(batch Batch, accountHash, storageHash common.Hash, entry []byte) error
 }
 
 type DBEntryType uint8
@@ -242,6 +321,7 @@ const (
 	StateTrieMigrationDB
 	TxLookUpEntryDB
 	bridgeServiceDB
+	SnapshotDB
 	// databaseEntryTypeSize should be the last item in this list!!
 	databaseEntryTypeSize
 )
@@ -250,6 +330,25 @@ func (et DBEntryType) String() string {
 	return dbBaseDirs[et]
 }
 
+// DBEntryTypes returns the entry types of all the databases.
+func DBEntryTypes() []DBEntryType {
+	types := make([]DBEntryType, databaseEntryTypeSize)
+	for i := range types {
+		types[i] = DBEntryType(i)
+	}
+	return types
+}
+
+// ParseDBEntryType returns the entry type of the database of the given name, e.g. "statetrie".
+func ParseDBEntryType(name string) (DBEntryType, error) {
+	for et, dir := range dbBaseDirs {
+		if dir == name {
+			return DBEntryType(et), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown database %q", name)
+}
+
 const notInMigrationFlag = 0
 const inMigrationFlag = 1
 
@@ -262,6 +361,7 @@ var dbBaseDirs = [databaseEntryTypeSize]string{
 	"statetrie_migrated", // "statetrie_migrated_#N" path will be used. (#N is a migrated block number.)
 	"txlookup",
 	"bridgeservice",
+	"snapshot",
 }
 
 // Sum of dbConfigRatio should be 100.
@@ -272,9 +372,10 @@ var dbConfigRatio = [databaseEntryTypeSize]int{
 	5,  // BodyDB
 	5,  // ReceiptsDB
 	40, // StateTrieDB
-	40, // StateTrieMigrationDB
+	37, // StateTrieMigrationDB
 	2,  // TXLookUpEntryDB
 	1,  // bridgeServiceDB
+	3,  // SnapshotDB
 }
 
 // checkDBEntryConfigRatio checks if sum of dbConfigRatio is 100.
@@ -320,6 +421,8 @@ type databaseManager struct {
 	lockInMigration      sync.RWMutex
 	inMigration          bool
 	migrationBlockNumber uint64
+
+	ancients *freezer // ancient store of the cold chain data, nil if not enabled
 }
 
 func NewMemoryDBManager() DBManager {
@@ -351,6 +454,13 @@ type DBConfig struct {
 	LevelDBCompression LevelDBCompressionType
 	LevelDBBufferPool  bool
 
+	// ChainDataCompression is applied to the block bodies and the receipts on writing.
+	ChainDataCompression ChainDataCompressionType
+
+	// AncientDir is the directory of the ancient store keeping the old blocks in flat files,
+	// relative to Dir if not absolute. The ancient store is not used if it is empty.
+	AncientDir string
+
 	// DynamoDB related configurations
 	DynamoDBConfig *DynamoDBConfig
 }
@@ -359,7 +469,7 @@ const dbMetricPrefix = "klay/db/chaindata/"
 
 // singleDatabaseDBManager returns DBManager which handles one single Database.
 // Each Database will share one common Database.
-func singleDatabaseDBManager(dbc *DBConfig) (DBManager, error) {
+func singleDatabaseDBManager(dbc *DBConfig) (*databaseManager, error) {
 	dbm := newDatabaseManager(dbc)
 	db, err := newDatabase(dbc, 0)
 	if err != nil {
@@ -465,6 +575,7 @@ func NewDBManager(dbc *DBConfig) DBManager {
 		if dbm, err := singleDatabaseDBManager(dbc); err != nil {
 			logger.Crit("Failed to create a single database", "DBType", dbc.DBType, "err", err)
 		} else {
+			dbm.openAncients()
 			return dbm
 		}
 	} else {
@@ -483,6 +594,7 @@ func NewDBManager(dbc *DBConfig) DBManager {
 				dbm.migrationBlockNumber = migrationBlockNum
 			}
 		}
+		dbm.openAncients()
 		return dbm
 	}
 	logger.Crit("Must not reach here!")
@@ -771,7 +883,59 @@ func (dbm *databaseManager) getDatabase(dbEntryType DBEntryType) Database {
 	}
 }
 
+// databaseOrShard returns the database of the given entry type, or its shard if shard is not negative.
+func (dbm *databaseManager) databaseOrShard(dbEntry DBEntryType, shard int) (Database, error) {
+	db := dbm.getDatabase(dbEntry)
+	if db == nil {
+		return nil, fmt.Errorf("%s database is not open", dbEntry)
+	}
+	if shard < 0 {
+		return db, nil
+	}
+	sdb, ok := db.(*shardedDB)
+	if !ok {
+		return nil, fmt.Errorf("%s database is not sharded", dbEntry)
+	}
+	if shard >= len(sdb.shards) {
+		return nil, fmt.Errorf("%s database has %d shards", dbEntry, len(sdb.shards))
+	}
+	return sdb.shards[shard], nil
+}
+
+// Stat returns the given property of the database of the given entry type, or of its shard if shard
+// is not negative. The properties depend on the type of the database, e.g. "leveldb.stats".
+func (dbm *databaseManager) Stat(dbEntry DBEntryType, shard int, property string) (string, error) {
+	db, err := dbm.databaseOrShard(dbEntry, shard)
+	if err != nil {
+		return "", err
+	}
+	stater, ok := db.(Stater)
+	if !ok {
+		return "", fmt.Errorf("%s database does not support stats", db.Type())
+	}
+	return stater.Stat(property)
+}
+
+// Compact compacts the keys in [start, limit) of the database of the given entry type, or of its shard
+// if shard is not negative.
+func (dbm *databaseManager) Compact(dbEntry DBEntryType, shard int, start, limit []byte) error {
+	db, err := dbm.databaseOrShard(dbEntry, shard)
+	if err != nil {
+		return err
+	}
+	compacter, ok := db.(Compacter)
+	if !ok {
+		return fmt.Errorf("%s database does not support compaction", db.Type())
+	}
+	return compacter.Compact(start, limit)
+}
+
 func (dbm *databaseManager) Close() {
+	if dbm.ancients != nil {
+		if err := dbm.ancients.Close(); err != nil {
+			logger.Error("Failed to close the ancient store", "err", err)
+		}
+	}
 	// If single DB, only close the first database.
 	if dbm.config.SingleDB {
 		dbm.dbs[0].Close()
@@ -796,6 +960,9 @@ func (dbm *databaseManager) ReadCanonicalHash(number uint64) common.Hash {
 
 	db := dbm.getDatabase(headerDB)
 	data, _ := db.Get(headerHashKey(number))
+	if len(data) == 0 {
+		data = dbm.readAncient(freezerHashTable, number)
+	}
 	if len(data) == 0 {
 		return common.Hash{}
 	}
@@ -909,7 +1076,7 @@ func (dbm *databaseManager) HasHeader(hash common.Hash, number uint64) bool {
 
 	db := dbm.getDatabase(headerDB)
 	if has, err := db.Has(headerKey(number, hash)); !has || err != nil {
-		return false
+		return dbm.isAncient(hash, number)
 	}
 	return true
 }
@@ -939,6 +1106,9 @@ func (dbm *databaseManager) ReadHeader(hash common.Hash, number uint64) *types.H
 func (dbm *databaseManager) ReadHeaderRLP(hash common.Hash, number uint64) rlp.RawValue {
 	db := dbm.getDatabase(headerDB)
 	data, _ := db.Get(headerKey(number, hash))
+	if len(data) == 0 {
+		data = dbm.readAncientOfBlock(freezerHeaderTable, hash, number)
+	}
 	return data
 }
 
@@ -1010,7 +1180,7 @@ func (dbm *databaseManager) ReadHeaderNumber(hash common.Hash) *uint64 {
 func (dbm *databaseManager) HasBody(hash common.Hash, number uint64) bool {
 	db := dbm.getDatabase(BodyDB)
 	if has, err := db.Has(blockBodyKey(number, hash)); !has || err != nil {
-		return false
+		return len(dbm.readAncientOfBlock(freezerBodiesTable, hash, number)) > 0
 	}
 	return true
 }
@@ -1060,6 +1230,10 @@ func (dbm *databaseManager) ReadBodyRLP(hash common.Hash, number uint64) rlp.Raw
 	// not found in cache, find body in database
 	db := dbm.getDatabase(BodyDB)
 	data, _ := db.Get(blockBodyKey(number, hash))
+	data = dbm.decodeChainData(data)
+	if len(data) == 0 {
+		data = dbm.readAncientOfBlock(freezerBodiesTable, hash, number)
+	}
 
 	// Write to cache at the end of successful read.
 	dbm.cm.writeBodyRLPCache(hash, data)
@@ -1089,6 +1263,10 @@ func (dbm *databaseManager) ReadBodyRLPByHash(hash common.Hash) rlp.RawValue {
 
 	db := dbm.getDatabase(BodyDB)
 	data, _ := db.Get(blockBodyKey(*number, hash))
+	data = dbm.decodeChainData(data)
+	if len(data) == 0 {
+		data = dbm.readAncientOfBlock(freezerBodiesTable, hash, *number)
+	}
 
 	// Write to cache at the end of successful read.
 	dbm.cm.writeBodyRLPCache(hash, data)
@@ -1116,7 +1294,7 @@ func (dbm *databaseManager) PutBodyToBatch(batch Batch, hash common.Hash, number
 		logger.Crit("Failed to RLP encode body", "err", err)
 	}
 
-	if err := batch.Put(blockBodyKey(number, hash), data); err != nil {
+	if err := batch.Put(blockBodyKey(number, hash), dbm.encodeChainData(data)); err != nil {
 		logger.Crit("Failed to store block body", "err", err)
 	}
 }
@@ -1126,7 +1304,7 @@ func (dbm *databaseManager) WriteBodyRLP(hash common.Hash, number uint64, rlp rl
 	dbm.cm.writeBodyRLPCache(hash, rlp)
 
 	db := dbm.getDatabase(BodyDB)
-	if err := db.Put(blockBodyKey(number, hash), rlp); err != nil {
+	if err := db.Put(blockBodyKey(number, hash), dbm.encodeChainData(rlp)); err != nil {
 		logger.Crit("Failed to store block body", "err", err)
 	}
 }
@@ -1149,6 +1327,9 @@ func (dbm *databaseManager) ReadTd(hash common.Hash, number uint64) *big.Int {
 
 	db := dbm.getDatabase(MiscDB)
 	data, _ := db.Get(headerTDKey(number, hash))
+	if len(data) == 0 {
+		data = dbm.readAncientOfBlock(freezerTdTable, hash, number)
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -1208,6 +1389,10 @@ func (dbm *databaseManager) ReadReceipts(blockHash common.Hash, number uint64) t
 	db := dbm.getDatabase(ReceiptsDB)
 	// Retrieve the flattened receipt slice
 	data, _ := db.Get(blockReceiptsKey(number, blockHash))
+	data = dbm.decodeChainData(data)
+	if len(data) == 0 {
+		data = dbm.readAncientOfBlock(freezerReceiptTable, blockHash, number)
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -1265,7 +1450,7 @@ func (dbm *databaseManager) putReceiptsToPutter(putter KeyValueWriter, hash comm
 		logger.Crit("Failed to encode block receipts", "err", err)
 	}
 	// Store the flattened receipt slice
-	if err := putter.Put(blockReceiptsKey(number, hash), bytes); err != nil {
+	if err := putter.Put(blockReceiptsKey(number, hash), dbm.encodeChainData(bytes)); err != nil {
 		logger.Crit("Failed to store block receipts", "err", err)
 	}
 }
@@ -1375,6 +1560,13 @@ func (dbm *databaseManager) DeleteBlock(hash common.Hash, number uint64) {
 	dbm.cm.deleteBlockCache(hash)
 }
 
+// DeleteBodyAndReceipts removes the body and the receipts of a block, keeping its header.
+func (dbm *databaseManager) DeleteBodyAndReceipts(hash common.Hash, number uint64) {
+	dbm.DeleteReceipts(hash, number)
+	dbm.DeleteBody(hash, number)
+	dbm.cm.deleteBlockCache(hash)
+}
+
 // Find Common Ancestor operation
 // FindCommonAncestor returns the last common ancestor of two block headers
 func (dbm *databaseManager) FindCommonAncestor(a, b *types.Header) *types.Header {
@@ -1643,6 +1835,40 @@ func (dbm *databaseManager) DeleteTxLookupEntry(hash common.Hash) {
 	db.Delete(TxLookupKey(hash))
 }
 
+// DeleteTxLookupEntriesFromBatch removes the positional metadata of every transaction
+// of a block by the given batch.
+func (dbm *databaseManager) DeleteTxLookupEntriesFromBatch(batch Batch, block *types.Block) {
+	for _, tx := range block.Transactions() {
+		if err := batch.Delete(TxLookupKey(tx.Hash())); err != nil {
+			logger.Crit("Failed to delete transaction lookup entry", "err", err)
+		}
+	}
+}
+
+// ReadTxIndexTail returns the number of the oldest block whose transactions are indexed.
+// If the tail does not exist, 0 is returned since all the blocks have been indexed.
+func (dbm *databaseManager) ReadTxIndexTail() (uint64, error) {
+	return dbm.readCheckpoint(txIndexTailKey)
+}
+
+// WriteTxIndexTail stores the number of the oldest block whose transactions are indexed.
+func (dbm *databaseManager) WriteTxIndexTail(number uint64) error {
+	db := dbm.getDatabase(MiscDB)
+	return db.Put(txIndexTailKey, common.Int64ToByteBigEndian(number))
+}
+
+// ReadBodyTail returns the number of the oldest block whose body and receipts are kept.
+// If the tail does not exist, 0 is returned since no block has been pruned.
+func (dbm *databaseManager) ReadBodyTail() (uint64, error) {
+	return dbm.readCheckpoint(bodyTailKey)
+}
+
+// WriteBodyTail stores the number of the oldest block whose body and receipts are kept.
+func (dbm *databaseManager) WriteBodyTail(number uint64) error {
+	db := dbm.getDatabase(MiscDB)
+	return db.Put(bodyTailKey, common.Int64ToByteBigEndian(number))
+}
+
 // ReadTxAndLookupInfo retrieves a specific transaction from the database, along with
 // its added positional metadata.
 func (dbm *databaseManager) ReadTxAndLookupInfo(hash common.Hash) (*types.Transaction, common.Hash, uint64, uint64) {
@@ -2105,8 +2331,24 @@ func (dbm *databaseManager) WriteChainDataFetcherCheckpoint(checkpoint uint64) e
 }
 
 func (dbm *databaseManager) ReadChainDataFetcherCheckpoint() (uint64, error) {
+	return dbm.readCheckpoint(chaindatafetcherCheckpointKey)
+}
+
+// WriteChainDataFetcherSinkCheckpoint stores the checkpoint of the given chaindatafetcher sink.
+func (dbm *databaseManager) WriteChainDataFetcherSinkCheckpoint(sink string, checkpoint uint64) error {
+	db := dbm.getDatabase(MiscDB)
+	return db.Put(chaindatafetcherSinkCheckpointKey(sink), common.Int64ToByteBigEndian(checkpoint))
+}
+
+// ReadChainDataFetcherSinkCheckpoint retrieves the checkpoint of the given chaindatafetcher sink.
+// If the checkpoint does not exist, 0 is returned.
+func (dbm *databaseManager) ReadChainDataFetcherSinkCheckpoint(sink string) (uint64, error) {
+	return dbm.readCheckpoint(chaindatafetcherSinkCheckpointKey(sink))
+}
+
+func (dbm *databaseManager) readCheckpoint(key []byte) (uint64, error) {
 	db := dbm.getDatabase(MiscDB)
-	data, err := db.Get(chaindatafetcherCheckpointKey)
+	data, err := db.Get(key)
 	if err != nil {
 		// if the key is not in the database, 0 is returned as the checkpoint
 		if err == leveldb.ErrNotFound || err == badger.ErrKeyNotFound ||
@@ -0,0 +1,177 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"github.com/klaytn/klaytn/common"
+)
+
+// openAncients opens the ancient store if it is configured.
+func (dbm *databaseManager) openAncients() {
+	dir := dbm.config.AncientDir
+	if dir == "" || dbm.config.DBType == MemoryDB {
+		return
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(dbm.config.Dir, dir)
+	}
+	ancients, err := newFreezer(dir)
+	if err != nil {
+		logger.Crit("Failed to open the ancient store", "dir", dir, "err", err)
+	}
+	dbm.ancients = ancients
+}
+
+// readAncient returns the item of the given kind of the frozen block, or nil if
+// the block is not frozen.
+func (dbm *databaseManager) readAncient(kind string, number uint64) []byte {
+	if dbm.ancients == nil {
+		return nil
+	}
+	data, err := dbm.ancients.Ancient(kind, number)
+	if err != nil {
+		if err != errOutOfBounds {
+			logger.Error("Failed to read the ancient store", "kind", kind, "number", number, "err", err)
+		}
+		return nil
+	}
+	return data
+}
+
+// isAncient returns true if the canonical block of the given hash and number is frozen.
+func (dbm *databaseManager) isAncient(hash common.Hash, number uint64) bool {
+	return bytes.Equal(dbm.readAncient(freezerHashTable, number), hash[:])
+}
+
+// readAncientOfBlock returns the item of the given kind of the block if it is frozen.
+func (dbm *databaseManager) readAncientOfBlock(kind string, hash common.Hash, number uint64) []byte {
+	if !dbm.isAncient(hash, number) {
+		return nil
+	}
+	return dbm.readAncient(kind, number)
+}
+
+// HasAncients returns true if the ancient store is enabled.
+func (dbm *databaseManager) HasAncients() bool {
+	return dbm.ancients != nil
+}
+
+// AncientBlocks returns the number of the blocks from the genesis which are moved
+// to the ancient store, or 0 if the ancient store is not enabled.
+func (dbm *databaseManager) AncientBlocks() uint64 {
+	if dbm.ancients == nil {
+		return 0
+	}
+	return dbm.ancients.Frozen()
+}
+
+// FreezeBlocks moves the canonical hashes, the headers, the bodies, the receipts
+// and the total blockscores of the canonical blocks before the given limit from
+// the key-value database to the ancient store, and returns the number of the
+// blocks frozen. The hash to number mappings and the transaction lookup entries
+// are kept in the key-value database, so the frozen blocks are still found by
+// their hashes. The bodies and the receipts which have been pruned are frozen as
+// empty, remaining unavailable.
+func (dbm *databaseManager) FreezeBlocks(limit uint64) (uint64, error) {
+	if dbm.ancients == nil {
+		return 0, errAncientNotEnabled
+	}
+	var (
+		frozen = dbm.ancients.Frozen()
+		hashes []common.Hash
+		err    error
+	)
+	for number := frozen; number < limit; number++ {
+		hash := dbm.ReadCanonicalHash(number)
+		if hash == (common.Hash{}) {
+			err = fmt.Errorf("canonical hash of block %d is not found", number)
+			break
+		}
+		header := dbm.ReadHeaderRLP(hash, number)
+		if len(header) == 0 {
+			err = fmt.Errorf("header of block %d is not found", number)
+			break
+		}
+		body, _ := dbm.getDatabase(BodyDB).Get(blockBodyKey(number, hash))
+		receipts, _ := dbm.getDatabase(ReceiptsDB).Get(blockReceiptsKey(number, hash))
+		td, _ := dbm.getDatabase(MiscDB).Get(headerTDKey(number, hash))
+
+		if err = dbm.ancients.AppendAncient(number, hash[:], header, dbm.decodeChainData(body), dbm.decodeChainData(receipts), td); err != nil {
+			break
+		}
+		hashes = append(hashes, hash)
+	}
+	if len(hashes) == 0 {
+		return frozen, err
+	}
+	if err := dbm.ancients.Sync(); err != nil {
+		return frozen, err
+	}
+	// The frozen blocks are removed from the key-value database only after they are flushed
+	if derr := dbm.deleteFrozenBlocks(frozen, hashes); derr != nil {
+		err = derr
+	}
+	return dbm.ancients.Frozen(), err
+}
+
+// deleteFrozenBlocks removes the data of the frozen blocks from the number from
+// the key-value database, except the hash to number mappings.
+func (dbm *databaseManager) deleteFrozenBlocks(from uint64, hashes []common.Hash) error {
+	var (
+		headerBatch   = dbm.getDatabase(headerDB).NewBatch()
+		bodyBatch     = dbm.getDatabase(BodyDB).NewBatch()
+		receiptsBatch = dbm.getDatabase(ReceiptsDB).NewBatch()
+		miscBatch     = dbm.getDatabase(MiscDB).NewBatch()
+	)
+	for i, hash := range hashes {
+		number := from + uint64(i)
+		if err := headerBatch.Delete(headerHashKey(number)); err != nil {
+			return err
+		}
+		if err := headerBatch.Delete(headerKey(number, hash)); err != nil {
+			return err
+		}
+		if err := bodyBatch.Delete(blockBodyKey(number, hash)); err != nil {
+			return err
+		}
+		if err := receiptsBatch.Delete(blockReceiptsKey(number, hash)); err != nil {
+			return err
+		}
+		if err := miscBatch.Delete(headerTDKey(number, hash)); err != nil {
+			return err
+		}
+	}
+	for _, batch := range []Batch{headerBatch, bodyBatch, receiptsBatch, miscBatch} {
+		if err := batch.Write(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TruncateAncientBlocks discards the frozen blocks from the given number on,
+// which is used to rewind the chain.
+func (dbm *databaseManager) TruncateAncientBlocks(items uint64) error {
+	if dbm.ancients == nil {
+		return nil
+	}
+	return dbm.ancients.TruncateAncients(items)
+}
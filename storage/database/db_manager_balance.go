@@ -0,0 +1,67 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"encoding/binary"
+
+	"github.com/klaytn/klaytn/common"
+)
+
+// NewBalanceChangeBatch returns a batch to write the balance history index.
+// Balance history index is stored in MiscDB.
+func (dbm *databaseManager) NewBalanceChangeBatch() Batch {
+	return dbm.NewBatch(MiscDB)
+}
+
+// PutBalanceChangeToBatch puts the entry of a balance change of the given account in a block to the batch.
+// The entry is the marshaled balance change defined in node/cn/balance_history.go.
+func (dbm *databaseManager) PutBalanceChangeToBatch(batch Batch, addr common.Address, blockNum uint64, entry []byte) error {
+	return batch.Put(balanceChangeKey(addr, blockNum), entry)
+}
+
+// IterateBalanceChanges calls fn with the entries of the balance changes of the given account
+// in the order of the block numbers, starting from the given block number.
+// The iteration stops if fn returns false.
+func (dbm *databaseManager) IterateBalanceChanges(addr common.Address, from uint64, fn func(blockNum uint64, entry []byte) bool) {
+	db := dbm.getDatabase(MiscDB)
+	prefix := append(append([]byte{}, balanceChangePrefix...), addr.Bytes()...)
+	it := db.NewIterator(prefix, common.Int64ToByteBigEndian(from))
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+8 {
+			continue
+		}
+		if !fn(binary.BigEndian.Uint64(key[len(prefix):]), common.CopyBytes(it.Value())) {
+			return
+		}
+	}
+}
+
+// ReadBalanceIndexTail returns the first block number indexed by the balance history index.
+// If the balance history index has never been enabled, 0 is returned.
+func (dbm *databaseManager) ReadBalanceIndexTail() (uint64, error) {
+	return dbm.readCheckpoint(balanceIndexTailKey)
+}
+
+// WriteBalanceIndexTail stores the first block number indexed by the balance history index.
+func (dbm *databaseManager) WriteBalanceIndexTail(blockNum uint64) error {
+	db := dbm.getDatabase(MiscDB)
+	return db.Put(balanceIndexTailKey, common.Int64ToByteBigEndian(blockNum))
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"encoding/binary"
+
+	"github.com/klaytn/klaytn/common"
+)
+
+// NewFeePayerTxBatch returns a batch to write the fee payer index.
+// Fee payer index is stored in MiscDB.
+func (dbm *databaseManager) NewFeePayerTxBatch() Batch {
+	return dbm.NewBatch(MiscDB)
+}
+
+// PutFeePayerTxToBatch puts the entry of a transaction paid by the given fee payer to the batch.
+// The entry is the marshaled fee payer transaction defined in node/cn/fee_payer_stats.go.
+func (dbm *databaseManager) PutFeePayerTxToBatch(batch Batch, feePayer common.Address, blockNum uint64, txIndex uint32, entry []byte) error {
+	return batch.Put(feePayerTxKey(feePayer, blockNum, txIndex), entry)
+}
+
+// IterateFeePayerTxs calls fn with the entries of the transactions paid by the given fee payer
+// in the order of their positions in the chain, starting from the given block number.
+// The iteration stops if fn returns false.
+func (dbm *databaseManager) IterateFeePayerTxs(feePayer common.Address, from uint64, fn func(blockNum uint64, txIndex uint32, entry []byte) bool) {
+	db := dbm.getDatabase(MiscDB)
+	prefix := append(append([]byte{}, feePayerTxPrefix...), feePayer.Bytes()...)
+	it := db.NewIterator(prefix, common.Int64ToByteBigEndian(from))
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+8+4 {
+			continue
+		}
+		blockNum := binary.BigEndian.Uint64(key[len(prefix):])
+		txIndex := binary.BigEndian.Uint32(key[len(prefix)+8:])
+		if !fn(blockNum, txIndex, common.CopyBytes(it.Value())) {
+			return
+		}
+	}
+}
+
+// ReadFeePayerIndexTail returns the first block number indexed by the fee payer index.
+// If the fee payer index has never been enabled, 0 is returned.
+func (dbm *databaseManager) ReadFeePayerIndexTail() (uint64, error) {
+	return dbm.readCheckpoint(feePayerIndexTailKey)
+}
+
+// WriteFeePayerIndexTail stores the first block number indexed by the fee payer index.
+func (dbm *databaseManager) WriteFeePayerIndexTail(blockNum uint64) error {
+	db := dbm.getDatabase(MiscDB)
+	return db.Put(feePayerIndexTailKey, common.Int64ToByteBigEndian(blockNum))
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"encoding/binary"
+
+	"github.com/klaytn/klaytn/common"
+)
+
+// NewLogIndexBatch returns a batch to write the log index.
+// Log index is stored in MiscDB.
+func (dbm *databaseManager) NewLogIndexBatch() Batch {
+	return dbm.NewBatch(MiscDB)
+}
+
+// PutLogAddressToBatch puts the entry of an address emitting logs in a block to the batch.
+func (dbm *databaseManager) PutLogAddressToBatch(batch Batch, addr common.Address, blockNum uint64, blockHash common.Hash) error {
+	return batch.Put(logAddressKey(addr, blockNum), blockHash.Bytes())
+}
+
+// PutLogTopicToBatch puts the entry of an address emitting logs with the given first topic in a block to the batch.
+func (dbm *databaseManager) PutLogTopicToBatch(batch Batch, addr common.Address, topic common.Hash, blockNum uint64, blockHash common.Hash) error {
+	return batch.Put(logTopicKey(addr, topic, blockNum), blockHash.Bytes())
+}
+
+// IterateLogBlocks calls fn with the blocks containing the logs emitted by the given address,
+// and with the given first topic if it is not nil, in the order of the block numbers, starting
+// from the given block number. The iteration stops if fn returns false.
+func (dbm *databaseManager) IterateLogBlocks(addr common.Address, topic *common.Hash, from uint64, fn func(blockNum uint64, blockHash common.Hash) bool) {
+	db := dbm.getDatabase(MiscDB)
+	prefix := append(append([]byte{}, logAddressPrefix...), addr.Bytes()...)
+	if topic != nil {
+		prefix = append(append(append([]byte{}, logTopicPrefix...), addr.Bytes()...), topic.Bytes()...)
+	}
+	it := db.NewIterator(prefix, common.Int64ToByteBigEndian(from))
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+8 {
+			continue
+		}
+		if !fn(binary.BigEndian.Uint64(key[len(prefix):]), common.BytesToHash(it.Value())) {
+			return
+		}
+	}
+}
+
+// ReadLogIndexTail returns the first block number indexed by the log index.
+// If the log index has never been enabled, 0 is returned.
+func (dbm *databaseManager) ReadLogIndexTail() (uint64, error) {
+	return dbm.readCheckpoint(logIndexTailKey)
+}
+
+// WriteLogIndexTail stores the first block number indexed by the log index.
+func (dbm *databaseManager) WriteLogIndexTail(blockNum uint64) error {
+	db := dbm.getDatabase(MiscDB)
+	return db.Put(logIndexTailKey, common.Int64ToByteBigEndian(blockNum))
+}
+
+// ReadLogIndexHead returns the last block number indexed by the log index.
+func (dbm *databaseManager) ReadLogIndexHead() (uint64, error) {
+	return dbm.readCheckpoint(logIndexHeadKey)
+}
+
+// WriteLogIndexHead stores the last block number indexed by the log index.
+func (dbm *databaseManager) WriteLogIndexHead(blockNum uint64) error {
+	db := dbm.getDatabase(MiscDB)
+	return db.Put(logIndexHeadKey, common.Int64ToByteBigEndian(blockNum))
+}
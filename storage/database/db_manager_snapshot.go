@@ -0,0 +1,114 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"github.com/klaytn/klaytn/common"
+)
+
+// NewSnapshotDBBatch returns a batch to write the state snapshot.
+// The state snapshot is stored in SnapshotDB.
+func (dbm *databaseManager) NewSnapshotDBBatch() Batch {
+	return dbm.NewBatch(SnapshotDB)
+}
+
+// NewSnapshotDBIterator returns an iterator over the entries of the state snapshot
+// with the given prefix, starting at the given key.
+func (dbm *databaseManager) NewSnapshotDBIterator(prefix []byte, start []byte) Iterator {
+	return dbm.getDatabase(SnapshotDB).NewIterator(prefix, start)
+}
+
+// ReadSnapshotRoot returns the state root of the disk layer of the state snapshot.
+// An empty hash is returned if the snapshot does not exist.
+func (dbm *databaseManager) ReadSnapshotRoot() common.Hash {
+	db := dbm.getDatabase(SnapshotDB)
+	data, _ := db.Get(snapshotRootKey)
+	if len(data) != common.HashLength {
+		return common.Hash{}
+	}
+	return common.BytesToHash(data)
+}
+
+// PutSnapshotRootToBatch puts the state root of the disk layer of the state snapshot to the batch.
+func (dbm *databaseManager) PutSnapshotRootToBatch(batch Batch, root common.Hash) error {
+	return batch.Put(snapshotRootKey, root.Bytes())
+}
+
+// DeleteSnapshotRootFromBatch puts the deletion of the state root of the state snapshot
+// to the batch, which invalidates the snapshot.
+func (dbm *databaseManager) DeleteSnapshotRootFromBatch(batch Batch) error {
+	return batch.Delete(snapshotRootKey)
+}
+
+// ReadSnapshotGenerator returns the encoded progress of the state snapshot generation.
+func (dbm *databaseManager) ReadSnapshotGenerator() []byte {
+	db := dbm.getDatabase(SnapshotDB)
+	data, _ := db.Get(snapshotGeneratorKey)
+	return data
+}
+
+// PutSnapshotGeneratorToBatch puts the encoded progress of the state snapshot generation to the batch.
+func (dbm *databaseManager) PutSnapshotGeneratorToBatch(batch Batch, generator []byte) error {
+	return batch.Put(snapshotGeneratorKey, generator)
+}
+
+// ReadAccountSnapshot returns the account trie value of the given account hash in the state snapshot.
+func (dbm *databaseManager) ReadAccountSnapshot(hash common.Hash) []byte {
+	db := dbm.getDatabase(SnapshotDB)
+	data, _ := db.Get(accountSnapshotKey(hash))
+	return data
+}
+
+// PutAccountSnapshotToBatch puts the account trie value of the given account hash to the batch.
+func (dbm *databaseManager) PutAccountSnapshotToBatch(batch Batch, hash common.Hash, entry []byte) error {
+	return batch.Put(accountSnapshotKey(hash), entry)
+}
+
+// DeleteAccountSnapshotFromBatch puts the deletion of the account of the given account hash to the batch.
+func (dbm *databaseManager) DeleteAccountSnapshotFromBatch(batch Batch, hash common.Hash) error {
+	return batch.Delete(accountSnapshotKey(hash))
+}
+
+// ReadStorageSnapshot returns the storage trie value of the given storage slot hash
+// of the account in the state snapshot.
+func (dbm *databaseManager) ReadStorageSnapshot(accountHash, storageHash common.Hash) []byte {
+	db := dbm.getDatabase(SnapshotDB)
+	data, _ := db.Get(storageSnapshotKey(accountHash, storageHash))
+	return data
+}
+
+// PutStorageSnapshotToBatch puts the storage trie value of the given storage slot hash
+// of the account to the batch.
+func (dbm *databaseManager) PutStorageSnapshotToBatch(batch Batch, accountHash, storageHash common.Hash, entry []byte) error {
+	return batch.Put(storageSnapshotKey(accountHash, storageHash), entry)
+}
+
+// DeleteStorageSnapshotFromBatch puts the deletion of the given storage slot of the account to the batch.
+func (dbm *databaseManager) DeleteStorageSnapshotFromBatch(batch Batch, accountHash, storageHash common.Hash) error {
+	return batch.Delete(storageSnapshotKey(accountHash, storageHash))
+}
+
+// PutSnapSyncAccountToBatch puts the account trie value fetched by snap sync to the batch.
+// The fetched values are staged apart from the state snapshot until the state tries are built.
+func (dbm *databaseManager) PutSnapSyncAccountToBatch(batch Batch, hash common.Hash, entry []byte) error {
+	return batch.Put(snapSyncAccountKey(hash), entry)
+}
+
+// PutSnapSyncStorageToBatch puts the storage trie value fetched by snap sync to the batch.
+func (dbm *databaseManager) PutSnapSyncStorageToBatch(batch Batch, accountHash, storageHash common.Hash, entry []byte) error {
+	return batch.Put(snapSyncStorageKey(accountHash, storageHash), entry)
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import "github.com/klaytn/klaytn/common"
+
+// WriteStorageTrieOwners writes the contract accounts owning the given storage trie roots.
+// Storage trie owners are stored in MiscDB.
+func (dbm *databaseManager) WriteStorageTrieOwners(roots map[common.Address]common.Hash) error {
+	db := dbm.getDatabase(MiscDB)
+	batch := db.NewBatch()
+	for addr, root := range roots {
+		if err := batch.Put(storageTrieOwnerKey(root, addr), []byte{}); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// ReadStorageTrieOwners returns the contract accounts whose storage tries have had the given root.
+// A root can be owned by multiple contracts if their storages are the same.
+func (dbm *databaseManager) ReadStorageTrieOwners(root common.Hash) []common.Address {
+	db := dbm.getDatabase(MiscDB)
+	prefix := append(storageTrieOwnerPrefix, root.Bytes()...)
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	var owners []common.Address
+	for it.Next() {
+		if key := it.Key(); len(key) == len(prefix)+common.AddressLength {
+			owners = append(owners, common.BytesToAddress(key[len(prefix):]))
+		}
+	}
+	return owners
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import "github.com/klaytn/klaytn/common"
+
+// ReadSupplyCheckpoint reads the supply checkpoint of the given block number from database.
+// The checkpoint is the marshaled SupplyCheckpoint defined in reward/supply_tracker.go.
+// Supply checkpoints are stored in MiscDB.
+func (dbm *databaseManager) ReadSupplyCheckpoint(blockNum uint64) ([]byte, error) {
+	db := dbm.getDatabase(MiscDB)
+	return db.Get(makeKey(supplyCheckpointPrefix, blockNum))
+}
+
+// WriteSupplyCheckpoint writes the supply checkpoint of the given block number to database.
+func (dbm *databaseManager) WriteSupplyCheckpoint(blockNum uint64, checkpoint []byte) error {
+	db := dbm.getDatabase(MiscDB)
+	return db.Put(makeKey(supplyCheckpointPrefix, blockNum), checkpoint)
+}
+
+// ReadLastSupplyCheckpointNumber returns the block number of the last supply checkpoint.
+// If there is no supply checkpoint, 0 is returned.
+func (dbm *databaseManager) ReadLastSupplyCheckpointNumber() (uint64, error) {
+	return dbm.readCheckpoint(lastSupplyCheckpointKey)
+}
+
+// WriteLastSupplyCheckpointNumber stores the block number of the last supply checkpoint.
+func (dbm *databaseManager) WriteLastSupplyCheckpointNumber(blockNum uint64) error {
+	db := dbm.getDatabase(MiscDB)
+	return db.Put(lastSupplyCheckpointKey, common.Int64ToByteBigEndian(blockNum))
+}
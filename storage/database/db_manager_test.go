@@ -1014,3 +1014,47 @@ func getFilesInDir(t *testing.T, dirPath string, substr string) []string {
 
 	return dirNames
 }
+
+func TestDBManager_StatAndCompact(t *testing.T) {
+	for i, dbm := range dbManagers {
+		dbc := dbConfigs[i]
+		sharded := !dbc.SingleDB && dbc.NumStateTrieShards > 1 && dbc.DBType != MemoryDB
+
+		_, err := dbm.Stat(StateTrieDB, -1, "leveldb.stats")
+		if dbc.DBType == LevelDB {
+			assert.NoError(t, err)
+			_, err = dbm.Stat(StateTrieDB, -1, "leveldb.unknown")
+			assert.Error(t, err)
+			assert.NoError(t, dbm.Compact(StateTrieDB, -1, nil, nil))
+		} else {
+			assert.Error(t, err)
+		}
+
+		// A shard can be inspected and compacted only in a sharded database
+		last := int(dbc.NumStateTrieShards) - 1
+		if sharded && dbc.DBType == LevelDB {
+			stat, err := dbm.Stat(StateTrieDB, -1, "leveldb.stats")
+			assert.NoError(t, err)
+			assert.Contains(t, stat, "shard "+strconv.Itoa(last)+":")
+			_, err = dbm.Stat(StateTrieDB, last, "leveldb.stats")
+			assert.NoError(t, err)
+			assert.NoError(t, dbm.Compact(StateTrieDB, last, []byte{0x00}, []byte{0x80}))
+		}
+		if sharded {
+			_, err = dbm.Stat(StateTrieDB, last+1, "leveldb.stats")
+			assert.Error(t, err)
+		} else {
+			assert.Error(t, dbm.Compact(StateTrieDB, 0, nil, nil))
+		}
+	}
+}
+
+func TestParseDBEntryType(t *testing.T) {
+	for _, et := range DBEntryTypes() {
+		parsed, err := ParseDBEntryType(et.String())
+		assert.NoError(t, err)
+		assert.Equal(t, et, parsed)
+	}
+	_, err := ParseDBEntryType("unknown")
+	assert.Error(t, err)
+}
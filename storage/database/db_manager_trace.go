@@ -0,0 +1,77 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"encoding/binary"
+
+	"github.com/klaytn/klaytn/common"
+)
+
+// NewTraceIndexBatch returns a batch to write the trace index.
+// Trace index is stored in MiscDB.
+func (dbm *databaseManager) NewTraceIndexBatch() Batch {
+	return dbm.NewBatch(MiscDB)
+}
+
+// PutTracedAddressToBatch puts the entry of an account appearing in the call traces of a block to the batch.
+func (dbm *databaseManager) PutTracedAddressToBatch(batch Batch, addr common.Address, blockNum uint64, blockHash common.Hash) error {
+	return batch.Put(traceAddressKey(addr, blockNum), blockHash.Bytes())
+}
+
+// IterateTracedBlocks calls fn with the blocks whose call traces contain the given account
+// in the order of the block numbers, starting from the given block number.
+// The iteration stops if fn returns false.
+func (dbm *databaseManager) IterateTracedBlocks(addr common.Address, from uint64, fn func(blockNum uint64, blockHash common.Hash) bool) {
+	db := dbm.getDatabase(MiscDB)
+	prefix := append(append([]byte{}, traceAddressPrefix...), addr.Bytes()...)
+	it := db.NewIterator(prefix, common.Int64ToByteBigEndian(from))
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+8 {
+			continue
+		}
+		if !fn(binary.BigEndian.Uint64(key[len(prefix):]), common.BytesToHash(it.Value())) {
+			return
+		}
+	}
+}
+
+// ReadTraceIndexTail returns the first block number indexed by the trace index.
+// If the trace index has never been enabled, 0 is returned.
+func (dbm *databaseManager) ReadTraceIndexTail() (uint64, error) {
+	return dbm.readCheckpoint(traceIndexTailKey)
+}
+
+// WriteTraceIndexTail stores the first block number indexed by the trace index.
+func (dbm *databaseManager) WriteTraceIndexTail(blockNum uint64) error {
+	db := dbm.getDatabase(MiscDB)
+	return db.Put(traceIndexTailKey, common.Int64ToByteBigEndian(blockNum))
+}
+
+// ReadTraceIndexHead returns the last block number indexed by the trace index.
+func (dbm *databaseManager) ReadTraceIndexHead() (uint64, error) {
+	return dbm.readCheckpoint(traceIndexHeadKey)
+}
+
+// WriteTraceIndexHead stores the last block number indexed by the trace index.
+func (dbm *databaseManager) WriteTraceIndexHead(blockNum uint64) error {
+	db := dbm.getDatabase(MiscDB)
+	return db.Put(traceIndexHeadKey, common.Int64ToByteBigEndian(blockNum))
+}
@@ -0,0 +1,382 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/golang/snappy"
+)
+
+const (
+	// freezerHashTable indicates the name of the freezer canonical hash table.
+	freezerHashTable = "hashes"
+
+	// freezerHeaderTable indicates the name of the freezer header table.
+	freezerHeaderTable = "headers"
+
+	// freezerBodiesTable indicates the name of the freezer block body table.
+	freezerBodiesTable = "bodies"
+
+	// freezerReceiptTable indicates the name of the freezer receipts table.
+	freezerReceiptTable = "receipts"
+
+	// freezerTdTable indicates the name of the freezer total blockscore table.
+	freezerTdTable = "tds"
+)
+
+// freezerTables lists the tables of the freezer with whether their items are
+// compressed by snappy.
+var freezerTables = map[string]bool{
+	freezerHashTable:    false,
+	freezerHeaderTable:  false,
+	freezerBodiesTable:  true,
+	freezerReceiptTable: true,
+	freezerTdTable:      false,
+}
+
+var (
+	// errOutOfBounds is returned if the item requested is not contained within the freezer table.
+	errOutOfBounds = errors.New("out of bounds")
+
+	// errAncientNotEnabled is returned if the ancient store is used without being configured.
+	errAncientNotEnabled = errors.New("ancient store is not enabled")
+)
+
+// indexEntrySize is the size of an index entry, the end offset of an item in the data file.
+const indexEntrySize = 8
+
+// freezerTable is an append-only table of the items numbered from 0, whose data
+// are concatenated in a flat data file and whose end offsets are kept in an index
+// file. It is only appended or truncated at its head, so the items are never
+// rewritten and the table costs no compaction.
+type freezerTable struct {
+	items    uint64 // number of the items stored in the table, must be atomically accessed
+	noSnappy bool   // whether the items are stored without compression
+
+	data  *os.File
+	index *os.File
+	size  uint64 // size of the data file
+
+	lock sync.RWMutex // protects the files from being truncated or closed while being read
+}
+
+// newFreezerTable opens the table of the given name in the directory, creating
+// it if it does not exist, and drops the broken items left by a crash.
+func newFreezerTable(dir, name string, noSnappy bool) (*freezerTable, error) {
+	index, err := os.OpenFile(filepath.Join(dir, name+".idx"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.OpenFile(filepath.Join(dir, name+".dat"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		index.Close()
+		return nil, err
+	}
+	t := &freezerTable{noSnappy: noSnappy, data: data, index: index}
+	if err := t.repair(); err != nil {
+		t.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// repair drops the partially written index entry and the items whose data are
+// not fully written, and the data not indexed.
+func (t *freezerTable) repair() error {
+	stat, err := t.index.Stat()
+	if err != nil {
+		return err
+	}
+	items := uint64(stat.Size()) / indexEntrySize
+
+	stat, err = t.data.Stat()
+	if err != nil {
+		return err
+	}
+	dataSize := uint64(stat.Size())
+	for ; items > 0; items-- {
+		end, err := t.offset(items)
+		if err != nil {
+			return err
+		}
+		if end <= dataSize {
+			break
+		}
+	}
+	return t.truncate(items)
+}
+
+// offset returns the end offset of the given number of the items.
+func (t *freezerTable) offset(items uint64) (uint64, error) {
+	if items == 0 {
+		return 0, nil
+	}
+	var buf [indexEntrySize]byte
+	if _, err := t.index.ReadAt(buf[:], int64((items-1)*indexEntrySize)); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(buf[:]), nil
+}
+
+// truncate discards the items from the given number on.
+func (t *freezerTable) truncate(items uint64) error {
+	end, err := t.offset(items)
+	if err != nil {
+		return err
+	}
+	if err := t.index.Truncate(int64(items * indexEntrySize)); err != nil {
+		return err
+	}
+	if err := t.data.Truncate(int64(end)); err != nil {
+		return err
+	}
+	t.size = end
+	atomic.StoreUint64(&t.items, items)
+	return nil
+}
+
+// Truncate discards the items from the given number on, if there are more.
+func (t *freezerTable) Truncate(items uint64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if atomic.LoadUint64(&t.items) <= items {
+		return nil
+	}
+	return t.truncate(items)
+}
+
+// Append appends the item of the given number, which should be the number of
+// the items in the table.
+func (t *freezerTable) Append(number uint64, item []byte) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if items := atomic.LoadUint64(&t.items); number != items {
+		return fmt.Errorf("appending unexpected item: want %d, have %d", items, number)
+	}
+	if !t.noSnappy {
+		item = snappy.Encode(nil, item)
+	}
+	if _, err := t.data.WriteAt(item, int64(t.size)); err != nil {
+		return err
+	}
+	var buf [indexEntrySize]byte
+	binary.BigEndian.PutUint64(buf[:], t.size+uint64(len(item)))
+	if _, err := t.index.WriteAt(buf[:], int64(number*indexEntrySize)); err != nil {
+		return err
+	}
+	t.size += uint64(len(item))
+	atomic.AddUint64(&t.items, 1)
+	return nil
+}
+
+// Retrieve returns the item of the given number.
+func (t *freezerTable) Retrieve(number uint64) ([]byte, error) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if number >= atomic.LoadUint64(&t.items) {
+		return nil, errOutOfBounds
+	}
+	start, err := t.offset(number)
+	if err != nil {
+		return nil, err
+	}
+	end, err := t.offset(number + 1)
+	if err != nil {
+		return nil, err
+	}
+	item := make([]byte, end-start)
+	if _, err := t.data.ReadAt(item, int64(start)); err != nil {
+		return nil, err
+	}
+	if t.noSnappy {
+		return item, nil
+	}
+	return snappy.Decode(nil, item)
+}
+
+// Items returns the number of the items in the table.
+func (t *freezerTable) Items() uint64 {
+	return atomic.LoadUint64(&t.items)
+}
+
+// Sync flushes the written items to the disk.
+func (t *freezerTable) Sync() error {
+	if err := t.data.Sync(); err != nil {
+		return err
+	}
+	return t.index.Sync()
+}
+
+// Close closes the files of the table.
+func (t *freezerTable) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var errs []error
+	for _, f := range []*os.File{t.data, t.index} {
+		if err := f.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// freezer is an ancient store of the cold chain data, keeping the canonical
+// hashes, the headers, the bodies, the receipts and the total blockscores of
+// the old blocks in the append-only flat files, which are cheaper to keep than
+// the key-value database and cost no compaction.
+//
+// The items of all the tables are numbered by the block numbers from the genesis,
+// and a block is frozen only if it is appended to all the tables.
+type freezer struct {
+	frozen uint64 // number of the blocks frozen, must be atomically accessed
+	tables map[string]*freezerTable
+
+	lock sync.Mutex // serializes the appends and the truncations
+}
+
+// newFreezer opens the freezer in the given directory, creating it if it does
+// not exist, and truncates the tables to the blocks frozen in all of them.
+func newFreezer(dir string) (*freezer, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	f := &freezer{tables: make(map[string]*freezerTable)}
+	for name, compressed := range freezerTables {
+		table, err := newFreezerTable(dir, name, !compressed)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		f.tables[name] = table
+	}
+	frozen := f.tables[freezerHashTable].Items()
+	for _, table := range f.tables {
+		if items := table.Items(); items < frozen {
+			frozen = items
+		}
+	}
+	if err := f.truncate(frozen); err != nil {
+		f.Close()
+		return nil, err
+	}
+	logger.Info("Opened ancient store", "dir", dir, "blocks", frozen)
+	return f, nil
+}
+
+// Ancient returns the item of the given kind of the frozen block.
+func (f *freezer) Ancient(kind string, number uint64) ([]byte, error) {
+	table, ok := f.tables[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown ancient table %s", kind)
+	}
+	if number >= f.Frozen() {
+		return nil, errOutOfBounds
+	}
+	return table.Retrieve(number)
+}
+
+// Frozen returns the number of the blocks frozen.
+func (f *freezer) Frozen() uint64 {
+	return atomic.LoadUint64(&f.frozen)
+}
+
+// AppendAncient appends the block of the given number, which should be the
+// number of the blocks frozen. An empty body or receipts mean that they are
+// not available.
+func (f *freezer) AppendAncient(number uint64, hash, header, body, receipts, td []byte) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if frozen := f.Frozen(); number != frozen {
+		return fmt.Errorf("appending unexpected block: want %d, have %d", frozen, number)
+	}
+	items := map[string][]byte{
+		freezerHashTable:    hash,
+		freezerHeaderTable:  header,
+		freezerBodiesTable:  body,
+		freezerReceiptTable: receipts,
+		freezerTdTable:      td,
+	}
+	for name, item := range items {
+		if err := f.tables[name].Append(number, item); err != nil {
+			// Drop the block partially appended
+			f.truncate(number)
+			return err
+		}
+	}
+	atomic.StoreUint64(&f.frozen, number+1)
+	return nil
+}
+
+// TruncateAncients discards the blocks from the given number on.
+func (f *freezer) TruncateAncients(items uint64) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.Frozen() <= items {
+		return nil
+	}
+	return f.truncate(items)
+}
+
+func (f *freezer) truncate(items uint64) error {
+	atomic.StoreUint64(&f.frozen, items)
+	for _, table := range f.tables {
+		if err := table.Truncate(items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sync flushes the frozen blocks to the disk.
+func (f *freezer) Sync() error {
+	for _, table := range f.tables {
+		if err := table.Sync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes all the tables.
+func (f *freezer) Close() error {
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
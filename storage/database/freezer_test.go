@@ -0,0 +1,134 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDBManager_FreezeBlocks tests that the frozen blocks are moved out of the key-value
+// database and still readable from the ancient store, also after reopening it.
+func TestDBManager_FreezeBlocks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "klaytn-test-freezer")
+	if err != nil {
+		t.Fatalf("cannot create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dbc := &DBConfig{Dir: dir, DBType: LevelDB, LevelDBCacheSize: 32, OpenFilesLimit: 32, AncientDir: "ancient"}
+	dbm := NewDBManager(dbc)
+	assert.True(t, dbm.HasAncients())
+
+	const blocks = 10
+	headers := make([]*types.Header, blocks)
+	for i := uint64(0); i < blocks; i++ {
+		header := &types.Header{Number: new(big.Int).SetUint64(i), BlockScore: big.NewInt(1), Extra: []byte{}}
+		if i > 0 {
+			header.ParentHash = headers[i-1].Hash()
+		}
+		headers[i] = header
+		hash := header.Hash()
+
+		dbm.WriteHeader(header)
+		dbm.WriteCanonicalHash(hash, i)
+		dbm.WriteTd(hash, i, new(big.Int).SetUint64(i+1))
+		dbm.WriteBody(hash, i, &types.Body{Transactions: types.Transactions{}})
+		dbm.WriteReceipts(hash, i, types.Receipts{genReceipt(int(i) + 1)})
+	}
+
+	frozen, err := dbm.FreezeBlocks(5)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), frozen)
+	assert.Equal(t, uint64(5), dbm.AncientBlocks())
+
+	check := func(dbm DBManager) {
+		dbm.ClearBlockChainCache()
+		for i, header := range headers {
+			number, hash := uint64(i), header.Hash()
+			assert.Equal(t, hash, dbm.ReadCanonicalHash(number))
+			assert.True(t, dbm.HasHeader(hash, number))
+			assert.Equal(t, hash, dbm.ReadHeader(hash, number).Hash())
+			assert.True(t, dbm.HasBody(hash, number))
+			assert.NotNil(t, dbm.ReadBody(hash, number))
+			assert.Equal(t, new(big.Int).SetUint64(number+1), dbm.ReadTd(hash, number))
+			receipts := dbm.ReadReceipts(hash, number)
+			if assert.Len(t, receipts, 1) {
+				assert.Equal(t, uint64(i+1), receipts[0].GasUsed)
+			}
+		}
+		// A non-canonical block is not found in the ancient store
+		assert.False(t, dbm.HasHeader(common.Hash{1}, 0))
+	}
+	check(dbm)
+
+	// The frozen blocks are removed from the key-value database
+	raw, _ := dbm.getDatabase(headerDB).Get(headerKey(0, headers[0].Hash()))
+	assert.Empty(t, raw)
+
+	dbm.Close()
+	dbm = NewDBManager(dbc)
+	defer dbm.Close()
+	assert.Equal(t, uint64(5), dbm.AncientBlocks())
+	check(dbm)
+
+	assert.NoError(t, dbm.TruncateAncientBlocks(3))
+	assert.Equal(t, uint64(3), dbm.AncientBlocks())
+	assert.Nil(t, dbm.(*databaseManager).readAncient(freezerHashTable, 3))
+	assert.NotNil(t, dbm.(*databaseManager).readAncient(freezerHashTable, 2))
+}
+
+// TestFreezerTable_Repair tests that the item partially written by a crash is dropped on reopening.
+func TestFreezerTable_Repair(t *testing.T) {
+	dir, err := ioutil.TempDir("", "klaytn-test-freezer-table")
+	if err != nil {
+		t.Fatalf("cannot create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	table, err := newFreezerTable(dir, "test", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(0); i < 3; i++ {
+		assert.NoError(t, table.Append(i, []byte{byte(i), byte(i), byte(i)}))
+	}
+	assert.Error(t, table.Append(5, []byte{5}))
+
+	// Cut the data of the last item
+	assert.NoError(t, table.data.Truncate(int64(table.size-1)))
+	assert.NoError(t, table.Close())
+
+	table, err = newFreezerTable(dir, "test", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer table.Close()
+	assert.Equal(t, uint64(2), table.Items())
+
+	item, err := table.Retrieve(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1, 1, 1}, item)
+	_, err = table.Retrieve(2)
+	assert.Equal(t, errOutOfBounds, err)
+}
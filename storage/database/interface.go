@@ -67,6 +67,17 @@ type KeyValueWriter interface {
 	Delete(key []byte) error
 }
 
+// Stater wraps the Stat method of a database exposing its internal properties, e.g. "leveldb.stats".
+type Stater interface {
+	Stat(property string) (string, error)
+}
+
+// Compacter wraps the Compact method of a database which can be compacted on demand.
+type Compacter interface {
+	// Compact compacts the keys in [start, limit). A nil start or limit means the first or the last key.
+	Compact(start []byte, limit []byte) error
+}
+
 // Database wraps all database operations. All methods are safe for concurrent use.
 type Database interface {
 	KeyValueWriter
@@ -263,6 +263,16 @@ func (db *levelDB) Path() string {
 	return db.fn
 }
 
+// Stat returns the given property of LevelDB, e.g. "leveldb.stats" or "leveldb.num-files-at-level0".
+func (db *levelDB) Stat(property string) (string, error) {
+	return db.db.GetProperty(property)
+}
+
+// Compact compacts the keys in [start, limit) of LevelDB.
+func (db *levelDB) Compact(start []byte, limit []byte) error {
+	return db.db.CompactRange(util.Range{Start: start, Limit: limit})
+}
+
 // Put puts the given key / value to the queue
 func (db *levelDB) Put(key []byte, value []byte) error {
 	// Generate the data to write to disk, update the meter and write
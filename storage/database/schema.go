@@ -61,6 +61,12 @@ var (
 
 	txLookupPrefix = []byte("l") // txLookupPrefix + hash -> transaction/receipt lookup metadata
 
+	// txIndexTailKey tracks the oldest block whose transactions have been indexed.
+	txIndexTailKey = []byte("TransactionIndexTail")
+
+	// bodyTailKey tracks the oldest block whose body and receipts are kept, except the genesis block.
+	bodyTailKey = []byte("BlockBodyTail")
+
 	preimagePrefix = []byte("secure-key-")  // preimagePrefix + hash -> preimage
 	configPrefix   = []byte("klay-config-") // config prefix for the db
 
@@ -94,7 +100,37 @@ var (
 
 	stakingInfoPrefix = []byte("stakingInfo")
 
-	chaindatafetcherCheckpointKey = []byte("chaindatafetcherCheckpoint")
+	supplyCheckpointPrefix  = []byte("supplyCheckpoint")
+	lastSupplyCheckpointKey = []byte("LastSupplyCheckpoint")
+
+	storageTrieOwnerPrefix = []byte("storageTrieOwner") // storageTrieOwnerPrefix + storage root + address -> empty
+
+	feePayerTxPrefix     = []byte("feePayerTx") // feePayerTxPrefix + fee payer + num + tx index -> fee payer tx entry
+	feePayerIndexTailKey = []byte("FeePayerIndexTail")
+
+	balanceChangePrefix = []byte("balanceChange") // balanceChangePrefix + address + num -> balance change entry
+	balanceIndexTailKey = []byte("BalanceIndexTail")
+
+	traceAddressPrefix = []byte("traceAddress") // traceAddressPrefix + address + num -> block hash
+	traceIndexTailKey  = []byte("TraceIndexTail")
+	traceIndexHeadKey  = []byte("TraceIndexHead")
+
+	logAddressPrefix = []byte("logAddress") // logAddressPrefix + address + num -> block hash
+	logTopicPrefix   = []byte("logTopic")   // logTopicPrefix + address + first topic + num -> block hash
+	logIndexTailKey  = []byte("LogIndexTail")
+	logIndexHeadKey  = []byte("LogIndexHead")
+
+	chaindatafetcherCheckpointKey        = []byte("chaindatafetcherCheckpoint")
+	chaindatafetcherSinkCheckpointPrefix = []byte("chaindatafetcherCheckpoint-")
+
+	snapshotRootKey      = []byte("SnapshotRoot")      // snapshotRootKey -> state root of the disk layer of the snapshot
+	snapshotGeneratorKey = []byte("SnapshotGenerator") // snapshotGeneratorKey -> progress of the snapshot generation
+
+	SnapshotAccountPrefix = []byte("a") // SnapshotAccountPrefix + account hash -> account trie value
+	SnapshotStoragePrefix = []byte("o") // SnapshotStoragePrefix + account hash + storage hash -> storage trie value
+
+	SnapSyncAccountPrefix = []byte("sa") // SnapSyncAccountPrefix + account hash -> account trie value fetched by snap sync
+	SnapSyncStoragePrefix = []byte("so") // SnapSyncStoragePrefix + account hash + storage hash -> storage trie value fetched by snap sync
 )
 
 // TxLookupEntry is a positional metadata to help looking up the data content of
@@ -189,6 +225,76 @@ func makeKey(prefix []byte, num uint64) []byte {
 	return append(prefix, byteKey...)
 }
 
+// chaindatafetcherSinkCheckpointKey = chaindatafetcherSinkCheckpointPrefix + sink
+func chaindatafetcherSinkCheckpointKey(sink string) []byte {
+	return append(chaindatafetcherSinkCheckpointPrefix, []byte(sink)...)
+}
+
+// storageTrieOwnerKey = storageTrieOwnerPrefix + root + addr
+func storageTrieOwnerKey(root common.Hash, addr common.Address) []byte {
+	return append(append(storageTrieOwnerPrefix, root.Bytes()...), addr.Bytes()...)
+}
+
+// accountSnapshotKey = SnapshotAccountPrefix + account hash
+func accountSnapshotKey(hash common.Hash) []byte {
+	return append(append(make([]byte, 0, len(SnapshotAccountPrefix)+common.HashLength), SnapshotAccountPrefix...), hash.Bytes()...)
+}
+
+// storageSnapshotKey = SnapshotStoragePrefix + account hash + storage hash
+func storageSnapshotKey(accountHash, storageHash common.Hash) []byte {
+	key := make([]byte, 0, len(SnapshotStoragePrefix)+2*common.HashLength)
+	return append(append(append(key, SnapshotStoragePrefix...), accountHash.Bytes()...), storageHash.Bytes()...)
+}
+
+// snapSyncAccountKey = SnapSyncAccountPrefix + account hash
+func snapSyncAccountKey(hash common.Hash) []byte {
+	return append(append(make([]byte, 0, len(SnapSyncAccountPrefix)+common.HashLength), SnapSyncAccountPrefix...), hash.Bytes()...)
+}
+
+// snapSyncStorageKey = SnapSyncStoragePrefix + account hash + storage hash
+func snapSyncStorageKey(accountHash, storageHash common.Hash) []byte {
+	key := make([]byte, 0, len(SnapSyncStoragePrefix)+2*common.HashLength)
+	return append(append(append(key, SnapSyncStoragePrefix...), accountHash.Bytes()...), storageHash.Bytes()...)
+}
+
+// feePayerTxKey = feePayerTxPrefix + feePayer + num (uint64 big endian) + txIndex (uint32 big endian)
+func feePayerTxKey(feePayer common.Address, num uint64, txIndex uint32) []byte {
+	key := make([]byte, 0, len(feePayerTxPrefix)+common.AddressLength+8+4)
+	key = append(append(key, feePayerTxPrefix...), feePayer.Bytes()...)
+	key = append(key, common.Int64ToByteBigEndian(num)...)
+	var index [4]byte
+	binary.BigEndian.PutUint32(index[:], txIndex)
+	return append(key, index[:]...)
+}
+
+// balanceChangeKey = balanceChangePrefix + address + num (uint64 big endian)
+func balanceChangeKey(addr common.Address, num uint64) []byte {
+	key := make([]byte, 0, len(balanceChangePrefix)+common.AddressLength+8)
+	key = append(append(key, balanceChangePrefix...), addr.Bytes()...)
+	return append(key, common.Int64ToByteBigEndian(num)...)
+}
+
+// traceAddressKey = traceAddressPrefix + address + num (uint64 big endian)
+func traceAddressKey(addr common.Address, num uint64) []byte {
+	key := make([]byte, 0, len(traceAddressPrefix)+common.AddressLength+8)
+	key = append(append(key, traceAddressPrefix...), addr.Bytes()...)
+	return append(key, common.Int64ToByteBigEndian(num)...)
+}
+
+// logAddressKey = logAddressPrefix + address + num (uint64 big endian)
+func logAddressKey(addr common.Address, num uint64) []byte {
+	key := make([]byte, 0, len(logAddressPrefix)+common.AddressLength+8)
+	key = append(append(key, logAddressPrefix...), addr.Bytes()...)
+	return append(key, common.Int64ToByteBigEndian(num)...)
+}
+
+// logTopicKey = logTopicPrefix + address + topic + num (uint64 big endian)
+func logTopicKey(addr common.Address, topic common.Hash, num uint64) []byte {
+	key := make([]byte, 0, len(logTopicPrefix)+common.AddressLength+common.HashLength+8)
+	key = append(append(append(key, logTopicPrefix...), addr.Bytes()...), topic.Bytes()...)
+	return append(key, common.Int64ToByteBigEndian(num)...)
+}
+
 func databaseDirKey(dbEntryType uint64) []byte {
 	return append(databaseDirPrefix, common.Int64ToByteBigEndian(dbEntryType)...)
 }
@@ -161,6 +161,37 @@ func (db *shardedDB) Close() {
 	}
 }
 
+// Stat returns the given property of each shard.
+func (db *shardedDB) Stat(property string) (string, error) {
+	var buf bytes.Buffer
+	for i, shard := range db.shards {
+		stater, ok := shard.(Stater)
+		if !ok {
+			return "", fmt.Errorf("shard %d does not support stats", i)
+		}
+		stat, err := stater.Stat(property)
+		if err != nil {
+			return "", fmt.Errorf("shard %d: %v", i, err)
+		}
+		fmt.Fprintf(&buf, "shard %d:\n%s\n", i, stat)
+	}
+	return buf.String(), nil
+}
+
+// Compact compacts the keys in [start, limit) of each shard.
+func (db *shardedDB) Compact(start []byte, limit []byte) error {
+	for i, shard := range db.shards {
+		compacter, ok := shard.(Compacter)
+		if !ok {
+			return fmt.Errorf("shard %d does not support compaction", i)
+		}
+		if err := compacter.Compact(start, limit); err != nil {
+			return fmt.Errorf("shard %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
 // Not enough size of channel slows down the iterator
 const shardedDBCombineChanSize = 1024 // Size of resultCh
 const shardedDBSubChannelSize = 128   // Size of each sub-channel of resultChs
@@ -0,0 +1,245 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package statedb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// A portable dump of the trie node cache is a single file, which can be copied to or downloaded
+// by another node, containing the files of a saved local cache. It consists of the magic, the
+// format version and the gzip compressed entries of the files. Each entry has the length of the
+// file name (uint16), the file name, the size of the file (uint64) and the content of the file,
+// and the entries end with an entry of an empty file name.
+const trieNodeCacheDumpVersion = 1
+
+var trieNodeCacheDumpMagic = [8]byte{'k', 'l', 'a', 'y', 't', 'n', 'c', 'd'}
+
+var (
+	errEmptyTrieNodeCacheFile          = errors.New("trie node cache file is empty or saved from a cache of another size")
+	errNoLocalTrieNodeCache            = errors.New("trie node cache has no local cache to dump or load")
+	errLoadingTrieNodeCacheInProgress  = errors.New("loading trie node cache is in progress")
+	errInvalidTrieNodeCacheDump        = errors.New("invalid trie node cache dump")
+	errUnsupportedTrieNodeCacheVersion = errors.New("unsupported trie node cache dump version")
+)
+
+// localFastCache returns the local cache of the given trie node cache, or nil if it does not have one.
+func localFastCache(cache TrieNodeCache) *FastCache {
+	switch c := cache.(type) {
+	case *FastCache:
+		return c
+	case *HybridCache:
+		fc, _ := c.Local().(*FastCache)
+		return fc
+	}
+	return nil
+}
+
+// CanLoadTrieNodeCache returns an error if a trie node cache dump cannot be saved or loaded now.
+func (db *Database) CanLoadTrieNodeCache() error {
+	if localFastCache(db.trieNodeCache) == nil {
+		return errNoLocalTrieNodeCache
+	}
+	if db.loadingTrieNodeCacheTriggered {
+		return errLoadingTrieNodeCacheInProgress
+	}
+	return db.CanSaveTrieNodeCacheToFile()
+}
+
+// DumpTrieNodeCacheToFile saves the local trie node cache to filePath like SaveTrieNodeCacheToFile,
+// and writes the portable dump of the saved cache to dumpPath, which can be loaded by another node.
+func (db *Database) DumpTrieNodeCacheToFile(filePath, dumpPath string, concurrency int) {
+	db.savingTrieNodeCacheTriggered = true
+	defer func() { db.savingTrieNodeCacheTriggered = false }()
+
+	start := time.Now()
+	logger.Info("start dumping cache to file", "filePath", filePath, "dumpPath", dumpPath, "concurrency", concurrency)
+	if err := localFastCache(db.trieNodeCache).SaveToFile(filePath, concurrency); err != nil {
+		logger.Error("failed to save cache to file", "filePath", filePath, "elapsed", time.Since(start), "err", err)
+		return
+	}
+	if err := writeTrieNodeCacheDump(dumpPath, filePath); err != nil {
+		logger.Error("failed to dump cache to file", "dumpPath", dumpPath, "elapsed", time.Since(start), "err", err)
+		return
+	}
+	logger.Info("successfully dumped cache to file", "dumpPath", dumpPath, "elapsed", time.Since(start))
+}
+
+// LoadTrieNodeCache replaces the local trie node cache with the one in the portable dump read from r.
+// The dump should be taken from a cache of the same size. Since the trie nodes are cached by their
+// hashes, the cached nodes of another node of the same chain are valid regardless of its head block.
+func (db *Database) LoadTrieNodeCache(r io.Reader) error {
+	if err := db.CanLoadTrieNodeCache(); err != nil {
+		return err
+	}
+	db.loadingTrieNodeCacheTriggered = true
+	defer func() { db.loadingTrieNodeCacheTriggered = false }()
+
+	// The dump is unpacked next to the cache directory, which usually has enough space for the dump
+	var parent string
+	if db.trieNodeCacheConfig != nil && db.trieNodeCacheConfig.FastCacheFileDir != "" {
+		parent = filepath.Dir(db.trieNodeCacheConfig.FastCacheFileDir)
+		if err := os.MkdirAll(parent, 0700); err != nil {
+			return err
+		}
+	}
+	dir, err := ioutil.TempDir(parent, "trienodecache-dump")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	start := time.Now()
+	if err := readTrieNodeCacheDump(r, dir); err != nil {
+		return err
+	}
+	if err := localFastCache(db.trieNodeCache).LoadFromFile(dir); err != nil {
+		return err
+	}
+	logger.Info("successfully loaded cache from dump", "elapsed", time.Since(start))
+	return nil
+}
+
+// writeTrieNodeCacheDump writes the files in the directory to a dump file.
+func writeTrieNodeCacheDump(dumpPath, dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	// The dump is written to a temporary file first not to leave a partial dump
+	tmpPath := dumpPath + ".tmp"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+	defer out.Close()
+
+	if _, err := out.Write(trieNodeCacheDumpMagic[:]); err != nil {
+		return err
+	}
+	if err := binary.Write(out, binary.BigEndian, uint32(trieNodeCacheDumpVersion)); err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		if err := writeTrieNodeCacheDumpEntry(zw, dir, file); err != nil {
+			return err
+		}
+	}
+	// An entry of an empty file name ends the entries
+	if err := binary.Write(zw, binary.BigEndian, uint16(0)); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, dumpPath)
+}
+
+func writeTrieNodeCacheDumpEntry(w io.Writer, dir string, file os.FileInfo) error {
+	in, err := os.Open(filepath.Join(dir, file.Name()))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := binary.Write(w, binary.BigEndian, uint16(len(file.Name()))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, file.Name()); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint64(file.Size())); err != nil {
+		return err
+	}
+	_, err = io.CopyN(w, in, file.Size())
+	return err
+}
+
+// readTrieNodeCacheDump unpacks the files in a dump read from r to the directory.
+func readTrieNodeCacheDump(r io.Reader, dir string) error {
+	var magic [8]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil || !bytes.Equal(magic[:], trieNodeCacheDumpMagic[:]) {
+		return errInvalidTrieNodeCacheDump
+	}
+	var version uint32
+	if err := binary.Read(r, binary.BigEndian, &version); err != nil {
+		return errInvalidTrieNodeCacheDump
+	}
+	if version != trieNodeCacheDumpVersion {
+		return fmt.Errorf("%w: %d", errUnsupportedTrieNodeCacheVersion, version)
+	}
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errInvalidTrieNodeCacheDump, err)
+	}
+	defer zr.Close()
+
+	for {
+		var nameLen uint16
+		if err := binary.Read(zr, binary.BigEndian, &nameLen); err != nil {
+			return fmt.Errorf("%w: %v", errInvalidTrieNodeCacheDump, err)
+		}
+		if nameLen == 0 {
+			return nil
+		}
+		name := make([]byte, nameLen)
+		if _, err := io.ReadFull(zr, name); err != nil {
+			return fmt.Errorf("%w: %v", errInvalidTrieNodeCacheDump, err)
+		}
+		// Only the plain file names are allowed not to write files outside the directory
+		if filepath.Base(string(name)) != string(name) || string(name) == "." || string(name) == ".." {
+			return fmt.Errorf("%w: invalid file name %q", errInvalidTrieNodeCacheDump, name)
+		}
+		var size uint64
+		if err := binary.Read(zr, binary.BigEndian, &size); err != nil {
+			return fmt.Errorf("%w: %v", errInvalidTrieNodeCacheDump, err)
+		}
+		if err := readTrieNodeCacheDumpEntry(zr, filepath.Join(dir, string(name)), size); err != nil {
+			return err
+		}
+	}
+}
+
+func readTrieNodeCacheDumpEntry(r io.Reader, path string, size uint64) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.CopyN(out, r, int64(size)); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidTrieNodeCacheDump, err)
+	}
+	return out.Close()
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package statedb
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestDumpDatabase(t *testing.T, dir string, sizeMiB int) *Database {
+	return NewDatabaseWithNewCache(database.NewMemoryDBManager(), &TrieNodeCacheConfig{
+		CacheType:         CacheTypeLocal,
+		LocalCacheSizeMiB: sizeMiB,
+		FastCacheFileDir:  filepath.Join(dir, "fastcache"),
+	})
+}
+
+func TestTrieNodeCacheDump(t *testing.T) {
+	dir, err := ioutil.TempDir("", "klaytn-test-trienodecache-dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := newTestDumpDatabase(t, filepath.Join(dir, "src"), 32)
+	for i := 0; i < 100; i++ {
+		src.trieNodeCache.Set(common.BytesToHash([]byte{byte(i)}).Bytes(), []byte{byte(i), 1})
+	}
+	dumpPath := filepath.Join(dir, "dump")
+	assert.NoError(t, src.CanLoadTrieNodeCache())
+	src.DumpTrieNodeCacheToFile(src.trieNodeCacheConfig.FastCacheFileDir, dumpPath, 2)
+
+	// The cached nodes of the source are loaded by another node
+	dump, err := ioutil.ReadFile(dumpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := newTestDumpDatabase(t, filepath.Join(dir, "dst"), 32)
+	dst.trieNodeCache.Set([]byte("stale"), []byte("stale"))
+	assert.NoError(t, dst.LoadTrieNodeCache(bytes.NewReader(dump)))
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, []byte{byte(i), 1}, dst.trieNodeCache.Get(common.BytesToHash([]byte{byte(i)}).Bytes()))
+	}
+	assert.Nil(t, dst.trieNodeCache.Get([]byte("stale")))
+
+	// The dump of a cache of another size is rejected
+	other := newTestDumpDatabase(t, filepath.Join(dir, "other"), 64)
+	assert.Equal(t, errEmptyTrieNodeCacheFile, other.LoadTrieNodeCache(bytes.NewReader(dump)))
+
+	// The corrupted dumps are rejected
+	assert.Equal(t, errInvalidTrieNodeCacheDump, dst.LoadTrieNodeCache(bytes.NewReader(dump[1:])))
+	err = dst.LoadTrieNodeCache(bytes.NewReader(dump[:len(dump)/2]))
+	assert.True(t, errors.Is(err, errInvalidTrieNodeCacheDump), err)
+
+	// The dump cannot be loaded without a local cache
+	noCache := NewDatabase(database.NewMemoryDBManager())
+	assert.Equal(t, errNoLocalTrieNodeCache, noCache.LoadTrieNodeCache(bytes.NewReader(dump)))
+}
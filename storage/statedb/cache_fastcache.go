@@ -17,6 +17,7 @@
 package statedb
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/VictoriaMetrics/fastcache"
@@ -40,7 +41,8 @@ var (
 )
 
 type FastCache struct {
-	fast *fastcache.Cache
+	fast     atomic.Value // *fastcache.Cache, replaced when a dump is loaded
+	maxBytes int
 }
 
 // newFastCache creates a FastCache with given cache size.
@@ -59,7 +61,8 @@ func newFastCache(config *TrieNodeCacheConfig) TrieNodeCache {
 		"MaxMiB", config.LocalCacheSizeMiB, "FilePath", config.FastCacheFileDir)
 
 	start := time.Now()
-	fc := &FastCache{fast: fastcache.LoadFromFileOrNew(config.FastCacheFileDir, config.LocalCacheSizeMiB*int(units.MiB))}
+	fc := &FastCache{maxBytes: config.LocalCacheSizeMiB * int(units.MiB)}
+	fc.fast.Store(fastcache.LoadFromFileOrNew(config.FastCacheFileDir, fc.maxBytes))
 	stats := fc.UpdateStats().(fastcache.Stats)
 
 	logger.Info("Initialized local trie node cache (fastCache)",
@@ -68,21 +71,25 @@ func newFastCache(config *TrieNodeCacheConfig) TrieNodeCache {
 	return fc
 }
 
+func (cache *FastCache) cache() *fastcache.Cache {
+	return cache.fast.Load().(*fastcache.Cache)
+}
+
 func (cache *FastCache) Get(k []byte) []byte {
-	return cache.fast.Get(nil, k)
+	return cache.cache().Get(nil, k)
 }
 
 func (cache *FastCache) Set(k, v []byte) {
-	cache.fast.Set(k, v)
+	cache.cache().Set(k, v)
 }
 
 func (cache *FastCache) Has(k []byte) ([]byte, bool) {
-	return cache.fast.HasGet(nil, k)
+	return cache.cache().HasGet(nil, k)
 }
 
 func (cache *FastCache) UpdateStats() interface{} {
 	var stats fastcache.Stats
-	cache.fast.UpdateStats(&stats)
+	cache.cache().UpdateStats(&stats)
 
 	memcacheFastMisses.Update(int64(stats.Misses))
 	memcacheFastCollisions.Update(int64(stats.Collisions))
@@ -100,7 +107,22 @@ func (cache *FastCache) UpdateStats() interface{} {
 }
 
 func (cache *FastCache) SaveToFile(filePath string, concurrency int) error {
-	return cache.fast.SaveToFileConcurrent(filePath, concurrency)
+	return cache.cache().SaveToFileConcurrent(filePath, concurrency)
+}
+
+// LoadFromFile replaces the cached data with the ones saved in the given directory.
+// The data should be saved from a cache of the same size.
+func (cache *FastCache) LoadFromFile(filePath string) error {
+	loaded := fastcache.LoadFromFileOrNew(filePath, cache.maxBytes)
+	var stats fastcache.Stats
+	loaded.UpdateStats(&stats)
+	if stats.EntriesCount == 0 {
+		return errEmptyTrieNodeCacheFile
+	}
+	old := cache.cache()
+	cache.fast.Store(loaded)
+	old.Reset()
+	return nil
 }
 
 func (cache *FastCache) Close() error {
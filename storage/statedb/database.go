@@ -116,9 +116,12 @@ type Database struct {
 
 	lock sync.RWMutex
 
-	trieNodeCache                TrieNodeCache        // GC friendly memory cache of trie node RLPs
-	trieNodeCacheConfig          *TrieNodeCacheConfig // Configuration of trieNodeCache
-	savingTrieNodeCacheTriggered bool                 // Whether saving trie node cache has been triggered or not
+	writeObserver func(hash common.Hash) // Called with the hash of every node before it is written to disk
+
+	trieNodeCache                 TrieNodeCache        // GC friendly memory cache of trie node RLPs
+	trieNodeCacheConfig           *TrieNodeCacheConfig // Configuration of trieNodeCache
+	savingTrieNodeCacheTriggered  bool                 // Whether saving trie node cache has been triggered or not
+	loadingTrieNodeCacheTriggered bool                 // Whether loading trie node cache has been triggered or not
 }
 
 // rawNode is a simple binary blob used to differentiate between collapsed trie
@@ -359,6 +362,17 @@ func (db *Database) DiskDB() database.DBManager {
 	return db.diskDB
 }
 
+// SetWriteObserver sets the function called with the hash of every trie node
+// and contract code before it is flushed to disk by Commit or Cap, or clears
+// it if nil. Since it is called before the node is written, the caller learns
+// every node persisted after the observer is set.
+func (db *Database) SetWriteObserver(observer func(hash common.Hash)) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	db.writeObserver = observer
+}
+
 // TrieNodeCache retrieves the trieNodeCache of the trie database.
 func (db *Database) TrieNodeCache() TrieNodeCache {
 	return db.trieNodeCache
@@ -773,6 +787,9 @@ func (db *Database) Cap(limit common.StorageSize) error {
 		// Fetch the oldest referenced node and push into the batch
 		node := db.nodes[oldest]
 		enc := node.rlp()
+		if db.writeObserver != nil {
+			db.writeObserver(oldest)
+		}
 		if err := database.PutAndWriteBatchesOverThreshold(batch, oldest[:], enc); err != nil {
 			db.lock.RUnlock()
 			return err
@@ -897,6 +914,9 @@ func (db *Database) writeBatchNodes(node common.Hash) error {
 			continue
 		}
 
+		if db.writeObserver != nil {
+			db.writeObserver(common.BytesToHash(result.key))
+		}
 		if err := batch.Put(result.key, result.val); err != nil {
 			return err
 		}
@@ -909,6 +929,9 @@ func (db *Database) writeBatchNodes(node common.Hash) error {
 	}
 
 	enc := rootNode.rlp()
+	if db.writeObserver != nil {
+		db.writeObserver(node)
+	}
 	if err := batch.Put(node[:], enc); err != nil {
 		return err
 	}
@@ -134,3 +134,44 @@ func TestCache(t *testing.T) {
 		assert.Equal(t, value, rValue)
 	}
 }
+
+func TestDatabase_WriteObserver(t *testing.T) {
+	memDB := database.NewMemoryDBManager()
+	db := NewDatabase(memDB)
+
+	observed := make(map[common.Hash]bool)
+	db.SetWriteObserver(func(hash common.Hash) {
+		observed[hash] = true
+	})
+	commitTrie := func(prefix byte) common.Hash {
+		tr, _ := NewTrie(common.Hash{}, db)
+		for i := 0; i < 100; i++ {
+			tr.Update([]byte{prefix, byte(i)}, common.MakeRandomBytes(32))
+		}
+		root, err := tr.Commit(nil)
+		assert.NoError(t, err)
+		return root
+	}
+	// The nodes written by Commit and Cap are observed
+	root := commitTrie(0x01)
+	assert.NoError(t, db.Commit(root, false, 0))
+	db.Reference(commitTrie(0x02), common.Hash{})
+	assert.NoError(t, db.Cap(0))
+
+	written := 0
+	it := memDB.GetMemDB().NewIterator(nil, nil)
+	for it.Next() {
+		if len(it.Key()) == common.HashLength {
+			assert.True(t, observed[common.BytesToHash(it.Key())])
+			written++
+		}
+	}
+	it.Release()
+	assert.NotZero(t, written)
+	assert.Equal(t, written, len(observed))
+
+	// The nodes are not observed after the observer is cleared
+	db.SetWriteObserver(nil)
+	assert.NoError(t, db.Commit(commitTrie(0x03), false, 0))
+	assert.Equal(t, written, len(observed))
+}
@@ -24,6 +24,7 @@ import (
 	"bytes"
 	crand "crypto/rand"
 	mrand "math/rand"
+	"sort"
 	"testing"
 	"time"
 
@@ -94,6 +95,227 @@ func TestVerifyBadProof(t *testing.T) {
 	}
 }
 
+type entrySlice []*kv
+
+func (p entrySlice) Len() int           { return len(p) }
+func (p entrySlice) Less(i, j int) bool { return bytes.Compare(p[i].k, p[j].k) < 0 }
+func (p entrySlice) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+// sortedEntries returns the trie entries sorted by key.
+func sortedEntries(vals map[string]*kv) entrySlice {
+	var entries entrySlice
+	for _, kv := range vals {
+		entries = append(entries, kv)
+	}
+	sort.Sort(entries)
+	return entries
+}
+
+// TestRangeProof tests normal range proofs with both edge proofs
+// as the existent proof.
+func TestRangeProof(t *testing.T) {
+	trie, vals := randomTrie(4096)
+	entries := sortedEntries(vals)
+	for i := 0; i < 500; i++ {
+		start := mrand.Intn(len(entries))
+		end := mrand.Intn(len(entries)-start) + start + 1
+
+		proof := database.NewMemoryDBManager()
+		if err := trie.Prove(entries[start].k, 0, proof); err != nil {
+			t.Fatalf("Failed to prove the first node %v", err)
+		}
+		if err := trie.Prove(entries[end-1].k, 0, proof); err != nil {
+			t.Fatalf("Failed to prove the last node %v", err)
+		}
+		var keys, values [][]byte
+		for i := start; i < end; i++ {
+			keys = append(keys, entries[i].k)
+			values = append(values, entries[i].v)
+		}
+		more, err := VerifyRangeProof(trie.Hash(), keys[0], keys[len(keys)-1], keys, values, proof)
+		if err != nil {
+			t.Fatalf("Case %d(%d->%d) expect no error, got %v", i, start, end-1, err)
+		}
+		if more != (end != len(entries)) {
+			t.Fatalf("Case %d(%d->%d) wrong more indicator, got %v", i, start, end-1, more)
+		}
+	}
+}
+
+// TestRangeProofWithNonExistentProof tests normal range proofs with
+// non-existent edge proofs.
+func TestRangeProofWithNonExistentProof(t *testing.T) {
+	trie, vals := randomTrie(4096)
+	entries := sortedEntries(vals)
+	for i := 0; i < 500; i++ {
+		start := mrand.Intn(len(entries))
+		end := mrand.Intn(len(entries)-start) + start + 1
+
+		// Short circuit if the decreased key is same with the previous key
+		first := decreaseKey(common.CopyBytes(entries[start].k))
+		if start != 0 && bytes.Equal(first, entries[start-1].k) {
+			continue
+		}
+		// Short circuit if the decreased key wrapped around
+		if bytes.Compare(first, entries[start].k) > 0 {
+			continue
+		}
+		// Short circuit if the increased key is same with the next key
+		last := increaseKey(common.CopyBytes(entries[end-1].k))
+		if end != len(entries) && bytes.Equal(last, entries[end].k) {
+			continue
+		}
+		// Short circuit if the increased key wrapped around
+		if bytes.Compare(last, entries[end-1].k) < 0 {
+			continue
+		}
+		proof := database.NewMemoryDBManager()
+		if err := trie.Prove(first, 0, proof); err != nil {
+			t.Fatalf("Failed to prove the first node %v", err)
+		}
+		if err := trie.Prove(last, 0, proof); err != nil {
+			t.Fatalf("Failed to prove the last node %v", err)
+		}
+		var keys, values [][]byte
+		for i := start; i < end; i++ {
+			keys = append(keys, entries[i].k)
+			values = append(values, entries[i].v)
+		}
+		if _, err := VerifyRangeProof(trie.Hash(), first, last, keys, values, proof); err != nil {
+			t.Fatalf("Case %d(%d->%d) expect no error, got %v", i, start, end-1, err)
+		}
+	}
+}
+
+// TestRangeProofEdgeCases tests the whole-trie proof, the one element
+// proof and the zero element proof.
+func TestRangeProofEdgeCases(t *testing.T) {
+	trie, vals := randomTrie(512)
+	entries := sortedEntries(vals)
+
+	// All elements without any proof
+	var keys, values [][]byte
+	for _, entry := range entries {
+		keys = append(keys, entry.k)
+		values = append(values, entry.v)
+	}
+	if more, err := VerifyRangeProof(trie.Hash(), nil, nil, keys, values, nil); err != nil || more {
+		t.Fatalf("whole trie proof failed, more %v, err %v", more, err)
+	}
+	// Missing an element breaks the whole trie proof
+	if _, err := VerifyRangeProof(trie.Hash(), nil, nil, keys[1:], values[1:], nil); err == nil {
+		t.Fatal("expected error for an incomplete whole trie proof")
+	}
+
+	// One element with an existent proof
+	start := len(entries) / 2
+	proof := database.NewMemoryDBManager()
+	if err := trie.Prove(entries[start].k, 0, proof); err != nil {
+		t.Fatalf("Failed to prove the node %v", err)
+	}
+	more, err := VerifyRangeProof(trie.Hash(), entries[start].k, entries[start].k, [][]byte{entries[start].k}, [][]byte{entries[start].v}, proof)
+	if err != nil || !more {
+		t.Fatalf("one element proof failed, more %v, err %v", more, err)
+	}
+
+	// Zero element right after the last entry
+	last := increaseKey(common.CopyBytes(entries[len(entries)-1].k))
+	proof = database.NewMemoryDBManager()
+	if err := trie.Prove(last, 0, proof); err != nil {
+		t.Fatalf("Failed to prove the node %v", err)
+	}
+	if _, err := VerifyRangeProof(trie.Hash(), last, nil, nil, nil, proof); err != nil {
+		t.Fatalf("zero element proof failed, err %v", err)
+	}
+
+	// Zero element in the middle of the trie is a lie
+	first := decreaseKey(common.CopyBytes(entries[len(entries)-1].k))
+	proof = database.NewMemoryDBManager()
+	if err := trie.Prove(first, 0, proof); err != nil {
+		t.Fatalf("Failed to prove the node %v", err)
+	}
+	if _, err := VerifyRangeProof(trie.Hash(), first, nil, nil, nil, proof); err == nil {
+		t.Fatal("expected error for a zero element proof with more entries")
+	}
+}
+
+// TestBadRangeProof tests a few cases which the proof is wrong.
+// The prover is expected to detect the error.
+func TestBadRangeProof(t *testing.T) {
+	trie, vals := randomTrie(4096)
+	entries := sortedEntries(vals)
+
+	for i := 0; i < 500; i++ {
+		start := mrand.Intn(len(entries))
+		end := mrand.Intn(len(entries)-start) + start + 1
+		proof := database.NewMemoryDBManager()
+		if err := trie.Prove(entries[start].k, 0, proof); err != nil {
+			t.Fatalf("Failed to prove the first node %v", err)
+		}
+		if err := trie.Prove(entries[end-1].k, 0, proof); err != nil {
+			t.Fatalf("Failed to prove the last node %v", err)
+		}
+		var keys, values [][]byte
+		for j := start; j < end; j++ {
+			keys = append(keys, entries[j].k)
+			values = append(values, entries[j].v)
+		}
+		var first, last = keys[0], keys[len(keys)-1]
+		testcase := mrand.Intn(4)
+		var index int
+		switch testcase {
+		case 0:
+			// Modified key
+			index = mrand.Intn(end - start)
+			keys[index] = randBytes(32) // In theory it can't be same
+		case 1:
+			// Modified val
+			index = mrand.Intn(end - start)
+			values[index] = randBytes(20) // In theory it can't be same
+		case 2:
+			// Gapped entry slice
+			index = mrand.Intn(end - start)
+			if (index == 0 && start < 100) || (index == end-start-1 && end <= 100) {
+				continue
+			}
+			keys = append(keys[:index], keys[index+1:]...)
+			values = append(values[:index], values[index+1:]...)
+		case 3:
+			// Out of order
+			index1 := mrand.Intn(end - start)
+			index2 := mrand.Intn(end - start)
+			if index1 == index2 {
+				continue
+			}
+			keys[index1], keys[index2] = keys[index2], keys[index1]
+			values[index1], values[index2] = values[index2], values[index1]
+		}
+		if _, err := VerifyRangeProof(trie.Hash(), first, last, keys, values, proof); err == nil {
+			t.Fatalf("%d Case %d index %d range: (%d->%d) expect error, got nil", i, testcase, index, start, end-1)
+		}
+	}
+}
+
+func increaseKey(key []byte) []byte {
+	for i := len(key) - 1; i >= 0; i-- {
+		key[i]++
+		if key[i] != 0x0 {
+			break
+		}
+	}
+	return key
+}
+
+func decreaseKey(key []byte) []byte {
+	for i := len(key) - 1; i >= 0; i-- {
+		key[i]--
+		if key[i] != 0xff {
+			break
+		}
+	}
+	return key
+}
+
 // mutateByte changes one byte in b.
 func mutateByte(b []byte) {
 	for r := mrand.Intn(len(b)); ; {
@@ -0,0 +1,89 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/common/profile"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBCData_BlockTime tests if the timestamps of the blocks follow the injected clock and
+// the explicit block timestamps without waiting for the wall clock.
+func TestBCData_BlockTime(t *testing.T) {
+	prof := profile.NewProfiler()
+
+	bcdata, err := NewBCData(6, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bcdata.Shutdown()
+
+	accountMap := NewAccountMap()
+	if err := accountMap.Initialize(bcdata); err != nil {
+		t.Fatal(err)
+	}
+
+	genesisTime := time.Unix(bcdata.bc.CurrentBlock().Time().Int64(), 0)
+	clock := NewTestClock(genesisTime.Add(time.Hour))
+	bcdata.SetClock(clock.Now)
+
+	genBlock := func() int64 {
+		if err := bcdata.GenABlockWithTransactions(accountMap, nil, prof); err != nil {
+			t.Fatal(err)
+		}
+		return bcdata.bc.CurrentBlock().Time().Int64()
+	}
+	start := time.Now()
+
+	// A block gets the time of the clock
+	assert.Equal(t, clock.Now().Unix(), genBlock())
+	clock.Advance(24 * time.Hour)
+	assert.Equal(t, clock.Now().Unix(), genBlock())
+
+	// The blocks are generated every second while the clock does not move
+	for i := int64(1); i <= 5; i++ {
+		assert.Equal(t, clock.Now().Unix()+i, genBlock())
+	}
+
+	// The explicit timestamp overrides the clock only once
+	blockTime := clock.Now().Add(30 * 24 * time.Hour)
+	if err := bcdata.GenABlockWithTransactionsAt(accountMap, nil, blockTime, prof); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, blockTime.Unix(), bcdata.bc.CurrentBlock().Time().Int64())
+	clock.Set(blockTime.Add(time.Minute))
+	assert.Equal(t, clock.Now().Unix(), genBlock())
+
+	// The block not later than its parent or from the future is not generated
+	err = bcdata.GenABlockWithTransactionsAt(accountMap, nil, blockTime, prof)
+	assert.True(t, errors.Is(err, errBlockTimeTooEarly), err)
+	clock.Set(time.Now().Add(time.Hour))
+	assert.True(t, errors.Is(bcdata.GenABlockWithTransactions(accountMap, nil, prof), errBlockTimeTooLate))
+	assert.Equal(t, uint64(9), bcdata.bc.CurrentBlock().NumberU64())
+
+	// No block has waited for the wall clock
+	assert.True(t, time.Since(start) < 5*time.Second)
+
+	// The wall clock is used again without the injected clock
+	bcdata.SetClock(nil)
+	genBlock()
+	assert.InDelta(t, time.Now().Unix(), bcdata.bc.CurrentBlock().Time().Int64(), 1)
+}
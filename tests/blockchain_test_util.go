@@ -0,0 +1,240 @@
+// Copyright 2018 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/crypto/sha3"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+
+	istanbulCore "github.com/klaytn/klaytn/consensus/istanbul/core"
+)
+
+func NewDatabase(dir string, dbType database.DBType) database.DBManager {
+	if dir == "" {
+		return database.NewMemoryDBManager()
+	} else {
+		dbc := &database.DBConfig{Dir: dir, DBType: dbType, LevelDBCacheSize: 768,
+			OpenFilesLimit: 1024, SingleDB: false, NumStateTrieShards: 4, ParallelDBWrite: true,
+			LevelDBCompression: database.AllNoCompression, LevelDBBufferPool: true}
+		return database.NewDBManager(dbc)
+	}
+}
+
+// Copied from consensus/istanbul/backend/engine.go
+func prepareIstanbulExtra(validators []common.Address) ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.Write(bytes.Repeat([]byte{0x0}, types.IstanbulExtraVanity))
+
+	ist := &types.IstanbulExtra{
+		Validators:    validators,
+		Seal:          []byte{},
+		CommittedSeal: [][]byte{},
+	}
+
+	payload, err := rlp.EncodeToBytes(&ist)
+	if err != nil {
+		return nil, err
+	}
+	return append(buf.Bytes(), payload...), nil
+}
+
+func initBlockChain(db database.DBManager, cacheConfig *blockchain.CacheConfig, coinbaseAddrs []*common.Address, validators []common.Address,
+	genesis *blockchain.Genesis, engine consensus.Engine) (*blockchain.BlockChain, *blockchain.Genesis, error) {
+
+	extraData, err := prepareIstanbulExtra(validators)
+
+	if genesis == nil {
+		genesis = blockchain.DefaultGenesisBlock()
+		genesis.Config = Forks["Byzantium"]
+		genesis.ExtraData = extraData
+		genesis.BlockScore = big.NewInt(1)
+		genesis.Config.Governance = params.GetDefaultGovernanceConfig(params.UseIstanbul)
+		genesis.Config.Istanbul = params.GetDefaultIstanbulConfig()
+		genesis.Config.UnitPrice = 25 * params.Ston
+	}
+
+	alloc := make(blockchain.GenesisAlloc)
+	for _, a := range coinbaseAddrs {
+		alloc[*a] = blockchain.GenesisAccount{Balance: new(big.Int).Mul(big.NewInt(1e16), big.NewInt(params.KLAY))}
+	}
+
+	genesis.Alloc = alloc
+
+	chainConfig, _, err := blockchain.SetupGenesisBlock(db, genesis, params.UnusedNetworkId, false, false)
+	if _, ok := err.(*params.ConfigCompatError); err != nil && !ok {
+		return nil, nil, err
+	}
+
+	genesis.Config = chainConfig
+
+	chain, err := blockchain.NewBlockChain(db, cacheConfig, chainConfig, engine, vm.Config{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return chain, genesis, nil
+}
+
+func createAccounts(numAccounts int) ([]*common.Address, []*ecdsa.PrivateKey, error) {
+	accs := make([]*common.Address, numAccounts)
+	privKeys := make([]*ecdsa.PrivateKey, numAccounts)
+
+	for i := 0; i < numAccounts; i++ {
+		k, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, nil, err
+		}
+		keyAddr := crypto.PubkeyToAddress(k.PublicKey)
+
+		accs[i] = &keyAddr
+		privKeys[i] = k
+	}
+
+	return accs, privKeys, nil
+}
+
+// Copied from consensus/istanbul/backend/engine.go
+func sigHash(header *types.Header) (hash common.Hash) {
+	hasher := sha3.NewKeccak256()
+
+	// Clean seal is required for calculating proposer seal.
+	rlp.Encode(hasher, types.IstanbulFilteredHeader(header, false))
+	hasher.Sum(hash[:0])
+	return hash
+}
+
+// writeSeal writes the extra-data field of the given header with the given seals.
+// Copied from consensus/istanbul/backend/engine.go
+func writeSeal(h *types.Header, seal []byte) error {
+	if len(seal)%types.IstanbulExtraSeal != 0 {
+		return errors.New("invalid signature")
+	}
+
+	istanbulExtra, err := types.ExtractIstanbulExtra(h)
+	if err != nil {
+		return err
+	}
+
+	istanbulExtra.Seal = seal
+	payload, err := rlp.EncodeToBytes(&istanbulExtra)
+	if err != nil {
+		return err
+	}
+
+	h.Extra = append(h.Extra[:types.IstanbulExtraVanity], payload...)
+	return nil
+}
+
+// writeCommittedSeals writes the extra-data field of a block header with given committed seals.
+// Copied from consensus/istanbul/backend/engine.go
+func writeCommittedSeals(h *types.Header, committedSeals [][]byte) error {
+	errInvalidCommittedSeals := errors.New("invalid committed seals")
+
+	if len(committedSeals) == 0 {
+		return errInvalidCommittedSeals
+	}
+
+	for _, seal := range committedSeals {
+		if len(seal) != types.IstanbulExtraSeal {
+			return errInvalidCommittedSeals
+		}
+	}
+
+	istanbulExtra, err := types.ExtractIstanbulExtra(h)
+	if err != nil {
+		return err
+	}
+
+	istanbulExtra.CommittedSeal = make([][]byte, len(committedSeals))
+	copy(istanbulExtra.CommittedSeal, committedSeals)
+
+	payload, err := rlp.EncodeToBytes(&istanbulExtra)
+	if err != nil {
+		return err
+	}
+
+	h.Extra = append(h.Extra[:types.IstanbulExtraVanity], payload...)
+	return nil
+}
+
+// sign implements istanbul.backend.Sign
+// Copied from consensus/istanbul/backend/backend.go
+func sign(data []byte, privkey *ecdsa.PrivateKey) ([]byte, error) {
+	hashData := crypto.Keccak256([]byte(data))
+	return crypto.Sign(hashData, privkey)
+}
+
+// makeCommittedSeal makes committed seals of the given header signed by validators
+// except the proposer. If there is only one validator, the proposer signs it.
+func makeCommittedSeal(h *types.Header, privKeys []*ecdsa.PrivateKey) ([][]byte, error) {
+	signers := privKeys
+	if len(privKeys) > 1 {
+		signers = privKeys[1:]
+	}
+	committedSeals := make([][]byte, 0, len(signers))
+
+	for _, privKey := range signers {
+		seal := istanbulCore.PrepareCommittedSeal(h.Hash())
+		committedSeal, err := sign(seal, privKey)
+		if err != nil {
+			return nil, err
+		}
+		committedSeals = append(committedSeals, committedSeal)
+	}
+
+	return committedSeals, nil
+}
+
+func sealBlock(b *types.Block, privKeys []*ecdsa.PrivateKey) (*types.Block, error) {
+	header := b.Header()
+
+	seal, err := sign(sigHash(header).Bytes(), privKeys[0])
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeSeal(header, seal)
+	if err != nil {
+		return nil, err
+	}
+
+	committedSeals, err := makeCommittedSeal(header, privKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeCommittedSeals(header, committedSeals)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.WithSeal(header), nil
+}
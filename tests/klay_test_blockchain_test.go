@@ -17,32 +17,26 @@
 package tests
 
 import (
-	"bytes"
 	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"math/big"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/klaytn/klaytn/blockchain"
 	"github.com/klaytn/klaytn/blockchain/types"
-	"github.com/klaytn/klaytn/blockchain/vm"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/common/profile"
 	"github.com/klaytn/klaytn/consensus"
 	"github.com/klaytn/klaytn/consensus/istanbul"
-	"github.com/klaytn/klaytn/crypto"
-	"github.com/klaytn/klaytn/crypto/sha3"
 	"github.com/klaytn/klaytn/governance"
-	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/reward"
-	"github.com/klaytn/klaytn/rlp"
 	"github.com/klaytn/klaytn/storage/database"
 	"github.com/klaytn/klaytn/work"
 
 	istanbulBackend "github.com/klaytn/klaytn/consensus/istanbul/backend"
-	istanbulCore "github.com/klaytn/klaytn/consensus/istanbul/core"
 )
 
 const transactionsJournalFilename = "transactions.rlp"
@@ -51,9 +45,47 @@ const transactionsJournalFilename = "transactions.rlp"
 const removeChaindataOnExit = true
 
 var (
-	errEmptyPending = errors.New("pending is empty")
+	errEmptyPending      = errors.New("pending is empty")
+	errBlockTimeTooEarly = errors.New("block timestamp should be later than the parent's")
+	errBlockTimeTooLate  = errors.New("block timestamp cannot be later than the wall clock")
 )
 
+// TestClock is a malleable clock which moves only when it is set or advanced.
+// It can be injected to BCData by SetClock to generate blocks with deterministic timestamps.
+type TestClock struct {
+	now time.Time
+	mu  sync.Mutex
+}
+
+// NewTestClock returns a TestClock starting at the given time.
+func NewTestClock(now time.Time) *TestClock {
+	return &TestClock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (c *TestClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Set moves the clock to the given time.
+func (c *TestClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = now
+}
+
+// Advance moves the clock forward by the given duration.
+func (c *TestClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
 type BCData struct {
 	bc                 *blockchain.BlockChain
 	addrs              []*common.Address
@@ -66,6 +98,8 @@ type BCData struct {
 	genesis            *blockchain.Genesis
 	governance         *governance.Governance
 	rewardDistributor  *reward.RewardDistributor
+	clock              func() time.Time // clock giving the timestamps of the blocks, the wall clock if nil
+	nextBlockTime      *time.Time       // explicit timestamp of the next block, overriding the clock once
 }
 
 var dir = "chaindata"
@@ -127,7 +161,20 @@ func NewBCData(maxAccounts, numValidators int) (*BCData, error) {
 
 	return &BCData{bc, addrs, privKeys, chainDb,
 		&genesisAddr, validatorAddresses,
-		validatorPrivKeys, engine, genesis, gov, rewardDistributor}, nil
+		validatorPrivKeys, engine, genesis, gov, rewardDistributor, nil, nil}, nil
+}
+
+// SetClock sets the clock giving the timestamps of the blocks to be generated.
+// A block gets the time of the clock, or its parent's timestamp + 1 if the clock
+// has not moved since the parent. The wall clock is used again if nil is given.
+func (bcdata *BCData) SetClock(clock func() time.Time) {
+	bcdata.clock = clock
+}
+
+// SetNextBlockTime sets the timestamp of the next block regardless of the clock.
+// The timestamp should be later than the parent's and not later than the wall clock.
+func (bcdata *BCData) SetNextBlockTime(t time.Time) {
+	bcdata.nextBlockTime = &t
 }
 
 func (bcdata *BCData) Shutdown() {
@@ -142,10 +189,27 @@ func (bcdata *BCData) Shutdown() {
 }
 
 func (bcdata *BCData) prepareHeader() (*types.Header, error) {
-	tstart := time.Now()
-	parent := bcdata.bc.CurrentBlock()
+	var (
+		parent    = bcdata.bc.CurrentBlock()
+		tstart    = time.Now()
+		malleable = bcdata.clock != nil || bcdata.nextBlockTime != nil
+	)
+	if bcdata.clock != nil {
+		tstart = bcdata.clock()
+	}
 
 	tstamp := tstart.Unix()
+	if bcdata.nextBlockTime != nil {
+		tstamp = bcdata.nextBlockTime.Unix()
+		bcdata.nextBlockTime = nil
+		if parent.Time().Cmp(new(big.Int).SetInt64(tstamp)) >= 0 {
+			return nil, fmt.Errorf("%w: %d <= %d", errBlockTimeTooEarly, tstamp, parent.Time().Int64())
+		}
+	}
+	// A block from the future is rejected, so the clock should not be ahead of the wall clock
+	if now := time.Now().Unix(); malleable && tstamp > now {
+		return nil, fmt.Errorf("%w: %d > %d", errBlockTimeTooLate, tstamp, now)
+	}
 	if parent.Time().Cmp(new(big.Int).SetInt64(tstamp)) >= 0 {
 		tstamp = parent.Time().Int64() + 1
 	}
@@ -167,6 +231,11 @@ func (bcdata *BCData) prepareHeader() (*types.Header, error) {
 	if err := bcdata.engine.Prepare(bcdata.bc, header); err != nil {
 		return nil, errors.New(fmt.Sprintf("Failed to prepare header for mining %s.\n", err))
 	}
+	// Prepare sets the wall clock to the header, so override it with the given time
+	header.Time = big.NewInt(tstamp)
+	if malleable {
+		header.TimeFoS = 0
+	}
 
 	return header, nil
 }
@@ -327,6 +396,15 @@ func (bcdata *BCData) GenABlockWithTxpool(accountMap *AccountMap, txpool *blockc
 	return nil
 }
 
+// GenABlockWithTransactionsAt generates a block with the given transactions and timestamp.
+func (bcdata *BCData) GenABlockWithTransactionsAt(accountMap *AccountMap, transactions types.Transactions,
+	blockTime time.Time, prof *profile.Profiler) error {
+	bcdata.SetNextBlockTime(blockTime)
+	defer func() { bcdata.nextBlockTime = nil }()
+
+	return bcdata.GenABlockWithTransactions(accountMap, transactions, prof)
+}
+
 func (bcdata *BCData) GenABlockWithTransactions(accountMap *AccountMap, transactions types.Transactions,
 	prof *profile.Profiler) error {
 
@@ -379,201 +457,3 @@ func (bcdata *BCData) GenABlockWithTransactions(accountMap *AccountMap, transact
 
 	return nil
 }
-
-////////////////////////////////////////////////////////////////////////////////
-func NewDatabase(dir string, dbType database.DBType) database.DBManager {
-	if dir == "" {
-		return database.NewMemoryDBManager()
-	} else {
-		dbc := &database.DBConfig{Dir: dir, DBType: dbType, LevelDBCacheSize: 768,
-			OpenFilesLimit: 1024, SingleDB: false, NumStateTrieShards: 4, ParallelDBWrite: true,
-			LevelDBCompression: database.AllNoCompression, LevelDBBufferPool: true}
-		return database.NewDBManager(dbc)
-	}
-}
-
-// Copied from consensus/istanbul/backend/engine.go
-func prepareIstanbulExtra(validators []common.Address) ([]byte, error) {
-	var buf bytes.Buffer
-
-	buf.Write(bytes.Repeat([]byte{0x0}, types.IstanbulExtraVanity))
-
-	ist := &types.IstanbulExtra{
-		Validators:    validators,
-		Seal:          []byte{},
-		CommittedSeal: [][]byte{},
-	}
-
-	payload, err := rlp.EncodeToBytes(&ist)
-	if err != nil {
-		return nil, err
-	}
-	return append(buf.Bytes(), payload...), nil
-}
-
-func initBlockChain(db database.DBManager, cacheConfig *blockchain.CacheConfig, coinbaseAddrs []*common.Address, validators []common.Address,
-	genesis *blockchain.Genesis, engine consensus.Engine) (*blockchain.BlockChain, *blockchain.Genesis, error) {
-
-	extraData, err := prepareIstanbulExtra(validators)
-
-	if genesis == nil {
-		genesis = blockchain.DefaultGenesisBlock()
-		genesis.Config = Forks["Byzantium"]
-		genesis.ExtraData = extraData
-		genesis.BlockScore = big.NewInt(1)
-		genesis.Config.Governance = params.GetDefaultGovernanceConfig(params.UseIstanbul)
-		genesis.Config.Istanbul = params.GetDefaultIstanbulConfig()
-		genesis.Config.UnitPrice = 25 * params.Ston
-	}
-
-	alloc := make(blockchain.GenesisAlloc)
-	for _, a := range coinbaseAddrs {
-		alloc[*a] = blockchain.GenesisAccount{Balance: new(big.Int).Mul(big.NewInt(1e16), big.NewInt(params.KLAY))}
-	}
-
-	genesis.Alloc = alloc
-
-	chainConfig, _, err := blockchain.SetupGenesisBlock(db, genesis, params.UnusedNetworkId, false, false)
-	if _, ok := err.(*params.ConfigCompatError); err != nil && !ok {
-		return nil, nil, err
-	}
-
-	genesis.Config = chainConfig
-
-	chain, err := blockchain.NewBlockChain(db, cacheConfig, chainConfig, engine, vm.Config{})
-	if err != nil {
-		return nil, nil, err
-	}
-
-	return chain, genesis, nil
-}
-
-func createAccounts(numAccounts int) ([]*common.Address, []*ecdsa.PrivateKey, error) {
-	accs := make([]*common.Address, numAccounts)
-	privKeys := make([]*ecdsa.PrivateKey, numAccounts)
-
-	for i := 0; i < numAccounts; i++ {
-		k, err := crypto.GenerateKey()
-		if err != nil {
-			return nil, nil, err
-		}
-		keyAddr := crypto.PubkeyToAddress(k.PublicKey)
-
-		accs[i] = &keyAddr
-		privKeys[i] = k
-	}
-
-	return accs, privKeys, nil
-}
-
-// Copied from consensus/istanbul/backend/engine.go
-func sigHash(header *types.Header) (hash common.Hash) {
-	hasher := sha3.NewKeccak256()
-
-	// Clean seal is required for calculating proposer seal.
-	rlp.Encode(hasher, types.IstanbulFilteredHeader(header, false))
-	hasher.Sum(hash[:0])
-	return hash
-}
-
-// writeSeal writes the extra-data field of the given header with the given seals.
-// Copied from consensus/istanbul/backend/engine.go
-func writeSeal(h *types.Header, seal []byte) error {
-	if len(seal)%types.IstanbulExtraSeal != 0 {
-		return errors.New("invalid signature")
-	}
-
-	istanbulExtra, err := types.ExtractIstanbulExtra(h)
-	if err != nil {
-		return err
-	}
-
-	istanbulExtra.Seal = seal
-	payload, err := rlp.EncodeToBytes(&istanbulExtra)
-	if err != nil {
-		return err
-	}
-
-	h.Extra = append(h.Extra[:types.IstanbulExtraVanity], payload...)
-	return nil
-}
-
-// writeCommittedSeals writes the extra-data field of a block header with given committed seals.
-// Copied from consensus/istanbul/backend/engine.go
-func writeCommittedSeals(h *types.Header, committedSeals [][]byte) error {
-	errInvalidCommittedSeals := errors.New("invalid committed seals")
-
-	if len(committedSeals) == 0 {
-		return errInvalidCommittedSeals
-	}
-
-	for _, seal := range committedSeals {
-		if len(seal) != types.IstanbulExtraSeal {
-			return errInvalidCommittedSeals
-		}
-	}
-
-	istanbulExtra, err := types.ExtractIstanbulExtra(h)
-	if err != nil {
-		return err
-	}
-
-	istanbulExtra.CommittedSeal = make([][]byte, len(committedSeals))
-	copy(istanbulExtra.CommittedSeal, committedSeals)
-
-	payload, err := rlp.EncodeToBytes(&istanbulExtra)
-	if err != nil {
-		return err
-	}
-
-	h.Extra = append(h.Extra[:types.IstanbulExtraVanity], payload...)
-	return nil
-}
-
-// sign implements istanbul.backend.Sign
-// Copied from consensus/istanbul/backend/backend.go
-func sign(data []byte, privkey *ecdsa.PrivateKey) ([]byte, error) {
-	hashData := crypto.Keccak256([]byte(data))
-	return crypto.Sign(hashData, privkey)
-}
-
-func makeCommittedSeal(h *types.Header, privKeys []*ecdsa.PrivateKey) ([][]byte, error) {
-	committedSeals := make([][]byte, 0, 3)
-
-	for i := 1; i < 4; i++ {
-		seal := istanbulCore.PrepareCommittedSeal(h.Hash())
-		committedSeal, err := sign(seal, privKeys[i])
-		if err != nil {
-			return nil, err
-		}
-		committedSeals = append(committedSeals, committedSeal)
-	}
-
-	return committedSeals, nil
-}
-
-func sealBlock(b *types.Block, privKeys []*ecdsa.PrivateKey) (*types.Block, error) {
-	header := b.Header()
-
-	seal, err := sign(sigHash(header).Bytes(), privKeys[0])
-	if err != nil {
-		return nil, err
-	}
-
-	err = writeSeal(header, seal)
-	if err != nil {
-		return nil, err
-	}
-
-	committedSeals, err := makeCommittedSeal(header, privKeys)
-	if err != nil {
-		return nil, err
-	}
-
-	err = writeCommittedSeals(header, committedSeals)
-	if err != nil {
-		return nil, err
-	}
-
-	return b.WithSeal(header), nil
-}
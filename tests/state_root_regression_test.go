@@ -0,0 +1,336 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/common/profile"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/governance"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/reward"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/require"
+
+	istanbulBackend "github.com/klaytn/klaytn/consensus/istanbul/backend"
+)
+
+// The state root fixtures are recorded block corpora with the state roots and the receipts of the blocks.
+// TestStateRootRegression imports them through the current code to catch the changes incurring a hard fork.
+//
+// A corpus of the Cypress mainnet or the Baobab testnet can be recorded from the chaindata of a stopped node:
+//
+//	go test -run TestStateRootRegression -stateroot.record=<chaindata> -stateroot.network=baobab -stateroot.blocks=1000
+//
+// Since the blocks are imported from the genesis, a corpus crossing a hardfork boundary of a public network
+// is as large as the boundary block. Such a corpus can be kept outside the tree and tested with
+// -stateroot.fixtures=<dir>. The fixture of a private chain crossing all the hardfork boundaries in a few blocks
+// is regenerated with -stateroot.generate, which should be done only when a new hardfork is added.
+var (
+	stateRootFixtureDir = flag.String("stateroot.fixtures", "stateroots", "Directory of the state root fixtures")
+	stateRootRecordDir  = flag.String("stateroot.record", "", "Chaindata directory to record a state root fixture from")
+	stateRootNetwork    = flag.String("stateroot.network", "cypress", "Network of the chaindata to record (cypress or baobab)")
+	stateRootBlocks     = flag.Uint64("stateroot.blocks", 1000, "Number of blocks to record after the genesis")
+	stateRootGenerate   = flag.Bool("stateroot.generate", false, "Generate the state root fixture crossing the hardfork boundaries")
+)
+
+// stateRootFixture is a block corpus and the results of the blocks recorded by a known good version.
+type stateRootFixture struct {
+	Network  string              `json:"network,omitempty"` // cypress or baobab to use the genesis of the network
+	Genesis  *blockchain.Genesis `json:"genesis,omitempty"` // used if the network is not given
+	Blocks   []hexutil.Bytes     `json:"blocks"`            // RLP encoded blocks following the genesis
+	Expected []stateRootRecord   `json:"expected"`
+}
+
+type stateRootRecord struct {
+	Number       uint64          `json:"number"`
+	Hardfork     string          `json:"hardfork"` // latest hardfork activated at the block
+	StateRoot    common.Hash     `json:"stateRoot"`
+	ReceiptsRoot common.Hash     `json:"receiptsRoot"`
+	Receipts     []receiptRecord `json:"receipts"`
+}
+
+type receiptRecord struct {
+	TxHash  common.Hash `json:"txHash"`
+	Status  uint        `json:"status"`
+	GasUsed uint64      `json:"gasUsed"`
+	Logs    int         `json:"logs"`
+}
+
+func newReceiptRecords(receipts types.Receipts) []receiptRecord {
+	records := make([]receiptRecord, len(receipts))
+	for i, receipt := range receipts {
+		records[i] = receiptRecord{receipt.TxHash, receipt.Status, receipt.GasUsed, len(receipt.Logs)}
+	}
+	return records
+}
+
+// hardforkAt returns the name of the latest hardfork activated at the given block.
+func hardforkAt(config *params.ChainConfig, num *big.Int) string {
+	switch {
+	case config.IsKZG(num):
+		return "kzg"
+	case config.IsBls12381(num):
+		return "bls12381"
+	case config.IsIstanbul(num):
+		return "istanbul"
+	}
+	return "genesis"
+}
+
+func networkGenesis(network string) (*blockchain.Genesis, error) {
+	switch network {
+	case "cypress":
+		return blockchain.DefaultGenesisBlock(), nil
+	case "baobab":
+		return blockchain.DefaultBaobabGenesisBlock(), nil
+	}
+	return nil, fmt.Errorf("unknown network %q", network)
+}
+
+func (f *stateRootFixture) genesis() (*blockchain.Genesis, error) {
+	if f.Network != "" {
+		return networkGenesis(f.Network)
+	}
+	if f.Genesis == nil {
+		return nil, fmt.Errorf("neither network nor genesis is given")
+	}
+	return f.Genesis, nil
+}
+
+func TestStateRootRegression(t *testing.T) {
+	if *stateRootRecordDir != "" {
+		recordStateRootFixture(t)
+		return
+	}
+	if *stateRootGenerate {
+		genStateRootFixture(t)
+		return
+	}
+
+	files, err := filepath.Glob(filepath.Join(*stateRootFixtureDir, "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files, "no state root fixture in %s", *stateRootFixtureDir)
+
+	for _, file := range files {
+		file := file
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			data, err := ioutil.ReadFile(file)
+			require.NoError(t, err)
+
+			var fixture stateRootFixture
+			require.NoError(t, json.Unmarshal(data, &fixture))
+			testStateRootFixture(t, &fixture)
+		})
+	}
+}
+
+// testStateRootFixture imports the blocks of the fixture and compares the state roots and the receipts of
+// the blocks with the recorded ones. The results are reported with the hardfork activated at each block.
+func testStateRootFixture(t *testing.T, fixture *stateRootFixture) {
+	require.Equal(t, len(fixture.Blocks), len(fixture.Expected), "blocks and expected results differ in number")
+
+	genesis, err := fixture.genesis()
+	require.NoError(t, err)
+
+	chainDb := database.NewMemoryDBManager()
+	defer chainDb.Close()
+
+	chainConfig, _, err := blockchain.SetupGenesisBlock(chainDb, genesis, params.UnusedNetworkId, false, false)
+	require.NoError(t, err)
+
+	// The engine only verifies the blocks, so any key can be used as the node key
+	nodeKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	gov := governance.NewGovernanceInitialize(chainConfig, chainDb)
+	engine := istanbulBackend.New(crypto.PubkeyToAddress(nodeKey.PublicKey), istanbul.DefaultConfig, nodeKey, chainDb, gov, common.ENDPOINTNODE)
+	chain, err := blockchain.NewBlockChain(chainDb, nil, chainConfig, engine, vm.Config{})
+	require.NoError(t, err)
+	defer chain.Stop()
+
+	gov.SetBlockchain(chain)
+	if gov.ProposerPolicy() == uint64(istanbul.WeightedRandom) {
+		reward.NewStakingManager(chain, gov, chainDb)
+	}
+
+	verified := make(map[string]int)
+	for i, raw := range fixture.Blocks {
+		var block types.Block
+		require.NoError(t, rlp.DecodeBytes(raw, &block))
+
+		var (
+			want     = fixture.Expected[i]
+			hardfork = hardforkAt(chainConfig, block.Number())
+		)
+		require.Equal(t, want.Number, block.NumberU64(), "block %d is out of order", i)
+		require.Equal(t, want.Hardfork, hardfork, "hardfork schedule changed at block %d", want.Number)
+
+		parent := chain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+		require.NotNil(t, parent, "parent of block %d is missing", want.Number)
+		statedb, err := chain.StateAt(parent.Root())
+		require.NoError(t, err)
+
+		// The block is processed before being inserted, so the mismatches are reported with the computed values
+		receipts, _, _, _, _, err := chain.Processor().Process(&block, statedb, vm.Config{})
+		require.NoError(t, err, "failed to process block %d (%s)", want.Number, hardfork)
+
+		require.Equal(t, want.Receipts, newReceiptRecords(receipts), "receipts mismatch at block %d (%s)", want.Number, hardfork)
+		require.Equal(t, want.ReceiptsRoot, types.DeriveSha(receipts), "receipts root mismatch at block %d (%s)", want.Number, hardfork)
+		require.Equal(t, want.StateRoot, statedb.IntermediateRoot(true), "state root mismatch at block %d (%s)", want.Number, hardfork)
+
+		_, err = chain.InsertChain(types.Blocks{&block})
+		require.NoError(t, err, "failed to insert block %d (%s)", want.Number, hardfork)
+		verified[hardfork]++
+	}
+
+	hardforks := make([]string, 0, len(verified))
+	for hardfork := range verified {
+		hardforks = append(hardforks, hardfork)
+	}
+	sort.Strings(hardforks)
+	for _, hardfork := range hardforks {
+		t.Logf("verified %d blocks of hardfork %s", verified[hardfork], hardfork)
+	}
+}
+
+// recordStateRootFixture records the blocks and their results from the chaindata of a stopped node.
+func recordStateRootFixture(t *testing.T) {
+	genesis, err := networkGenesis(*stateRootNetwork)
+	require.NoError(t, err)
+
+	chainDb := NewDatabase(*stateRootRecordDir, database.LevelDB)
+	defer chainDb.Close()
+
+	fixture, err := newStateRootFixture(chainDb, *stateRootNetwork, genesis.Config, *stateRootBlocks)
+	require.NoError(t, err)
+
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	require.NoError(t, err)
+
+	file := filepath.Join(*stateRootFixtureDir, fmt.Sprintf("%s-1-%d.json", *stateRootNetwork, *stateRootBlocks))
+	require.NoError(t, os.MkdirAll(*stateRootFixtureDir, 0755))
+	require.NoError(t, ioutil.WriteFile(file, data, 0644))
+	t.Logf("recorded %d blocks to %s", *stateRootBlocks, file)
+}
+
+// genStateRootFixture generates blocks activating a hardfork in each block with the current code.
+func genStateRootFixture(t *testing.T) {
+	prof := profile.NewProfiler()
+
+	bcdata, err := NewBCData(6, 4)
+	require.NoError(t, err)
+	defer bcdata.Shutdown()
+
+	// The genesis of the fixture shares the chain config, so it activates the hardforks at the same blocks
+	config := bcdata.bc.Config()
+	config.IstanbulCompatibleBlock = big.NewInt(2)
+	config.Bls12381CompatibleBlock = big.NewInt(3)
+	config.KZGCompatibleBlock = big.NewInt(4)
+	const last = 5
+
+	accountMap := NewAccountMap()
+	require.NoError(t, accountMap.Initialize(bcdata))
+
+	var (
+		signer    = types.NewEIP155Signer(config.ChainID)
+		gasPrice  = new(big.Int).SetUint64(config.UnitPrice)
+		reservoir = &TestAccountType{Addr: *bcdata.addrs[0], Keys: []*ecdsa.PrivateKey{bcdata.privKeys[0]}}
+	)
+	for num := 1; num <= last; num++ {
+		// Each block transfers KLAY to the other accounts and deploys a contract
+		var txs types.Transactions
+		for _, addr := range bcdata.addrs[1:] {
+			tx := types.NewTransaction(reservoir.GetNonce(), *addr, big.NewInt(int64(num)), gasLimit, gasPrice, nil)
+			require.NoError(t, tx.SignWithKeys(signer, reservoir.GetTxKeys()))
+			txs = append(txs, tx)
+			reservoir.AddNonce()
+		}
+		values := map[types.TxValueKeyType]interface{}{
+			types.TxValueKeyNonce:         reservoir.GetNonce(),
+			types.TxValueKeyFrom:          reservoir.GetAddr(),
+			types.TxValueKeyTo:            (*common.Address)(nil),
+			types.TxValueKeyAmount:        big.NewInt(0),
+			types.TxValueKeyGasLimit:      gasLimit,
+			types.TxValueKeyGasPrice:      gasPrice,
+			types.TxValueKeyHumanReadable: false,
+			types.TxValueKeyData:          common.FromHex(code),
+			types.TxValueKeyCodeFormat:    params.CodeFormatEVM,
+		}
+		tx, err := types.NewTransactionWithMap(types.TxTypeSmartContractDeploy, values)
+		require.NoError(t, err)
+		require.NoError(t, tx.SignWithKeys(signer, reservoir.GetTxKeys()))
+		txs = append(txs, tx)
+		reservoir.AddNonce()
+
+		require.NoError(t, bcdata.GenABlockWithTransactions(accountMap, txs, prof))
+	}
+
+	fixture, err := newStateRootFixture(bcdata.db, "", config, last)
+	require.NoError(t, err)
+	fixture.Genesis = bcdata.genesis
+
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(*stateRootFixtureDir, "hardforks.json"), data, 0644))
+}
+
+// newStateRootFixture returns a fixture of the blocks from 1 to last and their results stored in the database.
+func newStateRootFixture(db database.DBManager, network string, config *params.ChainConfig, last uint64) (*stateRootFixture, error) {
+	fixture := &stateRootFixture{Network: network}
+	for num := uint64(1); num <= last; num++ {
+		hash := db.ReadCanonicalHash(num)
+		block := db.ReadBlock(hash, num)
+		if block == nil {
+			return nil, fmt.Errorf("block %d is missing", num)
+		}
+		receipts := db.ReadReceipts(hash, num)
+		if len(receipts) != block.Transactions().Len() {
+			return nil, fmt.Errorf("receipts of block %d are missing", num)
+		}
+		raw, err := rlp.EncodeToBytes(block)
+		if err != nil {
+			return nil, err
+		}
+		fixture.Blocks = append(fixture.Blocks, raw)
+		fixture.Expected = append(fixture.Expected, stateRootRecord{
+			Number:       num,
+			Hardfork:     hardforkAt(config, block.Number()),
+			StateRoot:    block.Root(),
+			ReceiptsRoot: block.ReceiptHash(),
+			Receipts:     newReceiptRecords(receipts),
+		})
+	}
+	return fixture, nil
+}
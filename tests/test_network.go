@@ -0,0 +1,369 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/klaytn/klaytn/governance"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/work"
+
+	istanbulBackend "github.com/klaytn/klaytn/consensus/istanbul/backend"
+)
+
+var (
+	errInvalidTestNetworkConfig = errors.New("invalid test network config")
+	errUnknownSnapshot          = errors.New("unknown snapshot id")
+	errTimeTooFarAhead          = errors.New("the clock cannot be advanced beyond the wall clock")
+)
+
+// DefaultTimeWindow is the default amount of time the genesis block of a TestNetwork
+// is back-dated. Block timestamps must not be in the future, so the clock of a
+// TestNetwork can be advanced by at most this amount.
+const DefaultTimeWindow = 365 * 24 * time.Hour
+
+// TestNetworkConfig contains the parameters to build a TestNetwork.
+type TestNetworkConfig struct {
+	NumValidators int                 // Number of validators. The first NumValidators accounts are used as validators.
+	NumAccounts   int                 // Number of prefunded accounts including validators.
+	Balance       *big.Int            // Initial balance of each prefunded account.
+	ChainConfig   *params.ChainConfig // Chain config of the genesis. A default config is used if nil.
+	DBDir         string              // Directory of the chain database. A memory database is used if empty.
+	TimeWindow    time.Duration       // How far the genesis block is back-dated. DefaultTimeWindow is used if zero.
+}
+
+// DefaultTestNetworkConfig is a 4-validator network with 10 prefunded accounts.
+var DefaultTestNetworkConfig = TestNetworkConfig{
+	NumValidators: 4,
+	NumAccounts:   10,
+	Balance:       new(big.Int).Mul(big.NewInt(1e16), big.NewInt(params.KLAY)),
+	TimeWindow:    DefaultTimeWindow,
+}
+
+// networkSnapshot is a point which a TestNetwork can be rolled back to.
+type networkSnapshot struct {
+	blockNumber uint64
+	blockHash   common.Hash
+	clock       time.Time
+}
+
+// TestNetwork is an in-process Klaytn network consisting of a blockchain and a
+// set of validators sealing blocks with the Istanbul consensus engine.
+// It is meant to be used by integration tests of Klaytn and downstream projects.
+// Blocks are generated only when requested, and the network can be snapshotted,
+// rolled back and fast-forwarded in both time and blocks.
+type TestNetwork struct {
+	bc         *blockchain.BlockChain
+	db         database.DBManager
+	engine     consensus.Istanbul
+	governance *governance.Governance
+	genesis    *blockchain.Genesis
+
+	addrs              []*common.Address
+	privKeys           []*ecdsa.PrivateKey
+	validatorAddresses []common.Address
+	validatorPrivKeys  []*ecdsa.PrivateKey
+	rewardBase         common.Address
+
+	clock     time.Time // timestamp of the next block, at least parent's timestamp + 1
+	snapshots map[int]networkSnapshot
+	nextSnap  int
+
+	mu sync.Mutex
+}
+
+// NewTestNetwork creates a TestNetwork with the given config.
+func NewTestNetwork(config TestNetworkConfig) (*TestNetwork, error) {
+	if config.NumValidators <= 0 || config.NumAccounts < config.NumValidators {
+		return nil, fmt.Errorf("%w: numValidators=%d, numAccounts=%d", errInvalidTestNetworkConfig,
+			config.NumValidators, config.NumAccounts)
+	}
+	if config.Balance == nil {
+		config.Balance = DefaultTestNetworkConfig.Balance
+	}
+	if config.TimeWindow == 0 {
+		config.TimeWindow = DefaultTimeWindow
+	}
+
+	addrs, privKeys, err := createAccounts(config.NumAccounts)
+	if err != nil {
+		return nil, err
+	}
+	validatorAddresses := make([]common.Address, config.NumValidators)
+	validatorPrivKeys := make([]*ecdsa.PrivateKey, config.NumValidators)
+	for i := 0; i < config.NumValidators; i++ {
+		validatorAddresses[i] = *addrs[i]
+		validatorPrivKeys[i] = privKeys[i]
+	}
+
+	extraData, err := prepareIstanbulExtra(validatorAddresses)
+	if err != nil {
+		return nil, err
+	}
+
+	chainConfig := config.ChainConfig
+	if chainConfig == nil {
+		chainConfig = &params.ChainConfig{
+			ChainID:    big.NewInt(1),
+			UnitPrice:  25 * params.Ston,
+			Governance: params.GetDefaultGovernanceConfig(params.UseIstanbul),
+			Istanbul:   params.GetDefaultIstanbulConfig(),
+		}
+	}
+
+	genesisTime := time.Now().Add(-config.TimeWindow)
+	alloc := make(blockchain.GenesisAlloc)
+	for _, addr := range addrs {
+		alloc[*addr] = blockchain.GenesisAccount{Balance: new(big.Int).Set(config.Balance)}
+	}
+	genesis := &blockchain.Genesis{
+		Config:     chainConfig,
+		Timestamp:  uint64(genesisTime.Unix()),
+		ExtraData:  extraData,
+		BlockScore: big.NewInt(1),
+		Alloc:      alloc,
+	}
+
+	db := NewDatabase(config.DBDir, database.LevelDB)
+	chainConfig, _, err = blockchain.SetupGenesisBlock(db, genesis, params.UnusedNetworkId, false, false)
+	if _, ok := err.(*params.ConfigCompatError); err != nil && !ok {
+		db.Close()
+		return nil, err
+	}
+	genesis.Config = chainConfig
+
+	gov := governance.NewGovernanceInitialize(chainConfig, db)
+	gov.SetNodeAddress(validatorAddresses[0])
+
+	engine := istanbulBackend.New(validatorAddresses[0], istanbul.DefaultConfig, validatorPrivKeys[0], db, gov, common.CONSENSUSNODE)
+
+	// Every state is kept in the database so that the network can be rolled back to any block.
+	cacheConfig := &blockchain.CacheConfig{
+		ArchiveMode:   true,
+		CacheSize:     512,
+		BlockInterval: blockchain.DefaultBlockInterval,
+		TriesInMemory: blockchain.DefaultTriesInMemory,
+	}
+	bc, err := blockchain.NewBlockChain(db, cacheConfig, chainConfig, engine, vm.Config{})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	gov.SetBlockchain(bc)
+	engine.Start(bc, bc.CurrentBlock, bc.HasBadBlock)
+
+	return &TestNetwork{
+		bc:                 bc,
+		db:                 db,
+		engine:             engine,
+		governance:         gov,
+		genesis:            genesis,
+		addrs:              addrs,
+		privKeys:           privKeys,
+		validatorAddresses: validatorAddresses,
+		validatorPrivKeys:  validatorPrivKeys,
+		rewardBase:         validatorAddresses[0],
+		clock:              genesisTime.Add(time.Second),
+		snapshots:          make(map[int]networkSnapshot),
+	}, nil
+}
+
+// Shutdown stops the blockchain and the consensus engine and closes the database.
+func (n *TestNetwork) Shutdown() {
+	n.engine.Stop()
+	n.bc.Stop()
+	n.db.Close()
+}
+
+// BlockChain returns the blockchain of the network.
+func (n *TestNetwork) BlockChain() *blockchain.BlockChain { return n.bc }
+
+// Genesis returns the genesis used to initialize the network.
+func (n *TestNetwork) Genesis() *blockchain.Genesis { return n.genesis }
+
+// Governance returns the governance module shared by the validators.
+func (n *TestNetwork) Governance() *governance.Governance { return n.governance }
+
+// Accounts returns the addresses of the prefunded accounts.
+func (n *TestNetwork) Accounts() []*common.Address { return n.addrs }
+
+// PrivKeys returns the private keys of the prefunded accounts in the same order with Accounts.
+func (n *TestNetwork) PrivKeys() []*ecdsa.PrivateKey { return n.privKeys }
+
+// Validators returns the addresses of the validators.
+func (n *TestNetwork) Validators() []common.Address { return n.validatorAddresses }
+
+// Signer returns a signer for the next block.
+func (n *TestNetwork) Signer() types.Signer {
+	return types.MakeSigner(n.bc.Config(), n.bc.CurrentHeader().Number)
+}
+
+// State returns the state of the current head block.
+func (n *TestNetwork) State() (*state.StateDB, error) {
+	return n.bc.State()
+}
+
+// Now returns the clock of the network, which will be the timestamp of the next block.
+func (n *TestNetwork) Now() time.Time {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.clock
+}
+
+// AdvanceTime moves the clock of the network forward by the given duration.
+// Since a block from the future is rejected, the clock cannot pass the wall clock.
+func (n *TestNetwork) AdvanceTime(d time.Duration) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	next := n.clock.Add(d)
+	if next.After(time.Now()) {
+		return errTimeTooFarAhead
+	}
+	n.clock = next
+	return nil
+}
+
+// FastForward generates the given number of empty blocks.
+func (n *TestNetwork) FastForward(numBlocks int) error {
+	for i := 0; i < numBlocks; i++ {
+		if _, _, err := n.MineBlock(nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MineBlock executes the given transactions on top of the current head, seals a block
+// with the validators and inserts it into the blockchain. Transactions failed to be
+// applied are not included in the returned block.
+func (n *TestNetwork) MineBlock(transactions types.Transactions) (*types.Block, types.Receipts, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	parent := n.bc.CurrentBlock()
+	signer := types.MakeSigner(n.bc.Config(), parent.Number())
+
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+	}
+	if err := n.engine.Prepare(n.bc, header); err != nil {
+		return nil, nil, fmt.Errorf("failed to prepare header: %v", err)
+	}
+	// Prepare sets the wall clock to the header, so override it with the network clock.
+	tstamp := n.clock.Unix()
+	if tstamp <= parent.Time().Int64() {
+		tstamp = parent.Time().Int64() + 1
+	}
+	header.Time = big.NewInt(tstamp)
+	header.TimeFoS = 0
+
+	statedb, err := n.bc.StateAt(parent.Root())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	txs := make(map[common.Address]types.Transactions)
+	for _, tx := range transactions {
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			return nil, nil, err
+		}
+		txs[from] = append(txs[from], tx)
+	}
+	txset := types.NewTransactionsByPriceAndNonce(signer, txs)
+
+	task := work.NewTask(n.bc.Config(), signer, statedb, header)
+	task.ApplyTransactions(txset, n.bc, n.rewardBase)
+	receipts := task.Receipts()
+
+	b, err := n.engine.Finalize(n.bc, header, statedb, task.Transactions(), receipts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if b, err = sealBlock(b, n.validatorPrivKeys); err != nil {
+		return nil, nil, err
+	}
+	if i, err := n.bc.InsertChain(types.Blocks{b}); err != nil {
+		return nil, nil, fmt.Errorf("failed to insert block %d: %v", i, err)
+	}
+
+	n.clock = time.Unix(tstamp+1, 0)
+	return b, receipts, nil
+}
+
+// Snapshot records the current head block and the clock, and returns an id
+// which can be passed to Rollback.
+func (n *TestNetwork) Snapshot() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	head := n.bc.CurrentBlock()
+	id := n.nextSnap
+	n.snapshots[id] = networkSnapshot{
+		blockNumber: head.NumberU64(),
+		blockHash:   head.Hash(),
+		clock:       n.clock,
+	}
+	n.nextSnap++
+	return id
+}
+
+// Rollback rewinds the blockchain and the clock to the given snapshot.
+// The snapshot and the ones taken after it are discarded.
+//
+// Only the blockchain is rewound. The governance module keeps the votes, the tallies and
+// the cached governance items of the discarded blocks, so the tests using governance should
+// not roll back across the blocks carrying votes or governance changes.
+func (n *TestNetwork) Rollback(id int) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	snap, ok := n.snapshots[id]
+	if !ok {
+		return errUnknownSnapshot
+	}
+	if err := n.bc.SetHead(snap.blockNumber); err != nil {
+		return err
+	}
+	if head := n.bc.CurrentBlock(); head.Hash() != snap.blockHash {
+		return fmt.Errorf("failed to roll back to block %d: head=%d(%x)", snap.blockNumber, head.NumberU64(), head.Hash())
+	}
+	for i := range n.snapshots {
+		if i >= id {
+			delete(n.snapshots, i)
+		}
+	}
+	n.clock = snap.clock
+	return nil
+}
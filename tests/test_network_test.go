@@ -0,0 +1,101 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeValueTransfer(t *testing.T, n *TestNetwork, from, to int, nonce uint64, amount *big.Int) *types.Transaction {
+	tx := types.NewTransaction(nonce, *n.Accounts()[to], amount, 1000000, new(big.Int).SetUint64(25*params.Ston), nil)
+	signedTx, err := types.SignTx(tx, n.Signer(), n.PrivKeys()[from])
+	require.NoError(t, err)
+	return signedTx
+}
+
+func TestTestNetwork_SnapshotAndRollback(t *testing.T) {
+	n, err := NewTestNetwork(DefaultTestNetworkConfig)
+	require.NoError(t, err)
+	defer n.Shutdown()
+
+	to := *n.Accounts()[5]
+	amount := big.NewInt(params.KLAY)
+
+	statedb, err := n.State()
+	require.NoError(t, err)
+	initialBalance := statedb.GetBalance(to)
+
+	snap := n.Snapshot()
+	clock := n.Now()
+
+	b, receipts, err := n.MineBlock(types.Transactions{makeValueTransfer(t, n, 4, 5, 0, amount)})
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(b.Transactions()))
+	assert.Equal(t, types.ReceiptStatusSuccessful, receipts[0].Status)
+	require.NoError(t, n.FastForward(3))
+	assert.Equal(t, uint64(4), n.BlockChain().CurrentBlock().NumberU64())
+
+	statedb, err = n.State()
+	require.NoError(t, err)
+	assert.Equal(t, new(big.Int).Add(initialBalance, amount), statedb.GetBalance(to))
+
+	require.NoError(t, n.Rollback(snap))
+	assert.Equal(t, uint64(0), n.BlockChain().CurrentBlock().NumberU64())
+	assert.Equal(t, clock, n.Now())
+
+	statedb, err = n.State()
+	require.NoError(t, err)
+	assert.Equal(t, initialBalance, statedb.GetBalance(to))
+
+	// The rolled back snapshot cannot be used again.
+	assert.Equal(t, errUnknownSnapshot, n.Rollback(snap))
+
+	// A transaction with the same nonce can be included again after rollback.
+	b, _, err = n.MineBlock(types.Transactions{makeValueTransfer(t, n, 4, 5, 0, amount)})
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(b.Transactions()))
+}
+
+func TestTestNetwork_AdvanceTime(t *testing.T) {
+	config := DefaultTestNetworkConfig
+	config.NumValidators = 1
+	config.TimeWindow = time.Hour
+
+	n, err := NewTestNetwork(config)
+	require.NoError(t, err)
+	defer n.Shutdown()
+
+	genesisTime := n.Genesis().Timestamp
+
+	b, _, err := n.MineBlock(nil)
+	require.NoError(t, err)
+	assert.Equal(t, genesisTime+1, b.Time().Uint64())
+
+	require.NoError(t, n.AdvanceTime(30*time.Minute))
+	b, _, err = n.MineBlock(nil)
+	require.NoError(t, err)
+	assert.Equal(t, genesisTime+2+uint64((30*time.Minute).Seconds()), b.Time().Uint64())
+
+	assert.Equal(t, errTimeTooFarAhead, n.AdvanceTime(time.Hour))
+}
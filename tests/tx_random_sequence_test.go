@@ -0,0 +1,427 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/types/accountkey"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/stretchr/testify/require"
+)
+
+// randomTxKind is a kind of transaction generated by txSequenceGenerator.
+type randomTxKind int
+
+const (
+	randomTxValueTransfer randomTxKind = iota
+	randomTxFeeDelegatedValueTransfer
+	randomTxFeeDelegatedValueTransferWithRatio
+	randomTxAccountUpdate
+	randomTxFeeDelegatedAccountUpdate
+	randomTxFeeDelegatedAccountUpdateWithRatio
+	numRandomTxKinds
+)
+
+var randomTxKindToTxType = map[randomTxKind]types.TxType{
+	randomTxValueTransfer:                      types.TxTypeValueTransfer,
+	randomTxFeeDelegatedValueTransfer:          types.TxTypeFeeDelegatedValueTransfer,
+	randomTxFeeDelegatedValueTransferWithRatio: types.TxTypeFeeDelegatedValueTransferWithRatio,
+	randomTxAccountUpdate:                      types.TxTypeAccountUpdate,
+	randomTxFeeDelegatedAccountUpdate:          types.TxTypeFeeDelegatedAccountUpdate,
+	randomTxFeeDelegatedAccountUpdateWithRatio: types.TxTypeFeeDelegatedAccountUpdateWithRatio,
+}
+
+func (k randomTxKind) isValueTransfer() bool {
+	return k <= randomTxFeeDelegatedValueTransferWithRatio
+}
+
+func (k randomTxKind) isFeeDelegated() bool {
+	return k != randomTxValueTransfer && k != randomTxAccountUpdate
+}
+
+func (k randomTxKind) hasRatio() bool {
+	return k == randomTxFeeDelegatedValueTransferWithRatio || k == randomTxFeeDelegatedAccountUpdateWithRatio
+}
+
+// txFault is a way to make a generated transaction invalid.
+type txFault int
+
+const (
+	txFaultNone txFault = iota
+	txFaultNonceTooHigh
+	txFaultInsufficientBalance
+	txFaultInvalidSenderSig
+	txFaultInvalidFeePayerSig
+	numTxFaults
+)
+
+func (f txFault) String() string {
+	switch f {
+	case txFaultNone:
+		return "none"
+	case txFaultNonceTooHigh:
+		return "nonceTooHigh"
+	case txFaultInsufficientBalance:
+		return "insufficientBalance"
+	case txFaultInvalidSenderSig:
+		return "invalidSenderSig"
+	case txFaultInvalidFeePayerSig:
+		return "invalidFeePayerSig"
+	}
+	return "unknown"
+}
+
+// modelAccount is an account of the oracle model.
+type modelAccount struct {
+	TestAccountType
+	legacyKey *ecdsa.PrivateKey // the key the address is derived from
+	balance   *big.Int
+}
+
+// generatedTx is a transaction generated by txSequenceGenerator with the effects
+// expected by the oracle model.
+type generatedTx struct {
+	tx       *types.Transaction
+	kind     randomTxKind
+	fault    txFault
+	sender   *modelAccount
+	feePayer *modelAccount
+	to       *modelAccount
+	ratio    types.FeeRatio
+
+	newKeys   []*ecdsa.PrivateKey
+	newAccKey accountkey.AccountKey
+}
+
+// txSequenceGenerator generates random sequences of valid and invalid Klaytn transactions
+// and keeps an oracle model of the state expected after applying them.
+type txSequenceGenerator struct {
+	r        *rand.Rand
+	signer   types.Signer
+	gasLimit uint64
+	gasPrice *big.Int
+	faultPct int // probability in percent that a transaction is made invalid
+	accounts []*modelAccount
+}
+
+func newTxSequenceGenerator(seed int64, signer types.Signer, addrs []*common.Address, keys []*ecdsa.PrivateKey,
+	statedb *state.StateDB) *txSequenceGenerator {
+	accounts := make([]*modelAccount, len(addrs))
+	for i, addr := range addrs {
+		accounts[i] = &modelAccount{
+			TestAccountType: TestAccountType{
+				Addr:   *addr,
+				Keys:   []*ecdsa.PrivateKey{keys[i]},
+				Nonce:  statedb.GetNonce(*addr),
+				AccKey: accountkey.NewAccountKeyLegacy(),
+			},
+			legacyKey: keys[i],
+			balance:   statedb.GetBalance(*addr),
+		}
+	}
+	return &txSequenceGenerator{
+		r:        rand.New(rand.NewSource(seed)),
+		signer:   signer,
+		gasLimit: 1000000,
+		gasPrice: new(big.Int).SetUint64(25 * params.Ston),
+		faultPct: 25,
+		accounts: accounts,
+	}
+}
+
+// randomAccountKey generates a random legacy, public or weighted multisig key for the given account.
+func (g *txSequenceGenerator) randomAccountKey(acc *modelAccount) ([]*ecdsa.PrivateKey, accountkey.AccountKey, error) {
+	switch g.r.Intn(3) {
+	case 0:
+		return []*ecdsa.PrivateKey{acc.legacyKey}, accountkey.NewAccountKeyLegacy(), nil
+	case 1:
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, nil, err
+		}
+		return []*ecdsa.PrivateKey{key}, accountkey.NewAccountKeyPublicWithValue(&key.PublicKey), nil
+	default:
+		numKeys := 2 + g.r.Intn(3)
+		keys := make([]*ecdsa.PrivateKey, numKeys)
+		weightedKeys := make(accountkey.WeightedPublicKeys, numKeys)
+		totalWeight := uint(0)
+		for i := range keys {
+			key, err := crypto.GenerateKey()
+			if err != nil {
+				return nil, nil, err
+			}
+			weight := uint(1 + g.r.Intn(3))
+			keys[i] = key
+			weightedKeys[i] = accountkey.NewWeightedPublicKey(weight, (*accountkey.PublicKeySerializable)(&key.PublicKey))
+			totalWeight += weight
+		}
+		threshold := uint(1 + g.r.Intn(int(totalWeight)))
+		return keys, accountkey.NewAccountKeyWeightedMultiSigWithValues(threshold, weightedKeys), nil
+	}
+}
+
+// randomFault picks a fault applicable to the given kind of transaction.
+func (g *txSequenceGenerator) randomFault(kind randomTxKind) txFault {
+	if g.r.Intn(100) >= g.faultPct {
+		return txFaultNone
+	}
+	for {
+		fault := txFault(1 + g.r.Intn(int(numTxFaults)-1))
+		switch {
+		case fault == txFaultInsufficientBalance && !kind.isValueTransfer():
+		case fault == txFaultInvalidFeePayerSig && !kind.isFeeDelegated():
+		default:
+			return fault
+		}
+	}
+}
+
+// GenBlockTxs generates transactions for a block. Every account is used at most once
+// as a sender or a fee payer so that the validity of a transaction does not depend on
+// the execution order of the transactions in the block.
+func (g *txSequenceGenerator) GenBlockTxs() ([]*generatedTx, error) {
+	perm := g.r.Perm(len(g.accounts))
+	var gtxs []*generatedTx
+
+	for i := 0; i < len(perm); i++ {
+		sender := g.accounts[perm[i]]
+		kind := randomTxKind(g.r.Intn(int(numRandomTxKinds)))
+		if kind.isFeeDelegated() {
+			if i+1 >= len(perm) {
+				kind = randomTxValueTransfer
+			} else {
+				i++
+			}
+		}
+		gtx := &generatedTx{
+			kind:   kind,
+			fault:  g.randomFault(kind),
+			sender: sender,
+			to:     g.accounts[g.r.Intn(len(g.accounts))],
+		}
+		if kind.isFeeDelegated() {
+			gtx.feePayer = g.accounts[perm[i]]
+		}
+		if kind.hasRatio() {
+			gtx.ratio = types.FeeRatio(1 + g.r.Intn(99))
+		}
+		if err := g.makeTx(gtx); err != nil {
+			return nil, err
+		}
+		gtxs = append(gtxs, gtx)
+	}
+	return gtxs, nil
+}
+
+func (g *txSequenceGenerator) makeTx(gtx *generatedTx) error {
+	sender := gtx.sender
+	values := map[types.TxValueKeyType]interface{}{
+		types.TxValueKeyNonce:    sender.Nonce,
+		types.TxValueKeyFrom:     sender.Addr,
+		types.TxValueKeyGasLimit: g.gasLimit,
+		types.TxValueKeyGasPrice: g.gasPrice,
+	}
+	if gtx.fault == txFaultNonceTooHigh {
+		values[types.TxValueKeyNonce] = sender.Nonce + 1
+	}
+
+	if gtx.kind.isValueTransfer() {
+		amount := big.NewInt(1 + g.r.Int63n(params.Ston))
+		if gtx.fault == txFaultInsufficientBalance {
+			// Add 1 KLAY to make sure that the transfer fails even if the sender has received some.
+			amount = new(big.Int).Add(sender.balance, big.NewInt(params.KLAY))
+		}
+		values[types.TxValueKeyTo] = gtx.to.Addr
+		values[types.TxValueKeyAmount] = amount
+	} else {
+		keys, accKey, err := g.randomAccountKey(sender)
+		if err != nil {
+			return err
+		}
+		gtx.newKeys, gtx.newAccKey = keys, accKey
+		values[types.TxValueKeyAccountKey] = accKey
+	}
+	if gtx.kind.isFeeDelegated() {
+		values[types.TxValueKeyFeePayer] = gtx.feePayer.Addr
+	}
+	if gtx.kind.hasRatio() {
+		values[types.TxValueKeyFeeRatioOfFeePayer] = gtx.ratio
+	}
+
+	tx, err := types.NewTransactionWithMap(randomTxKindToTxType[gtx.kind], values)
+	if err != nil {
+		return err
+	}
+
+	senderKeys := sender.Keys
+	if gtx.fault == txFaultInvalidSenderSig {
+		if senderKeys, err = g.wrongKeys(); err != nil {
+			return err
+		}
+	}
+	if err := tx.SignWithKeys(g.signer, senderKeys); err != nil {
+		return err
+	}
+
+	if gtx.kind.isFeeDelegated() {
+		feePayerKeys := gtx.feePayer.Keys
+		if gtx.fault == txFaultInvalidFeePayerSig {
+			if feePayerKeys, err = g.wrongKeys(); err != nil {
+				return err
+			}
+		}
+		if err := tx.SignFeePayerWithKeys(g.signer, feePayerKeys); err != nil {
+			return err
+		}
+	}
+
+	gtx.tx = tx
+	return nil
+}
+
+// wrongKeys returns a key which does not belong to any account.
+func (g *txSequenceGenerator) wrongKeys() ([]*ecdsa.PrivateKey, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	return []*ecdsa.PrivateKey{key}, nil
+}
+
+// Apply updates the oracle model with the given transactions and their receipts.
+// It returns an error if the inclusion of the transactions differs from the model.
+func (g *txSequenceGenerator) Apply(gtxs []*generatedTx, block *types.Block, receipts types.Receipts) error {
+	included := make(map[common.Hash]*types.Receipt, len(receipts))
+	for i, tx := range block.Transactions() {
+		included[tx.Hash()] = receipts[i]
+	}
+
+	for _, gtx := range gtxs {
+		receipt, ok := included[gtx.tx.Hash()]
+		if gtx.fault != txFaultNone {
+			if ok {
+				return fmt.Errorf("invalid tx is included: kind=%d, fault=%s, hash=%s", gtx.kind, gtx.fault, gtx.tx.Hash().String())
+			}
+			continue
+		}
+		if !ok {
+			return fmt.Errorf("valid tx is not included: kind=%d, hash=%s", gtx.kind, gtx.tx.Hash().String())
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			return fmt.Errorf("tx failed: kind=%d, status=%d, hash=%s", gtx.kind, receipt.Status, gtx.tx.Hash().String())
+		}
+
+		// Sender and fee payer are charged for the whole gas limit first, and the remaining is refunded.
+		charged := new(big.Int).Mul(new(big.Int).SetUint64(g.gasLimit), g.gasPrice)
+		refunded := new(big.Int).Mul(new(big.Int).SetUint64(g.gasLimit-receipt.GasUsed), g.gasPrice)
+		switch {
+		case gtx.kind.hasRatio():
+			payerCharged, senderCharged := types.CalcFeeWithRatio(gtx.ratio, charged)
+			payerRefunded, senderRefunded := types.CalcFeeWithRatio(gtx.ratio, refunded)
+			gtx.feePayer.balance.Sub(gtx.feePayer.balance, new(big.Int).Sub(payerCharged, payerRefunded))
+			gtx.sender.balance.Sub(gtx.sender.balance, new(big.Int).Sub(senderCharged, senderRefunded))
+		case gtx.kind.isFeeDelegated():
+			gtx.feePayer.balance.Sub(gtx.feePayer.balance, new(big.Int).Sub(charged, refunded))
+		default:
+			gtx.sender.balance.Sub(gtx.sender.balance, new(big.Int).Sub(charged, refunded))
+		}
+
+		if gtx.kind.isValueTransfer() {
+			gtx.sender.balance.Sub(gtx.sender.balance, gtx.tx.Value())
+			gtx.to.balance.Add(gtx.to.balance, gtx.tx.Value())
+		} else {
+			gtx.sender.Keys, gtx.sender.AccKey = gtx.newKeys, gtx.newAccKey
+		}
+		gtx.sender.Nonce++
+	}
+	return nil
+}
+
+// Verify compares the oracle model with the given state.
+func (g *txSequenceGenerator) Verify(statedb *state.StateDB) error {
+	for _, acc := range g.accounts {
+		if nonce := statedb.GetNonce(acc.Addr); nonce != acc.Nonce {
+			return fmt.Errorf("nonce mismatch: addr=%s, state=%d, model=%d", acc.Addr.String(), nonce, acc.Nonce)
+		}
+		if balance := statedb.GetBalance(acc.Addr); balance.Cmp(acc.balance) != 0 {
+			return fmt.Errorf("balance mismatch: addr=%s, state=%v, model=%v", acc.Addr.String(), balance, acc.balance)
+		}
+		if key := statedb.GetKey(acc.Addr); !key.Equal(acc.AccKey) {
+			return fmt.Errorf("account key mismatch: addr=%s, state=%s, model=%s", acc.Addr.String(), key, acc.AccKey)
+		}
+	}
+	return nil
+}
+
+// TestRandomTxSequence applies randomly generated sequences of transactions and compares
+// the resulting state with an oracle model. It can be run as a long-form test by setting
+// RANDOM_TX_BLOCKS, and a failed sequence can be reproduced by setting RANDOM_TX_SEED.
+func TestRandomTxSequence(t *testing.T) {
+	numBlocks := 10
+	if i, err := strconv.ParseInt(os.Getenv("RANDOM_TX_BLOCKS"), 10, 32); err == nil {
+		numBlocks = int(i)
+	}
+	seed := time.Now().UnixNano()
+	if i, err := strconv.ParseInt(os.Getenv("RANDOM_TX_SEED"), 10, 64); err == nil {
+		seed = i
+	}
+	t.Logf("seed: %d, blocks: %d", seed, numBlocks)
+
+	config := DefaultTestNetworkConfig
+	config.NumAccounts = config.NumValidators + 16
+
+	n, err := NewTestNetwork(config)
+	require.NoError(t, err)
+	defer n.Shutdown()
+
+	statedb, err := n.State()
+	require.NoError(t, err)
+
+	// Validators are excluded from the model since they receive block rewards.
+	g := newTxSequenceGenerator(seed, n.Signer(), n.Accounts()[config.NumValidators:],
+		n.PrivKeys()[config.NumValidators:], statedb)
+
+	for i := 0; i < numBlocks; i++ {
+		gtxs, err := g.GenBlockTxs()
+		require.NoError(t, err)
+
+		txs := make(types.Transactions, len(gtxs))
+		for j, gtx := range gtxs {
+			txs[j] = gtx.tx
+		}
+
+		block, receipts, err := n.MineBlock(txs)
+		require.NoError(t, err)
+		require.NoError(t, g.Apply(gtxs, block, receipts), "seed: %d, block: %d", seed, block.NumberU64())
+
+		statedb, err := n.State()
+		require.NoError(t, err)
+		require.NoError(t, g.Verify(statedb), "seed: %d, block: %d", seed, block.NumberU64())
+	}
+}
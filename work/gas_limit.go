@@ -0,0 +1,80 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package work
+
+import (
+	"github.com/klaytn/klaytn/params"
+)
+
+// CalcGasLimit returns the gas limit of the next block, which moves the gas limit of the parent
+// toward the target by less than 1/GasLimitBoundDivisor of the parent limit. The gas limit never
+// goes below MinGasLimit.
+func CalcGasLimit(parentGasLimit, target uint64) uint64 {
+	if target < params.MinGasLimit {
+		target = params.MinGasLimit
+	}
+	delta := parentGasLimit / params.GasLimitBoundDivisor
+	if delta > 0 {
+		delta--
+	}
+	switch {
+	case parentGasLimit < target:
+		if limit := parentGasLimit + delta; limit < target {
+			return limit
+		}
+		return target
+	case parentGasLimit > target:
+		if limit := parentGasLimit - delta; limit > target {
+			return limit
+		}
+		return target
+	}
+	return target
+}
+
+// gasLimitTracker keeps the gas limit of the blocks built by the node, which follows the target
+// gradually over successive blocks. Since the gas limit is not a part of the header, it is a local
+// policy of the block building and is not verified by the other nodes.
+type gasLimitTracker struct {
+	target uint64 // target gas limit, 0 if the blocks are not limited
+	limit  uint64 // gas limit of the last block, 0 if the blocks are not limited
+	number uint64 // number of the last block
+}
+
+// setTarget changes the target gas limit. The gas limit starts from the target if the blocks
+// have not been limited, or moves from the current limit otherwise.
+func (t *gasLimitTracker) setTarget(target uint64) {
+	t.target = target
+	if target == 0 {
+		t.limit = 0
+	}
+}
+
+// next returns the gas limit of the block of the given number, 0 if not limited. The gas limit
+// steps toward the target only once per block number, so rebuilding a block keeps its limit.
+func (t *gasLimitTracker) next(number uint64) uint64 {
+	switch {
+	case t.target == 0:
+		t.limit = 0
+	case t.limit == 0:
+		t.limit = CalcGasLimit(t.target, t.target)
+	case number != t.number:
+		t.limit = CalcGasLimit(t.limit, t.target)
+	}
+	t.number = number
+	return t.limit
+}
@@ -0,0 +1,93 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package work
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/work/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalcGasLimit(t *testing.T) {
+	// The gas limit moves toward the target by less than 1/1024 of the parent limit
+	assert.Equal(t, uint64(10249999), CalcGasLimit(10240000, 20000000))
+	assert.Equal(t, uint64(10230001), CalcGasLimit(10240000, 5000000))
+	assert.Equal(t, uint64(10240000), CalcGasLimit(10235000, 10240000))
+	assert.Equal(t, uint64(10240000), CalcGasLimit(10240000, 10240000))
+	assert.Equal(t, params.MinGasLimit, CalcGasLimit(params.MinGasLimit, 0))
+}
+
+func TestGasLimitTracker(t *testing.T) {
+	var tracker gasLimitTracker
+	assert.Zero(t, tracker.next(1))
+
+	// The gas limit starts from the target if the blocks have not been limited
+	tracker.setTarget(10240000)
+	assert.Equal(t, uint64(10240000), tracker.next(2))
+
+	// The gas limit follows the target once per block
+	tracker.setTarget(20480000)
+	assert.Equal(t, uint64(10240000), tracker.next(2))
+	assert.Equal(t, uint64(10249999), tracker.next(3))
+	assert.Equal(t, uint64(10249999), tracker.next(3))
+	assert.Equal(t, uint64(10260007), tracker.next(4))
+
+	tracker.setTarget(0)
+	assert.Zero(t, tracker.next(5))
+}
+
+// TestTask_GasPool tests if the transactions are not applied beyond the gas limit of the block,
+// and the gas left by the transactions is given back to the block.
+func TestTask_GasPool(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	bc := mocks.NewMockBlockChain(mockCtrl)
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil)
+	assert.NoError(t, err)
+	header := &types.Header{Number: big.NewInt(1)}
+	env := NewTask(params.TestChainConfig, types.NewEIP155Signer(params.TestChainConfig.ChainID), statedb, header)
+	env.gasPool = new(blockchain.GasPool).AddGas(50000)
+
+	tx := func(nonce, gas uint64) *types.Transaction {
+		return types.NewTransaction(nonce, common.Address{0x01}, big.NewInt(0), gas, big.NewInt(1), nil)
+	}
+	bc.EXPECT().ApplyTransaction(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&types.Receipt{GasUsed: 21000}, uint64(0), nil, nil).Times(2)
+
+	err, _ = env.commitTransaction(tx(0, 30000), bc, common.Address{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(29000), env.gasPool.Gas())
+
+	err, _ = env.commitTransaction(tx(1, 30000), bc, common.Address{}, nil)
+	assert.Equal(t, blockchain.ErrGasLimitReached, err)
+	assert.Equal(t, uint64(29000), env.gasPool.Gas())
+
+	err, _ = env.commitTransaction(tx(1, 21000), bc, common.Address{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8000), env.gasPool.Gas())
+	assert.Len(t, env.Transactions(), 2)
+}
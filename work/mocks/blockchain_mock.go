@@ -12,6 +12,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	blockchain "github.com/klaytn/klaytn/blockchain"
 	state "github.com/klaytn/klaytn/blockchain/state"
+	snapshot "github.com/klaytn/klaytn/blockchain/state/snapshot"
 	types "github.com/klaytn/klaytn/blockchain/types"
 	vm "github.com/klaytn/klaytn/blockchain/vm"
 	common "github.com/klaytn/klaytn/common"
@@ -76,6 +77,20 @@ func (mr *MockBlockChainMockRecorder) BadBlocks() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BadBlocks", reflect.TypeOf((*MockBlockChain)(nil).BadBlocks))
 }
 
+// BlockStoreStatus mocks base method
+func (m *MockBlockChain) BlockStoreStatus() blockchain.BlockStoreStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockStoreStatus")
+	ret0, _ := ret[0].(blockchain.BlockStoreStatus)
+	return ret0
+}
+
+// BlockStoreStatus indicates an expected call of BlockStoreStatus
+func (mr *MockBlockChainMockRecorder) BlockStoreStatus() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockStoreStatus", reflect.TypeOf((*MockBlockChain)(nil).BlockStoreStatus))
+}
+
 // BlockSubscriptionLoop mocks base method
 func (m *MockBlockChain) BlockSubscriptionLoop(arg0 *blockchain.TxPool) {
 	m.ctrl.T.Helper()
@@ -156,6 +171,20 @@ func (mr *MockBlockChainMockRecorder) CurrentHeader() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CurrentHeader", reflect.TypeOf((*MockBlockChain)(nil).CurrentHeader))
 }
 
+// DumpTrieNodeCacheToDisk mocks base method
+func (m *MockBlockChain) DumpTrieNodeCacheToDisk(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DumpTrieNodeCacheToDisk", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DumpTrieNodeCacheToDisk indicates an expected call of DumpTrieNodeCacheToDisk
+func (mr *MockBlockChainMockRecorder) DumpTrieNodeCacheToDisk(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DumpTrieNodeCacheToDisk", reflect.TypeOf((*MockBlockChain)(nil).DumpTrieNodeCacheToDisk), arg0)
+}
+
 // Engine mocks base method
 func (m *MockBlockChain) Engine() consensus.Engine {
 	m.ctrl.T.Helper()
@@ -198,6 +227,21 @@ func (mr *MockBlockChainMockRecorder) FastSyncCommitHead(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FastSyncCommitHead", reflect.TypeOf((*MockBlockChain)(nil).FastSyncCommitHead), arg0)
 }
 
+// GenerateWitness mocks base method
+func (m *MockBlockChain) GenerateWitness(arg0 *types.Block) (*blockchain.Witness, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateWitness", arg0)
+	ret0, _ := ret[0].(*blockchain.Witness)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateWitness indicates an expected call of GenerateWitness
+func (mr *MockBlockChainMockRecorder) GenerateWitness(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateWitness", reflect.TypeOf((*MockBlockChain)(nil).GenerateWitness), arg0)
+}
+
 // Genesis mocks base method
 func (m *MockBlockChain) Genesis() *types.Block {
 	m.ctrl.T.Helper()
@@ -594,6 +638,20 @@ func (mr *MockBlockChainMockRecorder) IsSenderTxHashIndexingEnabled() *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSenderTxHashIndexingEnabled", reflect.TypeOf((*MockBlockChain)(nil).IsSenderTxHashIndexingEnabled))
 }
 
+// LoadTrieNodeCache mocks base method
+func (m *MockBlockChain) LoadTrieNodeCache(arg0 io.ReadCloser) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadTrieNodeCache", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LoadTrieNodeCache indicates an expected call of LoadTrieNodeCache
+func (mr *MockBlockChainMockRecorder) LoadTrieNodeCache(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadTrieNodeCache", reflect.TypeOf((*MockBlockChain)(nil).LoadTrieNodeCache), arg0)
+}
+
 // PostChainEvents mocks base method
 func (m *MockBlockChain) PostChainEvents(arg0 []interface{}, arg1 []*types.Log) {
 	m.ctrl.T.Helper()
@@ -674,6 +732,20 @@ func (mr *MockBlockChainMockRecorder) SaveTrieNodeCacheToDisk() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTrieNodeCacheToDisk", reflect.TypeOf((*MockBlockChain)(nil).SaveTrieNodeCacheToDisk))
 }
 
+// SetBodyRetention mocks base method
+func (m *MockBlockChain) SetBodyRetention(arg0 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBodyRetention", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBodyRetention indicates an expected call of SetBodyRetention
+func (mr *MockBlockChainMockRecorder) SetBodyRetention(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBodyRetention", reflect.TypeOf((*MockBlockChain)(nil).SetBodyRetention), arg0)
+}
+
 // SetHead mocks base method
 func (m *MockBlockChain) SetHead(arg0 uint64) error {
 	m.ctrl.T.Helper()
@@ -700,6 +772,18 @@ func (mr *MockBlockChainMockRecorder) SetProposerPolicy(arg0 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProposerPolicy", reflect.TypeOf((*MockBlockChain)(nil).SetProposerPolicy), arg0)
 }
 
+// SetTxLookupLimit mocks base method
+func (m *MockBlockChain) SetTxLookupLimit(arg0 uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTxLookupLimit", arg0)
+}
+
+// SetTxLookupLimit indicates an expected call of SetTxLookupLimit
+func (mr *MockBlockChainMockRecorder) SetTxLookupLimit(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTxLookupLimit", reflect.TypeOf((*MockBlockChain)(nil).SetTxLookupLimit), arg0)
+}
+
 // SetUseGiniCoeff mocks base method
 func (m *MockBlockChain) SetUseGiniCoeff(arg0 bool) {
 	m.ctrl.T.Helper()
@@ -712,6 +796,20 @@ func (mr *MockBlockChainMockRecorder) SetUseGiniCoeff(arg0 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUseGiniCoeff", reflect.TypeOf((*MockBlockChain)(nil).SetUseGiniCoeff), arg0)
 }
 
+// SlowestBlockImports mocks base method
+func (m *MockBlockChain) SlowestBlockImports(arg0 int) []*blockchain.BlockImportStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SlowestBlockImports", arg0)
+	ret0, _ := ret[0].([]*blockchain.BlockImportStats)
+	return ret0
+}
+
+// SlowestBlockImports indicates an expected call of SlowestBlockImports
+func (mr *MockBlockChainMockRecorder) SlowestBlockImports(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SlowestBlockImports", reflect.TypeOf((*MockBlockChain)(nil).SlowestBlockImports), arg0)
+}
+
 // StartCollectingTrieStats mocks base method
 func (m *MockBlockChain) StartCollectingTrieStats(arg0 common.Address) error {
 	m.ctrl.T.Helper()
@@ -842,6 +940,20 @@ func (mr *MockBlockChainMockRecorder) StateCache() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateCache", reflect.TypeOf((*MockBlockChain)(nil).StateCache))
 }
 
+// Snapshots mocks base method
+func (m *MockBlockChain) Snapshots() *snapshot.Tree {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshots")
+	ret0, _ := ret[0].(*snapshot.Tree)
+	return ret0
+}
+
+// Snapshots indicates an expected call of Snapshots
+func (mr *MockBlockChainMockRecorder) Snapshots() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshots", reflect.TypeOf((*MockBlockChain)(nil).Snapshots))
+}
+
 // StateMigrationStatus mocks base method
 func (m *MockBlockChain) StateMigrationStatus() (bool, uint64, int, int, int, float64, error) {
 	m.ctrl.T.Helper()
@@ -930,6 +1042,20 @@ func (mr *MockBlockChainMockRecorder) SubscribeChainHeadEvent(arg0 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeChainHeadEvent", reflect.TypeOf((*MockBlockChain)(nil).SubscribeChainHeadEvent), arg0)
 }
 
+// SubscribeChainReorgEvent mocks base method
+func (m *MockBlockChain) SubscribeChainReorgEvent(arg0 chan<- blockchain.ChainReorgEvent) event.Subscription {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeChainReorgEvent", arg0)
+	ret0, _ := ret[0].(event.Subscription)
+	return ret0
+}
+
+// SubscribeChainReorgEvent indicates an expected call of SubscribeChainReorgEvent
+func (mr *MockBlockChainMockRecorder) SubscribeChainReorgEvent(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeChainReorgEvent", reflect.TypeOf((*MockBlockChain)(nil).SubscribeChainReorgEvent), arg0)
+}
+
 // SubscribeChainSideEvent mocks base method
 func (m *MockBlockChain) SubscribeChainSideEvent(arg0 chan<- blockchain.ChainSideEvent) event.Subscription {
 	m.ctrl.T.Helper()
@@ -987,6 +1113,20 @@ func (mr *MockBlockChainMockRecorder) TrieNode(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrieNode", reflect.TypeOf((*MockBlockChain)(nil).TrieNode), arg0)
 }
 
+// TxIndexStatus mocks base method
+func (m *MockBlockChain) TxIndexStatus() blockchain.TxIndexStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxIndexStatus")
+	ret0, _ := ret[0].(blockchain.TxIndexStatus)
+	return ret0
+}
+
+// TxIndexStatus indicates an expected call of TxIndexStatus
+func (mr *MockBlockChainMockRecorder) TxIndexStatus() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxIndexStatus", reflect.TypeOf((*MockBlockChain)(nil).TxIndexStatus))
+}
+
 // Validator mocks base method
 func (m *MockBlockChain) Validator() blockchain.Validator {
 	m.ctrl.T.Helper()
@@ -1001,6 +1141,20 @@ func (mr *MockBlockChainMockRecorder) Validator() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validator", reflect.TypeOf((*MockBlockChain)(nil).Validator))
 }
 
+// VerifyWitness mocks base method
+func (m *MockBlockChain) VerifyWitness(arg0 *blockchain.Witness) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyWitness", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyWitness indicates an expected call of VerifyWitness
+func (mr *MockBlockChainMockRecorder) VerifyWitness(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyWitness", reflect.TypeOf((*MockBlockChain)(nil).VerifyWitness), arg0)
+}
+
 // WriteBlockWithState mocks base method
 func (m *MockBlockChain) WriteBlockWithState(arg0 *types.Block, arg1 []*types.Receipt, arg2 *state.StateDB) (blockchain.WriteResult, error) {
 	m.ctrl.T.Helper()
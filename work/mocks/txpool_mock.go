@@ -38,6 +38,34 @@ func (m *MockTxPool) EXPECT() *MockTxPoolMockRecorder {
 	return m.recorder
 }
 
+// AccessHints mocks base method
+func (m *MockTxPool) AccessHints() *blockchain.TxAccessHints {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AccessHints")
+	ret0, _ := ret[0].(*blockchain.TxAccessHints)
+	return ret0
+}
+
+// AccessHints indicates an expected call of AccessHints
+func (mr *MockTxPoolMockRecorder) AccessHints() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccessHints", reflect.TypeOf((*MockTxPool)(nil).AccessHints))
+}
+
+// AccountQueueStatus mocks base method
+func (m *MockTxPool) AccountQueueStatus(arg0 common.Address) *blockchain.AccountQueueStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AccountQueueStatus", arg0)
+	ret0, _ := ret[0].(*blockchain.AccountQueueStatus)
+	return ret0
+}
+
+// AccountQueueStatus indicates an expected call of AccountQueueStatus
+func (mr *MockTxPoolMockRecorder) AccountQueueStatus(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccountQueueStatus", reflect.TypeOf((*MockTxPool)(nil).AccountQueueStatus), arg0)
+}
+
 // AddLocal mocks base method
 func (m *MockTxPool) AddLocal(arg0 *types.Transaction) error {
 	m.ctrl.T.Helper()
@@ -52,6 +80,20 @@ func (mr *MockTxPoolMockRecorder) AddLocal(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLocal", reflect.TypeOf((*MockTxPool)(nil).AddLocal), arg0)
 }
 
+// Arrivals mocks base method
+func (m *MockTxPool) Arrivals() *blockchain.TxArrivals {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Arrivals")
+	ret0, _ := ret[0].(*blockchain.TxArrivals)
+	return ret0
+}
+
+// Arrivals indicates an expected call of Arrivals
+func (mr *MockTxPoolMockRecorder) Arrivals() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Arrivals", reflect.TypeOf((*MockTxPool)(nil).Arrivals))
+}
+
 // CachedPendingTxsByCount mocks base method
 func (m *MockTxPool) CachedPendingTxsByCount(arg0 int) types.Transactions {
 	m.ctrl.T.Helper()
@@ -81,6 +123,35 @@ func (mr *MockTxPoolMockRecorder) Content() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Content", reflect.TypeOf((*MockTxPool)(nil).Content))
 }
 
+// ContentFrom mocks base method
+func (m *MockTxPool) ContentFrom(arg0 common.Address) (types.Transactions, types.Transactions) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContentFrom", arg0)
+	ret0, _ := ret[0].(types.Transactions)
+	ret1, _ := ret[1].(types.Transactions)
+	return ret0, ret1
+}
+
+// ContentFrom indicates an expected call of ContentFrom
+func (mr *MockTxPoolMockRecorder) ContentFrom(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContentFrom", reflect.TypeOf((*MockTxPool)(nil).ContentFrom), arg0)
+}
+
+// Denylist mocks base method
+func (m *MockTxPool) Denylist() *blockchain.TxDenylist {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Denylist")
+	ret0, _ := ret[0].(*blockchain.TxDenylist)
+	return ret0
+}
+
+// Denylist indicates an expected call of Denylist
+func (mr *MockTxPoolMockRecorder) Denylist() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Denylist", reflect.TypeOf((*MockTxPool)(nil).Denylist))
+}
+
 // GasPrice mocks base method
 func (m *MockTxPool) GasPrice() *big.Int {
 	m.ctrl.T.Helper()
@@ -135,6 +206,20 @@ func (mr *MockTxPoolMockRecorder) HandleTxMsg(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleTxMsg", reflect.TypeOf((*MockTxPool)(nil).HandleTxMsg), arg0)
 }
 
+// InvalidChainIdTxs mocks base method
+func (m *MockTxPool) InvalidChainIdTxs() int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidChainIdTxs")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+// InvalidChainIdTxs indicates an expected call of InvalidChainIdTxs
+func (mr *MockTxPoolMockRecorder) InvalidChainIdTxs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidChainIdTxs", reflect.TypeOf((*MockTxPool)(nil).InvalidChainIdTxs))
+}
+
 // Pending mocks base method
 func (m *MockTxPool) Pending() (map[common.Address]types.Transactions, error) {
 	m.ctrl.T.Helper()
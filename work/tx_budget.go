@@ -0,0 +1,193 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package work
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+)
+
+// The classes of the transactions sharing the gas budget of a block.
+const (
+	// TxClassUser is the class of the transactions paying their own fees, except for anchoring.
+	TxClassUser = "user"
+	// TxClassFeeDelegated is the class of the fee delegated transactions, except for anchoring.
+	TxClassFeeDelegated = "feedelegated"
+	// TxClassAnchoring is the class of the chain data anchoring transactions.
+	TxClassAnchoring = "anchoring"
+)
+
+// TxClasses is the list of the transaction classes.
+var TxClasses = []string{TxClassUser, TxClassFeeDelegated, TxClassAnchoring}
+
+var errTxBudgetReached = errors.New("tx class budget reached")
+
+// TxClassOf returns the class of the transaction.
+func TxClassOf(tx *types.Transaction) string {
+	switch {
+	case tx.Type().IsChainDataAnchoring():
+		return TxClassAnchoring
+	case tx.IsFeeDelegatedTransaction():
+		return TxClassFeeDelegated
+	default:
+		return TxClassUser
+	}
+}
+
+// TxClassBudget is the share of the block gas budget for a class of transactions, in percent.
+type TxClassBudget struct {
+	MinShare uint64 `json:"minShare"` // reserved for the class while it has pending transactions
+	MaxShare uint64 `json:"maxShare"` // the class cannot use more than this
+}
+
+// TxBudgetConfig partitions the gas budget of a block by the class of transactions, to prevent a
+// class of transactions from monopolizing the blocks. The classes not configured can use the whole budget.
+type TxBudgetConfig struct {
+	BlockGas uint64                   `json:"blockGas"` // gas budget of a block, 0 disables the partitioning
+	Classes  map[string]TxClassBudget `json:"classes"`
+}
+
+// Validate returns an error if the configuration is malformed.
+func (c *TxBudgetConfig) Validate() error {
+	var reserved uint64
+	for class, budget := range c.Classes {
+		if !isTxClass(class) {
+			return fmt.Errorf("unknown tx class %q, available classes: %v", class, TxClasses)
+		}
+		if budget.MaxShare > 100 || budget.MinShare > budget.MaxShare {
+			return fmt.Errorf("invalid budget of tx class %q: min share %d%% and max share %d%% should be 0 <= min <= max <= 100",
+				class, budget.MinShare, budget.MaxShare)
+		}
+		reserved += budget.MinShare
+	}
+	if reserved > 100 {
+		return fmt.Errorf("sum of the min shares of tx classes (%d%%) exceeds 100%%", reserved)
+	}
+	return nil
+}
+
+// ParseTxClassBudgets parses the budgets of tx classes given in the form of
+// "class:minShare:maxShare,...", e.g. "user:30:100,anchoring:0:10".
+func ParseTxClassBudgets(s string) (map[string]TxClassBudget, error) {
+	budgets := make(map[string]TxClassBudget)
+	if s == "" {
+		return budgets, nil
+	}
+	for _, item := range strings.Split(s, ",") {
+		fields := strings.Split(strings.TrimSpace(item), ":")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid tx class budget %q, should be class:minShare:maxShare", item)
+		}
+		minShare, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid min share of tx class budget %q: %v", item, err)
+		}
+		maxShare, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max share of tx class budget %q: %v", item, err)
+		}
+		budgets[fields[0]] = TxClassBudget{MinShare: minShare, MaxShare: maxShare}
+	}
+	return budgets, nil
+}
+
+func isTxClass(class string) bool {
+	for _, c := range TxClasses {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// txBudget tracks the gas used by each class of transactions while a block is built.
+type txBudget struct {
+	total    uint64
+	used     uint64
+	reserves map[string]uint64 // gas reserved for the classes having pending transactions
+	caps     map[string]uint64
+	classUse map[string]uint64
+}
+
+// newTxBudget returns the budget of a block built with the pending transactions,
+// or nil if the partitioning is disabled.
+func newTxBudget(config TxBudgetConfig, pending map[common.Address]types.Transactions) *txBudget {
+	if config.BlockGas == 0 {
+		return nil
+	}
+	present := make(map[string]bool)
+	for _, txs := range pending {
+		for _, tx := range txs {
+			present[TxClassOf(tx)] = true
+		}
+	}
+	b := &txBudget{
+		total:    config.BlockGas,
+		reserves: make(map[string]uint64),
+		caps:     make(map[string]uint64),
+		classUse: make(map[string]uint64),
+	}
+	for class, budget := range config.Classes {
+		if present[class] && budget.MinShare > 0 {
+			b.reserves[class] = config.BlockGas / 100 * budget.MinShare
+		}
+		b.caps[class] = config.BlockGas / 100 * budget.MaxShare
+	}
+	return b
+}
+
+// available returns the gas the class can use, excluding the gas reserved for the other classes.
+func (b *txBudget) available(class string) uint64 {
+	avail := b.total - b.used
+	for c, reserve := range b.reserves {
+		if c != class && reserve > b.classUse[c] {
+			if unused := reserve - b.classUse[c]; unused < avail {
+				avail -= unused
+			} else {
+				return 0
+			}
+		}
+	}
+	if limit, ok := b.caps[class]; ok {
+		if b.classUse[class] >= limit {
+			return 0
+		}
+		if rest := limit - b.classUse[class]; rest < avail {
+			avail = rest
+		}
+	}
+	return avail
+}
+
+// check returns errTxBudgetReached if the gas limit of the transaction exceeds the budget of its class.
+func (b *txBudget) check(tx *types.Transaction) error {
+	if tx.Gas() > b.available(TxClassOf(tx)) {
+		return errTxBudgetReached
+	}
+	return nil
+}
+
+// use accounts the gas used by the transaction.
+func (b *txBudget) use(tx *types.Transaction, gasUsed uint64) {
+	b.used += gasUsed
+	b.classUse[TxClassOf(tx)] += gasUsed
+}
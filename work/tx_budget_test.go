@@ -0,0 +1,115 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package work
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxBudget(t *testing.T) {
+	var (
+		user        = common.Address{0x01}
+		payer       = common.Address{0x02}
+		anchor      = common.Address{0x03}
+		newTxOfType = func(txType types.TxType, gas uint64) *types.Transaction {
+			values := map[types.TxValueKeyType]interface{}{
+				types.TxValueKeyNonce:    uint64(0),
+				types.TxValueKeyFrom:     anchor,
+				types.TxValueKeyGasLimit: gas,
+				types.TxValueKeyGasPrice: big.NewInt(1),
+			}
+			switch txType {
+			case types.TxTypeChainDataAnchoring:
+				values[types.TxValueKeyAnchoredData] = []byte{0x01}
+			case types.TxTypeFeeDelegatedValueTransfer:
+				values[types.TxValueKeyFrom] = payer
+				values[types.TxValueKeyTo] = user
+				values[types.TxValueKeyAmount] = big.NewInt(1)
+				values[types.TxValueKeyFeePayer] = payer
+			}
+			tx, err := types.NewTransactionWithMap(txType, values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return tx
+		}
+		userTx = func(gas uint64) *types.Transaction {
+			return types.NewTransaction(0, anchor, big.NewInt(0), gas, big.NewInt(1), nil)
+		}
+		feeDelegatedTx = func(gas uint64) *types.Transaction {
+			return newTxOfType(types.TxTypeFeeDelegatedValueTransfer, gas)
+		}
+		anchoringTx = func(gas uint64) *types.Transaction {
+			return newTxOfType(types.TxTypeChainDataAnchoring, gas)
+		}
+	)
+	assert.Equal(t, TxClassUser, TxClassOf(userTx(21000)))
+	assert.Equal(t, TxClassFeeDelegated, TxClassOf(feeDelegatedTx(21000)))
+	assert.Equal(t, TxClassAnchoring, TxClassOf(anchoringTx(21000)))
+
+	classes, err := ParseTxClassBudgets("user:30:100, anchoring:0:10")
+	assert.NoError(t, err)
+	config := TxBudgetConfig{BlockGas: 1000000, Classes: classes}
+	assert.NoError(t, config.Validate())
+
+	// No budget if the partitioning is disabled
+	assert.Nil(t, newTxBudget(TxBudgetConfig{Classes: classes}, nil))
+
+	budget := newTxBudget(config, map[common.Address]types.Transactions{
+		user:   {userTx(21000)},
+		payer:  {feeDelegatedTx(21000)},
+		anchor: {anchoringTx(21000)},
+	})
+
+	// Anchoring txs are capped to 10%
+	assert.NoError(t, budget.check(anchoringTx(100000)))
+	budget.use(anchoringTx(100000), 60000)
+	assert.Equal(t, errTxBudgetReached, budget.check(anchoringTx(50000)))
+
+	// 30% is reserved for user txs
+	assert.Equal(t, errTxBudgetReached, budget.check(feeDelegatedTx(700000)))
+	assert.NoError(t, budget.check(feeDelegatedTx(640000)))
+	budget.use(feeDelegatedTx(640000), 600000)
+
+	// User txs can use the rest of the budget
+	assert.NoError(t, budget.check(userTx(340000)))
+	assert.Equal(t, errTxBudgetReached, budget.check(userTx(340001)))
+
+	// The budget is not reserved for the classes without pending txs
+	budget = newTxBudget(config, map[common.Address]types.Transactions{payer: {feeDelegatedTx(21000)}})
+	assert.NoError(t, budget.check(feeDelegatedTx(1000000)))
+
+	// Malformed configurations
+	_, err = ParseTxClassBudgets("user:30")
+	assert.Error(t, err)
+	_, err = ParseTxClassBudgets("user:a:100")
+	assert.Error(t, err)
+	for _, classes := range []map[string]TxClassBudget{
+		{"unknown": {MinShare: 0, MaxShare: 100}},
+		{TxClassUser: {MinShare: 50, MaxShare: 40}},
+		{TxClassUser: {MinShare: 0, MaxShare: 101}},
+		{TxClassUser: {MinShare: 60, MaxShare: 100}, TxClassAnchoring: {MinShare: 50, MaxShare: 100}},
+	} {
+		config := TxBudgetConfig{BlockGas: 1000000, Classes: classes}
+		assert.Error(t, config.Validate(), classes)
+	}
+}
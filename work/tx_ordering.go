@@ -0,0 +1,184 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package work
+
+import (
+	"bytes"
+	"container/heap"
+	"fmt"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+)
+
+// The policies ordering the transactions in a block. The transactions of a sender are
+// always ordered by nonce, and the policies decide which sender's transaction comes next.
+// The ties are broken by the transaction hash, so the order is deterministic.
+const (
+	// TxOrderingPrice orders the transactions by gas price. It is the default policy.
+	TxOrderingPrice = "price"
+	// TxOrderingPriceTime orders the transactions by gas price, and then by arrival.
+	TxOrderingPriceTime = "price-time"
+	// TxOrderingFIFO orders the transactions by arrival at the transaction pool.
+	TxOrderingFIFO = "fifo"
+	// TxOrderingRoundRobin takes a transaction from each sender in turn, in the order of arrival.
+	TxOrderingRoundRobin = "round-robin"
+)
+
+// DefaultTxOrderingPolicy is the policy used if no policy is given.
+const DefaultTxOrderingPolicy = TxOrderingPrice
+
+// TxOrderingPolicies is the list of the available transaction ordering policies.
+var TxOrderingPolicies = []string{TxOrderingPrice, TxOrderingPriceTime, TxOrderingFIFO, TxOrderingRoundRobin}
+
+// ValidateTxOrderingPolicy returns an error if the given transaction ordering policy is unknown.
+func ValidateTxOrderingPolicy(policy string) error {
+	for _, p := range TxOrderingPolicies {
+		if p == policy {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown tx ordering policy %q, available policies: %v", policy, TxOrderingPolicies)
+}
+
+// TransactionSet is a set of the pending transactions, which returns the transactions
+// in the order they are applied to a block while honouring the nonces.
+type TransactionSet interface {
+	// Peek returns the next transaction.
+	Peek() *types.Transaction
+	// Shift replaces the current transaction with the next one of the same sender.
+	Shift()
+	// Pop removes the current transaction and the remaining ones of the same sender.
+	Pop()
+}
+
+// NewTransactionSet creates a transaction set ordering the pending transactions by the given policy.
+// The arrival orders are used by the policies other than TxOrderingPrice.
+//
+// Note, the input map is reowned so the caller should not interact any more with it.
+func NewTransactionSet(policy string, signer types.Signer, pending map[common.Address]types.Transactions, arrivals *blockchain.TxArrivals) TransactionSet {
+	var less func(a, b *orderedTx) bool
+	switch policy {
+	case TxOrderingPriceTime:
+		less = func(a, b *orderedTx) bool {
+			if cmp := a.tx.GasPrice().Cmp(b.tx.GasPrice()); cmp != 0 {
+				return cmp > 0
+			}
+			return a.arrivedBefore(b)
+		}
+	case TxOrderingFIFO:
+		less = func(a, b *orderedTx) bool {
+			return a.arrivedBefore(b)
+		}
+	case TxOrderingRoundRobin:
+		less = func(a, b *orderedTx) bool {
+			if a.round != b.round {
+				return a.round < b.round
+			}
+			return a.arrivedBefore(b)
+		}
+	default:
+		return types.NewTransactionsByPriceAndNonce(signer, pending)
+	}
+
+	set := &orderedTxSet{
+		txs:      pending,
+		heads:    orderedTxHeap{less: less},
+		arrivals: arrivals,
+	}
+	for from, txs := range pending {
+		set.heads.txs = append(set.heads.txs, set.newOrderedTx(from, txs[0], 0))
+		set.txs[from] = txs[1:]
+	}
+	heap.Init(&set.heads)
+	return set
+}
+
+// orderedTx is the next transaction of a sender.
+type orderedTx struct {
+	tx      *types.Transaction
+	hash    common.Hash
+	from    common.Address
+	arrival uint64 // arrival order at the transaction pool
+	round   int    // number of the transactions of the sender taken before
+}
+
+// arrivedBefore orders the transactions by arrival, and then by hash.
+func (a *orderedTx) arrivedBefore(b *orderedTx) bool {
+	if a.arrival != b.arrival {
+		return a.arrival < b.arrival
+	}
+	return bytes.Compare(a.hash[:], b.hash[:]) < 0
+}
+
+type orderedTxHeap struct {
+	txs  []*orderedTx
+	less func(a, b *orderedTx) bool
+}
+
+func (h orderedTxHeap) Len() int            { return len(h.txs) }
+func (h orderedTxHeap) Less(i, j int) bool  { return h.less(h.txs[i], h.txs[j]) }
+func (h orderedTxHeap) Swap(i, j int)       { h.txs[i], h.txs[j] = h.txs[j], h.txs[i] }
+func (h *orderedTxHeap) Push(x interface{}) { h.txs = append(h.txs, x.(*orderedTx)) }
+
+func (h *orderedTxHeap) Pop() interface{} {
+	old := h.txs
+	n := len(old)
+	x := old[n-1]
+	h.txs = old[0 : n-1]
+	return x
+}
+
+// orderedTxSet is a TransactionSet ordering the senders by a transaction ordering policy.
+type orderedTxSet struct {
+	txs      map[common.Address]types.Transactions // Per account nonce-sorted list of the remaining transactions
+	heads    orderedTxHeap                         // Next transaction for each unique account
+	arrivals *blockchain.TxArrivals
+}
+
+func (s *orderedTxSet) newOrderedTx(from common.Address, tx *types.Transaction, round int) *orderedTx {
+	hash := tx.Hash()
+	return &orderedTx{
+		tx:      tx,
+		hash:    hash,
+		from:    from,
+		arrival: s.arrivals.Order(hash),
+		round:   round,
+	}
+}
+
+func (s *orderedTxSet) Peek() *types.Transaction {
+	if len(s.heads.txs) == 0 {
+		return nil
+	}
+	return s.heads.txs[0].tx
+}
+
+func (s *orderedTxSet) Shift() {
+	head := s.heads.txs[0]
+	if txs, ok := s.txs[head.from]; ok && len(txs) > 0 {
+		s.heads.txs[0], s.txs[head.from] = s.newOrderedTx(head.from, txs[0], head.round+1), txs[1:]
+		heap.Fix(&s.heads, 0)
+	} else {
+		heap.Pop(&s.heads)
+	}
+}
+
+func (s *orderedTxSet) Pop() {
+	heap.Pop(&s.heads)
+}
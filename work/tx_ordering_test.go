@@ -0,0 +1,93 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package work
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTransactionSet(t *testing.T) {
+	signer := types.NewEIP155Signer(big.NewInt(1))
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	newTx := func(key *ecdsa.PrivateKey, nonce uint64, price int64) *types.Transaction {
+		tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{0x01}, big.NewInt(0), 100000, big.NewInt(price), nil), signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+
+	var (
+		a0, a1, a2 = newTx(keys[0], 0, 2), newTx(keys[0], 1, 2), newTx(keys[0], 2, 2)
+		b0, b1     = newTx(keys[1], 0, 1), newTx(keys[1], 1, 1)
+		c0         = newTx(keys[2], 0, 2)
+		arrivals   = blockchain.NewTxArrivals(100)
+	)
+	for _, tx := range []*types.Transaction{b0, b1, a0, a1, a2, c0, b0} {
+		arrivals.Record(tx.Hash())
+	}
+	pending := func() map[common.Address]types.Transactions {
+		return map[common.Address]types.Transactions{
+			crypto.PubkeyToAddress(keys[0].PublicKey): {a0, a1, a2},
+			crypto.PubkeyToAddress(keys[1].PublicKey): {b0, b1},
+			crypto.PubkeyToAddress(keys[2].PublicKey): {c0},
+		}
+	}
+	collect := func(set TransactionSet) []*types.Transaction {
+		var txs []*types.Transaction
+		for tx := set.Peek(); tx != nil; tx = set.Peek() {
+			txs = append(txs, tx)
+			set.Shift()
+		}
+		return txs
+	}
+
+	assert.IsType(t, &types.TransactionsByPriceAndNonce{}, NewTransactionSet(TxOrderingPrice, signer, pending(), arrivals))
+	assert.Equal(t, []*types.Transaction{a0, a1, a2, c0, b0, b1}, collect(NewTransactionSet(TxOrderingPriceTime, signer, pending(), arrivals)))
+	assert.Equal(t, []*types.Transaction{b0, b1, a0, a1, a2, c0}, collect(NewTransactionSet(TxOrderingFIFO, signer, pending(), arrivals)))
+	assert.Equal(t, []*types.Transaction{b0, a0, c0, b1, a1, a2}, collect(NewTransactionSet(TxOrderingRoundRobin, signer, pending(), arrivals)))
+
+	// Pop skips the remaining transactions of the sender
+	set := NewTransactionSet(TxOrderingFIFO, signer, pending(), arrivals)
+	set.Shift()
+	set.Shift()
+	assert.Equal(t, a0, set.Peek())
+	set.Pop()
+	assert.Equal(t, []*types.Transaction{c0}, collect(set))
+
+	// The transactions of unknown arrivals come last
+	d0 := newTx(keys[2], 0, 3)
+	set = NewTransactionSet(TxOrderingFIFO, signer, map[common.Address]types.Transactions{
+		crypto.PubkeyToAddress(keys[1].PublicKey): {b0},
+		crypto.PubkeyToAddress(keys[2].PublicKey): {d0},
+	}, arrivals)
+	assert.Equal(t, []*types.Transaction{b0, d0}, collect(set))
+
+	assert.NoError(t, ValidateTxOrderingPolicy(TxOrderingRoundRobin))
+	assert.Error(t, ValidateTxOrderingPolicy("lifo"))
+}
@@ -0,0 +1,155 @@
+// Copyright 2021 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package work
+
+import (
+	"bytes"
+	"runtime"
+	"sort"
+	"sync/atomic"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+	"github.com/rcrowley/go-metrics"
+)
+
+var txGroupsGauge = metrics.NewRegisteredGauge("miner/txgroups", nil)
+
+// groupTxsByAccess partitions the pending transactions into the groups of the transactions
+// predicted to access common accounts by the access hints of the transaction pool.
+// The transactions of different groups are expected not to conflict, so they can be executed
+// concurrently. The conflicting transactions in a group are ordered by price and nonce, and
+// the groups are ordered by their senders, so the result is deterministic.
+//
+// The accounts in exclude, such as the rewardbase receiving the transaction fees, are not
+// regarded as a conflict.
+func groupTxsByAccess(signer types.Signer, pending map[common.Address]types.Transactions, hints *blockchain.TxAccessHints, exclude ...common.Address) []types.Transactions {
+	senders := make([]common.Address, 0, len(pending))
+	for sender := range pending {
+		senders = append(senders, sender)
+	}
+	sort.Slice(senders, func(i, j int) bool { return bytes.Compare(senders[i][:], senders[j][:]) < 0 })
+
+	// Union the senders accessing a common account
+	parents := make([]int, len(senders))
+	var find func(i int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+	excluded := make(map[common.Address]struct{}, len(exclude))
+	for _, addr := range exclude {
+		excluded[addr] = struct{}{}
+	}
+	owners := make(map[common.Address]int)
+	for i, sender := range senders {
+		parents[i] = i
+		for _, tx := range pending[sender] {
+			for _, addr := range hints.Predict(signer, tx) {
+				if _, ok := excluded[addr]; ok {
+					continue
+				}
+				owner, ok := owners[addr]
+				if !ok {
+					owners[addr] = i
+					continue
+				}
+				// Keep the smaller index as the root to order the groups by the first sender
+				if ri, ro := find(i), find(owner); ri < ro {
+					parents[ro] = ri
+				} else if ro < ri {
+					parents[ri] = ro
+				}
+			}
+		}
+	}
+
+	// Collect the transactions of each group
+	var (
+		indices = make(map[int]int)
+		members []map[common.Address]types.Transactions
+	)
+	for i, sender := range senders {
+		root := find(i)
+		idx, ok := indices[root]
+		if !ok {
+			idx = len(members)
+			indices[root] = idx
+			members = append(members, make(map[common.Address]types.Transactions))
+		}
+		members[idx][sender] = pending[sender]
+	}
+	groups := make([]types.Transactions, len(members))
+	for i, txs := range members {
+		set := types.NewTransactionsByPriceAndNonce(signer, txs)
+		for tx := set.Peek(); tx != nil; tx = set.Peek() {
+			groups[i] = append(groups[i], tx)
+			set.Shift()
+		}
+	}
+	txGroupsGauge.Update(int64(len(groups)))
+	return groups
+}
+
+// prefetchTxGroups executes the groups of the transactions concurrently on the copies of the given
+// state, while the transactions are executed serially to build a block. The results are discarded,
+// since the only goal is to warm up the caches of the state. It stops when interrupt is set.
+func prefetchTxGroups(config *params.ChainConfig, bc BlockChain, header *types.Header, statedb *state.StateDB, rewardbase common.Address, groups []types.Transactions, interrupt *int32) {
+	if len(groups) < 2 {
+		// There is nothing to be executed concurrently
+		return
+	}
+	type prefetchTask struct {
+		txs     types.Transactions
+		statedb *state.StateDB
+	}
+	var (
+		base = statedb.Copy() // the given state is modified by the caller after returning
+		jobs = make(chan *prefetchTask, len(groups))
+	)
+	for th := 0; th < runtime.NumCPU() && th < len(groups); th++ {
+		go func() {
+			vmConfig := &vm.Config{UseOpcodeComputationCost: true}
+			for task := range jobs {
+				usedGas := uint64(0)
+				for i, tx := range task.txs {
+					if atomic.LoadInt32(interrupt) == 1 {
+						return
+					}
+					task.statedb.Prepare(tx.Hash(), common.Hash{}, i)
+					bc.ApplyTransaction(config, &rewardbase, task.statedb, header, tx, &usedGas, vmConfig)
+				}
+			}
+		}()
+	}
+	// Copying a state is not thread-safe, so the copies are made by a single goroutine
+	go func() {
+		defer close(jobs)
+		for _, txs := range groups {
+			if atomic.LoadInt32(interrupt) == 1 {
+				return
+			}
+			jobs <- &prefetchTask{txs: txs, statedb: base.Copy()}
+		}
+	}()
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/klaytn/klaytn/accounts"
 	"github.com/klaytn/klaytn/blockchain"
 	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/state/snapshot"
 	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/blockchain/vm"
 	"github.com/klaytn/klaytn/common"
@@ -67,6 +68,22 @@ type TxPool interface {
 	Get(hash common.Hash) *types.Transaction
 	Stats() (int, int)
 	Content() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
+	ContentFrom(addr common.Address) (types.Transactions, types.Transactions)
+
+	// AccessHints should return the predictor of the accounts accessed by transactions.
+	AccessHints() *blockchain.TxAccessHints
+
+	// Arrivals should return the arrival order of transactions.
+	Arrivals() *blockchain.TxArrivals
+
+	// Denylist should return the accounts whose transactions are refused.
+	Denylist() *blockchain.TxDenylist
+
+	// AccountQueueStatus should return the status of the transactions of the account.
+	AccountQueueStatus(addr common.Address) *blockchain.AccountQueueStatus
+
+	// InvalidChainIdTxs should return the number of the transactions rejected for another chain id.
+	InvalidChainIdTxs() int64
 }
 
 // Backend wraps all methods required for mining.
@@ -93,12 +110,12 @@ type Miner struct {
 	shouldStart int32 // should start indicates whether we should start after sync
 }
 
-func New(backend Backend, config *params.ChainConfig, mux *event.TypeMux, engine consensus.Engine, nodetype common.ConnType, rewardbase common.Address, TxResendUseLegacy bool) *Miner {
+func New(backend Backend, config *params.ChainConfig, mux *event.TypeMux, engine consensus.Engine, nodetype common.ConnType, rewardbase common.Address, TxResendUseLegacy bool, instantSeal bool) *Miner {
 	miner := &Miner{
 		backend:  backend,
 		mux:      mux,
 		engine:   engine,
-		worker:   newWorker(config, engine, rewardbase, backend, mux, nodetype, TxResendUseLegacy),
+		worker:   newWorker(config, engine, rewardbase, backend, mux, nodetype, TxResendUseLegacy, instantSeal),
 		canStart: 1,
 	}
 	// TODO-Klaytn drop or missing tx
@@ -202,6 +219,51 @@ func (self *Miner) SetExtra(extra []byte) error {
 	return nil
 }
 
+// SetTxOrderingPolicy sets the policy ordering the transactions in the blocks built afterwards.
+func (self *Miner) SetTxOrderingPolicy(policy string) error {
+	if err := ValidateTxOrderingPolicy(policy); err != nil {
+		return err
+	}
+	self.worker.setTxOrderingPolicy(policy)
+	return nil
+}
+
+// TxOrderingPolicy returns the policy ordering the transactions in a block.
+func (self *Miner) TxOrderingPolicy() string {
+	return self.worker.txOrderingPolicy()
+}
+
+// SetTxBudget sets the partitioning of the gas budget by tx class for the blocks built afterwards.
+func (self *Miner) SetTxBudget(config TxBudgetConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	self.worker.setTxBudget(config)
+	return nil
+}
+
+// TxBudget returns the partitioning of the gas budget by tx class.
+func (self *Miner) TxBudget() TxBudgetConfig {
+	return self.worker.txBudgetConfig()
+}
+
+// SetTargetGasLimit sets the target gas limit of the blocks built afterwards, which the gas limit of
+// the blocks approaches by less than 1/GasLimitBoundDivisor of the limit per block. The blocks are not
+// limited by gas if the target is 0.
+func (self *Miner) SetTargetGasLimit(target uint64) {
+	self.worker.setTargetGasLimit(target)
+}
+
+// TargetGasLimit returns the target gas limit of the blocks, 0 if the blocks are not limited.
+func (self *Miner) TargetGasLimit() uint64 {
+	return self.worker.targetGasLimit()
+}
+
+// GasLimit returns the effective gas limit of the last block built, 0 if the blocks are not limited.
+func (self *Miner) GasLimit() uint64 {
+	return self.worker.currentGasLimit()
+}
+
 // Pending returns the currently pending block and associated state.
 func (self *Miner) Pending() (*types.Block, *state.StateDB) {
 	return self.worker.pending()
@@ -251,12 +313,14 @@ type BlockChain interface {
 	InsertHeaderChain(chain []*types.Header, checkFreq int) (int, error)
 	FastSyncCommitHead(hash common.Hash) error
 	StateCache() state.Database
+	Snapshots() *snapshot.Tree
 
 	SubscribeChainEvent(ch chan<- blockchain.ChainEvent) event.Subscription
 	SetHead(head uint64) error
 	Stop()
 
 	SubscribeRemovedLogsEvent(ch chan<- blockchain.RemovedLogsEvent) event.Subscription
+	SubscribeChainReorgEvent(ch chan<- blockchain.ChainReorgEvent) event.Subscription
 	SubscribeChainHeadEvent(ch chan<- blockchain.ChainHeadEvent) event.Subscription
 	SubscribeChainSideEvent(ch chan<- blockchain.ChainSideEvent) event.Subscription
 	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
@@ -269,6 +333,7 @@ type BlockChain interface {
 
 	Processor() blockchain.Processor
 	BadBlocks() ([]blockchain.BadBlockArgs, error)
+	SlowestBlockImports(n int) []*blockchain.BlockImportStats
 	StateAt(root common.Hash) (*state.StateDB, error)
 	StateAtWithPersistent(root common.Hash) (*state.StateDB, error)
 	StateAtWithGCLock(root common.Hash) (*state.StateDB, error)
@@ -304,8 +369,22 @@ type BlockChain interface {
 
 	// Save trie node cache to this
 	SaveTrieNodeCacheToDisk() error
+	DumpTrieNodeCacheToDisk(dumpPath string) error
+	LoadTrieNodeCache(r io.ReadCloser) error
+
+	// Transaction lookup index
+	SetTxLookupLimit(limit uint64)
+	TxIndexStatus() blockchain.TxIndexStatus
+
+	// Block body pruning
+	SetBodyRetention(retention uint64) error
+	BlockStoreStatus() blockchain.BlockStoreStatus
 
 	// KES
 	BlockSubscriptionLoop(pool *blockchain.TxPool)
 	CloseBlockSubscriptionLoop()
+
+	// Execution witness
+	GenerateWitness(block *types.Block) (*blockchain.Witness, error)
+	VerifyWitness(witness *blockchain.Witness) error
 }
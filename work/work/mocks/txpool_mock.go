@@ -38,6 +38,20 @@ func (m *MockTxPool) EXPECT() *MockTxPoolMockRecorder {
 	return m.recorder
 }
 
+// AccessHints mocks base method
+func (m *MockTxPool) AccessHints() *blockchain.TxAccessHints {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AccessHints")
+	ret0, _ := ret[0].(*blockchain.TxAccessHints)
+	return ret0
+}
+
+// AccessHints indicates an expected call of AccessHints
+func (mr *MockTxPoolMockRecorder) AccessHints() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccessHints", reflect.TypeOf((*MockTxPool)(nil).AccessHints))
+}
+
 // AddLocal mocks base method
 func (m *MockTxPool) AddLocal(arg0 *types.Transaction) error {
 	m.ctrl.T.Helper()
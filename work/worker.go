@@ -67,6 +67,8 @@ var (
 	nonceTooLowTxsGauge     = metrics.NewRegisteredGauge("miner/nonce/low/txs", nil)
 	nonceTooHighTxsGauge    = metrics.NewRegisteredGauge("miner/nonce/high/txs", nil)
 	gasLimitReachedTxsGauge = metrics.NewRegisteredGauge("miner/limitreached/gas/txs", nil)
+	budgetReachedTxsGauge   = metrics.NewRegisteredGauge("miner/limitreached/budget/txs", nil)
+	deniedTxsGauge          = metrics.NewRegisteredGauge("miner/denied/txs", nil)
 	strangeErrorTxsCounter  = metrics.NewRegisteredCounter("miner/strangeerror/txs", nil)
 
 	blockMiningTimer          = klaytnmetrics.NewRegisteredHybridTimer("miner/block/mining/time", nil)
@@ -109,6 +111,9 @@ type Task struct {
 	header   *types.Header
 	txs      []*types.Transaction
 	receipts []*types.Receipt
+	budget   *txBudget              // gas budget of the tx classes, nil if not partitioned
+	gasPool  *blockchain.GasPool    // gas available in the block, nil if not limited
+	denylist *blockchain.TxDenylist // accounts whose transactions are not included, nil if none
 
 	createdAt time.Time
 }
@@ -143,7 +148,10 @@ type worker struct {
 	proc    blockchain.Validator
 	chainDB database.DBManager
 
-	extra []byte
+	extra      []byte
+	txOrdering string          // policy ordering the transactions in a block
+	txBudget   TxBudgetConfig  // partitioning of the block gas budget by tx class
+	gasLimit   gasLimitTracker // gas limit of the blocks following the target
 
 	currentMu  sync.Mutex
 	current    *Task
@@ -158,9 +166,13 @@ type worker struct {
 	atWork int32
 
 	nodetype common.ConnType
+
+	// instantSeal makes the worker seal a block as soon as a transaction arrives
+	// and stay idle instead of sealing empty blocks. It is used by the developer mode.
+	instantSeal bool
 }
 
-func newWorker(config *params.ChainConfig, engine consensus.Engine, rewardbase common.Address, backend Backend, mux *event.TypeMux, nodetype common.ConnType, TxResendUseLegacy bool) *worker {
+func newWorker(config *params.ChainConfig, engine consensus.Engine, rewardbase common.Address, backend Backend, mux *event.TypeMux, nodetype common.ConnType, TxResendUseLegacy bool, instantSeal bool) *worker {
 	worker := &worker{
 		config:      config,
 		engine:      engine,
@@ -176,6 +188,8 @@ func newWorker(config *params.ChainConfig, engine consensus.Engine, rewardbase c
 		agents:      make(map[Agent]struct{}),
 		nodetype:    nodetype,
 		rewardbase:  rewardbase,
+		instantSeal: instantSeal,
+		txOrdering:  DefaultTxOrderingPolicy,
 	}
 
 	// Subscribe NewTxsEvent for tx pool
@@ -195,6 +209,48 @@ func (self *worker) setExtra(extra []byte) {
 	self.extra = extra
 }
 
+func (self *worker) setTxOrderingPolicy(policy string) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.txOrdering = policy
+}
+
+func (self *worker) txOrderingPolicy() string {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.txOrdering
+}
+
+func (self *worker) setTxBudget(config TxBudgetConfig) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.txBudget = config
+}
+
+func (self *worker) txBudgetConfig() TxBudgetConfig {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.txBudget
+}
+
+func (self *worker) setTargetGasLimit(target uint64) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.gasLimit.setTarget(target)
+}
+
+func (self *worker) targetGasLimit() uint64 {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.gasLimit.target
+}
+
+func (self *worker) currentGasLimit() uint64 {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.gasLimit.limit
+}
+
 func (self *worker) pending() (*types.Block, *state.StateDB) {
 	if atomic.LoadInt32(&self.mining) == 0 {
 		// return a snapshot to avoid contention on currentMu mutex
@@ -286,6 +342,11 @@ func (self *worker) handleTxsCh(quitByErr chan bool) {
 				if self.config.Clique != nil && self.config.Clique.Period == 0 {
 					self.commitNewWork()
 				}
+				// In the instant seal mode, wake only if the current work is empty.
+				// Otherwise, new transactions are included in the next block.
+				if self.instantSeal && !self.hasPendingTxs() {
+					self.commitNewWork()
+				}
 			}
 
 		case <-quitByErr:
@@ -531,10 +592,28 @@ func (self *worker) commitNewWork() {
 	// Create the current work task
 	work := self.current
 	if self.nodetype == common.CONSENSUSNODE {
-		txs := types.NewTransactionsByPriceAndNonce(self.current.signer, pending)
+		// Execute the independent groups of the transactions concurrently to warm up the state
+		// while the transactions are applied in order.
+		accessHints := self.backend.TxPool().AccessHints()
+		groups := groupTxsByAccess(self.current.signer, pending, accessHints, self.rewardbase)
+		var interruptPrefetch int32
+		prefetchTxGroups(self.config, self.chain, header, work.state, self.rewardbase, groups, &interruptPrefetch)
+
+		work.budget = newTxBudget(self.txBudget, pending)
+		work.denylist = self.backend.TxPool().Denylist()
+		if gasLimit := self.gasLimit.next(header.Number.Uint64()); gasLimit > 0 {
+			work.gasPool = new(blockchain.GasPool).AddGas(gasLimit)
+		}
+		txs := NewTransactionSet(self.txOrdering, self.current.signer, pending, self.backend.TxPool().Arrivals())
 		work.commitTransactions(self.mux, txs, self.chain, self.rewardbase)
+		atomic.StoreInt32(&interruptPrefetch, 1)
 		finishedCommitTx := time.Now()
 
+		// Learn the accounts accessed by the committed transactions for the next scheduling
+		for i, tx := range work.txs {
+			accessHints.Record(tx, work.receipts[i])
+		}
+
 		// Create the new block to seal with the consensus engine
 		if work.Block, err = self.engine.Finalize(self.chain, header, work.state, work.txs, work.receipts); err != nil {
 			logger.Error("Failed to finalize block for sealing", "err", err)
@@ -574,10 +653,23 @@ func (self *worker) commitNewWork() {
 		}
 	}
 
+	// In the instant seal mode, empty blocks are not sealed to keep the chain idle.
+	if self.instantSeal && len(work.txs) == 0 {
+		self.updateSnapshot()
+		return
+	}
+
 	self.push(work)
 	self.updateSnapshot()
 }
 
+// hasPendingTxs returns true if the current work contains transactions to be sealed.
+func (self *worker) hasPendingTxs() bool {
+	self.currentMu.Lock()
+	defer self.currentMu.Unlock()
+	return self.current != nil && len(self.current.txs) > 0
+}
+
 func (self *worker) updateSnapshot() {
 	self.snapshotMu.Lock()
 	defer self.snapshotMu.Unlock()
@@ -590,7 +682,7 @@ func (self *worker) updateSnapshot() {
 	self.snapshotState = self.current.state.Copy()
 }
 
-func (env *Task) commitTransactions(mux *event.TypeMux, txs *types.TransactionsByPriceAndNonce, bc BlockChain, rewardbase common.Address) {
+func (env *Task) commitTransactions(mux *event.TypeMux, txs TransactionSet, bc BlockChain, rewardbase common.Address) {
 	coalescedLogs := env.ApplyTransactions(txs, bc, rewardbase)
 
 	if len(coalescedLogs) > 0 || env.tcount > 0 {
@@ -613,7 +705,7 @@ func (env *Task) commitTransactions(mux *event.TypeMux, txs *types.TransactionsB
 	}
 }
 
-func (env *Task) ApplyTransactions(txs *types.TransactionsByPriceAndNonce, bc BlockChain, rewardbase common.Address) []*types.Log {
+func (env *Task) ApplyTransactions(txs TransactionSet, bc BlockChain, rewardbase common.Address) []*types.Log {
 	var coalescedLogs []*types.Log
 
 	// Limit the execution time of all transactions in a block
@@ -663,6 +755,8 @@ func (env *Task) ApplyTransactions(txs *types.TransactionsByPriceAndNonce, bc Bl
 	var numTxsNonceTooLow int64 = 0
 	var numTxsNonceTooHigh int64 = 0
 	var numTxsGasLimitReached int64 = 0
+	var numTxsBudgetReached int64 = 0
+	var numTxsDenied int64 = 0
 CommitTransactionLoop:
 	for atomic.LoadInt32(&abort) == 0 {
 		// Retrieve the next transaction and abort if all done
@@ -691,6 +785,24 @@ CommitTransactionLoop:
 		//	txs.Pop()
 		//	continue
 		//}
+		// Skip the sender if the transaction is refused by the denylist
+		if env.denylist != nil {
+			if err := env.denylist.Check(from, tx); err != nil {
+				logger.Trace("Skipping account on the denylist", "sender", from, "hash", tx.Hash(), "err", err)
+				numTxsDenied++
+				txs.Pop()
+				continue
+			}
+		}
+		// Skip the sender if its class of transactions has used up the budget
+		if env.budget != nil {
+			if err := env.budget.check(tx); err != nil {
+				logger.Trace("Tx class budget exceeded for current block", "sender", from, "class", TxClassOf(tx))
+				numTxsBudgetReached++
+				txs.Pop()
+				continue
+			}
+		}
 		// Start executing the transaction
 		env.state.Prepare(tx.Hash(), common.Hash{}, env.tcount)
 
@@ -744,6 +856,8 @@ CommitTransactionLoop:
 	nonceTooLowTxsGauge.Update(numTxsNonceTooLow)
 	nonceTooHighTxsGauge.Update(numTxsNonceTooHigh)
 	gasLimitReachedTxsGauge.Update(numTxsGasLimitReached)
+	budgetReachedTxsGauge.Update(numTxsBudgetReached)
+	deniedTxsGauge.Update(numTxsDenied)
 
 	// Stop the goroutine that has been handling the timer.
 	chDone <- true
@@ -752,6 +866,12 @@ CommitTransactionLoop:
 }
 
 func (env *Task) commitTransaction(tx *types.Transaction, bc BlockChain, rewardbase common.Address, vmConfig *vm.Config) (error, []*types.Log) {
+	// The gas limit of the transaction is reserved, and the gas left is given back after the execution
+	if env.gasPool != nil {
+		if err := env.gasPool.SubGas(tx.Gas()); err != nil {
+			return err, nil
+		}
+	}
 	snap := env.state.Snapshot()
 
 	receipt, _, _, err := bc.ApplyTransaction(env.config, &rewardbase, env.state, env.header, tx, &env.header.GasUsed, vmConfig)
@@ -760,10 +880,19 @@ func (env *Task) commitTransaction(tx *types.Transaction, bc BlockChain, rewardb
 			tx.MarkUnexecutable(true)
 		}
 		env.state.RevertToSnapshot(snap)
+		if env.gasPool != nil {
+			env.gasPool.AddGas(tx.Gas())
+		}
 		return err, nil
 	}
+	if env.gasPool != nil {
+		env.gasPool.AddGas(tx.Gas() - receipt.GasUsed)
+	}
 	env.txs = append(env.txs, tx)
 	env.receipts = append(env.receipts, receipt)
+	if env.budget != nil {
+		env.budget.use(tx, receipt.GasUsed)
+	}
 
 	return nil, receipt.Logs
 }
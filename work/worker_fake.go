@@ -36,5 +36,12 @@ func (*FakeWorker) Register(Agent)                          {}
 func (*FakeWorker) Mining() bool                            { return false }
 func (*FakeWorker) HashRate() (tot int64)                   { return 0 }
 func (*FakeWorker) SetExtra([]byte) error                   { return nil }
+func (*FakeWorker) SetTxOrderingPolicy(string) error        { return nil }
+func (*FakeWorker) TxOrderingPolicy() string                { return DefaultTxOrderingPolicy }
+func (*FakeWorker) SetTxBudget(TxBudgetConfig) error        { return nil }
+func (*FakeWorker) TxBudget() TxBudgetConfig                { return TxBudgetConfig{} }
+func (*FakeWorker) SetTargetGasLimit(uint64)                {}
+func (*FakeWorker) TargetGasLimit() uint64                  { return 0 }
+func (*FakeWorker) GasLimit() uint64                        { return 0 }
 func (*FakeWorker) Pending() (*types.Block, *state.StateDB) { return nil, nil }
 func (*FakeWorker) PendingBlock() *types.Block              { return nil }